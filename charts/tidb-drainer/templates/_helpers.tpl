@@ -43,14 +43,39 @@ config-file: |-
   ssl-ca = "/var/lib/drainer-syncer-checkpoint-tls/ca.crt"
   ssl-cert = "/var/lib/drainer-syncer-checkpoint-tls/tls.crt"
   ssl-key = "/var/lib/drainer-syncer-checkpoint-tls/tls.key"
-  {{- end -}}
   {{- if .Values.tlsSyncer.checkpoint.certAllowedCN }}
   cert-allowed-cn = {{ .Values.tlsSyncer.checkpoint.certAllowedCN | toJson }}
+  {{- end -}}
   {{- end -}}
     {{- end -}}
   {{- end -}}
 {{- end -}}
 
+{{/*
+Encapsulate the data of the TLS secrets mounted by drainer, whose hash is recorded in the pod template so that
+drainer is restarted to load the rotated certificates. The secrets are looked up from the cluster by Helm 3.
+*/}}
+{{- define "drainer.tlsSecretData" -}}
+{{- $secrets := list -}}
+{{- if and .Values.tlsCluster .Values.tlsCluster.enabled -}}
+{{- $secrets = append $secrets (include "drainer.tlsSecretName" .) -}}
+{{- end -}}
+{{- if .Values.tlsSyncer -}}
+{{- if .Values.tlsSyncer.tlsClientSecretName -}}
+{{- $secrets = append $secrets .Values.tlsSyncer.tlsClientSecretName -}}
+{{- end -}}
+{{- if .Values.tlsSyncer.checkpoint -}}
+{{- if .Values.tlsSyncer.checkpoint.tlsClientSecretName -}}
+{{- $secrets = append $secrets .Values.tlsSyncer.checkpoint.tlsClientSecretName -}}
+{{- end -}}
+{{- end -}}
+{{- end -}}
+{{- range $secrets }}
+{{- $secret := lookup "v1" "Secret" $.Release.Namespace . }}
+{{ . }}: {{ if $secret }}{{ $secret.data | toJson }}{{ end }}
+{{- end -}}
+{{- end -}}
+
 {{- define "drainer-configmap.name" -}}
 {{ include "drainer.name" . }}-{{ include "drainer-configmap.data" . | sha256sum | trunc 8 }}
 {{- end -}}
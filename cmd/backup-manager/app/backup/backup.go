@@ -22,32 +22,54 @@ import (
 	"os/exec"
 	"path"
 	"strings"
+	"time"
 
 	backupUtil "github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
+const (
+	// maxSASTokenRotationRetries is the max times to rerun br after it fails with the SAS token rotated
+	maxSASTokenRotationRetries = 3
+	// progressUpdateInterval is the min interval to update the progress of a step reported by BR
+	progressUpdateInterval = 30 * time.Second
+)
+
 // Options contains the input arguments to the backup command
 type Options struct {
 	backupUtil.GenericOptions
+	// Subcommand is what the backup job does, it backs up the data by default,
+	// and truncates or stops the log backup with the log subcommands
+	Subcommand string
+	// TruncateUntil is the ts to truncate the log backup until
+	TruncateUntil string
 }
 
-// backupData generates br args and runs br binary to do the real backup work
-func (bo *Options) backupData(ctx context.Context, backup *v1alpha1.Backup) error {
+// clusterArgs generates the br args to access the cluster
+func (bo *Options) clusterArgs(backup *v1alpha1.Backup) []string {
 	clusterNamespace := backup.Spec.BR.ClusterNamespace
 	if backup.Spec.BR.ClusterNamespace == "" {
 		clusterNamespace = backup.Namespace
 	}
 	args := make([]string, 0)
-	args = append(args, fmt.Sprintf("--pd=%s-pd.%s:2379", backup.Spec.BR.Cluster, clusterNamespace))
+	args = append(args, fmt.Sprintf("--pd=%s-pd.%s:%d", backup.Spec.BR.Cluster, clusterNamespace, bo.PDPort))
 	if bo.TLSCluster {
 		args = append(args, fmt.Sprintf("--ca=%s", path.Join(util.ClusterClientTLSPath, corev1.ServiceAccountRootCAKey)))
 		args = append(args, fmt.Sprintf("--cert=%s", path.Join(util.ClusterClientTLSPath, corev1.TLSCertKey)))
 		args = append(args, fmt.Sprintf("--key=%s", path.Join(util.ClusterClientTLSPath, corev1.TLSPrivateKeyKey)))
 	}
+	return args
+}
+
+// backupData generates br args and runs br binary to do the real backup work,
+// the progress reported by BR is updated to the Backup status
+func (bo *Options) backupData(ctx context.Context, backup *v1alpha1.Backup, statusUpdater controller.BackupConditionUpdaterInterface) error {
+	args := bo.clusterArgs(backup)
 	// `options` in spec are put to the last because we want them to have higher priority than generated arguments
 	dataArgs, err := constructOptions(backup)
 	if err != nil {
@@ -67,8 +89,56 @@ func (bo *Options) backupData(ctx context.Context, backup *v1alpha1.Backup) erro
 	}
 	fullArgs = append(fullArgs, args...)
 	klog.Infof("Running br command with args: %v", fullArgs)
+	sasToken := backupUtil.GetAzblobSASToken(backup.Spec.StorageProvider)
+	progress := &progressUpdater{backup: backup, statusUpdater: statusUpdater}
+	for retries := 0; ; retries++ {
+		err = bo.runBR(ctx, fullArgs, sasToken, progress)
+		if err == nil {
+			break
+		}
+		// a long running backup fails once the SAS token expires, so br is rerun with the rotated token
+		rotated := backupUtil.GetAzblobSASToken(backup.Spec.StorageProvider)
+		if ctx.Err() != nil || rotated == sasToken || retries >= maxSASTokenRotationRetries {
+			return err
+		}
+		klog.Errorf("cluster %s, br failed with the SAS token rotated, rerun br, err: %v", bo, err)
+		sasToken = rotated
+	}
+
+	klog.Infof("Backup data for cluster %s successfully", bo)
+	return nil
+}
+
+// runLogBackupCommand generates the args of `br log <command>` and runs br binary to start, truncate or stop the log backup
+func (bo *Options) runLogBackupCommand(ctx context.Context, backup *v1alpha1.Backup, command string, truncateUntil uint64) error {
+	args, err := backupUtil.ConstructBRGlobalOptionsForLogBackup(backup, command, truncateUntil)
+	if err != nil {
+		return err
+	}
+	if command == "start" {
+		// `options` in spec are put to the last because we want them to have higher priority than generated arguments
+		args = append(args, backup.Spec.BR.Options...)
+	}
+	fullArgs := append([]string{"log", command}, bo.clusterArgs(backup)...)
+	fullArgs = append(fullArgs, args...)
+	klog.Infof("Running br command with args: %v", fullArgs)
+	if err := bo.runBR(ctx, fullArgs, backupUtil.GetAzblobSASToken(backup.Spec.StorageProvider), nil); err != nil {
+		return err
+	}
+	klog.Infof("Run br log %s for cluster %s successfully", command, bo)
+	return nil
+}
+
+// runBR runs br binary with the args, the SAS token of the azure blob storage is passed to br if it's not empty,
+// and the progress logged by br is reported to the progress updater if it's not nil
+func (bo *Options) runBR(ctx context.Context, fullArgs []string, sasToken string, progress *progressUpdater) error {
+	args := fullArgs
+	if sasToken != "" {
+		// the SAS token is not logged
+		args = append(args[:len(args):len(args)], fmt.Sprintf("--azblob.sas-token=%s", sasToken))
+	}
 	bin := path.Join(util.BRBinPath, "br")
-	cmd := exec.CommandContext(ctx, bin, fullArgs...)
+	cmd := exec.CommandContext(ctx, bin, args...)
 
 	stdOut, err := cmd.StdoutPipe()
 	if err != nil {
@@ -89,7 +159,10 @@ func (bo *Options) backupData(ctx context.Context, backup *v1alpha1.Backup) erro
 		if strings.Contains(line, "[ERROR]") {
 			errMsg += line
 		}
-
+		if step, percentage, ok := backupUtil.ParseBRProgress(line); ok && progress != nil {
+			remaining, _ := backupUtil.ParseBRRemaining(line)
+			progress.update(step, percentage, remaining)
+		}
 		klog.Info(strings.Replace(line, "\n", "", -1))
 		if err != nil || io.EOF == err {
 			break
@@ -104,8 +177,6 @@ func (bo *Options) backupData(ctx context.Context, backup *v1alpha1.Backup) erro
 	if err != nil {
 		return fmt.Errorf("cluster %s, wait pipe message failed, errMsg %s, err: %v", bo, errMsg, err)
 	}
-
-	klog.Infof("Backup data for cluster %s successfully", bo)
 	return nil
 }
 
@@ -128,6 +199,40 @@ func constructOptions(backup *v1alpha1.Backup) ([]string, error) {
 	if config.Checksum != nil {
 		args = append(args, fmt.Sprintf("--checksum=%t", *config.Checksum))
 	}
+	if config.IgnoreStats != nil {
+		args = append(args, fmt.Sprintf("--ignore-stats=%t", *config.IgnoreStats))
+	}
 	args = append(args, config.Options...)
 	return args, nil
 }
+
+// progressUpdater updates the progress of the steps reported by BR to the Backup status,
+// the progress of a step is updated at most once per progressUpdateInterval except the completion.
+type progressUpdater struct {
+	backup        *v1alpha1.Backup
+	statusUpdater controller.BackupConditionUpdaterInterface
+	lastStep      string
+	lastUpdate    time.Time
+}
+
+// update updates the progress of the step, the completion time of the step is estimated
+// by the remaining time reported by BR, which is zero if unknown
+func (u *progressUpdater) update(step string, progress float64, remaining time.Duration) {
+	now := time.Now()
+	if step == u.lastStep && progress < 100 && now.Sub(u.lastUpdate) < progressUpdateInterval {
+		return
+	}
+	u.lastStep = step
+	u.lastUpdate = now
+	newStatus := &controller.BackupUpdateStatus{
+		ProgressStep:       &step,
+		Progress:           &progress,
+		ProgressUpdateTime: &metav1.Time{Time: now},
+	}
+	if remaining > 0 && progress < 100 {
+		newStatus.ProgressEstimatedCompletionTime = &metav1.Time{Time: now.Add(remaining)}
+	}
+	if err := u.statusUpdater.Update(u.backup, nil, newStatus); err != nil {
+		klog.Warningf("update progress %.2f%% of step %s of backup %s/%s failed, err: %v", progress, step, u.backup.Namespace, u.backup.Name, err)
+	}
+}
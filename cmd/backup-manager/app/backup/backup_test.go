@@ -0,0 +1,76 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestConstructOptions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	concurrency := uint32(4)
+	rateLimit := uint(64)
+	backup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "bk", Namespace: "ns"},
+		Spec: v1alpha1.BackupSpec{
+			Type: v1alpha1.BackupTypeFull,
+			StorageProvider: v1alpha1.StorageProvider{
+				S3: &v1alpha1.S3StorageProvider{Bucket: "backup", Prefix: "bk"},
+			},
+			BR: &v1alpha1.BRConfig{Cluster: "tc", ClusterNamespace: "ns"},
+		},
+	}
+
+	// the flags are not set if they are not specified
+	args, err := constructOptions(backup)
+	g.Expect(err).NotTo(HaveOccurred())
+	for _, arg := range args {
+		g.Expect(arg).NotTo(Or(HavePrefix("--concurrency"), HavePrefix("--ratelimit"), HavePrefix("--checksum")))
+	}
+
+	backup.Spec.BR.Concurrency = &concurrency
+	backup.Spec.BR.RateLimit = &rateLimit
+	backup.Spec.BR.Checksum = pointer.BoolPtr(false)
+	backup.Spec.BR.Options = []string{"--log-level=info"}
+	args, err = constructOptions(backup)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(args).To(ContainElements("--concurrency=4", "--ratelimit=64", "--checksum=false"))
+	// the options are appended at last to override the others
+	g.Expect(args[len(args)-1]).To(Equal("--log-level=info"))
+}
+
+func TestClusterArgs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	backup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "bk", Namespace: "ns"},
+		Spec: v1alpha1.BackupSpec{
+			BR: &v1alpha1.BRConfig{Cluster: "tc"},
+		},
+	}
+	bo := &Options{}
+	bo.PDPort = v1alpha1.DefaultPDClientPort
+	g.Expect(bo.clusterArgs(backup)).To(Equal([]string{"--pd=tc-pd.ns:2379"}))
+
+	// the customized PD client port of the cluster
+	bo.PDPort = 12379
+	backup.Spec.BR.ClusterNamespace = "tc-ns"
+	g.Expect(bo.clusterArgs(backup)).To(Equal([]string{"--pd=tc-pd.tc-ns:12379"}))
+}
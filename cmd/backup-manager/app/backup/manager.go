@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	bkconstants "github.com/pingcap/tidb-operator/pkg/backup/constants"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	corev1 "k8s.io/api/core/v1"
@@ -95,6 +96,11 @@ func (bm *Manager) ProcessBackup() error {
 		return fmt.Errorf("no br config in %s", bm)
 	}
 
+	if backup.IsLogBackup() {
+		// the log backup doesn't adjust the tikv_gc_life_time, so the DB is not needed
+		return bm.performLogBackup(ctx, backup.DeepCopy())
+	}
+
 	if backup.Spec.From == nil {
 		// skip the DB initialization if spec.from is not specified
 		return bm.performBackup(ctx, backup.DeepCopy(), nil)
@@ -167,8 +173,8 @@ func (bm *Manager) performBackup(ctx context.Context, backup *v1alpha1.Backup, d
 	}
 
 	var (
-		oldTikvGCTime, tikvGCLifeTime             string
-		oldTikvGCTimeDuration, tikvGCTimeDuration time.Duration
+		oldTikvGCTime, originalTikvGCTime, tikvGCLifeTime                     string
+		oldTikvGCTimeDuration, originalTikvGCTimeDuration, tikvGCTimeDuration time.Duration
 	)
 
 	// set tikv gc life time to prevent gc when backing up data
@@ -234,6 +240,37 @@ func (bm *Manager) performBackup(ctx context.Context, backup *v1alpha1.Backup, d
 			}
 		}
 
+		originalTikvGCTime, originalTikvGCTimeDuration, err = bm.GetOriginalTikvGCLifeTime(backup, bm.backupLister, oldTikvGCTime, oldTikvGCTimeDuration)
+		if err != nil {
+			errs = append(errs, err)
+			klog.Errorf("cluster %s get original %s failed, err: %s", bm, constants.TikvGCVariable, err)
+			uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:    v1alpha1.BackupFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "GetOriginalTikvGCLifeTimeFailed",
+				Message: err.Error(),
+			}, nil)
+			errs = append(errs, uerr)
+			return errorutils.NewAggregate(errs)
+		}
+
+		if originalTikvGCTimeDuration < tikvGCTimeDuration {
+			// record the original tikv_gc_life_time before adjusting it, so that it can be
+			// restored even if the backup is interrupted and retried
+			if err := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:   v1alpha1.BackupPrepare,
+				Status: corev1.ConditionTrue,
+			}, &controller.BackupUpdateStatus{
+				TikvGCLifeTime: &v1alpha1.TikvGCLifeTimeStatus{
+					Original: originalTikvGCTime,
+					Adjusted: tikvGCLifeTime,
+					State:    v1alpha1.TikvGCLifeTimeAdjusted,
+				},
+			}); err != nil {
+				return err
+			}
+		}
+
 		if oldTikvGCTimeDuration < tikvGCTimeDuration {
 			err = bm.SetTikvGCLifeTime(ctx, db, tikvGCLifeTime)
 			if err != nil {
@@ -261,20 +298,20 @@ func (bm *Manager) performBackup(ctx context.Context, backup *v1alpha1.Backup, d
 	}
 
 	// run br binary to do the real job
-	backupErr := bm.backupData(ctx, backup)
+	backupErr := bm.backupData(ctx, backup, bm.StatusUpdater)
 
-	if db != nil && oldTikvGCTimeDuration < tikvGCTimeDuration {
+	if db != nil && originalTikvGCTimeDuration < tikvGCTimeDuration {
 		// use another context to revert `tikv_gc_life_time` back.
 		// `DefaultTerminationGracePeriodSeconds` for a pod is 30, so we use a smaller timeout value here.
 		ctx2, cancel2 := context.WithTimeout(context.Background(), 25*time.Second)
 		defer cancel2()
-		err = bm.SetTikvGCLifeTime(ctx2, db, oldTikvGCTime)
+		err = bm.ResetTikvGCLifeTime(ctx2, db, backup, bm.backupLister, bm.StatusUpdater, originalTikvGCTime, tikvGCLifeTime)
 		if err != nil {
 			if backupErr != nil {
 				errs = append(errs, backupErr)
 			}
 			errs = append(errs, err)
-			klog.Errorf("cluster %s reset tikv GC life time to %s failed, err: %s", bm, oldTikvGCTime, err)
+			klog.Errorf("cluster %s reset tikv GC life time to %s failed, err: %s", bm, originalTikvGCTime, err)
 			uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
 				Type:    v1alpha1.BackupFailed,
 				Status:  corev1.ConditionTrue,
@@ -284,7 +321,6 @@ func (bm *Manager) performBackup(ctx context.Context, backup *v1alpha1.Backup, d
 			errs = append(errs, uerr)
 			return errorutils.NewAggregate(errs)
 		}
-		klog.Infof("reset cluster %s %s to %s success", bm, constants.TikvGCVariable, oldTikvGCTime)
 	}
 
 	if backupErr != nil {
@@ -336,3 +372,98 @@ func (bm *Manager) performBackup(ctx context.Context, backup *v1alpha1.Backup, d
 		Status: corev1.ConditionTrue,
 	}, updateStatus)
 }
+
+// performLogBackup starts, truncates or stops the task of the log backup by the subcommand. The log backup
+// is Running after the task is started and never completes, as the task keeps backing up until it's stopped.
+func (bm *Manager) performLogBackup(ctx context.Context, backup *v1alpha1.Backup) error {
+	switch bm.Subcommand {
+	case bkconstants.LogTruncateSubcommand:
+		return bm.truncateLogBackup(ctx, backup)
+	case bkconstants.LogStopSubcommand:
+		return bm.stopLogBackup(ctx, backup)
+	default:
+		return bm.startLogBackup(ctx, backup)
+	}
+}
+
+func (bm *Manager) startLogBackup(ctx context.Context, backup *v1alpha1.Backup) error {
+	started := time.Now()
+
+	var errs []error
+	backupFullPath, err := util.GetStoragePath(backup)
+	if err != nil {
+		errs = append(errs, err)
+		uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:    v1alpha1.BackupFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  "GetBackupRemotePathFailed",
+			Message: err.Error(),
+		}, nil)
+		errs = append(errs, uerr)
+		return errorutils.NewAggregate(errs)
+	}
+
+	if err := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupPrepare,
+		Status: corev1.ConditionTrue,
+	}, &controller.BackupUpdateStatus{
+		BackupPath: &backupFullPath,
+	}); err != nil {
+		return err
+	}
+
+	if err := bm.runLogBackupCommand(ctx, backup, "start", 0); err != nil {
+		errs = append(errs, err)
+		klog.Errorf("start log backup of cluster %s failed, err: %s", bm, err)
+		uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:    v1alpha1.BackupFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  "StartLogBackupFailed",
+			Message: err.Error(),
+		}, nil)
+		errs = append(errs, uerr)
+		return errorutils.NewAggregate(errs)
+	}
+	klog.Infof("start log backup of cluster %s to %s success", bm, backupFullPath)
+
+	return bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupRunning,
+		Status: corev1.ConditionTrue,
+	}, &controller.BackupUpdateStatus{
+		TimeStarted: &metav1.Time{Time: started},
+	})
+}
+
+// truncateLogBackup truncates the data of the log backup before the ts, the failure of truncating
+// doesn't fail the log backup, it's retried by the controller
+func (bm *Manager) truncateLogBackup(ctx context.Context, backup *v1alpha1.Backup) error {
+	truncateUntil, err := backuputil.ParseTSString(bm.TruncateUntil)
+	if err != nil {
+		return fmt.Errorf("invalid truncate until of log backup %s, err: %v", bm, err)
+	}
+	if err := bm.runLogBackupCommand(ctx, backup, "truncate", truncateUntil); err != nil {
+		return err
+	}
+	klog.Infof("truncate log backup %s until %d success", bm, truncateUntil)
+
+	truncatedUntil := strconv.FormatUint(truncateUntil, 10)
+	return bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupRunning,
+		Status: corev1.ConditionTrue,
+	}, &controller.BackupUpdateStatus{
+		LogTruncatedUntil: &truncatedUntil,
+	})
+}
+
+// stopLogBackup stops the task of the log backup before the Backup is deleted
+func (bm *Manager) stopLogBackup(ctx context.Context, backup *v1alpha1.Backup) error {
+	if err := bm.runLogBackupCommand(ctx, backup, "stop", 0); err != nil {
+		return err
+	}
+	klog.Infof("stop log backup %s success", bm)
+
+	return bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupLogStopped,
+		Status: corev1.ConditionTrue,
+	}, nil)
+}
@@ -19,6 +19,8 @@ import (
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/backup"
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/constants"
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	bkconstants "github.com/pingcap/tidb-operator/pkg/backup/constants"
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/spf13/cobra"
@@ -45,6 +47,9 @@ func NewBackupCommand() *cobra.Command {
 	cmd.Flags().StringVar(&bo.TiKVVersion, "tikvVersion", util.DefaultVersion, "TiKV version")
 	cmd.Flags().BoolVar(&bo.TLSClient, "client-tls", false, "Whether client tls is enabled")
 	cmd.Flags().BoolVar(&bo.TLSCluster, "cluster-tls", false, "Whether cluster tls is enabled")
+	cmd.Flags().Int32Var(&bo.PDPort, "pd-port", v1alpha1.DefaultPDClientPort, "The client port of PD of the cluster")
+	cmd.Flags().StringVar(&bo.Subcommand, "subcommand", bkconstants.BackupSubcommand, "What the backup job does, one of backup, log-truncate and log-stop")
+	cmd.Flags().StringVar(&bo.TruncateUntil, "truncate-until", "0", "The ts to truncate the log backup until")
 	return cmd
 }
 
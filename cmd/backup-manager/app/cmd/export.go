@@ -47,6 +47,7 @@ func NewExportCommand() *cobra.Command {
 	cmd.Flags().StringVar(&bo.Bucket, "bucket", "", "Bucket in which to store the backup data")
 	cmd.Flags().BoolVar(&bo.TLSClient, "client-tls", false, "Whether client tls is enabled")
 	cmd.Flags().StringVar(&bo.StorageType, "storageType", "", "Backend storage type")
+	cmd.Flags().Int32Var(&bo.Shard, "shard", -1, "The index of the shard to export if the backup is sharded")
 	return cmd
 }
 
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/constants"
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/restore"
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/spf13/cobra"
@@ -47,6 +48,7 @@ func NewRestoreCommand() *cobra.Command {
 	cmd.Flags().StringVar(&ro.TiKVVersion, "tikvVersion", util.DefaultVersion, "TiKV version")
 	cmd.Flags().BoolVar(&ro.TLSClient, "client-tls", false, "Whether client tls is enabled")
 	cmd.Flags().BoolVar(&ro.TLSCluster, "cluster-tls", false, "Whether cluster tls is enabled")
+	cmd.Flags().Int32Var(&ro.PDPort, "pd-port", v1alpha1.DefaultPDClientPort, "The client port of PD of the cluster")
 	return cmd
 }
 
@@ -61,6 +63,7 @@ func runRestore(restoreOpts restore.Options, kubecfg string) error {
 	informerFactory := informers.NewSharedInformerFactoryWithOptions(cli, constants.ResyncDuration, options...)
 	recorder := util.NewEventRecorder(kubeCli, "restore")
 	restoreInformer := informerFactory.Pingcap().V1alpha1().Restores()
+	backupInformer := informerFactory.Pingcap().V1alpha1().Backups()
 	statusUpdater := controller.NewRealRestoreConditionUpdater(cli, restoreInformer.Lister(), recorder)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -68,9 +71,9 @@ func runRestore(restoreOpts restore.Options, kubecfg string) error {
 	go informerFactory.Start(ctx.Done())
 
 	// waiting for the shared informer's store has synced.
-	cache.WaitForCacheSync(ctx.Done(), restoreInformer.Informer().HasSynced)
+	cache.WaitForCacheSync(ctx.Done(), restoreInformer.Informer().HasSynced, backupInformer.Informer().HasSynced)
 
 	klog.Infof("start to process restore %s", restoreOpts.String())
-	rm := restore.NewManager(restoreInformer.Lister(), statusUpdater, restoreOpts)
+	rm := restore.NewManager(restoreInformer.Lister(), backupInformer.Lister(), statusUpdater, restoreOpts)
 	return rm.ProcessRestore()
 }
@@ -57,6 +57,14 @@ const (
 	// MetaFile is the file name for meta data of backup with BR
 	MetaFile = "backupmeta"
 
+	// LogBackupGlobalCheckpointPath is the directory of the global checkpoints
+	// uploaded by the stores to the storage of log backup
+	LogBackupGlobalCheckpointPath = "v1/global_checkpoint/"
+
+	// LogBackupTruncateSafePointFile is the file of the safe point of the truncated log backup,
+	// the misspelling is kept as the same as BR
+	LogBackupTruncateSafePointFile = "v1_stream_trancate_safepoint.txt"
+
 	// BR certificate storage path
 	BRCertPath = "/var/lib/br-tls"
 
@@ -38,15 +38,31 @@ type Options struct {
 	Bucket      string
 	Prefix      string
 	StorageType string
+	// Shard is the index of the shard to export, -1 if the backup is not sharded
+	Shard int32
 }
 
-func (bo *Options) getBackupFullPath() string {
-	return filepath.Join(constants.BackupRootPath, bo.getBackupRelativePath())
+func (bo *Options) isSharded() bool {
+	return bo.Shard >= 0
 }
 
-func (bo *Options) getBackupRelativePath() string {
+func (bo *Options) getBackupFullPath(backup *v1alpha1.Backup) string {
+	backupPath := filepath.Join(constants.BackupRootPath, bo.getBackupRelativePath(backup))
+	if bo.isSharded() {
+		// the shards are stored in the same directory
+		backupPath = filepath.Join(backupPath, fmt.Sprintf("shard-%d", bo.Shard))
+	}
+	return backupPath
+}
+
+func (bo *Options) getBackupRelativePath(backup *v1alpha1.Backup) string {
 	var backupRelativePath string
-	backupName := fmt.Sprintf("backup-%s", time.Now().UTC().Format(time.RFC3339))
+	backupTime := time.Now()
+	if bo.isSharded() {
+		// all the shards are exported at the creation time of the backup
+		backupTime = backup.CreationTimestamp.Time
+	}
+	backupName := fmt.Sprintf("backup-%s", backupTime.UTC().Format(time.RFC3339))
 	if len(bo.Prefix) == 0 {
 		backupRelativePath = fmt.Sprintf("%s/%s", bo.Bucket, backupName)
 	} else {
@@ -59,7 +75,7 @@ func (bo *Options) getDestBucketURI(remotePath string) string {
 	return fmt.Sprintf("%s://%s", bo.StorageType, remotePath)
 }
 
-func (bo *Options) dumpTidbClusterData(ctx context.Context, bfPath string, backup *v1alpha1.Backup) error {
+func (bo *Options) dumpTidbClusterData(ctx context.Context, bfPath string, backup *v1alpha1.Backup, shardArgs []string) error {
 	err := backupUtil.EnsureDirectoryExist(bfPath)
 	if err != nil {
 		return err
@@ -72,6 +88,7 @@ func (bo *Options) dumpTidbClusterData(ctx context.Context, bfPath string, backu
 		fmt.Sprintf("--password=%s", bo.Password),
 	}
 	args = append(args, backupUtil.ConstructDumplingOptionsForBackup(backup)...)
+	args = append(args, shardArgs...)
 	if bo.TLSClient {
 		args = append(args, fmt.Sprintf("--ca=%s", path.Join(util.TiDBClientTLSPath, corev1.ServiceAccountRootCAKey)))
 		args = append(args, fmt.Sprintf("--cert=%s", path.Join(util.TiDBClientTLSPath, corev1.TLSCertKey)))
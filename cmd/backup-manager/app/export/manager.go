@@ -16,7 +16,9 @@ package export
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"os"
+	"path"
 	"strings"
 	"time"
 
@@ -153,96 +155,167 @@ func (bm *BackupManager) ProcessBackup() error {
 func (bm *BackupManager) performBackup(ctx context.Context, backup *v1alpha1.Backup, db *sql.DB) error {
 	started := time.Now()
 
-	err := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
-		Type:   v1alpha1.BackupPrepare,
-		Status: corev1.ConditionTrue,
-	}, nil)
-	if err != nil {
-		return err
-	}
-
-	var errs []error
-	oldTikvGCTime, err := bm.GetTikvGCLifeTime(ctx, db)
-	if err != nil {
-		errs = append(errs, err)
-		klog.Errorf("cluster %s get %s failed, err: %s", bm, constants.TikvGCVariable, err)
-		uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
-			Type:    v1alpha1.BackupFailed,
-			Status:  corev1.ConditionTrue,
-			Reason:  "GetTikvGCLifeTimeFailed",
-			Message: err.Error(),
+	var (
+		errs                       []error
+		err                        error
+		tikvGCLifeTime             string
+		tikvGCTimeDuration         time.Duration
+		originalTikvGCTime         string
+		originalTikvGCTimeDuration time.Duration
+	)
+	// only the first shard prepares and adjusts tikv_gc_life_time for a sharded backup,
+	// the other shards wait for the snapshot allocated by it after tikv_gc_life_time is adjusted
+	if !bm.isSharded() || bm.Shard == 0 {
+		err = bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:   v1alpha1.BackupPrepare,
+			Status: corev1.ConditionTrue,
 		}, nil)
-		errs = append(errs, uerr)
-		return errorutils.NewAggregate(errs)
-	}
-	klog.Infof("cluster %s %s is %s", bm, constants.TikvGCVariable, oldTikvGCTime)
+		if err != nil {
+			return err
+		}
 
-	oldTikvGCTimeDuration, err := time.ParseDuration(oldTikvGCTime)
-	if err != nil {
-		errs = append(errs, err)
-		klog.Errorf("cluster %s parse old %s failed, err: %s", bm, constants.TikvGCVariable, err)
-		uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
-			Type:    v1alpha1.BackupFailed,
-			Status:  corev1.ConditionTrue,
-			Reason:  "ParseOldTikvGCLifeTimeFailed",
-			Message: err.Error(),
-		}, nil)
-		errs = append(errs, uerr)
-		return errorutils.NewAggregate(errs)
-	}
+		oldTikvGCTime, err := bm.GetTikvGCLifeTime(ctx, db)
+		if err != nil {
+			errs = append(errs, err)
+			klog.Errorf("cluster %s get %s failed, err: %s", bm, constants.TikvGCVariable, err)
+			uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:    v1alpha1.BackupFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "GetTikvGCLifeTimeFailed",
+				Message: err.Error(),
+			}, nil)
+			errs = append(errs, uerr)
+			return errorutils.NewAggregate(errs)
+		}
+		klog.Infof("cluster %s %s is %s", bm, constants.TikvGCVariable, oldTikvGCTime)
 
-	var tikvGCTimeDuration time.Duration
-	var tikvGCLifeTime string
-	if backup.Spec.TikvGCLifeTime != nil {
-		tikvGCLifeTime = *backup.Spec.TikvGCLifeTime
-		tikvGCTimeDuration, err = time.ParseDuration(tikvGCLifeTime)
+		oldTikvGCTimeDuration, err := time.ParseDuration(oldTikvGCTime)
 		if err != nil {
 			errs = append(errs, err)
-			klog.Errorf("cluster %s parse configured %s failed, err: %s", bm, constants.TikvGCVariable, err)
+			klog.Errorf("cluster %s parse old %s failed, err: %s", bm, constants.TikvGCVariable, err)
 			uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
 				Type:    v1alpha1.BackupFailed,
 				Status:  corev1.ConditionTrue,
-				Reason:  "ParseConfiguredTikvGCLifeTimeFailed",
+				Reason:  "ParseOldTikvGCLifeTimeFailed",
 				Message: err.Error(),
 			}, nil)
 			errs = append(errs, uerr)
 			return errorutils.NewAggregate(errs)
 		}
-	} else {
-		tikvGCLifeTime = constants.TikvGCLifeTime
-		tikvGCTimeDuration, err = time.ParseDuration(tikvGCLifeTime)
+
+		if backup.Spec.TikvGCLifeTime != nil {
+			tikvGCLifeTime = *backup.Spec.TikvGCLifeTime
+			tikvGCTimeDuration, err = time.ParseDuration(tikvGCLifeTime)
+			if err != nil {
+				errs = append(errs, err)
+				klog.Errorf("cluster %s parse configured %s failed, err: %s", bm, constants.TikvGCVariable, err)
+				uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+					Type:    v1alpha1.BackupFailed,
+					Status:  corev1.ConditionTrue,
+					Reason:  "ParseConfiguredTikvGCLifeTimeFailed",
+					Message: err.Error(),
+				}, nil)
+				errs = append(errs, uerr)
+				return errorutils.NewAggregate(errs)
+			}
+		} else {
+			tikvGCLifeTime = constants.TikvGCLifeTime
+			tikvGCTimeDuration, err = time.ParseDuration(tikvGCLifeTime)
+			if err != nil {
+				errs = append(errs, err)
+				klog.Errorf("cluster %s parse default %s failed, err: %s", bm, constants.TikvGCVariable, err)
+				uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+					Type:    v1alpha1.BackupFailed,
+					Status:  corev1.ConditionTrue,
+					Reason:  "ParseDefaultTikvGCLifeTimeFailed",
+					Message: err.Error(),
+				}, nil)
+				errs = append(errs, uerr)
+				return errorutils.NewAggregate(errs)
+			}
+		}
+
+		originalTikvGCTime, originalTikvGCTimeDuration, err = bm.GetOriginalTikvGCLifeTime(backup, bm.backupLister, oldTikvGCTime, oldTikvGCTimeDuration)
 		if err != nil {
 			errs = append(errs, err)
-			klog.Errorf("cluster %s parse default %s failed, err: %s", bm, constants.TikvGCVariable, err)
+			klog.Errorf("cluster %s get original %s failed, err: %s", bm, constants.TikvGCVariable, err)
 			uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
 				Type:    v1alpha1.BackupFailed,
 				Status:  corev1.ConditionTrue,
-				Reason:  "ParseDefaultTikvGCLifeTimeFailed",
+				Reason:  "GetOriginalTikvGCLifeTimeFailed",
 				Message: err.Error(),
 			}, nil)
 			errs = append(errs, uerr)
 			return errorutils.NewAggregate(errs)
 		}
+
+		if originalTikvGCTimeDuration < tikvGCTimeDuration {
+			// record the original tikv_gc_life_time before adjusting it, so that it can be
+			// restored even if the backup is interrupted and retried
+			err = bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:   v1alpha1.BackupPrepare,
+				Status: corev1.ConditionTrue,
+			}, &controller.BackupUpdateStatus{
+				TikvGCLifeTime: &v1alpha1.TikvGCLifeTimeStatus{
+					Original: originalTikvGCTime,
+					Adjusted: tikvGCLifeTime,
+					State:    v1alpha1.TikvGCLifeTimeAdjusted,
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if oldTikvGCTimeDuration < tikvGCTimeDuration {
+			err = bm.SetTikvGCLifeTime(ctx, db, tikvGCLifeTime)
+			if err != nil {
+				errs = append(errs, err)
+				klog.Errorf("cluster %s set tikv GC life time to %s failed, err: %s", bm, constants.TikvGCLifeTime, err)
+				uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+					Type:    v1alpha1.BackupFailed,
+					Status:  corev1.ConditionTrue,
+					Reason:  "SetTikvGCLifeTimeFailed",
+					Message: err.Error(),
+				}, nil)
+				errs = append(errs, uerr)
+				return errorutils.NewAggregate(errs)
+			}
+			klog.Infof("set cluster %s %s to %s success", bm, constants.TikvGCVariable, constants.TikvGCLifeTime)
+		}
 	}
 
-	if oldTikvGCTimeDuration < tikvGCTimeDuration {
-		err = bm.SetTikvGCLifeTime(ctx, db, tikvGCLifeTime)
+	var shardArgs []string
+	if bm.isSharded() {
+		snapshotTS, err := bm.getShardSnapshotTS(ctx, db, backup)
 		if err != nil {
 			errs = append(errs, err)
-			klog.Errorf("cluster %s set tikv GC life time to %s failed, err: %s", bm, constants.TikvGCLifeTime, err)
+			klog.Errorf("cluster %s get snapshot ts of shard %d failed, err: %s", bm, bm.Shard, err)
 			uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
 				Type:    v1alpha1.BackupFailed,
 				Status:  corev1.ConditionTrue,
-				Reason:  "SetTikvGCLifeTimeFailed",
+				Reason:  "GetSnapshotTSFailed",
+				Message: err.Error(),
+			}, nil)
+			errs = append(errs, uerr)
+			return errorutils.NewAggregate(errs)
+		}
+		shardArgs, err = bm.getShardArgs(ctx, db, backup, snapshotTS)
+		if err != nil {
+			errs = append(errs, err)
+			klog.Errorf("cluster %s get tables of shard %d failed, err: %s", bm, bm.Shard, err)
+			uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:    v1alpha1.BackupFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "GetShardTablesFailed",
 				Message: err.Error(),
 			}, nil)
 			errs = append(errs, uerr)
 			return errorutils.NewAggregate(errs)
 		}
-		klog.Infof("set cluster %s %s to %s success", bm, constants.TikvGCVariable, constants.TikvGCLifeTime)
 	}
 
-	backupFullPath := bm.getBackupFullPath()
+	backupFullPath := bm.getBackupFullPath(backup)
 	// TODO: Concurrent get file size and upload backup data to speed up processing time
 	archiveBackupPath := backupFullPath + constants.DefaultArchiveExtention
 	remotePath := strings.TrimPrefix(archiveBackupPath, constants.BackupRootPath+"/")
@@ -250,6 +323,19 @@ func (bm *BackupManager) performBackup(ctx context.Context, backup *v1alpha1.Bac
 	updatePathStatus := &controller.BackupUpdateStatus{
 		BackupPath: &bucketURI,
 	}
+	if bm.isSharded() {
+		// the backup path is the directory of all the shards
+		backupDirURI := bm.getDestBucketURI(path.Dir(remotePath))
+		updatePathStatus = &controller.BackupUpdateStatus{
+			BackupPath: &backupDirURI,
+			Shard: &v1alpha1.BackupShardStatus{
+				Index:       bm.Shard,
+				Phase:       v1alpha1.BackupRunning,
+				BackupPath:  bucketURI,
+				TimeStarted: metav1.Time{Time: started},
+			},
+		}
+	}
 	err = bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
 		Type:   v1alpha1.BackupRunning,
 		Status: corev1.ConditionTrue,
@@ -258,19 +344,23 @@ func (bm *BackupManager) performBackup(ctx context.Context, backup *v1alpha1.Bac
 		return err
 	}
 
-	backupErr := bm.dumpTidbClusterData(ctx, backupFullPath, backup)
-	if oldTikvGCTimeDuration < tikvGCTimeDuration {
+	backupErr := bm.dumpTidbClusterData(ctx, backupFullPath, backup, shardArgs)
+	if bm.isSharded() && backupErr == nil && originalTikvGCTimeDuration < tikvGCTimeDuration {
+		// the adjusted tikv_gc_life_time is still needed until all the other shards finish
+		bm.waitForOtherShards(ctx, backup)
+	}
+	if originalTikvGCTimeDuration < tikvGCTimeDuration {
 		// use another context to revert `tikv_gc_life_time` back.
 		// `DefaultTerminationGracePeriodSeconds` for a pod is 30, so we use a smaller timeout value here.
 		ctx2, cancel2 := context.WithTimeout(context.Background(), 25*time.Second)
 		defer cancel2()
-		err = bm.SetTikvGCLifeTime(ctx2, db, oldTikvGCTime)
+		err = bm.ResetTikvGCLifeTime(ctx2, db, backup, bm.backupLister, bm.StatusUpdater, originalTikvGCTime, tikvGCLifeTime)
 		if err != nil {
 			if backupErr != nil {
 				errs = append(errs, backupErr)
 			}
 			errs = append(errs, err)
-			klog.Errorf("cluster %s reset tikv GC life time to %s failed, err: %s", bm, oldTikvGCTime, err)
+			klog.Errorf("cluster %s reset tikv GC life time to %s failed, err: %s", bm, originalTikvGCTime, err)
 			uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
 				Type:    v1alpha1.BackupFailed,
 				Status:  corev1.ConditionTrue,
@@ -280,7 +370,6 @@ func (bm *BackupManager) performBackup(ctx context.Context, backup *v1alpha1.Bac
 			errs = append(errs, uerr)
 			return errorutils.NewAggregate(errs)
 		}
-		klog.Infof("reset cluster %s %s to %s success", bm, constants.TikvGCVariable, oldTikvGCTime)
 	}
 
 	if backupErr != nil {
@@ -365,6 +454,24 @@ func (bm *BackupManager) performBackup(ctx context.Context, backup *v1alpha1.Bac
 
 	finish := time.Now()
 
+	if bm.isSharded() {
+		// the backup is completed by the controller after all the shards are complete
+		return bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:   v1alpha1.BackupRunning,
+			Status: corev1.ConditionTrue,
+		}, &controller.BackupUpdateStatus{
+			Shard: &v1alpha1.BackupShardStatus{
+				Index:         bm.Shard,
+				Phase:         v1alpha1.BackupComplete,
+				BackupPath:    bucketURI,
+				BackupSize:    size,
+				CommitTs:      commitTs,
+				TimeStarted:   metav1.Time{Time: started},
+				TimeCompleted: metav1.Time{Time: finish},
+			},
+		})
+	}
+
 	backupSizeReadable := humanize.Bytes(uint64(size))
 	updateStatus := &controller.BackupUpdateStatus{
 		TimeStarted:        &metav1.Time{Time: started},
@@ -379,3 +486,105 @@ func (bm *BackupManager) performBackup(ctx context.Context, backup *v1alpha1.Bac
 		Status: corev1.ConditionTrue,
 	}, updateStatus)
 }
+
+// getShardSnapshotTS returns the snapshot ts all the shards of the backup are exported at. The first shard
+// allocates it after tikv_gc_life_time is adjusted so that the snapshot is protected from GC, and records it
+// in the status of the backup, the other shards wait until it is recorded.
+func (bm *BackupManager) getShardSnapshotTS(ctx context.Context, db *sql.DB, backup *v1alpha1.Backup) (string, error) {
+	if bm.Shard != 0 {
+		return bm.waitForSnapshotTS(ctx, backup)
+	}
+	if backup.Status.CommitTs != "" && hasOtherShardsStarted(backup, bm.Shard) {
+		// the first shard is retried, keep the snapshot the other shards have been exported at
+		klog.Infof("cluster %s reuses the snapshot ts %s of the backup", bm, backup.Status.CommitTs)
+		return backup.Status.CommitTs, nil
+	}
+	snapshotTS, err := bm.GetSnapshotTS(ctx, db)
+	if err != nil {
+		return "", err
+	}
+	err = bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupPrepare,
+		Status: corev1.ConditionTrue,
+	}, &controller.BackupUpdateStatus{
+		CommitTs: &snapshotTS,
+	})
+	if err != nil {
+		return "", err
+	}
+	klog.Infof("cluster %s allocates the snapshot ts %s of the backup", bm, snapshotTS)
+	return snapshotTS, nil
+}
+
+func hasOtherShardsStarted(backup *v1alpha1.Backup, index int32) bool {
+	for _, shard := range backup.Status.Shards {
+		if shard.Index != index {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForSnapshotTS waits until the snapshot ts of the backup is recorded by the first shard
+func (bm *BackupManager) waitForSnapshotTS(ctx context.Context, backup *v1alpha1.Backup) (string, error) {
+	klog.Infof("wait for the first shard of cluster %s to allocate the snapshot ts", bm)
+	var snapshotTS string
+	err := wait.PollImmediateUntil(constants.PollInterval, func() (bool, error) {
+		latest, err := bm.backupLister.Backups(backup.Namespace).Get(backup.Name)
+		if err != nil {
+			klog.Warningf("get cluster %s backup failed, err: %s", bm, err)
+			return false, nil
+		}
+		if v1alpha1.IsBackupFailed(latest) {
+			return false, fmt.Errorf("backup %s/%s failed before the snapshot ts is allocated", backup.Namespace, backup.Name)
+		}
+		snapshotTS = latest.Status.CommitTs
+		return snapshotTS != "", nil
+	}, ctx.Done())
+	if err != nil {
+		return "", err
+	}
+	klog.Infof("cluster %s gets the snapshot ts %s of the backup", bm, snapshotTS)
+	return snapshotTS, nil
+}
+
+// getShardArgs returns the dumpling args exporting the tables of the shard at the snapshot of the backup
+func (bm *BackupManager) getShardArgs(ctx context.Context, db *sql.DB, backup *v1alpha1.Backup, snapshotTS string) ([]string, error) {
+	tables, err := bm.GetTables(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("cluster %s has %d tables, export shard %d of %d", bm, len(tables), bm.Shard, backup.GetShardCount())
+	// all the shards are exported at the same snapshot to be consistent
+	args := []string{fmt.Sprintf("--snapshot=%s", snapshotTS)}
+	args = append(args, util.ConstructDumplingShardFilters(tables, bm.Shard, backup.GetShardCount())...)
+	return args, nil
+}
+
+// waitForOtherShards waits until all the other shards of the backup are complete, or the backup fails
+func (bm *BackupManager) waitForOtherShards(ctx context.Context, backup *v1alpha1.Backup) {
+	klog.Infof("wait for the other shards of cluster %s to finish", bm)
+	err := wait.PollImmediateUntil(constants.PollInterval, func() (bool, error) {
+		latest, err := bm.backupLister.Backups(backup.Namespace).Get(backup.Name)
+		if err != nil {
+			klog.Warningf("get cluster %s backup failed, err: %s", bm, err)
+			return false, nil
+		}
+		if v1alpha1.IsBackupFailed(latest) {
+			return true, nil
+		}
+		for i := int32(0); i < latest.GetShardCount(); i++ {
+			if i == bm.Shard {
+				continue
+			}
+			shard := v1alpha1.GetBackupShardStatus(&latest.Status, i)
+			if shard == nil || shard.Phase != v1alpha1.BackupComplete {
+				return false, nil
+			}
+		}
+		return true, nil
+	}, ctx.Done())
+	if err != nil {
+		klog.Warningf("stop waiting for the other shards of cluster %s, err: %s", bm, err)
+	}
+}
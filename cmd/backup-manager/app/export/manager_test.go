@@ -0,0 +1,77 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetShardSnapshotTS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newManager := func(shard int32, backup *v1alpha1.Backup) *BackupManager {
+		informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Pingcap().V1alpha1().Backups()
+		g.Expect(informer.Informer().GetIndexer().Add(backup)).To(Succeed())
+		opts := Options{Shard: shard}
+		opts.Namespace = backup.Namespace
+		opts.ResourceName = backup.Name
+		return NewBackupManager(informer.Lister(), controller.NewFakeBackupConditionUpdater(informer), opts)
+	}
+	newBackup := func() *v1alpha1.Backup {
+		return &v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: "bk", Namespace: "ns"},
+			Spec:       v1alpha1.BackupSpec{Dumpling: &v1alpha1.DumplingConfig{ShardCount: 2}},
+		}
+	}
+
+	// the other shards use the snapshot ts recorded by the first shard
+	backup := newBackup()
+	backup.Status.CommitTs = "420000000000000000"
+	ts, err := newManager(1, backup).getShardSnapshotTS(context.TODO(), nil, backup)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ts).To(Equal("420000000000000000"))
+
+	// the other shards stop waiting if the backup fails before the snapshot ts is recorded
+	backup = newBackup()
+	v1alpha1.UpdateBackupCondition(&backup.Status, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupFailed,
+		Status: corev1.ConditionTrue,
+	})
+	_, err = newManager(1, backup).getShardSnapshotTS(context.TODO(), nil, backup)
+	g.Expect(err).To(HaveOccurred())
+
+	// the other shards stop waiting if they are stopped
+	backup = newBackup()
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	_, err = newManager(1, backup).getShardSnapshotTS(ctx, nil, backup)
+	g.Expect(err).To(HaveOccurred())
+
+	// the retried first shard keeps the snapshot ts the other shards have been exported at
+	backup = newBackup()
+	backup.Status.CommitTs = "420000000000000000"
+	backup.Status.Shards = []v1alpha1.BackupShardStatus{{Index: 1, Phase: v1alpha1.BackupComplete, CommitTs: "420000000000000000"}}
+	ts, err = newManager(0, backup).getShardSnapshotTS(context.TODO(), nil, backup)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ts).To(Equal("420000000000000000"))
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"k8s.io/klog/v2"
+)
+
+// userTablesSQL lists the user tables and views
+const userTablesSQL = "SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE FROM INFORMATION_SCHEMA.TABLES" +
+	" WHERE UPPER(TABLE_SCHEMA) NOT IN ('MYSQL', 'INFORMATION_SCHEMA', 'PERFORMANCE_SCHEMA', 'METRICS_SCHEMA', 'INSPECTION_SCHEMA')"
+
+// dropConflicts drops the tables of the restore set that exist in the target cluster, so that they are
+// restored from the backup. It's only called with spec.force, the restore is failed by the controller
+// before the job is created if there are conflicts otherwise.
+func (rm *Manager) dropConflicts(ctx context.Context, db *sql.DB, restore *v1alpha1.Restore) error {
+	rows, err := db.QueryContext(ctx, userTablesSQL)
+	if err != nil {
+		return fmt.Errorf("list tables of cluster %s failed, sql: %s, err: %v", rm, userTablesSQL, err)
+	}
+	type conflict struct {
+		tableName
+		tableType string
+	}
+	var conflicts []conflict
+	for rows.Next() {
+		var c conflict
+		if err := rows.Scan(&c.schema, &c.name, &c.tableType); err != nil {
+			rows.Close()
+			return fmt.Errorf("list tables of cluster %s failed, err: %v", rm, err)
+		}
+		if backuputil.InRestoreSet(restore, c.schema, c.name) {
+			conflicts = append(conflicts, c)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list tables of cluster %s failed, err: %v", rm, err)
+	}
+
+	for _, c := range conflicts {
+		sql := fmt.Sprintf("DROP TABLE IF EXISTS %s", c.tableName)
+		if c.tableType == "VIEW" {
+			sql = fmt.Sprintf("DROP VIEW IF EXISTS %s", c.tableName)
+		}
+		if _, err := db.ExecContext(ctx, sql); err != nil {
+			return fmt.Errorf("drop conflicting table %s of cluster %s failed, err: %v", c.tableName, rm, err)
+		}
+		klog.Infof("cluster %s drop conflicting table %s success", rm, c.tableName)
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	bkconstants "github.com/pingcap/tidb-operator/pkg/backup/constants"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	corev1 "k8s.io/api/core/v1"
@@ -35,6 +36,7 @@ import (
 
 type Manager struct {
 	restoreLister listers.RestoreLister
+	backupLister  listers.BackupLister
 	StatusUpdater controller.RestoreConditionUpdaterInterface
 	Options
 }
@@ -42,10 +44,12 @@ type Manager struct {
 // NewManager return a RestoreManager
 func NewManager(
 	restoreLister listers.RestoreLister,
+	backupLister listers.BackupLister,
 	statusUpdater controller.RestoreConditionUpdaterInterface,
 	restoreOpts Options) *Manager {
 	return &Manager{
 		restoreLister,
+		backupLister,
 		statusUpdater,
 		restoreOpts,
 	}
@@ -162,6 +166,24 @@ func (rm *Manager) performRestore(ctx context.Context, restore *v1alpha1.Restore
 		return errorutils.NewAggregate(errs)
 	}
 
+	var logBackup *v1alpha1.Backup
+	if restore.IsPiTR() {
+		var reason string
+		logBackup, commitTs, reason, err = rm.validatePiTR(ctx, restore, commitTs)
+		if err != nil {
+			errs = append(errs, err)
+			klog.Errorf("validate point-in-time restore of cluster %s failed, err: %s", rm, err)
+			uerr := rm.StatusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  reason,
+				Message: err.Error(),
+			}, nil)
+			errs = append(errs, uerr)
+			return errorutils.NewAggregate(errs)
+		}
+	}
+
 	var (
 		oldTikvGCTime, tikvGCLifeTime             string
 		oldTikvGCTimeDuration, tikvGCTimeDuration time.Duration
@@ -248,7 +270,22 @@ func (rm *Manager) performRestore(ctx context.Context, restore *v1alpha1.Restore
 		}
 	}
 
-	restoreErr := rm.restoreData(ctx, restore)
+	if db != nil && restore.Spec.Force {
+		if err := rm.dropConflicts(ctx, db, restore); err != nil {
+			errs = append(errs, err)
+			klog.Errorf("cluster %s drop conflicting tables failed, err: %s", rm, err)
+			uerr := rm.StatusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "DropConflictsFailed",
+				Message: err.Error(),
+			}, nil)
+			errs = append(errs, uerr)
+			return errorutils.NewAggregate(errs)
+		}
+	}
+
+	restoreErr := rm.restoreData(ctx, restore, logBackup, commitTs, rm.StatusUpdater)
 
 	if db != nil && oldTikvGCTimeDuration < tikvGCTimeDuration {
 		// use another context to revert `tikv_gc_life_time` back.
@@ -288,6 +325,49 @@ func (rm *Manager) performRestore(ctx context.Context, restore *v1alpha1.Restore
 	}
 	klog.Infof("restore cluster %s from %s succeed", rm, restore.Spec.Type)
 
+	completeCondition := &v1alpha1.RestoreCondition{
+		Type:   v1alpha1.RestoreComplete,
+		Status: corev1.ConditionTrue,
+	}
+	// the data is restored, the failure of the post-step is noted in the Complete condition
+	if db != nil && restore.Spec.Statistics != nil && restore.Spec.Statistics.Analyze {
+		err = rm.StatusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+			Type:   v1alpha1.RestoreAnalyzingStatistics,
+			Status: corev1.ConditionTrue,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		if err := rm.analyzeStatistics(ctx, db, restore); err != nil {
+			klog.Errorf("analyze statistics of cluster %s failed, err: %s", rm, err)
+			completeCondition.Reason = "AnalyzeStatisticsFailed"
+			completeCondition.Message = err.Error()
+		} else {
+			klog.Infof("analyze statistics of cluster %s succeed", rm)
+		}
+	}
+	if db != nil && restore.Spec.TiFlashReplica != nil {
+		err = rm.StatusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+			Type:   v1alpha1.RestoreSettingTiFlashReplicas,
+			Status: corev1.ConditionTrue,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		if err := rm.setTiFlashReplicas(ctx, db, restore); err != nil {
+			klog.Errorf("set tiflash replicas of cluster %s failed, err: %s", rm, err)
+			if completeCondition.Reason != "" {
+				completeCondition.Reason += ",SetTiFlashReplicasFailed"
+				completeCondition.Message += "; " + err.Error()
+			} else {
+				completeCondition.Reason = "SetTiFlashReplicasFailed"
+				completeCondition.Message = err.Error()
+			}
+		} else {
+			klog.Infof("set tiflash replicas of cluster %s succeed", rm)
+		}
+	}
+
 	finish := time.Now()
 	ts := strconv.FormatUint(commitTs, 10)
 	updateStatus := &controller.RestoreUpdateStatus{
@@ -295,8 +375,32 @@ func (rm *Manager) performRestore(ctx context.Context, restore *v1alpha1.Restore
 		TimeCompleted: &metav1.Time{Time: finish},
 		CommitTs:      &ts,
 	}
-	return rm.StatusUpdater.Update(restore, &v1alpha1.RestoreCondition{
-		Type:   v1alpha1.RestoreComplete,
-		Status: corev1.ConditionTrue,
-	}, updateStatus)
+	return rm.StatusUpdater.Update(restore, completeCondition, updateStatus)
+}
+
+// validatePiTR checks that the restored ts of the point-in-time restore is within the window
+// of the log backup and not earlier than the commit ts of the snapshot backup, and returns
+// the log backup and the restored ts, or the reason of the failure.
+func (rm *Manager) validatePiTR(ctx context.Context, restore *v1alpha1.Restore, commitTs uint64) (*v1alpha1.Backup, uint64, string, error) {
+	logBackup, err := rm.backupLister.Backups(restore.Namespace).Get(restore.Spec.PiTR.LogBackup)
+	if err != nil {
+		return nil, 0, "GetLogBackupFailed", fmt.Errorf("get log backup %s/%s failed, err: %v", restore.Namespace, restore.Spec.PiTR.LogBackup, err)
+	}
+	restoredTs, err := backuputil.ParseRestoredTs(restore.Spec.PiTR.RestoredTs)
+	if err != nil {
+		return nil, 0, "ParseRestoredTsFailed", err
+	}
+	start, end, err := util.GetLogBackupWindow(ctx, logBackup.Spec.StorageProvider)
+	if err != nil {
+		return nil, 0, "GetLogBackupWindowFailed", err
+	}
+	klog.Infof("cluster %s log backup window is [%d, %d], snapshot backup commit ts is %d, restored ts is %d", rm, start, end, commitTs, restoredTs)
+
+	if restoredTs < commitTs {
+		return nil, 0, "RestoredTsBeforeSnapshotBackup", fmt.Errorf("restored ts %d is earlier than the commit ts %d of the snapshot backup", restoredTs, commitTs)
+	}
+	if commitTs < start || restoredTs > end {
+		return nil, 0, "RestoredTsOutOfLogBackupWindow", fmt.Errorf("[%d, %d] from the commit ts of the snapshot backup to the restored ts is out of the log backup window [%d, %d]", commitTs, restoredTs, start, end)
+	}
+	return logBackup, restoredTs, "", nil
 }
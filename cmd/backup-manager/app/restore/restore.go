@@ -22,39 +22,55 @@ import (
 	"os/exec"
 	"path"
 	"strings"
+	"time"
 
 	backupUtil "github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
+// progressUpdateInterval is the min interval to update the progress of a step reported by BR
+const progressUpdateInterval = 30 * time.Second
+
 type Options struct {
 	backupUtil.GenericOptions
 }
 
-func (ro *Options) restoreData(ctx context.Context, restore *v1alpha1.Restore) error {
+// restoreData restores the data with BR, logBackup is the log backup of the point-in-time restore,
+// and restoredTs is the ts which the cluster is restored to, they are ignored for the snapshot restore.
+func (ro *Options) restoreData(
+	ctx context.Context,
+	restore *v1alpha1.Restore,
+	logBackup *v1alpha1.Backup,
+	restoredTs uint64,
+	statusUpdater controller.RestoreConditionUpdaterInterface,
+) error {
 	clusterNamespace := restore.Spec.BR.ClusterNamespace
 	if restore.Spec.BR.ClusterNamespace == "" {
 		clusterNamespace = restore.Namespace
 	}
 	args := make([]string, 0)
-	args = append(args, fmt.Sprintf("--pd=%s-pd.%s:2379", restore.Spec.BR.Cluster, clusterNamespace))
+	args = append(args, fmt.Sprintf("--pd=%s-pd.%s:%d", restore.Spec.BR.Cluster, clusterNamespace, ro.PDPort))
 	if ro.TLSCluster {
 		args = append(args, fmt.Sprintf("--ca=%s", path.Join(util.ClusterClientTLSPath, corev1.ServiceAccountRootCAKey)))
 		args = append(args, fmt.Sprintf("--cert=%s", path.Join(util.ClusterClientTLSPath, corev1.TLSCertKey)))
 		args = append(args, fmt.Sprintf("--key=%s", path.Join(util.ClusterClientTLSPath, corev1.TLSPrivateKeyKey)))
 	}
 	// `options` in spec are put to the last because we want them to have higher priority than generated arguments
-	dataArgs, err := constructBROptions(restore)
+	dataArgs, err := constructBROptions(restore, logBackup, restoredTs)
 	if err != nil {
 		return err
 	}
 	args = append(args, dataArgs...)
 
 	var restoreType string
-	if restore.Spec.Type == "" {
+	if restore.IsPiTR() {
+		restoreType = "point"
+	} else if restore.Spec.Type == "" {
 		restoreType = string(v1alpha1.BackupTypeFull)
 	} else {
 		restoreType = string(restore.Spec.Type)
@@ -65,8 +81,13 @@ func (ro *Options) restoreData(ctx context.Context, restore *v1alpha1.Restore) e
 	}
 	fullArgs = append(fullArgs, args...)
 	klog.Infof("Running br command with args: %v", fullArgs)
+	brArgs := fullArgs
+	if sasToken := backupUtil.GetAzblobSASToken(restore.Spec.StorageProvider); sasToken != "" {
+		// the SAS token is not logged
+		brArgs = append(brArgs[:len(brArgs):len(brArgs)], fmt.Sprintf("--azblob.sas-token=%s", sasToken))
+	}
 	bin := path.Join(util.BRBinPath, "br")
-	cmd := exec.CommandContext(ctx, bin, fullArgs...)
+	cmd := exec.CommandContext(ctx, bin, brArgs...)
 
 	stdOut, err := cmd.StdoutPipe()
 	if err != nil {
@@ -81,12 +102,17 @@ func (ro *Options) restoreData(ctx context.Context, restore *v1alpha1.Restore) e
 		return fmt.Errorf("cluster %s, execute br command failed, args: %s, err: %v", ro, fullArgs, err)
 	}
 	var errMsg string
+	progress := &progressUpdater{restore: restore, statusUpdater: statusUpdater}
 	reader := bufio.NewReader(stdOut)
 	for {
 		line, err := reader.ReadString('\n')
 		if strings.Contains(line, "[ERROR]") {
 			errMsg += line
 		}
+		if step, percentage, ok := backupUtil.ParseBRProgress(line); ok {
+			remaining, _ := backupUtil.ParseBRRemaining(line)
+			progress.update(step, percentage, remaining)
+		}
 		klog.Info(strings.Replace(line, "\n", "", -1))
 		if err != nil || io.EOF == err {
 			break
@@ -106,8 +132,16 @@ func (ro *Options) restoreData(ctx context.Context, restore *v1alpha1.Restore) e
 	return nil
 }
 
-func constructBROptions(restore *v1alpha1.Restore) ([]string, error) {
-	args, err := backupUtil.ConstructBRGlobalOptionsForRestore(restore)
+func constructBROptions(restore *v1alpha1.Restore, logBackup *v1alpha1.Backup, restoredTs uint64) ([]string, error) {
+	var (
+		args []string
+		err  error
+	)
+	if restore.IsPiTR() {
+		args, err = backupUtil.ConstructBRGlobalOptionsForPiTRRestore(restore, logBackup, restoredTs)
+	} else {
+		args, err = backupUtil.ConstructBRGlobalOptionsForRestore(restore)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -127,3 +161,34 @@ func constructBROptions(restore *v1alpha1.Restore) ([]string, error) {
 	args = append(args, config.Options...)
 	return args, nil
 }
+
+// progressUpdater updates the progress of the steps reported by BR to the Restore status,
+// the progress of a step is updated at most once per progressUpdateInterval except the completion.
+type progressUpdater struct {
+	restore       *v1alpha1.Restore
+	statusUpdater controller.RestoreConditionUpdaterInterface
+	lastStep      string
+	lastUpdate    time.Time
+}
+
+// update updates the progress of the step, the completion time of the step is estimated
+// by the remaining time reported by BR, which is zero if unknown
+func (u *progressUpdater) update(step string, progress float64, remaining time.Duration) {
+	now := time.Now()
+	if step == u.lastStep && progress < 100 && now.Sub(u.lastUpdate) < progressUpdateInterval {
+		return
+	}
+	u.lastStep = step
+	u.lastUpdate = now
+	newStatus := &controller.RestoreUpdateStatus{
+		ProgressStep:       &step,
+		Progress:           &progress,
+		ProgressUpdateTime: &metav1.Time{Time: now},
+	}
+	if remaining > 0 && progress < 100 {
+		newStatus.ProgressEstimatedCompletionTime = &metav1.Time{Time: now.Add(remaining)}
+	}
+	if err := u.statusUpdater.Update(u.restore, nil, newStatus); err != nil {
+		klog.Warningf("update progress %.2f%% of step %s of restore %s/%s failed, err: %v", progress, step, u.restore.Namespace, u.restore.Name, err)
+	}
+}
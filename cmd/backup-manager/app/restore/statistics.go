@@ -0,0 +1,115 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+// tablesWithoutStatsSQL lists the user tables without any histogram, e.g. the tables restored from
+// a backup taken without statistics
+const tablesWithoutStatsSQL = "SELECT t.TABLE_SCHEMA, t.TABLE_NAME FROM INFORMATION_SCHEMA.TABLES t" +
+	" WHERE t.TABLE_TYPE = 'BASE TABLE'" +
+	" AND UPPER(t.TABLE_SCHEMA) NOT IN ('MYSQL', 'INFORMATION_SCHEMA', 'PERFORMANCE_SCHEMA', 'METRICS_SCHEMA', 'INSPECTION_SCHEMA')" +
+	" AND NOT EXISTS (SELECT 1 FROM mysql.stats_histograms h WHERE h.table_id = t.TIDB_TABLE_ID)"
+
+type tableName struct {
+	schema string
+	name   string
+}
+
+func (t tableName) String() string {
+	return fmt.Sprintf("`%s`.`%s`", strings.ReplaceAll(t.schema, "`", "``"), strings.ReplaceAll(t.name, "`", "``"))
+}
+
+// analyzeStatistics runs ANALYZE TABLE for the restored tables without statistics, the tables are
+// limited to the restored db or table for the restore of type db or table.
+func (rm *Manager) analyzeStatistics(ctx context.Context, db *sql.DB, restore *v1alpha1.Restore) error {
+	query := tablesWithoutStatsSQL
+	var args []interface{}
+	if restore.Spec.Type == v1alpha1.BackupTypeDB || restore.Spec.Type == v1alpha1.BackupTypeTable {
+		query += " AND t.TABLE_SCHEMA = ?"
+		args = append(args, restore.Spec.BR.DB)
+	}
+	if restore.Spec.Type == v1alpha1.BackupTypeTable {
+		query += " AND t.TABLE_NAME = ?"
+		args = append(args, restore.Spec.BR.Table)
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("list tables without statistics of cluster %s failed, sql: %s, err: %v", rm, query, err)
+	}
+	var tables []tableName
+	for rows.Next() {
+		var t tableName
+		if err := rows.Scan(&t.schema, &t.name); err != nil {
+			rows.Close()
+			return fmt.Errorf("list tables without statistics of cluster %s failed, err: %v", rm, err)
+		}
+		tables = append(tables, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list tables without statistics of cluster %s failed, err: %v", rm, err)
+	}
+	klog.Infof("cluster %s has %d tables without statistics to analyze", rm, len(tables))
+
+	concurrency := 1
+	if c := restore.Spec.Statistics.Concurrency; c != nil && *c > 0 {
+		concurrency = int(*c)
+	}
+	ch := make(chan tableName)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range ch {
+				sql := fmt.Sprintf("ANALYZE TABLE %s", t)
+				if _, err := db.ExecContext(ctx, sql); err != nil {
+					klog.Errorf("cluster %s analyze table %s failed, err: %s", rm, t, err)
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("analyze table %s failed: %v", t, err))
+					mu.Unlock()
+					continue
+				}
+				klog.Infof("cluster %s analyze table %s success", rm, t)
+			}
+		}()
+	}
+	for _, t := range tables {
+		if ctx.Err() != nil {
+			break
+		}
+		ch <- t
+	}
+	close(ch)
+	wg.Wait()
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	return errorutils.NewAggregate(errs)
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// dbTablesSQL lists the base tables of a database
+	dbTablesSQL = "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'"
+	// tiflashReplicaSQL lists the tables with TiFlash replicas and whether the replicas are available
+	tiflashReplicaSQL = "SELECT TABLE_SCHEMA, TABLE_NAME, AVAILABLE FROM INFORMATION_SCHEMA.TIFLASH_REPLICA"
+
+	defaultTiFlashReplicaWaitTimeout = time.Hour
+	tiflashReplicaCheckInterval      = 10 * time.Second
+)
+
+// setTiFlashReplicas sets the TiFlash replicas of the restored tables declared in the spec, and waits
+// for the replicas to be available. The tables without TiFlash replicas are not waited for.
+func (rm *Manager) setTiFlashReplicas(ctx context.Context, db *sql.DB, restore *v1alpha1.Restore) error {
+	spec := restore.Spec.TiFlashReplica
+	timeout := defaultTiFlashReplicaWaitTimeout
+	if spec.WaitTimeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(spec.WaitTimeout); err != nil {
+			return fmt.Errorf("parse tiflash replica wait timeout %s failed, err: %v", spec.WaitTimeout, err)
+		}
+	}
+
+	waiting := make(map[tableName]bool)
+	for _, t := range spec.Tables {
+		tables := []tableName{{schema: t.DB, name: t.Table}}
+		if t.Table == "" {
+			var err error
+			if tables, err = rm.listDBTables(ctx, db, t.DB); err != nil {
+				return err
+			}
+		}
+		for _, table := range tables {
+			sql := fmt.Sprintf("ALTER TABLE %s SET TIFLASH REPLICA %d", table, t.Replicas)
+			if _, err := db.ExecContext(ctx, sql); err != nil {
+				return fmt.Errorf("set tiflash replica of table %s of cluster %s failed, err: %v", table, rm, err)
+			}
+			klog.Infof("cluster %s set tiflash replica of table %s to %d success", rm, table, t.Replicas)
+			waiting[table] = t.Replicas > 0
+		}
+	}
+	for table, wait := range waiting {
+		if !wait {
+			delete(waiting, table)
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := rm.checkTiFlashReplicas(ctx, db, waiting); err != nil {
+			return err
+		}
+		if len(waiting) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("tiflash replicas of %d tables are not available in %s, e.g. %s", len(waiting), timeout, firstTable(waiting))
+		}
+		klog.Infof("cluster %s waiting for tiflash replicas of %d tables to be available", rm, len(waiting))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(tiflashReplicaCheckInterval):
+		}
+	}
+}
+
+// listDBTables lists the base tables of the database
+func (rm *Manager) listDBTables(ctx context.Context, db *sql.DB, schema string) ([]tableName, error) {
+	rows, err := db.QueryContext(ctx, dbTablesSQL, schema)
+	if err != nil {
+		return nil, fmt.Errorf("list tables of db %s of cluster %s failed, sql: %s, err: %v", schema, rm, dbTablesSQL, err)
+	}
+	defer rows.Close()
+	var tables []tableName
+	for rows.Next() {
+		t := tableName{schema: schema}
+		if err := rows.Scan(&t.name); err != nil {
+			return nil, fmt.Errorf("list tables of db %s of cluster %s failed, err: %v", schema, rm, err)
+		}
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tables of db %s of cluster %s failed, err: %v", schema, rm, err)
+	}
+	return tables, nil
+}
+
+// checkTiFlashReplicas removes the tables whose TiFlash replicas are available from waiting
+func (rm *Manager) checkTiFlashReplicas(ctx context.Context, db *sql.DB, waiting map[tableName]bool) error {
+	rows, err := db.QueryContext(ctx, tiflashReplicaSQL)
+	if err != nil {
+		return fmt.Errorf("list tiflash replicas of cluster %s failed, sql: %s, err: %v", rm, tiflashReplicaSQL, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t tableName
+		var available bool
+		if err := rows.Scan(&t.schema, &t.name, &available); err != nil {
+			return fmt.Errorf("list tiflash replicas of cluster %s failed, err: %v", rm, err)
+		}
+		if available {
+			delete(waiting, t)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list tiflash replicas of cluster %s failed, err: %v", rm, err)
+	}
+	return nil
+}
+
+// firstTable returns the first table in order for the error message
+func firstTable(tables map[tableName]bool) tableName {
+	names := make([]tableName, 0, len(tables))
+	for t := range tables {
+		names = append(names, t)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].String() < names[j].String()
+	})
+	return names[0]
+}
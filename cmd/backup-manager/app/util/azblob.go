@@ -0,0 +1,420 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backupconst "github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/driver"
+	"gocloud.dev/gcerrors"
+)
+
+const (
+	azblobAPIVersion      = "2019-12-12"
+	azblobDefaultPageSize = 1000
+)
+
+// azblobSASTokenFile is the SAS token in the mounted secret of the azure blob storage
+var azblobSASTokenFile = path.Join(backupconst.AzblobCredentialsPath, backupconst.AzblobSASToken)
+
+// GetAzblobSASToken returns the SAS token to access the azure blob storage. The token in the mounted secret is
+// preferred as it's updated by kubelet after the token is rotated, while the one in the env is fixed once the
+// job starts.
+func GetAzblobSASToken(provider v1alpha1.StorageProvider) string {
+	if provider.Azblob == nil {
+		return ""
+	}
+	return readAzblobSASToken()
+}
+
+func readAzblobSASToken() string {
+	if data, err := ioutil.ReadFile(azblobSASTokenFile); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	return os.Getenv("AZURE_STORAGE_SAS_TOKEN")
+}
+
+// azblobError is the error responded by the azure blob storage
+type azblobError struct {
+	statusCode int
+	message    string
+}
+
+func (e *azblobError) Error() string {
+	return fmt.Sprintf("azblob responds %d: %s", e.statusCode, e.message)
+}
+
+// azblobBucket is a driver.Bucket of the azure blob storage based on its REST API. It authorizes the requests
+// with the SAS token if there is one, which is re-read for every request, otherwise with the account key.
+type azblobBucket struct {
+	client     *http.Client
+	endpoint   string
+	account    string
+	accountKey []byte
+	container  string
+	sasToken   func() string
+}
+
+// newAzblobStorage initialize a new azure blob storage
+func newAzblobStorage(conf *azblobConfig) (*blob.Bucket, error) {
+	account := os.Getenv("AZURE_STORAGE_ACCOUNT")
+	if account == "" {
+		return nil, fmt.Errorf("the azure storage account is not set")
+	}
+	b := &azblobBucket{
+		client:    &http.Client{},
+		endpoint:  fmt.Sprintf("https://%s.blob.core.windows.net", account),
+		account:   account,
+		container: conf.container,
+		sasToken:  readAzblobSASToken,
+	}
+	if key := os.Getenv("AZURE_STORAGE_KEY"); key != "" {
+		accountKey, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("decode the azure storage account key failed, err: %v", err)
+		}
+		b.accountKey = accountKey
+	}
+	bucket := blob.NewBucket(b)
+	if conf.prefix == "" {
+		return bucket, nil
+	}
+	return blob.PrefixedBucket(bucket, strings.Trim(conf.prefix, "/")+"/"), nil
+}
+
+// do sends the request to the blob or the container if key is empty
+func (b *azblobBucket) do(ctx context.Context, method, key string, query url.Values, header http.Header) (*http.Response, error) {
+	u, err := url.Parse(b.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/" + b.container
+	if key != "" {
+		u.Path += "/" + key
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+	u.RawQuery = query.Encode()
+	sasToken := b.sasToken()
+	if sasToken != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += strings.TrimPrefix(sasToken, "?")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("x-ms-version", azblobAPIVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	if sasToken == "" && len(b.accountKey) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("SharedKey %s:%s", b.account, b.sign(req, u.EscapedPath(), query)))
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		msg, _ := ioutil.ReadAll(resp.Body)
+		if len(msg) == 0 {
+			msg = []byte(resp.Status)
+		}
+		return nil, &azblobError{statusCode: resp.StatusCode, message: string(msg)}
+	}
+	return resp, nil
+}
+
+// sign returns the signature of the request authorized with shared key,
+// see https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (b *azblobBucket) sign(req *http.Request, escapedPath string, query url.Values) string {
+	var msHeaders []string
+	for k := range req.Header {
+		if k = strings.ToLower(k); strings.HasPrefix(k, "x-ms-") {
+			msHeaders = append(msHeaders, k)
+		}
+	}
+	sort.Strings(msHeaders)
+	canonicalizedHeaders := ""
+	for _, k := range msHeaders {
+		canonicalizedHeaders += fmt.Sprintf("%s:%s\n", k, req.Header.Get(k))
+	}
+
+	var params []string
+	for k := range query {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	canonicalizedResource := "/" + b.account + escapedPath
+	for _, k := range params {
+		canonicalizedResource += fmt.Sprintf("\n%s:%s", strings.ToLower(k), strings.Join(query[k], ","))
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		"", // Content-Length
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, x-ms-date is used instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonicalizedHeaders + canonicalizedResource,
+	}, "\n")
+	h := hmac.New(sha256.New, b.accountKey)
+	h.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (b *azblobBucket) ErrorCode(err error) gcerrors.ErrorCode {
+	e, ok := err.(*azblobError)
+	if !ok {
+		return gcerrors.Unknown
+	}
+	switch e.statusCode {
+	case http.StatusNotFound:
+		return gcerrors.NotFound
+	case http.StatusForbidden:
+		return gcerrors.PermissionDenied
+	case http.StatusConflict:
+		return gcerrors.AlreadyExists
+	default:
+		return gcerrors.Unknown
+	}
+}
+
+func (b *azblobBucket) As(i interface{}) bool { return false }
+
+func (b *azblobBucket) ErrorAs(err error, i interface{}) bool { return false }
+
+func (b *azblobBucket) Attributes(ctx context.Context, key string) (*driver.Attributes, error) {
+	resp, err := b.do(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	md5, _ := base64.StdEncoding.DecodeString(resp.Header.Get("Content-MD5"))
+	metadata := map[string]string{}
+	for k := range resp.Header {
+		if lk := strings.ToLower(k); strings.HasPrefix(lk, "x-ms-meta-") {
+			metadata[strings.TrimPrefix(lk, "x-ms-meta-")] = resp.Header.Get(k)
+		}
+	}
+	return &driver.Attributes{
+		CacheControl:       resp.Header.Get("Cache-Control"),
+		ContentDisposition: resp.Header.Get("Content-Disposition"),
+		ContentEncoding:    resp.Header.Get("Content-Encoding"),
+		ContentLanguage:    resp.Header.Get("Content-Language"),
+		ContentType:        resp.Header.Get("Content-Type"),
+		Metadata:           metadata,
+		ModTime:            modTime,
+		Size:               resp.ContentLength,
+		MD5:                md5,
+	}, nil
+}
+
+// azblobListResult is the result of listing blobs,
+// see https://docs.microsoft.com/en-us/rest/api/storageservices/list-blobs
+type azblobListResult struct {
+	Blobs struct {
+		Blob []struct {
+			Name       string `xml:"Name"`
+			Properties struct {
+				LastModified  string `xml:"Last-Modified"`
+				ContentLength int64  `xml:"Content-Length"`
+				ContentMD5    string `xml:"Content-MD5"`
+			} `xml:"Properties"`
+		} `xml:"Blob"`
+		BlobPrefix []struct {
+			Name string `xml:"Name"`
+		} `xml:"BlobPrefix"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+func (b *azblobBucket) ListPaged(ctx context.Context, opts *driver.ListOptions) (*driver.ListPage, error) {
+	pageSize := opts.PageSize
+	if pageSize == 0 {
+		pageSize = azblobDefaultPageSize
+	}
+	query := url.Values{
+		"restype":    []string{"container"},
+		"comp":       []string{"list"},
+		"maxresults": []string{strconv.Itoa(pageSize)},
+	}
+	if opts.Prefix != "" {
+		query.Set("prefix", opts.Prefix)
+	}
+	if opts.Delimiter != "" {
+		query.Set("delimiter", opts.Delimiter)
+	}
+	if len(opts.PageToken) > 0 {
+		query.Set("marker", string(opts.PageToken))
+	}
+	if opts.BeforeList != nil {
+		if err := opts.BeforeList(b.As); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := b.do(ctx, http.MethodGet, "", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &azblobListResult{}
+	if err := xml.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, err
+	}
+	page := &driver.ListPage{}
+	for _, item := range result.Blobs.Blob {
+		modTime, _ := http.ParseTime(item.Properties.LastModified)
+		md5, _ := base64.StdEncoding.DecodeString(item.Properties.ContentMD5)
+		page.Objects = append(page.Objects, &driver.ListObject{
+			Key:     item.Name,
+			ModTime: modTime,
+			Size:    item.Properties.ContentLength,
+			MD5:     md5,
+		})
+	}
+	for _, prefix := range result.Blobs.BlobPrefix {
+		page.Objects = append(page.Objects, &driver.ListObject{
+			Key:   prefix.Name,
+			IsDir: true,
+		})
+	}
+	if len(result.Blobs.BlobPrefix) > 0 {
+		sort.Slice(page.Objects, func(i, j int) bool {
+			return page.Objects[i].Key < page.Objects[j].Key
+		})
+	}
+	if result.NextMarker != "" {
+		page.NextPageToken = []byte(result.NextMarker)
+	}
+	return page, nil
+}
+
+// azblobReader reads a blob from the response
+type azblobReader struct {
+	io.ReadCloser
+	attrs *driver.ReaderAttributes
+}
+
+func (r *azblobReader) Attributes() *driver.ReaderAttributes { return r.attrs }
+
+func (r *azblobReader) As(i interface{}) bool { return false }
+
+func (b *azblobBucket) NewRangeReader(ctx context.Context, key string, offset, length int64, opts *driver.ReaderOptions) (driver.Reader, error) {
+	header := http.Header{}
+	if offset > 0 || length >= 0 {
+		byteRange := fmt.Sprintf("bytes=%d-", offset)
+		if length >= 0 {
+			byteRange += strconv.FormatInt(offset+length-1, 10)
+		}
+		header.Set("x-ms-range", byteRange)
+	}
+	if length == 0 {
+		// the range of zero length is invalid, read the attributes only
+		attrs, err := b.Attributes(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &azblobReader{
+			ReadCloser: ioutil.NopCloser(strings.NewReader("")),
+			attrs:      &driver.ReaderAttributes{ContentType: attrs.ContentType, ModTime: attrs.ModTime, Size: attrs.Size},
+		}, nil
+	}
+	resp, err := b.do(ctx, http.MethodGet, key, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	if opts.BeforeRead != nil {
+		if err := opts.BeforeRead(b.As); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+
+	size := resp.ContentLength
+	if contentRange := resp.Header.Get("Content-Range"); contentRange != "" {
+		// bytes <start>-<end>/<size>
+		if i := strings.LastIndex(contentRange, "/"); i >= 0 {
+			size, _ = strconv.ParseInt(contentRange[i+1:], 10, 64)
+		}
+	}
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &azblobReader{
+		ReadCloser: resp.Body,
+		attrs: &driver.ReaderAttributes{
+			ContentType: resp.Header.Get("Content-Type"),
+			ModTime:     modTime,
+			Size:        size,
+		},
+	}, nil
+}
+
+func (b *azblobBucket) NewTypedWriter(ctx context.Context, key, contentType string, opts *driver.WriterOptions) (driver.Writer, error) {
+	return nil, fmt.Errorf("writing blobs to azblob is not supported yet")
+}
+
+func (b *azblobBucket) Copy(ctx context.Context, dstKey, srcKey string, opts *driver.CopyOptions) error {
+	return fmt.Errorf("copying blobs of azblob is not supported yet")
+}
+
+func (b *azblobBucket) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (b *azblobBucket) SignedURL(ctx context.Context, key string, opts *driver.SignedURLOptions) (string, error) {
+	return "", fmt.Errorf("signed url of azblob is not supported yet")
+}
+
+func (b *azblobBucket) Close() error { return nil }
+
+var _ driver.Bucket = &azblobBucket{}
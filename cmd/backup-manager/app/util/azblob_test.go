@@ -0,0 +1,132 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"gocloud.dev/blob"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+)
+
+func TestGetAzblobSASToken(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	dir, err := ioutil.TempDir("", "azblob")
+	g.Expect(err).Should(gomega.Succeed())
+	defer os.RemoveAll(dir)
+	origin := azblobSASTokenFile
+	azblobSASTokenFile = filepath.Join(dir, "sas_token")
+	defer func() { azblobSASTokenFile = origin }()
+
+	provider := v1alpha1.StorageProvider{Azblob: &v1alpha1.AzblobStorageProvider{}}
+	g.Expect(GetAzblobSASToken(v1alpha1.StorageProvider{})).Should(gomega.BeEmpty())
+
+	// the token in the env is used if the secret is not mounted
+	os.Setenv("AZURE_STORAGE_SAS_TOKEN", "env-token")
+	defer os.Unsetenv("AZURE_STORAGE_SAS_TOKEN")
+	g.Expect(GetAzblobSASToken(provider)).Should(gomega.Equal("env-token"))
+
+	// the rotated token in the mounted secret is preferred
+	g.Expect(ioutil.WriteFile(azblobSASTokenFile, []byte("rotated-token\n"), 0644)).Should(gomega.Succeed())
+	g.Expect(GetAzblobSASToken(provider)).Should(gomega.Equal("rotated-token"))
+}
+
+func TestAzblobBucket(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var requests []*http.Request
+	svc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		switch {
+		case r.URL.Path == "/container" && r.URL.Query().Get("comp") == "list":
+			w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults>
+  <Blobs>
+    <Blob>
+      <Name>prefix/backupmeta</Name>
+      <Properties>
+        <Last-Modified>Wed, 01 Sep 2021 08:00:00 GMT</Last-Modified>
+        <Content-Length>10</Content-Length>
+      </Properties>
+    </Blob>
+  </Blobs>
+  <NextMarker>next</NextMarker>
+</EnumerationResults>`))
+		case r.URL.Path == "/container/prefix/backupmeta":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			if r.Method == http.MethodHead {
+				w.Header().Set("Content-Length", "10")
+				return
+			}
+			w.Write([]byte("0123456789"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svc.Close()
+
+	token := "sv=1&sig=first"
+	b := &azblobBucket{
+		client:    svc.Client(),
+		endpoint:  svc.URL,
+		account:   "account",
+		container: "container",
+		sasToken:  func() string { return token },
+	}
+	bucket := blob.PrefixedBucket(blob.NewBucket(b), "prefix/")
+	ctx := context.Background()
+
+	data, err := bucket.ReadAll(ctx, "backupmeta")
+	g.Expect(err).Should(gomega.Succeed())
+	g.Expect(string(data)).Should(gomega.Equal("0123456789"))
+	g.Expect(requests[len(requests)-1].URL.Query().Get("sig")).Should(gomega.Equal("first"))
+
+	// the rotated token is used by the following requests
+	token = "sv=1&sig=second"
+	exist, err := bucket.Exists(ctx, "backupmeta")
+	g.Expect(err).Should(gomega.Succeed())
+	g.Expect(exist).Should(gomega.BeTrue())
+	g.Expect(requests[len(requests)-1].URL.Query().Get("sig")).Should(gomega.Equal("second"))
+	exist, err = bucket.Exists(ctx, "not-exist")
+	g.Expect(err).Should(gomega.Succeed())
+	g.Expect(exist).Should(gomega.BeFalse())
+
+	objs, err := (&PageIterator{iter: bucket.List(nil)}).Next(ctx, 1)
+	g.Expect(err).Should(gomega.Succeed())
+	g.Expect(objs).Should(gomega.HaveLen(1))
+	g.Expect(objs[0].Key).Should(gomega.Equal("backupmeta"))
+	g.Expect(objs[0].Size).Should(gomega.Equal(int64(10)))
+	listQuery := requests[len(requests)-1].URL.Query()
+	g.Expect(listQuery.Get("prefix")).Should(gomega.Equal("prefix/"))
+
+	// the requests are signed with the account key if there is no SAS token
+	token = ""
+	b.accountKey = []byte("key")
+	_, err = bucket.ReadAll(ctx, "backupmeta")
+	g.Expect(err).Should(gomega.Succeed())
+	auth := requests[len(requests)-1].Header.Get("Authorization")
+	g.Expect(auth).Should(gomega.HavePrefix("SharedKey account:"))
+	_, err = base64.StdEncoding.DecodeString(auth[len("SharedKey account:"):])
+	g.Expect(err).Should(gomega.Succeed())
+}
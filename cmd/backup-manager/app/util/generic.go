@@ -22,11 +22,19 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path"
+	"strconv"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/constants"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
 )
 
 // GenericOptions contains the generic input arguments to the backup/restore command
@@ -41,6 +49,8 @@ type GenericOptions struct {
 	Password     string
 	User         string
 	TiKVVersion  string
+	// PDPort is the client port of PD of the cluster BR accesses
+	PDPort int32
 }
 
 func (bo *GenericOptions) String() string {
@@ -94,3 +104,90 @@ func (bo *GenericOptions) SetTikvGCLifeTime(ctx context.Context, db *sql.DB, gcT
 	}
 	return nil
 }
+
+// GetSnapshotTS returns the current ts of the cluster, which can be used as the snapshot of dumpling
+func (bo *GenericOptions) GetSnapshotTS(ctx context.Context, db *sql.DB) (string, error) {
+	var file, position, doDB, ignoreDB, gtidSet []byte
+	sql := "SHOW MASTER STATUS"
+	row := db.QueryRowContext(ctx, sql)
+	err := row.Scan(&file, &position, &doDB, &ignoreDB, &gtidSet)
+	if err != nil {
+		return "", fmt.Errorf("query cluster %s snapshot ts failed, sql: %s, err: %v", bo, sql, err)
+	}
+	if _, err := strconv.ParseUint(string(position), 10, 64); err != nil {
+		return "", fmt.Errorf("parse cluster %s snapshot ts %q failed, err: %v", bo, position, err)
+	}
+	return string(position), nil
+}
+
+// GetTables returns the names of all the tables in the cluster sorted by the schema and the table,
+// the names are quoted as the table filter of dumpling
+func (bo *GenericOptions) GetTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	sql := "SELECT TABLE_SCHEMA, TABLE_NAME FROM INFORMATION_SCHEMA.TABLES ORDER BY TABLE_SCHEMA, TABLE_NAME"
+	rows, err := db.QueryContext(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("query cluster %s tables failed, sql: %s, err: %v", bo, sql, err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var schema, table string
+		if err := rows.Scan(&schema, &table); err != nil {
+			return nil, fmt.Errorf("scan cluster %s tables failed, err: %v", bo, err)
+		}
+		tables = append(tables, TableFilterName(schema, table))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query cluster %s tables failed, sql: %s, err: %v", bo, sql, err)
+	}
+	return tables, nil
+}
+
+// GetOriginalTikvGCLifeTime returns the original tikv_gc_life_time of the cluster, which differs from
+// the current one if it has been adjusted by an interrupted run of the backup or other running backups
+func (bo *GenericOptions) GetOriginalTikvGCLifeTime(backup *v1alpha1.Backup, backupLister listers.BackupLister, current string, currentDuration time.Duration) (string, time.Duration, error) {
+	backups, err := backupLister.Backups(backup.Namespace).List(labels.Everything())
+	if err != nil {
+		return "", 0, err
+	}
+	original, ok := backuputil.GetOriginalTikvGCLifeTime(backup, backups)
+	if !ok {
+		return current, currentDuration, nil
+	}
+	klog.Infof("cluster %s %s has been adjusted by backups, the original one is %s", bo, constants.TikvGCVariable, original)
+	originalDuration, err := time.ParseDuration(original)
+	if err != nil {
+		return "", 0, err
+	}
+	return original, originalDuration, nil
+}
+
+// ResetTikvGCLifeTime restores the original tikv_gc_life_time, or hands it over to
+// other running backups of the same cluster which still need the adjusted one
+func (bo *GenericOptions) ResetTikvGCLifeTime(ctx context.Context, db *sql.DB, backup *v1alpha1.Backup, backupLister listers.BackupLister,
+	statusUpdater controller.BackupConditionUpdaterInterface, original, adjusted string) error {
+	backups, err := backupLister.Backups(backup.Namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	state := v1alpha1.TikvGCLifeTimeHandedOver
+	if backuputil.IsTikvGCLifeTimeAdjustedByOthers(backup, backups) {
+		klog.Infof("cluster %s %s is still adjusted by other backups, hand over the restoration", bo, constants.TikvGCVariable)
+	} else {
+		if err := bo.SetTikvGCLifeTime(ctx, db, original); err != nil {
+			return err
+		}
+		state = v1alpha1.TikvGCLifeTimeRestored
+		klog.Infof("reset cluster %s %s to %s success", bo, constants.TikvGCVariable, original)
+	}
+	return statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupRunning,
+		Status: corev1.ConditionTrue,
+	}, &controller.BackupUpdateStatus{
+		TikvGCLifeTime: &v1alpha1.TikvGCLifeTimeStatus{
+			Original: original,
+			Adjusted: adjusted,
+			State:    state,
+		},
+	})
+}
@@ -64,6 +64,12 @@ type gcsConfig struct {
 	prefix       string
 }
 
+type azblobConfig struct {
+	container  string
+	accessTier string
+	prefix     string
+}
+
 type localConfig struct {
 	mountPath string
 	prefix    string
@@ -73,12 +79,13 @@ type localConfig struct {
 type StorageBackend struct {
 	*blob.Bucket
 
-	s3    *s3Config
-	gcs   *gcsConfig
-	local *localConfig
+	s3     *s3Config
+	gcs    *gcsConfig
+	azblob *azblobConfig
+	local  *localConfig
 }
 
-// NewStorageBackend creates new storage backend, now supports S3/GCS/Local
+// NewStorageBackend creates new storage backend, now supports S3/GCS/Azblob/Local
 func NewStorageBackend(provider v1alpha1.StorageProvider) (*StorageBackend, error) {
 	var bucket *blob.Bucket
 	var err error
@@ -93,6 +100,9 @@ func NewStorageBackend(provider v1alpha1.StorageProvider) (*StorageBackend, erro
 	case v1alpha1.BackupStorageTypeGcs:
 		b.gcs = makeGcsConfig(provider.Gcs, true)
 		bucket, err = newGcsStorage(b.gcs)
+	case v1alpha1.BackupStorageTypeAzblob:
+		b.azblob = makeAzblobConfig(provider.Azblob)
+		bucket, err = newAzblobStorage(b.azblob)
 	case v1alpha1.BackupStorageTypeLocal:
 		b.local = makeLocalConfig(provider.Local)
 		bucket, err = newLocalStorage(b.local)
@@ -113,6 +123,8 @@ func (b *StorageBackend) StorageType() v1alpha1.BackupStorageType {
 		return v1alpha1.BackupStorageTypeS3
 	} else if b.gcs != nil {
 		return v1alpha1.BackupStorageTypeGcs
+	} else if b.azblob != nil {
+		return v1alpha1.BackupStorageTypeAzblob
 	} else if b.local != nil {
 		return v1alpha1.BackupStorageTypeLocal
 	}
@@ -145,12 +157,14 @@ func (b *StorageBackend) AsGCS() (*storage.Client, bool) {
 
 // GetBucket return bucket name
 //
-// If provider is S3/GCS, return bucket. Otherwise return empty string
+// If provider is S3/GCS, return bucket. If provider is Azblob, return container. Otherwise return empty string
 func (b *StorageBackend) GetBucket() string {
 	if b.s3 != nil {
 		return b.s3.bucket
 	} else if b.gcs != nil {
 		return b.gcs.bucket
+	} else if b.azblob != nil {
+		return b.azblob.container
 	}
 
 	return ""
@@ -162,6 +176,8 @@ func (b *StorageBackend) GetPrefix() string {
 		return b.s3.prefix
 	} else if b.gcs != nil {
 		return b.gcs.prefix
+	} else if b.azblob != nil {
+		return b.azblob.prefix
 	} else if b.local != nil {
 		return b.local.prefix
 	}
@@ -302,6 +318,10 @@ func genStorageArgs(provider v1alpha1.StorageProvider) ([]string, error) {
 		qs := makeGcsConfig(provider.Gcs, false)
 		s := newGcsStorageOption(qs)
 		return s, nil
+	case v1alpha1.BackupStorageTypeAzblob:
+		qs := makeAzblobConfig(provider.Azblob)
+		s := newAzblobStorageOption(qs)
+		return s, nil
 	case v1alpha1.BackupStorageTypeLocal:
 		localConfig := makeLocalConfig(provider.Local)
 		cmdOpts, err := newLocalStorageOption(localConfig)
@@ -314,6 +334,15 @@ func genStorageArgs(provider v1alpha1.StorageProvider) ([]string, error) {
 	}
 }
 
+// genStorageURL returns the url of the storage used by br, such as `s3://bucket/prefix`
+func genStorageURL(provider v1alpha1.StorageProvider) (string, error) {
+	args, err := genStorageArgs(provider)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(args[0], "--storage="), nil
+}
+
 // newLocalStorageOption constructs `--storage local://$PATH` arg for br
 func newLocalStorageOption(conf *localConfig) ([]string, error) {
 	return []string{fmt.Sprintf("--storage=local://%s", path.Join(conf.mountPath, conf.prefix))}, nil
@@ -419,6 +448,17 @@ func newGcsStorageOption(conf *gcsConfig) []string {
 	return gcsoptions
 }
 
+// newAzblobStorageOption constructs the arg for --storage option and the remote path for br
+func newAzblobStorageOption(conf *azblobConfig) []string {
+	var azblobOptions []string
+	path := fmt.Sprintf("azure://%s/", path.Join(conf.container, conf.prefix))
+	azblobOptions = append(azblobOptions, fmt.Sprintf("--storage=%s", path))
+	if conf.accessTier != "" {
+		azblobOptions = append(azblobOptions, fmt.Sprintf("--azblob.access-tier=%s", conf.accessTier))
+	}
+	return azblobOptions
+}
+
 // makeS3Config constructs s3Config parameters
 func makeS3Config(s3 *v1alpha1.S3StorageProvider, fakeRegion bool) *s3Config {
 	conf := s3Config{}
@@ -468,6 +508,20 @@ func makeGcsConfig(gcs *v1alpha1.GcsStorageProvider, fakeRegion bool) *gcsConfig
 	return &conf
 }
 
+// makeAzblobConfig constructs azblobConfig parameters
+func makeAzblobConfig(azblob *v1alpha1.AzblobStorageProvider) *azblobConfig {
+	conf := azblobConfig{}
+
+	path := strings.Trim(azblob.Container, "/") + "/" + strings.Trim(azblob.Prefix, "/")
+	fields := strings.SplitN(path, "/", 2)
+
+	conf.container = fields[0]
+	conf.accessTier = azblob.AccessTier
+	conf.prefix = fields[1]
+
+	return &conf
+}
+
 func makeLocalConfig(local *v1alpha1.LocalStorageProvider) *localConfig {
 	return &localConfig{
 		mountPath: local.VolumeMount.MountPath,
@@ -269,6 +269,18 @@ func TestStorageBackendBasic(t *testing.T) {
 			expectedBucket:    "gcs-bucket",
 			expectedPrefix:    "gcs-prefix",
 		},
+		{
+			name: "basic azblob storage backend",
+			provider: v1alpha1.StorageProvider{
+				Azblob: &v1alpha1.AzblobStorageProvider{
+					Container: "azblob-container/a/b",
+					Prefix:    "azblob-prefix/c/d",
+				},
+			},
+			expectStorageType: v1alpha1.BackupStorageTypeAzblob,
+			expectedBucket:    "azblob-container",
+			expectedPrefix:    "a/b/azblob-prefix/c/d",
+		},
 		{
 			name: "basic local storage backend",
 			provider: v1alpha1.StorageProvider{
@@ -319,6 +331,10 @@ func TestStorageBackendBasic(t *testing.T) {
 			return nil, nil
 		})
 		defer gcsPatches.Reset()
+		azblobPatches := gomonkey.ApplyFunc(newAzblobStorage, func(conf *azblobConfig) (*blob.Bucket, error) {
+			return nil, nil
+		})
+		defer azblobPatches.Reset()
 		localPatches := gomonkey.ApplyFunc(newLocalStorage, func(conf *localConfig) (*blob.Bucket, error) {
 			return nil, nil
 		})
@@ -16,14 +16,19 @@ package util
 import (
 	"context"
 	"database/sql"
+	"encoding/binary"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/signal"
 	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/gogo/protobuf/proto"
@@ -32,6 +37,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/spf13/pflag"
+	"gocloud.dev/blob"
 	"k8s.io/klog/v2"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
@@ -53,6 +59,11 @@ var (
 		"--filter", "*.*",
 		"--filter", constants.DefaultTableFilter,
 	}
+	// brProgressRegex matches the progress logged by BR, such as
+	// `[progress] [step="Full Restore"] [progress=12.50%] [count="1 / 8"]`
+	brProgressRegex = regexp.MustCompile(`\[progress\] \[step="?([^"\]]+)"?\] \[progress=([0-9.]+)%\]`)
+	// brRemainingRegex matches the remaining time of the step in the progress logged by BR, such as `[remaining=1m10s]`
+	brRemainingRegex = regexp.MustCompile(`\[remaining=([0-9a-zµ.]+)\]`)
 )
 
 func validCmdFlagFunc(flag *pflag.Flag) {
@@ -103,6 +114,11 @@ func GetStoragePath(backup *v1alpha1.Backup) (string, error) {
 		bucket = backup.Spec.StorageProvider.Gcs.Bucket
 		url = fmt.Sprintf("gcs://%s/", path.Join(bucket, prefix))
 		return url, nil
+	case v1alpha1.BackupStorageTypeAzblob:
+		prefix = backup.Spec.StorageProvider.Azblob.Prefix
+		bucket = backup.Spec.StorageProvider.Azblob.Container
+		url = fmt.Sprintf("azure://%s/", path.Join(bucket, prefix))
+		return url, nil
 	case v1alpha1.BackupStorageTypeLocal:
 		prefix = backup.Spec.StorageProvider.Local.Prefix
 		mountPath := backup.Spec.StorageProvider.Local.VolumeMount.MountPath
@@ -193,6 +209,34 @@ func ConstructBRGlobalOptionsForBackup(backup *v1alpha1.Backup) ([]string, error
 	return args, nil
 }
 
+// ConstructBRGlobalOptionsForLogBackup constructs BR options for the command of the log backup, which is
+// one of start, truncate and stop. The task of the log backup is named after the Backup.
+func ConstructBRGlobalOptionsForLogBackup(backup *v1alpha1.Backup, command string, truncateUntil uint64) ([]string, error) {
+	spec := backup.Spec
+	if spec.BR == nil {
+		return nil, fmt.Errorf("no config for br in Backup %s/%s", backup.Namespace, backup.Name)
+	}
+	args := constructBRGlobalOptions(spec.BR)
+	switch command {
+	case "start", "truncate":
+		storageArgs, err := genStorageArgs(spec.StorageProvider)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, storageArgs...)
+		if command == "start" {
+			args = append(args, fmt.Sprintf("--task-name=%s", backup.Name))
+		} else {
+			args = append(args, fmt.Sprintf("--until=%d", truncateUntil), "--yes")
+		}
+	case "stop":
+		args = append(args, fmt.Sprintf("--task-name=%s", backup.Name))
+	default:
+		return nil, fmt.Errorf("unknown log backup command %s for Backup %s/%s", command, backup.Namespace, backup.Name)
+	}
+	return args, nil
+}
+
 // ConstructDumplingOptionsForBackup constructs dumpling options for backup
 func ConstructDumplingOptionsForBackup(backup *v1alpha1.Backup) []string {
 	var args []string
@@ -224,6 +268,29 @@ func ConstructDumplingOptionsForBackup(backup *v1alpha1.Backup) []string {
 	return args
 }
 
+// TableFilterName quotes the schema and the table as a table filter matching the table exactly
+func TableFilterName(schema, table string) string {
+	quote := func(name string) string {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return quote(schema) + "." + quote(table)
+}
+
+// ConstructDumplingShardFilters constructs dumpling filters excluding the tables out of the range of the shard,
+// the tables sorted by name are split into count ranges evenly. The filters must be placed after other
+// filters, because the last matched filter decides whether a table is exported.
+func ConstructDumplingShardFilters(tables []string, shard, count int32) []string {
+	var args []string
+	start := len(tables) * int(shard) / int(count)
+	end := len(tables) * int(shard+1) / int(count)
+	for i, table := range tables {
+		if i < start || i >= end {
+			args = append(args, "--filter", "!"+table)
+		}
+	}
+	return args
+}
+
 // ConstructBRGlobalOptionsForRestore constructs BR global options for restore.
 func ConstructBRGlobalOptionsForRestore(restore *v1alpha1.Restore) ([]string, error) {
 	var args []string
@@ -262,6 +329,34 @@ func ConstructBRGlobalOptionsForRestore(restore *v1alpha1.Restore) ([]string, er
 	return args, nil
 }
 
+// ConstructBRGlobalOptionsForPiTRRestore constructs BR global options for the point-in-time restore,
+// the storage of the log backup is passed by `--storage` and the storage of the restore,
+// which holds the snapshot backup, is passed by `--full-backup-storage`
+func ConstructBRGlobalOptionsForPiTRRestore(restore *v1alpha1.Restore, logBackup *v1alpha1.Backup, restoredTs uint64) ([]string, error) {
+	var args []string
+	config := restore.Spec
+	if config.BR == nil {
+		return nil, fmt.Errorf("no config for br in restore %s/%s", restore.Namespace, restore.Name)
+	}
+	args = append(args, constructBRGlobalOptions(config.BR)...)
+	storageArgs, err := genStorageArgs(logBackup.Spec.StorageProvider)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, storageArgs...)
+	fullBackupStorage, err := genStorageURL(restore.Spec.StorageProvider)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, fmt.Sprintf("--full-backup-storage=%s", fullBackupStorage))
+	args = append(args, fmt.Sprintf("--restored-ts=%d", restoredTs))
+
+	for _, tableFilter := range config.TableFilter {
+		args = append(args, "--filter", tableFilter)
+	}
+	return args, nil
+}
+
 // constructBRGlobalOptions constructs BR basic global options.
 func constructBRGlobalOptions(config *v1alpha1.BRConfig) []string {
 	var args []string
@@ -389,6 +484,91 @@ func GetCommitTsFromBRMetaData(ctx context.Context, provider v1alpha1.StoragePro
 	return backupMeta.EndVersion, nil
 }
 
+// GetLogBackupWindow gets the window of the log backup from the storage, the point-in-time restore
+// is able to restore the cluster to a ts within the window. The start of the window is the safe point
+// of the truncated log backup, which is 0 if the log backup is never truncated, and the end of the window
+// is the global checkpoint of the log backup, which is the max of the checkpoints uploaded by the stores.
+func GetLogBackupWindow(ctx context.Context, provider v1alpha1.StorageProvider) (uint64, uint64, error) {
+	s, err := NewStorageBackend(provider)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer s.Close()
+
+	var start uint64
+	exist, err := s.Exists(ctx, constants.LogBackupTruncateSafePointFile)
+	if err != nil {
+		return 0, 0, err
+	}
+	if exist {
+		data, err := s.ReadAll(ctx, constants.LogBackupTruncateSafePointFile)
+		if err != nil {
+			return 0, 0, err
+		}
+		start, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse %s failed, err: %v", constants.LogBackupTruncateSafePointFile, err)
+		}
+	}
+
+	var end uint64
+	iter := s.List(&blob.ListOptions{Prefix: constants.LogBackupGlobalCheckpointPath})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if obj.IsDir || !strings.HasSuffix(obj.Key, ".ts") {
+			continue
+		}
+		data, err := s.ReadAll(ctx, obj.Key)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(data) != 8 {
+			return 0, 0, fmt.Errorf("invalid global checkpoint %s of %d bytes", obj.Key, len(data))
+		}
+		if checkpoint := binary.LittleEndian.Uint64(data); checkpoint > end {
+			end = checkpoint
+		}
+	}
+	if end == 0 {
+		return 0, 0, fmt.Errorf("no global checkpoint found in %s", constants.LogBackupGlobalCheckpointPath)
+	}
+	return start, end, nil
+}
+
+// ParseBRProgress parses the step and the progress in percentage from a line of BR log,
+// it returns false if the line is not a progress log
+func ParseBRProgress(line string) (string, float64, bool) {
+	matches := brProgressRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return "", 0, false
+	}
+	progress, err := strconv.ParseFloat(matches[2], 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return matches[1], progress, true
+}
+
+// ParseBRRemaining parses the remaining time of the step from a progress log of BR,
+// it returns false if the remaining time is unknown, e.g. `[remaining=?]` at the beginning of the step
+func ParseBRRemaining(line string) (time.Duration, bool) {
+	matches := brRemainingRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return 0, false
+	}
+	remaining, err := time.ParseDuration(matches[1])
+	if err != nil {
+		return 0, false
+	}
+	return remaining, true
+}
+
 // ConstructRcloneArgs constructs the rclone args
 func ConstructRcloneArgs(conf string, opts []string, command, source, dest string, verboseLog bool) []string {
 	var args []string
@@ -14,11 +14,15 @@
 package util
 
 import (
+	"context"
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	appconstant "github.com/pingcap/tidb-operator/cmd/backup-manager/app/constants"
@@ -128,6 +132,42 @@ func TestConstructDumplingOptionsForBackup(t *testing.T) {
 	}
 }
 
+func TestConstructDumplingShardFilters(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(TableFilterName("db", "t`1")).To(Equal("`db`.`t``1`"))
+
+	tables := []string{"`db`.`t1`", "`db`.`t2`", "`db`.`t3`", "`db`.`t4`", "`db`.`t5`"}
+	g.Expect(ConstructDumplingShardFilters(tables, 0, 2)).To(Equal([]string{
+		"--filter", "!`db`.`t3`", "--filter", "!`db`.`t4`", "--filter", "!`db`.`t5`",
+	}))
+	g.Expect(ConstructDumplingShardFilters(tables, 1, 2)).To(Equal([]string{
+		"--filter", "!`db`.`t1`", "--filter", "!`db`.`t2`",
+	}))
+
+	// every table is exported by exactly one shard
+	exported := map[string]int{}
+	for shard := int32(0); shard < 3; shard++ {
+		excluded := map[string]bool{}
+		args := ConstructDumplingShardFilters(tables, shard, 3)
+		for i := 1; i < len(args); i += 2 {
+			excluded[strings.TrimPrefix(args[i], "!")] = true
+		}
+		for _, table := range tables {
+			if !excluded[table] {
+				exported[table]++
+			}
+		}
+	}
+	for _, table := range tables {
+		g.Expect(exported[table]).To(Equal(1), table)
+	}
+
+	// the shards more than the tables export nothing
+	g.Expect(ConstructDumplingShardFilters(tables[:1], 0, 2)).To(Equal([]string{"--filter", "!`db`.`t1`"}))
+	g.Expect(ConstructDumplingShardFilters(tables[:1], 1, 2)).To(BeEmpty())
+}
+
 func TestConstructBRGlobalOptionsForBackup(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -205,6 +245,35 @@ func TestConstructBRGlobalOptionsForBackup(t *testing.T) {
 	}
 }
 
+func TestConstructBRGlobalOptionsForLogBackup(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	backup := newBackup()
+	backup.Spec.Mode = v1alpha1.BackupModeLog
+	backup.Spec.BR = &v1alpha1.BRConfig{Cluster: "cluster-1", LogLevel: "info"}
+
+	args, err := ConstructBRGlobalOptionsForLogBackup(backup, "start", 0)
+	g.Expect(err).To(Succeed())
+	g.Expect(args).To(Equal([]string{
+		"--log-level=info",
+		"--storage=s3://test1-demo1",
+		"--s3.provider=ceph",
+		"--s3.endpoint=http://10.0.0.1",
+		"--task-name=" + backup.Name,
+	}))
+
+	args, err = ConstructBRGlobalOptionsForLogBackup(backup, "truncate", 434319917412892673)
+	g.Expect(err).To(Succeed())
+	g.Expect(args[len(args)-2:]).To(Equal([]string{"--until=434319917412892673", "--yes"}))
+
+	args, err = ConstructBRGlobalOptionsForLogBackup(backup, "stop", 0)
+	g.Expect(err).To(Succeed())
+	g.Expect(args).To(Equal([]string{"--log-level=info", "--task-name=" + backup.Name}))
+
+	_, err = ConstructBRGlobalOptionsForLogBackup(backup, "pause", 0)
+	g.Expect(err).To(HaveOccurred())
+}
+
 func TestGetRemotePath(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -244,6 +313,20 @@ func TestGetRemotePath(t *testing.T) {
 			},
 			expect: "gcs://test1-demo1/",
 		},
+		{
+			name: "normal azblob",
+			backup: &v1alpha1.Backup{
+				Spec: v1alpha1.BackupSpec{
+					StorageProvider: v1alpha1.StorageProvider{
+						Azblob: &v1alpha1.AzblobStorageProvider{
+							Container: "test1-demo1",
+							Prefix:    "prefix",
+						},
+					},
+				},
+			},
+			expect: "azure://test1-demo1/prefix/",
+		},
 		{
 			name: "unknow storage type",
 			backup: &v1alpha1.Backup{
@@ -385,6 +468,111 @@ func TestConstructBRGlobalOptionsForRestore(t *testing.T) {
 	}
 }
 
+func TestConstructBRGlobalOptionsForPiTRRestore(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	restore := newRestore()
+	restore.Spec.BR = &v1alpha1.BRConfig{Cluster: "cluster-1", ClusterNamespace: "default"}
+	restore.Spec.Mode = v1alpha1.RestoreModePiTR
+	logBackup := &v1alpha1.Backup{
+		Spec: v1alpha1.BackupSpec{
+			StorageProvider: v1alpha1.StorageProvider{
+				S3: &v1alpha1.S3StorageProvider{
+					Provider: v1alpha1.S3StorageProviderTypeCeph,
+					Endpoint: "http://10.0.0.1",
+					Bucket:   "test1-log",
+					Prefix:   "log-backup",
+				},
+			},
+		},
+	}
+
+	args, err := ConstructBRGlobalOptionsForPiTRRestore(restore, logBackup, 434319917412892673)
+	g.Expect(err).To(Succeed())
+	g.Expect(args).To(Equal([]string{
+		"--storage=s3://test1-log/log-backup",
+		"--s3.provider=ceph",
+		"--s3.endpoint=http://10.0.0.1",
+		"--full-backup-storage=s3://test1-demo1",
+		"--restored-ts=434319917412892673",
+	}))
+
+	restore.Spec.TableFilter = []string{"db.*"}
+	args, err = ConstructBRGlobalOptionsForPiTRRestore(restore, logBackup, 434319917412892673)
+	g.Expect(err).To(Succeed())
+	g.Expect(args[len(args)-2:]).To(Equal([]string{"--filter", "db.*"}))
+}
+
+func TestGetLogBackupWindow(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tmpdir, err := ioutil.TempDir("", "test-get-log-backup-window")
+	g.Expect(err).To(Succeed())
+	defer os.RemoveAll(tmpdir)
+
+	provider := v1alpha1.StorageProvider{
+		Local: &v1alpha1.LocalStorageProvider{
+			VolumeMount: corev1.VolumeMount{MountPath: tmpdir},
+			Prefix:      "log-backup",
+		},
+	}
+	checkpointDir := filepath.Join(tmpdir, "log-backup", appconstant.LogBackupGlobalCheckpointPath)
+	g.Expect(os.MkdirAll(checkpointDir, 0755)).To(Succeed())
+
+	// no global checkpoint is uploaded
+	_, _, err = GetLogBackupWindow(context.TODO(), provider)
+	g.Expect(err).To(HaveOccurred())
+
+	writeCheckpoint := func(name string, ts uint64) {
+		data := make([]byte, 8)
+		binary.LittleEndian.PutUint64(data, ts)
+		g.Expect(ioutil.WriteFile(filepath.Join(checkpointDir, name), data, 0644)).To(Succeed())
+	}
+	writeCheckpoint("1.ts", 434319917412892673)
+	writeCheckpoint("2.ts", 434319917412892999)
+	start, end, err := GetLogBackupWindow(context.TODO(), provider)
+	g.Expect(err).To(Succeed())
+	g.Expect(start).To(Equal(uint64(0)))
+	g.Expect(end).To(Equal(uint64(434319917412892999)))
+
+	safePointFile := filepath.Join(tmpdir, "log-backup", appconstant.LogBackupTruncateSafePointFile)
+	g.Expect(ioutil.WriteFile(safePointFile, []byte("434319917412800000"), 0644)).To(Succeed())
+	start, end, err = GetLogBackupWindow(context.TODO(), provider)
+	g.Expect(err).To(Succeed())
+	g.Expect(start).To(Equal(uint64(434319917412800000)))
+	g.Expect(end).To(Equal(uint64(434319917412892999)))
+}
+
+func TestParseBRProgress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	step, progress, ok := ParseBRProgress(`[2022/07/15 19:00:00.000 +08:00] [INFO] [progress.go:135] [progress] [step="Full Restore"] [progress=12.50%] [count="1 / 8"] [speed="? p/s"] [elapsed=10s] [remaining=1m10s]`)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(step).To(Equal("Full Restore"))
+	g.Expect(progress).To(Equal(12.5))
+
+	step, progress, ok = ParseBRProgress(`[2022/07/15 19:00:00.000 +08:00] [INFO] [progress.go:135] [progress] [step=Checksum] [progress=100.00%] [count="8 / 8"]`)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(step).To(Equal("Checksum"))
+	g.Expect(progress).To(Equal(float64(100)))
+
+	_, _, ok = ParseBRProgress(`[2022/07/15 19:00:00.000 +08:00] [INFO] [client.go:100] ["restore files"] [count=8]`)
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestParseBRRemaining(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	remaining, ok := ParseBRRemaining(`[2022/07/15 19:00:00.000 +08:00] [INFO] [progress.go:135] [progress] [step="Full Backup"] [progress=12.50%] [count="1 / 8"] [speed="? p/s"] [elapsed=10s] [remaining=1m10s]`)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(remaining).To(Equal(70 * time.Second))
+
+	_, ok = ParseBRRemaining(`[2022/07/15 19:00:00.000 +08:00] [INFO] [progress.go:135] [progress] [step="Full Backup"] [progress=0.00%] [count="0 / 8"] [speed="? p/s"] [elapsed=0s] [remaining=?]`)
+	g.Expect(ok).To(BeFalse())
+
+	_, ok = ParseBRRemaining(`[2022/07/15 19:00:00.000 +08:00] [INFO] [progress.go:135] [progress] [step=Checksum] [progress=100.00%] [count="8 / 8"]`)
+	g.Expect(ok).To(BeFalse())
+}
+
 func TestGetCommitTsFromMetadata(t *testing.T) {
 	g := NewGomegaWithT(t)
 	tmpdir, err := ioutil.TempDir("", "test-get-commitTs-metadata")
@@ -31,7 +31,10 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller/backup"
 	"github.com/pingcap/tidb-operator/pkg/controller/backupschedule"
 	"github.com/pingcap/tidb-operator/pkg/controller/dmcluster"
+	"github.com/pingcap/tidb-operator/pkg/controller/dmtask"
+	"github.com/pingcap/tidb-operator/pkg/controller/opscommand"
 	"github.com/pingcap/tidb-operator/pkg/controller/periodicity"
+	"github.com/pingcap/tidb-operator/pkg/controller/podlabel"
 	"github.com/pingcap/tidb-operator/pkg/controller/restore"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbcluster"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbinitializer"
@@ -39,6 +42,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbngmonitoring"
 	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/pingcap/tidb-operator/pkg/overview"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
 	"github.com/pingcap/tidb-operator/pkg/upgrader"
 	"github.com/pingcap/tidb-operator/pkg/version"
@@ -103,6 +107,15 @@ func main() {
 		klog.Fatalf("failed to get config: %v", err)
 	}
 
+	// the requests to the Kubernetes API server are limited by the token buckets of the groups of controllers, so
+	// that a large number of backups or StatefulSet updates can't starve the reconciliation of the other resources
+	rawCfg := cfg
+	defaultBucket := controller.NewKubeClientBucket("default", float32(cliCfg.KubeClientQPS), cliCfg.KubeClientBurst)
+	statefulSetBucket := controller.NewKubeClientBucket("statefulset", float32(cliCfg.StatefulSetKubeClientQPS), cliCfg.StatefulSetKubeClientBurst)
+	backupBucket := controller.NewKubeClientBucket("backup", float32(cliCfg.BackupKubeClientQPS), cliCfg.BackupKubeClientBurst)
+	// the clients shared by the informers, the upgrader and the leader election
+	cfg = defaultBucket.Config(rawCfg, "shared")
+
 	cli, err := versioned.NewForConfig(cfg)
 	if err != nil {
 		klog.Fatalf("failed to create Clientset: %v", err)
@@ -140,6 +153,32 @@ func main() {
 	if err != nil {
 		klog.Fatalf("failed to create Dependencies: %s", err)
 	}
+	// newControllerDeps returns the Dependencies of a controller with its own clients, whose requests are limited
+	// by the bucket of its group and recorded in metrics with the name of the controller
+	newControllerDeps := func(bucket *controller.KubeClientBucket, name string) *controller.Dependencies {
+		cfg := bucket.Config(rawCfg, name)
+		cli, err := versioned.NewForConfig(cfg)
+		if err != nil {
+			klog.Fatalf("failed to create Clientset for controller %s: %v", name, err)
+		}
+		var kubeCli kubernetes.Interface
+		kubeCli, err = kubernetes.NewForConfig(cfg)
+		if err != nil {
+			klog.Fatalf("failed to get kubernetes Clientset for controller %s: %v", name, err)
+		}
+		if features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) {
+			asCli, err := asclientset.NewForConfig(cfg)
+			if err != nil {
+				klog.Fatalf("failed to get advanced-statefulset Clientset for controller %s: %v", name, err)
+			}
+			kubeCli = helper.NewHijackClient(kubeCli, asCli)
+		}
+		genericCli, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+		if err != nil {
+			klog.Fatalf("failed to get the generic kube-apiserver client for controller %s: %v", name, err)
+		}
+		return deps.WithClients(cli, kubeCli, genericCli)
+	}
 
 	onStarted := func(ctx context.Context) {
 		// Upgrade before running any controller logic. If it fails, we wait
@@ -159,21 +198,24 @@ func main() {
 
 		// Initialize all controllers
 		controllers := []Controller{
-			tidbcluster.NewController(deps),
-			tidbcluster.NewPodController(deps),
-			dmcluster.NewController(deps),
-			backup.NewController(deps),
-			restore.NewController(deps),
-			backupschedule.NewController(deps),
-			tidbinitializer.NewController(deps),
-			tidbmonitor.NewController(deps),
-			tidbngmonitoring.NewController(deps),
+			tidbcluster.NewController(newControllerDeps(statefulSetBucket, "tidbcluster")),
+			tidbcluster.NewPodController(newControllerDeps(defaultBucket, "tidbcluster-pod")),
+			podlabel.NewController(newControllerDeps(defaultBucket, "podlabel")),
+			dmcluster.NewController(newControllerDeps(statefulSetBucket, "dmcluster")),
+			backup.NewController(newControllerDeps(backupBucket, "backup")),
+			restore.NewController(newControllerDeps(backupBucket, "restore")),
+			backupschedule.NewController(newControllerDeps(backupBucket, "backupschedule")),
+			tidbinitializer.NewController(newControllerDeps(defaultBucket, "tidbinitializer")),
+			tidbmonitor.NewController(newControllerDeps(statefulSetBucket, "tidbmonitor")),
+			tidbngmonitoring.NewController(newControllerDeps(statefulSetBucket, "tidbngmonitoring")),
+			opscommand.NewController(newControllerDeps(defaultBucket, "opscommand")),
+			dmtask.NewController(newControllerDeps(defaultBucket, "dmtask")),
 		}
 		if cliCfg.PodWebhookEnabled {
-			controllers = append(controllers, periodicity.NewController(deps))
+			controllers = append(controllers, periodicity.NewController(newControllerDeps(defaultBucket, "periodicity")))
 		}
 		if features.DefaultFeatureGate.Enabled(features.AutoScaling) {
-			controllers = append(controllers, autoscaler.NewController(deps))
+			controllers = append(controllers, autoscaler.NewController(newControllerDeps(defaultBucket, "tidbclusterautoscaler")))
 		}
 
 		// Start informer factories after all controllers are initialized.
@@ -198,7 +240,15 @@ func main() {
 			go wait.Forever(func() { c.Run(cliCfg.Workers, ctx.Done()) }, cliCfg.WaitDuration)
 		}
 	}
+	// ctx is canceled on shutdown, so that the leadership is released and a standby instance
+	// takes over immediately rather than waiting for the lease to expire
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	onStopped := func() {
+		if ctx.Err() != nil {
+			klog.Info("leader election released")
+			return
+		}
 		klog.Fatal("leader election lost")
 	}
 
@@ -206,31 +256,39 @@ func main() {
 	if helmRelease != "" {
 		endPointsName += "-" + helmRelease
 	}
+	switch cliCfg.LeaderElectionResourceLock {
+	case resourcelock.LeasesResourceLock, resourcelock.EndpointsLeasesResourceLock:
+	default:
+		klog.Fatalf("unsupported leader election resource lock %q", cliCfg.LeaderElectionResourceLock)
+	}
+	lock, err := resourcelock.New(cliCfg.LeaderElectionResourceLock, ns, endPointsName, kubeCli.CoreV1(), kubeCli.CoordinationV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      hostName,
+			EventRecorder: &record.FakeRecorder{},
+		})
+	if err != nil {
+		klog.Fatalf("failed to create the leader election lock: %v", err)
+	}
 	// leader election for multiple tidb-controller-manager instances
-	go wait.Forever(func() {
-		leaderelection.RunOrDie(context.TODO(), leaderelection.LeaderElectionConfig{
-			Lock: &resourcelock.EndpointsLock{
-				EndpointsMeta: metav1.ObjectMeta{
-					Namespace: ns,
-					Name:      endPointsName,
-				},
-				Client: kubeCli.CoreV1(),
-				LockConfig: resourcelock.ResourceLockConfig{
-					Identity:      hostName,
-					EventRecorder: &record.FakeRecorder{},
+	leaderElectionDone := make(chan struct{})
+	go func() {
+		defer close(leaderElectionDone)
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+				Lock:            lock,
+				LeaseDuration:   cliCfg.LeaseDuration,
+				RenewDeadline:   cliCfg.RenewDeadline,
+				RetryPeriod:     cliCfg.RetryPeriod,
+				ReleaseOnCancel: true,
+				Callbacks: leaderelection.LeaderCallbacks{
+					OnStartedLeading: onStarted,
+					OnStoppedLeading: onStopped,
 				},
-			},
-			LeaseDuration: cliCfg.LeaseDuration,
-			RenewDeadline: cliCfg.RenewDeadline,
-			RetryPeriod:   cliCfg.RetryPeriod,
-			Callbacks: leaderelection.LeaderCallbacks{
-				OnStartedLeading: onStarted,
-				OnStoppedLeading: onStopped,
-			},
-		})
-	}, cliCfg.WaitDuration)
+			})
+		}, cliCfg.WaitDuration)
+	}()
 
-	srv := createHTTPServer()
+	srv := createHTTPServer(cliCfg, deps)
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc,
 		syscall.SIGHUP,
@@ -242,6 +300,8 @@ func main() {
 	go func() {
 		sig := <-sc
 		klog.Infof("got signal %s to exit", sig)
+		cancel()
+		<-leaderElectionDone
 		if err2 := srv.Shutdown(context.Background()); err2 != nil {
 			klog.Fatal("fail to shutdown the HTTP server", err2)
 		}
@@ -253,10 +313,14 @@ func main() {
 	klog.Infof("tidb-controller-manager exited")
 }
 
-func createHTTPServer() *http.Server {
+func createHTTPServer(cliCfg *controller.CLIConfig, deps *controller.Dependencies) *http.Server {
 	serverMux := http.NewServeMux()
 	// HTTP path for prometheus.
 	serverMux.Handle("/metrics", promhttp.Handler())
+	// HTTP paths for the read-only overview of the managed clusters.
+	if cliCfg.OverviewEnabled {
+		overview.NewHandler(deps).Register(serverMux)
+	}
 
 	return &http.Server{
 		Addr:    ":6060",
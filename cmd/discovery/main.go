@@ -25,6 +25,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/discovery/server"
 	"github.com/pingcap/tidb-operator/pkg/dmapi"
@@ -90,6 +91,14 @@ func main() {
 	if tlsEnabled == strconv.FormatBool(true) {
 		tcTls = true
 	}
+	pdPort := int32(v1alpha1.DefaultPDClientPort)
+	if p := os.Getenv("TC_PD_CLIENT_PORT"); p != "" {
+		port, err := strconv.ParseInt(p, 10, 32)
+		if err != nil {
+			klog.Fatalf("ENV TC_PD_CLIENT_PORT is invalid: %v", err)
+		}
+		pdPort = int32(port)
+	}
 	// informers
 	options := []informers.SharedInformerOption{
 		informers.WithNamespace(os.Getenv("MY_POD_NAMESPACE")),
@@ -114,7 +123,7 @@ func main() {
 	go wait.Forever(func() {
 		addr := fmt.Sprintf("0.0.0.0:%d", proxyPort)
 		klog.Infof("starting TiDB Proxy server, listening on %s", addr)
-		proxyServer := server.NewProxyServer(tcName, tcTls)
+		proxyServer := server.NewProxyServer(tcName, pdPort, tcTls)
 		proxyServer.ListenAndServe(addr)
 	}, 5*time.Second)
 
@@ -38,8 +38,9 @@ location =  ${GCS_LOCATION}
 storage_class = ${GCS_STORAGE_CLASS:-"COLDLINE"}
 [azure]
 type = azureblob
-account = ${AZUREBLOB_ACCOUNT}
-key = ${AZUREBLOB_KEY}
+account = ${AZURE_STORAGE_ACCOUNT:-$AZUREBLOB_ACCOUNT}
+key = ${AZURE_STORAGE_KEY:-$AZUREBLOB_KEY}
+access_tier = ${AZURE_ACCESS_TIER}
 EOF
 
 if [[ -n "${GCS_SERVICE_ACCOUNT_JSON_KEY:-}" ]]; then
@@ -59,9 +59,22 @@ const (
 	// RestoreLabelKey is restore key
 	RestoreLabelKey string = "tidb.pingcap.com/restore"
 
+	// MaintenanceLabelKey is the key for the maintenance task
+	MaintenanceLabelKey string = "tidb.pingcap.com/maintenance-task"
+
+	// OpsCommandLabelKey is the key for the OpsCommand
+	OpsCommandLabelKey string = "tidb.pingcap.com/ops-command"
+
 	// BackupProtectionFinalizer is the name of finalizer on backups
 	BackupProtectionFinalizer string = "tidb.pingcap.com/backup-protection"
 
+	// DMTaskProtectionFinalizer is the name of finalizer on DMTasks to delete the tasks from the DMClusters
+	DMTaskProtectionFinalizer string = "tidb.pingcap.com/dm-task-protection"
+
+	// TidbMonitorRBACFinalizer is the name of finalizer on TidbMonitors to delete the Roles and RoleBindings
+	// created in the namespaces of the target clusters
+	TidbMonitorRBACFinalizer string = "tidb.pingcap.com/tidb-monitor-rbac"
+
 	// AutoScalingGroupLabelKey describes the autoscaling group of the TiDB
 	AutoScalingGroupLabelKey = "tidb.pingcap.com/autoscaling-group"
 	// AutoInstanceLabelKey is label key used in autoscaling, it represents the autoscaler name
@@ -89,6 +102,8 @@ const (
 	AnnTiKVPartition string = "tidb.pingcap.com/tikv-partition"
 	// AnnForceUpgradeKey is tc annotation key to indicate whether force upgrade should be done
 	AnnForceUpgradeKey = "tidb.pingcap.com/force-upgrade"
+	// AnnMaintenanceKey is tc annotation key to indicate the maintenance mode of the cluster
+	AnnMaintenanceKey = "tidb.pingcap.com/maintenance"
 	// AnnPDDeferDeleting is pd pod annotation key  in pod for defer for deleting pod
 	AnnPDDeferDeleting = "tidb.pingcap.com/pd-defer-deleting"
 	// AnnSysctlInit is pod annotation key to indicate whether configuring sysctls with init container
@@ -97,9 +112,56 @@ const (
 	AnnEvictLeaderBeginTime = "tidb.pingcap.com/evictLeaderBeginTime"
 	// AnnStsLastSyncTimestamp is sts annotation key to indicate the last timestamp the operator sync the sts
 	AnnStsLastSyncTimestamp = "tidb.pingcap.com/sync-timestamp"
+	// AnnStartScriptVersion is pod annotation key to indicate the version of the start script if it is not v1
+	AnnStartScriptVersion = "tidb.pingcap.com/start-script-version"
+	// AnnTLSSecretHash is pod annotation key to indicate the hash of the certs mounted by the components which
+	// can't reload the rotated certs, e.g. Pump, so that the Pods are rolling restarted once the certs are rotated
+	AnnTLSSecretHash = "tidb.pingcap.com/tls-secret-hash"
+	// AnnAdditionalVolumesHash is pod annotation key to indicate the hash of the ConfigMaps and Secrets referenced by
+	// the additional volumes of the Pods of the Jobs, e.g. the backup Jobs, when the Pods are created
+	AnnAdditionalVolumesHash = "tidb.pingcap.com/additional-volumes-hash"
+	// AnnCertRotationTime is pod annotation key to record the time the certs mounted by the Pod are rotated, updating
+	// the Pod makes kubelet refresh the mounted Secrets at once, so that the components reload the rotated certs
+	AnnCertRotationTime = "tidb.pingcap.com/cert-rotation-time"
+	// AnnStsSuspendedReplicas is sts annotation key to record the replicas of the StatefulSet before the cluster is
+	// suspended, which are restored once the cluster is resumed
+	AnnStsSuspendedReplicas = "tidb.pingcap.com/suspended-replicas"
+	// AnnTiKVPerPodConfigHash is pod annotation key to indicate the hash of the configuration patch of the TiKV Pod
+	// the Pod is started with, so that only the Pods whose patch is changed are restarted
+	AnnTiKVPerPodConfigHash = "tidb.pingcap.com/tikv-per-pod-config-hash"
+	// AnnCollectDiagnostics is tc annotation key to request a diagnostics bundle, a bundle is collected
+	// each time the value of the annotation is changed
+	AnnCollectDiagnostics = "tidb.pingcap.com/collect-diagnostics"
+	// AnnDebugArtifactsRestartCount is pod annotation key to record the restart count of the TiDB or TiKV container
+	// whose debug artifacts are reported, so that each crash of the container is only reported once
+	AnnDebugArtifactsRestartCount = "tidb.pingcap.com/debug-artifacts-restart-count"
+	// AnnTiCDCGracefulShutdownBeginTime is pod annotation key to indicate the begin time of resigning the ownership
+	// and draining the tables of the TiCDC capture before the Pod is upgraded
+	AnnTiCDCGracefulShutdownBeginTime = "tidb.pingcap.com/ticdc-graceful-shutdown-begin-time"
+	// AnnInternalTrafficPolicy is svc annotation key to record the internal traffic policy of the TiDB service,
+	// which is set by patch as it's not in the Service API the operator is built with
+	AnnInternalTrafficPolicy = "tidb.pingcap.com/internal-traffic-policy"
+	// AnnSkipConfigValidation is tc annotation key to skip the validation of the component configs by the admission
+	// webhook, e.g. for the config items of new versions which are not known by the webhook yet
+	AnnSkipConfigValidation = "tidb.pingcap.com/skip-config-validation"
+	// AnnConfigRollback is tc annotation key to roll back the config of a component to the one in the ConfigMap
+	// named by the value, the annotation is removed once the config in the spec is reverted
+	AnnConfigRollback = "tidb.pingcap.com/config-rollback"
+	// AnnTenant is namespace annotation key to assign the namespace to a tenant, the TidbClusters in the
+	// namespaces of a tenant are limited by the policy of the tenant configured for the operator
+	AnnTenant = "tidb.pingcap.com/tenant"
+	// AnnRepairVersionSkew is tc annotation key to approve the plan to repair the version skew of the cluster,
+	// the plan is executed if the value is the ID of the plan in status
+	AnnRepairVersionSkew = "tidb.pingcap.com/repair-version-skew"
+	// AnnTopologyAwareHints is svc annotation key to enable the topology aware hints of the service
+	AnnTopologyAwareHints = "service.kubernetes.io/topology-aware-hints"
 
 	// AnnForceUpgradeVal is tc annotation value to indicate whether force upgrade should be done
 	AnnForceUpgradeVal = "true"
+	// AnnMaintenanceNodeUpgradeVal is tc annotation value to indicate that the Kubernetes nodes are being upgraded
+	AnnMaintenanceNodeUpgradeVal = "node-upgrade"
+	// AnnTopologyAwareHintsVal is svc annotation value to enable the topology aware hints of the service
+	AnnTopologyAwareHintsVal = "auto"
 	// AnnSysctlInitVal is pod annotation value to indicate whether configuring sysctls with init container
 	AnnSysctlInitVal = "true"
 
@@ -125,14 +187,20 @@ const (
 	TiDBLabelVal string = "tidb"
 	// TiKVLabelVal is TiKV label value
 	TiKVLabelVal string = "tikv"
+	// TiKVColdLabelVal is the label value of the TiKV cold group
+	TiKVColdLabelVal string = "tikv-cold"
 	// TiFlashLabelVal is TiFlash label value
 	TiFlashLabelVal string = "tiflash"
 	// TiCDCLabelVal is TiCDC label value
 	TiCDCLabelVal string = "ticdc"
+	// TiProxyLabelVal is TiProxy label value
+	TiProxyLabelVal string = "tiproxy"
 	// PumpLabelVal is Pump label value
 	PumpLabelVal string = "pump"
 	// DiscoveryLabelVal is Discovery label value
 	DiscoveryLabelVal string = "discovery"
+	// ImporterLabelVal is the label value of the tikv-importer deployed by the tikv-importer chart
+	ImporterLabelVal string = "importer"
 	// TiDBMonitorVal is Monitor label value
 	TiDBMonitorVal string = "monitor"
 
@@ -142,10 +210,20 @@ const (
 	RestoreJobLabelVal string = "restore"
 	// BackupJobLabelVal is backup job label value
 	BackupJobLabelVal string = "backup"
+	// LogBackupJobLabelVal is the label value of the jobs to truncate and stop the log backup
+	LogBackupJobLabelVal string = "log-backup"
 	// BackupScheduleJobLabelVal is backup schedule job label value
 	BackupScheduleJobLabelVal string = "backup-schedule"
 	// InitJobLabelVal is TiDB initializer job label value
 	InitJobLabelVal string = "initializer"
+	// MaintenanceJobLabelVal is maintenance job label value
+	MaintenanceJobLabelVal string = "maintenance"
+	// DiagnosticsJobLabelVal is diagnostics job label value
+	DiagnosticsJobLabelVal string = "diagnostics"
+	// StorageProvisioningJobLabelVal is storage provisioning job label value
+	StorageProvisioningJobLabelVal string = "storage-provisioning"
+	// OpsCommandJobLabelVal is OpsCommand job label value
+	OpsCommandJobLabelVal string = "ops-command"
 	// TiDBOperator is ManagedByLabelKey label value
 	TiDBOperator string = "tidb-operator"
 
@@ -200,6 +278,38 @@ func NewInitializer() Label {
 	}
 }
 
+// NewMaintenance initialize a new Label for Jobs of maintenance tasks
+func NewMaintenance() Label {
+	return Label{
+		ComponentLabelKey: MaintenanceJobLabelVal,
+		ManagedByLabelKey: TiDBOperator,
+	}
+}
+
+// NewDiagnostics initialize a new Label for the Jobs and Secrets of diagnostics bundles
+func NewDiagnostics() Label {
+	return Label{
+		ComponentLabelKey: DiagnosticsJobLabelVal,
+		ManagedByLabelKey: TiDBOperator,
+	}
+}
+
+// NewStorageProvisioning initialize a new Label for the Jobs of the storage provisioning hooks
+func NewStorageProvisioning() Label {
+	return Label{
+		ComponentLabelKey: StorageProvisioningJobLabelVal,
+		ManagedByLabelKey: TiDBOperator,
+	}
+}
+
+// NewOpsCommand initialize a new Label for Jobs of OpsCommands
+func NewOpsCommand() Label {
+	return Label{
+		ComponentLabelKey: OpsCommandJobLabelVal,
+		ManagedByLabelKey: TiDBOperator,
+	}
+}
+
 // NewBackup initialize a new Label for Jobs of bakcup
 func NewBackup() Label {
 	return Label{
@@ -270,6 +380,12 @@ func (l Label) UsedByEndUser() Label {
 	return l
 }
 
+// UsedByPod adds used-by=pod label, for the services of single Pods
+func (l Label) UsedByPod() Label {
+	l[UsedByLabelKey] = "pod"
+	return l
+}
+
 // Namespace adds namespace kv pair to label
 func (l Label) Namespace(name string) Label {
 	l[NamespaceLabelKey] = name
@@ -309,11 +425,28 @@ func (l Label) BackupJob() Label {
 	return l.Component(BackupJobLabelVal)
 }
 
+// LogBackupJob assigns log-backup to component key in label
+func (l Label) LogBackupJob() Label {
+	return l.Component(LogBackupJobLabelVal)
+}
+
 // RestoreJob assigns restore to component key in label
 func (l Label) RestoreJob() Label {
 	return l.Component(RestoreJobLabelVal)
 }
 
+// Maintenance assigns specific value to maintenance key in label
+func (l Label) Maintenance(val string) Label {
+	l[MaintenanceLabelKey] = val
+	return l
+}
+
+// OpsCommand assigns specific value to OpsCommand key in label
+func (l Label) OpsCommand(val string) Label {
+	l[OpsCommandLabelKey] = val
+	return l
+}
+
 // Backup assigns specific value to backup key in label
 func (l Label) Backup(val string) Label {
 	l[BackupLabelKey] = val
@@ -427,6 +560,16 @@ func (l Label) IsTiKV() bool {
 	return l[ComponentLabelKey] == TiKVLabelVal
 }
 
+// TiKVCold assigns tikv-cold to component key in label
+func (l Label) TiKVCold() Label {
+	return l.Component(TiKVColdLabelVal)
+}
+
+// IsTiKVCold returns whether label is a component of the TiKV cold group
+func (l Label) IsTiKVCold() bool {
+	return l[ComponentLabelKey] == TiKVColdLabelVal
+}
+
 // TiFlash assigns tiflash to component key in label
 func (l Label) TiFlash() Label {
 	return l.Component(TiFlashLabelVal)
@@ -447,6 +590,16 @@ func (l Label) IsTiCDC() bool {
 	return l[ComponentLabelKey] == TiCDCLabelVal
 }
 
+// TiProxy assigns tiproxy to component key in label
+func (l Label) TiProxy() Label {
+	return l.Component(TiProxyLabelVal)
+}
+
+// IsTiProxy returns whether label is a TiProxy component
+func (l Label) IsTiProxy() bool {
+	return l[ComponentLabelKey] == TiProxyLabelVal
+}
+
 // Selector gets labels.Selector from label
 func (l Label) Selector() (labels.Selector, error) {
 	return metav1.LabelSelectorAsSelector(l.LabelSelector())
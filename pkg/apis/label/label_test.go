@@ -52,6 +52,17 @@ func TestLabelNamespace(t *testing.T) {
 	g.Expect(l[NamespaceLabelKey]).To(Equal("ns-1"))
 }
 
+func TestLabelMaintenance(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	l := NewMaintenance()
+	l.Maintenance("compact")
+	g.Expect(l[MaintenanceLabelKey]).To(Equal("compact"))
+	g.Expect(l[ComponentLabelKey]).To(Equal(MaintenanceJobLabelVal))
+	// the label of the maintenance Jobs must not be mistaken for the maintenance annotation of the TidbCluster
+	g.Expect(MaintenanceLabelKey).NotTo(Equal(AnnMaintenanceKey))
+}
+
 func TestLabelComponent(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -15,9 +15,11 @@ package v1alpha1
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -44,6 +46,36 @@ func (bk *Backup) GetBackupJobName() string {
 	return fmt.Sprintf("backup-%s", bk.GetName())
 }
 
+// GetLogTruncateJobName return the job name to truncate the log backup
+func (bk *Backup) GetLogTruncateJobName() string {
+	return fmt.Sprintf("%s-log-truncate", bk.GetBackupJobName())
+}
+
+// GetLogStopJobName return the job name to stop the log backup task
+func (bk *Backup) GetLogStopJobName() string {
+	return fmt.Sprintf("%s-log-stop", bk.GetBackupJobName())
+}
+
+// IsLogBackup returns whether the backup is a log backup
+func (bk *Backup) IsLogBackup() bool {
+	return bk.Spec.Mode == BackupModeLog
+}
+
+// IsVolumeSnapshotBackup returns whether the backup is taken by the CSI VolumeSnapshots
+func (bk *Backup) IsVolumeSnapshotBackup() bool {
+	return bk.Spec.Mode == BackupModeVolumeSnapshot
+}
+
+// GetVolumeSnapshotName returns the name of the VolumeSnapshot of the PVC taken by the backup
+func (bk *Backup) GetVolumeSnapshotName(pvcName string) string {
+	return fmt.Sprintf("%s-%s", bk.GetName(), pvcName)
+}
+
+// GetVolumeSnapshotMetaName returns the name of the ConfigMap storing the meta of the volume snapshots
+func (bk *Backup) GetVolumeSnapshotMetaName() string {
+	return fmt.Sprintf("%s-volume-snapshot-meta", bk.GetName())
+}
+
 // GetTidbEndpointHash return the hash string base on tidb cluster's host and port
 func (bk *Backup) GetTidbEndpointHash() string {
 	return HashContents([]byte(bk.Spec.From.GetTidbEndpoint()))
@@ -54,6 +86,29 @@ func (bk *Backup) GetBackupPVCName() string {
 	return fmt.Sprintf("backup-pvc-%s", bk.GetTidbEndpointHash())
 }
 
+// GetShardCount returns the number of the shards of the backup, only the backup with dumpling can be sharded
+func (bk *Backup) GetShardCount() int32 {
+	if bk.Spec.BR != nil || bk.Spec.Dumpling == nil || bk.Spec.Dumpling.ShardCount < 1 {
+		return 1
+	}
+	return bk.Spec.Dumpling.ShardCount
+}
+
+// IsSharded returns whether the backup is split into multiple Jobs
+func (bk *Backup) IsSharded() bool {
+	return bk.GetShardCount() > 1
+}
+
+// GetBackupShardJobName return the job name of the shard of the backup
+func (bk *Backup) GetBackupShardJobName(index int32) string {
+	return fmt.Sprintf("%s-shard-%d", bk.GetBackupJobName(), index)
+}
+
+// GetBackupShardPVCName return the pvc name of the shard of the backup
+func (bk *Backup) GetBackupShardPVCName(index int32) string {
+	return fmt.Sprintf("%s-shard-%d", bk.GetBackupPVCName(), index)
+}
+
 // GetInstanceName return the backup instance name
 func (bk *Backup) GetInstanceName() string {
 	if bk.Labels != nil {
@@ -127,6 +182,44 @@ func UpdateBackupCondition(status *BackupStatus, condition *BackupCondition) boo
 	return !isUpdate
 }
 
+// GetBackupShardStatus returns the status of the shard from the given BackupStatus
+func GetBackupShardStatus(status *BackupStatus, index int32) *BackupShardStatus {
+	for i := range status.Shards {
+		if status.Shards[i].Index == index {
+			return &status.Shards[i]
+		}
+	}
+	return nil
+}
+
+// UpdateBackupShardStatus updates the status of the shard in the given BackupStatus,
+// and returns true if the status of the shard is changed
+func UpdateBackupShardStatus(status *BackupStatus, shard *BackupShardStatus) bool {
+	if old := GetBackupShardStatus(status, shard.Index); old != nil {
+		if apiequality.Semantic.DeepEqual(old, shard) {
+			return false
+		}
+		*old = *shard
+		return true
+	}
+	status.Shards = append(status.Shards, *shard)
+	sort.Slice(status.Shards, func(i, j int) bool {
+		return status.Shards[i].Index < status.Shards[j].Index
+	})
+	return true
+}
+
+// IsBackupShardsComplete returns true if all the shards of a sharded Backup have successfully completed
+func IsBackupShardsComplete(backup *Backup) bool {
+	for i := int32(0); i < backup.GetShardCount(); i++ {
+		shard := GetBackupShardStatus(&backup.Status, i)
+		if shard == nil || shard.Phase != BackupComplete {
+			return false
+		}
+	}
+	return true
+}
+
 // IsBackupComplete returns true if a Backup has successfully completed
 func IsBackupComplete(backup *Backup) bool {
 	_, condition := GetBackupCondition(&backup.Status, BackupComplete)
@@ -163,6 +256,12 @@ func IsBackupPrepared(backup *Backup) bool {
 	return condition != nil && condition.Status == corev1.ConditionTrue
 }
 
+// IsLogBackupStopped returns true if the task of a log Backup has been stopped
+func IsLogBackupStopped(backup *Backup) bool {
+	_, condition := GetBackupCondition(&backup.Status, BackupLogStopped)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
 // IsBackupClean returns true if a Backup has been successfully cleaned up
 func IsBackupClean(backup *Backup) bool {
 	_, condition := GetBackupCondition(&backup.Status, BackupClean)
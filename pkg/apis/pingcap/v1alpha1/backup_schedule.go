@@ -21,3 +21,8 @@ import (
 func (bs *BackupSchedule) GetBackupCRDName(timestamp time.Time) string {
 	return fmt.Sprintf("%s-%s", bs.GetName(), timestamp.UTC().Format(BackupNameTimeFormat))
 }
+
+// GetLogBackupCRDName returns the name of the log backup created from the logBackupTemplate
+func (bs *BackupSchedule) GetLogBackupCRDName() string {
+	return fmt.Sprintf("%s-log", bs.GetName())
+}
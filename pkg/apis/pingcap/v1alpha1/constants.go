@@ -20,6 +20,25 @@ const (
 	// DefaultTidbPort is the default tidb cluster port for connecting
 	DefaultTidbPort = 4000
 
+	// DefaultTidbStatusPort is the default port of the tidb status API
+	DefaultTidbStatusPort = 10080
+
+	// DefaultPDClientPort is the default port of the pd client requests
+	DefaultPDClientPort = 2379
+
+	// DefaultTiKVPort is the default port of tikv
+	DefaultTiKVPort = 20160
+
+	// DefaultTiKVColdStoreLabelKey and DefaultTiKVColdStoreLabelValue are the default store label of the tikv cold group
+	DefaultTiKVColdStoreLabelKey   = "tier"
+	DefaultTiKVColdStoreLabelValue = "cold"
+
+	// DefaultDMMasterPort is the default port of the dm-master client requests
+	DefaultDMMasterPort = 8261
+
+	// DefaultDMMasterPeerPort is the default port of the dm-master peer communication
+	DefaultDMMasterPeerPort = 8291
+
 	// DefaultTidbUser is the default tidb user for login tidb cluster
 	DefaultTidbUser = "root"
 )
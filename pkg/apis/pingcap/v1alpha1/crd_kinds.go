@@ -57,6 +57,14 @@ const (
 	TiDBNGMonitoringKind    = "TidbNGMonitoring"
 	TiDBNGMonitoringKindKey = "tidbngmonitoring"
 
+	DMTaskName    = "dmtasks"
+	DMTaskKind    = "DMTask"
+	DMTaskKindKey = "dmtask"
+
+	OpsCommandName    = "opscommands"
+	OpsCommandKind    = "OpsCommand"
+	OpsCommandKindKey = "opscommand"
+
 	SpecPath = "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1."
 )
 
@@ -79,6 +87,8 @@ type CrdKinds struct {
 	TiDBInitializer       CrdKind
 	TidbClusterAutoScaler CrdKind
 	TiDBNGMonitoring      CrdKind
+	DMTask                CrdKind
+	OpsCommand            CrdKind
 }
 
 var DefaultCrdKinds = CrdKinds{
@@ -92,4 +102,6 @@ var DefaultCrdKinds = CrdKinds{
 	TiDBInitializer:       CrdKind{Plural: TiDBInitializerName, Kind: TiDBInitializerKind, ShortNames: []string{"ti"}, SpecName: SpecPath + TiDBInitializerKind},
 	TidbClusterAutoScaler: CrdKind{Plural: TidbClusterAutoScalerName, Kind: TidbClusterAutoScalerKind, ShortNames: []string{"ta"}, SpecName: SpecPath + TidbClusterAutoScalerKind},
 	TiDBNGMonitoring:      CrdKind{Plural: TiDBNGMonitoringName, Kind: TiDBNGMonitoringKind, ShortNames: []string{"tngm"}, SpecName: SpecPath + TiDBNGMonitoringKind},
+	DMTask:                CrdKind{Plural: DMTaskName, Kind: DMTaskKind, ShortNames: []string{"dmt"}, SpecName: SpecPath + DMTaskKind},
+	OpsCommand:            CrdKind{Plural: OpsCommandName, Kind: OpsCommandKind, ShortNames: []string{"opc"}, SpecName: SpecPath + OpsCommandKind},
 }
@@ -26,6 +26,7 @@ const (
 	defaultBinlogImage  = "pingcap/tidb-binlog"
 	defaultTiFlashImage = "pingcap/tiflash"
 	defaultTiCDCImage   = "pingcap/ticdc"
+	defaultTiProxyImage = "pingcap/tiproxy"
 )
 
 var (
@@ -52,6 +53,9 @@ func SetTidbClusterDefault(tc *v1alpha1.TidbCluster) {
 	if tc.Spec.TiCDC != nil {
 		setTiCDCSpecDefault(tc)
 	}
+	if tc.Spec.TiProxy != nil {
+		setTiProxySpecDefault(tc)
+	}
 }
 
 // setTidbClusterSpecDefault is only managed the property under Spec
@@ -147,3 +151,11 @@ func setTiCDCSpecDefault(tc *v1alpha1.TidbCluster) {
 		}
 	}
 }
+
+func setTiProxySpecDefault(tc *v1alpha1.TidbCluster) {
+	if len(tc.Spec.Version) > 0 || tc.Spec.TiProxy.Version != nil {
+		if tc.Spec.TiProxy.BaseImage == "" {
+			tc.Spec.TiProxy.BaseImage = defaultTiProxyImage
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -37,6 +38,58 @@ func (dc *DMCluster) Timezone() string {
 	return tz
 }
 
+func (dc *DMCluster) GetHelperSpec() HelperSpec {
+	if dc.Spec.Helper == nil {
+		return defaultHelperSpec
+	}
+	return *dc.Spec.Helper
+}
+
+// HelperImage returns the cluster-level helper image, use the HelperImage of
+// the component accessor to respect the component-level overrides
+func (dc *DMCluster) HelperImage() string {
+	image, digest := dc.helperImageAndDigest()
+	return ImageWithRegistryPrefix(dc.Spec.ClusterRegistryPrefix, ImageWithDigest(image, digest))
+}
+
+// helperImageAndDigest returns the cluster-level helper image without the registry
+// prefix and the digest it is pinned to
+func (dc *DMCluster) helperImageAndDigest() (string, string) {
+	helper := dc.GetHelperSpec()
+	digest := ""
+	if helper.Digest != nil {
+		digest = *helper.Digest
+	}
+	if helper.Image == nil {
+		return defaultHelperImage, digest
+	}
+	return *helper.Image, digest
+}
+
+func (dc *DMCluster) HelperImagePullPolicy() corev1.PullPolicy {
+	pp := dc.GetHelperSpec().ImagePullPolicy
+	if pp == nil {
+		return dc.Spec.ImagePullPolicy
+	}
+	return *pp
+}
+
+// MasterPort returns the port that dm-master serves the client requests on
+func (dc *DMCluster) MasterPort() int32 {
+	if dc.Spec.Master.Port != nil {
+		return *dc.Spec.Master.Port
+	}
+	return DefaultDMMasterPort
+}
+
+// MasterPeerPort returns the port that dm-master members communicate with each other on
+func (dc *DMCluster) MasterPeerPort() int32 {
+	if dc.Spec.Master.PeerPort != nil {
+		return *dc.Spec.Master.PeerPort
+	}
+	return DefaultDMMasterPeerPort
+}
+
 func (dc *DMCluster) IsPVReclaimEnabled() bool {
 	enabled := dc.Spec.EnablePVReclaim
 	if enabled == nil {
@@ -49,8 +102,25 @@ func (dc *DMCluster) IsTLSClusterEnabled() bool {
 	return dc.Spec.TLSCluster != nil && dc.Spec.TLSCluster.Enabled
 }
 
+// HeterogeneousWithoutLocalMaster returns whether the dm-workers join the dm-masters of another DMCluster,
+// no dm-master is deployed for the DMCluster then
+func (dc *DMCluster) HeterogeneousWithoutLocalMaster() bool {
+	return dc.Spec.Cluster != nil && len(dc.Spec.Cluster.Name) > 0
+}
+
+// MasterCluster returns the namespace and the name of the DMCluster that the dm-masters belong to
+func (dc *DMCluster) MasterCluster() (string, string) {
+	if !dc.HeterogeneousWithoutLocalMaster() {
+		return dc.Namespace, dc.Name
+	}
+	if len(dc.Spec.Cluster.Namespace) > 0 {
+		return dc.Spec.Cluster.Namespace, dc.Spec.Cluster.Name
+	}
+	return dc.Namespace, dc.Spec.Cluster.Name
+}
+
 func (dc *DMCluster) MasterAllMembersReady() bool {
-	if int(dc.MasterStsDesiredReplicas()) != len(dc.Status.Master.Members) {
+	if !dc.HeterogeneousWithoutLocalMaster() && int(dc.MasterStsDesiredReplicas()) != len(dc.Status.Master.Members) {
 		return false
 	}
 
@@ -141,6 +211,9 @@ func (dc *DMCluster) GetInstanceName() string {
 
 func (dc *DMCluster) MasterImage() string {
 	image := dc.Spec.Master.BaseImage
+	if archBaseImage := dc.BaseMasterSpec().ArchBaseImage(); archBaseImage != "" {
+		image = archBaseImage
+	}
 	version := dc.Spec.Master.Version
 	if version == nil {
 		version = &dc.Spec.Version
@@ -148,11 +221,14 @@ func (dc *DMCluster) MasterImage() string {
 	if *version != "" {
 		image = fmt.Sprintf("%s:%s", image, *version)
 	}
-	return image
+	return ImageWithRegistryPrefix(dc.Spec.ClusterRegistryPrefix, image)
 }
 
 func (dc *DMCluster) WorkerImage() string {
 	image := dc.Spec.Worker.BaseImage
+	if archBaseImage := dc.BaseWorkerSpec().ArchBaseImage(); archBaseImage != "" {
+		image = archBaseImage
+	}
 	version := dc.Spec.Worker.Version
 	if version == nil {
 		version = &dc.Spec.Version
@@ -160,7 +236,7 @@ func (dc *DMCluster) WorkerImage() string {
 	if *version != "" {
 		image = fmt.Sprintf("%s:%s", image, *version)
 	}
-	return image
+	return ImageWithRegistryPrefix(dc.Spec.ClusterRegistryPrefix, image)
 }
 
 func (dc *DMCluster) MasterVersion() string {
@@ -217,8 +293,19 @@ func (dc *DMCluster) WorkerAllPodsStarted() bool {
 }
 
 func (dc *DMCluster) MasterIsAvailable() bool {
-	lowerLimit := dc.Spec.Master.Replicas/2 + 1
-	if int32(len(dc.Status.Master.Members)) < lowerLimit {
+	if dc.HeterogeneousWithoutLocalMaster() {
+		// the dm-masters of the referenced DMCluster are available if most of them are healthy
+		var availableNum int
+		for _, masterMember := range dc.Status.Master.Members {
+			if masterMember.Health {
+				availableNum++
+			}
+		}
+		return availableNum > len(dc.Status.Master.Members)/2
+	}
+
+	lowerLimit := (dc.Spec.Master.Replicas+int32(len(dc.Status.Master.PeerMembers)))/2 + 1
+	if int32(len(dc.Status.Master.Members)+len(dc.Status.Master.PeerMembers)) < lowerLimit {
 		return false
 	}
 
@@ -229,11 +316,19 @@ func (dc *DMCluster) MasterIsAvailable() bool {
 		}
 	}
 
+	var peerAvailableNum int32
+	for _, masterMember := range dc.Status.Master.PeerMembers {
+		if masterMember.Health {
+			peerAvailableNum++
+		}
+	}
+
+	availableNum += peerAvailableNum
 	if availableNum < lowerLimit {
 		return false
 	}
 
-	if dc.Status.Master.StatefulSet == nil || dc.Status.Master.StatefulSet.ReadyReplicas < lowerLimit {
+	if dc.Status.Master.StatefulSet == nil || dc.Status.Master.StatefulSet.ReadyReplicas+peerAvailableNum < lowerLimit {
 		return false
 	}
 
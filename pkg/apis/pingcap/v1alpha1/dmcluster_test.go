@@ -91,6 +91,35 @@ func TestDMMasterIsAvailable(t *testing.T) {
 				g.Expect(b).To(BeTrue())
 			},
 		},
+		{
+			name: "dm-masters of the referenced cluster are available",
+			update: func(dc *DMCluster) {
+				dc.Spec.Master.Replicas = 0
+				dc.Spec.Cluster = &TidbClusterRef{Name: "main", Namespace: "dm"}
+				dc.Status.Master.Members = map[string]MasterMember{
+					"main-dm-master-0": {Name: "main-dm-master-0", Health: true},
+					"main-dm-master-1": {Name: "main-dm-master-1", Health: true},
+					"main-dm-master-2": {Name: "main-dm-master-2", Health: false},
+				}
+			},
+			expectFn: func(g *GomegaWithT, b bool) {
+				g.Expect(b).To(BeTrue())
+			},
+		},
+		{
+			name: "dm-masters of the referenced cluster are not available",
+			update: func(dc *DMCluster) {
+				dc.Spec.Master.Replicas = 0
+				dc.Spec.Cluster = &TidbClusterRef{Name: "main", Namespace: "dm"}
+				dc.Status.Master.Members = map[string]MasterMember{
+					"main-dm-master-0": {Name: "main-dm-master-0", Health: true},
+					"main-dm-master-1": {Name: "main-dm-master-1", Health: false},
+				}
+			},
+			expectFn: func(g *GomegaWithT, b bool) {
+				g.Expect(b).To(BeFalse())
+			},
+		},
 	}
 
 	for i := range tests {
@@ -249,6 +278,36 @@ func TestDMComponentAccessor(t *testing.T) {
 				g.Expect(a.Tolerations()).Should(ConsistOf(toleration2))
 			},
 		},
+		{
+			name:      "default helper and statefulset settings",
+			cluster:   &DMClusterSpec{ImagePullPolicy: corev1.PullNever},
+			component: &ComponentSpec{},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				g.Expect(a.HelperImage()).Should(Equal(defaultHelperImage))
+				g.Expect(a.HelperImagePullPolicy()).Should(Equal(corev1.PullNever))
+				g.Expect(a.StatefulSetUpdateStrategy()).Should(Equal(apps.RollingUpdateStatefulSetStrategyType))
+				g.Expect(a.PodManagementPolicy()).Should(Equal(apps.ParallelPodManagement))
+				g.Expect(a.Timezone()).Should(Equal(defaultTimeZone))
+				g.Expect(a.MountTimezoneData()).Should(BeFalse())
+			},
+		},
+		{
+			name: "helper and statefulset settings at cluster-level",
+			cluster: &DMClusterSpec{
+				ClusterRegistryPrefix:     "registry.local/mirror",
+				Helper:                    &HelperSpec{Image: pointer.StringPtr("busybox:1.34.1"), Digest: pointer.StringPtr("sha256:abc")},
+				StatefulSetUpdateStrategy: apps.OnDeleteStatefulSetStrategyType,
+				PodManagementPolicy:       apps.OrderedReadyPodManagement,
+				MountTimezoneData:         pointer.BoolPtr(true),
+			},
+			component: &ComponentSpec{},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				g.Expect(a.HelperImage()).Should(Equal("registry.local/mirror/busybox:1.34.1@sha256:abc"))
+				g.Expect(a.StatefulSetUpdateStrategy()).Should(Equal(apps.OnDeleteStatefulSetStrategyType))
+				g.Expect(a.PodManagementPolicy()).Should(Equal(apps.OrderedReadyPodManagement))
+				g.Expect(a.MountTimezoneData()).Should(BeTrue())
+			},
+		},
 	}
 
 	for i := range tests {
@@ -0,0 +1,30 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// TaskName returns the name of the task in the DMCluster
+func (dt *DMTask) TaskName() string {
+	if dt.Spec.TaskName != "" {
+		return dt.Spec.TaskName
+	}
+	return dt.GetName()
+}
+
+// DesiredStage returns the desired stage of the task, Running by default
+func (dt *DMTask) DesiredStage() DMTaskStage {
+	if dt.Spec.Stage == "" {
+		return DMTaskStageRunning
+	}
+	return dt.Spec.Stage
+}
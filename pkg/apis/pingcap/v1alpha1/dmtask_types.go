@@ -0,0 +1,183 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DMTaskStage is the stage of a DM task
+type DMTaskStage string
+
+const (
+	// DMTaskStageRunning means the task is running
+	DMTaskStageRunning DMTaskStage = "Running"
+	// DMTaskStagePaused means the task is stopped with its checkpoints kept, it resumes from them once running again
+	DMTaskStagePaused DMTaskStage = "Paused"
+	// DMTaskStageStopped means the task is deleted from the DMCluster with its checkpoints
+	DMTaskStageStopped DMTaskStage = "Stopped"
+	// DMTaskStageFinished means all the subtasks of the task are finished, it's only observed in the status
+	DMTaskStageFinished DMTaskStage = "Finished"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DMTask manages a migration task of a DMCluster and the sources of the task declaratively
+// through the OpenAPI of the DM-master, which must be enabled with `openapi = true` in the config.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:shortName="dmt"
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster`,description="The name of the DMCluster the task is run in"
+// +kubebuilder:printcolumn:name="Desired",type=string,JSONPath=`.spec.stage`,description="The desired stage of the task"
+// +kubebuilder:printcolumn:name="Stage",type=string,JSONPath=`.status.stage`,description="The observed stage of the task"
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,priority=1,description="Why the task is not synced"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type DMTask struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the desired state of the task
+	Spec DMTaskSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the DMTask
+	Status DMTaskStatus `json:"status,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// DMTaskSpec describes a DM migration task and its sources
+type DMTaskSpec struct {
+	// Cluster is the name of the DMCluster in the same namespace to run the task
+	Cluster string `json:"cluster"`
+
+	// TaskName is the name of the task in the DMCluster
+	// Optional: Defaults to the name of the DMTask
+	// +optional
+	TaskName string `json:"taskName,omitempty"`
+
+	// Sources are the sources of the task, they are created if they don't exist in the DMCluster and
+	// updated once their configs or passwords change. The sources removed from the spec are deleted, but
+	// they are not deleted with the DMTask as they may be shared by other tasks
+	// +optional
+	Sources []DMTaskSource `json:"sources,omitempty"`
+
+	// Config is the config of the task in the JSON format of the Task in the OpenAPI of DM-master,
+	// the name in it is set to the task name. Changes of the config are applied by stopping the task,
+	// updating it and starting it again.
+	Config string `json:"config"`
+
+	// Stage is the desired stage of the task, one of `Running`, `Paused` and `Stopped`.
+	// A paused task resumes from its checkpoints once running again, while a stopped task
+	// is deleted from the DMCluster with its checkpoints, and it starts over once running again.
+	// The task is deleted from the DMCluster when the DMTask is deleted too.
+	// Optional: Defaults to Running
+	// +kubebuilder:validation:Enum=Running;Paused;Stopped
+	// +optional
+	Stage DMTaskStage `json:"stage,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// DMTaskSource is an upstream MySQL or MariaDB of a DM task
+type DMTaskSource struct {
+	// Name is the name of the source
+	Name string `json:"name"`
+
+	// Host is the host of the upstream database
+	Host string `json:"host"`
+
+	// Port is the port of the upstream database
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// User is the user to connect to the upstream database
+	User string `json:"user"`
+
+	// SecretName is the name of the Secret that contains the password of the user in the key password
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// EnableGTID enables the GTID based replication of the source
+	// +optional
+	EnableGTID bool `json:"enableGTID,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// DMTaskStatus is the status of a DMTask
+type DMTaskStatus struct {
+	// ObservedGeneration is the generation of the DMTask last synced
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Stage is the observed stage of the task
+	// +optional
+	Stage DMTaskStage `json:"stage,omitempty"`
+
+	// ConfigHash is the hash of the config of the task applied to the DMCluster
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// SubTasks are the statuses of the subtasks of the task, one for each source
+	// +optional
+	SubTasks []DMSubTaskStatus `json:"subTasks,omitempty"`
+
+	// SourceHashes are the hashes of the configs of the sources applied to the DMCluster, including the
+	// passwords, keyed by the names of the sources. The sources removed from the spec are deleted.
+	// +optional
+	SourceHashes map[string]string `json:"sourceHashes,omitempty"`
+
+	// ResumeCount is the number of times the task paused by an error has been resumed since it last ran
+	// steadily, the task is resumed with an exponential backoff
+	// +optional
+	ResumeCount int32 `json:"resumeCount,omitempty"`
+
+	// LastResumeTime is the time the task was last started or resumed
+	// +optional
+	LastResumeTime *metav1.Time `json:"lastResumeTime,omitempty"`
+
+	// Message describes why the task is not synced
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// DMSubTaskStatus is the status of the migration of a DM task from one source
+type DMSubTaskStatus struct {
+	// SourceName is the name of the source
+	SourceName string `json:"sourceName"`
+	// WorkerName is the name of the DM-worker the subtask runs on
+	// +optional
+	WorkerName string `json:"workerName,omitempty"`
+	// Stage is one of New, Running, Paused, Stopped and Finished
+	// +optional
+	Stage string `json:"stage,omitempty"`
+	// Unit is the processing unit, one of dump, load and sync
+	// +optional
+	Unit string `json:"unit,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// DMTaskList is DMTask list
+type DMTaskList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []DMTask `json:"items"`
+}
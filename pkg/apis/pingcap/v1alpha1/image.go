@@ -0,0 +1,67 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"strings"
+)
+
+// ImageWithRegistryPrefix rewrites the registry of the image to the given prefix,
+// e.g. with prefix `registry.local/mirror`:
+//   pingcap/tikv:v5.4.0 => registry.local/mirror/pingcap/tikv:v5.4.0
+//   gcr.io/pingcap/tikv:v5.4.0 => registry.local/mirror/pingcap/tikv:v5.4.0
+// The image is returned as is if the prefix or the image is empty.
+func ImageWithRegistryPrefix(prefix, image string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" || image == "" || strings.HasPrefix(image, prefix+"/") {
+		return image
+	}
+	// the first component of the image is a registry host only if it contains
+	// a '.' or a ':', or it is 'localhost', the same as docker does
+	if i := strings.IndexByte(image, '/'); i >= 0 {
+		host := image[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			image = image[i+1:]
+		}
+	}
+	return prefix + "/" + image
+}
+
+// ImageWithDigest pins the image to the given digest, e.g. with digest `sha256:abc...`:
+//   busybox:1.34.1 => busybox:1.34.1@sha256:abc...
+// The digest already in the image is replaced, and the image is returned as is
+// if the digest is empty.
+func ImageWithDigest(image, digest string) string {
+	if image == "" || digest == "" {
+		return image
+	}
+	if i := strings.IndexByte(image, '@'); i >= 0 {
+		image = image[:i]
+	}
+	return image + "@" + digest
+}
+
+// DigestOfImage returns the digest in the image reference or the image ID reported
+// in the container status, e.g. `docker-pullable://busybox@sha256:abc...` => `sha256:abc...`,
+// or an empty string if there is no digest.
+func DigestOfImage(image string) string {
+	if i := strings.LastIndexByte(image, '@'); i >= 0 {
+		return image[i+1:]
+	}
+	// image IDs of containerd and cri-o are in the form of `sha256:abc...`
+	if i := strings.LastIndex(image, "sha256:"); i >= 0 {
+		return image[i:]
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+)
+
+func TestImageWithRegistryPrefix(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		prefix   string
+		image    string
+		expected string
+	}{
+		{"", "pingcap/tikv:v5.4.0", "pingcap/tikv:v5.4.0"},
+		{"registry.local/mirror", "", ""},
+		{"registry.local/mirror", "pingcap/tikv:v5.4.0", "registry.local/mirror/pingcap/tikv:v5.4.0"},
+		{"registry.local/mirror/", "pingcap/tikv:v5.4.0", "registry.local/mirror/pingcap/tikv:v5.4.0"},
+		{"registry.local/mirror", "busybox:1.34.1", "registry.local/mirror/busybox:1.34.1"},
+		{"registry.local/mirror", "gcr.io/pingcap/tikv:v5.4.0", "registry.local/mirror/pingcap/tikv:v5.4.0"},
+		{"registry.local/mirror", "localhost:5000/pingcap/tikv", "registry.local/mirror/pingcap/tikv"},
+		{"registry.local/mirror", "localhost/pingcap/tikv@sha256:abc", "registry.local/mirror/pingcap/tikv@sha256:abc"},
+		{"registry.local/mirror", "registry.local/mirror/pingcap/tikv:v5.4.0", "registry.local/mirror/pingcap/tikv:v5.4.0"},
+	}
+	for _, tt := range tests {
+		g.Expect(ImageWithRegistryPrefix(tt.prefix, tt.image)).Should(Equal(tt.expected), "prefix %q, image %q", tt.prefix, tt.image)
+	}
+}
+
+func TestClusterRegistryPrefix(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &TidbCluster{
+		Spec: TidbClusterSpec{
+			Version:               "v5.4.0",
+			ClusterRegistryPrefix: "registry.local/mirror",
+			PD:                    &PDSpec{BaseImage: "pingcap/pd"},
+			Pump:                  &PumpSpec{BaseImage: "pingcap/tidb-binlog"},
+		},
+	}
+	g.Expect(tc.PDImage()).Should(Equal("registry.local/mirror/pingcap/pd:v5.4.0"))
+	g.Expect(*tc.PumpImage()).Should(Equal("registry.local/mirror/pingcap/tidb-binlog:v5.4.0"))
+	g.Expect(tc.HelperImage()).Should(Equal("registry.local/mirror/" + defaultHelperImage))
+}
+
+func TestImageWithDigest(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		image    string
+		digest   string
+		expected string
+	}{
+		{"busybox:1.34.1", "", "busybox:1.34.1"},
+		{"", "sha256:abc", ""},
+		{"busybox:1.34.1", "sha256:abc", "busybox:1.34.1@sha256:abc"},
+		{"busybox@sha256:def", "sha256:abc", "busybox@sha256:abc"},
+	}
+	for _, tt := range tests {
+		g.Expect(ImageWithDigest(tt.image, tt.digest)).Should(Equal(tt.expected), "image %q, digest %q", tt.image, tt.digest)
+	}
+}
+
+func TestDigestOfImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{"busybox:1.34.1", ""},
+		{"busybox:1.34.1@sha256:abc", "sha256:abc"},
+		{"docker-pullable://busybox@sha256:abc", "sha256:abc"},
+		{"sha256:abc", "sha256:abc"},
+	}
+	for _, tt := range tests {
+		g.Expect(DigestOfImage(tt.image)).Should(Equal(tt.expected), "image %q", tt.image)
+	}
+}
+
+func TestComponentHelperImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	digest := "sha256:abc"
+	override := "sha256:def"
+	tc := &TidbCluster{
+		Spec: TidbClusterSpec{
+			Helper: &HelperSpec{
+				Image:  pointer.StringPtr("busybox:1.34.1"),
+				Digest: &digest,
+			},
+			TiKV: &TiKVSpec{},
+			TiDB: &TiDBSpec{},
+			PD:   &PDSpec{},
+		},
+	}
+	tc.Spec.TiKV.Helper = &HelperSpec{Digest: &override}
+	tc.Spec.TiDB.Helper = &HelperSpec{Image: pointer.StringPtr("example.com/busybox:1.34.1")}
+
+	g.Expect(tc.HelperImage()).Should(Equal("busybox:1.34.1@sha256:abc"))
+	g.Expect(tc.BasePDSpec().HelperImage()).Should(Equal("busybox:1.34.1@sha256:abc"))
+	g.Expect(tc.BaseTiKVSpec().HelperImage()).Should(Equal("busybox:1.34.1@sha256:def"))
+	// the digest of the cluster-level image is dropped if the image is overridden
+	g.Expect(tc.BaseTiDBSpec().HelperImage()).Should(Equal("example.com/busybox:1.34.1"))
+}
@@ -27,8 +27,11 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AnalyzeTableTask":              schema_pkg_apis_pingcap_v1alpha1_AnalyzeTableTask(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoResource":                  schema_pkg_apis_pingcap_v1alpha1_AutoResource(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoResumeSpec":                schema_pkg_apis_pingcap_v1alpha1_AutoResumeSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoRule":                      schema_pkg_apis_pingcap_v1alpha1_AutoRule(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider":         schema_pkg_apis_pingcap_v1alpha1_AzblobStorageProvider(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig":                      schema_pkg_apis_pingcap_v1alpha1_BRConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Backup":                        schema_pkg_apis_pingcap_v1alpha1_Backup(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupList":                    schema_pkg_apis_pingcap_v1alpha1_BackupList(ref),
@@ -42,16 +45,27 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BatchDeleteOption":             schema_pkg_apis_pingcap_v1alpha1_BatchDeleteOption(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Binlog":                        schema_pkg_apis_pingcap_v1alpha1_Binlog(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CleanOption":                   schema_pkg_apis_pingcap_v1alpha1_CleanOption(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ClockSkewSpec":                 schema_pkg_apis_pingcap_v1alpha1_ClockSkewSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ClusterRef":                    schema_pkg_apis_pingcap_v1alpha1_ClusterRef(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CommonConfig":                  schema_pkg_apis_pingcap_v1alpha1_CommonConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CompactTiKVTask":               schema_pkg_apis_pingcap_v1alpha1_CompactTiKVTask(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ComponentSpec":                 schema_pkg_apis_pingcap_v1alpha1_ComponentSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigDriftSpec":               schema_pkg_apis_pingcap_v1alpha1_ConfigDriftSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigMapRef":                  schema_pkg_apis_pingcap_v1alpha1_ConfigMapRef(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMCluster":                     schema_pkg_apis_pingcap_v1alpha1_DMCluster(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMClusterList":                 schema_pkg_apis_pingcap_v1alpha1_DMClusterList(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMClusterSpec":                 schema_pkg_apis_pingcap_v1alpha1_DMClusterSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMDiscoverySpec":               schema_pkg_apis_pingcap_v1alpha1_DMDiscoverySpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMExperimental":                schema_pkg_apis_pingcap_v1alpha1_DMExperimental(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMSubTaskStatus":               schema_pkg_apis_pingcap_v1alpha1_DMSubTaskStatus(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTask":                        schema_pkg_apis_pingcap_v1alpha1_DMTask(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTaskList":                    schema_pkg_apis_pingcap_v1alpha1_DMTaskList(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTaskSource":                  schema_pkg_apis_pingcap_v1alpha1_DMTaskSource(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTaskSpec":                    schema_pkg_apis_pingcap_v1alpha1_DMTaskSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTaskStatus":                  schema_pkg_apis_pingcap_v1alpha1_DMTaskStatus(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DashboardConfig":               schema_pkg_apis_pingcap_v1alpha1_DashboardConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DebugArtifactsSpec":            schema_pkg_apis_pingcap_v1alpha1_DebugArtifactsSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DiagnosticsSpec":               schema_pkg_apis_pingcap_v1alpha1_DiagnosticsSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DiscoverySpec":                 schema_pkg_apis_pingcap_v1alpha1_DiscoverySpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DumplingConfig":                schema_pkg_apis_pingcap_v1alpha1_DumplingConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Experimental":                  schema_pkg_apis_pingcap_v1alpha1_Experimental(ref),
@@ -66,19 +80,29 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashServerConfig":             schema_pkg_apis_pingcap_v1alpha1_FlashServerConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider":            schema_pkg_apis_pingcap_v1alpha1_GcsStorageProvider(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec":                    schema_pkg_apis_pingcap_v1alpha1_HelperSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ImportWindowSpec":              schema_pkg_apis_pingcap_v1alpha1_ImportWindowSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.IngressSpec":                   schema_pkg_apis_pingcap_v1alpha1_IngressSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitSqlStorageSpec":            schema_pkg_apis_pingcap_v1alpha1_InitSqlStorageSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.IsolationRead":                 schema_pkg_apis_pingcap_v1alpha1_IsolationRead(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Log":                           schema_pkg_apis_pingcap_v1alpha1_Log(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec":                 schema_pkg_apis_pingcap_v1alpha1_LogTailerSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MaintenanceSpec":               schema_pkg_apis_pingcap_v1alpha1_MaintenanceSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MaintenanceTask":               schema_pkg_apis_pingcap_v1alpha1_MaintenanceTask(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterConfig":                  schema_pkg_apis_pingcap_v1alpha1_MasterConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterKeyFileConfig":           schema_pkg_apis_pingcap_v1alpha1_MasterKeyFileConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterKeyKMSConfig":            schema_pkg_apis_pingcap_v1alpha1_MasterKeyKMSConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterSpec":                    schema_pkg_apis_pingcap_v1alpha1_MasterSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MonitorContainer":              schema_pkg_apis_pingcap_v1alpha1_MonitorContainer(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NGMonitoringSpec":              schema_pkg_apis_pingcap_v1alpha1_NGMonitoringSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NotificationSink":              schema_pkg_apis_pingcap_v1alpha1_NotificationSink(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NotificationSpec":              schema_pkg_apis_pingcap_v1alpha1_NotificationSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracing":                   schema_pkg_apis_pingcap_v1alpha1_OpenTracing(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingReporter":           schema_pkg_apis_pingcap_v1alpha1_OpenTracingReporter(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingSampler":            schema_pkg_apis_pingcap_v1alpha1_OpenTracingSampler(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpsCommand":                    schema_pkg_apis_pingcap_v1alpha1_OpsCommand(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpsCommandList":                schema_pkg_apis_pingcap_v1alpha1_OpsCommandList(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpsCommandSpec":                schema_pkg_apis_pingcap_v1alpha1_OpsCommandSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpsCommandStatus":              schema_pkg_apis_pingcap_v1alpha1_OpsCommandStatus(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDConfig":                      schema_pkg_apis_pingcap_v1alpha1_PDConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDLogConfig":                   schema_pkg_apis_pingcap_v1alpha1_PDLogConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDMetricConfig":                schema_pkg_apis_pingcap_v1alpha1_PDMetricConfig(ref),
@@ -90,31 +114,47 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDServerConfig":                schema_pkg_apis_pingcap_v1alpha1_PDServerConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSpec":                        schema_pkg_apis_pingcap_v1alpha1_PDSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDStoreLabel":                  schema_pkg_apis_pingcap_v1alpha1_PDStoreLabel(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PagerDutyNotificationSink":     schema_pkg_apis_pingcap_v1alpha1_PagerDutyNotificationSink(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Performance":                   schema_pkg_apis_pingcap_v1alpha1_Performance(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PessimisticTxn":                schema_pkg_apis_pingcap_v1alpha1_PessimisticTxn(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PiTRRestoreSpec":               schema_pkg_apis_pingcap_v1alpha1_PiTRRestoreSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PlanCache":                     schema_pkg_apis_pingcap_v1alpha1_PlanCache(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Plugin":                        schema_pkg_apis_pingcap_v1alpha1_Plugin(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec":       schema_pkg_apis_pingcap_v1alpha1_PodDisruptionBudgetSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PreparedPlanCache":             schema_pkg_apis_pingcap_v1alpha1_PreparedPlanCache(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PrometheusConfiguration":       schema_pkg_apis_pingcap_v1alpha1_PrometheusConfiguration(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyConfig":                   schema_pkg_apis_pingcap_v1alpha1_ProxyConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyProtocol":                 schema_pkg_apis_pingcap_v1alpha1_ProxyProtocol(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PumpSpec":                      schema_pkg_apis_pingcap_v1alpha1_PumpSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.QueueConfig":                   schema_pkg_apis_pingcap_v1alpha1_QueueConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ReadOnlySpec":                  schema_pkg_apis_pingcap_v1alpha1_ReadOnlySpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RelabelConfig":                 schema_pkg_apis_pingcap_v1alpha1_RelabelConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RelayLogStorageSpec":           schema_pkg_apis_pingcap_v1alpha1_RelayLogStorageSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RemoteWriteSpec":               schema_pkg_apis_pingcap_v1alpha1_RemoteWriteSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ResetStoreLimitTask":           schema_pkg_apis_pingcap_v1alpha1_ResetStoreLimitTask(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Restore":                       schema_pkg_apis_pingcap_v1alpha1_Restore(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreList":                   schema_pkg_apis_pingcap_v1alpha1_RestoreList(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec":                   schema_pkg_apis_pingcap_v1alpha1_RestoreSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreStatisticsSpec":         schema_pkg_apis_pingcap_v1alpha1_RestoreStatisticsSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreTiFlashReplicaSpec":     schema_pkg_apis_pingcap_v1alpha1_RestoreTiFlashReplicaSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider":             schema_pkg_apis_pingcap_v1alpha1_S3StorageProvider(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SafeTLSConfig":                 schema_pkg_apis_pingcap_v1alpha1_SafeTLSConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ScrapeConfig":                  schema_pkg_apis_pingcap_v1alpha1_ScrapeConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretRef":                     schema_pkg_apis_pingcap_v1alpha1_SecretRef(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Security":                      schema_pkg_apis_pingcap_v1alpha1_Security(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ServiceSpec":                   schema_pkg_apis_pingcap_v1alpha1_ServiceSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SilenceWindow":                 schema_pkg_apis_pingcap_v1alpha1_SilenceWindow(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SlackNotificationSink":         schema_pkg_apis_pingcap_v1alpha1_SlackNotificationSink(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SpotTerminationSpec":           schema_pkg_apis_pingcap_v1alpha1_SpotTerminationSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Status":                        schema_pkg_apis_pingcap_v1alpha1_Status(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StmtSummary":                   schema_pkg_apis_pingcap_v1alpha1_StmtSummary(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageClaim":                  schema_pkg_apis_pingcap_v1alpha1_StorageClaim(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvider":               schema_pkg_apis_pingcap_v1alpha1_StorageProvider(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvisioningJob":        schema_pkg_apis_pingcap_v1alpha1_StorageProvisioningJob(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvisioningSpec":       schema_pkg_apis_pingcap_v1alpha1_StorageProvisioningSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvisioningWebhook":    schema_pkg_apis_pingcap_v1alpha1_StorageProvisioningWebhook(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSConfig":                     schema_pkg_apis_pingcap_v1alpha1_TLSConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCChangefeed":               schema_pkg_apis_pingcap_v1alpha1_TiCDCChangefeed(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCConfig":                   schema_pkg_apis_pingcap_v1alpha1_TiCDCConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCSpec":                     schema_pkg_apis_pingcap_v1alpha1_TiCDCSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig":              schema_pkg_apis_pingcap_v1alpha1_TiDBAccessConfig(ref),
@@ -124,12 +164,15 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSlowLogTailerSpec":         schema_pkg_apis_pingcap_v1alpha1_TiDBSlowLogTailerSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSpec":                      schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBTLSClient":                 schema_pkg_apis_pingcap_v1alpha1_TiDBTLSClient(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBUpgradeStrategy":           schema_pkg_apis_pingcap_v1alpha1_TiDBUpgradeStrategy(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashConfig":                 schema_pkg_apis_pingcap_v1alpha1_TiFlashConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashReplicaTable":           schema_pkg_apis_pingcap_v1alpha1_TiFlashReplicaTable(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashSpec":                   schema_pkg_apis_pingcap_v1alpha1_TiFlashSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBackupConfig":              schema_pkg_apis_pingcap_v1alpha1_TiKVBackupConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBlockCacheConfig":          schema_pkg_apis_pingcap_v1alpha1_TiKVBlockCacheConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig":                  schema_pkg_apis_pingcap_v1alpha1_TiKVCfConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVClient":                    schema_pkg_apis_pingcap_v1alpha1_TiKVClient(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVColdGroupSpec":             schema_pkg_apis_pingcap_v1alpha1_TiKVColdGroupSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVConfig":                    schema_pkg_apis_pingcap_v1alpha1_TiKVConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig":         schema_pkg_apis_pingcap_v1alpha1_TiKVCoprocessorConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorReadPoolConfig": schema_pkg_apis_pingcap_v1alpha1_TiKVCoprocessorReadPoolConfig(ref),
@@ -148,9 +191,11 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSpec":                      schema_pkg_apis_pingcap_v1alpha1_TiKVSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageConfig":             schema_pkg_apis_pingcap_v1alpha1_TiKVStorageConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageReadPoolConfig":     schema_pkg_apis_pingcap_v1alpha1_TiKVStorageReadPoolConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStoreScheduling":           schema_pkg_apis_pingcap_v1alpha1_TiKVStoreScheduling(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanCfConfig":             schema_pkg_apis_pingcap_v1alpha1_TiKVTitanCfConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanDBConfig":             schema_pkg_apis_pingcap_v1alpha1_TiKVTitanDBConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVUnifiedReadPoolConfig":     schema_pkg_apis_pingcap_v1alpha1_TiKVUnifiedReadPoolConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiProxySpec":                   schema_pkg_apis_pingcap_v1alpha1_TiProxySpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbAutoScalerSpec":            schema_pkg_apis_pingcap_v1alpha1_TidbAutoScalerSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbAutoScalerStatus":          schema_pkg_apis_pingcap_v1alpha1_TidbAutoScalerStatus(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbCluster":                   schema_pkg_apis_pingcap_v1alpha1_TidbCluster(ref),
@@ -175,7 +220,14 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbNGMonitoringSpec":          schema_pkg_apis_pingcap_v1alpha1_TidbNGMonitoringSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TikvAutoScalerSpec":            schema_pkg_apis_pingcap_v1alpha1_TikvAutoScalerSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TikvAutoScalerStatus":          schema_pkg_apis_pingcap_v1alpha1_TikvAutoScalerStatus(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TombstoneStoreGCSpec":          schema_pkg_apis_pingcap_v1alpha1_TombstoneStoreGCSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopSQLSpec":                    schema_pkg_apis_pingcap_v1alpha1_TopSQLSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TxnLocalLatches":               schema_pkg_apis_pingcap_v1alpha1_TxnLocalLatches(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.UserGrant":                     schema_pkg_apis_pingcap_v1alpha1_UserGrant(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.UserReconciliationSpec":        schema_pkg_apis_pingcap_v1alpha1_UserReconciliationSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.UserReconciliationStatus":      schema_pkg_apis_pingcap_v1alpha1_UserReconciliationStatus(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.VersionSkewSpec":               schema_pkg_apis_pingcap_v1alpha1_VersionSkewSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WebhookNotificationSink":       schema_pkg_apis_pingcap_v1alpha1_WebhookNotificationSink(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WorkerConfig":                  schema_pkg_apis_pingcap_v1alpha1_WorkerConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WorkerSpec":                    schema_pkg_apis_pingcap_v1alpha1_WorkerSpec(ref),
 		"k8s.io/api/core/v1.AWSElasticBlockStoreVolumeSource":                                      schema_k8sio_api_core_v1_AWSElasticBlockStoreVolumeSource(ref),
@@ -435,6 +487,55 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_AnalyzeTableTask(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AnalyzeTableTask runs ANALYZE TABLE on the tables",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"tables": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tables are the names of the tables in the format of db.table",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User is the user to connect to TiDB Optional: Defaults to root",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"secretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecretName is the name of the Secret that contains the password of the user in the key password",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image is the image with the mysql client Optional: Defaults to tnir/mysqlclient",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"tables"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_AutoResource(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -476,6 +577,33 @@ func schema_pkg_apis_pingcap_v1alpha1_AutoResource(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_AutoResumeSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AutoResumeSpec describes when to resume a paused cluster, only one of until and after can be set.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"until": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Until is the time after which the cluster is resumed",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"after": {
+						SchemaProps: spec.SchemaProps{
+							Description: "After is how long the cluster stays paused since the operator observes it paused",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_AutoRule(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -518,6 +646,54 @@ func schema_pkg_apis_pingcap_v1alpha1_AutoRule(ref common.ReferenceCallback) com
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_AzblobStorageProvider(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AzblobStorageProvider represents the azure blob storage for storing backups.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path is the full path where the backup is saved. The format of the path must be: \"<container-name>/<path-to-backup-file>\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"container": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Container in which to store the backup data.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"accessTier": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AccessTier represents the access tier of the blobs, Hot, Cool or Archive.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"secretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecretName is the name of secret which stores the azure storage account name and either the account key or a SAS token. The secret is mounted into the job, so a rotated SAS token is picked up without restarting the job.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"prefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Prefix of the data path.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_BRConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -609,6 +785,13 @@ func schema_pkg_apis_pingcap_v1alpha1_BRConfig(ref common.ReferenceCallback) com
 							Format:      "",
 						},
 					},
+					"ignoreStats": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IgnoreStats specifies whether to skip backing up the statistics of the tables. Set it to false to back up the statistics, which are loaded by the restore to avoid running with empty statistics. It's only used by backup and supported by BR since v4.0.9.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"options": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Options means options for backup data to remote storage with BR. These options has highest priority.",
@@ -830,6 +1013,12 @@ func schema_pkg_apis_pingcap_v1alpha1_BackupScheduleSpec(ref common.ReferenceCal
 							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupSpec"),
 						},
 					},
+					"logBackupTemplate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogBackupTemplate is the specification of the log backup running along with the scheduled backups, its mode is always log. The scheduled backups that are the base of the retained window of the log backup are never garbage collected, and the log backup is truncated until the oldest of them.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupSpec"),
+						},
+					},
 					"storageClassName": {
 						SchemaProps: spec.SchemaProps{
 							Description: "The storageClassName of the persistent volume for Backup data storage if not storage class name set in BackupSpec. Defaults to Kubernetes default storage class.",
@@ -857,12 +1046,18 @@ func schema_pkg_apis_pingcap_v1alpha1_BackupScheduleSpec(ref common.ReferenceCal
 							},
 						},
 					},
+					"rpo": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RPO is the recovery point objective of the scheduled backups, the BackupFresh condition is set to False if no backup completed successfully within it.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
 				},
 				Required: []string{"schedule", "backupTemplate"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupSpec", "k8s.io/api/core/v1.LocalObjectReference"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupSpec", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -904,6 +1099,27 @@ func schema_pkg_apis_pingcap_v1alpha1_BackupSpec(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"backupMode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Mode is the backup mode, snapshot, log or volume-snapshot. The log mode starts a log backup task, which continuously backs up the changes of the cluster to the storage until the Backup is deleted. It's only supported by BR. The volume-snapshot mode pauses the PD scheduling of the cluster in `spec.br`, records the min resolved ts of the cluster as the commit ts and takes the CSI VolumeSnapshots of the PVCs of PD and TiKV, the scheduling is resumed once the snapshots are cut. No backup job is created and the storage of the Backup is not used. Optional: Defaults to snapshot",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"logTruncateUntil": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogTruncateUntil truncates the data of the log backup before the ts, in the format of a TSO, e.g. `434319917412892673`, or a RFC3339 datetime, e.g. `2022-07-15T19:00:00+08:00`. It's only valid for the log mode.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"volumeSnapshotClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VolumeSnapshotClassName is the VolumeSnapshotClass of the snapshots taken by the volume-snapshot mode. Optional: Defaults to the default VolumeSnapshotClass of the CSI driver",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"tikvGCLifeTime": {
 						SchemaProps: spec.SchemaProps{
 							Description: "TikvGCLifeTime is to specify the safe gc life time for backup. The time limit during which data is retained for each GC, in the format of Go Duration. When a GC happens, the current time minus this value is the safe point.",
@@ -921,6 +1137,11 @@ func schema_pkg_apis_pingcap_v1alpha1_BackupSpec(ref common.ReferenceCallback) c
 							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider"),
 						},
 					},
+					"azblob": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider"),
+						},
+					},
 					"local": {
 						SchemaProps: spec.SchemaProps{
 							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider"),
@@ -985,6 +1206,13 @@ func schema_pkg_apis_pingcap_v1alpha1_BackupSpec(ref common.ReferenceCallback) c
 							},
 						},
 					},
+					"clusterRegistryPrefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterRegistryPrefix rewrites the registry of all the images used by the backup job, including the backup manager and tool images. E.g. with `registry.local/mirror`, `pingcap/br:v5.4.0` is pulled from `registry.local/mirror/pingcap/br:v5.4.0`, which is useful for air-gapped environments",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"tableFilter": {
 						SchemaProps: spec.SchemaProps{
 							Description: "TableFilter means Table filter expression for 'db.table' matching. BR supports this from v4.0.3.",
@@ -1045,11 +1273,43 @@ func schema_pkg_apis_pingcap_v1alpha1_BackupSpec(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"additionalVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volumes of the Backup Job Pods, e.g. the ConfigMaps or Secrets of custom CA bundles, proxy settings or wrapper scripts",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Volume"),
+									},
+								},
+							},
+						},
+					},
+					"additionalVolumeMounts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volume mounts of the backup container",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
+						},
+					},
+					"notifications": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Notifications configures the sinks to send the notification to when the backup fails",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NotificationSpec"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CleanOption", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DumplingConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Toleration"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CleanOption", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DumplingConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NotificationSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
 	}
 }
 
@@ -1287,6 +1547,60 @@ func schema_pkg_apis_pingcap_v1alpha1_CleanOption(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_ClockSkewSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ClockSkewSpec describes how to detect and handle clock skew. The clock of a PD member is measured by the Date header of its API responses, and the skew is its offset from the median clock of the operator and all the PD members, so the precision is about 1s. The nodes of the cluster with any of the nodeConditions are reported as skewed too.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"policy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Policy is the action taken when a badly skewed node is detected Optional: Defaults to Report",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"maxSkew": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxSkew is the maximum skew of a PD member that is tolerated Optional: Defaults to 2s",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"cordonThreshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CordonThreshold is the skew of a PD member beyond which its node is cordoned if the policy is Cordon Optional: Defaults to 10s",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"nodeConditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeConditions are the types of the node conditions that report the clock of the node is not synchronized, e.g. set by the node-problem-detector Optional: Defaults to NTPProblem",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"interval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Interval is the minimum interval between two checks Optional: Defaults to 5m",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_ClusterRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1375,6 +1689,68 @@ func schema_pkg_apis_pingcap_v1alpha1_CommonConfig(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_CompactTiKVTask(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "CompactTiKVTask compacts the data of all the TiKV stores",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"db": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DB is the db to compact, kv or raft Optional: Defaults to kv",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"columnFamilies": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ColumnFamilies are the column families to compact Optional: Defaults to default and write",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"tables": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tables limits the compaction to the data of the tables in the format of db.table, including their partitions. The key ranges of the tables are resolved by TiDB when the Job is created. It's only supported for the kv db.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"keyspaceIDs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KeyspaceIDs limits the compaction to the transactional data of the keyspaces, for the clusters with the API V2 enabled. It's only supported for the kv db.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int64",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_ComponentSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1593,6 +1969,20 @@ func schema_pkg_apis_pingcap_v1alpha1_ComponentSpec(ref common.ReferenceCallback
 							Format:      "",
 						},
 					},
+					"minReadySeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rollingUpdateIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 					"topologySpreadConstraints": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -1614,11 +2004,88 @@ func schema_pkg_apis_pingcap_v1alpha1_ComponentSpec(ref common.ReferenceCallback
 							},
 						},
 					},
+					"architecture": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archBaseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"startScriptVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"helper": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_ConfigDriftSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ConfigDriftSpec describes how to detect and handle config drift",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"policy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Policy is the action taken when drift is detected Optional: Defaults to Report",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"interval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Interval is the minimum interval between two checks Optional: Defaults to 5m",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -1751,6 +2218,19 @@ func schema_pkg_apis_pingcap_v1alpha1_DMClusterSpec(ref common.ReferenceCallback
 							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WorkerSpec"),
 						},
 					},
+					"cluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cluster is the DMCluster whose dm-masters the dm-workers of this DMCluster join, if configured, no dm-master is deployed for this DMCluster and the referenced DMCluster may be in another namespace. The TLS and the port of dm-master must be the same as the referenced DMCluster.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef"),
+						},
+					},
+					"clusterDomain": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterDomain is the Kubernetes Cluster Domain of the DM cluster, if configured, the dm-masters and dm-workers advertise their FQDN addresses with it, e.g. `demo-dm-master-0.demo-dm-master-peer.dm.svc.cluster-a.local`, so that they are reachable from the members in other Kubernetes clusters. It can't be changed for an existing cluster. Optional: Defaults to \"\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"paused": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Indicates that the dm cluster is paused and will not be processed by the controller.",
@@ -1758,6 +2238,20 @@ func schema_pkg_apis_pingcap_v1alpha1_DMClusterSpec(ref common.ReferenceCallback
 							Format:      "",
 						},
 					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Suspend scales the dm-master and dm-worker StatefulSets down to zero while keeping the PVCs and the status of the members, the replicas before the suspension are restored once it's unset. Unlike Paused, the dm cluster is still processed by the controller.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"upgradeTaskCheckPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UpgradeTaskCheckPolicy is the policy of the check of the task stages before upgrading dm-master and dm-worker. The dump of a subtask starts over if it's interrupted, so with Block the upgrade is blocked while any subtask is in the full migration phase, i.e. running the dump or load unit, and the UpgradeBlocked condition of the component describes the blocking subtasks. With Warn, only a warning event is recorded. Optional: Defaults to Block",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"version": {
 						SchemaProps: spec.SchemaProps{
 							Description: "dm cluster version",
@@ -1799,6 +2293,19 @@ func schema_pkg_apis_pingcap_v1alpha1_DMClusterSpec(ref common.ReferenceCallback
 							},
 						},
 					},
+					"clusterRegistryPrefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterRegistryPrefix rewrites the registry of all the images used by the dm cluster, including the dm-master, dm-worker and discovery images. E.g. with `registry.local/mirror`, `pingcap/dm:v2.0.7` is pulled from `registry.local/mirror/pingcap/dm:v2.0.7`, which is useful for air-gapped environments",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"helper": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Helper spec, the helper image is used by the sidecars and init containers of DM cluster Pods",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
+						},
+					},
 					"enablePVReclaim": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Whether enable PVC reclaim for orphan PVC left by statefulset scale-in Optional: Defaults to false",
@@ -1898,6 +2405,13 @@ func schema_pkg_apis_pingcap_v1alpha1_DMClusterSpec(ref common.ReferenceCallback
 							Format:      "",
 						},
 					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the DM cluster containers read-only, which is required if the images don't contain the database Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"tolerations": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Base tolerations of DM cluster Pods, components may add more tolerations upon this respectively",
@@ -1938,11 +2452,54 @@ func schema_pkg_apis_pingcap_v1alpha1_DMClusterSpec(ref common.ReferenceCallback
 							},
 						},
 					},
+					"architecture": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Architecture is the CPU architecture of the nodes that the Pods are scheduled to, a required node affinity on the `kubernetes.io/arch` label is generated for it. Can be overrode by architecture in master spec or worker spec Optional: Defaults to no architecture constraint",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"startScriptVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartScriptVersion is the version of the start scripts, changing it causes a rolling update. Components may override it to adopt the new start scripts gradually. Optional: Defaults to v1",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"statefulSetUpdateStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StatefulSetUpdateStrategy of DM cluster StatefulSets",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"podManagementPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodManagementPolicy of DM cluster StatefulSets",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"featureGates": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FeatureGates enables or disables the experimental behaviors of the operator for this cluster, the gates set here take precedence over the operator-wide settings, so that a feature can be canaried on a single cluster. Unknown gates are ignored.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"boolean"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMDiscoverySpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSCluster", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WorkerSpec", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMDiscoverySpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSCluster", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WorkerSpec", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration"},
 	}
 }
 
@@ -2009,413 +2566,372 @@ func schema_pkg_apis_pingcap_v1alpha1_DMExperimental(ref common.ReferenceCallbac
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_DashboardConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_DMSubTaskStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "DashboardConfig is the configuration for tidb-dashboard.",
+				Description: "DMSubTaskStatus is the status of the migration of a DM task from one source",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"tidb-cacert-path": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"tidb-cert-path": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"tidb-key-path": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"public-path-prefix": {
+					"sourceName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "SourceName is the name of the source",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"internal-proxy": {
+					"workerName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "WorkerName is the name of the DM-worker the subtask runs on",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"enable-telemetry": {
+					"stage": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When enabled, usage data will be sent to PingCAP for improving user experience. Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "Stage is one of New, Running, Paused, Stopped and Finished",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"enable-experimental": {
+					"unit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When enabled, experimental TiDB Dashboard features will be available. These features are incomplete or not well tested. Suggest not to enable in production. Optional: Defaults to false",
-							Type:        []string{"boolean"},
+							Description: "Unit is the processing unit, one of dump, load and sync",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 				},
+				Required: []string{"sourceName"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_DiscoverySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_DMTask(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "DiscoverySpec contains details of Discovery members",
+				Description: "DMTask manages a migration task of a DMCluster and the sources of the task declaratively through the OpenAPI of the DM-master, which must be enabled with `openapi = true` in the config.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"limits": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"requests": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spec defines the desired state of the task",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTaskSpec"),
 						},
 					},
 				},
+				Required: []string{"spec"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTaskSpec"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_DumplingConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_DMTaskList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "DumplingConfig contains config for dumpling",
+				Description: "DMTaskList is DMTask list",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"options": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Options means options for backup data to remote storage with dumpling.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"tableFilter": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated. Please use `Spec.TableFilter` instead. TableFilter means Table filter expression for 'db.table' matching",
-							Type:        []string{"array"},
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTask"),
 									},
 								},
 							},
 						},
 					},
 				},
+				Required: []string{"items"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTask"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_Experimental(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_DMTaskSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Experimental controls the features that are still experimental: their semantics, interfaces are subject to change. Using these features in the production environment is not recommended.",
+				Description: "DMTaskSource is an upstream MySQL or MariaDB of a DM task",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"allow-auto-random": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether enable the syntax like `auto_random(3)` on the primary key column. Imported from TiDB v3.1.0. Deprecated in TiDB v4.0.3, please check detail in https://docs.pingcap.com/tidb/dev/release-4.0.3#improvements.",
-							Type:        []string{"boolean"},
+							Description: "Name is the name of the source",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"allow-expression-index": {
+					"host": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether enable creating expression index.",
-							Type:        []string{"boolean"},
+							Description: "Host is the host of the upstream database",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_ExternalConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "ExternalConfig represents the external config.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"endpoint": {
-						SchemaProps: spec.SchemaProps{
-							Description: "ExternalEndpoint makes the auto-scaler controller able to query the external service to fetch the recommended replicas for TiKV/TiDB",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalEndpoint"),
-						},
-					},
-					"maxReplicas": {
+					"port": {
 						SchemaProps: spec.SchemaProps{
-							Description: "maxReplicas is the upper limit for the number of replicas to which the autoscaler can scale out.",
+							Description: "Port is the port of the upstream database",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-				},
-				Required: []string{"maxReplicas"},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalEndpoint"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_ExternalEndpoint(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "ExternalEndpoint describes the external service endpoint which provides the ability to get the tikv/tidb auto-scaling recommended replicas",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"host": {
+					"user": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Host indicates the external service's host",
+							Description: "User is the user to connect to the upstream database",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"port": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Port indicates the external service's port",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"path": {
+					"secretName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Path indicates the external service's path",
+							Description: "SecretName is the name of the Secret that contains the password of the user in the key password",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"tlsSecret": {
+					"enableGTID": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TLSSecret indicates the Secret which stores the TLS configuration. If set, the operator will use https to communicate to the external service",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretRef"),
+							Description: "EnableGTID enables the GTID based replication of the source",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
 				},
-				Required: []string{"host", "port", "path"},
+				Required: []string{"name", "host", "port", "user"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretRef"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_FileLogConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_DMTaskSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "DMTaskSpec describes a DM migration task and its sources",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"filename": {
+					"cluster": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log filename, leave empty to disable file log.",
+							Description: "Cluster is the name of the DMCluster in the same namespace to run the task",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"log-rotate": {
+					"taskName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated in v4.0.0 Is log rotate enabled.",
-							Type:        []string{"boolean"},
+							Description: "TaskName is the name of the task in the DMCluster Optional: Defaults to the name of the DMTask",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"max-size": {
+					"sources": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Max size for a single file, in MB.",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Sources are the sources of the task, they are created if they don't exist in the DMCluster and updated once their configs or passwords change. The sources removed from the spec are deleted, but they are not deleted with the DMTask as they may be shared by other tasks",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTaskSource"),
+									},
+								},
+							},
 						},
 					},
-					"max-days": {
+					"config": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Max log keep days, default is never deleting.",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Config is the config of the task in the JSON format of the Task in the OpenAPI of DM-master, the name in it is set to the task name. Changes of the config are applied by stopping the task, updating it and starting it again.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"max-backups": {
+					"stage": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Maximum number of old log files to retain.",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Stage is the desired stage of the task, one of `Running`, `Paused` and `Stopped`. A paused task resumes from its checkpoints once running again, while a stopped task is deleted from the DMCluster with its checkpoints, and it starts over once running again. The task is deleted from the DMCluster when the DMTask is deleted too. Optional: Defaults to Running",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
+				Required: []string{"cluster", "config"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMTaskSource"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_Flash(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_DMTaskStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Flash is the configuration of [flash] section.",
+				Description: "DMTaskStatus is the status of a DMTask",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"overlap_threshold": {
+					"observedGeneration": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 0.6",
-							Type:        []string{"number"},
-							Format:      "double",
+							Description: "ObservedGeneration is the generation of the DMTask last synced",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"compact_log_min_period": {
+					"stage": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 200",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Stage is the observed stage of the task",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"flash_cluster": {
+					"configHash": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashCluster"),
+							Description: "ConfigHash is the hash of the config of the task applied to the DMCluster",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"proxy": {
+					"subTasks": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashProxy"),
+							Description: "SubTasks are the statuses of the subtasks of the task, one for each source",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMSubTaskStatus"),
+									},
+								},
+							},
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashCluster", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashProxy"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_FlashCluster(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "FlashCluster is the configuration of [flash.flash_cluster] section.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"log": {
+					"sourceHashes": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to /data0/logs/flash_cluster_manager.log",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "SourceHashes are the hashes of the configs of the sources applied to the DMCluster, including the passwords, keyed by the names of the sources. The sources removed from the spec are deleted.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"refresh_interval": {
+					"resumeCount": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 20",
+							Description: "ResumeCount is the number of times the task paused by an error has been resumed since it last ran steadily, the task is resumed with an exponential backoff",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"update_rule_interval": {
+					"lastResumeTime": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "LastResumeTime is the time the task was last started or resumed",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
-					"master_ttl": {
+					"message": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 60",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Message describes why the task is not synced",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMSubTaskStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_FlashLogger(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_DashboardConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "FlashLogger is the configuration of [logger] section.",
+				Description: "DashboardConfig is the configuration for tidb-dashboard.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"errorlog": {
+					"tidb-cacert-path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to /data0/logs/error.log",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"size": {
+					"tidb-cert-path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 100M",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"log": {
+					"tidb-key-path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to /data0/logs/server.log",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"level": {
+					"public-path-prefix": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to information",
-							Type:        []string{"string"},
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"internal-proxy": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"enable-telemetry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When enabled, usage data will be sent to PingCAP for improving user experience. Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"count": {
+					"enable-experimental": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "When enabled, experimental TiDB Dashboard features will be available. These features are incomplete or not well tested. Suggest not to enable in production. Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
 				},
@@ -2424,63 +2940,85 @@ func schema_pkg_apis_pingcap_v1alpha1_FlashLogger(ref common.ReferenceCallback)
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_FlashProxy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_DebugArtifactsSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "FlashProxy is the configuration of [flash.proxy] section.",
+				Description: "DebugArtifactsSpec configures the capture of the debug artifacts of a component. An emptyDir volume is shared between the component and a sidecar. The component runs in the volume, so the core dumps are written to it if the `kernel.core_pattern` of the node is a relative path, e.g. the default `core`. The sidecar takes the heap profile of the component periodically and keeps the last one taken before the component becomes unavailable. The artifacts are uploaded to `${prefix}/${namespace}/${podName}/` of the storage by the sidecar, and an event with the path is recorded once the component is OOMKilled or crashes. The heap profiles are not taken if TLS is enabled for the cluster. Changing it causes a rolling update of the component.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"addr": {
+					"image": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 0.0.0.0:20170",
+							Description: "Image of the sidecar, rclone is required to upload to S3 or GCS and wget to take the heap profiles, e.g. the image of tidb-backup-manager",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"advertise-addr": {
+					"resources": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to {clusterName}-tiflash-POD_NUM.{clusterName}-tiflash-peer.{namespace}:20170",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Resources of the sidecar",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
 						},
 					},
-					"data-dir": {
+					"sizeLimit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to /data0/proxy",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "SizeLimit is the size limit of the shared volume, the Pod is evicted if the artifacts exceed it",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
 						},
 					},
-					"config": {
+					"interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to /data0/proxy.toml",
+							Description: "Interval is the interval between two heap profiles and two uploads, in the format of Go Duration. Defaults to 1m",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"log-file": {
+					"disableHeapProfile": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to /data0/logs/proxy.log",
-							Type:        []string{"string"},
+							Description: "DisableHeapProfile disables taking the heap profiles, so only the core dumps are captured",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_FlashSecurity(ref common.ReferenceCallback) common.OpenAPIDefinition {
+					"s3": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"),
+						},
+					},
+					"gcs": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider"),
+						},
+					},
+					"azblob": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider"),
+						},
+					},
+					"local": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider"),
+						},
+					},
+				},
+				Required: []string{"image"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_DiagnosticsSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "DiagnosticsSpec configures the collection of the diagnostics bundles. A bundle contains the recent events, the recent logs of the containers, the health of PD, the states of the stores and the status of the cluster. It's collected when a trigger fires or the annotation `tidb.pingcap.com/collect-diagnostics` of the cluster is set to a new value, and then uploaded to the storage by a Job.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"cert_allowed_cn": {
+					"triggers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "CertAllowedCN is the Common Name that allowed",
+							Description: "Triggers are the failure conditions on which a bundle is collected, Failover and UpgradeStuck are supported Optional: Defaults to all the triggers",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -2492,325 +3030,471 @@ func schema_pkg_apis_pingcap_v1alpha1_FlashSecurity(ref common.ReferenceCallback
 							},
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_FlashServerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "FlashServerConfig is the configuration of Proxy server.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"engine-addr": {
+					"upgradeStuckTimeout": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Default to {clusterName}-tiflash-POD_NUM.{clusterName}-tiflash-peer.{namespace}:3930",
+							Description: "UpgradeStuckTimeout is how long the upgrade of a Pod lasts before it's regarded as stuck, in the format of Go Duration. Defaults to 30m",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"status-addr": {
+					"minInterval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Default to 0.0.0.0:20292",
+							Description: "MinInterval is the minimum interval between two bundles collected by the triggers, in the format of Go Duration. Defaults to 1h",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"advertise-status-addr": {
+					"logTailLines": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Default to {clusterName}-tiflash-POD_NUM.{clusterName}-tiflash-peer.{namespace}:20292",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "LogTailLines is the number of the most recent lines of the logs collected for each container Defaults to 500",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"status-thread-pool-size": {
+					"image": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 1",
+							Description: "Image of the Job uploading the bundle, rclone is required to upload to S3 or GCS Optional: Defaults to the image of tidb-backup-manager of the operator",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"max-grpc-send-msg-len": {
+					"resources": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10485760",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Resources of the Job uploading the bundle",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
 						},
 					},
-					"grpc-compression-type": {
+					"s3": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to none",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"),
 						},
 					},
-					"grpc-concurrency": {
+					"gcs": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 4",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider"),
 						},
 					},
-					"grpc-concurrent-stream": {
+					"azblob": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 1024",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider"),
 						},
 					},
-					"grpc-memory-pool-quota": {
+					"local": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 32G",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider"),
 						},
 					},
-					"grpc-raft-conn-num": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider", "k8s.io/api/core/v1.ResourceRequirements"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_DiscoverySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DiscoverySpec contains details of Discovery members",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"limits": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"grpc-stream-initial-window-size": {
+					"requests": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2MB",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"grpc-keepalive-time": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_DumplingConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DumplingConfig contains config for dumpling",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"options": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10s",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Options means options for backup data to remote storage with dumpling.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"grpc-keepalive-timeout": {
+					"tableFilter": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 3s",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Deprecated. Please use `Spec.TableFilter` instead. TableFilter means Table filter expression for 'db.table' matching",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"concurrent-send-snap-limit": {
+					"shardCount": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 32",
+							Description: "ShardCount is the number of Jobs the export is split into, each Job exports a range of the tables sorted by name at the same snapshot, and the Backup is complete only after all of them succeed. Optional: Defaults to 1",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"concurrent-recv-snap-limit": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_Experimental(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Experimental controls the features that are still experimental: their semantics, interfaces are subject to change. Using these features in the production environment is not recommended.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"allow-auto-random": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 32",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Whether enable the syntax like `auto_random(3)` on the primary key column. Imported from TiDB v3.1.0. Deprecated in TiDB v4.0.3, please check detail in https://docs.pingcap.com/tidb/dev/release-4.0.3#improvements.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"end-point-recursion-limit": {
+					"allow-expression-index": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 1000",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Whether enable creating expression index.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"end-point-stream-channel-size": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_ExternalConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExternalConfig represents the external config.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"endpoint": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "ExternalEndpoint makes the auto-scaler controller able to query the external service to fetch the recommended replicas for TiKV/TiDB",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalEndpoint"),
 						},
 					},
-					"end-point-batch-row-limit": {
+					"maxReplicas": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "maxReplicas is the upper limit for the number of replicas to which the autoscaler can scale out.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"end-point-stream-batch-row-limit": {
+				},
+				Required: []string{"maxReplicas"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalEndpoint"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_ExternalEndpoint(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExternalEndpoint describes the external service endpoint which provides the ability to get the tikv/tidb auto-scaling recommended replicas",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"host": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Host indicates the external service's host",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"end-point-enable-batch-if-possible": {
+					"port": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Port indicates the external service's port",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"end-point-request-max-handle-duration": {
+					"path": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Path indicates the external service's path",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"snap-max-write-bytes-per-sec": {
+					"tlsSecret": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 100MB",
+							Description: "TLSSecret indicates the Secret which stores the TLS configuration. If set, the operator will use https to communicate to the external service",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretRef"),
+						},
+					},
+				},
+				Required: []string{"host", "port", "path"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretRef"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_FileLogConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"filename": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Log filename, leave empty to disable file log.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"snap-max-total-size": {
+					"log-rotate": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Deprecated in v4.0.0 Is log rotate enabled.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"stats-concurrency": {
+					"max-size": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Max size for a single file, in MB.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"heavy-load-threshold": {
+					"max-days": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Max log keep days, default is never deleting.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"heavy-load-wait-duration": {
+					"max-backups": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 60s",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Maximum number of old log files to retain.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"labels": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_Flash(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Flash is the configuration of [flash] section.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"overlap_threshold": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Optional: Defaults to 0.6",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"enable-request-batch": {
+					"compact_log_min_period": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Optional: Defaults to 200",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"request-batch-enable-cross-command": {
+					"flash_cluster": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashCluster"),
 						},
 					},
-					"request-batch-wait-duration": {
+					"proxy": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashProxy"),
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashCluster", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashProxy"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_GcsStorageProvider(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_FlashCluster(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "GcsStorageProvider represents the google cloud storage for storing backups.",
+				Description: "FlashCluster is the configuration of [flash.flash_cluster] section.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"projectId": {
+					"log": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ProjectId represents the project that organizes all your Google Cloud Platform resources",
+							Description: "Optional: Defaults to /data0/logs/flash_cluster_manager.log",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"location": {
+					"refresh_interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Location in which the gcs bucket is located.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 20",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"path": {
+					"update_rule_interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Path is the full path where the backup is saved. The format of the path must be: \"<bucket-name>/<path-to-backup-file>\"",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 10",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"bucket": {
+					"master_ttl": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Bucket in which to store the backup data.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 60",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"storageClass": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_FlashLogger(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FlashLogger is the configuration of [logger] section.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"errorlog": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageClass represents the storage class",
+							Description: "Optional: Defaults to /data0/logs/error.log",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"objectAcl": {
+					"size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ObjectAcl represents the access control list for new objects",
+							Description: "Optional: Defaults to 100M",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"bucketAcl": {
+					"log": {
 						SchemaProps: spec.SchemaProps{
-							Description: "BucketAcl represents the access control list for new buckets",
+							Description: "Optional: Defaults to /data0/logs/server.log",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"secretName": {
+					"level": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SecretName is the name of secret which stores the gcs service account credentials JSON.",
+							Description: "Optional: Defaults to information",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"prefix": {
+					"count": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Prefix of the data path.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 10",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 				},
-				Required: []string{"projectId"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_HelperSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_FlashProxy(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "HelperSpec contains details of helper component",
+				Description: "FlashProxy is the configuration of [flash.proxy] section.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"image": {
+					"addr": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Image used to tail slow log and set kernel parameters if necessary, must have `tail` and `sysctl` installed Optional: Defaults to busybox:1.26.2. Recommended to set to 1.34.1 for new installations.",
+							Description: "Optional: Defaults to 0.0.0.0:20170",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"imagePullPolicy": {
+					"advertise-addr": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to the cluster-level setting",
+							Description: "Optional: Defaults to {clusterName}-tiflash-POD_NUM.{clusterName}-tiflash-peer.{namespace}:20170",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"data-dir": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to /data0/proxy",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"config": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to /data0/proxy.toml",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"log-file": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to /data0/logs/proxy.log",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -2821,16 +3505,15 @@ func schema_pkg_apis_pingcap_v1alpha1_HelperSpec(ref common.ReferenceCallback) c
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_IngressSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_FlashSecurity(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "IngressSpec describe the ingress desired state for the target component",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"hosts": {
+					"cert_allowed_cn": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Hosts describe the hosts for the ingress",
+							Description: "CertAllowedCN is the Common Name that allowed",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -2842,411 +3525,374 @@ func schema_pkg_apis_pingcap_v1alpha1_IngressSpec(ref common.ReferenceCallback)
 							},
 						},
 					},
-					"annotations": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Annotations describe the desired annotations for the ingress",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
-						},
-					},
-					"tls": {
-						SchemaProps: spec.SchemaProps{
-							Description: "TLS configuration. Currently the Ingress only supports a single TLS port, 443. If multiple members of this list specify different hosts, they will be multiplexed on the same port according to the hostname specified through the SNI TLS extension, if the ingress controller fulfilling the ingress supports SNI.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/networking/v1.IngressTLS"),
-									},
-								},
-							},
-						},
-					},
 				},
-				Required: []string{"hosts"},
 			},
 		},
-		Dependencies: []string{
-			"k8s.io/api/networking/v1.IngressTLS"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_IsolationRead(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_FlashServerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "IsolationRead is the config for isolation read.",
+				Description: "FlashServerConfig is the configuration of Proxy server.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"engines": {
+					"engine-addr": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Engines filters tidb-server access paths by engine type. imported from v3.1.0",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Default to {clusterName}-tiflash-POD_NUM.{clusterName}-tiflash-peer.{namespace}:3930",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_Log(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "Log is the log section of config.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"level": {
+					"status-addr": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log level. Optional: Defaults to info",
+							Description: "Default to 0.0.0.0:20292",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"format": {
+					"advertise-status-addr": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log format. one of json, text, or console. Optional: Defaults to text",
+							Description: "Default to {clusterName}-tiflash-POD_NUM.{clusterName}-tiflash-peer.{namespace}:20292",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"disable-timestamp": {
+					"status-thread-pool-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated in v3.0.5. Use EnableTimestamp instead Disable automatic timestamps in output.",
-							Type:        []string{"boolean"},
+							Description: "Optional: Defaults to 1",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"enable-timestamp": {
+					"max-grpc-send-msg-len": {
 						SchemaProps: spec.SchemaProps{
-							Description: "EnableTimestamp enables automatic timestamps in log output.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Optional: Defaults to 10485760",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"enable-error-stack": {
+					"grpc-compression-type": {
 						SchemaProps: spec.SchemaProps{
-							Description: "EnableErrorStack enables annotating logs with the full stack error message.",
-							Type:        []string{"boolean"},
+							Description: "Optional: Defaults to none",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"file": {
+					"grpc-concurrency": {
 						SchemaProps: spec.SchemaProps{
-							Description: "File log config.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig"),
+							Description: "Optional: Defaults to 4",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"enable-slow-log": {
+					"grpc-concurrent-stream": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Optional: Defaults to 1024",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"slow-query-file": {
+					"grpc-memory-pool-quota": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Optional: Defaults to 32G",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"slow-threshold": {
+					"grpc-raft-conn-num": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 300",
+							Description: "Optional: Defaults to 10",
 							Type:        []string{"integer"},
-							Format:      "int64",
+							Format:      "int32",
 						},
 					},
-					"expensive-threshold": {
+					"grpc-stream-initial-window-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10000",
+							Description: "Optional: Defaults to 2MB",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"grpc-keepalive-time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 10s",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"grpc-keepalive-timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 3s",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"concurrent-send-snap-limit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 32",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"query-log-max-len": {
+					"concurrent-recv-snap-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2048",
+							Description: "Optional: Defaults to 32",
 							Type:        []string{"integer"},
-							Format:      "int64",
+							Format:      "int32",
 						},
 					},
-					"record-plan-in-slow-log": {
+					"end-point-recursion-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 1",
+							Description: "Optional: Defaults to 1000",
 							Type:        []string{"integer"},
-							Format:      "int64",
+							Format:      "int32",
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_LogTailerSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "LogTailerSpec represents an optional log tailer sidecar container",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"limits": {
+					"end-point-stream-channel-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"requests": {
+					"end-point-batch-row-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"end-point-stream-batch-row-limit": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"end-point-enable-batch-if-possible": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"end-point-request-max-handle-duration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"snap-max-write-bytes-per-sec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 100MB",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"snap-max-total-size": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"stats-concurrency": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"heavy-load-threshold": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"heavy-load-wait-duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 60s",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
+					"enable-request-batch": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"request-batch-enable-cross-command": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"request-batch-wait-duration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
 				},
 			},
 		},
-		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_MasterConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_GcsStorageProvider(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "MasterConfig is the configuration of dm-master-server",
+				Description: "GcsStorageProvider represents the google cloud storage for storing backups.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"log-level": {
+					"projectId": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log level. Optional: Defaults to info",
+							Description: "ProjectId represents the project that organizes all your Google Cloud Platform resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"log-file": {
+					"location": {
 						SchemaProps: spec.SchemaProps{
-							Description: "File log config.",
+							Description: "Location in which the gcs bucket is located.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"log-format": {
+					"path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log format. one of json or text.",
+							Description: "Path is the full path where the backup is saved. The format of the path must be: \"<bucket-name>/<path-to-backup-file>\"",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"rpc-timeout": {
+					"bucket": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RPC timeout when dm-master request to dm-worker Optional: Defaults to 30s",
+							Description: "Bucket in which to store the backup data.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"rpc-rate-limit": {
+					"storageClass": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RPC agent rate limit when dm-master request to dm-worker Optional: Defaults to 10",
-							Type:        []string{"number"},
-							Format:      "double",
+							Description: "StorageClass represents the storage class",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"rpc-rate-burst": {
+					"objectAcl": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RPC agent rate burst when dm-master request to dm-worker Optional: Defaults to 40",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "ObjectAcl represents the access control list for new objects",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"experimental": {
+					"bucketAcl": {
 						SchemaProps: spec.SchemaProps{
-							Description: "dm-master's experimental config",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMExperimental"),
+							Description: "BucketAcl represents the access control list for new buckets",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMExperimental"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_MasterKeyFileConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"method": {
+					"secretName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Encrypyion method, use master key encryption data key Possible values: plaintext, aes128-ctr, aes192-ctr, aes256-ctr Optional: Default to plaintext optional",
+							Description: "SecretName is the name of secret which stores the gcs service account credentials JSON.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"path": {
+					"prefix": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Text file containing the key in hex form, end with '\n'",
+							Description: "Prefix of the data path.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 				},
-				Required: []string{"path"},
+				Required: []string{"projectId"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_MasterKeyKMSConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_HelperSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "HelperSpec contains details of helper component",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"key-id": {
-						SchemaProps: spec.SchemaProps{
-							Description: "AWS CMK key-id it can be find in AWS Console or use aws cli This field is required",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"access-key": {
-						SchemaProps: spec.SchemaProps{
-							Description: "AccessKey of AWS user, leave empty if using other authrization method optional",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"secret-access-key": {
+					"image": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SecretKey of AWS user, leave empty if using other authrization method optional",
+							Description: "Image used to tail slow log and set kernel parameters if necessary, must have `tail` and `sysctl` installed Optional: Defaults to busybox:1.26.2. Recommended to set to 1.34.1 for new installations.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"region": {
+					"imagePullPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Region of this KMS key Optional: Default to us-east-1 optional",
+							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to the cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"endpoint": {
+					"digest": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Used for KMS compatible KMS, such as Ceph, minio, If use AWS, leave empty optional",
+							Description: "Digest pins the helper image to the digest, e.g. `sha256:<hex>`, so that the image pulled is verified by the container runtime. It replaces the digest in the image if there is one.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 				},
-				Required: []string{"key-id"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_MasterSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_ImportWindowSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "MasterSpec contains details of dm-master members",
+				Description: "ImportWindowSpec contains the items tuned while data is imported into the cluster",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"version": {
+					"pdSchedule": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"imagePullPolicy": {
-						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"imagePullSecrets": {
-						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
+							Description: "PDSchedule overrides the schedule config items of PD, e.g. `region-schedule-limit: \"0\"`",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"hostNetwork": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
-					"affinity": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
-							Ref:         ref("k8s.io/api/core/v1.Affinity"),
-						},
-					},
-					"priorityClassName": {
-						SchemaProps: spec.SchemaProps{
-							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"schedulerName": {
+					"storeLimit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "StoreLimit is the add-peer and remove-peer limit of all the TiKV and TiFlash stores, in operators per minute",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"nodeSelector": {
+					"tikvConfig": {
 						SchemaProps: spec.SchemaProps{
-							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
+							Description: "TiKVConfig overrides the config items of TiKV online, the keys are dotted paths, e.g. `import.num-threads: \"16\"`",
 							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
@@ -3259,12 +3905,24 @@ func schema_pkg_apis_pingcap_v1alpha1_MasterSpec(ref common.ReferenceCallback) c
 							},
 						},
 					},
-					"annotations": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_IngressSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "IngressSpec describe the ingress desired state for the target component",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"hosts": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "Hosts describe the hosts for the ingress",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Type:   []string{"string"},
@@ -3274,9 +3932,9 @@ func schema_pkg_apis_pingcap_v1alpha1_MasterSpec(ref common.ReferenceCallback) c
 							},
 						},
 					},
-					"labels": {
+					"annotations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Description: "Annotations describe the desired annotations for the ingress",
 							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
@@ -3289,235 +3947,213 @@ func schema_pkg_apis_pingcap_v1alpha1_MasterSpec(ref common.ReferenceCallback) c
 							},
 						},
 					},
-					"tolerations": {
+					"tls": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
+							Description: "TLS configuration. Currently the Ingress only supports a single TLS port, 443. If multiple members of this list specify different hosts, they will be multiplexed on the same port according to the hostname specified through the SNI TLS extension, if the ingress controller fulfilling the ingress supports SNI.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Toleration"),
+										Ref: ref("k8s.io/api/networking/v1.IngressTLS"),
 									},
 								},
 							},
 						},
 					},
-					"podSecurityContext": {
+				},
+				Required: []string{"hosts"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/networking/v1.IngressTLS"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_InitSqlStorageSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "InitSqlStorageSpec describes the SQL scripts downloaded from the object storage by the initializer. The scripts may contain statements spanning multiple lines, each statement must be terminated by a `;` at the end of a line.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"s3": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodSecurityContext of the component",
-							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"),
 						},
 					},
-					"configUpdateStrategy": {
+					"gcs": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider"),
 						},
 					},
-					"env": {
+					"azblob": {
 						SchemaProps: spec.SchemaProps{
-							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.EnvVar"),
-									},
-								},
-							},
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider"),
 						},
 					},
-					"initContainers": {
+					"local": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Init containers of the components",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
-									},
-								},
-							},
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider"),
 						},
 					},
-					"additionalContainers": {
+					"image": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional containers of the component.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
-									},
-								},
-							},
+							Description: "Image is the image of the init container downloading the scripts, which provides rclone and generates its config from the env of the storage, e.g. the tidb-backup-manager image",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"additionalVolumes": {
+					"files": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volumes of component pod.",
+							Description: "Files are the paths of the scripts relative to the prefix of the storage, executed in the order listed. If empty, all the files with the .sql suffix under the prefix are executed in the lexical order of their paths.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Volume"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"additionalVolumeMounts": {
+				},
+				Required: []string{"image"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_IsolationRead(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "IsolationRead is the config for isolation read.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"engines": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volume mounts of component pod.",
+							Description: "Engines filters tidb-server access paths by engine type. imported from v3.1.0",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"terminationGracePeriodSeconds": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"statefulSetUpdateStrategy": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_Log(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Log is the log section of config.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"level": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Description: "Log level. Optional: Defaults to info",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"podManagementPolicy": {
+					"format": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
+							Description: "Log format. one of json, text, or console. Optional: Defaults to text",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"topologySpreadConstraints": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-map-keys": []interface{}{
-									"topologyKey",
-								},
-								"x-kubernetes-list-type": "map",
-							},
-						},
+					"disable-timestamp": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
-									},
-								},
-							},
+							Description: "Deprecated in v3.0.5. Use EnableTimestamp instead Disable automatic timestamps in output.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"limits": {
+					"enable-timestamp": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "EnableTimestamp enables automatic timestamps in log output.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"requests": {
+					"enable-error-stack": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "EnableErrorStack enables annotating logs with the full stack error message.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"replicas": {
+					"file": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The desired ready replicas",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "File log config.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig"),
 						},
 					},
-					"baseImage": {
+					"enable-slow-log": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Base image of the component, image tag is now allowed during validation",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"service": {
+					"slow-query-file": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Service defines a Kubernetes service of Master cluster. Optional: Defaults to `.spec.services` in favor of backward compatibility",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterServiceSpec"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"maxFailoverCount": {
+					"slow-threshold": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover. Optional: Defaults to 3",
+							Description: "Optional: Defaults to 300",
 							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"storageClassName": {
-						SchemaProps: spec.SchemaProps{
-							Description: "The storageClassName of the persistent volume for dm-master data storage. Defaults to Kubernetes default storage class.",
-							Type:        []string{"string"},
-							Format:      "",
+							Format:      "int64",
 						},
 					},
-					"storageSize": {
+					"expensive-threshold": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageSize is the request storage size for dm-master. Defaults to \"10Gi\".",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 10000",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"dataSubDir": {
+					"query-log-max-len": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Subdirectory within the volume to store dm-master Data. By default, the data is stored in the root directory of volume which is mounted at /var/lib/dm-master. Specifying this will change the data directory to a subdirectory, e.g. /var/lib/dm-master/data if you set the value to \"data\". It's dangerous to change this value for a running cluster as it will upgrade your cluster to use a new storage directory. Defaults to \"\" (volume's root).",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 2048",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"config": {
+					"record-plan-in-slow-log": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Config is the Configuration of dm-master-servers",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterConfig"),
+							Description: "Optional: Defaults to 1",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
 				},
-				Required: []string{"replicas"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_MonitorContainer(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_LogTailerSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "MonitorContainer is the common attributes of the container of monitoring",
+				Description: "LogTailerSpec represents an optional log tailer sidecar container",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"limits": {
@@ -3548,24 +4184,6 @@ func schema_pkg_apis_pingcap_v1alpha1_MonitorContainer(ref common.ReferenceCallb
 							},
 						},
 					},
-					"baseImage": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"version": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"imagePullPolicy": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
 				},
 			},
 		},
@@ -3574,993 +4192,1003 @@ func schema_pkg_apis_pingcap_v1alpha1_MonitorContainer(ref common.ReferenceCallb
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_NGMonitoringSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_MaintenanceSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "NGMonitoringSpec is spec of ng monitoring",
+				Description: "MaintenanceSpec contains the periodic maintenance tasks of the cluster",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"version": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"imagePullPolicy": {
-						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"imagePullSecrets": {
+					"tasks": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
+							Description: "Tasks are the periodic maintenance tasks, each task is run as a Job at the time of the schedule. A task is postponed while the cluster is upgrading, and the running Job is deleted when an upgrade begins, so that the maintenance never runs concurrently with the rolling restarts.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MaintenanceTask"),
 									},
 								},
 							},
 						},
 					},
-					"hostNetwork": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MaintenanceTask"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_MaintenanceTask(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MaintenanceTask is a periodic maintenance task, exactly one of compactTiKV, analyzeTable and resetStoreLimit must be set",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"boolean"},
+							Description: "Name is the unique name of the task in the cluster",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"affinity": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
-							Ref:         ref("k8s.io/api/core/v1.Affinity"),
-						},
-					},
-					"priorityClassName": {
+					"schedule": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Description: "Schedule is the schedule of the task in the Cron format",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"schedulerName": {
+					"suspend": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
+							Description: "Suspend stops scheduling the task, the running Job is not affected",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"nodeSelector": {
+					"window": {
 						SchemaProps: spec.SchemaProps{
-							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Window is the length of the maintenance window beginning at the scheduled time. A run that can't start within the window, e.g. as the cluster is upgrading, is skipped, and the Job is stopped at the end of the window. Optional: Defaults to unlimited",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
 						},
 					},
-					"annotations": {
+					"compactTiKV": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "CompactTiKV compacts the data of all the TiKV stores by tikv-ctl",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CompactTiKVTask"),
 						},
 					},
-					"labels": {
+					"analyzeTable": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "AnalyzeTable runs ANALYZE TABLE on the tables by the mysql client",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AnalyzeTableTask"),
 						},
 					},
-					"tolerations": {
+					"resetStoreLimit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Toleration"),
-									},
-								},
-							},
+							Description: "ResetStoreLimit resets the store limit of all the stores by pd-ctl",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ResetStoreLimitTask"),
 						},
 					},
-					"podSecurityContext": {
+					"resources": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodSecurityContext of the component",
-							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+							Description: "Resources of the Job",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
 						},
 					},
-					"configUpdateStrategy": {
-						SchemaProps: spec.SchemaProps{
-							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
+				},
+				Required: []string{"name", "schedule"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AnalyzeTableTask", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CompactTiKVTask", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ResetStoreLimitTask", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_MasterConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MasterConfig is the configuration of dm-master-server",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"log-level": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Log level. Optional: Defaults to info",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"env": {
-						SchemaProps: spec.SchemaProps{
-							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.EnvVar"),
-									},
-								},
-							},
-						},
-					},
-					"initContainers": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Init containers of the components",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
-									},
-								},
-							},
-						},
-					},
-					"additionalContainers": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Additional containers of the component.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
-									},
-								},
-							},
-						},
-					},
-					"additionalVolumes": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Additional volumes of component pod.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Volume"),
-									},
-								},
-							},
-						},
-					},
-					"additionalVolumeMounts": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Additional volume mounts of component pod.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
-									},
-								},
-							},
-						},
-					},
-					"terminationGracePeriodSeconds": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"statefulSetUpdateStrategy": {
+					"log-file": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Description: "File log config.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"podManagementPolicy": {
+					"log-format": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
+							Description: "Log format. one of json or text.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"topologySpreadConstraints": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-map-keys": []interface{}{
-									"topologyKey",
-								},
-								"x-kubernetes-list-type": "map",
-							},
-						},
-						SchemaProps: spec.SchemaProps{
-							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
-									},
-								},
-							},
-						},
-					},
-					"limits": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
-						},
-					},
-					"requests": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
-						},
-					},
-					"baseImage": {
+					"rpc-timeout": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Base image of the component, image tag is now allowed during validation",
+							Description: "RPC timeout when dm-master request to dm-worker Optional: Defaults to 30s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"storageClassName": {
+					"rpc-rate-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageClassName is the persistent volume for ng monitoring. Defaults to Kubernetes default storage class.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "RPC agent rate limit when dm-master request to dm-worker Optional: Defaults to 10",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"storageVolumes": {
+					"rpc-rate-burst": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageVolumes configures additional storage for NG Monitoring pods.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
-									},
-								},
-							},
+							Description: "RPC agent rate burst when dm-master request to dm-worker Optional: Defaults to 40",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"config": {
+					"experimental": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Config is the configuration of ng monitoring",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/util/config.GenericConfig"),
+							Description: "dm-master's experimental config",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMExperimental"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "github.com/pingcap/tidb-operator/pkg/apis/util/config.GenericConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMExperimental"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_OpenTracing(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_MasterKeyFileConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "OpenTracing is the opentracing section of the config.",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"enable": {
+					"method": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to false",
-							Type:        []string{"boolean"},
+							Description: "Encrypyion method, use master key encryption data key Possible values: plaintext, aes128-ctr, aes192-ctr, aes256-ctr Optional: Default to plaintext optional",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"sampler": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingSampler"),
-						},
-					},
-					"reporter": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingReporter"),
-						},
-					},
-					"rpc-metrics": {
+					"path": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Text file containing the key in hex form, end with '\n'",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
+				Required: []string{"path"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingReporter", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingSampler"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_OpenTracingReporter(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_MasterKeyKMSConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "OpenTracingReporter is the config for opentracing reporter. See https://godoc.org/github.com/uber/jaeger-client-go/config#ReporterConfig",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"queue-size": {
+					"key-id": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "AWS CMK key-id it can be find in AWS Console or use aws cli This field is required",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"buffer-flush-interval": {
+					"access-key": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "AccessKey of AWS user, leave empty if using other authrization method optional",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"log-spans": {
+					"secret-access-key": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "SecretKey of AWS user, leave empty if using other authrization method optional",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"local-agent-host-port": {
+					"region": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Region of this KMS key Optional: Default to us-east-1 optional",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"endpoint": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Used for KMS compatible KMS, such as Ceph, minio, If use AWS, leave empty optional",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
+				Required: []string{"key-id"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_OpenTracingSampler(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_MasterSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "OpenTracingSampler is the config for opentracing sampler. See https://godoc.org/github.com/uber/jaeger-client-go/config#SamplerConfig",
+				Description: "MasterSpec contains details of dm-master members",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"type": {
+					"version": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"param": {
+					"imagePullPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"number"},
-							Format: "double",
+							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"sampling-server-url": {
+					"imagePullSecrets": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
 						},
 					},
-					"max-operations": {
+					"hostNetwork": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"sampling-refresh-interval": {
+					"affinity": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_PDConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "PDConfig is the configuration of pd-server",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"force-new-cluster": {
+					"priorityClassName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"enable-grpc-gateway": {
+					"schedulerName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"initial-cluster-token": {
+					"nodeSelector": {
 						SchemaProps: spec.SchemaProps{
-							Description: "set different tokens to prevent communication between PDs in different clusters.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"lease": {
+					"annotations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LeaderLease time, if leader doesn't update its TTL in etcd after lease time, etcd will expire the leader key and other servers can campaign the leader again. Etcd only supports seconds TTL, so here is second too. Optional: Defaults to 3",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"log": {
+					"labels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log related config.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDLogConfig"),
+							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"log-file": {
+					"tolerations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Backward compatibility.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
 						},
 					},
-					"log-level": {
+					"podSecurityContext": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "PodSecurityContext of the component",
+							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
 						},
 					},
-					"tso-save-interval": {
+					"configUpdateStrategy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TsoSaveInterval is the interval to save timestamp. Optional: Defaults to 3s",
+							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metric": {
+					"env": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDMetricConfig"),
+							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
 						},
 					},
-					"schedule": {
+					"initContainers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Immutable, change should be made through pd-ctl after cluster creation",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDScheduleConfig"),
+							Description: "Init containers of the components",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
 						},
 					},
-					"replication": {
+					"additionalContainers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Immutable, change should be made through pd-ctl after cluster creation",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDReplicationConfig"),
+							Description: "Additional containers of the component.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
 						},
 					},
-					"namespace": {
+					"additionalVolumes": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "Additional volumes of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDNamespaceConfig"),
+										Ref: ref("k8s.io/api/core/v1.Volume"),
 									},
 								},
 							},
 						},
 					},
-					"pd-server": {
+					"additionalVolumeMounts": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDServerConfig"),
+							Description: "Additional volume mounts of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
 						},
 					},
-					"cluster-version": {
+					"terminationGracePeriodSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"quota-backend-bytes": {
+					"statefulSetUpdateStrategy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "QuotaBackendBytes Raise alarms when backend size exceeds the given quota. 0 means use the default quota. the default size is 2GB, the maximum is 8GB.",
+							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"auto-compaction-mode": {
+					"podManagementPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "AutoCompactionMode is either 'periodic' or 'revision'. The default value is 'periodic'.",
+							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"auto-compaction-retention-v2": {
+					"minReadySeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "AutoCompactionRetention is either duration string with time unit (e.g. '5m' for 5-minute), or revision unit (e.g. '5000'). If no time unit is provided and compaction mode is 'periodic', the unit defaults to hour. For example, '5' translates into 5-hour. The default retention is 1 hour. Before etcd v3.3.x, the type of retention is int. We add 'v2' suffix to make it backward compatible.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"tikv-interval": {
+					"rollingUpdateIntervalSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TickInterval is the interval for etcd Raft tick.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"election-interval": {
+					"topologySpreadConstraints": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"topologyKey",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
-							Description: "ElectionInterval is the interval for etcd Raft election.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+									},
+								},
+							},
 						},
 					},
-					"enable-prevote": {
+					"architecture": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Prevote is true to enable Raft Pre-Vote. If enabled, Raft runs an additional election phase to check whether it would get enough votes to win an election, thus minimizing disruptions. Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"security": {
+					"archBaseImages": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSecurityConfig"),
-						},
-					},
-					"label-property": {
-						SchemaProps: spec.SchemaProps{
-							Type: []string{"object"},
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Type: []string{"array"},
-										Items: &spec.SchemaOrArray{
-											Schema: &spec.Schema{
-												SchemaProps: spec.SchemaProps{
-													Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDStoreLabel"),
-												},
-											},
-										},
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"namespace-classifier": {
+					"startScriptVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated in v4.0.0 NamespaceClassifier is for classifying stores/regions into different namespaces. Optional: Defaults to true",
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"dashboard": {
+					"helper": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DashboardConfig"),
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DashboardConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDLogConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDMetricConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDNamespaceConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDReplicationConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDScheduleConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSecurityConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDServerConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDStoreLabel"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_PDLogConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "PDLogConfig serializes log related config in toml/json.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"level": {
+					"timezone": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log level. Optional: Defaults to info",
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"format": {
+					"mountTimezoneData": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log format. one of json, text, or console.",
-							Type:        []string{"string"},
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"disable-timestamp": {
+					"limits": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Disable automatic timestamps in output.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"file": {
+					"requests": {
 						SchemaProps: spec.SchemaProps{
-							Description: "File log config.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig"),
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"development": {
+					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Development puts the logger in development mode, which changes the behavior of DPanicLevel and takes stacktraces more liberally.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "The desired ready replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"disable-caller": {
+					"baseImage": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableCaller stops annotating logs with the calling function's file name and line number. By default, all logs are annotated.",
-							Type:        []string{"boolean"},
+							Description: "Base image of the component, image tag is now allowed during validation",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"disable-stacktrace": {
+					"service": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableStacktrace completely disables automatic stacktrace capturing. By default, stacktraces are captured for WarnLevel and above logs in development and ErrorLevel and above in production.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Service defines a Kubernetes service of Master cluster. Optional: Defaults to `.spec.services` in favor of backward compatibility",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterServiceSpec"),
 						},
 					},
-					"disable-error-verbose": {
+					"maxFailoverCount": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableErrorVerbose stops annotating logs with the full verbose error message.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover. Optional: Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_PDMetricConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"job": {
+					"storageClassName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "The storageClassName of the persistent volume for dm-master data storage. Defaults to Kubernetes default storage class.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"address": {
+					"storageSize": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "StorageSize is the request storage size for dm-master. Defaults to \"10Gi\".",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"interval": {
+					"storageVolumes": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "StorageVolumes configure additional storage for dm-master pods.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
+									},
+								},
+							},
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_PDNamespaceConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "PDNamespaceConfig is to overwrite the global setting for specific namespace",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"leader-schedule-limit": {
+					"dataSubDir": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LeaderScheduleLimit is the max coexist leader schedules.",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Subdirectory within the volume to store dm-master Data. By default, the data is stored in the root directory of volume which is mounted at /var/lib/dm-master. Specifying this will change the data directory to a subdirectory, e.g. /var/lib/dm-master/data if you set the value to \"data\". It's dangerous to change this value for a running cluster as it will upgrade your cluster to use a new storage directory. Defaults to \"\" (volume's root).",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"region-schedule-limit": {
+					"config": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RegionScheduleLimit is the max coexist region schedules.",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Config is the Configuration of dm-master-servers",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterConfig"),
 						},
 					},
-					"replica-schedule-limit": {
+					"podDisruptionBudget": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ReplicaScheduleLimit is the max coexist replica schedules.",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "PodDisruptionBudget configures the PodDisruptionBudget of the dm-master pods. No PodDisruptionBudget is created if it's not set.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec"),
 						},
 					},
-					"merge-schedule-limit": {
+					"port": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MergeScheduleLimit is the max coexist merge schedules.",
+							Description: "Port is the port that dm-master serves the client requests on, it's used by the services and dm-workers. It can't be changed for an existing cluster. Optional: Defaults to 8261",
 							Type:        []string{"integer"},
-							Format:      "int64",
+							Format:      "int32",
 						},
 					},
-					"hot-region-schedule-limit": {
+					"peerPort": {
 						SchemaProps: spec.SchemaProps{
-							Description: "HotRegionScheduleLimit is the max coexist hot region schedules.",
+							Description: "PeerPort is the port that dm-master members communicate with each other on. It can't be changed for an existing cluster. Optional: Defaults to 8291",
 							Type:        []string{"integer"},
-							Format:      "int64",
+							Format:      "int32",
 						},
 					},
-					"max-replicas": {
+					"externalMembers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxReplicas is the number of replicas for each region.",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "ExternalMembers are the client URLs of the dm-masters deployed in other Kubernetes clusters, e.g. `http://demo-dm-master-0.demo-dm-master-peer.dm.svc.cluster-a.local:8261`. If configured, the dm-masters of this DMCluster join them instead of bootstrapping a new cluster, so that the dm-masters of the DMClusters form a single raft group. The names of the DMClusters must be different, as the names of the members are the names of the Pods.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
 				},
+				Required: []string{"replicas"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MasterServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_PDReplicationConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_MonitorContainer(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PDReplicationConfig is the replication configuration.",
+				Description: "MonitorContainer is the common attributes of the container of monitoring",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"max-replicas": {
+					"limits": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxReplicas is the number of replicas for each region. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 3",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"strictly-match-label": {
+					"requests": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StrictlyMatchLabel strictly checks if the label of TiKV is matched with LocaltionLabels. Immutable, change should be made through pd-ctl after cluster creation. Imported from v3.1.0",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"enable-placement-rules": {
+					"baseImage": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When PlacementRules feature is enabled. MaxReplicas and LocationLabels are not used anymore.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"imagePullPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_PDScheduleConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_NGMonitoringSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ScheduleConfig is the schedule configuration.",
+				Description: "NGMonitoringSpec is spec of ng monitoring",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"max-snapshot-count": {
+					"version": {
 						SchemaProps: spec.SchemaProps{
-							Description: "If the snapshot count of one store is greater than this value, it will never be used as a source or target store. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 3",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"max-pending-peer-count": {
+					"imagePullPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 16",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"max-merge-region-size": {
+					"imagePullSecrets": {
 						SchemaProps: spec.SchemaProps{
-							Description: "If both the size of region is smaller than MaxMergeRegionSize and the number of rows in region is smaller than MaxMergeRegionKeys, it will try to merge with adjacent regions. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 20",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
 						},
 					},
-					"max-merge-region-keys": {
+					"hostNetwork": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 200000",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"split-merge-interval": {
+					"affinity": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SplitMergeInterval is the minimum interval time to permit merge after split. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 1h",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
 						},
 					},
-					"patrol-region-interval": {
+					"priorityClassName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PatrolRegionInterval is the interval for scanning region during patrol. Immutable, change should be made through pd-ctl after cluster creation",
+							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"max-store-down-time": {
+					"schedulerName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxStoreDownTime is the max duration after which a store will be considered to be down if it hasn't reported heartbeats. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 30m",
+							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"leader-schedule-limit": {
+					"nodeSelector": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LeaderScheduleLimit is the max coexist leader schedules. Immutable, change should be made through pd-ctl after cluster creation. Optional: Defaults to 4. Imported from v3.1.0",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"region-schedule-limit": {
+					"annotations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RegionScheduleLimit is the max coexist region schedules. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 2048",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"replica-schedule-limit": {
+					"labels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ReplicaScheduleLimit is the max coexist replica schedules. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 64",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"merge-schedule-limit": {
+					"tolerations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MergeScheduleLimit is the max coexist merge schedules. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 8",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
 						},
 					},
-					"hot-region-schedule-limit": {
+					"podSecurityContext": {
 						SchemaProps: spec.SchemaProps{
-							Description: "HotRegionScheduleLimit is the max coexist hot region schedules. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 4",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "PodSecurityContext of the component",
+							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
 						},
 					},
-					"hot-region-cache-hits-threshold": {
+					"configUpdateStrategy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "HotRegionCacheHitThreshold is the cache hits threshold of the hot region. If the number of times a region hits the hot cache is greater than this threshold, it is considered a hot region. Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"tolerant-size-ratio": {
+					"env": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TolerantSizeRatio is the ratio of buffer size for balance scheduler. Immutable, change should be made through pd-ctl after cluster creation. Imported from v3.1.0",
-							Type:        []string{"number"},
-							Format:      "double",
+							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
 						},
 					},
-					"low-space-ratio": {
+					"initContainers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "\n     high space stage         transition stage           low space stage\n  |--------------------|-----------------------------|-------------------------|\n  ^                    ^                             ^                         ^\n  0       HighSpaceRatio * capacity       LowSpaceRatio * capacity          capacity\n\nLowSpaceRatio is the lowest usage ratio of store which regraded as low space. When in low space, store region score increases to very large and varies inversely with available size. Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"number"},
-							Format:      "double",
+							Description: "Init containers of the components",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
 						},
 					},
-					"high-space-ratio": {
+					"additionalContainers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "HighSpaceRatio is the highest usage ratio of store which regraded as high space. High space means there is a lot of spare capacity, and store region score varies directly with used size. Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"number"},
-							Format:      "double",
+							Description: "Additional containers of the component.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
 						},
 					},
-					"disable-raft-learner": {
+					"additionalVolumes": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableLearner is the option to disable using AddLearnerNode instead of AddNode Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Additional volumes of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Volume"),
+									},
+								},
+							},
 						},
 					},
-					"disable-remove-down-replica": {
+					"additionalVolumeMounts": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableRemoveDownReplica is the option to prevent replica checker from removing down replicas. Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Additional volume mounts of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
 						},
 					},
-					"disable-replace-offline-replica": {
+					"terminationGracePeriodSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableReplaceOfflineReplica is the option to prevent replica checker from repalcing offline replicas. Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"disable-make-up-replica": {
+					"statefulSetUpdateStrategy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableMakeUpReplica is the option to prevent replica checker from making up replicas when replica count is less than expected. Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"boolean"},
+							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"disable-remove-extra-replica": {
+					"podManagementPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableRemoveExtraReplica is the option to prevent replica checker from removing extra replicas. Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"boolean"},
+							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"disable-location-replacement": {
+					"minReadySeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableLocationReplacement is the option to prevent replica checker from moving replica to a better location. Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"disable-namespace-relocation": {
+					"rollingUpdateIntervalSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableNamespaceRelocation is the option to prevent namespace checker from moving replica to the target namespace. Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"schedulers-v2": {
+					"topologySpreadConstraints": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"topologyKey",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
-							Description: "Schedulers support for loding customized schedulers Immutable, change should be made through pd-ctl after cluster creation",
+							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSchedulerConfig"),
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
 									},
 								},
 							},
 						},
 					},
-					"schedulers-payload": {
+					"architecture": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Only used to display",
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archBaseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
 							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
@@ -4573,45 +5201,132 @@ func schema_pkg_apis_pingcap_v1alpha1_PDScheduleConfig(ref common.ReferenceCallb
 							},
 						},
 					},
-					"enable-one-way-merge": {
+					"startScriptVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "EnableOneWayMerge is the option to enable one way merge. This means a Region can only be merged into the next region of it. Imported from v3.1.0",
-							Type:        []string{"boolean"},
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"enable-cross-table-merge": {
+					"helper": {
 						SchemaProps: spec.SchemaProps{
-							Description: "EnableCrossTableMerge is the option to enable cross table merge. This means two Regions can be merged with different table IDs. This option only works when key type is \"table\". Imported from v3.1.0",
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
+					"limits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"requests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"baseImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Base image of the component, image tag is now allowed during validation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"storageClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageClassName is the persistent volume for ng monitoring. Defaults to Kubernetes default storage class.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"storageVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageVolumes configures additional storage for NG Monitoring pods.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
+									},
+								},
+							},
+						},
+					},
+					"config": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Config is the configuration of ng monitoring",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/util/config.GenericConfig"),
+						},
+					},
+					"topSQL": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TopSQL configures the Top SQL data collected from the target cluster. The settings override the same items in Config.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopSQLSpec"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSchedulerConfig"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopSQLSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "github.com/pingcap/tidb-operator/pkg/apis/util/config.GenericConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_PDSchedulerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_NotificationSink(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PDSchedulerConfig is customized scheduler configuration",
+				Description: "NotificationSink is a destination of the notifications, exactly one of webhook, slack and pagerDuty must be set",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"type": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Immutable, change should be made through pd-ctl after cluster creation",
+							Description: "Name of the sink, must be unique in the sinks",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"args": {
+					"minSeverity": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Immutable, change should be made through pd-ctl after cluster creation",
+							Description: "MinSeverity is the minimum severity of the notifications sent to the sink Optional: Defaults to Info",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"events": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Events are the types of the notifications sent to the sink Optional: Defaults to all types",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -4623,72 +5338,128 @@ func schema_pkg_apis_pingcap_v1alpha1_PDSchedulerConfig(ref common.ReferenceCall
 							},
 						},
 					},
-					"disable": {
+					"webhook": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Immutable, change should be made through pd-ctl after cluster creation",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Webhook sends the notifications as JSON objects to the URL by POST",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WebhookNotificationSink"),
+						},
+					},
+					"slack": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Slack sends the notifications to Slack by an incoming webhook",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SlackNotificationSink"),
+						},
+					},
+					"pagerDuty": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PagerDuty triggers PagerDuty incidents by the Events API v2",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PagerDutyNotificationSink"),
 						},
 					},
 				},
+				Required: []string{"name"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PagerDutyNotificationSink", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SlackNotificationSink", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WebhookNotificationSink"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_PDSecurityConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_NotificationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PDSecurityConfig is the configuration for supporting tls.",
+				Description: "NotificationSpec configures the sinks to send the notifications to",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"cacert-path": {
+					"sinks": {
 						SchemaProps: spec.SchemaProps{
-							Description: "CAPath is the path of file that contains list of trusted SSL CAs.",
-							Type:        []string{"string"},
-							Format:      "",
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NotificationSink"),
+									},
+								},
+							},
 						},
 					},
-					"cert-path": {
+				},
+				Required: []string{"sinks"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NotificationSink"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_OpenTracing(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OpenTracing is the opentracing section of the config.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enable": {
 						SchemaProps: spec.SchemaProps{
-							Description: "CertPath is the path of file that contains X509 certificate in PEM format.",
-							Type:        []string{"string"},
+							Description: "Optional: Defaults to false",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"key-path": {
+					"sampler": {
 						SchemaProps: spec.SchemaProps{
-							Description: "KeyPath is the path of file that contains X509 key in PEM format.",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingSampler"),
+						},
+					},
+					"reporter": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingReporter"),
+						},
+					},
+					"rpc-metrics": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingReporter", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracingSampler"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_PDServerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_OpenTracingReporter(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PDServerConfig is the configuration for pd server.",
+				Description: "OpenTracingReporter is the config for opentracing reporter. See https://godoc.org/github.com/uber/jaeger-client-go/config#ReporterConfig",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"use-region-storage": {
+					"queue-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "UseRegionStorage enables the independent region storage.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"metric-storage": {
+					"buffer-flush-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MetricStorage is the cluster metric storage. Currently we use prometheus as metric storage, we may use PD/TiKV as metric storage later. Imported from v3.1.0",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"log-spans": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"local-agent-host-port": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 				},
@@ -4697,632 +5468,579 @@ func schema_pkg_apis_pingcap_v1alpha1_PDServerConfig(ref common.ReferenceCallbac
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_PDSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_OpenTracingSampler(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PDSpec contains details of PD members",
+				Description: "OpenTracingSampler is the config for opentracing sampler. See https://godoc.org/github.com/uber/jaeger-client-go/config#SamplerConfig",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"version": {
+					"type": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"imagePullPolicy": {
+					"param": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"number"},
+							Format: "double",
 						},
 					},
-					"imagePullSecrets": {
+					"sampling-server-url": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
-									},
-								},
-							},
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"hostNetwork": {
+					"max-operations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"affinity": {
+					"sampling-refresh-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
-							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"priorityClassName": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_OpsCommand(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OpsCommand runs an ad-hoc pd-ctl, dmctl or `br debug` command against a cluster in a Job. The endpoints and the TLS flags of the cluster are set by the operator, and the output of the command is captured into the status.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"schedulerName": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"nodeSelector": {
+					"spec": {
 						SchemaProps: spec.SchemaProps{
-							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Spec defines the command to run",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpsCommandSpec"),
 						},
 					},
-					"annotations": {
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpsCommandSpec"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_OpsCommandList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OpsCommandList is OpsCommand list",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"labels": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"tolerations": {
+					"items": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"array"},
+							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Toleration"),
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpsCommand"),
 									},
 								},
 							},
 						},
 					},
-					"podSecurityContext": {
-						SchemaProps: spec.SchemaProps{
-							Description: "PodSecurityContext of the component",
-							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
-						},
-					},
-					"configUpdateStrategy": {
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpsCommand"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_OpsCommandSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OpsCommandSpec describes the command run by an OpsCommand",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
+							Description: "Type is the tool to run, one of `pd-ctl`, `dmctl` and `br-debug`",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"env": {
-						SchemaProps: spec.SchemaProps{
-							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.EnvVar"),
-									},
-								},
-							},
-						},
-					},
-					"initContainers": {
+					"cluster": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Init containers of the components",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
-									},
-								},
-							},
+							Description: "Cluster is the name of the cluster in the same namespace to run the command against, a DMCluster for `dmctl` and a TidbCluster for the others",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"additionalContainers": {
+					"args": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional containers of the component.",
+							Description: "Args are the arguments of the command, e.g. `[\"store\", \"limit\"]` for pd-ctl or `[\"query-status\"]` for dmctl. Only the read-only subcommands are allowed, the values of the flags should be passed in the `--flag=value` form, and the endpoints and the TLS flags must not be set as they are set by the operator.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"additionalVolumes": {
+					"imagePullPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volumes of component pod.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Volume"),
-									},
-								},
-							},
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"additionalVolumeMounts": {
+					"resources": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volume mounts of component pod.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
-									},
-								},
-							},
+							Ref: ref("k8s.io/api/core/v1.ResourceRequirements"),
 						},
 					},
-					"terminationGracePeriodSeconds": {
+					"activeDeadlineSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
+							Description: "ActiveDeadlineSeconds is the timeout of the Job of the command Optional: Defaults to 300",
 							Type:        []string{"integer"},
 							Format:      "int64",
 						},
 					},
-					"statefulSetUpdateStrategy": {
+				},
+				Required: []string{"type", "cluster", "args"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ResourceRequirements"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_OpsCommandStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OpsCommandStatus is the status of an OpsCommand",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"phase": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Description: "Phase is the phase of the command",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"podManagementPolicy": {
+					"jobName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
+							Description: "JobName is the name of the Job running the command",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"topologySpreadConstraints": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-map-keys": []interface{}{
-									"topologyKey",
-								},
-								"x-kubernetes-list-type": "map",
-							},
-						},
+					"startTime": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
-									},
-								},
-							},
+							Description: "StartTime is the time the Job of the command is created",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
-					"limits": {
+					"completionTime": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "CompletionTime is the time the command finished",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
-					"requests": {
+					"output": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "Output is the output of the command, only the last 32KiB is kept",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"serviceAccount": {
+					"message": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Specify a Service Account for pd",
+							Description: "Message describes why the command failed or could not be run",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"replicas": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_PDConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PDConfig is the configuration of pd-server",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"force-new-cluster": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The desired ready replicas",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"baseImage": {
+					"enable-grpc-gateway": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Base image of the component, image tag is now allowed during validation",
-							Type:        []string{"string"},
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"service": {
+					"initial-cluster-token": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Service defines a Kubernetes service of PD cluster. Optional: Defaults to `.spec.services` in favor of backward compatibility",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ServiceSpec"),
+							Description: "set different tokens to prevent communication between PDs in different clusters.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"maxFailoverCount": {
+					"lease": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover. Optional: Defaults to 3",
+							Description: "LeaderLease time, if leader doesn't update its TTL in etcd after lease time, etcd will expire the leader key and other servers can campaign the leader again. Etcd only supports seconds TTL, so here is second too. Optional: Defaults to 3",
 							Type:        []string{"integer"},
-							Format:      "int32",
+							Format:      "int64",
 						},
 					},
-					"storageClassName": {
+					"log": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The storageClassName of the persistent volume for PD data storage. Defaults to Kubernetes default storage class.",
+							Description: "Log related config.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDLogConfig"),
+						},
+					},
+					"log-file": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Backward compatibility.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"storageVolumes": {
+					"log-level": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageVolumes configure additional storage for PD pods.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
-									},
-								},
-							},
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"dataSubDir": {
+					"tso-save-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Subdirectory within the volume to store PD Data. By default, the data is stored in the root directory of volume which is mounted at /var/lib/pd. Specifying this will change the data directory to a subdirectory, e.g. /var/lib/pd/data if you set the value to \"data\". It's dangerous to change this value for a running cluster as it will upgrade your cluster to use a new storage directory. Defaults to \"\" (volume's root).",
+							Description: "TsoSaveInterval is the interval to save timestamp. Optional: Defaults to 3s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"config": {
+					"metric": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Config is the Configuration of pd-servers",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDConfigWraper"),
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDMetricConfig"),
 						},
 					},
-					"tlsClientSecretName": {
+					"schedule": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TLSClientSecretName is the name of secret which stores tidb server client certificate which used by Dashboard.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Immutable, change should be made through pd-ctl after cluster creation",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDScheduleConfig"),
 						},
 					},
-					"enableDashboardInternalProxy": {
+					"replication": {
 						SchemaProps: spec.SchemaProps{
-							Description: "(Deprecated) EnableDashboardInternalProxy would directly set `internal-proxy` in the `PdConfig`. Note that this is deprecated, we should just set `dashboard.internal-proxy` in `pd.config`.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Immutable, change should be made through pd-ctl after cluster creation",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDReplicationConfig"),
 						},
 					},
-					"mountClusterClientSecret": {
+					"namespace": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MountClusterClientSecret indicates whether to mount `cluster-client-secret` to the Pod",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDNamespaceConfig"),
+									},
+								},
+							},
 						},
 					},
-				},
-				Required: []string{"replicas"},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_PDStoreLabel(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "PDStoreLabel is the config item of LabelPropertyConfig.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"key": {
+					"pd-server": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDServerConfig"),
 						},
 					},
-					"value": {
+					"cluster-version": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_Performance(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "Performance is the performance section of the config.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"max-procs": {
+					"quota-backend-bytes": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "QuotaBackendBytes Raise alarms when backend size exceeds the given quota. 0 means use the default quota. the default size is 2GB, the maximum is 8GB.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"max-memory": {
+					"auto-compaction-mode": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 0",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "AutoCompactionMode is either 'periodic' or 'revision'. The default value is 'periodic'.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"stats-lease": {
+					"auto-compaction-retention-v2": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 3s",
+							Description: "AutoCompactionRetention is either duration string with time unit (e.g. '5m' for 5-minute), or revision unit (e.g. '5000'). If no time unit is provided and compaction mode is 'periodic', the unit defaults to hour. For example, '5' translates into 5-hour. The default retention is 1 hour. Before etcd v3.3.x, the type of retention is int. We add 'v2' suffix to make it backward compatible.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"stmt-count-limit": {
+					"tikv-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 5000",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "TickInterval is the interval for etcd Raft tick.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"feedback-probability": {
+					"election-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 0.05",
-							Type:        []string{"number"},
-							Format:      "double",
+							Description: "ElectionInterval is the interval for etcd Raft election.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"query-feedback-limit": {
+					"enable-prevote": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 512",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Prevote is true to enable Raft Pre-Vote. If enabled, Raft runs an additional election phase to check whether it would get enough votes to win an election, thus minimizing disruptions. Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"pseudo-estimate-ratio": {
+					"security": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 0.8",
-							Type:        []string{"number"},
-							Format:      "double",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSecurityConfig"),
 						},
 					},
-					"force-priority": {
+					"label-property": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to NO_PRIORITY",
-							Type:        []string{"string"},
-							Format:      "",
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type: []string{"array"},
+										Items: &spec.SchemaOrArray{
+											Schema: &spec.Schema{
+												SchemaProps: spec.SchemaProps{
+													Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDStoreLabel"),
+												},
+											},
+										},
+									},
+								},
+							},
 						},
 					},
-					"bind-info-lease": {
+					"namespace-classifier": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 3s",
+							Description: "Deprecated in v4.0.0 NamespaceClassifier is for classifying stores/regions into different namespaces. Optional: Defaults to true",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"txn-total-size-limit": {
+					"dashboard": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 104857600",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DashboardConfig"),
 						},
 					},
-					"tcp-keep-alive": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DashboardConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDLogConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDMetricConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDNamespaceConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDReplicationConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDScheduleConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSecurityConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDServerConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDStoreLabel"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_PDLogConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PDLogConfig serializes log related config in toml/json.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"level": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "Log level. Optional: Defaults to info",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"cross-join": {
+					"format": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "Log format. one of json, text, or console.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"run-auto-analyze": {
+					"disable-timestamp": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
+							Description: "Disable automatic timestamps in output.",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"agg-push-down-join": {
+					"file": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "File log config.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig"),
 						},
 					},
-					"committer-concurrency": {
+					"development": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Development puts the logger in development mode, which changes the behavior of DPanicLevel and takes stacktraces more liberally.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"max-txn-ttl": {
+					"disable-caller": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "DisableCaller stops annotating logs with the calling function's file name and line number. By default, all logs are annotated.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"txn-entry-count-limit": {
+					"disable-stacktrace": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated in v4.0.0 Optional: Defaults to 300000",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "DisableStacktrace completely disables automatic stacktrace capturing. By default, stacktraces are captured for WarnLevel and above logs in development and ErrorLevel and above in production.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disable-error-verbose": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableErrorVerbose stops annotating logs with the full verbose error message.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_PessimisticTxn(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PDMetricConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PessimisticTxn is the config for pessimistic transaction.",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"enable": {
+					"job": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Enable must be true for 'begin lock' or session variable to start a pessimistic transaction. Optional: Defaults to true",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"max-retry-count": {
+					"address": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The max count of retry for a single statement in a pessimistic transaction. Optional: Defaults to 256",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_PlanCache(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "PlanCache is the PlanCache section of the config.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"enabled": {
+					"interval": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
+							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"capacity": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
-						},
-					},
-					"shards": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
-						},
-					},
 				},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_Plugin(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PDNamespaceConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Plugin is the config for plugin",
+				Description: "PDNamespaceConfig is to overwrite the global setting for specific namespace",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"dir": {
+					"leader-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "LeaderScheduleLimit is the max coexist leader schedules.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"load": {
+					"region-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "RegionScheduleLimit is the max coexist region schedules.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_PreparedPlanCache(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "PreparedPlanCache is the PreparedPlanCache section of the config.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"enabled": {
+					"replica-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to false",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "ReplicaScheduleLimit is the max coexist replica schedules.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"capacity": {
+					"merge-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 100",
+							Description: "MergeScheduleLimit is the max coexist merge schedules.",
 							Type:        []string{"integer"},
-							Format:      "int32",
+							Format:      "int64",
 						},
 					},
-					"memory-guard-ratio": {
+					"hot-region-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 0.1",
-							Type:        []string{"number"},
-							Format:      "double",
+							Description: "HotRegionScheduleLimit is the max coexist hot region schedules.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"max-replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxReplicas is the number of replicas for each region.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
 				},
@@ -5331,175 +6049,365 @@ func schema_pkg_apis_pingcap_v1alpha1_PreparedPlanCache(ref common.ReferenceCall
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_PrometheusConfiguration(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PDReplicationConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Config  is the the desired state of Prometheus Configuration",
+				Description: "PDReplicationConfig is the replication configuration.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"configMapRef": {
+					"max-replicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "User can mount prometheus config with external configMap. The external configMap must contain `prometheus-config` key in data.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigMapRef"),
+							Description: "MaxReplicas is the number of replicas for each region. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"commandOptions": {
+					"strictly-match-label": {
 						SchemaProps: spec.SchemaProps{
-							Description: "user can  use it specify prometheus command options",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "StrictlyMatchLabel strictly checks if the label of TiKV is matched with LocaltionLabels. Immutable, change should be made through pd-ctl after cluster creation. Imported from v3.1.0",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"ruleConfigRef": {
+					"enable-placement-rules": {
 						SchemaProps: spec.SchemaProps{
-							Description: "User can mount prometheus rule config with external configMap. The external configMap must use the key with suffix `.rules.yml`.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigMapRef"),
+							Description: "When PlacementRules feature is enabled. MaxReplicas and LocationLabels are not used anymore.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
 				},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigMapRef"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_ProxyConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PDScheduleConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ProxyConfig is the configuration of TiFlash proxy process. All the configurations are same with those of TiKV except adding `engine-addr` in the TiKVServerConfig",
+				Description: "ScheduleConfig is the schedule configuration.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"log-level": {
+					"max-snapshot-count": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to info",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "If the snapshot count of one store is greater than this value, it will never be used as a source or target store. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"log-file": {
+					"max-pending-peer-count": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 16",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"log-rotation-timespan": {
+					"max-merge-region-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 24h",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "If both the size of region is smaller than MaxMergeRegionSize and the number of rows in region is smaller than MaxMergeRegionKeys, it will try to merge with adjacent regions. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 20",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"panic-when-unexpected-key-or-data": {
+					"max-merge-region-keys": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 200000",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"server": {
+					"split-merge-interval": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashServerConfig"),
+							Description: "SplitMergeInterval is the minimum interval time to permit merge after split. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 1h",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"storage": {
+					"patrol-region-interval": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageConfig"),
+							Description: "PatrolRegionInterval is the interval for scanning region during patrol. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"raftstore": {
+					"max-store-down-time": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftstoreConfig"),
+							Description: "MaxStoreDownTime is the max duration after which a store will be considered to be down if it hasn't reported heartbeats. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 30m",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"rocksdb": {
+					"leader-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVDbConfig"),
+							Description: "LeaderScheduleLimit is the max coexist leader schedules. Immutable, change should be made through pd-ctl after cluster creation. Optional: Defaults to 4. Imported from v3.1.0",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"coprocessor": {
+					"region-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig"),
+							Description: "RegionScheduleLimit is the max coexist region schedules. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 2048",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"readpool": {
+					"replica-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVReadPoolConfig"),
+							Description: "ReplicaScheduleLimit is the max coexist replica schedules. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 64",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"raftdb": {
+					"merge-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftDBConfig"),
+							Description: "MergeScheduleLimit is the max coexist merge schedules. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 8",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"import": {
+					"hot-region-schedule-limit": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVImportConfig"),
+							Description: "HotRegionScheduleLimit is the max coexist hot region schedules. Immutable, change should be made through pd-ctl after cluster creation Optional: Defaults to 4",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"gc": {
+					"hot-region-cache-hits-threshold": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVGCConfig"),
+							Description: "HotRegionCacheHitThreshold is the cache hits threshold of the hot region. If the number of times a region hits the hot cache is greater than this threshold, it is considered a hot region. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"pd": {
+					"tolerant-size-ratio": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPDConfig"),
+							Description: "TolerantSizeRatio is the ratio of buffer size for balance scheduler. Immutable, change should be made through pd-ctl after cluster creation. Imported from v3.1.0",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"security": {
+					"low-space-ratio": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfig"),
+							Description: "\n     high space stage         transition stage           low space stage\n  |--------------------|-----------------------------|-------------------------|\n  ^                    ^                             ^                         ^\n  0       HighSpaceRatio * capacity       LowSpaceRatio * capacity          capacity\n\nLowSpaceRatio is the lowest usage ratio of store which regraded as low space. When in low space, store region score increases to very large and varies inversely with available size. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashServerConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVDbConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVGCConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVImportConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPDConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftDBConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftstoreConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVReadPoolConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageConfig"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_ProxyProtocol(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "ProxyProtocol is the PROXY protocol section of the config.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"networks": {
+					"high-space-ratio": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PROXY protocol acceptable client networks. Empty *string means disable PROXY protocol, * means all networks.",
-							Type:        []string{"string"},
+							Description: "HighSpaceRatio is the highest usage ratio of store which regraded as high space. High space means there is a lot of spare capacity, and store region score varies directly with used size. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+					"disable-raft-learner": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableLearner is the option to disable using AddLearnerNode instead of AddNode Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"header-timeout": {
+					"disable-remove-down-replica": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PROXY protocol header read timeout, Unit is second.",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "DisableRemoveDownReplica is the option to prevent replica checker from removing down replicas. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disable-replace-offline-replica": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableReplaceOfflineReplica is the option to prevent replica checker from repalcing offline replicas. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disable-make-up-replica": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableMakeUpReplica is the option to prevent replica checker from making up replicas when replica count is less than expected. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disable-remove-extra-replica": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableRemoveExtraReplica is the option to prevent replica checker from removing extra replicas. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disable-location-replacement": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableLocationReplacement is the option to prevent replica checker from moving replica to a better location. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disable-namespace-relocation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableNamespaceRelocation is the option to prevent namespace checker from moving replica to the target namespace. Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"schedulers-v2": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedulers support for loding customized schedulers Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSchedulerConfig"),
+									},
+								},
+							},
+						},
+					},
+					"schedulers-payload": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Only used to display",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"enable-one-way-merge": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EnableOneWayMerge is the option to enable one way merge. This means a Region can only be merged into the next region of it. Imported from v3.1.0",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"enable-cross-table-merge": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EnableCrossTableMerge is the option to enable cross table merge. This means two Regions can be merged with different table IDs. This option only works when key type is \"table\". Imported from v3.1.0",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSchedulerConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_PumpSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PDSchedulerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PumpSpec contains details of Pump members",
+				Description: "PDSchedulerConfig is customized scheduler configuration",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"args": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"disable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Immutable, change should be made through pd-ctl after cluster creation",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_PDSecurityConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PDSecurityConfig is the configuration for supporting tls.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"cacert-path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CAPath is the path of file that contains list of trusted SSL CAs.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cert-path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CertPath is the path of file that contains X509 certificate in PEM format.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"key-path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KeyPath is the path of file that contains X509 key in PEM format.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_PDServerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PDServerConfig is the configuration for pd server.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"use-region-storage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UseRegionStorage enables the independent region storage.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"metric-storage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MetricStorage is the cluster metric storage. Currently we use prometheus as metric storage, we may use PD/TiKV as metric storage later. Imported from v3.1.0",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_PDSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PDSpec contains details of PD members",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"version": {
@@ -5713,6 +6621,20 @@ func schema_pkg_apis_pingcap_v1alpha1_PumpSpec(ref common.ReferenceCallback) com
 							Format:      "",
 						},
 					},
+					"minReadySeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rollingUpdateIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 					"topologySpreadConstraints": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -5734,6 +6656,55 @@ func schema_pkg_apis_pingcap_v1alpha1_PumpSpec(ref common.ReferenceCallback) com
 							},
 						},
 					},
+					"architecture": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archBaseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"startScriptVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"helper": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"limits": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
@@ -5764,7 +6735,7 @@ func schema_pkg_apis_pingcap_v1alpha1_PumpSpec(ref common.ReferenceCallback) com
 					},
 					"serviceAccount": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Specify a Service Account for pump",
+							Description: "Specify a Service Account for pd",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -5783,1078 +6754,671 @@ func schema_pkg_apis_pingcap_v1alpha1_PumpSpec(ref common.ReferenceCallback) com
 							Format:      "",
 						},
 					},
-					"storageClassName": {
+					"service": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The storageClassName of the persistent volume for Pump data storage. Defaults to Kubernetes default storage class.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Service defines a Kubernetes service of PD cluster. Optional: Defaults to `.spec.services` in favor of backward compatibility",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ServiceSpec"),
 						},
 					},
-					"config": {
+					"maxFailoverCount": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The configuration of Pump cluster.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/util/config.GenericConfig"),
+							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover. Optional: Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-				},
-				Required: []string{"replicas"},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "github.com/pingcap/tidb-operator/pkg/apis/util/config.GenericConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_QueueConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "QueueConfig allows the tuning of remote_write queue_config parameters. This object is referenced in the RemoteWriteSpec object.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"capacity": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Number of samples to buffer per shard before we start dropping them.",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"maxShards": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Max number of shards, i.e. amount of concurrency.",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"maxSamplesPerSend": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Maximum number of samples per send.",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"batchSendDeadline": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Maximum time sample will wait in buffer.",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"maxRetries": {
+					"progressDeadlineSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Max number of times to retry a batch on recoverable errors.",
+							Description: "ProgressDeadlineSeconds is the max seconds for a Pod to complete its update in a rolling update. If no Pod completes its update within the deadline, the rolling update is paused with the ProgressDeadlineExceeded condition, and no more Pods are deleted until the update revision is changed, or the deadline is raised or removed. Optional: Defaults to no deadline",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"minBackoff": {
-						SchemaProps: spec.SchemaProps{
-							Description: "On recoverable errors, backoff exponentially.",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"maxBackoff": {
+					"storageClassName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "The storageClassName of the persistent volume for PD data storage. Defaults to Kubernetes default storage class.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_RelabelConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "RelabelConfig allows dynamic rewriting of the label set, being applied to samples before ingestion. It defines `<metric_relabel_configs>`-section of Prometheus configuration. More info: https://prometheus.io/docs/prometheus/latest/configuration/configuration/#metric_relabel_configs",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"sourceLabels": {
+					"storageVolumes": {
 						SchemaProps: spec.SchemaProps{
-							Description: "A list of labels from which values are taken and concatenated with the configured separator in order.",
+							Description: "StorageVolumes configure additional storage for PD pods.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
 									},
 								},
 							},
 						},
 					},
-					"separator": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Separator is the string between concatenated values from the source labels.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"regex": {
+					"dataSubDir": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Regular expression against which the extracted value is matched. Default is '(.*)'",
+							Description: "Subdirectory within the volume to store PD Data. By default, the data is stored in the root directory of volume which is mounted at /var/lib/pd. Specifying this will change the data directory to a subdirectory, e.g. /var/lib/pd/data if you set the value to \"data\". It's dangerous to change this value for a running cluster as it will upgrade your cluster to use a new storage directory. Defaults to \"\" (volume's root).",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"modulus": {
+					"config": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Modulus to take of the hash of concatenated values from the source labels.",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Config is the Configuration of pd-servers",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDConfigWraper"),
 						},
 					},
-					"targetLabel": {
+					"configMapRef": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TargetLabel is the label to which the resulting string is written in a replacement. Regexp interpolation is allowed for the replace action.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value is the config file of pd-servers in TOML. The items of the inline config and the items managed by the operator take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out like the changes of the inline config after the TOML is validated.",
+							Ref:         ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
 						},
 					},
-					"replacement": {
+					"tlsClientSecretName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Replacement is the regex replacement pattern to be used.",
+							Description: "TLSClientSecretName is the name of secret which stores tidb server client certificate which used by Dashboard.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"action": {
+					"enableDashboardInternalProxy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Action is the action to be performed for the relabeling.",
-							Type:        []string{"string"},
+							Description: "(Deprecated) EnableDashboardInternalProxy would directly set `internal-proxy` in the `PdConfig`. Note that this is deprecated, we should just set `dashboard.internal-proxy` in `pd.config`.",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_RemoteWriteSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "RemoteWriteSpec defines the remote_write configuration for prometheus.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"url": {
+					"mountClusterClientSecret": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The URL of the endpoint to send samples to.",
-							Type:        []string{"string"},
+							Description: "MountClusterClientSecret indicates whether to mount `cluster-client-secret` to the Pod",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"remoteTimeout": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
-						},
-					},
-					"writeRelabelConfigs": {
+					"deleteSlots": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The list of remote write relabel configurations.",
+							Description: "DeleteSlots are the ordinals of the Pods to delete, in addition to the ones in the `pd.tidb.pingcap.com/delete-slots` annotation. The deleted Pods are replaced by Pods of new ordinals unless the replicas are decreased accordingly. Only takes effect when advanced StatefulSet is enabled.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RelabelConfig"),
+										Type:   []string{"integer"},
+										Format: "int32",
 									},
 								},
 							},
 						},
 					},
-					"basicAuth": {
-						SchemaProps: spec.SchemaProps{
-							Description: "BasicAuth for the URL.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BasicAuth"),
-						},
-					},
-					"bearerToken": {
-						SchemaProps: spec.SchemaProps{
-							Description: "File to read bearer token for remote write.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"bearerTokenFile": {
-						SchemaProps: spec.SchemaProps{
-							Description: "File to read bearer token for remote write.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"tlsConfig": {
+					"podDisruptionBudget": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TLS Config to use for remote write.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSConfig"),
+							Description: "PodDisruptionBudget configures the PodDisruptionBudget of the PD pods. No PodDisruptionBudget is created if it's not set.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec"),
 						},
 					},
-					"proxyUrl": {
+					"leaderPriorityByZone": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Proxy url",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "LeaderPriorityByZone is the leader priority of the PD members keyed by the zone of their nodes, which is read from the topology.kubernetes.io/zone label of the nodes. The members in the zones of higher priorities are preferred to be the leader, so the leader stays in the low-latency zones, and is moved to another member in the same zone first when it fails. The priority of the members in the zones not listed is 0.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
 						},
 					},
-					"queueConfig": {
+					"clientPort": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.QueueConfig"),
+							Description: "ClientPort is the port that PD serves the client requests on, it's used by the services, the probes and the other components to connect to PD. It can't be changed for an existing cluster. Optional: Defaults to 2379",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 				},
-				Required: []string{"url"},
+				Required: []string{"replicas"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BasicAuth", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.QueueConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RelabelConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSConfig"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.ConfigMapKeySelector", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_Restore(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PDStoreLabel(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Restore represents the restoration of backup of a tidb cluster.",
+				Description: "PDStoreLabel is the config item of LabelPropertyConfig.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"apiVersion": {
+					"key": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"spec": {
+					"value": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 				},
-				Required: []string{"spec"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_RestoreList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PagerDutyNotificationSink(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "RestoreList contains a list of Restore.",
+				Description: "PagerDutyNotificationSink is a PagerDuty Events API v2 sink",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"routingKeySecretRef": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "RoutingKeySecretRef refers to the key of the Secret that contains the integration key of the service",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
 						},
 					},
-					"apiVersion": {
+					"url": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Description: "URL of the Events API Optional: Defaults to https://events.pagerduty.com/v2/enqueue",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"items": {
-						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Restore"),
-									},
-								},
-							},
-						},
-					},
 				},
-				Required: []string{"items"},
+				Required: []string{"routingKeySecretRef"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Restore"},
+			"k8s.io/api/core/v1.SecretKeySelector"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_RestoreSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_Performance(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "RestoreSpec contains the specification for a restore of a tidb cluster backup.",
+				Description: "Performance is the performance section of the config.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"resources": {
+					"max-procs": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/api/core/v1.ResourceRequirements"),
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"env": {
+					"max-memory": {
 						SchemaProps: spec.SchemaProps{
-							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following builtin env vars will be overwritten by values set here - S3_PROVIDER - S3_ENDPOINT - AWS_REGION - AWS_ACL - AWS_STORAGE_CLASS - AWS_DEFAULT_REGION - AWS_ACCESS_KEY_ID - AWS_SECRET_ACCESS_KEY - GCS_PROJECT_ID - GCS_OBJECT_ACL - GCS_BUCKET_ACL - GCS_LOCATION - GCS_STORAGE_CLASS - GCS_SERVICE_ACCOUNT_JSON_KEY - BR_LOG_TO_TERM",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.EnvVar"),
-									},
-								},
-							},
-						},
-					},
-					"to": {
-						SchemaProps: spec.SchemaProps{
-							Description: "To is the tidb cluster that needs to restore.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig"),
+							Description: "Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"backupType": {
+					"stats-lease": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Type is the backup type for tidb cluster.",
+							Description: "Optional: Defaults to 3s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"tikvGCLifeTime": {
+					"stmt-count-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TikvGCLifeTime is to specify the safe gc life time for restore. The time limit during which data is retained for each GC, in the format of Go Duration. When a GC happens, the current time minus this value is the safe point.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 5000",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"s3": {
+					"feedback-probability": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"),
+							Description: "Optional: Defaults to 0.05",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"gcs": {
+					"query-feedback-limit": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider"),
+							Description: "Optional: Defaults to 512",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"local": {
+					"pseudo-estimate-ratio": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider"),
+							Description: "Optional: Defaults to 0.8",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"storageClassName": {
+					"force-priority": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The storageClassName of the persistent volume for Restore data storage. Defaults to Kubernetes default storage class.",
+							Description: "Optional: Defaults to NO_PRIORITY",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"storageSize": {
+					"bind-info-lease": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageSize is the request storage size for backup job",
+							Description: "Optional: Defaults to 3s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"br": {
-						SchemaProps: spec.SchemaProps{
-							Description: "BR is the configs for BR.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig"),
-						},
-					},
-					"tolerations": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Base tolerations of restore Pods, components may add more tolerations upon this respectively",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Toleration"),
-									},
-								},
-							},
-						},
-					},
-					"affinity": {
+					"txn-total-size-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Affinity of restore Pods",
-							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+							Description: "Optional: Defaults to 104857600",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"useKMS": {
+					"tcp-keep-alive": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Use KMS to decrypt the secrets",
+							Description: "Optional: Defaults to true",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"serviceAccount": {
+					"cross-join": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Specify service account of restore",
-							Type:        []string{"string"},
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"toolImage": {
+					"run-auto-analyze": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ToolImage specifies the tool image used in `Restore`, which supports BR and TiDB Lightning images. For examples `spec.toolImage: pingcap/br:v4.0.8` or `spec.toolImage: pingcap/tidb-lightning:v4.0.8` For BR image, if it does not contain tag, Pod will use image 'ToolImage:${TiKV_Version}'.",
-							Type:        []string{"string"},
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"imagePullSecrets": {
+					"agg-push-down-join": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
-									},
-								},
-							},
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"tableFilter": {
+					"committer-concurrency": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TableFilter means Table filter expression for 'db.table' matching. BR supports this from v4.0.3.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"podSecurityContext": {
+					"max-txn-ttl": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodSecurityContext of the component",
-							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"priorityClassName": {
+					"txn-entry-count-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PriorityClassName of Restore Job Pods",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Deprecated in v4.0.0 Optional: Defaults to 300000",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
 				},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Toleration"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_S3StorageProvider(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PessimisticTxn(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "S3StorageProvider represents a S3 compliant storage for storing backups.",
+				Description: "PessimisticTxn is the config for pessimistic transaction.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"provider": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Provider represents the specific storage provider that implements the S3 interface",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"region": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Region in which the S3 compatible bucket is located.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"path": {
+					"enable": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Path is the full path where the backup is saved. The format of the path must be: \"<bucket-name>/<path-to-backup-file>\"",
-							Type:        []string{"string"},
+							Description: "Enable must be true for 'begin lock' or session variable to start a pessimistic transaction. Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"bucket": {
+					"max-retry-count": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Bucket in which to store the backup data.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "The max count of retry for a single statement in a pessimistic transaction. Optional: Defaults to 256",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"endpoint": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_PiTRRestoreSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PiTRRestoreSpec contains the specification of a point-in-time restore",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"logBackup": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Endpoint of S3 compatible storage service",
+							Description: "LogBackup is the name of the Backup in the namespace of the Restore, whose storage is where the log backup task of the source cluster writes to. The storage is accessed with the credentials of the storage of the Restore, so the storage type of them should be the same.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"storageClass": {
+					"restoredTs": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageClass represents the storage class",
+							Description: "RestoredTs is the point in time to restore the cluster to, in the format of a TSO, e.g. `434319917412892673`, or a RFC3339 datetime, e.g. `2022-07-15T19:00:00+08:00`. It should be within the window of the log backup and not earlier than the commit ts of the snapshot backup.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"acl": {
+				},
+				Required: []string{"logBackup", "restoredTs"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_PlanCache(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PlanCache is the PlanCache section of the config.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Acl represents access control permissions for this bucket",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"secretName": {
+					"capacity": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SecretName is the name of secret which stores S3 compliant storage access key and secret key.",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"prefix": {
+					"shards": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Prefix of the data path.",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"sse": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_Plugin(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Plugin is the config for plugin",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"dir": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SSE Sever-Side Encryption.",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"options": {
+					"load": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Options Rclone options for backup and restore with mydumper and lightning.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 				},
-				Required: []string{"provider"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_SafeTLSConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PodDisruptionBudgetSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "SafeTLSConfig specifies safe TLS configuration parameters.",
+				Description: "PodDisruptionBudgetSpec specifies the PodDisruptionBudget of a component, which limits the pods unavailable at the same time due to the voluntary disruptions, e.g. node drains",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"ca": {
+					"maxUnavailable": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Struct containing the CA cert to use for the targets.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap"),
+							Description: "MaxUnavailable is the max number of the pods that can be unavailable during the voluntary disruptions, it can be an absolute number or a percentage of the replicas. Optional: Defaults to 1, which keeps the quorum of PD, TiKV and dm-master",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
 						},
 					},
-					"cert": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Struct containing the client cert file for the targets.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap"),
-						},
-					},
-					"keySecret": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/util/intstr.IntOrString"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_PreparedPlanCache(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PreparedPlanCache is the PreparedPlanCache section of the config.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Secret containing the client key file for the targets.",
-							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+							Description: "Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"serverName": {
+					"capacity": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Used to verify the hostname for the targets.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 100",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"insecureSkipVerify": {
+					"memory-guard-ratio": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Disable target certificate validation.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Optional: Defaults to 0.1",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
 				},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap", "k8s.io/api/core/v1.SecretKeySelector"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_SecretRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PrometheusConfiguration(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "SecretRef indicates to secret ref",
+				Description: "Config  is the the desired state of Prometheus Configuration",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
+					"configMapRef": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "User can mount prometheus config with external configMap. The external configMap must contain `prometheus-config` key in data.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigMapRef"),
 						},
 					},
-					"namespace": {
+					"commandOptions": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "user can  use it specify prometheus command options",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"ruleConfigRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User can mount prometheus rule config with external configMap. The external configMap must use the key with suffix `.rules.yml`.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigMapRef"),
 						},
 					},
 				},
-				Required: []string{"name", "namespace"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigMapRef"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_Security(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_ProxyConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Security is the security section of the config.",
+				Description: "ProxyConfig is the configuration of TiFlash proxy process. All the configurations are same with those of TiKV except adding `engine-addr` in the TiKVServerConfig",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"skip-grant-table": {
+					"log-level": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Optional: Defaults to info",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"ssl-ca": {
+					"log-file": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"ssl-cert": {
+					"log-rotation-timespan": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Optional: Defaults to 24h",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"ssl-key": {
+					"panic-when-unexpected-key-or-data": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
+							Type:   []string{"boolean"},
 							Format: "",
 						},
 					},
-					"cluster-ssl-ca": {
+					"server": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashServerConfig"),
 						},
 					},
-					"cluster-ssl-cert": {
+					"storage": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageConfig"),
 						},
 					},
-					"cluster-ssl-key": {
+					"raftstore": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftstoreConfig"),
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_ServiceSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "ServiceSpec specifies the service object in k8s",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"type": {
+					"rocksdb": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Type of the real kubernetes service",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVDbConfig"),
 						},
 					},
-					"annotations": {
+					"coprocessor": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional annotations for the service",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig"),
 						},
 					},
-					"labels": {
+					"readpool": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional labels for the service",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVReadPoolConfig"),
 						},
 					},
-					"loadBalancerIP": {
+					"raftdb": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LoadBalancerIP is the loadBalancerIP of service Optional: Defaults to omitted",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftDBConfig"),
 						},
 					},
-					"clusterIP": {
+					"import": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ClusterIP is the clusterIP of service",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVImportConfig"),
 						},
 					},
-					"portName": {
+					"gc": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PortName is the name of service port",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVGCConfig"),
 						},
 					},
-					"loadBalancerSourceRanges": {
+					"pd": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LoadBalancerSourceRanges is the loadBalancerSourceRanges of service If specified and supported by the platform, this will restrict traffic through the cloud-provider load-balancer will be restricted to the specified client IPs. This field will be ignored if the cloud-provider does not support the feature.\" More info: https://kubernetes.io/docs/concepts/services-networking/service/#aws-nlb-support Optional: Defaults to omitted",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPDConfig"),
+						},
+					},
+					"security": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfig"),
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashServerConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVDbConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVGCConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVImportConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPDConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftDBConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftstoreConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVReadPoolConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_Status(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_ProxyProtocol(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Status is the status section of the config.",
+				Description: "ProxyProtocol is the PROXY protocol section of the config.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"metrics-addr": {
+					"networks": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "PROXY protocol acceptable client networks. Empty *string means disable PROXY protocol, * means all networks.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"metrics-interval": {
+					"header-timeout": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 15",
+							Description: "PROXY protocol header read timeout, Unit is second.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"report-status": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
-					"record-db-qps": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to false",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
 				},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_StmtSummary(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_PumpSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "StmtSummary is the config for statement summary.",
+				Description: "PumpSpec contains details of Pump members",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"enable": {
+					"version": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Enable statement summary or not.",
-							Type:        []string{"boolean"},
+							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"enable-internal-query": {
+					"imagePullPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Enable summary internal query.",
-							Type:        []string{"boolean"},
+							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"max-stmt-count": {
-						SchemaProps: spec.SchemaProps{
-							Description: "The maximum number of statements kept in memory. Optional: Defaults to 100",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"max-sql-length": {
-						SchemaProps: spec.SchemaProps{
-							Description: "The maximum length of displayed normalized SQL and sample SQL. Optional: Defaults to 4096",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"refresh-interval": {
-						SchemaProps: spec.SchemaProps{
-							Description: "The refresh interval of statement summary.",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"history-size": {
-						SchemaProps: spec.SchemaProps{
-							Description: "The maximum history size of statement summary.",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_StorageClaim(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "StorageClaim contains details of TiFlash storages",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"resources": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Resources represents the minimum resources the volume should have. More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#resources",
-							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
-						},
-					},
-					"storageClassName": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Name of the StorageClass required by the claim. More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#class-1",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"k8s.io/api/core/v1.ResourceRequirements"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_StorageProvider(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "StorageProvider defines the configuration for storing a backup in backend storage.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"s3": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"),
-						},
-					},
-					"gcs": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider"),
-						},
-					},
-					"local": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider"),
-						},
-					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TLSConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TLSConfig extends the safe TLS configuration with file parameters.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"ca": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Struct containing the CA cert to use for the targets.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap"),
-						},
-					},
-					"cert": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Struct containing the client cert file for the targets.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap"),
-						},
-					},
-					"keySecret": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Secret containing the client key file for the targets.",
-							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
-						},
-					},
-					"serverName": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Used to verify the hostname for the targets.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"insecureSkipVerify": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Disable target certificate validation.",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
-					"caFile": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Path to the CA cert in the Prometheus container to use for the targets.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"certFile": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Path to the client cert file in the Prometheus container for the targets.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"keyFile": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Path to the client key file in the Prometheus container for the targets.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap", "k8s.io/api/core/v1.SecretKeySelector"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiCDCConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiCDCConfig is the configuration of tidbcdc ref https://github.com/pingcap/ticdc/blob/a28d9e43532edc4a0380f0ef87314631bf18d866/pkg/config/config.go#L176",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"timezone": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Time zone of TiCDC Optional: Defaults to UTC",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"gcTTL": {
-						SchemaProps: spec.SchemaProps{
-							Description: "CDC GC safepoint TTL duration, specified in seconds Optional: Defaults to 86400",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"logLevel": {
-						SchemaProps: spec.SchemaProps{
-							Description: "LogLevel is the log level Optional: Defaults to info",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"logFile": {
-						SchemaProps: spec.SchemaProps{
-							Description: "LogFile is the log file Optional: Defaults to /dev/stderr",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiCDCSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiCDCSpec contains details of TiCDC members",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"version": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"imagePullPolicy": {
-						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"imagePullSecrets": {
+					"imagePullSecrets": {
 						SchemaProps: spec.SchemaProps{
 							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
 							Type:        []string{"array"},
@@ -7051,6 +7615,20 @@ func schema_pkg_apis_pingcap_v1alpha1_TiCDCSpec(ref common.ReferenceCallback) co
 							Format:      "",
 						},
 					},
+					"minReadySeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rollingUpdateIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 					"topologySpreadConstraints": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -7072,476 +7650,473 @@ func schema_pkg_apis_pingcap_v1alpha1_TiCDCSpec(ref common.ReferenceCallback) co
 							},
 						},
 					},
-					"limits": {
+					"architecture": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"requests": {
+					"archBaseImages": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
 							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"serviceAccount": {
+					"startScriptVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Specify a Service Account for TiCDC",
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"replicas": {
+					"helper": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The desired ready replicas",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
 						},
 					},
-					"tlsClientSecretNames": {
+					"timezone": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TLSClientSecretNames are the names of secrets that store the client certificates for the downstream.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"baseImage": {
+					"mountTimezoneData": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Base image of the component, image tag is now allowed during validation",
-							Type:        []string{"string"},
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"config": {
+					"limits": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Config is the Configuration of tidbcdc servers",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CDCConfigWraper"),
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"storageVolumes": {
+					"requests": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageVolumes configure additional storage for TiCDC pods.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
 									},
 								},
 							},
 						},
 					},
+					"serviceAccount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Specify a Service Account for pump",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The desired ready replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"baseImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Base image of the component, image tag is now allowed during validation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"storageClassName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The storageClassName of the persistent volume for TiCDC data storage. Defaults to Kubernetes default storage class.",
+							Description: "The storageClassName of the persistent volume for Pump data storage. Defaults to Kubernetes default storage class.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"config": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The configuration of Pump cluster.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/util/config.GenericConfig"),
+						},
+					},
+					"configMapRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value is the config file of pump in TOML. The items of the inline config and the items managed by the operator take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out like the changes of the inline config after the TOML is validated.",
+							Ref:         ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
+						},
+					},
 				},
 				Required: []string{"replicas"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CDCConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "github.com/pingcap/tidb-operator/pkg/apis/util/config.GenericConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.ConfigMapKeySelector", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiDBAccessConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_QueueConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiDBAccessConfig defines the configuration for access tidb cluster",
+				Description: "QueueConfig allows the tuning of remote_write queue_config parameters. This object is referenced in the RemoteWriteSpec object.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"host": {
+					"capacity": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Host is the tidb cluster access address",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Number of samples to buffer per shard before we start dropping them.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"port": {
+					"maxShards": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Port is the port number to use for connecting tidb cluster",
+							Description: "Max number of shards, i.e. amount of concurrency.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"user": {
+					"maxSamplesPerSend": {
 						SchemaProps: spec.SchemaProps{
-							Description: "User is the user for login tidb cluster",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Maximum number of samples per send.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"secretName": {
+					"batchSendDeadline": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SecretName is the name of secret which stores tidb cluster's password.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Maximum time sample will wait in buffer.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"tlsClientSecretName": {
+					"maxRetries": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TLSClientSecretName is the name of secret which stores tidb server client certificate Optional: Defaults to nil",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Max number of times to retry a batch on recoverable errors.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"minBackoff": {
+						SchemaProps: spec.SchemaProps{
+							Description: "On recoverable errors, backoff exponentially.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"maxBackoff": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
 				},
-				Required: []string{"host", "secretName"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiDBConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_ReadOnlySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiDBConfig is the configuration of tidb-server For more detail, refer to https://pingcap.com/docs/stable/reference/configuration/tidb-server/configuration/",
+				Description: "ReadOnlySpec configures the read-only mode of the cluster. The operator sets the global `tidb_super_read_only` system variable of TiDB, so that all the TiDB instances reject the writes of all the users, and turns it off once the mode is disabled. Set `enabled` to false instead of removing readOnly to turn off the mode, as the credentials are required to change the system variable.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"cors": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"socket": {
+					"enabled": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Enabled enables the read-only mode",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"lease": {
+					"user": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 45s",
+							Description: "User is the user to connect to TiDB, it must have the SUPER or SYSTEM_VARIABLES_ADMIN privilege Optional: Defaults to root",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"run-ddl": {
+					"secretName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "SecretName is the name of the Secret that contains the password of the user in the key password",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"split-table": {
+					"haltScheduling": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
+							Description: "HaltScheduling halts the scheduling of PD too while the cluster is read-only, so no region is moved Optional: Defaults to false",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"token-limit": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 1000",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"oom-use-tmp-storage": {
+				},
+				Required: []string{"enabled", "secretName"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_RelabelConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RelabelConfig allows dynamic rewriting of the label set, being applied to samples before ingestion. It defines `<metric_relabel_configs>`-section of Prometheus configuration. More info: https://prometheus.io/docs/prometheus/latest/configuration/configuration/#metric_relabel_configs",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"sourceLabels": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "A list of labels from which values are taken and concatenated with the configured separator in order.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"tmp-storage-path": {
+					"separator": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Separator is the string between concatenated values from the source labels.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"oom-action": {
+					"regex": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to log",
+							Description: "Regular expression against which the extracted value is matched. Default is '(.*)'",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"max-index-length": {
+					"modulus": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 3072",
+							Description: "Modulus to take of the hash of concatenated values from the source labels.",
 							Type:        []string{"integer"},
 							Format:      "int64",
 						},
 					},
-					"mem-quota-query": {
+					"targetLabel": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 34359738368",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "TargetLabel is the label to which the resulting string is written in a replacement. Regexp interpolation is allowed for the replace action.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"tmp-storage-quota": {
+					"replacement": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TempStorageQuota describe the temporary storage Quota during query exector when OOMUseTmpStorage is enabled If the quota exceed the capacity of the TempStoragePath, the tidb-server would exit with fatal error",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Replacement is the regex replacement pattern to be used.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"enable-streaming": {
+					"action": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to false",
-							Type:        []string{"boolean"},
+							Description: "Action is the action to be performed for the relabeling.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"enable-batch-dml": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_RelayLogStorageSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RelayLogStorageSpec configures the upload of the relay logs of dm-worker to the remote storage. The relay logs of each dm-worker are uploaded to the path `<prefix>/<namespace>/<pod name>/` of the storage.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"image": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to false",
-							Type:        []string{"boolean"},
+							Description: "Image of the sidecar, rclone is required to upload to S3 or GCS, e.g. the image of tidb-backup-manager",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"txn-local-latches": {
+					"resources": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated in v4.0.0",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TxnLocalLatches"),
+							Description: "Resources of the sidecar",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
 						},
 					},
-					"lower-case-table-names": {
+					"relayDir": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "RelayDir is the directory of the relay logs, it must be the `relay-dir` of the sources and in the data volume of dm-worker mounted at /var/lib/dm-worker. Optional: Defaults to relay_log in the data directory of dm-worker",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"log": {
+					"interval": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Log"),
+							Description: "Interval is the interval between two uploads, in the format of Go Duration. Optional: Defaults to 5m",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"security": {
+					"localRetention": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Security"),
+							Description: "LocalRetention removes the relay log files from the local volume after they are uploaded and have not been modified for the duration, in the format of Go Duration. It must be longer than the time the subtasks take to replicate the relay logs, as dm-worker can not read them from the remote storage. Optional: Defaults to nil, which means the relay logs are not removed from the local volume by the sidecar",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"status": {
+					"remoteRetention": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Status"),
-						},
-					},
-					"performance": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Performance"),
-						},
-					},
-					"prepared-plan-cache": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PreparedPlanCache"),
-						},
-					},
-					"opentracing": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracing"),
-						},
-					},
-					"proxy-protocol": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyProtocol"),
-						},
-					},
-					"tikv-client": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVClient"),
-						},
-					},
-					"binlog": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Binlog"),
-						},
-					},
-					"compatible-kill-query": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "RemoteRetention removes the relay log files that have not been modified for the duration from the remote storage, in the format of Go Duration. Optional: Defaults to nil, which means the relay logs are retained in the remote storage",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"plugin": {
+					"s3": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Plugin"),
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"),
 						},
 					},
-					"pessimistic-txn": {
+					"gcs": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PessimisticTxn"),
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider"),
 						},
 					},
-					"check-mb4-value-in-utf8": {
+					"azblob": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider"),
 						},
 					},
-					"alter-primary-key": {
+					"local": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to false",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider"),
 						},
 					},
-					"treat-old-version-utf8-as-utf8mb4": {
+				},
+				Required: []string{"image"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider", "k8s.io/api/core/v1.ResourceRequirements"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_RemoteWriteSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RemoteWriteSpec defines the remote_write configuration for prometheus.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"url": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "The URL of the endpoint to send samples to.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"split-region-max-num": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 1000",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"stmt-summary": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StmtSummary"),
-						},
-					},
-					"repair-mode": {
+					"remoteTimeout": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RepairMode indicates that the TiDB is in the repair mode for table meta.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"repair-table-list": {
+					"writeRelabelConfigs": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "The list of remote write relabel configurations.",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RelabelConfig"),
 									},
 								},
 							},
 						},
 					},
-					"isolation-read": {
-						SchemaProps: spec.SchemaProps{
-							Description: "IsolationRead indicates that the TiDB reads data from which isolation level(engine and label).",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.IsolationRead"),
-						},
-					},
-					"max-server-connections": {
-						SchemaProps: spec.SchemaProps{
-							Description: "MaxServerConnections is the maximum permitted number of simultaneous client connections.",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"new_collations_enabled_on_first_bootstrap": {
-						SchemaProps: spec.SchemaProps{
-							Description: "NewCollationsEnabledOnFirstBootstrap indicates if the new collations are enabled, it effects only when a TiDB cluster bootstrapped on the first time.",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
-					"experimental": {
+					"basicAuth": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Experimental contains parameters for experimental features.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Experimental"),
+							Description: "BasicAuth for the URL.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BasicAuth"),
 						},
 					},
-					"enable-dynamic-config": {
+					"bearerToken": {
 						SchemaProps: spec.SchemaProps{
-							Description: "EnableDynamicConfig enables the TiDB to fetch configs from PD and update itself during runtime. see https://github.com/pingcap/tidb/pull/13660 for more details.",
-							Type:        []string{"boolean"},
+							Description: "File to read bearer token for remote write.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"enable-table-lock": {
+					"bearerTokenFile": {
 						SchemaProps: spec.SchemaProps{
-							Description: "imported from v3.1.0",
-							Type:        []string{"boolean"},
+							Description: "File to read bearer token for remote write.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"delay-clean-table-lock": {
-						SchemaProps: spec.SchemaProps{
-							Description: "imported from v3.1.0",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"skip-register-to-dashboard": {
+					"tlsConfig": {
 						SchemaProps: spec.SchemaProps{
-							Description: "imported from v4.0.5 SkipRegisterToDashboard tells TiDB don't register itself to the dashboard.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "TLS Config to use for remote write.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSConfig"),
 						},
 					},
-					"enable-telemetry": {
+					"proxyUrl": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When enabled, usage data (for example, instance versions) will be reported to PingCAP periodically for user experience analytics. If this config is set to `false` on all TiDB servers, telemetry will be always disabled regardless of the value of the global variable `tidb_enable_telemetry`. See PingCAP privacy policy for details: https://pingcap.com/en/privacy-policy/. Imported from v4.0.2. Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "Proxy url",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"labels": {
+					"queueConfig": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Labels are labels for TiDB server",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.QueueConfig"),
 						},
 					},
 				},
+				Required: []string{"url"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Binlog", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Experimental", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.IsolationRead", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Log", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracing", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Performance", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PessimisticTxn", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Plugin", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PreparedPlanCache", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyProtocol", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Security", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Status", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StmtSummary", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVClient", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TxnLocalLatches"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BasicAuth", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.QueueConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RelabelConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiDBProbe(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_ResetStoreLimitTask(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiDBProbe contains details of probing tidb. default probe by TCPPort on 4000.",
+				Description: "ResetStoreLimitTask resets the store limit of all the stores",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"type": {
+					"rate": {
 						SchemaProps: spec.SchemaProps{
-							Description: "\"tcp\" will use TCP socket to connetct port 4000\n\n\"command\" will probe the status api of tidb. This will use curl command to request tidb, before v4.0.9 there is no curl in the image, So do not use this before v4.0.9.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Rate is the store limit to reset to Optional: Defaults to 15",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 				},
@@ -7550,214 +8125,191 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBProbe(ref common.ReferenceCallback) co
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiDBServiceSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_Restore(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiDBServiceSpec defines `.tidb.service` field of `TidbCluster.spec`.",
+				Description: "Restore represents the restoration of backup of a tidb cluster.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"externalTrafficPolicy": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ExternalTrafficPolicy of the service Optional: Defaults to omitted",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"exposeStatus": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether expose the status port Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"mysqlNodePort": {
+					"spec": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Expose the tidb cluster mysql port to MySQLNodePort Optional: Defaults to 0",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"),
 						},
 					},
-					"statusNodePort": {
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_RestoreList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RestoreList contains a list of Restore.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Expose the tidb status node port to StatusNodePort Optional: Defaults to 0",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"additionalPorts": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Expose additional ports for TiDB Optional: Defaults to omitted",
-							Type:        []string{"array"},
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.ServicePort"),
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Restore"),
 									},
 								},
 							},
 						},
 					},
 				},
+				Required: []string{"items"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.ServicePort"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Restore"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiDBSlowLogTailerSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_RestoreSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiDBSlowLogTailerSpec represents an optional log tailer sidecar with TiDB",
+				Description: "RestoreSpec contains the specification for a restore of a tidb cluster backup.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"limits": {
+					"resources": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Ref: ref("k8s.io/api/core/v1.ResourceRequirements"),
 						},
 					},
-					"requests": {
+					"env": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following builtin env vars will be overwritten by values set here - S3_PROVIDER - S3_ENDPOINT - AWS_REGION - AWS_ACL - AWS_STORAGE_CLASS - AWS_DEFAULT_REGION - AWS_ACCESS_KEY_ID - AWS_SECRET_ACCESS_KEY - GCS_PROJECT_ID - GCS_OBJECT_ACL - GCS_BUCKET_ACL - GCS_LOCATION - GCS_STORAGE_CLASS - GCS_SERVICE_ACCOUNT_JSON_KEY - BR_LOG_TO_TERM",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
 									},
 								},
 							},
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiDBSpec contains details of TiDB members",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"version": {
+					"to": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
+							Description: "To is the tidb cluster that needs to restore.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig"),
+						},
+					},
+					"backupType": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type is the backup type for tidb cluster.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"imagePullPolicy": {
+					"restoreMode": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
+							Description: "Mode is the restore mode, snapshot, pitr or volume-snapshot. The pitr mode restores the cluster to a point in time, the snapshot backup in the storage of the Restore is restored first, and then the log backup from the commit ts of the snapshot backup to the restored ts is applied. It's only supported by BR. The volume-snapshot mode creates the PVCs of PD and TiKV of the cluster in `spec.br` from the snapshots of the Backup in `volumeSnapshotBackup`, so the Restore must be created before the TidbCluster. After the TiKV stores are up, the data of TiKV is reset to the commit ts of the Backup by a job, and the cluster must not be written until the Restore is complete. Optional: Defaults to snapshot",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"imagePullSecrets": {
+					"pitr": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
-									},
-								},
-							},
+							Description: "PiTR configures the point-in-time restore, it's required by the pitr mode",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PiTRRestoreSpec"),
 						},
 					},
-					"hostNetwork": {
+					"volumeSnapshotBackup": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"boolean"},
+							Description: "VolumeSnapshotBackup is the name of the complete Backup of the volume-snapshot mode in the same namespace, it's required by the volume-snapshot mode",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"affinity": {
+					"tikvGCLifeTime": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
-							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+							Description: "TikvGCLifeTime is to specify the safe gc life time for restore. The time limit during which data is retained for each GC, in the format of Go Duration. When a GC happens, the current time minus this value is the safe point.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"priorityClassName": {
+					"s3": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"),
 						},
 					},
-					"schedulerName": {
+					"gcs": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider"),
 						},
 					},
-					"nodeSelector": {
+					"azblob": {
 						SchemaProps: spec.SchemaProps{
-							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider"),
 						},
 					},
-					"annotations": {
+					"local": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider"),
 						},
 					},
-					"labels": {
+					"storageClassName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "The storageClassName of the persistent volume for Restore data storage. Defaults to Kubernetes default storage class.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"storageSize": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageSize is the request storage size for backup job",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"br": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BR is the configs for BR.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig"),
 						},
 					},
 					"tolerations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
+							Description: "Base tolerations of restore Pods, components may add more tolerations upon this respectively",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -7768,61 +8320,83 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref common.ReferenceCallback) com
 							},
 						},
 					},
-					"podSecurityContext": {
+					"affinity": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodSecurityContext of the component",
-							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+							Description: "Affinity of restore Pods",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
 						},
 					},
-					"configUpdateStrategy": {
+					"useKMS": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
+							Description: "Use KMS to decrypt the secrets",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"serviceAccount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Specify service account of restore",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"env": {
+					"toolImage": {
 						SchemaProps: spec.SchemaProps{
-							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.EnvVar"),
-									},
-								},
-							},
+							Description: "ToolImage specifies the tool image used in `Restore`, which supports BR and TiDB Lightning images. For examples `spec.toolImage: pingcap/br:v4.0.8` or `spec.toolImage: pingcap/tidb-lightning:v4.0.8` For BR image, if it does not contain tag, Pod will use image 'ToolImage:${TiKV_Version}'.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"initContainers": {
+					"imagePullSecrets": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Init containers of the components",
+							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
 									},
 								},
 							},
 						},
 					},
-					"additionalContainers": {
+					"clusterRegistryPrefix": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional containers of the component.",
+							Description: "ClusterRegistryPrefix rewrites the registry of all the images used by the restore job, including the backup manager and tool images. E.g. with `registry.local/mirror`, `pingcap/br:v5.4.0` is pulled from `registry.local/mirror/pingcap/br:v5.4.0`, which is useful for air-gapped environments",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tableFilter": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TableFilter means Table filter expression for 'db.table' matching. BR supports this from v4.0.3.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
+					"podSecurityContext": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodSecurityContext of the component",
+							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+						},
+					},
+					"priorityClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PriorityClassName of Restore Job Pods",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"additionalVolumes": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volumes of component pod.",
+							Description: "Additional volumes of the Restore Job Pods, e.g. the ConfigMaps or Secrets of custom CA bundles, proxy settings or wrapper scripts",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -7835,7 +8409,7 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref common.ReferenceCallback) com
 					},
 					"additionalVolumeMounts": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volume mounts of component pod.",
+							Description: "Additional volume mounts of the restore container",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -7846,146 +8420,182 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref common.ReferenceCallback) com
 							},
 						},
 					},
-					"terminationGracePeriodSeconds": {
+					"statistics": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Statistics configures the post-step to restore the statistics of the restored tables, which avoids the performance cliff of running with empty statistics",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreStatisticsSpec"),
 						},
 					},
-					"statefulSetUpdateStrategy": {
+					"importWindow": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
-							Type:        []string{"string"},
+							Description: "ImportWindow tunes the PD scheduling and the TiKV import config of the target cluster while the restore is running, the original values are restored after the restore finishes. Only BR restores are supported.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ImportWindowSpec"),
+						},
+					},
+					"force": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Force drops the tables in the target cluster that conflict with the restore set before restoring. Without it, a BR restore is failed with the reason RestoreConflict before the restore job is created if any table of the restore set exists in the target cluster. The restore set is the db or the table of the restore of type db or table, the tables matched by `tableFilter`, or all the user tables. It requires `spec.to`.",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"podManagementPolicy": {
+					"tiflashReplica": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
-							Type:        []string{"string"},
+							Description: "TiFlashReplica configures the post-step to set the TiFlash replicas of the restored tables, which are not restored by BR",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreTiFlashReplicaSpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ImportWindowSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PiTRRestoreSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreStatisticsSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreTiFlashReplicaSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_RestoreStatisticsSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RestoreStatisticsSpec configures how the statistics of the restored tables are restored. The statistics in the backup, i.e. the backup taken with `br.ignoreStats: false`, are loaded by BR during the restore, this post-step covers the tables restored without statistics.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"analyze": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Analyze runs `ANALYZE TABLE` for the restored tables without statistics after the data is restored, the Restore is in the AnalyzingStatistics phase meanwhile. It requires `spec.to`. The failure of analyzing doesn't fail the Restore, but is noted in the Complete condition.",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"topologySpreadConstraints": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-map-keys": []interface{}{
-									"topologyKey",
-								},
-								"x-kubernetes-list-type": "map",
-							},
+					"concurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Concurrency is the number of tables analyzed concurrently Optional: Defaults to 1",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_RestoreTiFlashReplicaSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RestoreTiFlashReplicaSpec configures how the TiFlash replicas of the restored tables are set. The tables are altered by `ALTER TABLE ... SET TIFLASH REPLICA` after the data is restored, the Restore is in the SettingTiFlashReplicas phase until the replicas are available. It requires `spec.to`. The failure of setting the replicas doesn't fail the Restore, but is noted in the Complete condition.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"tables": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Description: "Tables declares the TiFlash replicas of the restored tables",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashReplicaTable"),
 									},
 								},
 							},
 						},
 					},
-					"limits": {
+					"waitTimeout": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
-						},
-					},
-					"requests": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "WaitTimeout is the max time to wait for the TiFlash replicas to be available, e.g. `30m` Optional: Defaults to 1h",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"serviceAccount": {
+				},
+				Required: []string{"tables"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashReplicaTable"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_S3StorageProvider(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "S3StorageProvider represents a S3 compliant storage for storing backups.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"provider": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Specify a Service Account for tidb",
+							Description: "Provider represents the specific storage provider that implements the S3 interface",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"replicas": {
+					"region": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The desired ready replicas",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Region in which the S3 compatible bucket is located.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"baseImage": {
+					"path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Base image of the component, image tag is now allowed during validation",
+							Description: "Path is the full path where the backup is saved. The format of the path must be: \"<bucket-name>/<path-to-backup-file>\"",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"service": {
+					"bucket": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Service defines a Kubernetes service of TiDB cluster. Optional: No kubernetes service will be created by default.",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBServiceSpec"),
+							Description: "Bucket in which to store the backup data.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"binlogEnabled": {
+					"endpoint": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether enable TiDB Binlog, it is encouraged to not set this field and rely on the default behavior Optional: Defaults to true if PumpSpec is non-nil, otherwise false",
-							Type:        []string{"boolean"},
+							Description: "Endpoint of S3 compatible storage service",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"maxFailoverCount": {
+					"storageClass": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover Optional: Defaults to 3",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "StorageClass represents the storage class",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"separateSlowLog": {
+					"acl": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether output the slow log in an separate sidecar container Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "Acl represents access control permissions for this bucket",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"slowLogVolumeName": {
+					"secretName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional volume name configuration for slow query log.",
+							Description: "SecretName is the name of secret which stores S3 compliant storage access key and secret key.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"slowLogTailer": {
+					"prefix": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The specification of the slow log tailer sidecar",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSlowLogTailerSpec"),
+							Description: "Prefix of the data path.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"tlsClient": {
+					"sse": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether enable the TLS connection between the SQL client and TiDB server Optional: Defaults to nil",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBTLSClient"),
+							Description: "SSE Sever-Side Encryption.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"plugins": {
+					"options": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Plugins is a list of plugins that are loaded by TiDB server, empty means plugin disabled",
+							Description: "Options Rclone options for backup and restore with mydumper and lightning.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -7997,71 +8607,78 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref common.ReferenceCallback) com
 							},
 						},
 					},
-					"config": {
+				},
+				Required: []string{"provider"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_SafeTLSConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SafeTLSConfig specifies safe TLS configuration parameters.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"ca": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Config is the Configuration of tidb-servers",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBConfigWraper"),
+							Description: "Struct containing the CA cert to use for the targets.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap"),
 						},
 					},
-					"lifecycle": {
+					"cert": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Lifecycle describes actions that the management system should take in response to container lifecycle events. For the PostStart and PreStop lifecycle handlers, management of the container blocks until the action is complete, unless the container process fails, in which case the handler is aborted.",
-							Ref:         ref("k8s.io/api/core/v1.Lifecycle"),
+							Description: "Struct containing the client cert file for the targets.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap"),
 						},
 					},
-					"storageVolumes": {
+					"keySecret": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageVolumes configure additional storage for TiDB pods.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
-									},
-								},
-							},
+							Description: "Secret containing the client key file for the targets.",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
 						},
 					},
-					"storageClassName": {
+					"serverName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The storageClassName of the persistent volume for TiDB data storage. Defaults to Kubernetes default storage class.",
+							Description: "Used to verify the hostname for the targets.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"readinessProbe": {
+					"insecureSkipVerify": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ReadinessProbe describes actions that probe the tidb's readiness. the default behavior is like setting type as \"tcp\"",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBProbe"),
+							Description: "Disable target certificate validation.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
 				},
-				Required: []string{"replicas"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBProbe", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSlowLogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBTLSClient", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.Lifecycle", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap", "k8s.io/api/core/v1.SecretKeySelector"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiDBTLSClient(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_ScrapeConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiDBTLSClient can enable TLS connection between TiDB server and MySQL client",
+				Description: "ScrapeConfig is the scrape interval and timeout of a component",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"enabled": {
+					"interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When enabled, TiDB will accept TLS encrypted connections from MySQL client The steps to enable this feature:\n  1. Generate a TiDB server-side certificate and a client-side certifiacete for the TiDB cluster.\n     There are multiple ways to generate certificates:\n       - user-provided certificates: https://pingcap.com/docs/stable/how-to/secure/enable-tls-clients/\n       - use the K8s built-in certificate signing system signed certificates: https://kubernetes.io/docs/tasks/tls/managing-tls-in-a-cluster/\n       - or use cert-manager signed certificates: https://cert-manager.io/\n  2. Create a K8s Secret object which contains the TiDB server-side certificate created above.\n     The name of this Secret must be: <clusterName>-tidb-server-secret.\n       kubectl create secret generic <clusterName>-tidb-server-secret --namespace=<namespace> --from-file=tls.crt=<path/to/tls.crt> --from-file=tls.key=<path/to/tls.key> --from-file=ca.crt=<path/to/ca.crt>\n  3. Create a K8s Secret object which contains the TiDB client-side certificate created above which will be used by TiDB Operator.\n     The name of this Secret must be: <clusterName>-tidb-client-secret.\n       kubectl create secret generic <clusterName>-tidb-client-secret --namespace=<namespace> --from-file=tls.crt=<path/to/tls.crt> --from-file=tls.key=<path/to/tls.key> --from-file=ca.crt=<path/to/ca.crt>\n  4. Set Enabled to `true`.",
-							Type:        []string{"boolean"},
+							Description: "Interval is how frequently the targets of the component are scraped, e.g. 30s. Optional: Defaults to 15s",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"disableClientAuthn": {
+					"timeout": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisableClientAuthn will skip client authentication from the TiDB server. Optional: defaults to false",
-							Type:        []string{"boolean"},
+							Description: "Timeout is the timeout of scraping a target of the component, which must be less than the interval. Optional: Defaults to 10s, or the interval if it's less than 10s",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
@@ -8071,112 +8688,104 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBTLSClient(ref common.ReferenceCallback
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiFlashConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_SecretRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiFlashConfig is the configuration of TiFlash.",
+				Description: "SecretRef indicates to secret ref",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"config": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "commonConfig is the Configuration of TiFlash process",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CommonConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"proxy": {
+					"namespace": {
 						SchemaProps: spec.SchemaProps{
-							Description: "proxyConfig is the Configuration of proxy process",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 				},
+				Required: []string{"name", "namespace"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CommonConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiFlashSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_Security(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiFlashSpec contains details of TiFlash members",
+				Description: "Security is the security section of the config.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"version": {
+					"skip-grant-table": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"imagePullPolicy": {
+					"ssl-ca": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"imagePullSecrets": {
+					"ssl-cert": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
-									},
-								},
-							},
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"hostNetwork": {
+					"ssl-key": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"affinity": {
+					"cluster-ssl-ca": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
-							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"priorityClassName": {
+					"cluster-ssl-cert": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"schedulerName": {
+					"cluster-ssl-key": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"nodeSelector": {
-						SchemaProps: spec.SchemaProps{
-							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_ServiceSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ServiceSpec specifies the service object in k8s",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of the real kubernetes service",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 					"annotations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
+							Description: "Additional annotations for the service",
 							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
@@ -8191,7 +8800,7 @@ func schema_pkg_apis_pingcap_v1alpha1_TiFlashSpec(ref common.ReferenceCallback)
 					},
 					"labels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Description: "Additional labels for the service",
 							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
@@ -8204,891 +8813,3837 @@ func schema_pkg_apis_pingcap_v1alpha1_TiFlashSpec(ref common.ReferenceCallback)
 							},
 						},
 					},
-					"tolerations": {
+					"loadBalancerIP": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Toleration"),
-									},
-								},
-							},
+							Description: "LoadBalancerIP is the loadBalancerIP of service Optional: Defaults to omitted",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"podSecurityContext": {
+					"clusterIP": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodSecurityContext of the component",
-							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+							Description: "ClusterIP is the clusterIP of service",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"configUpdateStrategy": {
+					"portName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
+							Description: "PortName is the name of service port",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"env": {
+					"loadBalancerSourceRanges": {
 						SchemaProps: spec.SchemaProps{
-							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
+							Description: "LoadBalancerSourceRanges is the loadBalancerSourceRanges of service If specified and supported by the platform, this will restrict traffic through the cloud-provider load-balancer will be restricted to the specified client IPs. This field will be ignored if the cloud-provider does not support the feature.\" More info: https://kubernetes.io/docs/concepts/services-networking/service/#aws-nlb-support Optional: Defaults to omitted",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"initContainers": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_SilenceWindow(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SilenceWindow is a recurring window during which the matched alerts are silenced in the Alertmanager",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Init containers of the components",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
-									},
-								},
-							},
+							Description: "Name is the unique name of the window in the TidbMonitor",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"additionalContainers": {
+					"schedule": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional containers of the component.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
-									},
-								},
-							},
+							Description: "Schedule is the start time of the window in the Cron format",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"additionalVolumes": {
+					"duration": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volumes of component pod.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Volume"),
-									},
-								},
-							},
+							Description: "Duration is the length of the window, e.g. 2h",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"additionalVolumeMounts": {
+					"matchers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volume mounts of component pod.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
+							Description: "Matchers select the alerts to silence, the keys are the label names and the values are regular expressions",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"terminationGracePeriodSeconds": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"statefulSetUpdateStrategy": {
+					"comment": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Description: "Comment is attached to the silences Optional: Defaults to a description of the window",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"podManagementPolicy": {
+				},
+				Required: []string{"name", "schedule", "duration", "matchers"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_SlackNotificationSink(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SlackNotificationSink is a Slack incoming webhook sink",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"webhookURLSecretRef": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "WebhookURLSecretRef refers to the key of the Secret that contains the URL of the incoming webhook",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
 						},
 					},
-					"topologySpreadConstraints": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-map-keys": []interface{}{
-									"topologyKey",
-								},
-								"x-kubernetes-list-type": "map",
-							},
-						},
+				},
+				Required: []string{"webhookURLSecretRef"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_SpotTerminationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SpotTerminationSpec configures the handling of the nodes that are going to be terminated, e.g. the spot instances to be reclaimed, which are tainted by the node termination handlers. The region leaders of the TiKV stores on these nodes are evicted as soon as the taint is observed.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"taints": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Description: "Taints are the keys of the taints that mark a node as going to be terminated Optional: Defaults to aws-node-termination-handler/spot-itn, aws-node-termination-handler/rebalance-recommendation and cloud.google.com/impending-node-termination",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"limits": {
+					"preCreateReplacement": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "PreCreateReplacement records the TiKV stores and the TiDB members on the nodes going to be terminated as failures, so that the replacement Pods are created before the nodes are terminated. It takes effect only if the failover is enabled and the maxFailoverCount of the component is not reached.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"requests": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_Status(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Status is the status section of the config.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"metrics-addr": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"serviceAccount": {
+					"metrics-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Specify a Service Account for TiFlash",
-							Type:        []string{"string"},
+							Description: "Optional: Defaults to 15",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"report-status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"replicas": {
+					"record-db-qps": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The desired ready replicas",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"baseImage": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_StmtSummary(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StmtSummary is the config for statement summary.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enable": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Base image of the component, image tag is now allowed during validation",
-							Type:        []string{"string"},
+							Description: "Enable statement summary or not.",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"privileged": {
+					"enable-internal-query": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether create the TiFlash container in privileged mode, it is highly discouraged to enable this in critical environment. Optional: defaults to false",
+							Description: "Enable summary internal query.",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"maxFailoverCount": {
+					"max-stmt-count": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover Optional: Defaults to 3",
+							Description: "The maximum number of statements kept in memory. Optional: Defaults to 100",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"storageClaims": {
-						SchemaProps: spec.SchemaProps{
-							Description: "The persistent volume claims of the TiFlash data storages. TiFlash supports multiple disks.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageClaim"),
-									},
-								},
-							},
-						},
-					},
-					"config": {
+					"max-sql-length": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Config is the Configuration of TiFlash",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashConfigWraper"),
+							Description: "The maximum length of displayed normalized SQL and sample SQL. Optional: Defaults to 4096",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"logTailer": {
+					"refresh-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LogTailer is the configurations of the log tailers for TiFlash",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec"),
+							Description: "The refresh interval of statement summary.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"recoverFailover": {
+					"history-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RecoverFailover indicates that Operator can recover the failover Pods",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "The maximum history size of statement summary.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 				},
-				Required: []string{"replicas", "storageClaims"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageClaim", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiKVBackupConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_StorageClaim(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "StorageClaim contains details of TiFlash storages",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"num-threads": {
+					"resources": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Resources represents the minimum resources the volume should have. More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#resources",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+					"storageClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the StorageClass required by the claim. More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#class-1",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ResourceRequirements"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiKVBlockCacheConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_StorageProvider(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiKVBlockCacheConfig is the config of a block cache",
+				Description: "StorageProvider defines the configuration for storing a backup in backend storage.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"shared": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
-					"capacity": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"num-shard-bits": {
+					"s3": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"),
 						},
 					},
-					"strict-capacity-limit": {
+					"gcs": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider"),
 						},
 					},
-					"high-pri-pool-ratio": {
+					"azblob": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"number"},
-							Format: "double",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider"),
 						},
 					},
-					"memory-allocator": {
+					"local": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider"),
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AzblobStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiKVCfConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_StorageProvisioningJob(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiKVCfConfig is the config of a cf",
+				Description: "StorageProvisioningJob is the Job provisioning the storage. The env NAMESPACE, CLUSTER_NAME, COMPONENT, REPLICAS and STORAGE_CLASS_NAME are set for the container.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"block-size": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"block-cache-size": {
+					"image": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Image of the Job",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"disable-block-cache": {
+					"command": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Command of the container, the entrypoint of the image is used if empty",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"cache-index-and-filter-blocks": {
+					"serviceAccountName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "ServiceAccountName of the Job, e.g. the one allowed to manage the volumes of the nodes",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"pin-l0-filter-and-index-blocks": {
+				},
+				Required: []string{"image"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_StorageProvisioningSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StorageProvisioningSpec describes the hook pre-provisioning the storage of a component, e.g. creating the LVM volumes or reserving the local disks, before the StatefulSet of the component is created. Exactly one of webhook and job must be set. The StatefulSet is created once the hook signals the storage is ready, and is not created if the hook fails more than maxRetries times or times out. To retry a failed hook, remove the component from the components and add it back.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"components": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Components are the components the hook is run for, among pd, tikv and tiflash Optional: Defaults to pd, tikv and tiflash",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"use-bloom-filter": {
+					"webhook": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Webhook is called until it responds that the storage is ready",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvisioningWebhook"),
 						},
 					},
-					"optimize-filters-for-hits": {
+					"job": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Job is run for each component, the storage is ready once the Job completes",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvisioningJob"),
 						},
 					},
-					"whole-key-filtering": {
+					"timeout": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Timeout of the hook of a component, counted from the first attempt Optional: Defaults to 30m",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
 						},
 					},
-					"bloom-filter-bits-per-key": {
+					"maxRetries": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "MaxRetries is the maximum number of the retries of the failed attempts, i.e. the failed calls of the webhook or the failed Jobs Optional: Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"block-based-bloom-filter": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvisioningJob", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvisioningWebhook", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_StorageProvisioningWebhook(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StorageProvisioningWebhook is the webhook provisioning the storage. It's called by a POST request with a JSON body of the fields namespace, cluster, component, replicas and storageClassName, and must respond a JSON body of the field ready, which is false while the storage is being provisioned, and optionally the field message.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"url": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "URL of the webhook",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"read-amp-bytes-per-bit": {
+				},
+				Required: []string{"url"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TLSConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TLSConfig extends the safe TLS configuration with file parameters.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"ca": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Struct containing the CA cert to use for the targets.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap"),
 						},
 					},
-					"compression-per-level": {
+					"cert": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Struct containing the client cert file for the targets.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap"),
 						},
 					},
-					"write-buffer-size": {
+					"keySecret": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Secret containing the client key file for the targets.",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
 						},
 					},
-					"max-write-buffer-number": {
+					"serverName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Used to verify the hostname for the targets.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"min-write-buffer-number-to-merge": {
+					"insecureSkipVerify": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Disable target certificate validation.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"max-bytes-for-level-base": {
+					"caFile": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Path to the CA cert in the Prometheus container to use for the targets.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"target-file-size-base": {
+					"certFile": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Path to the client cert file in the Prometheus container for the targets.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"level0-file-num-compaction-trigger": {
+					"keyFile": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Path to the client key file in the Prometheus container for the targets.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SecretOrConfigMap", "k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiCDCChangefeed(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiCDCChangefeed references the Secret storing the sink URI of a changefeed",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"id": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ID is the ID of the changefeed",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sinkURISecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SinkURISecretRef references the key of a Secret in the namespace of the cluster, whose value is the sink URI of the changefeed",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+				},
+				Required: []string{"id", "sinkURISecretRef"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiCDCConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiCDCConfig is the configuration of tidbcdc ref https://github.com/pingcap/ticdc/blob/a28d9e43532edc4a0380f0ef87314631bf18d866/pkg/config/config.go#L176",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time zone of TiCDC Optional: Defaults to UTC",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"gcTTL": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CDC GC safepoint TTL duration, specified in seconds Optional: Defaults to 86400",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"logLevel": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogLevel is the log level Optional: Defaults to info",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"logFile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogFile is the log file Optional: Defaults to /dev/stderr",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiCDCSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiCDCSpec contains details of TiCDC members",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imagePullPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imagePullSecrets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
+						},
+					},
+					"hostNetwork": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"affinity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+						},
+					},
+					"priorityClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"schedulerName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"annotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"tolerations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
+						},
+					},
+					"podSecurityContext": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodSecurityContext of the component",
+							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+						},
+					},
+					"configUpdateStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"initContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Init containers of the components",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
+						},
+					},
+					"additionalContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional containers of the component.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
+						},
+					},
+					"additionalVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volumes of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Volume"),
+									},
+								},
+							},
+						},
+					},
+					"additionalVolumeMounts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volume mounts of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
+						},
+					},
+					"terminationGracePeriodSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"statefulSetUpdateStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"podManagementPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"minReadySeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rollingUpdateIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"topologySpreadConstraints": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"topologyKey",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+									},
+								},
+							},
+						},
+					},
+					"architecture": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archBaseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"startScriptVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"helper": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"limits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"requests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"serviceAccount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Specify a Service Account for TiCDC",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The desired ready replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"tlsClientSecretNames": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TLSClientSecretNames are the names of secrets that store the client certificates for the downstream.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"baseImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Base image of the component, image tag is now allowed during validation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"config": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Config is the Configuration of tidbcdc servers",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CDCConfigWraper"),
+						},
+					},
+					"configMapRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value is the config file of tidbcdc servers in TOML. The items of the inline config and the items managed by the operator take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out like the changes of the inline config after the TOML is validated.",
+							Ref:         ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
+						},
+					},
+					"storageVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageVolumes configure additional storage for TiCDC pods.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
+									},
+								},
+							},
+						},
+					},
+					"storageClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The storageClassName of the persistent volume for TiCDC data storage. Defaults to Kubernetes default storage class.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"gracefulShutdownTimeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "GracefulShutdownTimeout is the timeout of resigning the ownership and draining the tables of a capture before its Pod is restarted during the upgrade, in the format of Go Duration. Defaults to 10m",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"changefeeds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Changefeeds are the changefeeds whose sink URIs are kept in sync with the Secrets they reference. The Secrets are watched, and when the sink URI in a Secret is changed, e.g. the credentials of the sink are rotated, the changefeed is paused, updated in place and resumed from its checkpoint instead of being recreated. The changefeeds are created by the users, and the changefeeds paused by the users are updated and kept paused.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCChangefeed"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"replicas"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CDCConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCChangefeed", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.ConfigMapKeySelector", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiDBAccessConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiDBAccessConfig defines the configuration for access tidb cluster",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"host": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Host is the tidb cluster access address",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"port": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Port is the port number to use for connecting tidb cluster",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User is the user for login tidb cluster",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"secretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecretName is the name of secret which stores tidb cluster's password.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tlsClientSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TLSClientSecretName is the name of secret which stores tidb server client certificate Optional: Defaults to nil",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"host", "secretName"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiDBConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiDBConfig is the configuration of tidb-server For more detail, refer to https://pingcap.com/docs/stable/reference/configuration/tidb-server/configuration/",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"cors": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"socket": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"lease": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 45s",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"run-ddl": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"split-table": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"token-limit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 1000",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"oom-use-tmp-storage": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"tmp-storage-path": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"oom-action": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to log",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"max-index-length": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 3072",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"mem-quota-query": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 34359738368",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"tmp-storage-quota": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TempStorageQuota describe the temporary storage Quota during query exector when OOMUseTmpStorage is enabled If the quota exceed the capacity of the TempStoragePath, the tidb-server would exit with fatal error",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"enable-streaming": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"enable-batch-dml": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"txn-local-latches": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Deprecated in v4.0.0",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TxnLocalLatches"),
+						},
+					},
+					"lower-case-table-names": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"log": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Log"),
+						},
+					},
+					"security": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Security"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Status"),
+						},
+					},
+					"performance": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Performance"),
+						},
+					},
+					"prepared-plan-cache": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PreparedPlanCache"),
+						},
+					},
+					"opentracing": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracing"),
+						},
+					},
+					"proxy-protocol": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyProtocol"),
+						},
+					},
+					"tikv-client": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVClient"),
+						},
+					},
+					"binlog": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Binlog"),
+						},
+					},
+					"compatible-kill-query": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"plugin": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Plugin"),
+						},
+					},
+					"pessimistic-txn": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PessimisticTxn"),
+						},
+					},
+					"check-mb4-value-in-utf8": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"alter-primary-key": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"treat-old-version-utf8-as-utf8mb4": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"split-region-max-num": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 1000",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"stmt-summary": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StmtSummary"),
+						},
+					},
+					"repair-mode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RepairMode indicates that the TiDB is in the repair mode for table meta.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"repair-table-list": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"isolation-read": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IsolationRead indicates that the TiDB reads data from which isolation level(engine and label).",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.IsolationRead"),
+						},
+					},
+					"max-server-connections": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxServerConnections is the maximum permitted number of simultaneous client connections.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"new_collations_enabled_on_first_bootstrap": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NewCollationsEnabledOnFirstBootstrap indicates if the new collations are enabled, it effects only when a TiDB cluster bootstrapped on the first time.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"experimental": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Experimental contains parameters for experimental features.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Experimental"),
+						},
+					},
+					"enable-dynamic-config": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EnableDynamicConfig enables the TiDB to fetch configs from PD and update itself during runtime. see https://github.com/pingcap/tidb/pull/13660 for more details.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"enable-table-lock": {
+						SchemaProps: spec.SchemaProps{
+							Description: "imported from v3.1.0",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"delay-clean-table-lock": {
+						SchemaProps: spec.SchemaProps{
+							Description: "imported from v3.1.0",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"skip-register-to-dashboard": {
+						SchemaProps: spec.SchemaProps{
+							Description: "imported from v4.0.5 SkipRegisterToDashboard tells TiDB don't register itself to the dashboard.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"enable-telemetry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When enabled, usage data (for example, instance versions) will be reported to PingCAP periodically for user experience analytics. If this config is set to `false` on all TiDB servers, telemetry will be always disabled regardless of the value of the global variable `tidb_enable_telemetry`. See PingCAP privacy policy for details: https://pingcap.com/en/privacy-policy/. Imported from v4.0.2. Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels are labels for TiDB server",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Binlog", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Experimental", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.IsolationRead", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Log", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.OpenTracing", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Performance", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PessimisticTxn", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Plugin", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PreparedPlanCache", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyProtocol", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Security", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Status", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StmtSummary", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVClient", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TxnLocalLatches"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiDBProbe(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiDBProbe contains details of probing tidb. default probe by TCPPort on 4000.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "\"tcp\" will use TCP socket to connetct port 4000\n\n\"command\" will probe the status api of tidb. This will use curl command to request tidb, before v4.0.9 there is no curl in the image, So do not use this before v4.0.9.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiDBServiceSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiDBServiceSpec defines `.tidb.service` field of `TidbCluster.spec`.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"externalTrafficPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExternalTrafficPolicy of the service Optional: Defaults to omitted",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"exposeStatus": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether expose the status port Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"mysqlNodePort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Expose the tidb cluster mysql port to MySQLNodePort Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"statusNodePort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Expose the tidb status node port to StatusNodePort Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"additionalPorts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Expose additional ports for TiDB Optional: Defaults to omitted",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.ServicePort"),
+									},
+								},
+							},
+						},
+					},
+					"internalTrafficPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "InternalTrafficPolicy of the service, `Local` routes the traffic from within the cluster only to the TiDB on the same node as the client. It's supported by Kubernetes v1.22 and later. Optional: Defaults to omitted",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"topologyAwareHints": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TopologyAwareHints enables the topology aware hints of the service, which routes the traffic to the TiDB in the same zone as the client when the TiDB are evenly spread across the zones. It can't be enabled with the `Local` internal or external traffic policy. Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"sessionAffinity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SessionAffinity of the service, `ClientIP` routes the connections from the same client to the same TiDB Optional: Defaults to omitted",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sessionAffinityConfig": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SessionAffinityConfig of the service, only valid with the `ClientIP` session affinity",
+							Ref:         ref("k8s.io/api/core/v1.SessionAffinityConfig"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ServicePort", "k8s.io/api/core/v1.SessionAffinityConfig"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiDBSlowLogTailerSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiDBSlowLogTailerSpec represents an optional log tailer sidecar with TiDB",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"limits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"requests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiDBSpec contains details of TiDB members",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imagePullPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imagePullSecrets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
+						},
+					},
+					"hostNetwork": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"affinity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+						},
+					},
+					"priorityClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"schedulerName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"annotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"tolerations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
+						},
+					},
+					"podSecurityContext": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodSecurityContext of the component",
+							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+						},
+					},
+					"configUpdateStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"initContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Init containers of the components",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
+						},
+					},
+					"additionalContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional containers of the component.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
+						},
+					},
+					"additionalVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volumes of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Volume"),
+									},
+								},
+							},
+						},
+					},
+					"additionalVolumeMounts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volume mounts of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
+						},
+					},
+					"terminationGracePeriodSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"statefulSetUpdateStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"podManagementPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"minReadySeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rollingUpdateIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"topologySpreadConstraints": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"topologyKey",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+									},
+								},
+							},
+						},
+					},
+					"architecture": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archBaseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"startScriptVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"helper": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"limits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"requests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"serviceAccount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Specify a Service Account for tidb",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The desired ready replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"baseImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Base image of the component, image tag is now allowed during validation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"service": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Service defines a Kubernetes service of TiDB cluster. Optional: No kubernetes service will be created by default.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBServiceSpec"),
+						},
+					},
+					"perPodService": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PerPodService defines the Kubernetes services created for each TiDB Pod, named after the Pods, e.g. ${cluster}-tidb-0, for client affinity and isolation. The services of the Pods removed by scale-in are deleted. The IPs and node ports can't be specified as they are shared by the services. Optional: No per-pod service will be created by default.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBServiceSpec"),
+						},
+					},
+					"binlogEnabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether enable TiDB Binlog, it is encouraged to not set this field and rely on the default behavior Optional: Defaults to true if PumpSpec is non-nil, otherwise false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"maxFailoverCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover Optional: Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"progressDeadlineSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProgressDeadlineSeconds is the max seconds for a Pod to complete its update in a rolling update. If no Pod completes its update within the deadline, the rolling update is paused with the ProgressDeadlineExceeded condition, and no more Pods are deleted until the update revision is changed, or the deadline is raised or removed. Optional: Defaults to no deadline",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"resizePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResizePolicy is the restart policy of the resources of the TiDB container when the CPU and memory are resized in place, which is enabled by the InPlacePodVerticalScaling feature gate. The Pods are rolling updated instead if a resized resource requires the restart. The resources not listed don't require the restart.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ContainerResizePolicy"),
+									},
+								},
+							},
+						},
+					},
+					"separateSlowLog": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether output the slow log in an separate sidecar container Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"slowLogVolumeName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional volume name configuration for slow query log.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"slowLogTailer": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The specification of the slow log tailer sidecar",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSlowLogTailerSpec"),
+						},
+					},
+					"tlsClient": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether enable the TLS connection between the SQL client and TiDB server Optional: Defaults to nil",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBTLSClient"),
+						},
+					},
+					"plugins": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Plugins is a list of plugins that are loaded by TiDB server, empty means plugin disabled",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"config": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Config is the Configuration of tidb-servers",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBConfigWraper"),
+						},
+					},
+					"configMapRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value is the config file of tidb-servers in TOML. The items of the inline config and the items managed by the operator take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out like the changes of the inline config after the TOML is validated.",
+							Ref:         ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
+						},
+					},
+					"lifecycle": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Lifecycle describes actions that the management system should take in response to container lifecycle events. For the PostStart and PreStop lifecycle handlers, management of the container blocks until the action is complete, unless the container process fails, in which case the handler is aborted.",
+							Ref:         ref("k8s.io/api/core/v1.Lifecycle"),
+						},
+					},
+					"storageVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageVolumes configure additional storage for TiDB pods.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
+									},
+								},
+							},
+						},
+					},
+					"storageClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The storageClassName of the persistent volume for TiDB data storage. Defaults to Kubernetes default storage class.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"readinessProbe": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadinessProbe describes actions that probe the tidb's readiness. the default behavior is like setting type as \"tcp\"",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBProbe"),
+						},
+					},
+					"deleteSlots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeleteSlots are the ordinals of the Pods to delete, in addition to the ones in the `tidb.tidb.pingcap.com/delete-slots` annotation. The deleted Pods are replaced by Pods of new ordinals unless the replicas are decreased accordingly. Only takes effect when advanced StatefulSet is enabled.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
+						},
+					},
+					"podDisruptionBudget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodDisruptionBudget configures the PodDisruptionBudget of the TiDB pods. No PodDisruptionBudget is created if it's not set.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec"),
+						},
+					},
+					"upgradeStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UpgradeStrategy configures the rolling upgrade of the TiDB pods.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBUpgradeStrategy"),
+						},
+					},
+					"port": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Port is the port that TiDB serves the MySQL protocol on. Optional: Defaults to 4000",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"statusPort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StatusPort is the port that TiDB serves the status API and the metrics on. Optional: Defaults to 10080",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"debugArtifacts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DebugArtifacts configures the capture of the core dumps and the heap profiles of TiDB for the postmortems when TiDB is OOMKilled or crashes",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DebugArtifactsSpec"),
+						},
+					},
+				},
+				Required: []string{"replicas"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ContainerResizePolicy", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DebugArtifactsSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBProbe", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSlowLogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBTLSClient", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBUpgradeStrategy", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.ConfigMapKeySelector", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.Lifecycle", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiDBTLSClient(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiDBTLSClient can enable TLS connection between TiDB server and MySQL client",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When enabled, TiDB will accept TLS encrypted connections from MySQL client The steps to enable this feature:\n  1. Generate a TiDB server-side certificate and a client-side certifiacete for the TiDB cluster.\n     There are multiple ways to generate certificates:\n       - user-provided certificates: https://pingcap.com/docs/stable/how-to/secure/enable-tls-clients/\n       - use the K8s built-in certificate signing system signed certificates: https://kubernetes.io/docs/tasks/tls/managing-tls-in-a-cluster/\n       - or use cert-manager signed certificates: https://cert-manager.io/\n  2. Create a K8s Secret object which contains the TiDB server-side certificate created above.\n     The name of this Secret must be: <clusterName>-tidb-server-secret.\n       kubectl create secret generic <clusterName>-tidb-server-secret --namespace=<namespace> --from-file=tls.crt=<path/to/tls.crt> --from-file=tls.key=<path/to/tls.key> --from-file=ca.crt=<path/to/ca.crt>\n  3. Create a K8s Secret object which contains the TiDB client-side certificate created above which will be used by TiDB Operator.\n     The name of this Secret must be: <clusterName>-tidb-client-secret.\n       kubectl create secret generic <clusterName>-tidb-client-secret --namespace=<namespace> --from-file=tls.crt=<path/to/tls.crt> --from-file=tls.key=<path/to/tls.key> --from-file=ca.crt=<path/to/ca.crt>\n  4. Set Enabled to `true`.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disableClientAuthn": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisableClientAuthn will skip client authentication from the TiDB server. Optional: defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiDBUpgradeStrategy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiDBUpgradeStrategy is the strategy of the rolling upgrade of TiDB",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"maxUnavailable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxUnavailable is the max number of the TiDB pods that can be restarted at the same time during the upgrade, it can be an absolute number or a percentage of the replicas, which is rounded down. The pods are evicted through the Eviction API, so the PodDisruptionBudget of TiDB is honored. Optional: Defaults to 1, i.e. the pods are upgraded one by one",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/util/intstr.IntOrString"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiFlashConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiFlashConfig is the configuration of TiFlash.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"config": {
+						SchemaProps: spec.SchemaProps{
+							Description: "commonConfig is the Configuration of TiFlash process",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CommonConfig"),
+						},
+					},
+					"proxy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "proxyConfig is the Configuration of proxy process",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyConfig"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CommonConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyConfig"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiFlashReplicaTable(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiFlashReplicaTable declares the TiFlash replicas of a table, or of all the tables of a database",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"db": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DB is the database of the tables",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"table": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Table is the table, all the tables of the database are set if it's empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Replicas is the number of the TiFlash replicas, 0 removes the TiFlash replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"db", "replicas"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiFlashSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiFlashSpec contains details of TiFlash members",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imagePullPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imagePullSecrets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
+						},
+					},
+					"hostNetwork": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"affinity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+						},
+					},
+					"priorityClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"schedulerName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"annotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"tolerations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
+						},
+					},
+					"podSecurityContext": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodSecurityContext of the component",
+							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+						},
+					},
+					"configUpdateStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"initContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Init containers of the components",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
+						},
+					},
+					"additionalContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional containers of the component.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
+						},
+					},
+					"additionalVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volumes of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Volume"),
+									},
+								},
+							},
+						},
+					},
+					"additionalVolumeMounts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volume mounts of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
+						},
+					},
+					"terminationGracePeriodSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"statefulSetUpdateStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"podManagementPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"minReadySeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rollingUpdateIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"topologySpreadConstraints": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"topologyKey",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+									},
+								},
+							},
+						},
+					},
+					"architecture": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archBaseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"startScriptVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"helper": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"limits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"requests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"serviceAccount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Specify a Service Account for TiFlash",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The desired ready replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"baseImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Base image of the component, image tag is now allowed during validation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"privileged": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether create the TiFlash container in privileged mode, it is highly discouraged to enable this in critical environment. Optional: defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"maxFailoverCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover Optional: Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"storageClaims": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The persistent volume claims of the TiFlash data storages. TiFlash supports multiple disks.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageClaim"),
+									},
+								},
+							},
+						},
+					},
+					"config": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Config is the Configuration of TiFlash",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashConfigWraper"),
+						},
+					},
+					"configMapRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value is the common config file of TiFlash in TOML. The items of `config.config` and the items managed by the operator take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out like the changes of the inline config after the TOML is validated.",
+							Ref:         ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
+						},
+					},
+					"logTailer": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogTailer is the configurations of the log tailers for TiFlash",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec"),
+						},
+					},
+					"recoverFailover": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RecoverFailover indicates that Operator can recover the failover Pods",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"deleteSlots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeleteSlots are the ordinals of the Pods to delete, in addition to the ones in the `tiflash.tidb.pingcap.com/delete-slots` annotation. The deleted Pods are replaced by Pods of new ordinals unless the replicas are decreased accordingly. Only takes effect when advanced StatefulSet is enabled.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
+						},
+					},
+					"storeLabels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StoreLabels configures additional labels for TiFlash stores, which are set from the labels of the nodes in addition to the location labels of PD.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"podDisruptionBudget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodDisruptionBudget configures the PodDisruptionBudget of the TiFlash pods. No PodDisruptionBudget is created if it's not set.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec"),
+						},
+					},
+				},
+				Required: []string{"replicas", "storageClaims"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageClaim", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.ConfigMapKeySelector", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVBackupConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"num-threads": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVBlockCacheConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVBlockCacheConfig is the config of a block cache",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"shared": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"capacity": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"num-shard-bits": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"strict-capacity-limit": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"high-pri-pool-ratio": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+					"memory-allocator": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVCfConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVCfConfig is the config of a cf",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"block-size": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"block-cache-size": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"disable-block-cache": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"cache-index-and-filter-blocks": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"pin-l0-filter-and-index-blocks": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"use-bloom-filter": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"optimize-filters-for-hits": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"whole-key-filtering": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"bloom-filter-bits-per-key": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"block-based-bloom-filter": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"read-amp-bytes-per-bit": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"compression-per-level": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"write-buffer-size": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"max-write-buffer-number": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"min-write-buffer-number-to-merge": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"max-bytes-for-level-base": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"target-file-size-base": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"level0-file-num-compaction-trigger": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
 					"level0-slowdown-writes-trigger": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"level0-stop-writes-trigger": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"max-compaction-bytes": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"compaction-pri": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"dynamic-level-bytes": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"num-levels": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"max-bytes-for-level-multiplier": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"compaction-style": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"disable-auto-compactions": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"soft-pending-compaction-bytes-limit": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"hard-pending-compaction-bytes-limit": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"force-consistency-checks": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"prop-size-index-distance": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"prop-keys-index-distance": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"enable-doubly-skiplist": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"titan": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanCfConfig"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanCfConfig"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVClient(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVClient is the config for tikv client.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"grpc-connection-count": {
+						SchemaProps: spec.SchemaProps{
+							Description: "GrpcConnectionCount is the max gRPC connections that will be established with each tikv-server. Optional: Defaults to 16",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"grpc-keepalive-time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "After a duration of this time in seconds if the client doesn't see any activity it pings the server to see if the transport is still alive. Optional: Defaults to 10",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"grpc-keepalive-timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "After having pinged for keepalive check, the client waits for a duration of Timeout in seconds and if no activity is seen even after that the connection is closed. Optional: Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"commit-timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CommitTimeout is the max time which command 'commit' will wait. Optional: Defaults to 41s",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"max-txn-time-use": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Deprecated in v4.0.0 MaxTxnTimeUse is the max time a Txn may use (in seconds) from its startTS to commitTS. Optional: Defaults to 590",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"max-batch-size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxBatchSize is the max batch size when calling batch commands API. Optional: Defaults to 128",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"overload-threshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "If TiKV load is greater than this, TiDB will wait for a while to avoid little batch. Optional: Defaults to 200",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"max-batch-wait-time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxBatchWaitTime in nanosecond is the max wait time for batch. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"batch-wait-size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BatchWaitSize is the max wait size for batch. Optional: Defaults to 8",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"region-cache-ttl": {
+						SchemaProps: spec.SchemaProps{
+							Description: "If a Region has not been accessed for more than the given duration (in seconds), it will be reloaded from the PD. Optional: Defaults to 600",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"store-limit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "If a store has been up to the limit, it will return error for successive request to prevent the store occupying too much token in dispatching level. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"store-liveness-timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StoreLivenessTimeout is the timeout for store liveness check request.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"copr-cache": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CoprocessorCache"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CoprocessorCache"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVColdGroupSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVColdGroupSpec is the spec of the TiKV cold group, the fields not set are inherited from TiKV",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"limits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"requests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The desired ready replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"storageClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The storageClassName of the persistent volume for the data of the group Defaults to the storageClassName of TiKV",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelector of the Pods of the group, it overrides the one of TiKV",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"tolerations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tolerations of the Pods of the group, they override the ones of TiKV",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
+						},
+					},
+					"storeLabels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StoreLabels are the labels set to the stores of the group when they start, they are used to exclude the stores from the default placement rule and to pin the cold data. It can't be changed once set. Optional: Defaults to {\"tier\": \"cold\"}",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"replicas"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.Toleration", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVConfig is the configuration of TiKV.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"log-level": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to info",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"log-file": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"log-format": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"slow-log-file": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"slow-log-threshold": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"log-rotation-timespan": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 24h",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"log-rotation-size": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"refresh-config-interval": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"panic-when-unexpected-key-or-data": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"server": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVServerConfig"),
+						},
+					},
+					"storage": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageConfig"),
+						},
+					},
+					"raftstore": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftstoreConfig"),
+						},
+					},
+					"rocksdb": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVDbConfig"),
+						},
+					},
+					"coprocessor": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig"),
+						},
+					},
+					"readpool": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVReadPoolConfig"),
+						},
+					},
+					"raftdb": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftDBConfig"),
+						},
+					},
+					"import": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVImportConfig"),
+						},
+					},
+					"gc": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVGCConfig"),
+						},
+					},
+					"pd": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPDConfig"),
+						},
+					},
+					"security": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfig"),
+						},
+					},
+					"pessimistic-txn": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPessimisticTxn"),
+						},
+					},
+					"backup": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBackupConfig"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBackupConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVDbConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVGCConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVImportConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPDConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPessimisticTxn", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftDBConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftstoreConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVReadPoolConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVServerConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageConfig"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVCoprocessorConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVCoprocessorConfig is the configuration of TiKV Coprocessor component.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"split-region-on-table": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When it is set to `true`, TiKV will try to split a Region with table prefix if that Region crosses tables. It is recommended to turn off this option if there will be a large number of tables created. Optional: Defaults to false optional",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"batch-split-limit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "One split check produces several split keys in batch. This config limits the number of produced split keys in one batch. optional",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"region-max-size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When Region [a,e) size exceeds `region-max-size`, it will be split into several Regions [a,b), [b,c), [c,d), [d,e) and the size of [a,b), [b,c), [c,d) will be `region-split-size` (or a little larger). See also: region-split-size Optional: Defaults to 144MB optional",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"region-split-size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When Region [a,e) size exceeds `region-max-size`, it will be split into several Regions [a,b), [b,c), [c,d), [d,e) and the size of [a,b), [b,c), [c,d) will be `region-split-size` (or a little larger). See also: region-max-size Optional: Defaults to 96MB optional",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"region-max-keys": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When the number of keys in Region [a,e) exceeds the `region-max-keys`, it will be split into several Regions [a,b), [b,c), [c,d), [d,e) and the number of keys in [a,b), [b,c), [c,d) will be `region-split-keys`. See also: region-split-keys Optional: Defaults to 1440000 optional",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"region-split-keys": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When the number of keys in Region [a,e) exceeds the `region-max-keys`, it will be split into several Regions [a,b), [b,c), [c,d), [d,e) and the number of keys in [a,b), [b,c), [c,d) will be `region-split-keys`. See also: region-max-keys Optional: Defaults to 960000 optional",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVCoprocessorReadPoolConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"high-concurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 8",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"normal-concurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 8",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"low-concurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 8",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"max-tasks-per-worker-high": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 2000",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"max-tasks-per-worker-normal": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 2000",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"max-tasks-per-worker-low": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 2000",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"stack-size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 10MB",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"level0-stop-writes-trigger": {
+					"use-unified-pool": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVDbConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVDbConfig is the rocksdb config.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"wal-recovery-mode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 2",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"wal-ttl-seconds": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"max-compaction-bytes": {
+					"wal-size-limit": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"compaction-pri": {
+					"max-total-wal-size": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Optional: Defaults to 4GB",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"dynamic-level-bytes": {
+					"max-background-jobs": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Optional: Defaults to 8",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"num-levels": {
+					"max-manifest-file-size": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Optional: Defaults to 128MB",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"max-bytes-for-level-multiplier": {
+					"create-if-missing": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"compaction-style": {
+					"max-open-files": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Optional: Defaults to 40960",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"disable-auto-compactions": {
+					"enable-statistics": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"soft-pending-compaction-bytes-limit": {
+					"stats-dump-period": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Optional: Defaults to 10m",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"hard-pending-compaction-bytes-limit": {
+					"compaction-readahead-size": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Optional: Defaults to 0",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"force-consistency-checks": {
+					"info-log-max-size": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
+							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"prop-size-index-distance": {
+					"info-log-roll-time": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"prop-keys-index-distance": {
+					"info-log-keep-log-file-num": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"enable-doubly-skiplist": {
+					"info-log-dir": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
+							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"titan": {
+					"rate-bytes-per-sec": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanCfConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanCfConfig"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVClient(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiKVClient is the config for tikv client.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"grpc-connection-count": {
+					"rate-limiter-mode": {
 						SchemaProps: spec.SchemaProps{
-							Description: "GrpcConnectionCount is the max gRPC connections that will be established with each tikv-server. Optional: Defaults to 16",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"grpc-keepalive-time": {
+					"auto-tuned": {
 						SchemaProps: spec.SchemaProps{
-							Description: "After a duration of this time in seconds if the client doesn't see any activity it pings the server to see if the transport is still alive. Optional: Defaults to 10",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"grpc-keepalive-timeout": {
+					"bytes-per-sync": {
 						SchemaProps: spec.SchemaProps{
-							Description: "After having pinged for keepalive check, the client waits for a duration of Timeout in seconds and if no activity is seen even after that the connection is closed. Optional: Defaults to 3",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"commit-timeout": {
+					"wal-bytes-per-sync": {
 						SchemaProps: spec.SchemaProps{
-							Description: "CommitTimeout is the max time which command 'commit' will wait. Optional: Defaults to 41s",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"max-txn-time-use": {
+					"max-sub-compactions": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated in v4.0.0 MaxTxnTimeUse is the max time a Txn may use (in seconds) from its startTS to commitTS. Optional: Defaults to 590",
+							Description: "Optional: Defaults to 3",
 							Type:        []string{"integer"},
-							Format:      "int32",
+							Format:      "int64",
 						},
 					},
-					"max-batch-size": {
+					"writable-file-max-buffer-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxBatchSize is the max batch size when calling batch commands API. Optional: Defaults to 128",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"overload-threshold": {
+					"use-direct-io-for-flush-and-compaction": {
 						SchemaProps: spec.SchemaProps{
-							Description: "If TiKV load is greater than this, TiDB will wait for a while to avoid little batch. Optional: Defaults to 200",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"max-batch-wait-time": {
+					"enable-pipelined-write": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxBatchWaitTime in nanosecond is the max wait time for batch. Optional: Defaults to 0",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"batch-wait-size": {
+					"defaultcf": {
 						SchemaProps: spec.SchemaProps{
-							Description: "BatchWaitSize is the max wait size for batch. Optional: Defaults to 8",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"),
 						},
 					},
-					"region-cache-ttl": {
+					"writecf": {
 						SchemaProps: spec.SchemaProps{
-							Description: "If a Region has not been accessed for more than the given duration (in seconds), it will be reloaded from the PD. Optional: Defaults to 600",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"),
 						},
 					},
-					"store-limit": {
+					"lockcf": {
 						SchemaProps: spec.SchemaProps{
-							Description: "If a store has been up to the limit, it will return error for successive request to prevent the store occupying too much token in dispatching level. Optional: Defaults to 0",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"),
 						},
 					},
-					"store-liveness-timeout": {
+					"raftcf": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StoreLivenessTimeout is the timeout for store liveness check request.",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"),
 						},
 					},
-					"copr-cache": {
+					"titan": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CoprocessorCache"),
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanDBConfig"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CoprocessorCache"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanDBConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiKVConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TiKVEncryptionConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiKVConfig is the configuration of TiKV.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"log-level": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to info",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"log-file": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"log-format": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"slow-log-file": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"slow-log-threshold": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"log-rotation-timespan": {
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"data-encryption-method": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 24h",
+							Description: "Encrypyion method, use data key encryption raw rocksdb data Possible values: plaintext, aes128-ctr, aes192-ctr, aes256-ctr Optional: Default to plaintext optional",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"log-rotation-size": {
+					"data-key-rotation-period": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "The frequency of datakey rotation, It managered by tikv Optional: default to 7d optional",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"refresh-config-interval": {
+					"master-key": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Master key config",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVMasterKeyConfig"),
 						},
 					},
-					"panic-when-unexpected-key-or-data": {
+					"previous-master-key": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Previous master key config It used in master key rotation, the data key should decryption by previous master key and  then encrypytion by new master key",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVMasterKeyConfig"),
 						},
 					},
-					"server": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVMasterKeyConfig"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVGCConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"batch-keys": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVServerConfig"),
+							Description: "Optional: Defaults to 512",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"storage": {
+					"max-write-bytes-per-sec": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"raftstore": {
+					"enable-compaction-filter": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftstoreConfig"),
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"rocksdb": {
+					"compaction-filter-skip-version-check": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVDbConfig"),
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"coprocessor": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVImportConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"import-dir": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"readpool": {
+					"num-threads": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVReadPoolConfig"),
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"raftdb": {
+					"num-import-jobs": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftDBConfig"),
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"import": {
+					"num-import-sst-jobs": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVImportConfig"),
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"gc": {
+					"max-prepare-duration": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVGCConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"pd": {
+					"region-split-size": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPDConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"security": {
+					"stream-channel-window": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfig"),
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"pessimistic-txn": {
+					"max-open-engines": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPessimisticTxn"),
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"backup": {
+					"upload-speed-limit": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBackupConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 				},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBackupConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVDbConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVGCConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVImportConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPDConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPessimisticTxn", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftDBConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVRaftstoreConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVReadPoolConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVServerConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiKVCoprocessorConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TiKVMasterKeyConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiKVCoprocessorConfig is the configuration of TiKV Coprocessor component.",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"split-region-on-table": {
+					"type": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When it is set to `true`, TiKV will try to split a Region with table prefix if that Region crosses tables. It is recommended to turn off this option if there will be a large number of tables created. Optional: Defaults to false optional",
-							Type:        []string{"boolean"},
+							Description: "Use KMS encryption or use file encryption, possible values: kms, file If set to kms, kms MasterKeyKMSConfig should be filled, if set to file MasterKeyFileConfig should be filled optional",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"batch-split-limit": {
+					"method": {
 						SchemaProps: spec.SchemaProps{
-							Description: "One split check produces several split keys in batch. This config limits the number of produced split keys in one batch. optional",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Encrypyion method, use master key encryption data key Possible values: plaintext, aes128-ctr, aes192-ctr, aes256-ctr Optional: Default to plaintext optional",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"region-max-size": {
+					"path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When Region [a,e) size exceeds `region-max-size`, it will be split into several Regions [a,b), [b,c), [c,d), [d,e) and the size of [a,b), [b,c), [c,d) will be `region-split-size` (or a little larger). See also: region-split-size Optional: Defaults to 144MB optional",
+							Description: "Text file containing the key in hex form, end with '\n'",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"region-split-size": {
+					"key-id": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When Region [a,e) size exceeds `region-max-size`, it will be split into several Regions [a,b), [b,c), [c,d), [d,e) and the size of [a,b), [b,c), [c,d) will be `region-split-size` (or a little larger). See also: region-max-size Optional: Defaults to 96MB optional",
+							Description: "AWS CMK key-id it can be find in AWS Console or use aws cli This field is required",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"region-max-keys": {
+					"access-key": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When the number of keys in Region [a,e) exceeds the `region-max-keys`, it will be split into several Regions [a,b), [b,c), [c,d), [d,e) and the number of keys in [a,b), [b,c), [c,d) will be `region-split-keys`. See also: region-split-keys Optional: Defaults to 1440000 optional",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "AccessKey of AWS user, leave empty if using other authrization method optional",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"region-split-keys": {
+					"secret-access-key": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When the number of keys in Region [a,e) exceeds the `region-max-keys`, it will be split into several Regions [a,b), [b,c), [c,d), [d,e) and the number of keys in [a,b), [b,c), [c,d) will be `region-split-keys`. See also: region-max-keys Optional: Defaults to 960000 optional",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "SecretKey of AWS user, leave empty if using other authrization method optional",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"region": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Region of this KMS key Optional: Default to us-east-1 optional",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"endpoint": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Used for KMS compatible KMS, such as Ceph, minio, If use AWS, leave empty optional",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
+				Required: []string{"path", "key-id"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiKVCoprocessorReadPoolConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TiKVPDConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
 				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"high-concurrency": {
+					"endpoints": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 8",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "The PD endpoints for the client.\n\nDefault is empty.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"normal-concurrency": {
+					"retry-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 8",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "The interval at which to retry a PD connection initialization.\n\nDefault is 300ms. Optional: Defaults to 300ms",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"low-concurrency": {
+					"retry-max-count": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 8",
+							Description: "The maximum number of times to retry a PD connection initialization.\n\nDefault is isize::MAX, represented by -1. Optional: Defaults to -1",
 							Type:        []string{"integer"},
 							Format:      "int64",
 						},
 					},
-					"max-tasks-per-worker-high": {
+					"retry-log-every": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2000",
+							Description: "If the client observes the same error message on retry, it can repeat the message only every `n` times.\n\nDefault is 10. Set to 1 to disable this feature. Optional: Defaults to 10",
 							Type:        []string{"integer"},
 							Format:      "int64",
 						},
 					},
-					"max-tasks-per-worker-normal": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVPessimisticTxn(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2000",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"max-tasks-per-worker-low": {
+					"wait-for-lock-timeout": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2000",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "The default and maximum delay before responding to TiDB when pessimistic transactions encounter locks",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"stack-size": {
+					"wake-up-delay-duration": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10MB",
+							Description: "If more than one transaction is waiting for the same lock, only the one with smallest start timestamp will be waked up immediately when the lock is released. Others will be waked up after `wake_up_delay_duration` to reduce contention and make the oldest one more likely acquires the lock.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"use-unified-pool": {
+					"pipelined": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
 				},
@@ -9097,18 +12652,22 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVCoprocessorReadPoolConfig(ref common.R
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiKVDbConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TiKVRaftDBConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiKVDbConfig is the rocksdb config.",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
 					"wal-recovery-mode": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"wal-dir": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 					"wal-ttl-seconds": {
@@ -9125,58 +12684,50 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVDbConfig(ref common.ReferenceCallback)
 					},
 					"max-total-wal-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 4GB",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 					"max-background-jobs": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 8",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
 					"max-manifest-file-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 128MB",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 					"create-if-missing": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
 					"max-open-files": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 40960",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
 					"enable-statistics": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
 					"stats-dump-period": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10m",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 					"compaction-readahead-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 0",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 					"info-log-max-size": {
@@ -9203,43 +12754,12 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVDbConfig(ref common.ReferenceCallback)
 							Format: "",
 						},
 					},
-					"rate-bytes-per-sec": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"rate-limiter-mode": {
+					"max-sub-compactions": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"auto-tuned": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
-						},
-					},
-					"bytes-per-sync": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"wal-bytes-per-sync": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"max-sub-compactions": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 3",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
 					"writable-file-max-buffer-size": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
@@ -9258,1540 +12778,1742 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVDbConfig(ref common.ReferenceCallback)
 							Format: "",
 						},
 					},
-					"defaultcf": {
+					"allow-concurrent-memtable-write": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"),
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"writecf": {
+					"bytes-per-sync": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"lockcf": {
+					"wal-bytes-per-sync": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"raftcf": {
+					"defaultcf": {
 						SchemaProps: spec.SchemaProps{
 							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"),
 						},
 					},
-					"titan": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanDBConfig"),
-						},
-					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVTitanDBConfig"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiKVEncryptionConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TiKVRaftstoreConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "TiKVRaftstoreConfig is the configuration of TiKV raftstore component.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"data-encryption-method": {
+					"sync-log": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Encrypyion method, use data key encryption raw rocksdb data Possible values: plaintext, aes128-ctr, aes192-ctr, aes256-ctr Optional: Default to plaintext optional",
-							Type:        []string{"string"},
+							Description: "true for high reliability, prevent data loss when power failure. Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"data-key-rotation-period": {
+					"prevote": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The frequency of datakey rotation, It managered by tikv Optional: default to 7d optional",
-							Type:        []string{"string"},
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"master-key": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Master key config",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVMasterKeyConfig"),
-						},
-					},
-					"previous-master-key": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Previous master key config It used in master key rotation, the data key should decryption by previous master key and  then encrypytion by new master key",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVMasterKeyConfig"),
-						},
-					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVMasterKeyConfig"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVGCConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"batch-keys": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 512",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"max-write-bytes-per-sec": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"enable-compaction-filter": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
-						},
-					},
-					"compaction-filter-skip-version-check": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
-						},
-					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVImportConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"import-dir": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"num-threads": {
+					"raft-base-tick-interval": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "raft-base-tick-interval is a base tick interval (ms).",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"num-import-jobs": {
+					"raft-heartbeat-ticks": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"num-import-sst-jobs": {
+					"raft-election-timeout-ticks": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"max-prepare-duration": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"region-split-size": {
+					"raft-entry-max-size": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "When the entry exceed the max size, reject to propose it. Optional: Defaults to 8MB",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"stream-channel-window": {
+					"raft-max-size-per-msg": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Limit the max size of each append message. Optional: Defaults to 1MB",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"max-open-engines": {
+					"raft-max-inflight-msgs": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Limit the max number of in-flight append messages during optimistic replication phase. Optional: Defaults to 256",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"upload-speed-limit": {
+					"raft-log-gc-tick-interval": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Interval to gc unnecessary raft log (ms). Optional: Defaults to 10s",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVMasterKeyConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"type": {
+					"raft-log-gc-threshold": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Use KMS encryption or use file encryption, possible values: kms, file If set to kms, kms MasterKeyKMSConfig should be filled, if set to file MasterKeyFileConfig should be filled optional",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "A threshold to gc stale raft log, must >= 1. Optional: Defaults to 50",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"method": {
+					"raft-log-gc-count-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Encrypyion method, use master key encryption data key Possible values: plaintext, aes128-ctr, aes192-ctr, aes256-ctr Optional: Default to plaintext optional",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "When entry count exceed this value, gc will be forced trigger. Optional: Defaults to 72000",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"path": {
+					"raft-log-gc-size-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Text file containing the key in hex form, end with '\n'",
+							Description: "When the approximate size of raft log entries exceed this value gc will be forced trigger. Optional: Defaults to 72MB",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"key-id": {
+					"raft-entry-cache-life-time": {
 						SchemaProps: spec.SchemaProps{
-							Description: "AWS CMK key-id it can be find in AWS Console or use aws cli This field is required",
+							Description: "When a peer is not responding for this time, leader will not keep entry cache for it.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"access-key": {
+					"raft-reject-transfer-leader-duration": {
 						SchemaProps: spec.SchemaProps{
-							Description: "AccessKey of AWS user, leave empty if using other authrization method optional",
+							Description: "When a peer is newly added, reject transferring leader to the peer for a while.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"secret-access-key": {
+					"split-region-check-tick-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SecretKey of AWS user, leave empty if using other authrization method optional",
+							Description: "Interval (ms) to check region whether need to be split or not. Optional: Defaults to 10s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"region": {
+					"region-split-check-diff": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Region of this KMS key Optional: Default to us-east-1 optional",
+							Description: "/ When size change of region exceed the diff since last check, it / will be checked again whether it should be split. Optional: Defaults to 6MB",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"endpoint": {
+					"region-compact-check-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Used for KMS compatible KMS, such as Ceph, minio, If use AWS, leave empty optional",
+							Description: "/ Interval (ms) to check whether start compaction for a region. Optional: Defaults to 5m",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-				},
-				Required: []string{"path", "key-id"},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVPDConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"endpoints": {
-						SchemaProps: spec.SchemaProps{
-							Description: "The PD endpoints for the client.\n\nDefault is empty.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
-						},
-					},
-					"retry-interval": {
+					"clean-stale-peer-delay": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The interval at which to retry a PD connection initialization.\n\nDefault is 300ms. Optional: Defaults to 300ms",
+							Description: "delay time before deleting a stale peer Optional: Defaults to 10m",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"retry-max-count": {
+					"region-compact-check-step": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The maximum number of times to retry a PD connection initialization.\n\nDefault is isize::MAX, represented by -1. Optional: Defaults to -1",
+							Description: "/ Number of regions for each time checking. Optional: Defaults to 100",
 							Type:        []string{"integer"},
 							Format:      "int64",
 						},
 					},
-					"retry-log-every": {
+					"region-compact-min-tombstones": {
 						SchemaProps: spec.SchemaProps{
-							Description: "If the client observes the same error message on retry, it can repeat the message only every `n` times.\n\nDefault is 10. Set to 1 to disable this feature. Optional: Defaults to 10",
+							Description: "/ Minimum number of tombstones to trigger manual compaction. Optional: Defaults to 10000",
 							Type:        []string{"integer"},
 							Format:      "int64",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVPessimisticTxn(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"enabled": {
+					"region-compact-tombstones-percent": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "/ Minimum percentage of tombstones to trigger manual compaction. / Should between 1 and 100. Optional: Defaults to 30",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"wait-for-lock-timeout": {
+					"pd-heartbeat-tick-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The default and maximum delay before responding to TiDB when pessimistic transactions encounter locks",
+							Description: "Optional: Defaults to 60s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"wake-up-delay-duration": {
+					"pd-store-heartbeat-tick-interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "If more than one transaction is waiting for the same lock, only the one with smallest start timestamp will be waked up immediately when the lock is released. Others will be waked up after `wake_up_delay_duration` to reduce contention and make the oldest one more likely acquires the lock.",
+							Description: "Optional: Defaults to 10s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"pipelined": {
+					"snap-mgr-gc-tick-interval": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
+							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVRaftDBConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"wal-recovery-mode": {
+					"snap-gc-timeout": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"wal-dir": {
+					"lock-cf-compact-interval": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Optional: Defaults to 10m",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"wal-ttl-seconds": {
+					"lock-cf-compact-bytes-threshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 256MB",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"notify-capacity": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"wal-size-limit": {
+					"messages-per-tick": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Type:   []string{"integer"},
+							Format: "int64",
 						},
 					},
-					"max-total-wal-size": {
+					"max-peer-down-duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "/ When a peer is not active for max-peer-down-duration / the peer is considered to be down and is reported to PD. Optional: Defaults to 5m",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"max-leader-missing-duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "/ If the leader of a peer is missing for longer than max-leader-missing-duration / the peer would ask pd to confirm whether it is valid in any region. / If the peer is stale and is not valid in any region, it will destroy itself.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"abnormal-leader-missing-duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "/ Similar to the max-leader-missing-duration, instead it will log warnings and / try to alert monitoring systems, if there is any.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"peer-stale-state-check-interval": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"max-background-jobs": {
+					"leader-transfer-max-log-lag": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"max-manifest-file-size": {
+					"snap-apply-batch-size": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"create-if-missing": {
+					"consistency-check-interval": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
+							Description: "Interval (ms) to check region whether the data is consistent. Optional: Defaults to 0",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"report-region-flow-interval": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"max-open-files": {
+					"raft-store-max-leader-lease": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "The lease provided by a successfully proposed and applied entry.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"enable-statistics": {
+					"right-derive-when-split": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Right region derive origin region id when split.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"allow-remove-leader": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"boolean"},
 							Format: "",
 						},
 					},
-					"stats-dump-period": {
+					"merge-max-log-gap": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "/ Max log gap allowed to propose merge.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"compaction-readahead-size": {
+					"merge-check-tick-interval": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "/ Interval to re-propose merge.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"info-log-max-size": {
+					"use-delete-range": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
+							Type:   []string{"boolean"},
 							Format: "",
 						},
 					},
-					"info-log-roll-time": {
+					"cleanup-import-sst-interval": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Optional: Defaults to 10m",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"info-log-keep-log-file-num": {
+					"apply-max-batch-size": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"info-log-dir": {
+					"apply-pool-size": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Optional: Defaults to 2",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"max-sub-compactions": {
+					"store-max-batch-size": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
-					"writable-file-max-buffer-size": {
+					"store-pool-size": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
+							Description: "Optional: Defaults to 2",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"store-reschedule-duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 3s",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apply-yield-duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 500ms",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"hibernate-regions": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
 							Format: "",
 						},
 					},
-					"use-direct-io-for-flush-and-compaction": {
+					"apply-early": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"enable-pipelined-write": {
+					"perf-level": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"allow-concurrent-memtable-write": {
+					"dev-assert": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"bytes-per-sync": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVReadPoolConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"unified": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVUnifiedReadPoolConfig"),
 						},
 					},
-					"wal-bytes-per-sync": {
+					"coprocessor": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorReadPoolConfig"),
 						},
 					},
-					"defaultcf": {
+					"storage": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"),
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageReadPoolConfig"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCfConfig"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorReadPoolConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageReadPoolConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVUnifiedReadPoolConfig"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TiKVRaftstoreConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TiKVSecurityConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TiKVRaftstoreConfig is the configuration of TiKV raftstore component.",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"sync-log": {
+					"ca-path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "true for high reliability, prevent data loss when power failure. Optional: Defaults to true",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"prevote": {
+					"cert-path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"raft-base-tick-interval": {
+					"key-path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "raft-base-tick-interval is a base tick interval (ms).",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"raft-heartbeat-ticks": {
+					"override-ssl-target": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"raft-election-timeout-ticks": {
+					"cipher-file": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"raft-entry-max-size": {
+					"encryption": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When the entry exceed the max size, reject to propose it. Optional: Defaults to 8MB",
-							Type:        []string{"string"},
-							Format:      "",
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfigEncryption"),
 						},
 					},
-					"raft-max-size-per-msg": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfigEncryption"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVServerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVServerConfig is the configuration of TiKV server.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"status-thread-pool-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limit the max size of each append message. Optional: Defaults to 1MB",
+							Description: "Optional: Defaults to 1",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"raft-max-inflight-msgs": {
+					"max-grpc-send-msg-len": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limit the max number of in-flight append messages during optimistic replication phase. Optional: Defaults to 256",
+							Description: "Optional: Defaults to 10485760",
 							Type:        []string{"integer"},
-							Format:      "int64",
+							Format:      "int32",
 						},
 					},
-					"raft-log-gc-tick-interval": {
+					"grpc-compression-type": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Interval to gc unnecessary raft log (ms). Optional: Defaults to 10s",
+							Description: "Optional: Defaults to none",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"raft-log-gc-threshold": {
+					"grpc-concurrency": {
 						SchemaProps: spec.SchemaProps{
-							Description: "A threshold to gc stale raft log, must >= 1. Optional: Defaults to 50",
+							Description: "Optional: Defaults to 4",
 							Type:        []string{"integer"},
-							Format:      "int64",
+							Format:      "int32",
 						},
 					},
-					"raft-log-gc-count-limit": {
+					"grpc-concurrent-stream": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When entry count exceed this value, gc will be forced trigger. Optional: Defaults to 72000",
+							Description: "Optional: Defaults to 1024",
 							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"raft-log-gc-size-limit": {
-						SchemaProps: spec.SchemaProps{
-							Description: "When the approximate size of raft log entries exceed this value gc will be forced trigger. Optional: Defaults to 72MB",
-							Type:        []string{"string"},
-							Format:      "",
+							Format:      "int32",
 						},
 					},
-					"raft-entry-cache-life-time": {
+					"grpc-memory-pool-quota": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When a peer is not responding for this time, leader will not keep entry cache for it.",
+							Description: "Optional: Defaults to 32G",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"raft-reject-transfer-leader-duration": {
+					"grpc-raft-conn-num": {
 						SchemaProps: spec.SchemaProps{
-							Description: "When a peer is newly added, reject transferring leader to the peer for a while.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 10",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"split-region-check-tick-interval": {
+					"grpc-stream-initial-window-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Interval (ms) to check region whether need to be split or not. Optional: Defaults to 10s",
+							Description: "Optional: Defaults to 2MB",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"region-split-check-diff": {
+					"grpc-keepalive-time": {
 						SchemaProps: spec.SchemaProps{
-							Description: "/ When size change of region exceed the diff since last check, it / will be checked again whether it should be split. Optional: Defaults to 6MB",
+							Description: "Optional: Defaults to 10s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"region-compact-check-interval": {
+					"grpc-keepalive-timeout": {
 						SchemaProps: spec.SchemaProps{
-							Description: "/ Interval (ms) to check whether start compaction for a region. Optional: Defaults to 5m",
+							Description: "Optional: Defaults to 3s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"clean-stale-peer-delay": {
+					"concurrent-send-snap-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "delay time before deleting a stale peer Optional: Defaults to 10m",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Optional: Defaults to 32",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"region-compact-check-step": {
+					"concurrent-recv-snap-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "/ Number of regions for each time checking. Optional: Defaults to 100",
+							Description: "Optional: Defaults to 32",
 							Type:        []string{"integer"},
-							Format:      "int64",
+							Format:      "int32",
 						},
 					},
-					"region-compact-min-tombstones": {
+					"end-point-recursion-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "/ Minimum number of tombstones to trigger manual compaction. Optional: Defaults to 10000",
+							Description: "Optional: Defaults to 1000",
 							Type:        []string{"integer"},
-							Format:      "int64",
+							Format:      "int32",
 						},
 					},
-					"region-compact-tombstones-percent": {
+					"end-point-stream-channel-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "/ Minimum percentage of tombstones to trigger manual compaction. / Should between 1 and 100. Optional: Defaults to 30",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"pd-heartbeat-tick-interval": {
+					"end-point-batch-row-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 60s",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"pd-store-heartbeat-tick-interval": {
+					"end-point-stream-batch-row-limit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10s",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"snap-mgr-gc-tick-interval": {
+					"end-point-enable-batch-if-possible": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"snap-gc-timeout": {
+					"end-point-request-max-handle-duration": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"lock-cf-compact-interval": {
+					"snap-max-write-bytes-per-sec": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10m",
+							Description: "Optional: Defaults to 100MB",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"lock-cf-compact-bytes-threshold": {
+					"snap-max-total-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 256MB",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"notify-capacity": {
+					"stats-concurrency": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
-							Format: "int64",
+							Format: "int32",
 						},
 					},
-					"messages-per-tick": {
+					"heavy-load-threshold": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"integer"},
-							Format: "int64",
-						},
-					},
-					"max-peer-down-duration": {
-						SchemaProps: spec.SchemaProps{
-							Description: "/ When a peer is not active for max-peer-down-duration / the peer is considered to be down and is reported to PD. Optional: Defaults to 5m",
-							Type:        []string{"string"},
-							Format:      "",
+							Format: "int32",
 						},
 					},
-					"max-leader-missing-duration": {
+					"heavy-load-wait-duration": {
 						SchemaProps: spec.SchemaProps{
-							Description: "/ If the leader of a peer is missing for longer than max-leader-missing-duration / the peer would ask pd to confirm whether it is valid in any region. / If the peer is stale and is not valid in any region, it will destroy itself.",
+							Description: "Optional: Defaults to 60s",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"abnormal-leader-missing-duration": {
+					"labels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "/ Similar to the max-leader-missing-duration, instead it will log warnings and / try to alert monitoring systems, if there is any.",
-							Type:        []string{"string"},
-							Format:      "",
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"peer-stale-state-check-interval": {
+					"enable-request-batch": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
+							Type:   []string{"boolean"},
 							Format: "",
 						},
 					},
-					"leader-transfer-max-log-lag": {
+					"request-batch-enable-cross-command": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"snap-apply-batch-size": {
+					"request-batch-wait-duration": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"consistency-check-interval": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVSpec contains details of TiKV members",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"version": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Interval (ms) to check region whether the data is consistent. Optional: Defaults to 0",
+							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"report-region-flow-interval": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"raft-store-max-leader-lease": {
+					"imagePullPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The lease provided by a successfully proposed and applied entry.",
+							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"right-derive-when-split": {
+					"imagePullSecrets": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Right region derive origin region id when split.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
 						},
 					},
-					"allow-remove-leader": {
+					"hostNetwork": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"merge-max-log-gap": {
+					"affinity": {
 						SchemaProps: spec.SchemaProps{
-							Description: "/ Max log gap allowed to propose merge.",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
 						},
 					},
-					"merge-check-tick-interval": {
+					"priorityClassName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "/ Interval to re-propose merge.",
+							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"use-delete-range": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
-						},
-					},
-					"cleanup-import-sst-interval": {
+					"schedulerName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10m",
+							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apply-max-batch-size": {
+					"nodeSelector": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"apply-pool-size": {
+					"annotations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"store-max-batch-size": {
+					"labels": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"store-pool-size": {
+					"tolerations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
 						},
 					},
-					"store-reschedule-duration": {
+					"podSecurityContext": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 3s",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "PodSecurityContext of the component",
+							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
 						},
 					},
-					"apply-yield-duration": {
+					"configUpdateStrategy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 500ms",
+							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"hibernate-regions": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
-						},
-					},
-					"apply-early": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to false",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
-					"perf-level": {
+					"env": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 0",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
 						},
 					},
-					"dev-assert": {
+					"initContainers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to false",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Init containers of the components",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVReadPoolConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"unified": {
+					"additionalContainers": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVUnifiedReadPoolConfig"),
+							Description: "Additional containers of the component.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
 						},
 					},
-					"coprocessor": {
+					"additionalVolumes": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorReadPoolConfig"),
+							Description: "Additional volumes of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Volume"),
+									},
+								},
+							},
 						},
 					},
-					"storage": {
+					"additionalVolumeMounts": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageReadPoolConfig"),
-						},
-					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorReadPoolConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStorageReadPoolConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVUnifiedReadPoolConfig"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVSecurityConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"ca-path": {
+							Description: "Additional volume mounts of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
+						},
+					},
+					"terminationGracePeriodSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"cert-path": {
+					"statefulSetUpdateStrategy": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"key-path": {
+					"podManagementPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"override-ssl-target": {
+					"minReadySeconds": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"cipher-file": {
+					"rollingUpdateIntervalSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"encryption": {
+					"topologySpreadConstraints": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"topologyKey",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfigEncryption"),
+							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+									},
+								},
+							},
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSecurityConfigEncryption"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVServerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiKVServerConfig is the configuration of TiKV server.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"status-thread-pool-size": {
+					"architecture": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 1",
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"max-grpc-send-msg-len": {
+					"archBaseImages": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10485760",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"grpc-compression-type": {
+					"startScriptVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to none",
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"grpc-concurrency": {
+					"helper": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 4",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
 						},
 					},
-					"grpc-concurrent-stream": {
+					"timezone": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 1024",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"grpc-memory-pool-quota": {
+					"mountTimezoneData": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 32G",
-							Type:        []string{"string"},
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"grpc-raft-conn-num": {
+					"limits": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"grpc-stream-initial-window-size": {
+					"requests": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2MB",
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"serviceAccount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Specify a Service Account for tikv",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"grpc-keepalive-time": {
+					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10s",
+							Description: "The desired ready replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"baseImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Base image of the component, image tag is now allowed during validation",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"grpc-keepalive-timeout": {
+					"privileged": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 3s",
-							Type:        []string{"string"},
+							Description: "Whether create the TiKV container in privileged mode, it is highly discouraged to enable this in critical environment. Optional: defaults to false",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"concurrent-send-snap-limit": {
+					"maxFailoverCount": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 32",
+							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover Optional: Defaults to 3",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"concurrent-recv-snap-limit": {
+					"progressDeadlineSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 32",
+							Description: "ProgressDeadlineSeconds is the max seconds for a Pod to complete its update in a rolling update. If no Pod completes its update within the deadline, the rolling update is paused with the ProgressDeadlineExceeded condition, and no more Pods are deleted until the update revision is changed, or the deadline is raised or removed. Optional: Defaults to no deadline",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"end-point-recursion-limit": {
+					"resizePolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 1000",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "ResizePolicy is the restart policy of the resources of the TiKV container when the CPU and memory are resized in place, which is enabled by the InPlacePodVerticalScaling feature gate. The Pods are rolling updated instead if a resized resource requires the restart. The resources not listed don't require the restart.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ContainerResizePolicy"),
+									},
+								},
+							},
 						},
 					},
-					"end-point-stream-channel-size": {
+					"separateRocksDBLog": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Whether output the RocksDB log in a separate sidecar container Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"end-point-batch-row-limit": {
+					"separateRaftLog": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Whether output the Raft log in a separate sidecar container Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"end-point-stream-batch-row-limit": {
+					"rocksDBLogVolumeName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Optional volume name configuration for rocksdb log.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"end-point-enable-batch-if-possible": {
+					"raftLogVolumeName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Optional volume name configuration for raft log.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"end-point-request-max-handle-duration": {
+					"logTailer": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "LogTailer is the configurations of the log tailers for TiKV",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec"),
 						},
 					},
-					"snap-max-write-bytes-per-sec": {
+					"storageClassName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 100MB",
+							Description: "The storageClassName of the persistent volume for TiKV data storage. Defaults to Kubernetes default storage class.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"snap-max-total-size": {
+					"dataSubDir": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Subdirectory within the volume to store TiKV Data. By default, the data is stored in the root directory of volume which is mounted at /var/lib/tikv. Specifying this will change the data directory to a subdirectory, e.g. /var/lib/tikv/data if you set the value to \"data\". It's dangerous to change this value for a running cluster as it will upgrade your cluster to use a new storage directory. Defaults to \"\" (volume's root).",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"stats-concurrency": {
+					"config": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Config is the Configuration of tikv-servers",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVConfigWraper"),
 						},
 					},
-					"heavy-load-threshold": {
+					"configMapRef": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value is the config file of tikv-servers in TOML. The items of the inline config and the items managed by the operator take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out like the changes of the inline config after the TOML is validated.",
+							Ref:         ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
 						},
 					},
-					"heavy-load-wait-duration": {
+					"recoverFailover": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 60s",
-							Type:        []string{"string"},
+							Description: "RecoverFailover indicates that Operator can recover the failed Pods",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"labels": {
+					"mountClusterClientSecret": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "MountClusterClientSecret indicates whether to mount `cluster-client-secret` to the Pod",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"enable-request-batch": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+					"evictLeaderTimeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EvictLeaderTimeout indicates the timeout to evict tikv leader, in the format of Go Duration. Defaults to 10m",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"request-batch-enable-cross-command": {
+					"maxReceivingSnapshotsOnUpgrade": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "MaxReceivingSnapshotsOnUpgrade is the maximum number of snapshots a store can be receiving or applying for its Pod to be restarted during the upgrade, the upgrade is delayed until the store settles down, e.g. a new store that is still being filled. A negative value disables the check. Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"request-batch-wait-duration": {
+					"leaderRecoveryPercentOnUpgrade": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "LeaderRecoveryPercentOnUpgrade is the percent of the leaders an upgraded store must regain, compared with its leader count before the leaders are evicted, for the upgrade to proceed to the next store, so that the region leaders are balanced again before another store is restarted. 0 disables the check, i.e. the upgrade proceeds once the upgraded Pod is ready. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiKVSpec contains details of TiKV members",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"version": {
+					"leaderRecoveryTimeout": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
+							Description: "LeaderRecoveryTimeout is the maximum time to wait for the leaders of an upgraded store to recover after its Pod is ready, in the format of Go Duration. Defaults to 10m",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"imagePullPolicy": {
+					"regionHealthRefreshInterval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
+							Description: "RegionHealthRefreshInterval is the minimum interval between two refreshes of the summary of the region health in the status, in the format of Go Duration. The summary is refreshed in every sync during the upgrade of TiKV, and the next store is not upgraded while there are regions with down peers. 0 disables the summary. Defaults to 5m",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"imagePullSecrets": {
+					"storageVolumes": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
+							Description: "StorageVolumes configure additional storage for TiKV pods.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
 									},
 								},
 							},
 						},
 					},
-					"hostNetwork": {
+					"enableVolumeReplace": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
+							Description: "EnableVolumeReplace allows shrinking the volumes of TiKV, which can't be resized in place. When a smaller storage request is set, the stores are replaced one by one: the leaders are evicted, the store is deleted and its regions are replicated to other stores, then the Pod is recreated with new PVCs of the desired size. Optional: Defaults to false",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"affinity": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
-							Ref:         ref("k8s.io/api/core/v1.Affinity"),
-						},
-					},
-					"priorityClassName": {
+					"storeLabels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "StoreLabels configures additional labels for TiKV stores.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"schedulerName": {
+					"enableNamedStatusPort": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
-							Type:        []string{"string"},
+							Description: "EnableNamedStatusPort enables status port(20180) in the Pod spec. If you set it to `true` for an existing cluster, the TiKV cluster will be rolling updated.",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"nodeSelector": {
+					"storeScheduling": {
 						SchemaProps: spec.SchemaProps{
-							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "StoreScheduling configures the PD scheduling settings of the stores, e.g. the weights and the store limits, so that the stores on heterogeneous hardware are balanced correctly. For the stores selected by multiple items, the settings of the later items take precedence.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStoreScheduling"),
 									},
 								},
 							},
 						},
 					},
-					"annotations": {
+					"deleteSlots": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "DeleteSlots are the ordinals of the Pods to delete, in addition to the ones in the `tikv.tidb.pingcap.com/delete-slots` annotation. The deleted Pods are replaced by Pods of new ordinals unless the replicas are decreased accordingly. Only takes effect when advanced StatefulSet is enabled.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
+										Type:   []string{"integer"},
+										Format: "int32",
 									},
 								},
 							},
 						},
 					},
-					"labels": {
+					"perPodConfig": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Description: "PerPodConfig is the configuration patch of the TiKV Pods, keyed by the ordinal of the Pod. The patch is merged into Config to render the configuration file of the Pod, and only the Pods whose patch is changed are restarted one by one to apply the new configuration. Note that setting it for the first time restarts all the Pods, because the start script is changed. Only takes effect when Config is set.",
 							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVConfigWraper"),
 									},
 								},
 							},
 						},
 					},
-					"tolerations": {
+					"podDisruptionBudget": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Toleration"),
-									},
-								},
-							},
+							Description: "PodDisruptionBudget configures the PodDisruptionBudget of the TiKV pods. No PodDisruptionBudget is created if it's not set.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec"),
 						},
 					},
-					"podSecurityContext": {
+					"port": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodSecurityContext of the component",
-							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+							Description: "Port is the port that TiKV serves on, it's advertised to PD for the other components to connect to. It can't be changed for an existing cluster. Optional: Defaults to 20160",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"configUpdateStrategy": {
+					"coldGroup": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
+							Description: "ColdGroup configures a secondary group of TiKV stores for the cold data, e.g. on the HDD-backed nodes. The stores of the group are excluded from the default placement rule of PD, so only the data pinned by the placement rules or placement policies with the constraints of the store labels of the group, e.g. `CONSTRAINTS=\"[+tier=cold]\"`, is stored on them. It requires the placement rules of PD enabled. The group shares the image and the configuration of TiKV. If TLS is enabled between the components, the certificate of TiKV must be valid for the peer service of the group, e.g. `*.${cluster_name}-tikv-cold-peer`.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVColdGroupSpec"),
+						},
+					},
+					"debugArtifacts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DebugArtifacts configures the capture of the core dumps and the heap profiles of TiKV for the postmortems when TiKV is OOMKilled or crashes",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DebugArtifactsSpec"),
+						},
+					},
+				},
+				Required: []string{"replicas"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ContainerResizePolicy", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DebugArtifactsSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVColdGroupSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVStoreScheduling", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.ConfigMapKeySelector", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVStorageConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVStorageConfig is the config of storage",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"max-key-size": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"scheduler-notify-capacity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Deprecated in v4.0.0",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"scheduler-concurrency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 2048000",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"scheduler-worker-pool-size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 4",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"scheduler-pending-write-threshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 100MB",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"env": {
+					"block-cache": {
 						SchemaProps: spec.SchemaProps{
-							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.EnvVar"),
-									},
-								},
-							},
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBlockCacheConfig"),
 						},
 					},
-					"initContainers": {
+					"reserve-space": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Init containers of the components",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
-									},
-								},
-							},
+							Description: "The size of the temporary file that preoccupies the extra space when TiKV is started. The name of temporary file is `space_placeholder_file`, located in the `storage.data-dir` directory. When TiKV runs out of disk space and cannot be started normally, you can delete this file as an emergency intervention and set it to `0MB`. Default value is 2GB.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"additionalContainers": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBlockCacheConfig"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVStorageReadPoolConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"high-concurrency": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional containers of the component.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Container"),
-									},
-								},
-							},
+							Description: "Optional: Defaults to 4",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"additionalVolumes": {
+					"normal-concurrency": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volumes of component pod.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.Volume"),
-									},
-								},
-							},
+							Description: "Optional: Defaults to 4",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"additionalVolumeMounts": {
+					"low-concurrency": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Additional volume mounts of component pod.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
-									},
-								},
-							},
+							Description: "Optional: Defaults to 4",
+							Type:        []string{"integer"},
+							Format:      "int64",
 						},
 					},
-					"terminationGracePeriodSeconds": {
+					"max-tasks-per-worker-high": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 2000",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"max-tasks-per-worker-normal": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Optional: Defaults to 2000",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"max-tasks-per-worker-low": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
+							Description: "Optional: Defaults to 2000",
 							Type:        []string{"integer"},
 							Format:      "int64",
 						},
 					},
-					"statefulSetUpdateStrategy": {
+					"stack-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
+							Description: "Optional: Defaults to 10MB",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"podManagementPolicy": {
+					"use-unified-pool": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
-							Type:        []string{"string"},
+							Description: "Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"topologySpreadConstraints": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-map-keys": []interface{}{
-									"topologyKey",
-								},
-								"x-kubernetes-list-type": "map",
-							},
-						},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVStoreScheduling(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVStoreScheduling is the PD scheduling settings of the TiKV stores selected by the ordinals of their Pods and the store labels. All the stores are selected if neither ordinals nor storeLabels is set.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"ordinals": {
 						SchemaProps: spec.SchemaProps{
-							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Description: "Ordinals selects the stores by the ordinals of their Pods",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+										Type:   []string{"integer"},
+										Format: "int32",
 									},
 								},
 							},
 						},
 					},
-					"limits": {
+					"storeLabels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Description: "StoreLabels selects the stores by the labels of the stores in PD, e.g. zone and host",
 							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"requests": {
+					"leaderWeight": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
-									},
-								},
-							},
+							Description: "LeaderWeight is the leader weight of the stores, the leaders are balanced by the leader count divided by the weight. The weight of the stores not selected by any item is reset to 1.",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"serviceAccount": {
+					"regionWeight": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Specify a Service Account for tikv",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "RegionWeight is the region weight of the stores, the regions are balanced by the region size divided by the weight. The weight of the stores not selected by any item is reset to 1.",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"replicas": {
+					"addPeerLimit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The desired ready replicas",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "AddPeerLimit is the maximum number of the add-peer operators per minute on the stores",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"baseImage": {
+					"removePeerLimit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Base image of the component, image tag is now allowed during validation",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "RemovePeerLimit is the maximum number of the remove-peer operators per minute on the stores",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"privileged": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVTitanCfConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVTitanCfConfig is the titian config.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"min-blob-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether create the TiKV container in privileged mode, it is highly discouraged to enable this in critical environment. Optional: defaults to false",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"maxFailoverCount": {
+					"blob-file-compression": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MaxFailoverCount limit the max replicas could be added in failover, 0 means no failover Optional: Defaults to 3",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"separateRocksDBLog": {
+					"blob-cache-size": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether output the RocksDB log in a separate sidecar container Optional: Defaults to false",
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"min-gc-batch-size": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"max-gc-batch-size": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"discardable-ratio": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+					"sample-ratio": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+					"merge-small-file-threshold": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"blob-run-mode": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"level_merge": {
+						SchemaProps: spec.SchemaProps{
+							Description: "optional",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"separateRaftLog": {
+					"gc-merge-rewrite": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether output the Raft log in a separate sidecar container Optional: Defaults to false",
+							Description: "optional",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"rocksDBLogVolumeName": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVTitanDBConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVTitanDBConfig is the config a titian db.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional volume name configuration for rocksdb log.",
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"dirname": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"disable-gc": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"max-background-gc": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"purge-obsolete-files-period": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The value of this field will be truncated to seconds.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"raftLogVolumeName": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiKVUnifiedReadPoolConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"min-thread-count": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional volume name configuration for raft log.",
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"max-thread-count": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"stack-size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Deprecated in v4.0.0",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"logTailer": {
+					"max-tasks-per-worker": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LogTailer is the configurations of the log tailers for TiKV",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec"),
+							Type:   []string{"integer"},
+							Format: "int32",
 						},
 					},
-					"storageClassName": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TiProxySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiProxySpec contains details of TiProxy members",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"version": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The storageClassName of the persistent volume for TiKV data storage. Defaults to Kubernetes default storage class.",
+							Description: "Version of the component. Override the cluster-level version if non-empty Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"dataSubDir": {
+					"imagePullPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Subdirectory within the volume to store TiKV Data. By default, the data is stored in the root directory of volume which is mounted at /var/lib/tikv. Specifying this will change the data directory to a subdirectory, e.g. /var/lib/tikv/data if you set the value to \"data\". It's dangerous to change this value for a running cluster as it will upgrade your cluster to use a new storage directory. Defaults to \"\" (volume's root).",
+							Description: "ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"config": {
+					"imagePullSecrets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
+						},
+					},
+					"hostNetwork": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Config is the Configuration of tikv-servers",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVConfigWraper"),
+							Description: "Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"recoverFailover": {
+					"affinity": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RecoverFailover indicates that Operator can recover the failed Pods",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Affinity of the component. Override the cluster-level setting if present. Optional: Defaults to cluster-level setting",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
 						},
 					},
-					"mountClusterClientSecret": {
+					"priorityClassName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MountClusterClientSecret indicates whether to mount `cluster-client-secret` to the Pod",
-							Type:        []string{"boolean"},
+							Description: "PriorityClassName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"evictLeaderTimeout": {
+					"schedulerName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "EvictLeaderTimeout indicates the timeout to evict tikv leader, in the format of Go Duration. Defaults to 10m",
+							Description: "SchedulerName of the component. Override the cluster-level one if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"storageVolumes": {
+					"nodeSelector": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StorageVolumes configure additional storage for TiKV pods.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
+							Description: "NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"storeLabels": {
+					"annotations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StoreLabels configures additional labels for TiKV stores.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
+							Description: "Annotations for the component. Merge into the cluster-level annotations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Type:   []string{"string"},
@@ -10801,310 +14523,284 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVSpec(ref common.ReferenceCallback) com
 							},
 						},
 					},
-					"enableNamedStatusPort": {
-						SchemaProps: spec.SchemaProps{
-							Description: "EnableNamedStatusPort enables status port(20180) in the Pod spec. If you set it to `true` for an existing cluster, the TiKV cluster will be rolling updated.",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
-				},
-				Required: []string{"replicas"},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVStorageConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiKVStorageConfig is the config of storage",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"max-key-size": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
-						},
-					},
-					"scheduler-notify-capacity": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated in v4.0.0",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"scheduler-concurrency": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2048000",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"scheduler-worker-pool-size": {
+					"labels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 4",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Labels for the component. Merge into the cluster-level labels if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"scheduler-pending-write-threshold": {
+					"tolerations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 100MB",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Tolerations of the component. Override the cluster-level tolerations if non-empty Optional: Defaults to cluster-level setting",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
 						},
 					},
-					"block-cache": {
+					"podSecurityContext": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBlockCacheConfig"),
+							Description: "PodSecurityContext of the component",
+							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
 						},
 					},
-					"reserve-space": {
+					"configUpdateStrategy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The size of the temporary file that preoccupies the extra space when TiKV is started. The name of temporary file is `space_placeholder_file`, located in the `storage.data-dir` directory. When TiKV runs out of disk space and cannot be started normally, you can delete this file as an emergency intervention and set it to `0MB`. Default value is 2GB.",
+							Description: "ConfigUpdateStrategy of the component. Override the cluster-level updateStrategy if present Optional: Defaults to cluster-level setting",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVBlockCacheConfig"},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVStorageReadPoolConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"high-concurrency": {
+					"env": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 4",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "List of environment variables to set in the container, like v1.Container.Env. Note that the following env names cannot be used and will be overridden by TiDB Operator builtin envs - NAMESPACE - TZ - SERVICE_NAME - PEER_SERVICE_NAME - HEADLESS_SERVICE_NAME - SET_NAME - HOSTNAME - CLUSTER_NAME - POD_NAME - BINLOG_ENABLED - SLOW_LOG_FILE",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
 						},
 					},
-					"normal-concurrency": {
+					"initContainers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 4",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Init containers of the components",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
 						},
 					},
-					"low-concurrency": {
+					"additionalContainers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 4",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Additional containers of the component.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
 						},
 					},
-					"max-tasks-per-worker-high": {
+					"additionalVolumes": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2000",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Additional volumes of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Volume"),
+									},
+								},
+							},
 						},
 					},
-					"max-tasks-per-worker-normal": {
+					"additionalVolumeMounts": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2000",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Description: "Additional volume mounts of component pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
 						},
 					},
-					"max-tasks-per-worker-low": {
+					"terminationGracePeriodSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 2000",
+							Description: "Optional duration in seconds the pod needs to terminate gracefully. May be decreased in delete request. Value must be non-negative integer. The value zero indicates delete immediately. If this value is nil, the default grace period will be used instead. The grace period is the duration in seconds after the processes running in the pod are sent a termination signal and the time when the processes are forcibly halted with a kill signal. Set this value longer than the expected cleanup time for your process. Defaults to 30 seconds.",
 							Type:        []string{"integer"},
 							Format:      "int64",
 						},
 					},
-					"stack-size": {
+					"statefulSetUpdateStrategy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to 10MB",
+							Description: "StatefulSetUpdateStrategy indicates the StatefulSetUpdateStrategy that will be employed to update Pods in the StatefulSet when a revision is made to Template.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"use-unified-pool": {
+					"podManagementPolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Optional: Defaults to true",
-							Type:        []string{"boolean"},
+							Description: "PodManagementPolicy of TiDB cluster StatefulSets",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVTitanCfConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiKVTitanCfConfig is the titian config.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"min-blob-size": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"blob-file-compression": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"blob-cache-size": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"min-gc-batch-size": {
+					"minReadySeconds": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"max-gc-batch-size": {
+					"rollingUpdateIntervalSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"discardable-ratio": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"number"},
-							Format: "double",
+					"topologySpreadConstraints": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"topologyKey",
+								},
+								"x-kubernetes-list-type": "map",
+							},
 						},
-					},
-					"sample-ratio": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"number"},
-							Format: "double",
+							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+									},
+								},
+							},
 						},
 					},
-					"merge-small-file-threshold": {
+					"architecture": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"blob-run-mode": {
+					"archBaseImages": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"level_merge": {
+					"startScriptVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "optional",
-							Type:        []string{"boolean"},
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"gc-merge-rewrite": {
+					"helper": {
 						SchemaProps: spec.SchemaProps{
-							Description: "optional",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVTitanDBConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "TiKVTitanDBConfig is the config a titian db.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"enabled": {
+					"timezone": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"dirname": {
+					"mountTimezoneData": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"disable-gc": {
+					"limits": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"max-background-gc": {
+					"requests": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int64",
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
 						},
 					},
-					"purge-obsolete-files-period": {
+					"serviceAccount": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The value of this field will be truncated to seconds.",
+							Description: "Specify a Service Account for TiProxy",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_pingcap_v1alpha1_TiKVUnifiedReadPoolConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"min-thread-count": {
+					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "The desired ready replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"max-thread-count": {
+					"baseImage": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Base image of the component, image tag is now allowed during validation",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"stack-size": {
+					"service": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated in v4.0.0",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Service configures the Service the clients connect to TiProxy through Optional: Defaults to a ClusterIP Service",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ServiceSpec"),
 						},
 					},
-					"max-tasks-per-worker": {
+					"config": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Config is the Configuration of TiProxy servers, the addresses and the certificates are managed by the operator and can't be overridden",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiProxyConfigWraper"),
 						},
 					},
 				},
+				Required: []string{"replicas"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiProxyConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
@@ -11542,6 +15238,12 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCSpec"),
 						},
 					},
+					"tiproxy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TiProxy cluster spec",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiProxySpec"),
+						},
+					},
 					"pump": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Pump cluster spec",
@@ -11561,6 +15263,12 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							Format:      "",
 						},
 					},
+					"autoResume": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AutoResume resumes the paused cluster automatically, so that it doesn't drift from the spec for long once forgotten. It's cleared together with paused when the cluster is resumed automatically.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoResumeSpec"),
+						},
+					},
 					"version": {
 						SchemaProps: spec.SchemaProps{
 							Description: "TiDB cluster version",
@@ -11602,6 +15310,13 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							},
 						},
 					},
+					"clusterRegistryPrefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterRegistryPrefix rewrites the registry of all the images used by the tidb cluster, including the component, helper and discovery images. E.g. with `registry.local/mirror`, `pingcap/tikv:v5.4.0` is pulled from `registry.local/mirror/pingcap/tikv:v5.4.0`, which is useful for air-gapped environments",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"configUpdateStrategy": {
 						SchemaProps: spec.SchemaProps{
 							Description: "ConfigUpdateStrategy determines how the configuration change is applied to the cluster. UpdateStrategyInPlace will update the ConfigMap of configuration in-place and an extra rolling-update of the cluster component is needed to reload the configuration change. UpdateStrategyRollingUpdate will create a new ConfigMap with the new configuration and rolling-update the related components to use the new ConfigMap, that is, the new configuration will be applied automatically.",
@@ -11609,6 +15324,13 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							Format:      "",
 						},
 					},
+					"configRevisionHistoryLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigRevisionHistoryLimit is the number of the old ConfigMaps of the configuration retained for each component when ConfigUpdateStrategy is RollingUpdate, the configuration of a component can be rolled back to a retained ConfigMap by setting its name to the annotation tidb.pingcap.com/config-rollback. The ConfigMaps in use and the newest one are always retained. Optional: Defaults to nil, which retains all the old ConfigMaps",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 					"enablePVReclaim": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Whether enable PVC reclaim for orphan PVC left by statefulset scale-in Optional: Defaults to false",
@@ -11622,6 +15344,13 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSCluster"),
 						},
 					},
+					"encryptionKeySecret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EncryptionKeySecret is the name of the Secret containing the master key of the encryption at rest of TiKV in the key `master-key`, which is a 256-bit key encoded in hex. The master key is rotated by setting it to a new Secret, the previous Secret must be kept until the next rotation, as TiKV uses it to re-encrypt the data keys. The encryption can't be disabled by removing the Secret, set `security.encryption.data-encryption-method` of TiKV to `plaintext` instead. Optional: Defaults to \"\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"hostNetwork": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Whether Hostnetwork is enabled for TiDB cluster Pods Optional: Defaults to false",
@@ -11687,6 +15416,21 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							},
 						},
 					},
+					"cloudTags": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CloudTags are the tags of the cloud volumes of the cluster. They are set as the annotations of the PVCs of all components, including the ones created by failover, so the keys should be the annotations understood by the CSI driver of the storage class, e.g. the ones tagging EBS volumes or labeling PD disks.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
 					"tolerations": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Base tolerations of TiDB cluster Pods, components may add more tolerations upon this respectively",
@@ -11707,6 +15451,13 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							Format:      "",
 						},
 					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the TiDB cluster containers read-only, which is required if the images don't contain the database Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"enableDynamicConfiguration": {
 						SchemaProps: spec.SchemaProps{
 							Description: "EnableDynamicConfiguration indicates whether to append `--advertise-status-addr` to the startup parameters of TiKV.",
@@ -11771,22 +15522,125 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							},
 						},
 						SchemaProps: spec.SchemaProps{
-							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
+							Description: "TopologySpreadConstraints describes how a group of pods ought to spread across topology domains. Scheduler will schedule pods in a way which abides by the constraints. This field is is only honored by clusters that enables the EvenPodsSpread feature. All topologySpreadConstraints are ANDed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+									},
+								},
+							},
+						},
+					},
+					"architecture": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Architecture is the CPU architecture of the nodes that the Pods are scheduled to, a required node affinity on the `kubernetes.io/arch` label is generated for it. Components may override it, so that e.g. TiKV runs on arm64 nodes while TiFlash keeps running on amd64 nodes in a mixed node pool. Optional: Defaults to no architecture constraint",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"startScriptVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartScriptVersion is the version of the start scripts, changing it causes a rolling update. Components may override it to adopt the new start scripts gradually. Optional: Defaults to v1",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"configDrift": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigDrift enables the periodic detection of drift between the desired config of TiKV/TiDB and the config that the instances are actually running with, e.g. changed by `SET CONFIG` Optional: Defaults to nil, which means the detection is disabled",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigDriftSpec"),
+						},
+					},
+					"clockSkew": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClockSkew enables the periodic detection of the clock skew of the PD members and the nodes of the cluster, which breaks the TSO allocation and the leader leases in subtle ways Optional: Defaults to nil, which means the detection is disabled",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ClockSkewSpec"),
+						},
+					},
+					"versionSkew": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VersionSkew enables the detection of the version skew between the PD, TiKV and TiDB instances, e.g. left by a partially applied upgrade or the images edited by hand, and suggests a plan to repair it, which is executed by the operator once approved Optional: Defaults to nil, which means the detection is disabled",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.VersionSkewSpec"),
+						},
+					},
+					"storageProvisioning": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageProvisioning runs the hook pre-provisioning the storage of the components before their StatefulSets are created",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvisioningSpec"),
+						},
+					},
+					"tombstoneStoreGC": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TombstoneStoreGC enables the periodic removal of the tombstone stores of TiKV and TiFlash from PD once the PVCs of their Pods are deleted, which keeps the store list of PD clean after many scale-in and scale-out cycles Optional: Defaults to nil, which means the tombstone stores are kept",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TombstoneStoreGCSpec"),
+						},
+					},
+					"notifications": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Notifications configures the sinks to send the notifications of the significant transitions of the cluster to, e.g. upgrade started or finished and failover triggered",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NotificationSpec"),
+						},
+					},
+					"maintenance": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maintenance configures the periodic maintenance tasks of the cluster, e.g. TiKV compaction",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MaintenanceSpec"),
+						},
+					},
+					"spotTermination": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SpotTermination configures the handling of the termination notices of spot or preemptible nodes",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SpotTerminationSpec"),
+						},
+					},
+					"publishReadiness": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PublishReadiness maintains a ConfigMap named `${clusterName}-readiness` that mirrors the Ready condition of the cluster. The `ready` key only exists while the cluster is ready, so the Pods of the workloads depending on the cluster can wait for it declaratively by referencing the key in a ConfigMapKeySelector. Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"diagnostics": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Diagnostics configures the collection of the diagnostics bundles for the root cause analysis when the cluster runs into a failure, e.g. a failover is triggered or an upgrade gets stuck",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DiagnosticsSpec"),
+						},
+					},
+					"readOnly": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadOnly puts the whole cluster into the read-only mode, e.g. as a break-glass during incident response or migrations",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ReadOnlySpec"),
+						},
+					},
+					"featureGates": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FeatureGates enables or disables the experimental behaviors of the operator for this cluster, the gates set here take precedence over the operator-wide settings, so that a feature can be canaried on a single cluster. Unknown gates are ignored.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint"),
+										Type:   []string{"boolean"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
+					"profile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Profile selects the preset config of TiKV, TiDB and TiFlash tuned for the workload, e.g. the thread pools of TiKV and the memory quotas of TiDB. The presets are merged under the config of the components, i.e. the items set in the config take precedence, and TiKV and TiDB without config are not affected. The effective config is exported to a ConfigMap named `${clusterName}-effective-config` for inspection. Changing the profile causes a rolling update of the affected components.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DiscoverySpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PumpSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSCluster", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoResumeSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ClockSkewSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigDriftSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DiagnosticsSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DiscoverySpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.MaintenanceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NotificationSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PumpSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ReadOnlySpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SpotTerminationSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageProvisioningSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSCluster", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiProxySpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TombstoneStoreGCSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.VersionSkewSpec", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration"},
 	}
 }
 
@@ -11910,6 +15764,13 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbInitializerSpec(ref common.ReferenceCa
 							},
 						},
 					},
+					"clusterRegistryPrefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterRegistryPrefix rewrites the registry of all the images used by the initializer job. E.g. with `registry.local/mirror`, `pingcap/tidb:v5.4.0` is pulled from `registry.local/mirror/pingcap/tidb:v5.4.0`, which is useful for air-gapped environments",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"permitHost": {
 						SchemaProps: spec.SchemaProps{
 							Description: "permitHost is the host which will only be allowed to connect to the TiDB.",
@@ -11931,12 +15792,24 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbInitializerSpec(ref common.ReferenceCa
 							Format:      "",
 						},
 					},
+					"initSqlStorage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "InitSqlStorage is the SQL scripts in the object storage executed in order after the initSql or the initSqlConfigMap, e.g. the schema bootstraps or the seed datasets too large for a ConfigMap",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitSqlStorageSpec"),
+						},
+					},
 					"passwordSecret": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
+					"userReconciliation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UserReconciliation keeps reconciling the users declared in the passwordSecret after the initialization, so that the users dropped or changed manually are recreated and their passwords and grants are restored. Optional: Defaults to nil, which means the users are only created once by the initializer Job",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.UserReconciliationSpec"),
+						},
+					},
 					"resources": {
 						SchemaProps: spec.SchemaProps{
 							Ref: ref("k8s.io/api/core/v1.ResourceRequirements"),
@@ -11956,12 +15829,38 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbInitializerSpec(ref common.ReferenceCa
 							Format:      "",
 						},
 					},
+					"additionalVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volumes of the initializer Job Pods, e.g. the ConfigMaps or Secrets of custom CA bundles, proxy settings or wrapper scripts",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Volume"),
+									},
+								},
+							},
+						},
+					},
+					"additionalVolumeMounts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional volume mounts of the initializer container",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"image", "cluster"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitSqlStorageSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.UserReconciliationSpec", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
 	}
 }
 
@@ -12030,11 +15929,17 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbInitializerStatus(ref common.Reference
 							Format:      "",
 						},
 					},
+					"userReconciliation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UserReconciliation is the result of the last reconciliation of the users",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.UserReconciliationStatus"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/batch/v1.JobCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.UserReconciliationStatus", "k8s.io/api/batch/v1.JobCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -12240,6 +16145,13 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbMonitorSpec(ref common.ReferenceCallba
 							},
 						},
 					},
+					"clusterRegistryPrefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterRegistryPrefix rewrites the registry of all the images used by the monitor, including the prometheus, grafana, initializer, reloader and thanos images. E.g. with `registry.local/mirror`, `prom/prometheus:v2.27.1` is pulled from `registry.local/mirror/prom/prometheus:v2.27.1`, which is useful for air-gapped environments",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"persistent": {
 						SchemaProps: spec.SchemaProps{
 							Description: "If Persistent enabled, storageClassName must be set to an existing storage. Defaults to false.",
@@ -12340,6 +16252,26 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbMonitorSpec(ref common.ReferenceCallba
 							Format:      "",
 						},
 					},
+					"dashboardVersionFromCluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DashboardVersionFromCluster selects the initializer image, which bundles the Grafana dashboards and the alert rules, by the version of the first monitored TidbCluster instead of `initializer.version`. The version is taken from the images of PD, TiKV and TiDB in the status of the cluster once they are the same, i.e. the dashboards are switched when the upgrade of the cluster is done, and the Pods of the monitor are rolled to reprovision Grafana. `alertManagerRulesVersion` still takes precedence for the alert rules if set.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"silenceWindows": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SilenceWindows are the recurring windows during which the matched alerts are silenced, e.g. the known-noisy alerts during the scheduled backups or maintenance tasks. The silences are created via the API of the Alertmanager at `alertmanagerURL` ahead of each window.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SilenceWindow"),
+									},
+								},
+							},
+						},
+					},
 					"additionalContainers": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Additional containers of the TidbMonitor.",
@@ -12355,7 +16287,7 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbMonitorSpec(ref common.ReferenceCallba
 					},
 					"clusterScoped": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ClusterScoped indicates whether this monitor should manage Kubernetes cluster-wide TiDB clusters",
+							Description: "ClusterScoped indicates whether this monitor should manage Kubernetes cluster-wide TiDB clusters. If not, a Role and RoleBinding are created in each namespace of the monitored clusters other than the namespace of the monitor.",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
@@ -12434,7 +16366,7 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbMonitorSpec(ref common.ReferenceCallba
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMMonitorSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GrafanaSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitializerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PrometheusReloaderSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PrometheusSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ReloaderSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ThanosSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMMonitorSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GrafanaSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitializerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PrometheusReloaderSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PrometheusSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ReloaderSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.SilenceWindow", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ThanosSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume"},
 	}
 }
 
@@ -12734,6 +16666,20 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbNGMonitoringSpec(ref common.ReferenceC
 							Format:      "",
 						},
 					},
+					"minReadySeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rollingUpdateIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 					"topologySpreadConstraints": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -12755,105 +16701,345 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbNGMonitoringSpec(ref common.ReferenceC
 							},
 						},
 					},
+					"architecture": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archBaseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"startScriptVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"helper": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"clusters": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Clusters reference TiDB cluster",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef"),
-									},
-								},
-							},
+							Description: "Clusters reference TiDB cluster",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef"),
+									},
+								},
+							},
+						},
+					},
+					"paused": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Paused pause controller if it is true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"pvReclaimPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Persistent volume reclaim policy applied to the PVs that consumed by tidb ng monitoring",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"clusterDomain": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterDomain is the Kubernetes Cluster Domain of tidb ng monitoring",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"clusterRegistryPrefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterRegistryPrefix rewrites the registry of all the images used by tidb ng monitoring. E.g. with `registry.local/mirror`, `pingcap/ng-monitoring:v5.4.0` is pulled from `registry.local/mirror/pingcap/ng-monitoring:v5.4.0`, which is useful for air-gapped environments",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ngMonitoring": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NGMonitoring is spec of ng monitoring",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NGMonitoringSpec"),
+						},
+					},
+				},
+				Required: []string{"clusters", "ngMonitoring"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NGMonitoringSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TikvAutoScalerSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TikvAutoScalerSpec describes the spec for tikv auto-scaling",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"rules": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Rules defines the rules for auto-scaling with PD API",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoRule"),
+									},
+								},
+							},
+						},
+					},
+					"scaleInIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ScaleInIntervalSeconds represents the duration seconds between each auto-scaling-in If not set, the default ScaleInIntervalSeconds will be set to 500",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"scaleOutIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ScaleOutIntervalSeconds represents the duration seconds between each auto-scaling-out If not set, the default ScaleOutIntervalSeconds will be set to 300",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"external": {
+						SchemaProps: spec.SchemaProps{
+							Description: "External makes the auto-scaler controller able to query the external service to fetch the recommended replicas for TiKV/TiDB",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalConfig"),
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources represent the resource type definitions that can be used for TiDB/TiKV The key is resource_type name of the resource",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoResource"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoResource", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoRule", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalConfig"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TikvAutoScalerStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TikvAutoScalerStatus describe the auto-scaling status of tikv",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"lastAutoScalingTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastAutoScalingTimestamp describes the last auto-scaling timestamp for the component(tidb/tikv)",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TombstoneStoreGCSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TombstoneStoreGCSpec describes how to remove the tombstone stores from PD. As PD removes all the tombstone stores at once, the stores are only removed when all of them belong to the cluster and the PVCs of their Pods are deleted.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"interval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Interval is the minimum interval between two removals Optional: Defaults to 1h",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
 						},
 					},
-					"paused": {
+					"dryRun": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Paused pause controller if it is true",
+							Description: "DryRun only reports the tombstone stores to be removed by events without removing them Optional: Defaults to false",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"pvReclaimPolicy": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TopSQLSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TopSQLSpec configures the Top SQL data collected by ng monitoring",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enable": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Persistent volume reclaim policy applied to the PVs that consumed by tidb ng monitoring",
-							Type:        []string{"string"},
+							Description: "Enable enables the collection of the Top SQL data of the target cluster. Optional: Defaults to true",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"clusterDomain": {
+					"retentionPeriod": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ClusterDomain is the Kubernetes Cluster Domain of tidb ng monitoring",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "RetentionPeriod is how long the Top SQL data is kept, e.g. 72h. Defaults to the retention period of ng monitoring if it's not set.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
 						},
 					},
-					"ngMonitoring": {
+					"storageSizeLimit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "NGMonitoring is spec of ng monitoring",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NGMonitoringSpec"),
+							Description: "StorageSizeLimit is the storage budget of the Top SQL data, the oldest data is removed when the budget is exceeded. No limit is set if it's not set.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
 						},
 					},
 				},
-				Required: []string{"clusters", "ngMonitoring"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.NGMonitoringSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
+			"k8s.io/apimachinery/pkg/api/resource.Quantity", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TikvAutoScalerSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TxnLocalLatches(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TikvAutoScalerSpec describes the spec for tikv auto-scaling",
+				Description: "TxnLocalLatches is the TxnLocalLatches section of the config.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"rules": {
+					"enabled": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Rules defines the rules for auto-scaling with PD API",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"capacity": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_UserGrant(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "UserGrant is the privileges granted to a user on an object, which are granted by GRANT privileges ON object TO user",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User is the name of the user, which must be declared in the passwordSecret",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"privileges": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Privileges are the names of the privileges, e.g. SELECT, INSERT or ALL PRIVILEGES",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoRule"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"scaleInIntervalSeconds": {
-						SchemaProps: spec.SchemaProps{
-							Description: "ScaleInIntervalSeconds represents the duration seconds between each auto-scaling-in If not set, the default ScaleInIntervalSeconds will be set to 500",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"scaleOutIntervalSeconds": {
+					"on": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ScaleOutIntervalSeconds represents the duration seconds between each auto-scaling-out If not set, the default ScaleOutIntervalSeconds will be set to 300",
-							Type:        []string{"integer"},
-							Format:      "int32",
+							Description: "On is the object the privileges are granted on, e.g. app.* or *.*",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"external": {
+				},
+				Required: []string{"user", "privileges", "on"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_UserReconciliationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "UserReconciliationSpec describes how the users declared in the passwordSecret are reconciled. The users other than root are recreated at the permitHost if they're dropped, their passwords are reset to the ones in the passwordSecret and the grants are granted again. The privileges granted manually are not revoked.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"interval": {
 						SchemaProps: spec.SchemaProps{
-							Description: "External makes the auto-scaler controller able to query the external service to fetch the recommended replicas for TiKV/TiDB",
-							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalConfig"),
+							Description: "Interval between two reconciliations Optional: Defaults to 5m",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
 						},
 					},
-					"resources": {
+					"grants": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Resources represent the resource type definitions that can be used for TiDB/TiKV The key is resource_type name of the resource",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "Grants are the privileges of the users that are kept granted",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoResource"),
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.UserGrant"),
 									},
 								},
 							},
@@ -12863,24 +17049,46 @@ func schema_pkg_apis_pingcap_v1alpha1_TikvAutoScalerSpec(ref common.ReferenceCal
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoResource", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoRule", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalConfig"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.UserGrant", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TikvAutoScalerStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_UserReconciliationStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TikvAutoScalerStatus describe the auto-scaling status of tikv",
+				Description: "UserReconciliationStatus is the result of the last reconciliation of the users",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"lastAutoScalingTimestamp": {
+					"lastReconcileTime": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LastAutoScalingTimestamp describes the last auto-scaling timestamp for the component(tidb/tikv)",
+							Description: "LastReconcileTime is the time of the last reconciliation",
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
+					"recreated": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Recreated are the users recreated by the last reconciliation as they were dropped",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is the error of the last reconciliation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
+				Required: []string{"lastReconcileTime"},
 			},
 		},
 		Dependencies: []string{
@@ -12888,26 +17096,50 @@ func schema_pkg_apis_pingcap_v1alpha1_TikvAutoScalerStatus(ref common.ReferenceC
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TxnLocalLatches(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_VersionSkewSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TxnLocalLatches is the TxnLocalLatches section of the config.",
+				Description: "VersionSkewSpec describes how to detect the version skew. The version of an instance is the tag of the image of its Pod, and only the major and minor versions are compared. The instances are skewed if they run different major versions, their minor versions differ by more than maxMinorSkew, or a component runs a newer version than the component upgraded before it, i.e. TiKV is newer than PD or TiDB is newer than TiKV. The repair plan is executed once the annotation `tidb.pingcap.com/repair-version-skew` of the cluster is set to the ID of the plan in status.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"enabled": {
+					"maxMinorSkew": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "MaxMinorSkew is the maximum difference of the minor versions of the instances that is tolerated Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"capacity": {
+					"gracePeriod": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "GracePeriod is how long a skew is tolerated before it's reported, so that the skew during a rolling upgrade is not reported Optional: Defaults to 30m",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_WebhookNotificationSink(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WebhookNotificationSink is a generic webhook sink",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"url": {
+						SchemaProps: spec.SchemaProps{
+							Description: "URL of the webhook",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
+				Required: []string{"url"},
 			},
 		},
 	}
@@ -13207,6 +17439,20 @@ func schema_pkg_apis_pingcap_v1alpha1_WorkerSpec(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"minReadySeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready, before the rolling update moves on to the next Pod. It absorbs the failures that show up some time after the Pod passes the readiness probe. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rollingUpdateIntervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod of the component and the upgrade of the next Pod, which paces the rolling update. Optional: Defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 					"topologySpreadConstraints": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -13228,6 +17474,55 @@ func schema_pkg_apis_pingcap_v1alpha1_WorkerSpec(ref common.ReferenceCallback) c
 							},
 						},
 					},
+					"architecture": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Architecture is the CPU architecture of the nodes that the component is scheduled to. Override the cluster-level architecture if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archBaseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ArchBaseImages overrides the base image of the component for the given architecture, e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a multi-arch manifest list. The version is appended the same way as the base image. It takes effect only if the architecture of the component is specified.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"startScriptVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartScriptVersion is the version of the start script of the component. Override the cluster-level startScriptVersion if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"helper": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Helper overrides the cluster-level helper image used by the sidecars and init containers of the component, each field takes effect separately Optional: Defaults to cluster-level setting",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec"),
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`, or a POSIX TZ string, e.g. `CST-8`. Override the cluster-level timezone if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mountTimezoneData": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the component container read-only, which is required if the image doesn't contain the database. Override the cluster-level mountTimezoneData if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"limits": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
@@ -13291,6 +17586,19 @@ func schema_pkg_apis_pingcap_v1alpha1_WorkerSpec(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"storageVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageVolumes configure additional storage for dm-worker pods.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume"),
+									},
+								},
+							},
+						},
+					},
 					"dataSubDir": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Subdirectory within the volume to store dm-worker Data. By default, the data is stored in the root directory of volume which is mounted at /var/lib/dm-worker. Specifying this will change the data directory to a subdirectory, e.g. /var/lib/dm-worker/data if you set the value to \"data\". It's dangerous to change this value for a running cluster as it will upgrade your cluster to use a new storage directory. Defaults to \"\" (volume's root).",
@@ -13311,12 +17619,37 @@ func schema_pkg_apis_pingcap_v1alpha1_WorkerSpec(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"sourcePlacements": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SourcePlacements are the placement hints of the upstream sources. The operator transfers a source to a free dm-worker running on the matching nodes when the dm-worker bound to it doesn't match, e.g. after the dm-worker is rescheduled. Use the affinity or the topology spread constraints to make the dm-workers run on the matching nodes.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMSourcePlacement"),
+									},
+								},
+							},
+						},
+					},
+					"podDisruptionBudget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodDisruptionBudget configures the PodDisruptionBudget of the dm-worker pods. No PodDisruptionBudget is created if it's not set.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec"),
+						},
+					},
+					"relayLogStorage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RelayLogStorage configures a sidecar uploading the relay logs of dm-worker to the remote storage periodically, so that the relay logs can be removed from the local volume after they are uploaded. Changing it causes a rolling update of dm-worker.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RelayLogStorageSpec"),
+						},
+					},
 				},
 				Required: []string{"replicas"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WorkerConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DMSourcePlacement", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PodDisruptionBudgetSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RelayLogStorageSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.WorkerConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
@@ -0,0 +1,141 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpsCommandType is the tool run by an OpsCommand
+type OpsCommandType string
+
+const (
+	// OpsCommandTypePDCtl runs pd-ctl against the PD of a TidbCluster
+	OpsCommandTypePDCtl OpsCommandType = "pd-ctl"
+	// OpsCommandTypeDMCtl runs dmctl against the DM-master of a DMCluster
+	OpsCommandTypeDMCtl OpsCommandType = "dmctl"
+	// OpsCommandTypeBRDebug runs `br debug` with the PD of a TidbCluster
+	OpsCommandTypeBRDebug OpsCommandType = "br-debug"
+)
+
+// OpsCommandPhase is the phase of an OpsCommand
+type OpsCommandPhase string
+
+const (
+	// OpsCommandPending means the Job of the command is not created yet
+	OpsCommandPending OpsCommandPhase = "Pending"
+	// OpsCommandRunning means the Job of the command is running
+	OpsCommandRunning OpsCommandPhase = "Running"
+	// OpsCommandSucceeded means the command exited successfully
+	OpsCommandSucceeded OpsCommandPhase = "Succeeded"
+	// OpsCommandFailed means the command failed or could not be run
+	OpsCommandFailed OpsCommandPhase = "Failed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OpsCommand runs an ad-hoc pd-ctl, dmctl or `br debug` command against a cluster in a Job.
+// The endpoints and the TLS flags of the cluster are set by the operator, and the output of
+// the command is captured into the status.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:shortName="opc"
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`,description="The tool the command is run by"
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster`,description="The name of the cluster the command is run against"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,description="The phase of the command"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type OpsCommand struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the command to run
+	Spec OpsCommandSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the OpsCommand
+	Status OpsCommandStatus `json:"status,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// OpsCommandSpec describes the command run by an OpsCommand
+type OpsCommandSpec struct {
+	// Type is the tool to run, one of `pd-ctl`, `dmctl` and `br-debug`
+	// +kubebuilder:validation:Enum=pd-ctl;dmctl;br-debug
+	Type OpsCommandType `json:"type"`
+
+	// Cluster is the name of the cluster in the same namespace to run the command against,
+	// a DMCluster for `dmctl` and a TidbCluster for the others
+	Cluster string `json:"cluster"`
+
+	// Args are the arguments of the command, e.g. `["store", "limit"]` for pd-ctl or
+	// `["query-status"]` for dmctl. Only the read-only subcommands are allowed, the values of the flags
+	// should be passed in the `--flag=value` form, and the endpoints and the TLS flags must not be set
+	// as they are set by the operator.
+	Args []string `json:"args"`
+
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// ActiveDeadlineSeconds is the timeout of the Job of the command
+	// Optional: Defaults to 300
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// OpsCommandStatus is the status of an OpsCommand
+type OpsCommandStatus struct {
+	// Phase is the phase of the command
+	// +optional
+	Phase OpsCommandPhase `json:"phase,omitempty"`
+
+	// JobName is the name of the Job running the command
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// StartTime is the time the Job of the command is created
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the command finished
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Output is the output of the command, only the last 32KiB is kept
+	// +optional
+	Output string `json:"output,omitempty"`
+
+	// Message describes why the command failed or could not be run
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// OpsCommandList is OpsCommand list
+type OpsCommandList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []OpsCommand `json:"items"`
+}
@@ -70,6 +70,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DMClusterList{},
 		&TidbNGMonitoring{},
 		&TidbNGMonitoringList{},
+		&DMTask{},
+		&DMTaskList{},
+		&OpsCommand{},
+		&OpsCommandList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
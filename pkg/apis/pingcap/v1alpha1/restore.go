@@ -46,6 +46,16 @@ func (rs *Restore) GetRestorePVCName() string {
 	return fmt.Sprintf("restore-pvc-%s", rs.GetTidbEndpointHash())
 }
 
+// IsPiTR returns whether the Restore restores tidb cluster to a point in time
+func (rs *Restore) IsPiTR() bool {
+	return rs.Spec.Mode == RestoreModePiTR
+}
+
+// IsVolumeSnapshotRestore returns whether the Restore restores the PVCs from the CSI VolumeSnapshots of a Backup
+func (rs *Restore) IsVolumeSnapshotRestore() bool {
+	return rs.Spec.Mode == RestoreModeVolumeSnapshot
+}
+
 // GetRestoreCondition get the specify type's RestoreCondition from the given RestoreStatus
 func GetRestoreCondition(status *RestoreStatus, conditionType RestoreConditionType) (int, *RestoreCondition) {
 	if status == nil {
@@ -63,6 +73,9 @@ func GetRestoreCondition(status *RestoreStatus, conditionType RestoreConditionTy
 // one. Sets LastTransitionTime to now if the status has changed.
 // Returns true if Restore condition has changed or has been added.
 func UpdateRestoreCondition(status *RestoreStatus, condition *RestoreCondition) bool {
+	if condition == nil {
+		return false
+	}
 	condition.LastTransitionTime = metav1.Now()
 	// Try to find this Restore condition.
 	conditionIndex, oldCondition := GetRestoreCondition(status, condition.Type)
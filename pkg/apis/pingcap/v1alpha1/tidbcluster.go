@@ -21,6 +21,8 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
@@ -36,6 +38,31 @@ const (
 	defaultEnablePVReclaim    = false
 	// defaultEvictLeaderTimeout is the timeout limit of evict leader
 	defaultEvictLeaderTimeout = 1500 * time.Minute
+	// defaultTiCDCGracefulShutdownTimeout is the timeout limit of the graceful shutdown of a ticdc capture
+	defaultTiCDCGracefulShutdownTimeout = 10 * time.Minute
+	// defaultMaxReceivingSnapshotsOnUpgrade is the default maximum number of snapshots a store can be receiving
+	// or applying for its Pod to be upgraded
+	defaultMaxReceivingSnapshotsOnUpgrade = 3
+	// defaultLeaderRecoveryTimeout is the default maximum time to wait for the leaders of an upgraded store to recover
+	defaultLeaderRecoveryTimeout = 10 * time.Minute
+	// defaultRegionHealthRefreshInterval is the default minimum interval between two refreshes of the region health
+	defaultRegionHealthRefreshInterval = 5 * time.Minute
+	// defaultConfigDriftCheckInterval is the default minimum interval between two config drift checks
+	defaultConfigDriftCheckInterval = 5 * time.Minute
+	// defaultClockSkewCheckInterval is the default minimum interval between two clock skew checks
+	defaultClockSkewCheckInterval = 5 * time.Minute
+	// defaultMaxClockSkew is the default maximum tolerated skew of a PD member
+	defaultMaxClockSkew = 2 * time.Second
+	// defaultClockSkewCordonThreshold is the default skew of a PD member beyond which its node is cordoned
+	defaultClockSkewCordonThreshold = 10 * time.Second
+	// defaultTombstoneStoreGCInterval is the default minimum interval between two removals of the tombstone stores
+	defaultTombstoneStoreGCInterval = time.Hour
+	// defaultVersionSkewGracePeriod is the default duration a version skew is tolerated before it's reported
+	defaultVersionSkewGracePeriod = 30 * time.Minute
+	// defaultStorageProvisioningTimeout is the default timeout of the storage provisioning hook of a component
+	defaultStorageProvisioningTimeout = 30 * time.Minute
+	// defaultStorageProvisioningMaxRetries is the default maximum number of the retries of the storage provisioning hook
+	defaultStorageProvisioningMaxRetries = 3
 )
 
 var (
@@ -46,6 +73,14 @@ var (
 		ResourceRequirements: corev1.ResourceRequirements{},
 	}
 	defaultHelperSpec = HelperSpec{}
+	// defaultSpotTerminationTaints are the taints added by the node termination handlers of the cloud providers
+	defaultSpotTerminationTaints = []string{
+		"aws-node-termination-handler/spot-itn",
+		"aws-node-termination-handler/rebalance-recommendation",
+		"cloud.google.com/impending-node-termination",
+	}
+	// defaultClockSkewNodeConditions are the node conditions that report the clock is not synchronized
+	defaultClockSkewNodeConditions = []corev1.NodeConditionType{"NTPProblem"}
 )
 
 // PDImage return the image used by PD.
@@ -58,6 +93,9 @@ func (tc *TidbCluster) PDImage() string {
 
 	image := tc.Spec.PD.Image
 	baseImage := tc.Spec.PD.BaseImage
+	if archBaseImage := tc.BasePDSpec().ArchBaseImage(); archBaseImage != "" {
+		baseImage = archBaseImage
+	}
 	// base image takes higher priority
 	if baseImage != "" {
 		version := tc.Spec.PD.Version
@@ -70,7 +108,7 @@ func (tc *TidbCluster) PDImage() string {
 			image = fmt.Sprintf("%s:%s", baseImage, *version)
 		}
 	}
-	return image
+	return ImageWithRegistryPrefix(tc.Spec.ClusterRegistryPrefix, image)
 }
 
 // PDVersion return the image version used by PD.
@@ -100,6 +138,9 @@ func (tc *TidbCluster) TiKVImage() string {
 
 	image := tc.Spec.TiKV.Image
 	baseImage := tc.Spec.TiKV.BaseImage
+	if archBaseImage := tc.BaseTiKVSpec().ArchBaseImage(); archBaseImage != "" {
+		baseImage = archBaseImage
+	}
 	// base image takes higher priority
 	if baseImage != "" {
 		version := tc.Spec.TiKV.Version
@@ -112,7 +153,7 @@ func (tc *TidbCluster) TiKVImage() string {
 			image = fmt.Sprintf("%s:%s", baseImage, *version)
 		}
 	}
-	return image
+	return ImageWithRegistryPrefix(tc.Spec.ClusterRegistryPrefix, image)
 }
 
 // TiKVVersion return the image version used by TiKV.
@@ -150,6 +191,58 @@ func (tc *TidbCluster) TiKVEvictLeaderTimeout() time.Duration {
 	return defaultEvictLeaderTimeout
 }
 
+// TiKVMaxReceivingSnapshotsOnUpgrade returns the maximum number of snapshots a store can be receiving
+// or applying for its Pod to be upgraded, a negative value means no limit.
+func (tc *TidbCluster) TiKVMaxReceivingSnapshotsOnUpgrade() int32 {
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.MaxReceivingSnapshotsOnUpgrade != nil {
+		return *tc.Spec.TiKV.MaxReceivingSnapshotsOnUpgrade
+	}
+	return defaultMaxReceivingSnapshotsOnUpgrade
+}
+
+// TiKVLeaderRecoveryPercentOnUpgrade returns the percent of the leaders an upgraded store must regain
+// for the upgrade to proceed, 0 means the leader count is not checked.
+func (tc *TidbCluster) TiKVLeaderRecoveryPercentOnUpgrade() int32 {
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.LeaderRecoveryPercentOnUpgrade != nil {
+		return *tc.Spec.TiKV.LeaderRecoveryPercentOnUpgrade
+	}
+	return 0
+}
+
+// TiKVLeaderRecoveryTimeout returns the maximum time to wait for the leaders of an upgraded store to recover
+func (tc *TidbCluster) TiKVLeaderRecoveryTimeout() time.Duration {
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.LeaderRecoveryTimeout != nil {
+		d, err := time.ParseDuration(*tc.Spec.TiKV.LeaderRecoveryTimeout)
+		if err == nil {
+			return d
+		}
+	}
+	return defaultLeaderRecoveryTimeout
+}
+
+// TiKVRegionHealthRefreshInterval returns the minimum interval between two refreshes of the region health, 0 means
+// the region health is not refreshed
+func (tc *TidbCluster) TiKVRegionHealthRefreshInterval() time.Duration {
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.RegionHealthRefreshInterval != nil {
+		d, err := time.ParseDuration(*tc.Spec.TiKV.RegionHealthRefreshInterval)
+		if err == nil {
+			return d
+		}
+	}
+	return defaultRegionHealthRefreshInterval
+}
+
+// TiCDCGracefulShutdownTimeout returns the timeout of the graceful shutdown of a capture during the upgrade
+func (tc *TidbCluster) TiCDCGracefulShutdownTimeout() time.Duration {
+	if tc.Spec.TiCDC != nil && tc.Spec.TiCDC.GracefulShutdownTimeout != nil {
+		d, err := time.ParseDuration(*tc.Spec.TiCDC.GracefulShutdownTimeout)
+		if err == nil {
+			return d
+		}
+	}
+	return defaultTiCDCGracefulShutdownTimeout
+}
+
 // TiFlashImage return the image used by TiFlash.
 //
 // If TiFlash isn't specified, return empty string.
@@ -160,6 +253,9 @@ func (tc *TidbCluster) TiFlashImage() string {
 
 	image := tc.Spec.TiFlash.Image
 	baseImage := tc.Spec.TiFlash.BaseImage
+	if archBaseImage := tc.BaseTiFlashSpec().ArchBaseImage(); archBaseImage != "" {
+		baseImage = archBaseImage
+	}
 	// base image takes higher priority
 	if baseImage != "" {
 		version := tc.Spec.TiFlash.Version
@@ -172,7 +268,7 @@ func (tc *TidbCluster) TiFlashImage() string {
 			image = fmt.Sprintf("%s:%s", baseImage, *version)
 		}
 	}
-	return image
+	return ImageWithRegistryPrefix(tc.Spec.ClusterRegistryPrefix, image)
 }
 
 // TiFlashVersion returns the image version used by TiFlash.
@@ -202,6 +298,9 @@ func (tc *TidbCluster) TiCDCImage() string {
 
 	image := tc.Spec.TiCDC.Image
 	baseImage := tc.Spec.TiCDC.BaseImage
+	if archBaseImage := tc.BaseTiCDCSpec().ArchBaseImage(); archBaseImage != "" {
+		baseImage = archBaseImage
+	}
 	// base image takes higher priority
 	if baseImage != "" {
 		version := tc.Spec.TiCDC.Version
@@ -214,7 +313,35 @@ func (tc *TidbCluster) TiCDCImage() string {
 			image = fmt.Sprintf("%s:%s", baseImage, *version)
 		}
 	}
-	return image
+	return ImageWithRegistryPrefix(tc.Spec.ClusterRegistryPrefix, image)
+}
+
+// TiProxyImage return the image used by TiProxy.
+//
+// If TiProxy isn't specified, return empty string.
+func (tc *TidbCluster) TiProxyImage() string {
+	if tc.Spec.TiProxy == nil {
+		return ""
+	}
+
+	image := tc.Spec.TiProxy.Image
+	baseImage := tc.Spec.TiProxy.BaseImage
+	if archBaseImage := tc.BaseTiProxySpec().ArchBaseImage(); archBaseImage != "" {
+		baseImage = archBaseImage
+	}
+	// base image takes higher priority
+	if baseImage != "" {
+		version := tc.Spec.TiProxy.Version
+		if version == nil {
+			version = &tc.Spec.Version
+		}
+		if *version == "" {
+			image = baseImage
+		} else {
+			image = fmt.Sprintf("%s:%s", baseImage, *version)
+		}
+	}
+	return ImageWithRegistryPrefix(tc.Spec.ClusterRegistryPrefix, image)
 }
 
 func (tc *TidbCluster) TiFlashContainerPrivilege() *bool {
@@ -235,6 +362,9 @@ func (tc *TidbCluster) TiDBImage() string {
 
 	image := tc.Spec.TiDB.Image
 	baseImage := tc.Spec.TiDB.BaseImage
+	if archBaseImage := tc.BaseTiDBSpec().ArchBaseImage(); archBaseImage != "" {
+		baseImage = archBaseImage
+	}
 	// base image takes higher priority
 	if baseImage != "" {
 		version := tc.Spec.TiDB.Version
@@ -247,7 +377,24 @@ func (tc *TidbCluster) TiDBImage() string {
 			image = fmt.Sprintf("%s:%s", baseImage, *version)
 		}
 	}
-	return image
+	return ImageWithRegistryPrefix(tc.Spec.ClusterRegistryPrefix, image)
+}
+
+// TiDBVersion returns the image version used by TiDB.
+//
+// If TiDB isn't specified, return empty string.
+func (tc *TidbCluster) TiDBVersion() string {
+	if tc.Spec.TiDB == nil {
+		return ""
+	}
+
+	image := tc.TiDBImage()
+	colonIdx := strings.LastIndexByte(image, ':')
+	if colonIdx >= 0 {
+		return image[colonIdx+1:]
+	}
+
+	return "latest"
 }
 
 // PumpImage return the image used by Pump.
@@ -260,6 +407,9 @@ func (tc *TidbCluster) PumpImage() *string {
 
 	image := tc.Spec.Pump.Image
 	baseImage := tc.Spec.Pump.BaseImage
+	if archBaseImage := tc.BasePumpSpec().ArchBaseImage(); archBaseImage != "" {
+		baseImage = archBaseImage
+	}
 	// base image takes higher priority
 	if baseImage != "" {
 		version := tc.Spec.Pump.Version
@@ -272,19 +422,234 @@ func (tc *TidbCluster) PumpImage() *string {
 			image = fmt.Sprintf("%s:%s", baseImage, *version)
 		}
 	}
+	image = ImageWithRegistryPrefix(tc.Spec.ClusterRegistryPrefix, image)
 	return &image
 }
 
+// HelperImage returns the cluster-level helper image, use the HelperImage of
+// the component accessor to respect the component-level overrides
 func (tc *TidbCluster) HelperImage() string {
-	image := tc.GetHelperSpec().Image
+	image, digest := tc.helperImageAndDigest()
+	return ImageWithRegistryPrefix(tc.Spec.ClusterRegistryPrefix, ImageWithDigest(image, digest))
+}
+
+// helperImageAndDigest returns the cluster-level helper image without the registry
+// prefix and the digest it is pinned to
+func (tc *TidbCluster) helperImageAndDigest() (string, string) {
+	helper := tc.GetHelperSpec()
+	image := helper.Image
 	if image == nil && tc.Spec.TiDB != nil {
 		// for backward compatibility
 		image = tc.Spec.TiDB.GetSlowLogTailerSpec().Image
 	}
+	digest := ""
+	if helper.Digest != nil {
+		digest = *helper.Digest
+	}
 	if image == nil {
-		return defaultHelperImage
+		return defaultHelperImage, digest
+	}
+	return *image, digest
+}
+
+// IsNodeUpgradeMaintenance returns whether the cluster is in the node upgrade maintenance mode, in which
+// the Pods are evicted node by node after the leaders are transferred, and failover is suppressed for
+// the Pods on cordoned nodes
+func (tc *TidbCluster) IsNodeUpgradeMaintenance() bool {
+	return tc.Annotations[label.AnnMaintenanceKey] == label.AnnMaintenanceNodeUpgradeVal
+}
+
+// IsConfigDriftCheckEnabled returns whether the config drift detection is enabled
+func (tc *TidbCluster) IsConfigDriftCheckEnabled() bool {
+	return tc.Spec.ConfigDrift != nil
+}
+
+// ConfigDriftPolicy returns the action taken when config drift is detected
+func (tc *TidbCluster) ConfigDriftPolicy() ConfigDriftPolicy {
+	if tc.Spec.ConfigDrift == nil || tc.Spec.ConfigDrift.Policy == "" {
+		return ConfigDriftPolicyReport
+	}
+	return tc.Spec.ConfigDrift.Policy
+}
+
+// ConfigDriftCheckInterval returns the minimum interval between two config drift checks
+func (tc *TidbCluster) ConfigDriftCheckInterval() time.Duration {
+	if tc.Spec.ConfigDrift == nil || tc.Spec.ConfigDrift.Interval == nil {
+		return defaultConfigDriftCheckInterval
+	}
+	return tc.Spec.ConfigDrift.Interval.Duration
+}
+
+// IsClockSkewCheckEnabled returns whether the clock skew detection is enabled
+func (tc *TidbCluster) IsClockSkewCheckEnabled() bool {
+	return tc.Spec.ClockSkew != nil
+}
+
+// ClockSkewPolicy returns the action taken when a badly skewed node is detected
+func (tc *TidbCluster) ClockSkewPolicy() ClockSkewPolicy {
+	if tc.Spec.ClockSkew == nil || tc.Spec.ClockSkew.Policy == "" {
+		return ClockSkewPolicyReport
+	}
+	return tc.Spec.ClockSkew.Policy
+}
+
+// ClockSkewCheckInterval returns the minimum interval between two clock skew checks
+func (tc *TidbCluster) ClockSkewCheckInterval() time.Duration {
+	if tc.Spec.ClockSkew == nil || tc.Spec.ClockSkew.Interval == nil {
+		return defaultClockSkewCheckInterval
+	}
+	return tc.Spec.ClockSkew.Interval.Duration
+}
+
+// IsTombstoneStoreGCEnabled returns whether the tombstone stores are removed from PD periodically
+func (tc *TidbCluster) IsTombstoneStoreGCEnabled() bool {
+	return tc.Spec.TombstoneStoreGC != nil
+}
+
+// TombstoneStoreGCInterval returns the minimum interval between two removals of the tombstone stores
+func (tc *TidbCluster) TombstoneStoreGCInterval() time.Duration {
+	if tc.Spec.TombstoneStoreGC == nil || tc.Spec.TombstoneStoreGC.Interval == nil {
+		return defaultTombstoneStoreGCInterval
+	}
+	return tc.Spec.TombstoneStoreGC.Interval.Duration
+}
+
+// MaxClockSkew returns the maximum tolerated skew of a PD member
+func (tc *TidbCluster) MaxClockSkew() time.Duration {
+	if tc.Spec.ClockSkew == nil || tc.Spec.ClockSkew.MaxSkew == nil {
+		return defaultMaxClockSkew
+	}
+	return tc.Spec.ClockSkew.MaxSkew.Duration
+}
+
+// ClockSkewCordonThreshold returns the skew of a PD member beyond which its node is cordoned
+func (tc *TidbCluster) ClockSkewCordonThreshold() time.Duration {
+	if tc.Spec.ClockSkew == nil || tc.Spec.ClockSkew.CordonThreshold == nil {
+		return defaultClockSkewCordonThreshold
+	}
+	return tc.Spec.ClockSkew.CordonThreshold.Duration
+}
+
+// ClockSkewNodeConditions returns the types of the node conditions that report the clock is not synchronized
+func (tc *TidbCluster) ClockSkewNodeConditions() []corev1.NodeConditionType {
+	if tc.Spec.ClockSkew == nil || len(tc.Spec.ClockSkew.NodeConditions) == 0 {
+		return defaultClockSkewNodeConditions
+	}
+	return tc.Spec.ClockSkew.NodeConditions
+}
+
+// IsVersionSkewCheckEnabled returns whether the version skew detection is enabled
+func (tc *TidbCluster) IsVersionSkewCheckEnabled() bool {
+	return tc.Spec.VersionSkew != nil
+}
+
+// MaxMinorVersionSkew returns the maximum tolerated difference of the minor versions of the instances
+func (tc *TidbCluster) MaxMinorVersionSkew() int64 {
+	if tc.Spec.VersionSkew == nil {
+		return 0
+	}
+	return int64(tc.Spec.VersionSkew.MaxMinorSkew)
+}
+
+// VersionSkewGracePeriod returns how long a version skew is tolerated before it's reported
+func (tc *TidbCluster) VersionSkewGracePeriod() time.Duration {
+	if tc.Spec.VersionSkew == nil || tc.Spec.VersionSkew.GracePeriod == nil {
+		return defaultVersionSkewGracePeriod
+	}
+	return tc.Spec.VersionSkew.GracePeriod.Duration
+}
+
+// IsStorageProvisioningEnabled returns whether the storage provisioning hook is run for the component
+func (tc *TidbCluster) IsStorageProvisioningEnabled(memberType MemberType) bool {
+	if tc.Spec.StorageProvisioning == nil {
+		return false
+	}
+	components := tc.Spec.StorageProvisioning.Components
+	if len(components) == 0 {
+		components = []MemberType{PDMemberType, TiKVMemberType, TiFlashMemberType}
+	}
+	for _, component := range components {
+		if component == memberType {
+			return true
+		}
+	}
+	return false
+}
+
+// StorageProvisioningTimeout returns the timeout of the storage provisioning hook of a component
+func (tc *TidbCluster) StorageProvisioningTimeout() time.Duration {
+	if tc.Spec.StorageProvisioning == nil || tc.Spec.StorageProvisioning.Timeout == nil {
+		return defaultStorageProvisioningTimeout
+	}
+	return tc.Spec.StorageProvisioning.Timeout.Duration
+}
+
+// StorageProvisioningMaxRetries returns the maximum number of the retries of the storage provisioning hook
+func (tc *TidbCluster) StorageProvisioningMaxRetries() int32 {
+	if tc.Spec.StorageProvisioning == nil || tc.Spec.StorageProvisioning.MaxRetries == nil {
+		return defaultStorageProvisioningMaxRetries
+	}
+	return *tc.Spec.StorageProvisioning.MaxRetries
+}
+
+// IsSpotTerminationEnabled returns whether the nodes going to be terminated are handled proactively
+func (tc *TidbCluster) IsSpotTerminationEnabled() bool {
+	return tc.Spec.SpotTermination != nil
+}
+
+// SpotTerminationTaints returns the keys of the taints that mark a node as going to be terminated
+func (tc *TidbCluster) SpotTerminationTaints() []string {
+	if tc.Spec.SpotTermination == nil || len(tc.Spec.SpotTermination.Taints) == 0 {
+		return defaultSpotTerminationTaints
+	}
+	return tc.Spec.SpotTermination.Taints
+}
+
+// GetInFlightOperation returns the in-flight operation of the given type on the Pod, or nil if not found
+func (tc *TidbCluster) GetInFlightOperation(opType InFlightOperationType, memberType MemberType, podName string) *InFlightOperation {
+	for i := range tc.Status.InFlightOperations {
+		op := &tc.Status.InFlightOperations[i]
+		if op.Type == opType && op.Component == memberType && op.PodName == podName {
+			return op
+		}
+	}
+	return nil
+}
+
+// SetInFlightOperation records the in-flight operation, the start time of an existing operation is kept
+func (tc *TidbCluster) SetInFlightOperation(op InFlightOperation) {
+	if existing := tc.GetInFlightOperation(op.Type, op.Component, op.PodName); existing != nil {
+		existing.StoreID = op.StoreID
+		return
 	}
-	return *image
+	if op.StartTime.IsZero() {
+		op.StartTime = metav1.Now()
+	}
+	tc.Status.InFlightOperations = append(tc.Status.InFlightOperations, op)
+}
+
+// RemoveInFlightOperation removes the in-flight operation of the given type on the Pod
+func (tc *TidbCluster) RemoveInFlightOperation(opType InFlightOperationType, memberType MemberType, podName string) {
+	var ops []InFlightOperation
+	for _, op := range tc.Status.InFlightOperations {
+		if op.Type == opType && op.Component == memberType && op.PodName == podName {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	tc.Status.InFlightOperations = ops
+}
+
+// RemoveInFlightOperations removes the in-flight operations of the given type on all the Pods of the component
+func (tc *TidbCluster) RemoveInFlightOperations(opType InFlightOperationType, memberType MemberType) {
+	var ops []InFlightOperation
+	for _, op := range tc.Status.InFlightOperations {
+		if op.Type == opType && op.Component == memberType {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	tc.Status.InFlightOperations = ops
 }
 
 func (tc *TidbCluster) HelperImagePullPolicy() corev1.PullPolicy {
@@ -346,24 +711,42 @@ func (tc *TidbCluster) TiFlashScaling() bool {
 	return tc.Status.TiFlash.Phase == ScalePhase
 }
 
+// DeleteSlots returns the ordinals of the Pods to delete of the component, which are the union of
+// the delete-slots annotation and the deleteSlots field of the component spec.
+func (tc *TidbCluster) DeleteSlots(component string) sets.Int32 {
+	return tc.getDeleteSlots(component)
+}
+
 func (tc *TidbCluster) getDeleteSlots(component string) (deleteSlots sets.Int32) {
 	deleteSlots = sets.NewInt32()
-	annotations := tc.GetAnnotations()
-	if annotations == nil {
-		return deleteSlots
-	}
 	var key string
 	if component == label.PDLabelVal {
 		key = label.AnnPDDeleteSlots
+		if tc.Spec.PD != nil {
+			deleteSlots.Insert(tc.Spec.PD.DeleteSlots...)
+		}
 	} else if component == label.TiDBLabelVal {
 		key = label.AnnTiDBDeleteSlots
+		if tc.Spec.TiDB != nil {
+			deleteSlots.Insert(tc.Spec.TiDB.DeleteSlots...)
+		}
 	} else if component == label.TiKVLabelVal {
 		key = label.AnnTiKVDeleteSlots
+		if tc.Spec.TiKV != nil {
+			deleteSlots.Insert(tc.Spec.TiKV.DeleteSlots...)
+		}
 	} else if component == label.TiFlashLabelVal {
 		key = label.AnnTiFlashDeleteSlots
+		if tc.Spec.TiFlash != nil {
+			deleteSlots.Insert(tc.Spec.TiFlash.DeleteSlots...)
+		}
 	} else {
 		return
 	}
+	annotations := tc.GetAnnotations()
+	if annotations == nil {
+		return
+	}
 	value, ok := annotations[key]
 	if !ok {
 		return
@@ -544,6 +927,27 @@ func (tc *TidbCluster) TiFlashAllStoresReady() bool {
 	return true
 }
 
+// TiCDCAllCapturesReady return whether all captures of TiCDC are ready.
+//
+// If TiCDC isn't specified, return false.
+func (tc *TidbCluster) TiCDCAllCapturesReady() bool {
+	if tc.Spec.TiCDC == nil {
+		return false
+	}
+
+	if int(tc.TiCDCDeployDesiredReplicas()) != len(tc.Status.TiCDC.Captures) {
+		return false
+	}
+
+	for _, capture := range tc.Status.TiCDC.Captures {
+		if !capture.Ready {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (tc *TidbCluster) TiFlashStsDesiredReplicas() int32 {
 	if tc.Spec.TiFlash == nil {
 		return 0
@@ -559,6 +963,32 @@ func (tc *TidbCluster) TiCDCDeployDesiredReplicas() int32 {
 	return tc.Spec.TiCDC.Replicas
 }
 
+func (tc *TidbCluster) TiProxyAllMembersReady() bool {
+	if tc.Spec.TiProxy == nil {
+		return false
+	}
+
+	if int(tc.TiProxyDeployDesiredReplicas()) != len(tc.Status.TiProxy.Members) {
+		return false
+	}
+
+	for _, member := range tc.Status.TiProxy.Members {
+		if !member.Health {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (tc *TidbCluster) TiProxyDeployDesiredReplicas() int32 {
+	if tc.Spec.TiProxy == nil {
+		return 0
+	}
+
+	return tc.Spec.TiProxy.Replicas
+}
+
 func (tc *TidbCluster) TiFlashStsActualReplicas() int32 {
 	stsStatus := tc.Status.TiFlash.StatefulSet
 	if stsStatus == nil {
@@ -707,6 +1137,24 @@ func (tc *TidbCluster) TiKVIsAvailable() bool {
 	return true
 }
 
+// PumpAllMembersOnline return whether all members of Pump are online.
+//
+// If Pump isn't specified, return false.
+func (tc *TidbCluster) PumpAllMembersOnline() bool {
+	if tc.Spec.Pump == nil {
+		return false
+	}
+
+	onlineNum := 0
+	for _, member := range tc.Status.Pump.Members {
+		if member.State == PumpStateOnline {
+			onlineNum++
+		}
+	}
+
+	return onlineNum == int(tc.Spec.Pump.Replicas)
+}
+
 func (tc *TidbCluster) PumpIsAvailable() bool {
 	lowerLimit := 1
 	if len(tc.Status.Pump.Members) < lowerLimit {
@@ -727,6 +1175,22 @@ func (tc *TidbCluster) GetClusterID() string {
 	return tc.Status.ClusterID
 }
 
+// AutoResumeTime returns the time to resume the paused cluster automatically,
+// nil is returned if the cluster is not paused or not resumed automatically.
+func (tc *TidbCluster) AutoResumeTime() *time.Time {
+	if !tc.Spec.Paused || tc.Spec.AutoResume == nil {
+		return nil
+	}
+	if tc.Spec.AutoResume.Until != nil {
+		return &tc.Spec.AutoResume.Until.Time
+	}
+	if tc.Spec.AutoResume.After != nil && tc.Status.PausedAt != nil {
+		t := tc.Status.PausedAt.Add(tc.Spec.AutoResume.After.Duration)
+		return &t
+	}
+	return nil
+}
+
 func (tc *TidbCluster) IsTLSClusterEnabled() bool {
 	return tc.Spec.TLSCluster != nil && tc.Spec.TLSCluster.Enabled
 }
@@ -738,6 +1202,81 @@ func (tc *TidbCluster) Scheme() string {
 	return "http"
 }
 
+// PDClientPort returns the port that PD serves the client requests on
+func (tc *TidbCluster) PDClientPort() int32 {
+	if tc.Spec.PD != nil && tc.Spec.PD.ClientPort != nil {
+		return *tc.Spec.PD.ClientPort
+	}
+	return DefaultPDClientPort
+}
+
+// TiKVPort returns the port that TiKV serves on
+func (tc *TidbCluster) TiKVPort() int32 {
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.Port != nil {
+		return *tc.Spec.TiKV.Port
+	}
+	return DefaultTiKVPort
+}
+
+// TiDBPort returns the port that TiDB serves the MySQL protocol on
+func (tc *TidbCluster) TiDBPort() int32 {
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.Port != nil {
+		return *tc.Spec.TiDB.Port
+	}
+	return DefaultTidbPort
+}
+
+// TiDBStatusPort returns the port that TiDB serves the status API on
+func (tc *TidbCluster) TiDBStatusPort() int32 {
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.StatusPort != nil {
+		return *tc.Spec.TiDB.StatusPort
+	}
+	return DefaultTidbStatusPort
+}
+
+// TiDBUpgradeMaxUnavailable returns the max number of the TiDB pods that can be restarted at the same time
+// during the upgrade, which is at least 1
+func (tc *TidbCluster) TiDBUpgradeMaxUnavailable() int {
+	if tc.Spec.TiDB == nil || tc.Spec.TiDB.UpgradeStrategy == nil || tc.Spec.TiDB.UpgradeStrategy.MaxUnavailable == nil {
+		return 1
+	}
+	maxUnavailable, err := intstr.GetValueFromIntOrPercent(tc.Spec.TiDB.UpgradeStrategy.MaxUnavailable, int(tc.Spec.TiDB.Replicas), false)
+	if err != nil {
+		klog.Warningf("invalid tidb maxUnavailable %s of %s/%s, upgrade the pods one by one: %v",
+			tc.Spec.TiDB.UpgradeStrategy.MaxUnavailable.String(), tc.Namespace, tc.Name, err)
+		return 1
+	}
+	if maxUnavailable < 1 {
+		return 1
+	}
+	return maxUnavailable
+}
+
+// TiKVColdGroupEnabled returns whether the TiKV cold group is configured
+func (tc *TidbCluster) TiKVColdGroupEnabled() bool {
+	return tc.Spec.TiKV != nil && tc.Spec.TiKV.ColdGroup != nil
+}
+
+// TiKVColdStsDesiredReplicas returns the desired replicas of the StatefulSet of the TiKV cold group
+func (tc *TidbCluster) TiKVColdStsDesiredReplicas() int32 {
+	if !tc.TiKVColdGroupEnabled() {
+		return 0
+	}
+	return tc.Spec.TiKV.ColdGroup.Replicas
+}
+
+// TiKVColdStoreLabels returns the labels of the stores of the TiKV cold group
+func (tc *TidbCluster) TiKVColdStoreLabels() map[string]string {
+	if tc.TiKVColdGroupEnabled() && len(tc.Spec.TiKV.ColdGroup.StoreLabels) > 0 {
+		return tc.Spec.TiKV.ColdGroup.StoreLabels
+	}
+	return map[string]string{DefaultTiKVColdStoreLabelKey: DefaultTiKVColdStoreLabelValue}
+}
+
+func (tc *TidbCluster) TiKVColdScaling() bool {
+	return tc.Status.TiKVCold.Phase == ScalePhase
+}
+
 func (tc *TidbCluster) Timezone() string {
 	tz := tc.Spec.Timezone
 	if tz == "" {
@@ -864,6 +1403,9 @@ func (tc *TidbCluster) TiCDCTimezone() string {
 		}
 
 	}
+	if tz := tc.BaseTiCDCSpec().Timezone(); tz != "" {
+		return tz
+	}
 	return tc.Timezone()
 }
 
@@ -49,7 +49,16 @@ type ComponentAccessor interface {
 	TerminationGracePeriodSeconds() *int64
 	StatefulSetUpdateStrategy() apps.StatefulSetUpdateStrategyType
 	PodManagementPolicy() apps.PodManagementPolicyType
+	MinReadySeconds() int32
+	RollingUpdateIntervalSeconds() int32
 	TopologySpreadConstraints() []corev1.TopologySpreadConstraint
+	Architecture() Architecture
+	ArchBaseImage() string
+	HelperImage() string
+	HelperImagePullPolicy() corev1.PullPolicy
+	StartScriptVersion() StartScriptVersion
+	Timezone() string
+	MountTimezoneData() bool
 }
 
 // Component defines component identity of all components
@@ -67,6 +76,7 @@ const (
 	ComponentDMMaster
 	ComponentDMWorker
 	ComponentNGMonitoring
+	ComponentTiProxy
 )
 
 type componentAccessorImpl struct {
@@ -89,6 +99,14 @@ type componentAccessorImpl struct {
 	podManagementPolicy       apps.PodManagementPolicyType
 	podSecurityContext        *corev1.PodSecurityContext
 	topologySpreadConstraints []TopologySpreadConstraint
+	architecture              Architecture
+	startScriptVersion        StartScriptVersion
+	registryPrefix            string
+	helperImage               string
+	helperDigest              string
+	helperImagePullPolicy     corev1.PullPolicy
+	timezone                  string
+	mountTimezoneData         *bool
 
 	// ComponentSpec is the Component Spec
 	ComponentSpec *ComponentSpec
@@ -151,10 +169,81 @@ func (a *componentAccessorImpl) HostNetwork() bool {
 }
 
 func (a *componentAccessorImpl) Affinity() *corev1.Affinity {
-	if a.ComponentSpec == nil || a.ComponentSpec.Affinity == nil {
-		return a.affinity
+	affinity := a.affinity
+	if a.ComponentSpec != nil && a.ComponentSpec.Affinity != nil {
+		affinity = a.ComponentSpec.Affinity
 	}
-	return a.ComponentSpec.Affinity
+	if arch := a.Architecture(); arch != "" {
+		return withArchitectureNodeAffinity(affinity, arch)
+	}
+	return affinity
+}
+
+func (a *componentAccessorImpl) Architecture() Architecture {
+	if a.ComponentSpec == nil || a.ComponentSpec.Architecture == nil {
+		return a.architecture
+	}
+	return *a.ComponentSpec.Architecture
+}
+
+func (a *componentAccessorImpl) StartScriptVersion() StartScriptVersion {
+	version := a.startScriptVersion
+	if a.ComponentSpec != nil && a.ComponentSpec.StartScriptVersion != nil {
+		version = *a.ComponentSpec.StartScriptVersion
+	}
+	if version == "" {
+		return StartScriptV1
+	}
+	return version
+}
+
+// ArchBaseImage returns the base image overridden for the architecture of the component,
+// or an empty string if there is no override
+func (a *componentAccessorImpl) ArchBaseImage() string {
+	arch := a.Architecture()
+	if a.ComponentSpec == nil || arch == "" {
+		return ""
+	}
+	return a.ComponentSpec.ArchBaseImages[arch]
+}
+
+// HelperImage returns the helper image used by the sidecars and init containers of the component.
+// The digest of the cluster-level helper image is dropped if the image is overridden.
+func (a *componentAccessorImpl) HelperImage() string {
+	image, digest := a.helperImage, a.helperDigest
+	if a.ComponentSpec != nil && a.ComponentSpec.Helper != nil {
+		if a.ComponentSpec.Helper.Image != nil {
+			image, digest = *a.ComponentSpec.Helper.Image, ""
+		}
+		if a.ComponentSpec.Helper.Digest != nil {
+			digest = *a.ComponentSpec.Helper.Digest
+		}
+	}
+	return ImageWithRegistryPrefix(a.registryPrefix, ImageWithDigest(image, digest))
+}
+
+func (a *componentAccessorImpl) HelperImagePullPolicy() corev1.PullPolicy {
+	if a.ComponentSpec == nil || a.ComponentSpec.Helper == nil || a.ComponentSpec.Helper.ImagePullPolicy == nil {
+		return a.helperImagePullPolicy
+	}
+	return *a.ComponentSpec.Helper.ImagePullPolicy
+}
+
+// Timezone returns the time zone of the component, or an empty string if neither the component
+// nor the cluster sets it
+func (a *componentAccessorImpl) Timezone() string {
+	if a.ComponentSpec == nil || a.ComponentSpec.Timezone == nil {
+		return a.timezone
+	}
+	return *a.ComponentSpec.Timezone
+}
+
+func (a *componentAccessorImpl) MountTimezoneData() bool {
+	mount := a.mountTimezoneData
+	if a.ComponentSpec != nil && a.ComponentSpec.MountTimezoneData != nil {
+		mount = a.ComponentSpec.MountTimezoneData
+	}
+	return mount != nil && *mount
 }
 
 func (a *componentAccessorImpl) PriorityClassName() *string {
@@ -306,6 +395,20 @@ func (a *componentAccessorImpl) TerminationGracePeriodSeconds() *int64 {
 	return a.ComponentSpec.TerminationGracePeriodSeconds
 }
 
+func (a *componentAccessorImpl) MinReadySeconds() int32 {
+	if a.ComponentSpec == nil || a.ComponentSpec.MinReadySeconds == nil {
+		return 0
+	}
+	return *a.ComponentSpec.MinReadySeconds
+}
+
+func (a *componentAccessorImpl) RollingUpdateIntervalSeconds() int32 {
+	if a.ComponentSpec == nil || a.ComponentSpec.RollingUpdateIntervalSeconds == nil {
+		return 0
+	}
+	return *a.ComponentSpec.RollingUpdateIntervalSeconds
+}
+
 func (a *componentAccessorImpl) TopologySpreadConstraints() []corev1.TopologySpreadConstraint {
 	tscs := a.topologySpreadConstraints
 	if a.ComponentSpec != nil && len(a.ComponentSpec.TopologySpreadConstraints) > 0 {
@@ -341,6 +444,39 @@ func (a *componentAccessorImpl) TopologySpreadConstraints() []corev1.TopologySpr
 	return ptscs
 }
 
+// withArchitectureNodeAffinity returns a copy of the affinity which requires the Pods to be
+// scheduled to the nodes of the given architecture
+func withArchitectureNodeAffinity(affinity *corev1.Affinity, arch Architecture) *corev1.Affinity {
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      corev1.LabelArchStable,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{string(arch)},
+	}
+
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	} else {
+		affinity = affinity.DeepCopy()
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{requirement}},
+			},
+		}
+		return affinity
+	}
+	// node selector terms are ORed, so the requirement must be added to each of them
+	for i := range required.NodeSelectorTerms {
+		required.NodeSelectorTerms[i].MatchExpressions = append(required.NodeSelectorTerms[i].MatchExpressions, requirement)
+	}
+	return affinity
+}
+
 func getComponentLabelValue(c Component) string {
 	switch c {
 	case ComponentPD:
@@ -353,6 +489,8 @@ func getComponentLabelValue(c Component) string {
 		return label.TiFlashLabelVal
 	case ComponentTiCDC:
 		return label.TiCDCLabelVal
+	case ComponentTiProxy:
+		return label.TiProxyLabelVal
 	case ComponentPump:
 		return label.PumpLabelVal
 	case ComponentDiscovery:
@@ -369,6 +507,7 @@ func getComponentLabelValue(c Component) string {
 
 func buildTidbClusterComponentAccessor(c Component, tc *TidbCluster, componentSpec *ComponentSpec) ComponentAccessor {
 	spec := &tc.Spec
+	helperImage, helperDigest := tc.helperImageAndDigest()
 	return &componentAccessorImpl{
 		name:                      tc.Name,
 		kind:                      TiDBClusterKind,
@@ -388,6 +527,14 @@ func buildTidbClusterComponentAccessor(c Component, tc *TidbCluster, componentSp
 		podManagementPolicy:       spec.PodManagementPolicy,
 		podSecurityContext:        spec.PodSecurityContext,
 		topologySpreadConstraints: spec.TopologySpreadConstraints,
+		architecture:              spec.Architecture,
+		startScriptVersion:        spec.StartScriptVersion,
+		registryPrefix:            spec.ClusterRegistryPrefix,
+		helperImage:               helperImage,
+		helperDigest:              helperDigest,
+		helperImagePullPolicy:     tc.HelperImagePullPolicy(),
+		timezone:                  spec.Timezone,
+		mountTimezoneData:         spec.MountTimezoneData,
 
 		ComponentSpec: componentSpec,
 	}
@@ -395,6 +542,7 @@ func buildTidbClusterComponentAccessor(c Component, tc *TidbCluster, componentSp
 
 func buildDMClusterComponentAccessor(c Component, dc *DMCluster, componentSpec *ComponentSpec) ComponentAccessor {
 	spec := &dc.Spec
+	helperImage, helperDigest := dc.helperImageAndDigest()
 	return &componentAccessorImpl{
 		name:                      dc.Name,
 		kind:                      DMClusterKind,
@@ -410,8 +558,18 @@ func buildDMClusterComponentAccessor(c Component, dc *DMCluster, componentSpec *
 		clusterAnnotations:        spec.Annotations,
 		tolerations:               spec.Tolerations,
 		configUpdateStrategy:      ConfigUpdateStrategyRollingUpdate,
+		statefulSetUpdateStrategy: spec.StatefulSetUpdateStrategy,
+		podManagementPolicy:       spec.PodManagementPolicy,
 		podSecurityContext:        spec.PodSecurityContext,
 		topologySpreadConstraints: spec.TopologySpreadConstraints,
+		architecture:              spec.Architecture,
+		startScriptVersion:        spec.StartScriptVersion,
+		registryPrefix:            spec.ClusterRegistryPrefix,
+		helperImage:               helperImage,
+		helperDigest:              helperDigest,
+		helperImagePullPolicy:     dc.HelperImagePullPolicy(),
+		timezone:                  dc.Timezone(),
+		mountTimezoneData:         spec.MountTimezoneData,
 
 		ComponentSpec: componentSpec,
 	}
@@ -462,6 +620,16 @@ func (tc *TidbCluster) BaseTiCDCSpec() ComponentAccessor {
 	return buildTidbClusterComponentAccessor(ComponentTiCDC, tc, spec)
 }
 
+// BaseTiProxySpec returns the base spec of TiProxy servers
+func (tc *TidbCluster) BaseTiProxySpec() ComponentAccessor {
+	var spec *ComponentSpec
+	if tc.Spec.TiProxy != nil {
+		spec = &tc.Spec.TiProxy.ComponentSpec
+	}
+
+	return buildTidbClusterComponentAccessor(ComponentTiProxy, tc, spec)
+}
+
 // BasePDSpec returns the base spec of PD servers
 func (tc *TidbCluster) BasePDSpec() ComponentAccessor {
 	var spec *ComponentSpec
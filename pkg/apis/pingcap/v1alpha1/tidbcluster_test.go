@@ -400,6 +400,67 @@ func TestComponentAccessor(t *testing.T) {
 				g.Expect(a.Tolerations()).Should(ConsistOf(toleration2))
 			},
 		},
+		{
+			name: "architecture node affinity",
+			cluster: &TidbClusterSpec{
+				Affinity:     affinity,
+				Architecture: ArchitectureAMD64,
+			},
+			component: &ComponentSpec{},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				g.Expect(a.Architecture()).Should(Equal(ArchitectureAMD64))
+				g.Expect(a.Affinity().PodAffinity).Should(Equal(affinity.PodAffinity))
+				g.Expect(a.Affinity().NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).Should(Equal([]corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64"}}}},
+				}))
+				// the cluster-level affinity must not be mutated
+				g.Expect(affinity.NodeAffinity).Should(BeNil())
+			},
+		},
+		{
+			name: "architecture override at component-level",
+			cluster: &TidbClusterSpec{
+				Architecture: ArchitectureAMD64,
+			},
+			component: &ComponentSpec{
+				Architecture: func() *Architecture { a := ArchitectureARM64; return &a }(),
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{
+								{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+								{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}},
+							},
+						},
+					},
+				},
+				ArchBaseImages: map[Architecture]string{
+					ArchitectureARM64: "example.com/tidb-arm64",
+				},
+			},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				g.Expect(a.Architecture()).Should(Equal(ArchitectureARM64))
+				g.Expect(a.ArchBaseImage()).Should(Equal("example.com/tidb-arm64"))
+				terms := a.Affinity().NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+				g.Expect(terms).Should(HaveLen(2))
+				for _, term := range terms {
+					g.Expect(term.MatchExpressions).Should(ContainElement(corev1.NodeSelectorRequirement{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}}))
+				}
+			},
+		},
+		{
+			name:    "no architecture",
+			cluster: &TidbClusterSpec{},
+			component: &ComponentSpec{
+				ArchBaseImages: map[Architecture]string{
+					ArchitectureARM64: "example.com/tidb-arm64",
+				},
+			},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				g.Expect(a.Affinity()).Should(BeNil())
+				g.Expect(a.ArchBaseImage()).Should(BeEmpty())
+			},
+		},
 	}
 
 	for i := range tests {
@@ -407,6 +468,83 @@ func TestComponentAccessor(t *testing.T) {
 	}
 }
 
+func TestArchBaseImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	arm64 := ArchitectureARM64
+	tc := &TidbCluster{
+		Spec: TidbClusterSpec{
+			Version:      "v5.4.0",
+			Architecture: ArchitectureAMD64,
+			TiKV: &TiKVSpec{
+				BaseImage: "pingcap/tikv",
+				ComponentSpec: ComponentSpec{
+					Architecture: &arm64,
+					ArchBaseImages: map[Architecture]string{
+						ArchitectureARM64: "example.com/tikv-arm64",
+					},
+				},
+			},
+			TiFlash: &TiFlashSpec{
+				BaseImage: "pingcap/tiflash",
+				ComponentSpec: ComponentSpec{
+					ArchBaseImages: map[Architecture]string{
+						ArchitectureARM64: "example.com/tiflash-arm64",
+					},
+				},
+			},
+		},
+	}
+	g.Expect(tc.TiKVImage()).Should(Equal("example.com/tikv-arm64:v5.4.0"))
+	g.Expect(tc.TiFlashImage()).Should(Equal("pingcap/tiflash:v5.4.0"))
+}
+
+func TestStartScriptVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	v1 := StartScriptV1
+	tc := &TidbCluster{
+		Spec: TidbClusterSpec{
+			StartScriptVersion: StartScriptV2,
+			PD:                 &PDSpec{},
+			TiKV: &TiKVSpec{
+				ComponentSpec: ComponentSpec{
+					StartScriptVersion: &v1,
+				},
+			},
+		},
+	}
+	g.Expect(tc.BasePDSpec().StartScriptVersion()).Should(Equal(StartScriptV2))
+	g.Expect(tc.BaseTiKVSpec().StartScriptVersion()).Should(Equal(StartScriptV1))
+
+	tc.Spec.StartScriptVersion = ""
+	g.Expect(tc.BasePDSpec().StartScriptVersion()).Should(Equal(StartScriptV1))
+}
+
+func TestInFlightOperation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &TidbCluster{}
+	g.Expect(tc.GetInFlightOperation(InFlightOperationUpgrade, TiKVMemberType, "tc-tikv-0")).Should(BeNil())
+
+	tc.SetInFlightOperation(InFlightOperation{Type: InFlightOperationUpgrade, Component: TiKVMemberType, PodName: "tc-tikv-0", StoreID: "1"})
+	tc.SetInFlightOperation(InFlightOperation{Type: InFlightOperationScaleIn, Component: TiKVMemberType, PodName: "tc-tikv-0", StoreID: "1"})
+	op := tc.GetInFlightOperation(InFlightOperationUpgrade, TiKVMemberType, "tc-tikv-0")
+	g.Expect(op).ShouldNot(BeNil())
+	g.Expect(op.StartTime.IsZero()).Should(BeFalse())
+	startTime := op.StartTime
+
+	tc.SetInFlightOperation(InFlightOperation{Type: InFlightOperationUpgrade, Component: TiKVMemberType, PodName: "tc-tikv-0", StoreID: "2"})
+	g.Expect(tc.Status.InFlightOperations).Should(HaveLen(2))
+	op = tc.GetInFlightOperation(InFlightOperationUpgrade, TiKVMemberType, "tc-tikv-0")
+	g.Expect(op.StoreID).Should(Equal("2"))
+	g.Expect(op.StartTime).Should(Equal(startTime))
+
+	tc.RemoveInFlightOperation(InFlightOperationUpgrade, TiKVMemberType, "tc-tikv-0")
+	g.Expect(tc.GetInFlightOperation(InFlightOperationUpgrade, TiKVMemberType, "tc-tikv-0")).Should(BeNil())
+	g.Expect(tc.Status.InFlightOperations).Should(HaveLen(1))
+}
+
 func TestHelperImage(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -568,6 +706,30 @@ func TestPDVersion(t *testing.T) {
 	}
 }
 
+func TestComponentTimezone(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	g.Expect(tc.BaseTiKVSpec().Timezone()).To(BeEmpty())
+	g.Expect(tc.BaseTiKVSpec().MountTimezoneData()).To(BeFalse())
+
+	tc.Spec.Timezone = "Asia/Shanghai"
+	tc.Spec.MountTimezoneData = pointer.BoolPtr(true)
+	tc.Spec.TiKV.Timezone = pointer.StringPtr("UTC")
+	tc.Spec.TiKV.MountTimezoneData = pointer.BoolPtr(false)
+	g.Expect(tc.BasePDSpec().Timezone()).To(Equal("Asia/Shanghai"))
+	g.Expect(tc.BasePDSpec().MountTimezoneData()).To(BeTrue())
+	g.Expect(tc.BaseTiKVSpec().Timezone()).To(Equal("UTC"))
+	g.Expect(tc.BaseTiKVSpec().MountTimezoneData()).To(BeFalse())
+
+	// the tz in the TiCDC config takes precedence
+	tc.Spec.TiCDC = &TiCDCSpec{ComponentSpec: ComponentSpec{Timezone: pointer.StringPtr("Europe/Berlin")}}
+	g.Expect(tc.TiCDCTimezone()).To(Equal("Europe/Berlin"))
+	tc.Spec.TiCDC.Config = NewCDCConfig()
+	tc.Spec.TiCDC.Config.Set("tz", "Asia/Tokyo")
+	g.Expect(tc.TiCDCTimezone()).To(Equal("Asia/Tokyo"))
+}
+
 func newTidbCluster() *TidbCluster {
 	return &TidbCluster{
 		TypeMeta: metav1.TypeMeta{
@@ -13,6 +13,8 @@
 
 package v1alpha1
 
+import "time"
+
 // GetPermitHost retrieves the permit host from TidbInitializer
 func (ti *TidbInitializer) GetPermitHost() string {
 	var permitHost string
@@ -23,3 +25,19 @@ func (ti *TidbInitializer) GetPermitHost() string {
 	}
 	return permitHost
 }
+
+// defaultUserReconcileInterval is the default interval between two reconciliations of the users
+const defaultUserReconcileInterval = 5 * time.Minute
+
+// IsUserReconciliationEnabled returns whether the users are reconciled after the initialization
+func (ti *TidbInitializer) IsUserReconciliationEnabled() bool {
+	return ti.Spec.UserReconciliation != nil && ti.Spec.PasswordSecret != nil
+}
+
+// UserReconcileInterval returns the interval between two reconciliations of the users
+func (ti *TidbInitializer) UserReconcileInterval() time.Duration {
+	if ti.Spec.UserReconciliation == nil || ti.Spec.UserReconciliation.Interval == nil {
+		return defaultUserReconcileInterval
+	}
+	return ti.Spec.UserReconciliation.Interval.Duration
+}
@@ -40,8 +40,9 @@ const (
 //
 // +k8s:openapi-gen=true
 // +kubebuilder:resource:shortName="ti"
-// +kubebuilder:printcolums:name="Phase",type=string,JSONPath=`.status.phase`,description="The current phase of initialization",priority=1
-// +kubebuilder:printcolums:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`,description="The name of the tidb cluster to initialize"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,description="The current phase of initialization"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type TidbInitializer struct {
 	metav1.TypeMeta `json:",inline"`
 	// +k8s:openapi-gen=false
@@ -73,6 +74,12 @@ type TidbInitializerSpec struct {
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
+	// ClusterRegistryPrefix rewrites the registry of all the images used by the initializer job.
+	// E.g. with `registry.local/mirror`, `pingcap/tidb:v5.4.0` is pulled from
+	// `registry.local/mirror/pingcap/tidb:v5.4.0`, which is useful for air-gapped environments
+	// +optional
+	ClusterRegistryPrefix string `json:"clusterRegistryPrefix,omitempty"`
+
 	// permitHost is the host which will only be allowed to connect to the TiDB.
 	// +optional
 	PermitHost *string `json:"permitHost,omitempty"`
@@ -85,9 +92,20 @@ type TidbInitializerSpec struct {
 	// +optional
 	InitSqlConfigMap *string `json:"initSqlConfigMap,omitempty"`
 
+	// InitSqlStorage is the SQL scripts in the object storage executed in order after the initSql or
+	// the initSqlConfigMap, e.g. the schema bootstraps or the seed datasets too large for a ConfigMap
+	// +optional
+	InitSqlStorage *InitSqlStorageSpec `json:"initSqlStorage,omitempty"`
+
 	// +optional
 	PasswordSecret *string `json:"passwordSecret,omitempty"`
 
+	// UserReconciliation keeps reconciling the users declared in the passwordSecret after the initialization,
+	// so that the users dropped or changed manually are recreated and their passwords and grants are restored.
+	// Optional: Defaults to nil, which means the users are only created once by the initializer Job
+	// +optional
+	UserReconciliation *UserReconciliationSpec `json:"userReconciliation,omitempty"`
+
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
@@ -99,6 +117,76 @@ type TidbInitializerSpec struct {
 	// Optional: Defaults to nil
 	// +optional
 	TLSClientSecretName *string `json:"tlsClientSecretName,omitempty"`
+
+	// Additional volumes of the initializer Job Pods, e.g. the ConfigMaps or Secrets of custom CA bundles,
+	// proxy settings or wrapper scripts
+	// +optional
+	AdditionalVolumes []corev1.Volume `json:"additionalVolumes,omitempty"`
+
+	// Additional volume mounts of the initializer container
+	// +optional
+	AdditionalVolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
+}
+
+// InitSqlStorageSpec describes the SQL scripts downloaded from the object storage by the initializer. The scripts
+// may contain statements spanning multiple lines, each statement must be terminated by a `;` at the end of a line.
+// +k8s:openapi-gen=true
+type InitSqlStorageSpec struct {
+	// StorageProvider is the storage the scripts are downloaded from, only S3 and GCS are supported.
+	// The credentials are read from the secretName of the storage.
+	StorageProvider `json:",inline"`
+
+	// Image is the image of the init container downloading the scripts, which provides rclone and generates its
+	// config from the env of the storage, e.g. the tidb-backup-manager image
+	Image string `json:"image"`
+
+	// Files are the paths of the scripts relative to the prefix of the storage, executed in the order listed.
+	// If empty, all the files with the .sql suffix under the prefix are executed in the lexical order of their paths.
+	// +optional
+	Files []string `json:"files,omitempty"`
+}
+
+// UserReconciliationSpec describes how the users declared in the passwordSecret are reconciled. The users
+// other than root are recreated at the permitHost if they're dropped, their passwords are reset to the ones in
+// the passwordSecret and the grants are granted again. The privileges granted manually are not revoked.
+// +k8s:openapi-gen=true
+type UserReconciliationSpec struct {
+	// Interval between two reconciliations
+	// Optional: Defaults to 5m
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Grants are the privileges of the users that are kept granted
+	// +optional
+	Grants []UserGrant `json:"grants,omitempty"`
+}
+
+// UserGrant is the privileges granted to a user on an object, which are granted by GRANT privileges ON object TO user
+// +k8s:openapi-gen=true
+type UserGrant struct {
+	// User is the name of the user, which must be declared in the passwordSecret
+	User string `json:"user"`
+
+	// Privileges are the names of the privileges, e.g. SELECT, INSERT or ALL PRIVILEGES
+	Privileges []string `json:"privileges"`
+
+	// On is the object the privileges are granted on, e.g. app.* or *.*
+	On string `json:"on"`
+}
+
+// UserReconciliationStatus is the result of the last reconciliation of the users
+// +k8s:openapi-gen=true
+type UserReconciliationStatus struct {
+	// LastReconcileTime is the time of the last reconciliation
+	LastReconcileTime metav1.Time `json:"lastReconcileTime"`
+
+	// Recreated are the users recreated by the last reconciliation as they were dropped
+	// +optional
+	Recreated []string `json:"recreated,omitempty"`
+
+	// Message is the error of the last reconciliation
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -107,6 +195,10 @@ type TidbInitializerStatus struct {
 
 	// Phase is a user readable state inferred from the underlying Job status and TidbCluster status
 	Phase InitializePhase `json:"phase,omitempty"`
+
+	// UserReconciliation is the result of the last reconciliation of the users
+	// +optional
+	UserReconciliation *UserReconciliationStatus `json:"userReconciliation,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
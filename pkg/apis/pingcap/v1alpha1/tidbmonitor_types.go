@@ -30,6 +30,9 @@ import (
 //
 // +k8s:openapi-gen=true
 // +kubebuilder:resource:shortName="tm"
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.statefulSet.readyReplicas`,description="The ready replicas number of the monitor"
+// +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.replicas`,description="The desired replicas number of the monitor"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type TidbMonitor struct {
 	metav1.TypeMeta `json:",inline"`
 	// +k8s:openapi-gen=false
@@ -91,6 +94,13 @@ type TidbMonitorSpec struct {
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
+	// ClusterRegistryPrefix rewrites the registry of all the images used by the monitor,
+	// including the prometheus, grafana, initializer, reloader and thanos images. E.g. with `registry.local/mirror`,
+	// `prom/prometheus:v2.27.1` is pulled from `registry.local/mirror/prom/prometheus:v2.27.1`,
+	// which is useful for air-gapped environments
+	// +optional
+	ClusterRegistryPrefix string `json:"clusterRegistryPrefix,omitempty"`
+
 	// If Persistent enabled, storageClassName must be set to an existing storage.
 	// Defaults to false.
 	// +optional
@@ -137,11 +147,27 @@ type TidbMonitorSpec struct {
 	// +optional
 	AlertManagerRulesVersion *string `json:"alertManagerRulesVersion,omitempty"`
 
+	// DashboardVersionFromCluster selects the initializer image, which bundles the Grafana dashboards and the
+	// alert rules, by the version of the first monitored TidbCluster instead of `initializer.version`.
+	// The version is taken from the images of PD, TiKV and TiDB in the status of the cluster once they are
+	// the same, i.e. the dashboards are switched when the upgrade of the cluster is done, and the Pods of
+	// the monitor are rolled to reprovision Grafana. `alertManagerRulesVersion` still takes precedence
+	// for the alert rules if set.
+	// +optional
+	DashboardVersionFromCluster bool `json:"dashboardVersionFromCluster,omitempty"`
+
+	// SilenceWindows are the recurring windows during which the matched alerts are silenced,
+	// e.g. the known-noisy alerts during the scheduled backups or maintenance tasks.
+	// The silences are created via the API of the Alertmanager at `alertmanagerURL` ahead of each window.
+	// +optional
+	SilenceWindows []SilenceWindow `json:"silenceWindows,omitempty"`
+
 	// Additional containers of the TidbMonitor.
 	// +optional
 	AdditionalContainers []corev1.Container `json:"additionalContainers,omitempty"`
 
-	// ClusterScoped indicates whether this monitor should manage Kubernetes cluster-wide TiDB clusters
+	// ClusterScoped indicates whether this monitor should manage Kubernetes cluster-wide TiDB clusters.
+	// If not, a Role and RoleBinding are created in each namespace of the monitored clusters other than the namespace of the monitor.
 	ClusterScoped bool `json:"clusterScoped,omitempty"`
 
 	// The labels to add to any time series or alerts when communicating with
@@ -187,6 +213,23 @@ type TidbMonitorSpec struct {
 	Timezone string `json:"timezone,omitempty"`
 }
 
+// +k8s:openapi-gen=true
+// SilenceWindow is a recurring window during which the matched alerts are silenced in the Alertmanager
+type SilenceWindow struct {
+	// Name is the unique name of the window in the TidbMonitor
+	Name string `json:"name"`
+	// Schedule is the start time of the window in the Cron format
+	Schedule string `json:"schedule"`
+	// Duration is the length of the window, e.g. 2h
+	Duration string `json:"duration"`
+	// Matchers select the alerts to silence, the keys are the label names and the values are regular expressions
+	Matchers map[string]string `json:"matchers"`
+	// Comment is attached to the silences
+	// Optional: Defaults to a description of the window
+	// +optional
+	Comment string `json:"comment,omitempty"`
+}
+
 // PrometheusReloaderSpec is the desired state of prometheus configuration reloader
 type PrometheusReloaderSpec struct {
 	MonitorContainer `json:",inline"`
@@ -229,6 +272,26 @@ type PrometheusSpec struct {
 
 	// Additional volume mounts of prometheus pod.
 	AdditionalVolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
+
+	// ScrapeConfigs overrides the scrape interval and timeout of the components, the keys are the
+	// names of the scrape jobs: pd, tidb, tikv, tiflash, tiflash-proxy, pump, drainer, ticdc, importer,
+	// lightning, dm-worker and dm-master.
+	// +optional
+	ScrapeConfigs map[string]ScrapeConfig `json:"scrapeConfigs,omitempty"`
+}
+
+// ScrapeConfig is the scrape interval and timeout of a component
+// +k8s:openapi-gen=true
+type ScrapeConfig struct {
+	// Interval is how frequently the targets of the component are scraped, e.g. 30s.
+	// Optional: Defaults to 15s
+	// +optional
+	Interval *string `json:"interval,omitempty"`
+
+	// Timeout is the timeout of scraping a target of the component, which must be less than the interval.
+	// Optional: Defaults to 10s, or the interval if it's less than 10s
+	// +optional
+	Timeout *string `json:"timeout,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -339,6 +402,23 @@ type ThanosSpec struct {
 	RoutePrefix string `json:"routePrefix,omitempty"`
 	// Additional volume mounts of thanos pod.
 	AdditionalVolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
+	// Query deploys a Thanos Query that queries all the replicas and shards of the Prometheus through their
+	// Thanos sidecars and deduplicates the series by the replica external label, so that the monitoring data
+	// is still available when a replica is down. The image is the same as the Thanos sidecar.
+	// +optional
+	Query *ThanosQuerySpec `json:"query,omitempty"`
+}
+
+// ThanosQuerySpec is the desired state of the Thanos Query of the monitor
+type ThanosQuerySpec struct {
+	corev1.ResourceRequirements `json:",inline"`
+	// Replicas is the number of the Thanos Query Pods
+	// Optional: Defaults to 1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Service is the service of the Thanos Query, which serves the Prometheus HTTP API on port 10902
+	// +optional
+	Service ServiceSpec `json:"service,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -377,6 +457,32 @@ type TidbMonitorStatus struct {
 	DeploymentStorageStatus *DeploymentStorageStatus `json:"deploymentStorageStatus,omitempty"`
 
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
+
+	// SilenceWindows is the status of the silence windows
+	// +optional
+	SilenceWindows []SilenceWindowStatus `json:"silenceWindows,omitempty"`
+
+	// DashboardVersion is the version of the initializer image selected by the version of the monitored
+	// cluster if `dashboardVersionFromCluster` is enabled
+	// +optional
+	DashboardVersion string `json:"dashboardVersion,omitempty"`
+}
+
+// SilenceWindowStatus is the status of a silence window
+type SilenceWindowStatus struct {
+	Name string `json:"name"`
+	// SilenceID is the ID of the silence created for the current or next window
+	// +optional
+	SilenceID string `json:"silenceID,omitempty"`
+	// StartsAt is the start time of the silence
+	// +optional
+	StartsAt *metav1.Time `json:"startsAt,omitempty"`
+	// EndsAt is the end time of the silence
+	// +optional
+	EndsAt *metav1.Time `json:"endsAt,omitempty"`
+	// Message describes why the silence can't be created
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -35,5 +35,11 @@ func (tngm *TidbNGMonitoring) NGMonitoringImage() string {
 			image = fmt.Sprintf("%s:%s", baseImage, *version)
 		}
 	}
-	return image
+	return ImageWithRegistryPrefix(tngm.Spec.ClusterRegistryPrefix, image)
+}
+
+// IsTopSQLEnabled returns whether the Top SQL data of the target cluster is collected
+func (tngm *TidbNGMonitoring) IsTopSQLEnabled() bool {
+	topSQL := tngm.Spec.NGMonitoring.TopSQL
+	return topSQL == nil || topSQL.Enable == nil || *topSQL.Enable
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -80,6 +81,12 @@ type TidbNGMonitoringSpec struct {
 	// ClusterDomain is the Kubernetes Cluster Domain of tidb ng monitoring
 	ClusterDomain string `json:"clusterDomain,omitempty"`
 
+	// ClusterRegistryPrefix rewrites the registry of all the images used by tidb ng monitoring.
+	// E.g. with `registry.local/mirror`, `pingcap/ng-monitoring:v5.4.0` is pulled from
+	// `registry.local/mirror/pingcap/ng-monitoring:v5.4.0`, which is useful for air-gapped environments
+	// +optional
+	ClusterRegistryPrefix string `json:"clusterRegistryPrefix,omitempty"`
+
 	// NGMonitoring is spec of ng monitoring
 	NGMonitoring NGMonitoringSpec `json:"ngMonitoring"`
 }
@@ -114,6 +121,31 @@ type NGMonitoringSpec struct {
 	// +kubebuilder:validation:Schemaless
 	// +kubebuilder:validation:XPreserveUnknownFields
 	Config *config.GenericConfig `json:"config,omitempty"`
+
+	// TopSQL configures the Top SQL data collected from the target cluster.
+	// The settings override the same items in Config.
+	// +optional
+	TopSQL *TopSQLSpec `json:"topSQL,omitempty"`
+}
+
+// TopSQLSpec configures the Top SQL data collected by ng monitoring
+//
+// +k8s:openapi-gen=true
+type TopSQLSpec struct {
+	// Enable enables the collection of the Top SQL data of the target cluster.
+	// Optional: Defaults to true
+	// +optional
+	Enable *bool `json:"enable,omitempty"`
+
+	// RetentionPeriod is how long the Top SQL data is kept, e.g. 72h.
+	// Defaults to the retention period of ng monitoring if it's not set.
+	// +optional
+	RetentionPeriod *metav1.Duration `json:"retentionPeriod,omitempty"`
+
+	// StorageSizeLimit is the storage budget of the Top SQL data, the oldest data is
+	// removed when the budget is exceeded. No limit is set if it's not set.
+	// +optional
+	StorageSizeLimit *resource.Quantity `json:"storageSizeLimit,omitempty"`
 }
 
 // NGMonitoringStatus is latest status of ng monitoring
@@ -122,4 +154,20 @@ type NGMonitoringStatus struct {
 	Phase  MemberPhase `json:"phase,omitempty"`
 
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
+
+	// TopSQL is the status of the Top SQL data
+	// +optional
+	TopSQL *TopSQLStatus `json:"topSQL,omitempty"`
+}
+
+// TopSQLStatus is the status of the Top SQL data collected by ng monitoring
+type TopSQLStatus struct {
+	// Enabled indicates whether the Top SQL data of the target cluster is collected
+	Enabled bool `json:"enabled"`
+	// StorageUsage is the storage used by the data of ng monitoring
+	// +optional
+	StorageUsage *resource.Quantity `json:"storageUsage,omitempty"`
+	// LastUpdateTime is the last time the storage usage is updated
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
 }
@@ -0,0 +1,64 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	stdjson "encoding/json"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+var _ stdjson.Marshaler = &TiProxyConfigWraper{}
+var _ stdjson.Unmarshaler = &TiProxyConfigWraper{}
+
+// NewTiProxyConfig returns an empty config structure
+func NewTiProxyConfig() *TiProxyConfigWraper {
+	return &TiProxyConfigWraper{
+		GenericConfig: config.New(map[string]interface{}{}),
+	}
+}
+
+// TiProxyConfigWraper simply wrapps a GenericConfig
+type TiProxyConfigWraper struct {
+	*config.GenericConfig `json:",inline"`
+}
+
+// MarshalJSON implements stdjson.Marshaler interface.
+func (c *TiProxyConfigWraper) MarshalJSON() ([]byte, error) {
+	toml, err := c.GenericConfig.MarshalTOML()
+	if err != nil {
+		return nil, errors.AddStack(err)
+	}
+
+	return json.Marshal(string(toml))
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler interface.
+// TiProxy has no deprecated config structure, so both the TOML string and the object
+// are unmarshaled into a generic map.
+func (c *TiProxyConfigWraper) UnmarshalJSON(data []byte) error {
+	var err error
+	c.GenericConfig, err = unmarshalJSON(data, &map[string]interface{}{})
+	return err
+}
+
+func (c *TiProxyConfigWraper) MarshalTOML() ([]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	return c.GenericConfig.MarshalTOML()
+}
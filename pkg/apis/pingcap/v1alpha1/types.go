@@ -17,8 +17,10 @@ import (
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
 )
@@ -56,10 +58,14 @@ const (
 	TiDBMemberType MemberType = "tidb"
 	// TiKVMemberType is tikv container type
 	TiKVMemberType MemberType = "tikv"
+	// TiKVColdMemberType is the member type of the TiKV cold group
+	TiKVColdMemberType MemberType = "tikv-cold"
 	// TiFlashMemberType is tiflash container type
 	TiFlashMemberType MemberType = "tiflash"
 	// TiCDCMemberType is ticdc container type
 	TiCDCMemberType MemberType = "ticdc"
+	// TiProxyMemberType is tiproxy container type
+	TiProxyMemberType MemberType = "tiproxy"
 	// PumpMemberType is pump container type
 	PumpMemberType MemberType = "pump"
 	// DMMasterMemberType is dm-master container type
@@ -90,6 +96,33 @@ const (
 	UpgradePhase MemberPhase = "Upgrade"
 	// ScalePhase represents the scaling state of TiDB cluster.
 	ScalePhase MemberPhase = "Scale"
+	// SuspendPhase represents the Pods of the component are scaled down to zero as the cluster is suspended.
+	SuspendPhase MemberPhase = "Suspend"
+)
+
+// Architecture is the CPU architecture of the nodes, i.e. the value of the
+// `kubernetes.io/arch` node label
+// +kubebuilder:validation:Enum=amd64;arm64
+type Architecture string
+
+const (
+	// ArchitectureAMD64 represents the amd64 (x86_64) architecture
+	ArchitectureAMD64 Architecture = "amd64"
+	// ArchitectureARM64 represents the arm64 (aarch64) architecture
+	ArchitectureARM64 Architecture = "arm64"
+)
+
+// StartScriptVersion is the version of the start scripts of the components
+// +kubebuilder:validation:Enum=v1;v2
+type StartScriptVersion string
+
+const (
+	// StartScriptV1 is the start scripts used since the beginning
+	StartScriptV1 StartScriptVersion = "v1"
+	// StartScriptV2 is the templated start scripts which share the common parts between components,
+	// and wait for the DNS record of the Pod to be resolvable instead of exiting after a fixed timeout.
+	// Only PD, TiKV, TiDB, DM-master and DM-worker have v2 start scripts.
+	StartScriptV2 StartScriptVersion = "v2"
 )
 
 // ConfigUpdateStrategy represents the strategy to update configuration
@@ -110,21 +143,32 @@ const (
 //
 // +k8s:openapi-gen=true
 // +kubebuilder:resource:shortName="tc"
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`,description="The version of the tidb cluster"
 // +kubebuilder:printcolumn:name="PD",type=string,JSONPath=`.status.pd.image`,description="The image for PD cluster"
 // +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.pd.requests.storage`,description="The storage size specified for PD node"
-// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.pd.statefulSet.readyReplicas`,description="The desired replicas number of PD cluster"
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.pd.statefulSet.readyReplicas`,description="The ready replicas number of PD cluster"
 // +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.pd.replicas`,description="The desired replicas number of PD cluster"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.pd.phase`,description="The phase of PD cluster",priority=1
 // +kubebuilder:printcolumn:name="TiKV",type=string,JSONPath=`.status.tikv.image`,description="The image for TiKV cluster"
 // +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.tikv.requests.storage`,description="The storage size specified for TiKV node"
 // +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.tikv.statefulSet.readyReplicas`,description="The ready replicas number of TiKV cluster"
 // +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.tikv.replicas`,description="The desired replicas number of TiKV cluster"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.tikv.phase`,description="The phase of TiKV cluster",priority=1
 // +kubebuilder:printcolumn:name="TiDB",type=string,JSONPath=`.status.tidb.image`,description="The image for TiDB cluster"
 // +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.tidb.statefulSet.readyReplicas`,description="The ready replicas number of TiDB cluster"
 // +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.tidb.replicas`,description="The desired replicas number of TiDB cluster"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.tidb.phase`,description="The phase of TiDB cluster",priority=1
+// +kubebuilder:printcolumn:name="TiFlash",type=string,JSONPath=`.status.tiflash.image`,description="The image for TiFlash cluster",priority=1
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.tiflash.statefulSet.readyReplicas`,description="The ready replicas number of TiFlash cluster",priority=1
+// +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.tiflash.replicas`,description="The desired replicas number of TiFlash cluster",priority=1
+// +kubebuilder:printcolumn:name="TiCDC-Ready",type=integer,JSONPath=`.status.ticdc.statefulSet.readyReplicas`,description="The ready replicas number of TiCDC cluster",priority=1
+// +kubebuilder:printcolumn:name="TiCDC-Desire",type=integer,JSONPath=`.spec.ticdc.replicas`,description="The desired replicas number of TiCDC cluster",priority=1
+// +kubebuilder:printcolumn:name="Pump-Ready",type=integer,JSONPath=`.status.pump.statefulSet.readyReplicas`,description="The ready replicas number of Pump cluster",priority=1
+// +kubebuilder:printcolumn:name="Pump-Desire",type=integer,JSONPath=`.spec.pump.replicas`,description="The desired replicas number of Pump cluster",priority=1
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
-// +genclient:noStatus
 type TidbCluster struct {
 	metav1.TypeMeta `json:",inline"`
 	// +k8s:openapi-gen=false
@@ -179,6 +223,10 @@ type TidbClusterSpec struct {
 	// +optional
 	TiCDC *TiCDCSpec `json:"ticdc,omitempty"`
 
+	// TiProxy cluster spec
+	// +optional
+	TiProxy *TiProxySpec `json:"tiproxy,omitempty"`
+
 	// Pump cluster spec
 	// +optional
 	Pump *PumpSpec `json:"pump,omitempty"`
@@ -192,6 +240,11 @@ type TidbClusterSpec struct {
 	// +optional
 	Paused bool `json:"paused,omitempty"`
 
+	// AutoResume resumes the paused cluster automatically, so that it doesn't drift from the spec for long
+	// once forgotten. It's cleared together with paused when the cluster is resumed automatically.
+	// +optional
+	AutoResume *AutoResumeSpec `json:"autoResume,omitempty"`
+
 	// TiDB cluster version
 	// +optional
 	Version string `json:"version"`
@@ -213,6 +266,13 @@ type TidbClusterSpec struct {
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
+	// ClusterRegistryPrefix rewrites the registry of all the images used by the tidb cluster,
+	// including the component, helper and discovery images. E.g. with `registry.local/mirror`,
+	// `pingcap/tikv:v5.4.0` is pulled from `registry.local/mirror/pingcap/tikv:v5.4.0`,
+	// which is useful for air-gapped environments
+	// +optional
+	ClusterRegistryPrefix string `json:"clusterRegistryPrefix,omitempty"`
+
 	// ConfigUpdateStrategy determines how the configuration change is applied to the cluster.
 	// UpdateStrategyInPlace will update the ConfigMap of configuration in-place and an extra rolling-update of the
 	// cluster component is needed to reload the configuration change.
@@ -222,6 +282,15 @@ type TidbClusterSpec struct {
 	// +kubebuilder:default=InPlace
 	ConfigUpdateStrategy ConfigUpdateStrategy `json:"configUpdateStrategy,omitempty"`
 
+	// ConfigRevisionHistoryLimit is the number of the old ConfigMaps of the configuration retained for each
+	// component when ConfigUpdateStrategy is RollingUpdate, the configuration of a component can be rolled back
+	// to a retained ConfigMap by setting its name to the annotation tidb.pingcap.com/config-rollback.
+	// The ConfigMaps in use and the newest one are always retained.
+	// Optional: Defaults to nil, which retains all the old ConfigMaps
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ConfigRevisionHistoryLimit *int32 `json:"configRevisionHistoryLimit,omitempty"`
+
 	// Whether enable PVC reclaim for orphan PVC left by statefulset scale-in
 	// Optional: Defaults to false
 	// +optional
@@ -232,6 +301,15 @@ type TidbClusterSpec struct {
 	// +optional
 	TLSCluster *TLSCluster `json:"tlsCluster,omitempty"`
 
+	// EncryptionKeySecret is the name of the Secret containing the master key of the encryption at rest of TiKV
+	// in the key `master-key`, which is a 256-bit key encoded in hex. The master key is rotated by setting it to a
+	// new Secret, the previous Secret must be kept until the next rotation, as TiKV uses it to re-encrypt the data keys.
+	// The encryption can't be disabled by removing the Secret, set `security.encryption.data-encryption-method`
+	// of TiKV to `plaintext` instead.
+	// Optional: Defaults to ""
+	// +optional
+	EncryptionKeySecret string `json:"encryptionKeySecret,omitempty"`
+
 	// Whether Hostnetwork is enabled for TiDB cluster Pods
 	// Optional: Defaults to false
 	// +optional
@@ -261,6 +339,12 @@ type TidbClusterSpec struct {
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
 
+	// CloudTags are the tags of the cloud volumes of the cluster. They are set as the annotations of the PVCs
+	// of all components, including the ones created by failover, so the keys should be the annotations
+	// understood by the CSI driver of the storage class, e.g. the ones tagging EBS volumes or labeling PD disks.
+	// +optional
+	CloudTags map[string]string `json:"cloudTags,omitempty"`
+
 	// Base tolerations of TiDB cluster Pods, components may add more tolerations upon this respectively
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
@@ -270,6 +354,12 @@ type TidbClusterSpec struct {
 	// +optional
 	Timezone string `json:"timezone,omitempty"`
 
+	// MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the
+	// TiDB cluster containers read-only, which is required if the images don't contain the database
+	// Optional: Defaults to false
+	// +optional
+	MountTimezoneData *bool `json:"mountTimezoneData,omitempty"`
+
 	// (Deprecated) Services list non-headless services type used in TidbCluster
 	// +k8s:openapi-gen=false
 	Services []Service `json:"services,omitempty"`
@@ -303,16 +393,883 @@ type TidbClusterSpec struct {
 
 	// PodSecurityContext of the component
 	// +optional
-	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// TopologySpreadConstraints describes how a group of pods ought to spread across topology
+	// domains. Scheduler will schedule pods in a way which abides by the constraints.
+	// This field is is only honored by clusters that enables the EvenPodsSpread feature.
+	// All topologySpreadConstraints are ANDed.
+	// +optional
+	// +listType=map
+	// +listMapKey=topologyKey
+	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Architecture is the CPU architecture of the nodes that the Pods are scheduled to,
+	// a required node affinity on the `kubernetes.io/arch` label is generated for it.
+	// Components may override it, so that e.g. TiKV runs on arm64 nodes while TiFlash
+	// keeps running on amd64 nodes in a mixed node pool.
+	// Optional: Defaults to no architecture constraint
+	// +optional
+	Architecture Architecture `json:"architecture,omitempty"`
+
+	// StartScriptVersion is the version of the start scripts, changing it causes a rolling update.
+	// Components may override it to adopt the new start scripts gradually.
+	// Optional: Defaults to v1
+	// +optional
+	StartScriptVersion StartScriptVersion `json:"startScriptVersion,omitempty"`
+
+	// ConfigDrift enables the periodic detection of drift between the desired config of TiKV/TiDB
+	// and the config that the instances are actually running with, e.g. changed by `SET CONFIG`
+	// Optional: Defaults to nil, which means the detection is disabled
+	// +optional
+	ConfigDrift *ConfigDriftSpec `json:"configDrift,omitempty"`
+
+	// ClockSkew enables the periodic detection of the clock skew of the PD members and the nodes of the
+	// cluster, which breaks the TSO allocation and the leader leases in subtle ways
+	// Optional: Defaults to nil, which means the detection is disabled
+	// +optional
+	ClockSkew *ClockSkewSpec `json:"clockSkew,omitempty"`
+
+	// VersionSkew enables the detection of the version skew between the PD, TiKV and TiDB instances, e.g. left
+	// by a partially applied upgrade or the images edited by hand, and suggests a plan to repair it, which is
+	// executed by the operator once approved
+	// Optional: Defaults to nil, which means the detection is disabled
+	// +optional
+	VersionSkew *VersionSkewSpec `json:"versionSkew,omitempty"`
+
+	// StorageProvisioning runs the hook pre-provisioning the storage of the components before their
+	// StatefulSets are created
+	// +optional
+	StorageProvisioning *StorageProvisioningSpec `json:"storageProvisioning,omitempty"`
+
+	// TombstoneStoreGC enables the periodic removal of the tombstone stores of TiKV and TiFlash from PD once
+	// the PVCs of their Pods are deleted, which keeps the store list of PD clean after many scale-in and
+	// scale-out cycles
+	// Optional: Defaults to nil, which means the tombstone stores are kept
+	// +optional
+	TombstoneStoreGC *TombstoneStoreGCSpec `json:"tombstoneStoreGC,omitempty"`
+
+	// Notifications configures the sinks to send the notifications of the significant transitions
+	// of the cluster to, e.g. upgrade started or finished and failover triggered
+	// +optional
+	Notifications *NotificationSpec `json:"notifications,omitempty"`
+
+	// Maintenance configures the periodic maintenance tasks of the cluster, e.g. TiKV compaction
+	// +optional
+	Maintenance *MaintenanceSpec `json:"maintenance,omitempty"`
+
+	// SpotTermination configures the handling of the termination notices of spot or preemptible nodes
+	// +optional
+	SpotTermination *SpotTerminationSpec `json:"spotTermination,omitempty"`
+
+	// PublishReadiness maintains a ConfigMap named `${clusterName}-readiness` that mirrors the Ready
+	// condition of the cluster. The `ready` key only exists while the cluster is ready, so the Pods of the
+	// workloads depending on the cluster can wait for it declaratively by referencing the key in a
+	// ConfigMapKeySelector.
+	// Optional: Defaults to false
+	// +optional
+	PublishReadiness bool `json:"publishReadiness,omitempty"`
+
+	// Diagnostics configures the collection of the diagnostics bundles for the root cause analysis
+	// when the cluster runs into a failure, e.g. a failover is triggered or an upgrade gets stuck
+	// +optional
+	Diagnostics *DiagnosticsSpec `json:"diagnostics,omitempty"`
+
+	// ReadOnly puts the whole cluster into the read-only mode, e.g. as a break-glass during incident
+	// response or migrations
+	// +optional
+	ReadOnly *ReadOnlySpec `json:"readOnly,omitempty"`
+
+	// FeatureGates enables or disables the experimental behaviors of the operator for this cluster,
+	// the gates set here take precedence over the operator-wide settings, so that a feature can be
+	// canaried on a single cluster. Unknown gates are ignored.
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	// Profile selects the preset config of TiKV, TiDB and TiFlash tuned for the workload, e.g. the thread pools
+	// of TiKV and the memory quotas of TiDB. The presets are merged under the config of the components, i.e.
+	// the items set in the config take precedence, and TiKV and TiDB without config are not affected.
+	// The effective config is exported to a ConfigMap named `${clusterName}-effective-config` for inspection.
+	// Changing the profile causes a rolling update of the affected components.
+	// +kubebuilder:validation:Enum=oltp;olap;htap
+	// +optional
+	Profile ConfigProfile `json:"profile,omitempty"`
+}
+
+// ReadOnlySpec configures the read-only mode of the cluster. The operator sets the global `tidb_super_read_only`
+// system variable of TiDB, so that all the TiDB instances reject the writes of all the users, and turns it off
+// once the mode is disabled. Set `enabled` to false instead of removing readOnly to turn off the mode, as the
+// credentials are required to change the system variable.
+// +k8s:openapi-gen=true
+type ReadOnlySpec struct {
+	// Enabled enables the read-only mode
+	Enabled bool `json:"enabled"`
+
+	// User is the user to connect to TiDB, it must have the SUPER or SYSTEM_VARIABLES_ADMIN privilege
+	// Optional: Defaults to root
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// SecretName is the name of the Secret that contains the password of the user in the key password
+	SecretName string `json:"secretName"`
+
+	// HaltScheduling halts the scheduling of PD too while the cluster is read-only, so no region is moved
+	// Optional: Defaults to false
+	// +optional
+	HaltScheduling bool `json:"haltScheduling,omitempty"`
+}
+
+// DiagnosticsSpec configures the collection of the diagnostics bundles. A bundle contains the recent events,
+// the recent logs of the containers, the health of PD, the states of the stores and the status of the cluster.
+// It's collected when a trigger fires or the annotation `tidb.pingcap.com/collect-diagnostics` of the cluster
+// is set to a new value, and then uploaded to the storage by a Job.
+// +k8s:openapi-gen=true
+type DiagnosticsSpec struct {
+	// Triggers are the failure conditions on which a bundle is collected, Failover and UpgradeStuck are supported
+	// Optional: Defaults to all the triggers
+	// +optional
+	Triggers []DiagnosticsTrigger `json:"triggers,omitempty"`
+
+	// UpgradeStuckTimeout is how long the upgrade of a Pod lasts before it's regarded as stuck,
+	// in the format of Go Duration.
+	// Defaults to 30m
+	// +optional
+	UpgradeStuckTimeout *string `json:"upgradeStuckTimeout,omitempty"`
+
+	// MinInterval is the minimum interval between two bundles collected by the triggers,
+	// in the format of Go Duration.
+	// Defaults to 1h
+	// +optional
+	MinInterval *string `json:"minInterval,omitempty"`
+
+	// LogTailLines is the number of the most recent lines of the logs collected for each container
+	// Defaults to 500
+	// +optional
+	LogTailLines *int64 `json:"logTailLines,omitempty"`
+
+	// Image of the Job uploading the bundle, rclone is required to upload to S3 or GCS
+	// Optional: Defaults to the image of tidb-backup-manager of the operator
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Resources of the Job uploading the bundle
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// StorageProvider configures where the bundles are stored, a bundle is stored as
+	// `${prefix}/${clusterName}-diagnostics-${timestamp}.tar.gz`
+	StorageProvider `json:",inline"`
+}
+
+// DiagnosticsTrigger is a failure condition on which a diagnostics bundle is collected
+// +k8s:openapi-gen=true
+type DiagnosticsTrigger string
+
+const (
+	// DiagnosticsTriggerFailover fires when a failover of PD, TiKV, TiDB or TiFlash is triggered
+	DiagnosticsTriggerFailover DiagnosticsTrigger = "Failover"
+	// DiagnosticsTriggerUpgradeStuck fires when the upgrade of a TiKV Pod, including the eviction of the region
+	// leaders, lasts longer than the upgradeStuckTimeout
+	DiagnosticsTriggerUpgradeStuck DiagnosticsTrigger = "UpgradeStuck"
+)
+
+// SpotTerminationSpec configures the handling of the nodes that are going to be terminated, e.g. the spot
+// instances to be reclaimed, which are tainted by the node termination handlers. The region leaders of the
+// TiKV stores on these nodes are evicted as soon as the taint is observed.
+// +k8s:openapi-gen=true
+type SpotTerminationSpec struct {
+	// Taints are the keys of the taints that mark a node as going to be terminated
+	// Optional: Defaults to aws-node-termination-handler/spot-itn, aws-node-termination-handler/rebalance-recommendation
+	// and cloud.google.com/impending-node-termination
+	// +optional
+	Taints []string `json:"taints,omitempty"`
+
+	// PreCreateReplacement records the TiKV stores and the TiDB members on the nodes going to be terminated
+	// as failures, so that the replacement Pods are created before the nodes are terminated.
+	// It takes effect only if the failover is enabled and the maxFailoverCount of the component is not reached.
+	// +optional
+	PreCreateReplacement bool `json:"preCreateReplacement,omitempty"`
+}
+
+// ConfigDriftPolicy is the action taken when the live config drifts from the desired config
+// +k8s:openapi-gen=true
+type ConfigDriftPolicy string
+
+const (
+	// ConfigDriftPolicyReport only reports the drift by the ConfigDrifted condition and events
+	ConfigDriftPolicyReport ConfigDriftPolicy = "Report"
+	// ConfigDriftPolicyRevert reports the drift and reverts the drifted items to the desired values
+	// online, which is only supported by TiKV
+	ConfigDriftPolicyRevert ConfigDriftPolicy = "Revert"
+)
+
+// ConfigDriftSpec describes how to detect and handle config drift
+// +k8s:openapi-gen=true
+type ConfigDriftSpec struct {
+	// Policy is the action taken when drift is detected
+	// Optional: Defaults to Report
+	// +kubebuilder:validation:Enum=Report;Revert
+	// +optional
+	Policy ConfigDriftPolicy `json:"policy,omitempty"`
+
+	// Interval is the minimum interval between two checks
+	// Optional: Defaults to 5m
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ConfigProfile is the name of the preset config tuned for a kind of workload
+// +k8s:openapi-gen=true
+type ConfigProfile string
+
+const (
+	// ConfigProfileOLTP tunes for the transactional workloads with a large number of small queries
+	ConfigProfileOLTP ConfigProfile = "oltp"
+	// ConfigProfileOLAP tunes for the analytical workloads with large queries scanning lots of data
+	ConfigProfileOLAP ConfigProfile = "olap"
+	// ConfigProfileHTAP balances between the transactional and the analytical workloads
+	ConfigProfileHTAP ConfigProfile = "htap"
+)
+
+// ConfigDriftStatus is the result of the last config drift check
+type ConfigDriftStatus struct {
+	// LastCheckTime is the time of the last check
+	// +nullable
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+	// Drifts is the drifted instances found by the last check
+	// +optional
+	Drifts []ConfigDrift `json:"drifts,omitempty"`
+}
+
+// ConfigDrift is the config items of an instance whose live value differs from the desired value
+type ConfigDrift struct {
+	Component MemberType `json:"component"`
+	// Instance is the name of the Pod
+	Instance string `json:"instance"`
+	// Keys is the drifted config items, in the form of dotted paths, e.g. `storage.block-cache.capacity`
+	Keys []string `json:"keys"`
+}
+
+// ClockSkewPolicy is the action taken when the clock of a node is badly skewed
+// +k8s:openapi-gen=true
+type ClockSkewPolicy string
+
+const (
+	// ClockSkewPolicyReport only reports the skew by the ClockSkewDetected condition and events
+	ClockSkewPolicyReport ClockSkewPolicy = "Report"
+	// ClockSkewPolicyCordon reports the skew and cordons the nodes whose skew exceeds the cordonThreshold,
+	// so that no more Pods are scheduled onto them. The Pods running on them are not evicted as they
+	// might be bound to the local volumes, and the nodes are never uncordoned by the operator.
+	ClockSkewPolicyCordon ClockSkewPolicy = "Cordon"
+)
+
+// AutoResumeSpec describes when to resume a paused cluster, only one of until and after can be set.
+// +k8s:openapi-gen=true
+type AutoResumeSpec struct {
+	// Until is the time after which the cluster is resumed
+	// +optional
+	Until *metav1.Time `json:"until,omitempty"`
+
+	// After is how long the cluster stays paused since the operator observes it paused
+	// +optional
+	After *metav1.Duration `json:"after,omitempty"`
+}
+
+// ClockSkewSpec describes how to detect and handle clock skew. The clock of a PD member is measured by the
+// Date header of its API responses, and the skew is its offset from the median clock of the operator and
+// all the PD members, so the precision is about 1s. The nodes of the cluster with any of the nodeConditions
+// are reported as skewed too.
+// +k8s:openapi-gen=true
+type ClockSkewSpec struct {
+	// Policy is the action taken when a badly skewed node is detected
+	// Optional: Defaults to Report
+	// +kubebuilder:validation:Enum=Report;Cordon
+	// +optional
+	Policy ClockSkewPolicy `json:"policy,omitempty"`
+
+	// MaxSkew is the maximum skew of a PD member that is tolerated
+	// Optional: Defaults to 2s
+	// +optional
+	MaxSkew *metav1.Duration `json:"maxSkew,omitempty"`
+
+	// CordonThreshold is the skew of a PD member beyond which its node is cordoned if the policy is Cordon
+	// Optional: Defaults to 10s
+	// +optional
+	CordonThreshold *metav1.Duration `json:"cordonThreshold,omitempty"`
+
+	// NodeConditions are the types of the node conditions that report the clock of the node is not
+	// synchronized, e.g. set by the node-problem-detector
+	// Optional: Defaults to NTPProblem
+	// +optional
+	NodeConditions []corev1.NodeConditionType `json:"nodeConditions,omitempty"`
+
+	// Interval is the minimum interval between two checks
+	// Optional: Defaults to 5m
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// VersionSkewSpec describes how to detect the version skew. The version of an instance is the tag of the image of
+// its Pod, and only the major and minor versions are compared. The instances are skewed if they run different
+// major versions, their minor versions differ by more than maxMinorSkew, or a component runs a newer version than
+// the component upgraded before it, i.e. TiKV is newer than PD or TiDB is newer than TiKV.
+// The repair plan is executed once the annotation `tidb.pingcap.com/repair-version-skew` of the cluster is set to
+// the ID of the plan in status.
+// +k8s:openapi-gen=true
+type VersionSkewSpec struct {
+	// MaxMinorSkew is the maximum difference of the minor versions of the instances that is tolerated
+	// Optional: Defaults to 0
+	// +optional
+	MaxMinorSkew int32 `json:"maxMinorSkew,omitempty"`
+
+	// GracePeriod is how long a skew is tolerated before it's reported, so that the skew during a rolling
+	// upgrade is not reported
+	// Optional: Defaults to 30m
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// TombstoneStoreGCSpec describes how to remove the tombstone stores from PD. As PD removes all the tombstone
+// stores at once, the stores are only removed when all of them belong to the cluster and the PVCs of their
+// Pods are deleted.
+// +k8s:openapi-gen=true
+type TombstoneStoreGCSpec struct {
+	// Interval is the minimum interval between two removals
+	// Optional: Defaults to 1h
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// DryRun only reports the tombstone stores to be removed by events without removing them
+	// Optional: Defaults to false
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// TombstoneStoreGCStatus is the result of the last removal of the tombstone stores
+type TombstoneStoreGCStatus struct {
+	// LastGCTime is the time of the last removal
+	// +nullable
+	LastGCTime metav1.Time `json:"lastGCTime,omitempty"`
+	// Stores is the IDs of the tombstone stores removed by the last removal, or to be removed in the dry run
+	// +optional
+	Stores []string `json:"stores,omitempty"`
+	// Message describes why the tombstone stores were not removed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VolumeRepairRecord is the audit record of the repair of the volumes of a Pod whose PVs are bound to a lost node
+type VolumeRepairRecord struct {
+	// PodName is the name of the Pod that is unschedulable because of the lost node
+	PodName string `json:"podName"`
+	// NodeName is the name of the lost node the PVs are bound to
+	NodeName string `json:"nodeName"`
+	// PVCs is the names of the deleted PVCs
+	// +optional
+	PVCs []string `json:"pvcs,omitempty"`
+	// PVs is the names of the deleted PVs
+	// +optional
+	PVs []string `json:"pvs,omitempty"`
+	// RepairTime is the time the volumes are deleted
+	// +nullable
+	RepairTime metav1.Time `json:"repairTime,omitempty"`
+}
+
+// StorageProvisioningSpec describes the hook pre-provisioning the storage of a component, e.g. creating the LVM
+// volumes or reserving the local disks, before the StatefulSet of the component is created. Exactly one of webhook
+// and job must be set. The StatefulSet is created once the hook signals the storage is ready, and is not created
+// if the hook fails more than maxRetries times or times out. To retry a failed hook, remove the component from
+// the components and add it back.
+// +k8s:openapi-gen=true
+type StorageProvisioningSpec struct {
+	// Components are the components the hook is run for, among pd, tikv and tiflash
+	// Optional: Defaults to pd, tikv and tiflash
+	// +optional
+	Components []MemberType `json:"components,omitempty"`
+	// Webhook is called until it responds that the storage is ready
+	// +optional
+	Webhook *StorageProvisioningWebhook `json:"webhook,omitempty"`
+	// Job is run for each component, the storage is ready once the Job completes
+	// +optional
+	Job *StorageProvisioningJob `json:"job,omitempty"`
+	// Timeout of the hook of a component, counted from the first attempt
+	// Optional: Defaults to 30m
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// MaxRetries is the maximum number of the retries of the failed attempts, i.e. the failed calls of the webhook
+	// or the failed Jobs
+	// Optional: Defaults to 3
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+}
+
+// StorageProvisioningWebhook is the webhook provisioning the storage. It's called by a POST request with a JSON
+// body of the fields namespace, cluster, component, replicas and storageClassName, and must respond a JSON body of
+// the field ready, which is false while the storage is being provisioned, and optionally the field message.
+// +k8s:openapi-gen=true
+type StorageProvisioningWebhook struct {
+	// URL of the webhook
+	URL string `json:"url"`
+}
+
+// StorageProvisioningJob is the Job provisioning the storage. The env NAMESPACE, CLUSTER_NAME, COMPONENT,
+// REPLICAS and STORAGE_CLASS_NAME are set for the container.
+// +k8s:openapi-gen=true
+type StorageProvisioningJob struct {
+	// Image of the Job
+	Image string `json:"image"`
+	// Command of the container, the entrypoint of the image is used if empty
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// ServiceAccountName of the Job, e.g. the one allowed to manage the volumes of the nodes
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// StorageProvisioningPhase is the phase of the storage provisioning hook of a component
+type StorageProvisioningPhase string
+
+const (
+	// StorageProvisioningRunning means the hook is running
+	StorageProvisioningRunning StorageProvisioningPhase = "Running"
+	// StorageProvisioningReady means the storage is ready and the StatefulSet can be created
+	StorageProvisioningReady StorageProvisioningPhase = "Ready"
+	// StorageProvisioningFailed means the hook failed more than the max retries or timed out
+	StorageProvisioningFailed StorageProvisioningPhase = "Failed"
+)
+
+// StorageProvisioningStatus is the status of the storage provisioning hook of a component
+type StorageProvisioningStatus struct {
+	Component MemberType               `json:"component"`
+	Phase     StorageProvisioningPhase `json:"phase"`
+	// StartTime is the time of the first attempt
+	StartTime metav1.Time `json:"startTime"`
+	// Failures is the number of the failed attempts
+	// +optional
+	Failures int32 `json:"failures,omitempty"`
+	// Message is the last message of the hook
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ClockSkewStatus is the result of the last clock skew check
+type ClockSkewStatus struct {
+	// LastCheckTime is the time of the last check
+	// +nullable
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+	// Nodes is the skewed nodes found by the last check
+	// +optional
+	Nodes []ClockSkewNode `json:"nodes,omitempty"`
+}
+
+// ClockSkewNode is a node whose clock is skewed
+type ClockSkewNode struct {
+	// Node is the name of the node
+	Node string `json:"node"`
+	// Instances is the names of the Pods of the cluster on the node
+	// +optional
+	Instances []string `json:"instances,omitempty"`
+	// Skew is the measured skew of the PD members on the node, a positive value means the clock is ahead
+	// +optional
+	Skew *metav1.Duration `json:"skew,omitempty"`
+	// Conditions is the types of the node conditions reporting the clock is not synchronized
+	// +optional
+	Conditions []corev1.NodeConditionType `json:"conditions,omitempty"`
+	// Cordoned is whether the node is cordoned as its skew exceeds the cordonThreshold
+	// +optional
+	Cordoned bool `json:"cordoned,omitempty"`
+}
+
+// VersionSkewStatus is the version skew detected
+type VersionSkewStatus struct {
+	// DetectedTime is the time the skew is detected first
+	// +nullable
+	DetectedTime metav1.Time `json:"detectedTime,omitempty"`
+	// Instances are the instances that don't run the desired version of their components
+	// +optional
+	Instances []VersionSkewInstance `json:"instances,omitempty"`
+	// Plan is the suggested plan to repair the skew, it's only suggested once the skew is reported
+	// +optional
+	Plan *VersionSkewRepairPlan `json:"plan,omitempty"`
+}
+
+// VersionSkewInstance is an instance that doesn't run the desired version of its component
+type VersionSkewInstance struct {
+	// Component is the component of the instance, pd, tikv or tidb
+	Component MemberType `json:"component"`
+	// Name is the name of the Pod of the instance
+	Name string `json:"name"`
+	// Version is the version the instance runs
+	Version string `json:"version"`
+	// DesiredVersion is the version of the component in the spec
+	DesiredVersion string `json:"desiredVersion"`
+}
+
+// VersionSkewRepairAction is how a step of the repair plan brings the instances to the desired version
+type VersionSkewRepairAction string
+
+const (
+	// VersionSkewRepairUpgradeStatefulSet re-applies the StatefulSet of the component, so that the instances that
+	// don't run the desired version are upgraded by the operator gracefully, one by one
+	VersionSkewRepairUpgradeStatefulSet VersionSkewRepairAction = "UpgradeStatefulSet"
+	// VersionSkewRepairRestartPods restarts the Pods whose images differ from the StatefulSet although they run
+	// its revision, e.g. edited by hand, one by one, so that they are recreated from the StatefulSet. The region
+	// leaders of a TiKV store are evicted, and the PD leader is transferred, before the Pod is deleted.
+	VersionSkewRepairRestartPods VersionSkewRepairAction = "RestartPods"
+)
+
+// VersionSkewRepairPlan is the plan to repair the version skew, its steps are executed in order, following the
+// upgrade order of the components, PD, TiKV and then TiDB
+type VersionSkewRepairPlan struct {
+	// ID identifies the steps of the plan, the plan is approved by setting the annotation
+	// `tidb.pingcap.com/repair-version-skew` of the cluster to it
+	ID string `json:"id"`
+	// Approved is whether the plan is approved, the steps of an approved plan are not changed
+	// +optional
+	Approved bool `json:"approved,omitempty"`
+	// Steps are the steps of the plan
+	Steps []VersionSkewRepairStep `json:"steps"`
+}
+
+// VersionSkewRepairStep is a step of the repair plan
+type VersionSkewRepairStep struct {
+	// Component is the component repaired by the step
+	Component MemberType `json:"component"`
+	// Action is how the instances are brought to the version
+	Action VersionSkewRepairAction `json:"action"`
+	// Instances are the names of the Pods repaired by the step
+	Instances []string `json:"instances"`
+	// Version is the version the instances are brought to
+	Version string `json:"version"`
+	// StartTime is the time the step is started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time all the instances of the step run the version and are ready
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// InFlightOperationType is the type of a long running operation on an instance
+type InFlightOperationType string
+
+const (
+	// InFlightOperationUpgrade means the region leaders of the store are being evicted before the Pod is upgraded
+	InFlightOperationUpgrade InFlightOperationType = "Upgrade"
+	// InFlightOperationScaleIn means the store has been deleted and is waiting to become tombstone
+	InFlightOperationScaleIn InFlightOperationType = "ScaleIn"
+	// InFlightOperationSpotTermination means the region leaders of the store are being evicted as its node
+	// is going to be terminated
+	InFlightOperationSpotTermination InFlightOperationType = "SpotTermination"
+	// InFlightOperationRestart means the region leaders of the store are being evicted before the Pod is restarted
+	// to apply its new configuration
+	InFlightOperationRestart InFlightOperationType = "Restart"
+)
+
+// InFlightOperation is a long running operation on an instance that is in progress, it is persisted
+// so that a new leader of tidb-controller-manager can resume it without re-deriving the state
+type InFlightOperation struct {
+	Type      InFlightOperationType `json:"type"`
+	Component MemberType            `json:"component"`
+	// PodName is the name of the Pod the operation is performed on
+	PodName string `json:"podName"`
+	// StoreID is the ID of the store the operation is performed on
+	// +optional
+	StoreID string `json:"storeID,omitempty"`
+	// LeaderCount is the leader count of the store before the operation
+	// +optional
+	LeaderCount int32 `json:"leaderCount,omitempty"`
+	// StartTime is the time the operation started
+	StartTime metav1.Time `json:"startTime"`
+}
+
+// NotificationSeverity is the severity of a notification
+type NotificationSeverity string
+
+const (
+	NotificationSeverityInfo     NotificationSeverity = "Info"
+	NotificationSeverityWarning  NotificationSeverity = "Warning"
+	NotificationSeverityCritical NotificationSeverity = "Critical"
+)
+
+// NotificationEventType is the type of the transition a notification is sent for
+type NotificationEventType string
+
+const (
+	// NotificationEventUpgradeStarted is sent with severity Info when a component starts upgrading
+	NotificationEventUpgradeStarted NotificationEventType = "UpgradeStarted"
+	// NotificationEventUpgradeFinished is sent with severity Info when a component finishes upgrading
+	NotificationEventUpgradeFinished NotificationEventType = "UpgradeFinished"
+	// NotificationEventFailoverTriggered is sent with severity Warning when a failure member or store is recorded
+	NotificationEventFailoverTriggered NotificationEventType = "FailoverTriggered"
+	// NotificationEventPVCResizeBlocked is sent with severity Warning when the PVCs of a component that can't be
+	// resized to the requested size change, as recorded in the VolumeResizeBlocked condition of the component
+	NotificationEventPVCResizeBlocked NotificationEventType = "PVCResizeBlocked"
+	// NotificationEventBackupFailed is sent with severity Critical when a backup fails
+	NotificationEventBackupFailed NotificationEventType = "BackupFailed"
+)
+
+// +k8s:openapi-gen=true
+// NotificationSpec configures the sinks to send the notifications to
+type NotificationSpec struct {
+	Sinks []NotificationSink `json:"sinks"`
+}
+
+// +k8s:openapi-gen=true
+// NotificationSink is a destination of the notifications, exactly one of webhook, slack and pagerDuty must be set
+type NotificationSink struct {
+	// Name of the sink, must be unique in the sinks
+	Name string `json:"name"`
+	// MinSeverity is the minimum severity of the notifications sent to the sink
+	// Optional: Defaults to Info
+	// +kubebuilder:validation:Enum=Info;Warning;Critical
+	// +optional
+	MinSeverity NotificationSeverity `json:"minSeverity,omitempty"`
+	// Events are the types of the notifications sent to the sink
+	// Optional: Defaults to all types
+	// +optional
+	Events []NotificationEventType `json:"events,omitempty"`
+	// Webhook sends the notifications as JSON objects to the URL by POST
+	// +optional
+	Webhook *WebhookNotificationSink `json:"webhook,omitempty"`
+	// Slack sends the notifications to Slack by an incoming webhook
+	// +optional
+	Slack *SlackNotificationSink `json:"slack,omitempty"`
+	// PagerDuty triggers PagerDuty incidents by the Events API v2
+	// +optional
+	PagerDuty *PagerDutyNotificationSink `json:"pagerDuty,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// WebhookNotificationSink is a generic webhook sink
+type WebhookNotificationSink struct {
+	// URL of the webhook
+	URL string `json:"url"`
+}
+
+// +k8s:openapi-gen=true
+// SlackNotificationSink is a Slack incoming webhook sink
+type SlackNotificationSink struct {
+	// WebhookURLSecretRef refers to the key of the Secret that contains the URL of the incoming webhook
+	WebhookURLSecretRef corev1.SecretKeySelector `json:"webhookURLSecretRef"`
+}
+
+// +k8s:openapi-gen=true
+// PagerDutyNotificationSink is a PagerDuty Events API v2 sink
+type PagerDutyNotificationSink struct {
+	// RoutingKeySecretRef refers to the key of the Secret that contains the integration key of the service
+	RoutingKeySecretRef corev1.SecretKeySelector `json:"routingKeySecretRef"`
+	// URL of the Events API
+	// Optional: Defaults to https://events.pagerduty.com/v2/enqueue
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// MaintenanceSpec contains the periodic maintenance tasks of the cluster
+type MaintenanceSpec struct {
+	// Tasks are the periodic maintenance tasks, each task is run as a Job at the time of the schedule.
+	// A task is postponed while the cluster is upgrading, and the running Job is deleted when an upgrade
+	// begins, so that the maintenance never runs concurrently with the rolling restarts.
+	// +optional
+	Tasks []MaintenanceTask `json:"tasks,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// MaintenanceTask is a periodic maintenance task, exactly one of compactTiKV, analyzeTable and resetStoreLimit
+// must be set
+type MaintenanceTask struct {
+	// Name is the unique name of the task in the cluster
+	Name string `json:"name"`
+	// Schedule is the schedule of the task in the Cron format
+	Schedule string `json:"schedule"`
+	// Suspend stops scheduling the task, the running Job is not affected
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// Window is the length of the maintenance window beginning at the scheduled time. A run that can't
+	// start within the window, e.g. as the cluster is upgrading, is skipped, and the Job is stopped at
+	// the end of the window.
+	// Optional: Defaults to unlimited
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+	// CompactTiKV compacts the data of all the TiKV stores by tikv-ctl
+	// +optional
+	CompactTiKV *CompactTiKVTask `json:"compactTiKV,omitempty"`
+	// AnalyzeTable runs ANALYZE TABLE on the tables by the mysql client
+	// +optional
+	AnalyzeTable *AnalyzeTableTask `json:"analyzeTable,omitempty"`
+	// ResetStoreLimit resets the store limit of all the stores by pd-ctl
+	// +optional
+	ResetStoreLimit *ResetStoreLimitTask `json:"resetStoreLimit,omitempty"`
+	// Resources of the Job
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// CompactTiKVTask compacts the data of all the TiKV stores
+type CompactTiKVTask struct {
+	// DB is the db to compact, kv or raft
+	// Optional: Defaults to kv
+	// +kubebuilder:validation:Enum=kv;raft
+	// +optional
+	DB string `json:"db,omitempty"`
+	// ColumnFamilies are the column families to compact
+	// Optional: Defaults to default and write
+	// +optional
+	ColumnFamilies []string `json:"columnFamilies,omitempty"`
+	// Tables limits the compaction to the data of the tables in the format of db.table, including
+	// their partitions. The key ranges of the tables are resolved by TiDB when the Job is created.
+	// It's only supported for the kv db.
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+	// KeyspaceIDs limits the compaction to the transactional data of the keyspaces, for the clusters
+	// with the API V2 enabled. It's only supported for the kv db.
+	// +optional
+	KeyspaceIDs []uint32 `json:"keyspaceIDs,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// AnalyzeTableTask runs ANALYZE TABLE on the tables
+type AnalyzeTableTask struct {
+	// Tables are the names of the tables in the format of db.table
+	Tables []string `json:"tables"`
+	// User is the user to connect to TiDB
+	// Optional: Defaults to root
+	// +optional
+	User string `json:"user,omitempty"`
+	// SecretName is the name of the Secret that contains the password of the user in the key password
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// Image is the image with the mysql client
+	// Optional: Defaults to tnir/mysqlclient
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// ResetStoreLimitTask resets the store limit of all the stores
+type ResetStoreLimitTask struct {
+	// Rate is the store limit to reset to
+	// Optional: Defaults to 15
+	// +optional
+	Rate *int32 `json:"rate,omitempty"`
+}
+
+// MaintenanceTaskPhase is the phase of the last run of a maintenance task
+type MaintenanceTaskPhase string
+
+const (
+	// MaintenanceTaskRunning means the Job of the task is running
+	MaintenanceTaskRunning MaintenanceTaskPhase = "Running"
+	// MaintenanceTaskSucceeded means the Job of the task succeeded
+	MaintenanceTaskSucceeded MaintenanceTaskPhase = "Succeeded"
+	// MaintenanceTaskFailed means the Job of the task failed or the task can't be run
+	MaintenanceTaskFailed MaintenanceTaskPhase = "Failed"
+	// MaintenanceTaskInterrupted means the Job of the task was deleted as an upgrade began
+	MaintenanceTaskInterrupted MaintenanceTaskPhase = "Interrupted"
+	// MaintenanceTaskSkipped means the run was skipped as it couldn't start within the window
+	MaintenanceTaskSkipped MaintenanceTaskPhase = "Skipped"
+)
+
+// MaintenanceTaskStatus is the status of a maintenance task
+type MaintenanceTaskStatus struct {
+	Name string `json:"name"`
+	// Phase is the phase of the last run
+	// +optional
+	Phase MaintenanceTaskPhase `json:"phase,omitempty"`
+	// Message describes the result of the last run, or why the task is postponed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// JobName is the name of the Job of the last run
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+	// LastScheduleTime is the time the last Job was created, or the scheduled time of the last run if it
+	// was skipped
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastCompletionTime is the time the last Job finished
+	// +optional
+	LastCompletionTime *metav1.Time `json:"lastCompletionTime,omitempty"`
+	// History contains the results of the recent finished runs, the latest first
+	// +optional
+	History []MaintenanceTaskRun `json:"history,omitempty"`
+}
+
+// MaintenanceTaskRun is the result of a finished run of a maintenance task
+type MaintenanceTaskRun struct {
+	// JobName is the name of the Job of the run, empty if the run was skipped
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+	// Phase is the final phase of the run
+	Phase MaintenanceTaskPhase `json:"phase"`
+	// Message describes the result of the run
+	// +optional
+	Message string `json:"message,omitempty"`
+	// ScheduleTime is the time the Job was created, or the scheduled time if the run was skipped
+	// +optional
+	ScheduleTime *metav1.Time `json:"scheduleTime,omitempty"`
+	// CompletionTime is the time the run finished
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// DiagnosticsPhase is the phase of the collection and the upload of a diagnostics bundle
+type DiagnosticsPhase string
+
+const (
+	// DiagnosticsCollecting means the bundle is being collected in the background
+	DiagnosticsCollecting DiagnosticsPhase = "Collecting"
+	// DiagnosticsUploading means the Job uploading the bundle is running
+	DiagnosticsUploading DiagnosticsPhase = "Uploading"
+	// DiagnosticsUploaded means the bundle is uploaded to the storage
+	DiagnosticsUploaded DiagnosticsPhase = "Uploaded"
+	// DiagnosticsFailed means the bundle can't be collected or uploaded
+	DiagnosticsFailed DiagnosticsPhase = "Failed"
+)
+
+// DiagnosticsStatus is the status of the last diagnostics bundle
+type DiagnosticsStatus struct {
+	// Reason is why the bundle was collected
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Annotation is the value of the annotation `tidb.pingcap.com/collect-diagnostics` the last
+	// bundle requested by the annotation was collected for
+	// +optional
+	Annotation string `json:"annotation,omitempty"`
+	// Phase is the phase of the collection and the upload of the bundle
+	// +optional
+	Phase DiagnosticsPhase `json:"phase,omitempty"`
+	// Message describes why the bundle can't be collected or uploaded
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Bundle is the name of the bundle
+	// +optional
+	Bundle string `json:"bundle,omitempty"`
+	// JobName is the name of the Job uploading the bundle
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+	// CollectTime is the time the bundle was collected
+	// +optional
+	CollectTime *metav1.Time `json:"collectTime,omitempty"`
+}
 
-	// TopologySpreadConstraints describes how a group of pods ought to spread across topology
-	// domains. Scheduler will schedule pods in a way which abides by the constraints.
-	// This field is is only honored by clusters that enables the EvenPodsSpread feature.
-	// All topologySpreadConstraints are ANDed.
+// ReadOnlyStatus is the status of the read-only mode of the cluster
+type ReadOnlyStatus struct {
+	// Enabled is whether the read-only mode is desired
+	Enabled bool `json:"enabled"`
+	// Enforced is whether all the healthy TiDB instances are confirmed to be read-only,
+	// or writable if the mode is disabled
+	Enforced bool `json:"enforced"`
+	// SchedulingHalted is whether the scheduling of PD is halted by the operator
 	// +optional
-	// +listType=map
-	// +listMapKey=topologyKey
-	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	SchedulingHalted bool `json:"schedulingHalted,omitempty"`
+	// Message describes why the mode is not enforced yet
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the time the mode is last enabled or disabled
+	// +optional
+	// +nullable
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // TidbClusterStatus represents the current status of a tidb cluster.
@@ -320,11 +1277,57 @@ type TidbClusterStatus struct {
 	ClusterID  string                    `json:"clusterID,omitempty"`
 	PD         PDStatus                  `json:"pd,omitempty"`
 	TiKV       TiKVStatus                `json:"tikv,omitempty"`
+	TiKVCold   TiKVStatus                `json:"tikvCold,omitempty"`
 	TiDB       TiDBStatus                `json:"tidb,omitempty"`
 	Pump       PumpStatus                `json:"pump,omitempty"`
 	TiFlash    TiFlashStatus             `json:"tiflash,omitempty"`
 	TiCDC      TiCDCStatus               `json:"ticdc,omitempty"`
+	TiProxy    TiProxyStatus             `json:"tiproxy,omitempty"`
 	AutoScaler *TidbClusterAutoScalerRef `json:"auto-scaler,omitempty"`
+	// HelperImages records the helper images and the image of the tikv-importer of the cluster in use, and their
+	// effective digests for auditing
+	// +optional
+	HelperImages []HelperImageStatus `json:"helperImages,omitempty"`
+	// ConfigDrift is the result of the last config drift check
+	// +optional
+	ConfigDrift *ConfigDriftStatus `json:"configDrift,omitempty"`
+	// ClockSkew is the result of the last clock skew check
+	// +optional
+	ClockSkew *ClockSkewStatus `json:"clockSkew,omitempty"`
+	// VersionSkew is the version skew detected, it's nil if the versions of the instances are not skewed
+	// +optional
+	VersionSkew *VersionSkewStatus `json:"versionSkew,omitempty"`
+	// SpecChecksum is the checksum of the specs last applied by the operator to the StatefulSets and Services
+	// of the cluster, it's changed only when the operator applies new desired specs to them
+	// +optional
+	SpecChecksum string `json:"specChecksum,omitempty"`
+	// StorageProvisioning is the status of the storage provisioning hooks of the components
+	// +optional
+	StorageProvisioning []StorageProvisioningStatus `json:"storageProvisioning,omitempty"`
+	// TombstoneStoreGC is the result of the last removal of the tombstone stores
+	// +optional
+	TombstoneStoreGC *TombstoneStoreGCStatus `json:"tombstoneStoreGC,omitempty"`
+	// VolumeRepairs are the recent repairs of the volumes bound to the lost nodes, the latest first
+	// +optional
+	VolumeRepairs []VolumeRepairRecord `json:"volumeRepairs,omitempty"`
+	// InFlightOperations are the long running operations in progress
+	// +optional
+	InFlightOperations []InFlightOperation `json:"inFlightOperations,omitempty"`
+	// Maintenance is the status of the maintenance tasks
+	// +optional
+	Maintenance []MaintenanceTaskStatus `json:"maintenance,omitempty"`
+	// Diagnostics is the status of the last diagnostics bundle
+	// +optional
+	Diagnostics *DiagnosticsStatus `json:"diagnostics,omitempty"`
+	// PausedAt is the time the operator observes the cluster paused, it's cleared once the cluster is resumed
+	// +optional
+	PausedAt *metav1.Time `json:"pausedAt,omitempty"`
+	// CertRotation is the rotation status of the certificates of the cluster
+	// +optional
+	CertRotation []CertRotationStatus `json:"certRotation,omitempty"`
+	// ReadOnly is the status of the read-only mode of the cluster
+	// +optional
+	ReadOnly *ReadOnlyStatus `json:"readOnly,omitempty"`
 	// Represents the latest available observations of a tidb cluster's state.
 	// +optional
 	// +nullable
@@ -364,6 +1367,31 @@ const (
 	// - All TiKV stores are up.
 	// - All TiFlash stores are up.
 	TidbClusterReady TidbClusterConditionType = "Ready"
+	// TidbClusterConfigDrifted indicates that the live config of some TiKV/TiDB instances
+	// differs from the desired config, it's only maintained if spec.configDrift is set.
+	TidbClusterConfigDrifted TidbClusterConditionType = "ConfigDrifted"
+	// TidbClusterScaleOutBlocked indicates that the scaling out of some components, including the scaling
+	// out for failover, is blocked as the resource quotas or the capacity of the cluster are insufficient.
+	// The message contains the shortfall of each blocked component.
+	TidbClusterScaleOutBlocked TidbClusterConditionType = "ScaleOutBlocked"
+	// TidbClusterTimezoneUpdating indicates that some components are being rolling restarted to apply
+	// the changed time zone. The message contains the old and the new time zone of each component.
+	TidbClusterTimezoneUpdating TidbClusterConditionType = "TimezoneUpdating"
+	// TidbClusterClockSkewDetected indicates that the clocks of some nodes of the cluster are skewed, it's
+	// only maintained if spec.clockSkew is set. The message contains the skewed nodes.
+	TidbClusterClockSkewDetected TidbClusterConditionType = "ClockSkewDetected"
+	// TidbClusterTLSInvalid indicates that some cluster TLS Secrets don't cover the DNS names the components
+	// are reached by or are out of their validity window, it's only maintained if spec.tlsCluster is enabled.
+	// The message contains the missing SANs and the expiry of each invalid Secret.
+	TidbClusterTLSInvalid TidbClusterConditionType = "TLSInvalid"
+	// TidbClusterTenantPolicyViolated indicates that the cluster violates the policy of the tenant its namespace
+	// belongs to, it's only maintained if the tenant policies are configured for the operator.
+	// The message contains the violated limits.
+	TidbClusterTenantPolicyViolated TidbClusterConditionType = "TenantPolicyViolated"
+	// TidbClusterVersionSkew indicates that the versions of some PD, TiKV and TiDB instances are skewed beyond the
+	// supported skew for longer than the grace period, it's only maintained if spec.versionSkew is set.
+	// The message contains the versions of the instances.
+	TidbClusterVersionSkew TidbClusterConditionType = "VersionSkew"
 )
 
 // +k8s:openapi-gen=true
@@ -402,6 +1430,14 @@ type PDSpec struct {
 	// +optional
 	MaxFailoverCount *int32 `json:"maxFailoverCount,omitempty"`
 
+	// ProgressDeadlineSeconds is the max seconds for a Pod to complete its update in a rolling update. If no Pod
+	// completes its update within the deadline, the rolling update is paused with the ProgressDeadlineExceeded
+	// condition, and no more Pods are deleted until the update revision is changed, or the deadline is raised or removed.
+	// Optional: Defaults to no deadline
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
 	// The storageClassName of the persistent volume for PD data storage.
 	// Defaults to Kubernetes default storage class.
 	// +optional
@@ -428,6 +1464,13 @@ type PDSpec struct {
 	// +kubebuilder:validation:XPreserveUnknownFields
 	Config *PDConfigWraper `json:"config,omitempty"`
 
+	// ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value
+	// is the config file of pd-servers in TOML. The items of the inline config and the items managed by the operator
+	// take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out
+	// like the changes of the inline config after the TOML is validated.
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+
 	// TLSClientSecretName is the name of secret which stores tidb server client certificate
 	// which used by Dashboard.
 	// +optional
@@ -441,6 +1484,32 @@ type PDSpec struct {
 	// MountClusterClientSecret indicates whether to mount `cluster-client-secret` to the Pod
 	// +optional
 	MountClusterClientSecret *bool `json:"mountClusterClientSecret,omitempty"`
+
+	// DeleteSlots are the ordinals of the Pods to delete, in addition to the ones in the `pd.tidb.pingcap.com/delete-slots`
+	// annotation. The deleted Pods are replaced by Pods of new ordinals unless the replicas are decreased
+	// accordingly. Only takes effect when advanced StatefulSet is enabled.
+	// +optional
+	DeleteSlots []int32 `json:"deleteSlots,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget of the PD pods.
+	// No PodDisruptionBudget is created if it's not set.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// LeaderPriorityByZone is the leader priority of the PD members keyed by the zone of their nodes, which is
+	// read from the topology.kubernetes.io/zone label of the nodes. The members in the zones of higher priorities
+	// are preferred to be the leader, so the leader stays in the low-latency zones, and is moved to another member
+	// in the same zone first when it fails. The priority of the members in the zones not listed is 0.
+	// +optional
+	LeaderPriorityByZone map[string]int32 `json:"leaderPriorityByZone,omitempty"`
+
+	// ClientPort is the port that PD serves the client requests on, it's used by the services, the probes and
+	// the other components to connect to PD. It can't be changed for an existing cluster.
+	// Optional: Defaults to 2379
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	ClientPort *int32 `json:"clientPort,omitempty"`
 }
 
 // TiKVSpec contains details of TiKV members
@@ -473,6 +1542,20 @@ type TiKVSpec struct {
 	// +optional
 	MaxFailoverCount *int32 `json:"maxFailoverCount,omitempty"`
 
+	// ProgressDeadlineSeconds is the max seconds for a Pod to complete its update in a rolling update. If no Pod
+	// completes its update within the deadline, the rolling update is paused with the ProgressDeadlineExceeded
+	// condition, and no more Pods are deleted until the update revision is changed, or the deadline is raised or removed.
+	// Optional: Defaults to no deadline
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// ResizePolicy is the restart policy of the resources of the TiKV container when the CPU and memory are resized
+	// in place, which is enabled by the InPlacePodVerticalScaling feature gate. The Pods are rolling updated instead
+	// if a resized resource requires the restart. The resources not listed don't require the restart.
+	// +optional
+	ResizePolicy []ContainerResizePolicy `json:"resizePolicy,omitempty"`
+
 	// Whether output the RocksDB log in a separate sidecar container
 	// Optional: Defaults to false
 	// +optional
@@ -517,6 +1600,13 @@ type TiKVSpec struct {
 	// +kubebuilder:validation:XPreserveUnknownFields
 	Config *TiKVConfigWraper `json:"config,omitempty"`
 
+	// ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value
+	// is the config file of tikv-servers in TOML. The items of the inline config and the items managed by the operator
+	// take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out
+	// like the changes of the inline config after the TOML is validated.
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+
 	// RecoverFailover indicates that Operator can recover the failed Pods
 	// +optional
 	RecoverFailover bool `json:"recoverFailover,omitempty"`
@@ -530,10 +1620,49 @@ type TiKVSpec struct {
 	// +optional
 	EvictLeaderTimeout *string `json:"evictLeaderTimeout,omitempty"`
 
+	// MaxReceivingSnapshotsOnUpgrade is the maximum number of snapshots a store can be receiving or applying
+	// for its Pod to be restarted during the upgrade, the upgrade is delayed until the store settles down,
+	// e.g. a new store that is still being filled. A negative value disables the check.
+	// Defaults to 3
+	// +optional
+	MaxReceivingSnapshotsOnUpgrade *int32 `json:"maxReceivingSnapshotsOnUpgrade,omitempty"`
+
+	// LeaderRecoveryPercentOnUpgrade is the percent of the leaders an upgraded store must regain, compared
+	// with its leader count before the leaders are evicted, for the upgrade to proceed to the next store,
+	// so that the region leaders are balanced again before another store is restarted.
+	// 0 disables the check, i.e. the upgrade proceeds once the upgraded Pod is ready.
+	// Optional: Defaults to 0
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	LeaderRecoveryPercentOnUpgrade *int32 `json:"leaderRecoveryPercentOnUpgrade,omitempty"`
+
+	// LeaderRecoveryTimeout is the maximum time to wait for the leaders of an upgraded store to recover
+	// after its Pod is ready, in the format of Go Duration.
+	// Defaults to 10m
+	// +optional
+	LeaderRecoveryTimeout *string `json:"leaderRecoveryTimeout,omitempty"`
+
+	// RegionHealthRefreshInterval is the minimum interval between two refreshes of the summary of the region
+	// health in the status, in the format of Go Duration. The summary is refreshed in every sync during the
+	// upgrade of TiKV, and the next store is not upgraded while there are regions with down peers.
+	// 0 disables the summary.
+	// Defaults to 5m
+	// +optional
+	RegionHealthRefreshInterval *string `json:"regionHealthRefreshInterval,omitempty"`
+
 	// StorageVolumes configure additional storage for TiKV pods.
 	// +optional
 	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
 
+	// EnableVolumeReplace allows shrinking the volumes of TiKV, which can't be resized in place.
+	// When a smaller storage request is set, the stores are replaced one by one: the leaders are evicted,
+	// the store is deleted and its regions are replicated to other stores, then the Pod is recreated
+	// with new PVCs of the desired size.
+	// Optional: Defaults to false
+	// +optional
+	EnableVolumeReplace bool `json:"enableVolumeReplace,omitempty"`
+
 	// StoreLabels configures additional labels for TiKV stores.
 	// +optional
 	StoreLabels []string `json:"storeLabels,omitempty"`
@@ -541,6 +1670,115 @@ type TiKVSpec struct {
 	// EnableNamedStatusPort enables status port(20180) in the Pod spec.
 	// If you set it to `true` for an existing cluster, the TiKV cluster will be rolling updated.
 	EnableNamedStatusPort bool `json:"enableNamedStatusPort,omitempty"`
+
+	// StoreScheduling configures the PD scheduling settings of the stores, e.g. the weights and the store
+	// limits, so that the stores on heterogeneous hardware are balanced correctly. For the stores selected
+	// by multiple items, the settings of the later items take precedence.
+	// +optional
+	StoreScheduling []TiKVStoreScheduling `json:"storeScheduling,omitempty"`
+
+	// DeleteSlots are the ordinals of the Pods to delete, in addition to the ones in the `tikv.tidb.pingcap.com/delete-slots`
+	// annotation. The deleted Pods are replaced by Pods of new ordinals unless the replicas are decreased
+	// accordingly. Only takes effect when advanced StatefulSet is enabled.
+	// +optional
+	DeleteSlots []int32 `json:"deleteSlots,omitempty"`
+
+	// PerPodConfig is the configuration patch of the TiKV Pods, keyed by the ordinal of the Pod.
+	// The patch is merged into Config to render the configuration file of the Pod, and only the Pods
+	// whose patch is changed are restarted one by one to apply the new configuration.
+	// Note that setting it for the first time restarts all the Pods, because the start script is changed.
+	// Only takes effect when Config is set.
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:XPreserveUnknownFields
+	PerPodConfig map[string]*TiKVConfigWraper `json:"perPodConfig,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget of the TiKV pods.
+	// No PodDisruptionBudget is created if it's not set.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// Port is the port that TiKV serves on, it's advertised to PD for the other components to connect to.
+	// It can't be changed for an existing cluster.
+	// Optional: Defaults to 20160
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// ColdGroup configures a secondary group of TiKV stores for the cold data, e.g. on the HDD-backed nodes.
+	// The stores of the group are excluded from the default placement rule of PD, so only the data pinned
+	// by the placement rules or placement policies with the constraints of the store labels of the group,
+	// e.g. `CONSTRAINTS="[+tier=cold]"`, is stored on them. It requires the placement rules of PD enabled.
+	// The group shares the image and the configuration of TiKV. If TLS is enabled between the components,
+	// the certificate of TiKV must be valid for the peer service of the group, e.g. `*.${cluster_name}-tikv-cold-peer`.
+	// +optional
+	ColdGroup *TiKVColdGroupSpec `json:"coldGroup,omitempty"`
+
+	// DebugArtifacts configures the capture of the core dumps and the heap profiles of TiKV for the postmortems
+	// when TiKV is OOMKilled or crashes
+	// +optional
+	DebugArtifacts *DebugArtifactsSpec `json:"debugArtifacts,omitempty"`
+}
+
+// TiKVColdGroupSpec is the spec of the TiKV cold group, the fields not set are inherited from TiKV
+// +k8s:openapi-gen=true
+type TiKVColdGroupSpec struct {
+	corev1.ResourceRequirements `json:",inline"`
+
+	// The desired ready replicas
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// The storageClassName of the persistent volume for the data of the group
+	// Defaults to the storageClassName of TiKV
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// NodeSelector of the Pods of the group, it overrides the one of TiKV
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the Pods of the group, they override the ones of TiKV
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// StoreLabels are the labels set to the stores of the group when they start, they are used to exclude
+	// the stores from the default placement rule and to pin the cold data. It can't be changed once set.
+	// Optional: Defaults to {"tier": "cold"}
+	// +optional
+	StoreLabels map[string]string `json:"storeLabels,omitempty"`
+}
+
+// TiKVStoreScheduling is the PD scheduling settings of the TiKV stores selected by the ordinals of their
+// Pods and the store labels. All the stores are selected if neither ordinals nor storeLabels is set.
+// +k8s:openapi-gen=true
+type TiKVStoreScheduling struct {
+	// Ordinals selects the stores by the ordinals of their Pods
+	// +optional
+	Ordinals []int32 `json:"ordinals,omitempty"`
+
+	// StoreLabels selects the stores by the labels of the stores in PD, e.g. zone and host
+	// +optional
+	StoreLabels map[string]string `json:"storeLabels,omitempty"`
+
+	// LeaderWeight is the leader weight of the stores, the leaders are balanced by the leader count
+	// divided by the weight. The weight of the stores not selected by any item is reset to 1.
+	// +optional
+	LeaderWeight *float64 `json:"leaderWeight,omitempty"`
+
+	// RegionWeight is the region weight of the stores, the regions are balanced by the region size
+	// divided by the weight. The weight of the stores not selected by any item is reset to 1.
+	// +optional
+	RegionWeight *float64 `json:"regionWeight,omitempty"`
+
+	// AddPeerLimit is the maximum number of the add-peer operators per minute on the stores
+	// +optional
+	AddPeerLimit *float64 `json:"addPeerLimit,omitempty"`
+
+	// RemovePeerLimit is the maximum number of the remove-peer operators per minute on the stores
+	// +optional
+	RemovePeerLimit *float64 `json:"removePeerLimit,omitempty"`
 }
 
 // TiFlashSpec contains details of TiFlash members
@@ -581,6 +1819,13 @@ type TiFlashSpec struct {
 	// +optional
 	Config *TiFlashConfigWraper `json:"config,omitempty"`
 
+	// ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value
+	// is the common config file of TiFlash in TOML. The items of `config.config` and the items managed by the
+	// operator take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are
+	// rolled out like the changes of the inline config after the TOML is validated.
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+
 	// LogTailer is the configurations of the log tailers for TiFlash
 	// +optional
 	LogTailer *LogTailerSpec `json:"logTailer,omitempty"`
@@ -588,6 +1833,22 @@ type TiFlashSpec struct {
 	// RecoverFailover indicates that Operator can recover the failover Pods
 	// +optional
 	RecoverFailover bool `json:"recoverFailover,omitempty"`
+
+	// DeleteSlots are the ordinals of the Pods to delete, in addition to the ones in the `tiflash.tidb.pingcap.com/delete-slots`
+	// annotation. The deleted Pods are replaced by Pods of new ordinals unless the replicas are decreased
+	// accordingly. Only takes effect when advanced StatefulSet is enabled.
+	// +optional
+	DeleteSlots []int32 `json:"deleteSlots,omitempty"`
+
+	// StoreLabels configures additional labels for TiFlash stores, which are set from the labels
+	// of the nodes in addition to the location labels of PD.
+	// +optional
+	StoreLabels []string `json:"storeLabels,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget of the TiFlash pods.
+	// No PodDisruptionBudget is created if it's not set.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
 }
 
 // TiCDCSpec contains details of TiCDC members
@@ -619,6 +1880,13 @@ type TiCDCSpec struct {
 	// +kubebuilder:validation:XPreserveUnknownFields
 	Config *CDCConfigWraper `json:"config,omitempty"`
 
+	// ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value
+	// is the config file of tidbcdc servers in TOML. The items of the inline config and the items managed by the operator
+	// take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out
+	// like the changes of the inline config after the TOML is validated.
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+
 	// StorageVolumes configure additional storage for TiCDC pods.
 	// +optional
 	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
@@ -627,6 +1895,31 @@ type TiCDCSpec struct {
 	// Defaults to Kubernetes default storage class.
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// GracefulShutdownTimeout is the timeout of resigning the ownership and draining the tables of a
+	// capture before its Pod is restarted during the upgrade, in the format of Go Duration.
+	// Defaults to 10m
+	// +optional
+	GracefulShutdownTimeout *string `json:"gracefulShutdownTimeout,omitempty"`
+
+	// Changefeeds are the changefeeds whose sink URIs are kept in sync with the Secrets they reference.
+	// The Secrets are watched, and when the sink URI in a Secret is changed, e.g. the credentials of the sink
+	// are rotated, the changefeed is paused, updated in place and resumed from its checkpoint instead of
+	// being recreated. The changefeeds are created by the users, and the changefeeds paused by the users are
+	// updated and kept paused.
+	// +optional
+	Changefeeds []TiCDCChangefeed `json:"changefeeds,omitempty"`
+}
+
+// TiCDCChangefeed references the Secret storing the sink URI of a changefeed
+// +k8s:openapi-gen=true
+type TiCDCChangefeed struct {
+	// ID is the ID of the changefeed
+	ID string `json:"id"`
+
+	// SinkURISecretRef references the key of a Secret in the namespace of the cluster, whose value is
+	// the sink URI of the changefeed
+	SinkURISecretRef corev1.SecretKeySelector `json:"sinkURISecretRef"`
 }
 
 // TiCDCConfig is the configuration of tidbcdc
@@ -654,6 +1947,37 @@ type TiCDCConfig struct {
 	LogFile *string `toml:"log-file,omitempty" json:"logFile,omitempty"`
 }
 
+// TiProxySpec contains details of TiProxy members
+// +k8s:openapi-gen=true
+type TiProxySpec struct {
+	ComponentSpec               `json:",inline"`
+	corev1.ResourceRequirements `json:",inline"`
+
+	// Specify a Service Account for TiProxy
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// The desired ready replicas
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// Base image of the component, image tag is now allowed during validation
+	// +kubebuilder:default=pingcap/tiproxy
+	// +optional
+	BaseImage string `json:"baseImage"`
+
+	// Service configures the Service the clients connect to TiProxy through
+	// Optional: Defaults to a ClusterIP Service
+	// +optional
+	Service *ServiceSpec `json:"service,omitempty"`
+
+	// Config is the Configuration of TiProxy servers, the addresses and the certificates are managed
+	// by the operator and can't be overridden
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:XPreserveUnknownFields
+	Config *TiProxyConfigWraper `json:"config,omitempty"`
+}
+
 // LogTailerSpec represents an optional log tailer sidecar container
 // +k8s:openapi-gen=true
 type LogTailerSpec struct {
@@ -696,6 +2020,13 @@ type TiDBSpec struct {
 	// +optional
 	Service *TiDBServiceSpec `json:"service,omitempty"`
 
+	// PerPodService defines the Kubernetes services created for each TiDB Pod, named after the Pods, e.g.
+	// ${cluster}-tidb-0, for client affinity and isolation. The services of the Pods removed by scale-in are
+	// deleted. The IPs and node ports can't be specified as they are shared by the services.
+	// Optional: No per-pod service will be created by default.
+	// +optional
+	PerPodService *TiDBServiceSpec `json:"perPodService,omitempty"`
+
 	// Whether enable TiDB Binlog, it is encouraged to not set this field and rely on the default behavior
 	// Optional: Defaults to true if PumpSpec is non-nil, otherwise false
 	// +optional
@@ -707,6 +2038,20 @@ type TiDBSpec struct {
 	// +optional
 	MaxFailoverCount *int32 `json:"maxFailoverCount,omitempty"`
 
+	// ProgressDeadlineSeconds is the max seconds for a Pod to complete its update in a rolling update. If no Pod
+	// completes its update within the deadline, the rolling update is paused with the ProgressDeadlineExceeded
+	// condition, and no more Pods are deleted until the update revision is changed, or the deadline is raised or removed.
+	// Optional: Defaults to no deadline
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// ResizePolicy is the restart policy of the resources of the TiDB container when the CPU and memory are resized
+	// in place, which is enabled by the InPlacePodVerticalScaling feature gate. The Pods are rolling updated instead
+	// if a resized resource requires the restart. The resources not listed don't require the restart.
+	// +optional
+	ResizePolicy []ContainerResizePolicy `json:"resizePolicy,omitempty"`
+
 	// Whether output the slow log in an separate sidecar container
 	// Optional: Defaults to true
 	// +optional
@@ -737,6 +2082,13 @@ type TiDBSpec struct {
 	// +kubebuilder:validation:XPreserveUnknownFields
 	Config *TiDBConfigWraper `json:"config,omitempty"`
 
+	// ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value
+	// is the config file of tidb-servers in TOML. The items of the inline config and the items managed by the operator
+	// take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out
+	// like the changes of the inline config after the TOML is validated.
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+
 	// Lifecycle describes actions that the management system should take in response to container lifecycle
 	// events. For the PostStart and PreStop lifecycle handlers, management of the container blocks
 	// until the action is complete, unless the container process fails, in which case the handler is aborted.
@@ -755,6 +2107,40 @@ type TiDBSpec struct {
 	// the default behavior is like setting type as "tcp"
 	// +optional
 	ReadinessProbe *TiDBProbe `json:"readinessProbe,omitempty"`
+
+	// DeleteSlots are the ordinals of the Pods to delete, in addition to the ones in the `tidb.tidb.pingcap.com/delete-slots`
+	// annotation. The deleted Pods are replaced by Pods of new ordinals unless the replicas are decreased
+	// accordingly. Only takes effect when advanced StatefulSet is enabled.
+	// +optional
+	DeleteSlots []int32 `json:"deleteSlots,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget of the TiDB pods.
+	// No PodDisruptionBudget is created if it's not set.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// UpgradeStrategy configures the rolling upgrade of the TiDB pods.
+	// +optional
+	UpgradeStrategy *TiDBUpgradeStrategy `json:"upgradeStrategy,omitempty"`
+
+	// Port is the port that TiDB serves the MySQL protocol on.
+	// Optional: Defaults to 4000
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// StatusPort is the port that TiDB serves the status API and the metrics on.
+	// Optional: Defaults to 10080
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	StatusPort *int32 `json:"statusPort,omitempty"`
+
+	// DebugArtifacts configures the capture of the core dumps and the heap profiles of TiDB for the postmortems
+	// when TiDB is OOMKilled or crashes
+	// +optional
+	DebugArtifacts *DebugArtifactsSpec `json:"debugArtifacts,omitempty"`
 }
 
 const (
@@ -807,6 +2193,13 @@ type PumpSpec struct {
 	// +kubebuilder:validation:XPreserveUnknownFields
 	Config *config.GenericConfig `json:"config,omitempty"`
 
+	// ConfigMapRef references the key of a user-managed ConfigMap in the namespace of the cluster, whose value
+	// is the config file of pump in TOML. The items of the inline config and the items managed by the operator
+	// take precedence over it. The ConfigMap is read when the cluster is synced, and its changes are rolled out
+	// like the changes of the inline config after the TOML is validated.
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+
 	// +k8s:openapi-gen=false
 	// For backward compatibility with helm chart
 	SetTimeZone *bool `json:"setTimeZone,omitempty"`
@@ -824,6 +2217,70 @@ type HelperSpec struct {
 	// Optional: Defaults to the cluster-level setting
 	// +optional
 	ImagePullPolicy *corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Digest pins the helper image to the digest, e.g. `sha256:<hex>`, so that the image pulled is
+	// verified by the container runtime. It replaces the digest in the image if there is one.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	Digest *string `json:"digest,omitempty"`
+}
+
+// HelperImageStatus is the effective helper image and the digests it is resolved to
+type HelperImageStatus struct {
+	// Image is the helper image in the Pod spec
+	Image string `json:"image"`
+	// Digests are the digests of the image reported by the container runtime of the running helper
+	// containers, there is more than one digest if the image is not pinned and differs between nodes
+	// +optional
+	Digests []string `json:"digests,omitempty"`
+	// Verified is true if the image is pinned by digest and all the digests reported match the pinned one
+	// +optional
+	Verified bool `json:"verified,omitempty"`
+}
+
+// TiDBUpgradeStrategy is the strategy of the rolling upgrade of TiDB
+// +k8s:openapi-gen=true
+type TiDBUpgradeStrategy struct {
+	// MaxUnavailable is the max number of the TiDB pods that can be restarted at the same time during the
+	// upgrade, it can be an absolute number or a percentage of the replicas, which is rounded down.
+	// The pods are evicted through the Eviction API, so the PodDisruptionBudget of TiDB is honored.
+	// Optional: Defaults to 1, i.e. the pods are upgraded one by one
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// DebugArtifactsSpec configures the capture of the debug artifacts of a component. An emptyDir volume is shared
+// between the component and a sidecar. The component runs in the volume, so the core dumps are written to it if the
+// `kernel.core_pattern` of the node is a relative path, e.g. the default `core`. The sidecar takes the heap profile
+// of the component periodically and keeps the last one taken before the component becomes unavailable. The artifacts
+// are uploaded to `${prefix}/${namespace}/${podName}/` of the storage by the sidecar, and an event with the path is
+// recorded once the component is OOMKilled or crashes. The heap profiles are not taken if TLS is enabled for the
+// cluster. Changing it causes a rolling update of the component.
+// +k8s:openapi-gen=true
+type DebugArtifactsSpec struct {
+	// Image of the sidecar, rclone is required to upload to S3 or GCS and wget to take the heap profiles,
+	// e.g. the image of tidb-backup-manager
+	Image string `json:"image"`
+
+	// Resources of the sidecar
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// SizeLimit is the size limit of the shared volume, the Pod is evicted if the artifacts exceed it
+	// +optional
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+
+	// Interval is the interval between two heap profiles and two uploads, in the format of Go Duration.
+	// Defaults to 1m
+	// +optional
+	Interval *string `json:"interval,omitempty"`
+
+	// DisableHeapProfile disables taking the heap profiles, so only the core dumps are captured
+	// +optional
+	DisableHeapProfile bool `json:"disableHeapProfile,omitempty"`
+
+	// StorageProvider configures where the artifacts are stored
+	StorageProvider `json:",inline"`
 }
 
 // TiDBSlowLogTailerSpec represents an optional log tailer sidecar with TiDB
@@ -964,6 +2421,21 @@ type ComponentSpec struct {
 	// +optional
 	PodManagementPolicy apps.PodManagementPolicyType `json:"podManagementPolicy,omitempty"`
 
+	// MinReadySeconds is the minimum number of seconds an upgraded Pod of the component must be ready,
+	// before the rolling update moves on to the next Pod. It absorbs the failures that show up some
+	// time after the Pod passes the readiness probe.
+	// Optional: Defaults to 0
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+
+	// RollingUpdateIntervalSeconds is the minimum number of seconds between the creation of an upgraded Pod
+	// of the component and the upgrade of the next Pod, which paces the rolling update.
+	// Optional: Defaults to 0
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RollingUpdateIntervalSeconds *int32 `json:"rollingUpdateIntervalSeconds,omitempty"`
+
 	// TopologySpreadConstraints describes how a group of pods ought to spread across topology
 	// domains. Scheduler will schedule pods in a way which abides by the constraints.
 	// This field is is only honored by clusters that enables the EvenPodsSpread feature.
@@ -972,6 +2444,45 @@ type ComponentSpec struct {
 	// +listType=map
 	// +listMapKey=topologyKey
 	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Architecture is the CPU architecture of the nodes that the component is scheduled to.
+	// Override the cluster-level architecture if present
+	// Optional: Defaults to cluster-level setting
+	// +optional
+	Architecture *Architecture `json:"architecture,omitempty"`
+
+	// ArchBaseImages overrides the base image of the component for the given architecture,
+	// e.g. `arm64: example.com/pingcap/tikv-arm64`, which is useful when the image is not a
+	// multi-arch manifest list. The version is appended the same way as the base image.
+	// It takes effect only if the architecture of the component is specified.
+	// +optional
+	ArchBaseImages map[Architecture]string `json:"archBaseImages,omitempty"`
+
+	// StartScriptVersion is the version of the start script of the component.
+	// Override the cluster-level startScriptVersion if present
+	// Optional: Defaults to cluster-level setting
+	// +optional
+	StartScriptVersion *StartScriptVersion `json:"startScriptVersion,omitempty"`
+
+	// Helper overrides the cluster-level helper image used by the sidecars and init containers
+	// of the component, each field takes effect separately
+	// Optional: Defaults to cluster-level setting
+	// +optional
+	Helper *HelperSpec `json:"helper,omitempty"`
+
+	// Timezone is the time zone of the component Pods, either an IANA time zone name, e.g. `Asia/Shanghai`,
+	// or a POSIX TZ string, e.g. `CST-8`.
+	// Override the cluster-level timezone if present
+	// Optional: Defaults to cluster-level setting
+	// +optional
+	Timezone *string `json:"timezone,omitempty"`
+
+	// MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the
+	// component container read-only, which is required if the image doesn't contain the database.
+	// Override the cluster-level mountTimezoneData if present
+	// Optional: Defaults to cluster-level setting
+	// +optional
+	MountTimezoneData *bool `json:"mountTimezoneData,omitempty"`
 }
 
 // ServiceSpec specifies the service object in k8s
@@ -1011,6 +2522,17 @@ type ServiceSpec struct {
 	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
 }
 
+// PodDisruptionBudgetSpec specifies the PodDisruptionBudget of a component, which limits the pods
+// unavailable at the same time due to the voluntary disruptions, e.g. node drains
+// +k8s:openapi-gen=true
+type PodDisruptionBudgetSpec struct {
+	// MaxUnavailable is the max number of the pods that can be unavailable during the voluntary
+	// disruptions, it can be an absolute number or a percentage of the replicas.
+	// Optional: Defaults to 1, which keeps the quorum of PD, TiKV and dm-master
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 // TiDBServiceSpec defines `.tidb.service` field of `TidbCluster.spec`.
 // +k8s:openapi-gen=true
 type TiDBServiceSpec struct {
@@ -1041,8 +2563,41 @@ type TiDBServiceSpec struct {
 	// Optional: Defaults to omitted
 	// +optional
 	AdditionalPorts []corev1.ServicePort `json:"additionalPorts,omitempty"`
+
+	// InternalTrafficPolicy of the service, `Local` routes the traffic from within the cluster
+	// only to the TiDB on the same node as the client. It's supported by Kubernetes v1.22 and later.
+	// Optional: Defaults to omitted
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	InternalTrafficPolicy *ServiceInternalTrafficPolicyType `json:"internalTrafficPolicy,omitempty"`
+
+	// TopologyAwareHints enables the topology aware hints of the service, which routes the traffic
+	// to the TiDB in the same zone as the client when the TiDB are evenly spread across the zones.
+	// It can't be enabled with the `Local` internal or external traffic policy.
+	// Optional: Defaults to false
+	// +optional
+	TopologyAwareHints bool `json:"topologyAwareHints,omitempty"`
+
+	// SessionAffinity of the service, `ClientIP` routes the connections from the same client to the same TiDB
+	// Optional: Defaults to omitted
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+
+	// SessionAffinityConfig of the service, only valid with the `ClientIP` session affinity
+	// +optional
+	SessionAffinityConfig *corev1.SessionAffinityConfig `json:"sessionAffinityConfig,omitempty"`
 }
 
+// ServiceInternalTrafficPolicyType is the internal traffic policy of a service
+type ServiceInternalTrafficPolicyType string
+
+const (
+	// ServiceInternalTrafficPolicyCluster routes the traffic to all the endpoints
+	ServiceInternalTrafficPolicyCluster ServiceInternalTrafficPolicyType = "Cluster"
+	// ServiceInternalTrafficPolicyLocal routes the traffic only to the endpoints on the same node as the client
+	ServiceInternalTrafficPolicyLocal ServiceInternalTrafficPolicyType = "Local"
+)
+
 // (Deprecated) Service represent service type used in TidbCluster
 // +k8s:openapi-gen=false
 type Service struct {
@@ -1063,6 +2618,30 @@ type PDStatus struct {
 	FailureMembers  map[string]PDFailureMember `json:"failureMembers,omitempty"`
 	UnjoinedMembers map[string]UnjoinedMember  `json:"unjoinedMembers,omitempty"`
 	Image           string                     `json:"image,omitempty"`
+	// StartScriptVersion is the version of the start script in use by the StatefulSet
+	StartScriptVersion StartScriptVersion `json:"startScriptVersion,omitempty"`
+	// Volumes contains the status of the volumes of this component, keyed by volume name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the conditions of this component, e.g. ComponentVolumeResizing
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// RollingUpdate is the progress of the rolling update of this component
+	RollingUpdate *RollingUpdateStatus `json:"rollingUpdate,omitempty"`
+	// Ordinals are the effective ordinals of the Pods, derived from the replicas and the delete slots
+	Ordinals []int32 `json:"ordinals,omitempty"`
+	// EvictLeaderSchedulerAudit is the result of the last audit of the evict-leader schedulers in PD
+	EvictLeaderSchedulerAudit *EvictLeaderSchedulerAudit `json:"evictLeaderSchedulerAudit,omitempty"`
+}
+
+// EvictLeaderSchedulerAudit is the result of an audit of the evict-leader schedulers of the TiKV stores in PD.
+// The schedulers of the stores not being upgraded or restarted are orphaned, and they are removed if they are
+// still orphaned in the next audit, so that the schedulers created right before an audit are not removed.
+type EvictLeaderSchedulerAudit struct {
+	// LastAuditTime is the time of the last audit
+	// +nullable
+	LastAuditTime metav1.Time `json:"lastAuditTime,omitempty"`
+	// OrphanedStores are the IDs of the stores whose evict-leader schedulers are found orphaned in the last audit
+	// +optional
+	OrphanedStores []string `json:"orphanedStores,omitempty"`
 }
 
 // PDMember is PD member
@@ -1077,6 +2656,19 @@ type PDMember struct {
 	// TODO: remove nullable, https://github.com/kubernetes/kubernetes/issues/86811
 	// +nullable
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// VolumeTopology is the zone and the node of the PVs bound to the Pod of the member
+	// +optional
+	VolumeTopology *VolumeTopology `json:"volumeTopology,omitempty"`
+}
+
+// VolumeTopology is the topology of the PVs of a Pod, collected from the node affinity and the labels of the PVs
+type VolumeTopology struct {
+	// Zone is the zone the PVs are bound to, empty if the PVs are not bound to a zone
+	// +optional
+	Zone string `json:"zone,omitempty"`
+	// Node is the node the PVs are bound to, e.g. the node of the local PVs, empty if the PVs are not bound to a node
+	// +optional
+	Node string `json:"node,omitempty"`
 }
 
 // EmptyStruct is defined to delight controller-gen tools
@@ -1111,6 +2703,16 @@ type TiDBStatus struct {
 	FailureMembers           map[string]TiDBFailureMember `json:"failureMembers,omitempty"`
 	ResignDDLOwnerRetryCount int32                        `json:"resignDDLOwnerRetryCount,omitempty"`
 	Image                    string                       `json:"image,omitempty"`
+	// StartScriptVersion is the version of the start script in use by the StatefulSet
+	StartScriptVersion StartScriptVersion `json:"startScriptVersion,omitempty"`
+	// Volumes contains the status of the volumes of this component, keyed by volume name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the conditions of this component, e.g. ComponentVolumeResizing
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// RollingUpdate is the progress of the rolling update of this component
+	RollingUpdate *RollingUpdateStatus `json:"rollingUpdate,omitempty"`
+	// Ordinals are the effective ordinals of the Pods, derived from the replicas and the delete slots
+	Ordinals []int32 `json:"ordinals,omitempty"`
 }
 
 // TiDBMember is TiDB member
@@ -1147,18 +2749,50 @@ type EvictLeaderStatus struct {
 	Value         string      `json:"value,omitempty"`
 }
 
+// RegionHealthStatus is the summary of the region health reported by PD
+type RegionHealthStatus struct {
+	// MissPeerRegionCount is the number of the regions with fewer peers than the max replicas
+	MissPeerRegionCount int32 `json:"missPeerRegionCount"`
+	// DownPeerRegionCount is the number of the regions with peers on the down stores
+	DownPeerRegionCount int32 `json:"downPeerRegionCount"`
+	// PendingPeerRegionCount is the number of the regions with peers whose raft logs are lagging behind
+	PendingPeerRegionCount int32 `json:"pendingPeerRegionCount"`
+	// LastRefreshTime is the time the summary is refreshed
+	LastRefreshTime metav1.Time `json:"lastRefreshTime,omitempty"`
+}
+
 // TiKVStatus is TiKV status
 type TiKVStatus struct {
-	Synced          bool                          `json:"synced,omitempty"`
-	Phase           MemberPhase                   `json:"phase,omitempty"`
-	BootStrapped    bool                          `json:"bootStrapped,omitempty"`
-	StatefulSet     *apps.StatefulSetStatus       `json:"statefulSet,omitempty"`
-	Stores          map[string]TiKVStore          `json:"stores,omitempty"`
-	PeerStores      map[string]TiKVStore          `json:"peerStores,omitempty"`
-	TombstoneStores map[string]TiKVStore          `json:"tombstoneStores,omitempty"`
-	FailureStores   map[string]TiKVFailureStore   `json:"failureStores,omitempty"`
-	Image           string                        `json:"image,omitempty"`
-	EvictLeader     map[string]*EvictLeaderStatus `json:"evictLeader,omitempty"`
+	Synced          bool                        `json:"synced,omitempty"`
+	Phase           MemberPhase                 `json:"phase,omitempty"`
+	BootStrapped    bool                        `json:"bootStrapped,omitempty"`
+	StatefulSet     *apps.StatefulSetStatus     `json:"statefulSet,omitempty"`
+	Stores          map[string]TiKVStore        `json:"stores,omitempty"`
+	PeerStores      map[string]TiKVStore        `json:"peerStores,omitempty"`
+	TombstoneStores map[string]TiKVStore        `json:"tombstoneStores,omitempty"`
+	FailureStores   map[string]TiKVFailureStore `json:"failureStores,omitempty"`
+	Image           string                      `json:"image,omitempty"`
+	// StartScriptVersion is the version of the start script in use by the StatefulSet
+	StartScriptVersion StartScriptVersion            `json:"startScriptVersion,omitempty"`
+	EvictLeader        map[string]*EvictLeaderStatus `json:"evictLeader,omitempty"`
+	// Volumes contains the status of the volumes of this component, keyed by volume name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the conditions of this component, e.g. ComponentVolumeResizing
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// RollingUpdate is the progress of the rolling update of this component
+	RollingUpdate *RollingUpdateStatus `json:"rollingUpdate,omitempty"`
+	// Ordinals are the effective ordinals of the Pods, derived from the replicas and the delete slots
+	Ordinals []int32 `json:"ordinals,omitempty"`
+	// RegionHealth is the summary of the region health of the cluster, which is refreshed periodically
+	RegionHealth *RegionHealthStatus `json:"regionHealth,omitempty"`
+	// EncryptionKeySecret is the Secret of the master key TiKV is configured with
+	EncryptionKeySecret string `json:"encryptionKeySecret,omitempty"`
+	// PreviousEncryptionKeySecret is the Secret of the master key before the last rotation, which TiKV is
+	// configured with as the previous master key
+	PreviousEncryptionKeySecret string `json:"previousEncryptionKeySecret,omitempty"`
+	// ExcludedStoreLabels are the store labels of the TiKV cold group excluded from the default placement
+	// rule of PD, the label constraints are removed from the rule once the cold group is disabled
+	ExcludedStoreLabels map[string]string `json:"excludedStoreLabels,omitempty"`
 }
 
 // TiFlashStatus is TiFlash status
@@ -1171,6 +2805,12 @@ type TiFlashStatus struct {
 	TombstoneStores map[string]TiKVStore        `json:"tombstoneStores,omitempty"`
 	FailureStores   map[string]TiKVFailureStore `json:"failureStores,omitempty"`
 	Image           string                      `json:"image,omitempty"`
+	// Volumes contains the status of the volumes of this component, keyed by volume name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the conditions of this component, e.g. ComponentVolumeResizing
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Ordinals are the effective ordinals of the Pods, derived from the replicas and the delete slots
+	Ordinals []int32 `json:"ordinals,omitempty"`
 }
 
 // TiCDCStatus is TiCDC status
@@ -1179,6 +2819,26 @@ type TiCDCStatus struct {
 	Phase       MemberPhase             `json:"phase,omitempty"`
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
 	Captures    map[string]TiCDCCapture `json:"captures,omitempty"`
+	// Volumes contains the status of the volumes of this component, keyed by volume name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the conditions of this component, e.g. ComponentVolumeResizing
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Changefeeds contains the status of the changefeeds in `spec.ticdc.changefeeds`, keyed by the changefeed IDs
+	Changefeeds map[string]TiCDCChangefeedStatus `json:"changefeeds,omitempty"`
+}
+
+// TiCDCChangefeedStatus is the status of a changefeed whose sink URI is kept in sync with a Secret
+type TiCDCChangefeedStatus struct {
+	// SinkURIHash is the hash of the sink URI applied to the changefeed
+	SinkURIHash string `json:"sinkURIHash,omitempty"`
+	// State is the state of the changefeed reported by TiCDC, e.g. normal, stopped, error or failed.
+	// The changefeeds in the states other than normal and stopped are not updated, the users have to
+	// resolve them.
+	State string `json:"state,omitempty"`
+	// PausedForUpdate is true if the changefeed is paused by the operator to update its sink URI, only
+	// these changefeeds are resumed after the update. The changefeeds paused by the users are updated
+	// and kept paused.
+	PausedForUpdate bool `json:"pausedForUpdate,omitempty"`
 }
 
 // TiCDCCapture is TiCDC Capture status
@@ -1190,6 +2850,23 @@ type TiCDCCapture struct {
 	Ready   bool   `json:"ready,omitempty"`
 }
 
+// TiProxyStatus is TiProxy status
+type TiProxyStatus struct {
+	Synced      bool                     `json:"synced,omitempty"`
+	Phase       MemberPhase              `json:"phase,omitempty"`
+	StatefulSet *apps.StatefulSetStatus  `json:"statefulSet,omitempty"`
+	Members     map[string]TiProxyMember `json:"members,omitempty"`
+}
+
+// TiProxyMember is TiProxy member
+type TiProxyMember struct {
+	Name   string `json:"name"`
+	Health bool   `json:"health"`
+	// Last time the health transitioned from one to another.
+	// +nullable
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
 // TiKVStores is either Up/Down/Offline/Tombstone
 type TiKVStore struct {
 	// store id is also uint64, due to the same reason as pd id, we store id as string
@@ -1202,6 +2879,9 @@ type TiKVStore struct {
 	// TODO: remove nullable, https://github.com/kubernetes/kubernetes/issues/86811
 	// +nullable
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// VolumeTopology is the zone and the node of the PVs bound to the Pod of the store
+	// +optional
+	VolumeTopology *VolumeTopology `json:"volumeTopology,omitempty"`
 }
 
 // TiKVFailureStore is the tikv failure store information
@@ -1230,6 +2910,10 @@ type PumpStatus struct {
 	Phase       MemberPhase             `json:"phase,omitempty"`
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
 	Members     []*PumpNodeStatus       `json:"members,omitempty"`
+	// Volumes contains the status of the volumes of this component, keyed by volume name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the conditions of this component, e.g. ComponentVolumeResizing
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // TiDBTLSClient can enable TLS connection between TiDB server and MySQL client
@@ -1279,6 +2963,55 @@ type TLSCluster struct {
 	//        Same for other components.
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
+
+	// CertRotation enables the operator to renew the certificates in the Secrets above before they expire.
+	// The renewed certificates are reloaded by the components online, the pods are not restarted.
+	// +optional
+	CertRotation *CertRotationSpec `json:"certRotation,omitempty"`
+}
+
+// CertIssuer is the issuer renewing the certificates
+type CertIssuer string
+
+const (
+	// CertIssuerCertManager renews the certificate by triggering the reissuance of the cert-manager
+	// Certificate that owns the Secret
+	CertIssuerCertManager CertIssuer = "cert-manager"
+	// CertIssuerCSR renews the certificate with a CertificateSigningRequest of the Kubernetes API
+	CertIssuerCSR CertIssuer = "CSR"
+)
+
+// CertRotationSpec describes how the certificates of the cluster are renewed
+type CertRotationSpec struct {
+	// Issuer renews the certificates, one of cert-manager and CSR
+	Issuer CertIssuer `json:"issuer"`
+
+	// RenewBefore is how long before the expiry the certificates are renewed
+	// Optional: Defaults to 720h
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// SignerName is the signer of the CertificateSigningRequests, required if the issuer is CSR.
+	// The requests must be approved by the cluster administrator or an approver of the signer.
+	// +optional
+	SignerName string `json:"signerName,omitempty"`
+}
+
+// CertRotationStatus is the rotation status of the certificate in a Secret
+type CertRotationStatus struct {
+	SecretName string `json:"secretName"`
+	// NotAfter is the expiry of the certificate
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+	// LastRotationTime is the time the certificate was renewed by the operator
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// CSRName is the name of the pending CertificateSigningRequest
+	// +optional
+	CSRName string `json:"csrName,omitempty"`
+	// Message describes why the certificate can't be renewed
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +genclient
@@ -1288,12 +3021,16 @@ type TLSCluster struct {
 //
 // +k8s:openapi-gen=true
 // +kubebuilder:resource:shortName="bk"
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.backupType`,description="the type of backup, such as full, db, table. Only used when Mode = snapshot."
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.br.cluster`,description="The name of the tidb cluster to backup, only for BR"
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`,description="The current status of the backup"
 // +kubebuilder:printcolumn:name="BackupPath",type=string,JSONPath=`.status.backupPath`,description="The full path of backup data"
 // +kubebuilder:printcolumn:name="BackupSize",type=string,JSONPath=`.status.backupSizeReadable`,description="The data size of the backup"
 // +kubebuilder:printcolumn:name="CommitTS",type=string,JSONPath=`.status.commitTs`,description="The commit ts of tidb cluster dump"
 // +kubebuilder:printcolumn:name="Started",type=date,JSONPath=`.status.timeStarted`,description="The time at which the backup was started",priority=1
 // +kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.timeCompleted`,description="The time at which the backup was completed",priority=1
+// +kubebuilder:printcolumn:name="Progress",type=number,JSONPath=`.status.progresses[-1:].progress`,description="The progress of the latest step of the backup reported by BR",priority=1
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.conditions[-1:].message`,description="The message of the latest backup condition",priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type Backup struct {
 	metav1.TypeMeta `json:",inline"`
@@ -1326,6 +3063,8 @@ const (
 	BackupStorageTypeS3 BackupStorageType = "s3"
 	// BackupStorageTypeGcs represents the google cloud storage
 	BackupStorageTypeGcs BackupStorageType = "gcs"
+	// BackupStorageTypeAzblob represents the azure blob storage
+	BackupStorageTypeAzblob BackupStorageType = "azure"
 	// BackupStorageTypeLocal represents local volume storage type
 	BackupStorageTypeLocal BackupStorageType = "local"
 	// BackupStorageTypeUnknown represents the unknown storage type
@@ -1346,9 +3085,10 @@ const (
 // StorageProvider defines the configuration for storing a backup in backend storage.
 // +k8s:openapi-gen=true
 type StorageProvider struct {
-	S3    *S3StorageProvider    `json:"s3,omitempty"`
-	Gcs   *GcsStorageProvider   `json:"gcs,omitempty"`
-	Local *LocalStorageProvider `json:"local,omitempty"`
+	S3     *S3StorageProvider     `json:"s3,omitempty"`
+	Gcs    *GcsStorageProvider    `json:"gcs,omitempty"`
+	Azblob *AzblobStorageProvider `json:"azblob,omitempty"`
+	Local  *LocalStorageProvider  `json:"local,omitempty"`
 }
 
 // LocalStorageProvider defines local storage options, which can be any k8s supported mounted volume
@@ -1412,6 +3152,24 @@ type GcsStorageProvider struct {
 	Prefix string `json:"prefix,omitempty"`
 }
 
+// +k8s:openapi-gen=true
+// AzblobStorageProvider represents the azure blob storage for storing backups.
+type AzblobStorageProvider struct {
+	// Path is the full path where the backup is saved.
+	// The format of the path must be: "<container-name>/<path-to-backup-file>"
+	Path string `json:"path,omitempty"`
+	// Container in which to store the backup data.
+	Container string `json:"container,omitempty"`
+	// AccessTier represents the access tier of the blobs, Hot, Cool or Archive.
+	AccessTier string `json:"accessTier,omitempty"`
+	// SecretName is the name of secret which stores the azure storage account name
+	// and either the account key or a SAS token. The secret is mounted into the job,
+	// so a rotated SAS token is picked up without restarting the job.
+	SecretName string `json:"secretName,omitempty"`
+	// Prefix of the data path.
+	Prefix string `json:"prefix,omitempty"`
+}
+
 // BackupType represents the backup type.
 // +k8s:openapi-gen=true
 type BackupType string
@@ -1429,6 +3187,19 @@ const (
 	BackupTypeTiFlashReplica BackupType = "tiflash-replica"
 )
 
+// BackupMode represents the backup mode, such as snapshot or log.
+// +k8s:openapi-gen=true
+type BackupMode string
+
+const (
+	// BackupModeSnapshot represents the snapshot backup of tidb cluster.
+	BackupModeSnapshot BackupMode = "snapshot"
+	// BackupModeLog represents the log backup of tidb cluster.
+	BackupModeLog BackupMode = "log"
+	// BackupModeVolumeSnapshot represents the backup of tidb cluster by the CSI VolumeSnapshots of the PVCs.
+	BackupModeVolumeSnapshot BackupMode = "volume-snapshot"
+)
+
 // TiDBAccessConfig defines the configuration for access tidb cluster
 // +k8s:openapi-gen=true
 type TiDBAccessConfig struct {
@@ -1512,6 +3283,24 @@ type BackupSpec struct {
 	From *TiDBAccessConfig `json:"from,omitempty"`
 	// Type is the backup type for tidb cluster.
 	Type BackupType `json:"backupType,omitempty"`
+	// Mode is the backup mode, snapshot, log or volume-snapshot. The log mode starts a log backup task, which continuously
+	// backs up the changes of the cluster to the storage until the Backup is deleted. It's only supported by BR.
+	// The volume-snapshot mode pauses the PD scheduling of the cluster in `spec.br`, records the min resolved ts of the
+	// cluster as the commit ts and takes the CSI VolumeSnapshots of the PVCs of PD and TiKV, the scheduling is resumed
+	// once the snapshots are cut. No backup job is created and the storage of the Backup is not used.
+	// Optional: Defaults to snapshot
+	// +kubebuilder:validation:Enum=snapshot;log;volume-snapshot
+	// +optional
+	Mode BackupMode `json:"backupMode,omitempty"`
+	// LogTruncateUntil truncates the data of the log backup before the ts, in the format of a TSO,
+	// e.g. `434319917412892673`, or a RFC3339 datetime, e.g. `2022-07-15T19:00:00+08:00`.
+	// It's only valid for the log mode.
+	// +optional
+	LogTruncateUntil string `json:"logTruncateUntil,omitempty"`
+	// VolumeSnapshotClassName is the VolumeSnapshotClass of the snapshots taken by the volume-snapshot mode.
+	// Optional: Defaults to the default VolumeSnapshotClass of the CSI driver
+	// +optional
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
 	// TikvGCLifeTime is to specify the safe gc life time for backup.
 	// The time limit during which data is retained for each GC, in the format of Go Duration.
 	// When a GC happens, the current time minus this value is the safe point.
@@ -1539,6 +3328,12 @@ type BackupSpec struct {
 	// ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// ClusterRegistryPrefix rewrites the registry of all the images used by the backup job,
+	// including the backup manager and tool images. E.g. with `registry.local/mirror`,
+	// `pingcap/br:v5.4.0` is pulled from `registry.local/mirror/pingcap/br:v5.4.0`,
+	// which is useful for air-gapped environments
+	// +optional
+	ClusterRegistryPrefix string `json:"clusterRegistryPrefix,omitempty"`
 	// TableFilter means Table filter expression for 'db.table' matching. BR supports this from v4.0.3.
 	TableFilter []string `json:"tableFilter,omitempty"`
 	// Affinity of backup Pods
@@ -1559,6 +3354,19 @@ type BackupSpec struct {
 
 	// PriorityClassName of Backup Job Pods
 	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Additional volumes of the Backup Job Pods, e.g. the ConfigMaps or Secrets of custom CA bundles,
+	// proxy settings or wrapper scripts
+	// +optional
+	AdditionalVolumes []corev1.Volume `json:"additionalVolumes,omitempty"`
+
+	// Additional volume mounts of the backup container
+	// +optional
+	AdditionalVolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
+
+	// Notifications configures the sinks to send the notification to when the backup fails
+	// +optional
+	Notifications *NotificationSpec `json:"notifications,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -1568,6 +3376,12 @@ type DumplingConfig struct {
 	Options []string `json:"options,omitempty"`
 	// Deprecated. Please use `Spec.TableFilter` instead. TableFilter means Table filter expression for 'db.table' matching
 	TableFilter []string `json:"tableFilter,omitempty"`
+	// ShardCount is the number of Jobs the export is split into, each Job exports a range of the tables
+	// sorted by name at the same snapshot, and the Backup is complete only after all of them succeed.
+	// Optional: Defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ShardCount int32 `json:"shardCount,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -1597,6 +3411,10 @@ type BRConfig struct {
 	SendCredToTikv *bool `json:"sendCredToTikv,omitempty"`
 	// OnLine specifies whether online during restore
 	OnLine *bool `json:"onLine,omitempty"`
+	// IgnoreStats specifies whether to skip backing up the statistics of the tables. Set it to false to
+	// back up the statistics, which are loaded by the restore to avoid running with empty statistics.
+	// It's only used by backup and supported by BR since v4.0.9.
+	IgnoreStats *bool `json:"ignoreStats,omitempty"`
 	// Options means options for backup data to remote storage with BR. These options has highest priority.
 	Options []string `json:"options,omitempty"`
 }
@@ -1622,6 +3440,8 @@ const (
 	BackupInvalid BackupConditionType = "Invalid"
 	// BackupPrepare means the backup prepare backup process
 	BackupPrepare BackupConditionType = "Prepare"
+	// BackupLogStopped means the log backup task has been stopped
+	BackupLogStopped BackupConditionType = "LogStopped"
 )
 
 // BackupCondition describes the observed state of a Backup at a certain point.
@@ -1653,12 +3473,89 @@ type BackupStatus struct {
 	BackupSize int64 `json:"backupSize,omitempty"`
 	// CommitTs is the snapshot time point of tidb cluster.
 	CommitTs string `json:"commitTs,omitempty"`
+	// LogTruncatedUntil is the ts the data of the log backup is truncated until, only for the log mode
+	// +optional
+	LogTruncatedUntil string `json:"logTruncatedUntil,omitempty"`
 	// Phase is a user readable state inferred from the underlying Backup conditions
 	Phase BackupConditionType `json:"phase,omitempty"`
+	// TikvGCLifeTime tracks the tikv_gc_life_time adjusted for the backup, so that the original
+	// value can be restored even if the backup is interrupted and retried
+	// +optional
+	TikvGCLifeTime *TikvGCLifeTimeStatus `json:"tikvGCLifeTime,omitempty"`
+	// Shards is the status of the shards of the backup if spec.dumpling.shardCount is greater than 1
+	// +nullable
+	// +optional
+	Shards []BackupShardStatus `json:"shards,omitempty"`
+	// Progresses is the progress of the steps of the backup reported by BR, e.g. `Full Backup` and `Checksum`
+	// +nullable
+	// +optional
+	Progresses []BackupProgress `json:"progresses,omitempty"`
+	// VolumeSnapshotRetries is the number of the failed attempts to cut the volume snapshots, only for the
+	// volume-snapshot mode, the backup fails once it exceeds the limit
+	// +optional
+	VolumeSnapshotRetries int32 `json:"volumeSnapshotRetries,omitempty"`
 	// +nullable
 	Conditions []BackupCondition `json:"conditions,omitempty"`
 }
 
+// BackupProgress is the progress of a step of the backup
+type BackupProgress struct {
+	// Step is the name of the step reported by BR, e.g. `Full Backup`
+	Step string `json:"step"`
+	// Progress is the percentage of the step, from 0 to 100
+	Progress float64 `json:"progress"`
+	// LastTransitionTime is the time at which the progress was updated
+	// +nullable
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// EstimatedCompletionTime is the time at which the step is estimated to complete by the remaining time reported by BR
+	// +nullable
+	// +optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+}
+
+// BackupShardStatus is the status of a shard of the backup
+type BackupShardStatus struct {
+	// Index is the index of the shard
+	Index int32 `json:"index"`
+	// Phase is the phase of the shard, either Running or Complete
+	Phase BackupConditionType `json:"phase,omitempty"`
+	// BackupPath is the location of the backup data of the shard
+	BackupPath string `json:"backupPath,omitempty"`
+	// BackupSize is the data size of the shard
+	BackupSize int64 `json:"backupSize,omitempty"`
+	// CommitTs is the snapshot time point of the shard, which is the same for all the shards
+	CommitTs string `json:"commitTs,omitempty"`
+	// TimeStarted is the time at which the shard was started
+	// +nullable
+	TimeStarted metav1.Time `json:"timeStarted,omitempty"`
+	// TimeCompleted is the time at which the shard was completed
+	// +nullable
+	TimeCompleted metav1.Time `json:"timeCompleted,omitempty"`
+}
+
+// TikvGCLifeTimeState is the state of the tikv_gc_life_time adjusted for a backup
+type TikvGCLifeTimeState string
+
+const (
+	// TikvGCLifeTimeAdjusted means the tikv_gc_life_time has been adjusted and not restored yet
+	TikvGCLifeTimeAdjusted TikvGCLifeTimeState = "Adjusted"
+	// TikvGCLifeTimeRestored means the original tikv_gc_life_time has been restored
+	TikvGCLifeTimeRestored TikvGCLifeTimeState = "Restored"
+	// TikvGCLifeTimeHandedOver means the restoration is handed over to another running
+	// backup of the same cluster, which restores the original tikv_gc_life_time when it finishes
+	TikvGCLifeTimeHandedOver TikvGCLifeTimeState = "HandedOver"
+)
+
+// TikvGCLifeTimeStatus is the tikv_gc_life_time adjusted for a backup
+type TikvGCLifeTimeStatus struct {
+	// Original is the tikv_gc_life_time before any backup of the cluster adjusted it
+	Original string `json:"original"`
+	// Adjusted is the tikv_gc_life_time set during the backup
+	Adjusted string `json:"adjusted"`
+	// State is the state of the adjustment
+	State TikvGCLifeTimeState `json:"state"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -1708,6 +3605,11 @@ type BackupScheduleSpec struct {
 	MaxReservedTime *string `json:"maxReservedTime,omitempty"`
 	// BackupTemplate is the specification of the backup structure to get scheduled.
 	BackupTemplate BackupSpec `json:"backupTemplate"`
+	// LogBackupTemplate is the specification of the log backup running along with the scheduled backups,
+	// its mode is always log. The scheduled backups that are the base of the retained window of the log
+	// backup are never garbage collected, and the log backup is truncated until the oldest of them.
+	// +optional
+	LogBackupTemplate *BackupSpec `json:"logBackupTemplate,omitempty"`
 	// The storageClassName of the persistent volume for Backup data storage if not storage class name set in BackupSpec.
 	// Defaults to Kubernetes default storage class.
 	// +optional
@@ -1717,6 +3619,10 @@ type BackupScheduleSpec struct {
 	// ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// RPO is the recovery point objective of the scheduled backups, the BackupFresh condition
+	// is set to False if no backup completed successfully within it.
+	// +optional
+	RPO *metav1.Duration `json:"rpo,omitempty"`
 }
 
 // BackupScheduleStatus represents the current state of a BackupSchedule.
@@ -1727,8 +3633,27 @@ type BackupScheduleStatus struct {
 	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
 	// AllBackupCleanTime represents the time when all backup entries are cleaned up
 	AllBackupCleanTime *metav1.Time `json:"allBackupCleanTime,omitempty"`
+	// LogBackup is the name of the log backup created from the logBackupTemplate
+	LogBackup string `json:"logBackup,omitempty"`
+	// LastSuccessfulBackup is the latest backup completed successfully
+	LastSuccessfulBackup string `json:"lastSuccessfulBackup,omitempty"`
+	// LastSuccessfulBackupTime is the time at which the latest successful backup was completed
+	LastSuccessfulBackupTime *metav1.Time `json:"lastSuccessfulBackupTime,omitempty"`
+	// LastSuccessfulBackupDuration is how long the latest successful backup took
+	LastSuccessfulBackupDuration *metav1.Duration `json:"lastSuccessfulBackupDuration,omitempty"`
+	// LastSuccessfulBackupSize is the data size of the latest successful backup in bytes
+	LastSuccessfulBackupSize int64 `json:"lastSuccessfulBackupSize,omitempty"`
+	// ConsecutiveFailures is the number of the backups failed since the latest successful backup
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Conditions contains the conditions of the backup schedule, e.g. BackupFresh
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+const (
+	// BackupScheduleBackupFresh indicates whether a backup completed successfully within the RPO
+	BackupScheduleBackupFresh = "BackupFresh"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -1740,6 +3665,7 @@ type BackupScheduleStatus struct {
 // +kubebuilder:printcolumn:name="Started",type=date,JSONPath=`.status.timeStarted`,description="The time at which the restore was started",priority=1
 // +kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.timeCompleted`,description="The time at which the restore was completed",priority=1
 // +kubebuilder:printcolumn:name="CommitTS",type=string,JSONPath=`.status.commitTs`,description="The commit ts of tidb cluster restore"
+// +kubebuilder:printcolumn:name="Progress",type=number,JSONPath=`.status.progresses[-1:].progress`,description="The progress of the latest step of the restore reported by BR",priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type Restore struct {
 	metav1.TypeMeta `json:",inline"`
@@ -1780,6 +3706,12 @@ const (
 	RestoreRetryFailed RestoreConditionType = "RetryFailed"
 	// RestoreInvalid means invalid restore CR.
 	RestoreInvalid RestoreConditionType = "Invalid"
+	// RestoreAnalyzingStatistics means the data is restored and the statistics of the restored
+	// tables are being analyzed as the post-step of the Restore.
+	RestoreAnalyzingStatistics RestoreConditionType = "AnalyzingStatistics"
+	// RestoreSettingTiFlashReplicas means the data is restored and the TiFlash replicas of the restored
+	// tables are being set as the post-step of the Restore.
+	RestoreSettingTiFlashReplicas RestoreConditionType = "SettingTiFlashReplicas"
 )
 
 // RestoreCondition describes the observed state of a Restore at a certain point.
@@ -1820,6 +3752,24 @@ type RestoreSpec struct {
 	To *TiDBAccessConfig `json:"to,omitempty"`
 	// Type is the backup type for tidb cluster.
 	Type BackupType `json:"backupType,omitempty"`
+	// Mode is the restore mode, snapshot, pitr or volume-snapshot. The pitr mode restores the cluster to a point in time,
+	// the snapshot backup in the storage of the Restore is restored first, and then the log backup
+	// from the commit ts of the snapshot backup to the restored ts is applied. It's only supported by BR.
+	// The volume-snapshot mode creates the PVCs of PD and TiKV of the cluster in `spec.br` from the snapshots
+	// of the Backup in `volumeSnapshotBackup`, so the Restore must be created before the TidbCluster. After the TiKV
+	// stores are up, the data of TiKV is reset to the commit ts of the Backup by a job, and the cluster must not be
+	// written until the Restore is complete.
+	// Optional: Defaults to snapshot
+	// +kubebuilder:validation:Enum=snapshot;pitr;volume-snapshot
+	// +optional
+	Mode RestoreMode `json:"restoreMode,omitempty"`
+	// PiTR configures the point-in-time restore, it's required by the pitr mode
+	// +optional
+	PiTR *PiTRRestoreSpec `json:"pitr,omitempty"`
+	// VolumeSnapshotBackup is the name of the complete Backup of the volume-snapshot mode in the same namespace,
+	// it's required by the volume-snapshot mode
+	// +optional
+	VolumeSnapshotBackup string `json:"volumeSnapshotBackup,omitempty"`
 	// TikvGCLifeTime is to specify the safe gc life time for restore.
 	// The time limit during which data is retained for each GC, in the format of Go Duration.
 	// When a GC happens, the current time minus this value is the safe point.
@@ -1852,6 +3802,12 @@ type RestoreSpec struct {
 	// ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// ClusterRegistryPrefix rewrites the registry of all the images used by the restore job,
+	// including the backup manager and tool images. E.g. with `registry.local/mirror`,
+	// `pingcap/br:v5.4.0` is pulled from `registry.local/mirror/pingcap/br:v5.4.0`,
+	// which is useful for air-gapped environments
+	// +optional
+	ClusterRegistryPrefix string `json:"clusterRegistryPrefix,omitempty"`
 	// TableFilter means Table filter expression for 'db.table' matching. BR supports this from v4.0.3.
 	TableFilter []string `json:"tableFilter,omitempty"`
 
@@ -1861,6 +3817,131 @@ type RestoreSpec struct {
 
 	// PriorityClassName of Restore Job Pods
 	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Additional volumes of the Restore Job Pods, e.g. the ConfigMaps or Secrets of custom CA bundles,
+	// proxy settings or wrapper scripts
+	// +optional
+	AdditionalVolumes []corev1.Volume `json:"additionalVolumes,omitempty"`
+
+	// Additional volume mounts of the restore container
+	// +optional
+	AdditionalVolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
+
+	// Statistics configures the post-step to restore the statistics of the restored tables,
+	// which avoids the performance cliff of running with empty statistics
+	// +optional
+	Statistics *RestoreStatisticsSpec `json:"statistics,omitempty"`
+
+	// ImportWindow tunes the PD scheduling and the TiKV import config of the target cluster while
+	// the restore is running, the original values are restored after the restore finishes.
+	// Only BR restores are supported.
+	// +optional
+	ImportWindow *ImportWindowSpec `json:"importWindow,omitempty"`
+
+	// Force drops the tables in the target cluster that conflict with the restore set before restoring.
+	// Without it, a BR restore is failed with the reason RestoreConflict before the restore job is created
+	// if any table of the restore set exists in the target cluster. The restore set is the db or the table
+	// of the restore of type db or table, the tables matched by `tableFilter`, or all the user tables.
+	// It requires `spec.to`.
+	// +optional
+	Force bool `json:"force,omitempty"`
+
+	// TiFlashReplica configures the post-step to set the TiFlash replicas of the restored tables,
+	// which are not restored by BR
+	// +optional
+	TiFlashReplica *RestoreTiFlashReplicaSpec `json:"tiflashReplica,omitempty"`
+}
+
+// RestoreMode represents the restore mode, such as snapshot or pitr.
+// +k8s:openapi-gen=true
+type RestoreMode string
+
+const (
+	// RestoreModeSnapshot represents restoring the snapshot backup of tidb cluster.
+	RestoreModeSnapshot RestoreMode = "snapshot"
+	// RestoreModePiTR represents restoring tidb cluster to a point in time with the snapshot backup and the log backup.
+	RestoreModePiTR RestoreMode = "pitr"
+	// RestoreModeVolumeSnapshot represents restoring the PVCs of tidb cluster from the CSI VolumeSnapshots of a Backup.
+	RestoreModeVolumeSnapshot RestoreMode = "volume-snapshot"
+)
+
+// +k8s:openapi-gen=true
+// PiTRRestoreSpec contains the specification of a point-in-time restore
+type PiTRRestoreSpec struct {
+	// LogBackup is the name of the Backup in the namespace of the Restore, whose storage is where the
+	// log backup task of the source cluster writes to. The storage is accessed with the credentials
+	// of the storage of the Restore, so the storage type of them should be the same.
+	LogBackup string `json:"logBackup"`
+
+	// RestoredTs is the point in time to restore the cluster to, in the format of a TSO,
+	// e.g. `434319917412892673`, or a RFC3339 datetime, e.g. `2022-07-15T19:00:00+08:00`.
+	// It should be within the window of the log backup and not earlier than the commit ts of the snapshot backup.
+	RestoredTs string `json:"restoredTs"`
+}
+
+// +k8s:openapi-gen=true
+// ImportWindowSpec contains the items tuned while data is imported into the cluster
+type ImportWindowSpec struct {
+	// PDSchedule overrides the schedule config items of PD, e.g. `region-schedule-limit: "0"`
+	// +optional
+	PDSchedule map[string]string `json:"pdSchedule,omitempty"`
+
+	// StoreLimit is the add-peer and remove-peer limit of all the TiKV and TiFlash stores,
+	// in operators per minute
+	// +optional
+	StoreLimit *float64 `json:"storeLimit,omitempty"`
+
+	// TiKVConfig overrides the config items of TiKV online, the keys are dotted paths,
+	// e.g. `import.num-threads: "16"`
+	// +optional
+	TiKVConfig map[string]string `json:"tikvConfig,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// RestoreStatisticsSpec configures how the statistics of the restored tables are restored.
+// The statistics in the backup, i.e. the backup taken with `br.ignoreStats: false`, are loaded by BR
+// during the restore, this post-step covers the tables restored without statistics.
+type RestoreStatisticsSpec struct {
+	// Analyze runs `ANALYZE TABLE` for the restored tables without statistics after the data is
+	// restored, the Restore is in the AnalyzingStatistics phase meanwhile. It requires `spec.to`.
+	// The failure of analyzing doesn't fail the Restore, but is noted in the Complete condition.
+	// +optional
+	Analyze bool `json:"analyze,omitempty"`
+
+	// Concurrency is the number of tables analyzed concurrently
+	// Optional: Defaults to 1
+	// +optional
+	Concurrency *int32 `json:"concurrency,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// RestoreTiFlashReplicaSpec configures how the TiFlash replicas of the restored tables are set.
+// The tables are altered by `ALTER TABLE ... SET TIFLASH REPLICA` after the data is restored, the Restore
+// is in the SettingTiFlashReplicas phase until the replicas are available. It requires `spec.to`.
+// The failure of setting the replicas doesn't fail the Restore, but is noted in the Complete condition.
+type RestoreTiFlashReplicaSpec struct {
+	// Tables declares the TiFlash replicas of the restored tables
+	Tables []TiFlashReplicaTable `json:"tables"`
+
+	// WaitTimeout is the max time to wait for the TiFlash replicas to be available, e.g. `30m`
+	// Optional: Defaults to 1h
+	// +optional
+	WaitTimeout string `json:"waitTimeout,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// TiFlashReplicaTable declares the TiFlash replicas of a table, or of all the tables of a database
+type TiFlashReplicaTable struct {
+	// DB is the database of the tables
+	DB string `json:"db"`
+
+	// Table is the table, all the tables of the database are set if it's empty
+	// +optional
+	Table string `json:"table,omitempty"`
+
+	// Replicas is the number of the TiFlash replicas, 0 removes the TiFlash replicas
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
 }
 
 // RestoreStatus represents the current status of a tidb cluster restore.
@@ -1875,10 +3956,65 @@ type RestoreStatus struct {
 	CommitTs string `json:"commitTs,omitempty"`
 	// Phase is a user readable state inferred from the underlying Restore conditions
 	Phase RestoreConditionType `json:"phase,omitempty"`
+	// ImportWindow records the original values of the items tuned by spec.importWindow,
+	// so that they can be restored after the restore finishes
+	// +optional
+	ImportWindow *ImportWindowStatus `json:"importWindow,omitempty"`
+	// Progresses is the progress of the steps of the restore reported by BR,
+	// e.g. the snapshot restore and the log restore of a point-in-time restore
+	// +nullable
+	// +optional
+	Progresses []RestoreProgress `json:"progresses,omitempty"`
 	// +nullable
 	Conditions []RestoreCondition `json:"conditions,omitempty"`
 }
 
+// RestoreProgress is the progress of a step of the restore
+type RestoreProgress struct {
+	// Step is the name of the step reported by BR, e.g. `Full Restore`
+	Step string `json:"step"`
+	// Progress is the percentage of the step, from 0 to 100
+	Progress float64 `json:"progress"`
+	// LastTransitionTime is the time at which the progress was updated
+	// +nullable
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// EstimatedCompletionTime is the time at which the step is estimated to complete by the remaining time reported by BR
+	// +nullable
+	// +optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+}
+
+// ImportWindowState is the state of the import window of a restore
+type ImportWindowState string
+
+const (
+	// ImportWindowApplied means the items are tuned and not restored yet
+	ImportWindowApplied ImportWindowState = "Applied"
+	// ImportWindowRestored means the original values of the items have been restored
+	ImportWindowRestored ImportWindowState = "Restored"
+)
+
+// ImportWindowStatus is the original values of the items tuned by the import window
+type ImportWindowStatus struct {
+	// State is the state of the import window
+	State ImportWindowState `json:"state"`
+	// PDSchedule is the original schedule config items of PD
+	// +optional
+	PDSchedule map[string]string `json:"pdSchedule,omitempty"`
+	// StoreLimits is the original store limits by the store ID
+	// +optional
+	StoreLimits map[string]ImportWindowStoreLimit `json:"storeLimits,omitempty"`
+	// TiKVConfig is the original config items of TiKV
+	// +optional
+	TiKVConfig map[string]string `json:"tikvConfig,omitempty"`
+}
+
+// ImportWindowStoreLimit is the original store limits of a store, in operators per minute
+type ImportWindowStoreLimit struct {
+	AddPeer    float64 `json:"addPeer"`
+	RemovePeer float64 `json:"removePeer"`
+}
+
 // +k8s:openapi-gen=true
 // IngressSpec describe the ingress desired state for the target component
 type IngressSpec struct {
@@ -1904,15 +4040,19 @@ type IngressSpec struct {
 //
 // +k8s:openapi-gen=true
 // +kubebuilder:resource:shortName="dc"
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`,description="The version of the dm cluster"
 // +kubebuilder:printcolumn:name="Master",type=string,JSONPath=`.status.master.image`,description="The image for dm-master cluster"
 // +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.master.storageSize`,description="The storage size specified for dm-master node"
 // +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.master.statefulSet.readyReplicas`,description="The ready replicas number of dm-master cluster"
 // +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.master.replicas`,description="The desired replicas number of dm-master cluster"
-// +kubebuilder:printcolumn:name="Worker",type=string,JSONPath=`.status.worker.image`,description="The image for dm-master cluster"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.master.phase`,description="The phase of dm-master cluster",priority=1
+// +kubebuilder:printcolumn:name="Worker",type=string,JSONPath=`.status.worker.image`,description="The image for dm-worker cluster"
 // +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.worker.storageSize`,description="The storage size specified for dm-worker node"
-// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.worker.statefulSet.readyReplicas`,description="The ready replicas number of dm-worker cluster"
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.worker.statefulSet.readyReplicas`,description="The ready replicas number of dm-worker cluster"
 // +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.worker.replicas`,description="The desired replicas number of dm-worker cluster"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.worker.phase`,description="The phase of dm-worker cluster",priority=1
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type DMCluster struct {
@@ -1965,11 +4105,39 @@ type DMClusterSpec struct {
 	// +optional
 	Worker *WorkerSpec `json:"worker,omitempty"`
 
+	// Cluster is the DMCluster whose dm-masters the dm-workers of this DMCluster join, if configured,
+	// no dm-master is deployed for this DMCluster and the referenced DMCluster may be in another namespace.
+	// The TLS and the port of dm-master must be the same as the referenced DMCluster.
+	// +optional
+	Cluster *TidbClusterRef `json:"cluster,omitempty"`
+
+	// ClusterDomain is the Kubernetes Cluster Domain of the DM cluster, if configured, the dm-masters and
+	// dm-workers advertise their FQDN addresses with it, e.g. `demo-dm-master-0.demo-dm-master-peer.dm.svc.cluster-a.local`,
+	// so that they are reachable from the members in other Kubernetes clusters.
+	// It can't be changed for an existing cluster.
+	// Optional: Defaults to ""
+	// +optional
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+
 	// Indicates that the dm cluster is paused and will not be processed by
 	// the controller.
 	// +optional
 	Paused bool `json:"paused,omitempty"`
 
+	// Suspend scales the dm-master and dm-worker StatefulSets down to zero while keeping the PVCs and
+	// the status of the members, the replicas before the suspension are restored once it's unset.
+	// Unlike Paused, the dm cluster is still processed by the controller.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// UpgradeTaskCheckPolicy is the policy of the check of the task stages before upgrading dm-master and dm-worker.
+	// The dump of a subtask starts over if it's interrupted, so with Block the upgrade is blocked while any subtask
+	// is in the full migration phase, i.e. running the dump or load unit, and the UpgradeBlocked condition of the
+	// component describes the blocking subtasks. With Warn, only a warning event is recorded.
+	// Optional: Defaults to Block
+	// +optional
+	UpgradeTaskCheckPolicy DMUpgradeTaskCheckPolicy `json:"upgradeTaskCheckPolicy,omitempty"`
+
 	// dm cluster version
 	// +optional
 	Version string `json:"version"`
@@ -1991,6 +4159,17 @@ type DMClusterSpec struct {
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
+	// ClusterRegistryPrefix rewrites the registry of all the images used by the dm cluster,
+	// including the dm-master, dm-worker and discovery images. E.g. with `registry.local/mirror`,
+	// `pingcap/dm:v2.0.7` is pulled from `registry.local/mirror/pingcap/dm:v2.0.7`,
+	// which is useful for air-gapped environments
+	// +optional
+	ClusterRegistryPrefix string `json:"clusterRegistryPrefix,omitempty"`
+
+	// Helper spec, the helper image is used by the sidecars and init containers of DM cluster Pods
+	// +optional
+	Helper *HelperSpec `json:"helper,omitempty"`
+
 	// Whether enable PVC reclaim for orphan PVC left by statefulset scale-in
 	// Optional: Defaults to false
 	// +optional
@@ -2039,6 +4218,12 @@ type DMClusterSpec struct {
 	// +optional
 	Timezone string `json:"timezone,omitempty"`
 
+	// MountTimezoneData mounts the time zone database of the node, i.e. /usr/share/zoneinfo, to the
+	// DM cluster containers read-only, which is required if the images don't contain the database
+	// Optional: Defaults to false
+	// +optional
+	MountTimezoneData *bool `json:"mountTimezoneData,omitempty"`
+
 	// Base tolerations of DM cluster Pods, components may add more tolerations upon this respectively
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
@@ -2055,6 +4240,33 @@ type DMClusterSpec struct {
 	// +listType=map
 	// +listMapKey=topologyKey
 	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Architecture is the CPU architecture of the nodes that the Pods are scheduled to,
+	// a required node affinity on the `kubernetes.io/arch` label is generated for it.
+	// Can be overrode by architecture in master spec or worker spec
+	// Optional: Defaults to no architecture constraint
+	// +optional
+	Architecture Architecture `json:"architecture,omitempty"`
+
+	// StatefulSetUpdateStrategy of DM cluster StatefulSets
+	// +optional
+	StatefulSetUpdateStrategy apps.StatefulSetUpdateStrategyType `json:"statefulSetUpdateStrategy,omitempty"`
+
+	// PodManagementPolicy of DM cluster StatefulSets
+	// +optional
+	PodManagementPolicy apps.PodManagementPolicyType `json:"podManagementPolicy,omitempty"`
+
+	// StartScriptVersion is the version of the start scripts, changing it causes a rolling update.
+	// Components may override it to adopt the new start scripts gradually.
+	// Optional: Defaults to v1
+	// +optional
+	StartScriptVersion StartScriptVersion `json:"startScriptVersion,omitempty"`
+
+	// FeatureGates enables or disables the experimental behaviors of the operator for this cluster,
+	// the gates set here take precedence over the operator-wide settings, so that a feature can be
+	// canaried on a single cluster. Unknown gates are ignored.
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
 }
 
 // DMClusterStatus represents the current status of a dm cluster.
@@ -2104,6 +4316,10 @@ type MasterSpec struct {
 	// +optional
 	StorageSize string `json:"storageSize,omitempty"`
 
+	// StorageVolumes configure additional storage for dm-master pods.
+	// +optional
+	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
+
 	// Subdirectory within the volume to store dm-master Data. By default, the data
 	// is stored in the root directory of volume which is mounted at
 	// /var/lib/dm-master.
@@ -2118,6 +4334,35 @@ type MasterSpec struct {
 	// Config is the Configuration of dm-master-servers
 	// +optional
 	Config *MasterConfig `json:"config,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget of the dm-master pods.
+	// No PodDisruptionBudget is created if it's not set.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// Port is the port that dm-master serves the client requests on, it's used by the services and dm-workers.
+	// It can't be changed for an existing cluster.
+	// Optional: Defaults to 8261
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// PeerPort is the port that dm-master members communicate with each other on.
+	// It can't be changed for an existing cluster.
+	// Optional: Defaults to 8291
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	PeerPort *int32 `json:"peerPort,omitempty"`
+
+	// ExternalMembers are the client URLs of the dm-masters deployed in other Kubernetes clusters,
+	// e.g. `http://demo-dm-master-0.demo-dm-master-peer.dm.svc.cluster-a.local:8261`. If configured,
+	// the dm-masters of this DMCluster join them instead of bootstrapping a new cluster, so that
+	// the dm-masters of the DMClusters form a single raft group. The names of the DMClusters must be
+	// different, as the names of the members are the names of the Pods.
+	// +optional
+	ExternalMembers []string `json:"externalMembers,omitempty"`
 }
 
 type MasterServiceSpec struct {
@@ -2164,6 +4409,10 @@ type WorkerSpec struct {
 	// +optional
 	StorageSize string `json:"storageSize,omitempty"`
 
+	// StorageVolumes configure additional storage for dm-worker pods.
+	// +optional
+	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
+
 	// Subdirectory within the volume to store dm-worker Data. By default, the data
 	// is stored in the root directory of volume which is mounted at
 	// /var/lib/dm-worker.
@@ -2182,6 +4431,90 @@ type WorkerSpec struct {
 	// RecoverFailover indicates that Operator can recover the failover Pods
 	// +optional
 	RecoverFailover bool `json:"recoverFailover,omitempty"`
+
+	// SourcePlacements are the placement hints of the upstream sources. The operator transfers a source
+	// to a free dm-worker running on the matching nodes when the dm-worker bound to it doesn't match,
+	// e.g. after the dm-worker is rescheduled. Use the affinity or the topology spread constraints to
+	// make the dm-workers run on the matching nodes.
+	// +optional
+	SourcePlacements []DMSourcePlacement `json:"sourcePlacements,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget of the dm-worker pods.
+	// No PodDisruptionBudget is created if it's not set.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// RelayLogStorage configures a sidecar uploading the relay logs of dm-worker to the remote storage
+	// periodically, so that the relay logs can be removed from the local volume after they are uploaded.
+	// Changing it causes a rolling update of dm-worker.
+	// +optional
+	RelayLogStorage *RelayLogStorageSpec `json:"relayLogStorage,omitempty"`
+}
+
+// RelayLogStorageSpec configures the upload of the relay logs of dm-worker to the remote storage.
+// The relay logs of each dm-worker are uploaded to the path `<prefix>/<namespace>/<pod name>/` of the storage.
+// +k8s:openapi-gen=true
+type RelayLogStorageSpec struct {
+	// Image of the sidecar, rclone is required to upload to S3 or GCS, e.g. the image of tidb-backup-manager
+	Image string `json:"image"`
+
+	// Resources of the sidecar
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// RelayDir is the directory of the relay logs, it must be the `relay-dir` of the sources and in the data
+	// volume of dm-worker mounted at /var/lib/dm-worker.
+	// Optional: Defaults to relay_log in the data directory of dm-worker
+	// +optional
+	RelayDir string `json:"relayDir,omitempty"`
+
+	// Interval is the interval between two uploads, in the format of Go Duration.
+	// Optional: Defaults to 5m
+	// +optional
+	Interval *string `json:"interval,omitempty"`
+
+	// LocalRetention removes the relay log files from the local volume after they are uploaded and have not been
+	// modified for the duration, in the format of Go Duration. It must be longer than the time the subtasks take
+	// to replicate the relay logs, as dm-worker can not read them from the remote storage.
+	// Optional: Defaults to nil, which means the relay logs are not removed from the local volume by the sidecar
+	// +optional
+	LocalRetention *string `json:"localRetention,omitempty"`
+
+	// RemoteRetention removes the relay log files that have not been modified for the duration from the remote
+	// storage, in the format of Go Duration.
+	// Optional: Defaults to nil, which means the relay logs are retained in the remote storage
+	// +optional
+	RemoteRetention *string `json:"remoteRetention,omitempty"`
+
+	// StorageProvider configures where the relay logs are stored
+	StorageProvider `json:",inline"`
+}
+
+// DMUpgradeTaskCheckPolicy is the policy of the check of the task stages before upgrading dm-master and dm-worker
+// +kubebuilder:validation:Enum=Block;Warn
+type DMUpgradeTaskCheckPolicy string
+
+const (
+	// DMUpgradeTaskCheckBlock blocks the upgrade while any subtask is in the full migration phase
+	DMUpgradeTaskCheckBlock DMUpgradeTaskCheckPolicy = "Block"
+	// DMUpgradeTaskCheckWarn records a warning event and upgrades anyway
+	DMUpgradeTaskCheckWarn DMUpgradeTaskCheckPolicy = "Warn"
+)
+
+// DMSourcePlacement is the placement hint of the dm-worker bound to an upstream source, so that the
+// dm-worker can run close to the upstream network-wise
+type DMSourcePlacement struct {
+	// Source is the name of the upstream source
+	Source string `json:"source"`
+
+	// NodeSelector requires the dm-worker bound to the source to run on the nodes with the labels
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Zone requires the dm-worker bound to the source to run on the nodes in the zone,
+	// the zone of a node is read from its topology.kubernetes.io/zone label
+	// +optional
+	Zone string `json:"zone,omitempty"`
 }
 
 // DMClusterCondition is dm cluster condition
@@ -2219,14 +4552,23 @@ const (
 
 // MasterStatus is dm-master status
 type MasterStatus struct {
-	Synced          bool                           `json:"synced,omitempty"`
-	Phase           MemberPhase                    `json:"phase,omitempty"`
-	StatefulSet     *apps.StatefulSetStatus        `json:"statefulSet,omitempty"`
-	Members         map[string]MasterMember        `json:"members,omitempty"`
+	Synced      bool                    `json:"synced,omitempty"`
+	Phase       MemberPhase             `json:"phase,omitempty"`
+	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
+	Members     map[string]MasterMember `json:"members,omitempty"`
+	// PeerMembers contains the dm-masters NOT in current DMCluster, e.g. the dm-masters of the
+	// DMClusters in other Kubernetes clusters joined by the externalMembers
+	PeerMembers     map[string]MasterMember        `json:"peerMembers,omitempty"`
 	Leader          MasterMember                   `json:"leader,omitempty"`
 	FailureMembers  map[string]MasterFailureMember `json:"failureMembers,omitempty"`
 	UnjoinedMembers map[string]UnjoinedMember      `json:"unjoinedMembers,omitempty"`
 	Image           string                         `json:"image,omitempty"`
+	// StartScriptVersion is the version of the start script in use by the StatefulSet
+	StartScriptVersion StartScriptVersion `json:"startScriptVersion,omitempty"`
+	// Volumes contains the status of the volumes of this component, keyed by volume name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the conditions of this component, e.g. ComponentVolumeResizing
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // MasterMember is dm-master member status
@@ -2260,6 +4602,12 @@ type WorkerStatus struct {
 	Members        map[string]WorkerMember        `json:"members,omitempty"`
 	FailureMembers map[string]WorkerFailureMember `json:"failureMembers,omitempty"`
 	Image          string                         `json:"image,omitempty"`
+	// StartScriptVersion is the version of the start script in use by the StatefulSet
+	StartScriptVersion StartScriptVersion `json:"startScriptVersion,omitempty"`
+	// Volumes contains the status of the volumes of this component, keyed by volume name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the conditions of this component, e.g. ComponentVolumeResizing
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // WorkerMember is dm-worker member status
@@ -2291,6 +4639,66 @@ type StorageVolume struct {
 	MountPath        string  `json:"mountPath,omitempty"`
 }
 
+// StorageVolumeStatus is the observed status of the volumes created from a PVC template
+type StorageVolumeStatus struct {
+	// Count is the count of volumes
+	Count int `json:"count"`
+	// BoundCount is the count of bound volumes
+	BoundCount int `json:"boundCount"`
+	// ResizedCount is the count of volumes whose file system has been expanded to ResizedCapacity
+	ResizedCount int `json:"resizedCount"`
+	// CurrentCapacity is the smallest actual capacity among the volumes
+	CurrentCapacity resource.Quantity `json:"currentCapacity"`
+	// ResizedCapacity is the desired capacity of the volumes
+	ResizedCapacity resource.Quantity `json:"resizedCapacity"`
+}
+
+const (
+	// ComponentVolumeResizing indicates that some volumes of the component are being resized
+	ComponentVolumeResizing = "ComponentVolumeResizing"
+	// ComponentVolumeResizeBlocked indicates that some volumes of the component can't be resized, e.g. the
+	// storage class does not support volume expansion, the message lists the blocked PVCs
+	ComponentVolumeResizeBlocked = "VolumeResizeBlocked"
+	// ComponentProgressDeadlineExceeded indicates that the rolling update of the component is paused
+	// as no Pod completed its update within the progress deadline
+	ComponentProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+	// ComponentUpgradeBlocked indicates that the upgrade of the component is blocked, e.g. the dm-master and
+	// dm-worker are not upgraded while any subtask is in the full migration phase
+	ComponentUpgradeBlocked = "UpgradeBlocked"
+)
+
+// RollingUpdateStatus is the progress of the rolling update of a component
+type RollingUpdateStatus struct {
+	// UpdateRevision is the revision of the StatefulSet being rolled out
+	UpdateRevision string `json:"updateRevision"`
+	// Partition is the last observed partition of the StatefulSet, which is lowered each time a Pod
+	// completes its update
+	Partition int32 `json:"partition"`
+	// LastProgressTime is the last time a Pod completed its update, or the time the rolling update started
+	// +nullable
+	LastProgressTime metav1.Time `json:"lastProgressTime,omitempty"`
+}
+
+// ResourceResizeRestartPolicy is the restart policy of a resource when it's resized in place
+type ResourceResizeRestartPolicy string
+
+const (
+	// ResourceResizeNotRequired means the resource is resized without restarting the container
+	ResourceResizeNotRequired ResourceResizeRestartPolicy = "NotRequired"
+	// ResourceResizeRestartContainer means the container must be restarted to resize the resource
+	ResourceResizeRestartContainer ResourceResizeRestartPolicy = "RestartContainer"
+)
+
+// ContainerResizePolicy is the restart policy of a resource of the container when it's resized in place
+type ContainerResizePolicy struct {
+	// ResourceName is the name of the resource, `cpu` or `memory`
+	// +kubebuilder:validation:Enum=cpu;memory
+	ResourceName corev1.ResourceName `json:"resourceName"`
+	// RestartPolicy is the restart policy of the resource, `NotRequired` or `RestartContainer`
+	// +kubebuilder:validation:Enum=NotRequired;RestartContainer
+	RestartPolicy ResourceResizeRestartPolicy `json:"restartPolicy"`
+}
+
 // TopologySpreadConstraint specifies how to spread matching pods among the given topology.
 // It is a minimal version of corev1.TopologySpreadConstraint to avoid to add too many fields of API
 // Refer to https://kubernetes.io/docs/concepts/workloads/pods/pod-topology-spread-constraints
@@ -0,0 +1,248 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// componentConfig is the config of a component and the typed config struct the keys are checked against
+type componentConfig struct {
+	path  *field.Path
+	new   *config.GenericConfig
+	old   *config.GenericConfig
+	typed interface{}
+}
+
+// ValidateTidbClusterConfig validates the configs of PD, TiKV, TiDB and TiFlash against the typed configs, the
+// unknown keys and the values of wrong types are rejected before the components are rolling restarted with them.
+// Only the configs changed from the old TidbCluster are validated, so that the existing clusters are not blocked,
+// and the validation is skipped if the annotation tidb.pingcap.com/skip-config-validation is "true". old is nil on
+// creation.
+func ValidateTidbClusterConfig(old, tc *v1alpha1.TidbCluster) field.ErrorList {
+	if tc.Annotations[label.AnnSkipConfigValidation] == "true" {
+		return nil
+	}
+	oldSpec := v1alpha1.TidbClusterSpec{}
+	if old != nil {
+		oldSpec = old.Spec
+	}
+	spec := tc.Spec
+	path := field.NewPath("spec")
+	var configs []componentConfig
+	if spec.PD != nil && spec.PD.Config != nil {
+		c := componentConfig{path: path.Child("pd", "config"), new: spec.PD.Config.GenericConfig, typed: v1alpha1.PDConfig{}}
+		if oldSpec.PD != nil && oldSpec.PD.Config != nil {
+			c.old = oldSpec.PD.Config.GenericConfig
+		}
+		configs = append(configs, c)
+	}
+	if spec.TiKV != nil && spec.TiKV.Config != nil {
+		c := componentConfig{path: path.Child("tikv", "config"), new: spec.TiKV.Config.GenericConfig, typed: v1alpha1.TiKVConfig{}}
+		if oldSpec.TiKV != nil && oldSpec.TiKV.Config != nil {
+			c.old = oldSpec.TiKV.Config.GenericConfig
+		}
+		configs = append(configs, c)
+	}
+	if spec.TiDB != nil && spec.TiDB.Config != nil {
+		c := componentConfig{path: path.Child("tidb", "config"), new: spec.TiDB.Config.GenericConfig, typed: v1alpha1.TiDBConfig{}}
+		if oldSpec.TiDB != nil && oldSpec.TiDB.Config != nil {
+			c.old = oldSpec.TiDB.Config.GenericConfig
+		}
+		configs = append(configs, c)
+	}
+	if spec.TiFlash != nil && spec.TiFlash.Config != nil {
+		var oldConfig *v1alpha1.TiFlashConfigWraper
+		if oldSpec.TiFlash != nil {
+			oldConfig = oldSpec.TiFlash.Config
+		}
+		if common := spec.TiFlash.Config.Common; common != nil {
+			c := componentConfig{path: path.Child("tiflash", "config", "config"), new: common.GenericConfig, typed: v1alpha1.CommonConfig{}}
+			if oldConfig != nil && oldConfig.Common != nil {
+				c.old = oldConfig.Common.GenericConfig
+			}
+			configs = append(configs, c)
+		}
+		if proxy := spec.TiFlash.Config.Proxy; proxy != nil {
+			c := componentConfig{path: path.Child("tiflash", "config", "proxy"), new: proxy.GenericConfig, typed: v1alpha1.ProxyConfig{}}
+			if oldConfig != nil && oldConfig.Proxy != nil {
+				c.old = oldConfig.Proxy.GenericConfig
+			}
+			configs = append(configs, c)
+		}
+	}
+
+	allErrs := field.ErrorList{}
+	for _, c := range configs {
+		if c.new == nil || (c.old != nil && reflect.DeepEqual(c.new.Inner(), c.old.Inner())) {
+			continue
+		}
+		allErrs = append(allErrs, validateConfigKeys(c.new.Inner(), reflect.TypeOf(c.typed), c.path)...)
+	}
+	return allErrs
+}
+
+// TidbClusterConfigWarnings returns the warnings of the config items which are overridden by the operator
+func TidbClusterConfigWarnings(tc *v1alpha1.TidbCluster) []string {
+	var warnings []string
+	warn := func(path string, conf *config.GenericConfig, reason string, keys ...string) {
+		if conf == nil {
+			return
+		}
+		for _, key := range keys {
+			if conf.Get(key) != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %s is overridden by the operator as %s", path, key, reason))
+			}
+		}
+	}
+
+	tlsCluster := "spec.tlsCluster is enabled"
+	if tc.Spec.PD != nil && tc.Spec.PD.Config != nil {
+		if tc.IsTLSClusterEnabled() {
+			warn("spec.pd.config", tc.Spec.PD.Config.GenericConfig, tlsCluster, "security.cacert-path", "security.cert-path", "security.key-path")
+		}
+		if tc.Spec.PD.EnableDashboardInternalProxy != nil {
+			warn("spec.pd.config", tc.Spec.PD.Config.GenericConfig, "spec.pd.enableDashboardInternalProxy is set", "dashboard.internal-proxy")
+		}
+	}
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.Config != nil && tc.IsTLSClusterEnabled() {
+		warn("spec.tikv.config", tc.Spec.TiKV.Config.GenericConfig, tlsCluster, "security.ca-path", "security.cert-path", "security.key-path")
+	}
+	if tidb := tc.Spec.TiDB; tidb != nil && tidb.Config != nil {
+		if tc.IsTLSClusterEnabled() {
+			warn("spec.tidb.config", tidb.Config.GenericConfig, tlsCluster, "security.cluster-ssl-ca", "security.cluster-ssl-cert", "security.cluster-ssl-key")
+		}
+		if tidb.IsTLSClientEnabled() {
+			warn("spec.tidb.config", tidb.Config.GenericConfig, "spec.tidb.tlsClient is enabled", "security.ssl-ca", "security.ssl-cert", "security.ssl-key")
+		}
+		if tidb.Port != nil {
+			warn("spec.tidb.config", tidb.Config.GenericConfig, "spec.tidb.port is set", "port")
+		}
+		if tidb.StatusPort != nil {
+			warn("spec.tidb.config", tidb.Config.GenericConfig, "spec.tidb.statusPort is set", "status.status-port")
+		}
+	}
+	if tc.Spec.TiFlash != nil && tc.Spec.TiFlash.Config != nil && tc.IsTLSClusterEnabled() {
+		if common := tc.Spec.TiFlash.Config.Common; common != nil {
+			warn("spec.tiflash.config.config", common.GenericConfig, tlsCluster, "security.ca_path", "security.cert_path", "security.key_path")
+		}
+		if proxy := tc.Spec.TiFlash.Config.Proxy; proxy != nil {
+			warn("spec.tiflash.config.proxy", proxy.GenericConfig, tlsCluster, "security.ca-path", "security.cert-path", "security.key-path")
+		}
+	}
+	return warnings
+}
+
+// DMClusterConfigWarnings returns the warnings of the config items which are overridden by the operator. The configs
+// of dm-master and dm-worker are typed, so the unknown keys and the values of wrong types are already rejected or
+// pruned by the schema of the CRD.
+func DMClusterConfigWarnings(dc *v1alpha1.DMCluster) []string {
+	if !dc.IsTLSClusterEnabled() {
+		return nil
+	}
+	var warnings []string
+	warn := func(path string, security v1alpha1.DMSecurityConfig) {
+		for key, value := range map[string]*string{"ssl-ca": security.SSLCA, "ssl-cert": security.SSLCert, "ssl-key": security.SSLKey} {
+			if value != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %s is overridden by the operator as spec.tlsCluster is enabled", path, key))
+			}
+		}
+	}
+	if dc.Spec.Master.Config != nil {
+		warn("spec.master.config", dc.Spec.Master.Config.DMSecurityConfig)
+	}
+	if dc.Spec.Worker != nil && dc.Spec.Worker.Config != nil {
+		warn("spec.worker.config", dc.Spec.Worker.Config.DMSecurityConfig)
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// validateConfigKeys checks the keys of the config against the json names of the fields of the typed config, the
+// values of the maps and the interfaces are not checked as they are free-form.
+func validateConfigKeys(conf map[string]interface{}, typ reflect.Type, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fields := map[string]reflect.Type{}
+	collectConfigFields(typ, fields)
+
+	keys := make([]string, 0, len(conf))
+	for key := range conf {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := conf[key]
+		keyPath := path.Key(key)
+		fieldType, ok := fields[key]
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(keyPath, key, fmt.Sprintf("unknown config key, set annotation %s to \"true\" to skip the validation if it's supported by the version", label.AnnSkipConfigValidation)))
+			continue
+		}
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		switch fieldType.Kind() {
+		case reflect.Map, reflect.Interface:
+			continue
+		case reflect.Struct:
+			if m, ok := value.(map[string]interface{}); ok {
+				allErrs = append(allErrs, validateConfigKeys(m, fieldType, keyPath)...)
+				continue
+			}
+		}
+		// the value is checked by decoding it into the field
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(data, reflect.New(fieldType).Interface())
+		}
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(keyPath, value, fmt.Sprintf("must be of type %s", fieldType)))
+		}
+	}
+	return allErrs
+}
+
+// collectConfigFields collects the fields of the struct by their json names, the inlined fields are collected too
+func collectConfigFields(typ reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous {
+			t := f.Type
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Struct {
+				collectConfigFields(t, fields)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +33,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	utilnet "k8s.io/utils/net"
@@ -46,6 +50,8 @@ func ValidateTidbCluster(tc *v1alpha1.TidbCluster) field.ErrorList {
 	allErrs = append(allErrs, validateAnnotations(tc.ObjectMeta.Annotations, fldPath.Child("annotations"))...)
 	// validate spec
 	allErrs = append(allErrs, validateTiDBClusterSpec(&tc.Spec, field.NewPath("spec"))...)
+	// validate delete slots against replicas
+	allErrs = append(allErrs, validateTidbClusterDeleteSlots(tc)...)
 	return allErrs
 }
 
@@ -59,6 +65,33 @@ func ValidateDMCluster(dc *v1alpha1.DMCluster) field.ErrorList {
 	allErrs = append(allErrs, validateDMAnnotations(dc.ObjectMeta.Annotations, fldPath.Child("annotations"))...)
 	// validate spec
 	allErrs = append(allErrs, validateDMClusterSpec(&dc.Spec, field.NewPath("spec"))...)
+	if dc.Spec.Cluster != nil {
+		allErrs = append(allErrs, validateDMClusterRef(dc, field.NewPath("spec"))...)
+	}
+	return allErrs
+}
+
+// validateDMClusterRef validates the DMCluster whose dm-workers join the dm-masters of the referenced DMCluster
+func validateDMClusterRef(dc *v1alpha1.DMCluster, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	ref := dc.Spec.Cluster
+	if ref.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("cluster", "name"), "name of the referenced dm cluster must not be empty"))
+		return allErrs
+	}
+	if ref.ClusterDomain != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("cluster", "clusterDomain"), "clusterDomain is not supported for dm cluster"))
+	}
+	if ns, name := dc.MasterCluster(); ns == dc.Namespace && name == dc.Name {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cluster"), ref.Name, "dm cluster can't reference itself"))
+	}
+	if dc.Spec.Master.Replicas != 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("master", "replicas"), dc.Spec.Master.Replicas,
+			"dm-master can't be deployed if the dm-workers join the dm-masters of the referenced dm cluster"))
+	}
+	if dc.Spec.Worker == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("worker"), "dm-worker must be deployed if the dm cluster references another dm cluster"))
+	}
 	return allErrs
 }
 
@@ -84,6 +117,340 @@ func ValidateTidbMonitor(monitor *v1alpha1.TidbMonitor) field.ErrorList {
 	if monitor.Spec.Persistent {
 		allErrs = append(allErrs, validateStorageInfo(monitor.Spec.Storage, field.NewPath("spec"))...)
 	}
+	if len(monitor.Spec.SilenceWindows) > 0 {
+		if monitor.Spec.AlertmanagerURL == nil || *monitor.Spec.AlertmanagerURL == "" {
+			allErrs = append(allErrs, field.Required(field.NewPath("spec", "alertmanagerURL"), "alertmanagerURL must be set to create the silences"))
+		}
+		allErrs = append(allErrs, validateSilenceWindows(monitor.Spec.SilenceWindows, field.NewPath("spec", "silenceWindows"))...)
+	}
+	allErrs = append(allErrs, validateRemoteWrites(monitor.Spec.Prometheus.RemoteWrite, field.NewPath("spec", "prometheus", "remoteWrite"))...)
+	allErrs = append(allErrs, validateScrapeConfigs(monitor.Spec.Prometheus.ScrapeConfigs, field.NewPath("spec", "prometheus", "scrapeConfigs"))...)
+	allErrs = append(allErrs, validateMonitorReplicas(&monitor.Spec, field.NewPath("spec"))...)
+	return allErrs
+}
+
+// validateMonitorReplicas validates that the replicas of the monitor are distinguished by the replica external label,
+// otherwise the series and the alerts of the replicas can't be deduplicated
+func validateMonitorReplicas(spec *v1alpha1.TidbMonitorSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.ReplicaExternalLabelName == nil || *spec.ReplicaExternalLabelName != "" {
+		return allErrs
+	}
+	if spec.Replicas != nil && *spec.Replicas > 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicaExternalLabelName"), "", "must not be empty if replicas is greater than 1"))
+	}
+	if spec.Thanos != nil && spec.Thanos.Query != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicaExternalLabelName"), "", "must not be empty if the Thanos Query is deployed"))
+	}
+	return allErrs
+}
+
+var (
+	// opsCommandReadOnlyPaths are the read-only subcommand paths allowed to be run by the OpsCommands of each type,
+	// <id> matches a number and <arg> matches any argument
+	opsCommandReadOnlyPaths = map[v1alpha1.OpsCommandType][]string{
+		v1alpha1.OpsCommandTypePDCtl: {
+			"cluster",
+			"config show", "config show <arg>",
+			"health",
+			"hot read", "hot write", "hot store",
+			"label", "label store <arg>", "label store <arg> <arg>",
+			"member", "member leader show",
+			"operator show", "operator show <arg>", "operator check <id>",
+			"region", "region <id>", "region key <arg>", "region sibling <id>", "region store <id>",
+			"region topread", "region topread <id>", "region topwrite", "region topwrite <id>",
+			"region topconfver", "region topconfver <id>", "region topversion", "region topversion <id>",
+			"region topsize", "region topsize <id>", "region check <arg>",
+			"scheduler show", "scheduler config <arg>",
+			"store", "store <id>", "store limit",
+			"tso <id>",
+		},
+		v1alpha1.OpsCommandTypeDMCtl: {
+			"check-task <arg>",
+			"get-config <arg> <arg>",
+			"list-member",
+			"query-status", "query-status <arg>",
+			"show-ddl-locks", "show-ddl-locks <arg>",
+		},
+		v1alpha1.OpsCommandTypeBRDebug: {
+			"backupmeta validate",
+			"checksum",
+			"decode",
+			"encode",
+			"search-sst",
+		},
+	}
+	// opsCommandValueFlags are the flags whose values may be passed as the next arguments, the values of
+	// the other flags must be passed in the --flag=value form
+	opsCommandValueFlags = map[v1alpha1.OpsCommandType]sets.String{
+		v1alpha1.OpsCommandTypePDCtl:   sets.NewString("--jq"),
+		v1alpha1.OpsCommandTypeDMCtl:   sets.NewString(),
+		v1alpha1.OpsCommandTypeBRDebug: sets.NewString("-s", "--storage"),
+	}
+	// opsCommandReservedFlags are the flags of the endpoints and the TLS set by the operator,
+	// and the flags of the interactive mode which never exits
+	opsCommandReservedFlags = sets.NewString("-u", "--pd", "--master-addr", "--ca", "--cacert", "--cert", "--key",
+		"--ssl-ca", "--ssl-cert", "--ssl-key", "-i", "--interact")
+)
+
+// opsCommandPath returns the subcommand path of the args, which are the args except the flags and their values
+func opsCommandPath(args []string, valueFlags sets.String) []string {
+	var subcommand []string
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			subcommand = append(subcommand, args[i])
+			continue
+		}
+		if valueFlags.Has(args[i]) {
+			i++
+		}
+	}
+	return subcommand
+}
+
+// matchOpsCommandPath returns whether the subcommand path matches the pattern in opsCommandReadOnlyPaths
+func matchOpsCommandPath(subcommand []string, pattern string) bool {
+	words := strings.Fields(pattern)
+	if len(words) != len(subcommand) {
+		return false
+	}
+	for i, word := range words {
+		switch word {
+		case "<arg>":
+		case "<id>":
+			if _, err := strconv.ParseUint(subcommand[i], 10, 64); err != nil {
+				return false
+			}
+		default:
+			if subcommand[i] != word {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValidateOpsCommand validates that the command of the OpsCommand is allowed and doesn't override the flags set by the operator
+func ValidateOpsCommand(oc *v1alpha1.OpsCommand) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec")
+	if oc.Spec.Cluster == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("cluster"), "cluster must be set"))
+	}
+	readOnlyPaths, ok := opsCommandReadOnlyPaths[oc.Spec.Type]
+	if !ok {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), oc.Spec.Type,
+			[]string{string(v1alpha1.OpsCommandTypePDCtl), string(v1alpha1.OpsCommandTypeDMCtl), string(v1alpha1.OpsCommandTypeBRDebug)}))
+		return allErrs
+	}
+	argsPath := fldPath.Child("args")
+	if len(oc.Spec.Args) == 0 {
+		allErrs = append(allErrs, field.Required(argsPath, "the subcommand must be set"))
+		return allErrs
+	}
+	subcommand := opsCommandPath(oc.Spec.Args, opsCommandValueFlags[oc.Spec.Type])
+	matched := false
+	for _, pattern := range readOnlyPaths {
+		if matchOpsCommandPath(subcommand, pattern) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		allErrs = append(allErrs, field.NotSupported(argsPath, strings.Join(subcommand, " "), readOnlyPaths))
+	}
+	for i, arg := range oc.Spec.Args {
+		flag := strings.SplitN(arg, "=", 2)[0]
+		if opsCommandReservedFlags.Has(flag) {
+			allErrs = append(allErrs, field.Forbidden(argsPath.Index(i), fmt.Sprintf("flag %s is set by the operator", flag)))
+		}
+	}
+	if oc.Spec.ActiveDeadlineSeconds != nil && *oc.Spec.ActiveDeadlineSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("activeDeadlineSeconds"), *oc.Spec.ActiveDeadlineSeconds, "must be greater than 0"))
+	}
+	return allErrs
+}
+
+// ValidateDMTask validates the DMCluster, the config, the sources and the stage of the DMTask
+func ValidateDMTask(dt *v1alpha1.DMTask) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec")
+	if dt.Spec.Cluster == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("cluster"), "cluster must be set"))
+	}
+	if dt.Spec.Config == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("config"), "config must be set"))
+	} else {
+		config := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(dt.Spec.Config), &config); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("config"), dt.Spec.Config, fmt.Sprintf("must be a JSON object: %v", err)))
+		}
+	}
+	names := sets.NewString()
+	for i, source := range dt.Spec.Sources {
+		idxPath := fldPath.Child("sources").Index(i)
+		if source.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "name must be set"))
+		} else if names.Has(source.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), source.Name))
+		}
+		names.Insert(source.Name)
+		if source.Host == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("host"), "host must be set"))
+		}
+		if source.User == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("user"), "user must be set"))
+		}
+		for _, msg := range validation.IsValidPortNum(int(source.Port)) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("port"), source.Port, msg))
+		}
+	}
+	switch dt.Spec.Stage {
+	case "", v1alpha1.DMTaskStageRunning, v1alpha1.DMTaskStagePaused, v1alpha1.DMTaskStageStopped:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("stage"), dt.Spec.Stage,
+			[]string{string(v1alpha1.DMTaskStageRunning), string(v1alpha1.DMTaskStagePaused), string(v1alpha1.DMTaskStageStopped)}))
+	}
+	return allErrs
+}
+
+var (
+	// scrapeJobNames are the names of the scrape jobs generated for the components monitored by TidbMonitor
+	scrapeJobNames = sets.NewString("pd", "tidb", "tikv", "tiflash", "tiflash-proxy", "pump", "drainer", "ticdc",
+		"importer", "lightning", "dm-worker", "dm-master")
+	// defaultScrapeInterval is the scrape interval of the jobs generated for TidbMonitor
+	defaultScrapeInterval = model.Duration(15 * time.Second)
+)
+
+// validateScrapeConfigs validates the components and that the scrape timeouts are less than the scrape intervals
+func validateScrapeConfigs(scrapeConfigs map[string]v1alpha1.ScrapeConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, name := range sets.StringKeySet(scrapeConfigs).List() {
+		scrapeConfig := scrapeConfigs[name]
+		keyPath := fldPath.Key(name)
+		if !scrapeJobNames.Has(name) {
+			allErrs = append(allErrs, field.NotSupported(keyPath, name, scrapeJobNames.List()))
+			continue
+		}
+		interval := defaultScrapeInterval
+		if scrapeConfig.Interval != nil {
+			d, err := model.ParseDuration(*scrapeConfig.Interval)
+			if err != nil || d <= 0 {
+				allErrs = append(allErrs, field.Invalid(keyPath.Child("interval"), *scrapeConfig.Interval, "must be a positive Prom time duration string, e.g. 30s"))
+				continue
+			}
+			interval = d
+		}
+		if scrapeConfig.Timeout != nil {
+			d, err := model.ParseDuration(*scrapeConfig.Timeout)
+			if err != nil || d <= 0 {
+				allErrs = append(allErrs, field.Invalid(keyPath.Child("timeout"), *scrapeConfig.Timeout, "must be a positive Prom time duration string, e.g. 10s"))
+				continue
+			}
+			if d >= interval {
+				allErrs = append(allErrs, field.Invalid(keyPath.Child("timeout"), *scrapeConfig.Timeout, fmt.Sprintf("must be less than the interval %s", interval)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateRemoteWrites validates the URLs, the relabeling rules and the Secrets and ConfigMaps referenced by the remote writes
+func validateRemoteWrites(remoteWrites []*v1alpha1.RemoteWriteSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, remoteWrite := range remoteWrites {
+		idxPath := fldPath.Index(i)
+		if remoteWrite == nil {
+			continue
+		}
+		if remoteWrite.URL == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("url"), "url must not be empty"))
+		} else if _, err := url.Parse(remoteWrite.URL); err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("url"), remoteWrite.URL, err.Error()))
+		}
+		if remoteWrite.ProxyURL != nil && *remoteWrite.ProxyURL != "" {
+			if _, err := url.Parse(*remoteWrite.ProxyURL); err != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("proxyUrl"), *remoteWrite.ProxyURL, err.Error()))
+			}
+		}
+		for j, relabelConfig := range remoteWrite.WriteRelabelConfigs {
+			if relabelConfig.Regex == "" {
+				continue
+			}
+			if _, err := regexp.Compile(relabelConfig.Regex); err != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("writeRelabelConfigs").Index(j).Child("regex"), relabelConfig.Regex, err.Error()))
+			}
+		}
+		if remoteWrite.BasicAuth != nil {
+			if remoteWrite.BearerTokenFile != "" {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("basicAuth"), "basicAuth and bearerTokenFile are mutually exclusive"))
+			}
+			allErrs = append(allErrs, validateSecretKeySelector(&remoteWrite.BasicAuth.Username, idxPath.Child("basicAuth", "username"))...)
+			allErrs = append(allErrs, validateSecretKeySelector(&remoteWrite.BasicAuth.Password, idxPath.Child("basicAuth", "password"))...)
+		}
+		if tlsConfig := remoteWrite.TLSConfig; tlsConfig != nil {
+			tlsPath := idxPath.Child("tlsConfig")
+			allErrs = append(allErrs, validateSecretOrConfigMap(tlsConfig.CA, tlsPath.Child("ca"))...)
+			allErrs = append(allErrs, validateSecretOrConfigMap(tlsConfig.Cert, tlsPath.Child("cert"))...)
+			if tlsConfig.KeySecret != nil {
+				allErrs = append(allErrs, validateSecretKeySelector(tlsConfig.KeySecret, tlsPath.Child("keySecret"))...)
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateSecretOrConfigMap validates that at most one of the Secret and ConfigMap is set and the set one is valid
+func validateSecretOrConfigMap(s v1alpha1.SecretOrConfigMap, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if s.Secret != nil && s.ConfigMap != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "secret and configMap are mutually exclusive"))
+	}
+	if s.Secret != nil {
+		allErrs = append(allErrs, validateSecretKeySelector(s.Secret, fldPath.Child("secret"))...)
+	}
+	if s.ConfigMap != nil {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(s.ConfigMap.Name, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("configMap", "name"), s.ConfigMap.Name, msg))
+		}
+		if len(s.ConfigMap.Key) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("configMap", "key"), ""))
+		}
+	}
+	return allErrs
+}
+
+// validateSilenceWindows validates that the windows are named uniquely and have valid durations and matchers
+func validateSilenceWindows(windows []v1alpha1.SilenceWindow, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.NewString()
+	for i, window := range windows {
+		idxPath := fldPath.Index(i)
+		for _, msg := range validation.IsDNS1123Label(window.Name) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), window.Name, msg))
+		}
+		if names.Has(window.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), window.Name))
+		}
+		names.Insert(window.Name)
+		if window.Schedule == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("schedule"), "schedule must not be empty"))
+		}
+		if d, err := time.ParseDuration(window.Duration); err != nil || d <= 0 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("duration"), window.Duration, "must be a positive duration, e.g. 2h"))
+		}
+		if len(window.Matchers) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("matchers"), "matchers must not be empty"))
+		}
+		for name, value := range window.Matchers {
+			if !model.LabelName(name).IsValid() {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("matchers"), name, "must be a valid label name"))
+			}
+			if _, err := regexp.Compile(value); err != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("matchers").Key(name), value, err.Error()))
+			}
+		}
+	}
 	return allErrs
 }
 
@@ -127,9 +494,399 @@ func validateTiDBClusterSpec(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path
 	if spec.TiCDC != nil {
 		allErrs = append(allErrs, validateTiCDCSpec(spec.TiCDC, fldPath.Child("ticdc"))...)
 	}
+	if spec.TiProxy != nil {
+		allErrs = append(allErrs, validateTiProxySpec(spec.TiProxy, fldPath.Child("tiproxy"))...)
+	}
 	if spec.PDAddresses != nil {
 		allErrs = append(allErrs, validatePDAddresses(spec.PDAddresses, fldPath.Child("pdAddresses"))...)
 	}
+	if spec.Helper != nil {
+		allErrs = append(allErrs, validateHelperSpec(spec.Helper, fldPath.Child("helper"))...)
+	}
+	if spec.ConfigDrift != nil {
+		allErrs = append(allErrs, validateConfigDriftSpec(spec.ConfigDrift, fldPath.Child("configDrift"))...)
+	}
+	if spec.ClockSkew != nil {
+		allErrs = append(allErrs, validateClockSkewSpec(spec.ClockSkew, fldPath.Child("clockSkew"))...)
+	}
+	if spec.VersionSkew != nil {
+		allErrs = append(allErrs, validateVersionSkewSpec(spec.VersionSkew, fldPath.Child("versionSkew"))...)
+	}
+	if spec.TombstoneStoreGC != nil {
+		allErrs = append(allErrs, validateTombstoneStoreGCSpec(spec.TombstoneStoreGC, fldPath.Child("tombstoneStoreGC"))...)
+	}
+	if spec.AutoResume != nil {
+		allErrs = append(allErrs, validateAutoResumeSpec(spec.AutoResume, fldPath.Child("autoResume"))...)
+	}
+	if spec.TLSCluster != nil && spec.TLSCluster.CertRotation != nil {
+		allErrs = append(allErrs, validateCertRotationSpec(spec.TLSCluster.CertRotation, fldPath.Child("tlsCluster", "certRotation"))...)
+	}
+	if spec.Notifications != nil {
+		allErrs = append(allErrs, ValidateNotificationSpec(spec.Notifications, fldPath.Child("notifications"))...)
+	}
+	if spec.Maintenance != nil {
+		allErrs = append(allErrs, validateMaintenanceSpec(spec.Maintenance, fldPath.Child("maintenance"))...)
+	}
+	if spec.SpotTermination != nil {
+		allErrs = append(allErrs, validateSpotTerminationSpec(spec.SpotTermination, fldPath.Child("spotTermination"))...)
+	}
+	if spec.StorageProvisioning != nil {
+		allErrs = append(allErrs, validateStorageProvisioningSpec(spec.StorageProvisioning, fldPath.Child("storageProvisioning"))...)
+	}
+	if spec.Diagnostics != nil {
+		allErrs = append(allErrs, validateDiagnosticsSpec(spec.Diagnostics, fldPath.Child("diagnostics"))...)
+	}
+	if spec.ReadOnly != nil && spec.ReadOnly.SecretName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("readOnly", "secretName"), "the secret of the password is required to set the read-only mode"))
+	}
+	if spec.ConfigRevisionHistoryLimit != nil && *spec.ConfigRevisionHistoryLimit < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("configRevisionHistoryLimit"), *spec.ConfigRevisionHistoryLimit, "must be greater than or equal to 0"))
+	}
+	allErrs = append(allErrs, validateTimezone(spec.Timezone, fldPath.Child("timezone"))...)
+	return allErrs
+}
+
+// validateSpotTerminationSpec validates that the taints are valid taint keys
+func validateSpotTerminationSpec(spec *v1alpha1.SpotTerminationSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, key := range spec.Taints {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("taints").Index(i), key, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateStorageProvisioningSpec validates that exactly one hook is set and the components support the hook
+func validateStorageProvisioningSpec(spec *v1alpha1.StorageProvisioningSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if (spec.Webhook == nil) == (spec.Job == nil) {
+		allErrs = append(allErrs, field.Invalid(fldPath, "", "exactly one of webhook and job must be set"))
+	}
+	if spec.Webhook != nil && spec.Webhook.URL == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("webhook", "url"), "the url of the webhook is required"))
+	}
+	if spec.Job != nil && spec.Job.Image == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("job", "image"), "the image of the job is required"))
+	}
+	for i, component := range spec.Components {
+		switch component {
+		case v1alpha1.PDMemberType, v1alpha1.TiKVMemberType, v1alpha1.TiFlashMemberType:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("components").Index(i), component,
+				[]string{v1alpha1.PDMemberType.String(), v1alpha1.TiKVMemberType.String(), v1alpha1.TiFlashMemberType.String()}))
+		}
+	}
+	if spec.Timeout != nil && spec.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeout"), spec.Timeout.Duration.String(), "must be greater than 0"))
+	}
+	if spec.MaxRetries != nil && *spec.MaxRetries < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxRetries"), *spec.MaxRetries, "must not be negative"))
+	}
+	return allErrs
+}
+
+// validateDiagnosticsSpec validates the triggers and that the storage of the bundles is set
+func validateDiagnosticsSpec(spec *v1alpha1.DiagnosticsSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, trigger := range spec.Triggers {
+		switch trigger {
+		case v1alpha1.DiagnosticsTriggerFailover, v1alpha1.DiagnosticsTriggerUpgradeStuck:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("triggers").Index(i), trigger, []string{
+				string(v1alpha1.DiagnosticsTriggerFailover), string(v1alpha1.DiagnosticsTriggerUpgradeStuck)}))
+		}
+	}
+	allErrs = append(allErrs, validateTimeDurationStr(spec.UpgradeStuckTimeout, fldPath.Child("upgradeStuckTimeout"))...)
+	allErrs = append(allErrs, validateTimeDurationStr(spec.MinInterval, fldPath.Child("minInterval"))...)
+	if spec.LogTailLines != nil && *spec.LogTailLines <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("logTailLines"), *spec.LogTailLines, "must be greater than 0"))
+	}
+	switch {
+	case spec.S3 != nil:
+		if spec.S3.Bucket == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("s3", "bucket"), "bucket must not be empty"))
+		}
+	case spec.Gcs != nil:
+		if spec.Gcs.Bucket == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("gcs", "bucket"), "bucket must not be empty"))
+		}
+	case spec.Azblob != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("azblob"), "azblob is not supported to store the bundles yet"))
+	case spec.Local != nil:
+		if spec.Local.VolumeMount.MountPath == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("local", "volumeMount", "mountPath"), "mountPath must not be empty"))
+		}
+	default:
+		allErrs = append(allErrs, field.Required(fldPath, "one of s3, gcs and local must be set to store the bundles"))
+	}
+	return allErrs
+}
+
+// validateDebugArtifactsSpec validates the image of the sidecar, the interval and that the storage of the artifacts is set
+func validateDebugArtifactsSpec(spec *v1alpha1.DebugArtifactsSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Image == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("image"), "image of the sidecar must not be empty"))
+	}
+	allErrs = append(allErrs, validateTimeDurationStr(spec.Interval, fldPath.Child("interval"))...)
+	allErrs = append(allErrs, validateSidecarStorageProvider(spec.StorageProvider, fldPath, "artifacts")...)
+	return allErrs
+}
+
+// validateRelayLogStorageSpec validates the upload of the relay logs of dm-worker
+func validateRelayLogStorageSpec(spec *v1alpha1.RelayLogStorageSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Image == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("image"), "image of the sidecar must not be empty"))
+	}
+	if spec.RelayDir != "" && !strings.HasPrefix(path.Clean(spec.RelayDir)+"/", "/var/lib/dm-worker/") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("relayDir"), spec.RelayDir, "must be in the data volume of dm-worker mounted at /var/lib/dm-worker"))
+	}
+	allErrs = append(allErrs, validateTimeDurationStr(spec.Interval, fldPath.Child("interval"))...)
+	allErrs = append(allErrs, validateTimeDurationStr(spec.LocalRetention, fldPath.Child("localRetention"))...)
+	allErrs = append(allErrs, validateTimeDurationStr(spec.RemoteRetention, fldPath.Child("remoteRetention"))...)
+	allErrs = append(allErrs, validateSidecarStorageProvider(spec.StorageProvider, fldPath, "relay logs")...)
+	return allErrs
+}
+
+// validateSidecarStorageProvider validates the storage the files of a sidecar are uploaded to
+func validateSidecarStorageProvider(provider v1alpha1.StorageProvider, fldPath *field.Path, what string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch {
+	case provider.S3 != nil:
+		if provider.S3.Bucket == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("s3", "bucket"), "bucket must not be empty"))
+		}
+	case provider.Gcs != nil:
+		if provider.Gcs.Bucket == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("gcs", "bucket"), "bucket must not be empty"))
+		}
+	case provider.Azblob != nil:
+		if provider.Azblob.Container == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("azblob", "container"), "container must not be empty"))
+		}
+	case provider.Local != nil:
+		if provider.Local.VolumeMount.MountPath == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("local", "volumeMount", "mountPath"), "mountPath must not be empty"))
+		}
+	default:
+		allErrs = append(allErrs, field.Required(fldPath, fmt.Sprintf("one of s3, gcs, azblob and local must be set to store the %s", what)))
+	}
+	return allErrs
+}
+
+// maxMaintenanceTaskNameLength keeps the names of the Jobs, which are suffixed by the task name and
+// the schedule time, within the limit of the label values
+const maxMaintenanceTaskNameLength = 20
+
+// maxKeyspaceID is the max ID of the keyspaces, which are encoded in 3 bytes
+const maxKeyspaceID = 1<<24 - 1
+
+var tableNamePattern = regexp.MustCompile(`^[^.\x60\s]+\.[^.\x60\s]+$`)
+
+// validateMaintenanceSpec validates that the tasks are named uniquely and each of them has exactly one action
+func validateMaintenanceSpec(spec *v1alpha1.MaintenanceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.NewString()
+	for i, task := range spec.Tasks {
+		idxPath := fldPath.Child("tasks").Index(i)
+		for _, msg := range validation.IsDNS1123Label(task.Name) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), task.Name, msg))
+		}
+		if len(task.Name) > maxMaintenanceTaskNameLength {
+			allErrs = append(allErrs, field.TooLong(idxPath.Child("name"), task.Name, maxMaintenanceTaskNameLength))
+		}
+		if names.Has(task.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), task.Name))
+		}
+		names.Insert(task.Name)
+		if task.Schedule == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("schedule"), "schedule must not be empty"))
+		}
+		if task.Window != nil && task.Window.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("window"), task.Window.Duration.String(), "must be greater than 0"))
+		}
+
+		actions := 0
+		if task.CompactTiKV != nil {
+			actions++
+			switch task.CompactTiKV.DB {
+			case "", "kv", "raft":
+			default:
+				allErrs = append(allErrs, field.NotSupported(idxPath.Child("compactTiKV", "db"), task.CompactTiKV.DB, []string{"kv", "raft"}))
+			}
+			for j, cf := range task.CompactTiKV.ColumnFamilies {
+				switch cf {
+				case "default", "lock", "write":
+				default:
+					allErrs = append(allErrs, field.NotSupported(idxPath.Child("compactTiKV", "columnFamilies").Index(j), cf, []string{"default", "lock", "write"}))
+				}
+			}
+			for j, table := range task.CompactTiKV.Tables {
+				if !tableNamePattern.MatchString(table) {
+					allErrs = append(allErrs, field.Invalid(idxPath.Child("compactTiKV", "tables").Index(j), table, "must be in the format of db.table"))
+				}
+			}
+			for j, id := range task.CompactTiKV.KeyspaceIDs {
+				if id > maxKeyspaceID {
+					allErrs = append(allErrs, field.Invalid(idxPath.Child("compactTiKV", "keyspaceIDs").Index(j), id, fmt.Sprintf("must be less than or equal to %d", maxKeyspaceID)))
+				}
+			}
+			if task.CompactTiKV.DB == "raft" && (len(task.CompactTiKV.Tables) > 0 || len(task.CompactTiKV.KeyspaceIDs) > 0) {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("compactTiKV"), "tables and keyspaceIDs are only supported for the kv db"))
+			}
+		}
+		if task.AnalyzeTable != nil {
+			actions++
+			if len(task.AnalyzeTable.Tables) == 0 {
+				allErrs = append(allErrs, field.Required(idxPath.Child("analyzeTable", "tables"), "tables must not be empty"))
+			}
+			for j, table := range task.AnalyzeTable.Tables {
+				if !tableNamePattern.MatchString(table) {
+					allErrs = append(allErrs, field.Invalid(idxPath.Child("analyzeTable", "tables").Index(j), table, "must be in the format of db.table"))
+				}
+			}
+		}
+		if task.ResetStoreLimit != nil {
+			actions++
+			if task.ResetStoreLimit.Rate != nil && *task.ResetStoreLimit.Rate <= 0 {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("resetStoreLimit", "rate"), *task.ResetStoreLimit.Rate, "must be greater than 0"))
+			}
+		}
+		if actions != 1 {
+			allErrs = append(allErrs, field.Invalid(idxPath, task.Name, "exactly one of compactTiKV, analyzeTable and resetStoreLimit must be set"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateNotificationSpec validates that the sinks are named uniquely and each of them has exactly one destination
+func ValidateNotificationSpec(spec *v1alpha1.NotificationSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.NewString()
+	for i, sink := range spec.Sinks {
+		idxPath := fldPath.Child("sinks").Index(i)
+		if sink.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "name must not be empty"))
+		} else if names.Has(sink.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), sink.Name))
+		}
+		names.Insert(sink.Name)
+
+		switch sink.MinSeverity {
+		case "", v1alpha1.NotificationSeverityInfo, v1alpha1.NotificationSeverityWarning, v1alpha1.NotificationSeverityCritical:
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("minSeverity"), sink.MinSeverity, []string{
+				string(v1alpha1.NotificationSeverityInfo), string(v1alpha1.NotificationSeverityWarning), string(v1alpha1.NotificationSeverityCritical)}))
+		}
+
+		destinations := 0
+		if sink.Webhook != nil {
+			destinations++
+			if u, err := url.Parse(sink.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("webhook", "url"), sink.Webhook.URL, "must be a valid http or https URL"))
+			}
+		}
+		if sink.Slack != nil {
+			destinations++
+			allErrs = append(allErrs, validateSecretKeySelector(&sink.Slack.WebhookURLSecretRef, idxPath.Child("slack", "webhookURLSecretRef"))...)
+		}
+		if sink.PagerDuty != nil {
+			destinations++
+			allErrs = append(allErrs, validateSecretKeySelector(&sink.PagerDuty.RoutingKeySecretRef, idxPath.Child("pagerDuty", "routingKeySecretRef"))...)
+		}
+		if destinations != 1 {
+			allErrs = append(allErrs, field.Invalid(idxPath, sink.Name, "exactly one of webhook, slack and pagerDuty must be set"))
+		}
+	}
+	return allErrs
+}
+
+// validateConfigDriftSpec validates the policy and the check interval of config drift detection
+func validateConfigDriftSpec(spec *v1alpha1.ConfigDriftSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch spec.Policy {
+	case "", v1alpha1.ConfigDriftPolicyReport, v1alpha1.ConfigDriftPolicyRevert:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("policy"), spec.Policy,
+			[]string{string(v1alpha1.ConfigDriftPolicyReport), string(v1alpha1.ConfigDriftPolicyRevert)}))
+	}
+	if spec.Interval != nil && spec.Interval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("interval"), spec.Interval.Duration.String(), "must be greater than 0"))
+	}
+	return allErrs
+}
+
+// validateClockSkewSpec validates the policy, the thresholds and the check interval of clock skew detection
+func validateAutoResumeSpec(spec *v1alpha1.AutoResumeSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Until != nil && spec.After != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("after"), "only one of until and after can be set"))
+	}
+	if spec.After != nil && spec.After.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("after"), spec.After.Duration.String(), "must be greater than 0"))
+	}
+	return allErrs
+}
+
+func validateCertRotationSpec(spec *v1alpha1.CertRotationSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch spec.Issuer {
+	case v1alpha1.CertIssuerCertManager:
+	case v1alpha1.CertIssuerCSR:
+		if spec.SignerName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("signerName"), "signerName is required if the issuer is CSR"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("issuer"), spec.Issuer,
+			[]string{string(v1alpha1.CertIssuerCertManager), string(v1alpha1.CertIssuerCSR)}))
+	}
+	if spec.RenewBefore != nil && spec.RenewBefore.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("renewBefore"), spec.RenewBefore.Duration.String(), "must be greater than 0"))
+	}
+	return allErrs
+}
+
+func validateClockSkewSpec(spec *v1alpha1.ClockSkewSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch spec.Policy {
+	case "", v1alpha1.ClockSkewPolicyReport, v1alpha1.ClockSkewPolicyCordon:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("policy"), spec.Policy,
+			[]string{string(v1alpha1.ClockSkewPolicyReport), string(v1alpha1.ClockSkewPolicyCordon)}))
+	}
+	if spec.MaxSkew != nil && spec.MaxSkew.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxSkew"), spec.MaxSkew.Duration.String(), "must be greater than 0"))
+	}
+	if spec.CordonThreshold != nil && spec.CordonThreshold.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cordonThreshold"), spec.CordonThreshold.Duration.String(), "must be greater than 0"))
+	}
+	if spec.MaxSkew != nil && spec.CordonThreshold != nil && spec.CordonThreshold.Duration < spec.MaxSkew.Duration {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cordonThreshold"), spec.CordonThreshold.Duration.String(), "must not be less than maxSkew"))
+	}
+	if spec.Interval != nil && spec.Interval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("interval"), spec.Interval.Duration.String(), "must be greater than 0"))
+	}
+	return allErrs
+}
+
+func validateVersionSkewSpec(spec *v1alpha1.VersionSkewSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.MaxMinorSkew < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxMinorSkew"), spec.MaxMinorSkew, "must not be negative"))
+	}
+	if spec.GracePeriod != nil && spec.GracePeriod.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("gracePeriod"), spec.GracePeriod.Duration.String(), "must not be negative"))
+	}
+	return allErrs
+}
+
+func validateTombstoneStoreGCSpec(spec *v1alpha1.TombstoneStoreGCSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Interval != nil && spec.Interval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("interval"), spec.Interval.Duration.String(), "must be greater than 0"))
+	}
 	return allErrs
 }
 
@@ -144,10 +901,15 @@ func validateDiscoverySpec(spec v1alpha1.DiscoverySpec, fldPath *field.Path) fie
 func validatePDSpec(spec *v1alpha1.PDSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	allErrs = append(allErrs, validateRequestsStorage(spec.ResourceRequirements.Requests, fldPath)...)
 	if len(spec.StorageVolumes) > 0 {
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
 	}
+	// the peer port 2380 is not configurable
+	allErrs = append(allErrs, validatePort(spec.ClientPort, fldPath.Child("clientPort"), 2380)...)
 	return allErrs
 }
 
@@ -169,6 +931,9 @@ func validatePDAddresses(arrayOfAddresses []string, fldPath *field.Path) field.E
 func validateTiKVSpec(spec *v1alpha1.TiKVSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	allErrs = append(allErrs, validateRequestsStorage(spec.ResourceRequirements.Requests, fldPath)...)
 	if len(spec.DataSubDir) > 0 {
 		allErrs = append(allErrs, validateLocalDescendingPath(spec.DataSubDir, fldPath.Child("dataSubDir"))...)
@@ -183,12 +948,87 @@ func validateTiKVSpec(spec *v1alpha1.TiKVSpec, fldPath *field.Path) field.ErrorL
 		allErrs = append(allErrs, validateVolumeName(spec.RocksDBLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
 	}
 	allErrs = append(allErrs, validateTimeDurationStr(spec.EvictLeaderTimeout, fldPath.Child("evictLeaderTimeout"))...)
+	allErrs = append(allErrs, validateTimeDurationStr(spec.LeaderRecoveryTimeout, fldPath.Child("leaderRecoveryTimeout"))...)
+	if interval := spec.RegionHealthRefreshInterval; interval != nil {
+		// 0 is allowed to disable the region health summary
+		if d, err := time.ParseDuration(*interval); err != nil || d < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("regionHealthRefreshInterval"), *interval, "must be a non-negative Go time duration, e.g. 5m"))
+		}
+	}
+	if percent := spec.LeaderRecoveryPercentOnUpgrade; percent != nil && (*percent < 0 || *percent > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("leaderRecoveryPercentOnUpgrade"), *percent, "must be between 0 and 100"))
+	}
+	// the status port 20180 is not configurable
+	allErrs = append(allErrs, validatePort(spec.Port, fldPath.Child("port"), 20180)...)
+	for i := range spec.StoreScheduling {
+		allErrs = append(allErrs, validateTiKVStoreScheduling(&spec.StoreScheduling[i], fldPath.Child("storeScheduling").Index(i))...)
+	}
+	for key := range spec.PerPodConfig {
+		if ordinal, err := strconv.ParseInt(key, 10, 32); err != nil || ordinal < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("perPodConfig").Key(key), key, "must be the ordinal of a Pod"))
+		}
+	}
+	if spec.ColdGroup != nil {
+		allErrs = append(allErrs, validateTiKVColdGroup(spec.ColdGroup, fldPath.Child("coldGroup"))...)
+	}
+	if spec.DebugArtifacts != nil {
+		allErrs = append(allErrs, validateDebugArtifactsSpec(spec.DebugArtifacts, fldPath.Child("debugArtifacts"))...)
+	}
+	return allErrs
+}
+
+// validateTiKVColdGroup validates the replicas and the store labels of the TiKV cold group
+func validateTiKVColdGroup(spec *v1alpha1.TiKVColdGroupSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), spec.Replicas, "must be greater than or equal to 0"))
+	}
+	for key, val := range spec.StoreLabels {
+		// the labels are passed to TiKV in the form of k1=v1,k2=v2
+		if key == "" || val == "" || strings.ContainsAny(key+val, "=,") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storeLabels").Key(key), val, "the key and the value must be non-empty and must not contain '=' or ','"))
+		}
+		if key == "engine" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storeLabels").Key(key), val, "the label is reserved by TiFlash"))
+		}
+	}
+	return allErrs
+}
+
+// validateTiKVStoreScheduling validates that the weights are not negative and the store limits are positive
+func validateTiKVStoreScheduling(spec *v1alpha1.TiKVStoreScheduling, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, ordinal := range spec.Ordinals {
+		if ordinal < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ordinals").Index(i), ordinal, "must be greater than or equal to 0"))
+		}
+	}
+	for key := range spec.StoreLabels {
+		if key == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storeLabels"), key, "the key must not be empty"))
+		}
+	}
+	if spec.LeaderWeight != nil && *spec.LeaderWeight < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("leaderWeight"), *spec.LeaderWeight, "must be greater than or equal to 0"))
+	}
+	if spec.RegionWeight != nil && *spec.RegionWeight < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("regionWeight"), *spec.RegionWeight, "must be greater than or equal to 0"))
+	}
+	if spec.AddPeerLimit != nil && *spec.AddPeerLimit <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("addPeerLimit"), *spec.AddPeerLimit, "must be greater than 0"))
+	}
+	if spec.RemovePeerLimit != nil && *spec.RemovePeerLimit <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("removePeerLimit"), *spec.RemovePeerLimit, "must be greater than 0"))
+	}
 	return allErrs
 }
 
 func validateTiFlashSpec(spec *v1alpha1.TiFlashSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	allErrs = append(allErrs, validateTiFlashConfig(spec.Config, fldPath)...)
 	if len(spec.StorageClaims) < 1 {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec.StorageClaims"),
@@ -203,6 +1043,32 @@ func validateTiCDCSpec(spec *v1alpha1.TiCDCSpec, fldPath *field.Path) field.Erro
 	if len(spec.StorageVolumes) > 0 {
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
 	}
+	allErrs = append(allErrs, validateTimeDurationStr(spec.GracefulShutdownTimeout, fldPath.Child("gracefulShutdownTimeout"))...)
+	allErrs = append(allErrs, validateTiCDCChangefeeds(spec.Changefeeds, fldPath.Child("changefeeds"))...)
+	return allErrs
+}
+
+func validateTiCDCChangefeeds(changefeeds []v1alpha1.TiCDCChangefeed, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	ids := map[string]struct{}{}
+	for i, cf := range changefeeds {
+		idxPath := fldPath.Index(i)
+		if cf.ID == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("id"), "the id of the changefeed is required"))
+		} else if _, ok := ids[cf.ID]; ok {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("id"), cf.ID))
+		}
+		ids[cf.ID] = struct{}{}
+		if cf.SinkURISecretRef.Name == "" || cf.SinkURISecretRef.Key == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("sinkURISecretRef"), "the name and the key of the secret are required"))
+		}
+	}
+	return allErrs
+}
+
+func validateTiProxySpec(spec *v1alpha1.TiProxySpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
 	return allErrs
 }
 
@@ -260,8 +1126,20 @@ func validateTiFlashConfig(config *v1alpha1.TiFlashConfigWraper, path *field.Pat
 func validateTiDBSpec(spec *v1alpha1.TiDBSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
+	if spec.UpgradeStrategy != nil {
+		allErrs = append(allErrs, validateTiDBUpgradeStrategy(spec.UpgradeStrategy, fldPath.Child("upgradeStrategy"))...)
+	}
 	if spec.Service != nil {
 		allErrs = append(allErrs, validateService(&spec.Service.ServiceSpec, fldPath)...)
+		allErrs = append(allErrs, validateTiDBService(spec.Service, fldPath.Child("service"))...)
+	}
+	if spec.PerPodService != nil {
+		allErrs = append(allErrs, validateService(&spec.PerPodService.ServiceSpec, fldPath)...)
+		allErrs = append(allErrs, validateTiDBService(spec.PerPodService, fldPath.Child("perPodService"))...)
+		allErrs = append(allErrs, validateTiDBPerPodService(spec.PerPodService, fldPath.Child("perPodService"))...)
 	}
 	if len(spec.StorageVolumes) > 0 {
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
@@ -269,6 +1147,18 @@ func validateTiDBSpec(spec *v1alpha1.TiDBSpec, fldPath *field.Path) field.ErrorL
 	if spec.ShouldSeparateSlowLog() && spec.SlowLogVolumeName != "" {
 		allErrs = append(allErrs, validateVolumeName(spec.SlowLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
 	}
+	port, statusPort := int32(v1alpha1.DefaultTidbPort), int32(v1alpha1.DefaultTidbStatusPort)
+	if spec.Port != nil {
+		port = *spec.Port
+	}
+	if spec.StatusPort != nil {
+		statusPort = *spec.StatusPort
+	}
+	allErrs = append(allErrs, validatePort(spec.Port, fldPath.Child("port"), statusPort)...)
+	allErrs = append(allErrs, validatePort(spec.StatusPort, fldPath.Child("statusPort"), port)...)
+	if spec.DebugArtifacts != nil {
+		allErrs = append(allErrs, validateDebugArtifactsSpec(spec.DebugArtifacts, fldPath.Child("debugArtifacts"))...)
+	}
 	return allErrs
 }
 
@@ -305,16 +1195,109 @@ func validateDMDiscoverySpec(spec v1alpha1.DMDiscoverySpec, fldPath *field.Path)
 func validateMasterSpec(spec *v1alpha1.MasterSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
 	// make sure that storageSize for dm-master is assigned
 	if spec.Replicas > 0 && spec.StorageSize == "" {
 		allErrs = append(allErrs, field.Required(fldPath.Child("storageSize"), "storageSize must not be empty"))
 	}
+	if len(spec.StorageVolumes) > 0 {
+		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	}
+	port, peerPort := int32(v1alpha1.DefaultDMMasterPort), int32(v1alpha1.DefaultDMMasterPeerPort)
+	if spec.Port != nil {
+		port = *spec.Port
+	}
+	if spec.PeerPort != nil {
+		peerPort = *spec.PeerPort
+	}
+	allErrs = append(allErrs, validatePort(spec.Port, fldPath.Child("port"), peerPort)...)
+	allErrs = append(allErrs, validatePort(spec.PeerPort, fldPath.Child("peerPort"), port)...)
+	allErrs = append(allErrs, validateMasterExternalMembers(spec.ExternalMembers, fldPath.Child("externalMembers"))...)
+	return allErrs
+}
+
+// validateMasterExternalMembers validates that the external members are the client URLs of dm-master
+func validateMasterExternalMembers(members []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, member := range members {
+		idxPath := fldPath.Index(i)
+		example := " dm-master client URL format example: http://{ADDRESS}:{PORT}"
+		u, err := url.Parse(member)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath, member, err.Error()+example))
+		} else if u.Scheme != "http" && u.Scheme != "https" {
+			allErrs = append(allErrs, field.Invalid(idxPath, member, "Support 'http' and 'https' scheme only."+example))
+		} else if u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(idxPath, member, "the address is missing."+example))
+		}
+	}
 	return allErrs
 }
 
 func validateWorkerSpec(spec *v1alpha1.WorkerSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
+	if spec.PodDisruptionBudget != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.PodDisruptionBudget, fldPath.Child("podDisruptionBudget"))...)
+	}
+	if len(spec.StorageVolumes) > 0 {
+		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	}
+	allErrs = append(allErrs, validateDMSourcePlacements(spec.SourcePlacements, fldPath.Child("sourcePlacements"))...)
+	if spec.RelayLogStorage != nil {
+		allErrs = append(allErrs, validateRelayLogStorageSpec(spec.RelayLogStorage, fldPath.Child("relayLogStorage"))...)
+	}
+	return allErrs
+}
+
+// validateTiDBUpgradeStrategy validates that maxUnavailable is a positive number or percentage
+func validateTiDBUpgradeStrategy(spec *v1alpha1.TiDBUpgradeStrategy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.MaxUnavailable == nil {
+		return allErrs
+	}
+	value, err := intstr.GetValueFromIntOrPercent(spec.MaxUnavailable, 100, false)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxUnavailable"), spec.MaxUnavailable.String(), err.Error()))
+	} else if value <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxUnavailable"), spec.MaxUnavailable.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+// validatePodDisruptionBudget validates that maxUnavailable is a non-negative number or percentage
+func validatePodDisruptionBudget(spec *v1alpha1.PodDisruptionBudgetSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.MaxUnavailable == nil {
+		return allErrs
+	}
+	value, err := intstr.GetValueFromIntOrPercent(spec.MaxUnavailable, 100, false)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxUnavailable"), spec.MaxUnavailable.String(), err.Error()))
+	} else if value < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxUnavailable"), spec.MaxUnavailable.String(), "must be non-negative"))
+	}
+	return allErrs
+}
+
+// validateDMSourcePlacements validates that each source has one placement which matches some nodes
+func validateDMSourcePlacements(placements []v1alpha1.DMSourcePlacement, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	sources := sets.NewString()
+	for i, placement := range placements {
+		idxPath := fldPath.Index(i)
+		if placement.Source == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("source"), "source must not be empty"))
+		} else if sources.Has(placement.Source) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("source"), placement.Source))
+		}
+		sources.Insert(placement.Source)
+		if len(placement.NodeSelector) == 0 && placement.Zone == "" {
+			allErrs = append(allErrs, field.Required(idxPath, "one of nodeSelector and zone must be set"))
+		}
+	}
 	return allErrs
 }
 
@@ -337,7 +1320,21 @@ func validateNGMonitoringSpec(spec *v1alpha1.NGMonitoringSpec, fldPath *field.Pa
 	if len(spec.StorageVolumes) > 0 {
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
 	}
+	if spec.TopSQL != nil {
+		allErrs = append(allErrs, validateTopSQLSpec(spec.TopSQL, fldPath.Child("topSQL"))...)
+	}
+
+	return allErrs
+}
 
+func validateTopSQLSpec(spec *v1alpha1.TopSQLSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.RetentionPeriod != nil && spec.RetentionPeriod.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("retentionPeriod"), spec.RetentionPeriod.Duration.String(), "must be positive"))
+	}
+	if spec.StorageSizeLimit != nil && spec.StorageSizeLimit.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("storageSizeLimit"), spec.StorageSizeLimit.String(), "must be positive"))
+	}
 	return allErrs
 }
 
@@ -346,6 +1343,85 @@ func validateComponentSpec(spec *v1alpha1.ComponentSpec, fldPath *field.Path) fi
 	// TODO validate other fields
 	allErrs = append(allErrs, validateEnv(spec.Env, fldPath.Child("env"))...)
 	allErrs = append(allErrs, validateAdditionalContainers(spec.AdditionalContainers, fldPath.Child("additionalContainers"))...)
+	allErrs = append(allErrs, validateArchBaseImages(spec.ArchBaseImages, fldPath.Child("archBaseImages"))...)
+	if spec.Helper != nil {
+		allErrs = append(allErrs, validateHelperSpec(spec.Helper, fldPath.Child("helper"))...)
+	}
+	if spec.Timezone != nil {
+		allErrs = append(allErrs, validateTimezone(*spec.Timezone, fldPath.Child("timezone"))...)
+	}
+	if spec.MinReadySeconds != nil && *spec.MinReadySeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReadySeconds"), *spec.MinReadySeconds, "must be greater than or equal to 0"))
+	}
+	if spec.RollingUpdateIntervalSeconds != nil && *spec.RollingUpdateIntervalSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rollingUpdateIntervalSeconds"), *spec.RollingUpdateIntervalSeconds, "must be greater than or equal to 0"))
+	}
+	return allErrs
+}
+
+var (
+	// ianaTimezoneRegexp matches the IANA time zone names, e.g. Asia/Shanghai, Etc/GMT+8
+	ianaTimezoneRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+\-]*(/[A-Za-z0-9_+\-]+)*$`)
+	// posixTimezoneRegexp matches the POSIX TZ strings, e.g. CST-8, EST5EDT,M3.2.0,M11.1.0
+	posixTimezoneRegexp = regexp.MustCompile(`^([A-Za-z]{3,}|<[A-Za-z0-9+\-]{3,}>)[+\-]?[0-9]{1,2}(:[0-9]{2}){0,2}` +
+		`(([A-Za-z]{3,}|<[A-Za-z0-9+\-]{3,}>)([+\-]?[0-9]{1,2}(:[0-9]{2}){0,2})?` +
+		`(,(J[0-9]{1,3}|[0-9]{1,3}|M[0-9]{1,2}\.[1-5]\.[0-6])(/[+\-]?[0-9]{1,3}(:[0-9]{2}){0,2})?){2})?$`)
+)
+
+// validateTimezone validates the value of the TZ env, which is either an IANA time zone name optionally
+// prefixed with ":" or a POSIX TZ string. An unknown IANA name is only rejected if the time zone database
+// is available, as the database may be absent where the validation runs.
+func validateTimezone(tz string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if tz == "" || posixTimezoneRegexp.MatchString(tz) {
+		return allErrs
+	}
+	name := strings.TrimPrefix(tz, ":")
+	if !ianaTimezoneRegexp.MatchString(name) || len(name) > 255 {
+		allErrs = append(allErrs, field.Invalid(fldPath, tz, "must be an IANA time zone name, e.g. Asia/Shanghai, or a POSIX TZ string, e.g. CST-8"))
+		return allErrs
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		if _, dbErr := time.LoadLocation("Etc/UTC"); dbErr == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, tz, "unknown time zone"))
+		}
+	}
+	return allErrs
+}
+
+var imageDigestRegexp = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// validateHelperSpec validates the digest of the helper image, which must not conflict
+// with the digest in the image if there is one
+func validateHelperSpec(spec *v1alpha1.HelperSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Digest == nil {
+		return allErrs
+	}
+	digest := *spec.Digest
+	if !imageDigestRegexp.MatchString(digest) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("digest"), digest, "must be in the form of sha256:<64 lowercase hex characters>"))
+		return allErrs
+	}
+	if spec.Image != nil && strings.Contains(*spec.Image, "@") && v1alpha1.DigestOfImage(*spec.Image) != digest {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("digest"), digest, fmt.Sprintf("conflicts with the digest in image %q", *spec.Image)))
+	}
+	return allErrs
+}
+
+// validateArchBaseImages validates the architectures and base images in archBaseImages
+func validateArchBaseImages(images map[v1alpha1.Architecture]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	supported := []string{string(v1alpha1.ArchitectureAMD64), string(v1alpha1.ArchitectureARM64)}
+	for arch, image := range images {
+		if arch != v1alpha1.ArchitectureAMD64 && arch != v1alpha1.ArchitectureARM64 {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Key(string(arch)), arch, supported))
+			continue
+		}
+		if image == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Key(string(arch)), "base image must not be empty"))
+		}
+	}
 	return allErrs
 }
 
@@ -495,6 +1571,7 @@ func ValidateCreateTidbCluster(tc *v1alpha1.TidbCluster) field.ErrorList {
 	// basic validation
 	allErrs = append(allErrs, ValidateTidbCluster(tc)...)
 	allErrs = append(allErrs, validateNewTidbClusterSpec(&tc.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, ValidateTidbClusterConfig(nil, tc)...)
 	return allErrs
 }
 
@@ -510,6 +1587,10 @@ func ValidateUpdateTidbCluster(old, tc *v1alpha1.TidbCluster) field.ErrorList {
 	}
 	allErrs = append(allErrs, validateUpdatePDConfig(old.Spec.PD.Config, tc.Spec.PD.Config, field.NewPath("spec.pd.config"))...)
 	allErrs = append(allErrs, disallowUsingLegacyAPIInNewCluster(old, tc)...)
+	allErrs = append(allErrs, validateUpdatePorts(old, tc)...)
+	allErrs = append(allErrs, validateUpdateTiKVColdGroup(old, tc)...)
+	allErrs = append(allErrs, validateUpdateEncryptionKeySecret(old, tc)...)
+	allErrs = append(allErrs, ValidateTidbClusterConfig(old, tc)...)
 
 	return allErrs
 }
@@ -579,6 +1660,39 @@ func disallowUsingLegacyAPIInNewCluster(old, tc *v1alpha1.TidbCluster) field.Err
 	return allErrs
 }
 
+// validateUpdatePorts checks that the ports advertised to PD are not changed, which would make the
+// existing members unreachable by the addresses stored in PD
+func validateUpdatePorts(old, tc *v1alpha1.TidbCluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+	path := field.NewPath("spec")
+	if old.Spec.PD != nil && tc.Spec.PD != nil && old.PDClientPort() != tc.PDClientPort() {
+		allErrs = append(allErrs, field.Forbidden(path.Child("pd.clientPort"), "clientPort of PD is immutable"))
+	}
+	if old.Spec.TiKV != nil && tc.Spec.TiKV != nil && old.TiKVPort() != tc.TiKVPort() {
+		allErrs = append(allErrs, field.Forbidden(path.Child("tikv.port"), "port of TiKV is immutable"))
+	}
+	return allErrs
+}
+
+// validateUpdateTiKVColdGroup checks that the store labels of the TiKV cold group are not changed, because
+// the labels of the existing stores are not updated
+func validateUpdateTiKVColdGroup(old, tc *v1alpha1.TidbCluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if old.TiKVColdGroupEnabled() && tc.TiKVColdGroupEnabled() && !reflect.DeepEqual(old.TiKVColdStoreLabels(), tc.TiKVColdStoreLabels()) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "tikv", "coldGroup", "storeLabels"), "storeLabels of the TiKV cold group are immutable"))
+	}
+	return allErrs
+}
+
+func validateUpdateEncryptionKeySecret(old, tc *v1alpha1.TidbCluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if old.Spec.EncryptionKeySecret != "" && tc.Spec.EncryptionKeySecret == "" {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "encryptionKeySecret"),
+			"encryptionKeySecret can't be removed once set, as TiKV needs the master key to read the encrypted data"))
+	}
+	return allErrs
+}
+
 func validateUpdatePDConfig(old, conf *v1alpha1.PDConfigWraper, path *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	// for newly created cluster, both old and new are non-nil, guaranteed by validation
@@ -612,6 +1726,80 @@ func validateUpdatePDConfig(old, conf *v1alpha1.PDConfigWraper, path *field.Path
 	return allErrs
 }
 
+// validateTidbClusterDeleteSlots validates the delete slots of the components in the annotations and in
+// the spec strictly, the ordinals must be non-negative and less than the max replica count derived from
+// the replicas, otherwise they are ignored silently by the advanced StatefulSet.
+func validateTidbClusterDeleteSlots(tc *v1alpha1.TidbCluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+	annPath := field.NewPath("metadata", "annotations")
+	specPath := field.NewPath("spec")
+
+	type componentDeleteSlots struct {
+		component string
+		annKey    string
+		specSlots []int32
+		fldPath   *field.Path
+		replicas  int32
+	}
+	var components []componentDeleteSlots
+	if tc.Spec.PD != nil {
+		components = append(components, componentDeleteSlots{label.PDLabelVal, label.AnnPDDeleteSlots,
+			tc.Spec.PD.DeleteSlots, specPath.Child("pd", "deleteSlots"), tc.PDStsDesiredReplicas()})
+	}
+	if tc.Spec.TiDB != nil {
+		components = append(components, componentDeleteSlots{label.TiDBLabelVal, label.AnnTiDBDeleteSlots,
+			tc.Spec.TiDB.DeleteSlots, specPath.Child("tidb", "deleteSlots"), tc.TiDBStsDesiredReplicas()})
+	}
+	if tc.Spec.TiKV != nil {
+		components = append(components, componentDeleteSlots{label.TiKVLabelVal, label.AnnTiKVDeleteSlots,
+			tc.Spec.TiKV.DeleteSlots, specPath.Child("tikv", "deleteSlots"), tc.TiKVStsDesiredReplicas()})
+	}
+	if tc.Spec.TiFlash != nil {
+		components = append(components, componentDeleteSlots{label.TiFlashLabelVal, label.AnnTiFlashDeleteSlots,
+			tc.Spec.TiFlash.DeleteSlots, specPath.Child("tiflash", "deleteSlots"), tc.TiFlashStsDesiredReplicas()})
+	}
+
+	for _, c := range components {
+		inSpec := sets.NewInt32()
+		for i, slot := range c.specSlots {
+			if slot < 0 {
+				allErrs = append(allErrs, field.Invalid(c.fldPath.Index(i), slot, "must be non-negative"))
+			} else if inSpec.Has(slot) {
+				allErrs = append(allErrs, field.Duplicate(c.fldPath.Index(i), slot))
+			}
+			inSpec.Insert(slot)
+		}
+		var inAnn []int32
+		if value, ok := tc.Annotations[c.annKey]; ok {
+			// the syntax is validated by validateDeleteSlots
+			if err := json.Unmarshal([]byte(value), &inAnn); err == nil {
+				for _, slot := range inAnn {
+					if slot < 0 {
+						allErrs = append(allErrs, field.Invalid(annPath.Key(c.annKey), value, fmt.Sprintf("ordinal %d must be non-negative", slot)))
+					}
+				}
+			}
+		}
+
+		deleteSlots := tc.DeleteSlots(c.component)
+		for _, slot := range deleteSlots.List() {
+			if slot < 0 {
+				deleteSlots.Delete(slot)
+			}
+		}
+		maxReplicaCount, effective := v1alpha1.GetMaxReplicaCountAndDeleteSlots(c.replicas, deleteSlots)
+		for _, slot := range deleteSlots.Difference(effective).List() {
+			msg := fmt.Sprintf("ordinal %d conflicts with %d replicas, only the ordinals less than %d can be deleted", slot, c.replicas, maxReplicaCount)
+			if inSpec.Has(slot) {
+				allErrs = append(allErrs, field.Invalid(c.fldPath, c.specSlots, msg))
+			} else {
+				allErrs = append(allErrs, field.Invalid(annPath.Key(c.annKey), tc.Annotations[c.annKey], msg))
+			}
+		}
+	}
+	return allErrs
+}
+
 func validateDeleteSlots(annotations map[string]string, key string, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if annotations != nil {
@@ -640,6 +1828,75 @@ func validateService(spec *v1alpha1.ServiceSpec, fldPath *field.Path) field.Erro
 	return allErrs
 }
 
+// maxClientIPServiceAffinitySeconds is the max timeout of the ClientIP session affinity allowed by Kubernetes
+const maxClientIPServiceAffinitySeconds = 86400
+
+// validateTiDBService validates the traffic policies and the session affinity of the TiDB service, the topology
+// aware hints are ignored by kube-proxy with the Local traffic policies, so they are not allowed to be set together
+func validateTiDBService(spec *v1alpha1.TiDBServiceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	internalLocal := false
+	if spec.InternalTrafficPolicy != nil {
+		switch *spec.InternalTrafficPolicy {
+		case v1alpha1.ServiceInternalTrafficPolicyCluster:
+		case v1alpha1.ServiceInternalTrafficPolicyLocal:
+			internalLocal = true
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("internalTrafficPolicy"), *spec.InternalTrafficPolicy,
+				[]string{string(v1alpha1.ServiceInternalTrafficPolicyCluster), string(v1alpha1.ServiceInternalTrafficPolicyLocal)}))
+		}
+	}
+	externalLocal := spec.ExternalTrafficPolicy != nil && *spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal
+	if spec.TopologyAwareHints && (internalLocal || externalLocal) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("topologyAwareHints"),
+			"topology aware hints are ignored with the Local internal or external traffic policy"))
+	}
+
+	switch spec.SessionAffinity {
+	case "", corev1.ServiceAffinityNone:
+		if spec.SessionAffinityConfig != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("sessionAffinityConfig"),
+				fmt.Sprintf("must not be set when sessionAffinity is not %s", corev1.ServiceAffinityClientIP)))
+		}
+	case corev1.ServiceAffinityClientIP:
+		if config := spec.SessionAffinityConfig; config != nil && config.ClientIP != nil && config.ClientIP.TimeoutSeconds != nil {
+			timeout := *config.ClientIP.TimeoutSeconds
+			if timeout <= 0 || timeout > maxClientIPServiceAffinitySeconds {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("sessionAffinityConfig", "clientIP", "timeoutSeconds"), timeout,
+					fmt.Sprintf("must be greater than 0 and less than or equal to %d", maxClientIPServiceAffinitySeconds)))
+			}
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("sessionAffinity"), spec.SessionAffinity,
+			[]string{string(corev1.ServiceAffinityNone), string(corev1.ServiceAffinityClientIP)}))
+	}
+	return allErrs
+}
+
+// validateTiDBPerPodService validates that the per-pod services don't specify the IPs and the node ports, which
+// can't be shared by the services of the Pods
+func validateTiDBPerPodService(spec *v1alpha1.TiDBServiceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.ClusterIP != nil && *spec.ClusterIP != "" && *spec.ClusterIP != corev1.ClusterIPNone {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("clusterIP"), "must be empty or None for the per-pod services"))
+	}
+	if spec.LoadBalancerIP != nil && *spec.LoadBalancerIP != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("loadBalancerIP"), "must not be set for the per-pod services"))
+	}
+	if spec.GetMySQLNodePort() != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("mysqlNodePort"), "must not be set for the per-pod services"))
+	}
+	if spec.GetStatusNodePort() != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("statusNodePort"), "must not be set for the per-pod services"))
+	}
+	for i, port := range spec.AdditionalPorts {
+		if port.NodePort != 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("additionalPorts").Index(i).Child("nodePort"), "must not be set for the per-pod services"))
+		}
+	}
+	return allErrs
+}
+
 // This validate will make sure targetPath:
 // 1. is not abs path
 // 2. does not have any element which is ".."
@@ -683,6 +1940,25 @@ func validateTimeDurationStr(timeStr *string, fldPath *field.Path) field.ErrorLi
 	return allErrs
 }
 
+// validatePort validates that the port is a valid port number and doesn't conflict with the other ports
+// of the component, nil means the default port is used.
+func validatePort(port *int32, fldPath *field.Path, otherPorts ...int32) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if port == nil {
+		return allErrs
+	}
+	if *port < 1 || *port > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath, *port, "must be between 1 and 65535, inclusive"))
+		return allErrs
+	}
+	for _, other := range otherPorts {
+		if *port == other {
+			allErrs = append(allErrs, field.Invalid(fldPath, *port, fmt.Sprintf("conflicts with port %d of the component", other)))
+		}
+	}
+	return allErrs
+}
+
 // validatePromDurationStr validate prometheus duration, Units Supported: y, w, d, h, m, s, ms.
 func validatePromDurationStr(timeStr *string, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
@@ -16,6 +16,7 @@ package validation
 import (
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
@@ -23,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/pointer"
 )
@@ -412,6 +414,116 @@ func TestValidateService(t *testing.T) {
 	}
 }
 
+func TestValidateTiDBService(t *testing.T) {
+	g := NewGomegaWithT(t)
+	local := v1alpha1.ServiceInternalTrafficPolicyLocal
+	zone := v1alpha1.ServiceInternalTrafficPolicyType("Zone")
+	externalLocal := corev1.ServiceExternalTrafficPolicyTypeLocal
+	tests := []struct {
+		name           string
+		spec           v1alpha1.TiDBServiceSpec
+		expectedErrors int
+	}{
+		{
+			name: "topology aware hints",
+			spec: v1alpha1.TiDBServiceSpec{TopologyAwareHints: true},
+		},
+		{
+			name: "local internal traffic policy with client IP session affinity",
+			spec: v1alpha1.TiDBServiceSpec{
+				InternalTrafficPolicy: &local,
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: &corev1.SessionAffinityConfig{ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: pointer.Int32Ptr(3600)}},
+			},
+		},
+		{
+			name:           "unsupported internal traffic policy",
+			spec:           v1alpha1.TiDBServiceSpec{InternalTrafficPolicy: &zone},
+			expectedErrors: 1,
+		},
+		{
+			name:           "topology aware hints with local internal traffic policy",
+			spec:           v1alpha1.TiDBServiceSpec{TopologyAwareHints: true, InternalTrafficPolicy: &local},
+			expectedErrors: 1,
+		},
+		{
+			name:           "topology aware hints with local external traffic policy",
+			spec:           v1alpha1.TiDBServiceSpec{TopologyAwareHints: true, ExternalTrafficPolicy: &externalLocal},
+			expectedErrors: 1,
+		},
+		{
+			name: "session affinity config without client IP session affinity",
+			spec: v1alpha1.TiDBServiceSpec{
+				SessionAffinityConfig: &corev1.SessionAffinityConfig{ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: pointer.Int32Ptr(3600)}},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name: "session affinity timeout out of range",
+			spec: v1alpha1.TiDBServiceSpec{
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: &corev1.SessionAffinityConfig{ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: pointer.Int32Ptr(86401)}},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name:           "unsupported session affinity",
+			spec:           v1alpha1.TiDBServiceSpec{SessionAffinity: "Cookie"},
+			expectedErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateTiDBService(&tt.spec, field.NewPath("spec", "tidb", "service"))
+			g.Expect(errs).To(HaveLen(tt.expectedErrors), "%v", errs)
+		})
+	}
+}
+
+func TestValidateTiDBPerPodService(t *testing.T) {
+	g := NewGomegaWithT(t)
+	mysqlNodePort, statusNodePort := 30000, 30001
+	tests := []struct {
+		name           string
+		spec           v1alpha1.TiDBServiceSpec
+		expectedErrors int
+	}{
+		{
+			name: "load balancer",
+			spec: v1alpha1.TiDBServiceSpec{ServiceSpec: v1alpha1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}},
+		},
+		{
+			name: "headless",
+			spec: v1alpha1.TiDBServiceSpec{ServiceSpec: v1alpha1.ServiceSpec{ClusterIP: pointer.StringPtr(corev1.ClusterIPNone)}},
+		},
+		{
+			name: "shared IPs",
+			spec: v1alpha1.TiDBServiceSpec{ServiceSpec: v1alpha1.ServiceSpec{
+				Type:           corev1.ServiceTypeLoadBalancer,
+				ClusterIP:      pointer.StringPtr("10.0.0.10"),
+				LoadBalancerIP: pointer.StringPtr("1.1.1.1"),
+			}},
+			expectedErrors: 2,
+		},
+		{
+			name: "shared node ports",
+			spec: v1alpha1.TiDBServiceSpec{
+				ServiceSpec:     v1alpha1.ServiceSpec{Type: corev1.ServiceTypeNodePort},
+				MySQLNodePort:   &mysqlNodePort,
+				StatusNodePort:  &statusNodePort,
+				AdditionalPorts: []corev1.ServicePort{{Name: "extra", Port: 8080, NodePort: 30002}},
+			},
+			expectedErrors: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateTiDBPerPodService(&tt.spec, field.NewPath("spec", "tidb", "perPodService"))
+			g.Expect(errs).To(HaveLen(tt.expectedErrors), "%v", errs)
+		})
+	}
+}
+
 func TestValidateTidbMonitor(t *testing.T) {
 	g := NewGomegaWithT(t)
 	tests := []struct {
@@ -448,6 +560,243 @@ func TestValidateTidbMonitor(t *testing.T) {
 	}
 }
 
+func TestValidateSilenceWindows(t *testing.T) {
+	successCases := [][]v1alpha1.SilenceWindow{
+		{},
+		{
+			{Name: "backup", Schedule: "0 2 * * *", Duration: "2h", Matchers: map[string]string{"alertname": "TiKV_.*"}},
+			{Name: "upgrade", Schedule: "0 4 * * 6", Duration: "30m", Matchers: map[string]string{"env": "prod", "job": "tikv"}, Comment: "weekly upgrade"},
+		},
+	}
+
+	for _, c := range successCases {
+		if errs := validateSilenceWindows(c, field.NewPath("silenceWindows")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := [][]v1alpha1.SilenceWindow{
+		{{Schedule: "@daily", Duration: "1h", Matchers: map[string]string{"alertname": "a"}}},
+		{
+			{Name: "backup", Schedule: "@daily", Duration: "1h", Matchers: map[string]string{"alertname": "a"}},
+			{Name: "backup", Schedule: "@weekly", Duration: "1h", Matchers: map[string]string{"alertname": "a"}},
+		},
+		{{Name: "backup", Duration: "1h", Matchers: map[string]string{"alertname": "a"}}},
+		{{Name: "backup", Schedule: "@daily", Duration: "1d", Matchers: map[string]string{"alertname": "a"}}},
+		{{Name: "backup", Schedule: "@daily", Duration: "-1h", Matchers: map[string]string{"alertname": "a"}}},
+		{{Name: "backup", Schedule: "@daily", Duration: "1h"}},
+		{{Name: "backup", Schedule: "@daily", Duration: "1h", Matchers: map[string]string{"alert-name": "a"}}},
+		{{Name: "backup", Schedule: "@daily", Duration: "1h", Matchers: map[string]string{"alertname": "TiKV_("}}},
+	}
+
+	for _, c := range errorCases {
+		if errs := validateSilenceWindows(c, field.NewPath("silenceWindows")); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateRemoteWrites(t *testing.T) {
+	secretRef := func(name, key string) corev1.SecretKeySelector {
+		return corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}
+	}
+	successCases := [][]*v1alpha1.RemoteWriteSpec{
+		{},
+		{
+			{URL: "http://mimir:9009/api/v1/push", WriteRelabelConfigs: []v1alpha1.RelabelConfig{{Regex: "tikv_.*", Action: "keep"}}},
+			{
+				URL:       "https://cortex/api/v1/push",
+				BasicAuth: &v1alpha1.BasicAuth{Username: secretRef("auth", "username"), Password: secretRef("auth", "password")},
+				TLSConfig: &v1alpha1.TLSConfig{SafeTLSConfig: v1alpha1.SafeTLSConfig{
+					CA:        v1alpha1.SecretOrConfigMap{ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"}},
+					Cert:      v1alpha1.SecretOrConfigMap{Secret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls"}, Key: "tls.crt"}},
+					KeySecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls"}, Key: "tls.key"},
+				}},
+			},
+		},
+	}
+
+	for _, c := range successCases {
+		if errs := validateRemoteWrites(c, field.NewPath("remoteWrite")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := [][]*v1alpha1.RemoteWriteSpec{
+		{{}},
+		{{URL: "http://mimir:9009/api/v1/push", WriteRelabelConfigs: []v1alpha1.RelabelConfig{{Regex: "tikv_("}}}},
+		{{URL: "http://mimir:9009/api/v1/push", ProxyURL: pointer.StringPtr("http://proxy:%zz")}},
+		{{URL: "http://mimir:9009/api/v1/push", BasicAuth: &v1alpha1.BasicAuth{Username: secretRef("auth", "username")}}},
+		{{URL: "http://mimir:9009/api/v1/push", BasicAuth: &v1alpha1.BasicAuth{Username: secretRef("auth", "username"), Password: secretRef("auth", "password")}, BearerTokenFile: "/token"}},
+		{{URL: "http://mimir:9009/api/v1/push", TLSConfig: &v1alpha1.TLSConfig{SafeTLSConfig: v1alpha1.SafeTLSConfig{
+			CA: v1alpha1.SecretOrConfigMap{
+				Secret:    &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+				ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+			},
+		}}}},
+	}
+
+	for _, c := range errorCases {
+		if errs := validateRemoteWrites(c, field.NewPath("remoteWrite")); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateScrapeConfigs(t *testing.T) {
+	successCases := []map[string]v1alpha1.ScrapeConfig{
+		{},
+		{
+			"tikv": {Interval: pointer.StringPtr("1m"), Timeout: pointer.StringPtr("30s")},
+			"pd":   {Interval: pointer.StringPtr("5s")},
+			"tidb": {Timeout: pointer.StringPtr("5s")},
+		},
+	}
+
+	for _, c := range successCases {
+		if errs := validateScrapeConfigs(c, field.NewPath("scrapeConfigs")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []map[string]v1alpha1.ScrapeConfig{
+		{"tikv-server": {Interval: pointer.StringPtr("1m")}},
+		{"tikv": {Interval: pointer.StringPtr("1 minute")}},
+		{"tikv": {Interval: pointer.StringPtr("0s")}},
+		{"tikv": {Interval: pointer.StringPtr("30s"), Timeout: pointer.StringPtr("30s")}},
+		{"tikv": {Interval: pointer.StringPtr("30s"), Timeout: pointer.StringPtr("1m")}},
+		{"pd": {Timeout: pointer.StringPtr("15s")}},
+	}
+
+	for _, c := range errorCases {
+		if errs := validateScrapeConfigs(c, field.NewPath("scrapeConfigs")); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateMonitorReplicas(t *testing.T) {
+	successCases := []v1alpha1.TidbMonitorSpec{
+		{},
+		{Replicas: pointer.Int32Ptr(2)},
+		{Replicas: pointer.Int32Ptr(2), ReplicaExternalLabelName: pointer.StringPtr("replica"), Thanos: &v1alpha1.ThanosSpec{Query: &v1alpha1.ThanosQuerySpec{}}},
+		{Replicas: pointer.Int32Ptr(1), ReplicaExternalLabelName: pointer.StringPtr("")},
+	}
+
+	for _, c := range successCases {
+		if errs := validateMonitorReplicas(&c, field.NewPath("spec")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TidbMonitorSpec{
+		{Replicas: pointer.Int32Ptr(2), ReplicaExternalLabelName: pointer.StringPtr("")},
+		{ReplicaExternalLabelName: pointer.StringPtr(""), Thanos: &v1alpha1.ThanosSpec{Query: &v1alpha1.ThanosQuerySpec{}}},
+	}
+
+	for _, c := range errorCases {
+		if errs := validateMonitorReplicas(&c, field.NewPath("spec")); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateOpsCommand(t *testing.T) {
+	newOpsCommand := func(typ v1alpha1.OpsCommandType, args ...string) *v1alpha1.OpsCommand {
+		return &v1alpha1.OpsCommand{Spec: v1alpha1.OpsCommandSpec{Type: typ, Cluster: "demo", Args: args}}
+	}
+	successCases := []*v1alpha1.OpsCommand{
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "store", "limit"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "config", "show", "replication"),
+		newOpsCommand(v1alpha1.OpsCommandTypeDMCtl, "query-status", "task-1"),
+		newOpsCommand(v1alpha1.OpsCommandTypeBRDebug, "backupmeta", "validate", "-s", "s3://backup/prefix"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "store"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "store", "1"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "region", "2", "--jq", ".id"),
+		newOpsCommand(v1alpha1.OpsCommandTypeDMCtl, "query-status"),
+	}
+
+	for _, c := range successCases {
+		if errs := ValidateOpsCommand(c); len(errs) != 0 {
+			t.Errorf("expected success for %v: %v", c.Spec, errs)
+		}
+	}
+
+	noCluster := newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "store")
+	noCluster.Spec.Cluster = ""
+	errorCases := []*v1alpha1.OpsCommand{
+		noCluster,
+		newOpsCommand("tikv-ctl", "compact"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "unsafe", "remove-failed-stores", "1"),
+		newOpsCommand(v1alpha1.OpsCommandTypeDMCtl, "stop-task", "task-1"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "store", "-u", "http://other-pd:2379"),
+		newOpsCommand(v1alpha1.OpsCommandTypeDMCtl, "query-status", "--master-addr=other:8261"),
+		newOpsCommand(v1alpha1.OpsCommandTypeBRDebug, "checksum", "--ca", "/tmp/ca.crt"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "store", "-i"),
+		// the mutating subcommands
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "store", "delete", "1"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "store", "limit", "1", "5"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "member", "delete", "name", "pd-0"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "operator", "add", "transfer-leader", "1", "2"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "config", "set", "max-replicas", "5"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "scheduler", "remove", "balance-leader-scheduler"),
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "region", "remove", "1"),
+		newOpsCommand(v1alpha1.OpsCommandTypeBRDebug, "reset-pd-config-as-default"),
+		newOpsCommand(v1alpha1.OpsCommandTypeDMCtl, "pause-task", "task-1"),
+		newOpsCommand(v1alpha1.OpsCommandTypeDMCtl, "resume-task", "task-1"),
+		// the subcommand can't be hidden behind the flags
+		newOpsCommand(v1alpha1.OpsCommandTypePDCtl, "store", "-d", "delete", "1"),
+	}
+
+	for _, c := range errorCases {
+		if errs := ValidateOpsCommand(c); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c.Spec)
+		}
+	}
+}
+
+func TestValidateDMTask(t *testing.T) {
+	newDMTask := func(update func(dt *v1alpha1.DMTask)) *v1alpha1.DMTask {
+		dt := &v1alpha1.DMTask{Spec: v1alpha1.DMTaskSpec{
+			Cluster: "demo",
+			Sources: []v1alpha1.DMTaskSource{{Name: "mysql-01", Host: "mysql", Port: 3306, User: "root", SecretName: "mysql"}},
+			Config:  `{"task_mode": "all"}`,
+		}}
+		if update != nil {
+			update(dt)
+		}
+		return dt
+	}
+	successCases := []*v1alpha1.DMTask{
+		newDMTask(nil),
+		newDMTask(func(dt *v1alpha1.DMTask) { dt.Spec.Sources = nil }),
+		newDMTask(func(dt *v1alpha1.DMTask) { dt.Spec.Stage = v1alpha1.DMTaskStagePaused }),
+	}
+
+	for _, c := range successCases {
+		if errs := ValidateDMTask(c); len(errs) != 0 {
+			t.Errorf("expected success for %v: %v", c.Spec, errs)
+		}
+	}
+
+	errorCases := []*v1alpha1.DMTask{
+		newDMTask(func(dt *v1alpha1.DMTask) { dt.Spec.Cluster = "" }),
+		newDMTask(func(dt *v1alpha1.DMTask) { dt.Spec.Config = "" }),
+		newDMTask(func(dt *v1alpha1.DMTask) { dt.Spec.Config = "task_mode: all" }),
+		newDMTask(func(dt *v1alpha1.DMTask) { dt.Spec.Sources = append(dt.Spec.Sources, dt.Spec.Sources[0]) }),
+		newDMTask(func(dt *v1alpha1.DMTask) { dt.Spec.Sources[0].Host = "" }),
+		newDMTask(func(dt *v1alpha1.DMTask) { dt.Spec.Sources[0].Port = 0 }),
+		newDMTask(func(dt *v1alpha1.DMTask) { dt.Spec.Stage = v1alpha1.DMTaskStageFinished }),
+	}
+
+	for _, c := range errorCases {
+		if errs := ValidateDMTask(c); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c.Spec)
+		}
+	}
+}
+
 func TestValidateDMCluster(t *testing.T) {
 	g := NewGomegaWithT(t)
 	tests := []struct {
@@ -455,6 +804,8 @@ func TestValidateDMCluster(t *testing.T) {
 		version           string
 		masterReplicas    int32
 		masterStorageSize string
+		storageVolumes    []v1alpha1.StorageVolume
+		sourcePlacements  []v1alpha1.DMSourcePlacement
 		expectedError     string
 	}{
 		{
@@ -475,6 +826,41 @@ func TestValidateDMCluster(t *testing.T) {
 			masterStorageSize: "10Gi",
 			expectedError:     "",
 		},
+		{
+			name:              "invalid storageVolumes",
+			version:           "nightly",
+			masterReplicas:    3,
+			masterStorageSize: "10Gi",
+			storageVolumes:    []v1alpha1.StorageVolume{{Name: "relay", StorageSize: "1Z"}},
+			expectedError:     `value of "storageSize" format not supported`,
+		},
+		{
+			name:              "valid storageVolumes",
+			version:           "nightly",
+			masterReplicas:    3,
+			masterStorageSize: "10Gi",
+			storageVolumes:    []v1alpha1.StorageVolume{{Name: "relay", StorageSize: "20Gi", MountPath: "/var/lib/relay"}},
+			expectedError:     "",
+		},
+		{
+			name:              "source placement without the node selector and the zone",
+			version:           "nightly",
+			masterReplicas:    3,
+			masterStorageSize: "10Gi",
+			sourcePlacements:  []v1alpha1.DMSourcePlacement{{Source: "mysql-1"}},
+			expectedError:     "one of nodeSelector and zone must be set",
+		},
+		{
+			name:              "valid source placements",
+			version:           "nightly",
+			masterReplicas:    3,
+			masterStorageSize: "10Gi",
+			sourcePlacements: []v1alpha1.DMSourcePlacement{
+				{Source: "mysql-1", Zone: "us-west-1a"},
+				{Source: "mysql-2", NodeSelector: map[string]string{"dm-source": "mysql-2"}},
+			},
+			expectedError: "",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -482,10 +868,14 @@ func TestValidateDMCluster(t *testing.T) {
 			dc.Spec.Version = tt.version
 			dc.Spec.Master.Replicas = tt.masterReplicas
 			dc.Spec.Master.StorageSize = tt.masterStorageSize
+			dc.Spec.Worker.StorageVolumes = tt.storageVolumes
+			dc.Spec.Worker.SourcePlacements = tt.sourcePlacements
 			err := ValidateDMCluster(dc)
 			if tt.expectedError != "" {
 				g.Expect(len(err)).Should(Equal(1))
 				g.Expect(err[0].Detail).To(ContainSubstring(tt.expectedError))
+			} else {
+				g.Expect(err).To(BeEmpty())
 			}
 		})
 	}
@@ -656,3 +1046,976 @@ func TestValidatePDAddresses(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateMasterExternalMembers(t *testing.T) {
+	successCases := [][]string{
+		nil,
+		{
+			"http://demo-dm-master-0.demo-dm-master-peer.dm.svc.cluster-a.local:8261",
+			"https://1.2.3.4:8261",
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validateMasterExternalMembers(c, field.NewPath("externalMembers"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := [][]string{
+		{
+			"demo-dm-master-0.demo-dm-master-peer:8261",
+		},
+		{
+			"http://1.2.3.4:8261",
+			"tcp://1.2.3.4:8261",
+		},
+		{
+			"http://",
+		},
+	}
+
+	for _, c := range errorCases {
+		errs := validateMasterExternalMembers(c, field.NewPath("externalMembers"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", c)
+		}
+	}
+}
+
+func TestValidateArchBaseImages(t *testing.T) {
+	successCases := []map[v1alpha1.Architecture]string{
+		nil,
+		{
+			v1alpha1.ArchitectureAMD64: "pingcap/tikv",
+			v1alpha1.ArchitectureARM64: "example.com/tikv-arm64",
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validateArchBaseImages(c, field.NewPath("archBaseImages"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []map[v1alpha1.Architecture]string{
+		{
+			"s390x": "pingcap/tikv",
+		},
+		{
+			v1alpha1.ArchitectureARM64: "",
+		},
+	}
+
+	for _, c := range errorCases {
+		errs := validateArchBaseImages(c, field.NewPath("archBaseImages"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateHelperSpec(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	successCases := []v1alpha1.HelperSpec{
+		{},
+		{Image: pointer.StringPtr("busybox:1.34.1")},
+		{Image: pointer.StringPtr("busybox:1.34.1"), Digest: pointer.StringPtr(digest)},
+		{Image: pointer.StringPtr("busybox@" + digest), Digest: pointer.StringPtr(digest)},
+	}
+
+	for _, c := range successCases {
+		errs := validateHelperSpec(&c, field.NewPath("helper"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.HelperSpec{
+		{Digest: pointer.StringPtr("sha256:abc")},
+		{Digest: pointer.StringPtr("md5:" + strings.Repeat("a", 64))},
+		{Image: pointer.StringPtr("busybox@sha256:" + strings.Repeat("b", 64)), Digest: pointer.StringPtr(digest)},
+	}
+
+	for _, c := range errorCases {
+		errs := validateHelperSpec(&c, field.NewPath("helper"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateConfigDriftSpec(t *testing.T) {
+	successCases := []v1alpha1.ConfigDriftSpec{
+		{},
+		{Policy: v1alpha1.ConfigDriftPolicyReport},
+		{Policy: v1alpha1.ConfigDriftPolicyRevert, Interval: &metav1.Duration{Duration: time.Minute}},
+	}
+
+	for _, c := range successCases {
+		errs := validateConfigDriftSpec(&c, field.NewPath("configDrift"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.ConfigDriftSpec{
+		{Policy: "Ignore"},
+		{Interval: &metav1.Duration{}},
+		{Interval: &metav1.Duration{Duration: -time.Minute}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateConfigDriftSpec(&c, field.NewPath("configDrift"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateClockSkewSpec(t *testing.T) {
+	successCases := []v1alpha1.ClockSkewSpec{
+		{},
+		{Policy: v1alpha1.ClockSkewPolicyReport, Interval: &metav1.Duration{Duration: time.Minute}},
+		{Policy: v1alpha1.ClockSkewPolicyCordon, MaxSkew: &metav1.Duration{Duration: time.Second}, CordonThreshold: &metav1.Duration{Duration: 5 * time.Second}},
+	}
+
+	for _, c := range successCases {
+		errs := validateClockSkewSpec(&c, field.NewPath("clockSkew"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.ClockSkewSpec{
+		{Policy: "Evict"},
+		{MaxSkew: &metav1.Duration{}},
+		{CordonThreshold: &metav1.Duration{Duration: -time.Second}},
+		{MaxSkew: &metav1.Duration{Duration: 5 * time.Second}, CordonThreshold: &metav1.Duration{Duration: time.Second}},
+		{Interval: &metav1.Duration{}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateClockSkewSpec(&c, field.NewPath("clockSkew"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateVersionSkewSpec(t *testing.T) {
+	successCases := []v1alpha1.VersionSkewSpec{
+		{},
+		{MaxMinorSkew: 1, GracePeriod: &metav1.Duration{Duration: time.Hour}},
+		{GracePeriod: &metav1.Duration{}},
+	}
+
+	for _, c := range successCases {
+		errs := validateVersionSkewSpec(&c, field.NewPath("versionSkew"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.VersionSkewSpec{
+		{MaxMinorSkew: -1},
+		{GracePeriod: &metav1.Duration{Duration: -time.Minute}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateVersionSkewSpec(&c, field.NewPath("versionSkew"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTombstoneStoreGCSpec(t *testing.T) {
+	successCases := []v1alpha1.TombstoneStoreGCSpec{
+		{},
+		{Interval: &metav1.Duration{Duration: time.Hour}, DryRun: true},
+	}
+
+	for _, c := range successCases {
+		errs := validateTombstoneStoreGCSpec(&c, field.NewPath("tombstoneStoreGC"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TombstoneStoreGCSpec{
+		{Interval: &metav1.Duration{}},
+		{Interval: &metav1.Duration{Duration: -time.Minute}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateTombstoneStoreGCSpec(&c, field.NewPath("tombstoneStoreGC"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateAutoResumeSpec(t *testing.T) {
+	successCases := []v1alpha1.AutoResumeSpec{
+		{},
+		{Until: &metav1.Time{Time: time.Now()}},
+		{After: &metav1.Duration{Duration: time.Hour}},
+	}
+
+	for _, c := range successCases {
+		errs := validateAutoResumeSpec(&c, field.NewPath("autoResume"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.AutoResumeSpec{
+		{Until: &metav1.Time{Time: time.Now()}, After: &metav1.Duration{Duration: time.Hour}},
+		{After: &metav1.Duration{}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateAutoResumeSpec(&c, field.NewPath("autoResume"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateCertRotationSpec(t *testing.T) {
+	successCases := []v1alpha1.CertRotationSpec{
+		{Issuer: v1alpha1.CertIssuerCertManager},
+		{Issuer: v1alpha1.CertIssuerCertManager, RenewBefore: &metav1.Duration{Duration: 24 * time.Hour}},
+		{Issuer: v1alpha1.CertIssuerCSR, SignerName: "example.com/tidb"},
+	}
+
+	for _, c := range successCases {
+		errs := validateCertRotationSpec(&c, field.NewPath("certRotation"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.CertRotationSpec{
+		{},
+		{Issuer: "vault"},
+		{Issuer: v1alpha1.CertIssuerCSR},
+		{Issuer: v1alpha1.CertIssuerCertManager, RenewBefore: &metav1.Duration{}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateCertRotationSpec(&c, field.NewPath("certRotation"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateNotificationSpec(t *testing.T) {
+	secretRef := corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "notification"}, Key: "key"}
+	webhook := &v1alpha1.WebhookNotificationSink{URL: "https://example.com/notify"}
+	successCases := []v1alpha1.NotificationSpec{
+		{},
+		{Sinks: []v1alpha1.NotificationSink{{Name: "webhook", Webhook: webhook}}},
+		{Sinks: []v1alpha1.NotificationSink{
+			{Name: "slack", MinSeverity: v1alpha1.NotificationSeverityWarning, Slack: &v1alpha1.SlackNotificationSink{WebhookURLSecretRef: secretRef}},
+			{Name: "pagerduty", Events: []v1alpha1.NotificationEventType{v1alpha1.NotificationEventBackupFailed}, PagerDuty: &v1alpha1.PagerDutyNotificationSink{RoutingKeySecretRef: secretRef}},
+		}},
+	}
+
+	for _, c := range successCases {
+		errs := ValidateNotificationSpec(&c, field.NewPath("notifications"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.NotificationSpec{
+		{Sinks: []v1alpha1.NotificationSink{{Webhook: webhook}}},
+		{Sinks: []v1alpha1.NotificationSink{{Name: "webhook", Webhook: webhook}, {Name: "webhook", Webhook: webhook}}},
+		{Sinks: []v1alpha1.NotificationSink{{Name: "webhook", MinSeverity: "Debug", Webhook: webhook}}},
+		{Sinks: []v1alpha1.NotificationSink{{Name: "none"}}},
+		{Sinks: []v1alpha1.NotificationSink{{Name: "both", Webhook: webhook, Slack: &v1alpha1.SlackNotificationSink{WebhookURLSecretRef: secretRef}}}},
+		{Sinks: []v1alpha1.NotificationSink{{Name: "webhook", Webhook: &v1alpha1.WebhookNotificationSink{URL: "example.com"}}}},
+		{Sinks: []v1alpha1.NotificationSink{{Name: "slack", Slack: &v1alpha1.SlackNotificationSink{}}}},
+	}
+
+	for _, c := range errorCases {
+		errs := ValidateNotificationSpec(&c, field.NewPath("notifications"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateMaintenanceSpec(t *testing.T) {
+	successCases := []v1alpha1.MaintenanceSpec{
+		{},
+		{Tasks: []v1alpha1.MaintenanceTask{
+			{Name: "compact", Schedule: "0 2 * * 0", CompactTiKV: &v1alpha1.CompactTiKVTask{}},
+			{Name: "analyze", Schedule: "0 3 * * *", AnalyzeTable: &v1alpha1.AnalyzeTableTask{Tables: []string{"test.t1", "test.t2"}}},
+			{Name: "store-limit", Schedule: "@hourly", ResetStoreLimit: &v1alpha1.ResetStoreLimitTask{Rate: pointer.Int32Ptr(15)}},
+		}},
+		{Tasks: []v1alpha1.MaintenanceTask{
+			{Name: "compact", Schedule: "0 2 * * 0", CompactTiKV: &v1alpha1.CompactTiKVTask{DB: "raft", ColumnFamilies: []string{"default"}}},
+		}},
+		{Tasks: []v1alpha1.MaintenanceTask{
+			{Name: "compact", Schedule: "0 2 * * 0", Window: &metav1.Duration{Duration: 2 * time.Hour},
+				CompactTiKV: &v1alpha1.CompactTiKVTask{Tables: []string{"test.t1"}, KeyspaceIDs: []uint32{1}}},
+		}},
+	}
+
+	for _, c := range successCases {
+		errs := validateMaintenanceSpec(&c, field.NewPath("maintenance"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.MaintenanceSpec{
+		{Tasks: []v1alpha1.MaintenanceTask{{Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "Compact", Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "compact-the-tikv-stores-weekly", Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{
+			{Name: "compact", Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{}},
+			{Name: "compact", Schedule: "@weekly", CompactTiKV: &v1alpha1.CompactTiKVTask{}},
+		}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "compact", CompactTiKV: &v1alpha1.CompactTiKVTask{}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "none", Schedule: "@daily"}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "both", Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{}, ResetStoreLimit: &v1alpha1.ResetStoreLimitTask{}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "compact", Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{DB: "data"}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "compact", Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{ColumnFamilies: []string{"raft"}}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "compact", Schedule: "@daily", Window: &metav1.Duration{}, CompactTiKV: &v1alpha1.CompactTiKVTask{}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "compact", Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{Tables: []string{"t1"}}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "compact", Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{KeyspaceIDs: []uint32{1 << 24}}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "compact", Schedule: "@daily", CompactTiKV: &v1alpha1.CompactTiKVTask{DB: "raft", Tables: []string{"test.t1"}}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "analyze", Schedule: "@daily", AnalyzeTable: &v1alpha1.AnalyzeTableTask{}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "analyze", Schedule: "@daily", AnalyzeTable: &v1alpha1.AnalyzeTableTask{Tables: []string{"t1"}}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "analyze", Schedule: "@daily", AnalyzeTable: &v1alpha1.AnalyzeTableTask{Tables: []string{"test.t1`; DROP TABLE t2"}}}}},
+		{Tasks: []v1alpha1.MaintenanceTask{{Name: "store-limit", Schedule: "@daily", ResetStoreLimit: &v1alpha1.ResetStoreLimitTask{Rate: pointer.Int32Ptr(0)}}}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateMaintenanceSpec(&c, field.NewPath("maintenance"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateDiagnosticsSpec(t *testing.T) {
+	s3 := v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "diagnostics"}}
+	successCases := []v1alpha1.DiagnosticsSpec{
+		{StorageProvider: s3},
+		{
+			Triggers:            []v1alpha1.DiagnosticsTrigger{v1alpha1.DiagnosticsTriggerFailover, v1alpha1.DiagnosticsTriggerUpgradeStuck},
+			UpgradeStuckTimeout: pointer.StringPtr("1h"),
+			MinInterval:         pointer.StringPtr("30m"),
+			LogTailLines:        pointer.Int64Ptr(100),
+			StorageProvider:     s3,
+		},
+		{StorageProvider: v1alpha1.StorageProvider{Gcs: &v1alpha1.GcsStorageProvider{Bucket: "diagnostics"}}},
+		{StorageProvider: v1alpha1.StorageProvider{Local: &v1alpha1.LocalStorageProvider{VolumeMount: corev1.VolumeMount{Name: "nfs", MountPath: "/nfs"}}}},
+	}
+
+	for _, c := range successCases {
+		errs := validateDiagnosticsSpec(&c, field.NewPath("diagnostics"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.DiagnosticsSpec{
+		{},
+		{Triggers: []v1alpha1.DiagnosticsTrigger{"Crash"}, StorageProvider: s3},
+		{UpgradeStuckTimeout: pointer.StringPtr("1"), StorageProvider: s3},
+		{MinInterval: pointer.StringPtr("-1h"), StorageProvider: s3},
+		{LogTailLines: pointer.Int64Ptr(0), StorageProvider: s3},
+		{StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{}}},
+		{StorageProvider: v1alpha1.StorageProvider{Local: &v1alpha1.LocalStorageProvider{}}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateDiagnosticsSpec(&c, field.NewPath("diagnostics"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateStorageProvisioningSpec(t *testing.T) {
+	webhook := &v1alpha1.StorageProvisioningWebhook{URL: "http://provisioner.platform:8080/provision"}
+	job := &v1alpha1.StorageProvisioningJob{Image: "platform/lvm-provisioner"}
+	successCases := []v1alpha1.StorageProvisioningSpec{
+		{Webhook: webhook},
+		{
+			Components: []v1alpha1.MemberType{v1alpha1.TiKVMemberType, v1alpha1.TiFlashMemberType},
+			Job:        job,
+			Timeout:    &metav1.Duration{Duration: time.Hour},
+			MaxRetries: pointer.Int32Ptr(0),
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validateStorageProvisioningSpec(&c, field.NewPath("storageProvisioning"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.StorageProvisioningSpec{
+		{},
+		{Webhook: webhook, Job: job},
+		{Webhook: &v1alpha1.StorageProvisioningWebhook{}},
+		{Job: &v1alpha1.StorageProvisioningJob{}},
+		{Components: []v1alpha1.MemberType{v1alpha1.TiDBMemberType}, Job: job},
+		{Webhook: webhook, Timeout: &metav1.Duration{}},
+		{Webhook: webhook, MaxRetries: pointer.Int32Ptr(-1)},
+	}
+
+	for _, c := range errorCases {
+		errs := validateStorageProvisioningSpec(&c, field.NewPath("storageProvisioning"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateDebugArtifactsSpec(t *testing.T) {
+	s3 := v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "artifacts"}}
+	successCases := []v1alpha1.DebugArtifactsSpec{
+		{Image: "pingcap/tidb-backup-manager", StorageProvider: s3},
+		{Image: "pingcap/tidb-backup-manager", Interval: pointer.StringPtr("30s"), DisableHeapProfile: true, StorageProvider: s3},
+		{Image: "pingcap/tidb-backup-manager", StorageProvider: v1alpha1.StorageProvider{Azblob: &v1alpha1.AzblobStorageProvider{Container: "artifacts"}}},
+		{Image: "pingcap/tidb-backup-manager", StorageProvider: v1alpha1.StorageProvider{Local: &v1alpha1.LocalStorageProvider{VolumeMount: corev1.VolumeMount{Name: "nfs", MountPath: "/nfs"}}}},
+	}
+
+	for _, c := range successCases {
+		errs := validateDebugArtifactsSpec(&c, field.NewPath("debugArtifacts"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.DebugArtifactsSpec{
+		{StorageProvider: s3},
+		{Image: "pingcap/tidb-backup-manager"},
+		{Image: "pingcap/tidb-backup-manager", Interval: pointer.StringPtr("1"), StorageProvider: s3},
+		{Image: "pingcap/tidb-backup-manager", StorageProvider: v1alpha1.StorageProvider{Gcs: &v1alpha1.GcsStorageProvider{}}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateDebugArtifactsSpec(&c, field.NewPath("debugArtifacts"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateRelayLogStorageSpec(t *testing.T) {
+	s3 := v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "relay"}}
+	successCases := []v1alpha1.RelayLogStorageSpec{
+		{Image: "pingcap/tidb-backup-manager", StorageProvider: s3},
+		{Image: "pingcap/tidb-backup-manager", RelayDir: "/var/lib/dm-worker/relay", Interval: pointer.StringPtr("1m"), LocalRetention: pointer.StringPtr("24h"), RemoteRetention: pointer.StringPtr("168h"), StorageProvider: s3},
+	}
+
+	for _, c := range successCases {
+		errs := validateRelayLogStorageSpec(&c, field.NewPath("relayLogStorage"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.RelayLogStorageSpec{
+		{StorageProvider: s3},
+		{Image: "pingcap/tidb-backup-manager"},
+		{Image: "pingcap/tidb-backup-manager", RelayDir: "/var/lib/dm-worker-relay", StorageProvider: s3},
+		{Image: "pingcap/tidb-backup-manager", RelayDir: "/var/lib/dm-worker/../relay", StorageProvider: s3},
+		{Image: "pingcap/tidb-backup-manager", LocalRetention: pointer.StringPtr("1"), StorageProvider: s3},
+		{Image: "pingcap/tidb-backup-manager", RemoteRetention: pointer.StringPtr("-1h"), StorageProvider: s3},
+	}
+
+	for _, c := range errorCases {
+		errs := validateRelayLogStorageSpec(&c, field.NewPath("relayLogStorage"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateSpotTerminationSpec(t *testing.T) {
+	successCases := []v1alpha1.SpotTerminationSpec{
+		{},
+		{PreCreateReplacement: true},
+		{Taints: []string{"aws-node-termination-handler/spot-itn", "node.example.com/terminating"}},
+	}
+
+	for _, c := range successCases {
+		if errs := validateSpotTerminationSpec(&c, field.NewPath("spotTermination")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.SpotTerminationSpec{
+		{Taints: []string{""}},
+		{Taints: []string{"node terminating"}},
+		{Taints: []string{"example.com/a/b"}},
+	}
+
+	for _, c := range errorCases {
+		if errs := validateSpotTerminationSpec(&c, field.NewPath("spotTermination")); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidatePodDisruptionBudget(t *testing.T) {
+	successCases := []v1alpha1.PodDisruptionBudgetSpec{
+		{},
+		{MaxUnavailable: intstrPtr(intstr.FromInt(1))},
+		{MaxUnavailable: intstrPtr(intstr.FromString("30%"))},
+	}
+
+	for _, c := range successCases {
+		if errs := validatePodDisruptionBudget(&c, field.NewPath("podDisruptionBudget")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.PodDisruptionBudgetSpec{
+		{MaxUnavailable: intstrPtr(intstr.FromInt(-1))},
+		{MaxUnavailable: intstrPtr(intstr.FromString("one"))},
+	}
+
+	for _, c := range errorCases {
+		if errs := validatePodDisruptionBudget(&c, field.NewPath("podDisruptionBudget")); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTiDBUpgradeStrategy(t *testing.T) {
+	successCases := []v1alpha1.TiDBUpgradeStrategy{
+		{},
+		{MaxUnavailable: intstrPtr(intstr.FromInt(3))},
+		{MaxUnavailable: intstrPtr(intstr.FromString("25%"))},
+	}
+
+	for _, c := range successCases {
+		if errs := validateTiDBUpgradeStrategy(&c, field.NewPath("upgradeStrategy")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TiDBUpgradeStrategy{
+		{MaxUnavailable: intstrPtr(intstr.FromInt(0))},
+		{MaxUnavailable: intstrPtr(intstr.FromString("0%"))},
+		{MaxUnavailable: intstrPtr(intstr.FromString("many"))},
+	}
+
+	for _, c := range errorCases {
+		if errs := validateTiDBUpgradeStrategy(&c, field.NewPath("upgradeStrategy")); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func intstrPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
+
+func TestValidateTopSQLSpec(t *testing.T) {
+	quantity := func(q string) *resource.Quantity {
+		v := resource.MustParse(q)
+		return &v
+	}
+	successCases := []v1alpha1.TopSQLSpec{
+		{},
+		{Enable: pointer.BoolPtr(false)},
+		{RetentionPeriod: &metav1.Duration{Duration: 72 * time.Hour}, StorageSizeLimit: quantity("10Gi")},
+	}
+
+	for _, c := range successCases {
+		if errs := validateTopSQLSpec(&c, field.NewPath("topSQL")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TopSQLSpec{
+		{RetentionPeriod: &metav1.Duration{}},
+		{RetentionPeriod: &metav1.Duration{Duration: -time.Hour}},
+		{StorageSizeLimit: quantity("0")},
+	}
+
+	for _, c := range errorCases {
+		if errs := validateTopSQLSpec(&c, field.NewPath("topSQL")); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTiKVStoreScheduling(t *testing.T) {
+	weight := func(w float64) *float64 { return &w }
+	successCases := []v1alpha1.TiKVStoreScheduling{
+		{},
+		{Ordinals: []int32{0, 1}, LeaderWeight: weight(2), RegionWeight: weight(0)},
+		{StoreLabels: map[string]string{"zone": "a"}, AddPeerLimit: weight(30), RemovePeerLimit: weight(0.5)},
+	}
+
+	for _, c := range successCases {
+		if errs := validateTiKVStoreScheduling(&c, field.NewPath("storeScheduling")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TiKVStoreScheduling{
+		{Ordinals: []int32{-1}},
+		{StoreLabels: map[string]string{"": "a"}},
+		{LeaderWeight: weight(-1)},
+		{RegionWeight: weight(-0.5)},
+		{AddPeerLimit: weight(0)},
+		{RemovePeerLimit: weight(-1)},
+	}
+
+	for _, c := range errorCases {
+		if errs := validateTiKVStoreScheduling(&c, field.NewPath("storeScheduling")); len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTiKVPerPodConfig(t *testing.T) {
+	perPodConfigErrs := func(keys ...string) field.ErrorList {
+		spec := &v1alpha1.TiKVSpec{PerPodConfig: map[string]*v1alpha1.TiKVConfigWraper{}}
+		for _, key := range keys {
+			spec.PerPodConfig[key] = v1alpha1.NewTiKVConfig()
+		}
+		var errs field.ErrorList
+		for _, err := range validateTiKVSpec(spec, field.NewPath("spec", "tikv")) {
+			if strings.HasPrefix(err.Field, "spec.tikv.perPodConfig") {
+				errs = append(errs, err)
+			}
+		}
+		return errs
+	}
+
+	if errs := perPodConfigErrs("0", "12"); len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+	for _, key := range []string{"-1", "a", "tc-tikv-0", ""} {
+		if errs := perPodConfigErrs(key); len(errs) != 1 {
+			t.Errorf("expected failure for %q, got: %v", key, errs)
+		}
+	}
+}
+
+func TestValidateTiKVRegionHealthRefreshInterval(t *testing.T) {
+	intervalErrs := func(interval string) field.ErrorList {
+		spec := &v1alpha1.TiKVSpec{RegionHealthRefreshInterval: &interval}
+		var errs field.ErrorList
+		for _, err := range validateTiKVSpec(spec, field.NewPath("spec", "tikv")) {
+			if err.Field == "spec.tikv.regionHealthRefreshInterval" {
+				errs = append(errs, err)
+			}
+		}
+		return errs
+	}
+
+	for _, interval := range []string{"0", "0s", "30s", "5m"} {
+		if errs := intervalErrs(interval); len(errs) != 0 {
+			t.Errorf("expected success for %q: %v", interval, errs)
+		}
+	}
+	for _, interval := range []string{"-1m", "5", "five minutes"} {
+		if errs := intervalErrs(interval); len(errs) != 1 {
+			t.Errorf("expected failure for %q, got: %v", interval, errs)
+		}
+	}
+}
+
+func TestValidateTimezone(t *testing.T) {
+	successCases := []string{
+		"",
+		"UTC",
+		"Asia/Shanghai",
+		":America/Argentina/Buenos_Aires",
+		"Etc/GMT+8",
+		"CST-8",
+		"<+08>-8",
+		"EST5EDT,M3.2.0,M11.1.0",
+		"CET-1CEST,M3.5.0/2,M10.5.0/3",
+	}
+
+	for _, c := range successCases {
+		if errs := validateTimezone(c, field.NewPath("timezone")); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []string{
+		"Asia Shanghai",
+		"/etc/localtime",
+		"../zoneinfo/UTC",
+		"Asia/Shanghai\n",
+		"CST-8,M3.2.0",
+	}
+	if _, err := time.LoadLocation("Etc/UTC"); err == nil {
+		errorCases = append(errorCases, "Asia/Atlantis")
+	}
+
+	for _, c := range errorCases {
+		if errs := validateTimezone(c, field.NewPath("timezone")); len(errs) == 0 {
+			t.Errorf("expected failure for %q", c)
+		}
+	}
+}
+
+func TestValidateComponentRolloutPacing(t *testing.T) {
+	g := NewGomegaWithT(t)
+	path := field.NewPath("spec", "tikv")
+
+	zero, positive, negative := int32(0), int32(30), int32(-1)
+	g.Expect(validateComponentSpec(&v1alpha1.ComponentSpec{MinReadySeconds: &zero, RollingUpdateIntervalSeconds: &positive}, path)).To(BeEmpty())
+	g.Expect(validateComponentSpec(&v1alpha1.ComponentSpec{MinReadySeconds: &negative}, path)).To(HaveLen(1))
+	g.Expect(validateComponentSpec(&v1alpha1.ComponentSpec{RollingUpdateIntervalSeconds: &negative}, path)).To(HaveLen(1))
+}
+
+func TestValidateTidbClusterDeleteSlots(t *testing.T) {
+	newTC := func(anns map[string]string, replicas int32, deleteSlots ...int32) *v1alpha1.TidbCluster {
+		return &v1alpha1.TidbCluster{
+			ObjectMeta: metav1.ObjectMeta{Annotations: anns},
+			Spec: v1alpha1.TidbClusterSpec{
+				TiKV: &v1alpha1.TiKVSpec{Replicas: replicas, DeleteSlots: deleteSlots},
+			},
+		}
+	}
+
+	successCases := []*v1alpha1.TidbCluster{
+		newTC(nil, 3),
+		newTC(nil, 3, 0, 1),
+		newTC(map[string]string{label.AnnTiKVDeleteSlots: "[1]"}, 3, 2),
+		// the annotations with invalid syntax are reported by validateAnnotations
+		newTC(map[string]string{label.AnnTiKVDeleteSlots: "1"}, 3),
+	}
+	for _, c := range successCases {
+		if errs := validateTidbClusterDeleteSlots(c); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []*v1alpha1.TidbCluster{
+		newTC(nil, 3, -1),
+		newTC(nil, 3, 1, 1),
+		newTC(nil, 3, 3),
+		newTC(nil, 0, 0),
+		newTC(map[string]string{label.AnnTiKVDeleteSlots: "[-1]"}, 3),
+		newTC(map[string]string{label.AnnTiKVDeleteSlots: "[5]"}, 3, 1),
+	}
+	for _, c := range errorCases {
+		if errs := validateTidbClusterDeleteSlots(c); len(errs) == 0 {
+			t.Errorf("expected failure for %v %v", c.Annotations, c.Spec.TiKV.DeleteSlots)
+		}
+	}
+}
+
+func TestValidatePort(t *testing.T) {
+	g := NewGomegaWithT(t)
+	path := field.NewPath("port")
+
+	g.Expect(validatePort(nil, path, 4000)).To(BeEmpty())
+	g.Expect(validatePort(pointer.Int32Ptr(4001), path, 10080)).To(BeEmpty())
+	g.Expect(validatePort(pointer.Int32Ptr(0), path)).To(HaveLen(1))
+	g.Expect(validatePort(pointer.Int32Ptr(65536), path)).To(HaveLen(1))
+	g.Expect(validatePort(pointer.Int32Ptr(10080), path, 10080)).To(HaveLen(1))
+}
+
+func TestValidateUpdatePorts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	old := &v1alpha1.TidbCluster{
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{},
+			TiKV: &v1alpha1.TiKVSpec{},
+			TiDB: &v1alpha1.TiDBSpec{},
+		},
+	}
+	tc := old.DeepCopy()
+	// setting the default ports explicitly is allowed
+	tc.Spec.PD.ClientPort = pointer.Int32Ptr(v1alpha1.DefaultPDClientPort)
+	tc.Spec.TiKV.Port = pointer.Int32Ptr(v1alpha1.DefaultTiKVPort)
+	tc.Spec.TiDB.Port = pointer.Int32Ptr(4001)
+	g.Expect(validateUpdatePorts(old, tc)).To(BeEmpty())
+
+	tc.Spec.PD.ClientPort = pointer.Int32Ptr(12379)
+	tc.Spec.TiKV.Port = pointer.Int32Ptr(20161)
+	g.Expect(validateUpdatePorts(old, tc)).To(HaveLen(2))
+}
+
+func TestValidateTiKVColdGroup(t *testing.T) {
+	g := NewGomegaWithT(t)
+	path := field.NewPath("coldGroup")
+
+	g.Expect(validateTiKVColdGroup(&v1alpha1.TiKVColdGroupSpec{Replicas: 3}, path)).To(BeEmpty())
+	g.Expect(validateTiKVColdGroup(&v1alpha1.TiKVColdGroupSpec{Replicas: 3, StoreLabels: map[string]string{"disk": "hdd"}}, path)).To(BeEmpty())
+	g.Expect(validateTiKVColdGroup(&v1alpha1.TiKVColdGroupSpec{Replicas: -1}, path)).To(HaveLen(1))
+	g.Expect(validateTiKVColdGroup(&v1alpha1.TiKVColdGroupSpec{StoreLabels: map[string]string{"disk": ""}}, path)).To(HaveLen(1))
+	g.Expect(validateTiKVColdGroup(&v1alpha1.TiKVColdGroupSpec{StoreLabels: map[string]string{"disk": "hdd,ssd"}}, path)).To(HaveLen(1))
+	g.Expect(validateTiKVColdGroup(&v1alpha1.TiKVColdGroupSpec{StoreLabels: map[string]string{"engine": "cold"}}, path)).To(HaveLen(1))
+
+	old := &v1alpha1.TidbCluster{
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV: &v1alpha1.TiKVSpec{ColdGroup: &v1alpha1.TiKVColdGroupSpec{Replicas: 3}},
+		},
+	}
+	tc := old.DeepCopy()
+	// setting the default labels explicitly is allowed
+	tc.Spec.TiKV.ColdGroup.StoreLabels = map[string]string{"tier": "cold"}
+	g.Expect(validateUpdateTiKVColdGroup(old, tc)).To(BeEmpty())
+	tc.Spec.TiKV.ColdGroup.StoreLabels = map[string]string{"disk": "hdd"}
+	g.Expect(validateUpdateTiKVColdGroup(old, tc)).To(HaveLen(1))
+}
+
+func TestValidateTiCDCChangefeeds(t *testing.T) {
+	g := NewGomegaWithT(t)
+	path := field.NewPath("spec", "ticdc", "changefeeds")
+
+	newChangefeed := func(id, secret, key string) v1alpha1.TiCDCChangefeed {
+		return v1alpha1.TiCDCChangefeed{
+			ID: id,
+			SinkURISecretRef: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+				Key:                  key,
+			},
+		}
+	}
+	g.Expect(validateTiCDCChangefeeds([]v1alpha1.TiCDCChangefeed{newChangefeed("cf-1", "sink", "uri"), newChangefeed("cf-2", "sink", "uri-2")}, path)).To(BeEmpty())
+	g.Expect(validateTiCDCChangefeeds([]v1alpha1.TiCDCChangefeed{newChangefeed("", "sink", "uri")}, path)).To(HaveLen(1))
+	g.Expect(validateTiCDCChangefeeds([]v1alpha1.TiCDCChangefeed{newChangefeed("cf-1", "sink", "uri"), newChangefeed("cf-1", "sink", "uri-2")}, path)).To(HaveLen(1))
+	g.Expect(validateTiCDCChangefeeds([]v1alpha1.TiCDCChangefeed{newChangefeed("cf-1", "", "uri")}, path)).To(HaveLen(1))
+	g.Expect(validateTiCDCChangefeeds([]v1alpha1.TiCDCChangefeed{newChangefeed("cf-1", "sink", "")}, path)).To(HaveLen(1))
+}
+
+func TestValidateUpdateEncryptionKeySecret(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	old := &v1alpha1.TidbCluster{}
+	tc := old.DeepCopy()
+	tc.Spec.EncryptionKeySecret = "key-1"
+	g.Expect(validateUpdateEncryptionKeySecret(old, tc)).To(BeEmpty())
+	old = tc.DeepCopy()
+	// rotating the master key is allowed, removing it is not
+	tc.Spec.EncryptionKeySecret = "key-2"
+	g.Expect(validateUpdateEncryptionKeySecret(old, tc)).To(BeEmpty())
+	tc.Spec.EncryptionKeySecret = ""
+	g.Expect(validateUpdateEncryptionKeySecret(old, tc)).To(HaveLen(1))
+}
+
+func TestValidateDMClusterRef(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newDMCluster := func() *v1alpha1.DMCluster {
+		return &v1alpha1.DMCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "dm-workers"},
+			Spec: v1alpha1.DMClusterSpec{
+				Version: "v2.0.7",
+				Cluster: &v1alpha1.TidbClusterRef{Name: "main", Namespace: "dm"},
+				Worker:  &v1alpha1.WorkerSpec{Replicas: 3},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		update func(dc *v1alpha1.DMCluster)
+		errs   []string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name: "name is empty",
+			update: func(dc *v1alpha1.DMCluster) {
+				dc.Spec.Cluster.Name = ""
+			},
+			errs: []string{"spec.cluster.name"},
+		},
+		{
+			name: "reference itself",
+			update: func(dc *v1alpha1.DMCluster) {
+				dc.Spec.Cluster = &v1alpha1.TidbClusterRef{Name: "workers"}
+			},
+			errs: []string{"spec.cluster"},
+		},
+		{
+			name: "dm-master and cluster domain",
+			update: func(dc *v1alpha1.DMCluster) {
+				dc.Spec.Cluster.ClusterDomain = "cluster.local"
+				dc.Spec.Master.Replicas = 3
+			},
+			errs: []string{"spec.cluster.clusterDomain", "spec.master.replicas"},
+		},
+		{
+			name: "no dm-worker",
+			update: func(dc *v1alpha1.DMCluster) {
+				dc.Spec.Worker = nil
+			},
+			errs: []string{"spec.worker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		dc := newDMCluster()
+		if tt.update != nil {
+			tt.update(dc)
+		}
+		var fields []string
+		for _, err := range validateDMClusterRef(dc, field.NewPath("spec")) {
+			fields = append(fields, err.Field)
+		}
+		g.Expect(fields).To(Equal(tt.errs))
+	}
+}
+
+func TestValidateTidbClusterConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV: &v1alpha1.TiKVSpec{Config: v1alpha1.NewTiKVConfig()},
+			TiDB: &v1alpha1.TiDBSpec{Config: v1alpha1.NewTiDBConfig()},
+		},
+	}
+	tc.Spec.TiKV.Config.Set("raftstore.prevote", true)
+	tc.Spec.TiDB.Config.Set("log.level", "info")
+	tc.Spec.TiDB.Config.Set("security.ssl-ca", "/var/lib/ca.crt")
+	g.Expect(ValidateTidbClusterConfig(nil, tc)).To(BeEmpty())
+
+	// the unknown keys and the values of wrong types are rejected
+	tc.Spec.TiKV.Config.Set("prevote", true)
+	tc.Spec.TiDB.Config.Set("log.enable-timestamp", "yes")
+	errs := ValidateTidbClusterConfig(nil, tc)
+	g.Expect(errs).To(HaveLen(2))
+	g.Expect(errs[0].Field).To(Equal("spec.tikv.config[prevote]"))
+	g.Expect(errs[1].Field).To(Equal("spec.tidb.config[log][enable-timestamp]"))
+
+	// only the changed configs are validated on update
+	old := tc.DeepCopy()
+	tc.Spec.TiDB.Config.Set("log.enable-timestamp", true)
+	errs = ValidateTidbClusterConfig(old, tc)
+	g.Expect(errs).To(BeEmpty())
+
+	// the validation is skipped by the annotation
+	tc.Spec.TiDB.Config.Set("log.enable-timestamp", "yes")
+	tc.Annotations = map[string]string{label.AnnSkipConfigValidation: "true"}
+	g.Expect(ValidateTidbClusterConfig(old, tc)).To(BeEmpty())
+
+	// the config items overridden by the operator are warned
+	tc.Spec.TiDB.TLSClient = &v1alpha1.TiDBTLSClient{Enabled: true}
+	g.Expect(TidbClusterConfigWarnings(tc)).To(Equal([]string{
+		"spec.tidb.config: security.ssl-ca is overridden by the operator as spec.tidb.tlsClient is enabled",
+	}))
+}
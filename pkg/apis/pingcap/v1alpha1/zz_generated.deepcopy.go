@@ -25,10 +25,33 @@ import (
 	v1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	v1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	types "k8s.io/apimachinery/pkg/types"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnalyzeTableTask) DeepCopyInto(out *AnalyzeTableTask) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnalyzeTableTask.
+func (in *AnalyzeTableTask) DeepCopy() *AnalyzeTableTask {
+	if in == nil {
+		return nil
+	}
+	out := new(AnalyzeTableTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutoResource) DeepCopyInto(out *AutoResource) {
 	*out = *in
@@ -53,6 +76,31 @@ func (in *AutoResource) DeepCopy() *AutoResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoResumeSpec) DeepCopyInto(out *AutoResumeSpec) {
+	*out = *in
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = (*in).DeepCopy()
+	}
+	if in.After != nil {
+		in, out := &in.After, &out.After
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoResumeSpec.
+func (in *AutoResumeSpec) DeepCopy() *AutoResumeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoResumeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutoRule) DeepCopyInto(out *AutoRule) {
 	*out = *in
@@ -79,6 +127,22 @@ func (in *AutoRule) DeepCopy() *AutoRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzblobStorageProvider) DeepCopyInto(out *AzblobStorageProvider) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzblobStorageProvider.
+func (in *AzblobStorageProvider) DeepCopy() *AzblobStorageProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(AzblobStorageProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BRConfig) DeepCopyInto(out *BRConfig) {
 	*out = *in
@@ -107,6 +171,11 @@ func (in *BRConfig) DeepCopyInto(out *BRConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.IgnoreStats != nil {
+		in, out := &in.IgnoreStats, &out.IgnoreStats
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Options != nil {
 		in, out := &in.Options, &out.Options
 		*out = make([]string, len(*in))
@@ -203,6 +272,27 @@ func (in *BackupList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupProgress) DeepCopyInto(out *BackupProgress) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupProgress.
+func (in *BackupProgress) DeepCopy() *BackupProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupSchedule) DeepCopyInto(out *BackupSchedule) {
 	*out = *in
@@ -278,6 +368,11 @@ func (in *BackupScheduleSpec) DeepCopyInto(out *BackupScheduleSpec) {
 		**out = **in
 	}
 	in.BackupTemplate.DeepCopyInto(&out.BackupTemplate)
+	if in.LogBackupTemplate != nil {
+		in, out := &in.LogBackupTemplate, &out.LogBackupTemplate
+		*out = new(BackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.StorageClassName != nil {
 		in, out := &in.StorageClassName, &out.StorageClassName
 		*out = new(string)
@@ -288,6 +383,11 @@ func (in *BackupScheduleSpec) DeepCopyInto(out *BackupScheduleSpec) {
 		*out = make([]v1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.RPO != nil {
+		in, out := &in.RPO, &out.RPO
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -312,6 +412,22 @@ func (in *BackupScheduleStatus) DeepCopyInto(out *BackupScheduleStatus) {
 		in, out := &in.AllBackupCleanTime, &out.AllBackupCleanTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSuccessfulBackupTime != nil {
+		in, out := &in.LastSuccessfulBackupTime, &out.LastSuccessfulBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulBackupDuration != nil {
+		in, out := &in.LastSuccessfulBackupDuration, &out.LastSuccessfulBackupDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -325,6 +441,24 @@ func (in *BackupScheduleStatus) DeepCopy() *BackupScheduleStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupShardStatus) DeepCopyInto(out *BackupShardStatus) {
+	*out = *in
+	in.TimeStarted.DeepCopyInto(&out.TimeStarted)
+	in.TimeCompleted.DeepCopyInto(&out.TimeCompleted)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupShardStatus.
+func (in *BackupShardStatus) DeepCopy() *BackupShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 	*out = *in
@@ -394,6 +528,25 @@ func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 		*out = new(v1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdditionalVolumes != nil {
+		in, out := &in.AdditionalVolumes, &out.AdditionalVolumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AdditionalVolumeMounts != nil {
+		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -412,6 +565,25 @@ func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 	*out = *in
 	in.TimeStarted.DeepCopyInto(&out.TimeStarted)
 	in.TimeCompleted.DeepCopyInto(&out.TimeCompleted)
+	if in.TikvGCLifeTime != nil {
+		in, out := &in.TikvGCLifeTime, &out.TikvGCLifeTime
+		*out = new(TikvGCLifeTimeStatus)
+		**out = **in
+	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]BackupShardStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Progresses != nil {
+		in, out := &in.Progresses, &out.Progresses
+		*out = make([]BackupProgress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]BackupCondition, len(*in))
@@ -592,6 +764,51 @@ func (in *CDCConfigWraper) DeepCopy() *CDCConfigWraper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertRotationSpec) DeepCopyInto(out *CertRotationSpec) {
+	*out = *in
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertRotationSpec.
+func (in *CertRotationSpec) DeepCopy() *CertRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertRotationStatus) DeepCopyInto(out *CertRotationStatus) {
+	*out = *in
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertRotationStatus.
+func (in *CertRotationStatus) DeepCopy() *CertRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CleanOption) DeepCopyInto(out *CleanOption) {
 	*out = *in
@@ -609,6 +826,97 @@ func (in *CleanOption) DeepCopy() *CleanOption {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClockSkewNode) DeepCopyInto(out *ClockSkewNode) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Skew != nil {
+		in, out := &in.Skew, &out.Skew
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.NodeConditionType, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClockSkewNode.
+func (in *ClockSkewNode) DeepCopy() *ClockSkewNode {
+	if in == nil {
+		return nil
+	}
+	out := new(ClockSkewNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClockSkewSpec) DeepCopyInto(out *ClockSkewSpec) {
+	*out = *in
+	if in.MaxSkew != nil {
+		in, out := &in.MaxSkew, &out.MaxSkew
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CordonThreshold != nil {
+		in, out := &in.CordonThreshold, &out.CordonThreshold
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.NodeConditions != nil {
+		in, out := &in.NodeConditions, &out.NodeConditions
+		*out = make([]v1.NodeConditionType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClockSkewSpec.
+func (in *ClockSkewSpec) DeepCopy() *ClockSkewSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClockSkewSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClockSkewStatus) DeepCopyInto(out *ClockSkewStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]ClockSkewNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClockSkewStatus.
+func (in *ClockSkewStatus) DeepCopy() *ClockSkewStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClockSkewStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterRef) DeepCopyInto(out *ClusterRef) {
 	*out = *in
@@ -751,6 +1059,37 @@ func (in *CommonConfig) DeepCopy() *CommonConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompactTiKVTask) DeepCopyInto(out *CompactTiKVTask) {
+	*out = *in
+	if in.ColumnFamilies != nil {
+		in, out := &in.ColumnFamilies, &out.ColumnFamilies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KeyspaceIDs != nil {
+		in, out := &in.KeyspaceIDs, &out.KeyspaceIDs
+		*out = make([]uint32, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompactTiKVTask.
+func (in *CompactTiKVTask) DeepCopy() *CompactTiKVTask {
+	if in == nil {
+		return nil
+	}
+	out := new(CompactTiKVTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 	*out = *in
@@ -867,11 +1206,53 @@ func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.MinReadySeconds != nil {
+		in, out := &in.MinReadySeconds, &out.MinReadySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RollingUpdateIntervalSeconds != nil {
+		in, out := &in.RollingUpdateIntervalSeconds, &out.RollingUpdateIntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
 	if in.TopologySpreadConstraints != nil {
 		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
 		*out = make([]TopologySpreadConstraint, len(*in))
 		copy(*out, *in)
 	}
+	if in.Architecture != nil {
+		in, out := &in.Architecture, &out.Architecture
+		*out = new(Architecture)
+		**out = **in
+	}
+	if in.ArchBaseImages != nil {
+		in, out := &in.ArchBaseImages, &out.ArchBaseImages
+		*out = make(map[Architecture]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StartScriptVersion != nil {
+		in, out := &in.StartScriptVersion, &out.StartScriptVersion
+		*out = new(StartScriptVersion)
+		**out = **in
+	}
+	if in.Helper != nil {
+		in, out := &in.Helper, &out.Helper
+		*out = new(HelperSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timezone != nil {
+		in, out := &in.Timezone, &out.Timezone
+		*out = new(string)
+		**out = **in
+	}
+	if in.MountTimezoneData != nil {
+		in, out := &in.MountTimezoneData, &out.MountTimezoneData
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -886,7 +1267,73 @@ func (in *ComponentSpec) DeepCopy() *ComponentSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
+func (in *ConfigDrift) DeepCopyInto(out *ConfigDrift) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigDrift.
+func (in *ConfigDrift) DeepCopy() *ConfigDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigDriftSpec) DeepCopyInto(out *ConfigDriftSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigDriftSpec.
+func (in *ConfigDriftSpec) DeepCopy() *ConfigDriftSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigDriftSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigDriftStatus) DeepCopyInto(out *ConfigDriftStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.Drifts != nil {
+		in, out := &in.Drifts, &out.Drifts
+		*out = make([]ConfigDrift, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigDriftStatus.
+func (in *ConfigDriftStatus) DeepCopy() *ConfigDriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigDriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
 	*out = *in
 	if in.Namespace != nil {
 		in, out := &in.Namespace, &out.Namespace
@@ -906,6 +1353,22 @@ func (in *ConfigMapRef) DeepCopy() *ConfigMapRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerResizePolicy) DeepCopyInto(out *ContainerResizePolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerResizePolicy.
+func (in *ContainerResizePolicy) DeepCopy() *ContainerResizePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerResizePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoprocessorCache) DeepCopyInto(out *CoprocessorCache) {
 	*out = *in
@@ -1082,6 +1545,11 @@ func (in *DMClusterSpec) DeepCopyInto(out *DMClusterSpec) {
 		*out = new(WorkerSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(TidbClusterRef)
+		**out = **in
+	}
 	if in.PVReclaimPolicy != nil {
 		in, out := &in.PVReclaimPolicy, &out.PVReclaimPolicy
 		*out = new(v1.PersistentVolumeReclaimPolicy)
@@ -1092,6 +1560,11 @@ func (in *DMClusterSpec) DeepCopyInto(out *DMClusterSpec) {
 		*out = make([]v1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.Helper != nil {
+		in, out := &in.Helper, &out.Helper
+		*out = new(HelperSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.EnablePVReclaim != nil {
 		in, out := &in.EnablePVReclaim, &out.EnablePVReclaim
 		*out = new(bool)
@@ -1100,7 +1573,7 @@ func (in *DMClusterSpec) DeepCopyInto(out *DMClusterSpec) {
 	if in.TLSCluster != nil {
 		in, out := &in.TLSCluster, &out.TLSCluster
 		*out = new(TLSCluster)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.TLSClientSecretNames != nil {
 		in, out := &in.TLSClientSecretNames, &out.TLSClientSecretNames
@@ -1143,6 +1616,11 @@ func (in *DMClusterSpec) DeepCopyInto(out *DMClusterSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.MountTimezoneData != nil {
+		in, out := &in.MountTimezoneData, &out.MountTimezoneData
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]v1.Toleration, len(*in))
@@ -1160,6 +1638,13 @@ func (in *DMClusterSpec) DeepCopyInto(out *DMClusterSpec) {
 		*out = make([]TopologySpreadConstraint, len(*in))
 		copy(*out, *in)
 	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -1294,6 +1779,175 @@ func (in *DMSecurityConfig) DeepCopy() *DMSecurityConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DMSourcePlacement) DeepCopyInto(out *DMSourcePlacement) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DMSourcePlacement.
+func (in *DMSourcePlacement) DeepCopy() *DMSourcePlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(DMSourcePlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DMSubTaskStatus) DeepCopyInto(out *DMSubTaskStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DMSubTaskStatus.
+func (in *DMSubTaskStatus) DeepCopy() *DMSubTaskStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DMSubTaskStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DMTask) DeepCopyInto(out *DMTask) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DMTask.
+func (in *DMTask) DeepCopy() *DMTask {
+	if in == nil {
+		return nil
+	}
+	out := new(DMTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DMTask) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DMTaskList) DeepCopyInto(out *DMTaskList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DMTask, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DMTaskList.
+func (in *DMTaskList) DeepCopy() *DMTaskList {
+	if in == nil {
+		return nil
+	}
+	out := new(DMTaskList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DMTaskList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DMTaskSource) DeepCopyInto(out *DMTaskSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DMTaskSource.
+func (in *DMTaskSource) DeepCopy() *DMTaskSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DMTaskSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DMTaskSpec) DeepCopyInto(out *DMTaskSpec) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]DMTaskSource, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DMTaskSpec.
+func (in *DMTaskSpec) DeepCopy() *DMTaskSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DMTaskSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DMTaskStatus) DeepCopyInto(out *DMTaskStatus) {
+	*out = *in
+	if in.SubTasks != nil {
+		in, out := &in.SubTasks, &out.SubTasks
+		*out = make([]DMSubTaskStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.SourceHashes != nil {
+		in, out := &in.SourceHashes, &out.SourceHashes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastResumeTime != nil {
+		in, out := &in.LastResumeTime, &out.LastResumeTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DMTaskStatus.
+func (in *DMTaskStatus) DeepCopy() *DMTaskStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DMTaskStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DashboardConfig) DeepCopyInto(out *DashboardConfig) {
 	*out = *in
@@ -1414,6 +2068,38 @@ func (in *DataResourceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DebugArtifactsSpec) DeepCopyInto(out *DebugArtifactsSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SizeLimit != nil {
+		in, out := &in.SizeLimit, &out.SizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(string)
+		**out = **in
+	}
+	in.StorageProvider.DeepCopyInto(&out.StorageProvider)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DebugArtifactsSpec.
+func (in *DebugArtifactsSpec) DeepCopy() *DebugArtifactsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DebugArtifactsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentStorageStatus) DeepCopyInto(out *DeploymentStorageStatus) {
 	*out = *in
@@ -1431,31 +2117,93 @@ func (in *DeploymentStorageStatus) DeepCopy() *DeploymentStorageStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DiscoverySpec) DeepCopyInto(out *DiscoverySpec) {
+func (in *DiagnosticsSpec) DeepCopyInto(out *DiagnosticsSpec) {
 	*out = *in
-	if in.ComponentSpec != nil {
-		in, out := &in.ComponentSpec, &out.ComponentSpec
-		*out = new(ComponentSpec)
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]DiagnosticsTrigger, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpgradeStuckTimeout != nil {
+		in, out := &in.UpgradeStuckTimeout, &out.UpgradeStuckTimeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.MinInterval != nil {
+		in, out := &in.MinInterval, &out.MinInterval
+		*out = new(string)
+		**out = **in
+	}
+	if in.LogTailLines != nil {
+		in, out := &in.LogTailLines, &out.LogTailLines
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
-	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	in.StorageProvider.DeepCopyInto(&out.StorageProvider)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoverySpec.
-func (in *DiscoverySpec) DeepCopy() *DiscoverySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiagnosticsSpec.
+func (in *DiagnosticsSpec) DeepCopy() *DiagnosticsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DiscoverySpec)
+	out := new(DiagnosticsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DumplingConfig) DeepCopyInto(out *DumplingConfig) {
+func (in *DiagnosticsStatus) DeepCopyInto(out *DiagnosticsStatus) {
 	*out = *in
-	if in.Options != nil {
+	if in.CollectTime != nil {
+		in, out := &in.CollectTime, &out.CollectTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiagnosticsStatus.
+func (in *DiagnosticsStatus) DeepCopy() *DiagnosticsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiagnosticsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoverySpec) DeepCopyInto(out *DiscoverySpec) {
+	*out = *in
+	if in.ComponentSpec != nil {
+		in, out := &in.ComponentSpec, &out.ComponentSpec
+		*out = new(ComponentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoverySpec.
+func (in *DiscoverySpec) DeepCopy() *DiscoverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DumplingConfig) DeepCopyInto(out *DumplingConfig) {
+	*out = *in
+	if in.Options != nil {
 		in, out := &in.Options, &out.Options
 		*out = make([]string, len(*in))
 		copy(*out, *in)
@@ -1494,6 +2242,28 @@ func (in *EmptyStruct) DeepCopy() *EmptyStruct {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictLeaderSchedulerAudit) DeepCopyInto(out *EvictLeaderSchedulerAudit) {
+	*out = *in
+	in.LastAuditTime.DeepCopyInto(&out.LastAuditTime)
+	if in.OrphanedStores != nil {
+		in, out := &in.OrphanedStores, &out.OrphanedStores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictLeaderSchedulerAudit.
+func (in *EvictLeaderSchedulerAudit) DeepCopy() *EvictLeaderSchedulerAudit {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictLeaderSchedulerAudit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvictLeaderStatus) DeepCopyInto(out *EvictLeaderStatus) {
 	*out = *in
@@ -2062,6 +2832,27 @@ func (in *GrafanaSpec) DeepCopy() *GrafanaSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelperImageStatus) DeepCopyInto(out *HelperImageStatus) {
+	*out = *in
+	if in.Digests != nil {
+		in, out := &in.Digests, &out.Digests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelperImageStatus.
+func (in *HelperImageStatus) DeepCopy() *HelperImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HelperImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelperSpec) DeepCopyInto(out *HelperSpec) {
 	*out = *in
@@ -2075,6 +2866,11 @@ func (in *HelperSpec) DeepCopyInto(out *HelperSpec) {
 		*out = new(v1.PullPolicy)
 		**out = **in
 	}
+	if in.Digest != nil {
+		in, out := &in.Digest, &out.Digest
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -2088,6 +2884,111 @@ func (in *HelperSpec) DeepCopy() *HelperSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportWindowSpec) DeepCopyInto(out *ImportWindowSpec) {
+	*out = *in
+	if in.PDSchedule != nil {
+		in, out := &in.PDSchedule, &out.PDSchedule
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StoreLimit != nil {
+		in, out := &in.StoreLimit, &out.StoreLimit
+		*out = new(float64)
+		**out = **in
+	}
+	if in.TiKVConfig != nil {
+		in, out := &in.TiKVConfig, &out.TiKVConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportWindowSpec.
+func (in *ImportWindowSpec) DeepCopy() *ImportWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportWindowStatus) DeepCopyInto(out *ImportWindowStatus) {
+	*out = *in
+	if in.PDSchedule != nil {
+		in, out := &in.PDSchedule, &out.PDSchedule
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StoreLimits != nil {
+		in, out := &in.StoreLimits, &out.StoreLimits
+		*out = make(map[string]ImportWindowStoreLimit, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TiKVConfig != nil {
+		in, out := &in.TiKVConfig, &out.TiKVConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportWindowStatus.
+func (in *ImportWindowStatus) DeepCopy() *ImportWindowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportWindowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportWindowStoreLimit) DeepCopyInto(out *ImportWindowStoreLimit) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportWindowStoreLimit.
+func (in *ImportWindowStoreLimit) DeepCopy() *ImportWindowStoreLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportWindowStoreLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InFlightOperation) DeepCopyInto(out *InFlightOperation) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InFlightOperation.
+func (in *InFlightOperation) DeepCopy() *InFlightOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(InFlightOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressSpec) DeepCopyInto(out *IngressSpec) {
 	*out = *in
@@ -2123,6 +3024,28 @@ func (in *IngressSpec) DeepCopy() *IngressSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitSqlStorageSpec) DeepCopyInto(out *InitSqlStorageSpec) {
+	*out = *in
+	in.StorageProvider.DeepCopyInto(&out.StorageProvider)
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitSqlStorageSpec.
+func (in *InitSqlStorageSpec) DeepCopy() *InitSqlStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InitSqlStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InitializerSpec) DeepCopyInto(out *InitializerSpec) {
 	*out = *in
@@ -2325,6 +3248,125 @@ func (in *LogTailerSpec) DeepCopy() *LogTailerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceSpec) DeepCopyInto(out *MaintenanceSpec) {
+	*out = *in
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]MaintenanceTask, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceSpec.
+func (in *MaintenanceSpec) DeepCopy() *MaintenanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceTask) DeepCopyInto(out *MaintenanceTask) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CompactTiKV != nil {
+		in, out := &in.CompactTiKV, &out.CompactTiKV
+		*out = new(CompactTiKVTask)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AnalyzeTable != nil {
+		in, out := &in.AnalyzeTable, &out.AnalyzeTable
+		*out = new(AnalyzeTableTask)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResetStoreLimit != nil {
+		in, out := &in.ResetStoreLimit, &out.ResetStoreLimit
+		*out = new(ResetStoreLimitTask)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceTask.
+func (in *MaintenanceTask) DeepCopy() *MaintenanceTask {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceTaskRun) DeepCopyInto(out *MaintenanceTaskRun) {
+	*out = *in
+	if in.ScheduleTime != nil {
+		in, out := &in.ScheduleTime, &out.ScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceTaskRun.
+func (in *MaintenanceTaskRun) DeepCopy() *MaintenanceTaskRun {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceTaskRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceTaskStatus) DeepCopyInto(out *MaintenanceTaskStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastCompletionTime != nil {
+		in, out := &in.LastCompletionTime, &out.LastCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]MaintenanceTaskRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceTaskStatus.
+func (in *MaintenanceTaskStatus) DeepCopy() *MaintenanceTaskStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceTaskStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MasterConfig) DeepCopyInto(out *MasterConfig) {
 	*out = *in
@@ -2525,18 +3567,45 @@ func (in *MasterSpec) DeepCopyInto(out *MasterSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.StorageVolumes != nil {
+		in, out := &in.StorageVolumes, &out.StorageVolumes
+		*out = make([]StorageVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = new(MasterConfig)
 		(*in).DeepCopyInto(*out)
 	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MasterSpec.
-func (in *MasterSpec) DeepCopy() *MasterSpec {
-	if in == nil {
-		return nil
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeerPort != nil {
+		in, out := &in.PeerPort, &out.PeerPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExternalMembers != nil {
+		in, out := &in.ExternalMembers, &out.ExternalMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MasterSpec.
+func (in *MasterSpec) DeepCopy() *MasterSpec {
+	if in == nil {
+		return nil
 	}
 	out := new(MasterSpec)
 	in.DeepCopyInto(out)
@@ -2558,6 +3627,13 @@ func (in *MasterStatus) DeepCopyInto(out *MasterStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.PeerMembers != nil {
+		in, out := &in.PeerMembers, &out.PeerMembers
+		*out = make(map[string]MasterMember, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	in.Leader.DeepCopyInto(&out.Leader)
 	if in.FailureMembers != nil {
 		in, out := &in.FailureMembers, &out.FailureMembers
@@ -2573,6 +3649,20 @@ func (in *MasterStatus) DeepCopyInto(out *MasterStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -2629,6 +3719,11 @@ func (in *NGMonitoringSpec) DeepCopyInto(out *NGMonitoringSpec) {
 		in, out := &in.Config, &out.Config
 		*out = (*in).DeepCopy()
 	}
+	if in.TopSQL != nil {
+		in, out := &in.TopSQL, &out.TopSQL
+		*out = new(TopSQLSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2650,6 +3745,11 @@ func (in *NGMonitoringStatus) DeepCopyInto(out *NGMonitoringStatus) {
 		*out = new(appsv1.StatefulSetStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TopSQL != nil {
+		in, out := &in.TopSQL, &out.TopSQL
+		*out = new(TopSQLStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2684,6 +3784,65 @@ func (in *Networks) DeepCopy() *Networks {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSink) DeepCopyInto(out *NotificationSink) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]NotificationEventType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookNotificationSink)
+		**out = **in
+	}
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackNotificationSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PagerDuty != nil {
+		in, out := &in.PagerDuty, &out.PagerDuty
+		*out = new(PagerDutyNotificationSink)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSink.
+func (in *NotificationSink) DeepCopy() *NotificationSink {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSpec) DeepCopyInto(out *NotificationSpec) {
+	*out = *in
+	if in.Sinks != nil {
+		in, out := &in.Sinks, &out.Sinks
+		*out = make([]NotificationSink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSpec.
+func (in *NotificationSpec) DeepCopy() *NotificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenTracing) DeepCopyInto(out *OpenTracing) {
 	*out = *in
@@ -2797,6 +3956,122 @@ func (in *OpenTracingSampler) DeepCopy() *OpenTracingSampler {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsCommand) DeepCopyInto(out *OpsCommand) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsCommand.
+func (in *OpsCommand) DeepCopy() *OpsCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpsCommand) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsCommandList) DeepCopyInto(out *OpsCommandList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpsCommand, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsCommandList.
+func (in *OpsCommandList) DeepCopy() *OpsCommandList {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsCommandList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpsCommandList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsCommandSpec) DeepCopyInto(out *OpsCommandSpec) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsCommandSpec.
+func (in *OpsCommandSpec) DeepCopy() *OpsCommandSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsCommandSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsCommandStatus) DeepCopyInto(out *OpsCommandStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsCommandStatus.
+func (in *OpsCommandStatus) DeepCopy() *OpsCommandStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsCommandStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDConfig) DeepCopyInto(out *PDConfig) {
 	*out = *in
@@ -3083,6 +4358,11 @@ func (in *PDLogConfig) DeepCopy() *PDLogConfig {
 func (in *PDMember) DeepCopyInto(out *PDMember) {
 	*out = *in
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.VolumeTopology != nil {
+		in, out := &in.VolumeTopology, &out.VolumeTopology
+		*out = new(VolumeTopology)
+		**out = **in
+	}
 	return
 }
 
@@ -3494,6 +4774,11 @@ func (in *PDSpec) DeepCopyInto(out *PDSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
 	if in.StorageClassName != nil {
 		in, out := &in.StorageClassName, &out.StorageClassName
 		*out = new(string)
@@ -3511,6 +4796,11 @@ func (in *PDSpec) DeepCopyInto(out *PDSpec) {
 		*out = new(PDConfigWraper)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TLSClientSecretName != nil {
 		in, out := &in.TLSClientSecretName, &out.TLSClientSecretName
 		*out = new(string)
@@ -3526,6 +4816,28 @@ func (in *PDSpec) DeepCopyInto(out *PDSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.DeleteSlots != nil {
+		in, out := &in.DeleteSlots, &out.DeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LeaderPriorityByZone != nil {
+		in, out := &in.LeaderPriorityByZone, &out.LeaderPriorityByZone
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ClientPort != nil {
+		in, out := &in.ClientPort, &out.ClientPort
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -3576,6 +4888,35 @@ func (in *PDStatus) DeepCopyInto(out *PDStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.EvictLeaderSchedulerAudit != nil {
+		in, out := &in.EvictLeaderSchedulerAudit, &out.EvictLeaderSchedulerAudit
+		*out = new(EvictLeaderSchedulerAudit)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3637,6 +4978,23 @@ func (in PDStoreLabels) DeepCopy() PDStoreLabels {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyNotificationSink) DeepCopyInto(out *PagerDutyNotificationSink) {
+	*out = *in
+	in.RoutingKeySecretRef.DeepCopyInto(&out.RoutingKeySecretRef)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyNotificationSink.
+func (in *PagerDutyNotificationSink) DeepCopy() *PagerDutyNotificationSink {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyNotificationSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Performance) DeepCopyInto(out *Performance) {
 	*out = *in
@@ -3764,6 +5122,22 @@ func (in *PessimisticTxn) DeepCopy() *PessimisticTxn {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PiTRRestoreSpec) DeepCopyInto(out *PiTRRestoreSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PiTRRestoreSpec.
+func (in *PiTRRestoreSpec) DeepCopy() *PiTRRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PiTRRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PlanCache) DeepCopyInto(out *PlanCache) {
 	*out = *in
@@ -3821,6 +5195,27 @@ func (in *Plugin) DeepCopy() *Plugin {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreparedPlanCache) DeepCopyInto(out *PreparedPlanCache) {
 	*out = *in
@@ -3974,6 +5369,13 @@ func (in *PrometheusSpec) DeepCopyInto(out *PrometheusSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ScrapeConfigs != nil {
+		in, out := &in.ScrapeConfigs, &out.ScrapeConfigs
+		*out = make(map[string]ScrapeConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -4134,6 +5536,11 @@ func (in *PumpSpec) DeepCopyInto(out *PumpSpec) {
 		in, out := &in.Config, &out.Config
 		*out = (*in).DeepCopy()
 	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SetTimeZone != nil {
 		in, out := &in.SetTimeZone, &out.SetTimeZone
 		*out = new(bool)
@@ -4171,13 +5578,27 @@ func (in *PumpStatus) DeepCopyInto(out *PumpStatus) {
 			}
 		}
 	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PumpStatus.
-func (in *PumpStatus) DeepCopy() *PumpStatus {
-	if in == nil {
-		return nil
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PumpStatus.
+func (in *PumpStatus) DeepCopy() *PumpStatus {
+	if in == nil {
+		return nil
 	}
 	out := new(PumpStatus)
 	in.DeepCopyInto(out)
@@ -4221,6 +5642,56 @@ func (in *Quota) DeepCopy() *Quota {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadOnlySpec) DeepCopyInto(out *ReadOnlySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadOnlySpec.
+func (in *ReadOnlySpec) DeepCopy() *ReadOnlySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadOnlySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadOnlyStatus) DeepCopyInto(out *ReadOnlyStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadOnlyStatus.
+func (in *ReadOnlyStatus) DeepCopy() *ReadOnlyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadOnlyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegionHealthStatus) DeepCopyInto(out *RegionHealthStatus) {
+	*out = *in
+	in.LastRefreshTime.DeepCopyInto(&out.LastRefreshTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionHealthStatus.
+func (in *RegionHealthStatus) DeepCopy() *RegionHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RelabelConfig) DeepCopyInto(out *RelabelConfig) {
 	*out = *in
@@ -4242,6 +5713,43 @@ func (in *RelabelConfig) DeepCopy() *RelabelConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelayLogStorageSpec) DeepCopyInto(out *RelayLogStorageSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(string)
+		**out = **in
+	}
+	if in.LocalRetention != nil {
+		in, out := &in.LocalRetention, &out.LocalRetention
+		*out = new(string)
+		**out = **in
+	}
+	if in.RemoteRetention != nil {
+		in, out := &in.RemoteRetention, &out.RemoteRetention
+		*out = new(string)
+		**out = **in
+	}
+	in.StorageProvider.DeepCopyInto(&out.StorageProvider)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelayLogStorageSpec.
+func (in *RelayLogStorageSpec) DeepCopy() *RelayLogStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RelayLogStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReloaderSpec) DeepCopyInto(out *ReloaderSpec) {
 	*out = *in
@@ -4303,6 +5811,27 @@ func (in *RemoteWriteSpec) DeepCopy() *RemoteWriteSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResetStoreLimitTask) DeepCopyInto(out *ResetStoreLimitTask) {
+	*out = *in
+	if in.Rate != nil {
+		in, out := &in.Rate, &out.Rate
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResetStoreLimitTask.
+func (in *ResetStoreLimitTask) DeepCopy() *ResetStoreLimitTask {
+	if in == nil {
+		return nil
+	}
+	out := new(ResetStoreLimitTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Restore) DeepCopyInto(out *Restore) {
 	*out = *in
@@ -4381,6 +5910,27 @@ func (in *RestoreList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreProgress) DeepCopyInto(out *RestoreProgress) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreProgress.
+func (in *RestoreProgress) DeepCopy() *RestoreProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 	*out = *in
@@ -4397,6 +5947,11 @@ func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 		*out = new(TiDBAccessConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PiTR != nil {
+		in, out := &in.PiTR, &out.PiTR
+		*out = new(PiTRRestoreSpec)
+		**out = **in
+	}
 	if in.TikvGCLifeTime != nil {
 		in, out := &in.TikvGCLifeTime, &out.TikvGCLifeTime
 		*out = new(string)
@@ -4440,6 +5995,35 @@ func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 		*out = new(v1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdditionalVolumes != nil {
+		in, out := &in.AdditionalVolumes, &out.AdditionalVolumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AdditionalVolumeMounts != nil {
+		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Statistics != nil {
+		in, out := &in.Statistics, &out.Statistics
+		*out = new(RestoreStatisticsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImportWindow != nil {
+		in, out := &in.ImportWindow, &out.ImportWindow
+		*out = new(ImportWindowSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TiFlashReplica != nil {
+		in, out := &in.TiFlashReplica, &out.TiFlashReplica
+		*out = new(RestoreTiFlashReplicaSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -4453,11 +6037,44 @@ func (in *RestoreSpec) DeepCopy() *RestoreSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreStatisticsSpec) DeepCopyInto(out *RestoreStatisticsSpec) {
+	*out = *in
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreStatisticsSpec.
+func (in *RestoreStatisticsSpec) DeepCopy() *RestoreStatisticsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreStatisticsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreStatus) DeepCopyInto(out *RestoreStatus) {
 	*out = *in
 	in.TimeStarted.DeepCopyInto(&out.TimeStarted)
 	in.TimeCompleted.DeepCopyInto(&out.TimeCompleted)
+	if in.ImportWindow != nil {
+		in, out := &in.ImportWindow, &out.ImportWindow
+		*out = new(ImportWindowStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Progresses != nil {
+		in, out := &in.Progresses, &out.Progresses
+		*out = make([]RestoreProgress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]RestoreCondition, len(*in))
@@ -4478,6 +6095,44 @@ func (in *RestoreStatus) DeepCopy() *RestoreStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTiFlashReplicaSpec) DeepCopyInto(out *RestoreTiFlashReplicaSpec) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]TiFlashReplicaTable, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTiFlashReplicaSpec.
+func (in *RestoreTiFlashReplicaSpec) DeepCopy() *RestoreTiFlashReplicaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTiFlashReplicaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollingUpdateStatus) DeepCopyInto(out *RollingUpdateStatus) {
+	*out = *in
+	in.LastProgressTime.DeepCopyInto(&out.LastProgressTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateStatus.
+func (in *RollingUpdateStatus) DeepCopy() *RollingUpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RollingUpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3StorageProvider) DeepCopyInto(out *S3StorageProvider) {
 	*out = *in
@@ -4522,6 +6177,32 @@ func (in *SafeTLSConfig) DeepCopy() *SafeTLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScrapeConfig) DeepCopyInto(out *ScrapeConfig) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(string)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScrapeConfig.
+func (in *ScrapeConfig) DeepCopy() *ScrapeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ScrapeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretOrConfigMap) DeepCopyInto(out *SecretOrConfigMap) {
 	*out = *in
@@ -4687,53 +6368,138 @@ func (in *ServiceSpec) DeepCopy() *ServiceSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Status) DeepCopyInto(out *Status) {
+func (in *SilenceWindow) DeepCopyInto(out *SilenceWindow) {
 	*out = *in
-	if in.MetricsAddr != nil {
-		in, out := &in.MetricsAddr, &out.MetricsAddr
-		*out = new(string)
-		**out = **in
+	if in.Matchers != nil {
+		in, out := &in.Matchers, &out.Matchers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.MetricsInterval != nil {
-		in, out := &in.MetricsInterval, &out.MetricsInterval
-		*out = new(uint)
-		**out = **in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SilenceWindow.
+func (in *SilenceWindow) DeepCopy() *SilenceWindow {
+	if in == nil {
+		return nil
 	}
-	if in.ReportStatus != nil {
-		in, out := &in.ReportStatus, &out.ReportStatus
-		*out = new(bool)
-		**out = **in
+	out := new(SilenceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SilenceWindowStatus) DeepCopyInto(out *SilenceWindowStatus) {
+	*out = *in
+	if in.StartsAt != nil {
+		in, out := &in.StartsAt, &out.StartsAt
+		*out = (*in).DeepCopy()
 	}
-	if in.RecordQPSbyDB != nil {
-		in, out := &in.RecordQPSbyDB, &out.RecordQPSbyDB
-		*out = new(bool)
-		**out = **in
+	if in.EndsAt != nil {
+		in, out := &in.EndsAt, &out.EndsAt
+		*out = (*in).DeepCopy()
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Status.
-func (in *Status) DeepCopy() *Status {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SilenceWindowStatus.
+func (in *SilenceWindowStatus) DeepCopy() *SilenceWindowStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Status)
+	out := new(SilenceWindowStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StmtSummary) DeepCopyInto(out *StmtSummary) {
+func (in *SlackNotificationSink) DeepCopyInto(out *SlackNotificationSink) {
 	*out = *in
-	if in.Enable != nil {
-		in, out := &in.Enable, &out.Enable
-		*out = new(bool)
-		**out = **in
-	}
-	if in.EnableInternalQuery != nil {
-		in, out := &in.EnableInternalQuery, &out.EnableInternalQuery
-		*out = new(bool)
-		**out = **in
+	in.WebhookURLSecretRef.DeepCopyInto(&out.WebhookURLSecretRef)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackNotificationSink.
+func (in *SlackNotificationSink) DeepCopy() *SlackNotificationSink {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackNotificationSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpotTerminationSpec) DeepCopyInto(out *SpotTerminationSpec) {
+	*out = *in
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpotTerminationSpec.
+func (in *SpotTerminationSpec) DeepCopy() *SpotTerminationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SpotTerminationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Status) DeepCopyInto(out *Status) {
+	*out = *in
+	if in.MetricsAddr != nil {
+		in, out := &in.MetricsAddr, &out.MetricsAddr
+		*out = new(string)
+		**out = **in
+	}
+	if in.MetricsInterval != nil {
+		in, out := &in.MetricsInterval, &out.MetricsInterval
+		*out = new(uint)
+		**out = **in
+	}
+	if in.ReportStatus != nil {
+		in, out := &in.ReportStatus, &out.ReportStatus
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RecordQPSbyDB != nil {
+		in, out := &in.RecordQPSbyDB, &out.RecordQPSbyDB
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Status.
+func (in *Status) DeepCopy() *Status {
+	if in == nil {
+		return nil
+	}
+	out := new(Status)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StmtSummary) DeepCopyInto(out *StmtSummary) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableInternalQuery != nil {
+		in, out := &in.EnableInternalQuery, &out.EnableInternalQuery
+		*out = new(bool)
+		**out = **in
 	}
 	if in.MaxStmtCount != nil {
 		in, out := &in.MaxStmtCount, &out.MaxStmtCount
@@ -4803,6 +6569,11 @@ func (in *StorageProvider) DeepCopyInto(out *StorageProvider) {
 		*out = new(GcsStorageProvider)
 		**out = **in
 	}
+	if in.Azblob != nil {
+		in, out := &in.Azblob, &out.Azblob
+		*out = new(AzblobStorageProvider)
+		**out = **in
+	}
 	if in.Local != nil {
 		in, out := &in.Local, &out.Local
 		*out = new(LocalStorageProvider)
@@ -4821,6 +6592,101 @@ func (in *StorageProvider) DeepCopy() *StorageProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageProvisioningJob) DeepCopyInto(out *StorageProvisioningJob) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageProvisioningJob.
+func (in *StorageProvisioningJob) DeepCopy() *StorageProvisioningJob {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageProvisioningJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageProvisioningSpec) DeepCopyInto(out *StorageProvisioningSpec) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]MemberType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(StorageProvisioningWebhook)
+		**out = **in
+	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(StorageProvisioningJob)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageProvisioningSpec.
+func (in *StorageProvisioningSpec) DeepCopy() *StorageProvisioningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageProvisioningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageProvisioningStatus) DeepCopyInto(out *StorageProvisioningStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageProvisioningStatus.
+func (in *StorageProvisioningStatus) DeepCopy() *StorageProvisioningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageProvisioningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageProvisioningWebhook) DeepCopyInto(out *StorageProvisioningWebhook) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageProvisioningWebhook.
+func (in *StorageProvisioningWebhook) DeepCopy() *StorageProvisioningWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageProvisioningWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageVolume) DeepCopyInto(out *StorageVolume) {
 	*out = *in
@@ -4842,9 +6708,32 @@ func (in *StorageVolume) DeepCopy() *StorageVolume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageVolumeStatus) DeepCopyInto(out *StorageVolumeStatus) {
+	*out = *in
+	out.CurrentCapacity = in.CurrentCapacity.DeepCopy()
+	out.ResizedCapacity = in.ResizedCapacity.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageVolumeStatus.
+func (in *StorageVolumeStatus) DeepCopy() *StorageVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSCluster) DeepCopyInto(out *TLSCluster) {
 	*out = *in
+	if in.CertRotation != nil {
+		in, out := &in.CertRotation, &out.CertRotation
+		*out = new(CertRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -4875,6 +6764,29 @@ func (in *TLSConfig) DeepCopy() *TLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosQuerySpec) DeepCopyInto(out *ThanosQuerySpec) {
+	*out = *in
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Service.DeepCopyInto(&out.Service)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuerySpec.
+func (in *ThanosQuerySpec) DeepCopy() *ThanosQuerySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosQuerySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThanosSpec) DeepCopyInto(out *ThanosSpec) {
 	*out = *in
@@ -4911,6 +6823,11 @@ func (in *ThanosSpec) DeepCopyInto(out *ThanosSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Query != nil {
+		in, out := &in.Query, &out.Query
+		*out = new(ThanosQuerySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -4940,6 +6857,39 @@ func (in *TiCDCCapture) DeepCopy() *TiCDCCapture {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiCDCChangefeed) DeepCopyInto(out *TiCDCChangefeed) {
+	*out = *in
+	in.SinkURISecretRef.DeepCopyInto(&out.SinkURISecretRef)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiCDCChangefeed.
+func (in *TiCDCChangefeed) DeepCopy() *TiCDCChangefeed {
+	if in == nil {
+		return nil
+	}
+	out := new(TiCDCChangefeed)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiCDCChangefeedStatus) DeepCopyInto(out *TiCDCChangefeedStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiCDCChangefeedStatus.
+func (in *TiCDCChangefeedStatus) DeepCopy() *TiCDCChangefeedStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TiCDCChangefeedStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiCDCConfig) DeepCopyInto(out *TiCDCConfig) {
 	*out = *in
@@ -4991,6 +6941,11 @@ func (in *TiCDCSpec) DeepCopyInto(out *TiCDCSpec) {
 		*out = new(CDCConfigWraper)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.StorageVolumes != nil {
 		in, out := &in.StorageVolumes, &out.StorageVolumes
 		*out = make([]StorageVolume, len(*in))
@@ -5003,6 +6958,18 @@ func (in *TiCDCSpec) DeepCopyInto(out *TiCDCSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.GracefulShutdownTimeout != nil {
+		in, out := &in.GracefulShutdownTimeout, &out.GracefulShutdownTimeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.Changefeeds != nil {
+		in, out := &in.Changefeeds, &out.Changefeeds
+		*out = make([]TiCDCChangefeed, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -5031,6 +6998,27 @@ func (in *TiCDCStatus) DeepCopyInto(out *TiCDCStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Changefeeds != nil {
+		in, out := &in.Changefeeds, &out.Changefeeds
+		*out = make(map[string]TiCDCChangefeedStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -5414,6 +7402,16 @@ func (in *TiDBServiceSpec) DeepCopyInto(out *TiDBServiceSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.InternalTrafficPolicy != nil {
+		in, out := &in.InternalTrafficPolicy, &out.InternalTrafficPolicy
+		*out = new(ServiceInternalTrafficPolicyType)
+		**out = **in
+	}
+	if in.SessionAffinityConfig != nil {
+		in, out := &in.SessionAffinityConfig, &out.SessionAffinityConfig
+		*out = new(v1.SessionAffinityConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5464,6 +7462,11 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(TiDBServiceSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PerPodService != nil {
+		in, out := &in.PerPodService, &out.PerPodService
+		*out = new(TiDBServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BinlogEnabled != nil {
 		in, out := &in.BinlogEnabled, &out.BinlogEnabled
 		*out = new(bool)
@@ -5474,6 +7477,16 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ResizePolicy != nil {
+		in, out := &in.ResizePolicy, &out.ResizePolicy
+		*out = make([]ContainerResizePolicy, len(*in))
+		copy(*out, *in)
+	}
 	if in.SeparateSlowLog != nil {
 		in, out := &in.SeparateSlowLog, &out.SeparateSlowLog
 		*out = new(bool)
@@ -5499,6 +7512,11 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(TiDBConfigWraper)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Lifecycle != nil {
 		in, out := &in.Lifecycle, &out.Lifecycle
 		*out = new(v1.Lifecycle)
@@ -5521,6 +7539,36 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(TiDBProbe)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeleteSlots != nil {
+		in, out := &in.DeleteSlots, &out.DeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradeStrategy != nil {
+		in, out := &in.UpgradeStrategy, &out.UpgradeStrategy
+		*out = new(TiDBUpgradeStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StatusPort != nil {
+		in, out := &in.StatusPort, &out.StatusPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DebugArtifacts != nil {
+		in, out := &in.DebugArtifacts, &out.DebugArtifacts
+		*out = new(DebugArtifactsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5556,14 +7604,38 @@ func (in *TiDBStatus) DeepCopyInto(out *TiDBStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBStatus.
-func (in *TiDBStatus) DeepCopy() *TiDBStatus {
-	if in == nil {
-		return nil
-	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBStatus.
+func (in *TiDBStatus) DeepCopy() *TiDBStatus {
+	if in == nil {
+		return nil
+	}
 	out := new(TiDBStatus)
 	in.DeepCopyInto(out)
 	return out
@@ -5585,6 +7657,27 @@ func (in *TiDBTLSClient) DeepCopy() *TiDBTLSClient {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBUpgradeStrategy) DeepCopyInto(out *TiDBUpgradeStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBUpgradeStrategy.
+func (in *TiDBUpgradeStrategy) DeepCopy() *TiDBUpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBUpgradeStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiFlashCommonConfigWraper) DeepCopyInto(out *TiFlashCommonConfigWraper) {
 	*out = *in
@@ -5677,6 +7770,22 @@ func (in *TiFlashProxyConfigWraper) DeepCopy() *TiFlashProxyConfigWraper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiFlashReplicaTable) DeepCopyInto(out *TiFlashReplicaTable) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiFlashReplicaTable.
+func (in *TiFlashReplicaTable) DeepCopy() *TiFlashReplicaTable {
+	if in == nil {
+		return nil
+	}
+	out := new(TiFlashReplicaTable)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiFlashSpec) DeepCopyInto(out *TiFlashSpec) {
 	*out = *in
@@ -5704,11 +7813,31 @@ func (in *TiFlashSpec) DeepCopyInto(out *TiFlashSpec) {
 		*out = new(TiFlashConfigWraper)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.LogTailer != nil {
 		in, out := &in.LogTailer, &out.LogTailer
 		*out = new(LogTailerSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeleteSlots != nil {
+		in, out := &in.DeleteSlots, &out.DeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.StoreLabels != nil {
+		in, out := &in.StoreLabels, &out.StoreLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5758,6 +7887,25 @@ func (in *TiFlashStatus) DeepCopyInto(out *TiFlashStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -6105,6 +8253,49 @@ func (in *TiKVClient) DeepCopy() *TiKVClient {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVColdGroupSpec) DeepCopyInto(out *TiKVColdGroupSpec) {
+	*out = *in
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StoreLabels != nil {
+		in, out := &in.StoreLabels, &out.StoreLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVColdGroupSpec.
+func (in *TiKVColdGroupSpec) DeepCopy() *TiKVColdGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVColdGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVConfig) DeepCopyInto(out *TiKVConfig) {
 	*out = *in
@@ -7513,6 +9704,16 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ResizePolicy != nil {
+		in, out := &in.ResizePolicy, &out.ResizePolicy
+		*out = make([]ContainerResizePolicy, len(*in))
+		copy(*out, *in)
+	}
 	if in.SeparateRocksDBLog != nil {
 		in, out := &in.SeparateRocksDBLog, &out.SeparateRocksDBLog
 		*out = new(bool)
@@ -7538,6 +9739,11 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 		*out = new(TiKVConfigWraper)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MountClusterClientSecret != nil {
 		in, out := &in.MountClusterClientSecret, &out.MountClusterClientSecret
 		*out = new(bool)
@@ -7548,6 +9754,26 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MaxReceivingSnapshotsOnUpgrade != nil {
+		in, out := &in.MaxReceivingSnapshotsOnUpgrade, &out.MaxReceivingSnapshotsOnUpgrade
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LeaderRecoveryPercentOnUpgrade != nil {
+		in, out := &in.LeaderRecoveryPercentOnUpgrade, &out.LeaderRecoveryPercentOnUpgrade
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LeaderRecoveryTimeout != nil {
+		in, out := &in.LeaderRecoveryTimeout, &out.LeaderRecoveryTimeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.RegionHealthRefreshInterval != nil {
+		in, out := &in.RegionHealthRefreshInterval, &out.RegionHealthRefreshInterval
+		*out = new(string)
+		**out = **in
+	}
 	if in.StorageVolumes != nil {
 		in, out := &in.StorageVolumes, &out.StorageVolumes
 		*out = make([]StorageVolume, len(*in))
@@ -7560,6 +9786,53 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.StoreScheduling != nil {
+		in, out := &in.StoreScheduling, &out.StoreScheduling
+		*out = make([]TiKVStoreScheduling, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeleteSlots != nil {
+		in, out := &in.DeleteSlots, &out.DeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.PerPodConfig != nil {
+		in, out := &in.PerPodConfig, &out.PerPodConfig
+		*out = make(map[string]*TiKVConfigWraper, len(*in))
+		for key, val := range *in {
+			var outVal *TiKVConfigWraper
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(TiKVConfigWraper)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ColdGroup != nil {
+		in, out := &in.ColdGroup, &out.ColdGroup
+		*out = new(TiKVColdGroupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DebugArtifacts != nil {
+		in, out := &in.DebugArtifacts, &out.DebugArtifacts
+		*out = new(DebugArtifactsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -7624,6 +9897,42 @@ func (in *TiKVStatus) DeepCopyInto(out *TiKVStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.RegionHealth != nil {
+		in, out := &in.RegionHealth, &out.RegionHealth
+		*out = new(RegionHealthStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludedStoreLabels != nil {
+		in, out := &in.ExcludedStoreLabels, &out.ExcludedStoreLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -7748,6 +10057,11 @@ func (in *TiKVStorageReadPoolConfig) DeepCopy() *TiKVStorageReadPoolConfig {
 func (in *TiKVStore) DeepCopyInto(out *TiKVStore) {
 	*out = *in
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.VolumeTopology != nil {
+		in, out := &in.VolumeTopology, &out.VolumeTopology
+		*out = new(VolumeTopology)
+		**out = **in
+	}
 	return
 }
 
@@ -7761,6 +10075,54 @@ func (in *TiKVStore) DeepCopy() *TiKVStore {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVStoreScheduling) DeepCopyInto(out *TiKVStoreScheduling) {
+	*out = *in
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.StoreLabels != nil {
+		in, out := &in.StoreLabels, &out.StoreLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LeaderWeight != nil {
+		in, out := &in.LeaderWeight, &out.LeaderWeight
+		*out = new(float64)
+		**out = **in
+	}
+	if in.RegionWeight != nil {
+		in, out := &in.RegionWeight, &out.RegionWeight
+		*out = new(float64)
+		**out = **in
+	}
+	if in.AddPeerLimit != nil {
+		in, out := &in.AddPeerLimit, &out.AddPeerLimit
+		*out = new(float64)
+		**out = **in
+	}
+	if in.RemovePeerLimit != nil {
+		in, out := &in.RemovePeerLimit, &out.RemovePeerLimit
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVStoreScheduling.
+func (in *TiKVStoreScheduling) DeepCopy() *TiKVStoreScheduling {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVStoreScheduling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVTitanCfConfig) DeepCopyInto(out *TiKVTitanCfConfig) {
 	*out = *in
@@ -7910,65 +10272,158 @@ func (in *TiKVUnifiedReadPoolConfig) DeepCopy() *TiKVUnifiedReadPoolConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbAutoScalerSpec) DeepCopyInto(out *TidbAutoScalerSpec) {
+func (in *TiProxyConfigWraper) DeepCopyInto(out *TiProxyConfigWraper) {
 	*out = *in
-	in.BasicAutoScalerSpec.DeepCopyInto(&out.BasicAutoScalerSpec)
+	if in.GenericConfig != nil {
+		in, out := &in.GenericConfig, &out.GenericConfig
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbAutoScalerSpec.
-func (in *TidbAutoScalerSpec) DeepCopy() *TidbAutoScalerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiProxyConfigWraper.
+func (in *TiProxyConfigWraper) DeepCopy() *TiProxyConfigWraper {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbAutoScalerSpec)
+	out := new(TiProxyConfigWraper)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbAutoScalerStatus) DeepCopyInto(out *TidbAutoScalerStatus) {
+func (in *TiProxyMember) DeepCopyInto(out *TiProxyMember) {
 	*out = *in
-	in.BasicAutoScalerStatus.DeepCopyInto(&out.BasicAutoScalerStatus)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbAutoScalerStatus.
-func (in *TidbAutoScalerStatus) DeepCopy() *TidbAutoScalerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiProxyMember.
+func (in *TiProxyMember) DeepCopy() *TiProxyMember {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbAutoScalerStatus)
+	out := new(TiProxyMember)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbCluster) DeepCopyInto(out *TidbCluster) {
+func (in *TiProxySpec) DeepCopyInto(out *TiProxySpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	in.ComponentSpec.DeepCopyInto(&out.ComponentSpec)
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(TiProxyConfigWraper)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbCluster.
-func (in *TidbCluster) DeepCopy() *TidbCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiProxySpec.
+func (in *TiProxySpec) DeepCopy() *TiProxySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbCluster)
+	out := new(TiProxySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TidbCluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiProxyStatus) DeepCopyInto(out *TiProxyStatus) {
+	*out = *in
+	if in.StatefulSet != nil {
+		in, out := &in.StatefulSet, &out.StatefulSet
+		*out = new(appsv1.StatefulSetStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make(map[string]TiProxyMember, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiProxyStatus.
+func (in *TiProxyStatus) DeepCopy() *TiProxyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TiProxyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbAutoScalerSpec) DeepCopyInto(out *TidbAutoScalerSpec) {
+	*out = *in
+	in.BasicAutoScalerSpec.DeepCopyInto(&out.BasicAutoScalerSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbAutoScalerSpec.
+func (in *TidbAutoScalerSpec) DeepCopy() *TidbAutoScalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbAutoScalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbAutoScalerStatus) DeepCopyInto(out *TidbAutoScalerStatus) {
+	*out = *in
+	in.BasicAutoScalerStatus.DeepCopyInto(&out.BasicAutoScalerStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbAutoScalerStatus.
+func (in *TidbAutoScalerStatus) DeepCopy() *TidbAutoScalerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbAutoScalerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbCluster) DeepCopyInto(out *TidbCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbCluster.
+func (in *TidbCluster) DeepCopy() *TidbCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -8201,6 +10656,11 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = new(TiCDCSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TiProxy != nil {
+		in, out := &in.TiProxy, &out.TiProxy
+		*out = new(TiProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Pump != nil {
 		in, out := &in.Pump, &out.Pump
 		*out = new(PumpSpec)
@@ -8211,6 +10671,11 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = new(HelperSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AutoResume != nil {
+		in, out := &in.AutoResume, &out.AutoResume
+		*out = new(AutoResumeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PVReclaimPolicy != nil {
 		in, out := &in.PVReclaimPolicy, &out.PVReclaimPolicy
 		*out = new(v1.PersistentVolumeReclaimPolicy)
@@ -8221,6 +10686,11 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = make([]v1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.ConfigRevisionHistoryLimit != nil {
+		in, out := &in.ConfigRevisionHistoryLimit, &out.ConfigRevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 	if in.EnablePVReclaim != nil {
 		in, out := &in.EnablePVReclaim, &out.EnablePVReclaim
 		*out = new(bool)
@@ -8229,7 +10699,7 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 	if in.TLSCluster != nil {
 		in, out := &in.TLSCluster, &out.TLSCluster
 		*out = new(TLSCluster)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.HostNetwork != nil {
 		in, out := &in.HostNetwork, &out.HostNetwork
@@ -8267,6 +10737,13 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.CloudTags != nil {
+		in, out := &in.CloudTags, &out.CloudTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]v1.Toleration, len(*in))
@@ -8274,6 +10751,11 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MountTimezoneData != nil {
+		in, out := &in.MountTimezoneData, &out.MountTimezoneData
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Services != nil {
 		in, out := &in.Services, &out.Services
 		*out = make([]Service, len(*in))
@@ -8304,6 +10786,63 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = make([]TopologySpreadConstraint, len(*in))
 		copy(*out, *in)
 	}
+	if in.ConfigDrift != nil {
+		in, out := &in.ConfigDrift, &out.ConfigDrift
+		*out = new(ConfigDriftSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClockSkew != nil {
+		in, out := &in.ClockSkew, &out.ClockSkew
+		*out = new(ClockSkewSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VersionSkew != nil {
+		in, out := &in.VersionSkew, &out.VersionSkew
+		*out = new(VersionSkewSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageProvisioning != nil {
+		in, out := &in.StorageProvisioning, &out.StorageProvisioning
+		*out = new(StorageProvisioningSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TombstoneStoreGC != nil {
+		in, out := &in.TombstoneStoreGC, &out.TombstoneStoreGC
+		*out = new(TombstoneStoreGCSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SpotTermination != nil {
+		in, out := &in.SpotTermination, &out.SpotTermination
+		*out = new(SpotTerminationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(DiagnosticsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = new(ReadOnlySpec)
+		**out = **in
+	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -8322,15 +10861,93 @@ func (in *TidbClusterStatus) DeepCopyInto(out *TidbClusterStatus) {
 	*out = *in
 	in.PD.DeepCopyInto(&out.PD)
 	in.TiKV.DeepCopyInto(&out.TiKV)
+	in.TiKVCold.DeepCopyInto(&out.TiKVCold)
 	in.TiDB.DeepCopyInto(&out.TiDB)
 	in.Pump.DeepCopyInto(&out.Pump)
 	in.TiFlash.DeepCopyInto(&out.TiFlash)
 	in.TiCDC.DeepCopyInto(&out.TiCDC)
+	in.TiProxy.DeepCopyInto(&out.TiProxy)
 	if in.AutoScaler != nil {
 		in, out := &in.AutoScaler, &out.AutoScaler
 		*out = new(TidbClusterAutoScalerRef)
 		**out = **in
 	}
+	if in.HelperImages != nil {
+		in, out := &in.HelperImages, &out.HelperImages
+		*out = make([]HelperImageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConfigDrift != nil {
+		in, out := &in.ConfigDrift, &out.ConfigDrift
+		*out = new(ConfigDriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClockSkew != nil {
+		in, out := &in.ClockSkew, &out.ClockSkew
+		*out = new(ClockSkewStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VersionSkew != nil {
+		in, out := &in.VersionSkew, &out.VersionSkew
+		*out = new(VersionSkewStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageProvisioning != nil {
+		in, out := &in.StorageProvisioning, &out.StorageProvisioning
+		*out = make([]StorageProvisioningStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TombstoneStoreGC != nil {
+		in, out := &in.TombstoneStoreGC, &out.TombstoneStoreGC
+		*out = new(TombstoneStoreGCStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeRepairs != nil {
+		in, out := &in.VolumeRepairs, &out.VolumeRepairs
+		*out = make([]VolumeRepairRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InFlightOperations != nil {
+		in, out := &in.InFlightOperations, &out.InFlightOperations
+		*out = make([]InFlightOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = make([]MaintenanceTaskStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(DiagnosticsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PausedAt != nil {
+		in, out := &in.PausedAt, &out.PausedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CertRotation != nil {
+		in, out := &in.CertRotation, &out.CertRotation
+		*out = make([]CertRotationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = new(ReadOnlyStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]TidbClusterCondition, len(*in))
@@ -8446,11 +11063,21 @@ func (in *TidbInitializerSpec) DeepCopyInto(out *TidbInitializerSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.InitSqlStorage != nil {
+		in, out := &in.InitSqlStorage, &out.InitSqlStorage
+		*out = new(InitSqlStorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PasswordSecret != nil {
 		in, out := &in.PasswordSecret, &out.PasswordSecret
 		*out = new(string)
 		**out = **in
 	}
+	if in.UserReconciliation != nil {
+		in, out := &in.UserReconciliation, &out.UserReconciliation
+		*out = new(UserReconciliationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = new(v1.ResourceRequirements)
@@ -8461,6 +11088,20 @@ func (in *TidbInitializerSpec) DeepCopyInto(out *TidbInitializerSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AdditionalVolumes != nil {
+		in, out := &in.AdditionalVolumes, &out.AdditionalVolumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AdditionalVolumeMounts != nil {
+		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -8478,6 +11119,11 @@ func (in *TidbInitializerSpec) DeepCopy() *TidbInitializerSpec {
 func (in *TidbInitializerStatus) DeepCopyInto(out *TidbInitializerStatus) {
 	*out = *in
 	in.JobStatus.DeepCopyInto(&out.JobStatus)
+	if in.UserReconciliation != nil {
+		in, out := &in.UserReconciliation, &out.UserReconciliation
+		*out = new(UserReconciliationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -8657,6 +11303,13 @@ func (in *TidbMonitorSpec) DeepCopyInto(out *TidbMonitorSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.SilenceWindows != nil {
+		in, out := &in.SilenceWindows, &out.SilenceWindows
+		*out = make([]SilenceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.AdditionalContainers != nil {
 		in, out := &in.AdditionalContainers, &out.AdditionalContainers
 		*out = make([]v1.Container, len(*in))
@@ -8724,6 +11377,13 @@ func (in *TidbMonitorStatus) DeepCopyInto(out *TidbMonitorStatus) {
 		*out = new(appsv1.StatefulSetStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SilenceWindows != nil {
+		in, out := &in.SilenceWindows, &out.SilenceWindows
+		*out = make([]SilenceWindowStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -8877,6 +11537,118 @@ func (in *TikvAutoScalerStatus) DeepCopy() *TikvAutoScalerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TikvGCLifeTimeStatus) DeepCopyInto(out *TikvGCLifeTimeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TikvGCLifeTimeStatus.
+func (in *TikvGCLifeTimeStatus) DeepCopy() *TikvGCLifeTimeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TikvGCLifeTimeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TombstoneStoreGCSpec) DeepCopyInto(out *TombstoneStoreGCSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TombstoneStoreGCSpec.
+func (in *TombstoneStoreGCSpec) DeepCopy() *TombstoneStoreGCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TombstoneStoreGCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TombstoneStoreGCStatus) DeepCopyInto(out *TombstoneStoreGCStatus) {
+	*out = *in
+	in.LastGCTime.DeepCopyInto(&out.LastGCTime)
+	if in.Stores != nil {
+		in, out := &in.Stores, &out.Stores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TombstoneStoreGCStatus.
+func (in *TombstoneStoreGCStatus) DeepCopy() *TombstoneStoreGCStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TombstoneStoreGCStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopSQLSpec) DeepCopyInto(out *TopSQLSpec) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RetentionPeriod != nil {
+		in, out := &in.RetentionPeriod, &out.RetentionPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StorageSizeLimit != nil {
+		in, out := &in.StorageSizeLimit, &out.StorageSizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopSQLSpec.
+func (in *TopSQLSpec) DeepCopy() *TopSQLSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TopSQLSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopSQLStatus) DeepCopyInto(out *TopSQLStatus) {
+	*out = *in
+	if in.StorageUsage != nil {
+		in, out := &in.StorageUsage, &out.StorageUsage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopSQLStatus.
+func (in *TopSQLStatus) DeepCopy() *TopSQLStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopSQLStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TopologySpreadConstraint) DeepCopyInto(out *TopologySpreadConstraint) {
 	*out = *in
@@ -8974,6 +11746,252 @@ func (in *User) DeepCopy() *User {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserGrant) DeepCopyInto(out *UserGrant) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserGrant.
+func (in *UserGrant) DeepCopy() *UserGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(UserGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserReconciliationSpec) DeepCopyInto(out *UserReconciliationSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Grants != nil {
+		in, out := &in.Grants, &out.Grants
+		*out = make([]UserGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserReconciliationSpec.
+func (in *UserReconciliationSpec) DeepCopy() *UserReconciliationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UserReconciliationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserReconciliationStatus) DeepCopyInto(out *UserReconciliationStatus) {
+	*out = *in
+	in.LastReconcileTime.DeepCopyInto(&out.LastReconcileTime)
+	if in.Recreated != nil {
+		in, out := &in.Recreated, &out.Recreated
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserReconciliationStatus.
+func (in *UserReconciliationStatus) DeepCopy() *UserReconciliationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UserReconciliationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionSkewInstance) DeepCopyInto(out *VersionSkewInstance) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSkewInstance.
+func (in *VersionSkewInstance) DeepCopy() *VersionSkewInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionSkewInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionSkewRepairPlan) DeepCopyInto(out *VersionSkewRepairPlan) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]VersionSkewRepairStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSkewRepairPlan.
+func (in *VersionSkewRepairPlan) DeepCopy() *VersionSkewRepairPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionSkewRepairPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionSkewRepairStep) DeepCopyInto(out *VersionSkewRepairStep) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSkewRepairStep.
+func (in *VersionSkewRepairStep) DeepCopy() *VersionSkewRepairStep {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionSkewRepairStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionSkewSpec) DeepCopyInto(out *VersionSkewSpec) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSkewSpec.
+func (in *VersionSkewSpec) DeepCopy() *VersionSkewSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionSkewSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionSkewStatus) DeepCopyInto(out *VersionSkewStatus) {
+	*out = *in
+	in.DetectedTime.DeepCopyInto(&out.DetectedTime)
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]VersionSkewInstance, len(*in))
+		copy(*out, *in)
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(VersionSkewRepairPlan)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSkewStatus.
+func (in *VersionSkewStatus) DeepCopy() *VersionSkewStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionSkewStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeRepairRecord) DeepCopyInto(out *VolumeRepairRecord) {
+	*out = *in
+	if in.PVCs != nil {
+		in, out := &in.PVCs, &out.PVCs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PVs != nil {
+		in, out := &in.PVs, &out.PVs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.RepairTime.DeepCopyInto(&out.RepairTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeRepairRecord.
+func (in *VolumeRepairRecord) DeepCopy() *VolumeRepairRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeRepairRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeTopology) DeepCopyInto(out *VolumeTopology) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeTopology.
+func (in *VolumeTopology) DeepCopy() *VolumeTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeTopology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookNotificationSink) DeepCopyInto(out *WebhookNotificationSink) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookNotificationSink.
+func (in *WebhookNotificationSink) DeepCopy() *WebhookNotificationSink {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookNotificationSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkerConfig) DeepCopyInto(out *WorkerConfig) {
 	*out = *in
@@ -9060,11 +12078,35 @@ func (in *WorkerSpec) DeepCopyInto(out *WorkerSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.StorageVolumes != nil {
+		in, out := &in.StorageVolumes, &out.StorageVolumes
+		*out = make([]StorageVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = new(WorkerConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SourcePlacements != nil {
+		in, out := &in.SourcePlacements, &out.SourcePlacements
+		*out = make([]DMSourcePlacement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RelayLogStorage != nil {
+		in, out := &in.RelayLogStorage, &out.RelayLogStorage
+		*out = new(RelayLogStorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -9100,6 +12142,20 @@ func (in *WorkerStatus) DeepCopyInto(out *WorkerStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
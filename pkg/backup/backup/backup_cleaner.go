@@ -55,6 +55,9 @@ func (bc *backupCleaner) Clean(backup *v1alpha1.Backup) error {
 		// The backup object has not been deleted or we need to retain backup data，do nothing
 		return nil
 	}
+	if backup.IsVolumeSnapshotBackup() {
+		return bc.cleanVolumeSnapshots(backup)
+	}
 	ns := backup.GetNamespace()
 	name := backup.GetName()
 	backupJobName := backup.GetBackupJobName()
@@ -154,6 +157,11 @@ func (bc *backupCleaner) makeCleanJob(backup *v1alpha1.Backup) (*batchv1.Job, st
 		volumes = append(volumes, localVolume)
 		volumeMounts = append(volumeMounts, localVolumeMount)
 	}
+	if backup.Spec.Azblob != nil && backup.Spec.Azblob.SecretName != "" {
+		volume, volumeMount := backuputil.GenerateAzblobCredentialsVolume(backup.Spec.Azblob)
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, volumeMount)
+	}
 
 	serviceAccount := constants.DefaultServiceAccountName
 	if backup.Spec.ServiceAccount != "" {
@@ -175,7 +183,7 @@ func (bc *backupCleaner) makeCleanJob(backup *v1alpha1.Backup) (*batchv1.Job, st
 			Containers: []corev1.Container{
 				{
 					Name:            label.BackupJobLabelVal,
-					Image:           bc.deps.CLIConfig.TiDBBackupManagerImage,
+					Image:           v1alpha1.ImageWithRegistryPrefix(backup.Spec.ClusterRegistryPrefix, bc.deps.CLIConfig.TiDBBackupManagerImage),
 					Args:            args,
 					ImagePullPolicy: corev1.PullIfNotPresent,
 					Env:             util.AppendEnvIfPresent(envVars, "TZ"),
@@ -213,9 +221,32 @@ func (bc *backupCleaner) makeCleanJob(backup *v1alpha1.Backup) (*batchv1.Job, st
 
 // ensureBackupJobDeleted ensure that backup Job have finished, it will delete the job if it is running
 func (bc *backupCleaner) ensureBackupJobFinished(backup *v1alpha1.Backup) (bool, error) {
+	jobNames := []string{backup.GetBackupJobName()}
+	if backup.IsSharded() {
+		jobNames = nil
+		for i := int32(0); i < backup.GetShardCount(); i++ {
+			jobNames = append(jobNames, backup.GetBackupShardJobName(i))
+		}
+	} else if backup.IsLogBackup() {
+		// the truncation is interrupted as the whole log backup is going to be cleaned
+		jobNames = append(jobNames, backup.GetLogTruncateJobName())
+	}
+
+	// all the Jobs are deleted at the same time if they are running
+	allFinished := true
+	for _, jobName := range jobNames {
+		finished, err := bc.ensureJobFinished(backup, jobName)
+		if err != nil {
+			return false, err
+		}
+		allFinished = allFinished && finished
+	}
+	return allFinished, nil
+}
+
+func (bc *backupCleaner) ensureJobFinished(backup *v1alpha1.Backup, backupJobName string) (bool, error) {
 	ns := backup.GetNamespace()
 	name := backup.GetName()
-	backupJobName := backup.GetBackupJobName()
 
 	backupJob, err := bc.deps.JobLister.Jobs(ns).Get(backupJobName)
 	if err != nil {
@@ -251,3 +282,37 @@ func (bc *backupCleaner) ensureBackupJobFinished(backup *v1alpha1.Backup) (bool,
 
 	return false, nil
 }
+
+// cleanVolumeSnapshots deletes the snapshots recorded in the meta ConfigMap of a volume-snapshot backup and
+// then the ConfigMap, the backup has no data in the storage to be cleaned
+func (bc *backupCleaner) cleanVolumeSnapshots(backup *v1alpha1.Backup) error {
+	if v1alpha1.IsBackupClean(backup) {
+		return nil
+	}
+	ns := backup.GetNamespace()
+	name := backup.GetName()
+
+	cm, err := bc.deps.UserConfigMapLister.ConfigMaps(ns).Get(backup.GetVolumeSnapshotMetaName())
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("backup %s/%s get configmap %s failed, err: %v", ns, name, backup.GetVolumeSnapshotMetaName(), err)
+	}
+	if err == nil {
+		meta, err := backuputil.DecodeVolumeSnapshotMeta(cm)
+		if err != nil {
+			return err
+		}
+		for _, vol := range meta.Volumes {
+			if err := bc.deps.VolumeSnapshotControl.DeleteVolumeSnapshot(backup, meta.ClusterNamespace, vol.SnapshotName); err != nil {
+				return fmt.Errorf("backup %s/%s delete volume snapshot %s/%s failed, err: %v", ns, name, meta.ClusterNamespace, vol.SnapshotName, err)
+			}
+		}
+		if err := bc.deps.ConfigMapControl.DeleteConfigMap(backup, cm); err != nil {
+			return fmt.Errorf("backup %s/%s delete configmap %s failed, err: %v", ns, name, cm.Name, err)
+		}
+		klog.Infof("backup %s/%s deleted %d volume snapshots", ns, name, len(meta.Volumes))
+	}
+	return bc.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupClean,
+		Status: corev1.ConditionTrue,
+	}, nil)
+}
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup"
@@ -29,6 +30,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 )
 
@@ -49,6 +52,16 @@ func NewBackupManager(deps *controller.Dependencies) backup.BackupManager {
 }
 
 func (bm *backupManager) Sync(backup *v1alpha1.Backup) error {
+	if backup.DeletionTimestamp != nil && backup.IsLogBackup() && !v1alpha1.IsLogBackupStopped(backup) {
+		// the task of the log backup is stopped before the backup data is cleaned
+		return bm.syncLogStopJob(backup)
+	}
+	if backup.DeletionTimestamp != nil && backup.IsVolumeSnapshotBackup() && !backup.Status.TimeStarted.IsZero() &&
+		!v1alpha1.IsBackupComplete(backup) && !v1alpha1.IsBackupFailed(backup) {
+		// the scheduling halted by the running backup is resumed before the snapshots are cleaned
+		return bm.abortVolumeSnapshotBackup(backup)
+	}
+
 	// because a finalizer is installed on the backup on creation, when backup is deleted,
 	// backup.DeletionTimestamp will be set, controller will be informed with an onUpdate event,
 	// this is the moment that we can do clean up work.
@@ -61,6 +74,10 @@ func (bm *backupManager) Sync(backup *v1alpha1.Backup) error {
 		return nil
 	}
 
+	if backuputil.NeedRestoreTikvGCLifeTime(backup) {
+		return bm.restoreTikvGCLifeTime(backup)
+	}
+
 	return bm.syncBackupJob(backup)
 }
 
@@ -74,6 +91,7 @@ func (bm *backupManager) syncBackupJob(backup *v1alpha1.Backup) error {
 	backupJobName := backup.GetBackupJobName()
 
 	var err error
+	var tc *v1alpha1.TidbCluster
 	if backup.Spec.BR == nil {
 		err = backuputil.ValidateBackup(backup, "")
 	} else {
@@ -82,7 +100,6 @@ func (bm *backupManager) syncBackupJob(backup *v1alpha1.Backup) error {
 			backupNamespace = backup.Spec.BR.ClusterNamespace
 		}
 
-		var tc *v1alpha1.TidbCluster
 		tc, err = bm.deps.TiDBClusterLister.TidbClusters(backupNamespace).Get(backup.Spec.BR.Cluster)
 		if err != nil {
 			reason := fmt.Sprintf("failed to fetch tidbcluster %s/%s", backupNamespace, backup.Spec.BR.Cluster)
@@ -110,8 +127,23 @@ func (bm *backupManager) syncBackupJob(backup *v1alpha1.Backup) error {
 		return controller.IgnoreErrorf("invalid backup spec %s/%s cause %s", ns, name, err.Error())
 	}
 
-	_, err = bm.deps.JobLister.Jobs(ns).Get(backupJobName)
+	if backup.IsVolumeSnapshotBackup() {
+		return bm.syncVolumeSnapshotBackup(backup, tc)
+	}
+
+	if backup.IsSharded() {
+		return bm.syncShardedExportJobs(backup)
+	}
+
+	existingJob, err := bm.deps.JobLister.Jobs(ns).Get(backupJobName)
 	if err == nil {
+		if backup.IsLogBackup() {
+			// the task of the log backup has been started, truncate it if required
+			return bm.syncLogTruncateJob(backup)
+		}
+		if getJobFinishedCondition(existingJob) == batchv1.JobFailed {
+			return bm.syncFailedBackupJob(backup, backupJobName)
+		}
 		// already have a backup job running，return directly
 		return nil
 	}
@@ -124,7 +156,7 @@ func (bm *backupManager) syncBackupJob(backup *v1alpha1.Backup) error {
 	var reason string
 	if backup.Spec.BR == nil {
 		// not found backup job, so we need to create it
-		job, reason, err = bm.makeExportJob(backup)
+		job, reason, err = bm.makeExportJob(backup, -1)
 		if err != nil {
 			bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
 				Type:    v1alpha1.BackupRetryFailed,
@@ -135,7 +167,7 @@ func (bm *backupManager) syncBackupJob(backup *v1alpha1.Backup) error {
 			return err
 		}
 
-		reason, err = bm.ensureBackupPVCExist(backup)
+		reason, err = bm.ensureBackupPVCExist(backup, backup.GetBackupPVCName())
 		if err != nil {
 			bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
 				Type:    v1alpha1.BackupRetryFailed,
@@ -177,10 +209,158 @@ func (bm *backupManager) syncBackupJob(backup *v1alpha1.Backup) error {
 	}, nil)
 }
 
-func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, string, error) {
+// syncShardedExportJobs creates a Job for each shard of the backup, and completes
+// the backup with the aggregated status after all the shards are complete
+func (bm *backupManager) syncShardedExportJobs(backup *v1alpha1.Backup) error {
 	ns := backup.GetNamespace()
 	name := backup.GetName()
 
+	if v1alpha1.IsBackupShardsComplete(backup) {
+		return bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:   v1alpha1.BackupComplete,
+			Status: corev1.ConditionTrue,
+		}, aggregateBackupShardsStatus(backup))
+	}
+
+	created := false
+	for i := int32(0); i < backup.GetShardCount(); i++ {
+		backupJobName := backup.GetBackupShardJobName(i)
+		existingJob, err := bm.deps.JobLister.Jobs(ns).Get(backupJobName)
+		if err == nil {
+			if getJobFinishedCondition(existingJob) == batchv1.JobFailed {
+				return bm.syncFailedBackupJob(backup, backupJobName)
+			}
+			// already have a backup job for the shard
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("backup %s/%s get job %s failed, err: %v", ns, name, backupJobName, err)
+		}
+
+		job, reason, err := bm.makeExportJob(backup, i)
+		if err != nil {
+			bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:    v1alpha1.BackupRetryFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  reason,
+				Message: err.Error(),
+			}, nil)
+			return err
+		}
+
+		if err := bm.deps.JobControl.CreateJob(backup, job); err != nil {
+			errMsg := fmt.Errorf("create backup %s/%s job %s failed, err: %v", ns, name, backupJobName, err)
+			bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:    v1alpha1.BackupRetryFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "CreateBackupJobFailed",
+				Message: errMsg.Error(),
+			}, nil)
+			return errMsg
+		}
+		created = true
+	}
+
+	if !created {
+		return nil
+	}
+	return bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupScheduled,
+		Status: corev1.ConditionTrue,
+	}, nil)
+}
+
+// syncFailedBackupJob fails the backup after its job fails, as the job isn't retried and its pod
+// may die before updating the status, then restores the tikv_gc_life_time adjusted by the backup
+func (bm *backupManager) syncFailedBackupJob(backup *v1alpha1.Backup, jobName string) error {
+	err := bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:    v1alpha1.BackupFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  "BackupJobFailed",
+		Message: fmt.Sprintf("job %s/%s failed", backup.GetNamespace(), jobName),
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if !backuputil.NeedRestoreTikvGCLifeTime(backup) {
+		return nil
+	}
+	return bm.restoreTikvGCLifeTime(backup)
+}
+
+// restoreTikvGCLifeTime restores the original tikv_gc_life_time recorded by the failed backup, or hands it
+// over to other running backups of the same cluster which still need the adjusted one
+func (bm *backupManager) restoreTikvGCLifeTime(backup *v1alpha1.Backup) error {
+	ns := backup.GetNamespace()
+	name := backup.GetName()
+	status := backup.Status.TikvGCLifeTime.DeepCopy()
+
+	backups, err := bm.deps.BackupLister.Backups(ns).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	if backuputil.IsTikvGCLifeTimeAdjustedByOthers(backup, backups) {
+		klog.Infof("backup %s/%s hands over restoring tikv_gc_life_time to other running backups", ns, name)
+		status.State = v1alpha1.TikvGCLifeTimeHandedOver
+	} else {
+		secret, err := bm.deps.SecretLister.Secrets(ns).Get(backup.Spec.From.SecretName)
+		if err != nil {
+			return fmt.Errorf("backup %s/%s get secret %s failed, err: %v", ns, name, backup.Spec.From.SecretName, err)
+		}
+		if err := bm.deps.TiDBControl.SetTikvGCLifeTime(backup.Spec.From, string(secret.Data[constants.TidbPasswordKey]), status.Original); err != nil {
+			return fmt.Errorf("backup %s/%s restore tikv_gc_life_time to %s failed, err: %v", ns, name, status.Original, err)
+		}
+		klog.Infof("backup %s/%s restored tikv_gc_life_time to %s", ns, name, status.Original)
+		status.State = v1alpha1.TikvGCLifeTimeRestored
+	}
+	// the failed condition is kept as is
+	_, condition := v1alpha1.GetBackupCondition(&backup.Status, v1alpha1.BackupFailed)
+	return bm.statusUpdater.Update(backup, condition.DeepCopy(), &controller.BackupUpdateStatus{
+		TikvGCLifeTime: status,
+	})
+}
+
+// aggregateBackupShardsStatus sums up the data size of all the shards, the backup
+// starts with the first shard and completes with the last one
+func aggregateBackupShardsStatus(backup *v1alpha1.Backup) *controller.BackupUpdateStatus {
+	var (
+		size          int64
+		timeStarted   metav1.Time
+		timeCompleted metav1.Time
+	)
+	for i, shard := range backup.Status.Shards {
+		size += shard.BackupSize
+		if i == 0 || shard.TimeStarted.Before(&timeStarted) {
+			timeStarted = shard.TimeStarted
+		}
+		if timeCompleted.Before(&shard.TimeCompleted) {
+			timeCompleted = shard.TimeCompleted
+		}
+	}
+	sizeReadable := humanize.Bytes(uint64(size))
+	// all the shards are exported at the same snapshot
+	commitTs := backup.Status.Shards[0].CommitTs
+	return &controller.BackupUpdateStatus{
+		TimeStarted:        &timeStarted,
+		TimeCompleted:      &timeCompleted,
+		BackupSize:         &size,
+		BackupSizeReadable: &sizeReadable,
+		CommitTs:           &commitTs,
+	}
+}
+
+// makeExportJob makes the Job exporting the data of the shard with the index,
+// the index is ignored if the backup is not sharded
+func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup, shard int32) (*batchv1.Job, string, error) {
+	ns := backup.GetNamespace()
+	name := backup.GetName()
+	jobName := backup.GetBackupJobName()
+	pvcName := backup.GetBackupPVCName()
+	if backup.IsSharded() {
+		jobName = backup.GetBackupShardJobName(shard)
+		pvcName = backup.GetBackupShardPVCName(shard)
+	}
+
 	envVars, reason, err := backuputil.GenerateTidbPasswordEnv(ns, name, backup.Spec.From.SecretName, backup.Spec.UseKMS, bm.deps.SecretLister)
 	if err != nil {
 		return nil, reason, err
@@ -196,7 +376,7 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 	envVars = util.AppendOverwriteEnv(envVars, backup.Spec.Env)
 
 	// TODO: make pvc request storage size configurable
-	reason, err = bm.ensureBackupPVCExist(backup)
+	reason, err = bm.ensureBackupPVCExist(backup, pvcName)
 	if err != nil {
 		return nil, reason, err
 	}
@@ -213,6 +393,9 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 		fmt.Sprintf("--bucket=%s", bucketName),
 		fmt.Sprintf("--storageType=%s", backuputil.GetStorageType(backup.Spec.StorageProvider)),
 	}
+	if backup.IsSharded() {
+		args = append(args, fmt.Sprintf("--shard=%d", shard))
+	}
 
 	volumeMounts := []corev1.VolumeMount{}
 	volumes := []corev1.Volume{}
@@ -251,7 +434,7 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 		})
 		initContainers = append(initContainers, corev1.Container{
 			Name:            "dumpling",
-			Image:           backup.Spec.ToolImage,
+			Image:           v1alpha1.ImageWithRegistryPrefix(backup.Spec.ClusterRegistryPrefix, backup.Spec.ToolImage),
 			Command:         []string{"/bin/sh", "-c"},
 			Args:            []string{fmt.Sprintf("cp /dumpling %s/dumpling; echo 'dumpling copy finished'", util.DumplingBinPath)},
 			ImagePullPolicy: corev1.PullIfNotPresent,
@@ -260,6 +443,18 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 		})
 	}
 
+	builtinMounts := append([]corev1.VolumeMount{{Name: label.BackupJobLabelVal, MountPath: constants.BackupRootPath}}, volumeMounts...)
+	builtinVolumes := append([]corev1.Volume{{Name: label.BackupJobLabelVal}}, volumes...)
+	if err := util.ValidateAdditionalVolumes(builtinVolumes, builtinMounts, backup.Spec.AdditionalVolumes, backup.Spec.AdditionalVolumeMounts); err != nil {
+		return nil, fmt.Sprintf("invalid additional volumes of backup %s/%s", ns, name), err
+	}
+	additionalVolumesHash, err := util.AdditionalVolumesHash(bm.deps.UserConfigMapLister, bm.deps.SecretLister, ns, backup.Spec.AdditionalVolumes)
+	if err != nil {
+		return nil, fmt.Sprintf("failed to hash the additional volumes of backup %s/%s", ns, name), err
+	}
+	volumes = append(volumes, backup.Spec.AdditionalVolumes...)
+	volumeMounts = append(volumeMounts, backup.Spec.AdditionalVolumeMounts...)
+
 	serviceAccount := constants.DefaultServiceAccountName
 	if backup.Spec.ServiceAccount != "" {
 		serviceAccount = backup.Spec.ServiceAccount
@@ -269,6 +464,9 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 	podLabels := jobLabels
 	jobAnnotations := backup.Annotations
 	podAnnotations := backup.Annotations
+	if additionalVolumesHash != "" {
+		podAnnotations = util.CombineStringMap(map[string]string{label.AnnAdditionalVolumesHash: additionalVolumesHash}, podAnnotations)
+	}
 
 	// TODO: need add ResourceRequirement for backup job
 	podSpec := &corev1.PodTemplateSpec{
@@ -283,7 +481,7 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 			Containers: []corev1.Container{
 				{
 					Name:            label.BackupJobLabelVal,
-					Image:           bm.deps.CLIConfig.TiDBBackupManagerImage,
+					Image:           v1alpha1.ImageWithRegistryPrefix(backup.Spec.ClusterRegistryPrefix, bm.deps.CLIConfig.TiDBBackupManagerImage),
 					Args:            args,
 					ImagePullPolicy: corev1.PullIfNotPresent,
 					VolumeMounts: append([]corev1.VolumeMount{
@@ -302,7 +500,7 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 					Name: label.BackupJobLabelVal,
 					VolumeSource: corev1.VolumeSource{
 						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-							ClaimName: backup.GetBackupPVCName(),
+							ClaimName: pvcName,
 						},
 					},
 				},
@@ -313,7 +511,7 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        backup.GetBackupJobName(),
+			Name:        jobName,
 			Namespace:   ns,
 			Labels:      jobLabels,
 			Annotations: jobAnnotations,
@@ -332,6 +530,12 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 
 // makeBackupJob requires that backup.Spec.BR != nil
 func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, string, error) {
+	jobLabel := label.NewBackup().Instance(backup.GetInstanceName()).BackupJob().Backup(backup.GetName())
+	return bm.makeBRJob(backup, backup.GetBackupJobName(), jobLabel)
+}
+
+// makeBRJob makes the Job running backup manager with BR, the args are appended to the args of the backup command
+func (bm *backupManager) makeBRJob(backup *v1alpha1.Backup, jobName string, jobLabel label.Label, extraArgs ...string) (*batchv1.Job, string, error) {
 	ns := backup.GetNamespace()
 	name := backup.GetName()
 	backupNamespace := ns
@@ -364,6 +568,18 @@ func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 		Name:  "BR_LOG_TO_TERM",
 		Value: string(rune(1)),
 	})
+	// limit the threads of BR to the CPU limit of the Job, so that BR is not throttled by the CPU quota
+	if _, ok := backup.Spec.ResourceRequirements.Limits[corev1.ResourceCPU]; ok {
+		envVars = append(envVars, corev1.EnvVar{
+			Name: "GOMAXPROCS",
+			ValueFrom: &corev1.EnvVarSource{
+				ResourceFieldRef: &corev1.ResourceFieldSelector{
+					ContainerName: label.BackupJobLabelVal,
+					Resource:      "limits.cpu",
+				},
+			},
+		})
+	}
 
 	// set env vars specified in backup.Spec.Env
 	envVars = util.AppendOverwriteEnv(envVars, backup.Spec.Env)
@@ -378,8 +594,9 @@ func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 	if tikvVersion != "" {
 		args = append(args, fmt.Sprintf("--tikvVersion=%s", tikvVersion))
 	}
+	args = append(args, extraArgs...)
 
-	jobLabels := util.CombineStringMap(label.NewBackup().Instance(backup.GetInstanceName()).BackupJob().Backup(name), backup.Labels)
+	jobLabels := util.CombineStringMap(jobLabel, backup.Labels)
 	podLabels := jobLabels
 	jobAnnotations := backup.Annotations
 	podAnnotations := jobAnnotations
@@ -387,6 +604,10 @@ func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 	volumeMounts := []corev1.VolumeMount{}
 	volumes := []corev1.Volume{}
 
+	// only pass the customized port to keep compatible with the backup manager images without the flag
+	if tc.PDClientPort() != v1alpha1.DefaultPDClientPort {
+		args = append(args, fmt.Sprintf("--pd-port=%d", tc.PDClientPort()))
+	}
 	if tc.IsTLSClusterEnabled() {
 		args = append(args, "--cluster-tls=true")
 		volumeMounts = append(volumeMounts, corev1.VolumeMount{
@@ -444,6 +665,24 @@ func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 		volumes = append(volumes, backup.Spec.Local.Volume)
 		volumeMounts = append(volumeMounts, backup.Spec.Local.VolumeMount)
 	}
+	if backup.Spec.Azblob != nil && backup.Spec.Azblob.SecretName != "" {
+		volume, volumeMount := backuputil.GenerateAzblobCredentialsVolume(backup.Spec.Azblob)
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, volumeMount)
+	}
+
+	if err := util.ValidateAdditionalVolumes(volumes, volumeMounts, backup.Spec.AdditionalVolumes, backup.Spec.AdditionalVolumeMounts); err != nil {
+		return nil, fmt.Sprintf("invalid additional volumes of backup %s/%s", ns, name), err
+	}
+	additionalVolumesHash, err := util.AdditionalVolumesHash(bm.deps.UserConfigMapLister, bm.deps.SecretLister, ns, backup.Spec.AdditionalVolumes)
+	if err != nil {
+		return nil, fmt.Sprintf("failed to hash the additional volumes of backup %s/%s", ns, name), err
+	}
+	if additionalVolumesHash != "" {
+		podAnnotations = util.CombineStringMap(map[string]string{label.AnnAdditionalVolumesHash: additionalVolumesHash}, podAnnotations)
+	}
+	volumes = append(volumes, backup.Spec.AdditionalVolumes...)
+	volumeMounts = append(volumeMounts, backup.Spec.AdditionalVolumeMounts...)
 
 	serviceAccount := constants.DefaultServiceAccountName
 	if backup.Spec.ServiceAccount != "" {
@@ -471,7 +710,7 @@ func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 			InitContainers: []corev1.Container{
 				{
 					Name:            "br",
-					Image:           brImage,
+					Image:           v1alpha1.ImageWithRegistryPrefix(backup.Spec.ClusterRegistryPrefix, brImage),
 					Command:         []string{"/bin/sh", "-c"},
 					Args:            []string{fmt.Sprintf("cp /br %s/br; echo 'BR copy finished'", util.BRBinPath)},
 					ImagePullPolicy: corev1.PullIfNotPresent,
@@ -482,7 +721,7 @@ func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 			Containers: []corev1.Container{
 				{
 					Name:            label.BackupJobLabelVal,
-					Image:           bm.deps.CLIConfig.TiDBBackupManagerImage,
+					Image:           v1alpha1.ImageWithRegistryPrefix(backup.Spec.ClusterRegistryPrefix, bm.deps.CLIConfig.TiDBBackupManagerImage),
 					Args:            args,
 					ImagePullPolicy: corev1.PullIfNotPresent,
 					VolumeMounts:    volumeMounts,
@@ -501,7 +740,7 @@ func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        backup.GetBackupJobName(),
+			Name:        jobName,
 			Namespace:   ns,
 			Labels:      jobLabels,
 			Annotations: jobAnnotations,
@@ -518,7 +757,7 @@ func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 	return job, "", nil
 }
 
-func (bm *backupManager) ensureBackupPVCExist(backup *v1alpha1.Backup) (string, error) {
+func (bm *backupManager) ensureBackupPVCExist(backup *v1alpha1.Backup, backupPVCName string) (string, error) {
 	ns := backup.GetNamespace()
 	name := backup.GetName()
 
@@ -531,7 +770,6 @@ func (bm *backupManager) ensureBackupPVCExist(backup *v1alpha1.Backup) (string,
 		errMsg := fmt.Errorf("backup %s/%s parse storage size %s failed, err: %v", ns, name, constants.DefaultStorageSize, err)
 		return "ParseStorageSizeFailed", errMsg
 	}
-	backupPVCName := backup.GetBackupPVCName()
 	pvc, err := bm.deps.PVCLister.PersistentVolumeClaims(ns).Get(backupPVCName)
 
 	if err == nil {
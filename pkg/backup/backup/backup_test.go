@@ -22,12 +22,16 @@ import (
 
 	"github.com/onsi/gomega"
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/testutils"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
@@ -214,6 +218,146 @@ func TestBackupManagerDumpling(t *testing.T) {
 	g.Expect(job.Spec.Template.Spec.Containers[0].Env).NotTo(gomega.ContainElement(env2No))
 }
 
+func TestBackupManagerShardedDumpling(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+	var err error
+
+	bm := NewBackupManager(deps).(*backupManager)
+
+	backup := validDumplingBackup()
+	backup.Spec.Dumpling = &v1alpha1.DumplingConfig{ShardCount: 3}
+	_, err = deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Create(context.TODO(), backup, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	helper.CreateSecret(backup)
+
+	// a job is created for each shard
+	err = bm.syncBackupJob(backup)
+	g.Expect(err).Should(BeNil())
+	helper.hasCondition(backup.Namespace, backup.Name, v1alpha1.BackupScheduled, "")
+	for i := int32(0); i < 3; i++ {
+		job, err := deps.KubeClientset.BatchV1().Jobs(backup.Namespace).Get(context.TODO(), backup.GetBackupShardJobName(i), metav1.GetOptions{})
+		g.Expect(err).Should(BeNil())
+		g.Expect(job.Spec.Template.Spec.Containers[0].Args).To(ContainElement(fmt.Sprintf("--shard=%d", i)))
+		g.Expect(job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(backup.GetBackupShardPVCName(i)))
+	}
+	_, err = deps.KubeClientset.BatchV1().Jobs(backup.Namespace).Get(context.TODO(), backup.GetBackupJobName(), metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	// the backup is complete after all the shards are complete
+	start := metav1.NewTime(time.Unix(1000, 0))
+	end := metav1.NewTime(time.Unix(2000, 0))
+	for i := int32(0); i < 3; i++ {
+		backup.Status.Shards = append(backup.Status.Shards, v1alpha1.BackupShardStatus{
+			Index:         i,
+			Phase:         v1alpha1.BackupComplete,
+			BackupSize:    1024,
+			CommitTs:      "421762809912885249",
+			TimeStarted:   metav1.NewTime(start.Add(time.Duration(i) * time.Second)),
+			TimeCompleted: metav1.NewTime(end.Add(-time.Duration(i) * time.Second)),
+		})
+	}
+	err = bm.syncBackupJob(backup)
+	g.Expect(err).Should(BeNil())
+	helper.hasCondition(backup.Namespace, backup.Name, v1alpha1.BackupComplete, "")
+	get, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Get(context.TODO(), backup.Name, metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Expect(get.Status.BackupSize).To(Equal(int64(3072)))
+	g.Expect(get.Status.BackupSizeReadable).To(Equal("3.1 kB"))
+	g.Expect(get.Status.CommitTs).To(Equal("421762809912885249"))
+	g.Expect(get.Status.TimeStarted.Equal(&start)).To(BeTrue())
+	g.Expect(get.Status.TimeCompleted.Equal(&end)).To(BeTrue())
+}
+
+func TestBackupManagerRestoreTikvGCLifeTime(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+	tidbControl := deps.TiDBControl.(*controller.FakeTiDBControl)
+
+	bm := NewBackupManager(deps).(*backupManager)
+
+	adjust := func(backup *v1alpha1.Backup, original string) {
+		v1alpha1.UpdateBackupCondition(&backup.Status, &v1alpha1.BackupCondition{Type: v1alpha1.BackupRunning, Status: corev1.ConditionTrue})
+		backup.Status.TikvGCLifeTime = &v1alpha1.TikvGCLifeTimeStatus{Original: original, Adjusted: "72h", State: v1alpha1.TikvGCLifeTimeAdjusted}
+		_, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Update(context.TODO(), backup, metav1.UpdateOptions{})
+		g.Expect(err).Should(BeNil())
+		g.Eventually(func() bool {
+			get, err := deps.BackupLister.Backups(backup.Namespace).Get(backup.Name)
+			return err == nil && get.Status.TikvGCLifeTime != nil
+		}, time.Second*10).Should(BeTrue())
+	}
+	getStatus := func(backup *v1alpha1.Backup) *v1alpha1.TikvGCLifeTimeStatus {
+		get, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Get(context.TODO(), backup.Name, metav1.GetOptions{})
+		g.Expect(err).Should(BeNil())
+		return get.Status.TikvGCLifeTime
+	}
+
+	backup := validDumplingBackup()
+	_, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Create(context.TODO(), backup, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	helper.CreateSecret(backup)
+	err = bm.syncBackupJob(backup)
+	g.Expect(err).Should(BeNil())
+	adjust(backup, "10m")
+
+	// the job is still running
+	err = bm.syncBackupJob(backup)
+	g.Expect(err).Should(BeNil())
+	_, ok := tidbControl.GetTikvGCLifeTime("localhost", v1alpha1.DefaultTidbPort)
+	g.Expect(ok).To(BeFalse())
+
+	// the job fails before the backup manager in it restores the tikv_gc_life_time
+	job, err := deps.KubeClientset.BatchV1().Jobs(backup.Namespace).Get(context.TODO(), backup.GetBackupJobName(), metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	_, err = deps.KubeClientset.BatchV1().Jobs(job.Namespace).UpdateStatus(context.TODO(), job, metav1.UpdateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() batchv1.JobConditionType {
+		job, err := deps.JobLister.Jobs(job.Namespace).Get(job.Name)
+		if err != nil {
+			return ""
+		}
+		return getJobFinishedCondition(job)
+	}, time.Second*10).Should(Equal(batchv1.JobFailed))
+
+	// another running backup of the same cluster still needs the adjusted one
+	running := validDumplingBackup()
+	running.Name = "running"
+	_, err = deps.Clientset.PingcapV1alpha1().Backups(running.Namespace).Create(context.TODO(), running, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	adjust(running, "10m")
+	err = bm.syncBackupJob(backup)
+	g.Expect(err).Should(BeNil())
+	helper.hasCondition(backup.Namespace, backup.Name, v1alpha1.BackupFailed, "BackupJobFailed")
+	g.Expect(getStatus(backup).State).To(Equal(v1alpha1.TikvGCLifeTimeHandedOver))
+	_, ok = tidbControl.GetTikvGCLifeTime("localhost", v1alpha1.DefaultTidbPort)
+	g.Expect(ok).To(BeFalse())
+
+	// the running backup fails too, and the failed backups are not counted
+	v1alpha1.UpdateBackupCondition(&running.Status, &v1alpha1.BackupCondition{Type: v1alpha1.BackupFailed, Status: corev1.ConditionTrue, Reason: "AlreadyFailed"})
+	_, err = deps.Clientset.PingcapV1alpha1().Backups(running.Namespace).Update(context.TODO(), running, metav1.UpdateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() bool {
+		get, err := deps.BackupLister.Backups(running.Namespace).Get(running.Name)
+		return err == nil && v1alpha1.IsBackupFailed(get)
+	}, time.Second*10).Should(BeTrue())
+	g.Expect(backuputil.NeedRestoreTikvGCLifeTime(running)).To(BeTrue())
+	err = bm.Sync(running)
+	g.Expect(err).Should(BeNil())
+	g.Expect(getStatus(running).State).To(Equal(v1alpha1.TikvGCLifeTimeRestored))
+	helper.hasCondition(running.Namespace, running.Name, v1alpha1.BackupFailed, "AlreadyFailed")
+	gcLifeTime, ok := tidbControl.GetTikvGCLifeTime("localhost", v1alpha1.DefaultTidbPort)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(gcLifeTime).To(Equal("10m"))
+	g.Expect(backuputil.NeedRestoreTikvGCLifeTime(running)).To(BeFalse())
+}
+
 func TestBackupManagerBR(t *testing.T) {
 	g := NewGomegaWithT(t)
 	helper := newHelper(t)
@@ -233,6 +377,11 @@ func TestBackupManagerBR(t *testing.T) {
 
 	// test valid backups
 	for i, backup := range genValidBRBackups() {
+		backup.Spec.AdditionalVolumes = []corev1.Volume{{
+			Name:         "ca-bundle",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"}}},
+		}}
+		backup.Spec.AdditionalVolumeMounts = []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/custom"}}
 		_, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Create(context.TODO(), backup, metav1.CreateOptions{})
 		g.Expect(err).Should(BeNil())
 
@@ -268,9 +417,49 @@ func TestBackupManagerBR(t *testing.T) {
 		g.Expect(job.Spec.Template.Spec.Containers[0].Env).To(gomega.ContainElement(env1))
 		g.Expect(job.Spec.Template.Spec.Containers[0].Env).To(gomega.ContainElement(env2Yes))
 		g.Expect(job.Spec.Template.Spec.Containers[0].Env).NotTo(gomega.ContainElement(env2No))
+		g.Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(backup.Spec.AdditionalVolumes[0]))
+		g.Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(backup.Spec.AdditionalVolumeMounts[0]))
+		g.Expect(job.Spec.Template.Annotations).To(HaveKey(label.AnnAdditionalVolumesHash))
+		for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+			g.Expect(env.Name).NotTo(Equal("GOMAXPROCS"))
+		}
 	}
 }
 
+func TestBackupManagerBRResources(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+
+	bm := NewBackupManager(deps).(*backupManager)
+	backup := genValidBRBackups()[0]
+	backup.Spec.ResourceRequirements = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+	}
+	helper.CreateSecret(backup)
+	helper.CreateTC(backup.Spec.BR.ClusterNamespace, backup.Spec.BR.Cluster)
+
+	job, _, err := bm.makeBackupJob(backup)
+	g.Expect(err).NotTo(HaveOccurred())
+	podSpec := job.Spec.Template.Spec
+	g.Expect(podSpec.InitContainers[0].Resources).To(Equal(backup.Spec.ResourceRequirements))
+	g.Expect(podSpec.Containers[0].Resources).To(Equal(backup.Spec.ResourceRequirements))
+	g.Expect(podSpec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+		Name: "GOMAXPROCS",
+		ValueFrom: &corev1.EnvVarSource{
+			ResourceFieldRef: &corev1.ResourceFieldSelector{ContainerName: label.BackupJobLabelVal, Resource: "limits.cpu"},
+		},
+	}))
+
+	// the env set in the spec takes precedence
+	backup.Spec.Env = append(backup.Spec.Env, corev1.EnvVar{Name: "GOMAXPROCS", Value: "1"})
+	job, _, err = bm.makeBackupJob(backup)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "GOMAXPROCS", Value: "1"}))
+}
+
 func TestClean(t *testing.T) {
 	g := NewGomegaWithT(t)
 	helper := newHelper(t)
@@ -366,3 +555,174 @@ func TestClean(t *testing.T) {
 	}
 
 }
+
+func TestBackupManagerVolumeSnapshot(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+
+	backup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap", Namespace: "ns"},
+		Spec: v1alpha1.BackupSpec{
+			Mode:        v1alpha1.BackupModeVolumeSnapshot,
+			BR:          &v1alpha1.BRConfig{Cluster: "cluster"},
+			CleanPolicy: v1alpha1.CleanPolicyTypeDelete,
+		},
+	}
+	_, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Create(context.TODO(), backup, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "ns"}}
+	_, err = deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() error {
+		_, err := deps.TiDBClusterLister.TidbClusters(tc.Namespace).Get(tc.Name)
+		return err
+	}, time.Second*10).Should(BeNil())
+
+	var haltScheduling interface{}
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.UpdateScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		haltScheduling = action.Config["halt-scheduling"]
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.GetMinResolvedTSActionType, func(action *pdapi.Action) (interface{}, error) {
+		// the resolved ts is got with the scheduling halted
+		g.Expect(haltScheduling).To(Equal(true))
+		return &pdapi.MinResolvedTS{MinResolvedTS: 420000000000000000, IsRealTime: true}, nil
+	})
+	pvcIndexer := deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+	for _, l := range []label.Label{label.New().Instance("cluster").PD(), label.New().Instance("cluster").TiKV()} {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-cluster-%s-0", l[label.ComponentLabelKey], l[label.ComponentLabelKey]), Namespace: "ns", Labels: l.Labels()},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}},
+			},
+		}
+		g.Expect(pvcIndexer.Add(pvc)).Should(Succeed())
+	}
+
+	bm := NewBackupManager(deps).(*backupManager)
+	snapshots := deps.VolumeSnapshotControl.(*controller.FakeVolumeSnapshotControl)
+
+	// the snapshots are created with the scheduling halted
+	err = bm.syncBackupJob(backup)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(haltScheduling).To(Equal(true))
+	g.Expect(snapshots.Sources).To(Equal(map[string]string{
+		"ns/snap-pd-cluster-pd-0":     "pd-cluster-pd-0",
+		"ns/snap-tikv-cluster-tikv-0": "tikv-cluster-tikv-0",
+	}))
+	cm, err := deps.UserConfigMapLister.ConfigMaps("ns").Get(backup.GetVolumeSnapshotMetaName())
+	g.Expect(err).Should(BeNil())
+	meta, err := backuputil.DecodeVolumeSnapshotMeta(cm)
+	g.Expect(err).Should(BeNil())
+	g.Expect(meta.Volumes).To(HaveLen(2))
+	g.Expect(meta.Volumes[1].Storage).To(Equal("10Gi"))
+	g.Expect(meta.ResolvedTS).To(Equal(uint64(420000000000000000)))
+	helper.hasCondition(backup.Namespace, backup.Name, v1alpha1.BackupRunning, "")
+
+	// the scheduling is resumed once all the snapshots are cut
+	for _, state := range snapshots.Snapshots {
+		state.CreationTime = "2021-01-01T00:00:00Z"
+	}
+	err = bm.syncBackupJob(backup)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(haltScheduling).To(Equal(false))
+
+	// the backup is complete after all the snapshots are ready
+	for _, state := range snapshots.Snapshots {
+		state.ReadyToUse = true
+		state.RestoreSize = "10Gi"
+	}
+	err = bm.syncBackupJob(backup)
+	g.Expect(err).Should(BeNil())
+	helper.hasCondition(backup.Namespace, backup.Name, v1alpha1.BackupComplete, "")
+	get, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Get(context.TODO(), backup.Name, metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Expect(get.Status.BackupSize).To(Equal(int64(20 << 30)))
+	g.Expect(get.Status.CommitTs).To(Equal("420000000000000000"))
+
+	// the snapshots are deleted by the cleaner
+	get.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	err = bm.backupCleaner.Clean(get)
+	g.Expect(err).Should(BeNil())
+	g.Expect(snapshots.Snapshots).To(BeEmpty())
+	helper.hasCondition(backup.Namespace, backup.Name, v1alpha1.BackupClean, "")
+}
+
+func TestBackupManagerVolumeSnapshotRetry(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+
+	newBackup := func(name string, created time.Time) *v1alpha1.Backup {
+		backup := &v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", CreationTimestamp: metav1.NewTime(created)},
+			Spec: v1alpha1.BackupSpec{
+				Mode: v1alpha1.BackupModeVolumeSnapshot,
+				BR:   &v1alpha1.BRConfig{Cluster: "cluster"},
+			},
+		}
+		_, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Create(context.TODO(), backup, metav1.CreateOptions{})
+		g.Expect(err).Should(BeNil())
+		g.Eventually(func() error {
+			_, err := deps.BackupLister.Backups(backup.Namespace).Get(backup.Name)
+			return err
+		}, time.Second*10).Should(BeNil())
+		return backup
+	}
+	now := time.Now()
+	first := newBackup("first", now.Add(-time.Minute))
+	second := newBackup("second", now)
+	tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "ns"}}
+	_, err := deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() error {
+		_, err := deps.TiDBClusterLister.TidbClusters(tc.Namespace).Get(tc.Name)
+		return err
+	}, time.Second*10).Should(BeNil())
+
+	var haltScheduling []interface{}
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.UpdateScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		haltScheduling = append(haltScheduling, action.Config["halt-scheduling"])
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.GetMinResolvedTSActionType, func(action *pdapi.Action) (interface{}, error) {
+		return nil, fmt.Errorf("min resolved ts is not supported")
+	})
+
+	bm := NewBackupManager(deps).(*backupManager)
+
+	// the second backup waits for the first one of the same cluster
+	err = bm.syncBackupJob(second)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(haltScheduling).To(BeEmpty())
+
+	// the scheduling is resumed after every failed attempt, and the backup fails after the retries
+	for i := 1; i <= maxVolumeSnapshotRetries; i++ {
+		err = bm.syncBackupJob(first)
+		g.Expect(err).Should(HaveOccurred())
+		g.Expect(haltScheduling).To(Equal([]interface{}{true, false}))
+		haltScheduling = nil
+		g.Expect(first.Status.VolumeSnapshotRetries).To(Equal(int32(i)))
+		helper.hasCondition(first.Namespace, first.Name, v1alpha1.BackupRetryFailed, "GetMinResolvedTSFailed")
+	}
+	err = bm.syncBackupJob(first)
+	g.Expect(err).Should(BeNil())
+	g.Expect(haltScheduling).To(Equal([]interface{}{true, false}))
+	helper.hasCondition(first.Namespace, first.Name, v1alpha1.BackupFailed, "GetMinResolvedTSFailed")
+
+	// the second backup starts once the first one fails
+	g.Eventually(func() bool {
+		b, err := deps.BackupLister.Backups(first.Namespace).Get(first.Name)
+		return err == nil && v1alpha1.IsBackupFailed(b)
+	}, time.Second*10).Should(BeTrue())
+	haltScheduling = nil
+	err = bm.syncBackupJob(second)
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(controller.IsRequeueError(err)).To(BeFalse())
+	g.Expect(haltScheduling).To(Equal([]interface{}{true, false}))
+}
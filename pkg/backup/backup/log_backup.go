@@ -0,0 +1,133 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// syncLogTruncateJob truncates the log backup with a Job if the logTruncateUntil in spec is beyond the ts
+// that the log backup has been truncated until, the Job of the last truncation is deleted before a new one
+// is created
+func (bm *backupManager) syncLogTruncateJob(backup *v1alpha1.Backup) error {
+	if !backuputil.NeedLogTruncate(backup) {
+		return nil
+	}
+	ns := backup.GetNamespace()
+	name := backup.GetName()
+	jobName := backup.GetLogTruncateJobName()
+
+	job, err := bm.deps.JobLister.Jobs(ns).Get(jobName)
+	if err == nil {
+		if getJobFinishedCondition(job) == "" {
+			// the truncation is running
+			return nil
+		}
+		if err := bm.deps.JobControl.DeleteJob(backup, job); err != nil {
+			return fmt.Errorf("backup %s/%s delete job %s failed, err: %v", ns, name, jobName, err)
+		}
+		return controller.RequeueErrorf("backup %s/%s, the job %s of the last truncation is deleted", ns, name, jobName)
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("backup %s/%s get job %s failed, err: %v", ns, name, jobName, err)
+	}
+
+	jobLabel := label.NewBackup().Instance(backup.GetInstanceName()).LogBackupJob().Backup(name)
+	job, reason, err := bm.makeBRJob(backup, jobName, jobLabel,
+		fmt.Sprintf("--subcommand=%s", constants.LogTruncateSubcommand),
+		fmt.Sprintf("--truncate-until=%s", backup.Spec.LogTruncateUntil))
+	if err != nil {
+		bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:    v1alpha1.BackupRetryFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  reason,
+			Message: err.Error(),
+		}, nil)
+		return err
+	}
+	if err := bm.deps.JobControl.CreateJob(backup, job); err != nil {
+		return fmt.Errorf("create backup %s/%s job %s failed, err: %v", ns, name, jobName, err)
+	}
+	klog.Infof("backup %s/%s, truncate the log backup until %s", ns, name, backup.Spec.LogTruncateUntil)
+	return nil
+}
+
+// syncLogStopJob stops the task of the log backup with a Job, the LogStopped condition is set by the Job
+// after the task is stopped. The task is not started if the Backup has never been Running.
+func (bm *backupManager) syncLogStopJob(backup *v1alpha1.Backup) error {
+	ns := backup.GetNamespace()
+	name := backup.GetName()
+	jobName := backup.GetLogStopJobName()
+
+	if backup.Status.TimeStarted.IsZero() {
+		return bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:   v1alpha1.BackupLogStopped,
+			Status: corev1.ConditionTrue,
+			Reason: "LogBackupNotStarted",
+		}, nil)
+	}
+
+	job, err := bm.deps.JobLister.Jobs(ns).Get(jobName)
+	if err == nil {
+		if getJobFinishedCondition(job) != batchv1.JobFailed {
+			// the task is being stopped, or has been stopped but the condition is not observed yet
+			return nil
+		}
+		// retry to stop the task
+		if err := bm.deps.JobControl.DeleteJob(backup, job); err != nil {
+			return fmt.Errorf("backup %s/%s delete job %s failed, err: %v", ns, name, jobName, err)
+		}
+		return controller.RequeueErrorf("backup %s/%s, the job %s failed to stop the log backup", ns, name, jobName)
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("backup %s/%s get job %s failed, err: %v", ns, name, jobName, err)
+	}
+
+	jobLabel := label.NewBackup().Instance(backup.GetInstanceName()).LogBackupJob().Backup(name)
+	job, reason, err := bm.makeBRJob(backup, jobName, jobLabel, fmt.Sprintf("--subcommand=%s", constants.LogStopSubcommand))
+	if err != nil {
+		bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:    v1alpha1.BackupRetryFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  reason,
+			Message: err.Error(),
+		}, nil)
+		return err
+	}
+	if err := bm.deps.JobControl.CreateJob(backup, job); err != nil {
+		return fmt.Errorf("create backup %s/%s job %s failed, err: %v", ns, name, jobName, err)
+	}
+	klog.Infof("backup %s/%s, stop the log backup task", ns, name)
+	return nil
+}
+
+// getJobFinishedCondition returns JobComplete or JobFailed if the Job is finished, otherwise returns empty
+func getJobFinishedCondition(job *batchv1.Job) batchv1.JobConditionType {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return c.Type
+		}
+	}
+	return ""
+}
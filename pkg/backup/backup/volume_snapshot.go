@@ -0,0 +1,321 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// maxVolumeSnapshotRetries is the maximum number of the retries of the failed attempts to cut the volume snapshots
+const maxVolumeSnapshotRetries = 5
+
+// syncVolumeSnapshotBackup backs up the cluster by the CSI VolumeSnapshots of the PVCs of PD and TiKV.
+// The snapshots of the volumes are cut at different points in time, so they are aligned to a consistency fence:
+// the PD scheduling is halted so that no region is moved between the stores while the snapshots are cut, and
+// the min resolved ts of the cluster is recorded before cutting them. The transactions committed before the
+// resolved ts are resolved and persisted by a majority of the peers of each region, so they are kept in the
+// snapshots, while the data written after it may be partially kept, and it's removed by the restore, which resets
+// the data of TiKV to the resolved ts. The scheduling is resumed once all the snapshots are cut, without waiting
+// for them to be ready to use, which may take long to upload the data.
+// The meta of the snapshots is recorded in a ConfigMap after all the snapshots are created, so the
+// snapshots are created only once even if the sync is retried.
+// The snapshot backups of a cluster are taken one by one, as they halt and resume the scheduling of the same PD.
+func (bm *backupManager) syncVolumeSnapshotBackup(backup *v1alpha1.Backup, tc *v1alpha1.TidbCluster) error {
+	if v1alpha1.IsBackupComplete(backup) || v1alpha1.IsBackupFailed(backup) {
+		return nil
+	}
+	ns := backup.GetNamespace()
+	name := backup.GetName()
+	pdClient := controller.GetPDClient(bm.deps.PDControl, tc)
+
+	cm, err := bm.deps.UserConfigMapLister.ConfigMaps(ns).Get(backup.GetVolumeSnapshotMetaName())
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("backup %s/%s get configmap %s failed, err: %v", ns, name, backup.GetVolumeSnapshotMetaName(), err)
+	}
+	var meta *backuputil.VolumeSnapshotMeta
+	if errors.IsNotFound(err) {
+		prior, err := bm.priorVolumeSnapshotBackup(backup)
+		if err != nil {
+			return err
+		}
+		if prior != nil {
+			return controller.RequeueErrorf("backup %s/%s waits for the volume snapshot backup %s/%s of tidbcluster %s/%s",
+				ns, name, prior.GetNamespace(), prior.GetName(), tc.GetNamespace(), tc.GetName())
+		}
+		if backup.Status.TimeStarted.IsZero() {
+			now := metav1.Now()
+			if err := bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:   v1alpha1.BackupRunning,
+				Status: corev1.ConditionTrue,
+			}, &controller.BackupUpdateStatus{TimeStarted: &now}); err != nil {
+				return err
+			}
+		}
+		if err := pdClient.UpdateScheduleConfig(map[string]interface{}{"halt-scheduling": true}); err != nil {
+			return fmt.Errorf("backup %s/%s failed to halt the scheduling of pd, error: %v", ns, name, err)
+		}
+		klog.Infof("backup %s/%s halted the scheduling of pd of tidbcluster %s/%s", ns, name, tc.GetNamespace(), tc.GetName())
+
+		resolvedTS, err := pdClient.GetMinResolvedTS()
+		if err == nil && resolvedTS.MinResolvedTS == 0 {
+			err = fmt.Errorf("the min resolved ts of tidbcluster %s/%s is not available", tc.GetNamespace(), tc.GetName())
+		}
+		if err != nil {
+			return bm.retryVolumeSnapshotBackup(backup, tc, "GetMinResolvedTSFailed",
+				fmt.Errorf("backup %s/%s failed to get the min resolved ts, error: %v", ns, name, err))
+		}
+
+		meta, err = bm.createVolumeSnapshots(backup, tc, resolvedTS.MinResolvedTS)
+		if err != nil {
+			return bm.retryVolumeSnapshotBackup(backup, tc, "CreateVolumeSnapshotFailed", err)
+		}
+	} else {
+		meta, err = backuputil.DecodeVolumeSnapshotMeta(cm)
+		if err != nil {
+			return err
+		}
+	}
+
+	var size int64
+	var notCut, notReady []string
+	for _, vol := range meta.Volumes {
+		state, err := bm.deps.VolumeSnapshotControl.GetVolumeSnapshot(meta.ClusterNamespace, vol.SnapshotName)
+		if err != nil {
+			return fmt.Errorf("backup %s/%s get volume snapshot %s/%s failed, err: %v", ns, name, meta.ClusterNamespace, vol.SnapshotName, err)
+		}
+		if state.Error != "" {
+			if err := bm.resumeScheduling(backup, tc); err != nil {
+				return err
+			}
+			return bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:    v1alpha1.BackupFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "VolumeSnapshotFailed",
+				Message: fmt.Sprintf("volume snapshot %s/%s of pvc %s failed: %s", meta.ClusterNamespace, vol.SnapshotName, vol.PVCName, state.Error),
+			}, nil)
+		}
+		if state.CreationTime == "" && !state.ReadyToUse {
+			notCut = append(notCut, vol.SnapshotName)
+		}
+		if !state.ReadyToUse {
+			notReady = append(notReady, vol.SnapshotName)
+		}
+		if q, err := resource.ParseQuantity(state.RestoreSize); err == nil {
+			size += q.Value()
+		}
+	}
+	if len(notCut) > 0 {
+		return controller.RequeueErrorf("backup %s/%s, volume snapshots %v in %s are not cut", ns, name, notCut, meta.ClusterNamespace)
+	}
+
+	// the scheduling doesn't affect the snapshots once they are cut
+	if err := bm.resumeScheduling(backup, tc); err != nil {
+		return err
+	}
+	if len(notReady) > 0 {
+		return controller.RequeueErrorf("backup %s/%s, volume snapshots %v in %s are not ready", ns, name, notReady, meta.ClusterNamespace)
+	}
+
+	now := metav1.Now()
+	sizeReadable := humanize.Bytes(uint64(size))
+	updateStatus := &controller.BackupUpdateStatus{
+		TimeCompleted:      &now,
+		BackupSize:         &size,
+		BackupSizeReadable: &sizeReadable,
+	}
+	if meta.ResolvedTS > 0 {
+		commitTs := strconv.FormatUint(meta.ResolvedTS, 10)
+		updateStatus.CommitTs = &commitTs
+	}
+	return bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:   v1alpha1.BackupComplete,
+		Status: corev1.ConditionTrue,
+	}, updateStatus)
+}
+
+// retryVolumeSnapshotBackup resumes the scheduling halted by the backup which failed to cut the volume snapshots,
+// so that the scheduling isn't halted indefinitely by a persistent failure, e.g. the VolumeSnapshot CRD or
+// the snapshot class is missing, and the backup is retried until it fails more than maxVolumeSnapshotRetries times.
+func (bm *backupManager) retryVolumeSnapshotBackup(backup *v1alpha1.Backup, tc *v1alpha1.TidbCluster, reason string, err error) error {
+	if err := bm.resumeScheduling(backup, tc); err != nil {
+		return err
+	}
+	retries := backup.Status.VolumeSnapshotRetries + 1
+	if retries > maxVolumeSnapshotRetries {
+		klog.Errorf("backup %s/%s failed to cut the volume snapshots after %d retries, error: %v", backup.GetNamespace(), backup.GetName(), maxVolumeSnapshotRetries, err)
+		return bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:    v1alpha1.BackupFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  reason,
+			Message: err.Error(),
+		}, nil)
+	}
+	bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:    v1alpha1.BackupRetryFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: err.Error(),
+	}, &controller.BackupUpdateStatus{VolumeSnapshotRetries: &retries})
+	return err
+}
+
+// priorVolumeSnapshotBackup returns the unfinished volume snapshot backup of the same cluster created before
+// the backup, which must be finished before the backup starts, otherwise the scheduling resumed by one of them
+// may be still required by the other.
+func (bm *backupManager) priorVolumeSnapshotBackup(backup *v1alpha1.Backup) (*v1alpha1.Backup, error) {
+	backups, err := bm.deps.BackupLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("backup %s/%s failed to list the backups, error: %v", backup.GetNamespace(), backup.GetName(), err)
+	}
+	clusterNamespace, clusterName := volumeSnapshotBackupCluster(backup)
+	for _, b := range backups {
+		if (b.GetNamespace() == backup.GetNamespace() && b.GetName() == backup.GetName()) || !b.IsVolumeSnapshotBackup() || b.Spec.BR == nil ||
+			v1alpha1.IsBackupComplete(b) || v1alpha1.IsBackupFailed(b) || v1alpha1.IsBackupInvalid(b) {
+			continue
+		}
+		if ns, name := volumeSnapshotBackupCluster(b); ns != clusterNamespace || name != clusterName {
+			continue
+		}
+		if b.CreationTimestamp.Before(&backup.CreationTimestamp) ||
+			(b.CreationTimestamp.Equal(&backup.CreationTimestamp) && b.GetNamespace()+"/"+b.GetName() < backup.GetNamespace()+"/"+backup.GetName()) {
+			return b, nil
+		}
+	}
+	return nil, nil
+}
+
+// volumeSnapshotBackupCluster returns the namespace and the name of the cluster backed up by the backup
+func volumeSnapshotBackupCluster(backup *v1alpha1.Backup) (string, string) {
+	clusterNamespace := backup.GetNamespace()
+	if backup.Spec.BR.ClusterNamespace != "" {
+		clusterNamespace = backup.Spec.BR.ClusterNamespace
+	}
+	return clusterNamespace, backup.Spec.BR.Cluster
+}
+
+// createVolumeSnapshots creates the snapshots of the PVCs of PD and TiKV and records them in the meta ConfigMap
+// with the resolved ts the data is reset to after restored
+func (bm *backupManager) createVolumeSnapshots(backup *v1alpha1.Backup, tc *v1alpha1.TidbCluster, resolvedTS uint64) (*backuputil.VolumeSnapshotMeta, error) {
+	ns := backup.GetNamespace()
+	name := backup.GetName()
+	meta := &backuputil.VolumeSnapshotMeta{
+		ClusterName:      tc.GetName(),
+		ClusterNamespace: tc.GetNamespace(),
+		Version:          tc.TiKVVersion(),
+		ResolvedTS:       resolvedTS,
+	}
+	for _, component := range []label.Label{label.New().Instance(tc.GetInstanceName()).PD(), label.New().Instance(tc.GetInstanceName()).TiKV()} {
+		selector, err := component.Selector()
+		if err != nil {
+			return nil, err
+		}
+		pvcs, err := bm.deps.PVCLister.PersistentVolumeClaims(tc.GetNamespace()).List(selector)
+		if err != nil {
+			return nil, fmt.Errorf("backup %s/%s list pvcs of tidbcluster %s/%s failed, err: %v", ns, name, tc.GetNamespace(), tc.GetName(), err)
+		}
+		sort.Slice(pvcs, func(i, j int) bool { return pvcs[i].Name < pvcs[j].Name })
+		for _, pvc := range pvcs {
+			if pvc.DeletionTimestamp != nil {
+				continue
+			}
+			snapshotName := backup.GetVolumeSnapshotName(pvc.Name)
+			snapshotLabels := label.NewBackup().Instance(backup.GetInstanceName()).Backup(name).Labels()
+			err := bm.deps.VolumeSnapshotControl.CreateVolumeSnapshot(backup, tc.GetNamespace(), snapshotName, pvc.Name, backup.Spec.VolumeSnapshotClassName, snapshotLabels)
+			if err != nil && !errors.IsAlreadyExists(err) {
+				return nil, fmt.Errorf("backup %s/%s create volume snapshot of pvc %s/%s failed, err: %v", ns, name, pvc.Namespace, pvc.Name, err)
+			}
+			storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			meta.Volumes = append(meta.Volumes, backuputil.VolumeSnapshotVolume{
+				Component:        component[label.ComponentLabelKey],
+				PodName:          pvc.Annotations[label.AnnPodNameKey],
+				PVCName:          pvc.Name,
+				SnapshotName:     snapshotName,
+				StorageClassName: pvc.Spec.StorageClassName,
+				AccessModes:      pvc.Spec.AccessModes,
+				Storage:          storage.String(),
+				Labels:           pvc.Labels,
+			})
+		}
+	}
+	if len(meta.Volumes) == 0 {
+		return nil, fmt.Errorf("backup %s/%s, no pvc of pd or tikv is found in tidbcluster %s/%s", ns, name, tc.GetNamespace(), tc.GetName())
+	}
+
+	data, err := backuputil.EncodeVolumeSnapshotMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            backup.GetVolumeSnapshotMetaName(),
+			Namespace:       ns,
+			Labels:          label.NewBackup().Instance(backup.GetInstanceName()).Backup(name).Labels(),
+			OwnerReferences: []metav1.OwnerReference{controller.GetBackupOwnerRef(backup)},
+		},
+		Data: data,
+	}
+	if _, err := bm.deps.ConfigMapControl.CreateConfigMap(backup, cm); err != nil {
+		return nil, fmt.Errorf("backup %s/%s create configmap %s failed, err: %v", ns, name, cm.Name, err)
+	}
+	klog.Infof("backup %s/%s created %d volume snapshots of tidbcluster %s/%s", ns, name, len(meta.Volumes), tc.GetNamespace(), tc.GetName())
+	return meta, nil
+}
+
+// abortVolumeSnapshotBackup resumes the PD scheduling halted by the running backup which is being deleted,
+// and marks the backup failed
+func (bm *backupManager) abortVolumeSnapshotBackup(backup *v1alpha1.Backup) error {
+	clusterNamespace, clusterName := volumeSnapshotBackupCluster(backup)
+	tc, err := bm.deps.TiDBClusterLister.TidbClusters(clusterNamespace).Get(clusterName)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if err := bm.resumeScheduling(backup, tc); err != nil {
+			return err
+		}
+	}
+	return bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+		Type:    v1alpha1.BackupFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  "BackupDeleted",
+		Message: "the backup is deleted before all the volume snapshots are ready",
+	}, nil)
+}
+
+// resumeScheduling resumes the PD scheduling halted by the backup, unless it's halted by the read-only mode of the cluster
+func (bm *backupManager) resumeScheduling(backup *v1alpha1.Backup, tc *v1alpha1.TidbCluster) error {
+	if tc.Status.ReadOnly != nil && tc.Status.ReadOnly.SchedulingHalted {
+		return nil
+	}
+	pdClient := controller.GetPDClient(bm.deps.PDControl, tc)
+	if err := pdClient.UpdateScheduleConfig(map[string]interface{}{"halt-scheduling": false}); err != nil {
+		return fmt.Errorf("backup %s/%s failed to resume the scheduling of pd, error: %v", backup.GetNamespace(), backup.GetName(), err)
+	}
+	klog.Infof("backup %s/%s resumed the scheduling of pd of tidbcluster %s/%s", backup.GetNamespace(), backup.GetName(), tc.GetNamespace(), tc.GetName())
+	return nil
+}
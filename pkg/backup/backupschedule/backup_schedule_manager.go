@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,10 +25,13 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup"
 	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	"github.com/robfig/cron"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
@@ -50,10 +54,17 @@ func NewBackupScheduleManager(deps *controller.Dependencies) backup.BackupSchedu
 func (bm *backupScheduleManager) Sync(bs *v1alpha1.BackupSchedule) error {
 	defer bm.backupGC(bs)
 
+	// the statistics are tracked for the paused backup schedules too, so that the RPO violation is alerted
+	bm.syncBackupStatistics(bs)
+
 	if bs.Spec.Pause {
 		return controller.IgnoreErrorf("backupSchedule %s/%s has been paused", bs.GetNamespace(), bs.GetName())
 	}
 
+	if err := bm.syncLogBackup(bs); err != nil {
+		return err
+	}
+
 	if err := bm.canPerformNextBackup(bs); err != nil {
 		return err
 	}
@@ -68,7 +79,7 @@ func (bm *backupScheduleManager) Sync(bs *v1alpha1.BackupSchedule) error {
 		return nil
 	}
 
-	backup, err := createBackup(bm.deps.BackupControl, bs, *scheduledTime)
+	backup, err := createBackup(bm.deps.BackupControl, bs, *scheduledTime, bm.pdClientPort(bs))
 	if err != nil {
 		return err
 	}
@@ -79,6 +90,120 @@ func (bm *backupScheduleManager) Sync(bs *v1alpha1.BackupSchedule) error {
 	return nil
 }
 
+// syncBackupStatistics records the latest successful backup and the number of the backups failed since it in status,
+// exports them as metrics and sets the BackupFresh condition if the RPO is specified
+func (bm *backupScheduleManager) syncBackupStatistics(bs *v1alpha1.BackupSchedule) {
+	ns := bs.GetNamespace()
+	bsName := bs.GetName()
+
+	backups, err := bm.getBackupList(bs)
+	if err != nil {
+		klog.Errorf("syncBackupStatistics failed, err: %s", err)
+		return
+	}
+	sort.Sort(byCreateTimeDesc(backups))
+
+	var lastSuccess *v1alpha1.Backup
+	var failures int32
+	for _, backup := range backups {
+		if v1alpha1.IsBackupComplete(backup) {
+			lastSuccess = backup
+			break
+		}
+		if v1alpha1.IsBackupFailed(backup) {
+			failures++
+		}
+	}
+	bs.Status.ConsecutiveFailures = failures
+
+	if lastSuccess != nil && lastSuccess.Name != bs.Status.LastSuccessfulBackup {
+		completed := lastSuccess.Status.TimeCompleted
+		if completed.IsZero() {
+			completed = lastSuccess.CreationTimestamp
+		}
+		// the status is kept if the latest successful backup has been garbage collected
+		if bs.Status.LastSuccessfulBackupTime == nil || bs.Status.LastSuccessfulBackupTime.Before(&completed) {
+			bs.Status.LastSuccessfulBackup = lastSuccess.Name
+			bs.Status.LastSuccessfulBackupTime = completed.DeepCopy()
+			bs.Status.LastSuccessfulBackupDuration = nil
+			if started := lastSuccess.Status.TimeStarted; !started.IsZero() && !lastSuccess.Status.TimeCompleted.IsZero() {
+				bs.Status.LastSuccessfulBackupDuration = &metav1.Duration{Duration: lastSuccess.Status.TimeCompleted.Sub(started.Time)}
+			}
+			bs.Status.LastSuccessfulBackupSize = lastSuccess.Status.BackupSize
+		}
+	}
+
+	metrics.BackupScheduleConsecutiveFailures.WithLabelValues(ns, bsName).Set(float64(failures))
+	if bs.Status.LastSuccessfulBackupTime != nil {
+		metrics.BackupScheduleLastSuccessTimestamp.WithLabelValues(ns, bsName).Set(float64(bs.Status.LastSuccessfulBackupTime.Unix()))
+		metrics.BackupScheduleLastSuccessSize.WithLabelValues(ns, bsName).Set(float64(bs.Status.LastSuccessfulBackupSize))
+	}
+	if bs.Status.LastSuccessfulBackupDuration != nil {
+		metrics.BackupScheduleLastSuccessDuration.WithLabelValues(ns, bsName).Set(bs.Status.LastSuccessfulBackupDuration.Seconds())
+	}
+
+	if bs.Spec.RPO == nil {
+		if meta.FindStatusCondition(bs.Status.Conditions, v1alpha1.BackupScheduleBackupFresh) != nil {
+			meta.RemoveStatusCondition(&bs.Status.Conditions, v1alpha1.BackupScheduleBackupFresh)
+		}
+		metrics.BackupScheduleFresh.DeleteLabelValues(ns, bsName)
+		return
+	}
+	// a new backup schedule is fresh until the RPO elapses
+	since := bs.CreationTimestamp.Time
+	if bs.Status.LastSuccessfulBackupTime != nil {
+		since = bs.Status.LastSuccessfulBackupTime.Time
+	}
+	rpo := bs.Spec.RPO.Duration
+	if bm.now().Sub(since) > rpo {
+		msg := fmt.Sprintf("no backup completed successfully since %s, exceeding the rpo %s", since.Format(time.RFC3339), rpo)
+		if !meta.IsStatusConditionFalse(bs.Status.Conditions, v1alpha1.BackupScheduleBackupFresh) {
+			klog.Warningf("backup schedule %s/%s, %s", ns, bsName, msg)
+		}
+		meta.SetStatusCondition(&bs.Status.Conditions, metav1.Condition{
+			Type:    v1alpha1.BackupScheduleBackupFresh,
+			Status:  metav1.ConditionFalse,
+			Reason:  "RPOExceeded",
+			Message: msg,
+		})
+		metrics.BackupScheduleFresh.WithLabelValues(ns, bsName).Set(0)
+		return
+	}
+	meta.SetStatusCondition(&bs.Status.Conditions, metav1.Condition{
+		Type:   v1alpha1.BackupScheduleBackupFresh,
+		Status: metav1.ConditionTrue,
+		Reason: "WithinRPO",
+	})
+	metrics.BackupScheduleFresh.WithLabelValues(ns, bsName).Set(1)
+}
+
+// syncLogBackup creates the log backup from the logBackupTemplate if it doesn't exist, the log backup keeps
+// running along with the scheduled backups and is deleted with the BackupSchedule
+func (bm *backupScheduleManager) syncLogBackup(bs *v1alpha1.BackupSchedule) error {
+	if bs.Spec.LogBackupTemplate == nil {
+		return nil
+	}
+	ns := bs.GetNamespace()
+	bsName := bs.GetName()
+	logBackupName := bs.GetLogBackupCRDName()
+
+	_, err := bm.deps.BackupLister.Backups(ns).Get(logBackupName)
+	if err == nil {
+		bs.Status.LogBackup = logBackupName
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("backup schedule %s/%s, get log backup %s failed, err: %v", ns, bsName, logBackupName, err)
+	}
+
+	logBackup, err := bm.deps.BackupControl.CreateBackup(buildLogBackup(bs))
+	if err != nil {
+		return err
+	}
+	bs.Status.LogBackup = logBackup.GetName()
+	return nil
+}
+
 func (bm *backupScheduleManager) deleteLastBackupJob(bs *v1alpha1.BackupSchedule) error {
 	ns := bs.GetNamespace()
 	bsName := bs.GetName()
@@ -191,7 +316,7 @@ func getLastScheduledTime(bs *v1alpha1.BackupSchedule, nowFn nowFn) (*time.Time,
 	return &scheduledTime, nil
 }
 
-func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time) *v1alpha1.Backup {
+func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time, pdClientPort int32) *v1alpha1.Backup {
 	ns := bs.GetNamespace()
 	bsName := bs.GetName()
 
@@ -214,13 +339,15 @@ func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time) *v1alpha1.Bac
 		if backupSpec.BR.ClusterNamespace == "" {
 			clusterNamespace = ns
 		}
-		pdAddress = fmt.Sprintf("%s-pd.%s:2379", backupSpec.BR.Cluster, clusterNamespace)
+		pdAddress = fmt.Sprintf("%s-pd.%s:%d", backupSpec.BR.Cluster, clusterNamespace, pdClientPort)
 
 		backupPrefix := strings.ReplaceAll(pdAddress, ":", "-") + "-" + timestamp.UTC().Format(v1alpha1.BackupNameTimeFormat)
 		if backupSpec.S3 != nil {
 			backupSpec.S3.Prefix = path.Join(backupSpec.S3.Prefix, backupPrefix)
 		} else if backupSpec.Gcs != nil {
 			backupSpec.Gcs.Prefix = path.Join(backupSpec.Gcs.Prefix, backupPrefix)
+		} else if backupSpec.Azblob != nil {
+			backupSpec.Azblob.Prefix = path.Join(backupSpec.Azblob.Prefix, backupPrefix)
 		} else if backupSpec.Local != nil {
 			backupSpec.Local.Prefix = path.Join(backupSpec.Local.Prefix, backupPrefix)
 		}
@@ -247,11 +374,56 @@ func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time) *v1alpha1.Bac
 	return backup
 }
 
-func createBackup(bkController controller.BackupControlInterface, bs *v1alpha1.BackupSchedule, timestamp time.Time) (*v1alpha1.Backup, error) {
-	bk := buildBackup(bs, timestamp)
+func buildLogBackup(bs *v1alpha1.BackupSchedule) *v1alpha1.Backup {
+	ns := bs.GetNamespace()
+	bsName := bs.GetName()
+
+	backupSpec := *bs.Spec.LogBackupTemplate.DeepCopy()
+	backupSpec.Mode = v1alpha1.BackupModeLog
+	if bs.Spec.ImagePullSecrets != nil {
+		backupSpec.ImagePullSecrets = bs.Spec.ImagePullSecrets
+	}
+
+	bsLabel := util.CombineStringMap(label.NewBackupSchedule().Instance(bsName).BackupSchedule(bsName), bs.Labels)
+	return &v1alpha1.Backup{
+		Spec: backupSpec,
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   ns,
+			Name:        bs.GetLogBackupCRDName(),
+			Labels:      bsLabel,
+			Annotations: bs.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				controller.GetBackupScheduleOwnerRef(bs),
+			},
+		},
+	}
+}
+
+func createBackup(bkController controller.BackupControlInterface, bs *v1alpha1.BackupSchedule, timestamp time.Time, pdClientPort int32) (*v1alpha1.Backup, error) {
+	bk := buildBackup(bs, timestamp, pdClientPort)
 	return bkController.CreateBackup(bk)
 }
 
+// pdClientPort returns the PD client port of the cluster backed up by the BR backups of the schedule,
+// the default port is returned if the cluster can't be found
+func (bm *backupScheduleManager) pdClientPort(bs *v1alpha1.BackupSchedule) int32 {
+	br := bs.Spec.BackupTemplate.BR
+	if br == nil {
+		return v1alpha1.DefaultPDClientPort
+	}
+	ns := br.ClusterNamespace
+	if ns == "" {
+		ns = bs.GetNamespace()
+	}
+	tc, err := bm.deps.TiDBClusterLister.TidbClusters(ns).Get(br.Cluster)
+	if err != nil {
+		klog.Warningf("backup schedule %s/%s, get tidbcluster %s/%s failed, use the default PD client port, err: %v",
+			bs.GetNamespace(), bs.GetName(), ns, br.Cluster, err)
+		return v1alpha1.DefaultPDClientPort
+	}
+	return tc.PDClientPort()
+}
+
 func (bm *backupScheduleManager) backupGC(bs *v1alpha1.BackupSchedule) {
 	ns := bs.GetNamespace()
 	bsName := bs.GetName()
@@ -286,11 +458,21 @@ func (bm *backupScheduleManager) backupGCByMaxReservedTime(bs *v1alpha1.BackupSc
 		return
 	}
 
+	// the backups that are the base of the retained window of the log backup are never deleted
+	logBackup := bm.getLogBackup(bs)
+	var base *v1alpha1.Backup
+	if logBackup != nil {
+		base = getLogBackupBase(backupsList, backuputil.TimeToTSO(bm.now().Add(-reservedTime)))
+	}
+
 	var deleteCount int
 	for _, backup := range backupsList {
 		if backup.CreationTimestamp.Add(reservedTime).After(bm.now()) {
 			continue
 		}
+		if base != nil && !backup.CreationTimestamp.Before(&base.CreationTimestamp) {
+			continue
+		}
 		// delete the expired backup
 		if err := bm.deps.BackupControl.DeleteBackup(backup); err != nil {
 			klog.Errorf("backup schedule %s/%s gc backup %s failed, err %v", ns, bsName, backup.GetName(), err)
@@ -304,6 +486,7 @@ func (bm *backupScheduleManager) backupGCByMaxReservedTime(bs *v1alpha1.BackupSc
 		// All backups have been deleted, so the last backup information in the backupSchedule should be reset
 		bm.resetLastBackup(bs)
 	}
+	bm.truncateLogBackup(bs, logBackup, base)
 }
 
 func (bm *backupScheduleManager) backupGCByMaxBackups(bs *v1alpha1.BackupSchedule) {
@@ -318,9 +501,24 @@ func (bm *backupScheduleManager) backupGCByMaxBackups(bs *v1alpha1.BackupSchedul
 
 	sort.Sort(byCreateTimeDesc(backupsList))
 
+	// the oldest complete backup of the reserved backups is the base of the retained window of the log backup,
+	// the latest complete backup is reserved as the base if none of the reserved backups is complete
+	logBackup := bm.getLogBackup(bs)
+	var base *v1alpha1.Backup
+	if logBackup != nil {
+		for i, backup := range backupsList {
+			if !v1alpha1.IsBackupComplete(backup) {
+				continue
+			}
+			if base == nil || i < int(*bs.Spec.MaxBackups) {
+				base = backup
+			}
+		}
+	}
+
 	var deleteCount int
 	for i, backup := range backupsList {
-		if i < int(*bs.Spec.MaxBackups) {
+		if i < int(*bs.Spec.MaxBackups) || backup == base {
 			continue
 		}
 		// delete the backup
@@ -336,6 +534,64 @@ func (bm *backupScheduleManager) backupGCByMaxBackups(bs *v1alpha1.BackupSchedul
 		// All backups have been deleted, so the last backup information in the backupSchedule should be reset
 		bm.resetLastBackup(bs)
 	}
+	bm.truncateLogBackup(bs, logBackup, base)
+}
+
+// getLogBackup returns the log backup of the BackupSchedule, nil is returned if there is no log backup
+func (bm *backupScheduleManager) getLogBackup(bs *v1alpha1.BackupSchedule) *v1alpha1.Backup {
+	if bs.Status.LogBackup == "" {
+		return nil
+	}
+	logBackup, err := bm.deps.BackupLister.Backups(bs.GetNamespace()).Get(bs.Status.LogBackup)
+	if err != nil {
+		return nil
+	}
+	return logBackup
+}
+
+// getLogBackupBase returns the latest complete backup whose commit ts is not after the ts, the cluster can be
+// restored to any point in time after the ts with the backup and the log backup
+func getLogBackupBase(backups []*v1alpha1.Backup, ts uint64) *v1alpha1.Backup {
+	var base *v1alpha1.Backup
+	var baseTS uint64
+	for _, backup := range backups {
+		if !v1alpha1.IsBackupComplete(backup) {
+			continue
+		}
+		commitTS, err := strconv.ParseUint(backup.Status.CommitTs, 10, 64)
+		if err != nil || commitTS > ts {
+			continue
+		}
+		if base == nil || commitTS > baseTS {
+			base, baseTS = backup, commitTS
+		}
+	}
+	return base
+}
+
+// truncateLogBackup truncates the log backup until the commit ts of the base backup, as the log before it
+// can't be restored without the deleted backups
+func (bm *backupScheduleManager) truncateLogBackup(bs *v1alpha1.BackupSchedule, logBackup, base *v1alpha1.Backup) {
+	if logBackup == nil || base == nil || logBackup.DeletionTimestamp != nil {
+		return
+	}
+	ns := bs.GetNamespace()
+	bsName := bs.GetName()
+
+	commitTS, err := strconv.ParseUint(base.Status.CommitTs, 10, 64)
+	if err != nil {
+		return
+	}
+	if logBackup.Spec.LogTruncateUntil != "" {
+		if until, err := backuputil.ParseTSString(logBackup.Spec.LogTruncateUntil); err == nil && until >= commitTS {
+			return
+		}
+	}
+	if err := bm.deps.BackupControl.TruncateLogBackup(logBackup, commitTS); err != nil {
+		klog.Errorf("backup schedule %s/%s truncate log backup %s failed, err %v", ns, bsName, logBackup.GetName(), err)
+		return
+	}
+	klog.Infof("backup schedule %s/%s truncate log backup %s until %d, the commit ts of backup %s", ns, bsName, logBackup.GetName(), commitTS, base.GetName())
 }
 
 func (bm *backupScheduleManager) resetLastBackup(bs *v1alpha1.BackupSchedule) {
@@ -358,7 +614,14 @@ func (bm *backupScheduleManager) getBackupList(bs *v1alpha1.BackupSchedule) ([]*
 		return nil, fmt.Errorf("get backup schedule %s/%s backup list failed, selector: %s, err: %v", ns, bsName, selector, err)
 	}
 
-	return backupsList, nil
+	// the log backup is never garbage collected
+	var backups []*v1alpha1.Backup
+	for _, backup := range backupsList {
+		if !backup.IsLogBackup() {
+			backups = append(backups, backup)
+		}
+	}
+	return backups, nil
 }
 
 type byCreateTimeDesc []*v1alpha1.Backup
@@ -16,6 +16,7 @@ package backupschedule
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -24,8 +25,12 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/utils/pointer"
@@ -198,14 +203,14 @@ func TestBuildBackup(t *testing.T) {
 	}
 
 	// test BR == nil
-	get = buildBackup(bs, now)
+	get = buildBackup(bs, now, v1alpha1.DefaultPDClientPort)
 	if diff := cmp.Diff(bk, get); diff != "" {
 		t.Errorf("unexpected (-want, +got): %s", diff)
 	}
 	// should keep StorageSize from BackupSchedule
 	bs.Spec.StorageSize = "9527G"
 	bk.Spec.StorageSize = bs.Spec.StorageSize
-	get = buildBackup(bs, now)
+	get = buildBackup(bs, now, v1alpha1.DefaultPDClientPort)
 	if diff := cmp.Diff(bk, get); diff != "" {
 		t.Errorf("unexpected (-want, +got): %s", diff)
 	}
@@ -214,10 +219,152 @@ func TestBuildBackup(t *testing.T) {
 	bs.Spec.BackupTemplate.BR = &v1alpha1.BRConfig{}
 	bk.Spec.BR = bs.Spec.BackupTemplate.BR.DeepCopy()
 	bk.Spec.StorageSize = "" // no use for BR
-	get = buildBackup(bs, now)
+	get = buildBackup(bs, now, v1alpha1.DefaultPDClientPort)
 	if diff := cmp.Diff(bk, get); diff != "" {
 		t.Errorf("unexpected (-want, +got): %s", diff)
 	}
+
+	// the backup prefix should use the PD client port of the cluster
+	bs.Spec.BackupTemplate.BR = &v1alpha1.BRConfig{Cluster: "demo"}
+	bs.Spec.BackupTemplate.S3 = &v1alpha1.S3StorageProvider{Prefix: "prefix"}
+	get = buildBackup(bs, now, 12379)
+	expectedPrefix := "prefix/demo-pd." + bs.Namespace + "-12379-" + now.UTC().Format(v1alpha1.BackupNameTimeFormat)
+	if get.Spec.S3.Prefix != expectedPrefix {
+		t.Errorf("unexpected backup prefix, want %s, got %s", expectedPrefix, get.Spec.S3.Prefix)
+	}
+}
+
+func TestLogBackupRetention(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.close()
+	deps := helper.deps
+	m := NewBackupScheduleManager(deps).(*backupScheduleManager)
+	bs := &v1alpha1.BackupSchedule{}
+	bs.Namespace = "ns"
+	bs.Name = "bsname"
+	bs.Spec.LogBackupTemplate = &v1alpha1.BackupSpec{BR: &v1alpha1.BRConfig{Cluster: "cluster"}}
+
+	// the log backup is created from the template
+	g.Expect(m.syncLogBackup(bs)).Should(Succeed())
+	g.Expect(bs.Status.LogBackup).Should(Equal(bs.GetLogBackupCRDName()))
+	g.Eventually(func() error {
+		_, err := deps.BackupLister.Backups(bs.Namespace).Get(bs.Status.LogBackup)
+		return err
+	}, time.Second*10).Should(BeNil())
+	logBackup, err := deps.BackupLister.Backups(bs.Namespace).Get(bs.Status.LogBackup)
+	g.Expect(err).Should(BeNil())
+	g.Expect(logBackup.Spec.Mode).Should(Equal(v1alpha1.BackupModeLog))
+
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	newBackup := func(i int, condType v1alpha1.BackupConditionType) *v1alpha1.Backup {
+		created := now.AddDate(0, 0, i)
+		bk := buildBackup(bs, created, v1alpha1.DefaultPDClientPort)
+		bk.CreationTimestamp = metav1.Time{Time: created}
+		bk.Status.CommitTs = strconv.FormatUint(backuputil.TimeToTSO(created), 10)
+		bk.Status.Conditions = []v1alpha1.BackupCondition{{Type: condType, Status: v1.ConditionTrue}}
+		helper.createBackup(bk)
+		return bk
+	}
+	var bks []*v1alpha1.Backup
+	for i := -4; i < 0; i++ {
+		bks = append(bks, newBackup(i, v1alpha1.BackupComplete))
+	}
+	checkTruncateUntil := func(bk *v1alpha1.Backup) {
+		g.Eventually(func() string {
+			logBackup, err := deps.Clientset.PingcapV1alpha1().Backups(bs.Namespace).Get(context.TODO(), bs.Status.LogBackup, metav1.GetOptions{})
+			g.Expect(err).Should(BeNil())
+			return logBackup.Spec.LogTruncateUntil
+		}, time.Second*10).Should(Equal(bk.Status.CommitTs))
+	}
+
+	// the expired backup of -2d is the base of the retained window of the log backup
+	bs.Spec.MaxReservedTime = pointer.StringPtr("36h")
+	m.backupGC(bs)
+	helper.checkBacklist(bs.Namespace, 3)
+	_, err = deps.BackupLister.Backups(bs.Namespace).Get(bks[2].Name)
+	g.Expect(err).Should(BeNil())
+	checkTruncateUntil(bks[2])
+
+	// the latest complete backup is reserved as the base if the reserved backups are all failed
+	newBackup(0, v1alpha1.BackupFailed)
+	bs.Spec.MaxReservedTime = nil
+	bs.Spec.MaxBackups = pointer.Int32Ptr(1)
+	m.backupGC(bs)
+	helper.checkBacklist(bs.Namespace, 3)
+	_, err = deps.BackupLister.Backups(bs.Namespace).Get(bks[3].Name)
+	g.Expect(err).Should(BeNil())
+	checkTruncateUntil(bks[3])
+}
+
+func TestBackupStatistics(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.close()
+	deps := helper.deps
+	m := NewBackupScheduleManager(deps).(*backupScheduleManager)
+	bs := &v1alpha1.BackupSchedule{}
+	bs.Namespace = "ns"
+	bs.Name = "bsname"
+
+	now := time.Now().Truncate(time.Second)
+	m.now = func() time.Time { return now }
+	bs.CreationTimestamp = metav1.Time{Time: now.Add(-2 * time.Hour)}
+	bs.Spec.RPO = &metav1.Duration{Duration: 3 * time.Hour}
+
+	// a new backup schedule is fresh until the rpo elapses
+	m.syncBackupStatistics(bs)
+	g.Expect(bs.Status.LastSuccessfulBackup).Should(BeEmpty())
+	g.Expect(meta.IsStatusConditionTrue(bs.Status.Conditions, v1alpha1.BackupScheduleBackupFresh)).Should(BeTrue())
+	g.Expect(testutil.ToFloat64(metrics.BackupScheduleFresh.WithLabelValues("ns", "bsname"))).Should(Equal(float64(1)))
+
+	newBackup := func(created time.Time, condType v1alpha1.BackupConditionType) *v1alpha1.Backup {
+		bk := buildBackup(bs, created, v1alpha1.DefaultPDClientPort)
+		bk.CreationTimestamp = metav1.Time{Time: created}
+		bk.Status.TimeStarted = metav1.Time{Time: created}
+		bk.Status.TimeCompleted = metav1.Time{Time: created.Add(10 * time.Minute)}
+		bk.Status.BackupSize = 1024
+		bk.Status.Conditions = []v1alpha1.BackupCondition{{Type: condType, Status: v1.ConditionTrue}}
+		helper.createBackup(bk)
+		return bk
+	}
+	success := newBackup(now.Add(-4*time.Hour), v1alpha1.BackupComplete)
+	newBackup(now.Add(-3*time.Hour), v1alpha1.BackupFailed)
+	newBackup(now.Add(-2*time.Hour), v1alpha1.BackupFailed)
+	newBackup(now.Add(-1*time.Hour), v1alpha1.BackupScheduled)
+
+	// the latest successful backup completed more than 3h ago
+	m.syncBackupStatistics(bs)
+	g.Expect(bs.Status.LastSuccessfulBackup).Should(Equal(success.Name))
+	g.Expect(bs.Status.LastSuccessfulBackupTime.Time).Should(Equal(success.Status.TimeCompleted.Time))
+	g.Expect(bs.Status.LastSuccessfulBackupDuration.Duration).Should(Equal(10 * time.Minute))
+	g.Expect(bs.Status.LastSuccessfulBackupSize).Should(Equal(int64(1024)))
+	g.Expect(bs.Status.ConsecutiveFailures).Should(Equal(int32(2)))
+	cond := meta.FindStatusCondition(bs.Status.Conditions, v1alpha1.BackupScheduleBackupFresh)
+	g.Expect(cond.Status).Should(Equal(metav1.ConditionFalse))
+	g.Expect(cond.Reason).Should(Equal("RPOExceeded"))
+	g.Expect(testutil.ToFloat64(metrics.BackupScheduleFresh.WithLabelValues("ns", "bsname"))).Should(Equal(float64(0)))
+	g.Expect(testutil.ToFloat64(metrics.BackupScheduleConsecutiveFailures.WithLabelValues("ns", "bsname"))).Should(Equal(float64(2)))
+	g.Expect(testutil.ToFloat64(metrics.BackupScheduleLastSuccessTimestamp.WithLabelValues("ns", "bsname"))).Should(Equal(float64(success.Status.TimeCompleted.Unix())))
+	g.Expect(testutil.ToFloat64(metrics.BackupScheduleLastSuccessDuration.WithLabelValues("ns", "bsname"))).Should(Equal(float64(600)))
+
+	// a new successful backup resets the failures and the freshness
+	success = newBackup(now.Add(-30*time.Minute), v1alpha1.BackupComplete)
+	m.syncBackupStatistics(bs)
+	g.Expect(bs.Status.LastSuccessfulBackup).Should(Equal(success.Name))
+	g.Expect(bs.Status.ConsecutiveFailures).Should(BeZero())
+	g.Expect(meta.IsStatusConditionTrue(bs.Status.Conditions, v1alpha1.BackupScheduleBackupFresh)).Should(BeTrue())
+
+	// the last successful backup is kept in status after it's garbage collected
+	helper.deleteBackup(success)
+	m.syncBackupStatistics(bs)
+	g.Expect(bs.Status.LastSuccessfulBackup).Should(Equal(success.Name))
+
+	// the condition is removed without rpo
+	bs.Spec.RPO = nil
+	m.syncBackupStatistics(bs)
+	g.Expect(bs.Status.Conditions).Should(BeEmpty())
 }
 
 type helper struct {
@@ -38,6 +38,19 @@ const (
 	// GcsCredentialsKey represents the gcs service account credentials json key in related secret
 	GcsCredentialsKey = "credentials"
 
+	// AzblobAccountName represents the azure storage account name in related secret
+	AzblobAccountName = "account_name"
+
+	// AzblobAccountKey represents the azure storage account key in related secret
+	AzblobAccountKey = "account_key"
+
+	// AzblobSASToken represents the azure storage SAS token in related secret
+	AzblobSASToken = "sas_token"
+
+	// AzblobCredentialsPath is where the secret of the azure blob storage is mounted, so that
+	// the rotated SAS token can be read by the running job
+	AzblobCredentialsPath = "/var/lib/azblob-credentials"
+
 	// BackupManagerEnvVarPrefix represents the environment variable used for tidb-backup-manager must include this prefix
 	BackupManagerEnvVarPrefix = "BACKUP_MANAGER"
 
@@ -49,4 +62,14 @@ const (
 
 	// KMS secret env prefix
 	KMSSecretPrefix = "KMS_ENCRYPTED"
+
+	// BackupSubcommand is the default subcommand of the backup job, which backs up the data of the cluster,
+	// or starts the task of the log backup
+	BackupSubcommand = "backup"
+
+	// LogTruncateSubcommand is the subcommand of the backup job to truncate the log backup
+	LogTruncateSubcommand = "log-truncate"
+
+	// LogStopSubcommand is the subcommand of the backup job to stop the task of the log backup
+	LogStopSubcommand = "log-stop"
 )
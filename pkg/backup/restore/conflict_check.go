@@ -0,0 +1,75 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+// maxReportedConflicts is the max number of the conflicting tables listed in the condition of the restore
+const maxReportedConflicts = 10
+
+// getRestoreConflicts returns the tables of the restore set that exist in the target cluster, the tables are
+// listed by the first healthy TiDB. The check is skipped if the cluster has no TiDB member yet, e.g. the
+// cluster is just created to be restored into.
+func (rm *restoreManager) getRestoreConflicts(restore *v1alpha1.Restore, tc *v1alpha1.TidbCluster) ([]string, error) {
+	if tc.Spec.TiDB == nil || len(tc.Status.TiDB.Members) == 0 {
+		klog.Infof("restore %s/%s skip checking the conflicts as tidbcluster %s/%s has no tidb member",
+			restore.GetNamespace(), restore.GetName(), tc.GetNamespace(), tc.GetName())
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(tc.Status.TiDB.Members))
+	for name, member := range tc.Status.TiDB.Members {
+		if member.Health {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		ordinal, err := util.GetOrdinalFromPodName(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse the ordinal of tidb %s, error: %v", name, err))
+			continue
+		}
+		tables, err := rm.deps.TiDBControl.GetTables(tc, ordinal)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list the tables by tidb %s, error: %v", name, err))
+			continue
+		}
+		return backuputil.GetRestoreConflicts(restore, tables), nil
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no healthy tidb in tidbcluster %s/%s to list the tables", tc.GetNamespace(), tc.GetName())
+	}
+	return nil, errorutils.NewAggregate(errs)
+}
+
+// formatConflicts lists the conflicting tables in the message of the condition of the restore
+func formatConflicts(conflicts []string) string {
+	if len(conflicts) <= maxReportedConflicts {
+		return strings.Join(conflicts, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(conflicts[:maxReportedConflicts], ", "), len(conflicts)-maxReportedConflicts)
+}
@@ -0,0 +1,248 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// applyImportWindow tunes the items of spec.importWindow of the target cluster before the restore job
+// is created. The original values are recorded in status before they are changed for the first time,
+// so that they are not overwritten by the tuned values when the restore is retried.
+func (rm *restoreManager) applyImportWindow(restore *v1alpha1.Restore, tc *v1alpha1.TidbCluster) error {
+	window := restore.Spec.ImportWindow
+	if window == nil {
+		return nil
+	}
+	ns := restore.GetNamespace()
+	name := restore.GetName()
+	pdClient := controller.GetPDClient(rm.deps.PDControl, tc)
+
+	status := restore.Status.ImportWindow
+	if status == nil || status.State != v1alpha1.ImportWindowApplied {
+		var err error
+		status, err = rm.getImportWindowOriginals(window, tc, pdClient)
+		if err != nil {
+			return err
+		}
+		if err := rm.statusUpdater.Update(restore, nil, &controller.RestoreUpdateStatus{ImportWindow: status}); err != nil {
+			return err
+		}
+	}
+
+	if len(window.PDSchedule) > 0 {
+		if err := pdClient.UpdateScheduleConfig(parseScheduleConfig(window.PDSchedule)); err != nil {
+			return fmt.Errorf("failed to update PD schedule config, error: %v", err)
+		}
+	}
+	if window.StoreLimit != nil {
+		limits := make(map[string]v1alpha1.ImportWindowStoreLimit, len(status.StoreLimits))
+		for id := range status.StoreLimits {
+			limits[id] = v1alpha1.ImportWindowStoreLimit{AddPeer: *window.StoreLimit, RemovePeer: *window.StoreLimit}
+		}
+		if err := setStoreLimits(pdClient, limits); err != nil {
+			return err
+		}
+	}
+	if len(window.TiKVConfig) > 0 {
+		if err := rm.updateTiKVConfig(tc, window.TiKVConfig); err != nil {
+			return err
+		}
+	}
+	klog.Infof("restore %s/%s applied the import window to tidbcluster %s/%s", ns, name, tc.GetNamespace(), tc.GetName())
+	return nil
+}
+
+// revertImportWindow restores the original values of the items tuned by the import window after the
+// restore finishes.
+func (rm *restoreManager) revertImportWindow(restore *v1alpha1.Restore) error {
+	status := restore.Status.ImportWindow
+	if status == nil || status.State != v1alpha1.ImportWindowApplied {
+		return nil
+	}
+	ns := restore.GetNamespace()
+	name := restore.GetName()
+	restored := status.DeepCopy()
+	restored.State = v1alpha1.ImportWindowRestored
+
+	clusterNamespace := ns
+	if restore.Spec.BR.ClusterNamespace != "" {
+		clusterNamespace = restore.Spec.BR.ClusterNamespace
+	}
+	tc, err := rm.deps.TiDBClusterLister.TidbClusters(clusterNamespace).Get(restore.Spec.BR.Cluster)
+	if errors.IsNotFound(err) {
+		klog.Warningf("restore %s/%s can not restore the import window as tidbcluster %s/%s is not found", ns, name, clusterNamespace, restore.Spec.BR.Cluster)
+		return rm.statusUpdater.Update(restore, nil, &controller.RestoreUpdateStatus{ImportWindow: restored})
+	}
+	if err != nil {
+		return err
+	}
+	pdClient := controller.GetPDClient(rm.deps.PDControl, tc)
+
+	if len(status.PDSchedule) > 0 {
+		if err := pdClient.UpdateScheduleConfig(parseScheduleConfig(status.PDSchedule)); err != nil {
+			return fmt.Errorf("failed to restore PD schedule config, error: %v", err)
+		}
+	}
+	if err := setStoreLimits(pdClient, status.StoreLimits); err != nil {
+		return err
+	}
+	if len(status.TiKVConfig) > 0 {
+		if err := rm.updateTiKVConfig(tc, status.TiKVConfig); err != nil {
+			return err
+		}
+	}
+	klog.Infof("restore %s/%s restored the import window of tidbcluster %s/%s", ns, name, tc.GetNamespace(), tc.GetName())
+	return rm.statusUpdater.Update(restore, nil, &controller.RestoreUpdateStatus{ImportWindow: restored})
+}
+
+// getImportWindowOriginals returns the current values of the items to be tuned by the import window
+func (rm *restoreManager) getImportWindowOriginals(window *v1alpha1.ImportWindowSpec, tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient) (*v1alpha1.ImportWindowStatus, error) {
+	status := &v1alpha1.ImportWindowStatus{State: v1alpha1.ImportWindowApplied}
+
+	if len(window.PDSchedule) > 0 {
+		cfg, err := pdClient.GetScheduleConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PD schedule config, error: %v", err)
+		}
+		status.PDSchedule = make(map[string]string, len(window.PDSchedule))
+		for key := range window.PDSchedule {
+			value, ok := cfg[key]
+			if !ok {
+				return nil, fmt.Errorf("unknown PD schedule config %s", key)
+			}
+			status.PDSchedule[key] = formatConfigValue(value)
+		}
+	}
+
+	if window.StoreLimit != nil {
+		limits, err := pdClient.GetStoresLimit()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get store limits, error: %v", err)
+		}
+		status.StoreLimits = make(map[string]v1alpha1.ImportWindowStoreLimit, len(limits))
+		for id, limit := range limits {
+			status.StoreLimits[id] = v1alpha1.ImportWindowStoreLimit{AddPeer: limit.AddPeer, RemovePeer: limit.RemovePeer}
+		}
+	}
+
+	if len(window.TiKVConfig) > 0 {
+		var live *config.GenericConfig
+		for _, podName := range upTiKVPods(tc) {
+			cfg, err := rm.deps.TiKVControl.GetTiKVPodClient(tc.GetNamespace(), tc.GetName(), podName, tc.IsTLSClusterEnabled()).GetConfig()
+			if err != nil {
+				klog.Warningf("failed to get the config of tikv %s for tc %s/%s, error: %v", podName, tc.GetNamespace(), tc.GetName(), err)
+				continue
+			}
+			live = config.New(cfg)
+			break
+		}
+		if live == nil {
+			return nil, fmt.Errorf("failed to get the config of tikv for tc %s/%s", tc.GetNamespace(), tc.GetName())
+		}
+		status.TiKVConfig = make(map[string]string, len(window.TiKVConfig))
+		for key := range window.TiKVConfig {
+			value := live.Get(key)
+			if value == nil {
+				return nil, fmt.Errorf("unknown TiKV config %s", key)
+			}
+			status.TiKVConfig[key] = formatConfigValue(value.Interface())
+		}
+	}
+	return status, nil
+}
+
+// updateTiKVConfig changes the config items of all the TiKV stores that are up
+func (rm *restoreManager) updateTiKVConfig(tc *v1alpha1.TidbCluster, items map[string]string) error {
+	cfg := make(map[string]interface{}, len(items))
+	for key, value := range items {
+		cfg[key] = value
+	}
+	for _, podName := range upTiKVPods(tc) {
+		client := rm.deps.TiKVControl.GetTiKVPodClient(tc.GetNamespace(), tc.GetName(), podName, tc.IsTLSClusterEnabled())
+		if err := client.UpdateConfig(cfg); err != nil {
+			return fmt.Errorf("failed to update the config of tikv %s, error: %v", podName, err)
+		}
+	}
+	return nil
+}
+
+func setStoreLimits(pdClient pdapi.PDClient, limits map[string]v1alpha1.ImportWindowStoreLimit) error {
+	for id, limit := range limits {
+		storeID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid store id %s, error: %v", id, err)
+		}
+		if err := pdClient.SetStoreLimit(storeID, pdapi.StoreLimitAddPeer, limit.AddPeer); err != nil {
+			return err
+		}
+		if err := pdClient.SetStoreLimit(storeID, pdapi.StoreLimitRemovePeer, limit.RemovePeer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upTiKVPods(tc *v1alpha1.TidbCluster) []string {
+	var pods []string
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.State == v1alpha1.TiKVStateUp {
+			pods = append(pods, store.PodName)
+		}
+	}
+	sort.Strings(pods)
+	return pods
+}
+
+// parseScheduleConfig converts the values to the types PD expects, numbers and booleans are
+// sent as they are, the others are sent as strings
+func parseScheduleConfig(items map[string]string) map[string]interface{} {
+	cfg := make(map[string]interface{}, len(items))
+	for key, value := range items {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			cfg[key] = f
+		} else if b, err := strconv.ParseBool(value); err == nil {
+			cfg[key] = b
+		} else {
+			cfg[key] = value
+		}
+	}
+	return cfg
+}
+
+func formatConfigValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tikvapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestImportWindow(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+
+	restore := genValidBRRestores()[0]
+	restore.Spec.ImportWindow = &v1alpha1.ImportWindowSpec{
+		PDSchedule: map[string]string{"region-schedule-limit": "0"},
+		StoreLimit: pointer.Float64Ptr(200),
+		TiKVConfig: map[string]string{"import.num-threads": "16"},
+	}
+	helper.createRestore(restore)
+	helper.CreateSecret(restore)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: restore.Spec.BR.ClusterNamespace, Name: restore.Spec.BR.Cluster},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV: &v1alpha1.TiKVSpec{BaseImage: "pingcap/tikv"},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", PodName: "tikv-0", State: v1alpha1.TiKVStateUp},
+					"2": {ID: "2", PodName: "tikv-1", State: v1alpha1.TiKVStateDown},
+				},
+			},
+		},
+	}
+	_, err := deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() error {
+		_, err := deps.TiDBClusterLister.TidbClusters(tc.Namespace).Get(tc.Name)
+		return err
+	}, time.Second*10).Should(BeNil())
+
+	var (
+		scheduleConfig map[string]interface{}
+		storeLimits    = map[pdapi.StoreLimitType]float64{}
+		tikvConfig     map[string]interface{}
+	)
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		return map[string]interface{}{"region-schedule-limit": float64(2048), "leader-schedule-limit": float64(4)}, nil
+	})
+	pdClient.AddReaction(pdapi.UpdateScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		scheduleConfig = action.Config
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.GetStoresLimitActionType, func(action *pdapi.Action) (interface{}, error) {
+		return map[string]pdapi.StoreLimit{"1": {AddPeer: 15, RemovePeer: 20}}, nil
+	})
+	pdClient.AddReaction(pdapi.SetStoreLimitActionType, func(action *pdapi.Action) (interface{}, error) {
+		g.Expect(action.ID).To(Equal(uint64(1)))
+		storeLimits[action.LimitType] = action.Rate
+		return nil, nil
+	})
+	tikvClient := controller.NewFakeTiKVClient(deps.TiKVControl.(*tikvapi.FakeTiKVControl), tc, "tikv-0")
+	tikvClient.AddReaction(tikvapi.GetConfigActionType, func(action *tikvapi.Action) (interface{}, error) {
+		return map[string]interface{}{"import": map[string]interface{}{"num-threads": float64(8)}}, nil
+	})
+	tikvClient.AddReaction(tikvapi.UpdateConfigActionType, func(action *tikvapi.Action) (interface{}, error) {
+		tikvConfig = action.Config
+		return nil, nil
+	})
+
+	// the items are tuned before the restore job is created
+	m := NewRestoreManager(deps)
+	err = m.Sync(restore)
+	g.Expect(err).Should(BeNil())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreScheduled, "")
+	g.Expect(scheduleConfig).To(Equal(map[string]interface{}{"region-schedule-limit": float64(0)}))
+	g.Expect(storeLimits).To(Equal(map[pdapi.StoreLimitType]float64{pdapi.StoreLimitAddPeer: 200, pdapi.StoreLimitRemovePeer: 200}))
+	g.Expect(tikvConfig).To(Equal(map[string]interface{}{"import.num-threads": "16"}))
+
+	get, err := deps.Clientset.PingcapV1alpha1().Restores(restore.Namespace).Get(context.TODO(), restore.Name, metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Expect(get.Status.ImportWindow).To(Equal(&v1alpha1.ImportWindowStatus{
+		State:       v1alpha1.ImportWindowApplied,
+		PDSchedule:  map[string]string{"region-schedule-limit": "2048"},
+		StoreLimits: map[string]v1alpha1.ImportWindowStoreLimit{"1": {AddPeer: 15, RemovePeer: 20}},
+		TiKVConfig:  map[string]string{"import.num-threads": "8"},
+	}))
+
+	// the original values are restored after the restore finishes
+	v1alpha1.UpdateRestoreCondition(&get.Status, &v1alpha1.RestoreCondition{
+		Type:   v1alpha1.RestoreComplete,
+		Status: corev1.ConditionTrue,
+	})
+	err = m.Sync(get)
+	g.Expect(err).Should(BeNil())
+	g.Expect(scheduleConfig).To(Equal(map[string]interface{}{"region-schedule-limit": float64(2048)}))
+	g.Expect(storeLimits).To(Equal(map[pdapi.StoreLimitType]float64{pdapi.StoreLimitAddPeer: 15, pdapi.StoreLimitRemovePeer: 20}))
+	g.Expect(tikvConfig).To(Equal(map[string]interface{}{"import.num-threads": "8"}))
+
+	get, err = deps.Clientset.PingcapV1alpha1().Restores(restore.Namespace).Get(context.TODO(), restore.Name, metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Expect(get.Status.ImportWindow.State).To(Equal(v1alpha1.ImportWindowRestored))
+}
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 )
 
@@ -46,6 +47,9 @@ func NewRestoreManager(deps *controller.Dependencies) backup.RestoreManager {
 }
 
 func (rm *restoreManager) Sync(restore *v1alpha1.Restore) error {
+	if v1alpha1.IsRestoreComplete(restore) || v1alpha1.IsRestoreFailed(restore) {
+		return rm.revertImportWindow(restore)
+	}
 	return rm.syncRestoreJob(restore)
 }
 
@@ -58,7 +62,15 @@ func (rm *restoreManager) syncRestoreJob(restore *v1alpha1.Restore) error {
 	name := restore.GetName()
 	restoreJobName := restore.GetRestoreJobName()
 
-	var err error
+	if restore.IsVolumeSnapshotRestore() {
+		// the target cluster doesn't exist before the volumes are restored
+		return rm.syncVolumeSnapshotRestore(restore)
+	}
+
+	var (
+		err error
+		tc  *v1alpha1.TidbCluster
+	)
 	if restore.Spec.BR == nil {
 		err = backuputil.ValidateRestore(restore, "")
 	} else {
@@ -67,7 +79,6 @@ func (rm *restoreManager) syncRestoreJob(restore *v1alpha1.Restore) error {
 			restoreNamespace = restore.Spec.BR.ClusterNamespace
 		}
 
-		var tc *v1alpha1.TidbCluster
 		tc, err = rm.deps.TiDBClusterLister.TidbClusters(restoreNamespace).Get(restore.Spec.BR.Cluster)
 		if err != nil {
 			reason := fmt.Sprintf("failed to fetch tidbcluster %s/%s", restoreNamespace, restore.Spec.BR.Cluster)
@@ -95,6 +106,34 @@ func (rm *restoreManager) syncRestoreJob(restore *v1alpha1.Restore) error {
 		return controller.IgnoreErrorf("invalid restore spec %s/%s", ns, name)
 	}
 
+	if restore.IsPiTR() {
+		logBackupName := restore.Spec.PiTR.LogBackup
+		logBackup, err := rm.deps.BackupLister.Backups(ns).Get(logBackupName)
+		if err != nil {
+			reason := fmt.Sprintf("failed to fetch log backup %s/%s", ns, logBackupName)
+			rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreRetryFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  reason,
+				Message: err.Error(),
+			}, nil)
+			return err
+		}
+
+		// the log backup is accessed with the credentials of the storage of the restore
+		logStorageType := backuputil.GetStorageType(logBackup.Spec.StorageProvider)
+		if storageType := backuputil.GetStorageType(restore.Spec.StorageProvider); logStorageType != storageType {
+			rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreInvalid,
+				Status:  corev1.ConditionTrue,
+				Reason:  "InvalidSpec",
+				Message: fmt.Sprintf("storage type %s of log backup %s differs from storage type %s of the restore", logStorageType, logBackupName, storageType),
+			}, nil)
+
+			return controller.IgnoreErrorf("invalid restore spec %s/%s", ns, name)
+		}
+	}
+
 	_, err = rm.deps.JobLister.Jobs(ns).Get(restoreJobName)
 	if err == nil {
 		// already have a backup job running，return directly
@@ -132,6 +171,33 @@ func (rm *restoreManager) syncRestoreJob(restore *v1alpha1.Restore) error {
 			return err
 		}
 	} else {
+		// the tables are restored by the job once it's scheduled, so the conflicts are only checked before that
+		if !v1alpha1.IsRestoreScheduled(restore) {
+			conflicts, err := rm.getRestoreConflicts(restore, tc)
+			if err != nil {
+				rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+					Type:    v1alpha1.RestoreRetryFailed,
+					Status:  corev1.ConditionTrue,
+					Reason:  "CheckConflictsFailed",
+					Message: err.Error(),
+				}, nil)
+				return err
+			}
+			if len(conflicts) > 0 {
+				if !restore.Spec.Force {
+					rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+						Type:   v1alpha1.RestoreFailed,
+						Status: corev1.ConditionTrue,
+						Reason: "RestoreConflict",
+						Message: fmt.Sprintf("%d tables of the restore set exist in tidbcluster %s/%s: %s, set spec.force to drop them before restoring",
+							len(conflicts), tc.GetNamespace(), tc.GetName(), formatConflicts(conflicts)),
+					}, nil)
+					return controller.IgnoreErrorf("restore %s/%s conflicts with the existing tables", ns, name)
+				}
+				klog.Infof("restore %s/%s, %d conflicting tables will be dropped by the restore job", ns, name, len(conflicts))
+			}
+		}
+
 		job, reason, err = rm.makeRestoreJob(restore)
 		if err != nil {
 			rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
@@ -142,6 +208,16 @@ func (rm *restoreManager) syncRestoreJob(restore *v1alpha1.Restore) error {
 			}, nil)
 			return err
 		}
+
+		if err := rm.applyImportWindow(restore, tc); err != nil {
+			rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreRetryFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "ApplyImportWindowFailed",
+				Message: err.Error(),
+			}, nil)
+			return err
+		}
 	}
 
 	if err := rm.deps.JobControl.CreateJob(restore, job); err != nil {
@@ -228,7 +304,7 @@ func (rm *restoreManager) makeImportJob(restore *v1alpha1.Restore) (*batchv1.Job
 		})
 		initContainers = append(initContainers, corev1.Container{
 			Name:            "lightning",
-			Image:           restore.Spec.ToolImage,
+			Image:           v1alpha1.ImageWithRegistryPrefix(restore.Spec.ClusterRegistryPrefix, restore.Spec.ToolImage),
 			Command:         []string{"/bin/sh", "-c"},
 			Args:            []string{fmt.Sprintf("cp /tidb-lightning %s/tidb-lightning; echo 'tidb-lightning copy finished'", util.LightningBinPath)},
 			ImagePullPolicy: corev1.PullIfNotPresent,
@@ -242,6 +318,21 @@ func (rm *restoreManager) makeImportJob(restore *v1alpha1.Restore) (*batchv1.Job
 	jobAnnotations := restore.Annotations
 	podAnnotations := jobAnnotations
 
+	builtinMounts := append([]corev1.VolumeMount{{Name: label.RestoreJobLabelVal, MountPath: constants.BackupRootPath}}, volumeMounts...)
+	builtinVolumes := append([]corev1.Volume{{Name: label.RestoreJobLabelVal}}, volumes...)
+	if err := util.ValidateAdditionalVolumes(builtinVolumes, builtinMounts, restore.Spec.AdditionalVolumes, restore.Spec.AdditionalVolumeMounts); err != nil {
+		return nil, fmt.Sprintf("invalid additional volumes of restore %s/%s", ns, name), err
+	}
+	additionalVolumesHash, err := util.AdditionalVolumesHash(rm.deps.UserConfigMapLister, rm.deps.SecretLister, ns, restore.Spec.AdditionalVolumes)
+	if err != nil {
+		return nil, fmt.Sprintf("failed to hash the additional volumes of restore %s/%s", ns, name), err
+	}
+	if additionalVolumesHash != "" {
+		podAnnotations = util.CombineStringMap(map[string]string{label.AnnAdditionalVolumesHash: additionalVolumesHash}, podAnnotations)
+	}
+	volumes = append(volumes, restore.Spec.AdditionalVolumes...)
+	volumeMounts = append(volumeMounts, restore.Spec.AdditionalVolumeMounts...)
+
 	serviceAccount := constants.DefaultServiceAccountName
 	if restore.Spec.ServiceAccount != "" {
 		serviceAccount = restore.Spec.ServiceAccount
@@ -259,7 +350,7 @@ func (rm *restoreManager) makeImportJob(restore *v1alpha1.Restore) (*batchv1.Job
 			Containers: []corev1.Container{
 				{
 					Name:            label.RestoreJobLabelVal,
-					Image:           rm.deps.CLIConfig.TiDBBackupManagerImage,
+					Image:           v1alpha1.ImageWithRegistryPrefix(restore.Spec.ClusterRegistryPrefix, rm.deps.CLIConfig.TiDBBackupManagerImage),
 					Args:            args,
 					ImagePullPolicy: corev1.PullIfNotPresent,
 					VolumeMounts: append([]corev1.VolumeMount{
@@ -360,6 +451,10 @@ func (rm *restoreManager) makeRestoreJob(restore *v1alpha1.Restore) (*batchv1.Jo
 
 	volumeMounts := []corev1.VolumeMount{}
 	volumes := []corev1.Volume{}
+	// only pass the customized port to keep compatible with the backup manager images without the flag
+	if tc.PDClientPort() != v1alpha1.DefaultPDClientPort {
+		args = append(args, fmt.Sprintf("--pd-port=%d", tc.PDClientPort()))
+	}
 	if tc.IsTLSClusterEnabled() {
 		args = append(args, "--cluster-tls=true")
 		volumeMounts = append(volumeMounts, corev1.VolumeMount{
@@ -417,6 +512,24 @@ func (rm *restoreManager) makeRestoreJob(restore *v1alpha1.Restore) (*batchv1.Jo
 		volumes = append(volumes, restore.Spec.Local.Volume)
 		volumeMounts = append(volumeMounts, restore.Spec.Local.VolumeMount)
 	}
+	if restore.Spec.Azblob != nil && restore.Spec.Azblob.SecretName != "" {
+		volume, volumeMount := backuputil.GenerateAzblobCredentialsVolume(restore.Spec.Azblob)
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, volumeMount)
+	}
+
+	if err := util.ValidateAdditionalVolumes(volumes, volumeMounts, restore.Spec.AdditionalVolumes, restore.Spec.AdditionalVolumeMounts); err != nil {
+		return nil, fmt.Sprintf("invalid additional volumes of restore %s/%s", ns, name), err
+	}
+	additionalVolumesHash, err := util.AdditionalVolumesHash(rm.deps.UserConfigMapLister, rm.deps.SecretLister, ns, restore.Spec.AdditionalVolumes)
+	if err != nil {
+		return nil, fmt.Sprintf("failed to hash the additional volumes of restore %s/%s", ns, name), err
+	}
+	if additionalVolumesHash != "" {
+		podAnnotations = util.CombineStringMap(map[string]string{label.AnnAdditionalVolumesHash: additionalVolumesHash}, podAnnotations)
+	}
+	volumes = append(volumes, restore.Spec.AdditionalVolumes...)
+	volumeMounts = append(volumeMounts, restore.Spec.AdditionalVolumeMounts...)
 
 	serviceAccount := constants.DefaultServiceAccountName
 	if restore.Spec.ServiceAccount != "" {
@@ -444,7 +557,7 @@ func (rm *restoreManager) makeRestoreJob(restore *v1alpha1.Restore) (*batchv1.Jo
 			InitContainers: []corev1.Container{
 				{
 					Name:            "br",
-					Image:           brImage,
+					Image:           v1alpha1.ImageWithRegistryPrefix(restore.Spec.ClusterRegistryPrefix, brImage),
 					Command:         []string{"/bin/sh", "-c"},
 					Args:            []string{fmt.Sprintf("cp /br %s/br; echo 'BR copy finished'", util.BRBinPath)},
 					ImagePullPolicy: corev1.PullIfNotPresent,
@@ -455,7 +568,7 @@ func (rm *restoreManager) makeRestoreJob(restore *v1alpha1.Restore) (*batchv1.Jo
 			Containers: []corev1.Container{
 				{
 					Name:            label.RestoreJobLabelVal,
-					Image:           rm.deps.CLIConfig.TiDBBackupManagerImage,
+					Image:           v1alpha1.ImageWithRegistryPrefix(restore.Spec.ClusterRegistryPrefix, rm.deps.CLIConfig.TiDBBackupManagerImage),
 					Args:            args,
 					ImagePullPolicy: corev1.PullIfNotPresent,
 					VolumeMounts:    volumeMounts,
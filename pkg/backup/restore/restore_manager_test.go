@@ -22,8 +22,12 @@ import (
 
 	"github.com/onsi/gomega"
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/testutils"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
@@ -203,6 +207,11 @@ func TestBRRestore(t *testing.T) {
 	var err error
 
 	for i, restore := range genValidBRRestores() {
+		restore.Spec.AdditionalVolumes = []corev1.Volume{{
+			Name:         "ca-bundle",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"}}},
+		}}
+		restore.Spec.AdditionalVolumeMounts = []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/custom"}}
 		helper.createRestore(restore)
 		helper.CreateSecret(restore)
 		helper.CreateTC(restore.Spec.BR.ClusterNamespace, restore.Spec.BR.Cluster)
@@ -230,5 +239,246 @@ func TestBRRestore(t *testing.T) {
 		g.Expect(job.Spec.Template.Spec.Containers[0].Env).To(gomega.ContainElement(env1))
 		g.Expect(job.Spec.Template.Spec.Containers[0].Env).To(gomega.ContainElement(env2Yes))
 		g.Expect(job.Spec.Template.Spec.Containers[0].Env).NotTo(gomega.ContainElement(env2No))
+		g.Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(restore.Spec.AdditionalVolumes[0]))
+		g.Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(restore.Spec.AdditionalVolumeMounts[0]))
+		g.Expect(job.Spec.Template.Annotations).To(HaveKey(label.AnnAdditionalVolumesHash))
 	}
 }
+
+func TestBRPiTRRestore(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+
+	newPiTRRestore := func(name string) *v1alpha1.Restore {
+		restore := genValidBRRestores()[0]
+		restore.Name = name
+		restore.Spec.Type = v1alpha1.BackupTypeFull
+		restore.Spec.Mode = v1alpha1.RestoreModePiTR
+		restore.Spec.PiTR = &v1alpha1.PiTRRestoreSpec{
+			LogBackup:  "log-backup",
+			RestoredTs: "2022-07-15T19:00:00+08:00",
+		}
+		helper.createRestore(restore)
+		return restore
+	}
+
+	// the log backup does not exist
+	restore := newPiTRRestore("pitr-no-log-backup")
+	helper.CreateSecret(restore)
+	helper.CreateTC(restore.Spec.BR.ClusterNamespace, restore.Spec.BR.Cluster)
+	m := NewRestoreManager(deps)
+	g.Expect(m.Sync(restore)).ShouldNot(Succeed())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreRetryFailed, "failed to fetch log backup")
+
+	logBackup := &v1alpha1.Backup{
+		Spec: v1alpha1.BackupSpec{
+			StorageProvider: v1alpha1.StorageProvider{
+				Gcs: &v1alpha1.GcsStorageProvider{ProjectId: "project", Bucket: "log"},
+			},
+		},
+	}
+	logBackup.Namespace = restore.Namespace
+	logBackup.Name = "log-backup"
+	_, err := deps.Clientset.PingcapV1alpha1().Backups(logBackup.Namespace).Create(context.TODO(), logBackup, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() error {
+		_, err := deps.BackupLister.Backups(logBackup.Namespace).Get(logBackup.Name)
+		return err
+	}, time.Second*10).Should(BeNil())
+
+	// the storage type of the log backup differs from the restore
+	restore = newPiTRRestore("pitr-mismatched-storage")
+	g.Expect(m.Sync(restore)).ShouldNot(Succeed())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreInvalid, "InvalidSpec")
+
+	logBackup.Spec.StorageProvider = *restore.Spec.StorageProvider.DeepCopy()
+	_, err = deps.Clientset.PingcapV1alpha1().Backups(logBackup.Namespace).Update(context.TODO(), logBackup, metav1.UpdateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() v1alpha1.BackupStorageType {
+		b, err := deps.BackupLister.Backups(logBackup.Namespace).Get(logBackup.Name)
+		g.Expect(err).Should(BeNil())
+		return backuputil.GetStorageType(b.Spec.StorageProvider)
+	}, time.Second*10).Should(Equal(backuputil.GetStorageType(restore.Spec.StorageProvider)))
+
+	restore = newPiTRRestore("pitr")
+	g.Expect(m.Sync(restore)).Should(Succeed())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreScheduled, "")
+}
+
+func TestBRRestoreConflict(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+
+	restore := genValidBRRestores()[0]
+	helper.CreateSecret(restore)
+	helper.CreateTC(restore.Spec.BR.ClusterNamespace, restore.Spec.BR.Cluster)
+	tc, err := deps.Clientset.PingcapV1alpha1().TidbClusters(restore.Spec.BR.ClusterNamespace).Get(context.TODO(), restore.Spec.BR.Cluster, metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+		"tidb_0-tidb-0": {Name: "tidb_0-tidb-0", Health: true},
+	}
+	_, err = deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Update(context.TODO(), tc, metav1.UpdateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() int {
+		tc, err := deps.TiDBClusterLister.TidbClusters(tc.Namespace).Get(tc.Name)
+		g.Expect(err).Should(BeNil())
+		return len(tc.Status.TiDB.Members)
+	}, time.Second*10).Should(Equal(1))
+	deps.TiDBControl.(*controller.FakeTiDBControl).SetTables(map[string][]string{
+		"dbName": {"t1"},
+		"other":  {"t2"},
+	})
+	m := NewRestoreManager(deps)
+
+	// the table of the restored db exists
+	helper.createRestore(restore)
+	g.Expect(m.Sync(restore)).Should(BeAssignableToTypeOf(&controller.IgnoreError{}))
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreFailed, "RestoreConflict")
+
+	// the conflicting tables are dropped by the job with force
+	restore = genValidBRRestores()[0]
+	restore.Name = "force"
+	restore.Spec.Force = true
+	helper.createRestore(restore)
+	g.Expect(m.Sync(restore)).Should(Succeed())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreScheduled, "")
+}
+
+func TestVolumeSnapshotRestore(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+
+	backup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap", Namespace: "ns"},
+		Spec: v1alpha1.BackupSpec{
+			Mode: v1alpha1.BackupModeVolumeSnapshot,
+			BR:   &v1alpha1.BRConfig{Cluster: "cluster"},
+		},
+		Status: v1alpha1.BackupStatus{
+			Conditions: []v1alpha1.BackupCondition{{Type: v1alpha1.BackupComplete, Status: corev1.ConditionTrue}},
+		},
+	}
+	_, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Create(context.TODO(), backup, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() error {
+		_, err := deps.BackupLister.Backups(backup.Namespace).Get(backup.Name)
+		return err
+	}, time.Second*10).Should(BeNil())
+	data, err := backuputil.EncodeVolumeSnapshotMeta(&backuputil.VolumeSnapshotMeta{
+		ClusterName:      "cluster",
+		ClusterNamespace: "ns",
+		Volumes: []backuputil.VolumeSnapshotVolume{{
+			Component:    "tikv",
+			PVCName:      "tikv-cluster-tikv-0",
+			SnapshotName: "snap-tikv-cluster-tikv-0",
+			Storage:      "10Gi",
+			Labels:       map[string]string{"app.kubernetes.io/component": "tikv"},
+		}},
+	})
+	g.Expect(err).Should(BeNil())
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: backup.GetVolumeSnapshotMetaName(), Namespace: "ns"}, Data: data}
+	g.Expect(deps.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Add(cm)).Should(Succeed())
+
+	newRestore := func(name, cluster string) *v1alpha1.Restore {
+		restore := &v1alpha1.Restore{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec: v1alpha1.RestoreSpec{
+				Mode:                 v1alpha1.RestoreModeVolumeSnapshot,
+				BR:                   &v1alpha1.BRConfig{Cluster: cluster},
+				VolumeSnapshotBackup: backup.Name,
+			},
+		}
+		helper.createRestore(restore)
+		return restore
+	}
+	m := NewRestoreManager(deps)
+
+	// the cluster differs from the backed up cluster
+	restore := newRestore("other-cluster", "other")
+	g.Expect(m.Sync(restore)).ShouldNot(Succeed())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreInvalid, "InvalidSpec")
+
+	// the pvcs are restored from the snapshots
+	restore = newRestore("restore", "cluster")
+	g.Expect(m.Sync(restore)).Should(Succeed())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreComplete, "")
+	pvc, err := deps.PVCLister.PersistentVolumeClaims("ns").Get("tikv-cluster-tikv-0")
+	g.Expect(err).Should(BeNil())
+	g.Expect(pvc.Labels).To(Equal(map[string]string{"app.kubernetes.io/component": "tikv"}))
+	g.Expect(pvc.Spec.DataSource.Name).To(Equal("snap-tikv-cluster-tikv-0"))
+	g.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("10Gi"))
+
+	// the tidbcluster must not exist before the restore
+	helper.CreateTC("ns", "cluster")
+	restore = newRestore("tc-exists", "cluster")
+	g.Expect(m.Sync(restore)).ShouldNot(Succeed())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreInvalid, "InvalidSpec")
+
+	// the data is reset to the resolved ts of the backup after the tidbcluster is created
+	data, err = backuputil.EncodeVolumeSnapshotMeta(&backuputil.VolumeSnapshotMeta{
+		ClusterName:      "reset",
+		ClusterNamespace: "ns",
+		ResolvedTS:       42,
+		Volumes: []backuputil.VolumeSnapshotVolume{{
+			Component:    "tikv",
+			PVCName:      "tikv-reset-tikv-0",
+			SnapshotName: "snap-tikv-reset-tikv-0",
+			Storage:      "10Gi",
+		}},
+	})
+	g.Expect(err).Should(BeNil())
+	cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: backup.GetVolumeSnapshotMetaName(), Namespace: "ns"}, Data: data}
+	g.Expect(deps.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Update(cm)).Should(Succeed())
+	restore = newRestore("reset", "reset")
+	g.Expect(controller.IsRequeueError(m.Sync(restore))).To(BeTrue())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreRunning, "")
+	_, err = deps.PVCLister.PersistentVolumeClaims("ns").Get("tikv-reset-tikv-0")
+	g.Expect(err).Should(BeNil())
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "reset", Namespace: "ns"},
+		Spec: v1alpha1.TidbClusterSpec{
+			TLSCluster: &v1alpha1.TLSCluster{Enabled: true},
+			TiKV:       &v1alpha1.TiKVSpec{BaseImage: "pingcap/tikv", Replicas: 1},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{"1": {ID: "1", IP: "reset-tikv-0.reset-tikv-peer.ns.svc", State: v1alpha1.TiKVStateUp}},
+			},
+		},
+	}
+	_, err = deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() error {
+		_, err := deps.TiDBClusterLister.TidbClusters(tc.Namespace).Get(tc.Name)
+		return err
+	}, time.Second*10).Should(BeNil())
+	g.Expect(controller.IsRequeueError(m.Sync(restore))).To(BeTrue())
+	job, err := deps.KubeClientset.BatchV1().Jobs("ns").Get(context.TODO(), restore.GetRestoreJobName(), metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Expect(job.Spec.Template.Spec.Containers[0].Command[2]).To(ContainSubstring(
+		"/tikv-ctl --host reset-tikv-0.reset-tikv-peer.ns.svc:20160 --ca-path /var/lib/cluster-client-tls/ca.crt --cert-path /var/lib/cluster-client-tls/tls.crt --key-path /var/lib/cluster-client-tls/tls.key reset-to-version -v 42"))
+	g.Expect(job.Spec.Template.Spec.Volumes[0].Secret.SecretName).To(Equal("reset-cluster-client-secret"))
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	_, err = deps.KubeClientset.BatchV1().Jobs("ns").UpdateStatus(context.TODO(), job, metav1.UpdateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() int {
+		job, err := deps.JobLister.Jobs("ns").Get(job.Name)
+		if err != nil {
+			return 0
+		}
+		return len(job.Status.Conditions)
+	}, time.Second*10).Should(Equal(1))
+	g.Expect(m.Sync(restore)).Should(Succeed())
+	helper.hasCondition(restore.Namespace, restore.Name, v1alpha1.RestoreComplete, "")
+	restore, err = deps.Clientset.PingcapV1alpha1().Restores("ns").Get(context.TODO(), "reset", metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Expect(restore.Status.CommitTs).To(Equal("42"))
+}
@@ -0,0 +1,312 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+// syncVolumeSnapshotRestore creates the PVCs of PD and TiKV of the cluster from the snapshots of a volume-snapshot
+// Backup. The PVCs are created with the names and the labels of the backed up PVCs, so that they are adopted by
+// the StatefulSets of the TidbCluster created after the restore, which must have the same name and namespace as
+// the backed up cluster. The data of TiKV is reset to the resolved ts of the Backup after the TidbCluster is created,
+// see syncVolumeSnapshotResetData.
+func (rm *restoreManager) syncVolumeSnapshotRestore(restore *v1alpha1.Restore) error {
+	ns := restore.GetNamespace()
+	name := restore.GetName()
+
+	invalid := func(err error) error {
+		rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+			Type:    v1alpha1.RestoreInvalid,
+			Status:  corev1.ConditionTrue,
+			Reason:  "InvalidSpec",
+			Message: err.Error(),
+		}, nil)
+		return controller.IgnoreErrorf("invalid restore spec %s/%s cause %s", ns, name, err.Error())
+	}
+	if err := backuputil.ValidateRestore(restore, ""); err != nil {
+		return invalid(err)
+	}
+
+	backupName := restore.Spec.VolumeSnapshotBackup
+	backup, err := rm.deps.BackupLister.Backups(ns).Get(backupName)
+	if err != nil {
+		rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+			Type:    v1alpha1.RestoreRetryFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  fmt.Sprintf("failed to fetch backup %s/%s", ns, backupName),
+			Message: err.Error(),
+		}, nil)
+		return err
+	}
+	if !backup.IsVolumeSnapshotBackup() {
+		return invalid(fmt.Errorf("backup %s is not a volume-snapshot backup", backupName))
+	}
+	if !v1alpha1.IsBackupComplete(backup) {
+		return controller.RequeueErrorf("restore %s/%s, backup %s is not complete", ns, name, backupName)
+	}
+	cm, err := rm.deps.UserConfigMapLister.ConfigMaps(ns).Get(backup.GetVolumeSnapshotMetaName())
+	if err != nil {
+		return fmt.Errorf("restore %s/%s get configmap %s failed, err: %v", ns, name, backup.GetVolumeSnapshotMetaName(), err)
+	}
+	meta, err := backuputil.DecodeVolumeSnapshotMeta(cm)
+	if err != nil {
+		return err
+	}
+
+	clusterNamespace := ns
+	if restore.Spec.BR.ClusterNamespace != "" {
+		clusterNamespace = restore.Spec.BR.ClusterNamespace
+	}
+	// the names of the PVCs are derived from the name of the cluster, and a PVC can only be restored from
+	// a snapshot in the same namespace
+	if restore.Spec.BR.Cluster != meta.ClusterName || clusterNamespace != meta.ClusterNamespace {
+		return invalid(fmt.Errorf("the cluster %s/%s differs from the cluster %s/%s of backup %s",
+			clusterNamespace, restore.Spec.BR.Cluster, meta.ClusterNamespace, meta.ClusterName, backupName))
+	}
+
+	if restore.Status.TimeStarted.IsZero() {
+		_, err := rm.deps.TiDBClusterLister.TidbClusters(clusterNamespace).Get(restore.Spec.BR.Cluster)
+		if err == nil {
+			return invalid(fmt.Errorf("tidbcluster %s/%s exists, the restore must be created before the tidbcluster", clusterNamespace, restore.Spec.BR.Cluster))
+		}
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		now := metav1.Now()
+		if err := rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+			Type:   v1alpha1.RestoreRunning,
+			Status: corev1.ConditionTrue,
+		}, &controller.RestoreUpdateStatus{TimeStarted: &now}); err != nil {
+			return err
+		}
+	}
+
+	for _, vol := range meta.Volumes {
+		pvc, err := rm.deps.PVCLister.PersistentVolumeClaims(clusterNamespace).Get(vol.PVCName)
+		if err == nil {
+			if pvc.Spec.DataSource != nil && pvc.Spec.DataSource.Kind == controller.VolumeSnapshotGroupVersionKind.Kind && pvc.Spec.DataSource.Name == vol.SnapshotName {
+				// restored by the last sync
+				continue
+			}
+			return rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "PVCExists",
+				Message: fmt.Sprintf("pvc %s/%s exists and is not restored from volume snapshot %s", clusterNamespace, vol.PVCName, vol.SnapshotName),
+			}, nil)
+		}
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("restore %s/%s get pvc %s/%s failed, err: %v", ns, name, clusterNamespace, vol.PVCName, err)
+		}
+		if err := rm.deps.GeneralPVCControl.CreatePVC(restore, newVolumeSnapshotPVC(clusterNamespace, vol)); err != nil {
+			rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreRetryFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "CreatePVCFailed",
+				Message: err.Error(),
+			}, nil)
+			return fmt.Errorf("restore %s/%s create pvc %s/%s failed, err: %v", ns, name, clusterNamespace, vol.PVCName, err)
+		}
+	}
+	klog.Infof("restore %s/%s restored %d pvcs of tidbcluster %s/%s from backup %s", ns, name, len(meta.Volumes), clusterNamespace, meta.ClusterName, backupName)
+
+	if meta.ResolvedTS == 0 {
+		// the backup is taken without the resolved ts, the data can't be reset
+		klog.Warningf("restore %s/%s, backup %s has no resolved ts, the data of tikv is not reset", ns, name, backupName)
+		now := metav1.Now()
+		return rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+			Type:   v1alpha1.RestoreComplete,
+			Status: corev1.ConditionTrue,
+		}, &controller.RestoreUpdateStatus{TimeCompleted: &now})
+	}
+	return rm.syncVolumeSnapshotResetData(restore, clusterNamespace, meta.ResolvedTS)
+}
+
+// syncVolumeSnapshotResetData resets the data of TiKV to the resolved ts of the backup by `tikv-ctl reset-to-version`
+// once the TidbCluster is created on the restored volumes and all its stores are up, which removes the data written
+// after the resolved ts that may be partially kept by the snapshots cut at different points in time. The cluster
+// must not be written until the Restore is complete.
+func (rm *restoreManager) syncVolumeSnapshotResetData(restore *v1alpha1.Restore, clusterNamespace string, resolvedTS uint64) error {
+	ns := restore.GetNamespace()
+	name := restore.GetName()
+
+	tc, err := rm.deps.TiDBClusterLister.TidbClusters(clusterNamespace).Get(restore.Spec.BR.Cluster)
+	if errors.IsNotFound(err) {
+		return controller.RequeueErrorf("restore %s/%s, waiting for tidbcluster %s/%s to be created on the restored volumes", ns, name, clusterNamespace, restore.Spec.BR.Cluster)
+	}
+	if err != nil {
+		return fmt.Errorf("restore %s/%s get tidbcluster %s/%s failed, err: %v", ns, name, clusterNamespace, restore.Spec.BR.Cluster, err)
+	}
+
+	job, err := rm.deps.JobLister.Jobs(ns).Get(restore.GetRestoreJobName())
+	if errors.IsNotFound(err) {
+		if !tc.TiKVAllStoresReady() {
+			return controller.RequeueErrorf("restore %s/%s, waiting for the tikv stores of tidbcluster %s/%s to be up", ns, name, clusterNamespace, tc.GetName())
+		}
+		if err := rm.deps.JobControl.CreateJob(restore, rm.makeResetDataJob(restore, tc, resolvedTS)); err != nil {
+			rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreRetryFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "CreateResetDataJobFailed",
+				Message: err.Error(),
+			}, nil)
+			return fmt.Errorf("restore %s/%s create job %s failed, err: %v", ns, name, restore.GetRestoreJobName(), err)
+		}
+		klog.Infof("restore %s/%s resets the data of tidbcluster %s/%s to %d", ns, name, clusterNamespace, tc.GetName(), resolvedTS)
+		return controller.RequeueErrorf("restore %s/%s, resetting the data of tidbcluster %s/%s", ns, name, clusterNamespace, tc.GetName())
+	}
+	if err != nil {
+		return fmt.Errorf("restore %s/%s get job %s failed, err: %v", ns, name, restore.GetRestoreJobName(), err)
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobFailed:
+			return rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "ResetDataFailed",
+				Message: fmt.Sprintf("job %s failed: %s", job.Name, c.Message),
+			}, nil)
+		case batchv1.JobComplete:
+			now := metav1.Now()
+			commitTs := strconv.FormatUint(resolvedTS, 10)
+			return rm.statusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:   v1alpha1.RestoreComplete,
+				Status: corev1.ConditionTrue,
+			}, &controller.RestoreUpdateStatus{TimeCompleted: &now, CommitTs: &commitTs})
+		}
+	}
+	return controller.RequeueErrorf("restore %s/%s, resetting the data of tidbcluster %s/%s", ns, name, clusterNamespace, tc.GetName())
+}
+
+// makeResetDataJob returns the Job resetting the data of all the TiKV stores to the resolved ts one by one
+func (rm *restoreManager) makeResetDataJob(restore *v1alpha1.Restore, tc *v1alpha1.TidbCluster, resolvedTS uint64) *batchv1.Job {
+	var flags string
+	var volumeMounts []corev1.VolumeMount
+	var volumes []corev1.Volume
+	if tc.IsTLSClusterEnabled() {
+		flags = fmt.Sprintf(" --ca-path %s --cert-path %s --key-path %s",
+			path.Join(util.ClusterClientTLSPath, corev1.ServiceAccountRootCAKey),
+			path.Join(util.ClusterClientTLSPath, corev1.TLSCertKey),
+			path.Join(util.ClusterClientTLSPath, corev1.TLSPrivateKeyKey))
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      util.ClusterClientVolName,
+			ReadOnly:  true,
+			MountPath: util.ClusterClientTLSPath,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: util.ClusterClientVolName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.ClusterClientTLSSecretName(tc.GetName()),
+				},
+			},
+		})
+	}
+	var storeIDs []string
+	for id := range tc.Status.TiKV.Stores {
+		storeIDs = append(storeIDs, id)
+	}
+	sort.Strings(storeIDs)
+	script := "set -e\n"
+	for _, id := range storeIDs {
+		addr := fmt.Sprintf("%s:%d", tc.Status.TiKV.Stores[id].IP, tc.TiKVPort())
+		script += fmt.Sprintf("/tikv-ctl --host %s%s reset-to-version -v %d\n", addr, flags, resolvedTS)
+	}
+
+	jobLabels := util.CombineStringMap(label.NewRestore().Instance(restore.GetInstanceName()).RestoreJob().Restore(restore.GetName()), restore.Labels)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      restore.GetRestoreJobName(),
+			Namespace: restore.GetNamespace(),
+			Labels:    jobLabels,
+			OwnerReferences: []metav1.OwnerReference{
+				controller.GetRestoreOwnerRef(restore),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabels,
+				},
+				Spec: corev1.PodSpec{
+					SecurityContext: restore.Spec.PodSecurityContext,
+					Containers: []corev1.Container{
+						{
+							Name:            label.RestoreJobLabelVal,
+							Image:           tc.TiKVImage(),
+							Command:         []string{"/bin/sh", "-c", script},
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							VolumeMounts:    volumeMounts,
+							Resources:       restore.Spec.ResourceRequirements,
+						},
+					},
+					RestartPolicy:     corev1.RestartPolicyNever,
+					Tolerations:       restore.Spec.Tolerations,
+					ImagePullSecrets:  tc.Spec.ImagePullSecrets,
+					Affinity:          restore.Spec.Affinity,
+					Volumes:           volumes,
+					PriorityClassName: restore.Spec.PriorityClassName,
+				},
+			},
+		},
+	}
+}
+
+// newVolumeSnapshotPVC returns the PVC restored from the snapshot of the volume
+func newVolumeSnapshotPVC(ns string, vol backuputil.VolumeSnapshotVolume) *corev1.PersistentVolumeClaim {
+	apiGroup := controller.VolumeSnapshotGroupVersionKind.Group
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      vol.PVCName,
+			Namespace: ns,
+			Labels:    vol.Labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      vol.AccessModes,
+			StorageClassName: vol.StorageClassName,
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     controller.VolumeSnapshotGroupVersionKind.Kind,
+				Name:     vol.SnapshotName,
+			},
+		},
+	}
+	if q, err := resource.ParseQuantity(vol.Storage); err == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: q}
+	}
+	return pvc
+}
@@ -51,6 +51,19 @@ func GenValidStorageProviders() []v1alpha1.StorageProvider {
 				SecretName: "gcs",
 			},
 		},
+		{
+			Azblob: &v1alpha1.AzblobStorageProvider{
+				Container: "azblob",
+				Prefix:    "prefix-",
+			},
+		},
+		{
+			Azblob: &v1alpha1.AzblobStorageProvider{
+				Container:  "azblob",
+				Prefix:     "prefix-",
+				SecretName: "azblob",
+			},
+		},
 		{
 			Local: &v1alpha1.LocalStorageProvider{
 				Prefix: "prefix-",
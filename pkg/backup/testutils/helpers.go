@@ -71,6 +71,8 @@ func (h *Helper) createSecret(namespace, secretName string) {
 		constants.GcsCredentialsKey: []byte("dummy"),
 		constants.S3AccessKey:       []byte("dummy"),
 		constants.S3SecretKey:       []byte("dummy"),
+		constants.AzblobAccountName: []byte("dummy"),
+		constants.AzblobSASToken:    []byte("dummy"),
 	}
 	s.Namespace = namespace
 	s.Name = secretName
@@ -100,6 +102,12 @@ func (h *Helper) CreateSecret(obj interface{}) {
 				_, err := h.Deps.SecretLister.Secrets(obj1.Namespace).Get(obj1.Spec.StorageProvider.Gcs.SecretName)
 				return err
 			}, time.Second*10).Should(BeNil())
+		} else if obj1.Spec.StorageProvider.Azblob != nil && obj1.Spec.StorageProvider.Azblob.SecretName != "" {
+			h.createSecret(obj1.Namespace, obj1.Spec.StorageProvider.Azblob.SecretName)
+			g.Eventually(func() error {
+				_, err := h.Deps.SecretLister.Secrets(obj1.Namespace).Get(obj1.Spec.StorageProvider.Azblob.SecretName)
+				return err
+			}, time.Second*10).Should(BeNil())
 		}
 	} else if obj2, ok := obj.(*v1alpha1.Restore); ok {
 		h.createSecret(obj2.Namespace, obj2.Spec.To.SecretName)
@@ -119,6 +127,12 @@ func (h *Helper) CreateSecret(obj interface{}) {
 				_, err := h.Deps.SecretLister.Secrets(obj2.Namespace).Get(obj2.Spec.StorageProvider.Gcs.SecretName)
 				return err
 			}, time.Second*10).Should(BeNil())
+		} else if obj2.Spec.StorageProvider.Azblob != nil && obj2.Spec.StorageProvider.Azblob.SecretName != "" {
+			h.createSecret(obj2.Namespace, obj2.Spec.StorageProvider.Azblob.SecretName)
+			g.Eventually(func() error {
+				_, err := h.Deps.SecretLister.Secrets(obj2.Namespace).Get(obj2.Spec.StorageProvider.Azblob.SecretName)
+				return err
+			}, time.Second*10).Should(BeNil())
 		}
 	}
 }
@@ -16,12 +16,18 @@ package util
 import (
 	"fmt"
 	"net/url"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
 	"github.com/pingcap/tidb-operator/pkg/backup/constants"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 )
@@ -172,6 +178,63 @@ func generateGcsCertEnvVar(gcs *v1alpha1.GcsStorageProvider) ([]corev1.EnvVar, s
 	return envVars, "", nil
 }
 
+// generateAzblobCertEnvVar generate the env info in order to access azure blob storage
+func generateAzblobCertEnvVar(azblob *v1alpha1.AzblobStorageProvider, secret *corev1.Secret) []corev1.EnvVar {
+	envVars := []corev1.EnvVar{
+		{
+			Name:  "AZURE_ACCESS_TIER",
+			Value: azblob.AccessTier,
+		},
+	}
+	if secret == nil {
+		return envVars
+	}
+	envVars = append(envVars, corev1.EnvVar{
+		Name: "AZURE_STORAGE_ACCOUNT",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: azblob.SecretName},
+				Key:                  constants.AzblobAccountName,
+			},
+		},
+	})
+	// the SAS token is preferred as it can be rotated, the rotated one is read from the mounted secret by
+	// tidb-backup-manager, see GenerateAzblobCredentialsVolume
+	key, env := constants.AzblobSASToken, "AZURE_STORAGE_SAS_TOKEN"
+	if _, exist := secret.Data[constants.AzblobSASToken]; !exist {
+		key, env = constants.AzblobAccountKey, "AZURE_STORAGE_KEY"
+	}
+	envVars = append(envVars, corev1.EnvVar{
+		Name: env,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: azblob.SecretName},
+				Key:                  key,
+			},
+		},
+	})
+	return envVars
+}
+
+// GenerateAzblobCredentialsVolume generate the volume and volume mount of the secret of azure blob storage,
+// kubelet updates the mounted secret in place, so the rotated SAS token is visible to the running job
+func GenerateAzblobCredentialsVolume(azblob *v1alpha1.AzblobStorageProvider) (corev1.Volume, corev1.VolumeMount) {
+	volume := corev1.Volume{
+		Name: "azblob-credentials",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: azblob.SecretName,
+			},
+		},
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      "azblob-credentials",
+		ReadOnly:  true,
+		MountPath: constants.AzblobCredentialsPath,
+	}
+	return volume, volumeMount
+}
+
 // GenerateStorageCertEnv generate the env info in order to access backend backup storage
 func GenerateStorageCertEnv(ns string, useKMS bool, provider v1alpha1.StorageProvider, secretLister corelisterv1.SecretLister) ([]corev1.EnvVar, string, error) {
 	var certEnv []corev1.EnvVar
@@ -221,6 +284,30 @@ func GenerateStorageCertEnv(ns string, useKMS bool, provider v1alpha1.StoragePro
 		if err != nil {
 			return certEnv, reason, err
 		}
+	case v1alpha1.BackupStorageTypeAzblob:
+		var secret *corev1.Secret
+		azblobSecretName := provider.Azblob.SecretName
+		if azblobSecretName != "" {
+			secret, err = secretLister.Secrets(ns).Get(azblobSecretName)
+			if err != nil {
+				err := fmt.Errorf("get azblob secret %s/%s failed, err: %v", ns, azblobSecretName, err)
+				return certEnv, "GetAzblobSecretFailed", err
+			}
+
+			keyStr, exist := CheckAllKeysExistInSecret(secret, constants.AzblobAccountName)
+			if !exist {
+				err := fmt.Errorf("the azblob secret %s/%s missing some keys %s", ns, azblobSecretName, keyStr)
+				return certEnv, "azblobKeyNotExist", err
+			}
+			_, keyExist := CheckAllKeysExistInSecret(secret, constants.AzblobAccountKey)
+			_, tokenExist := CheckAllKeysExistInSecret(secret, constants.AzblobSASToken)
+			if !keyExist && !tokenExist {
+				err := fmt.Errorf("the azblob secret %s/%s missing both keys %s and %s", ns, azblobSecretName, constants.AzblobAccountKey, constants.AzblobSASToken)
+				return certEnv, "azblobKeyNotExist", err
+			}
+		}
+
+		certEnv = generateAzblobCertEnvVar(provider.Azblob, secret)
 	case v1alpha1.BackupStorageTypeLocal:
 		return []corev1.EnvVar{}, "", nil
 	default:
@@ -282,6 +369,8 @@ func GetBackupBucketName(backup *v1alpha1.Backup) (string, string, error) {
 		bucketName = backup.Spec.S3.Bucket
 	case v1alpha1.BackupStorageTypeGcs:
 		bucketName = backup.Spec.Gcs.Bucket
+	case v1alpha1.BackupStorageTypeAzblob:
+		bucketName = backup.Spec.Azblob.Container
 	default:
 		return bucketName, "UnsupportedStorageType", fmt.Errorf("backup %s/%s unsupported storage type %s", ns, name, storageType)
 	}
@@ -300,6 +389,8 @@ func GetBackupPrefixName(backup *v1alpha1.Backup) (string, string, error) {
 		prefix = backup.Spec.S3.Prefix
 	case v1alpha1.BackupStorageTypeGcs:
 		prefix = backup.Spec.Gcs.Prefix
+	case v1alpha1.BackupStorageTypeAzblob:
+		prefix = backup.Spec.Azblob.Prefix
 	default:
 		return prefix, "UnsupportedStorageType", fmt.Errorf("backup %s/%s unsupported storage type %s", ns, name, storageType)
 	}
@@ -315,6 +406,9 @@ func GetStorageType(provider v1alpha1.StorageProvider) v1alpha1.BackupStorageTyp
 	if provider.Gcs != nil {
 		return v1alpha1.BackupStorageTypeGcs
 	}
+	if provider.Azblob != nil {
+		return v1alpha1.BackupStorageTypeAzblob
+	}
 	if provider.Local != nil {
 		return v1alpha1.BackupStorageTypeLocal
 	}
@@ -331,6 +425,8 @@ func GetBackupDataPath(provider v1alpha1.StorageProvider) (string, string, error
 		backupPath = provider.S3.Path
 	case v1alpha1.BackupStorageTypeGcs:
 		backupPath = provider.Gcs.Path
+	case v1alpha1.BackupStorageTypeAzblob:
+		backupPath = provider.Azblob.Path
 	default:
 		return backupPath, "UnsupportedStorageType", fmt.Errorf("unsupported storage type %s", storageType)
 	}
@@ -362,6 +458,24 @@ func ValidateBackup(backup *v1alpha1.Backup, tikvImage string) error {
 	ns := backup.Namespace
 	name := backup.Name
 
+	if backup.Spec.Notifications != nil {
+		if errs := validation.ValidateNotificationSpec(backup.Spec.Notifications, field.NewPath("spec", "notifications")); len(errs) > 0 {
+			return fmt.Errorf("invalid notifications in spec of %s/%s: %v", ns, name, errs.ToAggregate())
+		}
+	}
+
+	// the volume-snapshot mode takes the snapshots of the PVCs of the cluster, neither the access config
+	// nor the storage is used
+	if backup.IsVolumeSnapshotBackup() {
+		if backup.Spec.BR == nil || backup.Spec.BR.Cluster == "" {
+			return fmt.Errorf("cluster should be configured in br for volume-snapshot mode in spec of %s/%s", ns, name)
+		}
+		if backup.Spec.Type != "" && backup.Spec.Type != v1alpha1.BackupTypeFull {
+			return fmt.Errorf("invalid backup type %s for volume-snapshot mode in spec of %s/%s", backup.Spec.Type, ns, name)
+		}
+		return nil
+	}
+
 	if backup.Spec.BR == nil {
 		if reason := validateAccessConfig(backup.Spec.From); reason != "" {
 			return fmt.Errorf(reason, ns, name)
@@ -369,8 +483,33 @@ func ValidateBackup(backup *v1alpha1.Backup, tikvImage string) error {
 		if backup.Spec.StorageSize == "" {
 			return fmt.Errorf("missing StorageSize config in spec of %s/%s", ns, name)
 		}
+		if backup.Spec.Dumpling != nil && backup.Spec.Dumpling.ShardCount < 0 {
+			return fmt.Errorf("invalid shard count %d for dumpling in spec of %s/%s", backup.Spec.Dumpling.ShardCount, ns, name)
+		}
+		if backup.IsLogBackup() {
+			return fmt.Errorf("log mode is only supported by BR in spec of %s/%s", ns, name)
+		}
 	} else {
-		if !canSkipSetGCLifeTime(tikvImage) {
+		if backup.Spec.Dumpling != nil && backup.Spec.Dumpling.ShardCount > 1 {
+			return fmt.Errorf("shard count is only supported by dumpling in spec of %s/%s", ns, name)
+		}
+		if backup.Spec.Mode != "" && backup.Spec.Mode != v1alpha1.BackupModeSnapshot && backup.Spec.Mode != v1alpha1.BackupModeLog {
+			return fmt.Errorf("invalid backup mode %s for BR in spec of %s/%s", backup.Spec.Mode, ns, name)
+		}
+		if backup.IsLogBackup() {
+			if backup.Spec.Type != "" && backup.Spec.Type != v1alpha1.BackupTypeFull {
+				return fmt.Errorf("invalid backup type %s for log mode in spec of %s/%s", backup.Spec.Type, ns, name)
+			}
+			if backup.Spec.LogTruncateUntil != "" {
+				if _, err := ParseTSString(backup.Spec.LogTruncateUntil); err != nil {
+					return fmt.Errorf("invalid log truncate until in spec of %s/%s: %v", ns, name, err)
+				}
+			}
+		} else if backup.Spec.LogTruncateUntil != "" {
+			return fmt.Errorf("log truncate until is only valid for log mode in spec of %s/%s", ns, name)
+		}
+		// the log backup doesn't adjust the tikv_gc_life_time
+		if !canSkipSetGCLifeTime(tikvImage) && !backup.IsLogBackup() {
 			if reason := validateAccessConfig(backup.Spec.From); reason != "" {
 				return fmt.Errorf(reason, ns, name)
 			}
@@ -394,6 +533,10 @@ func ValidateBackup(backup *v1alpha1.Backup, tikvImage string) error {
 			return fmt.Errorf("table should be configured for BR with backup type table in spec of %s/%s", ns, name)
 		}
 
+		if backup.Spec.BR.Concurrency != nil && *backup.Spec.BR.Concurrency == 0 {
+			return fmt.Errorf("concurrency should be greater than 0 for BR in spec of %s/%s", ns, name)
+		}
+
 		// validate storage providers
 		if backup.Spec.S3 != nil {
 			if err := validateS3(ns, name, backup.Spec.S3); err != nil {
@@ -403,6 +546,10 @@ func ValidateBackup(backup *v1alpha1.Backup, tikvImage string) error {
 			if err := validateGcs(ns, name, backup.Spec.Gcs); err != nil {
 				return err
 			}
+		} else if backup.Spec.Azblob != nil {
+			if err := validateAzblob(ns, name, backup.Spec.Azblob); err != nil {
+				return err
+			}
 		} else if backup.Spec.Local != nil {
 			if err := validateLocal(ns, name, backup.Spec.Local); err != nil {
 				return err
@@ -417,6 +564,19 @@ func ValidateRestore(restore *v1alpha1.Restore, tikvImage string) error {
 	ns := restore.Namespace
 	name := restore.Name
 
+	if restore.IsVolumeSnapshotRestore() {
+		if restore.Spec.BR == nil || restore.Spec.BR.Cluster == "" {
+			return fmt.Errorf("cluster should be configured in br for volume-snapshot mode in spec of %s/%s", ns, name)
+		}
+		if restore.Spec.VolumeSnapshotBackup == "" {
+			return fmt.Errorf("volume snapshot backup should be configured for volume-snapshot mode in spec of %s/%s", ns, name)
+		}
+		if restore.Spec.Type != "" && restore.Spec.Type != v1alpha1.BackupTypeFull {
+			return fmt.Errorf("invalid restore type %s for volume-snapshot mode in spec of %s/%s", restore.Spec.Type, ns, name)
+		}
+		return nil
+	}
+
 	if restore.Spec.BR == nil {
 		if reason := validateAccessConfig(restore.Spec.To); reason != "" {
 			return fmt.Errorf(reason, ns, name)
@@ -424,6 +584,18 @@ func ValidateRestore(restore *v1alpha1.Restore, tikvImage string) error {
 		if restore.Spec.StorageSize == "" {
 			return fmt.Errorf("missing StorageSize config in spec of %s/%s", ns, name)
 		}
+		if restore.Spec.ImportWindow != nil {
+			return fmt.Errorf("import window is only supported by BR in spec of %s/%s", ns, name)
+		}
+		if restore.IsPiTR() {
+			return fmt.Errorf("pitr mode is only supported by BR in spec of %s/%s", ns, name)
+		}
+		if restore.Spec.Force {
+			return fmt.Errorf("force is only supported by BR in spec of %s/%s", ns, name)
+		}
+		if restore.Spec.TiFlashReplica != nil {
+			return fmt.Errorf("tiflash replica is only supported by BR in spec of %s/%s", ns, name)
+		}
 	} else {
 		if !canSkipSetGCLifeTime(tikvImage) {
 			if reason := validateAccessConfig(restore.Spec.To); reason != "" {
@@ -434,6 +606,46 @@ func ValidateRestore(restore *v1alpha1.Restore, tikvImage string) error {
 			return fmt.Errorf("cluster should be configured for BR in spec of %s/%s", ns, name)
 		}
 
+		if stats := restore.Spec.Statistics; stats != nil {
+			if stats.Analyze {
+				if reason := validateAccessConfig(restore.Spec.To); reason != "" {
+					return fmt.Errorf("analyzing statistics requires the access config: "+reason, ns, name)
+				}
+			}
+			if stats.Concurrency != nil && *stats.Concurrency <= 0 {
+				return fmt.Errorf("statistics concurrency should be positive in spec of %s/%s", ns, name)
+			}
+		}
+
+		if replica := restore.Spec.TiFlashReplica; replica != nil {
+			if reason := validateAccessConfig(restore.Spec.To); reason != "" {
+				return fmt.Errorf("setting the tiflash replicas requires the access config: "+reason, ns, name)
+			}
+			for _, t := range replica.Tables {
+				if t.DB == "" {
+					return fmt.Errorf("db should be configured for the tiflash replicas in spec of %s/%s", ns, name)
+				}
+				if t.Replicas < 0 {
+					return fmt.Errorf("tiflash replicas of %s should not be negative in spec of %s/%s", t.DB, ns, name)
+				}
+			}
+			if replica.WaitTimeout != "" {
+				if _, err := time.ParseDuration(replica.WaitTimeout); err != nil {
+					return fmt.Errorf("invalid tiflash replica wait timeout %s in spec of %s/%s, err: %v", replica.WaitTimeout, ns, name, err)
+				}
+			}
+		}
+
+		if window := restore.Spec.ImportWindow; window != nil && window.StoreLimit != nil && *window.StoreLimit <= 0 {
+			return fmt.Errorf("import window store limit should be positive in spec of %s/%s", ns, name)
+		}
+
+		if restore.Spec.Force {
+			if reason := validateAccessConfig(restore.Spec.To); reason != "" {
+				return fmt.Errorf("dropping the conflicting tables requires the access config: "+reason, ns, name)
+			}
+		}
+
 		if restore.Spec.Type != "" &&
 			restore.Spec.Type != v1alpha1.BackupTypeFull &&
 			restore.Spec.Type != v1alpha1.BackupTypeDB &&
@@ -449,6 +661,19 @@ func ValidateRestore(restore *v1alpha1.Restore, tikvImage string) error {
 			return fmt.Errorf("table should be configured for BR with restore type table in spec of %s/%s", ns, name)
 		}
 
+		if restore.IsPiTR() {
+			pitr := restore.Spec.PiTR
+			if pitr == nil || pitr.LogBackup == "" {
+				return fmt.Errorf("log backup should be configured for pitr mode in spec of %s/%s", ns, name)
+			}
+			if restore.Spec.Type != "" && restore.Spec.Type != v1alpha1.BackupTypeFull {
+				return fmt.Errorf("invalid restore type %s for pitr mode in spec of %s/%s", restore.Spec.Type, ns, name)
+			}
+			if _, err := ParseRestoredTs(pitr.RestoredTs); err != nil {
+				return fmt.Errorf("invalid restored ts for pitr mode in spec of %s/%s: %v", ns, name, err)
+			}
+		}
+
 		// validate storage providers
 		if restore.Spec.S3 != nil {
 			if err := validateS3(ns, name, restore.Spec.S3); err != nil {
@@ -458,6 +683,10 @@ func ValidateRestore(restore *v1alpha1.Restore, tikvImage string) error {
 			if err := validateGcs(ns, name, restore.Spec.Gcs); err != nil {
 				return err
 			}
+		} else if restore.Spec.Azblob != nil {
+			if err := validateAzblob(ns, name, restore.Spec.Azblob); err != nil {
+				return err
+			}
 		} else if restore.Spec.Local != nil {
 			if err := validateLocal(ns, name, restore.Spec.Local); err != nil {
 				return err
@@ -467,6 +696,63 @@ func ValidateRestore(restore *v1alpha1.Restore, tikvImage string) error {
 	return nil
 }
 
+// ParseRestoredTs parses the restored ts of a point-in-time restore to a TSO,
+// the restored ts is either a TSO or a RFC3339 datetime
+func ParseRestoredTs(restoredTs string) (uint64, error) {
+	if restoredTs == "" {
+		return 0, fmt.Errorf("restored ts is empty")
+	}
+	return ParseTSString(restoredTs)
+}
+
+// ParseTSString parses a ts to a TSO, the ts is either a TSO or a RFC3339 datetime
+func ParseTSString(ts string) (uint64, error) {
+	if tso, err := strconv.ParseUint(ts, 10, 64); err == nil {
+		return tso, nil
+	}
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return 0, fmt.Errorf("%s is neither a TSO nor a RFC3339 datetime", ts)
+	}
+	return TimeToTSO(t), nil
+}
+
+// NeedRestoreTikvGCLifeTime returns whether the tikv_gc_life_time adjusted by the failed backup needs to be restored
+// by the controller, which can't restore it if the password of TiDB is encrypted by KMS
+func NeedRestoreTikvGCLifeTime(backup *v1alpha1.Backup) bool {
+	status := backup.Status.TikvGCLifeTime
+	return v1alpha1.IsBackupFailed(backup) && !backup.Spec.UseKMS && backup.Spec.From != nil &&
+		status != nil && status.State == v1alpha1.TikvGCLifeTimeAdjusted
+}
+
+// NeedLogTruncate returns whether the log backup needs to be truncated, i.e. the logTruncateUntil
+// in spec is beyond the ts that the log backup has been truncated until
+func NeedLogTruncate(backup *v1alpha1.Backup) bool {
+	if !backup.IsLogBackup() || backup.Spec.LogTruncateUntil == "" {
+		return false
+	}
+	truncateUntil, err := ParseTSString(backup.Spec.LogTruncateUntil)
+	if err != nil {
+		return false
+	}
+	if backup.Status.LogTruncatedUntil == "" {
+		return true
+	}
+	truncatedUntil, err := ParseTSString(backup.Status.LogTruncatedUntil)
+	return err != nil || truncateUntil > truncatedUntil
+}
+
+// TimeToTSO converts a time to a TSO, whose physical part is the unix time in milliseconds
+func TimeToTSO(t time.Time) uint64 {
+	return uint64(t.UnixNano()/int64(time.Millisecond)) << 18
+}
+
+// TSOToTime converts a TSO to the time of its physical part
+func TSOToTime(tso uint64) time.Time {
+	ms := int64(tso >> 18)
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
+
 func validateS3(ns, name string, s3 *v1alpha1.S3StorageProvider) error {
 	configuredForBR := fmt.Sprintf("configured for BR in spec of %s/%s", ns, name)
 	if s3.Bucket == "" {
@@ -499,6 +785,19 @@ func validateGcs(ns, name string, gcs *v1alpha1.GcsStorageProvider) error {
 	return nil
 }
 
+func validateAzblob(ns, name string, azblob *v1alpha1.AzblobStorageProvider) error {
+	configuredForBR := fmt.Sprintf("configured for BR in spec of %s/%s", ns, name)
+	if azblob.Container == "" {
+		return fmt.Errorf("container should be %s", configuredForBR)
+	}
+	switch azblob.AccessTier {
+	case "", "Hot", "Cool", "Archive":
+	default:
+		return fmt.Errorf("invalid access tier %s %s", azblob.AccessTier, configuredForBR)
+	}
+	return nil
+}
+
 func validateLocal(ns, name string, local *v1alpha1.LocalStorageProvider) error {
 	configuredForBR := fmt.Sprintf("configured for BR in spec of %s/%s", ns, name)
 	if local.VolumeMount.Name != local.Volume.Name {
@@ -539,3 +838,122 @@ func canSkipSetGCLifeTime(image string) bool {
 	}
 	return true
 }
+
+// systemSchemas are the schemas of TiDB that are never restored as user tables
+var systemSchemas = map[string]struct{}{
+	"mysql":              {},
+	"information_schema": {},
+	"performance_schema": {},
+	"metrics_schema":     {},
+	"inspection_schema":  {},
+}
+
+// GetRestoreConflicts returns the tables in the format of `db.table` that exist in the target cluster
+// and are in the restore set of the restore, the tables of the target cluster are keyed by the databases.
+// The restore set is the db or the table of the restore of type db or table, the tables matched by the
+// table filter, or all the user tables.
+func GetRestoreConflicts(restore *v1alpha1.Restore, tables map[string][]string) []string {
+	var conflicts []string
+	for db, names := range tables {
+		if _, ok := systemSchemas[strings.ToLower(db)]; ok {
+			continue
+		}
+		for _, table := range names {
+			if InRestoreSet(restore, db, table) {
+				conflicts = append(conflicts, fmt.Sprintf("%s.%s", db, table))
+			}
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// InRestoreSet returns whether the table is in the restore set of the restore
+func InRestoreSet(restore *v1alpha1.Restore, db, table string) bool {
+	switch restore.Spec.Type {
+	case v1alpha1.BackupTypeDB:
+		return strings.EqualFold(db, restore.Spec.BR.DB)
+	case v1alpha1.BackupTypeTable:
+		return strings.EqualFold(db, restore.Spec.BR.DB) && strings.EqualFold(table, restore.Spec.BR.Table)
+	}
+	if len(restore.Spec.TableFilter) == 0 {
+		return true
+	}
+	return matchTableFilter(restore.Spec.TableFilter, db, table)
+}
+
+// matchTableFilter matches the table with the table filter rules of BR case-insensitively, the last
+// matched rule decides whether the table is matched, and the rules prefixed with `!` exclude the table.
+// Only the wildcard rules are supported, the rules of the other syntaxes never match.
+func matchTableFilter(filters []string, db, table string) bool {
+	matched := false
+	for _, rule := range filters {
+		rule = strings.TrimSpace(rule)
+		exclude := strings.HasPrefix(rule, "!")
+		parts := strings.SplitN(strings.TrimPrefix(rule, "!"), ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		dbMatched, err := path.Match(strings.ToLower(parts[0]), strings.ToLower(db))
+		if err != nil || !dbMatched {
+			continue
+		}
+		tableMatched, err := path.Match(strings.ToLower(parts[1]), strings.ToLower(table))
+		if err != nil || !tableMatched {
+			continue
+		}
+		matched = !exclude
+	}
+	return matched
+}
+
+// GetOriginalTikvGCLifeTime returns the original tikv_gc_life_time if it has been adjusted by an interrupted run
+// of the backup, or by another running backup of the same cluster, in which cases the current value in the
+// cluster is not the original one. The finished backups are not counted, as the value they recorded may be
+// stale, e.g. the value is changed after they finished.
+func GetOriginalTikvGCLifeTime(backup *v1alpha1.Backup, backups []*v1alpha1.Backup) (string, bool) {
+	if status := backup.Status.TikvGCLifeTime; status != nil && status.State == v1alpha1.TikvGCLifeTimeAdjusted {
+		return status.Original, true
+	}
+	for _, other := range backups {
+		if isAdjustingTikvGCLifeTime(backup, other) {
+			return other.Status.TikvGCLifeTime.Original, true
+		}
+	}
+	return "", false
+}
+
+// IsTikvGCLifeTimeAdjustedByOthers returns whether other running backups of the same cluster have adjusted the
+// tikv_gc_life_time, in which case the original one must not be restored until all of them finish.
+func IsTikvGCLifeTimeAdjustedByOthers(backup *v1alpha1.Backup, backups []*v1alpha1.Backup) bool {
+	for _, other := range backups {
+		if isAdjustingTikvGCLifeTime(backup, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdjustingTikvGCLifeTime returns whether the other running backup of the same cluster has adjusted the
+// tikv_gc_life_time and not restored it
+func isAdjustingTikvGCLifeTime(backup, other *v1alpha1.Backup) bool {
+	if !v1alpha1.IsBackupRunning(other) || v1alpha1.IsBackupComplete(other) || v1alpha1.IsBackupFailed(other) {
+		return false
+	}
+	if other.Namespace != backup.Namespace || other.Name == backup.Name {
+		return false
+	}
+	if other.Spec.From == nil || backup.Spec.From == nil ||
+		other.Spec.From.Host != backup.Spec.From.Host || tidbPort(other.Spec.From) != tidbPort(backup.Spec.From) {
+		return false
+	}
+	status := other.Status.TikvGCLifeTime
+	return status != nil && status.State == v1alpha1.TikvGCLifeTimeAdjusted
+}
+
+func tidbPort(from *v1alpha1.TiDBAccessConfig) int32 {
+	if from.Port == 0 {
+		return v1alpha1.DefaultTidbPort
+	}
+	return from.Port
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -25,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
 )
 
 func TestCheckAllKeysExistInSecret(t *testing.T) {
@@ -149,6 +151,42 @@ func TestGenerateGcsCertEnvVar(t *testing.T) {
 	g.Expect(len(envs)).ShouldNot(Equal(0))
 }
 
+func TestGenerateAzblobCertEnvVar(t *testing.T) {
+	g := NewGomegaWithT(t)
+	azblob := &v1alpha1.AzblobStorageProvider{
+		AccessTier: "Cool",
+		SecretName: "azblob",
+	}
+	envNames := func(envs []corev1.EnvVar) []string {
+		var names []string
+		for _, env := range envs {
+			names = append(names, env.Name)
+		}
+		return names
+	}
+
+	// no credentials are set without the secret
+	envs := generateAzblobCertEnvVar(azblob, nil)
+	g.Expect(envNames(envs)).Should(Equal([]string{"AZURE_ACCESS_TIER"}))
+	g.Expect(envs[0].Value).Should(Equal("Cool"))
+
+	// the account key is used if there is no SAS token
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			constants.AzblobAccountName: []byte("dummy"),
+			constants.AzblobAccountKey:  []byte("dummy"),
+		},
+	}
+	envs = generateAzblobCertEnvVar(azblob, secret)
+	g.Expect(envNames(envs)).Should(Equal([]string{"AZURE_ACCESS_TIER", "AZURE_STORAGE_ACCOUNT", "AZURE_STORAGE_KEY"}))
+
+	// the SAS token is preferred
+	secret.Data[constants.AzblobSASToken] = []byte("dummy")
+	envs = generateAzblobCertEnvVar(azblob, secret)
+	g.Expect(envNames(envs)).Should(Equal([]string{"AZURE_ACCESS_TIER", "AZURE_STORAGE_ACCOUNT", "AZURE_STORAGE_SAS_TOKEN"}))
+	g.Expect(envs[2].ValueFrom.SecretKeyRef.Key).Should(Equal(constants.AzblobSASToken))
+}
+
 func TestGenerateStorageCertEnv(t *testing.T) {
 	g := NewGomegaWithT(t)
 	ns := "ns"
@@ -180,6 +218,13 @@ func TestGenerateStorageCertEnv(t *testing.T) {
 				},
 			},
 		},
+		{
+			provider: v1alpha1.StorageProvider{
+				Azblob: &v1alpha1.AzblobStorageProvider{
+					SecretName: secretName,
+				},
+			},
+		},
 		{
 			provider: v1alpha1.StorageProvider{},
 		},
@@ -225,6 +270,8 @@ func TestGenerateStorageCertEnv(t *testing.T) {
 			constants.GcsCredentialsKey: []byte("dummy"),
 			constants.S3AccessKey:       []byte("dummy"),
 			constants.S3SecretKey:       []byte("dummy"),
+			constants.AzblobAccountName: []byte("dummy"),
+			constants.AzblobAccountKey:  []byte("dummy"),
 		}
 		err = informer.Core().V1().Secrets().Informer().GetIndexer().Update(s)
 		g.Expect(err).Should(BeNil())
@@ -300,6 +347,19 @@ func TestGetBackupBucketAdnPrefixName(t *testing.T) {
 			},
 			name: "gcs",
 		},
+		{
+			backup: &v1alpha1.Backup{
+				Spec: v1alpha1.BackupSpec{
+					StorageProvider: v1alpha1.StorageProvider{
+						Azblob: &v1alpha1.AzblobStorageProvider{
+							Container: "azblob",
+							Prefix:    "azblob",
+						},
+					},
+				},
+			},
+			name: "azblob",
+		},
 		{
 			backup: &v1alpha1.Backup{},
 			name:   "",
@@ -364,6 +424,14 @@ func TestGetBackupDataPath(t *testing.T) {
 			},
 			name: "gcs://host",
 		},
+		{
+			provider: v1alpha1.StorageProvider{
+				Azblob: &v1alpha1.AzblobStorageProvider{
+					Path: "host",
+				},
+			},
+			name: "azure://host",
+		},
 		{
 			provider: v1alpha1.StorageProvider{},
 			name:     "",
@@ -408,8 +476,20 @@ func TestValidateBackup(t *testing.T) {
 	backup.Spec.StorageSize = "1m"
 	match("")
 
+	backup.Spec.Dumpling = &v1alpha1.DumplingConfig{ShardCount: -1}
+	match("invalid shard count -1 for dumpling")
+	backup.Spec.Dumpling.ShardCount = 4
+	match("")
+
+	backup.Spec.Mode = v1alpha1.BackupModeLog
+	match("log mode is only supported by BR")
+	backup.Spec.Mode = ""
+
 	// start BR != nil case
 	backup.Spec.BR = &v1alpha1.BRConfig{}
+	match("shard count is only supported by dumpling")
+
+	backup.Spec.Dumpling = nil
 	match("cluster should be configured for BR in spec")
 
 	backup.Spec.BR.Cluster = "tidb"
@@ -424,6 +504,11 @@ func TestValidateBackup(t *testing.T) {
 	match("table should be configured for BR with backup type table in spec of")
 
 	backup.Spec.BR.Table = "tableName"
+	concurrency := uint32(0)
+	backup.Spec.BR.Concurrency = &concurrency
+	match("concurrency should be greater than 0 for BR")
+
+	concurrency = 4
 	backup.Spec.S3 = &v1alpha1.S3StorageProvider{}
 	match("bucket should be configured for BR in spec of")
 
@@ -439,6 +524,29 @@ func TestValidateBackup(t *testing.T) {
 
 	backup.Spec.S3.Endpoint = "s3://localhost:80"
 	match("")
+
+	backup.Spec.S3 = nil
+	backup.Spec.Azblob = &v1alpha1.AzblobStorageProvider{}
+	match("container should be configured for BR in spec of")
+
+	backup.Spec.Azblob.Container = "container"
+	backup.Spec.Azblob.AccessTier = "invalid"
+	match("invalid access tier")
+
+	backup.Spec.Azblob.AccessTier = "Cool"
+	match("")
+
+	backup.Spec.LogTruncateUntil = "434319917412892673"
+	match("log truncate until is only valid for log mode")
+
+	backup.Spec.Mode = v1alpha1.BackupModeLog
+	match("invalid backup type table for log mode")
+
+	backup.Spec.Type = v1alpha1.BackupTypeFull
+	match("")
+
+	backup.Spec.LogTruncateUntil = "yesterday"
+	match("invalid log truncate until")
 }
 
 func TestValidateRestore(t *testing.T) {
@@ -499,6 +607,119 @@ func TestValidateRestore(t *testing.T) {
 
 	restore.Spec.S3.Endpoint = "s3://localhost:80"
 	match("")
+
+	restore.Spec.Statistics = &v1alpha1.RestoreStatisticsSpec{Analyze: true, Concurrency: pointer.Int32Ptr(0)}
+	match("statistics concurrency should be positive")
+
+	restore.Spec.Statistics.Concurrency = pointer.Int32Ptr(2)
+	match("")
+
+	restore.Spec.TiFlashReplica = &v1alpha1.RestoreTiFlashReplicaSpec{
+		Tables: []v1alpha1.TiFlashReplicaTable{{Replicas: 1}},
+	}
+	match("db should be configured for the tiflash replicas")
+
+	restore.Spec.TiFlashReplica.Tables[0] = v1alpha1.TiFlashReplicaTable{DB: "app", Replicas: -1}
+	match("tiflash replicas of app should not be negative")
+
+	restore.Spec.TiFlashReplica.Tables[0].Replicas = 2
+	restore.Spec.TiFlashReplica.WaitTimeout = "forever"
+	match("invalid tiflash replica wait timeout forever")
+
+	restore.Spec.TiFlashReplica.WaitTimeout = "30m"
+	match("")
+
+	restore.Spec.Mode = v1alpha1.RestoreModePiTR
+	match("log backup should be configured for pitr mode")
+
+	restore.Spec.PiTR = &v1alpha1.PiTRRestoreSpec{LogBackup: "log-backup", RestoredTs: "yesterday"}
+	match("invalid restore type table for pitr mode")
+
+	restore.Spec.Type = v1alpha1.BackupTypeFull
+	match("invalid restored ts for pitr mode")
+
+	restore.Spec.PiTR.RestoredTs = "2022-07-15T19:00:00+08:00"
+	match("")
+
+	restore.Spec.PiTR.RestoredTs = "434319917412892673"
+	match("")
+
+	restore.Spec.To = nil
+	match("analyzing statistics requires the access config: missing cluster config in spec of")
+
+	restore.Spec.Statistics = nil
+	match("setting the tiflash replicas requires the access config: missing cluster config in spec of")
+
+	restore.Spec.TiFlashReplica = nil
+	restore.Spec.Force = true
+	match("dropping the conflicting tables requires the access config: missing cluster config in spec of")
+}
+
+func TestGetRestoreConflicts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tables := map[string][]string{
+		"mysql": {"user"},
+		"app":   {"users", "orders", "orders_archive"},
+		"Logs":  {"access"},
+		"empty": {},
+	}
+	restore := &v1alpha1.Restore{}
+	restore.Spec.BR = &v1alpha1.BRConfig{}
+
+	// all the user tables are in the restore set of a full restore
+	g.Expect(GetRestoreConflicts(restore, tables)).Should(Equal([]string{"Logs.access", "app.orders", "app.orders_archive", "app.users"}))
+
+	restore.Spec.TableFilter = []string{"app.*", "!app.orders_*", "logs.acc?ss"}
+	g.Expect(GetRestoreConflicts(restore, tables)).Should(Equal([]string{"Logs.access", "app.orders", "app.users"}))
+
+	restore.Spec.TableFilter = []string{"other.*"}
+	g.Expect(GetRestoreConflicts(restore, tables)).Should(BeEmpty())
+
+	restore.Spec.Type = v1alpha1.BackupTypeDB
+	restore.Spec.BR.DB = "logs"
+	g.Expect(GetRestoreConflicts(restore, tables)).Should(Equal([]string{"Logs.access"}))
+
+	restore.Spec.Type = v1alpha1.BackupTypeTable
+	restore.Spec.BR.DB = "app"
+	restore.Spec.BR.Table = "orders"
+	g.Expect(GetRestoreConflicts(restore, tables)).Should(Equal([]string{"app.orders"}))
+}
+
+func TestParseRestoredTs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tso, err := ParseRestoredTs("434319917412892673")
+	g.Expect(err).Should(BeNil())
+	g.Expect(tso).Should(Equal(uint64(434319917412892673)))
+
+	tso, err = ParseRestoredTs("2022-07-15T19:00:00+08:00")
+	g.Expect(err).Should(BeNil())
+	g.Expect(tso).Should(Equal(uint64(1657882800000) << 18))
+	g.Expect(TSOToTime(tso).Equal(time.Date(2022, 7, 15, 11, 0, 0, 0, time.UTC))).Should(BeTrue())
+
+	_, err = ParseRestoredTs("")
+	g.Expect(err).ShouldNot(BeNil())
+
+	_, err = ParseRestoredTs("2022-07-15 19:00:00")
+	g.Expect(err).ShouldNot(BeNil())
+}
+
+func TestNeedLogTruncate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	backup := &v1alpha1.Backup{}
+	backup.Spec.LogTruncateUntil = "434319917412892673"
+	g.Expect(NeedLogTruncate(backup)).To(BeFalse())
+
+	backup.Spec.Mode = v1alpha1.BackupModeLog
+	g.Expect(NeedLogTruncate(backup)).To(BeTrue())
+
+	backup.Status.LogTruncatedUntil = "434319917412892673"
+	g.Expect(NeedLogTruncate(backup)).To(BeFalse())
+
+	backup.Spec.LogTruncateUntil = TSOToTime(434319917412892673).Add(time.Hour).Format(time.RFC3339)
+	g.Expect(NeedLogTruncate(backup)).To(BeTrue())
 }
 
 func TestGetImageTag(t *testing.T) {
@@ -558,3 +779,94 @@ func TestGetImageTag(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOriginalTikvGCLifeTime(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newBackup := func(name, host string, state v1alpha1.TikvGCLifeTimeState, original string, conditions ...v1alpha1.BackupConditionType) *v1alpha1.Backup {
+		backup := &v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec: v1alpha1.BackupSpec{
+				From: &v1alpha1.TiDBAccessConfig{Host: host},
+			},
+		}
+		if state != "" {
+			backup.Status.TikvGCLifeTime = &v1alpha1.TikvGCLifeTimeStatus{
+				Original: original,
+				Adjusted: "72h",
+				State:    state,
+			}
+		}
+		for _, c := range conditions {
+			v1alpha1.UpdateBackupCondition(&backup.Status, &v1alpha1.BackupCondition{Type: c, Status: corev1.ConditionTrue})
+		}
+		return backup
+	}
+
+	tests := []struct {
+		name             string
+		backup           *v1alpha1.Backup
+		backups          []*v1alpha1.Backup
+		expectedOriginal string
+		expectedAdjusted bool
+		// whether the tikv_gc_life_time is adjusted by other unfinished backups
+		expectedAdjustedByOthers bool
+	}{
+		{
+			name:   "not adjusted",
+			backup: newBackup("bk", "tidb", "", ""),
+			backups: []*v1alpha1.Backup{
+				newBackup("other-cluster", "another-tidb", v1alpha1.TikvGCLifeTimeAdjusted, "10m", v1alpha1.BackupRunning),
+				newBackup("finished", "tidb", v1alpha1.TikvGCLifeTimeAdjusted, "10m", v1alpha1.BackupRunning, v1alpha1.BackupComplete),
+				newBackup("restored", "tidb", v1alpha1.TikvGCLifeTimeRestored, "10m", v1alpha1.BackupRunning),
+			},
+		},
+		{
+			name:             "adjusted by an interrupted run",
+			backup:           newBackup("bk", "tidb", v1alpha1.TikvGCLifeTimeAdjusted, "10m", v1alpha1.BackupRunning),
+			expectedOriginal: "10m",
+			expectedAdjusted: true,
+		},
+		{
+			name:   "adjusted by another running backup",
+			backup: newBackup("bk", "tidb", "", ""),
+			backups: []*v1alpha1.Backup{
+				newBackup("running", "tidb", v1alpha1.TikvGCLifeTimeAdjusted, "20m", v1alpha1.BackupRunning),
+			},
+			expectedOriginal:         "20m",
+			expectedAdjusted:         true,
+			expectedAdjustedByOthers: true,
+		},
+		{
+			name:   "adjusted by another failed backup",
+			backup: newBackup("bk", "tidb", "", ""),
+			backups: []*v1alpha1.Backup{
+				newBackup("failed", "tidb", v1alpha1.TikvGCLifeTimeAdjusted, "30m", v1alpha1.BackupRunning, v1alpha1.BackupFailed),
+			},
+		},
+		{
+			name:   "adjusted by another backup not running",
+			backup: newBackup("bk", "tidb", "", ""),
+			backups: []*v1alpha1.Backup{
+				newBackup("scheduled", "tidb", v1alpha1.TikvGCLifeTimeAdjusted, "30m", v1alpha1.BackupScheduled),
+			},
+		},
+		{
+			name:   "adjusted by another running backup and a failed backup",
+			backup: newBackup("bk", "tidb", "", ""),
+			backups: []*v1alpha1.Backup{
+				newBackup("failed", "tidb", v1alpha1.TikvGCLifeTimeAdjusted, "30m", v1alpha1.BackupRunning, v1alpha1.BackupFailed),
+				newBackup("running", "tidb", v1alpha1.TikvGCLifeTimeAdjusted, "20m", v1alpha1.BackupRunning),
+			},
+			expectedOriginal:         "20m",
+			expectedAdjusted:         true,
+			expectedAdjustedByOthers: true,
+		},
+	}
+	for _, tt := range tests {
+		original, ok := GetOriginalTikvGCLifeTime(tt.backup, tt.backups)
+		g.Expect(ok).To(Equal(tt.expectedAdjusted), tt.name)
+		g.Expect(original).To(Equal(tt.expectedOriginal), tt.name)
+		g.Expect(IsTikvGCLifeTimeAdjustedByOthers(tt.backup, tt.backups)).To(Equal(tt.expectedAdjustedByOthers), tt.name)
+	}
+}
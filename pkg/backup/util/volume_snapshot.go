@@ -0,0 +1,74 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// VolumeSnapshotMetaKey is the key of the meta in the ConfigMap of a volume-snapshot Backup. The ConfigMap
+// doesn't carry the labels of the selector of the operator, so it's read by the unfiltered ConfigMap lister.
+const VolumeSnapshotMetaKey = "meta.json"
+
+// VolumeSnapshotMeta is the manifest of a volume-snapshot Backup, it records the snapshots of the PVCs
+// and how to recreate the PVCs from them
+type VolumeSnapshotMeta struct {
+	ClusterName      string `json:"clusterName"`
+	ClusterNamespace string `json:"clusterNamespace"`
+	// Version is the version of TiKV of the cluster when the snapshots are taken
+	Version string `json:"version,omitempty"`
+	// ResolvedTS is the min resolved ts of the cluster before the snapshots are taken, the data of TiKV is reset
+	// to it after the volumes are restored, as the snapshots of the volumes are cut at different points in time
+	ResolvedTS uint64                 `json:"resolvedTS,omitempty"`
+	Volumes    []VolumeSnapshotVolume `json:"volumes"`
+}
+
+// VolumeSnapshotVolume is the snapshot of a PVC
+type VolumeSnapshotVolume struct {
+	// Component is the component of the PVC, pd or tikv
+	Component        string                              `json:"component"`
+	PodName          string                              `json:"podName,omitempty"`
+	PVCName          string                              `json:"pvcName"`
+	SnapshotName     string                              `json:"snapshotName"`
+	StorageClassName *string                             `json:"storageClassName,omitempty"`
+	AccessModes      []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// Storage is the requested storage of the PVC
+	Storage string            `json:"storage"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// EncodeVolumeSnapshotMeta encodes the meta to the data of the ConfigMap
+func EncodeVolumeSnapshotMeta(meta *VolumeSnapshotMeta) (map[string]string, error) {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{VolumeSnapshotMetaKey: string(data)}, nil
+}
+
+// DecodeVolumeSnapshotMeta decodes the meta from the data of the ConfigMap
+func DecodeVolumeSnapshotMeta(cm *corev1.ConfigMap) (*VolumeSnapshotMeta, error) {
+	data, ok := cm.Data[VolumeSnapshotMetaKey]
+	if !ok {
+		return nil, fmt.Errorf("%s is not found in configmap %s/%s", VolumeSnapshotMetaKey, cm.Namespace, cm.Name)
+	}
+	meta := &VolumeSnapshotMeta{}
+	if err := json.Unmarshal([]byte(data), meta); err != nil {
+		return nil, fmt.Errorf("failed to decode the meta in configmap %s/%s, error: %v", cm.Namespace, cm.Name, err)
+	}
+	return meta, nil
+}
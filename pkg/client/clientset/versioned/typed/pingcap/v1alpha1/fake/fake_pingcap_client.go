@@ -37,10 +37,18 @@ func (c *FakePingcapV1alpha1) DMClusters(namespace string) v1alpha1.DMClusterInt
 	return &FakeDMClusters{c, namespace}
 }
 
+func (c *FakePingcapV1alpha1) DMTasks(namespace string) v1alpha1.DMTaskInterface {
+	return &FakeDMTasks{c, namespace}
+}
+
 func (c *FakePingcapV1alpha1) DataResources(namespace string) v1alpha1.DataResourceInterface {
 	return &FakeDataResources{c, namespace}
 }
 
+func (c *FakePingcapV1alpha1) OpsCommands(namespace string) v1alpha1.OpsCommandInterface {
+	return &FakeOpsCommands{c, namespace}
+}
+
 func (c *FakePingcapV1alpha1) Restores(namespace string) v1alpha1.RestoreInterface {
 	return &FakeRestores{c, namespace}
 }
@@ -21,8 +21,12 @@ type BackupScheduleExpansion interface{}
 
 type DMClusterExpansion interface{}
 
+type DMTaskExpansion interface{}
+
 type DataResourceExpansion interface{}
 
+type OpsCommandExpansion interface{}
+
 type RestoreExpansion interface{}
 
 type TidbClusterExpansion interface{}
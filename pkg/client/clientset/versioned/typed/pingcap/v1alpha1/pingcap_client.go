@@ -26,7 +26,9 @@ type PingcapV1alpha1Interface interface {
 	BackupsGetter
 	BackupSchedulesGetter
 	DMClustersGetter
+	DMTasksGetter
 	DataResourcesGetter
+	OpsCommandsGetter
 	RestoresGetter
 	TidbClustersGetter
 	TidbClusterAutoScalersGetter
@@ -52,10 +54,18 @@ func (c *PingcapV1alpha1Client) DMClusters(namespace string) DMClusterInterface
 	return newDMClusters(c, namespace)
 }
 
+func (c *PingcapV1alpha1Client) DMTasks(namespace string) DMTaskInterface {
+	return newDMTasks(c, namespace)
+}
+
 func (c *PingcapV1alpha1Client) DataResources(namespace string) DataResourceInterface {
 	return newDataResources(c, namespace)
 }
 
+func (c *PingcapV1alpha1Client) OpsCommands(namespace string) OpsCommandInterface {
+	return newOpsCommands(c, namespace)
+}
+
 func (c *PingcapV1alpha1Client) Restores(namespace string) RestoreInterface {
 	return newRestores(c, namespace)
 }
@@ -56,8 +56,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().BackupSchedules().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("dmclusters"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().DMClusters().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("dmtasks"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().DMTasks().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("dataresources"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().DataResources().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("opscommands"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().OpsCommands().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("restores"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().Restores().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("tidbclusters"):
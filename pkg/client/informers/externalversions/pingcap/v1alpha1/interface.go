@@ -27,8 +27,12 @@ type Interface interface {
 	BackupSchedules() BackupScheduleInformer
 	// DMClusters returns a DMClusterInformer.
 	DMClusters() DMClusterInformer
+	// DMTasks returns a DMTaskInformer.
+	DMTasks() DMTaskInformer
 	// DataResources returns a DataResourceInformer.
 	DataResources() DataResourceInformer
+	// OpsCommands returns a OpsCommandInformer.
+	OpsCommands() OpsCommandInformer
 	// Restores returns a RestoreInformer.
 	Restores() RestoreInformer
 	// TidbClusters returns a TidbClusterInformer.
@@ -69,11 +73,21 @@ func (v *version) DMClusters() DMClusterInformer {
 	return &dMClusterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// DMTasks returns a DMTaskInformer.
+func (v *version) DMTasks() DMTaskInformer {
+	return &dMTaskInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // DataResources returns a DataResourceInformer.
 func (v *version) DataResources() DataResourceInformer {
 	return &dataResourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// OpsCommands returns a OpsCommandInformer.
+func (v *version) OpsCommands() OpsCommandInformer {
+	return &opsCommandInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Restores returns a RestoreInformer.
 func (v *version) Restores() RestoreInformer {
 	return &restoreInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
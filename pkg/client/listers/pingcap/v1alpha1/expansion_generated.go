@@ -39,6 +39,14 @@ type DMClusterListerExpansion interface{}
 // DMClusterNamespaceLister.
 type DMClusterNamespaceListerExpansion interface{}
 
+// DMTaskListerExpansion allows custom methods to be added to
+// DMTaskLister.
+type DMTaskListerExpansion interface{}
+
+// DMTaskNamespaceListerExpansion allows custom methods to be added to
+// DMTaskNamespaceLister.
+type DMTaskNamespaceListerExpansion interface{}
+
 // DataResourceListerExpansion allows custom methods to be added to
 // DataResourceLister.
 type DataResourceListerExpansion interface{}
@@ -47,6 +55,14 @@ type DataResourceListerExpansion interface{}
 // DataResourceNamespaceLister.
 type DataResourceNamespaceListerExpansion interface{}
 
+// OpsCommandListerExpansion allows custom methods to be added to
+// OpsCommandLister.
+type OpsCommandListerExpansion interface{}
+
+// OpsCommandNamespaceListerExpansion allows custom methods to be added to
+// OpsCommandNamespaceLister.
+type OpsCommandNamespaceListerExpansion interface{}
+
 // RestoreListerExpansion allows custom methods to be added to
 // RestoreLister.
 type RestoreListerExpansion interface{}
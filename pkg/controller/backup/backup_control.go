@@ -107,13 +107,21 @@ func (c *defaultBackupControl) removeProtectionFinalizer(backup *v1alpha1.Backup
 	return nil
 }
 
+// needToAddFinalizer returns whether the finalizer is required, the log backup always requires it
+// to stop the log backup task on deletion
 func needToAddFinalizer(backup *v1alpha1.Backup) bool {
-	return backup.DeletionTimestamp == nil && v1alpha1.IsCleanCandidate(backup) && !slice.ContainsString(backup.Finalizers, label.BackupProtectionFinalizer, nil)
+	return backup.DeletionTimestamp == nil && (v1alpha1.IsCleanCandidate(backup) || backup.IsLogBackup()) &&
+		!slice.ContainsString(backup.Finalizers, label.BackupProtectionFinalizer, nil)
 }
 
 func needToRemoveFinalizer(backup *v1alpha1.Backup) bool {
-	return v1alpha1.IsCleanCandidate(backup) && isDeletionCandidate(backup) &&
-		(v1alpha1.IsBackupClean(backup) || v1alpha1.NeedNotClean(backup))
+	if !isDeletionCandidate(backup) {
+		return false
+	}
+	if backup.IsLogBackup() && !v1alpha1.IsLogBackupStopped(backup) {
+		return false
+	}
+	return !v1alpha1.IsCleanCandidate(backup) || v1alpha1.IsBackupClean(backup) || v1alpha1.NeedNotClean(backup)
 }
 
 func isDeletionCandidate(backup *v1alpha1.Backup) bool {
@@ -21,7 +21,9 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/backup"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/notification"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -59,6 +61,7 @@ func NewController(deps *controller.Dependencies) *Controller {
 	backupInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.updateBackup,
 		UpdateFunc: func(old, cur interface{}) {
+			c.notifyFailure(old.(*v1alpha1.Backup), cur.(*v1alpha1.Backup))
 			c.updateBackup(cur)
 		},
 		DeleteFunc: c.updateBackup,
@@ -165,6 +168,11 @@ func (c *Controller) updateBackup(cur interface{}) {
 	}
 
 	if v1alpha1.IsBackupFailed(newBackup) {
+		if backuputil.NeedRestoreTikvGCLifeTime(newBackup) {
+			klog.Infof("backup %s/%s is Failed with tikv_gc_life_time adjusted, enqueue to restore it", ns, name)
+			c.enqueueBackup(newBackup)
+			return
+		}
 		klog.V(4).Infof("backup %s/%s is Failed, skipping.", ns, name)
 		return
 	}
@@ -196,6 +204,15 @@ func (c *Controller) updateBackup(cur interface{}) {
 				break
 			}
 		}
+		if newBackup.IsSharded() && v1alpha1.IsBackupShardsComplete(newBackup) {
+			// all the shards are complete, aggregate the status of them
+			klog.Infof("all the shards of backup %s/%s are complete", ns, name)
+			c.enqueueBackup(newBackup)
+		}
+		if backuputil.NeedLogTruncate(newBackup) {
+			klog.Infof("log backup %s/%s is going to be truncated until %s", ns, name, newBackup.Spec.LogTruncateUntil)
+			c.enqueueBackup(newBackup)
+		}
 		return
 	}
 
@@ -203,6 +220,27 @@ func (c *Controller) updateBackup(cur interface{}) {
 	c.enqueueBackup(newBackup)
 }
 
+// notifyFailure sends the BackupFailed notification when the backup transitions to Failed
+func (c *Controller) notifyFailure(old, cur *v1alpha1.Backup) {
+	if v1alpha1.IsBackupFailed(old) || !v1alpha1.IsBackupFailed(cur) {
+		return
+	}
+	_, condition := v1alpha1.GetBackupCondition(&cur.Status, v1alpha1.BackupFailed)
+	message := condition.Message
+	if message == "" {
+		message = condition.Reason
+	}
+	c.deps.Notifier.Notify(cur.Spec.Notifications, notification.Notification{
+		Type:      v1alpha1.NotificationEventBackupFailed,
+		Severity:  v1alpha1.NotificationSeverityCritical,
+		Kind:      v1alpha1.BackupKind,
+		Namespace: cur.Namespace,
+		Name:      cur.Name,
+		Message:   message,
+		Time:      condition.LastTransitionTime.Time,
+	})
+}
+
 func (c *Controller) deleteJob(obj interface{}) {
 	job, ok := obj.(*batchv1.Job)
 	if !ok {
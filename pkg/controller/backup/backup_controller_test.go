@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/notification"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -287,6 +288,36 @@ func TestBackupControllerSync(t *testing.T) {
 
 }
 
+func TestBackupControllerNotifyFailure(t *testing.T) {
+	g := NewGomegaWithT(t)
+	bkc, _, _ := newFakeBackupController()
+	notifier := bkc.deps.Notifier.(*notification.FakeNotifier)
+
+	old := newBackup()
+	old.Spec.Notifications = &v1alpha1.NotificationSpec{
+		Sinks: []v1alpha1.NotificationSink{{Name: "webhook", Webhook: &v1alpha1.WebhookNotificationSink{URL: "http://example.com"}}},
+	}
+	cur := old.DeepCopy()
+	v1alpha1.UpdateBackupCondition(&cur.Status, &v1alpha1.BackupCondition{
+		Type:    v1alpha1.BackupFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  "AlreadyFailed",
+		Message: "Pod test-backup-xxx has failed",
+	})
+
+	bkc.notifyFailure(old, old)
+	g.Expect(notifier.Notifications()).To(BeEmpty())
+	bkc.notifyFailure(old, cur)
+	g.Expect(notifier.Notifications()).To(HaveLen(1))
+	n := notifier.Notifications()[0]
+	g.Expect(n.Type).To(Equal(v1alpha1.NotificationEventBackupFailed))
+	g.Expect(n.Severity).To(Equal(v1alpha1.NotificationSeverityCritical))
+	g.Expect(n.Message).To(Equal("Pod test-backup-xxx has failed"))
+	// the backup has been failed before
+	bkc.notifyFailure(cur, cur)
+	g.Expect(notifier.Notifications()).To(HaveLen(1))
+}
+
 func newFakeBackupController() (*Controller, cache.Indexer, *FakeBackupControl) {
 	fakeDeps := controller.NewFakeDependencies()
 	bkc := NewController(fakeDeps)
@@ -29,6 +29,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
@@ -40,6 +41,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 )
 
@@ -47,6 +49,7 @@ import (
 type BackupControlInterface interface {
 	CreateBackup(backup *v1alpha1.Backup) (*v1alpha1.Backup, error)
 	DeleteBackup(backup *v1alpha1.Backup) error
+	TruncateLogBackup(backup *v1alpha1.Backup, truncateTSO uint64) error
 }
 
 type realBackupControl struct {
@@ -95,6 +98,30 @@ func (c *realBackupControl) DeleteBackup(backup *v1alpha1.Backup) error {
 	return err
 }
 
+// TruncateLogBackup sets the logTruncateUntil of the log backup, the log backup is truncated until the ts by the backup controller
+func (c *realBackupControl) TruncateLogBackup(backup *v1alpha1.Backup, truncateTSO uint64) error {
+	ns := backup.GetNamespace()
+	backupName := backup.GetName()
+
+	bsName := backup.GetLabels()[label.BackupScheduleLabelKey]
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updated, err := c.cli.PingcapV1alpha1().Backups(ns).Get(context.TODO(), backupName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		updated.Spec.LogTruncateUntil = strconv.FormatUint(truncateTSO, 10)
+		_, err = c.cli.PingcapV1alpha1().Backups(ns).Update(context.TODO(), updated, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		klog.Errorf("failed to truncate log Backup: [%s/%s] until %d for backupSchedule/%s, err: %v", ns, backupName, truncateTSO, bsName, err)
+	} else {
+		klog.V(4).Infof("truncate log Backup: [%s/%s] until %d for backupSchedule/%s successfully", ns, backupName, truncateTSO, bsName)
+	}
+	c.recordBackupEvent("truncate", backup, err)
+	return err
+}
+
 func (c *realBackupControl) recordBackupEvent(verb string, backup *v1alpha1.Backup, err error) {
 	backupName := backup.GetName()
 	ns := backup.GetNamespace()
@@ -165,4 +192,11 @@ func (fbc *FakeBackupControl) DeleteBackup(backup *v1alpha1.Backup) error {
 	return fbc.backupIndexer.Delete(backup)
 }
 
+// TruncateLogBackup sets the logTruncateUntil of the backup in BackupIndexer
+func (fbc *FakeBackupControl) TruncateLogBackup(backup *v1alpha1.Backup, truncateTSO uint64) error {
+	backup = backup.DeepCopy()
+	backup.Spec.LogTruncateUntil = strconv.FormatUint(truncateTSO, 10)
+	return fbc.backupIndexer.Update(backup)
+}
+
 var _ BackupControlInterface = &FakeBackupControl{}
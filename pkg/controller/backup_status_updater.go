@@ -47,6 +47,22 @@ type BackupUpdateStatus struct {
 	BackupSize *int64
 	// CommitTs is the snapshot time point of tidb cluster.
 	CommitTs *string
+	// LogTruncatedUntil is the ts the log backup is truncated until.
+	LogTruncatedUntil *string
+	// TikvGCLifeTime is the tikv_gc_life_time adjusted for the backup.
+	TikvGCLifeTime *v1alpha1.TikvGCLifeTimeStatus
+	// Shard is the status of a shard of the backup, it is merged into `Shards` by the index.
+	Shard *v1alpha1.BackupShardStatus
+	// ProgressStep is the step of the backup whose progress is reported.
+	ProgressStep *string
+	// Progress is the progress of the step.
+	Progress *float64
+	// ProgressUpdateTime is the time at which the progress was updated.
+	ProgressUpdateTime *metav1.Time
+	// ProgressEstimatedCompletionTime is the time at which the step is estimated to complete.
+	ProgressEstimatedCompletionTime *metav1.Time
+	// VolumeSnapshotRetries is the number of the failed attempts to cut the volume snapshots.
+	VolumeSnapshotRetries *int32
 }
 
 // BackupConditionUpdaterInterface enables updating Backup conditions.
@@ -77,8 +93,8 @@ func (u *realBackupConditionUpdater) Update(backup *v1alpha1.Backup, condition *
 	backupName := backup.GetName()
 	var isUpdate bool
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		updateBackupStatus(&backup.Status, newStatus)
-		isUpdate = v1alpha1.UpdateBackupCondition(&backup.Status, condition)
+		isStatusUpdate := updateBackupStatus(&backup.Status, newStatus)
+		isUpdate = v1alpha1.UpdateBackupCondition(&backup.Status, condition) || isStatusUpdate
 		if isUpdate {
 			_, updateErr := u.cli.PingcapV1alpha1().Backups(ns).Update(context.TODO(), backup, metav1.UpdateOptions{})
 			if updateErr == nil {
@@ -100,10 +116,11 @@ func (u *realBackupConditionUpdater) Update(backup *v1alpha1.Backup, condition *
 }
 
 // updateBackupStatus updates existing Backup status
-// from the fields in BackupUpdateStatus.
-func updateBackupStatus(status *v1alpha1.BackupStatus, newStatus *BackupUpdateStatus) {
+// from the fields in BackupUpdateStatus, and returns
+// whether the fields that must be persisted immediately are changed.
+func updateBackupStatus(status *v1alpha1.BackupStatus, newStatus *BackupUpdateStatus) bool {
 	if newStatus == nil {
-		return
+		return false
 	}
 	if newStatus.BackupPath != nil {
 		status.BackupPath = *newStatus.BackupPath
@@ -123,6 +140,61 @@ func updateBackupStatus(status *v1alpha1.BackupStatus, newStatus *BackupUpdateSt
 	if newStatus.CommitTs != nil {
 		status.CommitTs = *newStatus.CommitTs
 	}
+	// the adjusted tikv_gc_life_time is persisted even if the condition is not changed,
+	// otherwise it can not be restored if the backup is interrupted
+	isUpdate := false
+	if newStatus.TikvGCLifeTime != nil {
+		isUpdate = status.TikvGCLifeTime == nil || *status.TikvGCLifeTime != *newStatus.TikvGCLifeTime
+		status.TikvGCLifeTime = newStatus.TikvGCLifeTime.DeepCopy()
+	}
+	// the log backup is truncated without changing the condition of the backup
+	if newStatus.LogTruncatedUntil != nil {
+		isUpdate = status.LogTruncatedUntil != *newStatus.LogTruncatedUntil || isUpdate
+		status.LogTruncatedUntil = *newStatus.LogTruncatedUntil
+	}
+	// the shards are updated by their own Jobs without changing the condition of the backup
+	if newStatus.Shard != nil {
+		isUpdate = v1alpha1.UpdateBackupShardStatus(status, newStatus.Shard) || isUpdate
+	}
+	// the progress is persisted even if the condition is not changed,
+	// as it changes while the backup is running
+	if newStatus.ProgressStep != nil && newStatus.Progress != nil {
+		isUpdate = updateBackupProgress(status, *newStatus.ProgressStep, *newStatus.Progress, newStatus.ProgressUpdateTime, newStatus.ProgressEstimatedCompletionTime) || isUpdate
+	}
+	// the retries are counted even if the RetryFailed condition is not changed
+	if newStatus.VolumeSnapshotRetries != nil {
+		isUpdate = status.VolumeSnapshotRetries != *newStatus.VolumeSnapshotRetries || isUpdate
+		status.VolumeSnapshotRetries = *newStatus.VolumeSnapshotRetries
+	}
+	return isUpdate
+}
+
+// updateBackupProgress updates the progress of the step in the Backup status,
+// and returns whether the progress is changed.
+func updateBackupProgress(status *v1alpha1.BackupStatus, step string, progress float64, updateTime, estimatedCompletionTime *metav1.Time) bool {
+	transitionTime := metav1.Now()
+	if updateTime != nil {
+		transitionTime = *updateTime
+	}
+	for i := range status.Progresses {
+		if status.Progresses[i].Step != step {
+			continue
+		}
+		if status.Progresses[i].Progress == progress {
+			return false
+		}
+		status.Progresses[i].Progress = progress
+		status.Progresses[i].LastTransitionTime = transitionTime
+		status.Progresses[i].EstimatedCompletionTime = estimatedCompletionTime.DeepCopy()
+		return true
+	}
+	status.Progresses = append(status.Progresses, v1alpha1.BackupProgress{
+		Step:                    step,
+		Progress:                progress,
+		LastTransitionTime:      transitionTime,
+		EstimatedCompletionTime: estimatedCompletionTime.DeepCopy(),
+	})
+	return true
 }
 
 var _ BackupConditionUpdaterInterface = &realBackupConditionUpdater{}
@@ -29,6 +29,7 @@ func TestUpdateBackupStatus(t *testing.T) {
 		status       *v1alpha1.BackupStatus
 		updateStatus *BackupUpdateStatus
 		expectStatus *v1alpha1.BackupStatus
+		expectUpdate bool
 	}{
 		{
 			name:         "updateStatus is nil",
@@ -48,12 +49,115 @@ func TestUpdateBackupStatus(t *testing.T) {
 			updateStatus: newUpdateBackupStatus(),
 			expectStatus: newExpectBackupStatus(),
 		},
+		{
+			name:   "tikv gc life time is adjusted",
+			status: newBackupStatus(),
+			updateStatus: &BackupUpdateStatus{
+				TikvGCLifeTime: &v1alpha1.TikvGCLifeTimeStatus{Original: "10m", Adjusted: "72h", State: v1alpha1.TikvGCLifeTimeAdjusted},
+			},
+			expectStatus: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.TikvGCLifeTime = &v1alpha1.TikvGCLifeTimeStatus{Original: "10m", Adjusted: "72h", State: v1alpha1.TikvGCLifeTimeAdjusted}
+				return s
+			}(),
+			expectUpdate: true,
+		},
+		{
+			name:   "shard is added",
+			status: newBackupStatus(),
+			updateStatus: &BackupUpdateStatus{
+				Shard: &v1alpha1.BackupShardStatus{Index: 1, Phase: v1alpha1.BackupRunning},
+			},
+			expectStatus: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Shards = []v1alpha1.BackupShardStatus{{Index: 1, Phase: v1alpha1.BackupRunning}}
+				return s
+			}(),
+			expectUpdate: true,
+		},
+		{
+			name: "shards are sorted by the index",
+			status: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Shards = []v1alpha1.BackupShardStatus{{Index: 1, Phase: v1alpha1.BackupRunning}}
+				return s
+			}(),
+			updateStatus: &BackupUpdateStatus{
+				Shard: &v1alpha1.BackupShardStatus{Index: 0, Phase: v1alpha1.BackupComplete, BackupSize: 1024},
+			},
+			expectStatus: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Shards = []v1alpha1.BackupShardStatus{
+					{Index: 0, Phase: v1alpha1.BackupComplete, BackupSize: 1024},
+					{Index: 1, Phase: v1alpha1.BackupRunning},
+				}
+				return s
+			}(),
+			expectUpdate: true,
+		},
+		{
+			name: "shard is not changed",
+			status: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Shards = []v1alpha1.BackupShardStatus{{Index: 0, Phase: v1alpha1.BackupRunning}}
+				return s
+			}(),
+			updateStatus: &BackupUpdateStatus{
+				Shard: &v1alpha1.BackupShardStatus{Index: 0, Phase: v1alpha1.BackupRunning},
+			},
+			expectStatus: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Shards = []v1alpha1.BackupShardStatus{{Index: 0, Phase: v1alpha1.BackupRunning}}
+				return s
+			}(),
+		},
+		{
+			name:         "progress of a new step",
+			status:       newBackupStatus(),
+			updateStatus: newBackupProgressStatus("Full Backup", 10),
+			expectStatus: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Progresses = []v1alpha1.BackupProgress{newBackupProgress("Full Backup", 10)}
+				return s
+			}(),
+			expectUpdate: true,
+		},
+		{
+			name: "progress of an existing step",
+			status: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Progresses = []v1alpha1.BackupProgress{newBackupProgress("Full Backup", 100), newBackupProgress("Checksum", 10)}
+				return s
+			}(),
+			updateStatus: newBackupProgressStatus("Checksum", 50),
+			expectStatus: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Progresses = []v1alpha1.BackupProgress{newBackupProgress("Full Backup", 100), newBackupProgress("Checksum", 50)}
+				return s
+			}(),
+			expectUpdate: true,
+		},
+		{
+			name: "progress is not changed",
+			status: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Progresses = []v1alpha1.BackupProgress{newBackupProgress("Full Backup", 10)}
+				return s
+			}(),
+			updateStatus: newBackupProgressStatus("Full Backup", 10),
+			expectStatus: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.Progresses = []v1alpha1.BackupProgress{newBackupProgress("Full Backup", 10)}
+				return s
+			}(),
+		},
 	}
 
 	for _, test := range tests {
 		t.Logf("test: %+v", test.name)
-		updateBackupStatus(test.status, test.updateStatus)
+		isUpdate := updateBackupStatus(test.status, test.updateStatus)
 		g.Expect(*test.status).Should(Equal(*test.expectStatus))
+		g.Expect(isUpdate).Should(Equal(test.expectUpdate))
 	}
 }
 
@@ -105,6 +209,29 @@ func newBackupStatus() *v1alpha1.BackupStatus {
 		BackupSize:         size,
 	}
 }
+
+func newBackupProgressStatus(step string, progress float64) *BackupUpdateStatus {
+	updateTime, _ := time.Parse(time.RFC3339, "2020-12-25T21:48:59Z")
+	estimatedTime, _ := time.Parse(time.RFC3339, "2020-12-25T21:58:59Z")
+	return &BackupUpdateStatus{
+		ProgressStep:                    &step,
+		Progress:                        &progress,
+		ProgressUpdateTime:              &metav1.Time{Time: updateTime},
+		ProgressEstimatedCompletionTime: &metav1.Time{Time: estimatedTime},
+	}
+}
+
+func newBackupProgress(step string, progress float64) v1alpha1.BackupProgress {
+	updateTime, _ := time.Parse(time.RFC3339, "2020-12-25T21:48:59Z")
+	estimatedTime, _ := time.Parse(time.RFC3339, "2020-12-25T21:58:59Z")
+	return v1alpha1.BackupProgress{
+		Step:                    step,
+		Progress:                progress,
+		LastTransitionTime:      metav1.Time{Time: updateTime},
+		EstimatedCompletionTime: &metav1.Time{Time: estimatedTime},
+	}
+}
+
 func newExpectBackupStatus() *v1alpha1.BackupStatus {
 	ts := "421762809912885269"
 	start, _ := time.Parse(time.RFC3339, "2020-12-25T21:46:59Z")
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/backupschedule"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 	"k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -121,6 +122,7 @@ func (c *Controller) sync(key string) error {
 	bs, err := c.deps.BackupScheduleLister.BackupSchedules(ns).Get(name)
 	if errors.IsNotFound(err) {
 		klog.Infof("BackupSchedule has been deleted %v", key)
+		metrics.DeleteBackupScheduleMetrics(ns, name)
 		return nil
 	}
 	if err != nil {
@@ -20,10 +20,12 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
@@ -134,6 +136,39 @@ func NewFakeConfigMapControl(cmInformer coreinformers.ConfigMapInformer) *FakeCo
 	}
 }
 
+// configMapClientLister reads the ConfigMaps from the Kubernetes API server instead of an informer. It's used for
+// the ConfigMaps not managed by the operator, which are only read on demand, so that all the ConfigMaps in the
+// watched namespaces are not cached.
+type configMapClientLister struct {
+	kubeCli   kubernetes.Interface
+	namespace string
+}
+
+// NewConfigMapClientLister returns a ConfigMapLister reading the ConfigMaps from the Kubernetes API server
+func NewConfigMapClientLister(kubeCli kubernetes.Interface) corelisterv1.ConfigMapLister {
+	return &configMapClientLister{kubeCli: kubeCli}
+}
+
+func (l *configMapClientLister) List(selector labels.Selector) ([]*corev1.ConfigMap, error) {
+	list, err := l.kubeCli.CoreV1().ConfigMaps(l.namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	cms := make([]*corev1.ConfigMap, 0, len(list.Items))
+	for i := range list.Items {
+		cms = append(cms, &list.Items[i])
+	}
+	return cms, nil
+}
+
+func (l *configMapClientLister) ConfigMaps(namespace string) corelisterv1.ConfigMapNamespaceLister {
+	return &configMapClientLister{kubeCli: l.kubeCli, namespace: namespace}
+}
+
+func (l *configMapClientLister) Get(name string) (*corev1.ConfigMap, error) {
+	return l.kubeCli.CoreV1().ConfigMaps(l.namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
 // FakeConfigMapControl is a fake ConfigMapControlInterface
 type FakeConfigMapControl struct {
 	CmIndexer              cache.Indexer
@@ -185,8 +220,14 @@ func (c *FakeConfigMapControl) UpdateConfigMap(_ runtime.Object, cm *corev1.Conf
 }
 
 // DeleteConfigMap deletes the ConfigMap of CmIndexer
-func (c *FakeConfigMapControl) DeleteConfigMap(_ runtime.Object, _ *corev1.ConfigMap) error {
-	return nil
+func (c *FakeConfigMapControl) DeleteConfigMap(_ runtime.Object, cm *corev1.ConfigMap) error {
+	defer c.deleteConfigMapTracker.Inc()
+	if c.deleteConfigMapTracker.ErrorReady() {
+		defer c.deleteConfigMapTracker.Reset()
+		return c.deleteConfigMapTracker.GetError()
+	}
+
+	return c.CmIndexer.Delete(cm)
 }
 
 func (c *FakeConfigMapControl) GetConfigMap(controller runtime.Object, cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
@@ -21,6 +21,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
@@ -140,6 +141,27 @@ func TestConfigMapControlDeleteConfigMapFailed(t *testing.T) {
 	g.Expect(events[0]).To(ContainSubstring(corev1.EventTypeWarning))
 }
 
+func TestConfigMapClientLister(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cm := newConfigMap()
+	cm.Labels = map[string]string{"app": "demo"}
+	fakeClient := fake.NewSimpleClientset(cm)
+	lister := NewConfigMapClientLister(fakeClient)
+
+	got, err := lister.ConfigMaps(cm.Namespace).Get(cm.Name)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got.Data).To(Equal(cm.Data))
+	_, err = lister.ConfigMaps("other").Get(cm.Name)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+	cms, err := lister.List(labels.SelectorFromSet(labels.Set{"app": "demo"}))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cms).To(HaveLen(1))
+	cms, err = lister.ConfigMaps(cm.Namespace).List(labels.SelectorFromSet(labels.Set{"app": "other"}))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cms).To(BeEmpty())
+}
+
 func newConfigMap() *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
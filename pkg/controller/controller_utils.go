@@ -60,6 +60,9 @@ var (
 
 	// tidbNGMonitoringKind cotnains the schema.GroupVersionKind for TidbNGMonitoring controller type.
 	tidbNGMonitoringKind = v1alpha1.SchemeGroupVersion.WithKind("TidbNGMonitoring")
+
+	// opsCommandKind contains the schema.GroupVersionKind for OpsCommand controller type.
+	opsCommandKind = v1alpha1.SchemeGroupVersion.WithKind("OpsCommand")
 )
 
 // RequeueError is used to requeue the item, this error type should't be considered as a real error
@@ -211,6 +214,19 @@ func GetTiDBNGMonitoringOwnerRef(tngm *v1alpha1.TidbNGMonitoring) metav1.OwnerRe
 	}
 }
 
+func GetOpsCommandOwnerRef(oc *v1alpha1.OpsCommand) metav1.OwnerReference {
+	controller := true
+	blockOwnerDeletion := true
+	return metav1.OwnerReference{
+		APIVersion:         opsCommandKind.GroupVersion().String(),
+		Kind:               opsCommandKind.Kind,
+		Name:               oc.GetName(),
+		UID:                oc.GetUID(),
+		Controller:         &controller,
+		BlockOwnerDeletion: &blockOwnerDeletion,
+	}
+}
+
 // GetServiceType returns member's service type
 func GetServiceType(services []v1alpha1.Service, serviceName string) corev1.ServiceType {
 	for _, svc := range services {
@@ -269,11 +285,27 @@ func TiKVMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-tikv", clusterName)
 }
 
+// TiKVPerPodConfigMapName returns the name of the ConfigMap that stores the configuration files of the TiKV Pods
+// that have a configuration patch
+func TiKVPerPodConfigMapName(clusterName string) string {
+	return fmt.Sprintf("%s-tikv-per-pod", clusterName)
+}
+
 // TiKVPeerMemberName returns tikv peer service name
 func TiKVPeerMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-tikv-peer", clusterName)
 }
 
+// TiKVColdMemberName returns the member name of the tikv cold group
+func TiKVColdMemberName(clusterName string) string {
+	return fmt.Sprintf("%s-tikv-cold", clusterName)
+}
+
+// TiKVColdPeerMemberName returns the peer service name of the tikv cold group
+func TiKVColdPeerMemberName(clusterName string) string {
+	return fmt.Sprintf("%s-tikv-cold-peer", clusterName)
+}
+
 // TiFlashMemberName returns tiflash member name
 func TiFlashMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-tiflash", clusterName)
@@ -294,6 +326,16 @@ func TiCDCPeerMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-ticdc-peer", clusterName)
 }
 
+// TiProxyMemberName returns tiproxy member name
+func TiProxyMemberName(clusterName string) string {
+	return fmt.Sprintf("%s-tiproxy", clusterName)
+}
+
+// TiProxyPeerMemberName returns tiproxy peer service name
+func TiProxyPeerMemberName(clusterName string) string {
+	return fmt.Sprintf("%s-tiproxy-peer", clusterName)
+}
+
 // TiDBMemberName returns tidb member name
 func TiDBMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-tidb", clusterName)
@@ -320,6 +362,16 @@ func PumpPeerMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-pump", clusterName)
 }
 
+// ReadinessConfigMapName returns the name of the ConfigMap that mirrors the readiness of the cluster
+func ReadinessConfigMapName(clusterName string) string {
+	return fmt.Sprintf("%s-readiness", clusterName)
+}
+
+// EffectiveConfigMapName returns the name of the ConfigMap that exports the effective config of the cluster
+func EffectiveConfigMapName(clusterName string) string {
+	return fmt.Sprintf("%s-effective-config", clusterName)
+}
+
 // DiscoveryMemberName returns the name of tidb discovery
 func DiscoveryMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-discovery", clusterName)
@@ -132,6 +132,20 @@ func TestGetTiDBClusterAutoScalerOwnerRef(t *testing.T) {
 	g.Expect(*ref.BlockOwnerDeletion).To(BeTrue())
 }
 
+func TestGetOpsCommandOwnerRef(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	oc := &v1alpha1.OpsCommand{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: metav1.NamespaceDefault}}
+	oc.UID = types.UID("demo-uid")
+	ref := GetOpsCommandOwnerRef(oc)
+	g.Expect(ref.APIVersion).To(Equal(opsCommandKind.GroupVersion().String()))
+	g.Expect(ref.Kind).To(Equal(opsCommandKind.Kind))
+	g.Expect(ref.Name).To(Equal(oc.GetName()))
+	g.Expect(ref.UID).To(Equal(types.UID("demo-uid")))
+	g.Expect(*ref.Controller).To(BeTrue())
+	g.Expect(*ref.BlockOwnerDeletion).To(BeTrue())
+}
+
 func TestGetServiceType(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -24,6 +24,7 @@ import (
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/dmapi"
+	"github.com/pingcap/tidb-operator/pkg/notification"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
 	"github.com/pingcap/tidb-operator/pkg/tiflashapi"
@@ -40,7 +41,9 @@ import (
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	extensionslister "k8s.io/client-go/listers/extensions/v1beta1"
 	networklister "k8s.io/client-go/listers/networking/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1beta1"
 	storagelister "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -86,6 +89,33 @@ type CLIConfig struct {
 	// Selector is used to filter CR labels to decide
 	// what resources should be watched and synced by controller
 	Selector string
+	// LeaderElectionResourceLock is the type of the resource object used for leader election
+	LeaderElectionResourceLock string
+	// KubeClientQPS and KubeClientBurst are the rate limits of the requests to the Kubernetes API server
+	// sent by the informers and the controllers except the StatefulSet and the backup controllers
+	KubeClientQPS   float64
+	KubeClientBurst int
+	// StatefulSetKubeClientQPS and StatefulSetKubeClientBurst are the rate limits of the requests to the
+	// Kubernetes API server sent by the controllers that update the StatefulSets, that is the tidbcluster,
+	// dmcluster, tidbmonitor and tidbngmonitoring controllers
+	StatefulSetKubeClientQPS   float64
+	StatefulSetKubeClientBurst int
+	// BackupKubeClientQPS and BackupKubeClientBurst are the rate limits of the requests to the Kubernetes
+	// API server sent by the backup, restore and backup schedule controllers, which are isolated from the
+	// others so that a large number of backups can't starve the reconciliation of the clusters
+	BackupKubeClientQPS   float64
+	BackupKubeClientBurst int
+	// OverviewEnabled is the key to indicate whether the read-only overview of the managed clusters
+	// is served by the HTTP server of the controller manager
+	OverviewEnabled bool
+	// ScaleOutResourceCheck is the key to indicate whether the resource quotas and the capacity of the
+	// cluster are checked before scaling out a component, including the scaling out for failover.
+	// It's disabled by default as the capacity may be added by the cluster autoscaler on demand.
+	ScaleOutResourceCheck bool
+	// TenantPolicies is the policies of the tenants in JSON keyed by the tenant IDs, the TidbClusters in the
+	// namespaces annotated with tidb.pingcap.com/tenant are limited by the policies of their tenants.
+	// It's only supported by the cluster scoped operator.
+	TenantPolicies string
 }
 
 // DefaultCLIConfig returns the default command line configuration
@@ -108,6 +138,15 @@ func DefaultCLIConfig() *CLIConfig {
 		TiDBBackupManagerImage: "pingcap/tidb-backup-manager:latest",
 		TiDBDiscoveryImage:     "pingcap/tidb-operator:latest",
 		Selector:               "",
+		// endpointsleases holds both the Endpoints and the Lease, so that the leadership can be
+		// migrated from the old versions that only use the Endpoints lock
+		LeaderElectionResourceLock: resourcelock.EndpointsLeasesResourceLock,
+		KubeClientQPS:              5,
+		KubeClientBurst:            10,
+		StatefulSetKubeClientQPS:   5,
+		StatefulSetKubeClientBurst: 10,
+		BackupKubeClientQPS:        5,
+		BackupKubeClientBurst:      10,
 	}
 }
 
@@ -139,6 +178,16 @@ func (c *CLIConfig) AddFlag(_ *flag.FlagSet) {
 	flag.DurationVar(&c.LeaseDuration, "leader-lease-duration", c.LeaseDuration, "leader-lease-duration is the duration that non-leader candidates will wait to force acquire leadership")
 	flag.DurationVar(&c.RenewDeadline, "leader-renew-deadline", c.RenewDeadline, "leader-renew-deadline is the duration that the acting master will retry refreshing leadership before giving up")
 	flag.DurationVar(&c.RetryPeriod, "leader-retry-period", c.RetryPeriod, "leader-retry-period is the duration the LeaderElector clients should wait between tries of actions")
+	flag.Float64Var(&c.KubeClientQPS, "kube-client-qps", c.KubeClientQPS, "The maximum QPS to the Kubernetes API server of the informers and the controllers except the StatefulSet and the backup controllers")
+	flag.IntVar(&c.KubeClientBurst, "kube-client-burst", c.KubeClientBurst, "The maximum burst for throttle to the Kubernetes API server of the informers and the controllers except the StatefulSet and the backup controllers")
+	flag.Float64Var(&c.StatefulSetKubeClientQPS, "statefulset-kube-client-qps", c.StatefulSetKubeClientQPS, "The maximum QPS to the Kubernetes API server of the tidbcluster, dmcluster, tidbmonitor and tidbngmonitoring controllers")
+	flag.IntVar(&c.StatefulSetKubeClientBurst, "statefulset-kube-client-burst", c.StatefulSetKubeClientBurst, "The maximum burst for throttle to the Kubernetes API server of the tidbcluster, dmcluster, tidbmonitor and tidbngmonitoring controllers")
+	flag.Float64Var(&c.BackupKubeClientQPS, "backup-kube-client-qps", c.BackupKubeClientQPS, "The maximum QPS to the Kubernetes API server of the backup, restore and backup schedule controllers")
+	flag.IntVar(&c.BackupKubeClientBurst, "backup-kube-client-burst", c.BackupKubeClientBurst, "The maximum burst for throttle to the Kubernetes API server of the backup, restore and backup schedule controllers")
+	flag.StringVar(&c.LeaderElectionResourceLock, "leader-election-resource-lock", c.LeaderElectionResourceLock, "The type of resource object that is used for locking during leader election, supported options are 'leases' and 'endpointsleases'")
+	flag.BoolVar(&c.OverviewEnabled, "overview-enabled", c.OverviewEnabled, "Whether to serve the read-only overview of the managed clusters at /overview (HTML) and /api/v1/overview (JSON)")
+	flag.BoolVar(&c.ScaleOutResourceCheck, "scale-out-resource-check", c.ScaleOutResourceCheck, "Whether to check the resource quotas and the capacity of the cluster before scaling out, the scaling out is blocked if the resources are insufficient")
+	flag.StringVar(&c.TenantPolicies, "tenant-policies", c.TenantPolicies, `The policies of the tenants in JSON keyed by the tenant IDs, e.g. {"team-a":{"maxClusters":3,"maxTiKVReplicas":15,"allowedStorageClasses":["ssd"]}}, the namespaces are assigned to the tenants by the annotation tidb.pingcap.com/tenant. It's only supported if cluster-scoped is true`)
 }
 
 // HasNodePermission returns whether the user has permission for node operations.
@@ -175,7 +224,11 @@ type Controls struct {
 	DMClusterControl   DMClusterControlInterface
 	CDCControl         TiCDCControlInterface
 	TiDBControl        TiDBControlInterface
+	NGMControl         NGMonitoringControlInterface
 	BackupControl      BackupControlInterface
+	// VolumeSnapshotControl manages the CSI VolumeSnapshots taken by the backups of the volume-snapshot mode
+	VolumeSnapshotControl VolumeSnapshotControlInterface
+	Notifier              notification.Interface
 }
 
 // Dependencies is used to store all shared dependent resources to avoid
@@ -183,6 +236,8 @@ type Controls struct {
 type Dependencies struct {
 	// CLIConfig represents all parameters read from command line
 	CLIConfig *CLIConfig
+	// TenantPolicies are the policies of the tenants parsed from CLIConfig.TenantPolicies
+	TenantPolicies map[string]TenantPolicy
 	// Operator client interface
 	Clientset versioned.Interface
 	// Kubernetes client interface
@@ -199,12 +254,18 @@ type Dependencies struct {
 	PVCLister                   corelisterv1.PersistentVolumeClaimLister
 	PVLister                    corelisterv1.PersistentVolumeLister
 	PodLister                   corelisterv1.PodLister
+	ResourceQuotaLister         corelisterv1.ResourceQuotaLister // only set if the scale out resource check is enabled by default
+	EventLister                 corelisterv1.EventLister // lists the events for the overview, nil if the overview is disabled
 	NodeLister                  corelisterv1.NodeLister
+	NamespaceLister             corelisterv1.NamespaceLister // only set if the tenant policies are configured
 	SecretLister                corelisterv1.SecretLister
 	ConfigMapLister             corelisterv1.ConfigMapLister
+	UserConfigMapLister         corelisterv1.ConfigMapLister // reads the ConfigMaps not managed by the operator from the API server
 	StatefulSetLister           appslisters.StatefulSetLister
 	DeploymentLister            appslisters.DeploymentLister
+	ControllerRevisionLister    appslisters.ControllerRevisionLister
 	JobLister                   batchlisters.JobLister
+	PDBLister                   policylisters.PodDisruptionBudgetLister
 	IngressLister               networklister.IngressLister
 	IngressV1Beta1Lister        extensionslister.IngressLister // in order to be compatibility with kubernetes which less than v1.19
 	StorageClassLister          storagelister.StorageClassLister
@@ -217,11 +278,36 @@ type Dependencies struct {
 	TiDBInitializerLister       listers.TidbInitializerLister
 	TiDBMonitorLister           listers.TidbMonitorLister
 	TiDBNGMonitoringLister      listers.TidbNGMonitoringLister
+	OpsCommandLister            listers.OpsCommandLister
+	DMTaskLister                listers.DMTaskLister
 
 	// Controls
 	Controls
 }
 
+// WithClients returns a copy of the Dependencies that sends the requests to the Kubernetes API server with the
+// given clients, the informers, listers and the recorder are shared with the original one, except that the
+// UserConfigMapLister reads the ConfigMaps with the given client.
+func (deps *Dependencies) WithClients(clientset versioned.Interface, kubeClientset kubernetes.Interface, genericCli client.Client) *Dependencies {
+	d := *deps
+	d.Clientset = clientset
+	d.KubeClientset = kubeClientset
+	d.GenericClient = genericCli
+	d.UserConfigMapLister = NewConfigMapClientLister(kubeClientset)
+	controls := newRealControls(d.CLIConfig, clientset, kubeClientset, genericCli, d.InformerFactory, d.KubeInformerFactory, d.Recorder)
+	// the clients of the components don't talk to the Kubernetes API server, share them to reuse the connections
+	controls.PDControl = deps.PDControl
+	controls.TiKVControl = deps.TiKVControl
+	controls.TiFlashControl = deps.TiFlashControl
+	controls.DMMasterControl = deps.DMMasterControl
+	controls.CDCControl = deps.CDCControl
+	controls.TiDBControl = deps.TiDBControl
+	controls.NGMControl = deps.NGMControl
+	controls.Notifier = deps.Notifier
+	d.Controls = controls
+	return &d
+}
+
 func newRealControls(
 	cliCfg *CLIConfig,
 	clientset versioned.Interface,
@@ -251,25 +337,28 @@ func newRealControls(
 	}
 
 	return Controls{
-		JobControl:         NewRealJobControl(kubeClientset, recorder),
-		ConfigMapControl:   NewRealConfigMapControl(kubeClientset, recorder),
-		StatefulSetControl: NewRealStatefuSetControl(kubeClientset, statefulSetLister, recorder),
-		ServiceControl:     NewRealServiceControl(kubeClientset, serviceLister, recorder),
-		PVControl:          NewRealPVControl(kubeClientset, pvcLister, pvLister, recorder),
-		PVCControl:         NewRealPVCControl(kubeClientset, recorder, pvcLister),
-		GeneralPVCControl:  NewRealGeneralPVCControl(kubeClientset, recorder),
-		GenericControl:     genericCtrl,
-		PodControl:         NewRealPodControl(kubeClientset, pdapi.NewDefaultPDControl(secretLister), podLister, recorder),
-		TypedControl:       NewTypedControl(genericCtrl),
-		PDControl:          pdControl,
-		TiKVControl:        tikvControl,
-		TiFlashControl:     tiflashControl,
-		DMMasterControl:    masterControl,
-		TiDBClusterControl: NewRealTidbClusterControl(clientset, tidbClusterLister, recorder),
-		DMClusterControl:   NewRealDMClusterControl(clientset, dmClusterLister, recorder),
-		CDCControl:         NewDefaultTiCDCControl(secretLister),
-		TiDBControl:        NewDefaultTiDBControl(secretLister),
-		BackupControl:      NewRealBackupControl(clientset, recorder),
+		JobControl:            NewRealJobControl(kubeClientset, recorder),
+		ConfigMapControl:      NewRealConfigMapControl(kubeClientset, recorder),
+		StatefulSetControl:    NewRealStatefuSetControl(kubeClientset, statefulSetLister, recorder),
+		ServiceControl:        NewRealServiceControl(kubeClientset, serviceLister, recorder),
+		PVControl:             NewRealPVControl(kubeClientset, pvcLister, pvLister, recorder),
+		PVCControl:            NewRealPVCControl(kubeClientset, recorder, pvcLister),
+		GeneralPVCControl:     NewRealGeneralPVCControl(kubeClientset, recorder),
+		GenericControl:        genericCtrl,
+		PodControl:            NewRealPodControl(kubeClientset, pdapi.NewDefaultPDControl(secretLister), podLister, recorder),
+		TypedControl:          NewTypedControl(genericCtrl),
+		PDControl:             pdControl,
+		TiKVControl:           tikvControl,
+		TiFlashControl:        tiflashControl,
+		DMMasterControl:       masterControl,
+		TiDBClusterControl:    NewRealTidbClusterControl(clientset, tidbClusterLister, recorder),
+		DMClusterControl:      NewRealDMClusterControl(clientset, dmClusterLister, recorder),
+		CDCControl:            NewDefaultTiCDCControl(secretLister),
+		NGMControl:            NewDefaultNGMonitoringControl(),
+		TiDBControl:           NewDefaultTiDBControl(secretLister),
+		BackupControl:         NewRealBackupControl(clientset, recorder),
+		VolumeSnapshotControl: NewRealVolumeSnapshotControl(genericCli, recorder),
+		Notifier:              notification.NewNotifier(secretLister),
 	}
 }
 
@@ -289,6 +378,9 @@ func newDependencies(
 		scLister         storagelister.StorageClassLister
 		ingLister        networklister.IngressLister
 		ingv1beta1Lister extensionslister.IngressLister
+		nsLister         corelisterv1.NamespaceLister
+		eventLister      corelisterv1.EventLister
+		quotaLister      corelisterv1.ResourceQuotaLister
 	)
 	if cliCfg.HasNodePermission() {
 		nodeLister = kubeInformerFactory.Core().V1().Nodes().Lister()
@@ -305,6 +397,23 @@ func newDependencies(
 	} else {
 		klog.Info("no permission for storage classes, skip creating sc lister")
 	}
+	if cliCfg.ScaleOutResourceCheck {
+		quotaLister = kubeInformerFactory.Core().V1().ResourceQuotas().Lister()
+	}
+	// the events are only cached for the overview, as caching all the events of the watched namespaces is expensive
+	if cliCfg.OverviewEnabled {
+		eventLister = kubeInformerFactory.Core().V1().Events().Lister()
+	}
+	tenantPolicies, err := ParseTenantPolicies(cliCfg.TenantPolicies)
+	if err != nil {
+		return nil, err
+	}
+	if len(tenantPolicies) > 0 {
+		if !cliCfg.ClusterScoped {
+			return nil, fmt.Errorf("tenant policies are only supported by the cluster scoped operator")
+		}
+		nsLister = kubeInformerFactory.Core().V1().Namespaces().Lister()
+	}
 
 	supported, err := utildiscovery.IsAPIGroupVersionResourceSupported(kubeClientset.Discovery(), "networking.k8s.io/v1", "ingresses")
 	if err != nil {
@@ -318,6 +427,7 @@ func newDependencies(
 
 	return &Dependencies{
 		CLIConfig:                      cliCfg,
+		TenantPolicies:                 tenantPolicies,
 		InformerFactory:                informerFactory,
 		Clientset:                      clientset,
 		KubeClientset:                  kubeClientset,
@@ -332,13 +442,19 @@ func newDependencies(
 		PVCLister:                   kubeInformerFactory.Core().V1().PersistentVolumeClaims().Lister(),
 		PVLister:                    pvLister,
 		PodLister:                   kubeInformerFactory.Core().V1().Pods().Lister(),
+		ResourceQuotaLister:         quotaLister,
+		EventLister:                 eventLister,
 		NodeLister:                  nodeLister,
+		NamespaceLister:             nsLister,
 		SecretLister:                kubeInformerFactory.Core().V1().Secrets().Lister(),
 		ConfigMapLister:             labelFilterKubeInformerFactory.Core().V1().ConfigMaps().Lister(),
+		UserConfigMapLister:         NewConfigMapClientLister(kubeClientset),
 		StatefulSetLister:           kubeInformerFactory.Apps().V1().StatefulSets().Lister(),
 		DeploymentLister:            kubeInformerFactory.Apps().V1().Deployments().Lister(),
+		ControllerRevisionLister:    kubeInformerFactory.Apps().V1().ControllerRevisions().Lister(),
 		StorageClassLister:          scLister,
 		JobLister:                   kubeInformerFactory.Batch().V1().Jobs().Lister(),
+		PDBLister:                   kubeInformerFactory.Policy().V1beta1().PodDisruptionBudgets().Lister(),
 		IngressLister:               ingLister,
 		IngressV1Beta1Lister:        ingv1beta1Lister,
 		TiDBClusterLister:           informerFactory.Pingcap().V1alpha1().TidbClusters().Lister(),
@@ -350,6 +466,8 @@ func newDependencies(
 		TiDBInitializerLister:       informerFactory.Pingcap().V1alpha1().TidbInitializers().Lister(),
 		TiDBMonitorLister:           informerFactory.Pingcap().V1alpha1().TidbMonitors().Lister(),
 		TiDBNGMonitoringLister:      informerFactory.Pingcap().V1alpha1().TidbNGMonitorings().Lister(),
+		OpsCommandLister:            informerFactory.Pingcap().V1alpha1().OpsCommands().Lister(),
+		DMTaskLister:                informerFactory.Pingcap().V1alpha1().DMTasks().Lister(),
 	}, nil
 }
 
@@ -401,24 +519,27 @@ func newFakeControl(kubeClientset kubernetes.Interface, informerFactory informer
 	genericCtrl := NewFakeGenericControl()
 	// Shared variables to construct `Dependencies` and some of its fields
 	return Controls{
-		JobControl:         NewFakeJobControl(kubeInformerFactory.Batch().V1().Jobs()),
-		ConfigMapControl:   NewFakeConfigMapControl(kubeInformerFactory.Core().V1().ConfigMaps()),
-		StatefulSetControl: NewFakeStatefulSetControl(kubeInformerFactory.Apps().V1().StatefulSets()),
-		ServiceControl:     NewFakeServiceControl(kubeInformerFactory.Core().V1().Services(), kubeInformerFactory.Core().V1().Endpoints()),
-		PVControl:          NewFakePVControl(kubeInformerFactory.Core().V1().PersistentVolumes(), kubeInformerFactory.Core().V1().PersistentVolumeClaims()),
-		PVCControl:         NewFakePVCControl(kubeInformerFactory.Core().V1().PersistentVolumeClaims()),
-		GeneralPVCControl:  NewFakeGeneralPVCControl(kubeInformerFactory.Core().V1().PersistentVolumeClaims()),
-		GenericControl:     genericCtrl,
-		PodControl:         NewFakePodControl(kubeInformerFactory.Core().V1().Pods()),
-		TypedControl:       NewTypedControl(genericCtrl),
-		PDControl:          pdapi.NewFakePDControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
-		TiKVControl:        tikvapi.NewFakeTiKVControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
-		DMMasterControl:    dmapi.NewFakeMasterControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
-		TiFlashControl:     tiflashapi.NewFakeTiFlashControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
-		TiDBClusterControl: NewFakeTidbClusterControl(informerFactory.Pingcap().V1alpha1().TidbClusters()),
-		CDCControl:         NewFakeTiCDCControl(),
-		TiDBControl:        NewFakeTiDBControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
-		BackupControl:      NewFakeBackupControl(informerFactory.Pingcap().V1alpha1().Backups()),
+		JobControl:            NewFakeJobControl(kubeInformerFactory.Batch().V1().Jobs()),
+		ConfigMapControl:      NewFakeConfigMapControl(kubeInformerFactory.Core().V1().ConfigMaps()),
+		StatefulSetControl:    NewFakeStatefulSetControl(kubeInformerFactory.Apps().V1().StatefulSets()),
+		ServiceControl:        NewFakeServiceControl(kubeInformerFactory.Core().V1().Services(), kubeInformerFactory.Core().V1().Endpoints()),
+		PVControl:             NewFakePVControl(kubeInformerFactory.Core().V1().PersistentVolumes(), kubeInformerFactory.Core().V1().PersistentVolumeClaims()),
+		PVCControl:            NewFakePVCControl(kubeInformerFactory.Core().V1().PersistentVolumeClaims()),
+		GeneralPVCControl:     NewFakeGeneralPVCControl(kubeInformerFactory.Core().V1().PersistentVolumeClaims()),
+		GenericControl:        genericCtrl,
+		PodControl:            NewFakePodControl(kubeInformerFactory.Core().V1().Pods()),
+		TypedControl:          NewTypedControl(genericCtrl),
+		PDControl:             pdapi.NewFakePDControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
+		TiKVControl:           tikvapi.NewFakeTiKVControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
+		DMMasterControl:       dmapi.NewFakeMasterControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
+		TiFlashControl:        tiflashapi.NewFakeTiFlashControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
+		TiDBClusterControl:    NewFakeTidbClusterControl(informerFactory.Pingcap().V1alpha1().TidbClusters()),
+		CDCControl:            NewFakeTiCDCControl(),
+		NGMControl:            NewFakeNGMonitoringControl(),
+		TiDBControl:           NewFakeTiDBControl(kubeInformerFactory.Core().V1().Secrets().Lister()),
+		BackupControl:         NewFakeBackupControl(informerFactory.Pingcap().V1alpha1().Backups()),
+		VolumeSnapshotControl: NewFakeVolumeSnapshotControl(),
+		Notifier:              notification.NewFakeNotifier(),
 	}
 }
 
@@ -457,5 +578,7 @@ func NewFakeDependencies() *Dependencies {
 		klog.Fatalf("failed to create Dependencies: %s", err)
 	}
 	deps.Controls = newFakeControl(kubeCli, informerFactory, kubeInformerFactory)
+	// the tests add the ConfigMaps to the indexer of the informer
+	deps.UserConfigMapLister = kubeInformerFactory.Core().V1().ConfigMaps().Lister()
 	return deps
 }
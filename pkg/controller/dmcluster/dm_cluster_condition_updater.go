@@ -44,7 +44,8 @@ func allStatefulSetsAreUpToDate(dc *v1alpha1.DMCluster) bool {
 		}
 		return status.CurrentRevision == status.UpdateRevision
 	}
-	return (isUpToDate(dc.Status.Master.StatefulSet, true)) &&
+	// no dm-master StatefulSet exists if the dm-workers join the dm-masters of another DMCluster
+	return (isUpToDate(dc.Status.Master.StatefulSet, !dc.HeterogeneousWithoutLocalMaster())) &&
 		(isUpToDate(dc.Status.Worker.StatefulSet, false))
 }
 
@@ -54,6 +55,9 @@ func (u *dmClusterConditionUpdater) updateReadyCondition(dc *v1alpha1.DMCluster)
 	message := ""
 
 	switch {
+	case dc.Spec.Suspend:
+		reason = utildmcluster.Suspended
+		message = "DM cluster is suspended"
 	case !allStatefulSetsAreUpToDate(dc):
 		reason = utildmcluster.StatfulSetNotUpToDate
 		message = "Statefulset(s) are in progress"
@@ -42,6 +42,7 @@ func NewDefaultDMClusterControl(
 	masterMemberManager manager.DMManager,
 	workerMemberManager manager.DMManager,
 	reclaimPolicyManager manager.DMManager,
+	pdbManager manager.DMManager,
 	orphanPodsCleaner member.OrphanPodsCleaner,
 	pvcCleaner member.PVCCleanerInterface,
 	pvcResizer member.PVCResizerInterface,
@@ -53,6 +54,7 @@ func NewDefaultDMClusterControl(
 		masterMemberManager,
 		workerMemberManager,
 		reclaimPolicyManager,
+		pdbManager,
 		//metaManager,
 		orphanPodsCleaner,
 		pvcCleaner,
@@ -68,6 +70,7 @@ type defaultDMClusterControl struct {
 	masterMemberManager  manager.DMManager
 	workerMemberManager  manager.DMManager
 	reclaimPolicyManager manager.DMManager
+	pdbManager           manager.DMManager
 	//metaManager       manager.DMManager
 	orphanPodsCleaner member.OrphanPodsCleaner
 	pvcCleaner        member.PVCCleanerInterface
@@ -126,6 +129,11 @@ func (c *defaultDMClusterControl) updateDMCluster(dc *v1alpha1.DMCluster) error
 		return err
 	}
 
+	// syncing the PodDisruptionBudgets of dm-master and dm-worker
+	if err := c.pdbManager.SyncDM(dc); err != nil {
+		errs = append(errs, err)
+	}
+
 	// cleaning all orphan pods(dm-master or dm-worker which don't have a related PVC) managed by operator
 	skipReasons, err := c.orphanPodsCleaner.Clean(dc)
 	if err != nil {
@@ -262,6 +262,7 @@ func newFakeDMClusterControl() (
 		masterMemberManager,
 		workerMemberManager,
 		reclaimPolicyManager,
+		meta.NewFakePDBManager(),
 		orphanPodCleaner,
 		pvcCleaner,
 		pvcResizer,
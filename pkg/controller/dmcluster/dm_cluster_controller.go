@@ -51,6 +51,7 @@ func NewController(deps *controller.Dependencies) *Controller {
 			mm.NewMasterMemberManager(deps, mm.NewMasterScaler(deps), mm.NewMasterUpgrader(deps), mm.NewMasterFailover(deps)),
 			mm.NewWorkerMemberManager(deps, mm.NewWorkerScaler(deps), mm.NewWorkerFailover(deps)),
 			meta.NewReclaimPolicyManager(deps),
+			meta.NewPDBManager(deps),
 			mm.NewOrphanPodsCleaner(deps),
 			mm.NewRealPVCCleaner(deps),
 			mm.NewPVCResizer(deps),
@@ -61,7 +61,13 @@ func (c *realDMClusterControl) UpdateDMCluster(dc *v1alpha1.DMCluster, newStatus
 	// don't wait due to limited number of clients, but backoff after the default number of steps
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		var updateErr error
+		// status is a subresource, so the spec and metadata changes (e.g. defaulting)
+		// are persisted by Update and the status is persisted by UpdateStatus
 		updateDC, updateErr = c.cli.PingcapV1alpha1().DMClusters(ns).Update(context.TODO(), dc, metav1.UpdateOptions{})
+		if updateErr == nil {
+			updateDC.Status = *status
+			updateDC, updateErr = c.cli.PingcapV1alpha1().DMClusters(ns).UpdateStatus(context.TODO(), updateDC, metav1.UpdateOptions{})
+		}
 		if updateErr == nil {
 			klog.Infof("DMCluster: [%s/%s] updated successfully", ns, dcName)
 			return nil
@@ -18,25 +18,30 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/dmapi"
 )
 
-// GetMasterClient gets the master client from the DMCluster
+// GetMasterClient gets the master client from the DMCluster, it connects to the dm-masters of the referenced
+// DMCluster if the DMCluster has no dm-master
 func GetMasterClient(dmControl dmapi.MasterControlInterface, dc *v1alpha1.DMCluster) dmapi.MasterClient {
-	return dmControl.GetMasterClient(dc.GetNamespace(), dc.GetName(), dc.IsTLSClusterEnabled())
+	ns, dcName := dc.MasterCluster()
+	return dmControl.GetMasterClient(ns, dcName, dc.IsTLSClusterEnabled(), dmapi.MasterPort(dc.MasterPort()))
 }
 
 // GetMasterClient gets the master client from the DMCluster
 func GetMasterPeerClient(dmControl dmapi.MasterControlInterface, dc *v1alpha1.DMCluster, podName string) dmapi.MasterClient {
-	return dmControl.GetMasterPeerClient(dc.GetNamespace(), dc.GetName(), podName, dc.IsTLSClusterEnabled())
+	ns, dcName := dc.MasterCluster()
+	return dmControl.GetMasterPeerClient(ns, dcName, podName, dc.IsTLSClusterEnabled(), dmapi.MasterPort(dc.MasterPort()))
 }
 
 // NewFakeMasterClient creates a fake master client that is set as the master client
 func NewFakeMasterClient(dmControl *dmapi.FakeMasterControl, dc *v1alpha1.DMCluster) *dmapi.FakeMasterClient {
 	masterClient := dmapi.NewFakeMasterClient()
-	dmControl.SetMasterClient(dc.GetNamespace(), dc.GetName(), masterClient)
+	ns, dcName := dc.MasterCluster()
+	dmControl.SetMasterClient(ns, dcName, masterClient)
 	return masterClient
 }
 
 func NewFakeMasterPeerClient(dmControl *dmapi.FakeMasterControl, dc *v1alpha1.DMCluster, podName string) *dmapi.FakeMasterClient {
 	masterClient := dmapi.NewFakeMasterClient()
-	dmControl.SetMasterPeerClient(dc.GetNamespace(), dc.GetName(), podName, masterClient)
+	ns, dcName := dc.MasterCluster()
+	dmControl.SetMasterPeerClient(ns, dcName, podName, masterClient)
 	return masterClient
 }
@@ -0,0 +1,65 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmtask
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+)
+
+// ControlInterface reconciles DMTask
+type ControlInterface interface {
+	// ReconcileDMTask implements the reconcile logic of DMTask
+	ReconcileDMTask(dt *v1alpha1.DMTask) error
+}
+
+// NewDefaultDMTaskControl returns a new instance of the default DMTask ControlInterface
+func NewDefaultDMTaskControl(manager member.DMTaskManager) ControlInterface {
+	return &defaultDMTaskControl{manager}
+}
+
+type defaultDMTaskControl struct {
+	dmTaskManager member.DMTaskManager
+}
+
+func (c *defaultDMTaskControl) ReconcileDMTask(dt *v1alpha1.DMTask) error {
+	return c.dmTaskManager.Sync(dt)
+}
+
+var _ ControlInterface = &defaultDMTaskControl{}
+
+// FakeDMTaskControl is a fake DMTask ControlInterface
+type FakeDMTaskControl struct {
+	err error
+}
+
+// NewFakeDMTaskControl returns a FakeDMTaskControl
+func NewFakeDMTaskControl() *FakeDMTaskControl {
+	return &FakeDMTaskControl{}
+}
+
+// SetReconcileDMTaskError sets error for DMTaskControl
+func (dtc *FakeDMTaskControl) SetReconcileDMTaskError(err error) {
+	dtc.err = err
+}
+
+// ReconcileDMTask fake ReconcileDMTask
+func (dtc *FakeDMTaskControl) ReconcileDMTask(dt *v1alpha1.DMTask) error {
+	if dtc.err != nil {
+		return dtc.err
+	}
+	return nil
+}
+
+var _ ControlInterface = &FakeDMTaskControl{}
@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -62,6 +63,8 @@ type TypedControlInterface interface {
 	CreateOrUpdateIngress(controller client.Object, ingress *networkingv1.Ingress) (*networkingv1.Ingress, error)
 	// CreateOrUpdateIngressV1beta1 create the desired v1beta1 ingress or update the current one to desired state if already existed
 	CreateOrUpdateIngressV1beta1(controller client.Object, ingress *extensionsv1beta1.Ingress) (*extensionsv1beta1.Ingress, error)
+	// CreateOrUpdatePodDisruptionBudget create the desired pdb or update the current one to desired state if already existed
+	CreateOrUpdatePodDisruptionBudget(controller client.Object, pdb *policyv1beta1.PodDisruptionBudget) (*policyv1beta1.PodDisruptionBudget, error)
 	// UpdateStatus update the /status subresource of the object
 	UpdateStatus(newStatus client.Object) error
 	// Delete delete the given object from the cluster
@@ -143,6 +146,21 @@ func (w *typedWrapper) CreateOrUpdateSecret(controller client.Object, secret *co
 	return result.(*corev1.Secret), nil
 }
 
+func (w *typedWrapper) CreateOrUpdatePodDisruptionBudget(controller client.Object, pdb *policyv1beta1.PodDisruptionBudget) (*policyv1beta1.PodDisruptionBudget, error) {
+	result, err := w.GenericControlInterface.CreateOrUpdate(controller, pdb, func(existing, desired client.Object) error {
+		existingPDB := existing.(*policyv1beta1.PodDisruptionBudget)
+		desiredPDB := desired.(*policyv1beta1.PodDisruptionBudget)
+
+		existingPDB.Labels = desiredPDB.Labels
+		existingPDB.Spec = desiredPDB.Spec
+		return nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*policyv1beta1.PodDisruptionBudget), nil
+}
+
 func (w *typedWrapper) Delete(controller, obj client.Object) error {
 	return w.GenericControlInterface.Delete(controller, obj)
 }
@@ -0,0 +1,97 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+)
+
+const ngMonitoringPort = 12020
+
+type storageUsage struct {
+	UsedBytes int64 `json:"used_bytes"`
+}
+
+// NGMonitoringControlInterface is the interface that knows how to query ng monitoring
+type NGMonitoringControlInterface interface {
+	// GetStorageUsage returns the bytes of the storage used by the data of ng monitoring
+	GetStorageUsage(tngm *v1alpha1.TidbNGMonitoring) (int64, error)
+}
+
+// defaultNGMonitoringControl is default implementation of NGMonitoringControlInterface.
+type defaultNGMonitoringControl struct {
+	// for unit test only
+	testURL string
+}
+
+// NewDefaultNGMonitoringControl returns a defaultNGMonitoringControl instance
+func NewDefaultNGMonitoringControl() *defaultNGMonitoringControl {
+	return &defaultNGMonitoringControl{}
+}
+
+func (c *defaultNGMonitoringControl) GetStorageUsage(tngm *v1alpha1.TidbNGMonitoring) (int64, error) {
+	// TODO: support TLS after ng monitoring serves with TLS
+	httpClient := &http.Client{Timeout: timeout}
+	url := fmt.Sprintf("%s/storage/usage", c.getBaseURL(tngm))
+	body, err := getBodyOK(httpClient, url)
+	if err != nil {
+		return 0, err
+	}
+
+	usage := storageUsage{}
+	if err := json.Unmarshal(body, &usage); err != nil {
+		return 0, err
+	}
+	return usage.UsedBytes, nil
+}
+
+func (c *defaultNGMonitoringControl) getBaseURL(tngm *v1alpha1.TidbNGMonitoring) string {
+	if c.testURL != "" {
+		return c.testURL
+	}
+
+	name := fmt.Sprintf("%s-ng-monitoring", tngm.GetName())
+	hostName := fmt.Sprintf("%s-0.%s.%s", name, name, tngm.GetNamespace())
+	if tngm.Spec.ClusterDomain != "" {
+		hostName = fmt.Sprintf("%s.svc.%s", hostName, tngm.Spec.ClusterDomain)
+	}
+	return fmt.Sprintf("http://%s:%d", hostName, ngMonitoringPort)
+}
+
+// FakeNGMonitoringControl is a fake implementation of NGMonitoringControlInterface.
+type FakeNGMonitoringControl struct {
+	getStorageUsage func(tngm *v1alpha1.TidbNGMonitoring) (int64, error)
+}
+
+// NewFakeNGMonitoringControl returns a FakeNGMonitoringControl instance
+func NewFakeNGMonitoringControl() *FakeNGMonitoringControl {
+	return &FakeNGMonitoringControl{}
+}
+
+// MockGetStorageUsage mocks the GetStorageUsage of FakeNGMonitoringControl
+func (c *FakeNGMonitoringControl) MockGetStorageUsage(mockfunc func(tngm *v1alpha1.TidbNGMonitoring) (int64, error)) {
+	c.getStorageUsage = mockfunc
+}
+
+// GetStorageUsage returns 0 unless it's mocked
+func (c *FakeNGMonitoringControl) GetStorageUsage(tngm *v1alpha1.TidbNGMonitoring) (int64, error) {
+	if c.getStorageUsage == nil {
+		return 0, nil
+	}
+	return c.getStorageUsage(tngm)
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNGMonitoringControlGetStorageUsage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tngm := &v1alpha1.TidbNGMonitoring{
+		ObjectMeta: metav1.ObjectMeta{Name: "ngm", Namespace: "ns"},
+	}
+
+	control := NewDefaultNGMonitoringControl()
+	g.Expect(control.getBaseURL(tngm)).To(Equal("http://ngm-ng-monitoring-0.ngm-ng-monitoring.ns:12020"))
+	tngm.Spec.ClusterDomain = "cluster.local"
+	g.Expect(control.getBaseURL(tngm)).To(Equal("http://ngm-ng-monitoring-0.ngm-ng-monitoring.ns.svc.cluster.local:12020"))
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/storage/usage" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.Write([]byte(`{"used_bytes":1048576}`))
+	})
+	defer svc.Close()
+	control.testURL = svc.URL
+	usage, err := control.GetStorageUsage(tngm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(usage).To(Equal(int64(1048576)))
+
+	control.testURL = svc.URL + "/not-found"
+	_, err = control.GetStorageUsage(tngm)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opscommand
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+)
+
+// ControlInterface reconciles OpsCommand
+type ControlInterface interface {
+	// ReconcileOpsCommand implements the reconcile logic of OpsCommand
+	ReconcileOpsCommand(oc *v1alpha1.OpsCommand) error
+}
+
+// NewDefaultOpsCommandControl returns a new instance of the default OpsCommand ControlInterface
+func NewDefaultOpsCommandControl(manager member.OpsCommandManager) ControlInterface {
+	return &defaultOpsCommandControl{manager}
+}
+
+type defaultOpsCommandControl struct {
+	opsCommandManager member.OpsCommandManager
+}
+
+func (c *defaultOpsCommandControl) ReconcileOpsCommand(oc *v1alpha1.OpsCommand) error {
+	return c.opsCommandManager.Sync(oc)
+}
+
+var _ ControlInterface = &defaultOpsCommandControl{}
+
+// FakeOpsCommandControl is a fake OpsCommand ControlInterface
+type FakeOpsCommandControl struct {
+	err error
+}
+
+// NewFakeOpsCommandControl returns a FakeOpsCommandControl
+func NewFakeOpsCommandControl() *FakeOpsCommandControl {
+	return &FakeOpsCommandControl{}
+}
+
+// SetReconcileOpsCommandError sets error for OpsCommandControl
+func (occ *FakeOpsCommandControl) SetReconcileOpsCommandError(err error) {
+	occ.err = err
+}
+
+// ReconcileOpsCommand fake ReconcileOpsCommand
+func (occ *FakeOpsCommandControl) ReconcileOpsCommand(oc *v1alpha1.OpsCommand) error {
+	if occ.err != nil {
+		return occ.err
+	}
+	return nil
+}
+
+var _ ControlInterface = &FakeOpsCommandControl{}
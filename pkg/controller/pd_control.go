@@ -15,9 +15,27 @@ package controller
 
 import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 )
 
+// RefPDClientPort returns the PD client port of the cluster referenced by the heterogeneous TidbCluster,
+// the default port is returned if the referenced cluster can't be found, e.g. it's in another Kubernetes cluster
+func RefPDClientPort(tcLister listers.TidbClusterLister, tc *v1alpha1.TidbCluster) int32 {
+	if tc.Spec.Cluster == nil || tc.Spec.Cluster.Name == "" {
+		return v1alpha1.DefaultPDClientPort
+	}
+	ns := tc.Spec.Cluster.Namespace
+	if ns == "" {
+		ns = tc.GetNamespace()
+	}
+	refTC, err := tcLister.TidbClusters(ns).Get(tc.Spec.Cluster.Name)
+	if err != nil {
+		return v1alpha1.DefaultPDClientPort
+	}
+	return refTC.PDClientPort()
+}
+
 // GetPDClientFromService gets the pd client from the TidbCluster
 func GetPDClientFromService(pdControl pdapi.PDControlInterface, tc *v1alpha1.TidbCluster) pdapi.PDClient {
 	if tc.HeterogeneousWithoutLocalPD() {
@@ -27,7 +45,7 @@ func GetPDClientFromService(pdControl pdapi.PDControlInterface, tc *v1alpha1.Tid
 			pdapi.ClusterRef(tc.Spec.Cluster.ClusterDomain),
 		)
 	}
-	return pdControl.GetPDClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled())
+	return pdControl.GetPDClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled(), pdapi.ClientPort(tc.PDClientPort()))
 }
 
 // GetPDClient tries to return an available PDClient
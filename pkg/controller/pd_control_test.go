@@ -19,9 +19,12 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestGetPDClient(t *testing.T) {
@@ -113,3 +116,29 @@ func TestGetPDClient(t *testing.T) {
 		testFn(&tests[i], t)
 	}
 }
+
+func TestRefPDClientPort(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	tcLister := listers.NewTidbClusterLister(indexer)
+
+	tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Name: "hetero", Namespace: "ns"}}
+	g.Expect(RefPDClientPort(tcLister, tc)).To(Equal(int32(v1alpha1.DefaultPDClientPort)))
+
+	// the referenced cluster is not found
+	tc.Spec.Cluster = &v1alpha1.TidbClusterRef{Name: "ref"}
+	g.Expect(RefPDClientPort(tcLister, tc)).To(Equal(int32(v1alpha1.DefaultPDClientPort)))
+
+	port := int32(12379)
+	refTC := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "ref", Namespace: "ns"},
+		Spec:       v1alpha1.TidbClusterSpec{PD: &v1alpha1.PDSpec{ClientPort: &port}},
+	}
+	g.Expect(indexer.Add(refTC)).To(Succeed())
+	g.Expect(RefPDClientPort(tcLister, tc)).To(Equal(port))
+
+	// the referenced cluster is in another namespace
+	tc.Spec.Cluster.Namespace = "other"
+	g.Expect(RefPDClientPort(tcLister, tc)).To(Equal(int32(v1alpha1.DefaultPDClientPort)))
+}
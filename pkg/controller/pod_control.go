@@ -152,6 +152,16 @@ func (c *realPodControl) UpdateMetaInfo(tc *v1alpha1.TidbCluster, pod *corev1.Po
 				}
 			}
 		}
+	case label.TiKVColdLabelVal:
+		if labels[label.StoreIDLabelKey] == "" {
+			// get store id
+			for _, store := range tc.Status.TiKVCold.Stores {
+				if store.PodName == podName {
+					storeID = store.ID
+					break
+				}
+			}
+		}
 	case label.TiFlashLabelVal:
 		if labels[label.StoreIDLabelKey] == "" {
 			// get store id
@@ -0,0 +1,216 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package podlabel dedicate the pod label controller.
+// This controller reconciles the cluster-id, member-id and store-id labels of
+// the Pods managed by our operator from the status of the TidbClusters and
+// DMClusters periodically. The labels are only backfilled by the meta manager
+// at the end of a successful sync of the TidbCluster, so they can be missing
+// or stale for a long time after the Pods are recreated out of band, e.g. when
+// the sync is blocked by a failing member manager. The scale-in and failover
+// logic depends on these labels to map the Pods to the PD members and stores.
+package podlabel
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+type Controller struct {
+	deps *controller.Dependencies
+}
+
+func NewController(deps *controller.Dependencies) *Controller {
+	return &Controller{
+		deps: deps,
+	}
+}
+
+func (c *Controller) Run(_ int, stopCh <-chan struct{}) {
+	klog.Info("Starting pod label controller")
+	defer klog.Info("Shutting down pod label controller")
+	wait.Until(c.run, time.Minute, stopCh)
+}
+
+func (c *Controller) run() {
+	var errs []error
+	if err := c.syncTidbClusterPods(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.syncDMClusterPods(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		klog.Errorf("error happened in pod label controller, err: %v", errors.NewAggregate(errs))
+	}
+}
+
+// syncTidbClusterPods sets the labels of the Pods of all TidbClusters and collects the errors
+func (c *Controller) syncTidbClusterPods() error {
+	tcs, err := c.deps.TiDBClusterLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, tc := range tcs {
+		if err := c.syncPods(tc.Namespace, label.New().Instance(tc.Name), tidbClusterPodLabels(tc)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// syncDMClusterPods sets the labels of the Pods of all DMClusters and collects the errors
+func (c *Controller) syncDMClusterPods() error {
+	dcs, err := c.deps.DMClusterLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, dc := range dcs {
+		if err := c.syncPods(dc.Namespace, label.NewDM().Instance(dc.Name), dmClusterPodLabels(dc)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// syncPods patches the labels of the Pods selected by l which are missing or differ from the desired ones
+func (c *Controller) syncPods(ns string, l label.Label, desired func(pod *corev1.Pod) map[string]string) error {
+	selector, err := l.Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := c.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, pod := range pods {
+		patchLabels := map[string]string{}
+		for k, v := range desired(pod) {
+			if v != "" && pod.Labels[k] != v {
+				patchLabels[k] = v
+			}
+		}
+		if len(patchLabels) == 0 {
+			continue
+		}
+		mergePatch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": patchLabels,
+			},
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, err = c.deps.KubeClientset.CoreV1().Pods(ns).Patch(context.TODO(), pod.Name, types.MergePatchType, mergePatch, metav1.PatchOptions{})
+		if err != nil {
+			klog.Errorf("pod [%s/%s] patch labels %v failed, error: %v", ns, pod.Name, patchLabels, err)
+			errs = append(errs, err)
+			continue
+		}
+		klog.Infof("pod [%s/%s] patch labels %v successfully", ns, pod.Name, patchLabels)
+	}
+	return errors.NewAggregate(errs)
+}
+
+// tidbClusterPodLabels returns the desired labels of the Pods of the TidbCluster, the labels which are unknown
+// from the status are left empty and not changed. The store id label of a Pod is only changed if its store is
+// removed from the status or a store of the Pod is Up while its store is not, so that the label doesn't flap
+// between the stores sharing a Pod, e.g. a Down store and the store recreated after the Pod loses its data.
+func tidbClusterPodLabels(tc *v1alpha1.TidbCluster) func(pod *corev1.Pod) map[string]string {
+	storeStates := map[string]string{}
+	storeIDs := map[string]string{}
+	for _, stores := range []map[string]v1alpha1.TiKVStore{tc.Status.TiKV.TombstoneStores, tc.Status.TiKVCold.TombstoneStores, tc.Status.TiFlash.TombstoneStores} {
+		for id, store := range stores {
+			storeStates[id] = store.State
+		}
+	}
+	for _, stores := range []map[string]v1alpha1.TiKVStore{tc.Status.TiKV.Stores, tc.Status.TiKVCold.Stores, tc.Status.TiFlash.Stores} {
+		for id, store := range stores {
+			storeStates[id] = store.State
+		}
+		for _, store := range stores {
+			if cur, ok := storeIDs[store.PodName]; !ok || preferStore(store.ID, store.State, cur, storeStates[cur]) {
+				storeIDs[store.PodName] = store.ID
+			}
+		}
+	}
+	memberIDs := map[string]string{}
+	for _, member := range tc.Status.PD.Members {
+		memberIDs[strings.Split(member.Name, ".")[0]] = member.ID
+	}
+
+	return func(pod *corev1.Pod) map[string]string {
+		podLabels := map[string]string{
+			label.ClusterIDLabelKey: tc.Status.ClusterID,
+		}
+		switch pod.Labels[label.ComponentLabelKey] {
+		case label.PDLabelVal:
+			podLabels[label.MemberIDLabelKey] = memberIDs[pod.Name]
+		case label.TiKVLabelVal, label.TiKVColdLabelVal, label.TiFlashLabelVal:
+			storeID := storeIDs[pod.Name]
+			cur := pod.Labels[label.StoreIDLabelKey]
+			if state, ok := storeStates[cur]; ok && (state == v1alpha1.TiKVStateUp || storeStates[storeID] != v1alpha1.TiKVStateUp) {
+				storeID = cur
+			}
+			podLabels[label.StoreIDLabelKey] = storeID
+		}
+		return podLabels
+	}
+}
+
+// preferStore returns whether the store id is preferred to the store cur of the same Pod, the Up store is
+// preferred, then the newer one with the larger id
+func preferStore(id, state, cur, curState string) bool {
+	if (state == v1alpha1.TiKVStateUp) != (curState == v1alpha1.TiKVStateUp) {
+		return state == v1alpha1.TiKVStateUp
+	}
+	idNum, _ := strconv.ParseUint(id, 10, 64)
+	curNum, _ := strconv.ParseUint(cur, 10, 64)
+	return idNum > curNum
+}
+
+// dmClusterPodLabels returns the desired labels of the Pods of the DMCluster, only the dm-masters have member ids
+func dmClusterPodLabels(dc *v1alpha1.DMCluster) func(pod *corev1.Pod) map[string]string {
+	memberIDs := map[string]string{}
+	for _, member := range dc.Status.Master.Members {
+		memberIDs[member.Name] = member.ID
+	}
+
+	return func(pod *corev1.Pod) map[string]string {
+		if pod.Labels[label.ComponentLabelKey] != label.DMMasterLabelVal {
+			return nil
+		}
+		return map[string]string{
+			label.MemberIDLabelKey: memberIDs[pod.Name],
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podlabel
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestControllerSyncTidbClusterPods(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	c := NewController(deps)
+	ctx := context.Background()
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: corev1.NamespaceDefault},
+		Status: v1alpha1.TidbClusterStatus{
+			ClusterID: "6666",
+			PD: v1alpha1.PDStatus{
+				Members: map[string]v1alpha1.PDMember{
+					"test-pd-0": {Name: "test-pd-0.test-pd-peer.default.svc", ID: "111"},
+				},
+			},
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"4":  {ID: "4", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp},
+					"6":  {ID: "6", PodName: "test-tikv-2", State: v1alpha1.TiKVStateUp},
+					"7":  {ID: "7", PodName: "test-tikv-2", State: v1alpha1.TiKVStateDown},
+					"9":  {ID: "9", PodName: "test-tikv-3", State: v1alpha1.TiKVStateDown},
+					"10": {ID: "10", PodName: "test-tikv-4", State: v1alpha1.TiKVStateDown},
+					"11": {ID: "11", PodName: "test-tikv-4", State: v1alpha1.TiKVStateUp},
+				},
+				TombstoneStores: map[string]v1alpha1.TiKVStore{
+					"8": {ID: "8", PodName: "test-tikv-3", State: v1alpha1.TiKVStateTombstone},
+				},
+			},
+		},
+	}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+
+	newPod := func(name, component string, labels map[string]string) *corev1.Pod {
+		l := label.New().Instance(tc.Name).Component(component).Labels()
+		for k, v := range labels {
+			l[k] = v
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: tc.Namespace, Labels: l}}
+		g.Expect(deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+		_, err := deps.KubeClientset.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return pod
+	}
+	// the labels are missing
+	newPod("test-pd-0", label.PDLabelVal, nil)
+	// the store id is stale after the pod is recreated with a new store
+	newPod("test-tikv-0", label.TiKVLabelVal, map[string]string{label.ClusterIDLabelKey: "6666", label.StoreIDLabelKey: "1"})
+	// the store is unknown from the status, the store id is kept
+	newPod("test-tikv-1", label.TiKVLabelVal, map[string]string{label.StoreIDLabelKey: "5"})
+	// the Up store is preferred to the Down store of the same pod
+	newPod("test-tikv-2", label.TiKVLabelVal, nil)
+	// the store id of the tombstone store is kept, which is checked when the pod is scaled in
+	newPod("test-tikv-3", label.TiKVLabelVal, map[string]string{label.StoreIDLabelKey: "8"})
+	// the store id of the Down store is replaced by the Up store of the same pod
+	newPod("test-tikv-4", label.TiKVLabelVal, map[string]string{label.StoreIDLabelKey: "10"})
+
+	g.Expect(c.syncTidbClusterPods()).To(Succeed())
+
+	expected := map[string]map[string]string{
+		"test-pd-0":   {label.ClusterIDLabelKey: "6666", label.MemberIDLabelKey: "111"},
+		"test-tikv-0": {label.ClusterIDLabelKey: "6666", label.StoreIDLabelKey: "4"},
+		"test-tikv-1": {label.ClusterIDLabelKey: "6666", label.StoreIDLabelKey: "5"},
+		"test-tikv-2": {label.ClusterIDLabelKey: "6666", label.StoreIDLabelKey: "6"},
+		"test-tikv-3": {label.ClusterIDLabelKey: "6666", label.StoreIDLabelKey: "8"},
+		"test-tikv-4": {label.ClusterIDLabelKey: "6666", label.StoreIDLabelKey: "11"},
+	}
+	for name, labels := range expected {
+		pod, err := deps.KubeClientset.CoreV1().Pods(tc.Namespace).Get(ctx, name, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		for k, v := range labels {
+			g.Expect(pod.Labels).To(HaveKeyWithValue(k, v), "pod %s", name)
+		}
+	}
+}
+
+func TestControllerSyncDMClusterPods(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	c := NewController(deps)
+	ctx := context.Background()
+
+	dc := &v1alpha1.DMCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: corev1.NamespaceDefault},
+		Status: v1alpha1.DMClusterStatus{
+			Master: v1alpha1.MasterStatus{
+				Members: map[string]v1alpha1.MasterMember{
+					"test-dm-master-0": {Name: "test-dm-master-0", ID: "222"},
+				},
+			},
+		},
+	}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().DMClusters().Informer().GetIndexer().Add(dc)).To(Succeed())
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-dm-master-0",
+		Namespace: dc.Namespace,
+		Labels:    label.NewDM().Instance(dc.Name).DMMaster().Labels(),
+	}}
+	g.Expect(deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+	_, err := deps.KubeClientset.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(c.syncDMClusterPods()).To(Succeed())
+
+	pod, err = deps.KubeClientset.CoreV1().Pods(dc.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pod.Labels).To(HaveKeyWithValue(label.MemberIDLabelKey, "222"))
+}
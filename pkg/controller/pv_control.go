@@ -41,6 +41,7 @@ type PVControlInterface interface {
 	PatchPVClaimRef(runtime.Object, *corev1.PersistentVolume, string) error
 	CreatePV(obj runtime.Object, pv *corev1.PersistentVolume) error
 	GetPV(name string) (*corev1.PersistentVolume, error)
+	DeletePV(obj runtime.Object, pv *corev1.PersistentVolume) error
 }
 
 type realPVControl struct {
@@ -100,6 +101,19 @@ func (c *realPVControl) CreatePV(obj runtime.Object, pv *corev1.PersistentVolume
 	return err
 }
 
+func (c *realPVControl) DeletePV(obj runtime.Object, pv *corev1.PersistentVolume) error {
+	metaObj, ok := obj.(metav1.Object)
+	if !ok {
+		return fmt.Errorf("%+v is not a runtime.Object, cannot get controller from it", obj)
+	}
+
+	name := metaObj.GetName()
+	pvName := pv.GetName()
+	err := c.kubeCli.CoreV1().PersistentVolumes().Delete(context.TODO(), pvName, metav1.DeleteOptions{})
+	c.recordPVEvent("delete", obj, name, pvName, err)
+	return err
+}
+
 func (c *realPVControl) PatchPVClaimRef(obj runtime.Object, pv *corev1.PersistentVolume, pvcName string) error {
 	metaObj, ok := obj.(metav1.Object)
 	if !ok {
@@ -230,6 +244,7 @@ type FakePVControl struct {
 	PVIndexer       cache.Indexer
 	updatePVTracker RequestTracker
 	createPVTracker RequestTracker
+	deletePVTracker RequestTracker
 }
 
 // NewFakePVControl returns a FakePVControl
@@ -239,6 +254,7 @@ func NewFakePVControl(pvInformer coreinformers.PersistentVolumeInformer, pvcInfo
 		pvInformer.Informer().GetIndexer(),
 		RequestTracker{},
 		RequestTracker{},
+		RequestTracker{},
 	}
 }
 
@@ -318,6 +334,22 @@ func (c *FakePVControl) CreatePV(_ runtime.Object, pv *corev1.PersistentVolume)
 	return c.PVIndexer.Add(pv)
 }
 
+// SetDeletePVError sets the error attributes of deletePVTracker
+func (c *FakePVControl) SetDeletePVError(err error, after int) {
+	c.deletePVTracker.SetError(err).SetAfter(after)
+}
+
+// DeletePV deletes the pv
+func (c *FakePVControl) DeletePV(_ runtime.Object, pv *corev1.PersistentVolume) error {
+	defer c.deletePVTracker.Inc()
+	if c.deletePVTracker.ErrorReady() {
+		defer c.deletePVTracker.Reset()
+		return c.deletePVTracker.GetError()
+	}
+
+	return c.PVIndexer.Delete(pv)
+}
+
 func (c *FakePVControl) GetPV(name string) (*corev1.PersistentVolume, error) {
 	defer c.updatePVTracker.Inc()
 	obj, existed, err := c.PVIndexer.GetByKey(name)
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -156,6 +157,7 @@ func (c *realPVCControl) UpdateMetaInfo(controller runtime.Object, pvc *corev1.P
 	clusterID := pod.Labels[label.ClusterIDLabelKey]
 	storeID := pod.Labels[label.StoreIDLabelKey]
 	memberID := pod.Labels[label.MemberIDLabelKey]
+	cloudTags := pvcCloudTags(controller)
 
 	if pvc.Annotations == nil {
 		pvc.Annotations = make(map[string]string)
@@ -169,7 +171,8 @@ func (c *realPVCControl) UpdateMetaInfo(controller runtime.Object, pvc *corev1.P
 		pvc.Labels[label.MemberIDLabelKey] == memberID &&
 		pvc.Labels[label.StoreIDLabelKey] == storeID &&
 		pvc.Labels[label.AnnPodNameKey] == podName &&
-		pvc.Annotations[label.AnnPodNameKey] == podName {
+		pvc.Annotations[label.AnnPodNameKey] == podName &&
+		cloudTagsSynced(pvc.Annotations, cloudTags) {
 		klog.V(4).Infof("pvc %s/%s already has labels and annotations synced, skipping, %s: %s", namespace, pvcName, kind, name)
 		return pvc, nil
 	}
@@ -179,6 +182,9 @@ func (c *realPVCControl) UpdateMetaInfo(controller runtime.Object, pvc *corev1.P
 	setIfNotEmpty(pvc.Labels, label.StoreIDLabelKey, storeID)
 	setIfNotEmpty(pvc.Labels, label.AnnPodNameKey, podName)
 	setIfNotEmpty(pvc.Annotations, label.AnnPodNameKey, podName)
+	for k, v := range cloudTags {
+		pvc.Annotations[k] = v
+	}
 
 	labels := pvc.GetLabels()
 	ann := pvc.GetAnnotations()
@@ -206,6 +212,25 @@ func (c *realPVCControl) UpdateMetaInfo(controller runtime.Object, pvc *corev1.P
 	return updatePVC, err
 }
 
+// pvcCloudTags returns the cloud tags of the controller that are set as the annotations of its PVCs,
+// the CSI driver tags the volumes with them
+func pvcCloudTags(controller runtime.Object) map[string]string {
+	if tc, ok := controller.(*v1alpha1.TidbCluster); ok {
+		return tc.Spec.CloudTags
+	}
+	return nil
+}
+
+// cloudTagsSynced returns whether all the cloud tags are set in the annotations
+func cloudTagsSynced(anns, cloudTags map[string]string) bool {
+	for k, v := range cloudTags {
+		if val, ok := anns[k]; !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *realPVCControl) recordPVCEvent(verb, kind, name string, object runtime.Object, pvcName string, err error) {
 	if err == nil {
 		reason := fmt.Sprintf("Successful%s", strings.Title(verb))
@@ -284,7 +309,7 @@ func (c *FakePVCControl) CreatePVC(_ runtime.Object, pvc *corev1.PersistentVolum
 }
 
 // UpdateMetaInfo updates the meta info of pvc
-func (c *FakePVCControl) UpdateMetaInfo(_ runtime.Object, pvc *corev1.PersistentVolumeClaim, pod *corev1.Pod) (*corev1.PersistentVolumeClaim, error) {
+func (c *FakePVCControl) UpdateMetaInfo(controller runtime.Object, pvc *corev1.PersistentVolumeClaim, pod *corev1.Pod) (*corev1.PersistentVolumeClaim, error) {
 	defer c.updatePVCTracker.Inc()
 	if c.updatePVCTracker.ErrorReady() {
 		defer c.updatePVCTracker.Reset()
@@ -301,6 +326,9 @@ func (c *FakePVCControl) UpdateMetaInfo(_ runtime.Object, pvc *corev1.Persistent
 	setIfNotEmpty(pvc.Labels, label.StoreIDLabelKey, pod.Labels[label.StoreIDLabelKey])
 	setIfNotEmpty(pvc.Labels, label.AnnPodNameKey, pod.GetName())
 	setIfNotEmpty(pvc.Annotations, label.AnnPodNameKey, pod.GetName())
+	for k, v := range pvcCloudTags(controller) {
+		pvc.Annotations[k] = v
+	}
 	return nil, c.PVCIndexer.Update(pvc)
 }
 
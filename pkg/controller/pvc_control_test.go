@@ -50,6 +50,37 @@ func TestPVCControlUpdateMetaInfoSuccess(t *testing.T) {
 	g.Expect(updatePVC.Annotations[label.AnnPodNameKey]).To(Equal(pod.GetName()))
 }
 
+func TestPVCControlUpdateMetaInfoCloudTags(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbCluster()
+	tc.Spec.CloudTags = map[string]string{"team": "db"}
+	pvc := newPVC(tc)
+	pod := newPod(tc)
+	fakeClient, pvcLister, _, recorder := newFakeClientAndRecorder()
+	control := NewRealPVCControl(fakeClient, recorder, pvcLister)
+
+	updated := 0
+	fakeClient.AddReactor("update", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
+		updated++
+		update := action.(core.UpdateAction)
+		return true, update.GetObject(), nil
+	})
+	updatePVC, err := control.UpdateMetaInfo(tc, pvc, pod)
+	g.Expect(err).To(Succeed())
+	g.Expect(updatePVC.Annotations["team"]).To(Equal("db"))
+	g.Expect(updated).To(Equal(1))
+
+	// the PVC is updated again once the tags are changed
+	_, err = control.UpdateMetaInfo(tc, updatePVC, pod)
+	g.Expect(err).To(Succeed())
+	g.Expect(updated).To(Equal(1))
+	tc.Spec.CloudTags["team"] = "infra"
+	updatePVC, err = control.UpdateMetaInfo(tc, updatePVC, pod)
+	g.Expect(err).To(Succeed())
+	g.Expect(updatePVC.Annotations["team"]).To(Equal("infra"))
+	g.Expect(updated).To(Equal(2))
+}
+
 func TestPVCControlUpdateMetaInfoFailed(t *testing.T) {
 	g := NewGomegaWithT(t)
 	tc := newTidbCluster()
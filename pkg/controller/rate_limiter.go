@@ -14,9 +14,16 @@
 package controller
 
 import (
+	"context"
+	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 	"golang.org/x/time/rate"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"k8s.io/client-go/util/flowcontrol"
 	wq "k8s.io/client-go/util/workqueue"
 )
 
@@ -29,3 +36,70 @@ func NewControllerRateLimiter(baseDelay, maxDelay time.Duration) wq.RateLimiter
 		&wq.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
 	)
 }
+
+// KubeClientBucket is a token bucket of the requests to the Kubernetes API server shared by a group of controllers,
+// so that the controllers don't compete for the QPS of the Kubernetes API server with the controllers of other groups,
+// e.g. a large number of backups can't starve the updates of the StatefulSets of the clusters.
+type KubeClientBucket struct {
+	name    string
+	limiter flowcontrol.RateLimiter
+}
+
+// NewKubeClientBucket returns a KubeClientBucket with the given QPS and burst
+func NewKubeClientBucket(name string, qps float32, burst int) *KubeClientBucket {
+	return &KubeClientBucket{
+		name:    name,
+		limiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+	}
+}
+
+// Config returns a copy of the rest config for a controller of the group, whose requests are limited by the bucket.
+// The requests and the time spent waiting for the bucket are recorded in metrics with the bucket and the controller.
+func (b *KubeClientBucket) Config(cfg *rest.Config, controller string) *rest.Config {
+	c := rest.CopyConfig(cfg)
+	c.QPS = b.limiter.QPS()
+	c.RateLimiter = &instrumentedRateLimiter{
+		RateLimiter: b.limiter,
+		bucket:      b.name,
+		controller:  controller,
+	}
+	c.WrapTransport = transport.Wrappers(c.WrapTransport, func(rt http.RoundTripper) http.RoundTripper {
+		return &instrumentedRoundTripper{rt: rt, bucket: b.name, controller: controller}
+	})
+	return c
+}
+
+type instrumentedRateLimiter struct {
+	flowcontrol.RateLimiter
+	bucket     string
+	controller string
+}
+
+func (l *instrumentedRateLimiter) Accept() {
+	start := time.Now()
+	l.RateLimiter.Accept()
+	metrics.KubeAPIRateLimiterWait.WithLabelValues(l.bucket, l.controller).Observe(time.Since(start).Seconds())
+}
+
+func (l *instrumentedRateLimiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	err := l.RateLimiter.Wait(ctx)
+	metrics.KubeAPIRateLimiterWait.WithLabelValues(l.bucket, l.controller).Observe(time.Since(start).Seconds())
+	return err
+}
+
+type instrumentedRoundTripper struct {
+	rt         http.RoundTripper
+	bucket     string
+	controller string
+}
+
+func (r *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.rt.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.KubeAPIRequests.WithLabelValues(r.bucket, r.controller, req.Method, code).Inc()
+	return resp, err
+}
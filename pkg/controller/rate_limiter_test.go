@@ -0,0 +1,62 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestKubeClientBucket(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"ns"}}`))
+	}))
+	defer server.Close()
+
+	cfg := &rest.Config{Host: server.URL}
+	backupBucket := NewKubeClientBucket("test-backup", 10, 20)
+	defaultBucket := NewKubeClientBucket("test-default", 5, 10)
+	backupCfg := backupBucket.Config(cfg, "backup")
+	restoreCfg := backupBucket.Config(cfg, "restore")
+	defaultCfg := defaultBucket.Config(cfg, "tidbinitializer")
+	g.Expect(cfg.RateLimiter).Should(BeNil())
+	// the controllers of a group share the token bucket of the group
+	g.Expect(backupCfg.RateLimiter.(*instrumentedRateLimiter).RateLimiter).Should(BeIdenticalTo(restoreCfg.RateLimiter.(*instrumentedRateLimiter).RateLimiter))
+	g.Expect(backupCfg.RateLimiter.(*instrumentedRateLimiter).RateLimiter).ShouldNot(BeIdenticalTo(defaultCfg.RateLimiter.(*instrumentedRateLimiter).RateLimiter))
+	g.Expect(backupCfg.RateLimiter.QPS()).Should(Equal(float32(10)))
+	g.Expect(defaultCfg.RateLimiter.QPS()).Should(Equal(float32(5)))
+
+	for _, c := range []*rest.Config{backupCfg, backupCfg, restoreCfg} {
+		cli, err := kubernetes.NewForConfig(c)
+		g.Expect(err).ShouldNot(HaveOccurred())
+		_, err = cli.CoreV1().Pods("ns").Get(context.TODO(), "pod", metav1.GetOptions{})
+		g.Expect(err).ShouldNot(HaveOccurred())
+	}
+	g.Expect(testutil.ToFloat64(metrics.KubeAPIRequests.WithLabelValues("test-backup", "backup", http.MethodGet, "200"))).Should(Equal(float64(2)))
+	g.Expect(testutil.ToFloat64(metrics.KubeAPIRequests.WithLabelValues("test-backup", "restore", http.MethodGet, "200"))).Should(Equal(float64(1)))
+	g.Expect(testutil.ToFloat64(metrics.KubeAPIRequests.WithLabelValues("test-default", "tidbinitializer", http.MethodGet, "200"))).Should(Equal(float64(0)))
+	g.Expect(testutil.CollectAndCount(metrics.KubeAPIRateLimiterWait)).Should(Equal(2))
+}
@@ -145,6 +145,13 @@ func (c *Controller) updateRestore(cur interface{}) {
 		return
 	}
 
+	if (v1alpha1.IsRestoreComplete(newRestore) || v1alpha1.IsRestoreFailed(newRestore)) &&
+		newRestore.Status.ImportWindow != nil && newRestore.Status.ImportWindow.State == v1alpha1.ImportWindowApplied {
+		klog.V(4).Infof("restore %s/%s is finished, enqueue to restore the import window", ns, name)
+		c.enqueueRestore(newRestore)
+		return
+	}
+
 	if v1alpha1.IsRestoreComplete(newRestore) {
 		klog.V(4).Infof("restore %s/%s is Complete, skipping.", ns, name)
 		return
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions/pingcap/v1alpha1"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
@@ -39,6 +40,16 @@ type RestoreUpdateStatus struct {
 	TimeCompleted *metav1.Time
 	// CommitTs is the snapshot time point of tidb cluster.
 	CommitTs *string
+	// ImportWindow is the original values of the items tuned by the import window.
+	ImportWindow *v1alpha1.ImportWindowStatus
+	// ProgressStep is the step of the restore whose progress is reported.
+	ProgressStep *string
+	// Progress is the progress of the step.
+	Progress *float64
+	// ProgressUpdateTime is the time at which the progress was updated.
+	ProgressUpdateTime *metav1.Time
+	// ProgressEstimatedCompletionTime is the time at which the step is estimated to complete.
+	ProgressEstimatedCompletionTime *metav1.Time
 }
 
 // RestoreConditionUpdaterInterface enables updating Restore conditions.
@@ -69,8 +80,8 @@ func (u *realRestoreConditionUpdater) Update(restore *v1alpha1.Restore, conditio
 	restoreName := restore.GetName()
 	var isUpdate bool
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		updateRestoreStatus(&restore.Status, newStatus)
-		isUpdate = v1alpha1.UpdateRestoreCondition(&restore.Status, condition)
+		isStatusUpdate := updateRestoreStatus(&restore.Status, newStatus)
+		isUpdate = v1alpha1.UpdateRestoreCondition(&restore.Status, condition) || isStatusUpdate
 		if isUpdate {
 			_, updateErr := u.cli.PingcapV1alpha1().Restores(ns).Update(context.TODO(), restore, metav1.UpdateOptions{})
 			if updateErr == nil {
@@ -92,10 +103,11 @@ func (u *realRestoreConditionUpdater) Update(restore *v1alpha1.Restore, conditio
 }
 
 // updateRestoreStatus updates existing Restore status
-// from the fields in RestoreUpdateStatus.
-func updateRestoreStatus(status *v1alpha1.RestoreStatus, newStatus *RestoreUpdateStatus) {
+// from the fields in RestoreUpdateStatus, and returns
+// whether the fields that must be persisted immediately are changed.
+func updateRestoreStatus(status *v1alpha1.RestoreStatus, newStatus *RestoreUpdateStatus) bool {
 	if newStatus == nil {
-		return
+		return false
 	}
 	if newStatus.TimeStarted != nil {
 		status.TimeStarted = *newStatus.TimeStarted
@@ -106,6 +118,47 @@ func updateRestoreStatus(status *v1alpha1.RestoreStatus, newStatus *RestoreUpdat
 	if newStatus.CommitTs != nil {
 		status.CommitTs = *newStatus.CommitTs
 	}
+	// the original values of the import window are persisted even if the condition is not changed,
+	// otherwise they can not be restored after the restore finishes
+	isUpdate := false
+	if newStatus.ImportWindow != nil {
+		isUpdate = !apiequality.Semantic.DeepEqual(status.ImportWindow, newStatus.ImportWindow)
+		status.ImportWindow = newStatus.ImportWindow.DeepCopy()
+	}
+	// the progress is persisted even if the condition is not changed,
+	// as it changes while the restore is running
+	if newStatus.ProgressStep != nil && newStatus.Progress != nil {
+		isUpdate = updateRestoreProgress(status, *newStatus.ProgressStep, *newStatus.Progress, newStatus.ProgressUpdateTime, newStatus.ProgressEstimatedCompletionTime) || isUpdate
+	}
+	return isUpdate
+}
+
+// updateRestoreProgress updates the progress of the step in the Restore status,
+// and returns whether the progress is changed.
+func updateRestoreProgress(status *v1alpha1.RestoreStatus, step string, progress float64, updateTime, estimatedCompletionTime *metav1.Time) bool {
+	transitionTime := metav1.Now()
+	if updateTime != nil {
+		transitionTime = *updateTime
+	}
+	for i := range status.Progresses {
+		if status.Progresses[i].Step != step {
+			continue
+		}
+		if status.Progresses[i].Progress == progress {
+			return false
+		}
+		status.Progresses[i].Progress = progress
+		status.Progresses[i].LastTransitionTime = transitionTime
+		status.Progresses[i].EstimatedCompletionTime = estimatedCompletionTime.DeepCopy()
+		return true
+	}
+	status.Progresses = append(status.Progresses, v1alpha1.RestoreProgress{
+		Step:                    step,
+		Progress:                progress,
+		LastTransitionTime:      transitionTime,
+		EstimatedCompletionTime: estimatedCompletionTime.DeepCopy(),
+	})
+	return true
 }
 
 var _ RestoreConditionUpdaterInterface = &realRestoreConditionUpdater{}
@@ -29,6 +29,7 @@ func TestUpdateRestoreStatus(t *testing.T) {
 		status       *v1alpha1.RestoreStatus
 		updateStatus *RestoreUpdateStatus
 		expectStatus *v1alpha1.RestoreStatus
+		expectUpdate bool
 	}{
 		{
 			name:         "updateStatus is nil",
@@ -48,12 +49,66 @@ func TestUpdateRestoreStatus(t *testing.T) {
 			updateStatus: newUpdateRestoreStatus(),
 			expectStatus: newExpectRestoreStatus(),
 		},
+		{
+			name:   "import window is applied",
+			status: newRestoreStatus(),
+			updateStatus: &RestoreUpdateStatus{
+				ImportWindow: &v1alpha1.ImportWindowStatus{State: v1alpha1.ImportWindowApplied, PDSchedule: map[string]string{"region-schedule-limit": "2048"}},
+			},
+			expectStatus: func() *v1alpha1.RestoreStatus {
+				s := newRestoreStatus()
+				s.ImportWindow = &v1alpha1.ImportWindowStatus{State: v1alpha1.ImportWindowApplied, PDSchedule: map[string]string{"region-schedule-limit": "2048"}}
+				return s
+			}(),
+			expectUpdate: true,
+		},
+		{
+			name:         "progress of a new step",
+			status:       newRestoreStatus(),
+			updateStatus: newRestoreProgressStatus("Full Restore", 10),
+			expectStatus: func() *v1alpha1.RestoreStatus {
+				s := newRestoreStatus()
+				s.Progresses = []v1alpha1.RestoreProgress{newRestoreProgress("Full Restore", 10)}
+				return s
+			}(),
+			expectUpdate: true,
+		},
+		{
+			name: "progress of an existing step",
+			status: func() *v1alpha1.RestoreStatus {
+				s := newRestoreStatus()
+				s.Progresses = []v1alpha1.RestoreProgress{newRestoreProgress("Full Restore", 100), newRestoreProgress("Restore Meta Files", 10)}
+				return s
+			}(),
+			updateStatus: newRestoreProgressStatus("Restore Meta Files", 50),
+			expectStatus: func() *v1alpha1.RestoreStatus {
+				s := newRestoreStatus()
+				s.Progresses = []v1alpha1.RestoreProgress{newRestoreProgress("Full Restore", 100), newRestoreProgress("Restore Meta Files", 50)}
+				return s
+			}(),
+			expectUpdate: true,
+		},
+		{
+			name: "progress is not changed",
+			status: func() *v1alpha1.RestoreStatus {
+				s := newRestoreStatus()
+				s.Progresses = []v1alpha1.RestoreProgress{newRestoreProgress("Full Restore", 10)}
+				return s
+			}(),
+			updateStatus: newRestoreProgressStatus("Full Restore", 10),
+			expectStatus: func() *v1alpha1.RestoreStatus {
+				s := newRestoreStatus()
+				s.Progresses = []v1alpha1.RestoreProgress{newRestoreProgress("Full Restore", 10)}
+				return s
+			}(),
+		},
 	}
 
 	for _, test := range tests {
 		t.Logf("test: %+v", test.name)
-		updateRestoreStatus(test.status, test.updateStatus)
+		isUpdate := updateRestoreStatus(test.status, test.updateStatus)
 		g.Expect(*test.status).Should(Equal(*test.expectStatus))
+		g.Expect(isUpdate).Should(Equal(test.expectUpdate))
 	}
 }
 
@@ -68,6 +123,24 @@ func newUpdateRestoreStatus() *RestoreUpdateStatus {
 	}
 }
 
+func newRestoreProgressStatus(step string, progress float64) *RestoreUpdateStatus {
+	updateTime, _ := time.Parse(time.RFC3339, "2020-12-25T21:48:59Z")
+	return &RestoreUpdateStatus{
+		ProgressStep:       &step,
+		Progress:           &progress,
+		ProgressUpdateTime: &metav1.Time{Time: updateTime},
+	}
+}
+
+func newRestoreProgress(step string, progress float64) v1alpha1.RestoreProgress {
+	updateTime, _ := time.Parse(time.RFC3339, "2020-12-25T21:48:59Z")
+	return v1alpha1.RestoreProgress{
+		Step:               step,
+		Progress:           progress,
+		LastTransitionTime: metav1.Time{Time: updateTime},
+	}
+}
+
 func newRestoreStatus() *v1alpha1.RestoreStatus {
 	start, _ := time.Parse(time.RFC3339, "2020-12-25T12:46:59Z")
 	return &v1alpha1.RestoreStatus{
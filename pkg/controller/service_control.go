@@ -218,8 +218,13 @@ func (c *FakeServiceControl) UpdateService(_ runtime.Object, svc *corev1.Service
 }
 
 // DeleteService deletes the service of SvcIndexer
-func (c *FakeServiceControl) DeleteService(_ runtime.Object, _ *corev1.Service) error {
-	return nil
+func (c *FakeServiceControl) DeleteService(_ runtime.Object, svc *corev1.Service) error {
+	defer c.deleteStatefulSetTracker.Inc()
+	if c.deleteStatefulSetTracker.ErrorReady() {
+		defer c.deleteStatefulSetTracker.Reset()
+		return c.deleteStatefulSetTracker.GetError()
+	}
+	return c.SvcIndexer.Delete(svc)
 }
 
 var _ ServiceControlInterface = &FakeServiceControl{}
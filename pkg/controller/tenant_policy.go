@@ -0,0 +1,54 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TenantPolicy limits the TidbClusters in the namespaces of a tenant, the namespaces are assigned to
+// the tenants by the annotation tidb.pingcap.com/tenant
+type TenantPolicy struct {
+	// MaxClusters is the max number of the TidbClusters of the tenant, 0 means unlimited.
+	// The StatefulSets of the clusters created after the limit is reached are not created.
+	MaxClusters int `json:"maxClusters,omitempty"`
+	// MaxTiKVReplicas is the max total TiKV replicas of the TidbClusters of the tenant, including the
+	// replicas of the cold groups, 0 means unlimited. TiKV is not scaled out beyond the limit.
+	MaxTiKVReplicas int32 `json:"maxTiKVReplicas,omitempty"`
+	// AllowedStorageClasses are the storage classes the TidbClusters of the tenant can use, empty means
+	// all the storage classes are allowed. The components using the default storage class are not checked.
+	// The StatefulSets of the clusters using the other storage classes are not created.
+	AllowedStorageClasses []string `json:"allowedStorageClasses,omitempty"`
+}
+
+// ParseTenantPolicies parses the tenant policies in JSON, which is an object keyed by the tenant IDs
+func ParseTenantPolicies(data string) (map[string]TenantPolicy, error) {
+	if data == "" {
+		return nil, nil
+	}
+	policies := map[string]TenantPolicy{}
+	if err := json.Unmarshal([]byte(data), &policies); err != nil {
+		return nil, fmt.Errorf("failed to parse tenant policies %q: %v", data, err)
+	}
+	for tenant, policy := range policies {
+		if tenant == "" {
+			return nil, fmt.Errorf("tenant ID of the tenant policies can't be empty")
+		}
+		if policy.MaxClusters < 0 || policy.MaxTiKVReplicas < 0 {
+			return nil, fmt.Errorf("limits of the policy of tenant %s can't be negative", tenant)
+		}
+	}
+	return policies, nil
+}
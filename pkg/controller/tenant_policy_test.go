@@ -0,0 +1,45 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseTenantPolicies(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	policies, err := ParseTenantPolicies("")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policies).To(BeEmpty())
+
+	policies, err = ParseTenantPolicies(`{"team-a":{"maxClusters":3,"maxTiKVReplicas":15,"allowedStorageClasses":["ssd"]},"team-b":{}}`)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policies).To(Equal(map[string]TenantPolicy{
+		"team-a": {MaxClusters: 3, MaxTiKVReplicas: 15, AllowedStorageClasses: []string{"ssd"}},
+		"team-b": {},
+	}))
+
+	for _, data := range []string{
+		`[]`,
+		`{"":{"maxClusters":1}}`,
+		`{"team-a":{"maxClusters":-1}}`,
+		`{"team-a":{"maxTiKVReplicas":-1}}`,
+	} {
+		_, err = ParseTenantPolicies(data)
+		g.Expect(err).To(HaveOccurred(), data)
+	}
+}
@@ -14,10 +14,15 @@
 package controller
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 )
 
@@ -27,10 +32,58 @@ type CaptureStatus struct {
 	IsOwner bool   `json:"is_owner"`
 }
 
+type captureInfo struct {
+	ID      string `json:"id"`
+	IsOwner bool   `json:"is_owner"`
+}
+
+type drainCaptureRequest struct {
+	CaptureID string `json:"capture_id"`
+}
+
+type drainCaptureResp struct {
+	CurrentTableCount int `json:"current_table_count"`
+}
+
+const (
+	// ChangefeedStateNormal is the state of the running changefeeds
+	ChangefeedStateNormal = "normal"
+	// ChangefeedStateStopped is the state of the paused changefeeds
+	ChangefeedStateStopped = "stopped"
+)
+
+// ChangefeedDetail is the detail of a changefeed returned by the ticdc open API
+type ChangefeedDetail struct {
+	ID      string `json:"id"`
+	SinkURI string `json:"sink_uri"`
+	State   string `json:"state"`
+}
+
+type updateChangefeedRequest struct {
+	SinkURI string `json:"sink_uri"`
+}
+
 // TiCDCControlInterface is the interface that knows how to manage ticdc captures
 type TiCDCControlInterface interface {
 	// GetStatus returns ticdc's status
 	GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error)
+	// ResignOwner resigns the ownership of the capture, it returns true if the capture is not the owner,
+	// otherwise the caller should retry until the ownership is taken by another capture.
+	ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) (ok bool, err error)
+	// DrainCapture moves the tables of the capture to the other captures, and returns the number of the tables
+	// remaining in the capture. It returns 0 if there are no other captures or the drain API is not supported,
+	// and retry is true if the capture can't be drained for now, e.g. it's still the owner.
+	DrainCapture(tc *v1alpha1.TidbCluster, ordinal int32) (tableCount int, retry bool, err error)
+	// GetChangefeed returns the detail of the changefeed
+	GetChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) (*ChangefeedDetail, error)
+	// PauseChangefeed pauses the changefeed, the pause is asynchronous
+	PauseChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) error
+	// ResumeChangefeed resumes the changefeed from its checkpoint, the resume is asynchronous
+	ResumeChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) error
+	// UpdateChangefeedSinkURI updates the sink URI of the changefeed in place, e.g. when the credentials of the
+	// sink are rotated, so that the changefeed continues from its checkpoint instead of being recreated. The
+	// changefeed must be paused before the update.
+	UpdateChangefeedSinkURI(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID, sinkURI string) error
 }
 
 // defaultTiCDCControl is default implementation of TiCDCControlInterface.
@@ -63,6 +116,173 @@ func (c *defaultTiCDCControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32)
 	return &status, err
 }
 
+func (c *defaultTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return false, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	status, err := c.GetStatus(tc, ordinal)
+	if err != nil {
+		return false, err
+	}
+	if !status.IsOwner {
+		return true, nil
+	}
+	captures, err := getCaptures(httpClient, baseURL)
+	if err != nil {
+		return false, err
+	}
+	if len(captures) <= 1 {
+		// no other capture can take over the ownership
+		return true, nil
+	}
+
+	url := fmt.Sprintf("%s/api/v1/owner/resign", baseURL)
+	_, _, err = doCaptureRequest(httpClient, "POST", url, nil, http.StatusAccepted, http.StatusOK)
+	return false, err
+}
+
+func (c *defaultTiCDCControl) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return 0, false, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	status, err := c.GetStatus(tc, ordinal)
+	if err != nil {
+		return 0, false, err
+	}
+	captures, err := getCaptures(httpClient, baseURL)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(captures) <= 1 {
+		// no other capture can take over the tables
+		return 0, false, nil
+	}
+	if status.IsOwner {
+		// the owner can't be drained, it must resign the ownership first
+		return 0, true, nil
+	}
+
+	url := fmt.Sprintf("%s/api/v1/captures/drain", baseURL)
+	data, err := json.Marshal(drainCaptureRequest{CaptureID: status.ID})
+	if err != nil {
+		return 0, false, err
+	}
+	code, body, err := doCaptureRequest(httpClient, "PUT", url, data, http.StatusAccepted, http.StatusOK, http.StatusNotFound, http.StatusServiceUnavailable)
+	if err != nil {
+		return 0, false, err
+	}
+	switch code {
+	case http.StatusNotFound:
+		// the drain API is only supported since TiCDC v6.2.0
+		return 0, false, nil
+	case http.StatusServiceUnavailable:
+		// the owner is not ready or another capture is being drained
+		return 0, true, nil
+	}
+	resp := drainCaptureResp{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, false, err
+	}
+	return resp.CurrentTableCount, false, nil
+}
+
+func (c *defaultTiCDCControl) GetChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) (*ChangefeedDetail, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := getBodyOK(httpClient, c.getChangefeedURL(tc, ordinal, changefeedID))
+	if err != nil {
+		return nil, err
+	}
+	detail := ChangefeedDetail{}
+	if err := json.Unmarshal(body, &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+func (c *defaultTiCDCControl) PauseChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = doCaptureRequest(httpClient, "POST", c.getChangefeedURL(tc, ordinal, changefeedID)+"/pause", nil, http.StatusAccepted, http.StatusOK)
+	return err
+}
+
+func (c *defaultTiCDCControl) ResumeChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = doCaptureRequest(httpClient, "POST", c.getChangefeedURL(tc, ordinal, changefeedID)+"/resume", nil, http.StatusAccepted, http.StatusOK)
+	return err
+}
+
+func (c *defaultTiCDCControl) UpdateChangefeedSinkURI(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID, sinkURI string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(updateChangefeedRequest{SinkURI: sinkURI})
+	if err != nil {
+		return err
+	}
+	_, _, err = doCaptureRequest(httpClient, "PUT", c.getChangefeedURL(tc, ordinal, changefeedID), data, http.StatusAccepted, http.StatusOK)
+	return err
+}
+
+func (c *defaultTiCDCControl) getChangefeedURL(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) string {
+	return fmt.Sprintf("%s/api/v1/changefeeds/%s", c.getBaseURL(tc, ordinal), url.PathEscape(changefeedID))
+}
+
+// getCaptures returns the captures of the ticdc cluster
+func getCaptures(httpClient *http.Client, baseURL string) ([]captureInfo, error) {
+	body, err := getBodyOK(httpClient, fmt.Sprintf("%s/api/v1/captures", baseURL))
+	if err != nil {
+		return nil, err
+	}
+	captures := []captureInfo{}
+	err = json.Unmarshal(body, &captures)
+	return captures, err
+}
+
+// doCaptureRequest sends the request to the ticdc open API, and returns an error if the status code of the
+// response is not one of the expected codes
+func doCaptureRequest(httpClient *http.Client, method, url string, data []byte, expectedCodes ...int) (int, []byte, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer httputil.DeferClose(res.Body)
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, code := range expectedCodes {
+		if res.StatusCode == code {
+			return res.StatusCode, body, nil
+		}
+	}
+	return res.StatusCode, nil, fmt.Errorf("Error response %s:%v URL: %s", string(body), res.StatusCode, url)
+}
+
 func (c *defaultTiCDCControl) getBaseURL(tc *v1alpha1.TidbCluster, ordinal int32) string {
 	if c.testURL != "" {
 		return c.testURL
@@ -78,7 +298,11 @@ func (c *defaultTiCDCControl) getBaseURL(tc *v1alpha1.TidbCluster, ordinal int32
 
 // FakeTiCDCControl is a fake implementation of TiCDCControlInterface.
 type FakeTiCDCControl struct {
-	getStatus func(tc *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error)
+	getStatus    func(tc *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error)
+	resignOwner  func(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error)
+	drainCapture func(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error)
+	// changefeeds is the changefeeds managed by the fake ticdc open API, keyed by the changefeed IDs
+	changefeeds map[string]*ChangefeedDetail
 }
 
 // NewFakeTiCDCControl returns a FakeTiCDCControl instance
@@ -97,3 +321,84 @@ func (c *FakeTiCDCControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*
 	}
 	return c.getStatus(tc, ordinal)
 }
+
+// MockResignOwner mocks the ResignOwner of FakeTiCDCControl
+func (c *FakeTiCDCControl) MockResignOwner(mockfunc func(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error)) {
+	c.resignOwner = mockfunc
+}
+
+// ResignOwner succeeds unless it's mocked
+func (c *FakeTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	if c.resignOwner == nil {
+		return true, nil
+	}
+	return c.resignOwner(tc, ordinal)
+}
+
+// MockDrainCapture mocks the DrainCapture of FakeTiCDCControl
+func (c *FakeTiCDCControl) MockDrainCapture(mockfunc func(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error)) {
+	c.drainCapture = mockfunc
+}
+
+// DrainCapture succeeds unless it's mocked
+func (c *FakeTiCDCControl) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error) {
+	if c.drainCapture == nil {
+		return 0, false, nil
+	}
+	return c.drainCapture(tc, ordinal)
+}
+
+// SetChangefeed adds or replaces the changefeed managed by FakeTiCDCControl
+func (c *FakeTiCDCControl) SetChangefeed(detail ChangefeedDetail) {
+	if c.changefeeds == nil {
+		c.changefeeds = map[string]*ChangefeedDetail{}
+	}
+	c.changefeeds[detail.ID] = &detail
+}
+
+// GetChangefeed returns the changefeed set by SetChangefeed
+func (c *FakeTiCDCControl) GetChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) (*ChangefeedDetail, error) {
+	detail, ok := c.changefeeds[changefeedID]
+	if !ok {
+		return nil, fmt.Errorf("changefeed %s not found", changefeedID)
+	}
+	d := *detail
+	return &d, nil
+}
+
+// PauseChangefeed stops the running changefeed at once
+func (c *FakeTiCDCControl) PauseChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) error {
+	detail, ok := c.changefeeds[changefeedID]
+	if !ok {
+		return fmt.Errorf("changefeed %s not found", changefeedID)
+	}
+	if detail.State == ChangefeedStateNormal {
+		detail.State = ChangefeedStateStopped
+	}
+	return nil
+}
+
+// ResumeChangefeed runs the stopped changefeed at once
+func (c *FakeTiCDCControl) ResumeChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID string) error {
+	detail, ok := c.changefeeds[changefeedID]
+	if !ok {
+		return fmt.Errorf("changefeed %s not found", changefeedID)
+	}
+	if detail.State == ChangefeedStateStopped {
+		detail.State = ChangefeedStateNormal
+	}
+	return nil
+}
+
+// UpdateChangefeedSinkURI updates the sink URI of the stopped changefeed
+func (c *FakeTiCDCControl) UpdateChangefeedSinkURI(tc *v1alpha1.TidbCluster, ordinal int32, changefeedID, sinkURI string) error {
+	detail, ok := c.changefeeds[changefeedID]
+	if !ok {
+		return fmt.Errorf("changefeed %s not found", changefeedID)
+	}
+	if detail.State != ChangefeedStateStopped {
+		return fmt.Errorf("changefeed %s is not stopped", changefeedID)
+	}
+	detail.SinkURI = sinkURI
+	return nil
+}
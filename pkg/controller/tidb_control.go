@@ -14,12 +14,16 @@
 package controller
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	"github.com/pingcap/tidb/config"
@@ -37,6 +41,27 @@ type DBInfo struct {
 	IsOwner bool `json:"is_owner"`
 }
 
+// schemaName is the name of a database or a table returned by the schema API of TiDB
+type schemaName struct {
+	O string `json:"O"`
+}
+
+type schemaDBInfo struct {
+	Name schemaName `json:"db_name"`
+}
+
+type schemaTableInfo struct {
+	ID        int64                `json:"id"`
+	Name      schemaName           `json:"name"`
+	Partition *schemaPartitionInfo `json:"partition"`
+}
+
+type schemaPartitionInfo struct {
+	Definitions []struct {
+		ID int64 `json:"id"`
+	} `json:"definitions"`
+}
+
 // TiDBControlInterface is the interface that knows how to manage tidb peers
 type TiDBControlInterface interface {
 	// GetHealth returns tidb's health info
@@ -45,6 +70,31 @@ type TiDBControlInterface interface {
 	GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBInfo, error)
 	// GetSettings return the TiDB instance settings
 	GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error)
+	// GetConfig returns the config that the TiDB instance is running with, keyed by the TOML names
+	GetConfig(tc *v1alpha1.TidbCluster, ordinal int32) (map[string]interface{}, error)
+	// GetTables returns the names of the tables of all the databases, keyed by the names of the databases
+	GetTables(tc *v1alpha1.TidbCluster, ordinal int32) (map[string][]string, error)
+	// GetTableIDs returns the IDs of the physical tables of the table, i.e. the IDs of the partitions if the
+	// table is partitioned, otherwise the ID of the table
+	GetTableIDs(tc *v1alpha1.TidbCluster, ordinal int32, db, table string) ([]int64, error)
+	// GetSuperReadOnly returns whether the tidb_super_read_only system variable is on in the TiDB instance
+	GetSuperReadOnly(tc *v1alpha1.TidbCluster, ordinal int32, user, password string) (bool, error)
+	// SetSuperReadOnly sets the global tidb_super_read_only system variable through the TiDB instance
+	SetSuperReadOnly(tc *v1alpha1.TidbCluster, ordinal int32, user, password string, readOnly bool) error
+	// SyncUser creates the user if it doesn't exist, resets its password and grants the privileges through the
+	// TiDB instance, it returns whether the user is created
+	SyncUser(tc *v1alpha1.TidbCluster, ordinal int32, user, password string, sqlUser SQLUser) (bool, error)
+	// SetTikvGCLifeTime sets the tikv_gc_life_time of the cluster accessed by the config
+	SetTikvGCLifeTime(from *v1alpha1.TiDBAccessConfig, password, gcLifeTime string) error
+}
+
+// SQLUser is a user of TiDB and the privileges kept granted to it
+type SQLUser struct {
+	Name     string
+	Host     string
+	Password string
+	// Grants are the privileges granted to the user, each in the form of <privileges> ON <object>
+	Grants []string
 }
 
 // defaultTiDBControl is default implementation of TiDBControlInterface.
@@ -137,6 +187,190 @@ func (c *defaultTiDBControl) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32
 	return &info, nil
 }
 
+func (c *defaultTiDBControl) GetConfig(tc *v1alpha1.TidbCluster, ordinal int32) (map[string]interface{}, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	url := fmt.Sprintf("%s/config", baseURL)
+	body, err := getBodyOK(httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+	cfg := map[string]interface{}{}
+	err = json.Unmarshal(body, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *defaultTiDBControl) GetTables(tc *v1alpha1.TidbCluster, ordinal int32) (map[string][]string, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	body, err := getBodyOK(httpClient, fmt.Sprintf("%s/schema", baseURL))
+	if err != nil {
+		return nil, err
+	}
+	dbs := []schemaDBInfo{}
+	if err := json.Unmarshal(body, &dbs); err != nil {
+		return nil, err
+	}
+
+	tables := make(map[string][]string, len(dbs))
+	for _, db := range dbs {
+		body, err := getBodyOK(httpClient, fmt.Sprintf("%s/schema/%s", baseURL, url.PathEscape(db.Name.O)))
+		if err != nil {
+			return nil, err
+		}
+		infos := []schemaTableInfo{}
+		if err := json.Unmarshal(body, &infos); err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(infos))
+		for _, info := range infos {
+			names = append(names, info.Name.O)
+		}
+		tables[db.Name.O] = names
+	}
+	return tables, nil
+}
+
+func (c *defaultTiDBControl) GetTableIDs(tc *v1alpha1.TidbCluster, ordinal int32, db, table string) ([]int64, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	body, err := getBodyOK(httpClient, fmt.Sprintf("%s/schema/%s/%s", baseURL, url.PathEscape(db), url.PathEscape(table)))
+	if err != nil {
+		return nil, err
+	}
+	info := schemaTableInfo{}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+	if info.Partition == nil || len(info.Partition.Definitions) == 0 {
+		return []int64{info.ID}, nil
+	}
+	ids := make([]int64, 0, len(info.Partition.Definitions))
+	for _, def := range info.Partition.Definitions {
+		ids = append(ids, def.ID)
+	}
+	return ids, nil
+}
+
+func (c *defaultTiDBControl) GetSuperReadOnly(tc *v1alpha1.TidbCluster, ordinal int32, user, password string) (bool, error) {
+	db, err := c.openDB(tc, ordinal, user, password)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	// the boolean system variables are shown as ON or OFF
+	var value string
+	if err := db.QueryRow("SELECT @@global.tidb_super_read_only").Scan(&value); err != nil {
+		return false, err
+	}
+	return strings.EqualFold(value, "ON") || value == "1", nil
+}
+
+func (c *defaultTiDBControl) SetSuperReadOnly(tc *v1alpha1.TidbCluster, ordinal int32, user, password string, readOnly bool) error {
+	db, err := c.openDB(tc, ordinal, user, password)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	value := "OFF"
+	if readOnly {
+		value = "ON"
+	}
+	_, err = db.Exec("SET GLOBAL tidb_super_read_only = " + value)
+	return err
+}
+
+func (c *defaultTiDBControl) SyncUser(tc *v1alpha1.TidbCluster, ordinal int32, user, password string, sqlUser SQLUser) (bool, error) {
+	db, err := c.openDB(tc, ordinal, user, password)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM mysql.user WHERE User = ? AND Host = ?", sqlUser.Name, sqlUser.Host).Scan(&count); err != nil {
+		return false, err
+	}
+	// the account names and the passwords can't be placeholders of the statements
+	account := fmt.Sprintf("%s@%s", quoteSQLString(sqlUser.Name), quoteSQLString(sqlUser.Host))
+	stmt := "ALTER USER "
+	if count == 0 {
+		stmt = "CREATE USER "
+	}
+	if _, err := db.Exec(stmt + account + " IDENTIFIED BY " + quoteSQLString(sqlUser.Password)); err != nil {
+		return false, err
+	}
+	for _, grant := range sqlUser.Grants {
+		if _, err := db.Exec(fmt.Sprintf("GRANT %s TO %s", grant, account)); err != nil {
+			return false, fmt.Errorf("failed to grant %s to %s, error: %v", grant, account, err)
+		}
+	}
+	return count == 0, nil
+}
+
+func (c *defaultTiDBControl) SetTikvGCLifeTime(from *v1alpha1.TiDBAccessConfig, password, gcLifeTime string) error {
+	user := from.User
+	if user == "" {
+		user = v1alpha1.DefaultTidbUser
+	}
+	port := from.Port
+	if port == 0 {
+		port = v1alpha1.DefaultTidbPort
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s:%d", from.Host, port)
+	cfg.Timeout = timeout
+	cfg.ReadTimeout = timeout
+	cfg.TLSConfig = "preferred"
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("UPDATE mysql.tidb SET variable_value = ? WHERE variable_name = 'tikv_gc_life_time'", gcLifeTime)
+	return err
+}
+
+// quoteSQLString quotes s as a string literal of SQL
+func quoteSQLString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// openDB returns the connection to the TiDB instance, TLS is used if the instance supports it
+func (c *defaultTiDBControl) openDB(tc *v1alpha1.TidbCluster, ordinal int32, user, password string) (*sql.DB, error) {
+	tcName := tc.GetName()
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s-%d.%s.%s:%d", TiDBMemberName(tcName), ordinal, TiDBPeerMemberName(tcName), tc.GetNamespace(), tc.TiDBPort())
+	cfg.Timeout = timeout
+	cfg.ReadTimeout = timeout
+	cfg.TLSConfig = "preferred"
+	return sql.Open("mysql", cfg.FormatDSN())
+}
+
 func getBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
 	res, err := httpClient.Get(apiURL)
 	if err != nil {
@@ -165,7 +399,7 @@ func (c *defaultTiDBControl) getBaseURL(tc *v1alpha1.TidbCluster, ordinal int32)
 	scheme := tc.Scheme()
 	hostName := fmt.Sprintf("%s-%d", TiDBMemberName(tcName), ordinal)
 
-	return fmt.Sprintf("%s://%s.%s.%s:10080", scheme, hostName, TiDBPeerMemberName(tcName), ns)
+	return fmt.Sprintf("%s://%s.%s.%s:%d", scheme, hostName, TiDBPeerMemberName(tcName), ns, tc.TiDBStatusPort())
 }
 
 // FakeTiDBControl is a fake implementation of TiDBControlInterface.
@@ -174,6 +408,16 @@ type FakeTiDBControl struct {
 	tiDBInfo     *DBInfo
 	getInfoError error
 	tidbConfig   *config.Config
+	liveConfig   map[string]interface{}
+	tables       map[string][]string
+	// tableIDs is the IDs of the physical tables, keyed by db.table
+	tableIDs map[string][]int64
+	// superReadOnly is the tidb_super_read_only system variable seen by the instances, keyed by the pod names
+	superReadOnly map[string]bool
+	// users are the users synced by SyncUser, keyed by name@host
+	users map[string]SQLUser
+	// tikvGCLifeTimes are the tikv_gc_life_time set by SetTikvGCLifeTime, keyed by host:port
+	tikvGCLifeTimes map[string]string
 }
 
 // NewFakeTiDBControl returns a FakeTiDBControl instance
@@ -197,6 +441,11 @@ func (c *FakeTiDBControl) GetHealth(tc *v1alpha1.TidbCluster, ordinal int32) (bo
 	return false, nil
 }
 
+// SetLiveConfig sets the config returned by GetConfig for FakeTiDBControl
+func (c *FakeTiDBControl) SetLiveConfig(cfg map[string]interface{}) {
+	c.liveConfig = cfg
+}
+
 func (c *FakeTiDBControl) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBInfo, error) {
 	return c.tiDBInfo, c.getInfoError
 }
@@ -204,3 +453,99 @@ func (c *FakeTiDBControl) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBI
 func (c *FakeTiDBControl) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error) {
 	return c.tidbConfig, c.getInfoError
 }
+
+func (c *FakeTiDBControl) GetConfig(tc *v1alpha1.TidbCluster, ordinal int32) (map[string]interface{}, error) {
+	return c.liveConfig, c.getInfoError
+}
+
+// SetTables sets the tables returned by GetTables for FakeTiDBControl
+func (c *FakeTiDBControl) SetTables(tables map[string][]string) {
+	c.tables = tables
+}
+
+func (c *FakeTiDBControl) GetTables(tc *v1alpha1.TidbCluster, ordinal int32) (map[string][]string, error) {
+	return c.tables, c.getInfoError
+}
+
+// SetTableIDs sets the IDs returned by GetTableIDs for FakeTiDBControl, keyed by db.table
+func (c *FakeTiDBControl) SetTableIDs(tableIDs map[string][]int64) {
+	c.tableIDs = tableIDs
+}
+
+func (c *FakeTiDBControl) GetTableIDs(tc *v1alpha1.TidbCluster, ordinal int32, db, table string) ([]int64, error) {
+	if c.getInfoError != nil {
+		return nil, c.getInfoError
+	}
+	ids, ok := c.tableIDs[db+"."+table]
+	if !ok {
+		return nil, fmt.Errorf("table %s.%s doesn't exist", db, table)
+	}
+	return ids, nil
+}
+
+// SetSuperReadOnlyOfPod sets the tidb_super_read_only system variable seen by the TiDB instance for FakeTiDBControl
+func (c *FakeTiDBControl) SetSuperReadOnlyOfPod(podName string, readOnly bool) {
+	if c.superReadOnly == nil {
+		c.superReadOnly = map[string]bool{}
+	}
+	c.superReadOnly[podName] = readOnly
+}
+
+func (c *FakeTiDBControl) GetSuperReadOnly(tc *v1alpha1.TidbCluster, ordinal int32, user, password string) (bool, error) {
+	podName := fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal)
+	return c.superReadOnly[podName], c.getInfoError
+}
+
+// SetSuperReadOnly sets the variable seen by the TiDB instance only, the others are updated by SetSuperReadOnlyOfPod
+func (c *FakeTiDBControl) SetSuperReadOnly(tc *v1alpha1.TidbCluster, ordinal int32, user, password string, readOnly bool) error {
+	if c.getInfoError != nil {
+		return c.getInfoError
+	}
+	c.SetSuperReadOnlyOfPod(fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal), readOnly)
+	return nil
+}
+
+// GetUser returns the user synced by SyncUser for FakeTiDBControl
+func (c *FakeTiDBControl) GetUser(name, host string) (SQLUser, bool) {
+	u, ok := c.users[name+"@"+host]
+	return u, ok
+}
+
+// DropUser drops the user as if it's dropped manually for FakeTiDBControl
+func (c *FakeTiDBControl) DropUser(name, host string) {
+	delete(c.users, name+"@"+host)
+}
+
+func (c *FakeTiDBControl) SyncUser(tc *v1alpha1.TidbCluster, ordinal int32, user, password string, sqlUser SQLUser) (bool, error) {
+	if c.getInfoError != nil {
+		return false, c.getInfoError
+	}
+	if c.users == nil {
+		c.users = map[string]SQLUser{}
+	}
+	key := sqlUser.Name + "@" + sqlUser.Host
+	_, exist := c.users[key]
+	c.users[key] = sqlUser
+	return !exist, nil
+}
+
+// GetTikvGCLifeTime returns the tikv_gc_life_time set by SetTikvGCLifeTime for FakeTiDBControl
+func (c *FakeTiDBControl) GetTikvGCLifeTime(host string, port int32) (string, bool) {
+	gcLifeTime, ok := c.tikvGCLifeTimes[fmt.Sprintf("%s:%d", host, port)]
+	return gcLifeTime, ok
+}
+
+func (c *FakeTiDBControl) SetTikvGCLifeTime(from *v1alpha1.TiDBAccessConfig, password, gcLifeTime string) error {
+	if c.getInfoError != nil {
+		return c.getInfoError
+	}
+	if c.tikvGCLifeTimes == nil {
+		c.tikvGCLifeTimes = map[string]string{}
+	}
+	port := from.Port
+	if port == 0 {
+		port = v1alpha1.DefaultTidbPort
+	}
+	c.tikvGCLifeTimes[fmt.Sprintf("%s:%d", from.Host, port)] = gcLifeTime
+	return nil
+}
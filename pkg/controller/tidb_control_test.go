@@ -268,6 +268,136 @@ func TestSettings(t *testing.T) {
 	}
 }
 
+func TestGetConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cases := []struct {
+		caseName string
+		failed   bool
+		resp     string
+		expected map[string]interface{}
+	}{
+		{
+			caseName: "GetConfig",
+			failed:   false,
+			resp:     `{"host":"host1","tikv-client":{"copr-cache":{"capacity-mb":1000}}}`,
+			expected: map[string]interface{}{
+				"host": "host1",
+				"tikv-client": map[string]interface{}{
+					"copr-cache": map[string]interface{}{"capacity-mb": float64(1000)},
+				},
+			},
+		},
+		{
+			caseName: "GetConfig",
+			failed:   true,
+			expected: nil,
+		},
+	}
+
+	for _, c := range cases {
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal("GET"), "check method")
+			g.Expect(request.URL.Path).To(Equal("/config"), "check url")
+
+			w.Header().Set("Content-Type", ContentTypeJSON)
+			if c.failed {
+				w.WriteHeader(http.StatusInternalServerError)
+			} else {
+				w.Write([]byte(c.resp))
+			}
+		})
+		defer svc.Close()
+
+		fakeClient := &fake.Clientset{}
+		informer := kubeinformers.NewSharedInformerFactory(fakeClient, 0)
+		control := NewDefaultTiDBControl(informer.Core().V1().Secrets().Lister())
+		control.testURL = svc.URL
+		tc := getTidbCluster()
+		result, err := control.GetConfig(tc, 0)
+		if c.failed {
+			g.Expect(err).To(HaveOccurred())
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		g.Expect(result).To(Equal(c.expected))
+	}
+}
+
+func TestGetTables(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("GET"), "check method")
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		switch request.URL.Path {
+		case "/schema":
+			w.Write([]byte(`[{"id":1,"db_name":{"O":"Test","L":"test"}},{"id":2,"db_name":{"O":"empty","L":"empty"}}]`))
+		case "/schema/Test":
+			w.Write([]byte(`[{"id":3,"name":{"O":"T1","L":"t1"}},{"id":4,"name":{"O":"t2","L":"t2"}}]`))
+		case "/schema/empty":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer svc.Close()
+
+	fakeClient := &fake.Clientset{}
+	informer := kubeinformers.NewSharedInformerFactory(fakeClient, 0)
+	control := NewDefaultTiDBControl(informer.Core().V1().Secrets().Lister())
+	control.testURL = svc.URL
+	tc := getTidbCluster()
+	result, err := control.GetTables(tc, 0)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(map[string][]string{
+		"Test":  {"T1", "t2"},
+		"empty": {},
+	}))
+}
+
+func TestGetTableIDs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("GET"), "check method")
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		switch request.URL.Path {
+		case "/schema/test/t1":
+			w.Write([]byte(`{"id":3,"name":{"O":"t1","L":"t1"},"partition":null}`))
+		case "/schema/test/p1":
+			w.Write([]byte(`{"id":4,"name":{"O":"p1","L":"p1"},"partition":{"definitions":[{"id":5,"name":{"O":"p0"}},{"id":6,"name":{"O":"p1"}}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer svc.Close()
+
+	fakeClient := &fake.Clientset{}
+	informer := kubeinformers.NewSharedInformerFactory(fakeClient, 0)
+	control := NewDefaultTiDBControl(informer.Core().V1().Secrets().Lister())
+	control.testURL = svc.URL
+	tc := getTidbCluster()
+	ids, err := control.GetTableIDs(tc, 0, "test", "t1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ids).To(Equal([]int64{3}))
+	ids, err = control.GetTableIDs(tc, 0, "test", "p1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ids).To(Equal([]int64{5, 6}))
+	_, err = control.GetTableIDs(tc, 0, "test", "t2")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestQuoteSQLString(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(quoteSQLString("app")).To(Equal(`'app'`))
+	g.Expect(quoteSQLString("%")).To(Equal(`'%'`))
+	g.Expect(quoteSQLString(`p'a\ss`)).To(Equal(`'p\'a\\ss'`))
+}
+
 func TestGetHTTPClient(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -235,7 +235,7 @@ func (c *PodController) syncTiKVPod(ctx context.Context, pod *corev1.Pod, tc *v1
 			tc.Status.TiKV.EvictLeader[pod.Name] = evictStatus
 			var err error
 			key := fmt.Sprintf("%s/%s", tc.Namespace, tc.Name)
-			tc, err = c.deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Update(ctx, tc, metav1.UpdateOptions{})
+			tc, err = c.deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).UpdateStatus(ctx, tc, metav1.UpdateOptions{})
 			if err != nil {
 				return reconcile.Result{}, perrors.Annotatef(err, "failed to update tc %q status", key)
 			}
@@ -292,7 +292,7 @@ func (c *PodController) syncTiKVPod(ctx context.Context, pod *corev1.Pod, tc *v1
 
 			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 				delete(tc.Status.TiKV.EvictLeader, pod.Name)
-				_, updateErr := c.deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Update(ctx, tc, metav1.UpdateOptions{})
+				_, updateErr := c.deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).UpdateStatus(ctx, tc, metav1.UpdateOptions{})
 				if updateErr == nil {
 					return nil
 				}
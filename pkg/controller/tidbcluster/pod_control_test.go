@@ -41,6 +41,14 @@ func (c *kvClient) GetLeaderCount() (int, error) {
 	return int(count), nil
 }
 
+func (c *kvClient) GetConfig() (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+
+func (c *kvClient) UpdateConfig(items map[string]interface{}) error {
+	return nil
+}
+
 func TestPodControllerSync(t *testing.T) {
 	interval := time.Millisecond * 100
 	timeout := time.Minute * 1
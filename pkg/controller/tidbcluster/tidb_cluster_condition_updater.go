@@ -14,10 +14,23 @@
 package tidbcluster
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
 	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// readinessConfigMapReadyKey only exists in the readiness ConfigMap while the cluster is ready
+	readinessConfigMapReadyKey = "ready"
 )
 
 // TidbClusterConditionUpdater interface that translates cluster state into
@@ -27,14 +40,14 @@ type TidbClusterConditionUpdater interface {
 }
 
 type tidbClusterConditionUpdater struct {
+	deps *controller.Dependencies
 }
 
 var _ TidbClusterConditionUpdater = &tidbClusterConditionUpdater{}
 
 func (u *tidbClusterConditionUpdater) Update(tc *v1alpha1.TidbCluster) error {
 	u.updateReadyCondition(tc)
-	// in the future, we may return error when we need to Kubernetes API, etc.
-	return nil
+	return u.syncReadinessConfigMap(tc)
 }
 
 func allStatefulSetsAreUpToDate(tc *v1alpha1.TidbCluster) bool {
@@ -47,7 +60,34 @@ func allStatefulSetsAreUpToDate(tc *v1alpha1.TidbCluster) bool {
 	return (isUpToDate(tc.Status.PD.StatefulSet, false)) &&
 		(isUpToDate(tc.Status.TiKV.StatefulSet, false)) &&
 		(isUpToDate(tc.Status.TiDB.StatefulSet, false)) &&
-		isUpToDate(tc.Status.TiFlash.StatefulSet, false)
+		(isUpToDate(tc.Status.TiFlash.StatefulSet, false)) &&
+		(isUpToDate(tc.Status.TiCDC.StatefulSet, false)) &&
+		(isUpToDate(tc.Status.TiProxy.StatefulSet, false)) &&
+		isUpToDate(tc.Status.Pump.StatefulSet, false)
+}
+
+// upgradingComponents returns the components whose upgrade is in progress, including the phases
+// before the StatefulSet is updated, e.g. evicting the leaders of TiKV.
+func upgradingComponents(tc *v1alpha1.TidbCluster) []string {
+	var components []string
+	phases := []struct {
+		memberType v1alpha1.MemberType
+		phase      v1alpha1.MemberPhase
+	}{
+		{v1alpha1.PDMemberType, tc.Status.PD.Phase},
+		{v1alpha1.TiKVMemberType, tc.Status.TiKV.Phase},
+		{v1alpha1.TiDBMemberType, tc.Status.TiDB.Phase},
+		{v1alpha1.TiFlashMemberType, tc.Status.TiFlash.Phase},
+		{v1alpha1.TiCDCMemberType, tc.Status.TiCDC.Phase},
+		{v1alpha1.TiProxyMemberType, tc.Status.TiProxy.Phase},
+		{v1alpha1.PumpMemberType, tc.Status.Pump.Phase},
+	}
+	for _, p := range phases {
+		if p.phase == v1alpha1.UpgradePhase {
+			components = append(components, p.memberType.String())
+		}
+	}
+	return components
 }
 
 func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbCluster) {
@@ -55,10 +95,15 @@ func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbClus
 	reason := ""
 	message := ""
 
+	upgrading := upgradingComponents(tc)
+
 	switch {
 	case !allStatefulSetsAreUpToDate(tc):
 		reason = utiltidbcluster.StatfulSetNotUpToDate
 		message = "Statefulset(s) are in progress"
+	case len(upgrading) > 0:
+		reason = utiltidbcluster.ComponentUpgrading
+		message = fmt.Sprintf("%s are being upgraded", strings.Join(upgrading, ", "))
 	case tc.Spec.PD != nil && !tc.PDAllMembersReady():
 		reason = utiltidbcluster.PDUnhealthy
 		message = "PD(s) are not healthy"
@@ -71,6 +116,15 @@ func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbClus
 	case tc.Spec.TiFlash != nil && !tc.TiFlashAllStoresReady():
 		reason = utiltidbcluster.TiFlashStoreNotUp
 		message = "TiFlash store(s) are not up"
+	case tc.Spec.TiCDC != nil && !tc.TiCDCAllCapturesReady():
+		reason = utiltidbcluster.TiCDCCaptureNotReady
+		message = "TiCDC capture(s) are not ready"
+	case tc.Spec.TiProxy != nil && !tc.TiProxyAllMembersReady():
+		reason = utiltidbcluster.TiProxyUnhealthy
+		message = "TiProxy(s) are not healthy"
+	case tc.Spec.Pump != nil && !tc.PumpAllMembersOnline():
+		reason = utiltidbcluster.PumpNotOnline
+		message = "Pump(s) are not online"
 	default:
 		status = v1.ConditionTrue
 		reason = utiltidbcluster.Ready
@@ -79,3 +133,54 @@ func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbClus
 	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterReady, status, reason, message)
 	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
 }
+
+// syncReadinessConfigMap maintains the ConfigMap that mirrors the Ready condition if PublishReadiness is
+// enabled, the ConfigMap is deleted after PublishReadiness is disabled.
+func (u *tidbClusterConditionUpdater) syncReadinessConfigMap(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	name := controller.ReadinessConfigMapName(tc.GetName())
+
+	if !tc.Spec.PublishReadiness {
+		if u.deps == nil {
+			return nil
+		}
+		cm, err := u.deps.ConfigMapLister.ConfigMaps(ns).Get(name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if err := u.deps.TypedControl.Delete(tc, cm); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete readiness ConfigMap %s/%s, error: %v", ns, name, err)
+		}
+		klog.Infof("readiness ConfigMap %s/%s is deleted", ns, name)
+		return nil
+	}
+
+	cond := utiltidbcluster.GetTidbClusterReadyCondition(tc.Status)
+	if cond == nil {
+		return nil
+	}
+	data := map[string]string{
+		"status":  string(cond.Status),
+		"reason":  cond.Reason,
+		"message": cond.Message,
+	}
+	if cond.Status == v1.ConditionTrue {
+		data[readinessConfigMapReadyKey] = "true"
+	}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       ns,
+			Labels:          label.New().Instance(tc.GetInstanceName()).Labels(),
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Data: data,
+	}
+	if _, err := u.deps.TypedControl.CreateOrUpdateConfigMap(tc, cm); err != nil {
+		return fmt.Errorf("failed to sync readiness ConfigMap %s/%s, error: %v", ns, name, err)
+	}
+	return nil
+}
@@ -14,13 +14,18 @@
 package tidbcluster
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
 	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestTidbClusterConditionUpdater_Ready(t *testing.T) {
@@ -64,6 +69,50 @@ func TestTidbClusterConditionUpdater_Ready(t *testing.T) {
 			wantReason:  utiltidbcluster.StatfulSetNotUpToDate,
 			wantMessage: "Statefulset(s) are in progress",
 		},
+		{
+			name: "component(s) being upgraded",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					PD:   &v1alpha1.PDSpec{},
+					TiKV: &v1alpha1.TiKVSpec{},
+					TiDB: &v1alpha1.TiDBSpec{},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					TiKV: v1alpha1.TiKVStatus{
+						Phase: v1alpha1.UpgradePhase,
+						StatefulSet: &appsv1.StatefulSetStatus{
+							CurrentRevision: "2",
+							UpdateRevision:  "2",
+						},
+					},
+				},
+			},
+			wantStatus:  v1.ConditionFalse,
+			wantReason:  utiltidbcluster.ComponentUpgrading,
+			wantMessage: "tikv are being upgraded",
+		},
+		{
+			name: "ticdc capture(s) not ready",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					TiCDC: &v1alpha1.TiCDCSpec{
+						Replicas: 2,
+					},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					TiCDC: v1alpha1.TiCDCStatus{
+						Captures: map[string]v1alpha1.TiCDCCapture{
+							"ticdc-0": {
+								Ready: true,
+							},
+						},
+					},
+				},
+			},
+			wantStatus:  v1.ConditionFalse,
+			wantReason:  utiltidbcluster.TiCDCCaptureNotReady,
+			wantMessage: "TiCDC capture(s) are not ready",
+		},
 		{
 			name: "pd(s) not healthy",
 			tc: &v1alpha1.TidbCluster{
@@ -360,3 +409,66 @@ func TestTidbClusterConditionUpdater_Ready(t *testing.T) {
 		})
 	}
 }
+
+func TestTidbClusterConditionUpdater_ReadinessConfigMap(t *testing.T) {
+	deps := controller.NewFakeDependencies()
+	conditionUpdater := &tidbClusterConditionUpdater{deps: deps}
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: v1.NamespaceDefault,
+			Name:      "demo",
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiDB: &v1alpha1.TiDBSpec{
+				Replicas: 1,
+			},
+			PublishReadiness: true,
+		},
+	}
+	getConfigMap := func() (*v1.ConfigMap, error) {
+		cm := &v1.ConfigMap{}
+		key := client.ObjectKey{Namespace: tc.Namespace, Name: "demo-readiness"}
+		return cm, deps.GenericControl.(*controller.FakeGenericControl).FakeCli.Get(context.TODO(), key, cm)
+	}
+
+	// not ready
+	if err := conditionUpdater.Update(tc); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := getConfigMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cm.Data[readinessConfigMapReadyKey]; ok {
+		t.Errorf("unexpected key %q in ConfigMap when the cluster is not ready", readinessConfigMapReadyKey)
+	}
+	if diff := cmp.Diff(utiltidbcluster.TiDBUnhealthy, cm.Data["reason"]); diff != "" {
+		t.Errorf("unexpected reason (-want, +got): %s", diff)
+	}
+
+	// ready
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+		"tidb-0": {
+			Health: true,
+		},
+	}
+	if err := conditionUpdater.Update(tc); err != nil {
+		t.Fatal(err)
+	}
+	if cm, err = getConfigMap(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("true", cm.Data[readinessConfigMapReadyKey]); diff != "" {
+		t.Errorf("unexpected ready (-want, +got): %s", diff)
+	}
+
+	// disabled
+	deps.LabelFilterKubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Add(cm)
+	tc.Spec.PublishReadiness = false
+	if err := conditionUpdater.Update(tc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getConfigMap(); !apierrors.IsNotFound(err) {
+		t.Errorf("expect ConfigMap to be deleted, got error: %v", err)
+	}
+}
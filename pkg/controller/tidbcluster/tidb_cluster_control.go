@@ -14,6 +14,10 @@
 package tidbcluster
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/defaulting"
 	v1alpha1validation "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
@@ -21,9 +25,12 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/manager"
 	"github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/pingcap/tidb-operator/pkg/notification"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 )
@@ -44,16 +51,26 @@ func NewDefaultTidbClusterControl(
 	tikvMemberManager manager.Manager,
 	tidbMemberManager manager.Manager,
 	reclaimPolicyManager manager.Manager,
+	pdbManager manager.Manager,
 	metaManager manager.Manager,
 	orphanPodsCleaner member.OrphanPodsCleaner,
+	volumeRepairManager manager.Manager,
 	pvcCleaner member.PVCCleanerInterface,
 	pvcResizer member.PVCResizerInterface,
 	pumpMemberManager manager.Manager,
 	tiflashMemberManager manager.Manager,
 	ticdcMemberManager manager.Manager,
+	tiproxyMemberManager manager.Manager,
 	discoveryManager member.TidbDiscoveryManager,
 	tidbClusterStatusManager manager.Manager,
+	maintenanceManager manager.Manager,
+	diagnosticsManager manager.Manager,
+	certRotationManager manager.Manager,
+	tlsPreflightManager manager.Manager,
+	tenantPolicyManager manager.Manager,
+	configHistoryManager member.ConfigHistoryManager,
 	conditionUpdater TidbClusterConditionUpdater,
+	notifier notification.Interface,
 	recorder record.EventRecorder) ControlInterface {
 	return &defaultTidbClusterControl{
 		tcControl:                tcControl,
@@ -61,16 +78,26 @@ func NewDefaultTidbClusterControl(
 		tikvMemberManager:        tikvMemberManager,
 		tidbMemberManager:        tidbMemberManager,
 		reclaimPolicyManager:     reclaimPolicyManager,
+		pdbManager:               pdbManager,
 		metaManager:              metaManager,
 		orphanPodsCleaner:        orphanPodsCleaner,
+		volumeRepairManager:      volumeRepairManager,
 		pvcCleaner:               pvcCleaner,
 		pvcResizer:               pvcResizer,
 		pumpMemberManager:        pumpMemberManager,
 		tiflashMemberManager:     tiflashMemberManager,
 		ticdcMemberManager:       ticdcMemberManager,
+		tiproxyMemberManager:     tiproxyMemberManager,
 		discoveryManager:         discoveryManager,
 		tidbClusterStatusManager: tidbClusterStatusManager,
+		maintenanceManager:       maintenanceManager,
+		diagnosticsManager:       diagnosticsManager,
+		certRotationManager:      certRotationManager,
+		tlsPreflightManager:      tlsPreflightManager,
+		tenantPolicyManager:      tenantPolicyManager,
+		configHistoryManager:     configHistoryManager,
 		conditionUpdater:         conditionUpdater,
+		notifier:                 notifier,
 		recorder:                 recorder,
 	}
 }
@@ -81,16 +108,26 @@ type defaultTidbClusterControl struct {
 	tikvMemberManager        manager.Manager
 	tidbMemberManager        manager.Manager
 	reclaimPolicyManager     manager.Manager
+	pdbManager               manager.Manager
 	metaManager              manager.Manager
 	orphanPodsCleaner        member.OrphanPodsCleaner
+	volumeRepairManager      manager.Manager
 	pvcCleaner               member.PVCCleanerInterface
 	pvcResizer               member.PVCResizerInterface
 	pumpMemberManager        manager.Manager
 	tiflashMemberManager     manager.Manager
 	ticdcMemberManager       manager.Manager
+	tiproxyMemberManager     manager.Manager
 	discoveryManager         member.TidbDiscoveryManager
 	tidbClusterStatusManager manager.Manager
+	maintenanceManager       manager.Manager
+	diagnosticsManager       manager.Manager
+	certRotationManager      manager.Manager
+	tlsPreflightManager      manager.Manager
+	tenantPolicyManager      manager.Manager
+	configHistoryManager     member.ConfigHistoryManager
 	conditionUpdater         TidbClusterConditionUpdater
+	notifier                 notification.Interface
 	recorder                 record.EventRecorder
 }
 
@@ -104,6 +141,14 @@ func (c *defaultTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster)
 	var errs []error
 	oldStatus := tc.Status.DeepCopy()
 
+	resumed := c.syncPaused(tc)
+
+	// roll back the config of a component to a retained ConfigMap, the spec is persisted together with the status
+	rolledBack, err := c.configHistoryManager.Rollback(tc)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	if err := c.updateTidbCluster(tc); err != nil {
 		errs = append(errs, err)
 	}
@@ -112,16 +157,126 @@ func (c *defaultTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster)
 		errs = append(errs, err)
 	}
 
-	if apiequality.Semantic.DeepEqual(&tc.Status, oldStatus) {
+	if !resumed && !rolledBack && apiequality.Semantic.DeepEqual(&tc.Status, oldStatus) {
 		return errorutils.NewAggregate(errs)
 	}
 	if _, err := c.tcControl.UpdateTidbCluster(tc.DeepCopy(), &tc.Status, oldStatus); err != nil {
 		errs = append(errs, err)
+	} else {
+		c.notifyTransitions(tc, oldStatus)
 	}
 
 	return errorutils.NewAggregate(errs)
 }
 
+// syncPaused records the time the cluster is paused, and resumes the cluster once the time of auto-resume
+// is reached, the spec is persisted together with the status. It returns whether the cluster is resumed.
+func (c *defaultTidbClusterControl) syncPaused(tc *v1alpha1.TidbCluster) bool {
+	if !tc.Spec.Paused {
+		tc.Status.PausedAt = nil
+		return false
+	}
+	now := time.Now()
+	if tc.Status.PausedAt == nil {
+		tc.Status.PausedAt = &metav1.Time{Time: now}
+	}
+	resumeTime := tc.AutoResumeTime()
+	if resumeTime == nil || now.Before(*resumeTime) {
+		return false
+	}
+
+	msg := fmt.Sprintf("the cluster paused at %s is resumed automatically", tc.Status.PausedAt.Format(time.RFC3339))
+	klog.Infof("tidb cluster %s/%s: %s", tc.GetNamespace(), tc.GetName(), msg)
+	c.recorder.Event(tc, v1.EventTypeNormal, "AutoResumed", msg)
+	tc.Spec.Paused = false
+	tc.Spec.AutoResume = nil
+	tc.Status.PausedAt = nil
+	return true
+}
+
+// notifyTransitions sends the notifications of the upgrades started or finished and the failovers triggered
+// between the old status and the new status
+func (c *defaultTidbClusterControl) notifyTransitions(tc *v1alpha1.TidbCluster, oldStatus *v1alpha1.TidbClusterStatus) {
+	if tc.Spec.Notifications == nil {
+		return
+	}
+	newNotification := func(eventType v1alpha1.NotificationEventType, severity v1alpha1.NotificationSeverity,
+		memberType v1alpha1.MemberType, message string) notification.Notification {
+		return notification.Notification{
+			Type:      eventType,
+			Severity:  severity,
+			Kind:      v1alpha1.TiDBClusterKind,
+			Namespace: tc.GetNamespace(),
+			Name:      tc.GetName(),
+			Component: memberType.String(),
+			Message:   message,
+		}
+	}
+
+	phases := []struct {
+		memberType v1alpha1.MemberType
+		old, new   v1alpha1.MemberPhase
+	}{
+		{v1alpha1.PDMemberType, oldStatus.PD.Phase, tc.Status.PD.Phase},
+		{v1alpha1.TiKVMemberType, oldStatus.TiKV.Phase, tc.Status.TiKV.Phase},
+		{v1alpha1.TiDBMemberType, oldStatus.TiDB.Phase, tc.Status.TiDB.Phase},
+		{v1alpha1.TiFlashMemberType, oldStatus.TiFlash.Phase, tc.Status.TiFlash.Phase},
+		{v1alpha1.PumpMemberType, oldStatus.Pump.Phase, tc.Status.Pump.Phase},
+		{v1alpha1.TiCDCMemberType, oldStatus.TiCDC.Phase, tc.Status.TiCDC.Phase},
+		{v1alpha1.TiProxyMemberType, oldStatus.TiProxy.Phase, tc.Status.TiProxy.Phase},
+	}
+	for _, p := range phases {
+		if p.old != v1alpha1.UpgradePhase && p.new == v1alpha1.UpgradePhase {
+			c.notifier.Notify(tc.Spec.Notifications, newNotification(v1alpha1.NotificationEventUpgradeStarted,
+				v1alpha1.NotificationSeverityInfo, p.memberType, fmt.Sprintf("%s starts upgrading", p.memberType)))
+		} else if p.old == v1alpha1.UpgradePhase && p.new != v1alpha1.UpgradePhase {
+			c.notifier.Notify(tc.Spec.Notifications, newNotification(v1alpha1.NotificationEventUpgradeFinished,
+				v1alpha1.NotificationSeverityInfo, p.memberType, fmt.Sprintf("%s finishes upgrading", p.memberType)))
+		}
+	}
+
+	failures := []struct {
+		memberType v1alpha1.MemberType
+		old, new   []string
+	}{
+		{v1alpha1.PDMemberType, failurePodNames(oldStatus.PD.FailureMembers), failurePodNames(tc.Status.PD.FailureMembers)},
+		{v1alpha1.TiKVMemberType, failurePodNames(oldStatus.TiKV.FailureStores), failurePodNames(tc.Status.TiKV.FailureStores)},
+		{v1alpha1.TiDBMemberType, failurePodNames(oldStatus.TiDB.FailureMembers), failurePodNames(tc.Status.TiDB.FailureMembers)},
+		{v1alpha1.TiFlashMemberType, failurePodNames(oldStatus.TiFlash.FailureStores), failurePodNames(tc.Status.TiFlash.FailureStores)},
+	}
+	for _, f := range failures {
+		old := sets.NewString(f.old...)
+		for _, podName := range f.new {
+			if old.Has(podName) {
+				continue
+			}
+			c.notifier.Notify(tc.Spec.Notifications, newNotification(v1alpha1.NotificationEventFailoverTriggered,
+				v1alpha1.NotificationSeverityWarning, f.memberType, fmt.Sprintf("failover is triggered for %s", podName)))
+		}
+	}
+}
+
+// failurePodNames returns the sorted Pod names of the failure members or stores
+func failurePodNames(failures interface{}) []string {
+	var names []string
+	switch m := failures.(type) {
+	case map[string]v1alpha1.PDFailureMember:
+		for _, f := range m {
+			names = append(names, f.PodName)
+		}
+	case map[string]v1alpha1.TiDBFailureMember:
+		for _, f := range m {
+			names = append(names, f.PodName)
+		}
+	case map[string]v1alpha1.TiKVFailureStore:
+		for _, f := range m {
+			names = append(names, f.PodName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (c *defaultTidbClusterControl) validate(tc *v1alpha1.TidbCluster) bool {
 	errs := v1alpha1validation.ValidateTidbCluster(tc)
 	if len(errs) > 0 {
@@ -144,6 +299,12 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		return err
 	}
 
+	// syncing the PodDisruptionBudgets of the components, so that the voluntary disruptions
+	// like node drains don't break the quorum
+	if err := c.pdbManager.Sync(tc); err != nil {
+		return err
+	}
+
 	// cleaning all orphan pods(pd, tikv or tiflash which don't have a related PVC) managed by operator
 	// this could be useful when failover run into an undesired situation as described in PD failover function
 	skipReasons, err := c.orphanPodsCleaner.Clean(tc)
@@ -156,11 +317,49 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		}
 	}
 
+	// recreating the volumes of the pd, tikv or tiflash pods that are unschedulable because their PVs
+	// are bound to a lost node, it's enabled by the PVTopologyRepair feature
+	if err := c.volumeRepairManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// check the policy of the tenant of the namespace before any resource of the members is created
+	// or scaled out, the member managers only block the creation and the scaling out violating it
+	if err := c.tenantPolicyManager.Sync(tc); err != nil {
+		return err
+	}
+
 	// reconcile TiDB discovery service
 	if err := c.discoveryManager.Reconcile(tc); err != nil {
 		return err
 	}
 
+	// run the periodic maintenance tasks as jobs, this is done before the member managers
+	// as the upgraders requeue until the upgrades are finished, and the running jobs must
+	// be stopped once an upgrade begins
+	if err := c.maintenanceManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// collect the diagnostics bundle of the failures observed in the last sync, this is done before
+	// the member managers for the same reason as above, and the failure of the collection must not
+	// block the reconciliation of the cluster
+	if err := c.diagnosticsManager.Sync(tc); err != nil {
+		klog.Errorf("failed to sync the diagnostics of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+
+	// renew the expiring certificates, the failure of the renewal must not block the reconciliation
+	// of the cluster either, as the certificates are renewed well before they expire
+	if err := c.certRotationManager.Sync(tc); err != nil {
+		klog.Errorf("failed to sync the cert rotation of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+
+	// validate the SANs and the expiry of the cluster TLS secrets before they are mounted, the members
+	// are not synced until the secrets are fixed, as the components can't handshake with them
+	if err := c.tlsPreflightManager.Sync(tc); err != nil {
+		return err
+	}
+
 	// works that should be done to make the pd cluster current state match the desired state:
 	//   - create or update the pd service
 	//   - create or update the pd headless service
@@ -226,6 +425,14 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		return err
 	}
 
+	//   - waiting for the pd cluster available(pd cluster is in quorum)
+	//   - create or update tiproxy statefulset and services
+	//   - upgrade the tiproxy cluster
+	//   - scale out/in the tiproxy cluster
+	if err := c.tiproxyMemberManager.Sync(tc); err != nil {
+		return err
+	}
+
 	// syncing the labels from Pod to PVC and PV, these labels include:
 	//   - label.StoreIDLabelKey
 	//   - label.MemberIDLabelKey
@@ -250,6 +457,12 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		return err
 	}
 
+	// delete the old ConfigMaps of the components beyond the history limit, the failure of the deletion
+	// must not block the reconciliation of the cluster
+	if err := c.configHistoryManager.Sync(tc); err != nil {
+		klog.Errorf("failed to sync the config history of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+
 	// syncing the some tidbcluster status attributes
 	// 	- sync tidbmonitor reference
 	return c.tidbClusterStatusManager.Sync(tc)
@@ -273,6 +486,9 @@ func (c *defaultTidbClusterControl) recordMetrics(tc *v1alpha1.TidbCluster) {
 	if tc.Spec.TiCDC != nil {
 		metrics.ClusterSpecReplicas.WithLabelValues(ns, tcName, "ticdc").Set(float64(tc.Spec.TiCDC.Replicas))
 	}
+	if tc.Spec.TiProxy != nil {
+		metrics.ClusterSpecReplicas.WithLabelValues(ns, tcName, "tiproxy").Set(float64(tc.Spec.TiProxy.Replicas))
+	}
 	if tc.Spec.Pump != nil {
 		metrics.ClusterSpecReplicas.WithLabelValues(ns, tcName, "pump").Set(float64(tc.Spec.Pump.Replicas))
 	}
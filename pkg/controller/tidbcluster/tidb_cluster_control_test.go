@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -25,6 +26,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mm "github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
+	"github.com/pingcap/tidb-operator/pkg/notification"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
@@ -319,6 +321,7 @@ func newFakeTidbClusterControl() (
 	pumpMemberManager := mm.NewFakePumpMemberManager()
 	tiflashMemberManager := mm.NewFakeTiFlashMemberManager()
 	ticdcMemberManager := mm.NewFakeTiCDCMemberManager()
+	tiproxyMemberManager := mm.NewFakeTiProxyMemberManager()
 	discoveryManager := mm.NewFakeDiscoveryManger()
 	statusManager := mm.NewFakeTidbClusterStatusManager()
 	pvcResizer := mm.NewFakePVCResizer()
@@ -328,22 +331,111 @@ func newFakeTidbClusterControl() (
 		tikvMemberManager,
 		tidbMemberManager,
 		reclaimPolicyManager,
+		meta.NewFakePDBManager(),
 		metaManager,
 		orphanPodCleaner,
+		mm.NewFakeTidbClusterVolumeRepairManager(),
 		pvcCleaner,
 		pvcResizer,
 		pumpMemberManager,
 		tiflashMemberManager,
 		ticdcMemberManager,
+		tiproxyMemberManager,
 		discoveryManager,
 		statusManager,
+		mm.NewFakeTidbClusterMaintenanceManager(),
+		mm.NewFakeTidbClusterDiagnosticsManager(),
+		mm.NewFakeTidbClusterCertRotationManager(),
+		mm.NewFakeTidbClusterTLSPreflightManager(),
+		mm.NewFakeTidbClusterTenantPolicyManager(),
+		mm.NewFakeConfigHistoryManager(),
 		&tidbClusterConditionUpdater{},
+		notification.NewFakeNotifier(),
 		recorder,
 	)
 
 	return control, reclaimPolicyManager, orphanPodCleaner, pdMemberManager, tikvMemberManager, tidbMemberManager, metaManager, pvcCleaner, tcUpdater
 }
 
+func TestTidbClusterControlNotifyTransitions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	notifier := notification.NewFakeNotifier()
+	control := &defaultTidbClusterControl{notifier: notifier}
+	tc := newTidbClusterForTidbClusterControl()
+	tc.Status.PD.Phase = v1alpha1.UpgradePhase
+	tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+	oldStatus := tc.Status.DeepCopy()
+
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
+	tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{
+		"1": {PodName: "test-tikv-1", StoreID: "1"},
+	}
+
+	// no notification is sent if not configured
+	control.notifyTransitions(tc, oldStatus)
+	g.Expect(notifier.Notifications()).Should(BeEmpty())
+
+	tc.Spec.Notifications = &v1alpha1.NotificationSpec{
+		Sinks: []v1alpha1.NotificationSink{{Name: "webhook", Webhook: &v1alpha1.WebhookNotificationSink{URL: "http://webhook"}}},
+	}
+	control.notifyTransitions(tc, oldStatus)
+	notifications := notifier.Notifications()
+	g.Expect(notifications).Should(HaveLen(3))
+	g.Expect(notifications[0].Type).Should(Equal(v1alpha1.NotificationEventUpgradeFinished))
+	g.Expect(notifications[0].Component).Should(Equal(v1alpha1.PDMemberType.String()))
+	g.Expect(notifications[1].Type).Should(Equal(v1alpha1.NotificationEventUpgradeStarted))
+	g.Expect(notifications[1].Component).Should(Equal(v1alpha1.TiDBMemberType.String()))
+	g.Expect(notifications[2].Type).Should(Equal(v1alpha1.NotificationEventFailoverTriggered))
+	g.Expect(notifications[2].Severity).Should(Equal(v1alpha1.NotificationSeverityWarning))
+	g.Expect(notifications[2].Message).Should(ContainSubstring("test-tikv-1"))
+}
+
+func TestTidbClusterControlSyncPaused(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	control := &defaultTidbClusterControl{recorder: recorder}
+	tc := newTidbClusterForTidbClusterControl()
+
+	// not paused
+	g.Expect(control.syncPaused(tc)).Should(BeFalse())
+	g.Expect(tc.Status.PausedAt).Should(BeNil())
+
+	// paused without auto-resume
+	tc.Spec.Paused = true
+	g.Expect(control.syncPaused(tc)).Should(BeFalse())
+	g.Expect(tc.Status.PausedAt).ShouldNot(BeNil())
+	pausedAt := tc.Status.PausedAt
+
+	// the time of auto-resume is not reached
+	tc.Spec.AutoResume = &v1alpha1.AutoResumeSpec{After: &metav1.Duration{Duration: time.Hour}}
+	g.Expect(control.syncPaused(tc)).Should(BeFalse())
+	g.Expect(tc.Spec.Paused).Should(BeTrue())
+	g.Expect(tc.Status.PausedAt).Should(Equal(pausedAt))
+
+	// the time of auto-resume is reached
+	tc.Status.PausedAt = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+	g.Expect(control.syncPaused(tc)).Should(BeTrue())
+	g.Expect(tc.Spec.Paused).Should(BeFalse())
+	g.Expect(tc.Spec.AutoResume).Should(BeNil())
+	g.Expect(tc.Status.PausedAt).Should(BeNil())
+	g.Expect(<-recorder.Events).Should(ContainSubstring("AutoResumed"))
+
+	// the time of auto-resume is specified by until
+	tc.Spec.Paused = true
+	tc.Spec.AutoResume = &v1alpha1.AutoResumeSpec{Until: &metav1.Time{Time: time.Now().Add(-time.Minute)}}
+	g.Expect(control.syncPaused(tc)).Should(BeTrue())
+	g.Expect(tc.Spec.Paused).Should(BeFalse())
+	<-recorder.Events
+
+	// pausedAt is cleared once the cluster is resumed manually
+	tc.Status.PausedAt = &metav1.Time{Time: time.Now()}
+	g.Expect(control.syncPaused(tc)).Should(BeFalse())
+	g.Expect(tc.Status.PausedAt).Should(BeNil())
+}
+
 func newTidbClusterForTidbClusterControl() *v1alpha1.TidbCluster {
 	return &v1alpha1.TidbCluster{
 		TypeMeta: metav1.TypeMeta{
@@ -14,17 +14,25 @@
 package tidbcluster
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	perrors "github.com/pingcap/errors"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mm "github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
@@ -32,6 +40,9 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// maxDriftFields is the maximum number of the changed fields named in a DriftDetected event
+const maxDriftFields = 5
+
 // Controller controls tidbclusters.
 type Controller struct {
 	deps *controller.Dependencies
@@ -52,16 +63,26 @@ func NewController(deps *controller.Dependencies) *Controller {
 			mm.NewTiKVMemberManager(deps, mm.NewTiKVFailover(deps), mm.NewTiKVScaler(deps), mm.NewTiKVUpgrader(deps)),
 			mm.NewTiDBMemberManager(deps, mm.NewTiDBScaler(deps), mm.NewTiDBUpgrader(deps), mm.NewTiDBFailover(deps)),
 			meta.NewReclaimPolicyManager(deps),
+			meta.NewPDBManager(deps),
 			meta.NewMetaManager(deps),
 			mm.NewOrphanPodsCleaner(deps),
+			mm.NewTidbClusterVolumeRepairManager(deps),
 			mm.NewRealPVCCleaner(deps),
 			mm.NewPVCResizer(deps),
 			mm.NewPumpMemberManager(deps, mm.NewPumpScaler(deps)),
 			mm.NewTiFlashMemberManager(deps, mm.NewTiFlashFailover(deps), mm.NewTiFlashScaler(deps), mm.NewTiFlashUpgrader(deps)),
 			mm.NewTiCDCMemberManager(deps, mm.NewTiCDCScaler(deps), mm.NewTiCDCUpgrader(deps)),
+			mm.NewTiProxyMemberManager(deps, mm.NewTiProxyScaler(deps), mm.NewTiProxyUpgrader(deps)),
 			mm.NewTidbDiscoveryManager(deps),
 			mm.NewTidbClusterStatusManager(deps),
-			&tidbClusterConditionUpdater{},
+			mm.NewTidbClusterMaintenanceManager(deps),
+			mm.NewTidbClusterDiagnosticsManager(deps),
+			mm.NewTidbClusterCertRotationManager(deps),
+			mm.NewTidbClusterTLSPreflightManager(deps),
+			mm.NewTidbClusterTenantPolicyManager(deps),
+			mm.NewConfigHistoryManager(deps),
+			&tidbClusterConditionUpdater{deps: deps},
+			deps.Notifier,
 			deps.Recorder,
 		),
 		queue: workqueue.NewNamedRateLimitingQueue(
@@ -86,6 +107,15 @@ func NewController(deps *controller.Dependencies) *Controller {
 		},
 		DeleteFunc: c.deleteStatefulSet,
 	})
+	if deps.NodeLister != nil {
+		deps.KubeInformerFactory.Core().V1().Nodes().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: c.updateNode,
+		})
+	}
+	deps.KubeInformerFactory.Core().V1().Secrets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addSecret,
+		UpdateFunc: c.updateSecret,
+	})
 
 	return c
 }
@@ -209,9 +239,37 @@ func (c *Controller) updateStatefulSet(old, cur interface{}) {
 		return
 	}
 	klog.V(4).Infof("StatefulSet %s/%s updated, TidbCluster: %s/%s", ns, setName, ns, tc.Name)
+	c.detectStatefulSetDrift(tc, oldSet, curSet)
 	c.enqueueTidbCluster(tc)
 }
 
+// detectStatefulSetDrift emits a DriftDetected event naming the changed fields if the spec of a StatefulSet of
+// the tidbcluster is changed while its last applied config is not, i.e. the StatefulSet is modified by another
+// actor, e.g. a GitOps tool syncing the StatefulSet itself, rather than applied by the operator
+func (c *Controller) detectStatefulSetDrift(tc *v1alpha1.TidbCluster, oldSet, curSet *apps.StatefulSet) {
+	lastApplied, ok := curSet.Annotations[mm.LastAppliedConfigAnnotation]
+	if !ok || oldSet.Annotations[mm.LastAppliedConfigAnnotation] != lastApplied {
+		return
+	}
+	if apiequality.Semantic.DeepEqual(oldSet.Spec, curSet.Spec) {
+		return
+	}
+	fields, err := changedFields("spec", oldSet.Spec, curSet.Spec)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to compare the spec of statefulset %s/%s: %v", curSet.Namespace, curSet.Name, err))
+		return
+	}
+	if len(fields) == 0 {
+		return
+	}
+	if len(fields) > maxDriftFields {
+		fields = append(fields[:maxDriftFields], "...")
+	}
+	klog.Infof("StatefulSet %s/%s of TidbCluster %s/%s is modified externally, changed fields: %v", curSet.Namespace, curSet.Name, tc.Namespace, tc.Name, fields)
+	c.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "DriftDetected",
+		"StatefulSet %s is modified externally and differs from the desired spec, changed fields: %s", curSet.Name, strings.Join(fields, ", "))
+}
+
 // deleteStatefulSet enqueues the tidbcluster for the statefulset accounting for deletion tombstones.
 func (c *Controller) deleteStatefulSet(obj interface{}) {
 	set, ok := obj.(*apps.StatefulSet)
@@ -244,6 +302,89 @@ func (c *Controller) deleteStatefulSet(obj interface{}) {
 	c.enqueueTidbCluster(tc)
 }
 
+// updateNode enqueues the tidbclusters with Pods on the node when the taints of the node are changed, so that
+// the instances on the nodes going to be terminated, e.g. the reclaimed spot instances, are handled immediately
+func (c *Controller) updateNode(old, cur interface{}) {
+	curNode := cur.(*corev1.Node)
+	oldNode := old.(*corev1.Node)
+	if apiequality.Semantic.DeepEqual(curNode.Spec.Taints, oldNode.Spec.Taints) {
+		return
+	}
+
+	selector, err := label.New().Selector()
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	pods, err := c.deps.PodLister.List(selector)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list pods on node %s, error: %v", curNode.Name, err))
+		return
+	}
+	enqueued := map[string]bool{}
+	for _, pod := range pods {
+		instance := pod.Labels[label.InstanceLabelKey]
+		if pod.Spec.NodeName != curNode.Name || instance == "" {
+			continue
+		}
+		tc, err := c.deps.TiDBClusterLister.TidbClusters(pod.Namespace).Get(instance)
+		if err != nil || !tc.IsSpotTerminationEnabled() {
+			continue
+		}
+		key := pod.Namespace + "/" + instance
+		if enqueued[key] {
+			continue
+		}
+		enqueued[key] = true
+		klog.V(4).Infof("the taints of node %s are changed, TidbCluster: %s", curNode.Name, key)
+		c.enqueueTidbCluster(tc)
+	}
+}
+
+// addSecret enqueues the tidbclusters referencing the Secret as the sink URI of a changefeed
+func (c *Controller) addSecret(obj interface{}) {
+	c.enqueueTidbClustersForSecret(obj.(*corev1.Secret))
+}
+
+// updateSecret enqueues the tidbclusters referencing the Secret as the sink URI of a changefeed when
+// the data of the Secret is changed, so that the changefeed is updated
+func (c *Controller) updateSecret(old, cur interface{}) {
+	curSecret := cur.(*corev1.Secret)
+	oldSecret := old.(*corev1.Secret)
+	if apiequality.Semantic.DeepEqual(curSecret.Data, oldSecret.Data) {
+		return
+	}
+	c.enqueueTidbClustersForSecret(curSecret)
+}
+
+func (c *Controller) enqueueTidbClustersForSecret(secret *corev1.Secret) {
+	tcs, err := c.deps.TiDBClusterLister.TidbClusters(secret.Namespace).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list tidbclusters in namespace %s, error: %v", secret.Namespace, err))
+		return
+	}
+	for _, tc := range tcs {
+		if !referencesSinkURISecret(tc, secret.Name) {
+			continue
+		}
+		klog.V(4).Infof("the sink uri Secret %s/%s is changed, TidbCluster: %s/%s", secret.Namespace, secret.Name, tc.Namespace, tc.Name)
+		c.enqueueTidbCluster(tc)
+	}
+}
+
+// referencesSinkURISecret returns whether the Secret is referenced by the SinkURISecretRef of a changefeed of the tidbcluster
+func referencesSinkURISecret(tc *v1alpha1.TidbCluster, name string) bool {
+	if tc.Spec.TiCDC == nil {
+		return false
+	}
+	for _, cf := range tc.Spec.TiCDC.Changefeeds {
+		if cf.SinkURISecretRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // resolveTidbClusterFromSet returns the TidbCluster by a StatefulSet,
 // or nil if the StatefulSet could not be resolved to a matching TidbCluster
 // of the correct Kind.
@@ -269,3 +410,64 @@ func (c *Controller) resolveTidbClusterFromSet(namespace string, set *apps.State
 	}
 	return tc
 }
+
+// changedFields returns the paths of the fields that differ between the old and the current object, in the
+// JSON form, e.g. spec.template.spec.containers[0].image
+func changedFields(path string, old, cur interface{}) ([]string, error) {
+	var oldObj, curObj interface{}
+	for _, o := range []struct {
+		in  interface{}
+		out *interface{}
+	}{{old, &oldObj}, {cur, &curObj}} {
+		data, err := json.Marshal(o.in)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, o.out); err != nil {
+			return nil, err
+		}
+	}
+	return diffJSON(path, oldObj, curObj), nil
+}
+
+func diffJSON(path string, old, cur interface{}) []string {
+	switch o := old.(type) {
+	case map[string]interface{}:
+		c, ok := cur.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+		keys := map[string]struct{}{}
+		for k := range o {
+			keys[k] = struct{}{}
+		}
+		for k := range c {
+			keys[k] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		var fields []string
+		for _, k := range sorted {
+			fields = append(fields, diffJSON(path+"."+k, o[k], c[k])...)
+		}
+		return fields
+	case []interface{}:
+		c, ok := cur.([]interface{})
+		if !ok || len(o) != len(c) {
+			return []string{path}
+		}
+		var fields []string
+		for i := range o {
+			fields = append(fields, diffJSON(fmt.Sprintf("%s[%d]", path, i), o[i], c[i])...)
+		}
+		return fields
+	default:
+		if !reflect.DeepEqual(old, cur) {
+			return []string{path}
+		}
+		return nil
+	}
+}
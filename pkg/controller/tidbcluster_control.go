@@ -64,7 +64,13 @@ func (c *realTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster, new
 	// don't wait due to limited number of clients, but backoff after the default number of steps
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		var updateErr error
+		// status is a subresource, so the spec and metadata changes (e.g. defaulting)
+		// are persisted by Update and the status is persisted by UpdateStatus
 		updateTC, updateErr = c.cli.PingcapV1alpha1().TidbClusters(ns).Update(context.TODO(), tc, metav1.UpdateOptions{})
+		if updateErr == nil {
+			updateTC.Status = *status
+			updateTC, updateErr = c.cli.PingcapV1alpha1().TidbClusters(ns).UpdateStatus(context.TODO(), updateTC, metav1.UpdateOptions{})
+		}
 		if updateErr == nil {
 			klog.Infof("TidbCluster: [%s/%s] updated successfully", ns, tcName)
 			return nil
@@ -0,0 +1,178 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// VolumeSnapshotGroupVersionKind is the kind of the CSI VolumeSnapshots, which are accessed as unstructured
+// objects as the snapshot API is not built into the operator
+var VolumeSnapshotGroupVersionKind = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// VolumeSnapshotState is the state of a VolumeSnapshot reported by the snapshot controller
+type VolumeSnapshotState struct {
+	// CreationTime is the point in time the snapshot is cut, it's set before the snapshot is ready to use, e.g.
+	// while the data is being uploaded
+	CreationTime string
+	ReadyToUse   bool
+	// RestoreSize is the minimum size of the volume restored from the snapshot
+	RestoreSize string
+	// Error is the error of the snapshot, it's empty if the snapshot is taken successfully so far
+	Error string
+}
+
+// VolumeSnapshotControlInterface manages the CSI VolumeSnapshots of the PVCs
+type VolumeSnapshotControlInterface interface {
+	// CreateVolumeSnapshot takes a snapshot of the PVC, the default class of the CSI driver is used if className is empty
+	CreateVolumeSnapshot(owner runtime.Object, ns, name, pvcName, className string, labels map[string]string) error
+	// GetVolumeSnapshot returns the state of the snapshot, or the NotFound error if it does not exist
+	GetVolumeSnapshot(ns, name string) (*VolumeSnapshotState, error)
+	DeleteVolumeSnapshot(owner runtime.Object, ns, name string) error
+}
+
+type realVolumeSnapshotControl struct {
+	cli      client.Client
+	recorder record.EventRecorder
+}
+
+// NewRealVolumeSnapshotControl creates a new VolumeSnapshotControlInterface
+func NewRealVolumeSnapshotControl(cli client.Client, recorder record.EventRecorder) VolumeSnapshotControlInterface {
+	return &realVolumeSnapshotControl{
+		cli:      cli,
+		recorder: recorder,
+	}
+}
+
+func (c *realVolumeSnapshotControl) CreateVolumeSnapshot(owner runtime.Object, ns, name, pvcName, className string, labels map[string]string) error {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGroupVersionKind)
+	snapshot.SetNamespace(ns)
+	snapshot.SetName(name)
+	snapshot.SetLabels(labels)
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": pvcName,
+		},
+	}
+	if className != "" {
+		spec["volumeSnapshotClassName"] = className
+	}
+	snapshot.Object["spec"] = spec
+
+	err := c.cli.Create(context.TODO(), snapshot)
+	if err != nil {
+		klog.Errorf("failed to create volume snapshot: [%s/%s] of pvc %s, %v", ns, name, pvcName, err)
+	} else {
+		klog.V(4).Infof("create volume snapshot: [%s/%s] of pvc %s successfully", ns, name, pvcName)
+	}
+	c.recordEvent("create", owner, ns, name, err)
+	return err
+}
+
+func (c *realVolumeSnapshotControl) GetVolumeSnapshot(ns, name string) (*VolumeSnapshotState, error) {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGroupVersionKind)
+	if err := c.cli.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: name}, snapshot); err != nil {
+		return nil, err
+	}
+	state := &VolumeSnapshotState{}
+	state.CreationTime, _, _ = unstructured.NestedString(snapshot.Object, "status", "creationTime")
+	state.ReadyToUse, _, _ = unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	state.RestoreSize, _, _ = unstructured.NestedString(snapshot.Object, "status", "restoreSize")
+	state.Error, _, _ = unstructured.NestedString(snapshot.Object, "status", "error", "message")
+	return state, nil
+}
+
+func (c *realVolumeSnapshotControl) DeleteVolumeSnapshot(owner runtime.Object, ns, name string) error {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGroupVersionKind)
+	snapshot.SetNamespace(ns)
+	snapshot.SetName(name)
+	err := c.cli.Delete(context.TODO(), snapshot)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		klog.Errorf("failed to delete volume snapshot: [%s/%s], %v", ns, name, err)
+	} else {
+		klog.V(4).Infof("delete volume snapshot: [%s/%s] successfully", ns, name)
+	}
+	c.recordEvent("delete", owner, ns, name, err)
+	return err
+}
+
+func (c *realVolumeSnapshotControl) recordEvent(verb string, owner runtime.Object, ns, name string, err error) {
+	if err == nil {
+		c.recorder.Eventf(owner, corev1.EventTypeNormal, fmt.Sprintf("Successful%s", strings.Title(verb)), "%s VolumeSnapshot %s/%s successful", verb, ns, name)
+	} else {
+		c.recorder.Eventf(owner, corev1.EventTypeWarning, fmt.Sprintf("Failed%s", strings.Title(verb)), "%s VolumeSnapshot %s/%s failed error: %s", verb, ns, name, err)
+	}
+}
+
+var _ VolumeSnapshotControlInterface = &realVolumeSnapshotControl{}
+
+// FakeVolumeSnapshotControl is a fake VolumeSnapshotControlInterface
+type FakeVolumeSnapshotControl struct {
+	// Snapshots are the states of the snapshots keyed by namespace/name
+	Snapshots map[string]*VolumeSnapshotState
+	// Sources are the PVCs of the snapshots keyed by namespace/name
+	Sources map[string]string
+}
+
+// NewFakeVolumeSnapshotControl returns a FakeVolumeSnapshotControl
+func NewFakeVolumeSnapshotControl() *FakeVolumeSnapshotControl {
+	return &FakeVolumeSnapshotControl{
+		Snapshots: map[string]*VolumeSnapshotState{},
+		Sources:   map[string]string{},
+	}
+}
+
+func (c *FakeVolumeSnapshotControl) CreateVolumeSnapshot(_ runtime.Object, ns, name, pvcName, _ string, _ map[string]string) error {
+	key := ns + "/" + name
+	if _, ok := c.Snapshots[key]; ok {
+		return errors.NewAlreadyExists(schema.GroupResource{Group: VolumeSnapshotGroupVersionKind.Group, Resource: "volumesnapshots"}, name)
+	}
+	c.Snapshots[key] = &VolumeSnapshotState{}
+	c.Sources[key] = pvcName
+	return nil
+}
+
+func (c *FakeVolumeSnapshotControl) GetVolumeSnapshot(ns, name string) (*VolumeSnapshotState, error) {
+	state, ok := c.Snapshots[ns+"/"+name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: VolumeSnapshotGroupVersionKind.Group, Resource: "volumesnapshots"}, name)
+	}
+	return state, nil
+}
+
+func (c *FakeVolumeSnapshotControl) DeleteVolumeSnapshot(_ runtime.Object, ns, name string) error {
+	delete(c.Snapshots, ns+"/"+name)
+	delete(c.Sources, ns+"/"+name)
+	return nil
+}
+
+var _ VolumeSnapshotControlInterface = &FakeVolumeSnapshotControl{}
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/dmapi"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
@@ -124,7 +125,7 @@ func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
 	var pdClients []pdapi.PDClient
 
 	if tc.Spec.PD != nil {
-		pdClients = append(pdClients, d.pdControl.GetPDClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled()))
+		pdClients = append(pdClients, d.pdControl.GetPDClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled(), pdapi.ClientPort(tc.PDClientPort())))
 	}
 
 	if tc.Spec.Cluster != nil && len(tc.Spec.Cluster.Name) > 0 {
@@ -132,10 +133,16 @@ func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
 		if len(namespace) == 0 {
 			namespace = tc.GetNamespace()
 		}
+		// the referenced cluster may be not accessible, e.g. it's in another Kubernetes cluster, use the default port then
+		refPDClientPort := int32(v1alpha1.DefaultPDClientPort)
+		if refTC, err := d.cli.PingcapV1alpha1().TidbClusters(namespace).Get(context.TODO(), tc.Spec.Cluster.Name, metav1.GetOptions{}); err == nil {
+			refPDClientPort = refTC.PDClientPort()
+		}
 		pdClients = append(pdClients,
 			d.pdControl.GetPDClient(pdapi.Namespace(namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled(),
 				pdapi.TLSCertFromTC(pdapi.Namespace(tc.GetNamespace()), tc.GetName()),
 				pdapi.ClusterRef(tc.Spec.Cluster.ClusterDomain),
+				pdapi.ClientPort(refPDClientPort),
 			),
 		)
 	}
@@ -170,7 +177,7 @@ func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
 		if member.Name == podName || member.Name == strArr[0] {
 			continue
 		}
-		memberURL := strings.ReplaceAll(member.PeerUrls[0], ":2380", ":2379")
+		memberURL := strings.ReplaceAll(member.PeerUrls[0], ":2380", fmt.Sprintf(":%d", tc.PDClientPort()))
 		membersArr = append(membersArr, memberURL)
 	}
 	delete(currentCluster.peers, podName)
@@ -185,17 +192,16 @@ func (d *tidbDiscovery) DiscoverDM(advertisePeerUrl string) (string, error) {
 		return "", fmt.Errorf("dm advertisePeerUrl is empty")
 	}
 	klog.Infof("dm advertisePeerUrl is: %s", advertisePeerUrl)
-	strArr := strings.Split(advertisePeerUrl, ".")
-	if len(strArr) != 2 {
+	hostAndPort := strings.Split(advertisePeerUrl, ":")
+	if len(hostAndPort) != 2 {
 		return "", fmt.Errorf("dm advertisePeerUrl format is wrong: %s", advertisePeerUrl)
 	}
-
-	podName, peerServiceNameWithPort := strArr[0], strArr[1]
-	strArr = strings.Split(peerServiceNameWithPort, ":")
-	if len(strArr) != 2 {
+	// the host is <pod>.<peer-service>, or <pod>.<peer-service>.<namespace>.svc.<cluster-domain> if the clusterDomain is set
+	strArr := strings.Split(hostAndPort[0], ".")
+	if len(strArr) != 2 && (len(strArr) < 5 || strArr[3] != "svc") {
 		return "", fmt.Errorf("dm advertisePeerUrl format is wrong: %s", advertisePeerUrl)
 	}
-	peerServiceName := strArr[0]
+	podName, peerServiceName := strArr[0], strArr[1]
 	dcName := strings.TrimSuffix(peerServiceName, "-dm-master-peer")
 	ns := os.Getenv("MY_POD_NAMESPACE")
 
@@ -217,10 +223,14 @@ func (d *tidbDiscovery) DiscoverDM(advertisePeerUrl string) (string, error) {
 
 	if len(currentCluster.peers) == int(dc.MasterStsDesiredReplicas()) {
 		delete(currentCluster.peers, podName)
+		// Join the dm-masters in other Kubernetes clusters if dc.Spec.Master.ExternalMembers is set
+		if len(dc.Spec.Master.ExternalMembers) != 0 {
+			return fmt.Sprintf("--join=%s", strings.Join(dc.Spec.Master.ExternalMembers, ",")), nil
+		}
 		return fmt.Sprintf("--initial-cluster=%s=%s://%s", podName, dc.Scheme(), advertisePeerUrl), nil
 	}
 
-	masterClient := d.masterControl.GetMasterClient(dc.GetNamespace(), dc.GetName(), dc.IsTLSClusterEnabled())
+	masterClient := d.masterControl.GetMasterClient(dc.GetNamespace(), dc.GetName(), dc.IsTLSClusterEnabled(), dmapi.MasterPort(dc.MasterPort()))
 	mastersInfos, err := masterClient.GetMasters()
 	if err != nil {
 		return "", err
@@ -234,7 +244,7 @@ func (d *tidbDiscovery) DiscoverDM(advertisePeerUrl string) (string, error) {
 		if master.Name == podName {
 			continue
 		}
-		memberURL := strings.ReplaceAll(master.PeerURLs[0], ":8291", ":8261")
+		memberURL := strings.ReplaceAll(master.PeerURLs[0], fmt.Sprintf(":%d", dc.MasterPeerPort()), fmt.Sprintf(":%d", dc.MasterPort()))
 		mastersArr = append(mastersArr, memberURL)
 	}
 	delete(currentCluster.peers, podName)
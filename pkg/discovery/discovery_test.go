@@ -711,6 +711,50 @@ func TestDiscoveryDMDiscovery(t *testing.T) {
 				g.Expect(s).To(Equal("--initial-cluster=demo-dm-master-2=http://demo-dm-master-2.demo-dm-master-peer:8291"))
 			},
 		},
+		{
+			name: "1 cluster, third ordinal with cluster domain, return the initial-cluster args",
+			ns:   "default",
+			url:  "demo-dm-master-2.demo-dm-master-peer.default.svc.cluster1.com:8291",
+			dc:   newDC(),
+			dmClusters: map[string]*clusterInfo{
+				"default/demo": {
+					resourceVersion: "1",
+					peers: map[string]struct{}{
+						"demo-dm-master-0": {},
+						"demo-dm-master-1": {},
+					},
+				},
+			},
+			expectFn: func(g *GomegaWithT, td *tidbDiscovery, s string, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(len(td.dmClusters["default/demo"].peers)).To(Equal(2))
+				g.Expect(s).To(Equal("--initial-cluster=demo-dm-master-2=http://demo-dm-master-2.demo-dm-master-peer.default.svc.cluster1.com:8291"))
+			},
+		},
+		{
+			name: "1 cluster, third ordinal with external members, return the join args",
+			ns:   "default",
+			url:  "demo-dm-master-2.demo-dm-master-peer.default.svc.cluster2.com:8291",
+			dc: func() *v1alpha1.DMCluster {
+				dc := newDC()
+				dc.Spec.Master.ExternalMembers = []string{"http://demo-dm-master-0.demo-dm-master-peer.default.svc.cluster1.com:8261"}
+				return dc
+			}(),
+			dmClusters: map[string]*clusterInfo{
+				"default/demo": {
+					resourceVersion: "1",
+					peers: map[string]struct{}{
+						"demo-dm-master-0": {},
+						"demo-dm-master-1": {},
+					},
+				},
+			},
+			expectFn: func(g *GomegaWithT, td *tidbDiscovery, s string, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(len(td.dmClusters["default/demo"].peers)).To(Equal(2))
+				g.Expect(s).To(Equal("--join=http://demo-dm-master-0.demo-dm-master-peer.default.svc.cluster1.com:8261"))
+			},
+		},
 		{
 			name: "1 cluster, the first ordinal second request, get members failed",
 			ns:   "default",
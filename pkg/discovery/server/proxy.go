@@ -27,9 +27,9 @@ import (
 	"k8s.io/klog/v2"
 )
 
-func buildUrl(tcName string, tlsEnabled bool) *url.URL {
+func buildUrl(tcName string, pdPort int32, tlsEnabled bool) *url.URL {
 	url := &url.URL{
-		Host:   fmt.Sprintf("%s-pd:2379", tcName),
+		Host:   fmt.Sprintf("%s-pd:%d", tcName, pdPort),
 		Scheme: "http",
 	}
 
@@ -80,9 +80,9 @@ type proxyServer struct {
 	tcTlsEnabled bool
 }
 
-func NewProxyServer(tcName string, tcTlsEnabled bool) Server {
+func NewProxyServer(tcName string, pdPort int32, tcTlsEnabled bool) Server {
 	return &proxyServer{
-		proxyTo:      buildUrl(tcName, tcTlsEnabled),
+		proxyTo:      buildUrl(tcName, pdPort, tcTlsEnabled),
 		tcTlsEnabled: tcTlsEnabled,
 	}
 }
@@ -30,7 +30,7 @@ func TestProxyServer(t *testing.T) {
 	defer dashboardServer.Close()
 
 	t.Log("create a proxy server")
-	s := NewProxyServer("foo", false)
+	s := NewProxyServer("foo", 2379, false)
 	proxyToURL, err := url.Parse(dashboardServer.URL)
 	if err != nil {
 		t.Fatal(err)
@@ -56,7 +56,7 @@ func TestProxyServer(t *testing.T) {
 }
 
 func TestProxyServerTLS(t *testing.T) {
-	s := NewProxyServer("foo", true)
+	s := NewProxyServer("foo", 2379, true)
 	httpServer := httptest.NewServer(s.(*proxyServer))
 	defer httpServer.Close()
 
@@ -14,10 +14,15 @@
 package dmapi
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 
 	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
@@ -36,13 +41,50 @@ type MasterClient interface {
 	EvictLeader() error
 	DeleteMaster(name string) error
 	DeleteWorker(name string) error
+	// TransferSource binds the source to the given free worker, the source will be unbound from its current worker
+	TransferSource(source, worker string) error
+	// GetSubTaskStatuses returns the statuses of the subtasks of all the tasks, it returns ErrOpenAPINotEnabled
+	// if the OpenAPI of dm-master is not enabled
+	GetSubTaskStatuses() ([]*SubTaskStatus, error)
+
+	// The methods below manage the sources and the tasks through the OpenAPI of dm-master,
+	// they return ErrOpenAPINotEnabled if the OpenAPI is not enabled
+
+	// GetSources returns all the sources
+	GetSources() ([]*SourceInfo, error)
+	// CreateSource creates a source
+	CreateSource(source *SourceConfig) error
+	// UpdateSource updates the config of a source, e.g. the password
+	UpdateSource(source *SourceConfig) error
+	// DeleteSource deletes a source, the source must not be used by any task
+	DeleteSource(name string) error
+	// GetTasks returns all the tasks
+	GetTasks() ([]*TaskInfo, error)
+	// GetTaskStatuses returns the statuses of the subtasks of a task
+	GetTaskStatuses(name string) ([]*SubTaskStatus, error)
+	// CreateTask creates a task from the config in the JSON format of the Task in the OpenAPI, the task is not started
+	CreateTask(task json.RawMessage) error
+	// UpdateTask updates the config of a stopped task
+	UpdateTask(name string, task json.RawMessage) error
+	// StartTask starts or resumes a task
+	StartTask(name string) error
+	// StopTask stops a task, the checkpoints of the task are kept and it resumes from them once started again
+	StopTask(name string) error
+	// DeleteTask deletes a task and its checkpoints
+	DeleteTask(name string) error
 }
 
 var (
 	membersPrefix = "apis/v1alpha1/members"
 	leaderPrefix  = "apis/v1alpha1/leader"
+	sourcesPrefix = "api/v1/sources"
+	tasksPrefix   = "api/v1/tasks"
 )
 
+// ErrOpenAPINotEnabled is returned if the OpenAPI of dm-master is not enabled, it's only enabled with
+// `openapi = true` in the config of dm-master
+var ErrOpenAPINotEnabled = errors.New("the OpenAPI of dm-master is not enabled")
+
 type RespHeader struct {
 	Result bool   `json:"result,omitempty"`
 	Msg    string `json:"msg,omitempty"`
@@ -106,6 +148,63 @@ type LeaderResp struct {
 	ListMemberResp []*ListMemberLeader `json:"members,omitempty"`
 }
 
+type TransferSourceReq struct {
+	WorkerName string `json:"worker_name"`
+}
+
+type SourceInfo struct {
+	Name string `json:"source_name"`
+}
+
+type SourceListResp struct {
+	Total int           `json:"total"`
+	Data  []*SourceInfo `json:"data"`
+}
+
+// SourceConfig is the config of a source, that is an upstream MySQL or MariaDB
+type SourceConfig struct {
+	Name       string `json:"source_name"`
+	Host       string `json:"host"`
+	Port       int32  `json:"port"`
+	User       string `json:"user"`
+	Password   string `json:"password"`
+	Enable     bool   `json:"enable"`
+	EnableGTID bool   `json:"enable_gtid"`
+}
+
+type CreateSourceReq struct {
+	Source *SourceConfig `json:"source"`
+}
+
+type TaskReq struct {
+	Task json.RawMessage `json:"task"`
+}
+
+type TaskInfo struct {
+	Name string `json:"name"`
+}
+
+type TaskListResp struct {
+	Total int         `json:"total"`
+	Data  []*TaskInfo `json:"data"`
+}
+
+// SubTaskStatus is the status of a subtask, that is the migration of a task from one source
+type SubTaskStatus struct {
+	Name       string `json:"name"`
+	SourceName string `json:"source_name"`
+	WorkerName string `json:"worker_name"`
+	// Stage is one of New, Running, Paused, Stopped and Finished
+	Stage string `json:"stage"`
+	// Unit is the processing unit, one of dump, load and sync
+	Unit string `json:"unit"`
+}
+
+type SubTaskStatusListResp struct {
+	Total int              `json:"total"`
+	Data  []*SubTaskStatus `json:"data"`
+}
+
 // masterClient is default implementation of MasterClient
 type masterClient struct {
 	url        string
@@ -225,6 +324,172 @@ func (c *masterClient) DeleteWorker(name string) error {
 	return c.deleteMember(query)
 }
 
+func (c *masterClient) TransferSource(source, worker string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s/transfer", c.url, sourcesPrefix, source)
+	data, err := json.Marshal(&TransferSourceReq{WorkerName: worker})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode >= 400 {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("unable to transfer source %s to worker %s, status code: %d, resp: %s", source, worker, res.StatusCode, body)
+	}
+	return nil
+}
+
+func (c *masterClient) GetSubTaskStatuses() ([]*SubTaskStatus, error) {
+	tasks, err := c.GetTasks()
+	if err != nil {
+		return nil, err
+	}
+	var statuses []*SubTaskStatus
+	for _, task := range tasks {
+		taskStatuses, err := c.GetTaskStatuses(task.Name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, taskStatuses...)
+	}
+	return statuses, nil
+}
+
+func (c *masterClient) GetSources() ([]*SourceInfo, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, sourcesPrefix)
+	body, err := c.doOpenAPIRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	sourceListResp := &SourceListResp{}
+	if err := json.Unmarshal(body, sourceListResp); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal list sources resp: %s, err: %s", body, err)
+	}
+	return sourceListResp.Data, nil
+}
+
+func (c *masterClient) CreateSource(source *SourceConfig) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, sourcesPrefix)
+	_, err := c.doOpenAPIRequest("POST", apiURL, &CreateSourceReq{Source: source})
+	return err
+}
+
+func (c *masterClient) UpdateSource(source *SourceConfig) error {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, sourcesPrefix, url.PathEscape(source.Name))
+	_, err := c.doOpenAPIRequest("PUT", apiURL, &CreateSourceReq{Source: source})
+	return err
+}
+
+func (c *masterClient) DeleteSource(name string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, sourcesPrefix, url.PathEscape(name))
+	_, err := c.doOpenAPIRequest("DELETE", apiURL, nil)
+	return err
+}
+
+func (c *masterClient) GetTasks() ([]*TaskInfo, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, tasksPrefix)
+	body, err := c.doOpenAPIRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	taskListResp := &TaskListResp{}
+	if err := json.Unmarshal(body, taskListResp); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal list tasks resp: %s, err: %s", body, err)
+	}
+	return taskListResp.Data, nil
+}
+
+func (c *masterClient) GetTaskStatuses(name string) ([]*SubTaskStatus, error) {
+	apiURL := fmt.Sprintf("%s/%s/%s/status", c.url, tasksPrefix, url.PathEscape(name))
+	body, err := c.doOpenAPIRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	statusListResp := &SubTaskStatusListResp{}
+	if err := json.Unmarshal(body, statusListResp); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal task %s status resp: %s, err: %s", name, body, err)
+	}
+	return statusListResp.Data, nil
+}
+
+func (c *masterClient) CreateTask(task json.RawMessage) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, tasksPrefix)
+	_, err := c.doOpenAPIRequest("POST", apiURL, &TaskReq{Task: task})
+	return err
+}
+
+func (c *masterClient) UpdateTask(name string, task json.RawMessage) error {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, tasksPrefix, url.PathEscape(name))
+	_, err := c.doOpenAPIRequest("PUT", apiURL, &TaskReq{Task: task})
+	return err
+}
+
+func (c *masterClient) StartTask(name string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s/start", c.url, tasksPrefix, url.PathEscape(name))
+	_, err := c.doOpenAPIRequest("POST", apiURL, struct{}{})
+	return err
+}
+
+func (c *masterClient) StopTask(name string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s/stop", c.url, tasksPrefix, url.PathEscape(name))
+	_, err := c.doOpenAPIRequest("POST", apiURL, struct{}{})
+	return err
+}
+
+func (c *masterClient) DeleteTask(name string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s?force=true", c.url, tasksPrefix, url.PathEscape(name))
+	_, err := c.doOpenAPIRequest("DELETE", apiURL, nil)
+	return err
+}
+
+// doOpenAPIRequest sends a request with the body in JSON to an OpenAPI of dm-master and returns the body of
+// the response. The OpenAPIs are not served if they are not enabled, which is told apart from the errors of
+// the OpenAPIs, e.g. the task is not found, as the body of the 404 response is not a JSON error
+func (c *masterClient) doOpenAPIRequest(method, apiURL string, reqBody interface{}) ([]byte, error) {
+	var reader io.Reader
+	if reqBody != nil {
+		data, err := json.Marshal(reqBody)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewBuffer(data)
+	}
+	req, err := http.NewRequest(method, apiURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httputil.DeferClose(res.Body)
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound && !json.Valid(body) {
+		return nil, ErrOpenAPINotEnabled
+	}
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("Error response %v URL %s, body response: %s", res.StatusCode, apiURL, body)
+	}
+	return body, nil
+}
+
 // NewMasterClient returns a new MasterClient
 func NewMasterClient(url string, timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) MasterClient {
 	return &masterClient{
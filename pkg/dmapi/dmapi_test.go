@@ -208,3 +208,117 @@ func TestDeleteMember(t *testing.T) {
 		g.Expect(err).NotTo(HaveOccurred())
 	}
 }
+
+func TestTransferSource(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("POST"), "check method")
+		g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s/mysql-01/transfer", sourcesPrefix)), "check url")
+		g.Expect(request.Header.Get("Content-Type")).To(Equal(ContentTypeJSON), "check content type")
+
+		req := &TransferSourceReq{}
+		g.Expect(json.NewDecoder(request.Body).Decode(req)).To(Succeed())
+		g.Expect(req.WorkerName).To(Equal("dm-worker-1"))
+		w.WriteHeader(http.StatusOK)
+	})
+	defer svc.Close()
+
+	masterClient := NewMasterClient(svc.URL, DefaultTimeout, &tls.Config{}, false)
+	err := masterClient.TransferSource("mysql-01", "dm-worker-1")
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestGetSubTaskStatuses(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	statuses := []*SubTaskStatus{
+		{Name: "task-1", SourceName: "mysql-01", WorkerName: "dm-worker-0", Stage: "Running", Unit: "dump"},
+		{Name: "task-1", SourceName: "mysql-02", WorkerName: "dm-worker-1", Stage: "Running", Unit: "sync"},
+	}
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("GET"), "check method")
+		var resp interface{}
+		switch request.URL.Path {
+		case "/" + tasksPrefix:
+			resp = &TaskListResp{Total: 1, Data: []*TaskInfo{{Name: "task-1"}}}
+		case fmt.Sprintf("/%s/task-1/status", tasksPrefix):
+			resp = &SubTaskStatusListResp{Total: 2, Data: statuses}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, err := json.Marshal(resp)
+		g.Expect(err).NotTo(HaveOccurred())
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.Write(data)
+	})
+	defer svc.Close()
+
+	masterClient := NewMasterClient(svc.URL, DefaultTimeout, &tls.Config{}, false)
+	result, err := masterClient.GetSubTaskStatuses()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(statuses))
+
+	notEnabled := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer notEnabled.Close()
+
+	masterClient = NewMasterClient(notEnabled.URL, DefaultTimeout, &tls.Config{}, false)
+	_, err = masterClient.GetSubTaskStatuses()
+	g.Expect(err).To(Equal(ErrOpenAPINotEnabled))
+}
+
+func TestManageTasks(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var requests []string
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		requests = append(requests, request.Method+" "+request.URL.RequestURI())
+		switch request.Method + " " + request.URL.Path {
+		case "POST /" + sourcesPrefix, "PUT /" + sourcesPrefix + "/mysql-01":
+			req := &CreateSourceReq{}
+			g.Expect(json.NewDecoder(request.Body).Decode(req)).To(Succeed())
+			g.Expect(req.Source.Name).To(Equal("mysql-01"))
+			g.Expect(req.Source.Enable).To(BeTrue())
+		case "POST /" + tasksPrefix, "PUT /" + tasksPrefix + "/task-1":
+			req := &TaskReq{}
+			g.Expect(json.NewDecoder(request.Body).Decode(req)).To(Succeed())
+			g.Expect(string(req.Task)).To(Equal(`{"name":"task-1"}`))
+		case "POST /" + tasksPrefix + "/task-1/start", "POST /" + tasksPrefix + "/task-1/stop", "DELETE /" + tasksPrefix + "/task-1",
+			"DELETE /" + sourcesPrefix + "/mysql-01":
+		default:
+			// an error of the OpenAPI
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_msg":"task not found","error_code":10000}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer svc.Close()
+
+	masterClient := NewMasterClient(svc.URL, DefaultTimeout, &tls.Config{}, false)
+	g.Expect(masterClient.CreateSource(&SourceConfig{Name: "mysql-01", Host: "mysql", Port: 3306, User: "root", Enable: true})).To(Succeed())
+	g.Expect(masterClient.UpdateSource(&SourceConfig{Name: "mysql-01", Host: "mysql", Port: 3306, User: "root", Enable: true})).To(Succeed())
+	g.Expect(masterClient.CreateTask(json.RawMessage(`{"name":"task-1"}`))).To(Succeed())
+	g.Expect(masterClient.UpdateTask("task-1", json.RawMessage(`{"name":"task-1"}`))).To(Succeed())
+	g.Expect(masterClient.StartTask("task-1")).To(Succeed())
+	g.Expect(masterClient.StopTask("task-1")).To(Succeed())
+	g.Expect(masterClient.DeleteTask("task-1")).To(Succeed())
+	g.Expect(masterClient.DeleteSource("mysql-01")).To(Succeed())
+	err := masterClient.StartTask("task-2")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err).NotTo(Equal(ErrOpenAPINotEnabled))
+	g.Expect(requests).To(Equal([]string{
+		"POST /" + sourcesPrefix,
+		"PUT /" + sourcesPrefix + "/mysql-01",
+		"POST /" + tasksPrefix,
+		"PUT /" + tasksPrefix + "/task-1",
+		"POST /" + tasksPrefix + "/task-1/start",
+		"POST /" + tasksPrefix + "/task-1/stop",
+		"DELETE /" + tasksPrefix + "/task-1?force=true",
+		"DELETE /" + sourcesPrefix + "/mysql-01",
+		"POST /" + tasksPrefix + "/task-2/start",
+	}))
+}
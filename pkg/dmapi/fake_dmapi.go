@@ -14,18 +14,32 @@
 package dmapi
 
 import (
+	"encoding/json"
 	"fmt"
 )
 
 type ActionType string
 
 const (
-	GetMastersActionType   ActionType = "GetMasters"
-	GetWorkersActionType   ActionType = "GetWorkers"
-	GetLeaderActionType    ActionType = "GetLeader"
-	EvictLeaderActionType  ActionType = "EvictLeader"
-	DeleteMasterActionType ActionType = "DeleteMaster"
-	DeleteWorkerActionType ActionType = "DeleteWorker"
+	GetMastersActionType         ActionType = "GetMasters"
+	GetWorkersActionType         ActionType = "GetWorkers"
+	GetLeaderActionType          ActionType = "GetLeader"
+	EvictLeaderActionType        ActionType = "EvictLeader"
+	DeleteMasterActionType       ActionType = "DeleteMaster"
+	DeleteWorkerActionType       ActionType = "DeleteWorker"
+	TransferSourceActionType     ActionType = "TransferSource"
+	GetSubTaskStatusesActionType ActionType = "GetSubTaskStatuses"
+	GetSourcesActionType         ActionType = "GetSources"
+	CreateSourceActionType       ActionType = "CreateSource"
+	UpdateSourceActionType       ActionType = "UpdateSource"
+	DeleteSourceActionType       ActionType = "DeleteSource"
+	GetTasksActionType           ActionType = "GetTasks"
+	GetTaskStatusesActionType    ActionType = "GetTaskStatuses"
+	CreateTaskActionType         ActionType = "CreateTask"
+	UpdateTaskActionType         ActionType = "UpdateTask"
+	StartTaskActionType          ActionType = "StartTask"
+	StopTaskActionType           ActionType = "StopTask"
+	DeleteTaskActionType         ActionType = "DeleteTask"
 )
 
 type NotFoundReaction struct {
@@ -40,6 +54,10 @@ type Action struct {
 	ID     uint64
 	Name   string
 	Labels map[string]string
+	// Source is the source of CreateSource and UpdateSource
+	Source *SourceConfig
+	// Task is the task config of CreateTask and UpdateTask
+	Task json.RawMessage
 }
 
 type Reaction func(action *Action) (interface{}, error)
@@ -113,3 +131,93 @@ func (c *FakeMasterClient) DeleteWorker(_ string) error {
 	_, err := c.fakeAPI(DeleteWorkerActionType, action)
 	return err
 }
+
+func (c *FakeMasterClient) TransferSource(source, worker string) error {
+	action := &Action{Name: worker, Labels: map[string]string{"source": source}}
+	_, err := c.fakeAPI(TransferSourceActionType, action)
+	return err
+}
+
+func (c *FakeMasterClient) GetSubTaskStatuses() ([]*SubTaskStatus, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetSubTaskStatusesActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*SubTaskStatus), nil
+}
+
+func (c *FakeMasterClient) GetSources() ([]*SourceInfo, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetSourcesActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*SourceInfo), nil
+}
+
+func (c *FakeMasterClient) CreateSource(source *SourceConfig) error {
+	action := &Action{Name: source.Name, Source: source}
+	_, err := c.fakeAPI(CreateSourceActionType, action)
+	return err
+}
+
+func (c *FakeMasterClient) UpdateSource(source *SourceConfig) error {
+	action := &Action{Name: source.Name, Source: source}
+	_, err := c.fakeAPI(UpdateSourceActionType, action)
+	return err
+}
+
+func (c *FakeMasterClient) DeleteSource(name string) error {
+	action := &Action{Name: name}
+	_, err := c.fakeAPI(DeleteSourceActionType, action)
+	return err
+}
+
+func (c *FakeMasterClient) GetTasks() ([]*TaskInfo, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetTasksActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*TaskInfo), nil
+}
+
+func (c *FakeMasterClient) GetTaskStatuses(name string) ([]*SubTaskStatus, error) {
+	action := &Action{Name: name}
+	result, err := c.fakeAPI(GetTaskStatusesActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*SubTaskStatus), nil
+}
+
+func (c *FakeMasterClient) CreateTask(task json.RawMessage) error {
+	action := &Action{Task: task}
+	_, err := c.fakeAPI(CreateTaskActionType, action)
+	return err
+}
+
+func (c *FakeMasterClient) UpdateTask(name string, task json.RawMessage) error {
+	action := &Action{Name: name, Task: task}
+	_, err := c.fakeAPI(UpdateTaskActionType, action)
+	return err
+}
+
+func (c *FakeMasterClient) StartTask(name string) error {
+	action := &Action{Name: name}
+	_, err := c.fakeAPI(StartTaskActionType, action)
+	return err
+}
+
+func (c *FakeMasterClient) StopTask(name string) error {
+	action := &Action{Name: name}
+	_, err := c.fakeAPI(StopTaskActionType, action)
+	return err
+}
+
+func (c *FakeMasterClient) DeleteTask(name string) error {
+	action := &Action{Name: name}
+	_, err := c.fakeAPI(DeleteTaskActionType, action)
+	return err
+}
@@ -25,11 +25,40 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// defaultMasterPort is the default port of the dm-master client requests
+const defaultMasterPort = 8261
+
+// Option configures the MasterClient
+type Option func(c *clientConfig)
+
+type clientConfig struct {
+	// port is the client port of dm-master. If it is 0, the default port is used
+	port int32
+}
+
+// MasterPort sets the client port of dm-master, it is used when generating the dm-master address.
+func MasterPort(port int32) Option {
+	return func(c *clientConfig) {
+		c.port = port
+	}
+}
+
+func newClientConfig(opts ...Option) *clientConfig {
+	c := &clientConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.port == 0 {
+		c.port = defaultMasterPort
+	}
+	return c
+}
+
 // MasterControlInterface is an interface that knows how to manage and get dm cluster's master client
 type MasterControlInterface interface {
 	// GetMasterClient provides MasterClient of the dm cluster.
-	GetMasterClient(namespace string, dcName string, tlsEnabled bool) MasterClient
-	GetMasterPeerClient(namespace string, dcName, podName string, tlsEnabled bool) MasterClient
+	GetMasterClient(namespace string, dcName string, tlsEnabled bool, opts ...Option) MasterClient
+	GetMasterPeerClient(namespace string, dcName, podName string, tlsEnabled bool, opts ...Option) MasterClient
 }
 
 // defaultMasterControl is the default implementation of MasterControlInterface.
@@ -45,10 +74,12 @@ func NewDefaultMasterControl(secretLister corelisterv1.SecretLister) MasterContr
 }
 
 // GetMasterClient provides a MasterClient of real dm-master cluster, if the MasterClient not existing, it will create new one.
-func (mc *defaultMasterControl) GetMasterClient(namespace string, dcName string, tlsEnabled bool) MasterClient {
+func (mc *defaultMasterControl) GetMasterClient(namespace string, dcName string, tlsEnabled bool, opts ...Option) MasterClient {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
 
+	config := newClientConfig(opts...)
+
 	var tlsConfig *tls.Config
 	var err error
 	var scheme = "http"
@@ -58,23 +89,25 @@ func (mc *defaultMasterControl) GetMasterClient(namespace string, dcName string,
 		tlsConfig, err = pdapi.GetTLSConfig(mc.secretLister, pdapi.Namespace(namespace), util.DMClientTLSSecretName(dcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for dm cluster %q, master client may not work: %v", dcName, err)
-			return NewMasterClient(MasterClientURL(namespace, dcName, scheme), DefaultTimeout, tlsConfig, true)
+			return NewMasterClient(MasterClientURL(namespace, dcName, scheme, config.port), DefaultTimeout, tlsConfig, true)
 		}
 
-		return NewMasterClient(MasterClientURL(namespace, dcName, scheme), DefaultTimeout, tlsConfig, true)
+		return NewMasterClient(MasterClientURL(namespace, dcName, scheme, config.port), DefaultTimeout, tlsConfig, true)
 	}
 
 	key := masterClientKey(scheme, namespace, dcName)
 	if _, ok := mc.masterClients[key]; !ok {
-		mc.masterClients[key] = NewMasterClient(MasterClientURL(namespace, dcName, scheme), DefaultTimeout, nil, false)
+		mc.masterClients[key] = NewMasterClient(MasterClientURL(namespace, dcName, scheme, config.port), DefaultTimeout, nil, false)
 	}
 	return mc.masterClients[key]
 }
 
-func (mc *defaultMasterControl) GetMasterPeerClient(namespace string, dcName string, podName string, tlsEnabled bool) MasterClient {
+func (mc *defaultMasterControl) GetMasterPeerClient(namespace string, dcName string, podName string, tlsEnabled bool, opts ...Option) MasterClient {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
 
+	config := newClientConfig(opts...)
+
 	var tlsConfig *tls.Config
 	var err error
 	var scheme = "http"
@@ -84,13 +117,13 @@ func (mc *defaultMasterControl) GetMasterPeerClient(namespace string, dcName str
 		tlsConfig, err = pdapi.GetTLSConfig(mc.secretLister, pdapi.Namespace(namespace), util.DMClientTLSSecretName(dcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for dm cluster %q, master client may not work: %v", dcName, err)
-			return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+			return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme, config.port), DefaultTimeout, tlsConfig, true)
 		}
 
-		return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+		return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme, config.port), DefaultTimeout, tlsConfig, true)
 	}
 
-	return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+	return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme, config.port), DefaultTimeout, tlsConfig, true)
 }
 
 // masterClientKey returns the master client key
@@ -103,13 +136,13 @@ func masterPeerClientKey(schema, namespace, clusterName, podName string) string
 }
 
 // MasterClientURL builds the url of master client
-func MasterClientURL(namespace, clusterName, scheme string) string {
-	return fmt.Sprintf("%s://%s-dm-master.%s:8261", scheme, clusterName, namespace)
+func MasterClientURL(namespace, clusterName, scheme string, port int32) string {
+	return fmt.Sprintf("%s://%s-dm-master.%s:%d", scheme, clusterName, namespace, port)
 }
 
 // MasterPeerClientURL builds the url of master peer client. It's used to evict leader because dm can't forward evict leader command now
-func MasterPeerClientURL(namespace, clusterName, podName, scheme string) string {
-	return fmt.Sprintf("%s://%s.%s-dm-master-peer.%s:8261", scheme, podName, clusterName, namespace)
+func MasterPeerClientURL(namespace, clusterName, podName, scheme string, port int32) string {
+	return fmt.Sprintf("%s://%s.%s-dm-master-peer.%s:%d", scheme, podName, clusterName, namespace, port)
 }
 
 // FakeMasterControl implements a fake version of MasterControlInterface.
@@ -133,10 +166,10 @@ func (fmc *FakeMasterControl) SetMasterPeerClient(namespace, dcName, podName str
 	fmc.masterPeerClients[masterPeerClientKey("http", namespace, dcName, podName)] = masterPeerClient
 }
 
-func (fmc *FakeMasterControl) GetMasterClient(namespace string, dcName string, tlsEnabled bool) MasterClient {
-	return fmc.defaultMasterControl.GetMasterClient(namespace, dcName, tlsEnabled)
+func (fmc *FakeMasterControl) GetMasterClient(namespace string, dcName string, tlsEnabled bool, opts ...Option) MasterClient {
+	return fmc.defaultMasterControl.GetMasterClient(namespace, dcName, tlsEnabled, opts...)
 }
 
-func (fmc *FakeMasterControl) GetMasterPeerClient(namespace, dcName, podName string, tlsEnabled bool) MasterClient {
+func (fmc *FakeMasterControl) GetMasterPeerClient(namespace, dcName, podName string, tlsEnabled bool, opts ...Option) MasterClient {
 	return fmc.masterPeerClients[masterPeerClientKey("http", namespace, dcName, podName)]
 }
@@ -28,9 +28,12 @@ import (
 var (
 	allFeatures     = sets.NewString(StableScheduling)
 	defaultFeatures = map[string]bool{
-		StableScheduling:    true,
-		AdvancedStatefulSet: false,
-		AutoScaling:         false,
+		StableScheduling:           true,
+		AdvancedStatefulSet:        false,
+		AutoScaling:                false,
+		InPlacePodVerticalScaling:  false,
+		DMWorkerFailoverRecreation: false,
+		PVTopologyRepair:           false,
 	}
 	// DefaultFeatureGate is a shared global FeatureGate.
 	DefaultFeatureGate FeatureGate = NewDefaultFeatureGate()
@@ -45,6 +48,22 @@ const (
 
 	// AutoScaling controls whether to use TidbClusterAutoScaler to auto scale-in/out pods
 	AutoScaling string = "AutoScaling"
+
+	// ScaleOutResourceCheck controls whether to check the resource quotas and the capacity of the cluster
+	// before scaling out, it's enabled for all clusters by the --scale-out-resource-check flag, which is disabled by default
+	ScaleOutResourceCheck string = "ScaleOutResourceCheck"
+
+	// InPlacePodVerticalScaling controls whether to resize the CPU and memory of the TiDB and TiKV Pods in place
+	// instead of rolling update them, it requires the InPlacePodVerticalScaling feature of Kubernetes
+	InPlacePodVerticalScaling string = "InPlacePodVerticalScaling"
+
+	// DMWorkerFailoverRecreation controls whether the failover of dm-worker deletes the Pod and the PVC of the
+	// offline worker, so that the worker stuck on a dead node is recreated with fresh storage
+	DMWorkerFailoverRecreation string = "DMWorkerFailoverRecreation"
+
+	// PVTopologyRepair controls whether the PVCs and the PVs of the unschedulable Pods are deleted if the PVs
+	// are bound to a node that no longer exists, so that the Pods stuck in Pending are recreated with fresh storage
+	PVTopologyRepair string = "PVTopologyRepair"
 )
 
 type FeatureGate interface {
@@ -134,3 +153,13 @@ func NewDefaultFeatureGate() FeatureGate {
 	f.SetFromMap(defaultFeatures)
 	return f
 }
+
+// EnabledForCluster returns whether the feature is enabled for a cluster. The feature gates in the spec of
+// the cluster take precedence over the operator-wide default, so that a feature can be canaried on or turned
+// off for a single cluster.
+func EnabledForCluster(clusterGates map[string]bool, key string, operatorDefault bool) bool {
+	if enabled, ok := clusterGates[key]; ok {
+		return enabled
+	}
+	return operatorDefault
+}
@@ -105,3 +105,45 @@ func TestEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestEnabledForCluster(t *testing.T) {
+	tests := []struct {
+		name            string
+		clusterGates    map[string]bool
+		operatorDefault bool
+		want            bool
+	}{
+		{
+			name:            "no gates set for the cluster",
+			operatorDefault: true,
+			want:            true,
+		},
+		{
+			name:            "disabled for the cluster",
+			clusterGates:    map[string]bool{"a": false},
+			operatorDefault: true,
+			want:            false,
+		},
+		{
+			name:            "enabled for the cluster",
+			clusterGates:    map[string]bool{"a": true},
+			operatorDefault: false,
+			want:            true,
+		},
+		{
+			name:            "other gates set for the cluster",
+			clusterGates:    map[string]bool{"b": true},
+			operatorDefault: false,
+			want:            false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EnabledForCluster(tt.clusterGates, "a", tt.operatorDefault)
+			if got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	debugArtifactsContainerName = "debug-artifacts"
+	debugArtifactsVolName       = "debug-artifacts"
+	debugArtifactsDir           = "/var/lib/debug-artifacts"
+	// debugArtifactsHeapDir keeps the last heap profile, which is moved to debugArtifactsDir to be uploaded
+	// once the component becomes unavailable
+	debugArtifactsHeapDir = debugArtifactsDir + "/.heap"
+	// debugArtifactsMinAge skips the artifacts being written, e.g. a core dump in progress
+	debugArtifactsMinAge = "30s"
+
+	defaultDebugArtifactsInterval = time.Minute
+	tikvStatusPort                = 20180
+)
+
+// getDebugArtifactsSpec returns the debug artifacts spec of the component, which is nil if it's not enabled
+func getDebugArtifactsSpec(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) *v1alpha1.DebugArtifactsSpec {
+	switch memberType {
+	case v1alpha1.TiDBMemberType:
+		if tc.Spec.TiDB != nil {
+			return tc.Spec.TiDB.DebugArtifacts
+		}
+	case v1alpha1.TiKVMemberType:
+		if tc.Spec.TiKV != nil {
+			return tc.Spec.TiKV.DebugArtifacts
+		}
+	}
+	return nil
+}
+
+// addDebugArtifactsSidecar shares a volume between the component and a sidecar uploading the core dumps and the
+// heap profiles of the component to the storage, if the capture of the debug artifacts is enabled
+func addDebugArtifactsSidecar(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, podSpec *corev1.PodSpec) error {
+	spec := getDebugArtifactsSpec(tc, memberType)
+	if spec == nil {
+		return nil
+	}
+
+	env, _, err := backuputil.GenerateStorageCertEnv(tc.GetNamespace(), false, spec.StorageProvider, deps.SecretLister)
+	if err != nil {
+		return fmt.Errorf("debug artifacts: %v", err)
+	}
+	remote, err := rcloneRemote(spec.StorageProvider, tc.GetNamespace())
+	if err != nil {
+		return fmt.Errorf("debug artifacts: %v", err)
+	}
+	heapURL := ""
+	if !spec.DisableHeapProfile && !tc.IsTLSClusterEnabled() {
+		port := int32(tikvStatusPort)
+		if memberType == v1alpha1.TiDBMemberType {
+			port = tc.TiDBStatusPort()
+		}
+		heapURL = fmt.Sprintf("http://127.0.0.1:%d/debug/pprof/heap", port)
+	}
+	interval := parseDurationOrDefault(spec.Interval, defaultDebugArtifactsInterval)
+
+	volMount := corev1.VolumeMount{Name: debugArtifactsVolName, MountPath: debugArtifactsDir}
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: debugArtifactsVolName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: spec.SizeLimit},
+		},
+	})
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if c.Name != memberType.String() {
+			continue
+		}
+		c.VolumeMounts = append(c.VolumeMounts, volMount)
+		// the core dumps are written to the working directory if the core pattern of the node is a relative path
+		c.WorkingDir = debugArtifactsDir
+		if memberType == v1alpha1.TiDBMemberType {
+			// dump the core when the Go runtime crashes
+			c.Env = util.AppendEnv(c.Env, []corev1.EnvVar{{Name: "GOTRACEBACK", Value: "crash"}})
+		}
+	}
+
+	sidecar := corev1.Container{
+		Name:  debugArtifactsContainerName,
+		Image: spec.Image,
+		// the rclone config of the remote storages is generated by the entrypoint of tidb-backup-manager from the env
+		Args: []string{"sh", "-c", debugArtifactsScript(remote, heapURL, interval)},
+		Env: append([]corev1.EnvVar{
+			{
+				Name: "POD_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.name",
+					},
+				},
+			},
+		}, env...),
+		VolumeMounts: []corev1.VolumeMount{volMount},
+	}
+	if spec.Resources != nil {
+		sidecar.Resources = *spec.Resources
+	}
+	if spec.Local != nil {
+		sidecar.VolumeMounts = append(sidecar.VolumeMounts, spec.Local.VolumeMount)
+		podSpec.Volumes = append(podSpec.Volumes, spec.Local.Volume)
+	}
+	podSpec.Containers = append(podSpec.Containers, sidecar)
+	return nil
+}
+
+// debugArtifactsScript returns the script of the sidecar. It keeps the last heap profile taken while the component
+// is available, moves it to the upload directory once the profile can't be taken, and moves the artifacts older
+// than debugArtifactsMinAge to the storage.
+func debugArtifactsScript(remote, heapURL string, interval time.Duration) string {
+	heap := ""
+	if heapURL != "" {
+		heap = fmt.Sprintf(`  if wget -q -T 10 -O %[1]s/heap.tmp %[2]s; then
+    mv %[1]s/heap.tmp %[1]s/heap.prof
+  elif [ -f %[1]s/heap.prof ]; then
+    mv %[1]s/heap.prof %[3]s/heap-$(date +%%Y%%m%%d%%H%%M%%S).prof
+  fi
+`, debugArtifactsHeapDir, heapURL, debugArtifactsDir)
+	}
+	return fmt.Sprintf(`mkdir -p %[1]s
+while true; do
+%[2]s  rclone --config=/tmp/rclone.conf move %[3]s %[4]s/${POD_NAME} --exclude '.heap/**' --min-age %[5]s
+  sleep %[6]d
+done
+`, debugArtifactsHeapDir, heap, debugArtifactsDir, remote, debugArtifactsMinAge, int64(interval/time.Second))
+}
+
+// rcloneRemote returns the rclone remote the files of the Pods in the namespace are uploaded to by the sidecars
+func rcloneRemote(provider v1alpha1.StorageProvider, ns string) (string, error) {
+	switch backuputil.GetStorageType(provider) {
+	case v1alpha1.BackupStorageTypeS3:
+		return fmt.Sprintf("s3:%s", path.Join(provider.S3.Bucket, provider.S3.Prefix, ns)), nil
+	case v1alpha1.BackupStorageTypeGcs:
+		return fmt.Sprintf("gcs:%s", path.Join(provider.Gcs.Bucket, provider.Gcs.Prefix, ns)), nil
+	case v1alpha1.BackupStorageTypeAzblob:
+		return fmt.Sprintf("azure:%s", path.Join(provider.Azblob.Container, provider.Azblob.Prefix, ns)), nil
+	case v1alpha1.BackupStorageTypeLocal:
+		return path.Join(provider.Local.VolumeMount.MountPath, provider.Local.Prefix, ns), nil
+	default:
+		return "", fmt.Errorf("no storage is set")
+	}
+}
+
+// debugArtifactsPath returns the path of the storage the artifacts of the Pod are uploaded to
+func debugArtifactsPath(provider v1alpha1.StorageProvider, ns, podName string) string {
+	switch backuputil.GetStorageType(provider) {
+	case v1alpha1.BackupStorageTypeS3:
+		return fmt.Sprintf("s3://%s/", path.Join(provider.S3.Bucket, provider.S3.Prefix, ns, podName))
+	case v1alpha1.BackupStorageTypeGcs:
+		return fmt.Sprintf("gcs://%s/", path.Join(provider.Gcs.Bucket, provider.Gcs.Prefix, ns, podName))
+	case v1alpha1.BackupStorageTypeAzblob:
+		return fmt.Sprintf("azure://%s/", path.Join(provider.Azblob.Container, provider.Azblob.Prefix, ns, podName))
+	case v1alpha1.BackupStorageTypeLocal:
+		return fmt.Sprintf("local://%s/", path.Join(provider.Local.VolumeMount.MountPath, provider.Local.Prefix, ns, podName))
+	default:
+		return ""
+	}
+}
+
+// syncDebugArtifactEvents records an event with the path of the debug artifacts once the TiDB or TiKV container
+// is OOMKilled or crashes. The restart count of the container reported is recorded in the annotation of the Pod,
+// so that each crash is only reported once.
+func (m *TidbClusterStatusManager) syncDebugArtifactEvents(tc *v1alpha1.TidbCluster) error {
+	if getDebugArtifactsSpec(tc, v1alpha1.TiDBMemberType) == nil && getDebugArtifactsSpec(tc, v1alpha1.TiKVMemberType) == nil {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return fmt.Errorf("debug artifacts: failed to create selector for cluster %s/%s, error: %s", ns, tc.GetName(), err)
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("debug artifacts: failed to list pods for cluster %s/%s, error: %s", ns, tc.GetName(), err)
+	}
+	for _, pod := range pods {
+		var memberType v1alpha1.MemberType
+		switch l := label.Label(pod.Labels); {
+		case l.IsTiDB():
+			memberType = v1alpha1.TiDBMemberType
+		case l.IsTiKV(), l.IsTiKVCold():
+			memberType = v1alpha1.TiKVMemberType
+		default:
+			continue
+		}
+		spec := getDebugArtifactsSpec(tc, memberType)
+		if spec == nil {
+			continue
+		}
+		if err := m.reportDebugArtifacts(tc, pod, memberType, spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportDebugArtifacts records an event if the container of the component in the Pod is restarted because of a crash
+// since the last report
+func (m *TidbClusterStatusManager) reportDebugArtifacts(tc *v1alpha1.TidbCluster, pod *corev1.Pod, memberType v1alpha1.MemberType, spec *v1alpha1.DebugArtifactsSpec) error {
+	var status *corev1.ContainerStatus
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == memberType.String() {
+			status = &pod.Status.ContainerStatuses[i]
+			break
+		}
+	}
+	if status == nil {
+		return nil
+	}
+	reported, _ := strconv.ParseInt(pod.Annotations[label.AnnDebugArtifactsRestartCount], 10, 32)
+	if int64(status.RestartCount) <= reported {
+		return nil
+	}
+
+	if terminated := status.LastTerminationState.Terminated; terminated != nil && (terminated.Reason == "OOMKilled" || terminated.ExitCode != 0) {
+		reason := terminated.Reason
+		if reason == "" {
+			reason = "Error"
+		}
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "DebugArtifactsCaptured",
+			"container %s of pod %s exited with %s (exit code %d), the core dumps and heap profiles are uploaded to %s",
+			status.Name, pod.Name, reason, terminated.ExitCode, debugArtifactsPath(spec.StorageProvider, pod.Namespace, pod.Name))
+	}
+
+	newPod := pod.DeepCopy()
+	if newPod.Annotations == nil {
+		newPod.Annotations = map[string]string{}
+	}
+	newPod.Annotations[label.AnnDebugArtifactsRestartCount] = strconv.Itoa(int(status.RestartCount))
+	if _, err := m.deps.PodControl.UpdatePod(tc, newPod); err != nil {
+		return fmt.Errorf("debug artifacts: failed to update pod %s/%s, error: %s", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
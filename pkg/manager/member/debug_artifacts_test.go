@@ -0,0 +1,121 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestAddDebugArtifactsSidecar(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tc := newTidbClusterForPD()
+	tc.Spec.TiDB = &v1alpha1.TiDBSpec{}
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "tidb"}}}
+
+	// disabled
+	g.Expect(addDebugArtifactsSidecar(fakeDeps, tc, v1alpha1.TiDBMemberType, podSpec)).To(Succeed())
+	g.Expect(podSpec.Containers).To(HaveLen(1))
+
+	tc.Spec.TiDB.DebugArtifacts = &v1alpha1.DebugArtifactsSpec{
+		Image:           "pingcap/tidb-backup-manager",
+		StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "artifacts", Prefix: "prod"}},
+	}
+	g.Expect(addDebugArtifactsSidecar(fakeDeps, tc, v1alpha1.TiDBMemberType, podSpec)).To(Succeed())
+	g.Expect(podSpec.Volumes).To(HaveLen(1))
+	g.Expect(podSpec.Volumes[0].EmptyDir).NotTo(BeNil())
+	g.Expect(podSpec.Containers).To(HaveLen(2))
+	tidb := podSpec.Containers[0]
+	g.Expect(tidb.WorkingDir).To(Equal(debugArtifactsDir))
+	g.Expect(tidb.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: debugArtifactsVolName, MountPath: debugArtifactsDir}))
+	g.Expect(tidb.Env).To(ContainElement(corev1.EnvVar{Name: "GOTRACEBACK", Value: "crash"}))
+	sidecar := podSpec.Containers[1]
+	g.Expect(sidecar.Name).To(Equal(debugArtifactsContainerName))
+	g.Expect(sidecar.Image).To(Equal("pingcap/tidb-backup-manager"))
+	script := sidecar.Args[2]
+	g.Expect(script).To(ContainSubstring("http://127.0.0.1:10080/debug/pprof/heap"))
+	g.Expect(script).To(ContainSubstring("move /var/lib/debug-artifacts s3:artifacts/prod/default/${POD_NAME}"))
+	g.Expect(script).To(ContainSubstring("sleep 60"))
+
+	// the heap profiles are not taken if TLS is enabled
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	podSpec = &corev1.PodSpec{Containers: []corev1.Container{{Name: "tidb"}}}
+	g.Expect(addDebugArtifactsSidecar(fakeDeps, tc, v1alpha1.TiDBMemberType, podSpec)).To(Succeed())
+	g.Expect(strings.Contains(podSpec.Containers[1].Args[2], "wget")).To(BeFalse())
+}
+
+func TestSyncDebugArtifactEvents(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tsm := NewTidbClusterStatusManager(fakeDeps)
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV = &v1alpha1.TiKVSpec{
+		DebugArtifacts: &v1alpha1.DebugArtifactsSpec{
+			Image:           "pingcap/tidb-backup-manager",
+			StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "artifacts"}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-tikv-0",
+			Namespace: tc.Namespace,
+			Labels:    label.New().Instance(tc.GetInstanceName()).TiKV().Labels(),
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "tikv",
+				RestartCount: 1,
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+				},
+			}},
+		},
+	}
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+
+	events := fakeDeps.Recorder.(*record.FakeRecorder).Events
+	g.Expect(tsm.syncDebugArtifactEvents(tc)).To(Succeed())
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(<-events).To(ContainSubstring("exited with OOMKilled (exit code 137), the core dumps and heap profiles are uploaded to s3://artifacts/default/test-tikv-0/"))
+	updated, err := fakeDeps.PodLister.Pods(tc.Namespace).Get(pod.Name)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.Annotations[label.AnnDebugArtifactsRestartCount]).To(Equal("1"))
+
+	// the crash is only reported once
+	g.Expect(tsm.syncDebugArtifactEvents(tc)).To(Succeed())
+	g.Expect(events).To(BeEmpty())
+
+	// a graceful restart is not reported
+	updated = updated.DeepCopy()
+	updated.Status.ContainerStatuses[0].RestartCount = 2
+	updated.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{Reason: "Completed"}
+	g.Expect(podIndexer.Update(updated)).To(Succeed())
+	g.Expect(tsm.syncDebugArtifactEvents(tc)).To(Succeed())
+	g.Expect(events).To(BeEmpty())
+	updated, err = fakeDeps.PodLister.Pods(tc.Namespace).Get(pod.Name)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.Annotations[label.AnnDebugArtifactsRestartCount]).To(Equal("2"))
+}
@@ -64,7 +64,15 @@ func (f *masterFailover) Failover(dc *v1alpha1.DMCluster) error {
 				"%s(%s) is unhealthy", podName, masterMember.ID)
 		}
 	}
-	inQuorum := healthCount > len(dc.Status.Master.Members)/2
+	for _, masterMember := range dc.Status.Master.PeerMembers {
+		if masterMember.Health {
+			healthCount++
+		} else {
+			f.deps.Recorder.Eventf(dc, apiv1.EventTypeWarning, "MasterPeerMemberUnhealthy",
+				"%s(%s) is unhealthy", masterMember.Name, masterMember.ID)
+		}
+	}
+	inQuorum := healthCount > (len(dc.Status.Master.Members)+len(dc.Status.Master.PeerMembers))/2
 	if !inQuorum {
 		return fmt.Errorf("DMCluster: %s/%s's dm-master cluster is not healthy: %d/%d, "+
 			"replicas: %d, failureCount: %d, can't failover",
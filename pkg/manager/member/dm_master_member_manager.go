@@ -17,11 +17,13 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/dmapi"
 	"github.com/pingcap/tidb-operator/pkg/manager"
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
 	"github.com/pingcap/tidb-operator/pkg/util"
@@ -63,6 +65,14 @@ func NewMasterMemberManager(deps *controller.Dependencies, masterScaler Scaler,
 }
 
 func (m *masterMemberManager) SyncDM(dc *v1alpha1.DMCluster) error {
+	if dc.HeterogeneousWithoutLocalMaster() {
+		// no dm-master is deployed, sync the status of the dm-masters of the referenced DMCluster instead
+		if err := m.syncReferencedMasterStatus(dc); err != nil {
+			klog.Errorf("failed to sync DMCluster: [%s/%s]'s referenced dm-master status, error: %v", dc.GetNamespace(), dc.GetName(), err)
+		}
+		return nil
+	}
+
 	// Sync dm-master Service
 	if err := m.syncMasterServiceForDMCluster(dc); err != nil {
 		return err
@@ -193,6 +203,9 @@ func (m *masterMemberManager) syncMasterStatefulSetForDMCluster(dc *v1alpha1.DMC
 	if err != nil {
 		return err
 	}
+	if dc.Spec.Suspend {
+		return suspendDMStatefulSet(m.deps, dc, oldMasterSet, newMasterSet)
+	}
 	if setNotExist {
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newMasterSet)
 		if err != nil {
@@ -213,6 +226,11 @@ func (m *masterMemberManager) syncMasterStatefulSetForDMCluster(dc *v1alpha1.DMC
 		return controller.RequeueErrorf("dmcluster: [%s/%s]'s dm-master needs force upgrade, %v", ns, dcName, errSTS)
 	}
 
+	// The members are kept in the dm cluster during the suspension, so the scaler is bypassed to resume them
+	if resumeDMStatefulSet(dc, oldMasterSet, newMasterSet) {
+		return mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, dc, newMasterSet, oldMasterSet)
+	}
+
 	// Scaling takes precedence over normal upgrading because:
 	// - if a dm-master fails in the upgrading, users may want to delete it or add
 	//   new replicas
@@ -281,6 +299,13 @@ func (m *masterMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 	dcName := dc.GetName()
 
 	dc.Status.Master.StatefulSet = &set.Status
+	dc.Status.Master.StartScriptVersion = getStartScriptVersion(set)
+
+	if dc.Spec.Suspend {
+		// the members can't be queried while the dm cluster is suspended, keep their last observed status
+		dc.Status.Master.Phase = v1alpha1.SuspendPhase
+		return nil
+	}
 
 	upgrading, err := m.masterStatefulSetIsUpgrading(set, dc)
 	if err != nil {
@@ -318,6 +343,56 @@ func (m *masterMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 		dc.Status.Master.Synced = false
 		return err
 	}
+	members := getMasterMembersStatus(dc, mastersInfo)
+	masterStatus, peerMasterStatus := splitPeerMasterMembers(dc, members)
+
+	dc.Status.Master.Synced = true
+	dc.Status.Master.Members = masterStatus
+	dc.Status.Master.PeerMembers = peerMasterStatus
+	// the leader may be a dm-master in another Kubernetes cluster
+	dc.Status.Master.Leader = members[leader.Name]
+	dc.Status.Master.Image = ""
+	c := findContainerByName(set, "dm-master")
+	if c != nil {
+		dc.Status.Master.Image = c.Image
+	}
+
+	// k8s check
+	err = m.collectUnjoinedMembers(dc, set, masterStatus)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// syncReferencedMasterStatus syncs the members of the dm-masters of the referenced DMCluster to the status
+// of the DMCluster without dm-master, so that the dm-workers wait for the referenced dm-masters to be available
+func (m *masterMemberManager) syncReferencedMasterStatus(dc *v1alpha1.DMCluster) error {
+	dc.Status.Master.StatefulSet = nil
+	dc.Status.Master.Phase = v1alpha1.NormalPhase
+
+	dmClient := controller.GetMasterClient(m.deps.DMMasterControl, dc)
+	mastersInfo, err := dmClient.GetMasters()
+	if err != nil {
+		dc.Status.Master.Synced = false
+		return err
+	}
+	leader, err := dmClient.GetLeader()
+	if err != nil {
+		dc.Status.Master.Synced = false
+		return err
+	}
+
+	dc.Status.Master.Synced = true
+	dc.Status.Master.Members = getMasterMembersStatus(dc, mastersInfo)
+	dc.Status.Master.Leader = dc.Status.Master.Members[leader.Name]
+	return nil
+}
+
+// getMasterMembersStatus returns the status of the dm-master members keyed by member name
+func getMasterMembersStatus(dc *v1alpha1.DMCluster, mastersInfo []*dmapi.MastersInfo) map[string]v1alpha1.MasterMember {
+	ns := dc.GetNamespace()
+	dcName := dc.GetName()
 	masterStatus := map[string]v1alpha1.MasterMember{}
 	for _, master := range mastersInfo {
 		id := master.MemberID
@@ -340,6 +415,9 @@ func (m *masterMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 		}
 
 		oldMasterMember, exist := dc.Status.Master.Members[name]
+		if !exist {
+			oldMasterMember, exist = dc.Status.Master.PeerMembers[name]
+		}
 
 		status.LastTransitionTime = metav1.Now()
 		if exist && status.Health == oldMasterMember.Health {
@@ -348,22 +426,31 @@ func (m *masterMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 
 		masterStatus[name] = status
 	}
+	return masterStatus
+}
 
-	dc.Status.Master.Synced = true
-	dc.Status.Master.Members = masterStatus
-	dc.Status.Master.Leader = dc.Status.Master.Members[leader.Name]
-	dc.Status.Master.Image = ""
-	c := findContainerByName(set, "dm-master")
-	if c != nil {
-		dc.Status.Master.Image = c.Image
+// splitPeerMasterMembers splits the dm-master members into the members of the DMCluster, whose names are the
+// names of its Pods, and the peer members, which are the dm-masters of the DMClusters in other Kubernetes
+// clusters joined by the externalMembers. All members belong to the DMCluster if it's not deployed across
+// Kubernetes clusters, i.e. neither the clusterDomain nor the externalMembers is configured.
+func splitPeerMasterMembers(dc *v1alpha1.DMCluster, members map[string]v1alpha1.MasterMember) (map[string]v1alpha1.MasterMember, map[string]v1alpha1.MasterMember) {
+	if dc.Spec.ClusterDomain == "" && len(dc.Spec.Master.ExternalMembers) == 0 {
+		return members, nil
 	}
-
-	// k8s check
-	err = m.collectUnjoinedMembers(dc, set, masterStatus)
-	if err != nil {
-		return err
+	prefix := controller.DMMasterMemberName(dc.GetName()) + "-"
+	masterStatus := map[string]v1alpha1.MasterMember{}
+	var peerMasterStatus map[string]v1alpha1.MasterMember
+	for name, member := range members {
+		if _, err := strconv.Atoi(strings.TrimPrefix(name, prefix)); strings.HasPrefix(name, prefix) && err == nil {
+			masterStatus[name] = member
+			continue
+		}
+		if peerMasterStatus == nil {
+			peerMasterStatus = map[string]v1alpha1.MasterMember{}
+		}
+		peerMasterStatus[name] = member
 	}
-	return nil
+	return masterStatus, peerMasterStatus
 }
 
 // syncMasterConfigMap syncs the configmap of dm-master
@@ -386,8 +473,8 @@ func (m *masterMemberManager) getNewMasterServiceForDMCluster(dc *v1alpha1.DMClu
 	ports := []corev1.ServicePort{
 		{
 			Name:       "dm-master",
-			Port:       8261,
-			TargetPort: intstr.FromInt(8261),
+			Port:       dc.MasterPort(),
+			TargetPort: intstr.FromInt(int(dc.MasterPort())),
 			Protocol:   corev1.ProtocolTCP,
 		},
 	}
@@ -455,8 +542,8 @@ func getNewMasterHeadlessServiceForDMCluster(dc *v1alpha1.DMCluster) *corev1.Ser
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "dm-master-peer",
-					Port:       8291,
-					TargetPort: intstr.FromInt(8291),
+					Port:       dc.MasterPeerPort(),
+					TargetPort: intstr.FromInt(int(dc.MasterPeerPort())),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
@@ -521,6 +608,9 @@ func getNewMasterSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 		{Name: "startup-script", ReadOnly: true, MountPath: "/usr/local/bin"},
 		{Name: v1alpha1.DMMasterMemberType.String(), MountPath: dmMasterDataVolumeMountPath},
 	}
+	// handle StorageVolumes and AdditionalVolumeMounts in ComponentSpec
+	storageVolMounts, additionalPVCs := util.BuildStorageVolumeAndVolumeMount(dc.Spec.Master.StorageVolumes, dc.Spec.Master.StorageClassName, v1alpha1.DMMasterMemberType)
+	volMounts = append(volMounts, storageVolMounts...)
 	volMounts = append(volMounts, dc.Spec.Master.AdditionalVolumeMounts...)
 
 	if dc.IsTLSClusterEnabled() {
@@ -593,7 +683,8 @@ func getNewMasterSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 	stsLabels := label.NewDM().Instance(instanceName).DMMaster()
 	podLabels := util.CombineStringMap(stsLabels, baseMasterSpec.Labels())
 	stsAnnotations := getStsAnnotations(dc.Annotations, label.DMMasterLabelVal)
-	podAnnotations := util.CombineStringMap(controller.AnnProm(8261), baseMasterSpec.Annotations())
+	podAnnotations := util.CombineStringMap(controller.AnnProm(dc.MasterPort()), baseMasterSpec.Annotations())
+	setStartScriptVersionAnnotation(podAnnotations, baseMasterSpec.StartScriptVersion())
 	failureReplicas := getDMMasterFailureReplicas(dc)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
@@ -609,12 +700,12 @@ func getNewMasterSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "peer",
-				ContainerPort: int32(8291),
+				ContainerPort: dc.MasterPeerPort(),
 				Protocol:      corev1.ProtocolTCP,
 			},
 			{
 				Name:          "client",
-				ContainerPort: int32(8261),
+				ContainerPort: dc.MasterPort(),
 				Protocol:      corev1.ProtocolTCP,
 			},
 		},
@@ -644,7 +735,7 @@ func getNewMasterSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 		},
 		{
 			Name:  "TZ",
-			Value: dc.Timezone(),
+			Value: baseMasterSpec.Timezone(),
 		},
 	}
 
@@ -663,6 +754,18 @@ func getNewMasterSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 	masterContainer.Env = util.AppendEnv(env, baseMasterSpec.Env())
 	podSpec.Volumes = append(vols, baseMasterSpec.AdditionalVolumes()...)
 	podSpec.Containers = append([]corev1.Container{masterContainer}, baseMasterSpec.AdditionalContainers()...)
+	addTimezoneData(baseMasterSpec, &podSpec, v1alpha1.DMMasterMemberType.String())
+	podSpec.InitContainers = baseMasterSpec.InitContainers()
+
+	updateStrategy := apps.StatefulSetUpdateStrategy{}
+	if baseMasterSpec.StatefulSetUpdateStrategy() == apps.OnDeleteStatefulSetStrategyType {
+		updateStrategy.Type = apps.OnDeleteStatefulSetStrategyType
+	} else {
+		updateStrategy.Type = apps.RollingUpdateStatefulSetStrategyType
+		updateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{
+			Partition: pointer.Int32Ptr(dc.Spec.Master.Replicas + int32(failureReplicas) + deleteSlotsNumber),
+		}
+	}
 
 	masterSet := &apps.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -697,15 +800,12 @@ func getNewMasterSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 				},
 			},
 			ServiceName:         controller.DMMasterPeerMemberName(dcName),
-			PodManagementPolicy: apps.ParallelPodManagement,
-			UpdateStrategy: apps.StatefulSetUpdateStrategy{
-				Type: apps.RollingUpdateStatefulSetStrategyType,
-				RollingUpdate: &apps.RollingUpdateStatefulSetStrategy{
-					Partition: pointer.Int32Ptr(dc.Spec.Master.Replicas + int32(failureReplicas) + deleteSlotsNumber),
-				}},
+			PodManagementPolicy: baseMasterSpec.PodManagementPolicy(),
+			UpdateStrategy:      updateStrategy,
 		},
 	}
 
+	masterSet.Spec.VolumeClaimTemplates = append(masterSet.Spec.VolumeClaimTemplates, additionalPVCs...)
 	return masterSet, nil
 }
 
@@ -728,8 +828,15 @@ func getMasterConfigMap(dc *v1alpha1.DMCluster) (*corev1.ConfigMap, error) {
 	}
 
 	startScript, err := RenderDMMasterStartScript(&DMMasterStartScriptModel{
-		Scheme:  dc.Scheme(),
-		DataDir: filepath.Join(dmMasterDataVolumeMountPath, dc.Spec.Master.DataSubDir),
+		Scheme:   dc.Scheme(),
+		DataDir:  filepath.Join(dmMasterDataVolumeMountPath, dc.Spec.Master.DataSubDir),
+		Port:     dc.MasterPort(),
+		PeerPort: dc.MasterPeerPort(),
+
+		ClusterDomain:   dc.Spec.ClusterDomain,
+		ExternalMembers: strings.Join(dc.Spec.Master.ExternalMembers, ","),
+
+		StartScriptVersion: dc.BaseMasterSpec().StartScriptVersion(),
 	})
 	if err != nil {
 		return nil, err
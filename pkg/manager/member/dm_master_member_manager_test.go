@@ -746,6 +746,40 @@ func TestMasterMemberManagerSyncMasterSts(t *testing.T) {
 	}
 }
 
+func TestMasterMemberManagerSyncReferencedMaster(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	mmm, _, _, masterControl, _, _, _ := newFakeMasterMemberManager()
+	dc := newDMClusterForMaster()
+	dc.Namespace = "dm-workers"
+	dc.Spec.Master.Replicas = 0
+	dc.Spec.Cluster = &v1alpha1.TidbClusterRef{Name: "main", Namespace: "dm"}
+
+	masterClient := controller.NewFakeMasterClient(masterControl, dc)
+	masterClient.AddReaction(dmapi.GetMastersActionType, func(action *dmapi.Action) (interface{}, error) {
+		return []*dmapi.MastersInfo{
+			{Name: "main-dm-master-0", MemberID: "1", Alive: true},
+			{Name: "main-dm-master-1", MemberID: "2", Alive: true},
+			{Name: "main-dm-master-2", MemberID: "3", Alive: false},
+		}, nil
+	})
+	masterClient.AddReaction(dmapi.GetLeaderActionType, func(action *dmapi.Action) (interface{}, error) {
+		return dmapi.MembersLeader{Name: "main-dm-master-1"}, nil
+	})
+
+	g.Expect(mmm.SyncDM(dc)).To(Succeed())
+	g.Expect(dc.Status.Master.Synced).To(BeTrue())
+	g.Expect(dc.Status.Master.Members).To(HaveLen(3))
+	g.Expect(dc.Status.Master.Leader.Name).To(Equal("main-dm-master-1"))
+	g.Expect(dc.MasterIsAvailable()).To(BeTrue())
+
+	// no dm-master is deployed
+	_, err := mmm.deps.StatefulSetLister.StatefulSets(dc.Namespace).Get(controller.DMMasterMemberName(dc.Name))
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+	_, err = mmm.deps.ServiceLister.Services(dc.Namespace).Get(controller.DMMasterMemberName(dc.Name))
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+}
+
 func newFakeMasterMemberManager() (*masterMemberManager, *controller.FakeStatefulSetControl, *controller.FakeServiceControl, *dmapi.FakeMasterControl, cache.Indexer, cache.Indexer, *controller.FakePodControl) {
 	fakeDeps := controller.NewFakeDependencies()
 	fakeDeps.CLIConfig.AutoFailover = true
@@ -1166,6 +1200,66 @@ func TestGetNewMasterSetForDMCluster(t *testing.T) {
 			},
 			testSts: testAdditionalVolumes(t, []corev1.Volume{{Name: "test", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}),
 		},
+		{
+			name: "dm-master storage volumes",
+			dc: v1alpha1.DMCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dc",
+					Namespace: "ns",
+				},
+				Spec: v1alpha1.DMClusterSpec{
+					Master: v1alpha1.MasterSpec{
+						StorageSize: "10Gi",
+						StorageVolumes: []v1alpha1.StorageVolume{
+							{Name: "log", StorageSize: "2Gi", MountPath: "/var/log"},
+						},
+					},
+					Worker: &v1alpha1.WorkerSpec{},
+				},
+			},
+			testSts: func(sts *apps.StatefulSet) {
+				g := NewGomegaWithT(t)
+				g.Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(2))
+				pvc := sts.Spec.VolumeClaimTemplates[1]
+				g.Expect(pvc.Name).To(Equal(fmt.Sprintf("%s-%s", v1alpha1.DMMasterMemberType, "log")))
+				g.Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("2Gi")))
+				g.Expect(sts.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name: pvc.Name, MountPath: "/var/log",
+				}))
+			},
+		},
+		{
+			name: "dm-master respects the cluster-level statefulset and time zone settings",
+			dc: v1alpha1.DMCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dc",
+					Namespace: "ns",
+				},
+				Spec: v1alpha1.DMClusterSpec{
+					Master: v1alpha1.MasterSpec{
+						ComponentSpec: v1alpha1.ComponentSpec{
+							InitContainers: []corev1.Container{{Name: "init"}},
+							Timezone:       pointer.StringPtr("Asia/Shanghai"),
+						},
+					},
+					Worker:                    &v1alpha1.WorkerSpec{},
+					StatefulSetUpdateStrategy: apps.OnDeleteStatefulSetStrategyType,
+					PodManagementPolicy:       apps.OrderedReadyPodManagement,
+					MountTimezoneData:         pointer.BoolPtr(true),
+				},
+			},
+			testSts: func(sts *apps.StatefulSet) {
+				g := NewGomegaWithT(t)
+				g.Expect(sts.Spec.UpdateStrategy).To(Equal(apps.StatefulSetUpdateStrategy{Type: apps.OnDeleteStatefulSetStrategyType}))
+				g.Expect(sts.Spec.PodManagementPolicy).To(Equal(apps.OrderedReadyPodManagement))
+				podSpec := sts.Spec.Template.Spec
+				g.Expect(podSpec.InitContainers).To(Equal([]corev1.Container{{Name: "init"}}))
+				g.Expect(podSpec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "TZ", Value: "Asia/Shanghai"}))
+				g.Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name: timezoneDataVolumeName, MountPath: timezoneDataPath, ReadOnly: true,
+				}))
+			},
+		},
 		// TODO add more tests
 	}
 
@@ -2053,6 +2147,38 @@ func TestMasterShouldRecover(t *testing.T) {
 	}
 }
 
+func TestSplitPeerMasterMembers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	members := map[string]v1alpha1.MasterMember{
+		"test-dm-master-0":   {Name: "test-dm-master-0", Health: true},
+		"test-dm-master-1":   {Name: "test-dm-master-1", Health: true},
+		"other-dm-master-0":  {Name: "other-dm-master-0", Health: true},
+		"test-dm-master-foo": {Name: "test-dm-master-foo", Health: false},
+	}
+
+	// all members belong to the DMCluster if it's not deployed across Kubernetes clusters
+	dc := newDMClusterForMaster()
+	local, peers := splitPeerMasterMembers(dc, members)
+	g.Expect(local).To(Equal(members))
+	g.Expect(peers).To(BeNil())
+
+	dc.Spec.ClusterDomain = "cluster1.com"
+	local, peers = splitPeerMasterMembers(dc, members)
+	g.Expect(local).To(HaveLen(2))
+	g.Expect(local).To(HaveKey("test-dm-master-0"))
+	g.Expect(local).To(HaveKey("test-dm-master-1"))
+	g.Expect(peers).To(HaveLen(2))
+	g.Expect(peers).To(HaveKey("other-dm-master-0"))
+	g.Expect(peers).To(HaveKey("test-dm-master-foo"))
+
+	// the peer members are nil if there are no dm-masters in other Kubernetes clusters
+	delete(members, "other-dm-master-0")
+	delete(members, "test-dm-master-foo")
+	_, peers = splitPeerMasterMembers(dc, members)
+	g.Expect(peers).To(BeNil())
+}
+
 func intPtr(i int) *int {
 	return &i
 }
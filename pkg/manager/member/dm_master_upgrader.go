@@ -62,6 +62,7 @@ func (u *masterUpgrader) gracefulUpgrade(dc *v1alpha1.DMCluster, oldSet *apps.St
 	}
 
 	if dc.Status.Master.StatefulSet.UpdateRevision == dc.Status.Master.StatefulSet.CurrentRevision {
+		removeUpgradeBlocked(&dc.Status.Master.Conditions)
 		return nil
 	}
 
@@ -76,6 +77,10 @@ func (u *masterUpgrader) gracefulUpgrade(dc *v1alpha1.DMCluster, oldSet *apps.St
 	}
 
 	mngerutils.SetUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
+	// the partition is kept while the upgrade is blocked
+	if blocked, err := checkDMTaskStagesForUpgrade(u.deps, dc, v1alpha1.DMMasterMemberType, &dc.Status.Master.Conditions); err != nil || blocked {
+		return err
+	}
 	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
 	for _i := len(podOrdinals) - 1; _i >= 0; _i-- {
 		i := podOrdinals[_i]
@@ -94,6 +99,9 @@ func (u *masterUpgrader) gracefulUpgrade(dc *v1alpha1.DMCluster, oldSet *apps.St
 			if member, exist := dc.Status.Master.Members[podName]; !exist || !member.Health {
 				return controller.RequeueErrorf("dmcluster: [%s/%s]'s dm-master upgraded pod: [%s] is not ready", ns, dcName, podName)
 			}
+			if msg := checkUpgradedPodPacing(dc.BaseMasterSpec(), pod); msg != "" {
+				return controller.RequeueErrorf("dmcluster: [%s/%s]'s dm-master upgraded pod: [%s] %s", ns, dcName, podName, msg)
+			}
 			continue
 		}
 
@@ -26,6 +26,7 @@ import (
 	. "github.com/onsi/gomega"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	podinformers "k8s.io/client-go/informers/core/v1"
@@ -41,6 +42,7 @@ func TestMasterUpgraderUpgrade(t *testing.T) {
 		changePods        func(pods []*corev1.Pod)
 		changeOldSet      func(set *apps.StatefulSet)
 		transferLeaderErr bool
+		subTaskStatuses   []*dmapi.SubTaskStatus
 		errExpectFn       func(*GomegaWithT, error)
 		expectFn          func(g *GomegaWithT, dc *v1alpha1.DMCluster, newSet *apps.StatefulSet)
 	}
@@ -66,6 +68,11 @@ func TestMasterUpgraderUpgrade(t *testing.T) {
 			})
 		}
 
+		masterClient := controller.NewFakeMasterClient(masterControl, dc)
+		masterClient.AddReaction(dmapi.GetSubTaskStatusesActionType, func(action *dmapi.Action) (interface{}, error) {
+			return test.subTaskStatuses, nil
+		})
+
 		pods := getMasterPods()
 		if test.changePods != nil {
 			test.changePods(pods)
@@ -245,6 +252,45 @@ func TestMasterUpgraderUpgrade(t *testing.T) {
 				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(3)))
 			},
 		},
+		{
+			name: "blocked by the subtask in the full migration phase",
+			changeFn: func(dc *v1alpha1.DMCluster) {
+				dc.Status.Master.Synced = true
+			},
+			subTaskStatuses: []*dmapi.SubTaskStatus{
+				{Name: "task-1", SourceName: "mysql-01", WorkerName: "dm-worker-0", Stage: "Running", Unit: "dump"},
+				{Name: "task-1", SourceName: "mysql-02", WorkerName: "dm-worker-1", Stage: "Running", Unit: "sync"},
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, dc *v1alpha1.DMCluster, newSet *apps.StatefulSet) {
+				g.Expect(dc.Status.Master.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(2)))
+				cond := meta.FindStatusCondition(dc.Status.Master.Conditions, v1alpha1.ComponentUpgradeBlocked)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Message).To(ContainSubstring("task-1/mysql-01 (dump Running on dm-worker-0)"))
+				g.Expect(cond.Message).NotTo(ContainSubstring("mysql-02"))
+			},
+		},
+		{
+			name: "only warn of the subtask in the full migration phase",
+			changeFn: func(dc *v1alpha1.DMCluster) {
+				dc.Status.Master.Synced = true
+				dc.Spec.UpgradeTaskCheckPolicy = v1alpha1.DMUpgradeTaskCheckWarn
+			},
+			subTaskStatuses: []*dmapi.SubTaskStatus{
+				{Name: "task-1", SourceName: "mysql-01", WorkerName: "dm-worker-0", Stage: "Paused", Unit: "load"},
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, dc *v1alpha1.DMCluster, newSet *apps.StatefulSet) {
+				g.Expect(dc.Status.Master.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+				g.Expect(meta.FindStatusCondition(dc.Status.Master.Conditions, v1alpha1.ComponentUpgradeBlocked)).To(BeNil())
+			},
+		},
 		{
 			name: "error when transfer leader",
 			changeFn: func(dc *v1alpha1.DMCluster) {
@@ -0,0 +1,118 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	relayLogStorageContainerName = "relay-log-storage"
+	defaultRelayLogDirName       = "relay_log"
+	defaultRelayLogInterval      = 5 * time.Minute
+)
+
+// relayLogLocalExcludes are the files of the relay logs which are rewritten by dm-worker in place, so they are
+// never removed from the local volume
+var relayLogLocalExcludes = []string{"relay.meta", "server-uuid.index"}
+
+// addRelayLogStorageSidecar adds a sidecar uploading the relay logs in the data volume of dm-worker to the storage,
+// if the remote storage of the relay logs is enabled
+func addRelayLogStorageSidecar(deps *controller.Dependencies, dc *v1alpha1.DMCluster, podSpec *corev1.PodSpec) error {
+	spec := dc.Spec.Worker.RelayLogStorage
+	if spec == nil {
+		return nil
+	}
+
+	env, _, err := backuputil.GenerateStorageCertEnv(dc.GetNamespace(), false, spec.StorageProvider, deps.SecretLister)
+	if err != nil {
+		return fmt.Errorf("relay log storage: %v", err)
+	}
+	remote, err := rcloneRemote(spec.StorageProvider, dc.GetNamespace())
+	if err != nil {
+		return fmt.Errorf("relay log storage: %v", err)
+	}
+	relayDir := spec.RelayDir
+	if relayDir == "" {
+		relayDir = path.Join(dmWorkerDataVolumeMountPath, dc.Spec.Worker.DataSubDir, defaultRelayLogDirName)
+	}
+
+	sidecar := corev1.Container{
+		Name:  relayLogStorageContainerName,
+		Image: spec.Image,
+		// the rclone config of the remote storages is generated by the entrypoint of tidb-backup-manager from the env
+		Args: []string{"sh", "-c", relayLogStorageScript(spec, relayDir, remote)},
+		Env: append([]corev1.EnvVar{
+			{
+				Name: "POD_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.name",
+					},
+				},
+			},
+		}, env...),
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: v1alpha1.DMWorkerMemberType.String(), MountPath: dmWorkerDataVolumeMountPath},
+		},
+	}
+	if spec.Resources != nil {
+		sidecar.Resources = *spec.Resources
+	}
+	if spec.Local != nil {
+		sidecar.VolumeMounts = append(sidecar.VolumeMounts, spec.Local.VolumeMount)
+		podSpec.Volumes = append(podSpec.Volumes, spec.Local.Volume)
+	}
+	podSpec.Containers = append(podSpec.Containers, sidecar)
+	return nil
+}
+
+// relayLogStorageScript returns the script of the sidecar. It copies the relay logs to the storage, then removes
+// the local files uploaded and not modified for the local retention, and the remote files not modified for the
+// remote retention.
+func relayLogStorageScript(spec *v1alpha1.RelayLogStorageSpec, relayDir, remote string) string {
+	interval := parseDurationOrDefault(spec.Interval, defaultRelayLogInterval)
+	// the invalid retentions are rejected by the validation, they are ignored here to never remove all the files
+	localRetention := parseDurationOrDefault(spec.LocalRetention, 0)
+	remoteRetention := parseDurationOrDefault(spec.RemoteRetention, 0)
+	dest := remote + "/${POD_NAME}"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "while true; do\n")
+	fmt.Fprintf(&b, "  if [ -d %[1]s ]; then\n    rclone --config=/tmp/rclone.conf copy %[1]s %[2]s\n", relayDir, dest)
+	if localRetention > 0 {
+		// the files are identical to the uploaded ones, so they are only removed from the local volume
+		excludes := ""
+		for _, e := range relayLogLocalExcludes {
+			excludes += fmt.Sprintf(" --exclude '%s'", e)
+		}
+		fmt.Fprintf(&b, "    rclone --config=/tmp/rclone.conf move %s %s --min-age %ds%s\n",
+			relayDir, dest, int64(localRetention/time.Second), excludes)
+	}
+	fmt.Fprintf(&b, "  fi\n")
+	if remoteRetention > 0 {
+		fmt.Fprintf(&b, "  rclone --config=/tmp/rclone.conf delete %[1]s --min-age %[2]ds\n  rclone --config=/tmp/rclone.conf rmdirs %[1]s --leave-root\n",
+			dest, int64(remoteRetention/time.Second))
+	}
+	fmt.Fprintf(&b, "  sleep %d\ndone\n", int64(interval/time.Second))
+	return b.String()
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestAddRelayLogStorageSidecar(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	dc := newDMClusterForWorker()
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "dm-worker"}}}
+
+	// disabled
+	g.Expect(addRelayLogStorageSidecar(fakeDeps, dc, podSpec)).To(Succeed())
+	g.Expect(podSpec.Containers).To(HaveLen(1))
+
+	dc.Spec.Worker.RelayLogStorage = &v1alpha1.RelayLogStorageSpec{
+		Image:           "pingcap/tidb-backup-manager",
+		StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "relay", Prefix: "prod"}},
+	}
+	g.Expect(addRelayLogStorageSidecar(fakeDeps, dc, podSpec)).To(Succeed())
+	g.Expect(podSpec.Containers).To(HaveLen(2))
+	sidecar := podSpec.Containers[1]
+	g.Expect(sidecar.Name).To(Equal(relayLogStorageContainerName))
+	g.Expect(sidecar.Image).To(Equal("pingcap/tidb-backup-manager"))
+	g.Expect(sidecar.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "dm-worker", MountPath: dmWorkerDataVolumeMountPath}))
+	script := sidecar.Args[2]
+	g.Expect(script).To(ContainSubstring("copy /var/lib/dm-worker/relay_log s3:relay/prod/default/${POD_NAME}"))
+	g.Expect(script).To(ContainSubstring("sleep 300"))
+	g.Expect(script).NotTo(ContainSubstring("move"))
+	g.Expect(script).NotTo(ContainSubstring("delete"))
+
+	// retentions
+	dc.Spec.Worker.RelayLogStorage.RelayDir = "/var/lib/dm-worker/relay"
+	dc.Spec.Worker.RelayLogStorage.Interval = pointer.StringPtr("1m")
+	dc.Spec.Worker.RelayLogStorage.LocalRetention = pointer.StringPtr("24h")
+	dc.Spec.Worker.RelayLogStorage.RemoteRetention = pointer.StringPtr("168h")
+	podSpec = &corev1.PodSpec{Containers: []corev1.Container{{Name: "dm-worker"}}}
+	g.Expect(addRelayLogStorageSidecar(fakeDeps, dc, podSpec)).To(Succeed())
+	script = podSpec.Containers[1].Args[2]
+	g.Expect(script).To(ContainSubstring("move /var/lib/dm-worker/relay s3:relay/prod/default/${POD_NAME} --min-age 86400s --exclude 'relay.meta' --exclude 'server-uuid.index'"))
+	g.Expect(script).To(ContainSubstring("delete s3:relay/prod/default/${POD_NAME} --min-age 604800s"))
+	g.Expect(script).To(ContainSubstring("sleep 60"))
+
+	// no storage
+	dc.Spec.Worker.RelayLogStorage.StorageProvider = v1alpha1.StorageProvider{}
+	g.Expect(addRelayLogStorageSidecar(fakeDeps, dc, podSpec)).NotTo(Succeed())
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// syncDMSourcePlacements transfers the sources to the dm-workers running on the nodes matching their
+// placements. A source is transferred only when the dm-worker bound to it doesn't match the placement
+// and a free dm-worker matches, so the sources are re-bound after the dm-workers are rescheduled.
+func syncDMSourcePlacements(deps *controller.Dependencies, dc *v1alpha1.DMCluster) error {
+	placements := dc.Spec.Worker.SourcePlacements
+	if len(placements) == 0 || dc.Spec.Suspend || !dc.Status.Worker.Synced {
+		return nil
+	}
+	ns := dc.GetNamespace()
+	dcName := dc.GetName()
+	if deps.NodeLister == nil {
+		klog.V(4).Infof("Node lister is unavailable, skip syncing source placements of DM cluster %s/%s. This may be caused by no relevant permissions", ns, dcName)
+		return nil
+	}
+
+	masterClient := controller.GetMasterClient(deps.DMMasterControl, dc)
+	workersInfo, err := masterClient.GetWorkers()
+	if err != nil {
+		return fmt.Errorf("failed to get dm-workers of dc %s/%s, error: %v", ns, dcName, err)
+	}
+
+	nodeLabels := map[string]map[string]string{}
+	for _, worker := range workersInfo {
+		labels, err := getDMWorkerNodeLabels(deps, ns, worker.Name)
+		if err != nil {
+			return err
+		}
+		nodeLabels[worker.Name] = labels
+	}
+
+	for _, placement := range placements {
+		var boundWorker, freeWorker string
+		for _, worker := range workersInfo {
+			switch {
+			case worker.Stage == v1alpha1.DMWorkerStateBound && worker.Source == placement.Source:
+				boundWorker = worker.Name
+			case freeWorker == "" && worker.Stage == v1alpha1.DMWorkerStateFree && isWorkerPodDesired(dc, worker.Name) &&
+				dmSourcePlacementMatches(placement, nodeLabels[worker.Name]):
+				freeWorker = worker.Name
+			}
+		}
+		if boundWorker == "" || dmSourcePlacementMatches(placement, nodeLabels[boundWorker]) {
+			continue
+		}
+		if freeWorker == "" {
+			klog.V(4).Infof("dc %s/%s: no free dm-worker matches the placement of source %s bound to %s", ns, dcName, placement.Source, boundWorker)
+			continue
+		}
+
+		if err := masterClient.TransferSource(placement.Source, freeWorker); err != nil {
+			return fmt.Errorf("failed to transfer source %s from %s to %s in dc %s/%s, error: %v", placement.Source, boundWorker, freeWorker, ns, dcName, err)
+		}
+		klog.Infof("dc %s/%s: transfer source %s from %s to %s to match its placement", ns, dcName, placement.Source, boundWorker, freeWorker)
+		// the free dm-worker is bound now, don't pick it for other sources
+		for _, worker := range workersInfo {
+			if worker.Name == freeWorker {
+				worker.Stage = v1alpha1.DMWorkerStateBound
+				worker.Source = placement.Source
+			}
+		}
+	}
+	return nil
+}
+
+// getDMWorkerNodeLabels returns the labels of the node where the dm-worker pod runs,
+// nil is returned if the pod doesn't exist or is not scheduled yet
+func getDMWorkerNodeLabels(deps *controller.Dependencies, ns, podName string) (map[string]string, error) {
+	pod, err := deps.PodLister.Pods(ns).Get(podName)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s, error: %v", ns, podName, err)
+	}
+	if pod.Spec.NodeName == "" {
+		return nil, nil
+	}
+	node, err := deps.NodeLister.Get(pod.Spec.NodeName)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node %s of pod %s/%s, error: %v", pod.Spec.NodeName, ns, podName, err)
+	}
+	return node.Labels, nil
+}
+
+// dmSourcePlacementMatches returns whether the node with the labels matches the placement
+func dmSourcePlacementMatches(placement v1alpha1.DMSourcePlacement, nodeLabels map[string]string) bool {
+	if nodeLabels == nil {
+		return false
+	}
+	for k, v := range placement.NodeSelector {
+		if nodeLabels[k] != v {
+			return false
+		}
+	}
+	return placement.Zone == "" || nodeLabels[corev1.LabelZoneFailureDomainStable] == placement.Zone
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/dmapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncDMSourcePlacements(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name        string
+		placements  []v1alpha1.DMSourcePlacement
+		workers     []*dmapi.WorkersInfo
+		transferErr bool
+		errExpectFn func(*GomegaWithT, error)
+		expectFn    func(*GomegaWithT, map[string]string)
+	}
+
+	// test-dm-worker-0 runs in zone a, test-dm-worker-1 in zone b and test-dm-worker-2 in zone c
+	zones := []string{"a", "b", "c"}
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		dc := newDMClusterForWorker()
+		dc.Status.Worker.Synced = true
+		dc.Spec.Worker.SourcePlacements = test.placements
+		podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+		nodeIndexer := deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+		for i, zone := range zones {
+			nodeName := "node-" + zone
+			nodeIndexer.Add(&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   nodeName,
+					Labels: map[string]string{corev1.LabelZoneFailureDomainStable: zone, "disk": "ssd"},
+				},
+			})
+			podIndexer.Add(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ordinalPodName(v1alpha1.DMWorkerMemberType, dc.GetName(), int32(i)),
+					Namespace: dc.GetNamespace(),
+				},
+				Spec: corev1.PodSpec{NodeName: nodeName},
+			})
+		}
+
+		transferred := map[string]string{}
+		masterClient := controller.NewFakeMasterClient(deps.DMMasterControl.(*dmapi.FakeMasterControl), dc)
+		masterClient.AddReaction(dmapi.GetWorkersActionType, func(action *dmapi.Action) (interface{}, error) {
+			return test.workers, nil
+		})
+		masterClient.AddReaction(dmapi.TransferSourceActionType, func(action *dmapi.Action) (interface{}, error) {
+			if test.transferErr {
+				return nil, fmt.Errorf("failed to transfer source")
+			}
+			transferred[action.Labels["source"]] = action.Name
+			return nil, nil
+		})
+
+		err := syncDMSourcePlacements(deps, dc)
+		test.errExpectFn(g, err)
+		test.expectFn(g, transferred)
+	}
+
+	tests := []testcase{
+		{
+			name:       "the bound dm-worker matches the placement",
+			placements: []v1alpha1.DMSourcePlacement{{Source: "mysql-0", Zone: "a"}},
+			workers: []*dmapi.WorkersInfo{
+				{Name: "test-dm-worker-0", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql-0"},
+				{Name: "test-dm-worker-1", Stage: v1alpha1.DMWorkerStateFree},
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, transferred map[string]string) {
+				g.Expect(transferred).To(BeEmpty())
+			},
+		},
+		{
+			name: "transfer the sources to the free dm-workers matching the placements",
+			placements: []v1alpha1.DMSourcePlacement{
+				{Source: "mysql-0", Zone: "b"},
+				{Source: "mysql-1", NodeSelector: map[string]string{"disk": "ssd"}, Zone: "c"},
+			},
+			workers: []*dmapi.WorkersInfo{
+				{Name: "test-dm-worker-0", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql-0"},
+				{Name: "test-dm-worker-1", Stage: v1alpha1.DMWorkerStateFree},
+				{Name: "test-dm-worker-2", Stage: v1alpha1.DMWorkerStateFree},
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, transferred map[string]string) {
+				// mysql-1 is not bound, dm-master binds it to a free dm-worker itself
+				g.Expect(transferred).To(Equal(map[string]string{"mysql-0": "test-dm-worker-1"}))
+			},
+		},
+		{
+			name: "a free dm-worker takes over only one source",
+			placements: []v1alpha1.DMSourcePlacement{
+				{Source: "mysql-0", Zone: "c"},
+				{Source: "mysql-1", Zone: "c"},
+			},
+			workers: []*dmapi.WorkersInfo{
+				{Name: "test-dm-worker-0", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql-0"},
+				{Name: "test-dm-worker-1", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql-1"},
+				{Name: "test-dm-worker-2", Stage: v1alpha1.DMWorkerStateFree},
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, transferred map[string]string) {
+				g.Expect(transferred).To(Equal(map[string]string{"mysql-0": "test-dm-worker-2"}))
+			},
+		},
+		{
+			name:       "no free dm-worker matches the placement",
+			placements: []v1alpha1.DMSourcePlacement{{Source: "mysql-0", NodeSelector: map[string]string{"disk": "nvme"}}},
+			workers: []*dmapi.WorkersInfo{
+				{Name: "test-dm-worker-0", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql-0"},
+				{Name: "test-dm-worker-1", Stage: v1alpha1.DMWorkerStateFree},
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, transferred map[string]string) {
+				g.Expect(transferred).To(BeEmpty())
+			},
+		},
+		{
+			name:       "failed to transfer the source",
+			placements: []v1alpha1.DMSourcePlacement{{Source: "mysql-0", Zone: "b"}},
+			workers: []*dmapi.WorkersInfo{
+				{Name: "test-dm-worker-0", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql-0"},
+				{Name: "test-dm-worker-1", Stage: v1alpha1.DMWorkerStateFree},
+			},
+			transferErr: true,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("failed to transfer source mysql-0 from test-dm-worker-0 to test-dm-worker-1"))
+			},
+			expectFn: func(g *GomegaWithT, transferred map[string]string) {
+				g.Expect(transferred).To(BeEmpty())
+			},
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
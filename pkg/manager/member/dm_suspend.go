@@ -0,0 +1,67 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+// suspendDMStatefulSet scales the StatefulSet of the dm component down to zero, the replicas before the
+// suspension are recorded in the annotations of the StatefulSet. The scaler is bypassed as the members
+// must not be removed from the dm cluster, and the PVCs are kept by the StatefulSet controller.
+func suspendDMStatefulSet(deps *controller.Dependencies, dc *v1alpha1.DMCluster, oldSet, newSet *apps.StatefulSet) error {
+	if oldSet == nil {
+		return nil
+	}
+	if newSet.Annotations == nil {
+		newSet.Annotations = map[string]string{}
+	}
+	if replicas, ok := oldSet.Annotations[label.AnnStsSuspendedReplicas]; ok {
+		newSet.Annotations[label.AnnStsSuspendedReplicas] = replicas
+	} else {
+		newSet.Annotations[label.AnnStsSuspendedReplicas] = strconv.Itoa(int(*oldSet.Spec.Replicas))
+		klog.Infof("dm cluster %s/%s is suspended, scale statefulset %s down to zero from %d replicas",
+			dc.GetNamespace(), dc.GetName(), oldSet.GetName(), *oldSet.Spec.Replicas)
+	}
+	newSet.Spec.Replicas = pointer.Int32Ptr(0)
+	return mngerutils.UpdateStatefulSet(deps.StatefulSetControl, dc, newSet, oldSet)
+}
+
+// resumeDMStatefulSet restores the replicas of the StatefulSet recorded when the dm cluster is suspended,
+// and returns whether the StatefulSet is being resumed. The changes of the replicas in the spec during
+// the suspension are applied by the scaler once the StatefulSet is resumed.
+func resumeDMStatefulSet(dc *v1alpha1.DMCluster, oldSet, newSet *apps.StatefulSet) bool {
+	value, ok := oldSet.Annotations[label.AnnStsSuspendedReplicas]
+	if !ok {
+		return false
+	}
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		klog.Warningf("dm cluster %s/%s: invalid annotation %s=%s of statefulset %s, scale it as usual",
+			dc.GetNamespace(), dc.GetName(), label.AnnStsSuspendedReplicas, value, oldSet.GetName())
+		return false
+	}
+	klog.Infof("dm cluster %s/%s is resumed, scale statefulset %s up to %d replicas", dc.GetNamespace(), dc.GetName(), oldSet.GetName(), replicas)
+	newSet.Spec.Replicas = pointer.Int32Ptr(int32(replicas))
+	delete(newSet.Annotations, label.AnnStsSuspendedReplicas)
+	return true
+}
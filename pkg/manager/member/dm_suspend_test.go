@@ -0,0 +1,99 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/dmapi"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestMasterMemberManagerSuspend(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dc := newDMClusterForMaster()
+	mmm, fakeSetControl, _, fakeMasterControl, _, _, _ := newFakeMasterMemberManager()
+	masterClient := controller.NewFakeMasterClient(fakeMasterControl, dc)
+	masterClient.AddReaction(dmapi.GetMastersActionType, func(action *dmapi.Action) (interface{}, error) {
+		return []*dmapi.MastersInfo{{Name: "master1", MemberID: "1", ClientURLs: []string{"http://master1:8261"}, Alive: true}}, nil
+	})
+	masterClient.AddReaction(dmapi.GetLeaderActionType, func(action *dmapi.Action) (interface{}, error) {
+		return dmapi.MembersLeader{Name: "master1", Addr: "http://master1:8261"}, nil
+	})
+	fakeSetControl.SetStatusChange(func(set *apps.StatefulSet) {
+		set.Status.Replicas = *set.Spec.Replicas
+		set.Status.CurrentRevision = "dm-master-1"
+		set.Status.UpdateRevision = "dm-master-1"
+	})
+	getSet := func() *apps.StatefulSet {
+		set, err := mmm.deps.StatefulSetLister.StatefulSets(dc.Namespace).Get(controller.DMMasterMemberName(dc.Name))
+		g.Expect(err).NotTo(HaveOccurred())
+		return set
+	}
+
+	err := mmm.SyncDM(dc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+	g.Expect(*getSet().Spec.Replicas).To(Equal(int32(3)))
+	g.Expect(mmm.SyncDM(dc)).To(Succeed())
+	g.Expect(dc.Status.Master.Members).To(HaveKey("master1"))
+
+	// the StatefulSet is scaled down to zero and the status of the members is kept
+	dc.Spec.Suspend = true
+	g.Expect(mmm.SyncDM(dc)).To(Succeed())
+	g.Expect(*getSet().Spec.Replicas).To(Equal(int32(0)))
+	g.Expect(getSet().Annotations[label.AnnStsSuspendedReplicas]).To(Equal("3"))
+	g.Expect(mmm.SyncDM(dc)).To(Succeed())
+	g.Expect(getSet().Annotations[label.AnnStsSuspendedReplicas]).To(Equal("3"))
+	g.Expect(dc.Status.Master.Phase).To(Equal(v1alpha1.SuspendPhase))
+	g.Expect(dc.Status.Master.Members).To(HaveKey("master1"))
+
+	// the replicas before the suspension are restored, regardless of the changes of the spec
+	dc.Spec.Suspend = false
+	dc.Spec.Master.Replicas = 5
+	g.Expect(mmm.SyncDM(dc)).To(Succeed())
+	g.Expect(*getSet().Spec.Replicas).To(Equal(int32(3)))
+	g.Expect(getSet().Annotations).NotTo(HaveKey(label.AnnStsSuspendedReplicas))
+}
+
+func TestResumeDMStatefulSet(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dc := newDMClusterForMaster()
+	newSet := func(annotations map[string]string) *apps.StatefulSet {
+		return &apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-dm-worker", Annotations: annotations},
+			Spec:       apps.StatefulSetSpec{Replicas: pointer.Int32Ptr(0)},
+		}
+	}
+
+	set := newSet(nil)
+	g.Expect(resumeDMStatefulSet(dc, newSet(nil), set)).To(BeFalse())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(0)))
+
+	set = newSet(map[string]string{label.AnnStsSuspendedReplicas: "2"})
+	g.Expect(resumeDMStatefulSet(dc, newSet(map[string]string{label.AnnStsSuspendedReplicas: "2"}), set)).To(BeTrue())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(2)))
+	g.Expect(set.Annotations).NotTo(HaveKey(label.AnnStsSuspendedReplicas))
+
+	set = newSet(nil)
+	g.Expect(resumeDMStatefulSet(dc, newSet(map[string]string{label.AnnStsSuspendedReplicas: "two"}), set)).To(BeFalse())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(0)))
+}
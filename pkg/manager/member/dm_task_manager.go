@@ -0,0 +1,500 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/dmapi"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/util/slice"
+)
+
+const (
+	// the task paused by an error is resumed after dmTaskResumeBackoff * 2^(resumeCount-1), at most
+	// dmTaskMaxResumeBackoff, and the resume count is reset once the task keeps running for dmTaskMaxResumeBackoff
+	dmTaskResumeBackoff    = 30 * time.Second
+	dmTaskMaxResumeBackoff = 10 * time.Minute
+)
+
+// DMTaskManager implements the logic for syncing DMTask.
+type DMTaskManager interface {
+	// Sync implements the logic for syncing DMTask.
+	Sync(*v1alpha1.DMTask) error
+}
+
+type dmTaskManager struct {
+	deps *controller.Dependencies
+}
+
+// NewDMTaskManager returns a DMTaskManager
+func NewDMTaskManager(deps *controller.Dependencies) DMTaskManager {
+	return &dmTaskManager{deps: deps}
+}
+
+// Sync creates the sources and the task in the DMCluster, applies the changes of the config, and starts, stops
+// or deletes the task according to the desired stage. The task is deleted from the DMCluster when the DMTask
+// is deleted.
+func (m *dmTaskManager) Sync(dt *v1alpha1.DMTask) error {
+	if dt.DeletionTimestamp != nil {
+		return m.deleteTask(dt)
+	}
+	if !slice.ContainsString(dt.Finalizers, label.DMTaskProtectionFinalizer, nil) {
+		dt = dt.DeepCopy()
+		dt.Finalizers = append(dt.Finalizers, label.DMTaskProtectionFinalizer)
+		updated, err := m.deps.Clientset.PingcapV1alpha1().DMTasks(dt.GetNamespace()).Update(context.TODO(), dt, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("add DMTask %s/%s protection finalizer failed, err: %v", dt.GetNamespace(), dt.GetName(), err)
+		}
+		dt = updated
+	}
+
+	status := dt.Status.DeepCopy()
+	var syncErr error
+	if errs := validation.ValidateDMTask(dt); len(errs) > 0 {
+		// the DMTask is synced again once the spec is fixed
+		status.Message = errs.ToAggregate().Error()
+	} else if syncErr = m.syncTask(dt, status); syncErr != nil {
+		status.Message = syncErr.Error()
+	} else {
+		status.Message = ""
+		status.ObservedGeneration = dt.Generation
+	}
+	if apiequality.Semantic.DeepEqual(&dt.Status, status) {
+		return syncErr
+	}
+	dt = dt.DeepCopy()
+	dt.Status = *status
+	if _, err := m.updateDMTaskStatus(dt); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+func (m *dmTaskManager) syncTask(dt *v1alpha1.DMTask, status *v1alpha1.DMTaskStatus) error {
+	ns := dt.GetNamespace()
+	taskName := dt.TaskName()
+	dc, err := m.deps.DMClusterLister.DMClusters(ns).Get(dt.Spec.Cluster)
+	if err != nil {
+		return fmt.Errorf("failed to get dmcluster %s, error: %v", dt.Spec.Cluster, err)
+	}
+	masterClient := controller.GetMasterClient(m.deps.DMMasterControl, dc)
+	if err := m.syncSources(dt, status, masterClient); err != nil {
+		return err
+	}
+	exists, err := taskExists(masterClient, taskName)
+	if err != nil {
+		return fmt.Errorf("failed to get the tasks, error: %v", err)
+	}
+
+	if dt.DesiredStage() == v1alpha1.DMTaskStageStopped {
+		if exists {
+			if err := masterClient.DeleteTask(taskName); err != nil {
+				return fmt.Errorf("failed to stop task %s, error: %v", taskName, err)
+			}
+			klog.Infof("DMTask %s/%s: task %s is stopped and deleted", ns, dt.GetName(), taskName)
+			m.deps.Recorder.Eventf(dt, corev1.EventTypeNormal, "StopTask", "task %s is stopped and deleted", taskName)
+		}
+		status.Stage = v1alpha1.DMTaskStageStopped
+		status.ConfigHash = ""
+		status.SubTasks = nil
+		return m.deleteRemovedSources(dt, status, masterClient)
+	}
+
+	config, hash, err := getDMTaskConfig(dt)
+	if err != nil {
+		return err
+	}
+	// the task stopped by the operator to be created or updated is started at once, without the backoff
+	applied := false
+	if !exists {
+		if err := masterClient.CreateTask(config); err != nil {
+			return fmt.Errorf("failed to create task %s, error: %v", taskName, err)
+		}
+		status.ConfigHash = hash
+		applied = true
+		klog.Infof("DMTask %s/%s: task %s is created", ns, dt.GetName(), taskName)
+		m.deps.Recorder.Eventf(dt, corev1.EventTypeNormal, "CreateTask", "task %s is created", taskName)
+	} else if status.ConfigHash != hash {
+		// the config of a running task can't be updated
+		subTasks, err := masterClient.GetTaskStatuses(taskName)
+		if err != nil {
+			return fmt.Errorf("failed to get the status of task %s, error: %v", taskName, err)
+		}
+		if hasRunningSubTask(subTasks) {
+			if err := masterClient.StopTask(taskName); err != nil {
+				return fmt.Errorf("failed to stop task %s to update the config, error: %v", taskName, err)
+			}
+		}
+		if err := masterClient.UpdateTask(taskName, config); err != nil {
+			return fmt.Errorf("failed to update task %s, error: %v", taskName, err)
+		}
+		status.ConfigHash = hash
+		applied = true
+		klog.Infof("DMTask %s/%s: the config of task %s is updated", ns, dt.GetName(), taskName)
+		m.deps.Recorder.Eventf(dt, corev1.EventTypeNormal, "UpdateTask", "the config of task %s is updated", taskName)
+	}
+	if err := m.deleteRemovedSources(dt, status, masterClient); err != nil {
+		return err
+	}
+
+	subTasks, err := masterClient.GetTaskStatuses(taskName)
+	if err != nil {
+		return fmt.Errorf("failed to get the status of task %s, error: %v", taskName, err)
+	}
+	stage := getDMTaskStage(subTasks)
+	switch {
+	case dt.DesiredStage() == v1alpha1.DMTaskStageRunning && stage == v1alpha1.DMTaskStagePaused:
+		if !applied {
+			// the task is paused by an error, resume it with a backoff so that a task failing at once
+			// is not resumed on every sync
+			if wait := dmTaskResumeWait(status, time.Now()); wait > 0 {
+				status.Stage = stage
+				status.SubTasks = convertDMSubTaskStatuses(subTasks)
+				return controller.RequeueErrorf("task %s is paused, resume it after %v", taskName, wait.Round(time.Second))
+			}
+			status.ResumeCount++
+		}
+		if err := masterClient.StartTask(taskName); err != nil {
+			return fmt.Errorf("failed to start task %s, error: %v", taskName, err)
+		}
+		status.LastResumeTime = &metav1.Time{Time: time.Now()}
+		klog.Infof("DMTask %s/%s: task %s is started", ns, dt.GetName(), taskName)
+		m.deps.Recorder.Eventf(dt, corev1.EventTypeNormal, "StartTask", "task %s is started", taskName)
+	case dt.DesiredStage() == v1alpha1.DMTaskStagePaused && hasRunningSubTask(subTasks):
+		if err := masterClient.StopTask(taskName); err != nil {
+			return fmt.Errorf("failed to pause task %s, error: %v", taskName, err)
+		}
+		klog.Infof("DMTask %s/%s: task %s is paused", ns, dt.GetName(), taskName)
+		m.deps.Recorder.Eventf(dt, corev1.EventTypeNormal, "PauseTask", "task %s is paused", taskName)
+	default:
+		if stage != v1alpha1.DMTaskStagePaused && status.ResumeCount > 0 && status.LastResumeTime != nil &&
+			time.Since(status.LastResumeTime.Time) >= dmTaskMaxResumeBackoff {
+			status.ResumeCount = 0
+		}
+		status.Stage = stage
+		status.SubTasks = convertDMSubTaskStatuses(subTasks)
+		return nil
+	}
+
+	subTasks, err = masterClient.GetTaskStatuses(taskName)
+	if err != nil {
+		return fmt.Errorf("failed to get the status of task %s, error: %v", taskName, err)
+	}
+	status.Stage = getDMTaskStage(subTasks)
+	status.SubTasks = convertDMSubTaskStatuses(subTasks)
+	return nil
+}
+
+// syncSources creates the sources of the task that don't exist in the DMCluster, and updates the sources whose
+// configs, including the passwords in the Secrets, changed since they were applied
+func (m *dmTaskManager) syncSources(dt *v1alpha1.DMTask, status *v1alpha1.DMTaskStatus, masterClient dmapi.MasterClient) error {
+	if len(dt.Spec.Sources) == 0 {
+		return nil
+	}
+	ns := dt.GetNamespace()
+	sources, err := masterClient.GetSources()
+	if err != nil {
+		return fmt.Errorf("failed to get the sources, error: %v", err)
+	}
+	existing := map[string]bool{}
+	for _, source := range sources {
+		existing[source.Name] = true
+	}
+	if status.SourceHashes == nil {
+		status.SourceHashes = map[string]string{}
+	}
+	for i := range dt.Spec.Sources {
+		source := &dt.Spec.Sources[i]
+		config, hash, err := m.getDMSourceConfig(ns, source)
+		if err != nil {
+			return err
+		}
+		if !existing[source.Name] {
+			if err := masterClient.CreateSource(config); err != nil {
+				return fmt.Errorf("failed to create source %s, error: %v", source.Name, err)
+			}
+			klog.Infof("DMTask %s/%s: source %s is created", ns, dt.GetName(), source.Name)
+			m.deps.Recorder.Eventf(dt, corev1.EventTypeNormal, "CreateSource", "source %s is created", source.Name)
+		} else if status.SourceHashes[source.Name] != hash {
+			if err := masterClient.UpdateSource(config); err != nil {
+				return fmt.Errorf("failed to update source %s, error: %v", source.Name, err)
+			}
+			klog.Infof("DMTask %s/%s: source %s is updated", ns, dt.GetName(), source.Name)
+			m.deps.Recorder.Eventf(dt, corev1.EventTypeNormal, "UpdateSource", "source %s is updated", source.Name)
+		}
+		status.SourceHashes[source.Name] = hash
+	}
+	return nil
+}
+
+// deleteRemovedSources deletes the sources applied by the DMTask that are removed from the spec, it's called
+// after the task is updated or deleted, as the sources used by a task can't be deleted
+func (m *dmTaskManager) deleteRemovedSources(dt *v1alpha1.DMTask, status *v1alpha1.DMTaskStatus, masterClient dmapi.MasterClient) error {
+	desired := map[string]bool{}
+	for _, source := range dt.Spec.Sources {
+		desired[source.Name] = true
+	}
+	var removed []string
+	for name := range status.SourceHashes {
+		if !desired[name] {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	sources, err := masterClient.GetSources()
+	if err != nil {
+		return fmt.Errorf("failed to get the sources, error: %v", err)
+	}
+	existing := map[string]bool{}
+	for _, source := range sources {
+		existing[source.Name] = true
+	}
+	ns := dt.GetNamespace()
+	for _, name := range removed {
+		if existing[name] {
+			if err := masterClient.DeleteSource(name); err != nil {
+				return fmt.Errorf("failed to delete source %s, error: %v", name, err)
+			}
+			klog.Infof("DMTask %s/%s: source %s is deleted", ns, dt.GetName(), name)
+			m.deps.Recorder.Eventf(dt, corev1.EventTypeNormal, "DeleteSource", "source %s is deleted", name)
+		}
+		delete(status.SourceHashes, name)
+	}
+	return nil
+}
+
+// getDMSourceConfig returns the config of a source with the password read from its Secret, and the hash of the config
+func (m *dmTaskManager) getDMSourceConfig(ns string, source *v1alpha1.DMTaskSource) (*dmapi.SourceConfig, string, error) {
+	var password string
+	if source.SecretName != "" {
+		secret, err := m.deps.SecretLister.Secrets(ns).Get(source.SecretName)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get secret %s/%s of source %s, error: %v", ns, source.SecretName, source.Name, err)
+		}
+		password = string(secret.Data["password"])
+	}
+	config, hash := newDMSourceConfig(source, password)
+	return config, hash, nil
+}
+
+// newDMSourceConfig returns the config of a source and the hash of the config
+func newDMSourceConfig(source *v1alpha1.DMTaskSource, password string) (*dmapi.SourceConfig, string) {
+	config := &dmapi.SourceConfig{
+		Name:       source.Name,
+		Host:       source.Host,
+		Port:       source.Port,
+		User:       source.User,
+		Password:   password,
+		Enable:     true,
+		EnableGTID: source.EnableGTID,
+	}
+	// the config has no types that fail to be marshaled
+	data, _ := json.Marshal(config)
+	return config, fmt.Sprintf("%x", sha256.Sum256(data))[:16]
+}
+
+// deleteTask deletes the task from the DMCluster and removes the finalizer of the DMTask. The task is left
+// behind if the DMCluster is not found or being deleted.
+func (m *dmTaskManager) deleteTask(dt *v1alpha1.DMTask) error {
+	if !slice.ContainsString(dt.Finalizers, label.DMTaskProtectionFinalizer, nil) {
+		return nil
+	}
+	ns := dt.GetNamespace()
+	taskName := dt.TaskName()
+	dc, err := m.deps.DMClusterLister.DMClusters(ns).Get(dt.Spec.Cluster)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get dmcluster %s/%s of DMTask %s, error: %v", ns, dt.Spec.Cluster, dt.GetName(), err)
+	}
+	if err == nil && dc.DeletionTimestamp == nil {
+		masterClient := controller.GetMasterClient(m.deps.DMMasterControl, dc)
+		exists, err := taskExists(masterClient, taskName)
+		if err != nil {
+			return fmt.Errorf("failed to get the tasks of dmcluster %s/%s, error: %v", ns, dc.GetName(), err)
+		}
+		if exists {
+			if err := masterClient.DeleteTask(taskName); err != nil {
+				return fmt.Errorf("failed to delete task %s of dmcluster %s/%s, error: %v", taskName, ns, dc.GetName(), err)
+			}
+			klog.Infof("DMTask %s/%s: task %s is deleted", ns, dt.GetName(), taskName)
+		}
+	}
+
+	dt = dt.DeepCopy()
+	dt.Finalizers = slice.RemoveString(dt.Finalizers, label.DMTaskProtectionFinalizer, nil)
+	if _, err := m.deps.Clientset.PingcapV1alpha1().DMTasks(ns).Update(context.TODO(), dt, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("remove DMTask %s/%s protection finalizer failed, err: %v", ns, dt.GetName(), err)
+	}
+	return nil
+}
+
+func (m *dmTaskManager) updateDMTaskStatus(dt *v1alpha1.DMTask) (*v1alpha1.DMTask, error) {
+	ns := dt.GetNamespace()
+	dtName := dt.GetName()
+
+	status := dt.Status.DeepCopy()
+	var update *v1alpha1.DMTask
+
+	// don't wait due to limited number of clients, but backoff after the default number of steps
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var updateErr error
+		update, updateErr = m.deps.Clientset.PingcapV1alpha1().DMTasks(ns).UpdateStatus(context.TODO(), dt, metav1.UpdateOptions{})
+		if updateErr == nil {
+			klog.Infof("DMTask: [%s/%s] updated successfully", ns, dtName)
+			return nil
+		}
+		klog.V(4).Infof("failed to update DMTask: [%s/%s], error: %v", ns, dtName, updateErr)
+
+		if updated, err := m.deps.DMTaskLister.DMTasks(ns).Get(dtName); err == nil {
+			// make a copy so we don't mutate the shared cache
+			dt = updated.DeepCopy()
+			dt.Status = *status
+		} else {
+			utilruntime.HandleError(fmt.Errorf("error getting updated DMTask %s/%s from lister: %v", ns, dtName, err))
+		}
+
+		return updateErr
+	})
+	if err != nil {
+		klog.Errorf("failed to update DMTask: [%s/%s], error: %v", ns, dtName, err)
+	}
+	return update, err
+}
+
+// getDMTaskConfig returns the config of the task with the name set, and the hash of the config
+func getDMTaskConfig(dt *v1alpha1.DMTask) (json.RawMessage, string, error) {
+	config := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(dt.Spec.Config), &config); err != nil {
+		return nil, "", fmt.Errorf("failed to parse the config of task %s, error: %v", dt.TaskName(), err)
+	}
+	config["name"] = dt.TaskName()
+	// the keys are sorted, so the hash doesn't change with the order of the keys
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, fmt.Sprintf("%x", sha256.Sum256(data))[:16], nil
+}
+
+func taskExists(masterClient dmapi.MasterClient, taskName string) (bool, error) {
+	tasks, err := masterClient.GetTasks()
+	if err != nil {
+		return false, err
+	}
+	for _, task := range tasks {
+		if task.Name == taskName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getDMTaskStage returns the stage of a task from the stages of its subtasks, a task is Paused
+// unless all its subtasks are running or finished
+func getDMTaskStage(subTasks []*dmapi.SubTaskStatus) v1alpha1.DMTaskStage {
+	if len(subTasks) == 0 {
+		return v1alpha1.DMTaskStagePaused
+	}
+	finished := 0
+	for _, subTask := range subTasks {
+		switch subTask.Stage {
+		case string(v1alpha1.DMTaskStageFinished):
+			finished++
+		case string(v1alpha1.DMTaskStageRunning):
+		default:
+			return v1alpha1.DMTaskStagePaused
+		}
+	}
+	if finished == len(subTasks) {
+		return v1alpha1.DMTaskStageFinished
+	}
+	return v1alpha1.DMTaskStageRunning
+}
+
+// dmTaskResumeWait returns how long to wait before the paused task is resumed automatically again
+func dmTaskResumeWait(status *v1alpha1.DMTaskStatus, now time.Time) time.Duration {
+	if status.ResumeCount == 0 || status.LastResumeTime == nil {
+		return 0
+	}
+	backoff := dmTaskMaxResumeBackoff
+	if status.ResumeCount <= 5 {
+		backoff = dmTaskResumeBackoff << (status.ResumeCount - 1)
+		if backoff > dmTaskMaxResumeBackoff {
+			backoff = dmTaskMaxResumeBackoff
+		}
+	}
+	return status.LastResumeTime.Add(backoff).Sub(now)
+}
+
+func hasRunningSubTask(subTasks []*dmapi.SubTaskStatus) bool {
+	for _, subTask := range subTasks {
+		if subTask.Stage == string(v1alpha1.DMTaskStageRunning) {
+			return true
+		}
+	}
+	return false
+}
+
+func convertDMSubTaskStatuses(subTasks []*dmapi.SubTaskStatus) []v1alpha1.DMSubTaskStatus {
+	var statuses []v1alpha1.DMSubTaskStatus
+	for _, subTask := range subTasks {
+		statuses = append(statuses, v1alpha1.DMSubTaskStatus{
+			SourceName: subTask.SourceName,
+			WorkerName: subTask.WorkerName,
+			Stage:      subTask.Stage,
+			Unit:       subTask.Unit,
+		})
+	}
+	return statuses
+}
+
+var _ DMTaskManager = &dmTaskManager{}
+
+// FakeDMTaskManager is a fake DMTaskManager
+type FakeDMTaskManager struct {
+	err error
+}
+
+// NewFakeDMTaskManager returns a FakeDMTaskManager
+func NewFakeDMTaskManager() *FakeDMTaskManager {
+	return &FakeDMTaskManager{}
+}
+
+// SetSyncError sets the error returned by Sync
+func (m *FakeDMTaskManager) SetSyncError(err error) {
+	m.err = err
+}
+
+// Sync fake Sync
+func (m *FakeDMTaskManager) Sync(_ *v1alpha1.DMTask) error {
+	return m.err
+}
+
+var _ DMTaskManager = &FakeDMTaskManager{}
@@ -0,0 +1,370 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/dmapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeDMTaskMaster is a fake dm-master with one source and one task
+type fakeDMTaskMaster struct {
+	sources  []*dmapi.SourceInfo
+	task     json.RawMessage
+	subTasks []*dmapi.SubTaskStatus
+	actions  []dmapi.ActionType
+	err      error
+}
+
+func (f *fakeDMTaskMaster) setStage(stage string) {
+	for _, subTask := range f.subTasks {
+		subTask.Stage = stage
+	}
+}
+
+func (f *fakeDMTaskMaster) addReactions(masterClient *dmapi.FakeMasterClient) {
+	record := func(actionType dmapi.ActionType, reaction dmapi.Reaction) {
+		masterClient.AddReaction(actionType, func(action *dmapi.Action) (interface{}, error) {
+			if f.err != nil {
+				return nil, f.err
+			}
+			f.actions = append(f.actions, actionType)
+			return reaction(action)
+		})
+	}
+	masterClient.AddReaction(dmapi.GetSourcesActionType, func(action *dmapi.Action) (interface{}, error) {
+		return f.sources, f.err
+	})
+	masterClient.AddReaction(dmapi.GetTasksActionType, func(action *dmapi.Action) (interface{}, error) {
+		if f.task == nil {
+			return []*dmapi.TaskInfo{}, f.err
+		}
+		return []*dmapi.TaskInfo{{Name: "task-1"}}, f.err
+	})
+	masterClient.AddReaction(dmapi.GetTaskStatusesActionType, func(action *dmapi.Action) (interface{}, error) {
+		return f.subTasks, f.err
+	})
+	record(dmapi.CreateSourceActionType, func(action *dmapi.Action) (interface{}, error) {
+		if action.Source.Password != "secret" {
+			return nil, fmt.Errorf("access denied for user %s", action.Source.User)
+		}
+		f.sources = append(f.sources, &dmapi.SourceInfo{Name: action.Name})
+		return nil, nil
+	})
+	record(dmapi.UpdateSourceActionType, func(action *dmapi.Action) (interface{}, error) {
+		if action.Source.Password != "secret" {
+			return nil, fmt.Errorf("access denied for user %s", action.Source.User)
+		}
+		return nil, nil
+	})
+	record(dmapi.DeleteSourceActionType, func(action *dmapi.Action) (interface{}, error) {
+		for i, source := range f.sources {
+			if source.Name == action.Name {
+				f.sources = append(f.sources[:i], f.sources[i+1:]...)
+				return nil, nil
+			}
+		}
+		return nil, fmt.Errorf("source %s not found", action.Name)
+	})
+	record(dmapi.CreateTaskActionType, func(action *dmapi.Action) (interface{}, error) {
+		f.task = action.Task
+		f.subTasks = []*dmapi.SubTaskStatus{{Name: "task-1", SourceName: "mysql-01", WorkerName: "test-dm-worker-0", Stage: "Stopped", Unit: "dump"}}
+		return nil, nil
+	})
+	record(dmapi.UpdateTaskActionType, func(action *dmapi.Action) (interface{}, error) {
+		f.task = action.Task
+		return nil, nil
+	})
+	record(dmapi.StartTaskActionType, func(action *dmapi.Action) (interface{}, error) {
+		f.setStage("Running")
+		return nil, nil
+	})
+	record(dmapi.StopTaskActionType, func(action *dmapi.Action) (interface{}, error) {
+		f.setStage("Stopped")
+		return nil, nil
+	})
+	record(dmapi.DeleteTaskActionType, func(action *dmapi.Action) (interface{}, error) {
+		f.task = nil
+		f.subTasks = nil
+		return nil, nil
+	})
+}
+
+func TestDMTaskManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newDMTask := func() *v1alpha1.DMTask {
+		return &v1alpha1.DMTask{
+			ObjectMeta: metav1.ObjectMeta{Name: "task-1", Namespace: corev1.NamespaceDefault, Generation: 2},
+			Spec: v1alpha1.DMTaskSpec{
+				Cluster: "test",
+				Sources: []v1alpha1.DMTaskSource{{Name: "mysql-01", Host: "mysql", Port: 3306, User: "root", SecretName: "mysql"}},
+				Config:  `{"task_mode": "all", "source_config": {"source_conf": [{"source_name": "mysql-01"}]}}`,
+			},
+		}
+	}
+	running := func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+		config, hash, err := getDMTaskConfig(dt)
+		g.Expect(err).NotTo(HaveOccurred())
+		f.sources = []*dmapi.SourceInfo{{Name: "mysql-01"}}
+		f.task = config
+		f.subTasks = []*dmapi.SubTaskStatus{{Name: "task-1", SourceName: "mysql-01", WorkerName: "test-dm-worker-0", Stage: "Running", Unit: "sync"}}
+		dt.Status.ConfigHash = hash
+		_, sourceHash := newDMSourceConfig(&dt.Spec.Sources[0], "secret")
+		dt.Status.SourceHashes = map[string]string{"mysql-01": sourceHash}
+	}
+
+	tests := []struct {
+		name      string
+		update    func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask)
+		expectErr bool
+		expect    func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus)
+	}{
+		{
+			name: "create the source and the task",
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(Equal([]dmapi.ActionType{dmapi.CreateSourceActionType, dmapi.CreateTaskActionType, dmapi.StartTaskActionType}))
+				g.Expect(string(f.task)).To(ContainSubstring(`"name":"task-1"`))
+				g.Expect(status.Stage).To(Equal(v1alpha1.DMTaskStageRunning))
+				g.Expect(status.ConfigHash).NotTo(BeEmpty())
+				g.Expect(status.SubTasks).To(Equal([]v1alpha1.DMSubTaskStatus{
+					{SourceName: "mysql-01", WorkerName: "test-dm-worker-0", Stage: "Running", Unit: "dump"},
+				}))
+				g.Expect(status.ObservedGeneration).To(BeEquivalentTo(2))
+				g.Expect(status.Message).To(BeEmpty())
+			},
+		},
+		{
+			name:   "the task is in sync",
+			update: running,
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(BeEmpty())
+				g.Expect(status.Stage).To(Equal(v1alpha1.DMTaskStageRunning))
+			},
+		},
+		{
+			name: "pause the task",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				running(f, dt)
+				dt.Spec.Stage = v1alpha1.DMTaskStagePaused
+			},
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(Equal([]dmapi.ActionType{dmapi.StopTaskActionType}))
+				g.Expect(status.Stage).To(Equal(v1alpha1.DMTaskStagePaused))
+			},
+		},
+		{
+			name: "update the config",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				running(f, dt)
+				dt.Spec.Config = `{"task_mode": "incremental", "source_config": {"source_conf": [{"source_name": "mysql-01"}]}}`
+			},
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(Equal([]dmapi.ActionType{dmapi.StopTaskActionType, dmapi.UpdateTaskActionType, dmapi.StartTaskActionType}))
+				g.Expect(string(f.task)).To(ContainSubstring(`"task_mode":"incremental"`))
+				g.Expect(status.Stage).To(Equal(v1alpha1.DMTaskStageRunning))
+			},
+		},
+		{
+			name: "update the source with the rotated password",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				running(f, dt)
+				_, sourceHash := newDMSourceConfig(&dt.Spec.Sources[0], "old-secret")
+				dt.Status.SourceHashes["mysql-01"] = sourceHash
+			},
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				_, sourceHash := newDMSourceConfig(&newDMTask().Spec.Sources[0], "secret")
+				g.Expect(f.actions).To(Equal([]dmapi.ActionType{dmapi.UpdateSourceActionType}))
+				g.Expect(status.SourceHashes).To(Equal(map[string]string{"mysql-01": sourceHash}))
+			},
+		},
+		{
+			name: "delete the source removed from the spec",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				running(f, dt)
+				f.sources = append(f.sources, &dmapi.SourceInfo{Name: "mysql-02"})
+				dt.Status.SourceHashes["mysql-02"] = "removed"
+			},
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(Equal([]dmapi.ActionType{dmapi.DeleteSourceActionType}))
+				g.Expect(f.sources).To(Equal([]*dmapi.SourceInfo{{Name: "mysql-01"}}))
+				g.Expect(status.SourceHashes).To(HaveLen(1))
+				g.Expect(status.SourceHashes).To(HaveKey("mysql-01"))
+			},
+		},
+		{
+			name: "resume the task paused by an error",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				running(f, dt)
+				f.setStage("Paused")
+			},
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(Equal([]dmapi.ActionType{dmapi.StartTaskActionType}))
+				g.Expect(status.Stage).To(Equal(v1alpha1.DMTaskStageRunning))
+				g.Expect(status.ResumeCount).To(BeEquivalentTo(1))
+				g.Expect(status.LastResumeTime).NotTo(BeNil())
+			},
+		},
+		{
+			name: "back off resuming the task paused by an error again",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				running(f, dt)
+				f.setStage("Paused")
+				dt.Status.ResumeCount = 2
+				dt.Status.LastResumeTime = &metav1.Time{Time: time.Now().Add(-30 * time.Second)}
+			},
+			expectErr: true,
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(BeEmpty())
+				g.Expect(status.Stage).To(Equal(v1alpha1.DMTaskStagePaused))
+				g.Expect(status.ResumeCount).To(BeEquivalentTo(2))
+				g.Expect(status.Message).To(ContainSubstring("resume it after 30s"))
+			},
+		},
+		{
+			name: "resume the task paused by an error after the backoff",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				running(f, dt)
+				f.setStage("Paused")
+				dt.Status.ResumeCount = 2
+				dt.Status.LastResumeTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+			},
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(Equal([]dmapi.ActionType{dmapi.StartTaskActionType}))
+				g.Expect(status.ResumeCount).To(BeEquivalentTo(3))
+			},
+		},
+		{
+			name: "reset the resume count once the task keeps running",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				running(f, dt)
+				dt.Status.ResumeCount = 2
+				dt.Status.LastResumeTime = &metav1.Time{Time: time.Now().Add(-dmTaskMaxResumeBackoff)}
+			},
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(BeEmpty())
+				g.Expect(status.ResumeCount).To(BeEquivalentTo(0))
+			},
+		},
+		{
+			name: "stop the task",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				running(f, dt)
+				dt.Spec.Stage = v1alpha1.DMTaskStageStopped
+			},
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(Equal([]dmapi.ActionType{dmapi.DeleteTaskActionType}))
+				g.Expect(f.task).To(BeNil())
+				g.Expect(status.Stage).To(Equal(v1alpha1.DMTaskStageStopped))
+				g.Expect(status.ConfigHash).To(BeEmpty())
+				g.Expect(status.SubTasks).To(BeEmpty())
+			},
+		},
+		{
+			name: "the config is invalid",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				dt.Spec.Config = "task_mode: all"
+			},
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(f.actions).To(BeEmpty())
+				g.Expect(status.Message).To(ContainSubstring("spec.config"))
+				g.Expect(status.ObservedGeneration).To(BeEquivalentTo(0))
+			},
+		},
+		{
+			name: "the OpenAPI is not enabled",
+			update: func(f *fakeDMTaskMaster, dt *v1alpha1.DMTask) {
+				f.err = dmapi.ErrOpenAPINotEnabled
+			},
+			expectErr: true,
+			expect: func(f *fakeDMTaskMaster, status *v1alpha1.DMTaskStatus) {
+				g.Expect(status.Message).To(ContainSubstring(dmapi.ErrOpenAPINotEnabled.Error()))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		deps := controller.NewFakeDependencies()
+		m := NewDMTaskManager(deps)
+
+		dc := newDMClusterForMaster()
+		g.Expect(deps.InformerFactory.Pingcap().V1alpha1().DMClusters().Informer().GetIndexer().Add(dc)).To(Succeed())
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: dc.Namespace},
+			Data:       map[string][]byte{"password": []byte("secret")},
+		}
+		g.Expect(deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer().Add(secret)).To(Succeed())
+		f := &fakeDMTaskMaster{}
+		f.addReactions(controller.NewFakeMasterClient(deps.DMMasterControl.(*dmapi.FakeMasterControl), dc))
+
+		dt := newDMTask()
+		if tt.update != nil {
+			tt.update(f, dt)
+		}
+		_, err := deps.Clientset.PingcapV1alpha1().DMTasks(dt.Namespace).Create(context.TODO(), dt, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+
+		err = m.Sync(dt)
+		if tt.expectErr {
+			g.Expect(err).To(HaveOccurred())
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		updated, err := deps.Clientset.PingcapV1alpha1().DMTasks(dt.Namespace).Get(context.TODO(), dt.Name, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(updated.Finalizers).To(Equal([]string{label.DMTaskProtectionFinalizer}))
+		tt.expect(f, &updated.Status)
+	}
+}
+
+func TestDMTaskManagerDeleteTask(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewDMTaskManager(deps)
+	dc := newDMClusterForMaster()
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().DMClusters().Informer().GetIndexer().Add(dc)).To(Succeed())
+	f := &fakeDMTaskMaster{task: json.RawMessage(`{"name":"task-1"}`)}
+	f.addReactions(controller.NewFakeMasterClient(deps.DMMasterControl.(*dmapi.FakeMasterControl), dc))
+
+	dt := &v1alpha1.DMTask{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "task-1",
+			Namespace:         corev1.NamespaceDefault,
+			Finalizers:        []string{label.DMTaskProtectionFinalizer},
+			DeletionTimestamp: &metav1.Time{},
+		},
+		Spec: v1alpha1.DMTaskSpec{Cluster: "test", Config: "{}"},
+	}
+	_, err := deps.Clientset.PingcapV1alpha1().DMTasks(dt.Namespace).Create(context.TODO(), dt, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(m.Sync(dt)).To(Succeed())
+	g.Expect(f.actions).To(Equal([]dmapi.ActionType{dmapi.DeleteTaskActionType}))
+	updated, err := deps.Clientset.PingcapV1alpha1().DMTasks(dt.Namespace).Get(context.TODO(), dt.Name, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.Finalizers).To(BeEmpty())
+}
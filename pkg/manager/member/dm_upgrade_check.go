@@ -0,0 +1,101 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/dmapi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// dmFullMigrationUnits are the units of the full migration phase of a subtask
+	dmFullMigrationUnits = sets.NewString("dump", "load")
+	// dmInactiveTaskStages are the stages of the subtasks that are not interrupted by the upgrade
+	dmInactiveTaskStages = sets.NewString("Stopped", "Finished")
+)
+
+// checkDMTaskStagesForUpgrade checks the stages of the subtasks before upgrading dm-master or dm-worker, and returns
+// whether the upgrade is blocked. The upgrade is blocked while any subtask is in the full migration phase, as the dump
+// starts over if it's interrupted, unless the policy is Warn. The UpgradeBlocked condition of the component is set
+// to describe the blocking subtasks, and removed once the upgrade is unblocked. The check is skipped if the OpenAPI
+// of dm-master is not enabled.
+func checkDMTaskStagesForUpgrade(deps *controller.Dependencies, dc *v1alpha1.DMCluster, memberType v1alpha1.MemberType,
+	conditions *[]metav1.Condition) (bool, error) {
+	ns := dc.GetNamespace()
+	dcName := dc.GetName()
+	statuses, err := controller.GetMasterClient(deps.DMMasterControl, dc).GetSubTaskStatuses()
+	if err == dmapi.ErrOpenAPINotEnabled {
+		klog.Warningf("dmcluster: [%s/%s] the OpenAPI of dm-master is not enabled, skip checking the task stages before upgrading %s", ns, dcName, memberType)
+		removeUpgradeBlocked(conditions)
+		return false, nil
+	}
+	if err != nil {
+		if dc.Spec.UpgradeTaskCheckPolicy == v1alpha1.DMUpgradeTaskCheckWarn {
+			klog.Warningf("dmcluster: [%s/%s] failed to get the task stages before upgrading %s, error: %v", ns, dcName, memberType, err)
+			return false, nil
+		}
+		return true, fmt.Errorf("checkDMTaskStagesForUpgrade: failed to get the task stages for cluster %s/%s, error: %s", ns, dcName, err)
+	}
+
+	var blockers []string
+	for _, status := range statuses {
+		if dmFullMigrationUnits.Has(status.Unit) && !dmInactiveTaskStages.Has(status.Stage) {
+			blockers = append(blockers, fmt.Sprintf("%s/%s (%s %s on %s)", status.Name, status.SourceName, status.Unit, status.Stage, status.WorkerName))
+		}
+	}
+	if len(blockers) == 0 {
+		removeUpgradeBlocked(conditions)
+		return false, nil
+	}
+
+	sort.Strings(blockers)
+	msg := fmt.Sprintf("subtasks %s are in the full migration phase", strings.Join(blockers, ", "))
+	if dc.Spec.UpgradeTaskCheckPolicy == v1alpha1.DMUpgradeTaskCheckWarn {
+		msg = fmt.Sprintf("%s, upgrade %s anyway as the upgrade task check policy is %s", msg, memberType, v1alpha1.DMUpgradeTaskCheckWarn)
+		klog.Warningf("dmcluster: [%s/%s] %s", ns, dcName, msg)
+		deps.Recorder.Event(dc, corev1.EventTypeWarning, v1alpha1.ComponentUpgradeBlocked, msg)
+		removeUpgradeBlocked(conditions)
+		return false, nil
+	}
+
+	msg = fmt.Sprintf("%s, the upgrade of %s is blocked until they finish, or set the upgrade task check policy to %s to upgrade anyway",
+		msg, memberType, v1alpha1.DMUpgradeTaskCheckWarn)
+	if !meta.IsStatusConditionTrue(*conditions, v1alpha1.ComponentUpgradeBlocked) {
+		klog.Warningf("dmcluster: [%s/%s] %s", ns, dcName, msg)
+		deps.Recorder.Event(dc, corev1.EventTypeWarning, v1alpha1.ComponentUpgradeBlocked, msg)
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    v1alpha1.ComponentUpgradeBlocked,
+		Status:  metav1.ConditionTrue,
+		Reason:  "TaskInFullMigration",
+		Message: msg,
+	})
+	return true, nil
+}
+
+func removeUpgradeBlocked(conditions *[]metav1.Condition) {
+	if meta.FindStatusCondition(*conditions, v1alpha1.ComponentUpgradeBlocked) != nil {
+		meta.RemoveStatusCondition(conditions, v1alpha1.ComponentUpgradeBlocked)
+	}
+}
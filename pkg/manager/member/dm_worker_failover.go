@@ -19,7 +19,10 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
@@ -48,6 +51,12 @@ func (f *workerFailover) Failover(dc *v1alpha1.DMCluster) error {
 			continue
 		}
 		deadline := worker.LastTransitionTime.Add(f.deps.CLIConfig.WorkerFailoverPeriod)
+		if worker.Stage == v1alpha1.DMWorkerStateOffline && time.Now().After(deadline) && isWorkerFailoverRecreationEnabled(dc) {
+			if err := f.tryToRecreateWorker(dc, podName, worker.LastTransitionTime); err != nil {
+				return err
+			}
+			continue
+		}
 		exist := false
 		for _, failureWorker := range dc.Status.Worker.FailureMembers {
 			if failureWorker.PodName == podName {
@@ -78,6 +87,57 @@ func (f *workerFailover) Failover(dc *v1alpha1.DMCluster) error {
 	return nil
 }
 
+// tryToRecreateWorker deletes the Pod and the PVC of the offline dm-worker, then the StatefulSet recreates the
+// worker with the same ordinal and fresh storage instead of a new worker being added. Only the Pod and the PVC
+// created before the worker went offline are deleted, so the recreated ones are kept while the new worker is
+// registering to dm-master.
+func (f *workerFailover) tryToRecreateWorker(dc *v1alpha1.DMCluster, podName string, offlineSince metav1.Time) error {
+	ns := dc.GetNamespace()
+	dcName := dc.GetName()
+
+	pod, err := f.deps.PodLister.Pods(ns).Get(podName)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("tryToRecreateWorker: failed to get pod %s for dmcluster %s/%s, error: %s", podName, ns, dcName, err)
+	}
+	ordinal, err := util.GetOrdinalFromPodName(podName)
+	if err != nil {
+		return err
+	}
+	pvcName := ordinalPVCName(v1alpha1.DMWorkerMemberType, controller.DMWorkerMemberName(dcName), ordinal)
+	pvc, err := f.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("tryToRecreateWorker: failed to get pvc %s for dmcluster %s/%s, error: %s", pvcName, ns, dcName, err)
+	}
+
+	deleted := false
+	if pod != nil && pod.DeletionTimestamp == nil && pod.CreationTimestamp.Before(&offlineSince) {
+		if err := f.deps.PodControl.DeletePod(dc, pod); err != nil {
+			return err
+		}
+		deleted = true
+	}
+	if pvc != nil && pvc.DeletionTimestamp == nil && pvc.CreationTimestamp.Before(&offlineSince) {
+		if err := f.deps.PVCControl.DeletePVC(dc, pvc); err != nil {
+			klog.Errorf("dm-worker failover: failed to delete pvc: %s/%s, %v", ns, pvcName, err)
+			return err
+		}
+		deleted = true
+	}
+	if deleted {
+		klog.Infof("dm-worker failover: delete pod and pvc of the offline worker %s/%s to recreate it", ns, podName)
+		msg := fmt.Sprintf("worker[%s/%s] is Offline, recreate it with fresh storage", ns, podName)
+		f.deps.Recorder.Event(dc, corev1.EventTypeWarning, unHealthEventReason, fmt.Sprintf(unHealthEventMsgPattern, "worker", podName, msg))
+	}
+	return nil
+}
+
+// isWorkerFailoverRecreationEnabled returns whether the offline dm-workers are recreated instead of new workers
+// being added by the failover
+func isWorkerFailoverRecreationEnabled(dc *v1alpha1.DMCluster) bool {
+	enabled := features.DefaultFeatureGate.Enabled(features.DMWorkerFailoverRecreation)
+	return features.EnabledForCluster(dc.Spec.FeatureGates, features.DMWorkerFailoverRecreation, enabled)
+}
+
 func (f *workerFailover) Recover(dc *v1alpha1.DMCluster) {
 	dc.Status.Worker.FailureMembers = nil
 	klog.Infof("dm-worker recover: clear FailureWorkers, %s/%s", dc.GetNamespace(), dc.GetName())
@@ -20,6 +20,8 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
@@ -291,3 +293,59 @@ func TestWorkerFailoverFailover(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkerFailoverRecreation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dc := newDMClusterForMaster()
+	dc.Spec.Worker.Replicas = 3
+	dc.Spec.Worker.MaxFailoverCount = pointer.Int32Ptr(3)
+	dc.Spec.FeatureGates = map[string]bool{features.DMWorkerFailoverRecreation: true}
+	ns := dc.GetNamespace()
+	offlineSince := time.Now().Add(-70 * time.Minute)
+	podName := ordinalPodName(v1alpha1.DMWorkerMemberType, dc.GetName(), 1)
+	dc.Status.Worker.Members = map[string]v1alpha1.WorkerMember{
+		podName: {
+			Stage:              v1alpha1.DMWorkerStateOffline,
+			Name:               podName,
+			LastTransitionTime: metav1.Time{Time: offlineSince},
+		},
+	}
+
+	fakeDeps := controller.NewFakeDependencies()
+	fakeDeps.CLIConfig.WorkerFailoverPeriod = 1 * time.Hour
+	workerFailover := &workerFailover{deps: fakeDeps}
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	pvcIndexer := fakeDeps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+	pvcName := ordinalPVCName(v1alpha1.DMWorkerMemberType, controller.DMWorkerMemberName(dc.GetName()), 1)
+	created := metav1.Time{Time: offlineSince.Add(-time.Hour)}
+	g.Expect(podIndexer.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: ns, CreationTimestamp: created}})).To(Succeed())
+	g.Expect(pvcIndexer.Add(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: ns, CreationTimestamp: created}})).To(Succeed())
+
+	// the pod and the pvc of the offline worker are deleted instead of a new worker being added
+	g.Expect(workerFailover.Failover(dc)).To(Succeed())
+	g.Expect(dc.Status.Worker.FailureMembers).To(BeEmpty())
+	_, exist, err := podIndexer.GetByKey(ns + "/" + podName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeFalse())
+	_, exist, err = pvcIndexer.GetByKey(ns + "/" + pvcName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeFalse())
+
+	// the recreated pod and pvc are kept while the new worker is registering
+	recreated := metav1.Time{Time: time.Now()}
+	g.Expect(podIndexer.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: ns, CreationTimestamp: recreated}})).To(Succeed())
+	g.Expect(pvcIndexer.Add(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: ns, CreationTimestamp: recreated}})).To(Succeed())
+	g.Expect(workerFailover.Failover(dc)).To(Succeed())
+	_, exist, err = podIndexer.GetByKey(ns + "/" + podName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeTrue())
+	_, exist, err = pvcIndexer.GetByKey(ns + "/" + pvcName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeTrue())
+
+	// a new worker is added as before if the feature is disabled for the cluster
+	dc.Spec.FeatureGates[features.DMWorkerFailoverRecreation] = false
+	g.Expect(workerFailover.Failover(dc)).To(Succeed())
+	g.Expect(dc.Status.Worker.FailureMembers).To(HaveKey(podName))
+}
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -171,6 +172,11 @@ func (m *workerMemberManager) syncWorkerStatefulSetForDMCluster(dc *v1alpha1.DMC
 		return nil
 	}
 
+	// failed to transfer the sources to match their placements will not block syncing the statefulset either
+	if err := syncDMSourcePlacements(m.deps, dc); err != nil {
+		klog.Errorf("failed to sync DMCluster: [%s/%s]'s source placements, error: %v", ns, dcName, err)
+	}
+
 	cm, err := m.syncWorkerConfigMap(dc, oldSts)
 	if err != nil {
 		return err
@@ -190,6 +196,12 @@ func (m *workerMemberManager) syncWorkerStatefulSetForDMCluster(dc *v1alpha1.DMC
 	if err != nil {
 		return err
 	}
+	if err := addRelayLogStorageSidecar(m.deps, dc, &newSts.Spec.Template.Spec); err != nil {
+		return err
+	}
+	if dc.Spec.Suspend {
+		return suspendDMStatefulSet(m.deps, dc, oldSts, newSts)
+	}
 
 	if stsNotExist {
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newSts)
@@ -203,6 +215,11 @@ func (m *workerMemberManager) syncWorkerStatefulSetForDMCluster(dc *v1alpha1.DMC
 		return nil
 	}
 
+	// The members are kept in the dm cluster during the suspension, so the scaler is bypassed to resume them
+	if resumeDMStatefulSet(dc, oldSts, newSts) {
+		return mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, dc, newSts, oldSts)
+	}
+
 	if err := m.scaler.Scale(dc, oldSts, newSts); err != nil {
 		return err
 	}
@@ -218,6 +235,19 @@ func (m *workerMemberManager) syncWorkerStatefulSetForDMCluster(dc *v1alpha1.DMC
 		}
 	}
 
+	if !templateEqual(newSts, oldSts) || dc.Status.Worker.Phase == v1alpha1.UpgradePhase {
+		blocked, err := checkDMTaskStagesForUpgrade(m.deps, dc, v1alpha1.DMWorkerMemberType, &dc.Status.Worker.Conditions)
+		if err != nil {
+			klog.Errorf("failed to check DMCluster: [%s/%s]'s task stages before upgrading dm-worker, error: %v", ns, dcName, err)
+		}
+		if blocked {
+			// no dm-worker Pod is recreated while the upgrade is blocked
+			mngerutils.SetUpgradePartition(newSts, *newSts.Spec.Replicas)
+		}
+	} else {
+		removeUpgradeBlocked(&dc.Status.Worker.Conditions)
+	}
+
 	return mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, dc, newSts, oldSts)
 }
 
@@ -228,6 +258,13 @@ func (m *workerMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 	}
 
 	dc.Status.Worker.StatefulSet = &set.Status
+	dc.Status.Worker.StartScriptVersion = getStartScriptVersion(set)
+
+	if dc.Spec.Suspend {
+		// the members can't be queried while the dm cluster is suspended, keep their last observed status
+		dc.Status.Worker.Phase = v1alpha1.SuspendPhase
+		return nil
+	}
 
 	upgrading, err := m.workerStatefulSetIsUpgrading(set, dc)
 	if err != nil {
@@ -252,6 +289,10 @@ func (m *workerMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 	workerStatus := map[string]v1alpha1.WorkerMember{}
 	for _, worker := range workersInfo {
 		name := worker.Name
+		// the dm-masters may be shared with the DMClusters joining them, only the dm-workers of this DMCluster are synced
+		if !strings.HasPrefix(name, controller.DMWorkerMemberName(dc.GetName())+"-") {
+			continue
+		}
 		status := v1alpha1.WorkerMember{
 			Name:  name,
 			Addr:  worker.Addr,
@@ -342,6 +383,9 @@ func getNewWorkerSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 		{Name: "startup-script", ReadOnly: true, MountPath: "/usr/local/bin"},
 		{Name: v1alpha1.DMWorkerMemberType.String(), MountPath: dmWorkerDataVolumeMountPath},
 	}
+	// handle StorageVolumes and AdditionalVolumeMounts in ComponentSpec
+	storageVolMounts, additionalPVCs := util.BuildStorageVolumeAndVolumeMount(dc.Spec.Worker.StorageVolumes, dc.Spec.Worker.StorageClassName, v1alpha1.DMWorkerMemberType)
+	volMounts = append(volMounts, storageVolMounts...)
 	volMounts = append(volMounts, dc.Spec.Worker.AdditionalVolumeMounts...)
 
 	if dc.IsTLSClusterEnabled() {
@@ -414,6 +458,7 @@ func getNewWorkerSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 	stsLabels := label.NewDM().Instance(instanceName).DMWorker()
 	podLabels := util.CombineStringMap(stsLabels, baseWorkerSpec.Labels())
 	podAnnotations := util.CombineStringMap(controller.AnnProm(8262), baseWorkerSpec.Annotations())
+	setStartScriptVersionAnnotation(podAnnotations, baseWorkerSpec.StartScriptVersion())
 	stsAnnotations := getStsAnnotations(dc.Annotations, label.DMWorkerLabelVal)
 
 	workerContainer := corev1.Container{
@@ -454,7 +499,7 @@ func getNewWorkerSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 		},
 		{
 			Name:  "TZ",
-			Value: dc.Timezone(),
+			Value: baseWorkerSpec.Timezone(),
 		},
 	}
 
@@ -473,6 +518,13 @@ func getNewWorkerSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 	workerContainer.Env = util.AppendEnv(env, baseWorkerSpec.Env())
 	podSpec.Volumes = append(vols, baseWorkerSpec.AdditionalVolumes()...)
 	podSpec.Containers = append([]corev1.Container{workerContainer}, baseWorkerSpec.AdditionalContainers()...)
+	addTimezoneData(baseWorkerSpec, &podSpec, v1alpha1.DMWorkerMemberType.String())
+	podSpec.InitContainers = baseWorkerSpec.InitContainers()
+
+	updateStrategy := apps.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType}
+	if baseWorkerSpec.StatefulSetUpdateStrategy() == apps.OnDeleteStatefulSetStrategyType {
+		updateStrategy.Type = apps.OnDeleteStatefulSetStrategyType
+	}
 
 	workerSet := &apps.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -507,13 +559,12 @@ func getNewWorkerSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 				},
 			},
 			ServiceName:         controller.DMWorkerPeerMemberName(dcName),
-			PodManagementPolicy: apps.ParallelPodManagement,
-			UpdateStrategy: apps.StatefulSetUpdateStrategy{
-				Type: apps.RollingUpdateStatefulSetStrategyType,
-			},
+			PodManagementPolicy: baseWorkerSpec.PodManagementPolicy(),
+			UpdateStrategy:      updateStrategy,
 		},
 	}
 
+	workerSet.Spec.VolumeClaimTemplates = append(workerSet.Spec.VolumeClaimTemplates, additionalPVCs...)
 	return workerSet, nil
 }
 
@@ -534,9 +585,19 @@ func getWorkerConfigMap(dc *v1alpha1.DMCluster) (*corev1.ConfigMap, error) {
 	if err != nil {
 		return nil, err
 	}
+	masterAddress := fmt.Sprintf("%s:%d", controller.DMMasterMemberName(dc.Name), dc.MasterPort())
+	if dc.HeterogeneousWithoutLocalMaster() {
+		ns, dcName := dc.MasterCluster()
+		masterAddress = fmt.Sprintf("%s.%s:%d", controller.DMMasterMemberName(dcName), ns, dc.MasterPort())
+	}
 	startScript, err := RenderDMWorkerStartScript(&DMWorkerStartScriptModel{
 		DataDir:       filepath.Join(dmWorkerDataVolumeMountPath, dc.Spec.Worker.DataSubDir),
-		MasterAddress: controller.DMMasterMemberName(dc.Name) + ":8261",
+		MasterAddress: masterAddress,
+		// the dm-masters of the referenced DMCluster may be in another namespace
+		AdvertiseWithNamespace: dc.HeterogeneousWithoutLocalMaster(),
+		ClusterDomain:          dc.Spec.ClusterDomain,
+
+		StartScriptVersion: dc.BaseWorkerSpec().StartScriptVersion(),
 	})
 	if err != nil {
 		return nil, err
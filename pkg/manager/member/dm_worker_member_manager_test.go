@@ -553,9 +553,11 @@ func TestWorkerMemberManagerUpgrade(t *testing.T) {
 				cluster.Spec.Worker.BaseImage = "dm-test-image-2"
 			},
 			workerInfos: []*dmapi.WorkersInfo{
-				{Name: "worker1", Addr: "http://worker1:8262", Stage: v1alpha1.DMWorkerStateFree},
-				{Name: "worker2", Addr: "http://worker2:8262", Stage: v1alpha1.DMWorkerStateFree},
-				{Name: "worker3", Addr: "http://worker3:8262", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql1"},
+				{Name: "test-dm-worker-0", Addr: "http://test-dm-worker-0:8262", Stage: v1alpha1.DMWorkerStateFree},
+				{Name: "test-dm-worker-1", Addr: "http://test-dm-worker-1:8262", Stage: v1alpha1.DMWorkerStateFree},
+				{Name: "test-dm-worker-2", Addr: "http://test-dm-worker-2:8262", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql1"},
+				// the dm-worker of another DMCluster joining the dm-masters
+				{Name: "workers-dm-worker-0", Addr: "http://workers-dm-worker-0:8262", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql2"},
 			},
 			err: false,
 			statusChange: func(set *appsv1.StatefulSet) {
@@ -571,9 +573,9 @@ func TestWorkerMemberManagerUpgrade(t *testing.T) {
 			},
 			expectDMClusterFn: func(g *GomegaWithT, dc *v1alpha1.DMCluster) {
 				g.Expect(len(dc.Status.Worker.Members)).To(Equal(3))
-				g.Expect(dc.Status.Worker.Members["worker1"].Stage).To(Equal(v1alpha1.DMWorkerStateFree))
-				g.Expect(dc.Status.Worker.Members["worker2"].Stage).To(Equal(v1alpha1.DMWorkerStateFree))
-				g.Expect(dc.Status.Worker.Members["worker3"].Stage).To(Equal(v1alpha1.DMWorkerStateBound))
+				g.Expect(dc.Status.Worker.Members["test-dm-worker-0"].Stage).To(Equal(v1alpha1.DMWorkerStateFree))
+				g.Expect(dc.Status.Worker.Members["test-dm-worker-1"].Stage).To(Equal(v1alpha1.DMWorkerStateFree))
+				g.Expect(dc.Status.Worker.Members["test-dm-worker-2"].Stage).To(Equal(v1alpha1.DMWorkerStateBound))
 			},
 		},
 	}
@@ -1111,6 +1113,65 @@ func TestGetNewWorkerSetForDMCluster(t *testing.T) {
 			},
 			testSts: testAdditionalVolumes(t, []corev1.Volume{{Name: "test", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}),
 		},
+		{
+			name: "dm-worker storage volumes",
+			dc: v1alpha1.DMCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dc",
+					Namespace: "ns",
+				},
+				Spec: v1alpha1.DMClusterSpec{
+					Master: v1alpha1.MasterSpec{},
+					Worker: &v1alpha1.WorkerSpec{
+						StorageSize: "10Gi",
+						StorageVolumes: []v1alpha1.StorageVolume{
+							{Name: "relay", StorageSize: "2Gi", MountPath: "/var/lib/relay"},
+						},
+					},
+				},
+			},
+			testSts: func(sts *appsv1.StatefulSet) {
+				g := NewGomegaWithT(t)
+				g.Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(2))
+				pvc := sts.Spec.VolumeClaimTemplates[1]
+				g.Expect(pvc.Name).To(Equal(fmt.Sprintf("%s-%s", v1alpha1.DMWorkerMemberType, "relay")))
+				g.Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("2Gi")))
+				g.Expect(sts.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name: pvc.Name, MountPath: "/var/lib/relay",
+				}))
+			},
+		},
+		{
+			name: "dm-worker respects the component-level statefulset settings and init containers",
+			dc: v1alpha1.DMCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dc",
+					Namespace: "ns",
+				},
+				Spec: v1alpha1.DMClusterSpec{
+					Master: v1alpha1.MasterSpec{},
+					Worker: &v1alpha1.WorkerSpec{
+						ComponentSpec: v1alpha1.ComponentSpec{
+							InitContainers:            []corev1.Container{{Name: "init"}},
+							StatefulSetUpdateStrategy: appsv1.OnDeleteStatefulSetStrategyType,
+							PodManagementPolicy:       appsv1.OrderedReadyPodManagement,
+						},
+					},
+					Timezone: "Asia/Shanghai",
+				},
+			},
+			testSts: func(sts *appsv1.StatefulSet) {
+				g := NewGomegaWithT(t)
+				g.Expect(sts.Spec.UpdateStrategy).To(Equal(appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}))
+				g.Expect(sts.Spec.PodManagementPolicy).To(Equal(appsv1.OrderedReadyPodManagement))
+				podSpec := sts.Spec.Template.Spec
+				g.Expect(podSpec.InitContainers).To(Equal([]corev1.Container{{Name: "init"}}))
+				g.Expect(podSpec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "TZ", Value: "Asia/Shanghai"}))
+				g.Expect(podSpec.Containers[0].VolumeMounts).NotTo(ContainElement(corev1.VolumeMount{
+					Name: timezoneDataVolumeName, MountPath: timezoneDataPath, ReadOnly: true,
+				}))
+			},
+		},
 		// TODO add more tests
 	}
 
@@ -1248,3 +1309,20 @@ keepalive-ttl = 25
 		})
 	}
 }
+
+func TestGetWorkerConfigMapJoinReferencedMasters(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dc := newDMClusterForWorker()
+	dc.Namespace = "dm-workers"
+	dc.Spec.Master.Replicas = 0
+	dc.Spec.Cluster = &v1alpha1.TidbClusterRef{Name: "main", Namespace: "dm"}
+
+	for _, version := range []v1alpha1.StartScriptVersion{v1alpha1.StartScriptV1, v1alpha1.StartScriptV2} {
+		dc.Spec.StartScriptVersion = version
+		cm, err := getWorkerConfigMap(dc)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(cm.Data["startup-script"]).To(ContainSubstring("--join=main-dm-master.dm:8261"))
+		g.Expect(cm.Data["startup-script"]).To(ContainSubstring("${POD_NAME}.${HEADLESS_SERVICE_NAME}.${NAMESPACE}"))
+	}
+}
@@ -78,6 +78,7 @@ func (s *workerScaler) ScaleOut(meta metav1.Object, oldSet *apps.StatefulSet, ne
 // Now it will be removed in syncing worker status. For dm-worker we can't remove its register info from dm-master
 // when it's still alive. So we delete it later after its keepalive lease is outdated or revoked.
 // We can defer deleting dm-worker register info because dm-master will patch replication task through keepalive info.
+// Before reducing replicas, the source bound to the dm-worker is transferred to a free dm-worker if there is one.
 // only remove one member at a time when scale down
 func (s *workerScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) error {
 	dc, ok := meta.(*v1alpha1.DMCluster)
@@ -101,6 +102,13 @@ func (s *workerScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, new
 	//	return nil
 	//}
 
+	// transfer the source bound to the dm-worker to another free dm-worker before removing it,
+	// so that the running migration tasks won't be interrupted
+	memberName := ordinalPodName(v1alpha1.DMWorkerMemberType, dcName, ordinal)
+	if err := s.drainWorker(dc, memberName); err != nil {
+		return err
+	}
+
 	pvcName := ordinalPVCName(v1alpha1.DMWorkerMemberType, setName, ordinal)
 	pvc, err := s.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
 	if err != nil {
@@ -126,6 +134,49 @@ func (s *workerScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, new
 	return nil
 }
 
+// drainWorker makes sure the dm-worker has no source bound. If a source is bound to it,
+// the source is transferred to a free dm-worker which is still desired and a requeue error
+// is returned to wait for the dm-worker becoming free.
+func (s *workerScaler) drainWorker(dc *v1alpha1.DMCluster, memberName string) error {
+	ns := dc.GetNamespace()
+	dcName := dc.GetName()
+	masterClient := controller.GetMasterClient(s.deps.DMMasterControl, dc)
+	workersInfo, err := masterClient.GetWorkers()
+	if err != nil {
+		return fmt.Errorf("dm-worker scale in: failed to get dm-workers of dc %s/%s, error: %v", ns, dcName, err)
+	}
+
+	var source string
+	var freeWorker string
+	for _, worker := range workersInfo {
+		if worker.Name == memberName {
+			if worker.Stage == v1alpha1.DMWorkerStateBound {
+				source = worker.Source
+			}
+			continue
+		}
+		if freeWorker == "" && worker.Stage == v1alpha1.DMWorkerStateFree && isWorkerPodDesired(dc, worker.Name) {
+			freeWorker = worker.Name
+		}
+	}
+
+	if source == "" {
+		return nil
+	}
+	if freeWorker == "" {
+		// dm-master will bind the source again when a free dm-worker shows up, don't block the scale-in
+		klog.Warningf("dm-worker scale in: no free dm-worker to take over source %s from %s in dc %s/%s", source, memberName, ns, dcName)
+		return nil
+	}
+
+	if err := masterClient.TransferSource(source, freeWorker); err != nil {
+		klog.Errorf("dm-worker scale in: failed to transfer source %s from %s to %s in dc %s/%s, error: %v", source, memberName, freeWorker, ns, dcName, err)
+		return err
+	}
+	klog.Infof("dm-worker scale in: transfer source %s from %s to %s in dc %s/%s", source, memberName, freeWorker, ns, dcName)
+	return controller.RequeueErrorf("dc [%s/%s]'s dm-worker %s is transferring source %s to %s, can't scale in now", ns, dcName, memberName, source, freeWorker)
+}
+
 type fakeWorkerScaler struct{}
 
 // NewFakeWorkerScaler returns a fake Scaler
@@ -158,9 +158,13 @@ func TestWorkerScalerScaleIn(t *testing.T) {
 		hasPVC           bool
 		pvcUpdateErr     bool
 		statusSyncFailed bool
+		boundSource      bool
+		hasFreeWorker    bool
+		getWorkersErr    bool
+		transferErr      bool
 		err              bool
 		changed          bool
-		isLeader         bool
+		requeue          bool
 	}
 
 	testFn := func(test testcase, t *testing.T) {
@@ -169,7 +173,36 @@ func TestWorkerScalerScaleIn(t *testing.T) {
 		newSet := oldSet.DeepCopy()
 		newSet.Spec.Replicas = pointer.Int32Ptr(3)
 
-		scaler, _, pvcIndexer, pvcControl := newFakeWorkerScaler()
+		scaler, masterControl, pvcIndexer, pvcControl := newFakeWorkerScaler()
+
+		workersInfo := []*dmapi.WorkersInfo{
+			{Name: ordinalPodName(v1alpha1.DMWorkerMemberType, dc.GetName(), 0), Stage: v1alpha1.DMWorkerStateBound, Source: "mysql-0"},
+			{Name: ordinalPodName(v1alpha1.DMWorkerMemberType, dc.GetName(), 4), Stage: v1alpha1.DMWorkerStateFree},
+		}
+		if test.boundSource {
+			workersInfo[1].Stage = v1alpha1.DMWorkerStateBound
+			workersInfo[1].Source = "mysql-1"
+		}
+		if test.hasFreeWorker {
+			workersInfo = append(workersInfo, &dmapi.WorkersInfo{Name: ordinalPodName(v1alpha1.DMWorkerMemberType, dc.GetName(), 1), Stage: v1alpha1.DMWorkerStateFree})
+		}
+		transferred := false
+		masterClient := controller.NewFakeMasterClient(masterControl, dc)
+		masterClient.AddReaction(dmapi.GetWorkersActionType, func(action *dmapi.Action) (interface{}, error) {
+			if test.getWorkersErr {
+				return nil, fmt.Errorf("failed to get workers")
+			}
+			return workersInfo, nil
+		})
+		masterClient.AddReaction(dmapi.TransferSourceActionType, func(action *dmapi.Action) (interface{}, error) {
+			if test.transferErr {
+				return nil, fmt.Errorf("failed to transfer source")
+			}
+			g.Expect(action.Name).To(Equal(ordinalPodName(v1alpha1.DMWorkerMemberType, dc.GetName(), 1)))
+			g.Expect(action.Labels["source"]).To(Equal("mysql-1"))
+			transferred = true
+			return nil, nil
+		})
 
 		if test.hasPVC {
 			pvc := newScaleInPVCForStatefulSet(oldSet, v1alpha1.DMWorkerMemberType, dc.Name)
@@ -185,12 +218,13 @@ func TestWorkerScalerScaleIn(t *testing.T) {
 		err := scaler.ScaleIn(dc, oldSet, newSet)
 		if test.err {
 			g.Expect(err).To(HaveOccurred())
-			if test.isLeader {
+			if test.requeue {
 				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
 			}
 		} else {
 			g.Expect(err).NotTo(HaveOccurred())
 		}
+		g.Expect(transferred).To(Equal(test.boundSource && test.hasFreeWorker && !test.transferErr))
 		if test.changed {
 			g.Expect(int(*newSet.Spec.Replicas)).To(Equal(4))
 		} else {
@@ -231,6 +265,38 @@ func TestWorkerScalerScaleIn(t *testing.T) {
 			err:              true,
 			changed:          false,
 		},
+		{
+			name:          "failed to get dm-workers",
+			hasPVC:        true,
+			getWorkersErr: true,
+			err:           true,
+			changed:       false,
+		},
+		{
+			name:          "dm-worker has source bound, transfer it to a free dm-worker",
+			hasPVC:        true,
+			boundSource:   true,
+			hasFreeWorker: true,
+			err:           true,
+			changed:       false,
+			requeue:       true,
+		},
+		{
+			name:          "dm-worker has source bound, failed to transfer source",
+			hasPVC:        true,
+			boundSource:   true,
+			hasFreeWorker: true,
+			transferErr:   true,
+			err:           true,
+			changed:       false,
+		},
+		{
+			name:        "dm-worker has source bound, no free dm-worker",
+			hasPVC:      true,
+			boundSource: true,
+			err:         false,
+			changed:     true,
+		},
 	}
 
 	for _, tt := range tests {
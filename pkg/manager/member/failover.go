@@ -13,7 +13,11 @@
 
 package member
 
-import "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"k8s.io/klog/v2"
+)
 
 // TODO: move this to a centralized place
 // Since the "Unhealthy" is a very universal event reason string, which could apply to all the TiDB/DM cluster components,
@@ -37,3 +41,24 @@ type DMFailover interface {
 	Recover(*v1alpha1.DMCluster)
 	RemoveUndesiredFailures(*v1alpha1.DMCluster)
 }
+
+// isPodOnCordonedNode returns whether the Pod is on a cordoned node while the cluster is in the node upgrade
+// maintenance mode, the Pod is expected to be evicted and rescheduled, so no failover should be done for it
+func isPodOnCordonedNode(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, podName string) bool {
+	if !tc.IsNodeUpgradeMaintenance() {
+		return false
+	}
+	pod, err := deps.PodLister.Pods(tc.GetNamespace()).Get(podName)
+	if err != nil || pod.Spec.NodeName == "" {
+		return false
+	}
+	node, err := deps.NodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		return false
+	}
+	if node.Spec.Unschedulable {
+		klog.Infof("pod %s/%s is on cordoned node %s during node upgrade, skip failover", pod.Namespace, podName, node.Name)
+		return true
+	}
+	return false
+}
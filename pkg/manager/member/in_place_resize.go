@@ -0,0 +1,181 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// podResizeSubresource is the subresource to resize the resources of a Pod since Kubernetes v1.33,
+// the resources are patched in the Pod spec directly before that
+const podResizeSubresource = "resize"
+
+// resizePodInPlace resizes the CPU and memory of the container of a Pod in place instead of recreating it in the
+// rolling update, if the InPlacePodVerticalScaling feature gate is enabled for the cluster. The Pod is then labeled
+// with the update revision, so that the StatefulSet controller regards it as updated and doesn't recreate it.
+// It returns false if the Pod must be recreated instead, that is the revision of the Pod differs from the update
+// revision in anything else, a resized resource requires the restart, the memory limit is lowered, or the resize
+// is rejected by Kubernetes, e.g. the InPlacePodVerticalScaling feature of Kubernetes is not enabled.
+func resizePodInPlace(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, containerName string, policies []v1alpha1.ContainerResizePolicy,
+	pod *corev1.Pod, set *apps.StatefulSet, updateRevision string) (bool, error) {
+	enabled := features.DefaultFeatureGate.Enabled(features.InPlacePodVerticalScaling)
+	if !features.EnabledForCluster(tc.Spec.FeatureGates, features.InPlacePodVerticalScaling, enabled) {
+		return false, nil
+	}
+
+	ns := pod.GetNamespace()
+	oldTemplate, err := getRevisionTemplate(deps, set, pod.Labels[apps.ControllerRevisionHashLabelKey])
+	if err != nil || oldTemplate == nil {
+		return false, err
+	}
+	newTemplate, err := getRevisionTemplate(deps, set, updateRevision)
+	if err != nil || newTemplate == nil {
+		return false, err
+	}
+	resources, ok := resizableResources(oldTemplate, newTemplate, containerName, policies)
+	if !ok {
+		return false, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []map[string]interface{}{{"name": containerName, "resources": resources}},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	podCli := deps.KubeClientset.CoreV1().Pods(ns)
+	_, err = podCli.Patch(context.TODO(), pod.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, podResizeSubresource)
+	if errors.IsNotFound(err) {
+		_, err = podCli.Patch(context.TODO(), pod.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	}
+	if errors.IsInvalid(err) || errors.IsForbidden(err) || errors.IsBadRequest(err) || errors.IsNotFound(err) {
+		klog.Warningf("tidbcluster: [%s/%s] failed to resize pod %s in place, rolling update it instead, error: %v", ns, tc.GetName(), pod.Name, err)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("resizePodInPlace: failed to resize pod %s for cluster %s/%s, error: %s", pod.Name, ns, tc.GetName(), err)
+	}
+
+	labelPatch := fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, apps.ControllerRevisionHashLabelKey, updateRevision)
+	if _, err := podCli.Patch(context.TODO(), pod.Name, types.MergePatchType, []byte(labelPatch), metav1.PatchOptions{}); err != nil {
+		return false, fmt.Errorf("resizePodInPlace: failed to label pod %s with revision %s for cluster %s/%s, error: %s", pod.Name, updateRevision, ns, tc.GetName(), err)
+	}
+	klog.Infof("tidbcluster: [%s/%s] pod %s is resized in place to revision %s", ns, tc.GetName(), pod.Name, updateRevision)
+	deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "ResizePodInPlace", "pod %s is resized in place, requests: %v, limits: %v",
+		pod.Name, resources.Requests, resources.Limits)
+	return true, nil
+}
+
+// getRevisionTemplate returns the Pod template of a revision of the StatefulSet, or nil if the revision is not found.
+// The revision is the name of the ControllerRevision, as recorded in the status of the StatefulSet and the
+// controller-revision-hash label of the Pods.
+func getRevisionTemplate(deps *controller.Dependencies, set *apps.StatefulSet, revision string) (*corev1.PodTemplateSpec, error) {
+	if revision == "" {
+		return nil, nil
+	}
+	cr, err := deps.ControllerRevisionLister.ControllerRevisions(set.Namespace).Get(revision)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getRevisionTemplate: failed to get controller revision %s/%s, error: %s", set.Namespace, revision, err)
+	}
+	// the data of the revision is a patch replacing the Pod template of the StatefulSet
+	revisionSet := &apps.StatefulSet{}
+	if err := json.Unmarshal(cr.Data.Raw, revisionSet); err != nil {
+		return nil, fmt.Errorf("getRevisionTemplate: failed to decode controller revision %s/%s, error: %s", set.Namespace, revision, err)
+	}
+	return &revisionSet.Spec.Template, nil
+}
+
+// resizableResources returns the new resources of the container if the Pod templates differ only in the CPU and
+// memory of the container, and they can be resized in place
+func resizableResources(oldTemplate, newTemplate *corev1.PodTemplateSpec, containerName string, policies []v1alpha1.ContainerResizePolicy) (*corev1.ResourceRequirements, bool) {
+	var oldContainer, newContainer *corev1.Container
+	for i := range oldTemplate.Spec.Containers {
+		if oldTemplate.Spec.Containers[i].Name == containerName {
+			oldContainer = &oldTemplate.Spec.Containers[i]
+		}
+	}
+	for i := range newTemplate.Spec.Containers {
+		if newTemplate.Spec.Containers[i].Name == containerName {
+			newContainer = &newTemplate.Spec.Containers[i]
+		}
+	}
+	if oldContainer == nil || newContainer == nil || apiequality.Semantic.DeepEqual(oldContainer.Resources, newContainer.Resources) {
+		return nil, false
+	}
+
+	// nothing else is changed
+	template := newTemplate.DeepCopy()
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == containerName {
+			template.Spec.Containers[i].Resources = oldContainer.Resources
+		}
+	}
+	if !apiequality.Semantic.DeepEqual(oldTemplate, template) {
+		return nil, false
+	}
+
+	restartPolicies := map[corev1.ResourceName]v1alpha1.ResourceResizeRestartPolicy{}
+	for _, p := range policies {
+		restartPolicies[p.ResourceName] = p.RestartPolicy
+	}
+	for _, pair := range [][2]corev1.ResourceList{
+		{oldContainer.Resources.Requests, newContainer.Resources.Requests},
+		{oldContainer.Resources.Limits, newContainer.Resources.Limits},
+	} {
+		oldList, newList := pair[0], pair[1]
+		// the resources can't be added or removed, which may change the QoS class of the Pod
+		if len(oldList) != len(newList) {
+			return nil, false
+		}
+		for name, oldQuantity := range oldList {
+			newQuantity, ok := newList[name]
+			if !ok {
+				return nil, false
+			}
+			if oldQuantity.Cmp(newQuantity) == 0 {
+				continue
+			}
+			if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+				return nil, false
+			}
+			if restartPolicies[name] == v1alpha1.ResourceResizeRestartContainer {
+				return nil, false
+			}
+		}
+	}
+	// lowering the memory limit may get the container OOM killed
+	oldLimit, newLimit := oldContainer.Resources.Limits.Memory(), newContainer.Resources.Limits.Memory()
+	if newLimit.Cmp(*oldLimit) < 0 {
+		return nil, false
+	}
+	return &newContainer.Resources, true
+}
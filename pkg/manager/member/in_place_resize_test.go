@@ -0,0 +1,173 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+)
+
+func TestResizePodInPlace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newTemplate := func(cpu, memory string) corev1.PodTemplateSpec {
+		resources := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}
+		return corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:      v1alpha1.TiDBMemberType.String(),
+						Image:     "pingcap/tidb:v5.2.1",
+						Resources: corev1.ResourceRequirements{Requests: resources, Limits: resources},
+					},
+					{Name: "slowlog", Image: "busybox:1.26.2"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		featureGates map[string]bool
+		policies     []v1alpha1.ContainerResizePolicy
+		newTemplate  func() corev1.PodTemplateSpec
+		rejected     bool
+		expectResize bool
+	}{
+		{
+			name:         "the feature is disabled",
+			newTemplate:  func() corev1.PodTemplateSpec { return newTemplate("2", "8Gi") },
+			expectResize: false,
+		},
+		{
+			name:         "resize the cpu and memory",
+			featureGates: map[string]bool{features.InPlacePodVerticalScaling: true},
+			newTemplate:  func() corev1.PodTemplateSpec { return newTemplate("2", "8Gi") },
+			expectResize: true,
+		},
+		{
+			name:         "the image is changed too",
+			featureGates: map[string]bool{features.InPlacePodVerticalScaling: true},
+			newTemplate: func() corev1.PodTemplateSpec {
+				template := newTemplate("2", "8Gi")
+				template.Spec.Containers[0].Image = "pingcap/tidb:v5.3.0"
+				return template
+			},
+			expectResize: false,
+		},
+		{
+			name:         "the memory requires the restart",
+			featureGates: map[string]bool{features.InPlacePodVerticalScaling: true},
+			policies: []v1alpha1.ContainerResizePolicy{
+				{ResourceName: corev1.ResourceMemory, RestartPolicy: v1alpha1.ResourceResizeRestartContainer},
+			},
+			newTemplate:  func() corev1.PodTemplateSpec { return newTemplate("2", "8Gi") },
+			expectResize: false,
+		},
+		{
+			name:         "only the cpu is resized",
+			featureGates: map[string]bool{features.InPlacePodVerticalScaling: true},
+			policies: []v1alpha1.ContainerResizePolicy{
+				{ResourceName: corev1.ResourceMemory, RestartPolicy: v1alpha1.ResourceResizeRestartContainer},
+			},
+			newTemplate:  func() corev1.PodTemplateSpec { return newTemplate("2", "4Gi") },
+			expectResize: true,
+		},
+		{
+			name:         "the memory limit is lowered",
+			featureGates: map[string]bool{features.InPlacePodVerticalScaling: true},
+			newTemplate:  func() corev1.PodTemplateSpec { return newTemplate("1", "2Gi") },
+			expectResize: false,
+		},
+		{
+			name:         "the resize is rejected",
+			featureGates: map[string]bool{features.InPlacePodVerticalScaling: true},
+			newTemplate:  func() corev1.PodTemplateSpec { return newTemplate("2", "8Gi") },
+			rejected:     true,
+			expectResize: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		deps := controller.NewFakeDependencies()
+		kubeCli := deps.KubeClientset.(*kubefake.Clientset)
+		tc := newTidbClusterForTiDBUpgrader()
+		tc.Spec.FeatureGates = tt.featureGates
+		tc.Spec.TiDB.ResizePolicy = tt.policies
+
+		set := &apps.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "upgrader-tidb", Namespace: tc.Namespace}}
+		// the revisions are the names of the ControllerRevisions, i.e. the name of the StatefulSet followed by the hash
+		oldRevision, newRevision := set.Name+"-5d8c9f7b6c", set.Name+"-7f9d8c6b5a"
+		for revision, template := range map[string]corev1.PodTemplateSpec{oldRevision: newTemplate("1", "4Gi"), newRevision: tt.newTemplate()} {
+			data, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": template}})
+			g.Expect(err).NotTo(HaveOccurred())
+			cr := &apps.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: revision, Namespace: tc.Namespace},
+				Data:       runtime.RawExtension{Raw: data},
+			}
+			g.Expect(deps.KubeInformerFactory.Apps().V1().ControllerRevisions().Informer().GetIndexer().Add(cr)).To(Succeed())
+		}
+		template := newTemplate("1", "4Gi")
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "upgrader-tidb-0",
+				Namespace: tc.Namespace,
+				Labels:    map[string]string{apps.ControllerRevisionHashLabelKey: oldRevision},
+			},
+			Spec: template.Spec,
+		}
+		_, err := kubeCli.CoreV1().Pods(tc.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		if tt.rejected {
+			kubeCli.PrependReactor("patch", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.NewInvalid(schema.GroupKind{Kind: "Pod"}, pod.Name, field.ErrorList{
+					field.Forbidden(field.NewPath("spec"), "pod updates may not change fields other than ..."),
+				})
+			})
+		}
+
+		resized, err := resizePodInPlace(deps, tc, v1alpha1.TiDBMemberType.String(), tc.Spec.TiDB.ResizePolicy, pod, set, newRevision)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(resized).To(Equal(tt.expectResize))
+		updated, err := kubeCli.CoreV1().Pods(tc.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		if tt.expectResize {
+			g.Expect(updated.Labels[apps.ControllerRevisionHashLabelKey]).To(Equal(newRevision))
+			g.Expect(updated.Spec.Containers[0].Resources).To(Equal(tt.newTemplate().Spec.Containers[0].Resources))
+		} else {
+			g.Expect(updated.Labels[apps.ControllerRevisionHashLabelKey]).To(Equal(oldRevision))
+			g.Expect(updated.Spec.Containers[0].Resources).To(Equal(template.Spec.Containers[0].Resources))
+		}
+	}
+}
@@ -32,10 +32,19 @@ func getNodeLabels(nodeLister corelisterv1.NodeLister, nodeName string, storeLab
 		}
 
 		// TODO after pd supports storeLabel containing slash character, these codes should be deleted
-		if storeLabel == "host" {
+		switch storeLabel {
+		case "host":
 			if host, found := ls[corev1.LabelHostname]; found {
 				labels[storeLabel] = host
 			}
+		case "zone":
+			if zone, found := ls[corev1.LabelZoneFailureDomainStable]; found {
+				labels[storeLabel] = zone
+			}
+		case "region":
+			if region, found := ls[corev1.LabelZoneRegionStable]; found {
+				labels[storeLabel] = region
+			}
 		}
 
 	}
@@ -0,0 +1,345 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	opsCommandContainerName = "ops-command"
+	// maxOpsCommandOutputSize is the max size of the output kept in the status, as the size of an object is limited
+	maxOpsCommandOutputSize         = 32 * 1024
+	defaultOpsCommandActiveDeadline = 300
+)
+
+// OpsCommandManager implements the logic for syncing OpsCommand.
+type OpsCommandManager interface {
+	// Sync implements the logic for syncing OpsCommand.
+	Sync(*v1alpha1.OpsCommand) error
+}
+
+type opsCommandManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewOpsCommandManager returns an OpsCommandManager
+func NewOpsCommandManager(deps *controller.Dependencies) OpsCommandManager {
+	return &opsCommandManager{deps: deps, now: time.Now}
+}
+
+// Sync creates the Job running the command, and records the output of the command once the Job finishes.
+// The command is run only once, a finished OpsCommand is not synced anymore.
+func (m *opsCommandManager) Sync(oc *v1alpha1.OpsCommand) error {
+	if oc.Status.Phase == v1alpha1.OpsCommandSucceeded || oc.Status.Phase == v1alpha1.OpsCommandFailed {
+		return nil
+	}
+
+	status := oc.Status.DeepCopy()
+	if err := m.syncJob(oc, status); err != nil {
+		return err
+	}
+	if apiequality.Semantic.DeepEqual(&oc.Status, status) {
+		return nil
+	}
+	oc = oc.DeepCopy()
+	oc.Status = *status
+	_, err := m.updateOpsCommand(oc)
+	return err
+}
+
+func (m *opsCommandManager) syncJob(oc *v1alpha1.OpsCommand, status *v1alpha1.OpsCommandStatus) error {
+	ns := oc.GetNamespace()
+	name := oc.GetName()
+	fail := func(message string) {
+		status.Phase = v1alpha1.OpsCommandFailed
+		status.Message = message
+		status.CompletionTime = &metav1.Time{Time: m.now()}
+		klog.Warningf("OpsCommand %s/%s failed: %s", ns, name, message)
+	}
+
+	if status.JobName == "" {
+		if errs := validation.ValidateOpsCommand(oc); len(errs) > 0 {
+			fail(errs.ToAggregate().Error())
+			return nil
+		}
+		job, err := m.makeOpsCommandJob(oc)
+		if err != nil {
+			fail(err.Error())
+			return nil
+		}
+		if err := m.deps.JobControl.CreateJob(oc, job); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		status.Phase = v1alpha1.OpsCommandRunning
+		status.JobName = job.Name
+		status.StartTime = &metav1.Time{Time: m.now()}
+		klog.Infof("OpsCommand %s/%s started, job: %s", ns, name, job.Name)
+		return nil
+	}
+
+	job, err := m.deps.JobLister.Jobs(ns).Get(status.JobName)
+	if errors.IsNotFound(err) {
+		fail(fmt.Sprintf("job %s is not found", status.JobName))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get job %s for OpsCommand %s/%s, error: %v", status.JobName, ns, name, err)
+	}
+	cond := getJobFinishedCondition(job)
+	if cond == nil {
+		return nil
+	}
+	status.Output = m.getOutput(oc)
+	if cond.Type == batchv1.JobFailed {
+		fail(cond.Message)
+		return nil
+	}
+	status.Phase = v1alpha1.OpsCommandSucceeded
+	status.Message = ""
+	status.CompletionTime = &metav1.Time{Time: m.now()}
+	klog.Infof("OpsCommand %s/%s succeeded", ns, name)
+	return nil
+}
+
+// getOutput returns the tail of the logs of the pod running the command, or the error if it fails
+func (m *opsCommandManager) getOutput(oc *v1alpha1.OpsCommand) string {
+	ns := oc.GetNamespace()
+	selector, err := label.NewOpsCommand().OpsCommand(oc.GetName()).Selector()
+	if err != nil {
+		return fmt.Sprintf("failed to get the output: %v", err)
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Sprintf("failed to get the output: %v", err)
+	}
+	if len(pods) == 0 {
+		return "failed to get the output: the pod running the command is not found"
+	}
+	// the Job doesn't retry, but take the latest pod in case the pod is recreated
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].CreationTimestamp.After(pods[j].CreationTimestamp.Time)
+	})
+	opts := &corev1.PodLogOptions{Container: opsCommandContainerName}
+	data, err := m.deps.KubeClientset.CoreV1().Pods(ns).GetLogs(pods[0].Name, opts).DoRaw(context.TODO())
+	if err != nil {
+		return fmt.Sprintf("failed to get the output: %v", err)
+	}
+	if len(data) > maxOpsCommandOutputSize {
+		data = data[len(data)-maxOpsCommandOutputSize:]
+	}
+	return string(data)
+}
+
+// makeOpsCommandJob returns the Job running the command, with the endpoints and the TLS flags of the cluster set
+func (m *opsCommandManager) makeOpsCommandJob(oc *v1alpha1.OpsCommand) (*batchv1.Job, error) {
+	ns := oc.GetNamespace()
+	clusterName := oc.Spec.Cluster
+
+	container := corev1.Container{
+		Name:            opsCommandContainerName,
+		ImagePullPolicy: oc.Spec.ImagePullPolicy,
+	}
+	var vols []corev1.Volume
+	clientTLS := func(secretName string) []string {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name: util.ClusterClientVolName, ReadOnly: true, MountPath: util.ClusterClientTLSPath,
+		})
+		vols = append(vols, corev1.Volume{
+			Name: util.ClusterClientVolName, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretName,
+				},
+			},
+		})
+		return []string{
+			path.Join(util.ClusterClientTLSPath, corev1.ServiceAccountRootCAKey),
+			path.Join(util.ClusterClientTLSPath, corev1.TLSCertKey),
+			path.Join(util.ClusterClientTLSPath, corev1.TLSPrivateKeyKey),
+		}
+	}
+
+	var imagePullSecrets []corev1.LocalObjectReference
+	switch oc.Spec.Type {
+	case v1alpha1.OpsCommandTypePDCtl, v1alpha1.OpsCommandTypeBRDebug:
+		tc, err := m.deps.TiDBClusterLister.TidbClusters(ns).Get(clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tidbcluster %s, error: %v", clusterName, err)
+		}
+		if tc.Spec.PD == nil {
+			return nil, fmt.Errorf("no PD in tidbcluster %s", clusterName)
+		}
+		imagePullSecrets = tc.Spec.ImagePullSecrets
+		if oc.Spec.Type == v1alpha1.OpsCommandTypePDCtl {
+			args := []string{"/pd-ctl", "-u", fmt.Sprintf("%s://%s:%d", tc.Scheme(), controller.PDMemberName(clusterName), tc.PDClientPort())}
+			if tc.IsTLSClusterEnabled() {
+				paths := clientTLS(util.ClusterClientTLSSecretName(clusterName))
+				args = append(args, "--cacert", paths[0], "--cert", paths[1], "--key", paths[2])
+			}
+			container.Image = tc.PDImage()
+			container.Command = append(args, oc.Spec.Args...)
+			break
+		}
+		args := append([]string{"/br", "debug"}, oc.Spec.Args...)
+		args = append(args, "--pd", fmt.Sprintf("%s:%d", controller.PDMemberName(clusterName), tc.PDClientPort()))
+		if tc.IsTLSClusterEnabled() {
+			paths := clientTLS(util.ClusterClientTLSSecretName(clusterName))
+			args = append(args, "--ca", paths[0], "--cert", paths[1], "--key", paths[2])
+		}
+		container.Image = opsCommandBRImage(tc)
+		container.Command = args
+	case v1alpha1.OpsCommandTypeDMCtl:
+		dc, err := m.deps.DMClusterLister.DMClusters(ns).Get(clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dmcluster %s, error: %v", clusterName, err)
+		}
+		imagePullSecrets = dc.Spec.ImagePullSecrets
+		args := []string{"/dmctl", "--master-addr", fmt.Sprintf("%s:%d", controller.DMMasterMemberName(clusterName), dc.MasterPort())}
+		if dc.IsTLSClusterEnabled() {
+			paths := clientTLS(util.DMClientTLSSecretName(clusterName))
+			args = append(args, "--ssl-ca", paths[0], "--ssl-cert", paths[1], "--ssl-key", paths[2])
+		}
+		container.Image = dc.MasterImage()
+		container.Command = append(args, oc.Spec.Args...)
+	default:
+		return nil, fmt.Errorf("unsupported type %s of OpsCommand", oc.Spec.Type)
+	}
+	if oc.Spec.Resources != nil {
+		container.Resources = *oc.Spec.Resources
+	}
+	activeDeadline := int64(defaultOpsCommandActiveDeadline)
+	if oc.Spec.ActiveDeadlineSeconds != nil {
+		activeDeadline = *oc.Spec.ActiveDeadlineSeconds
+	}
+
+	jobLabel := label.NewOpsCommand().Instance(clusterName).OpsCommand(oc.GetName())
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%s", oc.GetName(), label.OpsCommandJobLabelVal),
+			Namespace:       ns,
+			Labels:          jobLabel,
+			OwnerReferences: []metav1.OwnerReference{controller.GetOpsCommandOwnerRef(oc)},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          pointer.Int32Ptr(0),
+			ActiveDeadlineSeconds: pointer.Int64Ptr(activeDeadline),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabel,
+				},
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: imagePullSecrets,
+					Containers:       []corev1.Container{container},
+					RestartPolicy:    corev1.RestartPolicyNever,
+					Volumes:          vols,
+				},
+			},
+		},
+	}, nil
+}
+
+// opsCommandBRImage returns the BR image with the TiKV version of the cluster. The BR image is in the same
+// repository as the TiKV image if it's the tikv image, e.g. a private registry, otherwise it's `pingcap/br` with
+// the registry prefix of the cluster, the same as the BR image of the backups.
+func opsCommandBRImage(tc *v1alpha1.TidbCluster) string {
+	image := tc.TiKVImage()
+	if i := strings.IndexByte(image, '@'); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndexByte(image, ':'); i > strings.LastIndexByte(image, '/') {
+		image = image[:i]
+	}
+	if path.Base(image) == "tikv" {
+		return fmt.Sprintf("%s:%s", path.Join(path.Dir(image), "br"), tc.TiKVVersion())
+	}
+	return v1alpha1.ImageWithRegistryPrefix(tc.Spec.ClusterRegistryPrefix, "pingcap/br:"+tc.TiKVVersion())
+}
+
+func (m *opsCommandManager) updateOpsCommand(oc *v1alpha1.OpsCommand) (*v1alpha1.OpsCommand, error) {
+	ns := oc.GetNamespace()
+	ocName := oc.GetName()
+
+	status := oc.Status.DeepCopy()
+	var update *v1alpha1.OpsCommand
+
+	// don't wait due to limited number of clients, but backoff after the default number of steps
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var updateErr error
+		update, updateErr = m.deps.Clientset.PingcapV1alpha1().OpsCommands(ns).Update(context.TODO(), oc, metav1.UpdateOptions{})
+		if updateErr == nil {
+			klog.Infof("OpsCommand: [%s/%s] updated successfully", ns, ocName)
+			return nil
+		}
+		klog.V(4).Infof("failed to update OpsCommand: [%s/%s], error: %v", ns, ocName, updateErr)
+
+		if updated, err := m.deps.OpsCommandLister.OpsCommands(ns).Get(ocName); err == nil {
+			// make a copy so we don't mutate the shared cache
+			oc = updated.DeepCopy()
+			oc.Status = *status
+		} else {
+			utilruntime.HandleError(fmt.Errorf("error getting updated OpsCommand %s/%s from lister: %v", ns, ocName, err))
+		}
+
+		return updateErr
+	})
+	if err != nil {
+		klog.Errorf("failed to update OpsCommand: [%s/%s], error: %v", ns, ocName, err)
+	}
+	return update, err
+}
+
+var _ OpsCommandManager = &opsCommandManager{}
+
+// FakeOpsCommandManager is a fake OpsCommandManager
+type FakeOpsCommandManager struct {
+	err error
+}
+
+// NewFakeOpsCommandManager returns a FakeOpsCommandManager
+func NewFakeOpsCommandManager() *FakeOpsCommandManager {
+	return &FakeOpsCommandManager{}
+}
+
+// SetSyncError sets the error returned by Sync
+func (m *FakeOpsCommandManager) SetSyncError(err error) {
+	m.err = err
+}
+
+// Sync fake Sync
+func (m *FakeOpsCommandManager) Sync(_ *v1alpha1.OpsCommand) error {
+	return m.err
+}
+
+var _ OpsCommandManager = &FakeOpsCommandManager{}
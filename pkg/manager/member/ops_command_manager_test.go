@@ -0,0 +1,235 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+)
+
+func TestOpsCommandManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2021, 10, 10, 2, 30, 0, 0, time.UTC)
+	newOpsCommand := func() *v1alpha1.OpsCommand {
+		return &v1alpha1.OpsCommand{
+			ObjectMeta: metav1.ObjectMeta{Name: "stores", Namespace: corev1.NamespaceDefault},
+			Spec: v1alpha1.OpsCommandSpec{
+				Type:    v1alpha1.OpsCommandTypePDCtl,
+				Cluster: "test",
+				Args:    []string{"store"},
+			},
+		}
+	}
+	running := func(oc *v1alpha1.OpsCommand) {
+		oc.Status = v1alpha1.OpsCommandStatus{
+			Phase:     v1alpha1.OpsCommandRunning,
+			JobName:   "stores-ops-command",
+			StartTime: &metav1.Time{Time: now.Add(-time.Minute)},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		update func(oc *v1alpha1.OpsCommand)
+		job    func(job *batchv1.Job)
+		expect func(status *v1alpha1.OpsCommandStatus, jobs []*batchv1.Job)
+	}{
+		{
+			name: "create the job",
+			expect: func(status *v1alpha1.OpsCommandStatus, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(HaveLen(1))
+				g.Expect(status.Phase).To(Equal(v1alpha1.OpsCommandRunning))
+				g.Expect(status.JobName).To(Equal(jobs[0].Name))
+				g.Expect(status.StartTime.Time).To(Equal(now))
+				g.Expect(jobs[0].Labels[label.OpsCommandLabelKey]).To(Equal("stores"))
+			},
+		},
+		{
+			name: "the command is not allowed",
+			update: func(oc *v1alpha1.OpsCommand) {
+				oc.Spec.Args = []string{"unsafe", "remove-failed-stores", "1"}
+			},
+			expect: func(status *v1alpha1.OpsCommandStatus, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(BeEmpty())
+				g.Expect(status.Phase).To(Equal(v1alpha1.OpsCommandFailed))
+				g.Expect(status.Message).To(ContainSubstring("unsafe remove-failed-stores 1"))
+			},
+		},
+		{
+			name: "the cluster is not found",
+			update: func(oc *v1alpha1.OpsCommand) {
+				oc.Spec.Cluster = "not-exist"
+			},
+			expect: func(status *v1alpha1.OpsCommandStatus, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(BeEmpty())
+				g.Expect(status.Phase).To(Equal(v1alpha1.OpsCommandFailed))
+				g.Expect(status.Message).To(ContainSubstring("failed to get tidbcluster not-exist"))
+			},
+		},
+		{
+			name:   "the job is still running",
+			update: running,
+			job:    func(job *batchv1.Job) {},
+			expect: func(status *v1alpha1.OpsCommandStatus, jobs []*batchv1.Job) {
+				g.Expect(status.Phase).To(Equal(v1alpha1.OpsCommandRunning))
+				g.Expect(status.Output).To(BeEmpty())
+			},
+		},
+		{
+			name:   "the job succeeded",
+			update: running,
+			job: func(job *batchv1.Job) {
+				job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+			},
+			expect: func(status *v1alpha1.OpsCommandStatus, jobs []*batchv1.Job) {
+				g.Expect(status.Phase).To(Equal(v1alpha1.OpsCommandSucceeded))
+				g.Expect(status.Output).To(Equal("fake logs"))
+				g.Expect(status.CompletionTime.Time).To(Equal(now))
+			},
+		},
+		{
+			name:   "the job failed",
+			update: running,
+			job: func(job *batchv1.Job) {
+				job.Status.Conditions = []batchv1.JobCondition{
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "Job was active longer than specified deadline"},
+				}
+			},
+			expect: func(status *v1alpha1.OpsCommandStatus, jobs []*batchv1.Job) {
+				g.Expect(status.Phase).To(Equal(v1alpha1.OpsCommandFailed))
+				g.Expect(status.Message).To(Equal("Job was active longer than specified deadline"))
+				g.Expect(status.Output).To(Equal("fake logs"))
+			},
+		},
+		{
+			name:   "the job is not found",
+			update: running,
+			expect: func(status *v1alpha1.OpsCommandStatus, jobs []*batchv1.Job) {
+				g.Expect(status.Phase).To(Equal(v1alpha1.OpsCommandFailed))
+				g.Expect(status.Message).To(Equal("job stores-ops-command is not found"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		deps := controller.NewFakeDependencies()
+		m := NewOpsCommandManager(deps).(*opsCommandManager)
+		m.now = func() time.Time { return now }
+
+		tc := newTidbClusterForPD()
+		g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+		oc := newOpsCommand()
+		if tt.update != nil {
+			tt.update(oc)
+		}
+		_, err := deps.Clientset.PingcapV1alpha1().OpsCommands(oc.Namespace).Create(context.TODO(), oc, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		if tt.job != nil {
+			job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: oc.Namespace, Name: "stores-ops-command"}}
+			tt.job(job)
+			g.Expect(deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer().Add(job)).To(Succeed())
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Namespace: oc.Namespace,
+				Name:      "stores-ops-command-abcde",
+				Labels:    label.NewOpsCommand().OpsCommand(oc.Name),
+			}}
+			g.Expect(deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+		}
+
+		g.Expect(m.Sync(oc)).To(Succeed())
+		updated, err := deps.Clientset.PingcapV1alpha1().OpsCommands(oc.Namespace).Get(context.TODO(), oc.Name, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		jobs, err := deps.JobLister.List(labels.Everything())
+		g.Expect(err).NotTo(HaveOccurred())
+		tt.expect(&updated.Status, jobs)
+	}
+}
+
+func TestMakeOpsCommandJob(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewOpsCommandManager(deps).(*opsCommandManager)
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV = &v1alpha1.TiKVSpec{BaseImage: "pingcap/tikv"}
+	tc.Spec.Version = "v5.2.1"
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+	dc := newDMClusterForMaster()
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().DMClusters().Informer().GetIndexer().Add(dc)).To(Succeed())
+
+	oc := &v1alpha1.OpsCommand{
+		ObjectMeta: metav1.ObjectMeta{Name: "stores", Namespace: corev1.NamespaceDefault},
+		Spec: v1alpha1.OpsCommandSpec{
+			Type:    v1alpha1.OpsCommandTypePDCtl,
+			Cluster: "test",
+			Args:    []string{"store", "limit"},
+		},
+	}
+	job, err := m.makeOpsCommandJob(oc)
+	g.Expect(err).NotTo(HaveOccurred())
+	container := job.Spec.Template.Spec.Containers[0]
+	g.Expect(container.Image).To(Equal(tc.PDImage()))
+	g.Expect(container.Command).To(Equal([]string{"/pd-ctl", "-u", "https://test-pd:2379",
+		"--cacert", "/var/lib/cluster-client-tls/ca.crt", "--cert", "/var/lib/cluster-client-tls/tls.crt", "--key", "/var/lib/cluster-client-tls/tls.key",
+		"store", "limit"}))
+	g.Expect(job.Spec.Template.Spec.Volumes[0].Secret.SecretName).To(Equal("test-cluster-client-secret"))
+	g.Expect(*job.Spec.BackoffLimit).To(BeEquivalentTo(0))
+	g.Expect(*job.Spec.ActiveDeadlineSeconds).To(BeEquivalentTo(defaultOpsCommandActiveDeadline))
+	g.Expect(job.OwnerReferences[0].Kind).To(Equal("OpsCommand"))
+
+	oc.Spec.Type = v1alpha1.OpsCommandTypeBRDebug
+	oc.Spec.Args = []string{"checksum", "-s", "s3://backup/prefix"}
+	oc.Spec.ActiveDeadlineSeconds = pointer.Int64Ptr(60)
+	job, err = m.makeOpsCommandJob(oc)
+	g.Expect(err).NotTo(HaveOccurred())
+	container = job.Spec.Template.Spec.Containers[0]
+	g.Expect(container.Image).To(Equal("pingcap/br:v5.2.1"))
+	g.Expect(container.Command[:6]).To(Equal([]string{"/br", "debug", "checksum", "-s", "s3://backup/prefix", "--pd"}))
+	g.Expect(container.Command).To(ContainElement("test-pd:2379"))
+	g.Expect(container.Command).To(ContainElement("--ca"))
+	g.Expect(*job.Spec.ActiveDeadlineSeconds).To(BeEquivalentTo(60))
+
+	// the BR image is in the registry of the TiKV image
+	tc.Spec.ClusterRegistryPrefix = "registry.example.com"
+	job, err = m.makeOpsCommandJob(oc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("registry.example.com/pingcap/br:v5.2.1"))
+	tc.Spec.TiKV.BaseImage = "registry.example.com/custom-tikv"
+	job, err = m.makeOpsCommandJob(oc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("registry.example.com/pingcap/br:v5.2.1"))
+
+	oc.Spec.Type = v1alpha1.OpsCommandTypeDMCtl
+	oc.Spec.Args = []string{"query-status"}
+	job, err = m.makeOpsCommandJob(oc)
+	g.Expect(err).NotTo(HaveOccurred())
+	container = job.Spec.Template.Spec.Containers[0]
+	g.Expect(container.Image).To(Equal(dc.MasterImage()))
+	g.Expect(container.Command).To(Equal([]string{"/dmctl", "--master-addr", "test-dm-master:8261", "query-status"}))
+	g.Expect(job.Spec.Template.Spec.Volumes).To(BeEmpty())
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// evictLeaderSchedulerAuditInterval is the interval of the audits of the evict-leader schedulers
+	evictLeaderSchedulerAuditInterval = 5 * time.Minute
+	evictLeaderSchedulerPrefix        = "evict-leader-scheduler-"
+)
+
+// syncEvictLeaderSchedulers audits the evict-leader schedulers of the TiKV stores in PD at most once per audit
+// interval, and removes the orphaned ones found in two consecutive audits. The schedulers are created to evict
+// the region leaders before the stores are upgraded or restarted, and they may be leaked, e.g. the operator is
+// restarted in the middle, which keeps the leaders off the stores and throttles the cluster. Only the schedulers
+// of the stores of this cluster are audited, as the PD may be shared by other clusters.
+func (m *pdMemberManager) syncEvictLeaderSchedulers(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.Paused || tc.Spec.TiKV == nil || !tc.Status.PD.Synced || !tc.Status.TiKV.Synced {
+		return nil
+	}
+	audit := tc.Status.PD.EvictLeaderSchedulerAudit
+	if audit != nil && time.Since(audit.LastAuditTime.Time) < evictLeaderSchedulerAuditInterval {
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	schedulers, err := pdClient.GetEvictLeaderSchedulers()
+	if err != nil {
+		return fmt.Errorf("syncEvictLeaderSchedulers: failed to get evict-leader schedulers for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	stores, inUse, err := m.getEvictLeaderStoresInUse(tc)
+	if err != nil {
+		return err
+	}
+	lastOrphaned := sets.NewString()
+	if audit != nil {
+		lastOrphaned.Insert(audit.OrphanedStores...)
+	}
+
+	var orphaned []string
+	for _, scheduler := range schedulers {
+		storeID := strings.TrimPrefix(scheduler, evictLeaderSchedulerPrefix)
+		podName, ok := stores[storeID]
+		if !ok || inUse.Has(storeID) {
+			continue
+		}
+		if !lastOrphaned.Has(storeID) {
+			orphaned = append(orphaned, storeID)
+			continue
+		}
+		id, err := strconv.ParseUint(storeID, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := pdClient.EndEvictLeader(id); err != nil {
+			return fmt.Errorf("syncEvictLeaderSchedulers: failed to remove the evict-leader scheduler of store %s for cluster %s/%s, error: %s", storeID, ns, tcName, err)
+		}
+		klog.Infof("tidbcluster: [%s/%s] remove the orphaned evict-leader scheduler of store %s of pod %s", ns, tcName, storeID, podName)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "RemoveEvictLeaderScheduler",
+			"remove the orphaned evict-leader scheduler of store %s of pod %s", storeID, podName)
+	}
+	sort.Strings(orphaned)
+	tc.Status.PD.EvictLeaderSchedulerAudit = &v1alpha1.EvictLeaderSchedulerAudit{
+		LastAuditTime:  metav1.Now(),
+		OrphanedStores: orphaned,
+	}
+	return nil
+}
+
+// getEvictLeaderStoresInUse returns the pod names of the stores of the TiKV groups keyed by store ID, and the IDs of
+// the stores whose evict-leader schedulers may be in use, that is the stores being upgraded, restarted or evicted
+// on request, and the stores not up or whose Pods are not ready.
+func (m *pdMemberManager) getEvictLeaderStoresInUse(tc *v1alpha1.TidbCluster) (map[string]string, sets.String, error) {
+	ns := tc.GetNamespace()
+	stores := map[string]string{}
+	inUse := sets.NewString()
+	for _, op := range tc.Status.InFlightOperations {
+		if op.StoreID != "" {
+			inUse.Insert(op.StoreID)
+		}
+	}
+
+	for _, status := range []*v1alpha1.TiKVStatus{&tc.Status.TiKV, &tc.Status.TiKVCold} {
+		for id, store := range status.TombstoneStores {
+			stores[id] = store.PodName
+		}
+		for id, store := range status.Stores {
+			stores[id] = store.PodName
+			if store.State != v1alpha1.TiKVStateUp {
+				inUse.Insert(id)
+				continue
+			}
+			if _, ok := status.EvictLeader[store.PodName]; ok {
+				inUse.Insert(id)
+				continue
+			}
+			pod, err := m.deps.PodLister.Pods(ns).Get(store.PodName)
+			if errors.IsNotFound(err) {
+				inUse.Insert(id)
+				continue
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("getEvictLeaderStoresInUse: failed to get pod %s/%s, error: %s", ns, store.PodName, err)
+			}
+			_, evicting := pod.Annotations[EvictLeaderBeginTime]
+			_, webhookEvicting := pod.Annotations[label.AnnEvictLeaderBeginTime]
+			_, requested := pod.Annotations[v1alpha1.EvictLeaderAnnKey]
+			upgrading := status.Phase == v1alpha1.UpgradePhase && status.StatefulSet != nil &&
+				pod.Labels[apps.ControllerRevisionHashLabelKey] != status.StatefulSet.UpdateRevision
+			if evicting || webhookEvicting || requested || upgrading || !podutil.IsPodReady(pod) {
+				inUse.Insert(id)
+			}
+		}
+	}
+	return stores, inUse, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPDMemberManagerSyncEvictLeaderSchedulers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pmm, podIndexer, _ := newFakePDMemberManager()
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV = &v1alpha1.TiKVSpec{}
+	tc.Status.PD.Synced = true
+	tc.Status.TiKV.Synced = true
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{}
+	for i, id := range []string{"1", "2", "3", "4"} {
+		podName := TikvPodName(tc.Name, int32(i))
+		tc.Status.TiKV.Stores[id] = v1alpha1.TiKVStore{ID: id, PodName: podName, State: v1alpha1.TiKVStateUp}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: tc.Namespace},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+		if id == "2" {
+			// being upgraded
+			pod.Annotations = map[string]string{EvictLeaderBeginTime: time.Now().Format(time.RFC3339)}
+		}
+		g.Expect(podIndexer.Add(pod)).To(Succeed())
+	}
+	// being restarted
+	tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+		Type:      v1alpha1.InFlightOperationRestart,
+		Component: v1alpha1.TiKVMemberType,
+		PodName:   TikvPodName(tc.Name, 2),
+		StoreID:   "3",
+	})
+
+	pdClient := controller.NewFakePDClient(pmm.deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetEvictLeaderSchedulersActionType, func(action *pdapi.Action) (interface{}, error) {
+		// store 10 is not of this cluster
+		return []string{"evict-leader-scheduler-1", "evict-leader-scheduler-2", "evict-leader-scheduler-3", "evict-leader-scheduler-10"}, nil
+	})
+	var removed []uint64
+	pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		removed = append(removed, action.ID)
+		return nil, nil
+	})
+
+	// the orphaned scheduler is not removed in the first audit
+	g.Expect(pmm.syncEvictLeaderSchedulers(tc)).To(Succeed())
+	g.Expect(removed).To(BeEmpty())
+	g.Expect(tc.Status.PD.EvictLeaderSchedulerAudit.OrphanedStores).To(Equal([]string{"1"}))
+
+	// no audit within the interval
+	g.Expect(pmm.syncEvictLeaderSchedulers(tc)).To(Succeed())
+	g.Expect(removed).To(BeEmpty())
+
+	// removed if still orphaned in the next audit
+	tc.Status.PD.EvictLeaderSchedulerAudit.LastAuditTime = metav1.NewTime(time.Now().Add(-evictLeaderSchedulerAuditInterval))
+	g.Expect(pmm.syncEvictLeaderSchedulers(tc)).To(Succeed())
+	g.Expect(removed).To(Equal([]uint64{1}))
+	g.Expect(tc.Status.PD.EvictLeaderSchedulerAudit.OrphanedStores).To(BeEmpty())
+}
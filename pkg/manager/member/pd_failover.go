@@ -107,6 +107,9 @@ func (f *pdFailover) tryToMarkAPeerAsFailure(tc *v1alpha1.TidbCluster) error {
 		if pdMember.Health || time.Now().Before(failoverDeadline) || exist {
 			continue
 		}
+		if isPodOnCordonedNode(f.deps, tc, podName) {
+			continue
+		}
 
 		pod, err := f.deps.PodLister.Pods(ns).Get(podName)
 		if err != nil {
@@ -0,0 +1,76 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// syncPDLeaderPriority sets the leader priority of the PD members according to the zones of their nodes, so the
+// PD leader prefers the zones of higher priorities. The members whose Pods are not scheduled yet are skipped,
+// and their priorities are set once the Pods are scheduled.
+func syncPDLeaderPriority(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
+	priorities := tc.Spec.PD.LeaderPriorityByZone
+	if len(priorities) == 0 || set == nil || !tc.Status.PD.Synced {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	if deps.NodeLister == nil {
+		klog.Warningf("tidbcluster: [%s/%s] the nodes can't be read, skip setting the leader priority of pd", ns, tcName)
+		return nil
+	}
+
+	pdClient := controller.GetPDClient(deps.PDControl, tc)
+	membersInfo, err := pdClient.GetMembers()
+	if err != nil {
+		return fmt.Errorf("tidbcluster: [%s/%s] failed to get pd members, error: %v", ns, tcName, err)
+	}
+	current := map[string]int32{}
+	for _, member := range membersInfo.Members {
+		current[member.GetName()] = member.GetLeaderPriority()
+	}
+
+	for _, ordinal := range helper.GetPodOrdinals(*set.Spec.Replicas, set).List() {
+		name := PdName(tcName, ordinal, ns, tc.Spec.ClusterDomain)
+		priority, exist := current[name]
+		if !exist {
+			continue
+		}
+		pod, err := deps.PodLister.Pods(ns).Get(PdPodName(tcName, ordinal))
+		if err != nil || pod.Spec.NodeName == "" {
+			continue
+		}
+		node, err := deps.NodeLister.Get(pod.Spec.NodeName)
+		if err != nil {
+			continue
+		}
+		desired := priorities[node.Labels[corev1.LabelZoneFailureDomainStable]]
+		if priority == desired {
+			continue
+		}
+		if err := pdClient.SetMemberLeaderPriority(name, desired); err != nil {
+			return fmt.Errorf("tidbcluster: [%s/%s] failed to set the leader priority of pd member %s to %d, error: %v", ns, tcName, name, desired, err)
+		}
+		klog.Infof("tidbcluster: [%s/%s] set the leader priority of pd member %s to %d", ns, tcName, name, desired)
+	}
+	return nil
+}
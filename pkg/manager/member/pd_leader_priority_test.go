@@ -0,0 +1,78 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestSyncPDLeaderPriority(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForPD()
+	tc.Status.PD.Synced = true
+	ns := tc.GetNamespace()
+	set := &apps.StatefulSet{Spec: apps.StatefulSetSpec{Replicas: pointer.Int32Ptr(3)}}
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetMembersActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.MembersInfo{Members: []*pdpb.Member{
+			{Name: PdPodName(tc.Name, 0), LeaderPriority: 0},
+			{Name: PdPodName(tc.Name, 1), LeaderPriority: 1},
+			{Name: PdPodName(tc.Name, 2), LeaderPriority: 1},
+		}}, nil
+	})
+	priorities := map[string]int32{}
+	pdClient.AddReaction(pdapi.SetMemberLeaderPriorityActionType, func(action *pdapi.Action) (interface{}, error) {
+		priorities[action.Name] = action.LeaderPriority
+		return nil, nil
+	})
+
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	nodeIndexer := deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	for ordinal, zone := range []string{"zone-a", "zone-b", "zone-c"} {
+		nodeName := "node-" + zone
+		g.Expect(nodeIndexer.Add(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName, Labels: map[string]string{corev1.LabelZoneFailureDomainStable: zone}},
+		})).To(Succeed())
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: PdPodName(tc.Name, int32(ordinal)), Namespace: ns}}
+		// the pod of ordinal 2 is not scheduled yet
+		if ordinal != 2 {
+			pod.Spec.NodeName = nodeName
+		}
+		g.Expect(podIndexer.Add(pod)).To(Succeed())
+	}
+
+	// nothing is done if the priorities are not set
+	g.Expect(syncPDLeaderPriority(deps, tc, set)).To(Succeed())
+	g.Expect(priorities).To(BeEmpty())
+
+	tc.Spec.PD.LeaderPriorityByZone = map[string]int32{"zone-a": 2, "zone-c": 1}
+	g.Expect(syncPDLeaderPriority(deps, tc, set)).To(Succeed())
+	g.Expect(priorities).To(Equal(map[string]int32{
+		PdPodName(tc.Name, 0): 2,
+		// the zone not listed gets priority 0
+		PdPodName(tc.Name, 1): 0,
+	}))
+}
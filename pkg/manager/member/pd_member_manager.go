@@ -87,7 +87,12 @@ func (m *pdMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	}
 
 	// Sync PD StatefulSet
-	return m.syncPDStatefulSetForTidbCluster(tc)
+	if err := m.syncPDStatefulSetForTidbCluster(tc); err != nil {
+		return err
+	}
+
+	// Remove the evict-leader schedulers leaked by the upgrades and restarts of TiKV
+	return m.syncEvictLeaderSchedulers(tc)
 }
 
 func (m *pdMemberManager) syncPDServiceForTidbCluster(tc *v1alpha1.TidbCluster) error {
@@ -204,6 +209,13 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return err
 	}
 	if setNotExist {
+		if tenantPolicyBlocksCreation(tc) {
+			klog.Infof("TidbCluster: %s/%s, the tenant policy is violated, skip creating the pd statefulset", tc.GetNamespace(), tc.GetName())
+			return nil
+		}
+		if err := syncStorageProvisioning(m.deps, tc, v1alpha1.PDMemberType, newPDSet); err != nil {
+			return err
+		}
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newPDSet)
 		if err != nil {
 			return err
@@ -223,6 +235,10 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd needs force upgrade, %v", ns, tcName, errSTS)
 	}
 
+	if err := syncScaleOutResources(m.deps, tc, v1alpha1.PDMemberType, oldPDSet, newPDSet); err != nil {
+		return err
+	}
+
 	// Scaling takes precedence over upgrading because:
 	// - if a pd fails in the upgrading, users may want to delete it or add
 	//   new replicas
@@ -242,12 +258,22 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		}
 	}
 
+	// the leader priority is best effort and doesn't block the upgrade
+	if err := syncPDLeaderPriority(m.deps, tc, oldPDSet); err != nil {
+		klog.Errorf("failed to sync the leader priority of TidbCluster: [%s/%s]'s pd, error: %v", ns, tcName, err)
+	}
+
+	syncTimezoneChange(m.deps, tc, v1alpha1.PDMemberType, oldPDSet, newPDSet)
+
 	if !templateEqual(newPDSet, oldPDSet) || tc.Status.PD.Phase == v1alpha1.UpgradePhase {
 		if err := m.upgrader.Upgrade(tc, oldPDSet, newPDSet); err != nil {
 			return err
 		}
 	}
 
+	if versionSkewUpgradeRequested(tc, v1alpha1.PDMemberType, oldPDSet) {
+		return mngerutils.ForceUpdateStatefulSet(m.deps.StatefulSetControl, tc, newPDSet, oldPDSet)
+	}
 	return mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, tc, newPDSet, oldPDSet)
 }
 
@@ -297,6 +323,7 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 	tcName := tc.GetName()
 
 	tc.Status.PD.StatefulSet = &set.Status
+	tc.Status.PD.StartScriptVersion = getStartScriptVersion(set)
 
 	upgrading, err := m.pdStatefulSetIsUpgrading(set, tc)
 	if err != nil {
@@ -311,6 +338,9 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 	} else {
 		tc.Status.PD.Phase = v1alpha1.NormalPhase
 	}
+	if tc.Status.PD.Phase != v1alpha1.UpgradePhase {
+		clearRollingUpdateProgress(&tc.Status.PD.RollingUpdate, &tc.Status.PD.Conditions)
+	}
 
 	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
 
@@ -374,6 +404,8 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 			if exist && status.Health == oldPDMember.Health {
 				status.LastTransitionTime = oldPDMember.LastTransitionTime
 			}
+			// the name of the member is the FQDN of the Pod if the cluster domain is set
+			status.VolumeTopology = getVolumeTopology(m.deps, ns, strings.Split(name, ".")[0])
 			pdStatus[name] = status
 		} else {
 			oldPDMember, exist := tc.Status.PD.PeerMembers[name]
@@ -405,11 +437,15 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 // syncPDConfigMap syncs the configmap of PD
 func (m *pdMemberManager) syncPDConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
 
-	// For backward compatibility, only sync tidb configmap when .pd.config is non-nil
-	if tc.Spec.PD.Config == nil {
+	// For backward compatibility, only sync tidb configmap when .pd.config or .pd.configMapRef is non-nil
+	if tc.Spec.PD.Config == nil && tc.Spec.PD.ConfigMapRef == nil {
 		return nil, nil
 	}
-	newCm, err := getPDConfigMap(tc)
+	cfgTC, err := withPDConfigMapRef(m.deps.UserConfigMapLister, tc)
+	if err != nil {
+		return nil, err
+	}
+	newCm, err := getPDConfigMap(cfgTC)
 	if err != nil {
 		return nil, err
 	}
@@ -448,8 +484,8 @@ func (m *pdMemberManager) getNewPDServiceForTidbCluster(tc *v1alpha1.TidbCluster
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "client",
-					Port:       2379,
-					TargetPort: intstr.FromInt(2379),
+					Port:       tc.PDClientPort(),
+					TargetPort: intstr.FromInt(int(tc.PDClientPort())),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
@@ -646,7 +682,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 				privileged := true
 				initContainers = append(initContainers, corev1.Container{
 					Name:  "init",
-					Image: tc.HelperImage(),
+					Image: basePDSpec.HelperImage(),
 					Command: []string{
 						"sh",
 						"-c",
@@ -681,8 +717,9 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	setName := controller.PDMemberName(tcName)
 	stsLabels := label.New().Instance(instanceName).PD()
 	podLabels := util.CombineStringMap(stsLabels, basePDSpec.Labels())
-	podAnnotations := util.CombineStringMap(controller.AnnProm(2379), basePDSpec.Annotations())
-	stsAnnotations := getStsAnnotations(tc.Annotations, label.PDLabelVal)
+	podAnnotations := util.CombineStringMap(controller.AnnProm(tc.PDClientPort()), basePDSpec.Annotations())
+	setStartScriptVersionAnnotation(podAnnotations, basePDSpec.StartScriptVersion())
+	stsAnnotations := getTidbClusterStsAnnotations(tc, label.PDLabelVal)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
 	if err != nil {
@@ -702,7 +739,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 			},
 			{
 				Name:          "client",
-				ContainerPort: int32(2379),
+				ContainerPort: tc.PDClientPort(),
 				Protocol:      corev1.ProtocolTCP,
 			},
 		},
@@ -732,7 +769,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 		},
 		{
 			Name:  "TZ",
-			Value: tc.Spec.Timezone,
+			Value: basePDSpec.Timezone(),
 		},
 	}
 
@@ -751,6 +788,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	pdContainer.Env = util.AppendEnv(env, basePDSpec.Env())
 	podSpec.Volumes = append(vols, basePDSpec.AdditionalVolumes()...)
 	podSpec.Containers = append([]corev1.Container{pdContainer}, basePDSpec.AdditionalContainers()...)
+	addTimezoneData(basePDSpec, &podSpec, v1alpha1.PDMemberType.String())
 	podSpec.ServiceAccountName = tc.Spec.PD.ServiceAccount
 	if podSpec.ServiceAccountName == "" {
 		podSpec.ServiceAccountName = tc.Spec.ServiceAccount
@@ -807,6 +845,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	}
 
 	pdSet.Spec.VolumeClaimTemplates = append(pdSet.Spec.VolumeClaimTemplates, additionalPVCs...)
+	setVolumeClaimCloudTags(tc, pdSet.Spec.VolumeClaimTemplates)
 	return pdSet, nil
 }
 
@@ -847,6 +886,9 @@ func getPDConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 		Scheme:        tc.Scheme(),
 		DataDir:       filepath.Join(pdDataVolumeMountPath, tc.Spec.PD.DataSubDir),
 		ClusterDomain: tc.Spec.ClusterDomain,
+		ClientPort:    tc.PDClientPort(),
+
+		StartScriptVersion: tc.BasePDSpec().StartScriptVersion(),
 	})
 	if err != nil {
 		return nil, err
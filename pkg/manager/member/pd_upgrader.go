@@ -77,6 +77,10 @@ func (u *pdUpgrader) gracefulUpgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Stat
 	}
 
 	mngerutils.SetUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
+	if syncRollingUpdateProgress(u.deps, tc, v1alpha1.PDMemberType, tc.Spec.PD.ProgressDeadlineSeconds, tc.Status.PD.StatefulSet.UpdateRevision,
+		*oldSet.Spec.UpdateStrategy.RollingUpdate.Partition, &tc.Status.PD.RollingUpdate, &tc.Status.PD.Conditions) {
+		return nil
+	}
 	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
 	for _i := len(podOrdinals) - 1; _i >= 0; _i-- {
 		i := podOrdinals[_i]
@@ -95,6 +99,9 @@ func (u *pdUpgrader) gracefulUpgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Stat
 			if member, exist := tc.Status.PD.Members[PdName(tc.Name, i, tc.Namespace, tc.Spec.ClusterDomain)]; !exist || !member.Health {
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd upgraded pod: [%s] is not ready", ns, tcName, podName)
 			}
+			if msg := checkUpgradedPodPacing(tc.BasePDSpec(), pod); msg != "" {
+				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd upgraded pod: [%s] %s", ns, tcName, podName, msg)
+			}
 			continue
 		}
 
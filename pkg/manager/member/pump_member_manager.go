@@ -103,7 +103,14 @@ func (m *pumpMemberManager) syncPumpStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 	if err != nil {
 		return err
 	}
+	if err := m.syncTLSSecretHash(tc, oldSet, newSet); err != nil {
+		return err
+	}
 	if notFound {
+		if tenantPolicyBlocksCreation(tc) {
+			klog.Infof("TidbCluster: %s/%s, the tenant policy is violated, skip creating the pump statefulset", tc.GetNamespace(), tc.GetName())
+			return nil
+		}
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newSet)
 		if err != nil {
 			return err
@@ -111,10 +118,16 @@ func (m *pumpMemberManager) syncPumpStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		return m.deps.StatefulSetControl.CreateStatefulSet(tc, newSet)
 	}
 
+	if err := syncScaleOutResources(m.deps, tc, v1alpha1.PumpMemberType, oldSet, newSet); err != nil {
+		return err
+	}
+
 	if err := m.scaler.Scale(tc, oldSet, newSet); err != nil {
 		return err
 	}
 
+	syncTimezoneChange(m.deps, tc, v1alpha1.PumpMemberType, oldSet, newSet)
+
 	// Wait for PD & TiKV upgrading done
 	if tc.Status.TiFlash.Phase == v1alpha1.UpgradePhase ||
 		tc.Status.PD.Phase == v1alpha1.UpgradePhase ||
@@ -129,21 +142,60 @@ func (m *pumpMemberManager) syncPumpStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 	return mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSet, oldSet)
 }
 
-func (p *pumpMemberManager) buildBinlogClient(tc *v1alpha1.TidbCluster, control pdapi.PDControlInterface) (client binlogClient, err error) {
+// syncTLSSecretHash records the hash of the cluster TLS secret of Pump in the Pod template, as Pump can't
+// reload the rotated certs. For the StatefulSet created before the hash is recorded, the hash is kept in
+// the StatefulSet annotations until the certs are rotated to avoid rolling-updating the existing Pods.
+func (m *pumpMemberManager) syncTLSSecretHash(tc *v1alpha1.TidbCluster, oldSet, newSet *apps.StatefulSet) error {
+	if !tc.IsTLSClusterEnabled() {
+		return nil
+	}
+	secretName := util.ClusterTLSSecretName(tc.Name, label.PumpLabelVal)
+	secret, err := m.deps.SecretLister.Secrets(tc.Namespace).Get(secretName)
+	if errors.IsNotFound(err) {
+		// the Pods can't be started until the secret is created
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("syncTLSSecretHash: failed to get secret %s for cluster %s/%s, error: %s", secretName, tc.GetNamespace(), tc.GetName(), err)
+	}
+	hash := secretDataHash(secret)
+
+	if oldSet != nil {
+		if _, ok := oldSet.Spec.Template.Annotations[label.AnnTLSSecretHash]; !ok {
+			if baseline, ok := oldSet.Annotations[label.AnnTLSSecretHash]; !ok || baseline == hash {
+				if newSet.Annotations == nil {
+					newSet.Annotations = map[string]string{}
+				}
+				newSet.Annotations[label.AnnTLSSecretHash] = hash
+				return nil
+			}
+			klog.Infof("pump: the certs in secret %s/%s are rotated, rolling restart the Pods", tc.Namespace, secretName)
+		}
+	}
+	if newSet.Spec.Template.Annotations == nil {
+		newSet.Spec.Template.Annotations = map[string]string{}
+	}
+	newSet.Spec.Template.Annotations[label.AnnTLSSecretHash] = hash
+	return nil
+}
+
+func (p *pumpMemberManager) buildBinlogClient(tc *v1alpha1.TidbCluster) (client binlogClient, err error) {
 	if p.binlogClient != nil {
 		return p.binlogClient, nil
 	}
 
-	return buildBinlogClient(tc, control)
+	return buildBinlogClient(tc, p.deps)
 }
 
-func buildBinlogClient(tc *v1alpha1.TidbCluster, control pdapi.PDControlInterface) (client *binlog.Client, err error) {
+func buildBinlogClient(tc *v1alpha1.TidbCluster, deps *controller.Dependencies) (client *binlog.Client, err error) {
 	var endpoints []string
 	var tlsConfig *tls.Config
+	control := deps.PDControl
 	if tc.HeterogeneousWithoutLocalPD() {
-		endpoints, tlsConfig, err = control.GetEndpoints(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
+		endpoints, tlsConfig, err = control.GetEndpoints(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled(),
+			pdapi.ClientPort(controller.RefPDClientPort(deps.TiDBClusterLister, tc)))
 	} else {
-		endpoints, tlsConfig, err = control.GetEndpoints(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled())
+		endpoints, tlsConfig, err = control.GetEndpoints(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled(), pdapi.ClientPort(tc.PDClientPort()))
 	}
 	if err != nil {
 		return nil, err
@@ -176,7 +228,7 @@ func (m *pumpMemberManager) syncTiDBClusterStatus(tc *v1alpha1.TidbCluster, set
 		tc.Status.Pump.Phase = v1alpha1.NormalPhase
 	}
 
-	client, err := m.buildBinlogClient(tc, m.deps.PDControl)
+	client, err := m.buildBinlogClient(tc)
 	if err != nil {
 		return err
 	}
@@ -238,7 +290,11 @@ func (m *pumpMemberManager) syncHeadlessService(tc *v1alpha1.TidbCluster) error
 func (m *pumpMemberManager) syncConfigMap(tc *v1alpha1.TidbCluster, set *appsv1.StatefulSet) (*corev1.ConfigMap, error) {
 	basePumpSpec := tc.BasePumpSpec()
 
-	newCm, err := getNewPumpConfigMap(tc)
+	cfgTC, err := withPumpConfigMapRef(m.deps.UserConfigMapLister, tc)
+	if err != nil {
+		return nil, err
+	}
+	newCm, err := getNewPumpConfigMap(cfgTC)
 	if err != nil {
 		return nil, err
 	}
@@ -340,7 +396,7 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 	if tc.Spec.Pump.SetTimeZone != nil && *tc.Spec.Pump.SetTimeZone {
 		envs = append(envs, corev1.EnvVar{
 			Name:  "TZ",
-			Value: tc.Spec.Timezone,
+			Value: spec.Timezone(),
 		})
 	}
 	if spec.HostNetwork() {
@@ -440,6 +496,7 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 			},
 		},
 	}
+	setVolumeClaimCloudTags(tc, volumeClaims)
 
 	// TODO: set serviceAccountName in BuildPodSpec
 	serviceAccountName := tc.Spec.Pump.ServiceAccount
@@ -449,6 +506,7 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 	podSpec := spec.BuildPodSpec()
 	podSpec.Containers = containers
 	podSpec.Volumes = volumes
+	addTimezoneData(spec, &podSpec, v1alpha1.PumpMemberType.String())
 	podSpec.ServiceAccountName = serviceAccountName
 	// TODO: change to set field in BuildPodSpec
 	podSpec.InitContainers = spec.InitContainers()
@@ -511,6 +569,7 @@ func getPumpStartScript(tc *v1alpha1.TidbCluster) (string, error) {
 		LogLevel:      getPumpLogLevel(tc),
 		ClusterDomain: tc.Spec.ClusterDomain,
 		Namespace:     tc.GetNamespace(),
+		PDPort:        tc.PDClientPort(),
 	})
 }
 
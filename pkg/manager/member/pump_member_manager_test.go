@@ -19,6 +19,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
 	"github.com/pingcap/tidb-operator/pkg/controller"
@@ -420,6 +421,44 @@ func TestSyncConfigUpdate(t *testing.T) {
 	}
 }
 
+func TestPumpMemberManagerSyncTLSSecretHash(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pmm, _, _ := newFakePumpMemberManager()
+	tc := newTidbClusterForPump()
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pump-cluster-secret", Namespace: corev1.NamespaceDefault},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert-1")},
+	}
+	secrets := pmm.deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	g.Expect(secrets.Add(secret)).To(Succeed())
+
+	// the hash is recorded in the Pod template of the new StatefulSet
+	newSet := &appsv1.StatefulSet{}
+	g.Expect(pmm.syncTLSSecretHash(tc, nil, newSet)).To(Succeed())
+	hash := newSet.Spec.Template.Annotations[label.AnnTLSSecretHash]
+	g.Expect(hash).NotTo(BeEmpty())
+
+	// the existing Pods without the hash are not restarted until the certs are rotated
+	oldSet := &appsv1.StatefulSet{}
+	newSet = &appsv1.StatefulSet{}
+	g.Expect(pmm.syncTLSSecretHash(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.Template.Annotations).To(BeEmpty())
+	g.Expect(newSet.Annotations[label.AnnTLSSecretHash]).To(Equal(hash))
+
+	secret = secret.DeepCopy()
+	secret.Data[corev1.TLSCertKey] = []byte("cert-2")
+	g.Expect(secrets.Update(secret)).To(Succeed())
+	oldSet = newSet
+	newSet = &appsv1.StatefulSet{}
+	g.Expect(pmm.syncTLSSecretHash(tc, oldSet, newSet)).To(Succeed())
+	rotated := newSet.Spec.Template.Annotations[label.AnnTLSSecretHash]
+	g.Expect(rotated).NotTo(BeEmpty())
+	g.Expect(rotated).NotTo(Equal(hash))
+	g.Expect(newSet.Annotations).NotTo(HaveKey(label.AnnTLSSecretHash))
+}
+
 type pumpFakeIndexers struct {
 	tc  cache.Indexer
 	svc cache.Indexer
@@ -18,12 +18,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/notification"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -43,6 +47,12 @@ import (
 //  if storageClass does not support VolumeExpansion, skip and continue
 //  if not patched, patch
 //
+// The observed capacities of the PVCs are recorded in `.status.${component}.volumes`,
+// and the `ComponentVolumeResizing` condition of the component is true until the
+// file systems of all volumes are expanded to the desired capacity. The PVCs that
+// can't be resized are listed in the `VolumeResizeBlocked` condition, which is
+// notified when it changes.
+//
 // We patch all PVCs at the same time. For many cloud storage plugins (e.g.
 // AWS-EBS, GCE-PD), they support online file system expansion in latest
 // Kubernetes (1.15+).
@@ -57,7 +67,9 @@ import (
 // - If the feature `ExpandInUsePersistentVolumes` is not enabled or the volume
 //   plugin does not support, the pod referencing the volume must be deleted and
 //   recreated after the `FileSystemResizePending` condition becomes true.
-// - Shrinking volumes is not supported.
+// - Shrinking volumes is not supported, unless `spec.tikv.enableVolumeReplace` is
+//   set for TiKV, where the stores are replaced one by one with new PVCs of the
+//   desired size.
 //
 type PVCResizerInterface interface {
 	Resize(*v1alpha1.TidbCluster) error
@@ -74,6 +86,9 @@ var (
 
 	dmMasterRequirement = util.MustNewRequirement(label.ComponentLabelKey, selection.Equals, []string{label.DMMasterLabelVal})
 	dmWorkerRequirement = util.MustNewRequirement(label.ComponentLabelKey, selection.Equals, []string{label.DMWorkerLabelVal})
+
+	// the PVC name for StatefulSet will be ${pvcNameInTemplate}-${stsName}-${ordinal}
+	rePvcPrefix = regexp.MustCompile(`^(.+)-(\d+)$`)
 )
 
 type pvcResizer struct {
@@ -89,6 +104,12 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 	if err != nil {
 		return err
 	}
+	// the PVCs that can't be resized are collected per component, and recorded in the
+	// ComponentVolumeResizeBlocked condition of the component by syncVolumeResizeBlocked
+	var blocked []string
+	onBlocked := func(pvc *corev1.PersistentVolumeClaim, reason string) {
+		blocked = append(blocked, fmt.Sprintf("PVC %s can't be resized: %s", pvc.Name, reason))
+	}
 
 	// For each component, we compose a map called pvcPrefix2Quantity, with PVC name prefix for current component as keys,
 	// for example "pd-${tcName}-pd" (for tc.Spec.PD.Requests) or "pd-log-${tcName}-pd" (for tc.Spec.PD.storageVolumes elements with name "log").
@@ -111,9 +132,13 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				klog.Warningf("StorageVolume %q in %s/%s .Spec.PD is invalid", sv.Name, ns, tc.Name)
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*pdRequirement), pvcPrefix2Quantity); err != nil {
+		volumes, err := p.patchPVCs(ns, selector.Add(*pdRequirement), controller.PDMemberName(tc.Name), pvcPrefix2Quantity, false, onBlocked)
+		if err != nil {
 			return err
 		}
+		updateVolumeStatus(volumes, &tc.Status.PD.Volumes, &tc.Status.PD.Conditions)
+		p.syncVolumeResizeBlocked(tc, v1alpha1.PDMemberType.String(), &tc.Status.PD.Conditions, blocked)
+		blocked = nil
 	}
 	// patch TiDB PVCs
 	if tc.Spec.TiDB != nil {
@@ -127,9 +152,13 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				klog.Warningf("StorageVolume %q in %s/%s .Spec.TiDB is invalid", sv.Name, ns, tc.Name)
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*tidbRequirement), pvcPrefix2Quantity); err != nil {
+		volumes, err := p.patchPVCs(ns, selector.Add(*tidbRequirement), controller.TiDBMemberName(tc.Name), pvcPrefix2Quantity, false, onBlocked)
+		if err != nil {
 			return err
 		}
+		updateVolumeStatus(volumes, &tc.Status.TiDB.Volumes, &tc.Status.TiDB.Conditions)
+		p.syncVolumeResizeBlocked(tc, v1alpha1.TiDBMemberType.String(), &tc.Status.TiDB.Conditions, blocked)
+		blocked = nil
 	}
 	// patch TiKV PVCs
 	if tc.Spec.TiKV != nil {
@@ -147,9 +176,19 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				klog.Warningf("StorageVolume %q in %s/%s .Spec.TiKV is invalid", sv.Name, ns, tc.Name)
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*tikvRequirement), pvcPrefix2Quantity); err != nil {
+		replaceOnShrink := tc.Spec.TiKV.EnableVolumeReplace
+		volumes, err := p.patchPVCs(ns, selector.Add(*tikvRequirement), controller.TiKVMemberName(tc.Name), pvcPrefix2Quantity, replaceOnShrink, onBlocked)
+		if err != nil {
 			return err
 		}
+		updateVolumeStatus(volumes, &tc.Status.TiKV.Volumes, &tc.Status.TiKV.Conditions)
+		p.syncVolumeResizeBlocked(tc, v1alpha1.TiKVMemberType.String(), &tc.Status.TiKV.Conditions, blocked)
+		blocked = nil
+		if replaceOnShrink && len(pvcPrefix2Quantity) > 0 {
+			if err := p.replaceTiKVVolumes(tc, selector.Add(*tikvRequirement), pvcPrefix2Quantity); err != nil {
+				return err
+			}
+		}
 	}
 	// patch TiFlash PVCs
 	if tc.Spec.TiFlash != nil {
@@ -161,9 +200,13 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				pvcPrefix2Quantity[key] = quantity
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*tiflashRequirement), pvcPrefix2Quantity); err != nil {
+		volumes, err := p.patchPVCs(ns, selector.Add(*tiflashRequirement), controller.TiFlashMemberName(tc.Name), pvcPrefix2Quantity, false, onBlocked)
+		if err != nil {
 			return err
 		}
+		updateVolumeStatus(volumes, &tc.Status.TiFlash.Volumes, &tc.Status.TiFlash.Conditions)
+		p.syncVolumeResizeBlocked(tc, v1alpha1.TiFlashMemberType.String(), &tc.Status.TiFlash.Conditions, blocked)
+		blocked = nil
 	}
 	// patch TiCDC PVCs
 	if tc.Spec.TiCDC != nil {
@@ -177,9 +220,13 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				klog.Warningf("StorageVolume %q in %s/%s .Spec.TiCDC is invalid", sv.Name, ns, tc.Name)
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*ticdcRequirement), pvcPrefix2Quantity); err != nil {
+		volumes, err := p.patchPVCs(ns, selector.Add(*ticdcRequirement), controller.TiCDCMemberName(tc.Name), pvcPrefix2Quantity, false, onBlocked)
+		if err != nil {
 			return err
 		}
+		updateVolumeStatus(volumes, &tc.Status.TiCDC.Volumes, &tc.Status.TiCDC.Conditions)
+		p.syncVolumeResizeBlocked(tc, v1alpha1.TiCDCMemberType.String(), &tc.Status.TiCDC.Conditions, blocked)
+		blocked = nil
 	}
 	// patch Pump PVCs
 	if tc.Spec.Pump != nil {
@@ -189,9 +236,13 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 			key := fmt.Sprintf("data-%s-%s", tc.Name, pumpMemberType)
 			pvcPrefix2Quantity[key] = quantity
 		}
-		if err := p.patchPVCs(ns, selector.Add(*pumpRequirement), pvcPrefix2Quantity); err != nil {
+		volumes, err := p.patchPVCs(ns, selector.Add(*pumpRequirement), controller.PumpMemberName(tc.Name), pvcPrefix2Quantity, false, onBlocked)
+		if err != nil {
 			return err
 		}
+		updateVolumeStatus(volumes, &tc.Status.Pump.Volumes, &tc.Status.Pump.Conditions)
+		p.syncVolumeResizeBlocked(tc, v1alpha1.PumpMemberType.String(), &tc.Status.Pump.Conditions, blocked)
+		blocked = nil
 	}
 	return nil
 }
@@ -207,27 +258,47 @@ func (p *pvcResizer) ResizeDM(dc *v1alpha1.DMCluster) error {
 	// patch dm-master PVCs
 	{
 		pvcPrefix2Quantity := make(map[string]resource.Quantity)
+		dmMasterMemberType := v1alpha1.DMMasterMemberType.String()
 		if quantity, err := resource.ParseQuantity(dc.Spec.Master.StorageSize); err == nil {
-			dmMasterMemberType := v1alpha1.DMMasterMemberType.String()
 			key := fmt.Sprintf("%s-%s-%s", dmMasterMemberType, dc.Name, dmMasterMemberType)
 			pvcPrefix2Quantity[key] = quantity
 		}
-		if err := p.patchPVCs(ns, selector.Add(*dmMasterRequirement), pvcPrefix2Quantity); err != nil {
+		for _, sv := range dc.Spec.Master.StorageVolumes {
+			key := fmt.Sprintf("%s-%s-%s-%s", dmMasterMemberType, sv.Name, dc.Name, dmMasterMemberType)
+			if quantity, err := resource.ParseQuantity(sv.StorageSize); err == nil {
+				pvcPrefix2Quantity[key] = quantity
+			} else {
+				klog.Warningf("StorageVolume %q in %s/%s .Spec.Master is invalid", sv.Name, ns, dc.Name)
+			}
+		}
+		volumes, err := p.patchPVCs(ns, selector.Add(*dmMasterRequirement), controller.DMMasterMemberName(dc.Name), pvcPrefix2Quantity, false, nil)
+		if err != nil {
 			return err
 		}
+		updateVolumeStatus(volumes, &dc.Status.Master.Volumes, &dc.Status.Master.Conditions)
 	}
 
 	// patch dm-worker PVCs
 	if dc.Spec.Worker != nil {
 		pvcPrefix2Quantity := make(map[string]resource.Quantity)
+		dmWorkerMemberType := v1alpha1.DMWorkerMemberType.String()
 		if quantity, err := resource.ParseQuantity(dc.Spec.Worker.StorageSize); err == nil {
-			dmWorkerMemberType := v1alpha1.DMWorkerMemberType.String()
 			key := fmt.Sprintf("%s-%s-%s", dmWorkerMemberType, dc.Name, dmWorkerMemberType)
 			pvcPrefix2Quantity[key] = quantity
 		}
-		if err := p.patchPVCs(ns, selector.Add(*dmWorkerRequirement), pvcPrefix2Quantity); err != nil {
+		for _, sv := range dc.Spec.Worker.StorageVolumes {
+			key := fmt.Sprintf("%s-%s-%s-%s", dmWorkerMemberType, sv.Name, dc.Name, dmWorkerMemberType)
+			if quantity, err := resource.ParseQuantity(sv.StorageSize); err == nil {
+				pvcPrefix2Quantity[key] = quantity
+			} else {
+				klog.Warningf("StorageVolume %q in %s/%s .Spec.Worker is invalid", sv.Name, ns, dc.Name)
+			}
+		}
+		volumes, err := p.patchPVCs(ns, selector.Add(*dmWorkerRequirement), controller.DMWorkerMemberName(dc.Name), pvcPrefix2Quantity, false, nil)
+		if err != nil {
 			return err
 		}
+		updateVolumeStatus(volumes, &dc.Status.Worker.Volumes, &dc.Status.Worker.Conditions)
 	}
 	return nil
 }
@@ -243,19 +314,22 @@ func (p *pvcResizer) isVolumeExpansionSupported(storageClassName string) (bool,
 	return *sc.AllowVolumeExpansion, nil
 }
 
-// patchPVCs patches PVCs filtered by selector and prefix.
-func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, pvcQuantityInSpec map[string]resource.Quantity) error {
+// patchPVCs patches PVCs filtered by selector and prefix, onBlocked is called if not nil when a PVC can't be resized.
+// If replaceOnShrink is true, the PVCs to be shrunk are left to be replaced rather than reported as blocked.
+// It returns the observed status of the volumes, keyed by the PVC name in template.
+func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, stsName string, pvcQuantityInSpec map[string]resource.Quantity,
+	replaceOnShrink bool, onBlocked func(pvc *corev1.PersistentVolumeClaim, reason string)) (map[string]v1alpha1.StorageVolumeStatus, error) {
 	if len(pvcQuantityInSpec) == 0 {
-		return nil
+		return nil, nil
 	}
 	pvcs, err := p.deps.PVCLister.PersistentVolumeClaims(ns).List(selector)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// the PVC name for StatefulSet will be ${pvcNameInTemplate}-${stsName}-${ordinal}, here we want to drop the ordinal
-	rePvcPrefix := regexp.MustCompile(`^(.+)-\d+$`)
+	volumes := make(map[string]v1alpha1.StorageVolumeStatus)
 	for _, pvc := range pvcs {
+		// drop the ordinal of the PVC name
 		match := rePvcPrefix.FindStringSubmatch(pvc.Name)
 		pvcPrefix := match[1]
 		quantityInSpec, ok := pvcQuantityInSpec[pvcPrefix]
@@ -263,6 +337,8 @@ func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, pvcQuantityI
 			// TODO: PVC not specified in tc.spec, should we deal with it and raise a warning
 			continue
 		}
+		volName := strings.TrimSuffix(pvcPrefix, "-"+stsName)
+		volumes[volName] = observeVolume(volumes[volName], pvc, quantityInSpec)
 
 		if pvc.Spec.StorageClassName == nil {
 			klog.Warningf("PVC %s/%s has no storage class, skipped", pvc.Namespace, pvc.Name)
@@ -279,10 +355,13 @@ func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, pvcQuantityI
 			if p.deps.StorageClassLister != nil {
 				volumeExpansionSupported, err := p.isVolumeExpansionSupported(*pvc.Spec.StorageClassName)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				if !volumeExpansionSupported {
 					klog.Warningf("Storage Class %q used by PVC %s/%s does not support volume expansion, skipped", *pvc.Spec.StorageClassName, pvc.Namespace, pvc.Name)
+					if onBlocked != nil {
+						onBlocked(pvc, fmt.Sprintf("storage class %q does not support volume expansion", *pvc.Spec.StorageClassName))
+					}
 					continue
 				}
 			} else {
@@ -299,20 +378,124 @@ func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, pvcQuantityI
 				},
 			})
 			if err != nil {
-				return err
+				return nil, err
 			}
 			_, err = p.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(context.TODO(), pvc.Name, types.MergePatchType, mergePatch, metav1.PatchOptions{})
 			if err != nil {
-				return err
+				return nil, err
 			}
 			klog.V(2).Infof("PVC %s/%s storage request is updated from %s to %s", pvc.Namespace, pvc.Name, currentRequest.String(), quantityInSpec.String())
+		} else if quantityInSpec.Cmp(currentRequest) < 0 && replaceOnShrink {
+			klog.V(4).Infof("PVC %s/%s storage request will be shrunk from %s to %s by replacing the volume", pvc.Namespace, pvc.Name, currentRequest.String(), quantityInSpec.String())
 		} else if quantityInSpec.Cmp(currentRequest) < 0 {
 			klog.Warningf("PVC %s/%s/ storage request cannot be shrunk (%s to %s), skipped", pvc.Namespace, pvc.Name, currentRequest.String(), quantityInSpec.String())
+			if onBlocked != nil {
+				onBlocked(pvc, fmt.Sprintf("storage request cannot be shrunk from %s to %s", currentRequest.String(), quantityInSpec.String()))
+			}
 		} else {
 			klog.V(4).Infof("PVC %s/%s storage request is already %s, skipped", pvc.Namespace, pvc.Name, quantityInSpec.String())
 		}
 	}
-	return nil
+	return volumes, nil
+}
+
+// observeVolume adds a PVC to the observed status of the volumes it belongs to. The file system of a volume is
+// considered resized only when its storage request and actual capacity match the desired one and no resize is in progress.
+func observeVolume(status v1alpha1.StorageVolumeStatus, pvc *corev1.PersistentVolumeClaim, desired resource.Quantity) v1alpha1.StorageVolumeStatus {
+	status.Count++
+	status.ResizedCapacity = desired
+	request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	resized := ok && request.Cmp(desired) == 0
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return status
+	}
+	status.BoundCount++
+	capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	if !ok {
+		return status
+	}
+	if status.BoundCount == 1 || capacity.Cmp(status.CurrentCapacity) < 0 {
+		status.CurrentCapacity = capacity
+	}
+	if !resized || capacity.Cmp(desired) < 0 {
+		return status
+	}
+	for _, cond := range pvc.Status.Conditions {
+		if (cond.Type == corev1.PersistentVolumeClaimResizing || cond.Type == corev1.PersistentVolumeClaimFileSystemResizePending) &&
+			cond.Status == corev1.ConditionTrue {
+			return status
+		}
+	}
+	status.ResizedCount++
+	return status
+}
+
+// updateVolumeStatus records the observed volumes of a component in its status and sets the
+// ComponentVolumeResizing condition until the file systems of all volumes are expanded.
+func updateVolumeStatus(observed map[string]v1alpha1.StorageVolumeStatus,
+	volumes *map[string]v1alpha1.StorageVolumeStatus, conditions *[]metav1.Condition) {
+	*volumes = observed
+	if len(observed) == 0 {
+		if meta.FindStatusCondition(*conditions, v1alpha1.ComponentVolumeResizing) != nil {
+			meta.RemoveStatusCondition(conditions, v1alpha1.ComponentVolumeResizing)
+		}
+		return
+	}
+
+	var count, resizedCount int
+	for _, status := range observed {
+		count += status.Count
+		resizedCount += status.ResizedCount
+	}
+	if resizedCount < count {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:    v1alpha1.ComponentVolumeResizing,
+			Status:  metav1.ConditionTrue,
+			Reason:  "VolumeResizing",
+			Message: fmt.Sprintf("%d/%d volumes are resized", resizedCount, count),
+		})
+		return
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    v1alpha1.ComponentVolumeResizing,
+		Status:  metav1.ConditionFalse,
+		Reason:  "VolumeResized",
+		Message: "all volumes are resized",
+	})
+}
+
+// syncVolumeResizeBlocked sets the ComponentVolumeResizeBlocked condition of a component to the PVCs
+// that can't be resized, and notifies only when they change from the old condition, so that a blocked
+// resize is notified once instead of on every reconciliation.
+func (p *pvcResizer) syncVolumeResizeBlocked(tc *v1alpha1.TidbCluster, component string, conditions *[]metav1.Condition, blocked []string) {
+	old := meta.FindStatusCondition(*conditions, v1alpha1.ComponentVolumeResizeBlocked)
+	if len(blocked) == 0 {
+		if old != nil {
+			meta.RemoveStatusCondition(conditions, v1alpha1.ComponentVolumeResizeBlocked)
+		}
+		return
+	}
+
+	sort.Strings(blocked)
+	message := strings.Join(blocked, "; ")
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    v1alpha1.ComponentVolumeResizeBlocked,
+		Status:  metav1.ConditionTrue,
+		Reason:  "VolumeResizeBlocked",
+		Message: message,
+	})
+	if old != nil && old.Message == message {
+		return
+	}
+	p.deps.Notifier.Notify(tc.Spec.Notifications, notification.Notification{
+		Type:      v1alpha1.NotificationEventPVCResizeBlocked,
+		Severity:  v1alpha1.NotificationSeverityWarning,
+		Kind:      v1alpha1.TiDBClusterKind,
+		Namespace: tc.GetNamespace(),
+		Name:      tc.GetName(),
+		Component: component,
+		Message:   message,
+	})
 }
 
 func NewPVCResizer(deps *controller.Dependencies) PVCResizerInterface {
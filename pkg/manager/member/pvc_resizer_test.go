@@ -22,9 +22,11 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/notification"
 	v1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
@@ -78,6 +80,8 @@ func TestPVCResizer(t *testing.T) {
 		pvcs     []*v1.PersistentVolumeClaim
 		wantPVCs []*v1.PersistentVolumeClaim
 		wantErr  error
+		// the number of PVCResizeBlocked notifications
+		wantBlocked int
 	}{
 		{
 			name: "no PVCs",
@@ -336,12 +340,13 @@ func TestPVCResizer(t *testing.T) {
 			},
 			sc: newStorageClass("sc", false),
 			pvcs: []*v1.PersistentVolumeClaim{
-				newPVCWithStorage("pd-0", label.PDLabelVal, "sc", "1Gi"),
+				newPVCWithStorage("pd-tc-pd-0", label.PDLabelVal, "sc", "1Gi"),
 			},
 			wantPVCs: []*v1.PersistentVolumeClaim{
-				newPVCWithStorage("pd-0", label.PDLabelVal, "sc", "1Gi"),
+				newPVCWithStorage("pd-tc-pd-0", label.PDLabelVal, "sc", "1Gi"),
 			},
-			wantErr: nil,
+			wantErr:     nil,
+			wantBlocked: 1,
 		},
 		{
 			name: "shrinking is not supported",
@@ -362,12 +367,13 @@ func TestPVCResizer(t *testing.T) {
 			},
 			sc: newStorageClass("sc", false),
 			pvcs: []*v1.PersistentVolumeClaim{
-				newPVCWithStorage("pd-0", label.PDLabelVal, "sc", "2Gi"),
+				newPVCWithStorage("pd-tc-pd-0", label.PDLabelVal, "sc", "2Gi"),
 			},
 			wantPVCs: []*v1.PersistentVolumeClaim{
-				newPVCWithStorage("pd-0", label.PDLabelVal, "sc", "2Gi"),
+				newPVCWithStorage("pd-tc-pd-0", label.PDLabelVal, "sc", "2Gi"),
 			},
-			wantErr: nil,
+			wantErr:     nil,
+			wantBlocked: 1,
 		},
 	}
 
@@ -390,10 +396,26 @@ func TestPVCResizer(t *testing.T) {
 			informerFactory.Start(ctx.Done())
 			informerFactory.WaitForCacheSync(ctx.Done())
 
+			tt.tc.Spec.Notifications = &v1alpha1.NotificationSpec{
+				Sinks: []v1alpha1.NotificationSink{{Name: "webhook", Webhook: &v1alpha1.WebhookNotificationSink{URL: "http://webhook"}}},
+			}
 			err := resizer.Resize(tt.tc)
 			if !reflect.DeepEqual(tt.wantErr, err) {
 				t.Errorf("want %v, got %v", tt.wantErr, err)
 			}
+			if got := len(fakeDeps.Notifier.(*notification.FakeNotifier).Notifications()); got != tt.wantBlocked {
+				t.Errorf("want %d PVCResizeBlocked notifications, got %d", tt.wantBlocked, got)
+			}
+			if blocked := meta.IsStatusConditionTrue(tt.tc.Status.PD.Conditions, v1alpha1.ComponentVolumeResizeBlocked); blocked != (tt.wantBlocked > 0) {
+				t.Errorf("want the VolumeResizeBlocked condition to be %v, got %v", tt.wantBlocked > 0, blocked)
+			}
+			// the blocked resize is notified only on the transition, not on every reconciliation
+			if err := resizer.Resize(tt.tc); !reflect.DeepEqual(tt.wantErr, err) {
+				t.Errorf("want %v, got %v", tt.wantErr, err)
+			}
+			if got := len(fakeDeps.Notifier.(*notification.FakeNotifier).Notifications()); got != tt.wantBlocked {
+				t.Errorf("want %d PVCResizeBlocked notifications after resizing again, got %d", tt.wantBlocked, got)
+			}
 
 			for i, pvc := range tt.pvcs {
 				wantPVC := tt.wantPVCs[i]
@@ -474,6 +496,32 @@ func TestDMPVCResizer(t *testing.T) {
 				newDMPVCWithStorage("dm-worker-dc-dm-worker-2", label.DMWorkerLabelVal, "sc", "2Gi"),
 			},
 		},
+		{
+			name: "resize dm-worker storage volume PVCs",
+			dc: &v1alpha1.DMCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: v1.NamespaceDefault,
+					Name:      "dc",
+				},
+				Spec: v1alpha1.DMClusterSpec{
+					Worker: &v1alpha1.WorkerSpec{
+						StorageSize: "1Gi",
+						StorageVolumes: []v1alpha1.StorageVolume{
+							{Name: "relay", StorageSize: "3Gi"},
+						},
+					},
+				},
+			},
+			sc: newStorageClass("sc", true),
+			pvcs: []*v1.PersistentVolumeClaim{
+				newDMPVCWithStorage("dm-worker-dc-dm-worker-0", label.DMWorkerLabelVal, "sc", "1Gi"),
+				newDMPVCWithStorage("dm-worker-relay-dc-dm-worker-0", label.DMWorkerLabelVal, "sc", "1Gi"),
+			},
+			wantPVCs: []*v1.PersistentVolumeClaim{
+				newDMPVCWithStorage("dm-worker-dc-dm-worker-0", label.DMWorkerLabelVal, "sc", "1Gi"),
+				newDMPVCWithStorage("dm-worker-relay-dc-dm-worker-0", label.DMWorkerLabelVal, "sc", "3Gi"),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -514,3 +562,125 @@ func TestDMPVCResizer(t *testing.T) {
 		})
 	}
 }
+
+func TestPVCResizerVolumeStatus(t *testing.T) {
+	withCapacity := func(pvc *v1.PersistentVolumeClaim, capacity string, conditions ...v1.PersistentVolumeClaimConditionType) *v1.PersistentVolumeClaim {
+		pvc.Status.Phase = v1.ClaimBound
+		pvc.Status.Capacity = v1.ResourceList{v1.ResourceStorage: resource.MustParse(capacity)}
+		for _, cond := range conditions {
+			pvc.Status.Conditions = append(pvc.Status.Conditions, v1.PersistentVolumeClaimCondition{Type: cond, Status: v1.ConditionTrue})
+		}
+		return pvc
+	}
+	tests := []struct {
+		name          string
+		pvcs          []*v1.PersistentVolumeClaim
+		wantVolumes   map[string]v1alpha1.StorageVolumeStatus
+		wantCondition metav1.ConditionStatus
+		wantMessage   string
+	}{
+		{
+			name: "file system expansion is pending",
+			pvcs: []*v1.PersistentVolumeClaim{
+				withCapacity(newPVCWithStorage("tikv-tc-tikv-0", label.TiKVLabelVal, "sc", "2Gi"), "2Gi"),
+				withCapacity(newPVCWithStorage("tikv-tc-tikv-1", label.TiKVLabelVal, "sc", "2Gi"), "1Gi", v1.PersistentVolumeClaimFileSystemResizePending),
+				withCapacity(newPVCWithStorage("tikv-tc-tikv-2", label.TiKVLabelVal, "sc", "1Gi"), "1Gi"),
+				newPVCWithStorage("tikv-raft-tc-tikv-0", label.TiKVLabelVal, "sc", "1Gi"),
+			},
+			wantVolumes: map[string]v1alpha1.StorageVolumeStatus{
+				"tikv": {
+					Count:           3,
+					BoundCount:      3,
+					ResizedCount:    1,
+					CurrentCapacity: resource.MustParse("1Gi"),
+					ResizedCapacity: resource.MustParse("2Gi"),
+				},
+				"tikv-raft": {
+					Count:           1,
+					ResizedCapacity: resource.MustParse("1Gi"),
+				},
+			},
+			wantCondition: metav1.ConditionTrue,
+			wantMessage:   "1/4 volumes are resized",
+		},
+		{
+			name: "all volumes are resized",
+			pvcs: []*v1.PersistentVolumeClaim{
+				withCapacity(newPVCWithStorage("tikv-tc-tikv-0", label.TiKVLabelVal, "sc", "2Gi"), "2Gi"),
+				withCapacity(newPVCWithStorage("tikv-raft-tc-tikv-0", label.TiKVLabelVal, "sc", "1Gi"), "1Gi"),
+			},
+			wantVolumes: map[string]v1alpha1.StorageVolumeStatus{
+				"tikv": {
+					Count:           1,
+					BoundCount:      1,
+					ResizedCount:    1,
+					CurrentCapacity: resource.MustParse("2Gi"),
+					ResizedCapacity: resource.MustParse("2Gi"),
+				},
+				"tikv-raft": {
+					Count:           1,
+					BoundCount:      1,
+					ResizedCount:    1,
+					CurrentCapacity: resource.MustParse("1Gi"),
+					ResizedCapacity: resource.MustParse("1Gi"),
+				},
+			},
+			wantCondition: metav1.ConditionFalse,
+			wantMessage:   "all volumes are resized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fakeDeps := controller.NewFakeDependencies()
+			for _, pvc := range tt.pvcs {
+				fakeDeps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+			}
+			fakeDeps.KubeClientset.StorageV1().StorageClasses().Create(context.TODO(), newStorageClass("sc", true), metav1.CreateOptions{})
+
+			resizer := NewPVCResizer(fakeDeps)
+
+			informerFactory := fakeDeps.KubeInformerFactory
+			informerFactory.Start(ctx.Done())
+			informerFactory.WaitForCacheSync(ctx.Done())
+
+			tc := &v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: v1.NamespaceDefault,
+					Name:      "tc",
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{
+						ResourceRequirements: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceStorage: resource.MustParse("2Gi"),
+							},
+						},
+						StorageVolumes: []v1alpha1.StorageVolume{
+							{
+								Name:        "raft",
+								StorageSize: "1Gi",
+							},
+						},
+					},
+				},
+			}
+			if err := resizer.Resize(tc); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantVolumes, tc.Status.TiKV.Volumes); diff != "" {
+				t.Errorf("unexpected volumes (-want, +got): %s", diff)
+			}
+			cond := meta.FindStatusCondition(tc.Status.TiKV.Conditions, v1alpha1.ComponentVolumeResizing)
+			if cond == nil {
+				t.Fatalf("condition %s is not found", v1alpha1.ComponentVolumeResizing)
+			}
+			if cond.Status != tt.wantCondition || cond.Message != tt.wantMessage {
+				t.Errorf("want condition %s %q, got %s %q", tt.wantCondition, tt.wantMessage, cond.Status, cond.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// syncRollingUpdateProgress records the progress of the rolling update of a component and returns whether the
+// rolling update is paused. The rolling update makes progress when the partition of the StatefulSet is lowered,
+// which happens each time a Pod completes its update, and it's paused with the ProgressDeadlineExceeded condition
+// once no progress is made within the progress deadline. The pause is lifted when the update revision is changed,
+// or the deadline is raised or removed.
+func syncRollingUpdateProgress(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType,
+	deadlineSeconds *int32, updateRevision string, partition int32, progress **v1alpha1.RollingUpdateStatus, conditions *[]metav1.Condition) bool {
+	if deadlineSeconds == nil {
+		clearRollingUpdateProgress(progress, conditions)
+		return false
+	}
+
+	now := metav1.Now()
+	if *progress == nil || (*progress).UpdateRevision != updateRevision {
+		*progress = &v1alpha1.RollingUpdateStatus{
+			UpdateRevision:   updateRevision,
+			Partition:        partition,
+			LastProgressTime: now,
+		}
+	} else if partition < (*progress).Partition {
+		(*progress).Partition = partition
+		(*progress).LastProgressTime = now
+	}
+
+	deadline := (*progress).LastProgressTime.Add(time.Duration(*deadlineSeconds) * time.Second)
+	if now.Time.Before(deadline) {
+		removeProgressDeadlineExceeded(conditions)
+		return false
+	}
+
+	msg := fmt.Sprintf("no %s Pod completed its update in %ds since %s, the rolling update is paused",
+		memberType, *deadlineSeconds, (*progress).LastProgressTime.Format(time.RFC3339))
+	if !meta.IsStatusConditionTrue(*conditions, v1alpha1.ComponentProgressDeadlineExceeded) {
+		klog.Warningf("tidbcluster: [%s/%s] %s", tc.GetNamespace(), tc.GetName(), msg)
+		deps.Recorder.Event(tc, corev1.EventTypeWarning, v1alpha1.ComponentProgressDeadlineExceeded, msg)
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    v1alpha1.ComponentProgressDeadlineExceeded,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.ComponentProgressDeadlineExceeded,
+		Message: msg,
+	})
+	return true
+}
+
+// clearRollingUpdateProgress clears the progress of the rolling update of a component, it's called when the
+// component is not being upgraded, so that the time waiting for the other components doesn't count.
+func clearRollingUpdateProgress(progress **v1alpha1.RollingUpdateStatus, conditions *[]metav1.Condition) {
+	*progress = nil
+	removeProgressDeadlineExceeded(conditions)
+}
+
+func removeProgressDeadlineExceeded(conditions *[]metav1.Condition) {
+	if meta.FindStatusCondition(*conditions, v1alpha1.ComponentProgressDeadlineExceeded) != nil {
+		meta.RemoveStatusCondition(conditions, v1alpha1.ComponentProgressDeadlineExceeded)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestSyncRollingUpdateProgress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForPD()
+	status := &tc.Status.TiDB
+	deadline := pointer.Int32Ptr(600)
+	sync := func(revision string, partition int32) bool {
+		return syncRollingUpdateProgress(deps, tc, v1alpha1.TiDBMemberType, deadline, revision, partition, &status.RollingUpdate, &status.Conditions)
+	}
+
+	// the progress is recorded when the rolling update starts
+	g.Expect(sync("2", 3)).To(BeFalse())
+	g.Expect(status.RollingUpdate.UpdateRevision).To(Equal("2"))
+	g.Expect(status.RollingUpdate.Partition).To(Equal(int32(3)))
+
+	// the rolling update is paused if the partition is not lowered within the deadline
+	status.RollingUpdate.LastProgressTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	g.Expect(sync("2", 3)).To(BeTrue())
+	g.Expect(meta.IsStatusConditionTrue(status.Conditions, v1alpha1.ComponentProgressDeadlineExceeded)).To(BeTrue())
+	g.Expect(sync("2", 3)).To(BeTrue())
+
+	// the pause is lifted by raising the deadline
+	deadline = pointer.Int32Ptr(7200)
+	g.Expect(sync("2", 3)).To(BeFalse())
+	g.Expect(status.Conditions).To(BeEmpty())
+
+	// lowering the partition makes progress
+	deadline = pointer.Int32Ptr(600)
+	g.Expect(sync("2", 2)).To(BeFalse())
+	g.Expect(status.RollingUpdate.Partition).To(Equal(int32(2)))
+	g.Expect(status.RollingUpdate.LastProgressTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+
+	// a new update revision restarts the tracking
+	status.RollingUpdate.LastProgressTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	g.Expect(sync("3", 3)).To(BeFalse())
+	g.Expect(status.RollingUpdate.UpdateRevision).To(Equal("3"))
+
+	// the progress is cleared without a deadline
+	deadline = nil
+	g.Expect(sync("3", 3)).To(BeFalse())
+	g.Expect(status.RollingUpdate).To(BeNil())
+}
@@ -0,0 +1,409 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+)
+
+const (
+	// scaleOutBlockedEventReason is the reason of the events recorded when the scaling out of a component is blocked
+	scaleOutBlockedEventReason = "ScaleOutBlocked"
+	// storageClassQuotaSuffix is the suffix of the quota resources of a storage class, e.g.
+	// gold.storageclass.storage.k8s.io/requests.storage
+	storageClassQuotaSuffix = ".storageclass.storage.k8s.io/"
+)
+
+// syncScaleOutResources checks the resource quotas of the namespace and the capacity of the cluster before the
+// statefulset of the component is scaled out, including the scaling out for failover. If the resources are
+// insufficient for the new Pod, the replicas of newSet are reset to the ones of oldSet and the shortfall is
+// recorded in the ScaleOutBlocked condition, so that no Pending Pod is created to trigger further failover.
+func syncScaleOutResources(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, oldSet, newSet *apps.StatefulSet) error {
+	scaling, ordinal, _, _ := scaleOne(oldSet, newSet)
+	if !features.EnabledForCluster(tc.Spec.FeatureGates, features.ScaleOutResourceCheck, deps.CLIConfig.ScaleOutResourceCheck) || scaling <= 0 {
+		setScaleOutBlocked(tc, memberType, "")
+		return nil
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: *newSet.Spec.Template.ObjectMeta.DeepCopy(),
+		Spec:       *newSet.Spec.Template.Spec.DeepCopy(),
+	}
+	pod.Name = fmt.Sprintf("%s-%d", newSet.Name, ordinal)
+	pod.Namespace = newSet.Namespace
+	claims, err := getNewPVCsForScaleOut(deps, newSet, ordinal)
+	if err != nil {
+		return err
+	}
+
+	shortfalls, err := quotaShortfalls(deps, tc.GetNamespace(), pod, claims)
+	if err != nil {
+		return err
+	}
+	shortfalls = append(shortfalls, capacityShortfalls(deps, pod)...)
+	if len(shortfalls) == 0 {
+		setScaleOutBlocked(tc, memberType, "")
+		return nil
+	}
+
+	resetReplicas(newSet, oldSet)
+	msg := fmt.Sprintf("pod %s can't be created: %s", pod.Name, strings.Join(shortfalls, ", "))
+	if setScaleOutBlocked(tc, memberType, msg) {
+		deps.Recorder.Event(tc, corev1.EventTypeWarning, scaleOutBlockedEventReason, fmt.Sprintf("%s scaling out is blocked, %s", memberType, msg))
+	}
+	klog.Warningf("scaling out %s of tc %s/%s is blocked, %s", memberType, tc.GetNamespace(), tc.GetName(), msg)
+	return nil
+}
+
+// getNewPVCsForScaleOut returns the PVCs to be created for the Pod of the ordinal, the existing PVCs,
+// e.g. the ones deferred to be deleted by the last scaling in, are reused by the Pod
+func getNewPVCsForScaleOut(deps *controller.Dependencies, set *apps.StatefulSet, ordinal int32) ([]corev1.PersistentVolumeClaim, error) {
+	var claims []corev1.PersistentVolumeClaim
+	for _, tpl := range set.Spec.VolumeClaimTemplates {
+		name := fmt.Sprintf("%s-%s-%d", tpl.Name, set.Name, ordinal)
+		_, err := deps.PVCLister.PersistentVolumeClaims(set.Namespace).Get(name)
+		if err == nil {
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get pvc %s/%s, error: %v", set.Namespace, name, err)
+		}
+		claims = append(claims, tpl)
+	}
+	return claims, nil
+}
+
+// quotaShortfalls returns the resources of the quotas in the namespace that are insufficient for the Pod and its
+// PVCs. The quotas with scopes are not evaluated.
+func quotaShortfalls(deps *controller.Dependencies, ns string, pod *corev1.Pod, claims []corev1.PersistentVolumeClaim) ([]string, error) {
+	var quotas []*corev1.ResourceQuota
+	if deps.ResourceQuotaLister != nil {
+		list, err := deps.ResourceQuotaLister.ResourceQuotas(ns).List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resource quotas in namespace %s, error: %v", ns, err)
+		}
+		quotas = list
+	} else {
+		// the quotas are not cached if the check is only enabled for some clusters
+		list, err := deps.KubeClientset.CoreV1().ResourceQuotas(ns).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resource quotas in namespace %s, error: %v", ns, err)
+		}
+		for i := range list.Items {
+			quotas = append(quotas, &list.Items[i])
+		}
+	}
+	if len(quotas) == 0 {
+		return nil, nil
+	}
+
+	needs := corev1.ResourceList{}
+	add := func(name corev1.ResourceName, q resource.Quantity) {
+		sum := needs[name]
+		sum.Add(q)
+		needs[name] = sum
+	}
+	add(corev1.ResourcePods, *resource.NewQuantity(1, resource.DecimalSI))
+	requests, limits := resourcehelper.PodRequestsAndLimits(pod)
+	for name, q := range requests {
+		add(name, q)
+		add(corev1.ResourceName("requests."+string(name)), q)
+	}
+	for name, q := range limits {
+		add(corev1.ResourceName("limits."+string(name)), q)
+	}
+	for _, claim := range claims {
+		one := *resource.NewQuantity(1, resource.DecimalSI)
+		storage := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		add(corev1.ResourcePersistentVolumeClaims, one)
+		add(corev1.ResourceRequestsStorage, storage)
+		if claim.Spec.StorageClassName != nil {
+			prefix := *claim.Spec.StorageClassName + storageClassQuotaSuffix
+			add(corev1.ResourceName(prefix+string(corev1.ResourcePersistentVolumeClaims)), one)
+			add(corev1.ResourceName(prefix+string(corev1.ResourceRequestsStorage)), storage)
+		}
+	}
+
+	var shortfalls []string
+	for _, quota := range quotas {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		for _, name := range sortedResourceNames(quota.Status.Hard) {
+			need, ok := needs[name]
+			if !ok {
+				continue
+			}
+			available := quota.Status.Hard[name]
+			available.Sub(quota.Status.Used[name])
+			if available.Cmp(need) < 0 {
+				shortfalls = append(shortfalls, fmt.Sprintf("%s of resourcequota %s requires %s but %s is available",
+					name, quota.Name, need.String(), formatNonNegative(available)))
+			}
+		}
+	}
+	return shortfalls, nil
+}
+
+// capacityShortfalls returns the resources requested by the Pod that are insufficient on every node it may be
+// scheduled to. Only the node selector, the required node affinity, the required pod anti-affinity and the taints
+// are considered, so the Pod may still be unschedulable. The check is skipped unless all the nodes and the Pods
+// of the cluster are visible to the operator.
+func capacityShortfalls(deps *controller.Dependencies, pod *corev1.Pod) []string {
+	if deps.NodeLister == nil || !deps.CLIConfig.ClusterScoped {
+		return nil
+	}
+	nodes, err := deps.NodeLister.List(labels.Everything())
+	if err != nil || len(nodes) == 0 {
+		return nil
+	}
+	pods, err := deps.PodLister.List(labels.Everything())
+	if err != nil {
+		return nil
+	}
+
+	used := map[string]corev1.ResourceList{}
+	podCount := map[string]int64{}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		requests, _ := resourcehelper.PodRequestsAndLimits(p)
+		if used[p.Spec.NodeName] == nil {
+			used[p.Spec.NodeName] = corev1.ResourceList{}
+		}
+		for name, q := range requests {
+			sum := used[p.Spec.NodeName][name]
+			sum.Add(q)
+			used[p.Spec.NodeName][name] = sum
+		}
+		podCount[p.Spec.NodeName]++
+	}
+
+	antiAffinityDomains := podAntiAffinityDomains(nodes, pod, pods)
+	requests, _ := resourcehelper.PodRequestsAndLimits(pod)
+	// maxFree is the most free amount of each requested resource on a single candidate node
+	maxFree := corev1.ResourceList{}
+	candidates := 0
+	for _, node := range nodes {
+		if !isNodeCandidate(node, pod, antiAffinityDomains) {
+			continue
+		}
+		candidates++
+		if pods, ok := node.Status.Allocatable[corev1.ResourcePods]; ok && pods.Value() <= podCount[node.Name] {
+			continue
+		}
+		fits := true
+		for name, req := range requests {
+			free := node.Status.Allocatable[name]
+			free.Sub(used[node.Name][name])
+			if max, ok := maxFree[name]; !ok || free.Cmp(max) > 0 {
+				maxFree[name] = free
+			}
+			if free.Cmp(req) < 0 {
+				fits = false
+			}
+		}
+		if fits {
+			return nil
+		}
+	}
+	if candidates == 0 {
+		return []string{"no schedulable node satisfies the node selector, the affinity and the tolerations"}
+	}
+
+	var shortfalls []string
+	for _, name := range sortedResourceNames(requests) {
+		req := requests[name]
+		if free, ok := maxFree[name]; ok && free.Cmp(req) < 0 {
+			shortfalls = append(shortfalls, fmt.Sprintf("%s requests %s but at most %s is free on a node", name, req.String(), formatNonNegative(free)))
+		}
+	}
+	if len(shortfalls) == 0 {
+		// either the pods are full on all candidate nodes, or no node fits all the resources at the same time
+		shortfalls = append(shortfalls, fmt.Sprintf("no node has enough free resources for requests %s", formatResourceList(requests)))
+	}
+	return shortfalls
+}
+
+// podAntiAffinityDomains returns the topology domains occupied by the Pods matching each required pod anti-affinity
+// term of the Pod, which are keyed by the topology keys of the terms
+func podAntiAffinityDomains(nodes []*corev1.Node, pod *corev1.Pod, pods []*corev1.Pod) map[string]sets.String {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+		return nil
+	}
+	terms := pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(terms) == 0 {
+		return nil
+	}
+	nodeLabels := make(map[string]map[string]string, len(nodes))
+	for _, node := range nodes {
+		nodeLabels[node.Name] = node.Labels
+	}
+	domains := map[string]sets.String{}
+	for _, term := range terms {
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil || term.TopologyKey == "" {
+			continue
+		}
+		namespaces := sets.NewString(term.Namespaces...)
+		if namespaces.Len() == 0 {
+			namespaces.Insert(pod.Namespace)
+		}
+		for _, p := range pods {
+			if p.Spec.NodeName == "" || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			if !namespaces.Has(p.Namespace) || !selector.Matches(labels.Set(p.Labels)) {
+				continue
+			}
+			value, ok := nodeLabels[p.Spec.NodeName][term.TopologyKey]
+			if !ok {
+				continue
+			}
+			if domains[term.TopologyKey] == nil {
+				domains[term.TopologyKey] = sets.NewString()
+			}
+			domains[term.TopologyKey].Insert(value)
+		}
+	}
+	return domains
+}
+
+// isNodeCandidate returns whether the Pod may be scheduled to the node regarding the node selector, the required
+// node affinity, the topology domains occupied by the anti-affinity Pods and the taints
+func isNodeCandidate(node *corev1.Node, pod *corev1.Pod, antiAffinityDomains map[string]sets.String) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady && c.Status != corev1.ConditionTrue {
+			return false
+		}
+	}
+	if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		if required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil &&
+			!v1helper.MatchNodeSelectorTerms(required.NodeSelectorTerms, labels.Set(node.Labels), fields.Set{"metadata.name": node.Name}) {
+			return false
+		}
+	}
+	for key, values := range antiAffinityDomains {
+		if value, ok := node.Labels[key]; ok && values.Has(value) {
+			return false
+		}
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// setScaleOutBlocked records the shortfall of the component in the ScaleOutBlocked condition, or removes the
+// component from the condition if shortfall is empty. It returns whether the shortfall of the component is changed.
+func setScaleOutBlocked(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, shortfall string) bool {
+	return setComponentConditionEntry(tc, v1alpha1.TidbClusterScaleOutBlocked, utiltidbcluster.InsufficientResources, memberType, shortfall)
+}
+
+// setComponentConditionEntry sets the entry of the component in the message of the condition, the message
+// consists of the sorted "<component>: <entry>" items joined by "; ". The condition is removed if there is
+// no entry left. It returns whether the entry of the component is changed.
+func setComponentConditionEntry(tc *v1alpha1.TidbCluster, condType v1alpha1.TidbClusterConditionType, reason string, memberType v1alpha1.MemberType, entry string) bool {
+	prefix := fmt.Sprintf("%s: ", memberType)
+	var entries []string
+	old := ""
+	if cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, condType); cond != nil {
+		for _, item := range strings.Split(cond.Message, "; ") {
+			if strings.HasPrefix(item, prefix) {
+				old = strings.TrimPrefix(item, prefix)
+				continue
+			}
+			if item != "" {
+				entries = append(entries, item)
+			}
+		}
+	}
+	if entry != "" {
+		entries = append(entries, prefix+entry)
+	}
+	if len(entries) == 0 {
+		utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, condType)
+		return old != entry
+	}
+	sort.Strings(entries)
+	cond := utiltidbcluster.NewTidbClusterCondition(condType, corev1.ConditionTrue, reason, strings.Join(entries, "; "))
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	return old != entry
+}
+
+func sortedResourceNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+func formatResourceList(list corev1.ResourceList) string {
+	items := make([]string, 0, len(list))
+	for _, name := range sortedResourceNames(list) {
+		q := list[name]
+		items = append(items, fmt.Sprintf("%s=%s", name, q.String()))
+	}
+	return strings.Join(items, " ")
+}
+
+// formatNonNegative formats the quantity, the negative quantity, e.g. the available amount of an
+// over-committed quota, is formatted as 0
+func formatNonNegative(q resource.Quantity) string {
+	if q.Sign() < 0 {
+		return "0"
+	}
+	return q.String()
+}
@@ -0,0 +1,339 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func newStatefulSetForScaleOutCheck(replicas int32) *apps.StatefulSet {
+	return &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "tc-tikv", Namespace: corev1.NamespaceDefault},
+		Spec: apps.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(replicas),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{"pool": "tikv"},
+					Containers: []corev1.Container{{
+						Name: "tikv",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("4"),
+								corev1.ResourceMemory: resource.MustParse("16Gi"),
+							},
+						},
+					}},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "tikv"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					StorageClassName: pointer.StringPtr("ssd"),
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+					},
+				},
+			}},
+		},
+	}
+}
+
+func newNodeForScaleOutCheck(name, pool, cpu, memory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestSyncScaleOutResources(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name          string
+		quota         *corev1.ResourceQuota
+		nodes         []*corev1.Node
+		pods          []*corev1.Pod
+		affinity      *corev1.Affinity
+		existingPVC   bool
+		disabled      bool
+		featureGates  map[string]bool
+		expectBlocked bool
+		expectMessage []string
+	}
+	tests := []testcase{
+		{
+			name:  "no quota and enough capacity",
+			nodes: []*corev1.Node{newNodeForScaleOutCheck("node-1", "tikv", "8", "32Gi")},
+		},
+		{
+			name: "the quota of cpu is insufficient",
+			quota: &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: corev1.NamespaceDefault},
+				Status: corev1.ResourceQuotaStatus{
+					Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("10"), corev1.ResourceRequestsMemory: resource.MustParse("100Gi")},
+					Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("8"), corev1.ResourceRequestsMemory: resource.MustParse("64Gi")},
+				},
+			},
+			expectBlocked: true,
+			expectMessage: []string{"tikv: pod tc-tikv-3 can't be created", "requests.cpu of resourcequota quota requires 4 but 2 is available"},
+		},
+		{
+			name: "the quota of the storage class is insufficient",
+			quota: &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: corev1.NamespaceDefault},
+				Status: corev1.ResourceQuotaStatus{
+					Hard: corev1.ResourceList{"ssd.storageclass.storage.k8s.io/requests.storage": resource.MustParse("300Gi")},
+					Used: corev1.ResourceList{"ssd.storageclass.storage.k8s.io/requests.storage": resource.MustParse("300Gi")},
+				},
+			},
+			expectBlocked: true,
+			expectMessage: []string{"ssd.storageclass.storage.k8s.io/requests.storage of resourcequota quota requires 100Gi but 0 is available"},
+		},
+		{
+			name: "the existing pvc is reused",
+			quota: &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: corev1.NamespaceDefault},
+				Status: corev1.ResourceQuotaStatus{
+					Hard: corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("300Gi")},
+					Used: corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("300Gi")},
+				},
+			},
+			existingPVC: true,
+		},
+		{
+			name: "the quota with scopes is not evaluated",
+			quota: &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: corev1.NamespaceDefault},
+				Spec:       corev1.ResourceQuotaSpec{Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}},
+				Status: corev1.ResourceQuotaStatus{
+					Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")},
+					Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")},
+				},
+			},
+		},
+		{
+			name: "the capacity of the matching nodes is insufficient",
+			nodes: []*corev1.Node{
+				newNodeForScaleOutCheck("node-1", "tikv", "8", "32Gi"),
+				newNodeForScaleOutCheck("node-2", "tidb", "16", "64Gi"),
+			},
+			pods: []*corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"},
+				Spec: corev1.PodSpec{
+					NodeName: "node-1",
+					Containers: []corev1.Container{{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("6")},
+						},
+					}},
+				},
+			}},
+			expectBlocked: true,
+			expectMessage: []string{"cpu requests 4 but at most 2 is free on a node"},
+		},
+		{
+			name:          "no node matches the node selector",
+			nodes:         []*corev1.Node{newNodeForScaleOutCheck("node-1", "tidb", "8", "32Gi")},
+			expectBlocked: true,
+			expectMessage: []string{"no schedulable node satisfies the node selector, the affinity and the tolerations"},
+		},
+		{
+			name: "no node matches the required node affinity",
+			nodes: []*corev1.Node{
+				newNodeForScaleOutCheck("node-1", "tikv", "8", "32Gi"),
+			},
+			affinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      "zone",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"zone-a"},
+							}},
+						}},
+					},
+				},
+			},
+			expectBlocked: true,
+			expectMessage: []string{"no schedulable node satisfies the node selector, the affinity and the tolerations"},
+		},
+		{
+			name: "the nodes are occupied by the anti-affinity pods",
+			nodes: []*corev1.Node{
+				newNodeForScaleOutCheck("node-1", "tikv", "8", "32Gi"),
+				newNodeForScaleOutCheck("node-2", "tikv", "8", "32Gi"),
+			},
+			pods: []*corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "tc-tikv-0", Namespace: corev1.NamespaceDefault, Labels: map[string]string{"app": "tikv"}},
+					Spec:       corev1.PodSpec{NodeName: "node-1"},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "tc-tikv-1", Namespace: corev1.NamespaceDefault, Labels: map[string]string{"app": "tikv"}},
+					Spec:       corev1.PodSpec{NodeName: "node-2"},
+				},
+			},
+			affinity: &corev1.Affinity{
+				PodAntiAffinity: &corev1.PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "tikv"}},
+						TopologyKey:   "pool",
+					}},
+				},
+			},
+			expectBlocked: true,
+			expectMessage: []string{"no schedulable node satisfies the node selector, the affinity and the tolerations"},
+		},
+		{
+			name: "the anti-affinity pods are in another topology domain",
+			nodes: []*corev1.Node{
+				newNodeForScaleOutCheck("node-1", "tikv", "8", "32Gi"),
+			},
+			pods: []*corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{Name: "tc-tikv-0", Namespace: corev1.NamespaceDefault, Labels: map[string]string{"app": "tikv"}},
+				Spec:       corev1.PodSpec{NodeName: "node-1"},
+			}},
+			affinity: &corev1.Affinity{
+				PodAntiAffinity: &corev1.PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "tikv"}},
+						TopologyKey:   "pool",
+						Namespaces:    []string{"other"},
+					}},
+				},
+			},
+		},
+		{
+			name:     "the check is disabled",
+			nodes:    []*corev1.Node{newNodeForScaleOutCheck("node-1", "tidb", "8", "32Gi")},
+			disabled: true,
+		},
+		{
+			name:         "the check is disabled for the cluster",
+			nodes:        []*corev1.Node{newNodeForScaleOutCheck("node-1", "tidb", "8", "32Gi")},
+			featureGates: map[string]bool{features.ScaleOutResourceCheck: false},
+		},
+		{
+			name:          "the check is enabled for the cluster",
+			nodes:         []*corev1.Node{newNodeForScaleOutCheck("node-1", "tidb", "8", "32Gi")},
+			disabled:      true,
+			featureGates:  map[string]bool{features.ScaleOutResourceCheck: true},
+			expectBlocked: true,
+			expectMessage: []string{"no schedulable node satisfies the node selector, the affinity and the tolerations"},
+		},
+		{
+			name: "the quotas are listed from the api server if the check is only enabled for the cluster",
+			quota: &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: corev1.NamespaceDefault},
+				Status: corev1.ResourceQuotaStatus{
+					Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("10")},
+					Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("8")},
+				},
+			},
+			nodes:         []*corev1.Node{newNodeForScaleOutCheck("node-1", "tikv", "8", "32Gi")},
+			disabled:      true,
+			featureGates:  map[string]bool{features.ScaleOutResourceCheck: true},
+			expectBlocked: true,
+			expectMessage: []string{"requests.cpu of resourcequota quota requires 4 but 2 is available"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.name)
+		deps := controller.NewFakeDependencies()
+		deps.CLIConfig.ScaleOutResourceCheck = !test.disabled
+		// the quotas are only cached if the check is enabled by default
+		deps.ResourceQuotaLister = nil
+		if !test.disabled {
+			deps.ResourceQuotaLister = deps.KubeInformerFactory.Core().V1().ResourceQuotas().Lister()
+		}
+		if test.quota != nil {
+			g.Expect(deps.KubeInformerFactory.Core().V1().ResourceQuotas().Informer().GetIndexer().Add(test.quota)).To(Succeed())
+			_, err := deps.KubeClientset.CoreV1().ResourceQuotas(test.quota.Namespace).Create(context.TODO(), test.quota, metav1.CreateOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		for _, node := range test.nodes {
+			g.Expect(deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(node)).To(Succeed())
+		}
+		for _, pod := range test.pods {
+			g.Expect(deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+		}
+		if test.existingPVC {
+			pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "tikv-tc-tikv-3", Namespace: corev1.NamespaceDefault}}
+			g.Expect(deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)).To(Succeed())
+		}
+
+		tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Name: "tc", Namespace: corev1.NamespaceDefault}}
+		tc.Spec.FeatureGates = test.featureGates
+		oldSet := newStatefulSetForScaleOutCheck(3)
+		newSet := newStatefulSetForScaleOutCheck(4)
+		newSet.Spec.Template.Spec.Affinity = test.affinity
+		g.Expect(syncScaleOutResources(deps, tc, v1alpha1.TiKVMemberType, oldSet, newSet)).To(Succeed())
+
+		cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterScaleOutBlocked)
+		if !test.expectBlocked {
+			g.Expect(*newSet.Spec.Replicas).To(Equal(int32(4)))
+			g.Expect(cond).To(BeNil())
+			continue
+		}
+		g.Expect(*newSet.Spec.Replicas).To(Equal(int32(3)))
+		g.Expect(cond).NotTo(BeNil())
+		g.Expect(cond.Reason).To(Equal(utiltidbcluster.InsufficientResources))
+		for _, msg := range test.expectMessage {
+			g.Expect(cond.Message).To(ContainSubstring(msg))
+		}
+
+		// the condition is removed once the component doesn't need to scale out
+		g.Expect(syncScaleOutResources(deps, tc, v1alpha1.TiKVMemberType, oldSet, newStatefulSetForScaleOutCheck(3))).To(Succeed())
+		g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterScaleOutBlocked)).To(BeNil())
+	}
+}
+
+func TestSetScaleOutBlocked(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{}
+	g.Expect(setScaleOutBlocked(tc, v1alpha1.TiKVMemberType, "")).To(BeFalse())
+	g.Expect(setScaleOutBlocked(tc, v1alpha1.TiKVMemberType, "no cpu")).To(BeTrue())
+	g.Expect(setScaleOutBlocked(tc, v1alpha1.TiDBMemberType, "no memory")).To(BeTrue())
+	g.Expect(setScaleOutBlocked(tc, v1alpha1.TiKVMemberType, "no cpu")).To(BeFalse())
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterScaleOutBlocked)
+	g.Expect(cond.Message).To(Equal("tidb: no memory; tikv: no cpu"))
+
+	g.Expect(setScaleOutBlocked(tc, v1alpha1.TiDBMemberType, "")).To(BeTrue())
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterScaleOutBlocked)
+	g.Expect(cond.Message).To(Equal("tikv: no cpu"))
+	g.Expect(setScaleOutBlocked(tc, v1alpha1.TiKVMemberType, "")).To(BeTrue())
+	g.Expect(tc.Status.Conditions).To(BeEmpty())
+}
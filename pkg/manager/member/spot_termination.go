@@ -0,0 +1,207 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// spotTerminationEventReason is the reason of the events recorded when an instance is on a node going to be terminated
+	spotTerminationEventReason = "SpotTermination"
+)
+
+// getNodeTerminationTaint returns the key of the taint marking the node of the Pod as going to be terminated,
+// or an empty string if the node is not going to be terminated or the nodes can't be read by the operator
+func getNodeTerminationTaint(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, pod *corev1.Pod) string {
+	if !tc.IsSpotTerminationEnabled() || deps.NodeLister == nil || pod.Spec.NodeName == "" {
+		return ""
+	}
+	node, err := deps.NodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		return ""
+	}
+	for _, key := range tc.SpotTerminationTaints() {
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == key {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// isPodOnTerminatingNode returns whether the Pod is on a node going to be terminated
+func isPodOnTerminatingNode(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, podName string) bool {
+	pod, err := deps.PodLister.Pods(tc.GetNamespace()).Get(podName)
+	if err != nil {
+		return false
+	}
+	return getNodeTerminationTaint(deps, tc, pod) != ""
+}
+
+// syncTiKVSpotTermination evicts the region leaders of the Up stores on the nodes going to be terminated, and
+// records these stores as failure stores if the replacement Pods should be created in advance. The leader
+// eviction ends once the Pod is running on a node that is not going to be terminated.
+func syncTiKVSpotTermination(deps *controller.Dependencies, tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	for _, op := range tc.Status.InFlightOperations {
+		if op.Type != v1alpha1.InFlightOperationSpotTermination || op.Component != v1alpha1.TiKVMemberType {
+			continue
+		}
+		if _, exist := tc.Status.TiKV.Stores[op.StoreID]; !exist {
+			// the store has been deleted or become tombstone, the evict leader scheduler is removed by PD
+			tc.RemoveInFlightOperation(op.Type, op.Component, op.PodName)
+		}
+	}
+
+	for storeID, store := range tc.Status.TiKV.Stores {
+		pod, err := deps.PodLister.Pods(ns).Get(store.PodName)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("syncTiKVSpotTermination: failed to get pod %s/%s for tc %s, error: %s", ns, store.PodName, tcName, err)
+		}
+		taint := ""
+		if pod != nil {
+			taint = getNodeTerminationTaint(deps, tc, pod)
+		}
+		op := tc.GetInFlightOperation(v1alpha1.InFlightOperationSpotTermination, v1alpha1.TiKVMemberType, store.PodName)
+
+		if taint == "" {
+			if op == nil || pod == nil || !podutil.IsPodReady(pod) || store.State != v1alpha1.TiKVStateUp {
+				continue
+			}
+			id, err := strconv.ParseUint(storeID, 10, 64)
+			if err != nil {
+				return err
+			}
+			if err := endEvictLeaderbyStoreID(deps, tc, id); err != nil {
+				return err
+			}
+			tc.RemoveInFlightOperation(op.Type, op.Component, op.PodName)
+			continue
+		}
+
+		if op == nil && store.State == v1alpha1.TiKVStateUp {
+			id, err := strconv.ParseUint(storeID, 10, 64)
+			if err != nil {
+				return err
+			}
+			if err := controller.GetPDClient(deps.PDControl, tc).BeginEvictLeader(id); err != nil {
+				klog.Errorf("tikv: failed to begin evict leader for store %d of %s/%s on terminating node %s, error: %v", id, ns, tcName, pod.Spec.NodeName, err)
+				return err
+			}
+			tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+				Type:      v1alpha1.InFlightOperationSpotTermination,
+				Component: v1alpha1.TiKVMemberType,
+				PodName:   store.PodName,
+				StoreID:   storeID,
+			})
+			klog.Infof("tikv: begin evict leader for store %d of %s/%s as node %s is tainted by %s", id, ns, tcName, pod.Spec.NodeName, taint)
+			deps.Recorder.Eventf(tc, corev1.EventTypeWarning, spotTerminationEventReason,
+				"node %s of tikv pod %s is going to be terminated, evicting the region leaders of store %s", pod.Spec.NodeName, store.PodName, storeID)
+		}
+
+		if !tc.Spec.SpotTermination.PreCreateReplacement || !deps.CLIConfig.AutoFailover {
+			continue
+		}
+		if _, exist := tc.Status.TiKV.FailureStores[storeID]; exist {
+			continue
+		}
+		if tc.Spec.TiKV.MaxFailoverCount == nil || len(tc.Status.TiKV.FailureStores) >= int(*tc.Spec.TiKV.MaxFailoverCount) {
+			klog.Warningf("%s/%s failure stores count reached the limit, no replacement is created for tikv pod %s on terminating node", ns, tcName, store.PodName)
+			continue
+		}
+		if tc.Status.TiKV.FailureStores == nil {
+			tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{}
+		}
+		tc.Status.TiKV.FailureStores[storeID] = v1alpha1.TiKVFailureStore{
+			PodName:   store.PodName,
+			StoreID:   storeID,
+			CreatedAt: metav1.Now(),
+		}
+	}
+	return nil
+}
+
+// syncTiDBSpotTermination records the healthy TiDB members on the nodes going to be terminated as failure members,
+// so that the replacement Pods are created before the nodes are terminated
+func syncTiDBSpotTermination(deps *controller.Dependencies, tc *v1alpha1.TidbCluster) {
+	if !tc.IsSpotTerminationEnabled() || !tc.Spec.SpotTermination.PreCreateReplacement || !deps.CLIConfig.AutoFailover {
+		return
+	}
+	if tc.Spec.TiDB.MaxFailoverCount == nil || *tc.Spec.TiDB.MaxFailoverCount <= 0 {
+		return
+	}
+
+	for _, member := range tc.Status.TiDB.Members {
+		if _, exist := tc.Status.TiDB.FailureMembers[member.Name]; exist || !member.Health {
+			continue
+		}
+		if !isPodOnTerminatingNode(deps, tc, member.Name) {
+			continue
+		}
+		if len(tc.Status.TiDB.FailureMembers) >= int(*tc.Spec.TiDB.MaxFailoverCount) {
+			klog.Warningf("the failover count of tidb %s/%s reaches the limit (%d), no replacement is created for pod %s on terminating node",
+				tc.GetNamespace(), tc.GetName(), *tc.Spec.TiDB.MaxFailoverCount, member.Name)
+			return
+		}
+		if tc.Status.TiDB.FailureMembers == nil {
+			tc.Status.TiDB.FailureMembers = map[string]v1alpha1.TiDBFailureMember{}
+		}
+		tc.Status.TiDB.FailureMembers[member.Name] = v1alpha1.TiDBFailureMember{
+			PodName:   member.Name,
+			CreatedAt: metav1.Now(),
+		}
+		deps.Recorder.Eventf(tc, corev1.EventTypeWarning, spotTerminationEventReason,
+			"node of tidb pod %s is going to be terminated, creating a replacement pod", member.Name)
+	}
+}
+
+// hasFailureOnTerminatingNode returns whether any of the Pods is on a node going to be terminated, the failure records
+// of them must be kept until the nodes are terminated
+func hasFailureOnTerminatingNode(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, podNames []string) bool {
+	for _, podName := range podNames {
+		if isPodOnTerminatingNode(deps, tc, podName) {
+			return true
+		}
+	}
+	return false
+}
+
+func tikvFailureStorePodNames(tc *v1alpha1.TidbCluster) []string {
+	names := make([]string, 0, len(tc.Status.TiKV.FailureStores))
+	for _, failureStore := range tc.Status.TiKV.FailureStores {
+		names = append(names, failureStore.PodName)
+	}
+	return names
+}
+
+func tidbFailureMemberPodNames(tc *v1alpha1.TidbCluster) []string {
+	names := make([]string, 0, len(tc.Status.TiDB.FailureMembers))
+	for _, failureMember := range tc.Status.TiDB.FailureMembers {
+		names = append(names, failureMember.PodName)
+	}
+	return names
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func newSpotTerminationTidbCluster() *v1alpha1.TidbCluster {
+	return &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "tc", Namespace: corev1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:              &v1alpha1.PDSpec{Replicas: 3},
+			TiKV:            &v1alpha1.TiKVSpec{Replicas: 3, MaxFailoverCount: pointer.Int32Ptr(3)},
+			TiDB:            &v1alpha1.TiDBSpec{Replicas: 2, MaxFailoverCount: pointer.Int32Ptr(3)},
+			SpotTermination: &v1alpha1.SpotTerminationSpec{},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", PodName: "tc-tikv-0", State: v1alpha1.TiKVStateUp},
+					"2": {ID: "2", PodName: "tc-tikv-1", State: v1alpha1.TiKVStateUp},
+				},
+			},
+			TiDB: v1alpha1.TiDBStatus{
+				Members: map[string]v1alpha1.TiDBMember{
+					"tc-tidb-0": {Name: "tc-tidb-0", Health: true},
+					"tc-tidb-1": {Name: "tc-tidb-1", Health: true},
+				},
+			},
+		},
+	}
+}
+
+func addSpotTerminationPod(g *GomegaWithT, deps *controller.Dependencies, name, nodeName string) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: corev1.NamespaceDefault},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	g.Expect(deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Update(pod)).To(Succeed())
+}
+
+func addSpotTerminationNode(g *GomegaWithT, deps *controller.Dependencies, name string, taints ...string) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	for _, key := range taints {
+		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{Key: key, Effect: corev1.TaintEffectNoSchedule})
+	}
+	g.Expect(deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Update(node)).To(Succeed())
+}
+
+func TestSyncTiKVSpotTermination(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newSpotTerminationTidbCluster()
+	addSpotTerminationNode(g, deps, "node-1")
+	addSpotTerminationNode(g, deps, "node-2", "aws-node-termination-handler/spot-itn")
+	addSpotTerminationNode(g, deps, "node-3")
+	addSpotTerminationPod(g, deps, "tc-tikv-0", "node-1")
+	addSpotTerminationPod(g, deps, "tc-tikv-1", "node-2")
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	var evicting, ended []uint64
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		evicting = append(evicting, action.ID)
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		ended = append(ended, action.ID)
+		return nil, nil
+	})
+
+	// the leaders of the store on the terminating node are evicted only once
+	g.Expect(syncTiKVSpotTermination(deps, tc)).To(Succeed())
+	g.Expect(syncTiKVSpotTermination(deps, tc)).To(Succeed())
+	g.Expect(evicting).To(Equal([]uint64{2}))
+	op := tc.GetInFlightOperation(v1alpha1.InFlightOperationSpotTermination, v1alpha1.TiKVMemberType, "tc-tikv-1")
+	g.Expect(op).NotTo(BeNil())
+	g.Expect(op.StoreID).To(Equal("2"))
+	g.Expect(tc.Status.TiKV.FailureStores).To(BeEmpty())
+
+	// the replacement is created in advance
+	tc.Spec.SpotTermination.PreCreateReplacement = true
+	g.Expect(syncTiKVSpotTermination(deps, tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.FailureStores).To(HaveKey("2"))
+	g.Expect(hasFailureOnTerminatingNode(deps, tc, tikvFailureStorePodNames(tc))).To(BeTrue())
+
+	// the eviction ends once the pod is running on another node
+	addSpotTerminationPod(g, deps, "tc-tikv-1", "node-3")
+	g.Expect(syncTiKVSpotTermination(deps, tc)).To(Succeed())
+	g.Expect(ended).To(Equal([]uint64{2}))
+	g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationSpotTermination, v1alpha1.TiKVMemberType, "tc-tikv-1")).To(BeNil())
+	g.Expect(hasFailureOnTerminatingNode(deps, tc, tikvFailureStorePodNames(tc))).To(BeFalse())
+
+	// the operation of a deleted store is removed
+	tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+		Type:      v1alpha1.InFlightOperationSpotTermination,
+		Component: v1alpha1.TiKVMemberType,
+		PodName:   "tc-tikv-2",
+		StoreID:   "3",
+	})
+	g.Expect(syncTiKVSpotTermination(deps, tc)).To(Succeed())
+	g.Expect(tc.Status.InFlightOperations).To(BeEmpty())
+}
+
+func TestSyncTiDBSpotTermination(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newSpotTerminationTidbCluster()
+	tc.Spec.SpotTermination.Taints = []string{"node.example.com/terminating"}
+	addSpotTerminationNode(g, deps, "node-1", "aws-node-termination-handler/spot-itn")
+	addSpotTerminationNode(g, deps, "node-2", "node.example.com/terminating")
+	addSpotTerminationPod(g, deps, "tc-tidb-0", "node-1")
+	addSpotTerminationPod(g, deps, "tc-tidb-1", "node-2")
+
+	// no replacement is created unless required
+	syncTiDBSpotTermination(deps, tc)
+	g.Expect(tc.Status.TiDB.FailureMembers).To(BeEmpty())
+
+	tc.Spec.SpotTermination.PreCreateReplacement = true
+	syncTiDBSpotTermination(deps, tc)
+	g.Expect(tc.Status.TiDB.FailureMembers).To(HaveLen(1))
+	g.Expect(tc.Status.TiDB.FailureMembers).To(HaveKey("tc-tidb-1"))
+
+	// the healthy failure member on the terminating node is kept by failover
+	tidbFailover := &tidbFailover{deps: deps}
+	g.Expect(tidbFailover.Failover(tc)).To(Succeed())
+	g.Expect(tc.Status.TiDB.FailureMembers).To(HaveKey("tc-tidb-1"))
+
+	// the failure member is removed once the pod is running on another node
+	addSpotTerminationPod(g, deps, "tc-tidb-1", "node-1")
+	tc.Spec.SpotTermination.Taints = nil
+	addSpotTerminationNode(g, deps, "node-1")
+	g.Expect(tidbFailover.Failover(tc)).To(Succeed())
+	g.Expect(tc.Status.TiDB.FailureMembers).To(BeEmpty())
+}
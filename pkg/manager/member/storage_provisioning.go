@@ -0,0 +1,256 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+	apps "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// storageProvisioningWebhookTimeout is the timeout of a call of the storage provisioning webhook
+	storageProvisioningWebhookTimeout = 10 * time.Second
+)
+
+// storageProvisioningRequest is the body of the request to the storage provisioning webhook
+type storageProvisioningRequest struct {
+	Namespace        string `json:"namespace"`
+	Cluster          string `json:"cluster"`
+	Component        string `json:"component"`
+	Replicas         int32  `json:"replicas"`
+	StorageClassName string `json:"storageClassName,omitempty"`
+}
+
+// storageProvisioningResponse is the body of the response of the storage provisioning webhook
+type storageProvisioningResponse struct {
+	Ready   bool   `json:"ready"`
+	Message string `json:"message,omitempty"`
+}
+
+// syncStorageProvisioning runs the storage provisioning hook of the component before its StatefulSet newSet
+// is created. It returns nil once the hook signals the storage is ready, and an error otherwise, so that the
+// StatefulSet is not created until the storage is ready.
+func syncStorageProvisioning(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, newSet *apps.StatefulSet) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	// drop the status of the components no longer provisioned, so that a failed hook can be retried by
+	// removing the component from the components and adding it back
+	var statuses []v1alpha1.StorageProvisioningStatus
+	for _, status := range tc.Status.StorageProvisioning {
+		if tc.IsStorageProvisioningEnabled(status.Component) {
+			statuses = append(statuses, status)
+		}
+	}
+	tc.Status.StorageProvisioning = statuses
+
+	if !tc.IsStorageProvisioningEnabled(memberType) {
+		return nil
+	}
+
+	var status *v1alpha1.StorageProvisioningStatus
+	for i := range tc.Status.StorageProvisioning {
+		if tc.Status.StorageProvisioning[i].Component == memberType {
+			status = &tc.Status.StorageProvisioning[i]
+			break
+		}
+	}
+	if status == nil {
+		tc.Status.StorageProvisioning = append(tc.Status.StorageProvisioning, v1alpha1.StorageProvisioningStatus{
+			Component: memberType,
+			Phase:     v1alpha1.StorageProvisioningRunning,
+			StartTime: metav1.Now(),
+		})
+		status = &tc.Status.StorageProvisioning[len(tc.Status.StorageProvisioning)-1]
+		klog.Infof("TidbCluster: [%s/%s], start provisioning the storage of %s", ns, tcName, memberType)
+	}
+
+	switch status.Phase {
+	case v1alpha1.StorageProvisioningReady:
+		return nil
+	case v1alpha1.StorageProvisioningFailed:
+		return fmt.Errorf("TidbCluster: [%s/%s], failed to provision the storage of %s: %s", ns, tcName, memberType, status.Message)
+	}
+
+	fail := func(message string) error {
+		status.Phase = v1alpha1.StorageProvisioningFailed
+		status.Message = message
+		deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "StorageProvisioningFailed", "failed to provision the storage of %s: %s", memberType, message)
+		return fmt.Errorf("TidbCluster: [%s/%s], failed to provision the storage of %s: %s", ns, tcName, memberType, message)
+	}
+	if timeout := tc.StorageProvisioningTimeout(); time.Since(status.StartTime.Time) > timeout {
+		return fail(fmt.Sprintf("timed out after %s, last message: %s", timeout, status.Message))
+	}
+
+	var ready bool
+	var attemptErr, err error
+	spec := tc.Spec.StorageProvisioning
+	if spec.Webhook != nil {
+		ready, attemptErr = callStorageProvisioningWebhook(tc, memberType, newSet, status)
+	} else if spec.Job != nil {
+		ready, attemptErr, err = runStorageProvisioningJob(deps, tc, memberType, newSet, status)
+	} else {
+		return fail("neither webhook nor job is set")
+	}
+	if err != nil {
+		return err
+	}
+	if attemptErr != nil {
+		status.Failures++
+		status.Message = attemptErr.Error()
+		klog.Warningf("TidbCluster: [%s/%s], attempt %d of provisioning the storage of %s failed, error: %v", ns, tcName, status.Failures, memberType, attemptErr)
+		if status.Failures > tc.StorageProvisioningMaxRetries() {
+			return fail(fmt.Sprintf("failed %d times, last error: %v", status.Failures, attemptErr))
+		}
+		return controller.RequeueErrorf("TidbCluster: [%s/%s], retrying to provision the storage of %s", ns, tcName, memberType)
+	}
+	if !ready {
+		return controller.RequeueErrorf("TidbCluster: [%s/%s], waiting for the storage of %s to be provisioned", ns, tcName, memberType)
+	}
+
+	status.Phase = v1alpha1.StorageProvisioningReady
+	deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "StorageProvisioned", "the storage of %s is provisioned", memberType)
+	klog.Infof("TidbCluster: [%s/%s], the storage of %s is provisioned", ns, tcName, memberType)
+	return nil
+}
+
+// callStorageProvisioningWebhook calls the webhook and returns whether the storage is ready
+func callStorageProvisioningWebhook(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, newSet *apps.StatefulSet, status *v1alpha1.StorageProvisioningStatus) (bool, error) {
+	body, err := json.Marshal(storageProvisioningRequest{
+		Namespace:        tc.GetNamespace(),
+		Cluster:          tc.GetName(),
+		Component:        memberType.String(),
+		Replicas:         *newSet.Spec.Replicas,
+		StorageClassName: storageClassNameOf(newSet),
+	})
+	if err != nil {
+		return false, err
+	}
+	httpClient := &http.Client{Timeout: storageProvisioningWebhookTimeout}
+	res, err := httpClient.Post(tc.Spec.StorageProvisioning.Webhook.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	defer httputil.DeferClose(res.Body)
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return false, err
+	}
+	if res.StatusCode >= 300 {
+		return false, fmt.Errorf("error response %v, body response: %s", res.StatusCode, string(resBody))
+	}
+	var resp storageProvisioningResponse
+	if err := json.Unmarshal(resBody, &resp); err != nil {
+		return false, fmt.Errorf("failed to decode the response %q, error: %v", string(resBody), err)
+	}
+	status.Message = resp.Message
+	return resp.Ready, nil
+}
+
+// runStorageProvisioningJob runs a Job for each attempt and returns whether the storage is ready, i.e.
+// the Job of the current attempt completes, and the error of the attempt if the Job fails
+func runStorageProvisioningJob(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, newSet *apps.StatefulSet, status *v1alpha1.StorageProvisioningStatus) (bool, error, error) {
+	ns := tc.GetNamespace()
+	jobName := fmt.Sprintf("%s-%s-storage-provisioning-%d", tc.GetName(), memberType, status.Failures)
+
+	job, err := deps.JobLister.Jobs(ns).Get(jobName)
+	if errors.IsNotFound(err) {
+		job = makeStorageProvisioningJob(tc, memberType, newSet, jobName)
+		if err := deps.JobControl.CreateJob(tc, job); err != nil && !errors.IsAlreadyExists(err) {
+			return false, nil, err
+		}
+		status.Message = fmt.Sprintf("job %s is created", jobName)
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to get job %s for tc %s/%s, error: %v", jobName, ns, tc.GetName(), err)
+	}
+	cond := getJobFinishedCondition(job)
+	if cond == nil {
+		return false, nil, nil
+	}
+	if cond.Type == batchv1.JobFailed {
+		return false, fmt.Errorf("job %s failed: %s", jobName, cond.Message), nil
+	}
+	status.Message = fmt.Sprintf("job %s completed", jobName)
+	return true, nil, nil
+}
+
+func makeStorageProvisioningJob(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, newSet *apps.StatefulSet, jobName string) *batchv1.Job {
+	spec := tc.Spec.StorageProvisioning.Job
+	jobLabel := label.NewStorageProvisioning().Instance(tc.GetInstanceName())
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            jobName,
+			Namespace:       tc.GetNamespace(),
+			Labels:          jobLabel,
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: batchv1.JobSpec{
+			// each Job is an attempt of the hook, the retries are counted by the operator
+			BackoffLimit: pointer.Int32Ptr(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabel,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: spec.ServiceAccountName,
+					ImagePullSecrets:   tc.Spec.ImagePullSecrets,
+					Containers: []corev1.Container{
+						{
+							Name:    "storage-provisioning",
+							Image:   spec.Image,
+							Command: spec.Command,
+							Env: []corev1.EnvVar{
+								{Name: "NAMESPACE", Value: tc.GetNamespace()},
+								{Name: "CLUSTER_NAME", Value: tc.GetName()},
+								{Name: "COMPONENT", Value: memberType.String()},
+								{Name: "REPLICAS", Value: strconv.Itoa(int(*newSet.Spec.Replicas))},
+								{Name: "STORAGE_CLASS_NAME", Value: storageClassNameOf(newSet)},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+}
+
+// storageClassNameOf returns the storage class of the first volume claim template of the StatefulSet
+func storageClassNameOf(set *apps.StatefulSet) string {
+	for _, pvc := range set.Spec.VolumeClaimTemplates {
+		if pvc.Spec.StorageClassName != nil {
+			return *pvc.Spec.StorageClassName
+		}
+	}
+	return ""
+}
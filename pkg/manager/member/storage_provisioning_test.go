@@ -0,0 +1,187 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func newStatefulSetForStorageProvisioning() *apps.StatefulSet {
+	return &apps.StatefulSet{
+		Spec: apps.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(3),
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.StringPtr("local-lvm")}},
+			},
+		},
+	}
+}
+
+func TestSyncStorageProvisioningWebhook(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var requests []storageProvisioningRequest
+	var resp storageProvisioningResponse
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req storageProvisioningRequest
+		g.Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+		requests = append(requests, req)
+		w.WriteHeader(statusCode)
+		g.Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+	}))
+	defer server.Close()
+
+	fakeDeps := controller.NewFakeDependencies()
+	tc := newTidbClusterForPD()
+	set := newStatefulSetForStorageProvisioning()
+
+	// disabled
+	g.Expect(syncStorageProvisioning(fakeDeps, tc, v1alpha1.PDMemberType, set)).To(Succeed())
+	g.Expect(requests).To(BeEmpty())
+
+	tc.Spec.StorageProvisioning = &v1alpha1.StorageProvisioningSpec{
+		Components: []v1alpha1.MemberType{v1alpha1.TiKVMemberType},
+		Webhook:    &v1alpha1.StorageProvisioningWebhook{URL: server.URL},
+		MaxRetries: pointer.Int32Ptr(1),
+	}
+	// not enabled for PD
+	g.Expect(syncStorageProvisioning(fakeDeps, tc, v1alpha1.PDMemberType, set)).To(Succeed())
+	g.Expect(requests).To(BeEmpty())
+
+	// waiting for the storage
+	resp = storageProvisioningResponse{Message: "creating the volumes"}
+	err := syncStorageProvisioning(fakeDeps, tc, v1alpha1.TiKVMemberType, set)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(requests).To(Equal([]storageProvisioningRequest{
+		{Namespace: tc.Namespace, Cluster: tc.Name, Component: "tikv", Replicas: 3, StorageClassName: "local-lvm"},
+	}))
+	g.Expect(tc.Status.StorageProvisioning).To(HaveLen(1))
+	g.Expect(tc.Status.StorageProvisioning[0].Phase).To(Equal(v1alpha1.StorageProvisioningRunning))
+	g.Expect(tc.Status.StorageProvisioning[0].Message).To(Equal("creating the volumes"))
+
+	// a failed call is retried
+	statusCode = http.StatusInternalServerError
+	err = syncStorageProvisioning(fakeDeps, tc, v1alpha1.TiKVMemberType, set)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tc.Status.StorageProvisioning[0].Failures).To(Equal(int32(1)))
+
+	// ready
+	statusCode = http.StatusOK
+	resp = storageProvisioningResponse{Ready: true}
+	g.Expect(syncStorageProvisioning(fakeDeps, tc, v1alpha1.TiKVMemberType, set)).To(Succeed())
+	g.Expect(tc.Status.StorageProvisioning[0].Phase).To(Equal(v1alpha1.StorageProvisioningReady))
+	g.Expect(syncStorageProvisioning(fakeDeps, tc, v1alpha1.TiKVMemberType, set)).To(Succeed())
+	g.Expect(requests).To(HaveLen(3))
+
+	// failed after the max retries
+	tc.Status.StorageProvisioning = nil
+	statusCode = http.StatusInternalServerError
+	g.Expect(controller.IsRequeueError(syncStorageProvisioning(fakeDeps, tc, v1alpha1.TiKVMemberType, set))).To(BeTrue())
+	err = syncStorageProvisioning(fakeDeps, tc, v1alpha1.TiKVMemberType, set)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(controller.IsRequeueError(err)).To(BeFalse())
+	g.Expect(tc.Status.StorageProvisioning[0].Phase).To(Equal(v1alpha1.StorageProvisioningFailed))
+	g.Expect(syncStorageProvisioning(fakeDeps, tc, v1alpha1.TiKVMemberType, set)).NotTo(Succeed())
+	g.Expect(requests).To(HaveLen(5))
+
+	// retried once the component is removed and added back
+	tc.Spec.StorageProvisioning.Components = []v1alpha1.MemberType{v1alpha1.TiFlashMemberType}
+	g.Expect(syncStorageProvisioning(fakeDeps, tc, v1alpha1.TiKVMemberType, set)).To(Succeed())
+	g.Expect(tc.Status.StorageProvisioning).To(BeEmpty())
+
+	// timed out
+	tc.Spec.StorageProvisioning.Components = nil
+	tc.Spec.StorageProvisioning.Timeout = &metav1.Duration{Duration: time.Minute}
+	tc.Status.StorageProvisioning = []v1alpha1.StorageProvisioningStatus{
+		{Component: v1alpha1.TiKVMemberType, Phase: v1alpha1.StorageProvisioningRunning, StartTime: metav1.NewTime(time.Now().Add(-time.Hour))},
+	}
+	g.Expect(syncStorageProvisioning(fakeDeps, tc, v1alpha1.TiKVMemberType, set)).NotTo(Succeed())
+	g.Expect(tc.Status.StorageProvisioning[0].Phase).To(Equal(v1alpha1.StorageProvisioningFailed))
+	g.Expect(tc.Status.StorageProvisioning[0].Message).To(HavePrefix("timed out after 1m0s"))
+	g.Expect(requests).To(HaveLen(5))
+}
+
+func TestSyncStorageProvisioningJob(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	jobIndexer := fakeDeps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer()
+	tc := newTidbClusterForPD()
+	tc.Spec.StorageProvisioning = &v1alpha1.StorageProvisioningSpec{
+		Job: &v1alpha1.StorageProvisioningJob{
+			Image:              "platform/lvm-provisioner",
+			ServiceAccountName: "lvm-provisioner",
+		},
+	}
+	set := newStatefulSetForStorageProvisioning()
+	getJob := func(name string) *batchv1.Job {
+		job, err := fakeDeps.JobLister.Jobs(tc.Namespace).Get(name)
+		g.Expect(err).NotTo(HaveOccurred())
+		return job
+	}
+	finish := func(job *batchv1.Job, condType batchv1.JobConditionType) {
+		job = job.DeepCopy()
+		job.Status.Conditions = []batchv1.JobCondition{{Type: condType, Status: corev1.ConditionTrue, Message: "exit code 1"}}
+		g.Expect(jobIndexer.Update(job)).To(Succeed())
+	}
+
+	// the Job of the first attempt is created
+	err := syncStorageProvisioning(fakeDeps, tc, v1alpha1.PDMemberType, set)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	job := getJob("test-pd-storage-provisioning-0")
+	g.Expect(job.OwnerReferences).To(HaveLen(1))
+	g.Expect(*job.Spec.BackoffLimit).To(Equal(int32(0)))
+	podSpec := job.Spec.Template.Spec
+	g.Expect(podSpec.ServiceAccountName).To(Equal("lvm-provisioner"))
+	g.Expect(podSpec.Containers[0].Image).To(Equal("platform/lvm-provisioner"))
+	g.Expect(podSpec.Containers[0].Env).To(ContainElements(
+		corev1.EnvVar{Name: "COMPONENT", Value: "pd"},
+		corev1.EnvVar{Name: "REPLICAS", Value: "3"},
+		corev1.EnvVar{Name: "STORAGE_CLASS_NAME", Value: "local-lvm"},
+	))
+
+	// running
+	g.Expect(controller.IsRequeueError(syncStorageProvisioning(fakeDeps, tc, v1alpha1.PDMemberType, set))).To(BeTrue())
+	g.Expect(tc.Status.StorageProvisioning[0].Failures).To(BeZero())
+
+	// the Job of the next attempt is created once the Job fails
+	finish(job, batchv1.JobFailed)
+	g.Expect(controller.IsRequeueError(syncStorageProvisioning(fakeDeps, tc, v1alpha1.PDMemberType, set))).To(BeTrue())
+	g.Expect(tc.Status.StorageProvisioning[0].Failures).To(Equal(int32(1)))
+	g.Expect(tc.Status.StorageProvisioning[0].Message).To(Equal("job test-pd-storage-provisioning-0 failed: exit code 1"))
+	g.Expect(controller.IsRequeueError(syncStorageProvisioning(fakeDeps, tc, v1alpha1.PDMemberType, set))).To(BeTrue())
+	job = getJob("test-pd-storage-provisioning-1")
+
+	// ready once the Job completes
+	finish(job, batchv1.JobComplete)
+	g.Expect(syncStorageProvisioning(fakeDeps, tc, v1alpha1.PDMemberType, set)).To(Succeed())
+	g.Expect(tc.Status.StorageProvisioning[0].Phase).To(Equal(v1alpha1.StorageProvisioningReady))
+	events := collectEvents(fakeDeps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ContainElement(ContainSubstring("StorageProvisioned")))
+}
@@ -17,6 +17,8 @@ import (
 	"bytes"
 	"fmt"
 	"text/template"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 )
 
 // TODO(aylei): it is hard to maintain script in go literal, we should figure out a better solution
@@ -96,6 +98,9 @@ type TidbStartScriptModel struct {
 	PluginList      string
 	ClusterDomain   string
 	Path            string
+
+	// StartScriptVersion is the version of the start script to render
+	StartScriptVersion v1alpha1.StartScriptVersion
 }
 
 func (t *TidbStartScriptModel) FormatClusterDomain() string {
@@ -106,7 +111,7 @@ func (t *TidbStartScriptModel) FormatClusterDomain() string {
 }
 
 func RenderTiDBStartScript(model *TidbStartScriptModel) (string, error) {
-	return renderTemplateFunc(tidbStartScriptTpl, model)
+	return renderTemplateFunc(startScriptTemplate(model.StartScriptVersion, tidbStartScriptTpl, tidbStartScriptV2Tpl), model)
 }
 
 // pdStartScriptTpl is the pd start script
@@ -174,8 +179,8 @@ ARGS="--data-dir={{ .DataDir }} \
 --name={{- if .ClusterDomain }}${domain}{{- else }}${POD_NAME}{{- end }} \
 --peer-urls={{ .Scheme }}://0.0.0.0:2380 \
 --advertise-peer-urls={{ .Scheme }}://${domain}:2380 \
---client-urls={{ .Scheme }}://0.0.0.0:2379 \
---advertise-client-urls={{ .Scheme }}://${domain}:2379 \
+--client-urls={{ .Scheme }}://0.0.0.0:{{ .ClientPort }} \
+--advertise-client-urls={{ .Scheme }}://${domain}:{{ .ClientPort }} \
 --config=/etc/pd/pd.toml \
 "
 
@@ -207,6 +212,10 @@ type PDStartScriptModel struct {
 	Scheme        string
 	DataDir       string
 	ClusterDomain string
+	ClientPort    int32
+
+	// StartScriptVersion is the version of the start script to render
+	StartScriptVersion v1alpha1.StartScriptVersion
 }
 
 func (p *PDStartScriptModel) FormatClusterDomain() string {
@@ -217,7 +226,7 @@ func (p *PDStartScriptModel) FormatClusterDomain() string {
 }
 
 func RenderPDStartScript(model *PDStartScriptModel) (string, error) {
-	return renderTemplateFunc(pdStartScriptTpl, model)
+	return renderTemplateFunc(startScriptTemplate(model.StartScriptVersion, pdStartScriptTpl, pdStartScriptV2Tpl), model)
 }
 
 var tikvStartScriptTpl = template.Must(template.New("tikv-start-script").Parse(`#!/bin/sh
@@ -247,6 +256,15 @@ then
 	echo "entering debug mode."
 	tail -f /dev/null
 fi
+{{- if .EnablePerPodConfig }}
+
+# Use the configuration file of the Pod if it has a configuration patch
+config_file="/etc/tikv/tikv.toml"
+if [[ -f "/etc/tikv-per-pod/tikv-${HOSTNAME##*-}.toml" ]]
+then
+    config_file="/etc/tikv-per-pod/tikv-${HOSTNAME##*-}.toml"
+fi
+{{- end }}
 
 # Use HOSTNAME if POD_NAME is unset for backward compatibility.
 POD_NAME=${POD_NAME:-$HOSTNAME}{{ if .FormatClusterDomain }}
@@ -262,13 +280,13 @@ done
 ARGS="--pd=${result} \
 {{ else }}
 ARGS="--pd={{ .PDAddress }} \{{ end }}
---advertise-addr=${POD_NAME}.${HEADLESS_SERVICE_NAME}.${NAMESPACE}.svc{{ .FormatClusterDomain }}:20160 \
---addr=0.0.0.0:20160 \
+--advertise-addr=${POD_NAME}.${HEADLESS_SERVICE_NAME}.${NAMESPACE}.svc{{ .FormatClusterDomain }}:{{ .Port }} \
+--addr=0.0.0.0:{{ .Port }} \
 --status-addr=0.0.0.0:20180 \{{if .EnableAdvertiseStatusAddr }}
 --advertise-status-addr={{ .AdvertiseStatusAddr }}:20180 \{{end}}
 --data-dir={{ .DataDir }} \
 --capacity=${CAPACITY} \
---config=/etc/tikv/tikv.toml
+--config={{ if .EnablePerPodConfig }}${config_file}{{ else }}/etc/tikv/tikv.toml{{ end }}
 "
 
 if [ ! -z "${STORE_LABELS:-}" ]; then
@@ -287,6 +305,12 @@ type TiKVStartScriptModel struct {
 	DataDir                   string
 	ClusterDomain             string
 	PDAddress                 string
+	Port                      int32
+	// EnablePerPodConfig indicates whether to use the configuration file of the Pod if it has a configuration patch
+	EnablePerPodConfig bool
+
+	// StartScriptVersion is the version of the start script to render
+	StartScriptVersion v1alpha1.StartScriptVersion
 }
 
 func (t *TiKVStartScriptModel) FormatClusterDomain() string {
@@ -297,13 +321,13 @@ func (t *TiKVStartScriptModel) FormatClusterDomain() string {
 }
 
 func RenderTiKVStartScript(model *TiKVStartScriptModel) (string, error) {
-	return renderTemplateFunc(tikvStartScriptTpl, model)
+	return renderTemplateFunc(startScriptTemplate(model.StartScriptVersion, tikvStartScriptTpl, tikvStartScriptV2Tpl), model)
 }
 
 // pumpStartScriptTpl is the template string of pump start script
 // Note: changing this will cause a rolling-update of pump cluster
 var pumpStartScriptTpl = template.Must(template.New("pump-start-script").Parse(`{{ if .FormatClusterDomain }}
-pd_url="{{ .Scheme }}://{{ .ClusterName }}-pd:2379"
+pd_url="{{ .Scheme }}://{{ .ClusterName }}-pd:{{ .PDPort }}"
 encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
 discovery_url="{{ .ClusterName }}-discovery.{{ .Namespace }}:10261"
 until result=$(wget -qO- -T 3 http://${discovery_url}/verify/${encoded_domain_url} 2>/dev/null); do
@@ -319,7 +343,7 @@ set -euo pipefail
 -pd-urls=$pd_url \{{ else }}set -euo pipefail
 
 /pump \
--pd-urls={{ .Scheme }}://{{ .ClusterName }}-pd:2379 \{{ end }}
+-pd-urls={{ .Scheme }}://{{ .ClusterName }}-pd:{{ .PDPort }} \{{ end }}
 -L={{ .LogLevel }} \
 -advertise-addr=` + "`" + `echo ${HOSTNAME}` + "`" + `.{{ .ClusterName }}-pump{{ .FormatPumpZone }}:8250 \
 -config=/etc/pump/pump.toml \
@@ -337,6 +361,7 @@ type PumpStartScriptModel struct {
 	LogLevel      string
 	Namespace     string
 	ClusterDomain string
+	PDPort        int32
 }
 
 func (pssm *PumpStartScriptModel) FormatClusterDomain() string {
@@ -362,7 +387,7 @@ func RenderPumpStartScript(model *PumpStartScriptModel) (string, error) {
 var tidbInitStartScriptTpl = template.Must(template.New("tidb-init-start-script").Parse(`import os, sys, time, MySQLdb
 host = '{{ .ClusterName }}-tidb'
 permit_host = '{{ .PermitHost }}'
-port = 4000
+port = {{ .Port }}
 retry_count = 0
 for i in range(0, 10):
     try:
@@ -400,6 +425,27 @@ with open('/data/init.sql', 'r') as sql:
         conn.cursor().execute(line)
         conn.commit()
 {{- end }}
+{{- if .InitSQLStorage }}
+scripts = []
+for root, dirs, files in os.walk('{{ .SQLStorageDir }}'):
+    for file in files:
+        if file.endswith('.sql'):
+            scripts.append(os.path.join(root, file))
+for script in sorted(scripts):
+    print('executing ' + script)
+    with open(script, 'r') as sql:
+        stmt = ''
+        for line in sql:
+            if not stmt.strip() and (not line.strip() or line.strip().startswith('--')):
+                continue
+            stmt += line
+            if line.rstrip().endswith(';'):
+                conn.cursor().execute(stmt)
+                stmt = ''
+        if stmt.strip():
+            conn.cursor().execute(stmt)
+    conn.commit()
+{{- end }}
 if permit_host != '%%':
     conn.cursor().execute("update mysql.user set Host=%s where User='root';", (permit_host,))
 conn.cursor().execute("flush privileges;")
@@ -409,6 +455,7 @@ conn.close()
 
 type TiDBInitStartScriptModel struct {
 	ClusterName string
+	Port        int32
 	PermitHost  string
 	PasswordSet bool
 	InitSQL     bool
@@ -416,6 +463,9 @@ type TiDBInitStartScriptModel struct {
 	CAPath      string
 	CertPath    string
 	KeyPath     string
+	// InitSQLStorage executes the SQL scripts downloaded from the storage to SQLStorageDir
+	InitSQLStorage bool
+	SQLStorageDir  string
 }
 
 func RenderTiDBInitStartScript(model *TiDBInitStartScriptModel) (string, error) {
@@ -425,7 +475,7 @@ func RenderTiDBInitStartScript(model *TiDBInitStartScriptModel) (string, error)
 // tidbInitInitStartScriptTpl is the template string of tidb initializer init container start script
 var tidbInitInitStartScriptTpl = template.Must(template.New("tidb-init-init-start-script").Parse(`trap exit TERM
 host={{ .ClusterName }}-tidb
-port=4000
+port={{ .Port }}
 while true; do
   nc -zv -w 3 $host $port
   if [ $? -eq 0 ]; then
@@ -440,6 +490,7 @@ echo "info: successfully connected to $host:$port, able to initialize TiDB now"
 
 type TiDBInitInitStartScriptModel struct {
 	ClusterName string
+	Port        int32
 }
 
 func RenderTiDBInitInitStartScript(model *TiDBInitInitStartScriptModel) (string, error) {
@@ -490,9 +541,9 @@ POD_NAME=${POD_NAME:-$HOSTNAME}
 # the general form of variable PEER_SERVICE_NAME is: "<clusterName>-dm-master-peer"
 cluster_name=` + "`" + `echo ${PEER_SERVICE_NAME} | sed 's/-dm-master-peer//'` + "`" +
 	`
-domain="${POD_NAME}.${PEER_SERVICE_NAME}"
+domain="${POD_NAME}.${PEER_SERVICE_NAME}{{ if .ClusterDomain }}.${NAMESPACE}.svc.{{ .ClusterDomain }}{{ end }}"
 discovery_url="${cluster_name}-dm-discovery.${NAMESPACE}:10261"
-encoded_domain_url=` + "`" + `echo ${domain}:8291 | base64 | tr "\n" " " | sed "s/ //g"` + "`" +
+encoded_domain_url=` + "`" + `echo ${domain}:{{ .PeerPort }} | base64 | tr "\n" " " | sed "s/ //g"` + "`" +
 	`
 elapseTime=0
 period=1
@@ -518,10 +569,10 @@ done
 
 ARGS="--data-dir={{ .DataDir }} \
 --name=${POD_NAME} \
---peer-urls={{ .Scheme }}://0.0.0.0:8291 \
---advertise-peer-urls={{ .Scheme }}://${domain}:8291 \
---master-addr=:8261 \
---advertise-addr=${domain}:8261 \
+--peer-urls={{ .Scheme }}://0.0.0.0:{{ .PeerPort }} \
+--advertise-peer-urls={{ .Scheme }}://${domain}:{{ .PeerPort }} \
+--master-addr=:{{ .Port }} \
+--advertise-addr=${domain}:{{ .Port }} \
 --config=/etc/dm-master/dm-master.toml \
 "
 
@@ -531,16 +582,21 @@ then
 #   demo-dm-master-0=http://demo-dm-master-0.demo-dm-master-peer.demo.svc:8291,demo-dm-master-1=http://demo-dm-master-1.demo-dm-master-peer.demo.svc:8291
 # The --join args must be:
 #   --join=http://demo-dm-master-0.demo-dm-master-peer.demo.svc:8261,http://demo-dm-master-1.demo-dm-master-peer.demo.svc:8261
-join=` + "`" + `cat {{ .DataDir }}/join | sed -e 's/8291/8261/g' | tr "," "\n" | awk -F'=' '{print $2}' | tr "\n" ","` + "`" + `
+join=` + "`" + `cat {{ .DataDir }}/join | sed -e 's/{{ .PeerPort }}/{{ .Port }}/g' | tr "," "\n" | awk -F'=' '{print $2}' | tr "\n" ","` + "`" + `
 join=${join%,}
 ARGS="${ARGS} --join=${join}"
 elif [[ ! -d {{ .DataDir }}/member/wal ]]
 then
+{{- if .ExternalMembers }}
+# join the dm-masters in other Kubernetes clusters
+ARGS="${ARGS} --join={{ .ExternalMembers }}"
+{{- else }}
 until result=$(wget -qO- -T 3 ${discovery_url}/new/${encoded_domain_url}/dm 2>/dev/null); do
 echo "waiting for discovery service to return start args ..."
 sleep $((RANDOM % 5))
 done
 ARGS="${ARGS}${result}"
+{{- end }}
 fi
 
 echo "starting dm-master ..."
@@ -550,12 +606,21 @@ exec /dm-master ${ARGS}
 `))
 
 type DMMasterStartScriptModel struct {
-	Scheme  string
-	DataDir string
+	Scheme   string
+	DataDir  string
+	Port     int32
+	PeerPort int32
+	// ClusterDomain is appended to the advertised addresses, so that they are resolvable in other Kubernetes clusters
+	ClusterDomain string
+	// ExternalMembers is the comma separated client URLs of the dm-masters in other Kubernetes clusters to join
+	ExternalMembers string
+
+	// StartScriptVersion is the version of the start script to render
+	StartScriptVersion v1alpha1.StartScriptVersion
 }
 
 func RenderDMMasterStartScript(model *DMMasterStartScriptModel) (string, error) {
-	return renderTemplateFunc(dmMasterStartScriptTpl, model)
+	return renderTemplateFunc(startScriptTemplate(model.StartScriptVersion, dmMasterStartScriptTpl, dmMasterStartScriptV2Tpl), model)
 }
 
 // dmWorkerStartScriptTpl is the dm-worker start script
@@ -594,7 +659,7 @@ POD_NAME=${POD_NAME:-$HOSTNAME}
 # TODO: dm-worker will support data-dir in the future
 ARGS="--name=${POD_NAME} \
 --join={{ .MasterAddress }} \
---advertise-addr=${POD_NAME}.${HEADLESS_SERVICE_NAME}:8262 \
+--advertise-addr=${POD_NAME}.${HEADLESS_SERVICE_NAME}{{ if .ClusterDomain }}.${NAMESPACE}.svc.{{ .ClusterDomain }}{{ else if .AdvertiseWithNamespace }}.${NAMESPACE}{{ end }}:8262 \
 --worker-addr=0.0.0.0:8262 \
 --config=/etc/dm-worker/dm-worker.toml
 "
@@ -612,8 +677,303 @@ exec /dm-worker ${ARGS}
 type DMWorkerStartScriptModel struct {
 	DataDir       string
 	MasterAddress string
+	// AdvertiseWithNamespace advertises the address with the namespace, so that the dm-masters in other
+	// namespaces can connect to it
+	AdvertiseWithNamespace bool
+	// ClusterDomain is appended to the advertised address, so that it's resolvable in other Kubernetes clusters
+	ClusterDomain string
+
+	// StartScriptVersion is the version of the start script to render
+	StartScriptVersion v1alpha1.StartScriptVersion
 }
 
 func RenderDMWorkerStartScript(model *DMWorkerStartScriptModel) (string, error) {
-	return renderTemplateFunc(dmWorkerStartScriptTpl, model)
+	return renderTemplateFunc(startScriptTemplate(model.StartScriptVersion, dmWorkerStartScriptTpl, dmWorkerStartScriptV2Tpl), model)
+}
+
+// startScriptV2CommonTpl contains the common parts shared by the v2 start scripts
+var startScriptV2CommonTpl = template.Must(template.New("start-script-v2-common").Parse(`
+{{- define "common-header" -}}
+# Use DownwardAPIVolumeFiles to store informations of the cluster:
+# https://kubernetes.io/docs/tasks/inject-data-application/downward-api-volume-expose-pod-information/#the-downward-api
+#
+#   runmode="normal/debug"
+#
+
+set -uo pipefail
+
+ANNOTATIONS="/etc/podinfo/annotations"
+
+if [[ ! -f "${ANNOTATIONS}" ]]
+then
+    echo "${ANNOTATIONS} doesn't exist, exiting."
+    exit 1
+fi
+source ${ANNOTATIONS} 2>/dev/null
+
+runmode=${runmode:-normal}
+if [[ X${runmode} == Xdebug ]]
+then
+    echo "entering debug mode."
+    tail -f /dev/null
+fi
+
+# Use HOSTNAME if POD_NAME is unset for backward compatibility.
+POD_NAME=${POD_NAME:-$HOSTNAME}
+{{- end }}
+
+{{- define "wait-for-dns" }}
+# Wait for the DNS record of the Pod to be resolvable, otherwise the other members can not
+# connect to the advertised address. The record may be published late in a large cluster,
+# so keep waiting instead of exiting after a fixed timeout.
+if command -v nslookup >/dev/null 2>&1
+then
+    until nslookup ${domain} >/dev/null 2>&1
+    do
+        echo "waiting for the DNS record of ${domain} to be resolvable ..." >&2
+        sleep 1
+    done
+    echo "nslookup domain ${domain} success"
+fi
+{{- end }}
+
+{{- define "verify-pd-endpoints" }}
+encoded_domain_url=$(echo {{ . }} | base64 | tr "\n" " " | sed "s/ //g")
+discovery_url="${CLUSTER_NAME}-discovery.${NAMESPACE}:10261"
+until result=$(wget -qO- -T 3 http://${discovery_url}/verify/${encoded_domain_url} 2>/dev/null); do
+    echo "waiting for the verification of PD endpoints ..."
+    sleep $((RANDOM % 5))
+done
+{{- end }}
+`))
+
+func newStartScriptV2Template(name, text string) *template.Template {
+	return template.Must(template.Must(startScriptV2CommonTpl.Clone()).New(name).Parse(text))
+}
+
+// pdStartScriptV2Tpl is the v2 pd start script
+// Note: changing this will cause a rolling-update of pd cluster using the v2 start script
+var pdStartScriptV2Tpl = newStartScriptV2Template("pd-start-script-v2", `#!/bin/sh
+
+# This script is used to start pd containers in kubernetes cluster
+
+{{ template "common-header" }}
+
+# the general form of variable PEER_SERVICE_NAME is: "<clusterName>-pd-peer"
+cluster_name=$(echo ${PEER_SERVICE_NAME} | sed 's/-pd-peer//')
+domain="${POD_NAME}.${PEER_SERVICE_NAME}.${NAMESPACE}.svc{{ .FormatClusterDomain }}"
+discovery_url="${cluster_name}-discovery.${NAMESPACE}.svc:10261"
+encoded_domain_url=$(echo ${domain}:2380 | base64 | tr "\n" " " | sed "s/ //g")
+{{ template "wait-for-dns" }}
+
+ARGS="--data-dir={{ .DataDir }} \
+--name={{- if .ClusterDomain }}${domain}{{- else }}${POD_NAME}{{- end }} \
+--peer-urls={{ .Scheme }}://0.0.0.0:2380 \
+--advertise-peer-urls={{ .Scheme }}://${domain}:2380 \
+--client-urls={{ .Scheme }}://0.0.0.0:{{ .ClientPort }} \
+--advertise-client-urls={{ .Scheme }}://${domain}:{{ .ClientPort }} \
+--config=/etc/pd/pd.toml \
+"
+
+if [[ -f {{ .DataDir }}/join ]]
+then
+    # The content of the join file is:
+    #   demo-pd-0=http://demo-pd-0.demo-pd-peer.demo.svc:2380,demo-pd-1=http://demo-pd-1.demo-pd-peer.demo.svc:2380
+    # The --join args must be:
+    #   --join=http://demo-pd-0.demo-pd-peer.demo.svc:2380,http://demo-pd-1.demo-pd-peer.demo.svc:2380
+    join=$(cat {{ .DataDir }}/join | tr "," "\n" | awk -F'=' '{print $2}' | tr "\n" ",")
+    join=${join%,}
+    ARGS="${ARGS} --join=${join}"
+elif [[ ! -d {{ .DataDir }}/member/wal ]]
+then
+    until result=$(wget -qO- -T 3 http://${discovery_url}/new/${encoded_domain_url} 2>/dev/null); do
+        echo "waiting for discovery service to return start args ..."
+        sleep $((RANDOM % 5))
+    done
+    ARGS="${ARGS}${result}"
+fi
+
+echo "starting pd-server ..."
+sleep $((RANDOM % 10))
+echo "/pd-server ${ARGS}"
+exec /pd-server ${ARGS}
+`)
+
+// tikvStartScriptV2Tpl is the v2 tikv start script
+// Note: changing this will cause a rolling-update of tikv cluster using the v2 start script
+var tikvStartScriptV2Tpl = newStartScriptV2Template("tikv-start-script-v2", `#!/bin/sh
+
+# This script is used to start tikv containers in kubernetes cluster
+
+{{ template "common-header" }}
+
+domain="${POD_NAME}.${HEADLESS_SERVICE_NAME}.${NAMESPACE}.svc{{ .FormatClusterDomain }}"
+{{ template "wait-for-dns" }}
+{{ if .FormatClusterDomain }}
+{{- template "verify-pd-endpoints" .PDAddress }}
+pd_addr="${result}"
+{{- else }}
+pd_addr="{{ .PDAddress }}"
+{{- end }}
+{{- if .EnablePerPodConfig }}
+
+# Use the configuration file of the Pod if it has a configuration patch
+config_file="/etc/tikv/tikv.toml"
+if [ -f "/etc/tikv-per-pod/tikv-${POD_NAME##*-}.toml" ]; then
+    config_file="/etc/tikv-per-pod/tikv-${POD_NAME##*-}.toml"
+fi
+{{- end }}
+
+ARGS="--pd=${pd_addr} \
+--advertise-addr=${domain}:{{ .Port }} \
+--addr=0.0.0.0:{{ .Port }} \
+--status-addr=0.0.0.0:20180 \{{ if .EnableAdvertiseStatusAddr }}
+--advertise-status-addr={{ .AdvertiseStatusAddr }}:20180 \{{ end }}
+--data-dir={{ .DataDir }} \
+--capacity=${CAPACITY} \
+--config={{ if .EnablePerPodConfig }}${config_file}{{ else }}/etc/tikv/tikv.toml{{ end }}
+"
+
+if [ ! -z "${STORE_LABELS:-}" ]; then
+    LABELS=" --labels ${STORE_LABELS} "
+    ARGS="${ARGS}${LABELS}"
+fi
+
+echo "starting tikv-server ..."
+echo "/tikv-server ${ARGS}"
+exec /tikv-server ${ARGS}
+`)
+
+// tidbStartScriptV2Tpl is the v2 tidb start script
+// Note: changing this will cause a rolling-update of tidb-servers using the v2 start script
+var tidbStartScriptV2Tpl = newStartScriptV2Template("tidb-start-script-v2", `#!/bin/sh
+
+# This script is used to start tidb containers in kubernetes cluster
+
+{{ template "common-header" }}
+
+domain="${POD_NAME}.${HEADLESS_SERVICE_NAME}.${NAMESPACE}.svc{{ .FormatClusterDomain }}"
+{{ template "wait-for-dns" }}
+{{ if .FormatClusterDomain }}
+{{- template "verify-pd-endpoints" .Path }}
+pd_path=$(echo ${result} | sed 's/http:\/\///g')
+{{- else }}
+pd_path="{{ .Path }}"
+{{- end }}
+
+ARGS="--store=tikv \
+--advertise-address=${domain} \
+--host=0.0.0.0 \
+--path=${pd_path} \
+--config=/etc/tidb/tidb.toml
+"
+
+if [[ X${BINLOG_ENABLED:-} == Xtrue ]]
+then
+    ARGS="${ARGS} --enable-binlog=true"
+fi
+
+SLOW_LOG_FILE=${SLOW_LOG_FILE:-""}
+if [[ ! -z "${SLOW_LOG_FILE}" ]]
+then
+    ARGS="${ARGS} --log-slow-query=${SLOW_LOG_FILE:-}"
+fi
+{{- if .EnablePlugin }}
+
+ARGS="${ARGS} --plugin-dir {{ .PluginDirectory }} --plugin-load {{ .PluginList }}"
+{{- end }}
+
+echo "start tidb-server ..."
+echo "/tidb-server ${ARGS}"
+exec /tidb-server ${ARGS}
+`)
+
+// dmMasterStartScriptV2Tpl is the v2 dm-master start script
+// Note: changing this will cause a rolling-update of dm-master cluster using the v2 start script
+var dmMasterStartScriptV2Tpl = newStartScriptV2Template("dm-master-start-script-v2", `#!/bin/sh
+
+# This script is used to start dm-master containers in kubernetes cluster
+
+{{ template "common-header" }}
+
+# the general form of variable PEER_SERVICE_NAME is: "<clusterName>-dm-master-peer"
+cluster_name=$(echo ${PEER_SERVICE_NAME} | sed 's/-dm-master-peer//')
+domain="${POD_NAME}.${PEER_SERVICE_NAME}{{ if .ClusterDomain }}.${NAMESPACE}.svc.{{ .ClusterDomain }}{{ end }}"
+discovery_url="${cluster_name}-dm-discovery.${NAMESPACE}:10261"
+encoded_domain_url=$(echo ${domain}:{{ .PeerPort }} | base64 | tr "\n" " " | sed "s/ //g")
+{{ template "wait-for-dns" }}
+
+ARGS="--data-dir={{ .DataDir }} \
+--name=${POD_NAME} \
+--peer-urls={{ .Scheme }}://0.0.0.0:{{ .PeerPort }} \
+--advertise-peer-urls={{ .Scheme }}://${domain}:{{ .PeerPort }} \
+--master-addr=:{{ .Port }} \
+--advertise-addr=${domain}:{{ .Port }} \
+--config=/etc/dm-master/dm-master.toml \
+"
+
+if [[ -f {{ .DataDir }}/join ]]
+then
+    # The content of the join file is:
+    #   demo-dm-master-0=http://demo-dm-master-0.demo-dm-master-peer.demo.svc:8291,demo-dm-master-1=http://demo-dm-master-1.demo-dm-master-peer.demo.svc:8291
+    # The --join args must be:
+    #   --join=http://demo-dm-master-0.demo-dm-master-peer.demo.svc:8261,http://demo-dm-master-1.demo-dm-master-peer.demo.svc:8261
+    join=$(cat {{ .DataDir }}/join | sed -e 's/{{ .PeerPort }}/{{ .Port }}/g' | tr "," "\n" | awk -F'=' '{print $2}' | tr "\n" ",")
+    join=${join%,}
+    ARGS="${ARGS} --join=${join}"
+elif [[ ! -d {{ .DataDir }}/member/wal ]]
+then
+{{- if .ExternalMembers }}
+    # join the dm-masters in other Kubernetes clusters
+    ARGS="${ARGS} --join={{ .ExternalMembers }}"
+{{- else }}
+    until result=$(wget -qO- -T 3 ${discovery_url}/new/${encoded_domain_url}/dm 2>/dev/null); do
+        echo "waiting for discovery service to return start args ..."
+        sleep $((RANDOM % 5))
+    done
+    ARGS="${ARGS}${result}"
+{{- end }}
+fi
+
+echo "starting dm-master ..."
+sleep $((RANDOM % 10))
+echo "/dm-master ${ARGS}"
+exec /dm-master ${ARGS}
+`)
+
+// dmWorkerStartScriptV2Tpl is the v2 dm-worker start script
+// Note: changing this will cause a rolling-update of dm-worker cluster using the v2 start script
+var dmWorkerStartScriptV2Tpl = newStartScriptV2Template("dm-worker-start-script-v2", `#!/bin/sh
+
+# This script is used to start dm-worker containers in kubernetes cluster
+
+{{ template "common-header" }}
+
+domain="${POD_NAME}.${HEADLESS_SERVICE_NAME}{{ if .ClusterDomain }}.${NAMESPACE}.svc.{{ .ClusterDomain }}{{ else if .AdvertiseWithNamespace }}.${NAMESPACE}{{ end }}"
+{{ template "wait-for-dns" }}
+
+# TODO: dm-worker will support data-dir in the future
+ARGS="--name=${POD_NAME} \
+--join={{ .MasterAddress }} \
+--advertise-addr=${domain}:8262 \
+--worker-addr=0.0.0.0:8262 \
+--config=/etc/dm-worker/dm-worker.toml
+"
+
+if [ ! -z "${STORE_LABELS:-}" ]; then
+    LABELS=" --labels ${STORE_LABELS} "
+    ARGS="${ARGS}${LABELS}"
+fi
+
+echo "starting dm-worker ..."
+echo "/dm-worker ${ARGS}"
+exec /dm-worker ${ARGS}
+`)
+
+// startScriptTemplate returns the v2 template if the version is v2, otherwise the v1 template
+func startScriptTemplate(version v1alpha1.StartScriptVersion, v1, v2 *template.Template) *template.Template {
+	if version == v1alpha1.StartScriptV2 {
+		return v2
+	}
+	return v1
 }
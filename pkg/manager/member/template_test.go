@@ -15,9 +15,11 @@ package member
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 )
 
 func TestRenderTiDBInitStartScript(t *testing.T) {
@@ -174,6 +176,7 @@ func TestRenderTiKVStartScript(t *testing.T) {
 		dataSubDir          string
 		result              string
 		clusterDomain       string
+		enablePerPodConfig  bool
 	}{
 		{
 			name:                "disable AdvertiseAddr",
@@ -398,6 +401,65 @@ if [ ! -z "${STORE_LABELS:-}" ]; then
   ARGS="${ARGS}${LABELS}"
 fi
 
+echo "starting tikv-server ..."
+echo "/tikv-server ${ARGS}"
+exec /tikv-server ${ARGS}
+`,
+		},
+		{
+			name:               "enable per-pod config",
+			enablePerPodConfig: true,
+			result: `#!/bin/sh
+
+# This script is used to start tikv containers in kubernetes cluster
+
+# Use DownwardAPIVolumeFiles to store informations of the cluster:
+# https://kubernetes.io/docs/tasks/inject-data-application/downward-api-volume-expose-pod-information/#the-downward-api
+#
+#   runmode="normal/debug"
+#
+
+set -uo pipefail
+
+ANNOTATIONS="/etc/podinfo/annotations"
+
+if [[ ! -f "${ANNOTATIONS}" ]]
+then
+    echo "${ANNOTATIONS} does't exist, exiting."
+    exit 1
+fi
+source ${ANNOTATIONS} 2>/dev/null
+
+runmode=${runmode:-normal}
+if [[ X${runmode} == Xdebug ]]
+then
+	echo "entering debug mode."
+	tail -f /dev/null
+fi
+
+# Use the configuration file of the Pod if it has a configuration patch
+config_file="/etc/tikv/tikv.toml"
+if [[ -f "/etc/tikv-per-pod/tikv-${HOSTNAME##*-}.toml" ]]
+then
+    config_file="/etc/tikv-per-pod/tikv-${HOSTNAME##*-}.toml"
+fi
+
+# Use HOSTNAME if POD_NAME is unset for backward compatibility.
+POD_NAME=${POD_NAME:-$HOSTNAME}
+ARGS="--pd=http://${CLUSTER_NAME}-pd:2379 \
+--advertise-addr=${POD_NAME}.${HEADLESS_SERVICE_NAME}.${NAMESPACE}.svc:20160 \
+--addr=0.0.0.0:20160 \
+--status-addr=0.0.0.0:20180 \
+--data-dir=/var/lib/tikv \
+--capacity=${CAPACITY} \
+--config=${config_file}
+"
+
+if [ ! -z "${STORE_LABELS:-}" ]; then
+  LABELS=" --labels ${STORE_LABELS} "
+  ARGS="${ARGS}${LABELS}"
+fi
+
 echo "starting tikv-server ..."
 echo "/tikv-server ${ARGS}"
 exec /tikv-server ${ARGS}
@@ -409,10 +471,12 @@ exec /tikv-server ${ARGS}
 		t.Run(tt.name, func(t *testing.T) {
 			model := TiKVStartScriptModel{
 				PDAddress:                 "http://${CLUSTER_NAME}-pd:2379",
+				Port:                      20160,
 				EnableAdvertiseStatusAddr: tt.enableAdvertiseAddr,
 				AdvertiseStatusAddr:       tt.advertiseAddr,
 				DataDir:                   filepath.Join(tikvDataVolumeMountPath, tt.dataSubDir),
 				ClusterDomain:             tt.clusterDomain,
+				EnablePerPodConfig:        tt.enablePerPodConfig,
 			}
 			script, err := RenderTiKVStartScript(&model)
 			if err != nil {
@@ -722,6 +786,7 @@ exec /pd-server ${ARGS}
 			model := PDStartScriptModel{
 				DataDir:       filepath.Join(pdDataVolumeMountPath, tt.dataSubDir),
 				ClusterDomain: tt.clusterDomain,
+				ClientPort:    2379,
 			}
 			script, err := RenderPDStartScript(&model)
 			if err != nil {
@@ -809,6 +874,7 @@ fi`,
 				LogLevel:      tt.LogLevel,
 				Namespace:     tt.Namespace,
 				ClusterDomain: tt.clusterDomain,
+				PDPort:        2379,
 			}
 			script, err := RenderPumpStartScript(&model)
 			if err != nil {
@@ -820,3 +886,142 @@ fi`,
 		})
 	}
 }
+
+func TestRenderStartScriptV2(t *testing.T) {
+	waitForDNS := "until nslookup ${domain} >/dev/null 2>&1"
+	tests := []struct {
+		name     string
+		render   func() (string, error)
+		contains []string
+	}{
+		{
+			name: "pd",
+			render: func() (string, error) {
+				return RenderPDStartScript(&PDStartScriptModel{
+					Scheme:             "http",
+					DataDir:            "/var/lib/pd",
+					ClientPort:         12379,
+					StartScriptVersion: v1alpha1.StartScriptV2,
+				})
+			},
+			contains: []string{
+				waitForDNS,
+				`domain="${POD_NAME}.${PEER_SERVICE_NAME}.${NAMESPACE}.svc"`,
+				"--advertise-peer-urls=http://${domain}:2380",
+				"--client-urls=http://0.0.0.0:12379",
+				"--advertise-client-urls=http://${domain}:12379",
+				"exec /pd-server ${ARGS}",
+			},
+		},
+		{
+			name: "tikv with cluster domain",
+			render: func() (string, error) {
+				return RenderTiKVStartScript(&TiKVStartScriptModel{
+					PDAddress:          "http://${CLUSTER_NAME}-pd:12379",
+					DataDir:            "/var/lib/tikv",
+					ClusterDomain:      "cluster.local",
+					Port:               20161,
+					StartScriptVersion: v1alpha1.StartScriptV2,
+				})
+			},
+			contains: []string{
+				waitForDNS,
+				`domain="${POD_NAME}.${HEADLESS_SERVICE_NAME}.${NAMESPACE}.svc.cluster.local"`,
+				"encoded_domain_url=$(echo http://${CLUSTER_NAME}-pd:12379 | base64",
+				"--advertise-addr=${domain}:20161",
+				"--addr=0.0.0.0:20161",
+				"exec /tikv-server ${ARGS}",
+			},
+		},
+		{
+			name: "tidb",
+			render: func() (string, error) {
+				return RenderTiDBStartScript(&TidbStartScriptModel{
+					Path:               "${CLUSTER_NAME}-pd:2379",
+					EnablePlugin:       true,
+					PluginDirectory:    "/plugins",
+					PluginList:         "whitelist-1",
+					StartScriptVersion: v1alpha1.StartScriptV2,
+				})
+			},
+			contains: []string{
+				waitForDNS,
+				`pd_path="${CLUSTER_NAME}-pd:2379"`,
+				`ARGS="${ARGS} --plugin-dir /plugins --plugin-load whitelist-1"`,
+				"exec /tidb-server ${ARGS}",
+			},
+		},
+		{
+			name: "dm-master",
+			render: func() (string, error) {
+				return RenderDMMasterStartScript(&DMMasterStartScriptModel{
+					Scheme:             "http",
+					DataDir:            "/var/lib/dm-master",
+					Port:               18261,
+					PeerPort:           18291,
+					StartScriptVersion: v1alpha1.StartScriptV2,
+				})
+			},
+			contains: []string{
+				waitForDNS,
+				`domain="${POD_NAME}.${PEER_SERVICE_NAME}"`,
+				"encoded_domain_url=$(echo ${domain}:18291 | base64",
+				"--advertise-peer-urls=http://${domain}:18291",
+				"--advertise-addr=${domain}:18261",
+				"sed -e 's/18291/18261/g'",
+				"exec /dm-master ${ARGS}",
+			},
+		},
+		{
+			name: "dm-master with external members",
+			render: func() (string, error) {
+				return RenderDMMasterStartScript(&DMMasterStartScriptModel{
+					Scheme:             "http",
+					DataDir:            "/var/lib/dm-master",
+					Port:               8261,
+					PeerPort:           8291,
+					ClusterDomain:      "cluster2.com",
+					ExternalMembers:    "http://demo-dm-master-0.demo-dm-master-peer.tidb.svc.cluster1.com:8261",
+					StartScriptVersion: v1alpha1.StartScriptV2,
+				})
+			},
+			contains: []string{
+				`domain="${POD_NAME}.${PEER_SERVICE_NAME}.${NAMESPACE}.svc.cluster2.com"`,
+				"--advertise-peer-urls=http://${domain}:8291",
+				`ARGS="${ARGS} --join=http://demo-dm-master-0.demo-dm-master-peer.tidb.svc.cluster1.com:8261"`,
+				"exec /dm-master ${ARGS}",
+			},
+		},
+		{
+			name: "dm-worker",
+			render: func() (string, error) {
+				return RenderDMWorkerStartScript(&DMWorkerStartScriptModel{
+					MasterAddress:      "demo-dm-master:8261",
+					StartScriptVersion: v1alpha1.StartScriptV2,
+				})
+			},
+			contains: []string{
+				waitForDNS,
+				"--join=demo-dm-master:8261",
+				"exec /dm-worker ${ARGS}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := tt.render()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(script, "#!/bin/sh\n") {
+				t.Errorf("unexpected script header: %s", script)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(script, s) {
+					t.Errorf("expect script to contain %q, got: %s", s, script)
+				}
+			}
+		})
+	}
+}
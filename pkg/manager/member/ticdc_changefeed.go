@@ -0,0 +1,177 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+// syncChangefeedSinkURIs keeps the sink URIs of the changefeeds in `spec.ticdc.changefeeds` in sync with the
+// Secrets they reference. A running changefeed is paused, updated and resumed when its sink URI is changed,
+// and a changefeed paused by the users is updated and kept paused, only the changefeeds paused by the operator
+// are resumed. The changefeeds in the other states, e.g. error or failed, are left to the users. The states of
+// the changefeeds and the hashes of the sink URIs applied to them are recorded in the status.
+func (m *ticdcMemberManager) syncChangefeedSinkURIs(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	if len(tc.Spec.TiCDC.Changefeeds) == 0 {
+		tc.Status.TiCDC.Changefeeds = nil
+		return nil
+	}
+	ordinal, ok := readyTiCDCCaptureOrdinal(tc)
+	if !ok {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s], waiting for a ready ticdc capture to update the changefeeds", ns, tcName)
+	}
+
+	changefeeds := map[string]v1alpha1.TiCDCChangefeedStatus{}
+	var errs []error
+	var pending []string
+	for _, cf := range tc.Spec.TiCDC.Changefeeds {
+		status := tc.Status.TiCDC.Changefeeds[cf.ID]
+		sinkURI, ok, err := m.getChangefeedSinkURI(ns, cf)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if !ok {
+			changefeeds[cf.ID] = status
+			continue
+		}
+		done, err := m.syncChangefeedSinkURI(tc, ordinal, cf, sinkURI, &status)
+		changefeeds[cf.ID] = status
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update the sink uri of changefeed %s, error: %v", cf.ID, err))
+			continue
+		}
+		if !done {
+			pending = append(pending, cf.ID)
+		}
+	}
+	tc.Status.TiCDC.Changefeeds = changefeeds
+
+	if len(errs) > 0 {
+		return errorutils.NewAggregate(errs)
+	}
+	if len(pending) > 0 {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s], updating the sink uri of changefeeds %v", ns, tcName, pending)
+	}
+	return nil
+}
+
+// getChangefeedSinkURI returns the sink URI of the changefeed from the Secret it references, ok is false if
+// the optional Secret or key doesn't exist
+func (m *ticdcMemberManager) getChangefeedSinkURI(ns string, cf v1alpha1.TiCDCChangefeed) (sinkURI string, ok bool, err error) {
+	ref := cf.SinkURISecretRef
+	optional := ref.Optional != nil && *ref.Optional
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(ref.Name)
+	if err != nil {
+		if errors.IsNotFound(err) && optional {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get secret %s/%s of changefeed %s, error: %v", ns, ref.Name, cf.ID, err)
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		if optional {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("key %s not found in secret %s/%s of changefeed %s", ref.Key, ns, ref.Name, cf.ID)
+	}
+	return string(data), true, nil
+}
+
+// syncChangefeedSinkURI moves the changefeed one step towards running with the sink URI and records it in the
+// status, it returns true once the sink URI is applied and the changefeed paused by the operator is resumed,
+// or the changefeed is in a state that can't be updated
+func (m *ticdcMemberManager) syncChangefeedSinkURI(tc *v1alpha1.TidbCluster, ordinal int32, cf v1alpha1.TiCDCChangefeed, sinkURI string, status *v1alpha1.TiCDCChangefeedStatus) (bool, error) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	detail, err := m.deps.CDCControl.GetChangefeed(tc, ordinal, cf.ID)
+	if err != nil {
+		return false, err
+	}
+	lastState := status.State
+	status.State = detail.State
+	if detail.State != controller.ChangefeedStateNormal && detail.State != controller.ChangefeedStateStopped {
+		// e.g. the changefeed is in the error or failed state, it's not resumed until the users resolve it
+		klog.Warningf("tidbcluster: [%s/%s], changefeed %s is %s, skip updating its sink uri", ns, tcName, cf.ID, detail.State)
+		status.PausedForUpdate = false
+		return true, nil
+	}
+
+	// the sink uri returned by the API has its password masked, so it's only compared when no hash is recorded
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(sinkURI)))[:16]
+	changed := detail.SinkURI != sinkURI
+	if status.SinkURIHash != "" {
+		changed = status.SinkURIHash != hash
+	}
+	if changed {
+		// the changefeed can only be updated after it's paused, and the pause is asynchronous
+		if detail.State == controller.ChangefeedStateNormal {
+			if err := m.deps.CDCControl.PauseChangefeed(tc, ordinal, cf.ID); err != nil {
+				return false, err
+			}
+			status.PausedForUpdate = true
+			return false, nil
+		}
+		if err := m.deps.CDCControl.UpdateChangefeedSinkURI(tc, ordinal, cf.ID, sinkURI); err != nil {
+			return false, err
+		}
+		klog.Infof("tidbcluster: [%s/%s], updated the sink uri of changefeed %s from secret %s", ns, tcName, cf.ID, cf.SinkURISecretRef.Name)
+		if lastState == controller.ChangefeedStateNormal {
+			// the changefeed running in the last sync is paused by the operator, even if the flag failed to be
+			// recorded in the status
+			status.PausedForUpdate = true
+		}
+	}
+	status.SinkURIHash = hash
+
+	if !status.PausedForUpdate {
+		return true, nil
+	}
+	if detail.State == controller.ChangefeedStateStopped {
+		return false, m.deps.CDCControl.ResumeChangefeed(tc, ordinal, cf.ID)
+	}
+	status.PausedForUpdate = false
+	return true, nil
+}
+
+// readyTiCDCCaptureOrdinal returns the lowest ordinal of the ready captures, whose open API is used to manage
+// the changefeeds
+func readyTiCDCCaptureOrdinal(tc *v1alpha1.TidbCluster) (int32, bool) {
+	ordinal := int32(math.MaxInt32)
+	for podName, capture := range tc.Status.TiCDC.Captures {
+		if !capture.Ready {
+			continue
+		}
+		id, err := util.GetOrdinalFromPodName(podName)
+		if err != nil {
+			continue
+		}
+		if id < ordinal {
+			ordinal = id
+		}
+	}
+	return ordinal, ordinal != math.MaxInt32
+}
@@ -96,11 +96,16 @@ func NewTiCDCMemberManager(deps *controller.Dependencies, scaler Scaler, ticdcUp
 }
 
 func (m *ticdcMemberManager) syncTiCDCConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
-	if tc.Spec.TiCDC.Config == nil || tc.Spec.TiCDC.Config.OnlyOldItems() {
+	// For backward compatibility, only sync ticdc configmap when .ticdc.config has the items other than the
+	// ones passed by the command line arguments or .ticdc.configMapRef is non-nil
+	if (tc.Spec.TiCDC.Config == nil || tc.Spec.TiCDC.Config.OnlyOldItems()) && tc.Spec.TiCDC.ConfigMapRef == nil {
 		return nil, nil
 	}
-
-	newCm, err := getTiCDCConfigMap(tc)
+	cfgTC, err := withTiCDCConfigMapRef(m.deps.UserConfigMapLister, tc)
+	if err != nil {
+		return nil, err
+	}
+	newCm, err := getTiCDCConfigMap(cfgTC)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +137,11 @@ func (m *ticdcMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 
-	return m.syncStatefulSet(tc)
+	if err := m.syncStatefulSet(tc); err != nil {
+		return err
+	}
+
+	return m.syncChangefeedSinkURIs(tc)
 }
 
 func (m *ticdcMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
@@ -169,6 +178,10 @@ func (m *ticdcMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 	}
 
 	if stsNotExist {
+		if tenantPolicyBlocksCreation(tc) {
+			klog.Infof("TidbCluster: %s/%s, the tenant policy is violated, skip creating the ticdc statefulset", tc.GetNamespace(), tc.GetName())
+			return nil
+		}
 		if !tc.PDIsAvailable() {
 			klog.Infof("TidbCluster: %s/%s, waiting for PD cluster running", ns, tcName)
 			return nil
@@ -184,6 +197,10 @@ func (m *ticdcMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 		return nil
 	}
 
+	if err := syncScaleOutResources(m.deps, tc, v1alpha1.TiCDCMemberType, oldSts, newSts); err != nil {
+		return err
+	}
+
 	// Scaling takes precedence over upgrading because:
 	// - if a pod fails in the upgrading, users may want to delete it or add
 	//   new replicas
@@ -193,6 +210,8 @@ func (m *ticdcMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 
+	syncTimezoneChange(m.deps, tc, v1alpha1.TiCDCMemberType, oldSts, newSts)
+
 	if !templateEqual(newSts, oldSts) || tc.Status.TiCDC.Phase == v1alpha1.UpgradePhase {
 		if err := m.ticdcUpgrader.Upgrade(tc, oldSts, newSts); err != nil {
 			return err
@@ -359,7 +378,7 @@ func getNewTiCDCStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*ap
 		cmdArgs = append(cmdArgs, fmt.Sprintf("--cert=%s", path.Join(ticdcCertPath, corev1.TLSCertKey)))
 		cmdArgs = append(cmdArgs, fmt.Sprintf("--key=%s", path.Join(ticdcCertPath, corev1.TLSPrivateKeyKey)))
 		if tc.Spec.ClusterDomain == "" {
-			cmdArgs = append(cmdArgs, fmt.Sprintf("--pd=https://%s-pd:2379", tcName))
+			cmdArgs = append(cmdArgs, fmt.Sprintf("--pd=https://%s-pd:%d", tcName, tc.PDClientPort()))
 		} else {
 			cmdArgs = append(cmdArgs, "--pd=${result}")
 		}
@@ -389,7 +408,7 @@ func getNewTiCDCStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*ap
 		})
 	} else {
 		if tc.Spec.ClusterDomain == "" {
-			cmdArgs = append(cmdArgs, fmt.Sprintf("--pd=http://%s-pd:2379", tcName))
+			cmdArgs = append(cmdArgs, fmt.Sprintf("--pd=http://%s-pd:%d", tcName, tc.PDClientPort()))
 		} else {
 			cmdArgs = append(cmdArgs, "--pd=${result}")
 		}
@@ -409,9 +428,9 @@ func getNewTiCDCStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*ap
 	if tc.Spec.ClusterDomain != "" {
 		var pdAddr string
 		if tc.IsTLSClusterEnabled() {
-			pdAddr = fmt.Sprintf("https://%s-pd:2379", tcName)
+			pdAddr = fmt.Sprintf("https://%s-pd:%d", tcName, tc.PDClientPort())
 		} else {
-			pdAddr = fmt.Sprintf("http://%s-pd:2379", tcName)
+			pdAddr = fmt.Sprintf("http://%s-pd:%d", tcName, tc.PDClientPort())
 		}
 
 		str := `set -uo pipefail
@@ -488,6 +507,7 @@ done
 	podSpec := baseTiCDCSpec.BuildPodSpec()
 	podSpec.Containers = []corev1.Container{ticdcContainer}
 	podSpec.Volumes = append(vols, baseTiCDCSpec.AdditionalVolumes()...)
+	addTimezoneData(baseTiCDCSpec, &podSpec, v1alpha1.TiCDCMemberType.String())
 	podSpec.ServiceAccountName = tc.Spec.TiCDC.ServiceAccount
 	podSpec.InitContainers = append(podSpec.InitContainers, baseTiCDCSpec.InitContainers()...)
 	if podSpec.ServiceAccountName == "" {
@@ -550,6 +570,7 @@ done
 		},
 	}
 	ticdcSts.Spec.VolumeClaimTemplates = append(ticdcSts.Spec.VolumeClaimTemplates, additionalPVCs...)
+	setVolumeClaimCloudTags(tc, ticdcSts.Spec.VolumeClaimTemplates)
 	return ticdcSts, nil
 }
 
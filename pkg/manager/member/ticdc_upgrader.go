@@ -15,13 +15,16 @@ package member
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
 
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -102,11 +105,76 @@ func (u *ticdcUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulS
 			if _, exist := tc.Status.TiCDC.Captures[podName]; !exist {
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s ticdc upgraded pod: [%s] is not ready", ns, tcName, podName)
 			}
+			if msg := checkUpgradedPodPacing(tc.BaseTiCDCSpec(), pod); msg != "" {
+				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s ticdc upgraded pod: [%s] %s", ns, tcName, podName, msg)
+			}
 			continue
 		}
+		if *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition > i {
+			// the capture hands over its ownership and tables before the Pod is restarted by the StatefulSet
+			// controller, so that the changefeeds are not interrupted
+			if err := u.gracefulShutdownTiCDC(tc, pod, i); err != nil {
+				return err
+			}
+		}
 		mngerutils.SetUpgradePartition(newSet, i)
 		return nil
 	}
 
 	return nil
 }
+
+// gracefulShutdownTiCDC resigns the ownership and drains the tables of the capture before its Pod is upgraded,
+// it returns nil once the capture can be restarted or the graceful shutdown times out.
+func (u *ticdcUpgrader) gracefulShutdownTiCDC(tc *v1alpha1.TidbCluster, pod *corev1.Pod, ordinal int32) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	podName := pod.GetName()
+
+	if capture, exist := tc.Status.TiCDC.Captures[podName]; !exist || !capture.Ready {
+		// the capture is down, there is nothing to hand over
+		klog.Infof("ticdc upgrader: capture of pod %s/%s is not ready, skip the graceful shutdown", ns, podName)
+		return nil
+	}
+
+	timeout := tc.TiCDCGracefulShutdownTimeout()
+	beginTimeStr, exist := pod.Annotations[label.AnnTiCDCGracefulShutdownBeginTime]
+	beginTime, err := time.Parse(time.RFC3339, beginTimeStr)
+	if !exist || err != nil {
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		now := time.Now().Format(time.RFC3339)
+		pod.Annotations[label.AnnTiCDCGracefulShutdownBeginTime] = now
+		if _, err := u.deps.PodControl.UpdatePod(tc, pod); err != nil {
+			klog.Errorf("ticdc upgrader: failed to set pod %s/%s annotation %s to %s, %v",
+				ns, podName, label.AnnTiCDCGracefulShutdownBeginTime, now, err)
+			return err
+		}
+		klog.Infof("ticdc upgrader: begin graceful shutdown of pod %s/%s", ns, podName)
+	} else if time.Now().After(beginTime.Add(timeout)) {
+		klog.Warningf("ticdc upgrader: graceful shutdown timeout (threshold: %v) for pod %s/%s, upgrade it anyway", timeout, ns, podName)
+		return nil
+	}
+
+	resigned, err := u.deps.CDCControl.ResignOwner(tc, ordinal)
+	if err != nil {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s ticdc pod: [%s] failed to resign the ownership, %v", ns, tcName, podName, err)
+	}
+	if !resigned {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s ticdc pod: [%s] is resigning the ownership", ns, tcName, podName)
+	}
+
+	tableCount, retry, err := u.deps.CDCControl.DrainCapture(tc, ordinal)
+	if err != nil {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s ticdc pod: [%s] failed to drain the capture, %v", ns, tcName, podName, err)
+	}
+	if retry {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s ticdc pod: [%s] can't be drained for now, retry later", ns, tcName, podName)
+	}
+	if tableCount > 0 {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s ticdc pod: [%s] is draining, %d tables remaining", ns, tcName, podName, tableCount)
+	}
+	klog.Infof("ticdc upgrader: capture of pod %s/%s is drained, upgrade it", ns, podName)
+	return nil
+}
@@ -15,6 +15,7 @@ package member
 
 import (
 	"testing"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -40,6 +41,8 @@ func TestTiCDCUpgrader_Upgrade(t *testing.T) {
 		missPod      bool
 		errorExpect  bool
 		changeOldSet func(set *apps.StatefulSet)
+		changePod    func(pod *corev1.Pod)
+		cdcControl   func(cdcControl *controller.FakeTiCDCControl)
 		expectFn     func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet)
 	}
 
@@ -57,6 +60,12 @@ func TestTiCDCUpgrader_Upgrade(t *testing.T) {
 		if test.missPod {
 			pods = pods[:0]
 		}
+		if test.changePod != nil {
+			test.changePod(pods[0])
+		}
+		if test.cdcControl != nil {
+			test.cdcControl(upgrader.(*ticdcUpgrader).deps.CDCControl.(*controller.FakeTiCDCControl))
+		}
 		for _, pod := range pods {
 			podInformer.Informer().GetIndexer().Add(pod)
 		}
@@ -85,6 +94,72 @@ func TestTiCDCUpgrader_Upgrade(t *testing.T) {
 				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
 			},
 		},
+		{
+			name: "upgrade the pod once the capture is drained",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiCDC.Captures["upgrader-ticdc-0"] = v1alpha1.TiCDCCapture{PodName: "upgrader-ticdc-0", Ready: true}
+			},
+			cdcControl: func(cdcControl *controller.FakeTiCDCControl) {
+				cdcControl.MockDrainCapture(func(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error) {
+					g.Expect(ordinal).To(Equal(int32(0)))
+					return 0, false, nil
+				})
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiCDC.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
+			},
+		},
+		{
+			name: "wait for the capture to resign the ownership",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiCDC.Captures["upgrader-ticdc-0"] = v1alpha1.TiCDCCapture{PodName: "upgrader-ticdc-0", Ready: true}
+			},
+			cdcControl: func(cdcControl *controller.FakeTiCDCControl) {
+				cdcControl.MockResignOwner(func(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+					return false, nil
+				})
+			},
+			errorExpect: true,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+			},
+		},
+		{
+			name: "wait for the capture to be drained",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiCDC.Captures["upgrader-ticdc-0"] = v1alpha1.TiCDCCapture{PodName: "upgrader-ticdc-0", Ready: true}
+			},
+			cdcControl: func(cdcControl *controller.FakeTiCDCControl) {
+				cdcControl.MockDrainCapture(func(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error) {
+					return 3, false, nil
+				})
+			},
+			errorExpect: true,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+			},
+		},
+		{
+			name: "upgrade the pod when the graceful shutdown times out",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiCDC.GracefulShutdownTimeout = pointer.StringPtr("5m")
+				tc.Status.TiCDC.Captures["upgrader-ticdc-0"] = v1alpha1.TiCDCCapture{PodName: "upgrader-ticdc-0", Ready: true}
+			},
+			changePod: func(pod *corev1.Pod) {
+				pod.Annotations = map[string]string{
+					label.AnnTiCDCGracefulShutdownBeginTime: time.Now().Add(-10 * time.Minute).Format(time.RFC3339),
+				}
+			},
+			cdcControl: func(cdcControl *controller.FakeTiCDCControl) {
+				cdcControl.MockDrainCapture(func(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error) {
+					return 3, false, nil
+				})
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
+			},
+		},
 		{
 			name: "modify oldSet update strategy to OnDelete",
 			changeOldSet: func(set *apps.StatefulSet) {
@@ -71,6 +71,10 @@ func (m *realTidbDiscoveryManager) Reconcile(obj client.Object) error {
 			Verbs:         []string{"get"},
 		}
 	case *v1alpha1.DMCluster:
+		// If dm-master is not deployed return, the discovery is only used by dm-master
+		if cluster.HeterogeneousWithoutLocalMaster() {
+			return nil
+		}
 		clusterPolicyRule = rbacv1.PolicyRule{
 			APIGroups:     []string{v1alpha1.GroupName},
 			Resources:     []string{v1alpha1.DMClusterName},
@@ -162,21 +166,24 @@ func getTidbDiscoveryService(obj metav1.Object, deploy *appsv1.Deployment) *core
 
 func (m *realTidbDiscoveryManager) getTidbDiscoveryDeployment(obj metav1.Object) (*appsv1.Deployment, error) {
 	var (
-		resources corev1.ResourceRequirements
-		timezone  string
-		baseSpec  v1alpha1.ComponentAccessor
-		podSpec   corev1.PodSpec
+		resources      corev1.ResourceRequirements
+		timezone       string
+		registryPrefix string
+		baseSpec       v1alpha1.ComponentAccessor
+		podSpec        corev1.PodSpec
 	)
 
 	switch cluster := obj.(type) {
 	case *v1alpha1.TidbCluster:
 		resources = cluster.Spec.Discovery.ResourceRequirements
 		timezone = cluster.Timezone()
+		registryPrefix = cluster.Spec.ClusterRegistryPrefix
 		baseSpec = cluster.BaseDiscoverySpec()
 		podSpec = baseSpec.BuildPodSpec()
 	case *v1alpha1.DMCluster:
 		resources = cluster.Spec.Discovery.ResourceRequirements
 		timezone = cluster.Timezone()
+		registryPrefix = cluster.Spec.ClusterRegistryPrefix
 		baseSpec = cluster.BaseDiscoverySpec()
 		podSpec = baseSpec.BuildPodSpec()
 	default:
@@ -212,7 +219,7 @@ func (m *realTidbDiscoveryManager) getTidbDiscoveryDeployment(obj metav1.Object)
 		Command: []string{
 			"/usr/local/bin/tidb-discovery",
 		},
-		Image:           m.deps.CLIConfig.TiDBDiscoveryImage,
+		Image:           v1alpha1.ImageWithRegistryPrefix(registryPrefix, m.deps.CLIConfig.TiDBDiscoveryImage),
 		ImagePullPolicy: baseSpec.ImagePullPolicy(),
 		Env:             envs,
 		VolumeMounts:    volMounts,
@@ -255,6 +262,13 @@ func (m *realTidbDiscoveryManager) getTidbDiscoveryDeployment(obj metav1.Object)
 			Value: strconv.FormatBool(true),
 		})
 	}
+	// only set the port if it's customized to avoid restarting the existing discovery
+	if tc, ok := obj.(*v1alpha1.TidbCluster); ok && tc.Spec.PD != nil && tc.Spec.PD.ClientPort != nil {
+		podSpec.Containers[0].Env = append(podSpec.Containers[0].Env, corev1.EnvVar{
+			Name:  "TC_PD_CLIENT_PORT",
+			Value: strconv.Itoa(int(tc.PDClientPort())),
+		})
+	}
 
 	podLabels := util.CombineStringMap(l.Labels(), baseSpec.Labels())
 	podAnnotations := baseSpec.Annotations()
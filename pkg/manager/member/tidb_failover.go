@@ -43,7 +43,7 @@ func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
 
 	for _, tidbMember := range tc.Status.TiDB.Members {
 		_, exist := tc.Status.TiDB.FailureMembers[tidbMember.Name]
-		if exist && tidbMember.Health {
+		if exist && tidbMember.Health && !isPodOnTerminatingNode(f.deps, tc, tidbMember.Name) {
 			delete(tc.Status.TiDB.FailureMembers, tidbMember.Name)
 			klog.Infof("tidb failover: delete %s from tidb failoverMembers", tidbMember.Name)
 		}
@@ -84,6 +84,9 @@ func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
 				klog.Warningf("pod %s/%s is not scheduled yet, skipping failover", pod.Namespace, pod.Name)
 				continue
 			}
+			if isPodOnCordonedNode(f.deps, tc, pod.Name) {
+				continue
+			}
 
 			tc.Status.TiDB.FailureMembers[tidbMember.Name] = v1alpha1.TiDBFailureMember{
 				PodName:   tidbMember.Name,
@@ -17,9 +17,11 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	batchv1 "k8s.io/api/batch/v1"
@@ -47,6 +49,10 @@ const (
 	startScriptDir      = "/usr/local/bin"
 	startKey            = "start-script"
 	initStartKey        = "init-start-script"
+	// the SQL scripts in the object storage are downloaded by the init container to the emptyDir volume
+	sqlStorageContainerName = "download-sql"
+	sqlStorageKey           = "init-sql-storage"
+	sqlStorageDir           = "/sql"
 )
 
 // InitManager implements the logic for syncing TidbInitializer.
@@ -84,10 +90,10 @@ func (m *tidbInitManager) Sync(ti *v1alpha1.TidbInitializer) error {
 	if err != nil {
 		return err
 	}
-	return m.updateStatus(ti.DeepCopy())
+	return m.updateStatus(ti.DeepCopy(), tc)
 }
 
-func (m *tidbInitManager) updateStatus(ti *v1alpha1.TidbInitializer) error {
+func (m *tidbInitManager) updateStatus(ti *v1alpha1.TidbInitializer, tc *v1alpha1.TidbCluster) error {
 	name := controller.TiDBInitializerMemberName(ti.Spec.Clusters.Name)
 	ns := ti.Namespace
 	job, err := m.deps.JobLister.Jobs(ns).Get(name)
@@ -118,6 +124,13 @@ func (m *tidbInitManager) updateStatus(ti *v1alpha1.TidbInitializer) error {
 		ti.Status.Phase = phase
 		update = true
 	}
+	if phase == v1alpha1.InitializePhaseCompleted {
+		oldUserReconciliation := ti.Status.UserReconciliation.DeepCopy()
+		m.syncUsers(ti, tc)
+		if !apiequality.Semantic.DeepEqual(ti.Status.UserReconciliation, oldUserReconciliation) {
+			update = true
+		}
+	}
 	if update {
 		_, err = m.updateInitializer(ti)
 		return err
@@ -184,7 +197,7 @@ func (m *tidbInitManager) syncTiDBInitConfigMap(ti *v1alpha1.TidbInitializer) er
 	if tc.Spec.TiDB.IsTLSClientEnabled() && !tc.SkipTLSWhenConnectTiDB() {
 		tlsClientEnabled = true
 	}
-	newCm, err := getTiDBInitConfigMap(ti, tlsClientEnabled)
+	newCm, err := getTiDBInitConfigMap(ti, tlsClientEnabled, tc.TiDBPort())
 	if err != nil {
 		return err
 	}
@@ -347,12 +360,55 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 		})
 	}
 
+	var sqlStorageContainer *corev1.Container
+	if spec := ti.Spec.InitSqlStorage; spec != nil {
+		env, _, err := backuputil.GenerateStorageCertEnv(ns, false, spec.StorageProvider, m.deps.SecretLister)
+		if err != nil {
+			return nil, fmt.Errorf("makeTiDBInitJob: init sql storage of TidbInitializer %s/%s, error: %v", ns, ti.Name, err)
+		}
+		script, err := initSqlStorageScript(spec)
+		if err != nil {
+			return nil, fmt.Errorf("makeTiDBInitJob: init sql storage of TidbInitializer %s/%s, error: %v", ns, ti.Name, err)
+		}
+		vms = append(vms, corev1.VolumeMount{
+			Name: sqlStorageKey, ReadOnly: true, MountPath: sqlStorageDir,
+		})
+		vs = append(vs, corev1.Volume{
+			Name:         sqlStorageKey,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		sqlStorageContainer = &corev1.Container{
+			Name:  sqlStorageContainerName,
+			Image: v1alpha1.ImageWithRegistryPrefix(ti.Spec.ClusterRegistryPrefix, spec.Image),
+			// the rclone config of the storages is generated by the entrypoint of tidb-backup-manager from the env
+			Args: []string{"sh", "-c", script},
+			Env:  append(append([]corev1.EnvVar{}, envs...), env...),
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: sqlStorageKey, MountPath: sqlStorageDir},
+			},
+		}
+	}
+
+	if err := util.ValidateAdditionalVolumes(vs, vms, ti.Spec.AdditionalVolumes, ti.Spec.AdditionalVolumeMounts); err != nil {
+		return nil, fmt.Errorf("makeTiDBInitJob: invalid additional volumes of TidbInitializer %s/%s, error: %v", ns, ti.Name, err)
+	}
+	additionalVolumesHash, err := util.AdditionalVolumesHash(m.deps.UserConfigMapLister, m.deps.SecretLister, ns, ti.Spec.AdditionalVolumes)
+	if err != nil {
+		return nil, fmt.Errorf("makeTiDBInitJob: failed to hash the additional volumes of TidbInitializer %s/%s, error: %v", ns, ti.Name, err)
+	}
+	vs = append(vs, ti.Spec.AdditionalVolumes...)
+	vms = append(vms, ti.Spec.AdditionalVolumeMounts...)
+
 	meta, initLabel := getInitMeta(ti)
 
+	podAnnotations := util.CopyStringMap(ti.ObjectMeta.Annotations)
+	if additionalVolumesHash != "" {
+		podAnnotations = util.CombineStringMap(map[string]string{label.AnnAdditionalVolumesHash: additionalVolumesHash}, podAnnotations)
+	}
 	podSpec := &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:      util.CombineStringMap(initLabel, ti.ObjectMeta.Labels),
-			Annotations: util.CopyStringMap(ti.ObjectMeta.Annotations),
+			Annotations: podAnnotations,
 		},
 		Spec: corev1.PodSpec{
 			ImagePullSecrets: ti.Spec.ImagePullSecrets,
@@ -360,7 +416,7 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 			InitContainers: []corev1.Container{
 				{
 					Name:    initContainerName,
-					Image:   ti.Spec.Image,
+					Image:   v1alpha1.ImageWithRegistryPrefix(ti.Spec.ClusterRegistryPrefix, ti.Spec.Image),
 					Command: initcmds,
 					VolumeMounts: []corev1.VolumeMount{
 						{
@@ -376,7 +432,7 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 			Containers: []corev1.Container{
 				{
 					Name:         containerName,
-					Image:        ti.Spec.Image,
+					Image:        v1alpha1.ImageWithRegistryPrefix(ti.Spec.ClusterRegistryPrefix, ti.Spec.Image),
 					Command:      cmds,
 					VolumeMounts: vms,
 					Env:          envs,
@@ -395,6 +451,9 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 		podSpec.Spec.Containers[0].Resources = *ti.Spec.Resources
 		podSpec.Spec.InitContainers[0].Resources = *ti.Spec.Resources
 	}
+	if sqlStorageContainer != nil {
+		podSpec.Spec.InitContainers = append(podSpec.Spec.InitContainers, *sqlStorageContainer)
+	}
 
 	job := &batchv1.Job{
 		ObjectMeta: meta,
@@ -407,7 +466,7 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 	return job, nil
 }
 
-func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool) (*corev1.ConfigMap, error) {
+func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool, tidbPort int32) (*corev1.ConfigMap, error) {
 	var initSQL, passwdSet bool
 
 	permitHost := ti.GetPermitHost()
@@ -421,16 +480,20 @@ func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool) (
 
 	initStartScript, err := RenderTiDBInitInitStartScript(&TiDBInitInitStartScriptModel{
 		ClusterName: ti.Spec.Clusters.Name,
+		Port:        tidbPort,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	initModel := &TiDBInitStartScriptModel{
-		ClusterName: ti.Spec.Clusters.Name,
-		PermitHost:  permitHost,
-		InitSQL:     initSQL,
-		PasswordSet: passwdSet,
+		ClusterName:    ti.Spec.Clusters.Name,
+		Port:           tidbPort,
+		PermitHost:     permitHost,
+		InitSQL:        initSQL,
+		PasswordSet:    passwdSet,
+		InitSQLStorage: ti.Spec.InitSqlStorage != nil,
+		SQLStorageDir:  sqlStorageDir,
 	}
 	if tlsClientEnabled {
 		initModel.TLS = true
@@ -460,6 +523,40 @@ func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool) (
 	return cm, nil
 }
 
+// initSqlStorageScript returns the script downloading the SQL scripts from the storage. The listed files are
+// renamed with the prefixes of their indexes, so that they are executed in the order listed.
+func initSqlStorageScript(spec *v1alpha1.InitSqlStorageSpec) (string, error) {
+	switch backuputil.GetStorageType(spec.StorageProvider) {
+	case v1alpha1.BackupStorageTypeS3, v1alpha1.BackupStorageTypeGcs:
+	default:
+		return "", fmt.Errorf("only s3 and gcs are supported")
+	}
+	remote, err := rcloneRemote(spec.StorageProvider, "")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "set -e\n")
+	if len(spec.Files) == 0 {
+		fmt.Fprintf(&b, "rclone --config=/tmp/rclone.conf copy %s %s --include '*.sql'\n", shellQuote(remote), sqlStorageDir)
+	}
+	for i, file := range spec.Files {
+		dest := path.Join(sqlStorageDir, fmt.Sprintf("%04d-%s", i, path.Base(file)))
+		if !strings.HasSuffix(dest, ".sql") {
+			dest += ".sql"
+		}
+		fmt.Fprintf(&b, "rclone --config=/tmp/rclone.conf copyto %s %s\n", shellQuote(remote+"/"+strings.TrimPrefix(file, "/")), shellQuote(dest))
+	}
+	fmt.Fprintf(&b, "ls -lR %s\n", sqlStorageDir)
+	return b.String(), nil
+}
+
+// shellQuote quotes the string as a single argument of sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 func getInitMeta(ti *v1alpha1.TidbInitializer) (metav1.ObjectMeta, label.Label) {
 	name := controller.TiDBInitializerMemberName(ti.Spec.Clusters.Name)
 	initLabel := label.NewInitializer().Instance(ti.Name).Initializer(ti.Name)
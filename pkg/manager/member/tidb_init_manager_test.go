@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	apps "k8s.io/api/apps/v1"
@@ -48,6 +49,11 @@ func TestTiDBInitManagerSync(t *testing.T) {
 		}
 
 		ti := newTidbInitializerForTiDB()
+		ti.Spec.AdditionalVolumes = []corev1.Volume{{
+			Name:         "ca-bundle",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"}}},
+		}}
+		ti.Spec.AdditionalVolumeMounts = []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/custom"}}
 		oldSpec := ti.Spec
 
 		if test.tc != nil {
@@ -68,6 +74,9 @@ func TestTiDBInitManagerSync(t *testing.T) {
 
 			job, err := tim.makeTiDBInitJob(ti)
 			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(ti.Spec.AdditionalVolumes[0]))
+			g.Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(ti.Spec.AdditionalVolumeMounts[0]))
+			g.Expect(job.Spec.Template.Annotations).To(HaveKey(label.AnnAdditionalVolumesHash))
 			err = indexers.job.Add(job)
 			g.Expect(err).NotTo(HaveOccurred())
 		}
@@ -82,7 +91,7 @@ func TestTiDBInitManagerSync(t *testing.T) {
 			if err != nil {
 				return err
 			}
-			err = tim.updateStatus(ti.DeepCopy())
+			err = tim.updateStatus(ti.DeepCopy(), test.tc)
 			*/
 			return err
 		}()
@@ -128,6 +137,47 @@ func TestTiDBInitManagerSync(t *testing.T) {
 	}
 }
 
+func TestMakeTiDBInitJobWithInitSqlStorage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tim, tmm, _ := newFakeTiDBInitManager()
+	tc := newTidbClusterForTiDB()
+	g.Expect(tmm.deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+
+	ti := newTidbInitializerForTiDB()
+	ti.Spec.InitSqlStorage = &v1alpha1.InitSqlStorageSpec{
+		StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Provider: v1alpha1.S3StorageProviderTypeAWS, Bucket: "seed", Prefix: "prod"}},
+		Image:           "pingcap/tidb-backup-manager",
+		Files:           []string{"schema/tables.sql", "data/users"},
+	}
+	job, err := tim.makeTiDBInitJob(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+	podSpec := job.Spec.Template.Spec
+	g.Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{Name: sqlStorageKey, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}))
+	g.Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: sqlStorageKey, ReadOnly: true, MountPath: sqlStorageDir}))
+	g.Expect(podSpec.InitContainers).To(HaveLen(2))
+	download := podSpec.InitContainers[1]
+	g.Expect(download.Name).To(Equal(sqlStorageContainerName))
+	g.Expect(download.Image).To(Equal("pingcap/tidb-backup-manager"))
+	g.Expect(download.Args[2]).To(ContainSubstring("rclone --config=/tmp/rclone.conf copyto 's3:seed/prod/schema/tables.sql' '/sql/0000-tables.sql'\n"))
+	g.Expect(download.Args[2]).To(ContainSubstring("rclone --config=/tmp/rclone.conf copyto 's3:seed/prod/data/users' '/sql/0001-users.sql'\n"))
+
+	// all the scripts under the prefix
+	ti.Spec.InitSqlStorage.Files = nil
+	job, err = tim.makeTiDBInitJob(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(job.Spec.Template.Spec.InitContainers[1].Args[2]).To(ContainSubstring("rclone --config=/tmp/rclone.conf copy 's3:seed/prod' /sql --include '*.sql'\n"))
+
+	// the scripts are executed by the start script
+	cm, err := getTiDBInitConfigMap(ti, false, 4000)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data[startKey]).To(ContainSubstring("os.walk('/sql')"))
+
+	ti.Spec.InitSqlStorage.StorageProvider = v1alpha1.StorageProvider{Local: &v1alpha1.LocalStorageProvider{}}
+	_, err = tim.makeTiDBInitJob(ti)
+	g.Expect(err).To(HaveOccurred())
+}
+
 func newFakeTiDBInitManager() (*tidbInitManager, *tidbMemberManager, *fakeIndexers) {
 	tmm, _, _, indexers := newFakeTiDBMemberManager()
 	indexers.job = tmm.deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer()
@@ -0,0 +1,136 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const rootUser = "root"
+
+var (
+	// the privileges and the objects of the grants are put into the GRANT statements as is
+	grantPrivilegePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z_ ]*$`)
+	grantObjectPattern    = regexp.MustCompile("^[A-Za-z0-9_$`*]+(\\.[A-Za-z0-9_$`*]+)?$")
+)
+
+// syncUsers reconciles the users declared in the passwordSecret every interval once the initializer Job completes.
+// The users are synced through a healthy TiDB instance as root, which is the user the initializer Job connects
+// as too. The failures are recorded in the status and retried in the next reconciliation.
+func (m *tidbInitManager) syncUsers(ti *v1alpha1.TidbInitializer, tc *v1alpha1.TidbCluster) {
+	if !ti.IsUserReconciliationEnabled() {
+		ti.Status.UserReconciliation = nil
+		return
+	}
+	now := time.Now()
+	if status := ti.Status.UserReconciliation; status != nil && now.Sub(status.LastReconcileTime.Time) < ti.UserReconcileInterval() {
+		return
+	}
+
+	status := &v1alpha1.UserReconciliationStatus{LastReconcileTime: metav1.NewTime(now)}
+	ti.Status.UserReconciliation = status
+	if err := m.reconcileUsers(ti, tc, status); err != nil {
+		status.Message = err.Error()
+		klog.Warningf("TidbInitializer: [%s/%s] failed to reconcile the users, error: %v", ti.Namespace, ti.Name, err)
+	}
+}
+
+func (m *tidbInitManager) reconcileUsers(ti *v1alpha1.TidbInitializer, tc *v1alpha1.TidbCluster, status *v1alpha1.UserReconciliationStatus) error {
+	ns := ti.GetNamespace()
+	secretName := *ti.Spec.PasswordSecret
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(secretName)
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s: %v", secretName, err)
+	}
+
+	users := []string{}
+	for user := range secret.Data {
+		// the hidden files of the mounted secret are skipped by the initializer Job
+		if user == rootUser || strings.HasPrefix(user, ".") {
+			continue
+		}
+		users = append(users, user)
+	}
+	sort.Strings(users)
+
+	grants := map[string][]string{}
+	for _, grant := range ti.Spec.UserReconciliation.Grants {
+		if _, ok := secret.Data[grant.User]; !ok || grant.User == rootUser {
+			return fmt.Errorf("user %s of the grants is not declared in the secret %s", grant.User, secretName)
+		}
+		for _, privilege := range grant.Privileges {
+			if !grantPrivilegePattern.MatchString(privilege) {
+				return fmt.Errorf("invalid privilege %q of user %s", privilege, grant.User)
+			}
+		}
+		if len(grant.Privileges) == 0 || !grantObjectPattern.MatchString(grant.On) {
+			return fmt.Errorf("invalid grant %v ON %q of user %s", grant.Privileges, grant.On, grant.User)
+		}
+		grants[grant.User] = append(grants[grant.User], fmt.Sprintf("%s ON %s", strings.Join(grant.Privileges, ", "), grant.On))
+	}
+
+	var ordinals []int32
+	for _, member := range tc.Status.TiDB.Members {
+		if !member.Health {
+			continue
+		}
+		ordinal, err := util.GetOrdinalFromPodName(member.Name)
+		if err != nil {
+			continue
+		}
+		ordinals = append(ordinals, ordinal)
+	}
+	if len(ordinals) == 0 {
+		return fmt.Errorf("no healthy tidb instance")
+	}
+	sort.Slice(ordinals, func(i, j int) bool { return ordinals[i] < ordinals[j] })
+
+	rootPassword := firstLine(secret.Data[rootUser])
+	for _, user := range users {
+		sqlUser := controller.SQLUser{
+			Name:     user,
+			Host:     ti.GetPermitHost(),
+			Password: firstLine(secret.Data[user]),
+			Grants:   grants[user],
+		}
+		created, err := m.deps.TiDBControl.SyncUser(tc, ordinals[0], rootUser, rootPassword, sqlUser)
+		if err != nil {
+			return fmt.Errorf("failed to sync user %s through %s: %v", user, tidbPodName(tc.GetName(), ordinals[0]), err)
+		}
+		if created {
+			status.Recreated = append(status.Recreated, user)
+		}
+	}
+	if len(status.Recreated) > 0 {
+		m.deps.Recorder.Eventf(ti, corev1.EventTypeWarning, "UserRecreated", "users %s are dropped and recreated", strings.Join(status.Recreated, ","))
+		klog.Infof("TidbInitializer: [%s/%s] recreated users %v", ns, ti.Name, status.Recreated)
+	}
+	return nil
+}
+
+// firstLine returns the first line of the password, as the initializer Job reads it
+func firstLine(data []byte) string {
+	return strings.SplitN(string(data), "\n", 2)[0]
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestTiDBInitManagerSyncUsers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tim := &tidbInitManager{deps: fakeDeps}
+	tidbControl := fakeDeps.TiDBControl.(*controller.FakeTiDBControl)
+	tc := newTidbClusterForTiDB()
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+		"test-tidb-0": {Name: "test-tidb-0", Health: false},
+		"test-tidb-1": {Name: "test-tidb-1", Health: true},
+	}
+	ti := newTidbInitializerForTiDB()
+	ti.Spec.PasswordSecret = pointer.StringPtr("test-users")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-users", Namespace: ti.Namespace},
+		Data: map[string][]byte{
+			"root": []byte("root-password\n"),
+			"app":  []byte("app-password"),
+			"ro":   []byte("ro-password"),
+		},
+	}
+	g.Expect(fakeDeps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer().Add(secret)).To(Succeed())
+
+	// the users are only created by the Job if the reconciliation is disabled
+	tim.syncUsers(ti, tc)
+	g.Expect(ti.Status.UserReconciliation).To(BeNil())
+	_, ok := tidbControl.GetUser("app", "%")
+	g.Expect(ok).To(BeFalse())
+
+	ti.Spec.UserReconciliation = &v1alpha1.UserReconciliationSpec{
+		Grants: []v1alpha1.UserGrant{
+			{User: "app", Privileges: []string{"ALL PRIVILEGES"}, On: "app.*"},
+			{User: "ro", Privileges: []string{"SELECT", "SHOW VIEW"}, On: "app.*"},
+			{User: "ro", Privileges: []string{"PROCESS"}, On: "*.*"},
+		},
+	}
+	tim.syncUsers(ti, tc)
+	status := ti.Status.UserReconciliation
+	g.Expect(status).NotTo(BeNil())
+	g.Expect(status.Message).To(BeEmpty())
+	g.Expect(status.Recreated).To(Equal([]string{"app", "ro"}))
+	user, ok := tidbControl.GetUser("ro", "%")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(user).To(Equal(controller.SQLUser{Name: "ro", Host: "%", Password: "ro-password", Grants: []string{"SELECT, SHOW VIEW ON app.*", "PROCESS ON *.*"}}))
+	_, ok = tidbControl.GetUser("root", "%")
+	g.Expect(ok).To(BeFalse())
+
+	// not reconciled until the interval elapses
+	tidbControl.DropUser("app", "%")
+	tim.syncUsers(ti, tc)
+	g.Expect(ti.Status.UserReconciliation.LastReconcileTime).To(Equal(status.LastReconcileTime))
+	_, ok = tidbControl.GetUser("app", "%")
+	g.Expect(ok).To(BeFalse())
+
+	// the dropped user is recreated
+	ti.Status.UserReconciliation.LastReconcileTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	tim.syncUsers(ti, tc)
+	g.Expect(ti.Status.UserReconciliation.Recreated).To(Equal([]string{"app"}))
+	_, ok = tidbControl.GetUser("app", "%")
+	g.Expect(ok).To(BeTrue())
+	events := collectEvents(fakeDeps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ContainElement(ContainSubstring("UserRecreated users app are dropped and recreated")))
+
+	// the grants are checked before being put into the statements
+	ti.Status.UserReconciliation.LastReconcileTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	ti.Spec.UserReconciliation.Grants = []v1alpha1.UserGrant{{User: "app", Privileges: []string{"SELECT"}, On: "app.*; DROP DATABASE app"}}
+	tim.syncUsers(ti, tc)
+	g.Expect(ti.Status.UserReconciliation.Message).To(ContainSubstring("invalid grant"))
+
+	ti.Status.UserReconciliation.LastReconcileTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	ti.Spec.UserReconciliation.Grants = []v1alpha1.UserGrant{{User: "unknown", Privileges: []string{"SELECT"}, On: "*.*"}}
+	tim.syncUsers(ti, tc)
+	g.Expect(ti.Status.UserReconciliation.Message).To(ContainSubstring("user unknown of the grants is not declared"))
+
+	// no healthy tidb instance
+	ti.Status.UserReconciliation.LastReconcileTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	ti.Spec.UserReconciliation.Grants = nil
+	tc.Status.TiDB.Members = nil
+	tim.syncUsers(ti, tc)
+	g.Expect(ti.Status.UserReconciliation.Message).To(Equal("no healthy tidb instance"))
+}
@@ -14,6 +14,7 @@
 package member
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"path"
@@ -33,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	corelisters "k8s.io/client-go/listers/core/v1"
@@ -101,6 +103,10 @@ func (m *tidbMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 
+	if err := m.syncTiDBPerPodServices(tc); err != nil {
+		return err
+	}
+
 	if tc.Spec.TiDB.IsTLSClientEnabled() {
 		if err := m.checkTLSClientCert(tc); err != nil {
 			return err
@@ -108,7 +114,11 @@ func (m *tidbMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	}
 
 	// Sync TiDB StatefulSet
-	return m.syncTiDBStatefulSetForTidbCluster(tc)
+	if err := m.syncTiDBStatefulSetForTidbCluster(tc); err != nil {
+		return err
+	}
+
+	return syncReadOnly(m.deps, tc)
 }
 
 func (m *tidbMemberManager) checkTLSClientCert(tc *v1alpha1.TidbCluster) error {
@@ -203,8 +213,15 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 	if err != nil {
 		return err
 	}
+	if err := addDebugArtifactsSidecar(m.deps, tc, v1alpha1.TiDBMemberType, &newTiDBSet.Spec.Template.Spec); err != nil {
+		return err
+	}
 
 	if setNotExist {
+		if tenantPolicyBlocksCreation(tc) {
+			klog.Infof("TidbCluster: %s/%s, the tenant policy is violated, skip creating the tidb statefulset", tc.GetNamespace(), tc.GetName())
+			return nil
+		}
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newTiDBSet)
 		if err != nil {
 			return err
@@ -217,6 +234,10 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		return nil
 	}
 
+	if err := syncScaleOutResources(m.deps, tc, v1alpha1.TiDBMemberType, oldTiDBSet, newTiDBSet); err != nil {
+		return err
+	}
+
 	// Scaling takes precedence over upgrading because:
 	// - if a pod fails in the upgrading, users may want to delete it or add
 	//   new replicas
@@ -226,6 +247,9 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		return err
 	}
 
+	// Create the replacement Pods in advance for the members on the nodes going to be terminated
+	syncTiDBSpotTermination(m.deps, tc)
+
 	if m.deps.CLIConfig.AutoFailover {
 		if m.shouldRecover(tc) {
 			m.tidbFailover.Recover(tc)
@@ -236,12 +260,17 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		}
 	}
 
+	syncTimezoneChange(m.deps, tc, v1alpha1.TiDBMemberType, oldTiDBSet, newTiDBSet)
+
 	if !templateEqual(newTiDBSet, oldTiDBSet) || tc.Status.TiDB.Phase == v1alpha1.UpgradePhase {
 		if err := m.tidbUpgrader.Upgrade(tc, oldTiDBSet, newTiDBSet); err != nil {
 			return err
 		}
 	}
 
+	if versionSkewUpgradeRequested(tc, v1alpha1.TiDBMemberType, oldTiDBSet) {
+		return mngerutils.ForceUpdateStatefulSet(m.deps.StatefulSetControl, tc, newTiDBSet, oldTiDBSet)
+	}
 	return mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, tc, newTiDBSet, oldTiDBSet)
 }
 
@@ -249,6 +278,9 @@ func (m *tidbMemberManager) shouldRecover(tc *v1alpha1.TidbCluster) bool {
 	if tc.Status.TiDB.FailureMembers == nil {
 		return false
 	}
+	if hasFailureOnTerminatingNode(m.deps, tc, tidbFailureMemberPodNames(tc)) {
+		return false
+	}
 	// If all desired replicas (excluding failover pods) of tidb cluster are
 	// healthy, we can perform our failover recovery operation.
 	// Note that failover pods may fail (e.g. lack of resources) and we don't care
@@ -282,7 +314,11 @@ func (m *tidbMemberManager) syncTiDBService(tc *v1alpha1.TidbCluster) error {
 	if newSvc == nil {
 		return nil
 	}
+	return m.createOrUpdateTiDBService(tc, newSvc, tc.Spec.TiDB.Service)
+}
 
+// createOrUpdateTiDBService creates the TiDB service or updates it if it's changed, the orphan service is adopted
+func (m *tidbMemberManager) createOrUpdateTiDBService(tc *v1alpha1.TidbCluster, newSvc *corev1.Service, svcSpec *v1alpha1.TiDBServiceSpec) error {
 	ns := newSvc.Namespace
 
 	oldSvcTmp, err := m.deps.ServiceLister.Services(ns).Get(newSvc.Name)
@@ -291,7 +327,10 @@ func (m *tidbMemberManager) syncTiDBService(tc *v1alpha1.TidbCluster) error {
 		if err != nil {
 			return err
 		}
-		return m.deps.ServiceControl.CreateService(tc, newSvc)
+		if err := m.deps.ServiceControl.CreateService(tc, newSvc); err != nil {
+			return err
+		}
+		return m.patchTiDBServiceInternalTrafficPolicy(tc, newSvc, svcSpec.InternalTrafficPolicy)
 	}
 	if err != nil {
 		return fmt.Errorf("syncTiDBService: failed to get svc %s for cluster %s/%s, error: %s", newSvc.Name, ns, tc.GetName(), err)
@@ -341,18 +380,24 @@ func (m *tidbMemberManager) syncTiDBService(tc *v1alpha1.TidbCluster) error {
 		svc.OwnerReferences = newSvc.OwnerReferences
 	}
 
-	_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
-	return err
+	if _, err = m.deps.ServiceControl.UpdateService(tc, &svc); err != nil {
+		return err
+	}
+	return m.patchTiDBServiceInternalTrafficPolicy(tc, &svc, svcSpec.InternalTrafficPolicy)
 }
 
 // syncTiDBConfigMap syncs the configmap of tidb
 func (m *tidbMemberManager) syncTiDBConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
 
-	// For backward compatibility, only sync tidb configmap when .tidb.config is non-nil
-	if tc.Spec.TiDB.Config == nil {
+	// For backward compatibility, only sync tidb configmap when .tidb.config or .tidb.configMapRef is non-nil
+	if tc.Spec.TiDB.Config == nil && tc.Spec.TiDB.ConfigMapRef == nil {
 		return nil, nil
 	}
-	newCm, err := getTiDBConfigMap(tc)
+	cfgTC, err := withTiDBConfigMapRef(m.deps.UserConfigMapLister, tc)
+	if err != nil {
+		return nil, err
+	}
+	newCm, err := getTiDBConfigMap(cfgTC, controller.RefPDClientPort(m.deps.TiDBClusterLister, cfgTC))
 	if err != nil {
 		return nil, err
 	}
@@ -373,7 +418,9 @@ func (m *tidbMemberManager) syncTiDBConfigMap(tc *v1alpha1.TidbCluster, set *app
 	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
 }
 
-func getTiDBConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
+// getTiDBConfigMap returns the ConfigMap of TiDB, refPDClientPort is the PD client port of the cluster
+// referenced by the heterogeneous cluster without local PD
+func getTiDBConfigMap(tc *v1alpha1.TidbCluster, refPDClientPort int32) (*corev1.ConfigMap, error) {
 	config := tc.Spec.TiDB.Config
 	if config == nil {
 		return nil, nil
@@ -393,7 +440,14 @@ func getTiDBConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 		config.Set("security.ssl-cert", path.Join(serverCertPath, corev1.TLSCertKey))
 		config.Set("security.ssl-key", path.Join(serverCertPath, corev1.TLSPrivateKeyKey))
 	}
-	confText, err := config.MarshalTOML()
+	// only set the ports if they are customized to avoid restarting the existing clusters
+	if tc.Spec.TiDB.Port != nil {
+		config.Set("port", int64(*tc.Spec.TiDB.Port))
+	}
+	if tc.Spec.TiDB.StatusPort != nil {
+		config.Set("status.status-port", int64(*tc.Spec.TiDB.StatusPort))
+	}
+	confText, err := withConfigProfile(tc, v1alpha1.TiDBMemberType, config.GenericConfig).MarshalTOML()
 	if err != nil {
 		return nil, err
 	}
@@ -404,13 +458,15 @@ func getTiDBConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 		PluginDirectory: "/plugins",
 		PluginList:      strings.Join(plugins, ","),
 		ClusterDomain:   tc.Spec.ClusterDomain,
+
+		StartScriptVersion: tc.BaseTiDBSpec().StartScriptVersion(),
 	}
 
 	if tc.HeterogeneousWithoutLocalPD() {
 		// FIXME: not work for across k8s cluster without local pd
-		tidbStartScriptModel.Path = controller.PDMemberName(tc.Spec.Cluster.Name) + ":2379"
+		tidbStartScriptModel.Path = fmt.Sprintf("%s:%d", controller.PDMemberName(tc.Spec.Cluster.Name), refPDClientPort)
 	} else {
-		tidbStartScriptModel.Path = "${CLUSTER_NAME}-pd:2379"
+		tidbStartScriptModel.Path = fmt.Sprintf("${CLUSTER_NAME}-pd:%d", tc.PDClientPort())
 	}
 
 	startScript, err := RenderTiDBStartScript(tidbStartScriptModel)
@@ -445,12 +501,14 @@ func getNewTiDBServiceOrNil(tc *v1alpha1.TidbCluster) *corev1.Service {
 		return nil
 	}
 
+	tidbSelector := label.New().Instance(tc.GetInstanceName()).TiDB()
+	return newTiDBService(tc, svcSpec, controller.TiDBMemberName(tc.Name), tidbSelector.Copy().UsedByEndUser(), tidbSelector.Labels())
+}
+
+// newTiDBService returns the TiDB service of the spec that selects the TiDB Pods by the selector
+func newTiDBService(tc *v1alpha1.TidbCluster, svcSpec *v1alpha1.TiDBServiceSpec, svcName string, svcLabel label.Label, selector map[string]string) *corev1.Service {
 	ns := tc.Namespace
-	tcName := tc.Name
-	instanceName := tc.GetInstanceName()
-	tidbSelector := label.New().Instance(instanceName).TiDB()
-	svcName := controller.TiDBMemberName(tcName)
-	tidbLabels := util.CombineStringMap(tidbSelector.Copy().UsedByEndUser().Labels(), svcSpec.Labels)
+	tidbLabels := util.CombineStringMap(svcLabel.Labels(), svcSpec.Labels)
 	portName := "mysql-client"
 	if svcSpec.PortName != nil {
 		portName = *svcSpec.PortName
@@ -458,18 +516,18 @@ func getNewTiDBServiceOrNil(tc *v1alpha1.TidbCluster) *corev1.Service {
 	ports := []corev1.ServicePort{
 		{
 			Name:       portName,
-			Port:       4000,
-			TargetPort: intstr.FromInt(4000),
+			Port:       tc.TiDBPort(),
+			TargetPort: intstr.FromInt(int(tc.TiDBPort())),
 			Protocol:   corev1.ProtocolTCP,
 			NodePort:   svcSpec.GetMySQLNodePort(),
 		},
 	}
-	ports = append(ports, tc.Spec.TiDB.Service.AdditionalPorts...)
+	ports = append(ports, svcSpec.AdditionalPorts...)
 	if svcSpec.ShouldExposeStatus() {
 		ports = append(ports, corev1.ServicePort{
 			Name:       "status",
-			Port:       10080,
-			TargetPort: intstr.FromInt(10080),
+			Port:       tc.TiDBStatusPort(),
+			TargetPort: intstr.FromInt(int(tc.TiDBStatusPort())),
 			Protocol:   corev1.ProtocolTCP,
 			NodePort:   svcSpec.GetStatusNodePort(),
 		})
@@ -486,7 +544,7 @@ func getNewTiDBServiceOrNil(tc *v1alpha1.TidbCluster) *corev1.Service {
 		Spec: corev1.ServiceSpec{
 			Type:     svcSpec.Type,
 			Ports:    ports,
-			Selector: tidbSelector.Labels(),
+			Selector: selector,
 		},
 	}
 	if svcSpec.Type == corev1.ServiceTypeLoadBalancer {
@@ -503,9 +561,40 @@ func getNewTiDBServiceOrNil(tc *v1alpha1.TidbCluster) *corev1.Service {
 	if svcSpec.ClusterIP != nil {
 		tidbSvc.Spec.ClusterIP = *svcSpec.ClusterIP
 	}
+	if svcSpec.SessionAffinity != "" {
+		tidbSvc.Spec.SessionAffinity = svcSpec.SessionAffinity
+		tidbSvc.Spec.SessionAffinityConfig = svcSpec.SessionAffinityConfig
+	}
+	if svcSpec.TopologyAwareHints {
+		if tidbSvc.Annotations == nil {
+			tidbSvc.Annotations = map[string]string{}
+		}
+		tidbSvc.Annotations[label.AnnTopologyAwareHints] = label.AnnTopologyAwareHintsVal
+	}
+	if svcSpec.InternalTrafficPolicy != nil {
+		if tidbSvc.Annotations == nil {
+			tidbSvc.Annotations = map[string]string{}
+		}
+		tidbSvc.Annotations[label.AnnInternalTrafficPolicy] = string(*svcSpec.InternalTrafficPolicy)
+	}
 	return tidbSvc
 }
 
+// patchTiDBServiceInternalTrafficPolicy sets the internal traffic policy of the TiDB service by patch, as it's not
+// in the Service API the operator is built with. The policy is reset to the default once the service is updated,
+// which happens only when the annotation recording the policy or the other fields are changed, and set again here.
+func (m *tidbMemberManager) patchTiDBServiceInternalTrafficPolicy(tc *v1alpha1.TidbCluster, svc *corev1.Service, policy *v1alpha1.ServiceInternalTrafficPolicyType) error {
+	if policy == nil {
+		return nil
+	}
+	patch := fmt.Sprintf(`{"spec":{"internalTrafficPolicy":%q}}`, *policy)
+	_, err := m.deps.KubeClientset.CoreV1().Services(svc.Namespace).Patch(context.TODO(), svc.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("syncTiDBService: failed to set internal traffic policy of svc %s for cluster %s/%s, error: %s", svc.Name, svc.Namespace, tc.GetName(), err)
+	}
+	return nil
+}
+
 func getNewTiDBHeadlessServiceForTidbCluster(tc *v1alpha1.TidbCluster) *corev1.Service {
 	ns := tc.Namespace
 	tcName := tc.Name
@@ -526,8 +615,8 @@ func getNewTiDBHeadlessServiceForTidbCluster(tc *v1alpha1.TidbCluster) *corev1.S
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "status",
-					Port:       10080,
-					TargetPort: intstr.FromInt(10080),
+					Port:       tc.TiDBStatusPort(),
+					TargetPort: intstr.FromInt(int(tc.TiDBStatusPort())),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
@@ -617,7 +706,7 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 				privileged := true
 				initContainers = append(initContainers, corev1.Container{
 					Name:  "init",
-					Image: tc.HelperImage(),
+					Image: baseTiDBSpec.HelperImage(),
 					Command: []string{
 						"sh",
 						"-c",
@@ -691,8 +780,8 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		}
 		containers = append(containers, corev1.Container{
 			Name:            v1alpha1.SlowLogTailerMemberType.String(),
-			Image:           tc.HelperImage(),
-			ImagePullPolicy: tc.HelperImagePullPolicy(),
+			Image:           baseTiDBSpec.HelperImage(),
+			ImagePullPolicy: baseTiDBSpec.HelperImagePullPolicy(),
 			Resources:       controller.ContainerResource(tc.Spec.TiDB.GetSlowLogTailerSpec().ResourceRequirements),
 			VolumeMounts:    []corev1.VolumeMount{slowQueryLogVolumeMount},
 			Command: []string{
@@ -710,7 +799,7 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		},
 		{
 			Name:  "TZ",
-			Value: tc.Spec.Timezone,
+			Value: baseTiDBSpec.Timezone(),
 		},
 		{
 			Name:  "BINLOG_ENABLED",
@@ -750,12 +839,12 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "server",
-				ContainerPort: int32(4000),
+				ContainerPort: tc.TiDBPort(),
 				Protocol:      corev1.ProtocolTCP,
 			},
 			{
 				Name:          "status", // pprof, status, metrics
-				ContainerPort: int32(10080),
+				ContainerPort: tc.TiDBStatusPort(),
 				Protocol:      corev1.ProtocolTCP,
 			},
 		},
@@ -776,6 +865,7 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	podSpec := baseTiDBSpec.BuildPodSpec()
 	podSpec.Containers = append(containers, baseTiDBSpec.AdditionalContainers()...)
 	podSpec.Volumes = append(vols, baseTiDBSpec.AdditionalVolumes()...)
+	addTimezoneData(baseTiDBSpec, &podSpec, v1alpha1.TiDBMemberType.String())
 	podSpec.SecurityContext = podSecurityContext
 	podSpec.InitContainers = append(initContainers, baseTiDBSpec.InitContainers()...)
 	podSpec.ServiceAccountName = tc.Spec.TiDB.ServiceAccount
@@ -789,8 +879,9 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 
 	stsLabels := label.New().Instance(instanceName).TiDB()
 	podLabels := util.CombineStringMap(stsLabels, baseTiDBSpec.Labels())
-	podAnnotations := util.CombineStringMap(controller.AnnProm(10080), baseTiDBSpec.Annotations())
-	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiDBLabelVal)
+	podAnnotations := util.CombineStringMap(controller.AnnProm(tc.TiDBStatusPort()), baseTiDBSpec.Annotations())
+	setStartScriptVersionAnnotation(podAnnotations, baseTiDBSpec.StartScriptVersion())
+	stsAnnotations := getTidbClusterStsAnnotations(tc, label.TiDBLabelVal)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
 	if err != nil {
@@ -832,6 +923,7 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	}
 
 	tidbSet.Spec.VolumeClaimTemplates = append(tidbSet.Spec.VolumeClaimTemplates, additionalPVCs...)
+	setVolumeClaimCloudTags(tc, tidbSet.Spec.VolumeClaimTemplates)
 	return tidbSet, nil
 }
 
@@ -842,6 +934,7 @@ func (m *tidbMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 	}
 
 	tc.Status.TiDB.StatefulSet = &set.Status
+	tc.Status.TiDB.StartScriptVersion = getStartScriptVersion(set)
 
 	upgrading, err := m.tidbStatefulSetIsUpgradingFn(m.deps.PodLister, set, tc)
 	if err != nil {
@@ -856,6 +949,9 @@ func (m *tidbMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 	} else {
 		tc.Status.TiDB.Phase = v1alpha1.NormalPhase
 	}
+	if tc.Status.TiDB.Phase != v1alpha1.UpgradePhase {
+		clearRollingUpdateProgress(&tc.Status.TiDB.RollingUpdate, &tc.Status.TiDB.Conditions)
+	}
 
 	tidbStatus := map[string]v1alpha1.TiDBMember{}
 	for id := range helper.GetPodOrdinals(tc.Status.TiDB.StatefulSet.Replicas, set) {
@@ -942,7 +1038,7 @@ func buildTiDBReadinessProbHandler(tc *v1alpha1.TidbCluster) corev1.Handler {
 	// fall to default case v1alpha1.TCPProbeType
 	return corev1.Handler{
 		TCPSocket: &corev1.TCPSocketAction{
-			Port: intstr.FromInt(4000),
+			Port: intstr.FromInt(int(tc.TiDBPort())),
 		},
 	}
 }
@@ -950,7 +1046,7 @@ func buildTiDBReadinessProbHandler(tc *v1alpha1.TidbCluster) corev1.Handler {
 func buildTiDBProbeCommand(tc *v1alpha1.TidbCluster) (command []string) {
 	host := "127.0.0.1"
 
-	readinessURL := fmt.Sprintf("%s://%s:10080/status", tc.Scheme(), host)
+	readinessURL := fmt.Sprintf("%s://%s:%d/status", tc.Scheme(), host, tc.TiDBStatusPort())
 	command = append(command, "curl")
 	command = append(command, readinessURL)
 
@@ -1555,6 +1555,7 @@ func TestTiDBInitContainers(t *testing.T) {
 func TestGetNewTiDBService(t *testing.T) {
 	g := NewGomegaWithT(t)
 	trafficPolicy := corev1.ServiceExternalTrafficPolicyTypeLocal
+	internalTrafficPolicy := v1alpha1.ServiceInternalTrafficPolicyLocal
 	loadBalancerSourceRanges := []string{
 		"10.0.0.0/8",
 		"130.211.204.1/32",
@@ -1791,6 +1792,90 @@ func TestGetNewTiDBService(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "TiDB service with topology aware routing",
+			tc: v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "ns",
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					TiDB: &v1alpha1.TiDBSpec{
+						Service: &v1alpha1.TiDBServiceSpec{
+							ServiceSpec: v1alpha1.ServiceSpec{
+								Type: corev1.ServiceTypeClusterIP,
+							},
+							InternalTrafficPolicy: &internalTrafficPolicy,
+							TopologyAwareHints:    true,
+							SessionAffinity:       corev1.ServiceAffinityClientIP,
+							SessionAffinityConfig: &corev1.SessionAffinityConfig{
+								ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: pointer.Int32Ptr(600)},
+							},
+						},
+					},
+					PD:   &v1alpha1.PDSpec{},
+					TiKV: &v1alpha1.TiKVSpec{},
+				},
+			},
+			expected: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-tidb",
+					Namespace: "ns",
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       "tidb-cluster",
+						"app.kubernetes.io/managed-by": "tidb-operator",
+						"app.kubernetes.io/instance":   "foo",
+						"app.kubernetes.io/component":  "tidb",
+						"app.kubernetes.io/used-by":    "end-user",
+					},
+					Annotations: map[string]string{
+						"service.kubernetes.io/topology-aware-hints": "auto",
+						"tidb.pingcap.com/internal-traffic-policy":   "Local",
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "pingcap.com/v1alpha1",
+							Kind:       "TidbCluster",
+							Name:       "foo",
+							UID:        "",
+							Controller: func(b bool) *bool {
+								return &b
+							}(true),
+							BlockOwnerDeletion: func(b bool) *bool {
+								return &b
+							}(true),
+						},
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Type: corev1.ServiceTypeClusterIP,
+					Ports: []corev1.ServicePort{
+						{
+							Name:       "mysql-client",
+							Port:       4000,
+							TargetPort: intstr.FromInt(4000),
+							Protocol:   corev1.ProtocolTCP,
+						},
+						{
+							Name:       "status",
+							Port:       10080,
+							TargetPort: intstr.FromInt(10080),
+							Protocol:   corev1.ProtocolTCP,
+						},
+					},
+					Selector: map[string]string{
+						"app.kubernetes.io/name":       "tidb-cluster",
+						"app.kubernetes.io/managed-by": "tidb-operator",
+						"app.kubernetes.io/instance":   "foo",
+						"app.kubernetes.io/component":  "tidb",
+					},
+					SessionAffinity: corev1.ServiceAffinityClientIP,
+					SessionAffinityConfig: &corev1.SessionAffinityConfig{
+						ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: pointer.Int32Ptr(600)},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -2004,7 +2089,7 @@ func TestGetTiDBConfigMap(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			cm, err := getTiDBConfigMap(&tt.tc)
+			cm, err := getTiDBConfigMap(&tt.tc, v1alpha1.DefaultPDClientPort)
 			g.Expect(err).To(Succeed())
 			if tt.expected == nil {
 				g.Expect(cm).To(BeNil())
@@ -2396,6 +2481,46 @@ func TestBuildTiDBProbeHandler(t *testing.T) {
 	}
 	get = buildTiDBReadinessProbHandler(tc)
 	g.Expect(get).Should(Equal(defaultHandler))
+
+	// test custom ports
+	tc.Spec.TiDB.Port = pointer.Int32Ptr(4001)
+	tc.Spec.TiDB.StatusPort = pointer.Int32Ptr(10081)
+	get = buildTiDBReadinessProbHandler(tc)
+	g.Expect(get.TCPSocket.Port).Should(Equal(intstr.FromInt(4001)))
+	tc.Spec.TiDB.ReadinessProbe = &v1alpha1.TiDBProbe{
+		Type: pointer.StringPtr(v1alpha1.CommandProbeType),
+	}
+	get = buildTiDBReadinessProbHandler(tc)
+	g.Expect(get.Exec.Command[1]).Should(Equal("https://127.0.0.1:10081/status"))
+}
+
+func TestGetNewTiDBSetForTidbClusterWithCustomPorts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.PD.ClientPort = pointer.Int32Ptr(12379)
+	tc.Spec.TiDB.Port = pointer.Int32Ptr(4001)
+	tc.Spec.TiDB.StatusPort = pointer.Int32Ptr(10081)
+	tc.Spec.TiDB.Config = v1alpha1.NewTiDBConfig()
+
+	cm, err := getTiDBConfigMap(tc, v1alpha1.DefaultPDClientPort)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring("port = 4001"))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring("status-port = 10081"))
+	g.Expect(cm.Data["startup-script"]).To(ContainSubstring("--path=${CLUSTER_NAME}-pd:12379"))
+
+	sts, err := getNewTiDBSetForTidbCluster(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(sts.Spec.Template.Annotations["prometheus.io/port"]).To(Equal("10081"))
+	var tidb *corev1.Container
+	for i := range sts.Spec.Template.Spec.Containers {
+		if sts.Spec.Template.Spec.Containers[i].Name == v1alpha1.TiDBMemberType.String() {
+			tidb = &sts.Spec.Template.Spec.Containers[i]
+		}
+	}
+	g.Expect(tidb).NotTo(BeNil())
+	g.Expect(tidb.Ports).To(ContainElement(corev1.ContainerPort{Name: "server", ContainerPort: 4001, Protocol: corev1.ProtocolTCP}))
+	g.Expect(tidb.Ports).To(ContainElement(corev1.ContainerPort{Name: "status", ContainerPort: 10081, Protocol: corev1.ProtocolTCP}))
 }
 
 func newTidbClusterForTiDB() *v1alpha1.TidbCluster {
@@ -2439,3 +2564,20 @@ func mustTiDBConfig(x interface{}) *v1alpha1.TiDBConfigWraper {
 
 	return c
 }
+
+func TestGetTiDBConfigMapHeterogeneousPDAddress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "hetero", Namespace: "ns"},
+		Spec: v1alpha1.TidbClusterSpec{
+			Cluster: &v1alpha1.TidbClusterRef{Name: "ref"},
+			TiDB: &v1alpha1.TiDBSpec{
+				Config: v1alpha1.NewTiDBConfig(),
+			},
+		},
+	}
+	cm, err := getTiDBConfigMap(tc, 12379)
+	g.Expect(err).To(Succeed())
+	g.Expect(cm.Data["startup-script"]).To(ContainSubstring("ref-pd:12379"))
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// tidbPerPodServiceName returns the name of the service of the TiDB Pod, which is the same as the Pod
+func tidbPerPodServiceName(tcName string, ordinal int32) string {
+	return fmt.Sprintf("%s-%d", controller.TiDBMemberName(tcName), ordinal)
+}
+
+// getNewTiDBPerPodService returns the service that only selects the TiDB Pod of the ordinal
+func getNewTiDBPerPodService(tc *v1alpha1.TidbCluster, ordinal int32) *corev1.Service {
+	svcName := tidbPerPodServiceName(tc.Name, ordinal)
+	svcLabel := label.New().Instance(tc.GetInstanceName()).TiDB().UsedByPod()
+	selector := label.New().Instance(tc.GetInstanceName()).TiDB().Labels()
+	selector[apps.StatefulSetPodNameLabel] = svcName
+	return newTiDBService(tc, tc.Spec.TiDB.PerPodService, svcName, svcLabel, selector)
+}
+
+// syncTiDBPerPodServices creates or updates the services of the desired TiDB Pods, including the Pods added by
+// failover. The services of the Pods removed by scale-in are deleted after the Pods are deleted, and all the
+// services are deleted at once if the per-pod services are disabled.
+func (m *tidbMemberManager) syncTiDBPerPodServices(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	if tc.Spec.Paused {
+		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for tidb per-pod services", ns, tcName)
+		return nil
+	}
+
+	desired := sets.Int32{}
+	if tc.Spec.TiDB.PerPodService != nil {
+		desired = tc.TiDBStsDesiredOrdinals(false)
+	}
+	var errs []error
+	for _, ordinal := range desired.List() {
+		if err := m.createOrUpdateTiDBService(tc, getNewTiDBPerPodService(tc, ordinal), tc.Spec.TiDB.PerPodService); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiDB().UsedByPod().Selector()
+	if err != nil {
+		return err
+	}
+	svcs, err := m.deps.ServiceLister.Services(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncTiDBPerPodServices: failed to list per-pod services for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	for _, svc := range svcs {
+		if !metav1.IsControlledBy(svc, tc) {
+			continue
+		}
+		ordinal, err := util.GetOrdinalFromPodName(svc.Name)
+		if err != nil || svc.Name != tidbPerPodServiceName(tcName, ordinal) || desired.Has(ordinal) {
+			continue
+		}
+		if tc.Spec.TiDB.PerPodService != nil {
+			// the Pod may still be serving the clients during the scale-in
+			_, err := m.deps.PodLister.Pods(ns).Get(svc.Name)
+			if err == nil {
+				continue
+			}
+			if !errors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("syncTiDBPerPodServices: failed to get pod %s for cluster %s/%s, error: %s", svc.Name, ns, tcName, err))
+				continue
+			}
+		}
+		klog.Infof("tidb cluster %s/%s deletes the per-pod service %s", ns, tcName, svc.Name)
+		if err := m.deps.ServiceControl.DeleteService(tc, svc); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errorutils.NewAggregate(errs)
+}
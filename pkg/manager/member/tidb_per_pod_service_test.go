@@ -0,0 +1,81 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestSyncTiDBPerPodServices(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiDB.Replicas = 2
+	tc.Spec.TiDB.PerPodService = &v1alpha1.TiDBServiceSpec{
+		ServiceSpec: v1alpha1.ServiceSpec{
+			Type:        corev1.ServiceTypeLoadBalancer,
+			Annotations: map[string]string{"lb": "internal"},
+		},
+	}
+	tmm, _, _, indexers := newFakeTiDBMemberManager()
+	listSvcNames := func() []string {
+		svcs, err := tmm.deps.ServiceLister.Services(tc.Namespace).List(labels.Everything())
+		g.Expect(err).NotTo(HaveOccurred())
+		names := []string{}
+		for _, svc := range svcs {
+			names = append(names, svc.Name)
+		}
+		return names
+	}
+
+	g.Expect(tmm.syncTiDBPerPodServices(tc)).To(Succeed())
+	g.Expect(listSvcNames()).To(ConsistOf("test-tidb-0", "test-tidb-1"))
+	svc, err := tmm.deps.ServiceLister.Services(tc.Namespace).Get("test-tidb-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+	g.Expect(svc.Spec.Selector).To(HaveKeyWithValue(apps.StatefulSetPodNameLabel, "test-tidb-1"))
+	g.Expect(svc.Spec.Selector).To(HaveKeyWithValue(label.ComponentLabelKey, label.TiDBLabelVal))
+	g.Expect(svc.Labels).To(HaveKeyWithValue(label.UsedByLabelKey, "pod"))
+	g.Expect(svc.Annotations).To(HaveKeyWithValue("lb", "internal"))
+	g.Expect(metav1.IsControlledBy(svc, tc)).To(BeTrue())
+
+	// the service is kept until the Pod is deleted in the scale-in
+	tc.Spec.TiDB.Replicas = 1
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-tidb-1", Namespace: tc.Namespace}}
+	g.Expect(indexers.pod.Add(pod)).To(Succeed())
+	g.Expect(tmm.syncTiDBPerPodServices(tc)).To(Succeed())
+	g.Expect(listSvcNames()).To(ConsistOf("test-tidb-0", "test-tidb-1"))
+	g.Expect(indexers.pod.Delete(pod)).To(Succeed())
+	g.Expect(tmm.syncTiDBPerPodServices(tc)).To(Succeed())
+	g.Expect(listSvcNames()).To(ConsistOf("test-tidb-0"))
+
+	// the services not owned by the cluster are kept
+	g.Expect(indexers.svc.Add(&corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-tidb-2",
+		Namespace: tc.Namespace,
+		Labels:    label.New().Instance(tc.Name).TiDB().UsedByPod().Labels(),
+	}})).To(Succeed())
+	// all the services are deleted once the per-pod services are disabled
+	tc.Spec.TiDB.PerPodService = nil
+	g.Expect(tmm.syncTiDBPerPodServices(tc)).To(Succeed())
+	g.Expect(listSvcNames()).To(ConsistOf("test-tidb-2"))
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	readOnlyDefaultUser = "root"
+	readOnlyPasswordKey = "password"
+)
+
+// syncReadOnly enforces the read-only mode of the cluster. The global tidb_super_read_only system variable is set
+// through a healthy TiDB instance, then all the healthy instances are checked to see the new value, as the global
+// system variables are propagated to the other instances asynchronously. The scheduling of PD is halted too if
+// required. The mode is kept being enforced while it's enabled, and it's left alone once it's confirmed disabled.
+func syncReadOnly(deps *controller.Dependencies, tc *v1alpha1.TidbCluster) error {
+	spec := tc.Spec.ReadOnly
+	status := tc.Status.ReadOnly
+	enabled := spec != nil && spec.Enabled
+	if status == nil {
+		if !enabled {
+			return nil
+		}
+		status = &v1alpha1.ReadOnlyStatus{}
+		tc.Status.ReadOnly = status
+	}
+	if status.Enabled != enabled {
+		status.Enabled = enabled
+		status.Enforced = false
+		status.LastTransitionTime = metav1.Now()
+	}
+	if !enabled && status.Enforced && !status.SchedulingHalted {
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	haltScheduling := enabled && spec.HaltScheduling
+	if status.SchedulingHalted != haltScheduling {
+		pdClient := controller.GetPDClient(deps.PDControl, tc)
+		if err := pdClient.UpdateScheduleConfig(map[string]interface{}{"halt-scheduling": haltScheduling}); err != nil {
+			return fmt.Errorf("tidbcluster: [%s/%s] failed to set halt-scheduling of pd to %t, error: %v", ns, tcName, haltScheduling, err)
+		}
+		status.SchedulingHalted = haltScheduling
+		klog.Infof("tidbcluster: [%s/%s] set halt-scheduling of pd to %t", ns, tcName, haltScheduling)
+	}
+	if status.Enforced && !enabled {
+		return nil
+	}
+
+	if spec == nil {
+		status.Message = "spec.readOnly is removed, set spec.readOnly.enabled to false instead to turn off the read-only mode"
+		return nil
+	}
+	user := spec.User
+	if user == "" {
+		user = readOnlyDefaultUser
+	}
+	secret, err := deps.SecretLister.Secrets(ns).Get(spec.SecretName)
+	if err != nil {
+		status.Message = fmt.Sprintf("failed to get secret %s: %v", spec.SecretName, err)
+		return nil
+	}
+	password := string(secret.Data[readOnlyPasswordKey])
+
+	ordinals := []int32{}
+	for _, member := range tc.Status.TiDB.Members {
+		if !member.Health {
+			continue
+		}
+		ordinal, err := util.GetOrdinalFromPodName(member.Name)
+		if err != nil {
+			continue
+		}
+		ordinals = append(ordinals, ordinal)
+	}
+	if len(ordinals) == 0 {
+		status.Enforced = false
+		status.Message = "no healthy tidb instance"
+		return nil
+	}
+	sort.Slice(ordinals, func(i, j int) bool { return ordinals[i] < ordinals[j] })
+
+	readOnly, err := deps.TiDBControl.GetSuperReadOnly(tc, ordinals[0], user, password)
+	if err != nil {
+		status.Enforced = false
+		status.Message = fmt.Sprintf("failed to get tidb_super_read_only from %s: %v", tidbPodName(tcName, ordinals[0]), err)
+		return nil
+	}
+	if readOnly != enabled {
+		if err := deps.TiDBControl.SetSuperReadOnly(tc, ordinals[0], user, password, enabled); err != nil {
+			status.Enforced = false
+			status.Message = fmt.Sprintf("failed to set tidb_super_read_only through %s: %v", tidbPodName(tcName, ordinals[0]), err)
+			return nil
+		}
+		klog.Infof("tidbcluster: [%s/%s] set tidb_super_read_only to %t through %s", ns, tcName, enabled, tidbPodName(tcName, ordinals[0]))
+	}
+
+	pending := []string{}
+	for _, ordinal := range ordinals {
+		readOnly, err := deps.TiDBControl.GetSuperReadOnly(tc, ordinal, user, password)
+		if err != nil || readOnly != enabled {
+			pending = append(pending, tidbPodName(tcName, ordinal))
+		}
+	}
+	if len(pending) > 0 {
+		status.Enforced = false
+		status.Message = fmt.Sprintf("waiting for tidb instances %s to see tidb_super_read_only", strings.Join(pending, ","))
+		return nil
+	}
+
+	if !status.Enforced {
+		status.Enforced = true
+		status.Message = ""
+		if enabled {
+			deps.Recorder.Event(tc, corev1.EventTypeNormal, utiltidbcluster.ReadOnlyEnforced, "all the tidb instances are read-only")
+		} else {
+			deps.Recorder.Event(tc, corev1.EventTypeNormal, utiltidbcluster.ReadOnlyDisabled, "all the tidb instances are writable")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncReadOnly(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tidbControl := deps.TiDBControl.(*controller.FakeTiDBControl)
+	tc := newTidbClusterForTiDB()
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+		"test-tidb-0": {Name: "test-tidb-0", Health: true},
+		"test-tidb-1": {Name: "test-tidb-1", Health: true},
+		// the unhealthy instance is not checked
+		"test-tidb-2": {Name: "test-tidb-2", Health: false},
+	}
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	g.Expect(secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidb-secret", Namespace: tc.Namespace},
+		Data:       map[string][]byte{"password": []byte("pass")},
+	})).To(Succeed())
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	var halted interface{}
+	pdClient.AddReaction(pdapi.UpdateScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		halted = action.Config["halt-scheduling"]
+		return nil, nil
+	})
+
+	// nothing is done if the read-only mode is not enabled
+	g.Expect(syncReadOnly(deps, tc)).To(Succeed())
+	g.Expect(tc.Status.ReadOnly).To(BeNil())
+
+	// the variable is set through the first instance and not enforced until seen by all the instances
+	tc.Spec.ReadOnly = &v1alpha1.ReadOnlySpec{Enabled: true, SecretName: "tidb-secret", HaltScheduling: true}
+	g.Expect(syncReadOnly(deps, tc)).To(Succeed())
+	status := tc.Status.ReadOnly
+	g.Expect(status.Enabled).To(BeTrue())
+	g.Expect(status.Enforced).To(BeFalse())
+	g.Expect(status.SchedulingHalted).To(BeTrue())
+	g.Expect(halted).To(Equal(true))
+	g.Expect(status.Message).To(Equal("waiting for tidb instances test-tidb-1 to see tidb_super_read_only"))
+
+	tidbControl.SetSuperReadOnlyOfPod("test-tidb-1", true)
+	g.Expect(syncReadOnly(deps, tc)).To(Succeed())
+	g.Expect(status.Enforced).To(BeTrue())
+	g.Expect(status.Message).To(BeEmpty())
+
+	// the scheduling is resumed and the instances are writable again once disabled
+	tc.Spec.ReadOnly.Enabled = false
+	tidbControl.SetSuperReadOnlyOfPod("test-tidb-1", false)
+	g.Expect(syncReadOnly(deps, tc)).To(Succeed())
+	g.Expect(status.Enabled).To(BeFalse())
+	g.Expect(status.Enforced).To(BeTrue())
+	g.Expect(status.SchedulingHalted).To(BeFalse())
+	g.Expect(halted).To(Equal(false))
+}
@@ -14,6 +14,7 @@
 package member
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -22,6 +23,10 @@ import (
 
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -85,6 +90,13 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 	}
 
 	mngerutils.SetUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
+	if syncRollingUpdateProgress(u.deps, tc, v1alpha1.TiDBMemberType, tc.Spec.TiDB.ProgressDeadlineSeconds, tc.Status.TiDB.StatefulSet.UpdateRevision,
+		*oldSet.Spec.UpdateStrategy.RollingUpdate.Partition, &tc.Status.TiDB.RollingUpdate, &tc.Status.TiDB.Conditions) {
+		return nil
+	}
+	if maxUnavailable := tc.TiDBUpgradeMaxUnavailable(); maxUnavailable > 1 {
+		return u.upgradeTiDBPodsInParallel(tc, oldSet, newSet, maxUnavailable)
+	}
 	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
 	for _i := len(podOrdinals) - 1; _i >= 0; _i-- {
 		i := podOrdinals[_i]
@@ -102,8 +114,16 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 			if member, exist := tc.Status.TiDB.Members[podName]; !exist || !member.Health {
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb upgraded pod: [%s] is not ready", ns, tcName, podName)
 			}
+			if msg := checkUpgradedPodPacing(tc.BaseTiDBSpec(), pod); msg != "" {
+				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb upgraded pod: [%s] %s", ns, tcName, podName, msg)
+			}
 			continue
 		}
+
+		resized, err := resizePodInPlace(u.deps, tc, v1alpha1.TiDBMemberType.String(), tc.Spec.TiDB.ResizePolicy, pod, oldSet, tc.Status.TiDB.StatefulSet.UpdateRevision)
+		if err != nil || resized {
+			return err
+		}
 		return u.upgradeTiDBPod(tc, i, newSet)
 	}
 
@@ -115,6 +135,84 @@ func (u *tidbUpgrader) upgradeTiDBPod(tc *v1alpha1.TidbCluster, ordinal int32, n
 	return nil
 }
 
+// upgradeTiDBPodsInParallel restarts at most maxUnavailable TiDB pods at the same time. The partition is lowered to
+// the lowest ordinal of the pods to upgrade, and the pods at or above the partition of the StatefulSet are evicted,
+// as the StatefulSet controller only recreates them one by one, the evicted pods are then recreated with the update
+// revision. The pods are resized in place instead if possible. The upgraded pods that are not
+// healthy yet count as unavailable, and the evictions are rejected if they would violate the PodDisruptionBudget.
+func (u *tidbUpgrader) upgradeTiDBPodsInParallel(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSet, newSet *apps.StatefulSet, maxUnavailable int) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	updateRevision := tc.Status.TiDB.StatefulSet.UpdateRevision
+	partition := *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition
+
+	unavailable := 0
+	var evictPods []*corev1.Pod
+	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
+	for _i := len(podOrdinals) - 1; _i >= 0; _i-- {
+		i := podOrdinals[_i]
+		podName := tidbPodName(tcName, i)
+		pod, err := u.deps.PodLister.Pods(ns).Get(podName)
+		if errors.IsNotFound(err) && i >= partition {
+			// the evicted pod is being recreated
+			unavailable++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("tidbUpgrader.Upgrade: failed to get pods %s for cluster %s/%s, error: %s", podName, ns, tcName, err)
+		}
+		revision, exist := pod.Labels[apps.ControllerRevisionHashLabelKey]
+		if !exist {
+			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb pod: [%s] has no label: %s", ns, tcName, podName, apps.ControllerRevisionHashLabelKey)
+		}
+
+		if revision == updateRevision {
+			if member, exist := tc.Status.TiDB.Members[podName]; !exist || !member.Health || checkUpgradedPodPacing(tc.BaseTiDBSpec(), pod) != "" {
+				unavailable++
+			}
+			continue
+		}
+		if i < partition && unavailable >= maxUnavailable {
+			break
+		}
+
+		resized, err := resizePodInPlace(u.deps, tc, v1alpha1.TiDBMemberType.String(), tc.Spec.TiDB.ResizePolicy, pod, oldSet, updateRevision)
+		if err != nil {
+			return err
+		}
+		if resized {
+			continue
+		}
+		unavailable++
+		if i >= partition {
+			// the pod is allowed to be upgraded but not restarted yet
+			evictPods = append(evictPods, pod)
+			continue
+		}
+		// the pod is evicted in the next sync, once the StatefulSet is updated with the lowered partition, otherwise
+		// the StatefulSet controller would recreate the evicted pod with the current revision
+		partition = i
+	}
+	mngerutils.SetUpgradePartition(newSet, partition)
+
+	for _, pod := range evictPods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		err := u.deps.KubeClientset.PolicyV1beta1().Evictions(ns).Evict(context.TODO(), &policyv1beta1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: ns},
+		})
+		if errors.IsTooManyRequests(err) {
+			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb pod: [%s] can not be evicted now: %v", ns, tcName, pod.Name, err)
+		}
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("tidbUpgrader.Upgrade: failed to evict pod %s for cluster %s/%s, error: %s", pod.Name, ns, tcName, err)
+		}
+		klog.Infof("tidbcluster: [%s/%s]'s tidb pod: [%s] is evicted to be upgraded", ns, tcName, pod.Name)
+	}
+	return nil
+}
+
 type fakeTiDBUpgrader struct{}
 
 // NewFakeTiDBUpgrader returns a fake tidb upgrader
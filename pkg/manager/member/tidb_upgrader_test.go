@@ -14,19 +14,31 @@
 package member
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
 
 	. "github.com/onsi/gomega"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	podinformers "k8s.io/client-go/informers/core/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
 	"k8s.io/utils/pointer"
 )
 
@@ -205,6 +217,41 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
 			},
 		},
+		{
+			name: "progress deadline exceeded",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Spec.TiDB.ProgressDeadlineSeconds = pointer.Int32Ptr(600)
+				tc.Status.TiDB.RollingUpdate = &v1alpha1.RollingUpdateStatus{
+					UpdateRevision:   "2",
+					Partition:        1,
+					LastProgressTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+				}
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+				g.Expect(meta.IsStatusConditionTrue(tc.Status.TiDB.Conditions, v1alpha1.ComponentProgressDeadlineExceeded)).To(BeTrue())
+			},
+		},
+		{
+			name: "progress deadline not exceeded",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Spec.TiDB.ProgressDeadlineSeconds = pointer.Int32Ptr(600)
+			},
+			getLastAppliedConfigErr: false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
+				g.Expect(tc.Status.TiDB.RollingUpdate).NotTo(BeNil())
+				g.Expect(tc.Status.TiDB.RollingUpdate.Partition).To(Equal(int32(1)))
+				g.Expect(tc.Status.TiDB.Conditions).To(BeEmpty())
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -213,6 +260,129 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 
 }
 
+func TestTiDBUpgrader_UpgradeInParallel(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	upgrader, _, podInformer := newTiDBUpgrader()
+	kubeCli := upgrader.(*tidbUpgrader).deps.KubeClientset.(*kubefake.Clientset)
+	var evicted []string
+	pdbAllowed := true
+	kubeCli.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if !pdbAllowed {
+			return true, nil, errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		eviction := action.(core.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		evicted = append(evicted, eviction.Name)
+		return true, nil, nil
+	})
+
+	tc := newTidbClusterForTiDBUpgrader()
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	tc.Spec.TiDB.Replicas = 4
+	tc.Spec.TiDB.UpgradeStrategy = &v1alpha1.TiDBUpgradeStrategy{MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"}}
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{}
+	for i := int32(0); i < 4; i++ {
+		name := tidbPodName(upgradeTcName, i)
+		labels := label.New().Instance(upgradeInstanceName).TiDB().Labels()
+		labels[apps.ControllerRevisionHashLabelKey] = "1"
+		tc.Status.TiDB.Members[name] = v1alpha1.TiDBMember{Name: name, Health: true}
+		podInformer.Informer().GetIndexer().Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: corev1.NamespaceDefault,
+				Labels:    labels,
+			},
+		})
+	}
+	oldSet := newStatefulSetForTiDBUpgrader()
+	oldSet.Spec.Replicas = pointer.Int32Ptr(4)
+	oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(4)
+	mngerutils.SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+
+	// the partition is lowered first, the pods are not evicted until the partition is persisted
+	newSet := oldSet.DeepCopy()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+	g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(2)))
+	g.Expect(evicted).To(BeEmpty())
+
+	// the evictions are rejected by the pdb
+	pdbAllowed = false
+	oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+	newSet = oldSet.DeepCopy()
+	err := upgrader.Upgrade(tc, oldSet, newSet)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+	g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(2)))
+
+	// two pods are evicted at the same time
+	pdbAllowed = true
+	newSet = oldSet.DeepCopy()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(2)))
+	g.Expect(evicted).To(Equal([]string{"upgrader-tidb-3", "upgrader-tidb-2"}))
+
+	// the next pods are not upgraded until the recreated pods are healthy
+	evicted = nil
+	podInformer.Informer().GetIndexer().Delete(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "upgrader-tidb-3", Namespace: corev1.NamespaceDefault}})
+	obj, _, _ := podInformer.Informer().GetIndexer().GetByKey("default/upgrader-tidb-2")
+	pod := obj.(*corev1.Pod).DeepCopy()
+	pod.Labels[apps.ControllerRevisionHashLabelKey] = "2"
+	podInformer.Informer().GetIndexer().Update(pod)
+	tc.Status.TiDB.Members["upgrader-tidb-2"] = v1alpha1.TiDBMember{Name: "upgrader-tidb-2", Health: false}
+	newSet = oldSet.DeepCopy()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(2)))
+	g.Expect(evicted).To(BeEmpty())
+
+	// one more pod is upgraded once one of them is healthy
+	tc.Status.TiDB.Members["upgrader-tidb-2"] = v1alpha1.TiDBMember{Name: "upgrader-tidb-2", Health: true}
+	newSet = oldSet.DeepCopy()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+	g.Expect(evicted).To(BeEmpty())
+	oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(1)
+	newSet = oldSet.DeepCopy()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+	g.Expect(evicted).To(Equal([]string{"upgrader-tidb-1"}))
+
+	// the pod allowed to be upgraded is resized in place instead of evicted if possible
+	evicted = nil
+	for _, name := range []string{"upgrader-tidb-1", "upgrader-tidb-3"} {
+		recreated := pod.DeepCopy()
+		recreated.Name = name
+		podInformer.Informer().GetIndexer().Add(recreated)
+	}
+	tc.Spec.FeatureGates = map[string]bool{features.InPlacePodVerticalScaling: true}
+	crIndexer := upgrader.(*tidbUpgrader).deps.KubeInformerFactory.Apps().V1().ControllerRevisions().Informer().GetIndexer()
+	for revision, cpu := range map[string]string{"1": "1", "2": "2"} {
+		template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:      v1alpha1.TiDBMemberType.String(),
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+		}}}}
+		data, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": template}})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(crIndexer.Add(&apps.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: revision, Namespace: corev1.NamespaceDefault},
+			Data:       runtime.RawExtension{Raw: data},
+		})).To(Succeed())
+	}
+	obj, _, _ = podInformer.Informer().GetIndexer().GetByKey("default/upgrader-tidb-0")
+	_, err = kubeCli.CoreV1().Pods(corev1.NamespaceDefault).Create(context.TODO(), obj.(*corev1.Pod), metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(0)
+	newSet = oldSet.DeepCopy()
+	g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(evicted).To(BeEmpty())
+	resized, err := kubeCli.CoreV1().Pods(corev1.NamespaceDefault).Get(context.TODO(), "upgrader-tidb-0", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resized.Labels[apps.ControllerRevisionHashLabelKey]).To(Equal("2"))
+}
+
 func newTiDBUpgrader() (Upgrader, *controller.FakeTiDBControl, podinformers.PodInformer) {
 	fakeDeps := controller.NewFakeDependencies()
 	upgrader := &tidbUpgrader{fakeDeps}
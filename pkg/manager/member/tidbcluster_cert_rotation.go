@@ -0,0 +1,375 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultCertRenewBefore = 30 * 24 * time.Hour
+	// certManagerCertificateNameAnnotation is set by cert-manager on the Secrets it issues
+	certManagerCertificateNameAnnotation = "cert-manager.io/certificate-name"
+	// certRotationKeySuffix is the suffix of the Secret keeping the private key of a pending CertificateSigningRequest
+	certRotationKeySuffix = "-rotation-key"
+)
+
+var certManagerCertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// certSecret is a Secret of certs and the components reloading the certs online once they are rotated
+type certSecret struct {
+	name       string
+	components []v1alpha1.MemberType
+}
+
+// TidbClusterCertRotationManager renews the certificates of the cluster before they expire
+type TidbClusterCertRotationManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewTidbClusterCertRotationManager returns a TidbClusterCertRotationManager
+func NewTidbClusterCertRotationManager(deps *controller.Dependencies) *TidbClusterCertRotationManager {
+	return &TidbClusterCertRotationManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+// Sync checks the expiry of the certificates in the cluster Secrets and renews the expiring ones by the issuer.
+// Once a certificate is observed rotated, the Pods mounting it are annotated, so that kubelet refreshes the
+// mounted Secret at once and the components reload the certificate without restarting. Pump can't reload
+// the certificate and is rolling restarted by its member manager.
+func (m *TidbClusterCertRotationManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if !tc.IsTLSClusterEnabled() || tc.Spec.TLSCluster.CertRotation == nil {
+		tc.Status.CertRotation = nil
+		return nil
+	}
+
+	secrets := certRotationSecrets(tc)
+	statuses := make([]v1alpha1.CertRotationStatus, 0, len(secrets))
+	var errs []error
+	for _, secret := range secrets {
+		status := v1alpha1.CertRotationStatus{SecretName: secret.name}
+		for _, s := range tc.Status.CertRotation {
+			if s.SecretName == secret.name {
+				status = *s.DeepCopy()
+				break
+			}
+		}
+		if err := m.syncSecret(tc, secret, &status); err != nil {
+			errs = append(errs, err)
+		}
+		statuses = append(statuses, status)
+	}
+	tc.Status.CertRotation = statuses
+	return errorutils.NewAggregate(errs)
+}
+
+func (m *TidbClusterCertRotationManager) syncSecret(tc *v1alpha1.TidbCluster, cs certSecret, status *v1alpha1.CertRotationStatus) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	spec := tc.Spec.TLSCluster.CertRotation
+	now := m.now()
+
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(cs.name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			status.Message = fmt.Sprintf("secret %s is not found", cs.name)
+			return nil
+		}
+		return fmt.Errorf("failed to get secret %s for tc %s/%s, error: %v", cs.name, ns, tcName, err)
+	}
+	cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		status.Message = fmt.Sprintf("failed to parse the certificate in secret %s: %v", cs.name, err)
+		return nil
+	}
+
+	if status.NotAfter != nil && cert.NotAfter.After(status.NotAfter.Time) {
+		if err := m.reloadCerts(tc, cs, now); err != nil {
+			return err
+		}
+		klog.Infof("certificate in secret %s of tc %s/%s is rotated, expires at %s", cs.name, ns, tcName, cert.NotAfter.Format(time.RFC3339))
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "CertRotated", "certificate in secret %s is rotated, expires at %s", cs.name, cert.NotAfter.Format(time.RFC3339))
+		status.LastRotationTime = &metav1.Time{Time: now}
+		status.Message = ""
+	}
+	status.NotAfter = &metav1.Time{Time: cert.NotAfter}
+
+	if status.CSRName != "" {
+		return m.syncCSR(tc, secret, status, now)
+	}
+
+	renewBefore := defaultCertRenewBefore
+	if spec.RenewBefore != nil {
+		renewBefore = spec.RenewBefore.Duration
+	}
+	if now.Before(cert.NotAfter.Add(-renewBefore)) {
+		return nil
+	}
+
+	switch spec.Issuer {
+	case v1alpha1.CertIssuerCertManager:
+		return m.renewByCertManager(tc, secret, status)
+	case v1alpha1.CertIssuerCSR:
+		return m.createCSR(tc, secret, cert, status, now)
+	}
+	return nil
+}
+
+// renewByCertManager triggers the reissuance of the Certificate the same way as `cmctl renew` does
+func (m *TidbClusterCertRotationManager) renewByCertManager(tc *v1alpha1.TidbCluster, secret *corev1.Secret, status *v1alpha1.CertRotationStatus) error {
+	ns := tc.GetNamespace()
+	name, ok := secret.Annotations[certManagerCertificateNameAnnotation]
+	if !ok {
+		status.Message = fmt.Sprintf("secret %s is not issued by cert-manager", secret.Name)
+		return nil
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certManagerCertificateGVK)
+	if err := m.deps.GenericClient.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: name}, certificate); err != nil {
+		return fmt.Errorf("failed to get certificate %s/%s for secret %s, error: %v", ns, name, secret.Name, err)
+	}
+	conditions, _, err := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	if err != nil {
+		return fmt.Errorf("failed to get the conditions of certificate %s/%s, error: %v", ns, name, err)
+	}
+	for _, c := range conditions {
+		if cond, ok := c.(map[string]interface{}); ok && cond["type"] == "Issuing" && cond["status"] == string(metav1.ConditionTrue) {
+			// the certificate is being issued
+			return nil
+		}
+	}
+	conditions = append(conditions, map[string]interface{}{
+		"type":               "Issuing",
+		"status":             string(metav1.ConditionTrue),
+		"reason":             "ManuallyTriggered",
+		"message":            "Certificate re-issuance triggered by tidb-operator as it is expiring",
+		"lastTransitionTime": m.now().UTC().Format(time.RFC3339),
+	})
+	if err := unstructured.SetNestedSlice(certificate.Object, conditions, "status", "conditions"); err != nil {
+		return err
+	}
+	if err := m.deps.GenericClient.Status().Update(context.TODO(), certificate); err != nil {
+		return fmt.Errorf("failed to trigger the reissuance of certificate %s/%s, error: %v", ns, name, err)
+	}
+	klog.Infof("reissuance of certificate %s/%s for secret %s of tc %s/%s is triggered", ns, name, secret.Name, ns, tc.GetName())
+	status.Message = ""
+	return nil
+}
+
+// createCSR requests a certificate with the same subject and SANs as the expiring one, the private key is kept
+// in a Secret until the request is issued
+func (m *TidbClusterCertRotationManager) createCSR(tc *v1alpha1.TidbCluster, secret *corev1.Secret, cert *x509.Certificate, status *v1alpha1.CertRotationStatus, now time.Time) error {
+	ns := tc.GetNamespace()
+	ips := make([]string, 0, len(cert.IPAddresses))
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	csrBytes, keyBytes, err := crypto.NewCSR(cert.Subject.CommonName, cert.DNSNames, ips)
+	if err != nil {
+		return fmt.Errorf("failed to generate the csr for secret %s/%s, error: %v", ns, secret.Name, err)
+	}
+
+	keySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            secret.Name + certRotationKeySuffix,
+			Namespace:       ns,
+			Labels:          label.New().Instance(tc.GetInstanceName()).Labels(),
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Data: map[string][]byte{corev1.TLSPrivateKeyKey: keyBytes},
+	}
+	if _, err := m.deps.KubeClientset.CoreV1().Secrets(ns).Create(context.TODO(), keySecret, metav1.CreateOptions{}); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create secret %s/%s, error: %v", ns, keySecret.Name, err)
+		}
+		if _, err := m.deps.KubeClientset.CoreV1().Secrets(ns).Update(context.TODO(), keySecret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update secret %s/%s, error: %v", ns, keySecret.Name, err)
+		}
+	}
+
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			// CertificateSigningRequests are cluster scoped
+			Name:   fmt.Sprintf("%s-%s-%d", ns, secret.Name, now.Unix()),
+			Labels: label.New().Instance(tc.GetInstanceName()).Labels(),
+		},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrBytes}),
+			SignerName: tc.Spec.TLSCluster.CertRotation.SignerName,
+			Usages: []certificatesv1.KeyUsage{
+				certificatesv1.UsageDigitalSignature,
+				certificatesv1.UsageKeyEncipherment,
+				certificatesv1.UsageServerAuth,
+				certificatesv1.UsageClientAuth,
+			},
+		},
+	}
+	if _, err := m.deps.KubeClientset.CertificatesV1().CertificateSigningRequests().Create(context.TODO(), csr, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create csr %s for secret %s/%s, error: %v", csr.Name, ns, secret.Name, err)
+	}
+	klog.Infof("csr %s is created to renew the certificate in secret %s of tc %s/%s", csr.Name, secret.Name, ns, tc.GetName())
+	status.CSRName = csr.Name
+	status.Message = fmt.Sprintf("waiting for csr %s to be approved and issued", csr.Name)
+	return nil
+}
+
+// syncCSR writes the issued certificate and the private key into the Secret
+func (m *TidbClusterCertRotationManager) syncCSR(tc *v1alpha1.TidbCluster, secret *corev1.Secret, status *v1alpha1.CertRotationStatus, now time.Time) error {
+	ns := tc.GetNamespace()
+	csrs := m.deps.KubeClientset.CertificatesV1().CertificateSigningRequests()
+	csr, err := csrs.Get(context.TODO(), status.CSRName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		// the certificate is requested again in the next sync
+		status.CSRName = ""
+		status.Message = ""
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get csr %s for secret %s/%s, error: %v", status.CSRName, ns, secret.Name, err)
+	}
+	for _, cond := range csr.Status.Conditions {
+		if cond.Type == certificatesv1.CertificateDenied || cond.Type == certificatesv1.CertificateFailed {
+			status.Message = fmt.Sprintf("csr %s is %s: %s, delete it to request again", csr.Name, cond.Type, cond.Message)
+			return nil
+		}
+	}
+	if len(csr.Status.Certificate) == 0 {
+		return nil
+	}
+
+	keySecretName := secret.Name + certRotationKeySuffix
+	keySecret, err := m.deps.KubeClientset.CoreV1().Secrets(ns).Get(context.TODO(), keySecretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s, error: %v", ns, keySecretName, err)
+	}
+	newSecret := secret.DeepCopy()
+	newSecret.Data[corev1.TLSCertKey] = csr.Status.Certificate
+	newSecret.Data[corev1.TLSPrivateKeyKey] = keySecret.Data[corev1.TLSPrivateKeyKey]
+	if _, err := m.deps.KubeClientset.CoreV1().Secrets(ns).Update(context.TODO(), newSecret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update secret %s/%s, error: %v", ns, secret.Name, err)
+	}
+	klog.Infof("certificate issued by csr %s is written into secret %s of tc %s/%s", csr.Name, secret.Name, ns, tc.GetName())
+
+	if err := csrs.Delete(context.TODO(), csr.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete csr %s, error: %v", csr.Name, err)
+	}
+	if err := m.deps.KubeClientset.CoreV1().Secrets(ns).Delete(context.TODO(), keySecretName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete secret %s/%s, error: %v", ns, keySecretName, err)
+	}
+	// the pods reload the certificate once the rotation is observed in the next sync
+	status.CSRName = ""
+	status.Message = ""
+	return nil
+}
+
+// reloadCerts annotates the Pods mounting the Secret, so that kubelet refreshes the mounted certs at once
+func (m *TidbClusterCertRotationManager) reloadCerts(tc *v1alpha1.TidbCluster, cs certSecret, now time.Time) error {
+	ns := tc.GetNamespace()
+	for _, component := range cs.components {
+		selector, err := label.New().Instance(tc.GetInstanceName()).Component(component.String()).Selector()
+		if err != nil {
+			return err
+		}
+		pods, err := m.deps.PodLister.Pods(ns).List(selector)
+		if err != nil {
+			return fmt.Errorf("failed to list pods of %s for tc %s/%s, error: %v", component, ns, tc.GetName(), err)
+		}
+		for _, pod := range pods {
+			newPod := pod.DeepCopy()
+			if newPod.Annotations == nil {
+				newPod.Annotations = map[string]string{}
+			}
+			newPod.Annotations[label.AnnCertRotationTime] = now.Format(time.RFC3339)
+			if _, err := m.deps.PodControl.UpdatePod(tc, newPod); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// certRotationSecrets returns the Secrets of the cluster certs of the components deployed, the certs of
+// Pump are rotated too, but Pump is rolling restarted instead of reloading the certs
+func certRotationSecrets(tc *v1alpha1.TidbCluster) []certSecret {
+	tcName := tc.GetName()
+	var secrets []certSecret
+	clientComponents := []v1alpha1.MemberType{}
+	add := func(deployed bool, component v1alpha1.MemberType, mountsClientCert bool) {
+		if !deployed {
+			return
+		}
+		secrets = append(secrets, certSecret{
+			name:       util.ClusterTLSSecretName(tcName, component.String()),
+			components: []v1alpha1.MemberType{component},
+		})
+		if mountsClientCert {
+			clientComponents = append(clientComponents, component)
+		}
+	}
+	add(tc.Spec.PD != nil, v1alpha1.PDMemberType, true)
+	add(tc.Spec.TiKV != nil, v1alpha1.TiKVMemberType, true)
+	add(tc.Spec.TiDB != nil, v1alpha1.TiDBMemberType, false)
+	add(tc.Spec.TiFlash != nil, v1alpha1.TiFlashMemberType, false)
+	add(tc.Spec.TiCDC != nil, v1alpha1.TiCDCMemberType, true)
+	add(tc.Spec.TiProxy != nil, v1alpha1.TiProxyMemberType, false)
+	if tc.Spec.Pump != nil {
+		secrets = append(secrets, certSecret{name: util.ClusterTLSSecretName(tcName, v1alpha1.PumpMemberType.String())})
+	}
+	secrets = append(secrets, certSecret{
+		name:       util.ClusterClientTLSSecretName(tcName),
+		components: clientComponents,
+	})
+	return secrets
+}
+
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode the certificate as PEM")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+type FakeTidbClusterCertRotationManager struct {
+}
+
+func NewFakeTidbClusterCertRotationManager() *FakeTidbClusterCertRotationManager {
+	return &FakeTidbClusterCertRotationManager{}
+}
+
+func (f *FakeTidbClusterCertRotationManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
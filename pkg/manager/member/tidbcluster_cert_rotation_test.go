@@ -0,0 +1,182 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestCertificate(t *testing.T, cn string, notAfter time.Time) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertRotationSecrets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.Pump = &v1alpha1.PumpSpec{}
+	secrets := certRotationSecrets(tc)
+	g.Expect(secrets).To(Equal([]certSecret{
+		{name: "test-pd-cluster-secret", components: []v1alpha1.MemberType{v1alpha1.PDMemberType}},
+		{name: "test-tikv-cluster-secret", components: []v1alpha1.MemberType{v1alpha1.TiKVMemberType}},
+		{name: "test-tidb-cluster-secret", components: []v1alpha1.MemberType{v1alpha1.TiDBMemberType}},
+		{name: "test-pump-cluster-secret"},
+		{name: "test-cluster-client-secret", components: []v1alpha1.MemberType{v1alpha1.PDMemberType, v1alpha1.TiKVMemberType}},
+	}))
+}
+
+func TestTidbClusterCertRotationManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2021, 10, 10, 2, 30, 0, 0, time.UTC)
+	deps := controller.NewFakeDependencies()
+	m := NewTidbClusterCertRotationManager(deps)
+	m.now = func() time.Time { return now }
+	tc := newTidbClusterForPD()
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{
+		Enabled:      true,
+		CertRotation: &v1alpha1.CertRotationSpec{Issuer: v1alpha1.CertIssuerCSR, SignerName: "example.com/tidb"},
+	}
+	ns := tc.Namespace
+	kubeCli := deps.KubeClientset
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	addSecret := func(name string, notAfter time.Time) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       newTestCertificate(t, name, notAfter),
+				corev1.TLSPrivateKeyKey: []byte("key"),
+			},
+		}
+		g.Expect(secretIndexer.Add(secret)).To(Succeed())
+		_, err := kubeCli.CoreV1().Secrets(ns).Create(context.TODO(), secret, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	// the pd certificate is expiring
+	addSecret("test-pd-cluster-secret", now.Add(10*24*time.Hour))
+	addSecret("test-tikv-cluster-secret", now.Add(365*24*time.Hour))
+	addSecret("test-cluster-client-secret", now.Add(365*24*time.Hour))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pd-0",
+			Namespace: ns,
+			Labels:    label.New().Instance(tc.Name).PD().Labels(),
+		},
+	}
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+
+	// nothing is done if the rotation is not enabled
+	tc.Spec.TLSCluster.CertRotation = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.CertRotation).To(BeNil())
+
+	// a csr is created for the expiring certificate
+	tc.Spec.TLSCluster.CertRotation = &v1alpha1.CertRotationSpec{Issuer: v1alpha1.CertIssuerCSR, SignerName: "example.com/tidb"}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	statuses := tc.Status.CertRotation
+	g.Expect(statuses).To(HaveLen(4))
+	g.Expect(statuses[0].SecretName).To(Equal("test-pd-cluster-secret"))
+	g.Expect(statuses[0].NotAfter.Time).To(Equal(now.Add(10 * 24 * time.Hour)))
+	g.Expect(statuses[0].CSRName).To(Equal("default-test-pd-cluster-secret-1633833000"))
+	g.Expect(statuses[1].CSRName).To(BeEmpty())
+	g.Expect(statuses[2].Message).To(Equal("secret test-tidb-cluster-secret is not found"))
+	csr, err := kubeCli.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), statuses[0].CSRName, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(csr.Spec.SignerName).To(Equal("example.com/tidb"))
+	block, _ := pem.Decode(csr.Spec.Request)
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(request.Subject.CommonName).To(Equal("test-pd-cluster-secret"))
+	g.Expect(request.DNSNames).To(Equal([]string{"test-pd-cluster-secret"}))
+	keySecret, err := kubeCli.CoreV1().Secrets(ns).Get(context.TODO(), "test-pd-cluster-secret-rotation-key", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// nothing is done until the csr is issued
+	now = now.Add(time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.CertRotation[0].CSRName).To(Equal(csr.Name))
+
+	// the issued certificate is written into the secret
+	issued := newTestCertificate(t, "test-pd-cluster-secret", now.Add(365*24*time.Hour))
+	csr.Status.Certificate = issued
+	_, err = kubeCli.CertificatesV1().CertificateSigningRequests().UpdateStatus(context.TODO(), csr, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.CertRotation[0].CSRName).To(BeEmpty())
+	secret, err := kubeCli.CoreV1().Secrets(ns).Get(context.TODO(), "test-pd-cluster-secret", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(secret.Data[corev1.TLSCertKey]).To(Equal(issued))
+	g.Expect(secret.Data[corev1.TLSPrivateKeyKey]).To(Equal(keySecret.Data[corev1.TLSPrivateKeyKey]))
+	_, err = kubeCli.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), csr.Name, metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue(), "unexpected error: %v", err)
+	_, err = kubeCli.CoreV1().Secrets(ns).Get(context.TODO(), keySecret.Name, metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue(), "unexpected error: %v", err)
+
+	// the pods are annotated to reload the certificate once the rotation is observed
+	g.Expect(secretIndexer.Update(secret)).To(Succeed())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	status := tc.Status.CertRotation[0]
+	g.Expect(status.LastRotationTime.Time).To(Equal(now))
+	g.Expect(status.NotAfter.Time).To(Equal(now.Add(365 * 24 * time.Hour)))
+	g.Expect(status.CSRName).To(BeEmpty())
+	obj, _, err := podIndexer.GetByKey(ns + "/test-pd-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(obj.(*corev1.Pod).Annotations[label.AnnCertRotationTime]).To(Equal(now.Format(time.RFC3339)))
+
+	// a denied csr is reported and kept until deleted
+	tc.Status.CertRotation[1].CSRName = "denied"
+	_, err = kubeCli.CertificatesV1().CertificateSigningRequests().Create(context.TODO(), &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "denied"},
+		Status: certificatesv1.CertificateSigningRequestStatus{
+			Conditions: []certificatesv1.CertificateSigningRequestCondition{{Type: certificatesv1.CertificateDenied, Message: "not allowed"}},
+		},
+	}, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.CertRotation[1].CSRName).To(Equal("denied"))
+	g.Expect(tc.Status.CertRotation[1].Message).To(Equal("csr denied is Denied: not allowed, delete it to request again"))
+}
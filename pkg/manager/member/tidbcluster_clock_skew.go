@@ -0,0 +1,239 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// syncClockSkew measures the clock skew of the PD members and checks the conditions of the nodes of the cluster
+// at most once per check interval, records the skewed nodes in status and the ClockSkewDetected condition,
+// and cordons the badly skewed nodes if the policy is Cordon.
+func (m *TidbClusterStatusManager) syncClockSkew(tc *v1alpha1.TidbCluster) error {
+	if !tc.IsClockSkewCheckEnabled() {
+		tc.Status.ClockSkew = nil
+		utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterClockSkewDetected)
+		return nil
+	}
+	if tc.Status.ClockSkew != nil && time.Since(tc.Status.ClockSkew.LastCheckTime.Time) < tc.ClockSkewCheckInterval() {
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return fmt.Errorf("syncClockSkew: failed to create selector for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncClockSkew: failed to list pods for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	skews := m.measurePDClockSkews(tc)
+	skewedNodes := map[string]*v1alpha1.ClockSkewNode{}
+	getNode := func(name string) *v1alpha1.ClockSkewNode {
+		if skewedNodes[name] == nil {
+			skewedNodes[name] = &v1alpha1.ClockSkewNode{Node: name}
+		}
+		return skewedNodes[name]
+	}
+	checkedNodes := map[string]struct{}{}
+	for _, pod := range pods {
+		nodeName := pod.Spec.NodeName
+		if nodeName == "" {
+			continue
+		}
+		if skew, ok := skews[pod.Name]; ok && absDuration(skew) > tc.MaxClockSkew() {
+			node := getNode(nodeName)
+			if node.Skew == nil || absDuration(skew) > absDuration(node.Skew.Duration) {
+				node.Skew = &metav1.Duration{Duration: skew}
+			}
+		}
+		if _, checked := checkedNodes[nodeName]; checked {
+			continue
+		}
+		checkedNodes[nodeName] = struct{}{}
+		if conditions := m.clockSkewNodeConditions(tc, nodeName); len(conditions) > 0 {
+			getNode(nodeName).Conditions = conditions
+		}
+	}
+	for _, pod := range pods {
+		if node, ok := skewedNodes[pod.Spec.NodeName]; ok {
+			node.Instances = append(node.Instances, pod.Name)
+		}
+	}
+
+	nodes := make([]v1alpha1.ClockSkewNode, 0, len(skewedNodes))
+	for _, node := range skewedNodes {
+		if tc.ClockSkewPolicy() == v1alpha1.ClockSkewPolicyCordon && node.Skew != nil && absDuration(node.Skew.Duration) >= tc.ClockSkewCordonThreshold() {
+			node.Cordoned = m.cordonClockSkewedNode(tc, node)
+		}
+		nodes = append(nodes, *node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Node < nodes[j].Node })
+
+	var oldNodeNames []string
+	if tc.Status.ClockSkew != nil {
+		for _, node := range tc.Status.ClockSkew.Nodes {
+			oldNodeNames = append(oldNodeNames, node.Node)
+		}
+	}
+	tc.Status.ClockSkew = &v1alpha1.ClockSkewStatus{
+		LastCheckTime: metav1.Now(),
+		Nodes:         nodes,
+	}
+
+	if len(nodes) == 0 {
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterClockSkewDetected, corev1.ConditionFalse,
+			utiltidbcluster.ClockInSync, "The clocks of all nodes are in sync")
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		return nil
+	}
+
+	msgs := make([]string, 0, len(nodes))
+	nodeNames := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		var details []string
+		if node.Skew != nil {
+			details = append(details, fmt.Sprintf("skew %s", node.Skew.Duration.Round(time.Second)))
+		}
+		for _, condition := range node.Conditions {
+			details = append(details, string(condition))
+		}
+		if node.Cordoned {
+			details = append(details, "cordoned")
+		}
+		msgs = append(msgs, fmt.Sprintf("%s (%s): %s", node.Node, strings.Join(details, ", "), strings.Join(node.Instances, ",")))
+		nodeNames = append(nodeNames, node.Node)
+	}
+	message := strings.Join(msgs, "; ")
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterClockSkewDetected, corev1.ConditionTrue,
+		utiltidbcluster.ClockSkewed, message)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	if !reflect.DeepEqual(nodeNames, oldNodeNames) {
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, utiltidbcluster.ClockSkewed, message)
+	}
+	return nil
+}
+
+// measurePDClockSkews returns the clock skew of the healthy PD members by the name of the Pods. The skew of a
+// member is the offset of its clock from the median clock of the operator and all the members, so that the
+// skew of the clock of the operator itself doesn't make all the members skewed.
+func (m *TidbClusterStatusManager) measurePDClockSkews(tc *v1alpha1.TidbCluster) map[string]time.Duration {
+	if tc.Spec.PD == nil {
+		return nil
+	}
+
+	offsets := map[string]time.Duration{}
+	for name, member := range tc.Status.PD.Members {
+		if !member.Health {
+			continue
+		}
+		client := m.deps.PDControl.GetPDClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled(),
+			pdapi.SpecifyClient(member.ClientURL, member.Name))
+		start := time.Now()
+		t, err := client.GetTime()
+		if err != nil {
+			klog.Warningf("failed to get the time of pd %s for tc %s/%s, error: %v", name, tc.GetNamespace(), tc.GetName(), err)
+			continue
+		}
+		// the time is truncated to seconds, so the middle of the second and the middle of the request are compared
+		local := start.Add(time.Since(start) / 2)
+		offsets[name] = t.Add(500 * time.Millisecond).Sub(local)
+	}
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	// the offset of the clock of the operator is 0
+	values := []time.Duration{0}
+	for _, offset := range offsets {
+		values = append(values, offset)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	median := values[len(values)/2]
+	// prefer the one closer to the clock of the operator if there is an even number of clocks
+	if len(values)%2 == 0 && absDuration(values[len(values)/2-1]) < absDuration(median) {
+		median = values[len(values)/2-1]
+	}
+
+	skews := make(map[string]time.Duration, len(offsets))
+	for name, offset := range offsets {
+		skews[name] = offset - median
+	}
+	return skews
+}
+
+// clockSkewNodeConditions returns the node conditions of the node reporting the clock is not synchronized, no
+// condition is returned if the nodes can't be read by the operator
+func (m *TidbClusterStatusManager) clockSkewNodeConditions(tc *v1alpha1.TidbCluster, nodeName string) []corev1.NodeConditionType {
+	if m.deps.NodeLister == nil {
+		return nil
+	}
+	node, err := m.deps.NodeLister.Get(nodeName)
+	if err != nil {
+		return nil
+	}
+	var conditions []corev1.NodeConditionType
+	for _, condType := range tc.ClockSkewNodeConditions() {
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == condType && cond.Status == corev1.ConditionTrue {
+				conditions = append(conditions, condType)
+			}
+		}
+	}
+	return conditions
+}
+
+// cordonClockSkewedNode marks the node as unschedulable, and returns whether the node is cordoned
+func (m *TidbClusterStatusManager) cordonClockSkewedNode(tc *v1alpha1.TidbCluster, node *v1alpha1.ClockSkewNode) bool {
+	if m.deps.NodeLister != nil {
+		if n, err := m.deps.NodeLister.Get(node.Node); err == nil && n.Spec.Unschedulable {
+			return true
+		}
+	}
+	patch := []byte(`{"spec":{"unschedulable":true}}`)
+	if _, err := m.deps.KubeClientset.CoreV1().Nodes().Patch(context.TODO(), node.Node, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "ClockSkewCordonFailed",
+			"failed to cordon node %s with clock skew %s: %v", node.Node, node.Skew.Duration.Round(time.Second), err)
+		return false
+	}
+	klog.Infof("node %s is cordoned for tc %s/%s as its clock skew is %s", node.Node, tc.GetNamespace(), tc.GetName(), node.Skew.Duration)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "ClockSkewNodeCordoned",
+		"cordoned node %s with clock skew %s", node.Node, node.Skew.Duration.Round(time.Second))
+	return true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
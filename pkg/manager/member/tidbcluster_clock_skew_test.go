@@ -0,0 +1,141 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncClockSkew(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tsm := NewTidbClusterStatusManager(fakeDeps)
+	tc := newTidbClusterForPD()
+	tc.Spec.ClockSkew = &v1alpha1.ClockSkewSpec{Policy: v1alpha1.ClockSkewPolicyCordon}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{}
+
+	pdControl := fakeDeps.PDControl.(*pdapi.FakePDControl)
+	offsets := map[string]time.Duration{"test-pd-0": 0, "test-pd-1": 0, "test-pd-2": time.Minute}
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("test-pd-%d", i)
+		tc.Status.PD.Members[name] = v1alpha1.PDMember{Name: name, ClientURL: "http://" + name, Health: true}
+		client := pdapi.NewFakePDClient()
+		client.AddReaction(pdapi.GetTimeActionType, func(action *pdapi.Action) (interface{}, error) {
+			return time.Now().Add(offsets[name]).Truncate(time.Second), nil
+		})
+		pdControl.SetPDClientWithAddress(name, client)
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}}
+		if i == 1 {
+			node.Status.Conditions = []corev1.NodeCondition{{Type: "NTPProblem", Status: corev1.ConditionTrue}}
+		}
+		g.Expect(fakeDeps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(node)).To(Succeed())
+		_, err := fakeDeps.KubeClientset.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+
+		for _, component := range []string{"pd", "tikv"} {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("test-%s-%d", component, i),
+					Namespace: tc.Namespace,
+					Labels:    label.New().Instance(tc.GetInstanceName()).Component(component).Labels(),
+				},
+				Spec: corev1.PodSpec{NodeName: node.Name},
+			}
+			g.Expect(fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+		}
+	}
+
+	g.Expect(tsm.syncClockSkew(tc)).To(Succeed())
+	g.Expect(tc.Status.ClockSkew).NotTo(BeNil())
+	nodes := tc.Status.ClockSkew.Nodes
+	g.Expect(nodes).To(HaveLen(2))
+	g.Expect(nodes[0].Node).To(Equal("node-1"))
+	g.Expect(nodes[0].Skew).To(BeNil())
+	g.Expect(nodes[0].Conditions).To(Equal([]corev1.NodeConditionType{"NTPProblem"}))
+	g.Expect(nodes[0].Cordoned).To(BeFalse())
+	g.Expect(nodes[1].Node).To(Equal("node-2"))
+	g.Expect(nodes[1].Instances).To(Equal([]string{"test-pd-2", "test-tikv-2"}))
+	g.Expect(nodes[1].Skew.Duration).To(BeNumerically("~", time.Minute, time.Second))
+	g.Expect(nodes[1].Cordoned).To(BeTrue())
+	node, err := fakeDeps.KubeClientset.CoreV1().Nodes().Get(context.TODO(), "node-2", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(node.Spec.Unschedulable).To(BeTrue())
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterClockSkewDetected)
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(cond.Message).To(ContainSubstring("node-2 (skew 1m0s, cordoned): test-pd-2,test-tikv-2"))
+
+	// skip the check within the interval
+	lastCheckTime := metav1.NewTime(time.Now().Add(-time.Minute))
+	tc.Status.ClockSkew.LastCheckTime = lastCheckTime
+	g.Expect(tsm.syncClockSkew(tc)).To(Succeed())
+	g.Expect(tc.Status.ClockSkew.LastCheckTime).To(Equal(lastCheckTime))
+
+	// the skew is only reported if the policy is Report
+	tc.Spec.ClockSkew.Policy = v1alpha1.ClockSkewPolicyReport
+	tc.Status.ClockSkew = nil
+	offsets["test-pd-2"] = 0
+	g.Expect(tsm.syncClockSkew(tc)).To(Succeed())
+	g.Expect(tc.Status.ClockSkew.Nodes).To(HaveLen(1))
+	g.Expect(tc.Status.ClockSkew.Nodes[0].Node).To(Equal("node-1"))
+
+	// clean up the status if disabled
+	tc.Spec.ClockSkew = nil
+	g.Expect(tsm.syncClockSkew(tc)).To(Succeed())
+	g.Expect(tc.Status.ClockSkew).To(BeNil())
+	g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterClockSkewDetected)).To(BeNil())
+}
+
+func TestMeasurePDClockSkews(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tsm := NewTidbClusterStatusManager(fakeDeps)
+	tc := newTidbClusterForPD()
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{}
+
+	// the clocks of all the PD members are ahead of the operator
+	pdControl := fakeDeps.PDControl.(*pdapi.FakePDControl)
+	for i, offset := range []time.Duration{time.Minute, time.Minute, 2 * time.Minute} {
+		offset := offset
+		name := fmt.Sprintf("test-pd-%d", i)
+		tc.Status.PD.Members[name] = v1alpha1.PDMember{Name: name, Health: true}
+		client := pdapi.NewFakePDClient()
+		client.AddReaction(pdapi.GetTimeActionType, func(action *pdapi.Action) (interface{}, error) {
+			return time.Now().Add(offset).Truncate(time.Second), nil
+		})
+		pdControl.SetPDClientWithAddress(name, client)
+	}
+	tc.Status.PD.Members["test-pd-3"] = v1alpha1.PDMember{Name: "test-pd-3", Health: false}
+
+	skews := tsm.measurePDClockSkews(tc)
+	g.Expect(skews).To(HaveLen(3))
+	g.Expect(skews["test-pd-0"]).To(BeNumerically("~", 0, time.Second))
+	g.Expect(skews["test-pd-1"]).To(BeNumerically("~", 0, time.Second))
+	g.Expect(skews["test-pd-2"]).To(BeNumerically("~", time.Minute, time.Second))
+}
@@ -0,0 +1,311 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// configSizeRegexp matches the readable sizes of TiKV, e.g. `512MB` and `1GiB`, all units are binary
+	configSizeRegexp = regexp.MustCompile(`^(?i)(\d+(?:\.\d+)?)\s*(B|KB|KiB|MB|MiB|GB|GiB|TB|TiB|PB|PiB)$`)
+	// configDaysRegexp matches the readable durations of TiKV with a days part, e.g. `1d2h`
+	configDaysRegexp = regexp.MustCompile(`^(\d+)d(.*)$`)
+)
+
+// syncConfigDrift compares the live config of the TiKV and TiDB instances with the desired config
+// at most once per check interval, records the drifted items in status and the ConfigDrifted condition,
+// and reverts the drifted items of TiKV online if the policy is Revert.
+func (m *TidbClusterStatusManager) syncConfigDrift(tc *v1alpha1.TidbCluster) error {
+	if !tc.IsConfigDriftCheckEnabled() {
+		tc.Status.ConfigDrift = nil
+		utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterConfigDrifted)
+		return nil
+	}
+	if tc.Status.ConfigDrift != nil && time.Since(tc.Status.ConfigDrift.LastCheckTime.Time) < tc.ConfigDriftCheckInterval() {
+		return nil
+	}
+
+	var drifts []v1alpha1.ConfigDrift
+	drifts = append(drifts, m.checkTiKVConfigDrift(tc)...)
+	drifts = append(drifts, m.checkTiDBConfigDrift(tc)...)
+
+	var oldDrifts []v1alpha1.ConfigDrift
+	if tc.Status.ConfigDrift != nil {
+		oldDrifts = tc.Status.ConfigDrift.Drifts
+	}
+	tc.Status.ConfigDrift = &v1alpha1.ConfigDriftStatus{
+		LastCheckTime: metav1.Now(),
+		Drifts:        drifts,
+	}
+
+	if len(drifts) == 0 {
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterConfigDrifted, corev1.ConditionFalse,
+			utiltidbcluster.ConfigInSync, "The live config of all instances matches the desired config")
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		return nil
+	}
+
+	msgs := make([]string, 0, len(drifts))
+	for _, drift := range drifts {
+		msgs = append(msgs, fmt.Sprintf("%s %s: %s", drift.Component, drift.Instance, strings.Join(drift.Keys, ",")))
+	}
+	message := strings.Join(msgs, "; ")
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterConfigDrifted, corev1.ConditionTrue,
+		utiltidbcluster.ConfigDrifted, message)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	if !reflect.DeepEqual(drifts, oldDrifts) {
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, utiltidbcluster.ConfigDrifted, message)
+	}
+	return nil
+}
+
+// checkTiKVConfigDrift returns the drifted config items of the TiKV stores that are up, the drifted
+// items are reverted instead if the policy is Revert.
+func (m *TidbClusterStatusManager) checkTiKVConfigDrift(tc *v1alpha1.TidbCluster) []v1alpha1.ConfigDrift {
+	if tc.Spec.TiKV == nil || tc.Spec.TiKV.Config == nil || tc.Spec.TiKV.Config.GenericConfig == nil {
+		return nil
+	}
+	// the config is expected to change during rolling update
+	if tc.Status.TiKV.Phase == v1alpha1.UpgradePhase {
+		return nil
+	}
+	desired := flattenConfig(withConfigProfile(tc, v1alpha1.TiKVMemberType, tc.Spec.TiKV.Config.GenericConfig))
+
+	var drifts []v1alpha1.ConfigDrift
+	for _, store := range sortedTiKVStores(tc.Status.TiKV.Stores) {
+		if store.State != v1alpha1.TiKVStateUp {
+			continue
+		}
+		client := m.deps.TiKVControl.GetTiKVPodClient(tc.GetNamespace(), tc.GetName(), store.PodName, tc.IsTLSClusterEnabled())
+		live, err := client.GetConfig()
+		if err != nil {
+			klog.Warningf("failed to get the live config of tikv %s for tc %s/%s, error: %v", store.PodName, tc.GetNamespace(), tc.GetName(), err)
+			continue
+		}
+		keys := driftedConfigKeys(desired, flattenConfig(config.New(live)))
+		if len(keys) == 0 {
+			continue
+		}
+		if tc.ConfigDriftPolicy() == v1alpha1.ConfigDriftPolicyRevert {
+			items := make(map[string]interface{}, len(keys))
+			for _, key := range keys {
+				items[key] = desired[key]
+			}
+			if err := client.UpdateConfig(items); err != nil {
+				m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "ConfigDriftRevertFailed",
+					"failed to revert config %s of tikv %s: %v", strings.Join(keys, ","), store.PodName, err)
+			} else {
+				m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "ConfigDriftReverted",
+					"reverted config %s of tikv %s", strings.Join(keys, ","), store.PodName)
+				continue
+			}
+		}
+		drifts = append(drifts, v1alpha1.ConfigDrift{Component: v1alpha1.TiKVMemberType, Instance: store.PodName, Keys: keys})
+	}
+	return drifts
+}
+
+// checkTiDBConfigDrift returns the drifted config items of the healthy TiDB instances, TiDB doesn't
+// support changing config online, so the drift is only reported regardless of the policy.
+func (m *TidbClusterStatusManager) checkTiDBConfigDrift(tc *v1alpha1.TidbCluster) []v1alpha1.ConfigDrift {
+	if tc.Spec.TiDB == nil || tc.Spec.TiDB.Config == nil || tc.Spec.TiDB.Config.GenericConfig == nil {
+		return nil
+	}
+	if tc.Status.TiDB.Phase == v1alpha1.UpgradePhase {
+		return nil
+	}
+	desired := flattenConfig(withConfigProfile(tc, v1alpha1.TiDBMemberType, tc.Spec.TiDB.Config.GenericConfig))
+
+	names := make([]string, 0, len(tc.Status.TiDB.Members))
+	for name, member := range tc.Status.TiDB.Members {
+		if member.Health {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var drifts []v1alpha1.ConfigDrift
+	for _, name := range names {
+		ordinal, err := util.GetOrdinalFromPodName(name)
+		if err != nil {
+			klog.Warningf("failed to parse the ordinal of tidb %s for tc %s/%s, error: %v", name, tc.GetNamespace(), tc.GetName(), err)
+			continue
+		}
+		live, err := m.deps.TiDBControl.GetConfig(tc, ordinal)
+		if err != nil {
+			klog.Warningf("failed to get the live config of tidb %s for tc %s/%s, error: %v", name, tc.GetNamespace(), tc.GetName(), err)
+			continue
+		}
+		keys := driftedConfigKeys(desired, flattenConfig(config.New(live)))
+		if len(keys) == 0 {
+			continue
+		}
+		drifts = append(drifts, v1alpha1.ConfigDrift{Component: v1alpha1.TiDBMemberType, Instance: name, Keys: keys})
+	}
+	return drifts
+}
+
+func sortedTiKVStores(stores map[string]v1alpha1.TiKVStore) []v1alpha1.TiKVStore {
+	sorted := make([]v1alpha1.TiKVStore, 0, len(stores))
+	for _, store := range stores {
+		sorted = append(sorted, store)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].PodName < sorted[j].PodName
+	})
+	return sorted
+}
+
+// flattenConfig flattens the nested tables of the config into dotted keys, e.g. `storage.block-cache.capacity`
+func flattenConfig(c *config.GenericConfig) map[string]interface{} {
+	flat := map[string]interface{}{}
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			if sub, ok := v.(map[string]interface{}); ok {
+				walk(key, sub)
+				continue
+			}
+			flat[key] = v
+		}
+	}
+	walk("", c.Inner())
+	return flat
+}
+
+// driftedConfigKeys returns the sorted keys whose live values differ from the desired values, only
+// the items set in the desired config are compared as the live config contains all the defaults,
+// and the items unknown to the instance are ignored.
+func driftedConfigKeys(desired, live map[string]interface{}) []string {
+	var keys []string
+	for key, value := range desired {
+		liveValue, ok := live[key]
+		if !ok {
+			continue
+		}
+		if !reflect.DeepEqual(normalizeConfigValue(value), normalizeConfigValue(liveValue)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeConfigValue converts the value to a comparable form, numbers are converted to float64,
+// readable sizes are converted to the number of bytes and readable durations are converted to time.Duration,
+// so that e.g. `1GB` in the desired config equals `1GiB` reported by TiKV.
+func normalizeConfigValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case int:
+		return float64(val)
+	case int32:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case uint64:
+		return float64(val)
+	case float32:
+		return float64(val)
+	case json.Number:
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	case string:
+		if size, ok := parseConfigSize(val); ok {
+			return size
+		}
+		if d, ok := parseConfigDuration(val); ok {
+			return d
+		}
+		return val
+	case []interface{}:
+		normalized := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			normalized = append(normalized, normalizeConfigValue(item))
+		}
+		return normalized
+	case []string:
+		normalized := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			normalized = append(normalized, normalizeConfigValue(item))
+		}
+		return normalized
+	}
+	return v
+}
+
+func parseConfigSize(s string) (float64, bool) {
+	matches := configSizeRegexp.FindStringSubmatch(s)
+	if matches == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch strings.ToUpper(matches[2][:1]) {
+	case "K":
+		n *= 1 << 10
+	case "M":
+		n *= 1 << 20
+	case "G":
+		n *= 1 << 30
+	case "T":
+		n *= 1 << 40
+	case "P":
+		n *= 1 << 50
+	}
+	return n, true
+}
+
+func parseConfigDuration(s string) (time.Duration, bool) {
+	var days time.Duration
+	if matches := configDaysRegexp.FindStringSubmatch(s); matches != nil {
+		n, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return 0, false
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		s = matches[2]
+		if s == "" {
+			return days, true
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, false
+	}
+	return days + d, true
+}
@@ -0,0 +1,196 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/tikvapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDriftedConfigKeys(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	desired := flattenConfig(config.New(map[string]interface{}{
+		"log-level": "info",
+		"storage": map[string]interface{}{
+			"block-cache": map[string]interface{}{
+				"capacity": "1GB",
+			},
+			"reserve-space": "0MB",
+		},
+		"raftstore": map[string]interface{}{
+			"raft-base-tick-interval":     "1s",
+			"pd-heartbeat-tick":           int64(60),
+			"max-leader-missing-duration": "2h",
+		},
+		"server": map[string]interface{}{
+			"labels": []interface{}{"zone"},
+		},
+		"unknown": "value",
+	}))
+	live := flattenConfig(config.New(map[string]interface{}{
+		"log-level": "warn",
+		"storage": map[string]interface{}{
+			"block-cache": map[string]interface{}{
+				"capacity": "1GiB",
+			},
+			"reserve-space": "0KiB",
+		},
+		"raftstore": map[string]interface{}{
+			"raft-base-tick-interval":     "1s",
+			"pd-heartbeat-tick":           float64(30),
+			"max-leader-missing-duration": "120m",
+		},
+		"server": map[string]interface{}{
+			"labels": []interface{}{"zone", "rack"},
+		},
+	}))
+	g.Expect(driftedConfigKeys(desired, live)).Should(Equal([]string{"log-level", "raftstore.pd-heartbeat-tick", "server.labels"}))
+	g.Expect(driftedConfigKeys(desired, desired)).Should(BeEmpty())
+
+	g.Expect(normalizeConfigValue("1d")).Should(Equal(normalizeConfigValue("24h")))
+	g.Expect(normalizeConfigValue("1d12h")).Should(Equal(normalizeConfigValue("36h")))
+	g.Expect(normalizeConfigValue("512MB")).Should(Equal(normalizeConfigValue(int64(512 << 20))))
+}
+
+func TestSyncConfigDrift(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newTC := func(policy v1alpha1.ConfigDriftPolicy) *v1alpha1.TidbCluster {
+		tc := newTidbCluster()
+		tc.Spec.ConfigDrift = &v1alpha1.ConfigDriftSpec{Policy: policy}
+		tc.Spec.TiKV.Config = v1alpha1.NewTiKVConfig()
+		tc.Spec.TiKV.Config.Set("storage.block-cache.capacity", "1GB")
+		tc.Spec.TiDB.Config = v1alpha1.NewTiDBConfig()
+		tc.Spec.TiDB.Config.Set("tikv-client.copr-cache.capacity-mb", 1000)
+		tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+			"1": {ID: "1", PodName: "test-pd-tikv-0", State: v1alpha1.TiKVStateUp},
+			"2": {ID: "2", PodName: "test-pd-tikv-1", State: v1alpha1.TiKVStateUp},
+		}
+		tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+			"test-pd-tidb-0": {Name: "test-pd-tidb-0", Health: true},
+		}
+		return tc
+	}
+
+	testcases := []struct {
+		name           string
+		policy         v1alpha1.ConfigDriftPolicy
+		updateErr      bool
+		tidbCapacity   float64
+		expectedDrifts []v1alpha1.ConfigDrift
+		expectedUpdate bool
+	}{
+		{
+			name:         "report",
+			policy:       v1alpha1.ConfigDriftPolicyReport,
+			tidbCapacity: 500,
+			expectedDrifts: []v1alpha1.ConfigDrift{
+				{Component: v1alpha1.TiKVMemberType, Instance: "test-pd-tikv-1", Keys: []string{"storage.block-cache.capacity"}},
+				{Component: v1alpha1.TiDBMemberType, Instance: "test-pd-tidb-0", Keys: []string{"tikv-client.copr-cache.capacity-mb"}},
+			},
+		},
+		{
+			name:           "revert",
+			policy:         v1alpha1.ConfigDriftPolicyRevert,
+			tidbCapacity:   1000,
+			expectedUpdate: true,
+		},
+		{
+			name:         "revert failed",
+			policy:       v1alpha1.ConfigDriftPolicyRevert,
+			updateErr:    true,
+			tidbCapacity: 1000,
+			expectedDrifts: []v1alpha1.ConfigDrift{
+				{Component: v1alpha1.TiKVMemberType, Instance: "test-pd-tikv-1", Keys: []string{"storage.block-cache.capacity"}},
+			},
+			expectedUpdate: true,
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			fakeDeps := controller.NewFakeDependencies()
+			tsm := NewTidbClusterStatusManager(fakeDeps)
+			tc := newTC(testcase.policy)
+
+			tikvControl := fakeDeps.TiKVControl.(*tikvapi.FakeTiKVControl)
+			var updated map[string]interface{}
+			for podName, capacity := range map[string]string{"test-pd-tikv-0": "1GiB", "test-pd-tikv-1": "2GiB"} {
+				capacity := capacity
+				client := tikvapi.NewFakeTiKVClient()
+				client.AddReaction(tikvapi.GetConfigActionType, func(action *tikvapi.Action) (interface{}, error) {
+					return map[string]interface{}{
+						"storage": map[string]interface{}{
+							"block-cache": map[string]interface{}{"capacity": capacity},
+						},
+					}, nil
+				})
+				client.AddReaction(tikvapi.UpdateConfigActionType, func(action *tikvapi.Action) (interface{}, error) {
+					if testcase.updateErr {
+						return nil, fmt.Errorf("update failed")
+					}
+					updated = action.Config
+					return nil, nil
+				})
+				tikvControl.SetTiKVPodClient(tc.Namespace, tc.Name, podName, client)
+			}
+			fakeDeps.TiDBControl.(*controller.FakeTiDBControl).SetLiveConfig(map[string]interface{}{
+				"tikv-client": map[string]interface{}{
+					"copr-cache": map[string]interface{}{"capacity-mb": testcase.tidbCapacity},
+				},
+			})
+
+			err := tsm.syncConfigDrift(tc)
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(tc.Status.ConfigDrift).ShouldNot(BeNil())
+			g.Expect(tc.Status.ConfigDrift.Drifts).Should(Equal(testcase.expectedDrifts))
+			if testcase.expectedUpdate && !testcase.updateErr {
+				g.Expect(updated).Should(Equal(map[string]interface{}{"storage.block-cache.capacity": "1GB"}))
+			}
+			cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterConfigDrifted)
+			g.Expect(cond).ShouldNot(BeNil())
+			if len(testcase.expectedDrifts) > 0 {
+				g.Expect(cond.Status).Should(Equal(corev1.ConditionTrue))
+				g.Expect(cond.Reason).Should(Equal(utiltidbcluster.ConfigDrifted))
+			} else {
+				g.Expect(cond.Status).Should(Equal(corev1.ConditionFalse))
+			}
+
+			// skip the check within the interval
+			lastCheckTime := metav1.NewTime(time.Now().Add(-time.Minute))
+			tc.Status.ConfigDrift.LastCheckTime = lastCheckTime
+			tc.Status.ConfigDrift.Drifts = nil
+			err = tsm.syncConfigDrift(tc)
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(tc.Status.ConfigDrift.LastCheckTime).Should(Equal(lastCheckTime))
+
+			// clean up the status if disabled
+			tc.Spec.ConfigDrift = nil
+			err = tsm.syncConfigDrift(tc)
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(tc.Status.ConfigDrift).Should(BeNil())
+			g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterConfigDrifted)).Should(BeNil())
+		})
+	}
+}
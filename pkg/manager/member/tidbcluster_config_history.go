@@ -0,0 +1,281 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// configMapDigestSuffix matches the digest suffix added to the names of the versioned ConfigMaps
+var configMapDigestSuffix = regexp.MustCompile(`^-[0-9a-f]{7}(-new)?$`)
+
+// configHistoryComponent is a component whose versioned ConfigMaps are retained
+type configHistoryComponent struct {
+	memberName string
+	// dataKey is the key of the config file in the ConfigMap, empty if the config can't be rolled back
+	dataKey string
+	// setConfig sets the config of the component in the spec
+	setConfig func(tc *v1alpha1.TidbCluster, c *config.GenericConfig)
+}
+
+// ConfigHistoryManager retains the versioned ConfigMaps of the components and rolls back the configs to them
+type ConfigHistoryManager interface {
+	// Rollback reverts the config of the component in the spec to the one in the ConfigMap named by the annotation
+	// tidb.pingcap.com/config-rollback, it returns whether the spec or the annotations of the TidbCluster are changed
+	Rollback(tc *v1alpha1.TidbCluster) (bool, error)
+	// Sync deletes the old versioned ConfigMaps beyond spec.configRevisionHistoryLimit
+	Sync(tc *v1alpha1.TidbCluster) error
+}
+
+type configHistoryManager struct {
+	deps *controller.Dependencies
+}
+
+// NewConfigHistoryManager returns a ConfigHistoryManager
+func NewConfigHistoryManager(deps *controller.Dependencies) ConfigHistoryManager {
+	return &configHistoryManager{
+		deps: deps,
+	}
+}
+
+// configHistoryComponents returns the components by their component labels, TiFlash is not rolled back as its
+// rendered config files are full of the defaults set by the operator
+func configHistoryComponents(tcName string) map[string]configHistoryComponent {
+	return map[string]configHistoryComponent{
+		label.PDLabelVal: {
+			memberName: controller.PDMemberName(tcName),
+			dataKey:    "config-file",
+			setConfig: func(tc *v1alpha1.TidbCluster, c *config.GenericConfig) {
+				tc.Spec.PD.Config = &v1alpha1.PDConfigWraper{GenericConfig: c}
+			},
+		},
+		label.TiKVLabelVal: {
+			memberName: controller.TiKVMemberName(tcName),
+			dataKey:    "config-file",
+			setConfig: func(tc *v1alpha1.TidbCluster, c *config.GenericConfig) {
+				tc.Spec.TiKV.Config = &v1alpha1.TiKVConfigWraper{GenericConfig: c}
+			},
+		},
+		label.TiDBLabelVal: {
+			memberName: controller.TiDBMemberName(tcName),
+			dataKey:    "config-file",
+			setConfig: func(tc *v1alpha1.TidbCluster, c *config.GenericConfig) {
+				tc.Spec.TiDB.Config = &v1alpha1.TiDBConfigWraper{GenericConfig: c}
+			},
+		},
+		label.TiCDCLabelVal: {
+			memberName: controller.TiCDCMemberName(tcName),
+			dataKey:    "config-file",
+			setConfig: func(tc *v1alpha1.TidbCluster, c *config.GenericConfig) {
+				tc.Spec.TiCDC.Config = &v1alpha1.CDCConfigWraper{GenericConfig: c}
+			},
+		},
+		label.TiProxyLabelVal: {
+			memberName: controller.TiProxyMemberName(tcName),
+			dataKey:    "config-file",
+			setConfig: func(tc *v1alpha1.TidbCluster, c *config.GenericConfig) {
+				tc.Spec.TiProxy.Config = &v1alpha1.TiProxyConfigWraper{GenericConfig: c}
+			},
+		},
+		label.PumpLabelVal: {
+			memberName: controller.PumpMemberName(tcName),
+			dataKey:    "pump-config",
+			setConfig: func(tc *v1alpha1.TidbCluster, c *config.GenericConfig) {
+				tc.Spec.Pump.Config = c
+			},
+		},
+		label.TiFlashLabelVal: {
+			memberName: controller.TiFlashMemberName(tcName),
+		},
+	}
+}
+
+// Rollback reverts the config of the component to the config file in the ConfigMap, the config rendered by the
+// operator is set in the spec as is, so the config items set by the operator and the items from spec.*.configMapRef
+// are inlined too. The annotation is removed once the config is reverted or the ConfigMap is found invalid.
+func (m *configHistoryManager) Rollback(tc *v1alpha1.TidbCluster) (bool, error) {
+	cmName, ok := tc.Annotations[label.AnnConfigRollback]
+	if !ok {
+		return false, nil
+	}
+	ns := tc.GetNamespace()
+
+	fail := func(format string, args ...interface{}) (bool, error) {
+		msg := fmt.Sprintf("failed to roll back the config to ConfigMap %s: %s", cmName, fmt.Sprintf(format, args...))
+		klog.Warningf("tidb cluster %s/%s: %s", ns, tc.GetName(), msg)
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, "FailedConfigRollback", msg)
+		delete(tc.Annotations, label.AnnConfigRollback)
+		return true, nil
+	}
+
+	cm, err := m.deps.ConfigMapLister.ConfigMaps(ns).Get(cmName)
+	if errors.IsNotFound(err) {
+		return fail("the ConfigMap is not found")
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get ConfigMap %s/%s for tc %s/%s, error: %v", ns, cmName, ns, tc.GetName(), err)
+	}
+	if cm.Labels[label.ManagedByLabelKey] != label.TiDBOperator || cm.Labels[label.InstanceLabelKey] != tc.GetInstanceName() {
+		return fail("the ConfigMap is not managed by the operator for the cluster")
+	}
+	componentLabel := cm.Labels[label.ComponentLabelKey]
+	component, ok := configHistoryComponents(tc.GetName())[componentLabel]
+	if !ok || !isVersionedConfigMap(cmName, component.memberName) {
+		return fail("the ConfigMap is not a versioned config of the components")
+	}
+	if component.dataKey == "" {
+		return fail("the config of %s can't be rolled back", componentLabel)
+	}
+	if !componentDeployed(tc, componentLabel) {
+		return fail("%s is not deployed", componentLabel)
+	}
+	data, ok := cm.Data[component.dataKey]
+	if !ok {
+		return fail("the ConfigMap has no %s", component.dataKey)
+	}
+	c := config.New(map[string]interface{}{})
+	if err := c.UnmarshalTOML([]byte(data)); err != nil {
+		return fail("the config is invalid: %v", err)
+	}
+
+	component.setConfig(tc, c)
+	delete(tc.Annotations, label.AnnConfigRollback)
+	msg := fmt.Sprintf("the config of %s is rolled back to ConfigMap %s", componentLabel, cmName)
+	klog.Infof("tidb cluster %s/%s: %s", ns, tc.GetName(), msg)
+	m.deps.Recorder.Event(tc, corev1.EventTypeNormal, "ConfigRolledBack", msg)
+	return true, nil
+}
+
+// Sync deletes the old versioned ConfigMaps of each component beyond the history limit, from the oldest one.
+// The ConfigMaps mounted by the StatefulSets and the newest one, which may be not mounted yet, are retained.
+func (m *configHistoryManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.ConfigRevisionHistoryLimit == nil {
+		return nil
+	}
+	limit := int(*tc.Spec.ConfigRevisionHistoryLimit)
+	ns := tc.GetNamespace()
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return err
+	}
+	cms, err := m.deps.ConfigMapLister.ConfigMaps(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("failed to list ConfigMaps for tc %s/%s, error: %v", ns, tc.GetName(), err)
+	}
+
+	for componentLabel, component := range configHistoryComponents(tc.GetName()) {
+		var history []*corev1.ConfigMap
+		for _, cm := range cms {
+			if cm.Labels[label.ComponentLabelKey] == componentLabel && isVersionedConfigMap(cm.Name, component.memberName) {
+				history = append(history, cm)
+			}
+		}
+		if len(history) <= limit+1 {
+			continue
+		}
+		inUseName, err := m.inUseConfigMapName(ns, component.memberName)
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(history, func(i, j int) bool {
+			if history[i].CreationTimestamp.Equal(&history[j].CreationTimestamp) {
+				return history[i].Name > history[j].Name
+			}
+			return history[j].CreationTimestamp.Before(&history[i].CreationTimestamp)
+		})
+		retained := 0
+		for i, cm := range history {
+			if i == 0 || cm.Name == inUseName {
+				continue
+			}
+			if retained < limit {
+				retained++
+				continue
+			}
+			if err := m.deps.ConfigMapControl.DeleteConfigMap(tc, cm); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete ConfigMap %s/%s for tc %s/%s, error: %v", ns, cm.Name, ns, tc.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// inUseConfigMapName returns the name of the ConfigMap mounted by the StatefulSet of the component
+func (m *configHistoryManager) inUseConfigMapName(ns, memberName string) (string, error) {
+	set, err := m.deps.StatefulSetLister.StatefulSets(ns).Get(memberName)
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get StatefulSet %s/%s, error: %v", ns, memberName, err)
+	}
+	return mngerutils.FindConfigMapVolume(&set.Spec.Template.Spec, func(name string) bool {
+		return isVersionedConfigMap(name, memberName)
+	}), nil
+}
+
+// isVersionedConfigMap returns whether the ConfigMap is named by the member name with the digest suffix
+func isVersionedConfigMap(name, memberName string) bool {
+	return strings.HasPrefix(name, memberName) && configMapDigestSuffix.MatchString(strings.TrimPrefix(name, memberName))
+}
+
+// componentDeployed returns whether the component of the label is deployed in the TidbCluster
+func componentDeployed(tc *v1alpha1.TidbCluster, componentLabel string) bool {
+	switch componentLabel {
+	case label.PDLabelVal:
+		return tc.Spec.PD != nil
+	case label.TiKVLabelVal:
+		return tc.Spec.TiKV != nil
+	case label.TiDBLabelVal:
+		return tc.Spec.TiDB != nil
+	case label.TiCDCLabelVal:
+		return tc.Spec.TiCDC != nil
+	case label.TiProxyLabelVal:
+		return tc.Spec.TiProxy != nil
+	case label.PumpLabelVal:
+		return tc.Spec.Pump != nil
+	case label.TiFlashLabelVal:
+		return tc.Spec.TiFlash != nil
+	}
+	return false
+}
+
+type FakeConfigHistoryManager struct {
+}
+
+func NewFakeConfigHistoryManager() *FakeConfigHistoryManager {
+	return &FakeConfigHistoryManager{}
+}
+
+func (f *FakeConfigHistoryManager) Rollback(_ *v1alpha1.TidbCluster) (bool, error) {
+	return false, nil
+}
+
+func (f *FakeConfigHistoryManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+)
+
+func newConfigHistoryTestConfigMap(tc *v1alpha1.TidbCluster, name string, l label.Label, data map[string]string, age time.Duration) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         tc.Namespace,
+			Labels:            l.Instance(tc.Name).Labels(),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+		Data: data,
+	}
+}
+
+func TestConfigHistoryManagerRollback(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	m := NewConfigHistoryManager(deps)
+	indexer := deps.LabelFilterKubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: corev1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{Config: v1alpha1.NewPDConfig()},
+			TiDB: &v1alpha1.TiDBSpec{Config: v1alpha1.NewTiDBConfig()},
+		},
+	}
+	tc.Spec.TiDB.Config.Set("log.level", "debug")
+
+	// nothing to do without the annotation
+	changed, err := m.Rollback(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeFalse())
+
+	g.Expect(indexer.Add(newConfigHistoryTestConfigMap(tc, "test-tidb-1234567", label.New().TiDB(),
+		map[string]string{"config-file": "[log]\nlevel = \"info\"\n"}, time.Hour))).To(Succeed())
+	g.Expect(indexer.Add(newConfigHistoryTestConfigMap(tc, "test-tikv-1234567", label.New().TiKV(),
+		map[string]string{"config-file": ""}, time.Hour))).To(Succeed())
+
+	tc.Annotations = map[string]string{label.AnnConfigRollback: "test-tidb-1234567"}
+	changed, err = m.Rollback(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeTrue())
+	g.Expect(tc.Annotations).NotTo(HaveKey(label.AnnConfigRollback))
+	level, err := tc.Spec.TiDB.Config.Get("log.level").AsString()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(level).To(Equal("info"))
+
+	// the annotation is removed if the ConfigMap can't be rolled back to, and the spec is not changed
+	for _, name := range []string{"test-tidb-7654321", "test-tikv-1234567"} {
+		tc.Annotations = map[string]string{label.AnnConfigRollback: name}
+		changed, err = m.Rollback(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(changed).To(BeTrue())
+		g.Expect(tc.Annotations).NotTo(HaveKey(label.AnnConfigRollback))
+		g.Expect(tc.Spec.TiKV).To(BeNil())
+	}
+}
+
+func TestConfigHistoryManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	// the ConfigMaps managed by the operator are listed from the label filtered informer
+	deps.ConfigMapControl = controller.NewFakeConfigMapControl(deps.LabelFilterKubeInformerFactory.Core().V1().ConfigMaps())
+	m := NewConfigHistoryManager(deps)
+	indexer := deps.LabelFilterKubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: corev1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD: &v1alpha1.PDSpec{Config: v1alpha1.NewPDConfig()},
+		},
+	}
+	// from the newest to the oldest
+	names := []string{"test-pd-aaaaaaa", "test-pd-bbbbbbb", "test-pd-ccccccc", "test-pd-ddddddd", "test-pd-eeeeeee"}
+	for i, name := range names {
+		g.Expect(indexer.Add(newConfigHistoryTestConfigMap(tc, name, label.New().PD(), nil, time.Duration(i)*time.Hour))).To(Succeed())
+	}
+	// the ConfigMaps not versioned are never deleted
+	g.Expect(indexer.Add(newConfigHistoryTestConfigMap(tc, "test-pd", label.New().PD(), nil, 10*time.Hour))).To(Succeed())
+	// the oldest one is still in use
+	set := &apps.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "test-pd", Namespace: tc.Namespace}}
+	set.Spec.Template.Spec.Volumes = []corev1.Volume{{
+		Name: "config",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "test-pd-eeeeeee"},
+		}},
+	}}
+	g.Expect(deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(set)).To(Succeed())
+
+	listNames := func() []string {
+		cms, err := deps.ConfigMapLister.ConfigMaps(tc.Namespace).List(labels.Everything())
+		g.Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, cm := range cms {
+			names = append(names, cm.Name)
+		}
+		return names
+	}
+
+	// all the ConfigMaps are retained without the limit
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(listNames()).To(HaveLen(6))
+
+	tc.Spec.ConfigRevisionHistoryLimit = pointer.Int32Ptr(1)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(listNames()).To(ConsistOf("test-pd", "test-pd-aaaaaaa", "test-pd-bbbbbbb", "test-pd-eeeeeee"))
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// configProfilePresets are the config items preset by the profiles for each component, the sizes are in bytes
+var configProfilePresets = map[v1alpha1.ConfigProfile]map[v1alpha1.MemberType]map[string]interface{}{
+	v1alpha1.ConfigProfileOLTP: {
+		v1alpha1.TiKVMemberType: {
+			"server.grpc-concurrency":            int64(8),
+			"raftstore.store-pool-size":          int64(4),
+			"raftstore.apply-pool-size":          int64(4),
+			"storage.scheduler-worker-pool-size": int64(8),
+			"readpool.unified.max-thread-count":  int64(8),
+		},
+		v1alpha1.TiDBMemberType: {
+			"mem-quota-query":                  int64(1 << 30),
+			"prepared-plan-cache.enabled":      true,
+			"performance.txn-total-size-limit": int64(100 << 20),
+		},
+		v1alpha1.TiFlashMemberType: {
+			"profiles.default.max_memory_usage_for_all_queries": 0.5,
+		},
+	},
+	v1alpha1.ConfigProfileOLAP: {
+		v1alpha1.TiKVMemberType: {
+			"server.grpc-concurrency":           int64(4),
+			"raftstore.store-pool-size":         int64(2),
+			"raftstore.apply-pool-size":         int64(2),
+			"readpool.unified.max-thread-count": int64(16),
+		},
+		v1alpha1.TiDBMemberType: {
+			"mem-quota-query":                  int64(8 << 30),
+			"oom-use-tmp-storage":              true,
+			"performance.txn-total-size-limit": int64(10 << 30),
+		},
+		v1alpha1.TiFlashMemberType: {
+			"profiles.default.max_memory_usage_for_all_queries": 0.9,
+		},
+	},
+	v1alpha1.ConfigProfileHTAP: {
+		v1alpha1.TiKVMemberType: {
+			"server.grpc-concurrency":            int64(6),
+			"raftstore.store-pool-size":          int64(3),
+			"raftstore.apply-pool-size":          int64(3),
+			"storage.scheduler-worker-pool-size": int64(6),
+			"readpool.unified.max-thread-count":  int64(12),
+		},
+		v1alpha1.TiDBMemberType: {
+			"mem-quota-query":                  int64(4 << 30),
+			"oom-use-tmp-storage":              true,
+			"performance.txn-total-size-limit": int64(1 << 30),
+		},
+		v1alpha1.TiFlashMemberType: {
+			"profiles.default.max_memory_usage_for_all_queries": 0.7,
+		},
+	},
+}
+
+// applyConfigProfile sets the config items preset by the profile of the cluster that are not set in the config
+func applyConfigProfile(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, cfg *config.GenericConfig) {
+	for key, value := range configProfilePresets[tc.Spec.Profile][memberType] {
+		if cfg.MP == nil {
+			cfg.MP = map[string]interface{}{}
+		}
+		cfg.SetIfNil(key, value)
+	}
+}
+
+// withConfigProfile returns the config merged with the presets of the profile of the cluster, the config
+// is copied before merging, so the presets are never persisted into the spec of the cluster
+func withConfigProfile(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, cfg *config.GenericConfig) *config.GenericConfig {
+	if len(configProfilePresets[tc.Spec.Profile][memberType]) == 0 {
+		return cfg
+	}
+	merged := config.New(map[string]interface{}{})
+	if cfg != nil {
+		merged = cfg.DeepCopy()
+	}
+	applyConfigProfile(tc, memberType, merged)
+	return merged
+}
+
+// getEffectiveConfigMap renders the effective config of the components, which is the config in the spec
+// merged with the presets of the profile
+func getEffectiveConfigMap(tc *v1alpha1.TidbCluster, refPDClientPort int32) (*corev1.ConfigMap, error) {
+	data := map[string]string{}
+	marshal := func(key string, cfg *config.GenericConfig) error {
+		text, err := cfg.MarshalTOML()
+		if err != nil {
+			return fmt.Errorf("failed to marshal the effective config %s, error: %v", key, err)
+		}
+		data[key] = string(text)
+		return nil
+	}
+
+	if tc.Spec.PD != nil && tc.Spec.PD.Config != nil {
+		if err := marshal("pd.toml", tc.Spec.PD.Config.GenericConfig); err != nil {
+			return nil, err
+		}
+	}
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.Config != nil {
+		if err := marshal("tikv.toml", withConfigProfile(tc, v1alpha1.TiKVMemberType, tc.Spec.TiKV.Config.GenericConfig)); err != nil {
+			return nil, err
+		}
+	}
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.Config != nil {
+		if err := marshal("tidb.toml", withConfigProfile(tc, v1alpha1.TiDBMemberType, tc.Spec.TiDB.Config.GenericConfig)); err != nil {
+			return nil, err
+		}
+	}
+	if tc.Spec.TiFlash != nil {
+		tiflashConfig := getTiFlashConfig(tc, refPDClientPort)
+		if err := marshal("tiflash.toml", tiflashConfig.Common.GenericConfig); err != nil {
+			return nil, err
+		}
+		if err := marshal("tiflash-proxy.toml", tiflashConfig.Proxy.GenericConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            controller.EffectiveConfigMapName(tc.Name),
+			Namespace:       tc.Namespace,
+			Labels:          label.New().Instance(tc.GetInstanceName()).Labels(),
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Data: data,
+	}, nil
+}
+
+// syncEffectiveConfigMap exports the effective config of the components to a ConfigMap if a profile
+// is selected, the ConfigMap is deleted after the profile is unset.
+func (m *TidbClusterStatusManager) syncEffectiveConfigMap(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	name := controller.EffectiveConfigMapName(tc.GetName())
+
+	if tc.Spec.Profile == "" {
+		cm, err := m.deps.ConfigMapLister.ConfigMaps(ns).Get(name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if err := m.deps.TypedControl.Delete(tc, cm); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete effective config ConfigMap %s/%s, error: %v", ns, name, err)
+		}
+		klog.Infof("effective config ConfigMap %s/%s is deleted", ns, name)
+		return nil
+	}
+
+	cm, err := getEffectiveConfigMap(tc, controller.RefPDClientPort(m.deps.TiDBClusterLister, tc))
+	if err != nil {
+		return err
+	}
+	if _, err := m.deps.TypedControl.CreateOrUpdateConfigMap(tc, cm); err != nil {
+		return fmt.Errorf("failed to sync effective config ConfigMap %s/%s, error: %v", ns, name, err)
+	}
+	return nil
+}
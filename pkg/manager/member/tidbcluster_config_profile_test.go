@@ -0,0 +1,112 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+)
+
+func TestWithConfigProfile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV.Config = v1alpha1.NewTiKVConfig()
+	tc.Spec.TiKV.Config.Set("server.grpc-concurrency", int64(2))
+
+	// the config is not changed without a profile
+	cfg := withConfigProfile(tc, v1alpha1.TiKVMemberType, tc.Spec.TiKV.Config.GenericConfig)
+	g.Expect(cfg).To(BeIdenticalTo(tc.Spec.TiKV.Config.GenericConfig))
+
+	tc.Spec.Profile = v1alpha1.ConfigProfileOLAP
+	cfg = withConfigProfile(tc, v1alpha1.TiKVMemberType, tc.Spec.TiKV.Config.GenericConfig)
+	// the items set by users take precedence over the presets
+	g.Expect(cfg.Get("server.grpc-concurrency").MustInt()).To(Equal(int64(2)))
+	g.Expect(cfg.Get("readpool.unified.max-thread-count").MustInt()).To(Equal(int64(16)))
+	// the presets are not set into the spec
+	g.Expect(tc.Spec.TiKV.Config.Get("readpool.unified.max-thread-count")).To(BeNil())
+
+	cfg = withConfigProfile(tc, v1alpha1.TiDBMemberType, nil)
+	g.Expect(cfg.Get("oom-use-tmp-storage").Interface()).To(Equal(true))
+	g.Expect(cfg.Get("performance.txn-total-size-limit").MustInt()).To(Equal(int64(10 << 30)))
+
+	// PD has no presets
+	cfg = withConfigProfile(tc, v1alpha1.PDMemberType, nil)
+	g.Expect(cfg).To(BeNil())
+}
+
+func TestConfigProfileInConfigMaps(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.Profile = v1alpha1.ConfigProfileOLTP
+	tc.Spec.TiKV.Config = v1alpha1.NewTiKVConfig()
+	tc.Spec.TiDB.Config = v1alpha1.NewTiDBConfig()
+	tc.Spec.TiDB.Config.Set("mem-quota-query", int64(2<<30))
+
+	cm, err := getTikVConfigMap(tc, v1alpha1.DefaultPDClientPort)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring("store-pool-size = 4"))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring("grpc-concurrency = 8"))
+
+	cm, err = getTiDBConfigMap(tc, v1alpha1.DefaultPDClientPort)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring("mem-quota-query = 2147483648"))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring("txn-total-size-limit = 104857600"))
+
+	g.Expect(tc.Spec.TiKV.Config.Get("server.grpc-concurrency")).To(BeNil())
+	g.Expect(tc.Spec.TiDB.Config.Get("performance.txn-total-size-limit")).To(BeNil())
+}
+
+func TestGetEffectiveConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.Profile = v1alpha1.ConfigProfileHTAP
+	tc.Spec.PD.Config = v1alpha1.NewPDConfig()
+	tc.Spec.PD.Config.Set("log.level", "info")
+	tc.Spec.TiKV.Config = v1alpha1.NewTiKVConfig()
+	tc.Spec.TiFlash = &v1alpha1.TiFlashSpec{
+		StorageClaims: []v1alpha1.StorageClaim{{}},
+	}
+
+	cm, err := getEffectiveConfigMap(tc, v1alpha1.DefaultPDClientPort)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Name).To(Equal(controller.EffectiveConfigMapName(tc.Name)))
+	g.Expect(cm.Data).To(HaveKey("pd.toml"))
+	g.Expect(cm.Data["pd.toml"]).To(ContainSubstring(`level = "info"`))
+	g.Expect(cm.Data["tikv.toml"]).To(ContainSubstring("max-thread-count = 12"))
+	// TiDB without config is not exported
+	g.Expect(cm.Data).NotTo(HaveKey("tidb.toml"))
+	g.Expect(cm.Data["tiflash.toml"]).To(ContainSubstring("max_memory_usage_for_all_queries = 0.7"))
+	g.Expect(cm.Data).To(HaveKey("tiflash-proxy.toml"))
+}
+
+func TestSyncEffectiveConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tsm := NewTidbClusterStatusManager(fakeDeps)
+	tc := newTidbClusterForPD()
+
+	// nothing to clean up without a profile
+	g.Expect(tsm.syncEffectiveConfigMap(tc)).To(Succeed())
+
+	tc.Spec.Profile = v1alpha1.ConfigProfileOLTP
+	tc.Spec.TiKV.Config = v1alpha1.NewTiKVConfig()
+	g.Expect(tsm.syncEffectiveConfigMap(tc)).To(Succeed())
+}
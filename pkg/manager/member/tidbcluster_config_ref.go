@@ -0,0 +1,185 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+)
+
+// loadConfigMapRef loads the config referenced by the ConfigMapRef of a component, nil is returned if the
+// ref is nil, or the referenced ConfigMap or key doesn't exist and the ref is optional. The TOML is validated
+// here, so an invalid config fails the sync before the ConfigMap of the component is changed.
+func loadConfigMapRef(lister corelisterv1.ConfigMapLister, tc *v1alpha1.TidbCluster, ref *corev1.ConfigMapKeySelector) (*config.GenericConfig, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	optional := ref.Optional != nil && *ref.Optional
+
+	cm, err := lister.ConfigMaps(tc.Namespace).Get(ref.Name)
+	if err != nil {
+		if errors.IsNotFound(err) && optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get the config ConfigMap %s/%s of tidbcluster %s/%s, error: %v",
+			tc.Namespace, ref.Name, tc.Namespace, tc.Name, err)
+	}
+	data, ok := cm.Data[ref.Key]
+	if !ok {
+		if optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("key %s not found in the config ConfigMap %s/%s of tidbcluster %s/%s",
+			ref.Key, tc.Namespace, ref.Name, tc.Namespace, tc.Name)
+	}
+
+	cfg := config.New(map[string]interface{}{})
+	if err := cfg.UnmarshalTOML([]byte(data)); err != nil {
+		return nil, fmt.Errorf("invalid TOML of key %s in the config ConfigMap %s/%s of tidbcluster %s/%s, error: %v",
+			ref.Key, tc.Namespace, ref.Name, tc.Namespace, tc.Name, err)
+	}
+	return cfg, nil
+}
+
+// mergeConfigMapRef returns the config loaded from the ConfigMapRef overlaid with the inline config,
+// the items of the inline config take precedence. Neither of the configs is modified.
+func mergeConfigMapRef(ref, inline *config.GenericConfig) *config.GenericConfig {
+	merged := config.New(map[string]interface{}{})
+	if ref != nil && ref.MP != nil {
+		merged = ref.DeepCopy()
+	}
+	if inline != nil {
+		mergeConfig(merged.MP, inline.DeepCopy().MP)
+	}
+	return merged
+}
+
+// withPDConfigMapRef returns a copy of the cluster whose PD config is merged with the config
+// referenced by the ConfigMapRef, the cluster is returned as is if there is no ConfigMapRef.
+func withPDConfigMapRef(lister corelisterv1.ConfigMapLister, tc *v1alpha1.TidbCluster) (*v1alpha1.TidbCluster, error) {
+	if tc.Spec.PD.ConfigMapRef == nil {
+		return tc, nil
+	}
+	refConfig, err := loadConfigMapRef(lister, tc, tc.Spec.PD.ConfigMapRef)
+	if err != nil {
+		return nil, err
+	}
+	var inline *config.GenericConfig
+	if tc.Spec.PD.Config != nil {
+		inline = tc.Spec.PD.Config.GenericConfig
+	}
+	tc = tc.DeepCopy()
+	tc.Spec.PD.Config = &v1alpha1.PDConfigWraper{GenericConfig: mergeConfigMapRef(refConfig, inline)}
+	return tc, nil
+}
+
+// withTiKVConfigMapRef returns a copy of the cluster whose TiKV config is merged with the config
+// referenced by the ConfigMapRef, the cluster is returned as is if there is no ConfigMapRef.
+func withTiKVConfigMapRef(lister corelisterv1.ConfigMapLister, tc *v1alpha1.TidbCluster) (*v1alpha1.TidbCluster, error) {
+	if tc.Spec.TiKV.ConfigMapRef == nil {
+		return tc, nil
+	}
+	refConfig, err := loadConfigMapRef(lister, tc, tc.Spec.TiKV.ConfigMapRef)
+	if err != nil {
+		return nil, err
+	}
+	var inline *config.GenericConfig
+	if tc.Spec.TiKV.Config != nil {
+		inline = tc.Spec.TiKV.Config.GenericConfig
+	}
+	tc = tc.DeepCopy()
+	tc.Spec.TiKV.Config = &v1alpha1.TiKVConfigWraper{GenericConfig: mergeConfigMapRef(refConfig, inline)}
+	return tc, nil
+}
+
+// withTiDBConfigMapRef returns a copy of the cluster whose TiDB config is merged with the config
+// referenced by the ConfigMapRef, the cluster is returned as is if there is no ConfigMapRef.
+func withTiDBConfigMapRef(lister corelisterv1.ConfigMapLister, tc *v1alpha1.TidbCluster) (*v1alpha1.TidbCluster, error) {
+	if tc.Spec.TiDB.ConfigMapRef == nil {
+		return tc, nil
+	}
+	refConfig, err := loadConfigMapRef(lister, tc, tc.Spec.TiDB.ConfigMapRef)
+	if err != nil {
+		return nil, err
+	}
+	var inline *config.GenericConfig
+	if tc.Spec.TiDB.Config != nil {
+		inline = tc.Spec.TiDB.Config.GenericConfig
+	}
+	tc = tc.DeepCopy()
+	tc.Spec.TiDB.Config = &v1alpha1.TiDBConfigWraper{GenericConfig: mergeConfigMapRef(refConfig, inline)}
+	return tc, nil
+}
+
+// withTiFlashConfigMapRef returns a copy of the cluster whose TiFlash common config is merged with the config
+// referenced by the ConfigMapRef, the cluster is returned as is if there is no ConfigMapRef.
+func withTiFlashConfigMapRef(lister corelisterv1.ConfigMapLister, tc *v1alpha1.TidbCluster) (*v1alpha1.TidbCluster, error) {
+	if tc.Spec.TiFlash.ConfigMapRef == nil {
+		return tc, nil
+	}
+	refConfig, err := loadConfigMapRef(lister, tc, tc.Spec.TiFlash.ConfigMapRef)
+	if err != nil {
+		return nil, err
+	}
+	var inline *config.GenericConfig
+	if tc.Spec.TiFlash.Config != nil && tc.Spec.TiFlash.Config.Common != nil {
+		inline = tc.Spec.TiFlash.Config.Common.GenericConfig
+	}
+	tc = tc.DeepCopy()
+	if tc.Spec.TiFlash.Config == nil {
+		tc.Spec.TiFlash.Config = v1alpha1.NewTiFlashConfig()
+	}
+	tc.Spec.TiFlash.Config.Common = &v1alpha1.TiFlashCommonConfigWraper{GenericConfig: mergeConfigMapRef(refConfig, inline)}
+	return tc, nil
+}
+
+// withTiCDCConfigMapRef returns a copy of the cluster whose TiCDC config is merged with the config
+// referenced by the ConfigMapRef, the cluster is returned as is if there is no ConfigMapRef.
+func withTiCDCConfigMapRef(lister corelisterv1.ConfigMapLister, tc *v1alpha1.TidbCluster) (*v1alpha1.TidbCluster, error) {
+	if tc.Spec.TiCDC.ConfigMapRef == nil {
+		return tc, nil
+	}
+	refConfig, err := loadConfigMapRef(lister, tc, tc.Spec.TiCDC.ConfigMapRef)
+	if err != nil {
+		return nil, err
+	}
+	var inline *config.GenericConfig
+	if tc.Spec.TiCDC.Config != nil {
+		inline = tc.Spec.TiCDC.Config.GenericConfig
+	}
+	tc = tc.DeepCopy()
+	tc.Spec.TiCDC.Config = &v1alpha1.CDCConfigWraper{GenericConfig: mergeConfigMapRef(refConfig, inline)}
+	return tc, nil
+}
+
+// withPumpConfigMapRef returns a copy of the cluster whose Pump config is merged with the config
+// referenced by the ConfigMapRef, the cluster is returned as is if there is no ConfigMapRef.
+func withPumpConfigMapRef(lister corelisterv1.ConfigMapLister, tc *v1alpha1.TidbCluster) (*v1alpha1.TidbCluster, error) {
+	if tc.Spec.Pump.ConfigMapRef == nil {
+		return tc, nil
+	}
+	refConfig, err := loadConfigMapRef(lister, tc, tc.Spec.Pump.ConfigMapRef)
+	if err != nil {
+		return nil, err
+	}
+	inline := tc.Spec.Pump.Config
+	tc = tc.DeepCopy()
+	tc.Spec.Pump.Config = mergeConfigMapRef(refConfig, inline)
+	return tc, nil
+}
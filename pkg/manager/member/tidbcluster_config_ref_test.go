@@ -0,0 +1,212 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func newConfigMapForConfigRef(tc *v1alpha1.TidbCluster, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "user-config",
+			Namespace: tc.Namespace,
+		},
+		Data: data,
+	}
+}
+
+func TestWithConfigMapRef(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	indexer := fakeDeps.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	tc := newTidbClusterForPD()
+
+	// the cluster is returned as is without a ref
+	cfgTC, err := withTiKVConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfgTC).To(BeIdenticalTo(tc))
+
+	cm := newConfigMapForConfigRef(tc, map[string]string{
+		"tikv.toml": "[server]\ngrpc-concurrency = 4\n[storage]\nreserve-space = \"1GB\"\n",
+		"broken":    "[server\n",
+	})
+	g.Expect(indexer.Add(cm)).To(Succeed())
+
+	tc.Spec.TiKV.ConfigMapRef = &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+		Key:                  "tikv.toml",
+	}
+	tc.Spec.TiKV.Config = v1alpha1.NewTiKVConfig()
+	tc.Spec.TiKV.Config.Set("server.grpc-concurrency", int64(8))
+	cfgTC, err = withTiKVConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	// the items of the inline config take precedence
+	g.Expect(cfgTC.Spec.TiKV.Config.Get("server.grpc-concurrency").MustInt()).To(Equal(int64(8)))
+	g.Expect(cfgTC.Spec.TiKV.Config.Get("storage.reserve-space").MustString()).To(Equal("1GB"))
+	// the spec of the cluster is not changed
+	g.Expect(tc.Spec.TiKV.Config.Get("storage.reserve-space")).To(BeNil())
+
+	// the invalid TOML is rejected
+	tc.Spec.TiKV.ConfigMapRef.Key = "broken"
+	_, err = withTiKVConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("invalid TOML"))
+
+	// the missing key is an error unless the ref is optional
+	tc.Spec.TiKV.ConfigMapRef.Key = "missing"
+	_, err = withTiKVConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).To(HaveOccurred())
+	tc.Spec.TiKV.ConfigMapRef.Optional = pointer.BoolPtr(true)
+	cfgTC, err = withTiKVConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfgTC.Spec.TiKV.Config.Get("server.grpc-concurrency").MustInt()).To(Equal(int64(8)))
+
+	// the missing ConfigMap is an error unless the ref is optional
+	tc.Spec.TiKV.ConfigMapRef.Name = "missing"
+	_, err = withTiKVConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	tc.Spec.TiKV.ConfigMapRef.Optional = nil
+	_, err = withTiKVConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestSyncPDConfigMapWithConfigMapRef(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pmm, _, _ := newFakePDMemberManager()
+	indexer := pmm.deps.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	tc := newTidbClusterForPD()
+	tc.Spec.PD.Config = nil
+
+	cm := newConfigMapForConfigRef(tc, map[string]string{
+		"pd.toml": "[schedule]\nmax-store-down-time = \"1h\"\n",
+	})
+	g.Expect(indexer.Add(cm)).To(Succeed())
+	tc.Spec.PD.ConfigMapRef = &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+		Key:                  "pd.toml",
+	}
+
+	newCm, err := pmm.syncPDConfigMap(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(newCm).NotTo(BeNil())
+	g.Expect(strings.HasPrefix(newCm.Name, controller.PDMemberName(tc.Name))).To(BeTrue())
+	g.Expect(newCm.Data["config-file"]).To(ContainSubstring(`max-store-down-time = "1h"`))
+	// the loaded config is not set into the spec
+	g.Expect(tc.Spec.PD.Config).To(BeNil())
+
+	// the ConfigMap of PD is not synced if the referenced config is invalid
+	cm = cm.DeepCopy()
+	cm.Data["pd.toml"] = "[schedule\n"
+	g.Expect(indexer.Update(cm)).To(Succeed())
+	_, err = pmm.syncPDConfigMap(tc, nil)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestWithTiFlashTiCDCPumpConfigMapRef(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	indexer := fakeDeps.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "tc"},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiFlash: &v1alpha1.TiFlashSpec{},
+			TiCDC:   &v1alpha1.TiCDCSpec{},
+			Pump:    &v1alpha1.PumpSpec{},
+		},
+	}
+	cm := newConfigMapForConfigRef(tc, map[string]string{
+		"config.toml": "gc-ttl = 3600\n[log]\nlevel = \"info\"\n",
+	})
+	g.Expect(indexer.Add(cm)).To(Succeed())
+	ref := &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+		Key:                  "config.toml",
+	}
+
+	// the cluster is returned as is without ConfigMapRef
+	cfgTC, err := withTiFlashConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfgTC).To(BeIdenticalTo(tc))
+
+	tc.Spec.TiFlash.ConfigMapRef = ref
+	tc.Spec.TiCDC.ConfigMapRef = ref
+	tc.Spec.Pump.ConfigMapRef = ref
+	tc.Spec.TiCDC.Config = v1alpha1.NewCDCConfig()
+	tc.Spec.TiCDC.Config.Set("gc-ttl", 86400)
+
+	// the common config of TiFlash is merged
+	cfgTC, err = withTiFlashConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfgTC.Spec.TiFlash.Config.Common.Get("log.level").MustString()).To(Equal("info"))
+	g.Expect(tc.Spec.TiFlash.Config).To(BeNil())
+
+	// the inline config of TiCDC takes precedence
+	cfgTC, err = withTiCDCConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfgTC.Spec.TiCDC.Config.Get("gc-ttl").MustInt()).To(Equal(int64(86400)))
+	g.Expect(cfgTC.Spec.TiCDC.Config.Get("log.level").MustString()).To(Equal("info"))
+	g.Expect(tc.Spec.TiCDC.Config.Get("log.level")).To(BeNil())
+
+	cfgTC, err = withPumpConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfgTC.Spec.Pump.Config.Get("gc-ttl").MustInt()).To(Equal(int64(3600)))
+	g.Expect(tc.Spec.Pump.Config).To(BeNil())
+
+	// the invalid TOML is rejected
+	cm = cm.DeepCopy()
+	cm.Data["config.toml"] = "[log\n"
+	g.Expect(indexer.Update(cm)).To(Succeed())
+	_, err = withPumpConfigMapRef(fakeDeps.UserConfigMapLister, tc)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestSyncTiCDCConfigMapWithConfigMapRef(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tmm, _, _, _ := newFakeTiCDCMemberManager()
+	indexer := tmm.deps.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	tc := newTidbClusterForCDC()
+	tc.Spec.TiCDC.Config = nil
+
+	// the ConfigMap of TiCDC is not synced without config
+	newCm, err := tmm.syncTiCDCConfigMap(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(newCm).To(BeNil())
+
+	cm := newConfigMapForConfigRef(tc, map[string]string{
+		"ticdc.toml": "per-table-memory-quota = 10485760\n",
+	})
+	g.Expect(indexer.Add(cm)).To(Succeed())
+	tc.Spec.TiCDC.ConfigMapRef = &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+		Key:                  "ticdc.toml",
+	}
+	newCm, err = tmm.syncTiCDCConfigMap(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(newCm).NotTo(BeNil())
+	g.Expect(newCm.Data["config-file"]).To(ContainSubstring("per-table-memory-quota = 10485760"))
+	g.Expect(tc.Spec.TiCDC.Config).To(BeNil())
+}
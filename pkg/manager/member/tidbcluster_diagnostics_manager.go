@@ -0,0 +1,570 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	diagnosticsContainerName = "upload"
+	diagnosticsBundleKey     = "bundle.tar.gz"
+	diagnosticsBundleVolName = "bundle"
+	diagnosticsBundlePath    = "/diagnostics"
+
+	defaultDiagnosticsUpgradeStuckTimeout = 30 * time.Minute
+	defaultDiagnosticsMinInterval         = time.Hour
+	defaultDiagnosticsLogTailLines        = 500
+	// diagnosticsLogLimitBytes limits the size of the logs of a container
+	diagnosticsLogLimitBytes = 1024 * 1024
+	// maxDiagnosticsBundleSize keeps the bundle within the size limit of a Secret
+	maxDiagnosticsBundleSize = 1000 * 1024
+)
+
+// TidbClusterDiagnosticsManager collects the diagnostics bundles of the cluster and uploads them to the storage
+type TidbClusterDiagnosticsManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+
+	mu sync.Mutex
+	// collections are the bundles being collected in the background, keyed by the namespace and the bundle name
+	collections map[string]*diagnosticsCollection
+}
+
+// diagnosticsCollection is a bundle collected in the background, err is set once done is closed
+type diagnosticsCollection struct {
+	done chan struct{}
+	err  error
+}
+
+// NewTidbClusterDiagnosticsManager returns a TidbClusterDiagnosticsManager
+func NewTidbClusterDiagnosticsManager(deps *controller.Dependencies) *TidbClusterDiagnosticsManager {
+	return &TidbClusterDiagnosticsManager{
+		deps:        deps,
+		now:         time.Now,
+		collections: map[string]*diagnosticsCollection{},
+	}
+}
+
+// Sync collects a bundle when the annotation requests a new one or the triggers fire for a new reason, the
+// bundle is collected in the background, saved in a Secret and uploaded by a Job. At most one bundle is
+// collected or uploaded at the same time, and the bundles collected by the triggers are at least minInterval apart.
+func (m *TidbClusterDiagnosticsManager) Sync(tc *v1alpha1.TidbCluster) error {
+	spec := tc.Spec.Diagnostics
+	if spec == nil {
+		tc.Status.Diagnostics = nil
+		return nil
+	}
+	if tc.Status.Diagnostics == nil {
+		tc.Status.Diagnostics = &v1alpha1.DiagnosticsStatus{}
+	}
+	status := tc.Status.Diagnostics
+
+	if status.Phase == v1alpha1.DiagnosticsCollecting {
+		m.syncCollection(tc, status)
+		if status.Phase == v1alpha1.DiagnosticsCollecting {
+			return nil
+		}
+	}
+	if status.Phase == v1alpha1.DiagnosticsUploading {
+		if err := m.syncUploadJob(tc, status); err != nil {
+			return err
+		}
+		if status.Phase == v1alpha1.DiagnosticsUploading {
+			return nil
+		}
+	}
+
+	now := m.now()
+	annotation := tc.Annotations[label.AnnCollectDiagnostics]
+	if annotation != "" && annotation != status.Annotation {
+		reason := fmt.Sprintf("requested by the annotation %s=%s", label.AnnCollectDiagnostics, annotation)
+		if err := m.collect(tc, status, reason, now); err != nil {
+			return err
+		}
+		status.Annotation = annotation
+		return nil
+	}
+
+	reason := diagnosticsTriggerReason(tc, now)
+	if reason == "" || reason == status.Reason {
+		return nil
+	}
+	minInterval := parseDurationOrDefault(spec.MinInterval, defaultDiagnosticsMinInterval)
+	if status.CollectTime != nil && now.Sub(status.CollectTime.Time) < minInterval {
+		return nil
+	}
+	return m.collect(tc, status, reason, now)
+}
+
+// syncUploadJob updates the phase of the bundle by the Job uploading it, the Secret of the bundle is deleted
+// once it's uploaded, and kept if the upload fails so that the bundle can still be retrieved.
+func (m *TidbClusterDiagnosticsManager) syncUploadJob(tc *v1alpha1.TidbCluster, status *v1alpha1.DiagnosticsStatus) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	job, err := m.deps.JobLister.Jobs(ns).Get(status.JobName)
+	if errors.IsNotFound(err) {
+		status.Phase = v1alpha1.DiagnosticsFailed
+		status.Message = fmt.Sprintf("job %s is not found, the bundle is kept in the secret %s", status.JobName, status.Bundle)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get job %s of diagnostics bundle %s for tc %s/%s, error: %v", status.JobName, status.Bundle, ns, tcName, err)
+	}
+	cond := getJobFinishedCondition(job)
+	if cond == nil {
+		return nil
+	}
+	if cond.Type == batchv1.JobFailed {
+		status.Phase = v1alpha1.DiagnosticsFailed
+		status.Message = fmt.Sprintf("failed to upload the bundle, it's kept in the secret %s: %s", status.Bundle, cond.Message)
+		klog.Warningf("failed to upload diagnostics bundle %s of tc %s/%s: %s", status.Bundle, ns, tcName, cond.Message)
+		return nil
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: status.Bundle, Namespace: ns}}
+	if err := m.deps.TypedControl.Delete(tc, secret); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	status.Phase = v1alpha1.DiagnosticsUploaded
+	status.Message = ""
+	klog.Infof("diagnostics bundle %s of tc %s/%s is uploaded", status.Bundle, ns, tcName)
+	return nil
+}
+
+// collect starts collecting a bundle in the background, as getting the logs of all the containers takes a while
+func (m *TidbClusterDiagnosticsManager) collect(tc *v1alpha1.TidbCluster, status *v1alpha1.DiagnosticsStatus, reason string, now time.Time) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	bundle := fmt.Sprintf("%s-diagnostics-%d", tcName, now.Unix())
+
+	klog.Infof("collect diagnostics bundle %s of tc %s/%s, reason: %s", bundle, ns, tcName, reason)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "CollectDiagnostics", "collect diagnostics bundle %s, reason: %s", bundle, reason)
+
+	c := &diagnosticsCollection{done: make(chan struct{})}
+	m.mu.Lock()
+	m.collections[ns+"/"+bundle] = c
+	m.mu.Unlock()
+	// the collection works on a copy, as tc is updated by the other managers in the meantime
+	go func(tc *v1alpha1.TidbCluster) {
+		defer close(c.done)
+		c.err = m.collectAndUpload(tc, bundle)
+	}(tc.DeepCopy())
+
+	status.Reason = reason
+	status.CollectTime = &metav1.Time{Time: now}
+	status.Bundle = bundle
+	status.Phase = v1alpha1.DiagnosticsCollecting
+	status.Message = ""
+	status.JobName = ""
+	return nil
+}
+
+// syncCollection updates the phase of the bundle by the result of the collection in the background. The bundle
+// being collected is lost if the operator restarts, which is told by the Job uploading it not being created.
+func (m *TidbClusterDiagnosticsManager) syncCollection(tc *v1alpha1.TidbCluster, status *v1alpha1.DiagnosticsStatus) {
+	key := tc.GetNamespace() + "/" + status.Bundle
+	m.mu.Lock()
+	c, ok := m.collections[key]
+	m.mu.Unlock()
+	if !ok {
+		if _, err := m.deps.JobLister.Jobs(tc.GetNamespace()).Get(status.Bundle); err == nil {
+			status.Phase = v1alpha1.DiagnosticsUploading
+			status.JobName = status.Bundle
+			return
+		}
+		status.Phase = v1alpha1.DiagnosticsFailed
+		status.Message = "the collection of the bundle is interrupted"
+		return
+	}
+	select {
+	case <-c.done:
+	default:
+		return
+	}
+
+	m.mu.Lock()
+	delete(m.collections, key)
+	m.mu.Unlock()
+	if c.err != nil {
+		status.Phase = v1alpha1.DiagnosticsFailed
+		status.Message = c.err.Error()
+		klog.Warningf("failed to collect diagnostics bundle %s of tc %s/%s: %v", status.Bundle, tc.GetNamespace(), tc.GetName(), c.err)
+		return
+	}
+	status.Phase = v1alpha1.DiagnosticsUploading
+	status.JobName = status.Bundle
+}
+
+// collectAndUpload collects the bundle into a Secret and creates the Job uploading it
+func (m *TidbClusterDiagnosticsManager) collectAndUpload(tc *v1alpha1.TidbCluster, bundle string) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	data, err := m.collectBundle(tc)
+	if err == nil && len(data) > maxDiagnosticsBundleSize {
+		err = fmt.Errorf("the bundle is %d bytes, exceeding the limit of %d bytes, reduce the logTailLines", len(data), maxDiagnosticsBundleSize)
+	}
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bundle,
+			Namespace: ns,
+			Labels:    label.NewDiagnostics().Instance(tcName),
+		},
+		Data: map[string][]byte{diagnosticsBundleKey: data},
+	}
+	if _, err := m.deps.TypedControl.CreateOrUpdateSecret(tc, secret); err != nil {
+		return fmt.Errorf("failed to save the bundle in the secret %s: %v", bundle, err)
+	}
+
+	job, err := m.makeUploadJob(tc, bundle)
+	if err != nil {
+		return fmt.Errorf("%v, the bundle is kept in the secret %s", err, bundle)
+	}
+	if err := m.deps.JobControl.CreateJob(tc, job); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create the job uploading the bundle, it's kept in the secret %s: %v", bundle, err)
+	}
+	return nil
+}
+
+// collectBundle collects the recent events, the logs of the containers, the health of PD, the states of the
+// stores and the status of the cluster into a gzipped tarball. The failures of collecting the items are
+// recorded in the bundle instead of failing the collection, as they are likely related to the failure.
+func (m *TidbClusterDiagnosticsManager) collectBundle(tc *v1alpha1.TidbCluster) ([]byte, error) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	now := m.now()
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: path.Join(tcName, name), Mode: 0644, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	addJSON := func(name string, obj interface{}, err error) error {
+		if err != nil {
+			return add(name, []byte(err.Error()))
+		}
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return add(name, []byte(err.Error()))
+		}
+		return add(name, data)
+	}
+
+	if err := addJSON("status.json", tc.Status, nil); err != nil {
+		return nil, err
+	}
+	if tc.Spec.PD != nil {
+		pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+		health, err := pdClient.GetHealth()
+		if err := addJSON("pd-health.json", health, err); err != nil {
+			return nil, err
+		}
+		stores, err := pdClient.GetStores()
+		if err := addJSON("stores.json", stores, err); err != nil {
+			return nil, err
+		}
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods for tc %s/%s, error: %v", ns, tcName, err)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	// the events of the cluster and its Pods are listed by the involved objects, so the events of the namespace
+	// are neither cached nor listed
+	names := []string{tcName}
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	events, err := m.listEvents(ns, names)
+	if err != nil {
+		if err := add("events.log", []byte(err.Error())); err != nil {
+			return nil, err
+		}
+	} else if err := add("events.log", formatDiagnosticsEvents(tcName, events)); err != nil {
+		return nil, err
+	}
+
+	tailLines := int64(defaultDiagnosticsLogTailLines)
+	if tc.Spec.Diagnostics.LogTailLines != nil {
+		tailLines = *tc.Spec.Diagnostics.LogTailLines
+	}
+	for _, pod := range pods {
+		restarted := map[string]bool{}
+		for _, s := range pod.Status.ContainerStatuses {
+			restarted[s.Name] = s.RestartCount > 0
+		}
+		for _, c := range pod.Spec.Containers {
+			if err := add(path.Join("logs", pod.Name, c.Name+".log"), m.getLogs(pod, c.Name, tailLines, false)); err != nil {
+				return nil, err
+			}
+			// the logs of the last terminated container are usually the most relevant to a crash
+			if restarted[c.Name] {
+				if err := add(path.Join("logs", pod.Name, c.Name+".previous.log"), m.getLogs(pod, c.Name, tailLines, true)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// listEvents lists the events of the objects with the given names
+func (m *TidbClusterDiagnosticsManager) listEvents(ns string, names []string) ([]*corev1.Event, error) {
+	var events []*corev1.Event
+	for _, name := range names {
+		selector := fields.OneTermEqualSelector("involvedObject.name", name).String()
+		list, err := m.deps.KubeClientset.CoreV1().Events(ns).List(context.TODO(), metav1.ListOptions{FieldSelector: selector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the events of %s/%s: %v", ns, name, err)
+		}
+		for i := range list.Items {
+			if list.Items[i].InvolvedObject.Name == name {
+				events = append(events, &list.Items[i])
+			}
+		}
+	}
+	return events, nil
+}
+
+// getLogs returns the most recent lines of the logs of the container, or the error if it fails
+func (m *TidbClusterDiagnosticsManager) getLogs(pod *corev1.Pod, container string, tailLines int64, previous bool) []byte {
+	opts := &corev1.PodLogOptions{
+		Container:  container,
+		Previous:   previous,
+		Timestamps: true,
+		TailLines:  pointer.Int64Ptr(tailLines),
+		LimitBytes: pointer.Int64Ptr(diagnosticsLogLimitBytes),
+	}
+	data, err := m.deps.KubeClientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).DoRaw(context.TODO())
+	if err != nil {
+		return []byte(fmt.Sprintf("failed to get the logs: %v", err))
+	}
+	return data
+}
+
+// makeUploadJob returns the Job uploading the bundle in the Secret to the storage
+func (m *TidbClusterDiagnosticsManager) makeUploadJob(tc *v1alpha1.TidbCluster, bundle string) (*batchv1.Job, error) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	spec := tc.Spec.Diagnostics
+
+	env, _, err := backuputil.GenerateStorageCertEnv(ns, false, spec.StorageProvider, m.deps.SecretLister)
+	if err != nil {
+		return nil, err
+	}
+	src := path.Join(diagnosticsBundlePath, diagnosticsBundleKey)
+	file := bundle + ".tar.gz"
+	container := corev1.Container{
+		Name:  diagnosticsContainerName,
+		Image: spec.Image,
+		Env:   env,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: diagnosticsBundleVolName, ReadOnly: true, MountPath: diagnosticsBundlePath},
+		},
+	}
+	if container.Image == "" {
+		container.Image = m.deps.CLIConfig.TiDBBackupManagerImage
+	}
+	vols := []corev1.Volume{
+		{
+			Name: diagnosticsBundleVolName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: bundle},
+			},
+		},
+	}
+
+	// the rclone config of the remote storages is generated by the entrypoint of tidb-backup-manager from the env
+	switch backuputil.GetStorageType(spec.StorageProvider) {
+	case v1alpha1.BackupStorageTypeS3:
+		dst := fmt.Sprintf("s3:%s", path.Join(spec.S3.Bucket, spec.S3.Prefix, file))
+		container.Args = []string{"rclone", "--config=/tmp/rclone.conf", "copyto", src, dst}
+	case v1alpha1.BackupStorageTypeGcs:
+		dst := fmt.Sprintf("gcs:%s", path.Join(spec.Gcs.Bucket, spec.Gcs.Prefix, file))
+		container.Args = []string{"rclone", "--config=/tmp/rclone.conf", "copyto", src, dst}
+	case v1alpha1.BackupStorageTypeLocal:
+		dir := path.Join(spec.Local.VolumeMount.MountPath, spec.Local.Prefix)
+		container.Args = []string{"sh", "-c", fmt.Sprintf("mkdir -p %s && cp %s %s", dir, src, path.Join(dir, file))}
+		container.VolumeMounts = append(container.VolumeMounts, spec.Local.VolumeMount)
+		vols = append(vols, spec.Local.Volume)
+	default:
+		return nil, fmt.Errorf("no storage is set for the diagnostics bundles")
+	}
+	if spec.Resources != nil {
+		container.Resources = *spec.Resources
+	}
+
+	jobLabel := label.NewDiagnostics().Instance(tcName)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            bundle,
+			Namespace:       ns,
+			Labels:          jobLabel,
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(2),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabel,
+				},
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: tc.Spec.ImagePullSecrets,
+					Containers:       []corev1.Container{container},
+					RestartPolicy:    corev1.RestartPolicyNever,
+					Volumes:          vols,
+				},
+			},
+		},
+	}, nil
+}
+
+// diagnosticsTriggerReason returns the reason the enabled triggers fire for, which is empty if none fires
+func diagnosticsTriggerReason(tc *v1alpha1.TidbCluster, now time.Time) string {
+	spec := tc.Spec.Diagnostics
+	enabled := func(trigger v1alpha1.DiagnosticsTrigger) bool {
+		if len(spec.Triggers) == 0 {
+			return true
+		}
+		for _, t := range spec.Triggers {
+			if t == trigger {
+				return true
+			}
+		}
+		return false
+	}
+
+	var reasons []string
+	if enabled(v1alpha1.DiagnosticsTriggerFailover) {
+		for _, m := range tc.Status.PD.FailureMembers {
+			reasons = append(reasons, fmt.Sprintf("failover of pd member %s", m.PodName))
+		}
+		for _, s := range tc.Status.TiKV.FailureStores {
+			reasons = append(reasons, fmt.Sprintf("failover of tikv store %s on pod %s", s.StoreID, s.PodName))
+		}
+		for _, m := range tc.Status.TiDB.FailureMembers {
+			reasons = append(reasons, fmt.Sprintf("failover of tidb member %s", m.PodName))
+		}
+		for _, s := range tc.Status.TiFlash.FailureStores {
+			reasons = append(reasons, fmt.Sprintf("failover of tiflash store %s on pod %s", s.StoreID, s.PodName))
+		}
+	}
+	if enabled(v1alpha1.DiagnosticsTriggerUpgradeStuck) {
+		timeout := parseDurationOrDefault(spec.UpgradeStuckTimeout, defaultDiagnosticsUpgradeStuckTimeout)
+		for _, op := range tc.Status.InFlightOperations {
+			if op.Type == v1alpha1.InFlightOperationUpgrade && now.Sub(op.StartTime.Time) > timeout {
+				reasons = append(reasons, fmt.Sprintf("upgrade of %s pod %s stuck since %s", op.Component, op.PodName, op.StartTime.Format(time.RFC3339)))
+			}
+		}
+	}
+	sort.Strings(reasons)
+	return strings.Join(reasons, "; ")
+}
+
+// formatDiagnosticsEvents formats the events of the objects of the cluster in the order of time
+func formatDiagnosticsEvents(tcName string, events []*corev1.Event) []byte {
+	lastTime := func(e *corev1.Event) time.Time {
+		if !e.LastTimestamp.IsZero() {
+			return e.LastTimestamp.Time
+		}
+		if !e.EventTime.IsZero() {
+			return e.EventTime.Time
+		}
+		return e.FirstTimestamp.Time
+	}
+	var related []*corev1.Event
+	for _, e := range events {
+		name := e.InvolvedObject.Name
+		if name == tcName || strings.HasPrefix(name, tcName+"-") {
+			related = append(related, e)
+		}
+	}
+	sort.SliceStable(related, func(i, j int) bool { return lastTime(related[i]).Before(lastTime(related[j])) })
+
+	var buf bytes.Buffer
+	for _, e := range related {
+		fmt.Fprintf(&buf, "%s\t%s\t%s\t%s/%s\tx%d\t%s\n", lastTime(e).Format(time.RFC3339), e.Type, e.Reason,
+			e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Count, e.Message)
+	}
+	return buf.Bytes()
+}
+
+// parseDurationOrDefault parses the duration string, the default is returned if it's not set or invalid
+func parseDurationOrDefault(s *string, defaultDuration time.Duration) time.Duration {
+	if s == nil {
+		return defaultDuration
+	}
+	d, err := time.ParseDuration(*s)
+	if err != nil || d <= 0 {
+		return defaultDuration
+	}
+	return d
+}
+
+type FakeTidbClusterDiagnosticsManager struct {
+}
+
+func NewFakeTidbClusterDiagnosticsManager() *FakeTidbClusterDiagnosticsManager {
+	return &FakeTidbClusterDiagnosticsManager{}
+}
+
+func (f *FakeTidbClusterDiagnosticsManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
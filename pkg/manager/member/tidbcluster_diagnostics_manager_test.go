@@ -0,0 +1,198 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestDiagnosticsTriggerReason(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2021, 10, 10, 2, 30, 0, 0, time.UTC)
+	tc := newTidbClusterForPD()
+	tc.Spec.Diagnostics = &v1alpha1.DiagnosticsSpec{UpgradeStuckTimeout: pointer.StringPtr("10m")}
+	g.Expect(diagnosticsTriggerReason(tc, now)).To(BeEmpty())
+
+	tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{
+		"1": {PodName: "test-tikv-0", StoreID: "1"},
+	}
+	tc.Status.InFlightOperations = []v1alpha1.InFlightOperation{
+		{Type: v1alpha1.InFlightOperationUpgrade, Component: v1alpha1.TiKVMemberType, PodName: "test-tikv-1", StartTime: metav1.Time{Time: now.Add(-20 * time.Minute)}},
+		{Type: v1alpha1.InFlightOperationUpgrade, Component: v1alpha1.TiKVMemberType, PodName: "test-tikv-2", StartTime: metav1.Time{Time: now.Add(-5 * time.Minute)}},
+	}
+	g.Expect(diagnosticsTriggerReason(tc, now)).To(Equal("failover of tikv store 1 on pod test-tikv-0; upgrade of tikv pod test-tikv-1 stuck since 2021-10-10T02:10:00Z"))
+
+	tc.Spec.Diagnostics.Triggers = []v1alpha1.DiagnosticsTrigger{v1alpha1.DiagnosticsTriggerUpgradeStuck}
+	g.Expect(diagnosticsTriggerReason(tc, now)).To(Equal("upgrade of tikv pod test-tikv-1 stuck since 2021-10-10T02:10:00Z"))
+}
+
+func TestTidbClusterDiagnosticsManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2021, 10, 10, 2, 30, 0, 0, time.UTC)
+	deps := controller.NewFakeDependencies()
+	m := NewTidbClusterDiagnosticsManager(deps)
+	m.now = func() time.Time { return now }
+	tc := newTidbClusterForPD()
+	tc.Spec.Diagnostics = &v1alpha1.DiagnosticsSpec{
+		StorageProvider: v1alpha1.StorageProvider{
+			S3: &v1alpha1.S3StorageProvider{Provider: v1alpha1.S3StorageProviderTypeAWS, Bucket: "bucket", Prefix: "diagnostics"},
+		},
+	}
+	cli := deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+	getSecret := func(name string) (*corev1.Secret, error) {
+		secret := &corev1.Secret{}
+		err := cli.Get(context.TODO(), types.NamespacedName{Namespace: tc.Namespace, Name: name}, secret)
+		return secret, err
+	}
+	listJobs := func() []*batchv1.Job {
+		jobs, err := deps.JobLister.List(labels.Everything())
+		g.Expect(err).NotTo(HaveOccurred())
+		return jobs
+	}
+	// waitCollected waits for the bundle collected in the background and syncs the result
+	waitCollected := func() {
+		g.Expect(tc.Status.Diagnostics.Phase).To(Equal(v1alpha1.DiagnosticsCollecting))
+		m.mu.Lock()
+		c := m.collections[tc.Namespace+"/"+tc.Status.Diagnostics.Bundle]
+		m.mu.Unlock()
+		g.Expect(c).NotTo(BeNil())
+		<-c.done
+		g.Expect(m.Sync(tc)).To(Succeed())
+	}
+	// the events of the Pods of the cluster are listed by the involved objects
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tikv-0", Namespace: tc.Namespace, Labels: label.New().Instance(tc.Name).TiKV().Labels()},
+	}
+	g.Expect(deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "test-tikv-0.1", Namespace: tc.Namespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "test-tikv-0"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Count:          3,
+	}
+	_, err := deps.KubeClientset.CoreV1().Events(tc.Namespace).Create(context.TODO(), event, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// nothing is collected when no trigger fires
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.Diagnostics).To(Equal(&v1alpha1.DiagnosticsStatus{}))
+
+	// the bundle is collected into a secret and uploaded by a job when requested by the annotation
+	tc.Annotations = map[string]string{label.AnnCollectDiagnostics: "1"}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	waitCollected()
+	status := tc.Status.Diagnostics
+	g.Expect(status.Phase).To(Equal(v1alpha1.DiagnosticsUploading))
+	g.Expect(status.Annotation).To(Equal("1"))
+	g.Expect(status.Bundle).To(Equal("test-diagnostics-1633833000"))
+	g.Expect(status.JobName).To(Equal(status.Bundle))
+	g.Expect(status.CollectTime.Time).To(Equal(now))
+	secret, err := getSecret(status.Bundle)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(secret.Data[diagnosticsBundleKey]).NotTo(BeEmpty())
+	gr, err := gzip.NewReader(bytes.NewReader(secret.Data[diagnosticsBundleKey]))
+	g.Expect(err).NotTo(HaveOccurred())
+	files := map[string]string{}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		data, err := ioutil.ReadAll(tr)
+		g.Expect(err).NotTo(HaveOccurred())
+		files[hdr.Name] = string(data)
+	}
+	g.Expect(files).To(HaveKey("test/status.json"))
+	g.Expect(files["test/events.log"]).To(ContainSubstring("Pod/test-tikv-0\tx3\tBack-off restarting failed container"))
+	jobs := listJobs()
+	g.Expect(jobs).To(HaveLen(1))
+	g.Expect(jobs[0].OwnerReferences).To(HaveLen(1))
+	container := jobs[0].Spec.Template.Spec.Containers[0]
+	g.Expect(container.Args).To(Equal([]string{"rclone", "--config=/tmp/rclone.conf", "copyto",
+		"/diagnostics/bundle.tar.gz", "s3:bucket/diagnostics/test-diagnostics-1633833000.tar.gz"}))
+
+	// no bundle is collected while the last one is being uploaded
+	tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{
+		"1": {PodName: "test-tikv-0", StoreID: "1"},
+	}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.DiagnosticsUploading))
+	g.Expect(listJobs()).To(HaveLen(1))
+
+	// the secret is deleted once the bundle is uploaded
+	job := jobs[0].DeepCopy()
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	g.Expect(deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer().Update(job)).To(Succeed())
+	now = now.Add(10 * time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.DiagnosticsUploaded))
+	_, err = getSecret("test-diagnostics-1633833000")
+	g.Expect(errors.IsNotFound(err)).To(BeTrue(), "unexpected error: %v", err)
+
+	// the bundles collected by the triggers are at least minInterval apart
+	g.Expect(status.Reason).To(ContainSubstring("requested by the annotation"))
+	g.Expect(listJobs()).To(HaveLen(1))
+	now = now.Add(time.Hour)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	waitCollected()
+	g.Expect(status.Reason).To(Equal("failover of tikv store 1 on pod test-tikv-0"))
+	g.Expect(status.Phase).To(Equal(v1alpha1.DiagnosticsUploading))
+	g.Expect(listJobs()).To(HaveLen(2))
+
+	// the bundle failed to be uploaded is kept, and no bundle is collected again for the same reason
+	job, err = deps.JobLister.Jobs(tc.Namespace).Get(status.JobName)
+	g.Expect(err).NotTo(HaveOccurred())
+	job = job.DeepCopy()
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}}
+	g.Expect(deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer().Update(job)).To(Succeed())
+	now = now.Add(2 * time.Hour)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.DiagnosticsFailed))
+	g.Expect(status.Message).To(ContainSubstring("BackoffLimitExceeded"))
+	_, err = getSecret(status.Bundle)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(listJobs()).To(HaveLen(2))
+
+	// the bundle being collected is failed if the collection is lost, e.g. the operator restarts
+	status.Phase = v1alpha1.DiagnosticsCollecting
+	status.Bundle = "test-diagnostics-lost"
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.DiagnosticsFailed))
+	g.Expect(status.Message).To(ContainSubstring("interrupted"))
+}
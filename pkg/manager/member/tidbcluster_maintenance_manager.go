@@ -0,0 +1,515 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/robfig/cron"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	maintenanceContainerName = "maintenance"
+	defaultMySQLClientImage  = "tnir/mysqlclient"
+	defaultStoreLimitRate    = 15
+	// maxMaintenanceTaskHistory is the max number of the finished runs kept in the status of a task
+	maxMaintenanceTaskHistory = 10
+)
+
+// TidbClusterMaintenanceManager runs the periodic maintenance tasks of the cluster as Jobs
+type TidbClusterMaintenanceManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewTidbClusterMaintenanceManager returns a TidbClusterMaintenanceManager
+func NewTidbClusterMaintenanceManager(deps *controller.Dependencies) *TidbClusterMaintenanceManager {
+	return &TidbClusterMaintenanceManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+// Sync checks the Jobs of the running tasks and creates the Jobs of the tasks that are due. At most one
+// Job of a task runs at the same time, the tasks are postponed while the cluster is upgrading and the
+// running Jobs are deleted when an upgrade begins.
+func (m *TidbClusterMaintenanceManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.Maintenance == nil || len(tc.Spec.Maintenance.Tasks) == 0 {
+		tc.Status.Maintenance = nil
+		return nil
+	}
+
+	upgrading := isTidbClusterUpgrading(tc)
+	statuses := make([]v1alpha1.MaintenanceTaskStatus, 0, len(tc.Spec.Maintenance.Tasks))
+	var errs []error
+	for i := range tc.Spec.Maintenance.Tasks {
+		task := &tc.Spec.Maintenance.Tasks[i]
+		status := v1alpha1.MaintenanceTaskStatus{Name: task.Name}
+		for _, s := range tc.Status.Maintenance {
+			if s.Name == task.Name {
+				status = *s.DeepCopy()
+				break
+			}
+		}
+		if err := m.syncTask(tc, task, &status, upgrading); err != nil {
+			errs = append(errs, err)
+		}
+		statuses = append(statuses, status)
+	}
+	tc.Status.Maintenance = statuses
+	return errorutils.NewAggregate(errs)
+}
+
+func (m *TidbClusterMaintenanceManager) syncTask(tc *v1alpha1.TidbCluster, task *v1alpha1.MaintenanceTask, status *v1alpha1.MaintenanceTaskStatus, upgrading bool) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	now := m.now()
+
+	if status.Phase == v1alpha1.MaintenanceTaskRunning {
+		job, err := m.deps.JobLister.Jobs(ns).Get(status.JobName)
+		if errors.IsNotFound(err) {
+			status.Phase = v1alpha1.MaintenanceTaskFailed
+			status.Message = fmt.Sprintf("job %s is not found", status.JobName)
+			status.LastCompletionTime = &metav1.Time{Time: now}
+			recordMaintenanceTaskRun(status)
+		} else if err != nil {
+			return fmt.Errorf("failed to get job %s of maintenance task %s for tc %s/%s, error: %v", status.JobName, task.Name, ns, tcName, err)
+		} else if cond := getJobFinishedCondition(job); cond != nil {
+			status.Phase = v1alpha1.MaintenanceTaskSucceeded
+			if cond.Type == batchv1.JobFailed {
+				status.Phase = v1alpha1.MaintenanceTaskFailed
+			}
+			status.Message = cond.Message
+			status.LastCompletionTime = &metav1.Time{Time: cond.LastTransitionTime.Time}
+			recordMaintenanceTaskRun(status)
+			klog.Infof("maintenance task %s of tc %s/%s finished, phase: %s", task.Name, ns, tcName, status.Phase)
+		} else if upgrading {
+			if err := m.deps.JobControl.DeleteJob(tc, job); err != nil {
+				return err
+			}
+			status.Phase = v1alpha1.MaintenanceTaskInterrupted
+			status.Message = "the job was deleted as the cluster began upgrading"
+			status.LastCompletionTime = &metav1.Time{Time: now}
+			recordMaintenanceTaskRun(status)
+			klog.Infof("maintenance task %s of tc %s/%s is interrupted as the cluster began upgrading", task.Name, ns, tcName)
+		} else {
+			// the next run waits for the running job
+			return nil
+		}
+	}
+
+	if task.Suspend {
+		return nil
+	}
+	sched, err := cron.ParseStandard(task.Schedule)
+	if err != nil {
+		status.Phase = v1alpha1.MaintenanceTaskFailed
+		status.Message = fmt.Sprintf("invalid schedule %q: %v", task.Schedule, err)
+		return nil
+	}
+	earliestTime := tc.CreationTimestamp.Time
+	if status.LastScheduleTime != nil {
+		earliestTime = status.LastScheduleTime.Time
+	}
+	scheduledTime := sched.Next(earliestTime)
+	if scheduledTime.After(now) {
+		return nil
+	}
+	// the missed runs are not caught up, only the latest one is
+	for next := sched.Next(scheduledTime); !next.After(now); next = sched.Next(next) {
+		scheduledTime = next
+	}
+	if task.Window != nil && !now.Before(scheduledTime.Add(task.Window.Duration)) {
+		status.Phase = v1alpha1.MaintenanceTaskSkipped
+		status.Message = fmt.Sprintf("couldn't start within the window of %s beginning at %s", task.Window.Duration, scheduledTime.Format(time.RFC3339))
+		status.JobName = ""
+		status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+		status.LastCompletionTime = &metav1.Time{Time: now}
+		recordMaintenanceTaskRun(status)
+		klog.Infof("maintenance task %s of tc %s/%s is skipped as the window beginning at %s was missed", task.Name, ns, tcName, scheduledTime)
+		return nil
+	}
+	if upgrading {
+		status.Message = "postponed as the cluster is upgrading"
+		return nil
+	}
+
+	status.LastScheduleTime = &metav1.Time{Time: now}
+	status.LastCompletionTime = nil
+	job, err := m.makeMaintenanceJob(tc, task, now)
+	if err != nil {
+		status.Phase = v1alpha1.MaintenanceTaskFailed
+		status.Message = err.Error()
+		status.JobName = ""
+		status.LastCompletionTime = &metav1.Time{Time: now}
+		recordMaintenanceTaskRun(status)
+		return nil
+	}
+	if task.Window != nil {
+		// the job is stopped at the end of the window
+		remaining := int64(scheduledTime.Add(task.Window.Duration).Sub(now).Seconds())
+		if remaining < 1 {
+			remaining = 1
+		}
+		job.Spec.ActiveDeadlineSeconds = &remaining
+	}
+	if err := m.deps.JobControl.CreateJob(tc, job); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	status.Phase = v1alpha1.MaintenanceTaskRunning
+	status.Message = ""
+	status.JobName = job.Name
+	klog.Infof("maintenance task %s of tc %s/%s started, job: %s", task.Name, ns, tcName, job.Name)
+	return nil
+}
+
+func (m *TidbClusterMaintenanceManager) makeMaintenanceJob(tc *v1alpha1.TidbCluster, task *v1alpha1.MaintenanceTask, scheduledTime time.Time) (*batchv1.Job, error) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	container := corev1.Container{Name: maintenanceContainerName}
+	var vols []corev1.Volume
+	clusterClientTLS := func() []string {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name: util.ClusterClientVolName, ReadOnly: true, MountPath: util.ClusterClientTLSPath,
+		})
+		vols = append(vols, corev1.Volume{
+			Name: util.ClusterClientVolName, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.ClusterClientTLSSecretName(tcName),
+				},
+			},
+		})
+		return []string{
+			path.Join(util.ClusterClientTLSPath, corev1.ServiceAccountRootCAKey),
+			path.Join(util.ClusterClientTLSPath, corev1.TLSCertKey),
+			path.Join(util.ClusterClientTLSPath, corev1.TLSPrivateKeyKey),
+		}
+	}
+
+	switch {
+	case task.CompactTiKV != nil:
+		var addrs []string
+		for _, store := range tc.Status.TiKV.Stores {
+			if store.State == v1alpha1.TiKVStateUp {
+				addrs = append(addrs, fmt.Sprintf("%s:%d", store.IP, tc.TiKVPort()))
+			}
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("no up TiKV store to compact")
+		}
+		sort.Strings(addrs)
+		db := task.CompactTiKV.DB
+		if db == "" {
+			db = "kv"
+		}
+		cfs := task.CompactTiKV.ColumnFamilies
+		if len(cfs) == 0 {
+			cfs = []string{"default", "write"}
+		}
+		tlsArgs := ""
+		if tc.IsTLSClusterEnabled() {
+			paths := clusterClientTLS()
+			tlsArgs = fmt.Sprintf(" --ca-path %s --cert-path %s --key-path %s", paths[0], paths[1], paths[2])
+		}
+		ranges, err := m.getCompactKeyRanges(tc, task.CompactTiKV)
+		if err != nil {
+			return nil, err
+		}
+		compactCmds := fmt.Sprintf("    /tikv-ctl%s --host ${addr} compact -d %s -c ${cf}\n", tlsArgs, db)
+		if len(ranges) > 0 {
+			compactCmds = ""
+			for _, r := range ranges {
+				compactCmds += fmt.Sprintf("    /tikv-ctl%s --host ${addr} compact -d %s -c ${cf} --from '%s' --to '%s'\n",
+					tlsArgs, db, escapeTiKVKey(r[0]), escapeTiKVKey(r[1]))
+			}
+		}
+		script := fmt.Sprintf(`set -e
+for addr in %s; do
+  for cf in %s; do
+    echo "compacting cf ${cf} of db %s on ${addr}"
+%s  done
+done
+`, strings.Join(addrs, " "), strings.Join(cfs, " "), db, compactCmds)
+		container.Image = tc.TiKVImage()
+		container.Command = []string{"/bin/sh", "-c", script}
+	case task.ResetStoreLimit != nil:
+		rate := int32(defaultStoreLimitRate)
+		if task.ResetStoreLimit.Rate != nil {
+			rate = *task.ResetStoreLimit.Rate
+		}
+		args := []string{"/pd-ctl", "-u", fmt.Sprintf("%s://%s:%d", tc.Scheme(), controller.PDMemberName(tcName), tc.PDClientPort())}
+		if tc.IsTLSClusterEnabled() {
+			paths := clusterClientTLS()
+			args = append(args, "--cacert", paths[0], "--cert", paths[1], "--key", paths[2])
+		}
+		container.Image = tc.PDImage()
+		container.Command = append(args, "store", "limit", "all", fmt.Sprintf("%d", rate))
+	case task.AnalyzeTable != nil:
+		if tc.Spec.TiDB == nil {
+			return nil, fmt.Errorf("no TiDB to run ANALYZE TABLE on")
+		}
+		stmts := make([]string, 0, len(task.AnalyzeTable.Tables))
+		for _, table := range task.AnalyzeTable.Tables {
+			parts := strings.SplitN(table, ".", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid table %q, must be in the format of db.table", table)
+			}
+			stmts = append(stmts, fmt.Sprintf("ANALYZE TABLE `%s`.`%s`;", parts[0], parts[1]))
+		}
+		user := task.AnalyzeTable.User
+		if user == "" {
+			user = v1alpha1.DefaultTidbUser
+		}
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: "ANALYZE_SQL", Value: strings.Join(stmts, " ")},
+			corev1.EnvVar{Name: "MYSQL_USER", Value: user},
+		)
+		if task.AnalyzeTable.SecretName != "" {
+			container.Env = append(container.Env, corev1.EnvVar{
+				Name: "MYSQL_PWD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: task.AnalyzeTable.SecretName},
+						Key:                  passwdKey,
+					},
+				},
+			})
+		}
+		tlsArgs := ""
+		if tc.Spec.TiDB.IsTLSClientEnabled() && !tc.SkipTLSWhenConnectTiDB() {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name: "tidb-client-tls", ReadOnly: true, MountPath: util.TiDBClientTLSPath,
+			})
+			vols = append(vols, corev1.Volume{
+				Name: "tidb-client-tls", VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: util.TiDBClientTLSSecretName(tcName),
+					},
+				},
+			})
+			tlsArgs = fmt.Sprintf(" --ssl-ca=%s --ssl-cert=%s --ssl-key=%s",
+				path.Join(util.TiDBClientTLSPath, corev1.ServiceAccountRootCAKey),
+				path.Join(util.TiDBClientTLSPath, corev1.TLSCertKey),
+				path.Join(util.TiDBClientTLSPath, corev1.TLSPrivateKeyKey))
+		}
+		image := task.AnalyzeTable.Image
+		if image == "" {
+			image = defaultMySQLClientImage
+		}
+		container.Image = image
+		container.Command = []string{"/bin/sh", "-c", fmt.Sprintf(`mysql -h %s -P %d -u "${MYSQL_USER}"%s -e "${ANALYZE_SQL}"`,
+			controller.TiDBMemberName(tcName), tc.TiDBPort(), tlsArgs)}
+	default:
+		return nil, fmt.Errorf("no action is set in maintenance task %s", task.Name)
+	}
+	if task.Resources != nil {
+		container.Resources = *task.Resources
+	}
+
+	jobLabel := label.NewMaintenance().Instance(tcName).Maintenance(task.Name)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%s-%d", tcName, task.Name, scheduledTime.Unix()/60),
+			Namespace:       ns,
+			Labels:          jobLabel,
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabel,
+				},
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: tc.Spec.ImagePullSecrets,
+					Containers:       []corev1.Container{container},
+					RestartPolicy:    corev1.RestartPolicyNever,
+					Volumes:          vols,
+				},
+			},
+		},
+	}, nil
+}
+
+// getCompactKeyRanges returns the data key ranges of the tables and the keyspaces to compact, the IDs of
+// the tables are resolved by the first healthy TiDB. No range is returned if the whole db is compacted.
+func (m *TidbClusterMaintenanceManager) getCompactKeyRanges(tc *v1alpha1.TidbCluster, task *v1alpha1.CompactTiKVTask) ([][2][]byte, error) {
+	var ranges [][2][]byte
+	if len(task.Tables) > 0 {
+		ids, err := m.getTableIDs(tc, task.Tables)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			ranges = append(ranges, [2][]byte{tableDataKey(id), tableDataKey(id + 1)})
+		}
+	}
+	for _, id := range task.KeyspaceIDs {
+		prefix := []byte{'x', byte(id >> 16), byte(id >> 8), byte(id)}
+		ranges = append(ranges, [2][]byte{encodeDataKey(prefix), encodeDataKey(prefixNext(prefix))})
+	}
+	return ranges, nil
+}
+
+// getTableIDs returns the IDs of the physical tables of the tables in the format of db.table
+func (m *TidbClusterMaintenanceManager) getTableIDs(tc *v1alpha1.TidbCluster, tables []string) ([]int64, error) {
+	if tc.Spec.TiDB == nil {
+		return nil, fmt.Errorf("no TiDB to resolve the tables")
+	}
+	names := make([]string, 0, len(tc.Status.TiDB.Members))
+	for name, member := range tc.Status.TiDB.Members {
+		if member.Health {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		ordinal, err := util.GetOrdinalFromPodName(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse the ordinal of tidb %s, error: %v", name, err))
+			continue
+		}
+		var ids []int64
+		for _, table := range tables {
+			parts := strings.SplitN(table, ".", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid table %q, must be in the format of db.table", table)
+			}
+			tableIDs, err := m.deps.TiDBControl.GetTableIDs(tc, ordinal, parts[0], parts[1])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get the ID of table %s by tidb %s, error: %v", table, name, err))
+				ids = nil
+				break
+			}
+			ids = append(ids, tableIDs...)
+		}
+		if ids != nil {
+			return ids, nil
+		}
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no healthy TiDB to resolve the tables")
+	}
+	return nil, errorutils.NewAggregate(errs)
+}
+
+// tableDataKey returns the data key in TiKV where the rows and the indexes of the table begin
+func tableDataKey(id int64) []byte {
+	key := make([]byte, 9)
+	key[0] = 't'
+	// the sign bit is flipped so that the negative IDs are ordered before the positive ones
+	binary.BigEndian.PutUint64(key[1:], uint64(id)^(1<<63))
+	return encodeDataKey(key)
+}
+
+// encodeDataKey encodes the transactional key in the memcomparable format and adds the prefix of the data keys
+func encodeDataKey(key []byte) []byte {
+	const groupSize = 8
+	encoded := []byte{'z'}
+	for i := 0; i <= len(key); i += groupSize {
+		group := make([]byte, groupSize)
+		n := copy(group, key[i:])
+		encoded = append(encoded, group...)
+		encoded = append(encoded, byte(0xff-(groupSize-n)))
+	}
+	return encoded
+}
+
+// prefixNext returns the smallest key that is greater than all the keys beginning with the prefix
+func prefixNext(prefix []byte) []byte {
+	next := append([]byte{}, prefix...)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next
+		}
+	}
+	return append(append([]byte{}, prefix...), 0)
+}
+
+// escapeTiKVKey escapes the key as tikv-ctl accepts, the non-alphanumeric bytes are escaped in octal so
+// that the key can be single-quoted in the shell
+func escapeTiKVKey(key []byte) string {
+	var sb strings.Builder
+	for _, b := range key {
+		if (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') {
+			sb.WriteByte(b)
+		} else {
+			fmt.Fprintf(&sb, "\\%03o", b)
+		}
+	}
+	return sb.String()
+}
+
+// recordMaintenanceTaskRun adds the finished run in the status to the history of the task
+func recordMaintenanceTaskRun(status *v1alpha1.MaintenanceTaskStatus) {
+	run := v1alpha1.MaintenanceTaskRun{
+		JobName:        status.JobName,
+		Phase:          status.Phase,
+		Message:        status.Message,
+		ScheduleTime:   status.LastScheduleTime.DeepCopy(),
+		CompletionTime: status.LastCompletionTime.DeepCopy(),
+	}
+	status.History = append([]v1alpha1.MaintenanceTaskRun{run}, status.History...)
+	if len(status.History) > maxMaintenanceTaskHistory {
+		status.History = status.History[:maxMaintenanceTaskHistory]
+	}
+}
+
+// getJobFinishedCondition returns the Complete or Failed condition of the job if it has finished
+func getJobFinishedCondition(job *batchv1.Job) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		c := &job.Status.Conditions[i]
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return c
+		}
+	}
+	return nil
+}
+
+// isTidbClusterUpgrading returns whether any component of the cluster is upgrading
+func isTidbClusterUpgrading(tc *v1alpha1.TidbCluster) bool {
+	return tc.PDUpgrading() || tc.TiKVUpgrading() || tc.TiDBUpgrading() || tc.TiFlashUpgrading() ||
+		tc.Status.Pump.Phase == v1alpha1.UpgradePhase || tc.Status.TiCDC.Phase == v1alpha1.UpgradePhase
+}
+
+type FakeTidbClusterMaintenanceManager struct {
+}
+
+func NewFakeTidbClusterMaintenanceManager() *FakeTidbClusterMaintenanceManager {
+	return &FakeTidbClusterMaintenanceManager{}
+}
+
+func (f *FakeTidbClusterMaintenanceManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
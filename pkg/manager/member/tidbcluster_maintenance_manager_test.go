@@ -0,0 +1,378 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+)
+
+func TestTidbClusterMaintenanceManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2021, 10, 10, 2, 30, 0, 0, time.UTC)
+	newTC := func() *v1alpha1.TidbCluster {
+		tc := newTidbClusterForPD()
+		tc.CreationTimestamp = metav1.Time{Time: now.Add(-24 * time.Hour)}
+		tc.Spec.TiKV = &v1alpha1.TiKVSpec{BaseImage: "pingcap/tikv"}
+		tc.Spec.Version = "v5.2.1"
+		tc.Spec.Maintenance = &v1alpha1.MaintenanceSpec{
+			Tasks: []v1alpha1.MaintenanceTask{
+				{Name: "compact", Schedule: "0 2 * * *", CompactTiKV: &v1alpha1.CompactTiKVTask{}},
+			},
+		}
+		tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+			"1": {ID: "1", IP: "test-tikv-0.test-tikv-peer.default.svc", State: v1alpha1.TiKVStateUp},
+			"2": {ID: "2", IP: "test-tikv-1.test-tikv-peer.default.svc", State: v1alpha1.TiKVStateDown},
+		}
+		return tc
+	}
+	newManager := func() (*TidbClusterMaintenanceManager, *controller.Dependencies) {
+		deps := controller.NewFakeDependencies()
+		m := NewTidbClusterMaintenanceManager(deps)
+		m.now = func() time.Time { return now }
+		return m, deps
+	}
+	listJobs := func(deps *controller.Dependencies) []*batchv1.Job {
+		jobs, err := deps.JobLister.List(labels.Everything())
+		g.Expect(err).NotTo(HaveOccurred())
+		return jobs
+	}
+
+	tests := []struct {
+		name        string
+		update      func(tc *v1alpha1.TidbCluster)
+		job         func(job *batchv1.Job)
+		errExpectFn func(*GomegaWithT, error)
+		expect      func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job)
+		deleteErr   bool
+	}{
+		{
+			name: "create the job when the task is due",
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(HaveLen(1))
+				job := jobs[0]
+				g.Expect(tc.Status.Maintenance).To(HaveLen(1))
+				status := tc.Status.Maintenance[0]
+				g.Expect(status.Phase).To(Equal(v1alpha1.MaintenanceTaskRunning))
+				g.Expect(status.JobName).To(Equal(job.Name))
+				g.Expect(status.LastScheduleTime.Time).To(Equal(now))
+				g.Expect(job.Labels[label.MaintenanceLabelKey]).To(Equal("compact"))
+				g.Expect(job.OwnerReferences).To(HaveLen(1))
+				container := job.Spec.Template.Spec.Containers[0]
+				g.Expect(container.Image).To(Equal("pingcap/tikv:v5.2.1"))
+				g.Expect(container.Command[2]).To(ContainSubstring("for addr in test-tikv-0.test-tikv-peer.default.svc:20160; do"))
+				g.Expect(container.Command[2]).To(ContainSubstring("for cf in default write; do"))
+			},
+		},
+		{
+			name: "the task is not due",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.Maintenance = []v1alpha1.MaintenanceTaskStatus{
+					{Name: "compact", Phase: v1alpha1.MaintenanceTaskSucceeded, LastScheduleTime: &metav1.Time{Time: now.Add(-10 * time.Minute)}},
+				}
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(BeEmpty())
+				g.Expect(tc.Status.Maintenance[0].Phase).To(Equal(v1alpha1.MaintenanceTaskSucceeded))
+			},
+		},
+		{
+			name: "the task is suspended",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Maintenance.Tasks[0].Suspend = true
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(BeEmpty())
+				g.Expect(tc.Status.Maintenance[0].Phase).To(BeEmpty())
+			},
+		},
+		{
+			name: "the task is postponed while the cluster is upgrading",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(BeEmpty())
+				g.Expect(tc.Status.Maintenance[0].Message).To(ContainSubstring("postponed"))
+				g.Expect(tc.Status.Maintenance[0].LastScheduleTime).To(BeNil())
+			},
+		},
+		{
+			name: "the job is still running",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.Maintenance = []v1alpha1.MaintenanceTaskStatus{
+					{Name: "compact", Phase: v1alpha1.MaintenanceTaskRunning, JobName: "test-compact-1", LastScheduleTime: &metav1.Time{Time: now.Add(-25 * time.Hour)}},
+				}
+			},
+			job: func(job *batchv1.Job) {},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(HaveLen(1))
+				g.Expect(tc.Status.Maintenance[0].Phase).To(Equal(v1alpha1.MaintenanceTaskRunning))
+				g.Expect(tc.Status.Maintenance[0].JobName).To(Equal("test-compact-1"))
+			},
+		},
+		{
+			name: "the job failed",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.Maintenance = []v1alpha1.MaintenanceTaskStatus{
+					{Name: "compact", Phase: v1alpha1.MaintenanceTaskRunning, JobName: "test-compact-1", LastScheduleTime: &metav1.Time{Time: now.Add(-10 * time.Minute)}},
+				}
+			},
+			job: func(job *batchv1.Job) {
+				job.Status.Conditions = []batchv1.JobCondition{
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "Job has reached the specified backoff limit", LastTransitionTime: metav1.Time{Time: now.Add(-time.Minute)}},
+				}
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(HaveLen(1))
+				status := tc.Status.Maintenance[0]
+				g.Expect(status.Phase).To(Equal(v1alpha1.MaintenanceTaskFailed))
+				g.Expect(status.Message).To(Equal("Job has reached the specified backoff limit"))
+				g.Expect(status.LastCompletionTime.Time).To(Equal(now.Add(-time.Minute)))
+				g.Expect(status.History).To(HaveLen(1))
+				g.Expect(status.History[0].JobName).To(Equal("test-compact-1"))
+				g.Expect(status.History[0].Phase).To(Equal(v1alpha1.MaintenanceTaskFailed))
+			},
+		},
+		{
+			name: "the history is limited",
+			update: func(tc *v1alpha1.TidbCluster) {
+				history := make([]v1alpha1.MaintenanceTaskRun, maxMaintenanceTaskHistory)
+				for i := range history {
+					history[i] = v1alpha1.MaintenanceTaskRun{Phase: v1alpha1.MaintenanceTaskSucceeded, JobName: fmt.Sprintf("test-compact-old-%d", i)}
+				}
+				tc.Status.Maintenance = []v1alpha1.MaintenanceTaskStatus{
+					{Name: "compact", Phase: v1alpha1.MaintenanceTaskRunning, JobName: "test-compact-1", LastScheduleTime: &metav1.Time{Time: now.Add(-10 * time.Minute)}, History: history},
+				}
+			},
+			job: func(job *batchv1.Job) {
+				job.Status.Conditions = []batchv1.JobCondition{
+					{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: now.Add(-time.Minute)}},
+				}
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				history := tc.Status.Maintenance[0].History
+				g.Expect(history).To(HaveLen(maxMaintenanceTaskHistory))
+				g.Expect(history[0].JobName).To(Equal("test-compact-1"))
+				g.Expect(history[0].Phase).To(Equal(v1alpha1.MaintenanceTaskSucceeded))
+				g.Expect(history[maxMaintenanceTaskHistory-1].JobName).To(Equal(fmt.Sprintf("test-compact-old-%d", maxMaintenanceTaskHistory-2)))
+			},
+		},
+		{
+			name: "the job is stopped at the end of the window",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Maintenance.Tasks[0].Window = &metav1.Duration{Duration: time.Hour}
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(HaveLen(1))
+				g.Expect(*jobs[0].Spec.ActiveDeadlineSeconds).To(Equal(int64(30 * 60)))
+				g.Expect(tc.Status.Maintenance[0].Phase).To(Equal(v1alpha1.MaintenanceTaskRunning))
+			},
+		},
+		{
+			name: "the run is skipped after the window",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Maintenance.Tasks[0].Window = &metav1.Duration{Duration: 20 * time.Minute}
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(BeEmpty())
+				status := tc.Status.Maintenance[0]
+				g.Expect(status.Phase).To(Equal(v1alpha1.MaintenanceTaskSkipped))
+				g.Expect(status.LastScheduleTime.Time).To(Equal(time.Date(2021, 10, 10, 2, 0, 0, 0, time.UTC)))
+				g.Expect(status.History).To(HaveLen(1))
+				g.Expect(status.History[0].Phase).To(Equal(v1alpha1.MaintenanceTaskSkipped))
+			},
+		},
+		{
+			name: "the run is postponed within the window while the cluster is upgrading",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Maintenance.Tasks[0].Window = &metav1.Duration{Duration: time.Hour}
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.Status.Maintenance = []v1alpha1.MaintenanceTaskStatus{
+					{Name: "compact", Phase: v1alpha1.MaintenanceTaskSucceeded, LastScheduleTime: &metav1.Time{Time: now.Add(-72 * time.Hour)}},
+				}
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(BeEmpty())
+				g.Expect(tc.Status.Maintenance[0].Message).To(ContainSubstring("postponed"))
+			},
+		},
+		{
+			name: "the running job is deleted when an upgrade begins",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.UpgradePhase
+				tc.Status.Maintenance = []v1alpha1.MaintenanceTaskStatus{
+					{Name: "compact", Phase: v1alpha1.MaintenanceTaskRunning, JobName: "test-compact-1", LastScheduleTime: &metav1.Time{Time: now.Add(-10 * time.Minute)}},
+				}
+			},
+			job: func(job *batchv1.Job) {},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(tc.Status.Maintenance[0].Phase).To(Equal(v1alpha1.MaintenanceTaskInterrupted))
+			},
+		},
+		{
+			name: "failed to delete the running job",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.UpgradePhase
+				tc.Status.Maintenance = []v1alpha1.MaintenanceTaskStatus{
+					{Name: "compact", Phase: v1alpha1.MaintenanceTaskRunning, JobName: "test-compact-1", LastScheduleTime: &metav1.Time{Time: now.Add(-10 * time.Minute)}},
+				}
+			},
+			job:       func(job *batchv1.Job) {},
+			deleteErr: true,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(tc.Status.Maintenance[0].Phase).To(Equal(v1alpha1.MaintenanceTaskRunning))
+			},
+		},
+		{
+			name: "no up store to compact",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiKV.Stores = nil
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(BeEmpty())
+				status := tc.Status.Maintenance[0]
+				g.Expect(status.Phase).To(Equal(v1alpha1.MaintenanceTaskFailed))
+				g.Expect(status.LastScheduleTime.Time).To(Equal(now))
+				g.Expect(status.History).To(HaveLen(1))
+			},
+		},
+		{
+			name: "the tasks are removed",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Maintenance = nil
+				tc.Status.Maintenance = []v1alpha1.MaintenanceTaskStatus{{Name: "compact"}}
+			},
+			expect: func(tc *v1alpha1.TidbCluster, jobs []*batchv1.Job) {
+				g.Expect(jobs).To(BeEmpty())
+				g.Expect(tc.Status.Maintenance).To(BeNil())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		m, deps := newManager()
+		tc := newTC()
+		if tt.update != nil {
+			tt.update(tc)
+		}
+		if tt.job != nil {
+			job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: "test-compact-1"}}
+			tt.job(job)
+			g.Expect(deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer().Add(job)).To(Succeed())
+		}
+		if tt.deleteErr {
+			deps.JobControl.(*controller.FakeJobControl).SetDeleteJobError(fmt.Errorf("API server failed"), 0)
+		}
+		err := m.Sync(tc)
+		if tt.errExpectFn != nil {
+			tt.errExpectFn(g, err)
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		tt.expect(tc, listJobs(deps))
+	}
+}
+
+func TestMakeMaintenanceJob(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m := NewTidbClusterMaintenanceManager(controller.NewFakeDependencies())
+	tc := newTidbClusterForPD()
+	tc.Spec.Version = "v5.2.1"
+	tc.Spec.TiDB = &v1alpha1.TiDBSpec{}
+	now := time.Now()
+
+	job, err := m.makeMaintenanceJob(tc, &v1alpha1.MaintenanceTask{
+		Name:            "store-limit",
+		ResetStoreLimit: &v1alpha1.ResetStoreLimitTask{Rate: pointer.Int32Ptr(30)},
+	}, now)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(tc.PDImage()))
+	g.Expect(job.Spec.Template.Spec.Containers[0].Command).To(Equal([]string{"/pd-ctl", "-u", "http://test-pd:2379", "store", "limit", "all", "30"}))
+
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	job, err = m.makeMaintenanceJob(tc, &v1alpha1.MaintenanceTask{
+		Name:            "store-limit",
+		ResetStoreLimit: &v1alpha1.ResetStoreLimitTask{},
+	}, now)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(job.Spec.Template.Spec.Containers[0].Command).To(ContainElement("https://test-pd:2379"))
+	g.Expect(job.Spec.Template.Spec.Containers[0].Command).To(ContainElement("--cacert"))
+	g.Expect(job.Spec.Template.Spec.Volumes).To(HaveLen(1))
+
+	job, err = m.makeMaintenanceJob(tc, &v1alpha1.MaintenanceTask{
+		Name: "analyze",
+		AnalyzeTable: &v1alpha1.AnalyzeTableTask{
+			Tables:     []string{"test.t1", "test.t2"},
+			SecretName: "tidb-secret",
+		},
+	}, now)
+	g.Expect(err).NotTo(HaveOccurred())
+	container := job.Spec.Template.Spec.Containers[0]
+	g.Expect(container.Image).To(Equal(defaultMySQLClientImage))
+	g.Expect(container.Command[2]).To(Equal(`mysql -h test-tidb -P 4000 -u "${MYSQL_USER}" -e "${ANALYZE_SQL}"`))
+	g.Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "ANALYZE_SQL", Value: "ANALYZE TABLE `test`.`t1`; ANALYZE TABLE `test`.`t2`;"}))
+	g.Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "MYSQL_USER", Value: "root"}))
+	g.Expect(container.Env[2].ValueFrom.SecretKeyRef.Name).To(Equal("tidb-secret"))
+
+	tc.Spec.TiDB.Replicas = 2
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+		"test-tidb-0": {Name: "test-tidb-0", Health: false},
+		"test-tidb-1": {Name: "test-tidb-1", Health: true},
+	}
+	m.deps.TiDBControl.(*controller.FakeTiDBControl).SetTableIDs(map[string][]int64{"test.t1": {72}})
+	tc.Spec.TLSCluster = nil
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", IP: "test-tikv-0.test-tikv-peer.default.svc", State: v1alpha1.TiKVStateUp},
+	}
+	job, err = m.makeMaintenanceJob(tc, &v1alpha1.MaintenanceTask{
+		Name:        "compact",
+		CompactTiKV: &v1alpha1.CompactTiKVTask{Tables: []string{"test.t1"}, KeyspaceIDs: []uint32{1}},
+	}, now)
+	g.Expect(err).NotTo(HaveOccurred())
+	script := job.Spec.Template.Spec.Containers[0].Command[2]
+	g.Expect(script).To(ContainSubstring(`/tikv-ctl --host ${addr} compact -d kv -c ${cf} --from 'zt\200\000\000\000\000\000\000\377H\000\000\000\000\000\000\000\370' --to 'zt\200\000\000\000\000\000\000\377I\000\000\000\000\000\000\000\370'`))
+	g.Expect(script).To(ContainSubstring(`--from 'zx\000\000\001\000\000\000\000\373' --to 'zx\000\000\002\000\000\000\000\373'`))
+
+	_, err = m.makeMaintenanceJob(tc, &v1alpha1.MaintenanceTask{
+		Name:        "compact",
+		CompactTiKV: &v1alpha1.CompactTiKVTask{Tables: []string{"test.t2"}},
+	}, now)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestPrefixNext(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(prefixNext([]byte{'x', 0, 0, 1})).To(Equal([]byte{'x', 0, 0, 2}))
+	g.Expect(prefixNext([]byte{'x', 0xff, 0xff, 0xff})).To(Equal([]byte{'y', 0, 0, 0}))
+	g.Expect(prefixNext([]byte{0xff})).To(Equal([]byte{0xff, 0}))
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// syncSpecChecksum records the checksum of the specs last applied to the StatefulSets and Services controlled
+// by the cluster. The live specs are not hashed, so the checksum is stable while the children are mutated by
+// others, and GitOps tools can compare it to tell whether the operator has applied a change.
+func (m *TidbClusterStatusManager) syncSpecChecksum(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return fmt.Errorf("syncSpecChecksum: failed to create selector for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	sets, err := m.deps.StatefulSetLister.StatefulSets(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncSpecChecksum: failed to list statefulsets for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	svcs, err := m.deps.ServiceLister.Services(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncSpecChecksum: failed to list services for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+
+	var children []string
+	for _, set := range sets {
+		if metav1.IsControlledBy(set, tc) {
+			children = append(children, fmt.Sprintf("StatefulSet/%s\n%s", set.Name, set.Annotations[LastAppliedConfigAnnotation]))
+		}
+	}
+	for _, svc := range svcs {
+		if metav1.IsControlledBy(svc, tc) {
+			children = append(children, fmt.Sprintf("Service/%s\n%s", svc.Name, svc.Annotations[LastAppliedConfigAnnotation]))
+		}
+	}
+	if len(children) == 0 {
+		tc.Status.SpecChecksum = ""
+		return nil
+	}
+	sort.Strings(children)
+
+	h := sha256.New()
+	for _, child := range children {
+		fmt.Fprintf(h, "%s\n", child)
+	}
+	tc.Status.SpecChecksum = fmt.Sprintf("%x", h.Sum(nil))
+	return nil
+}
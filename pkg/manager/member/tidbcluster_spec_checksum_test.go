@@ -0,0 +1,77 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncSpecChecksum(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tsm := NewTidbClusterStatusManager(fakeDeps)
+	tc := newTidbClusterForPD()
+
+	g.Expect(tsm.syncSpecChecksum(tc)).To(Succeed())
+	g.Expect(tc.Status.SpecChecksum).To(BeEmpty())
+
+	meta := func(name, lastApplied string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       tc.Namespace,
+			Labels:          label.New().Instance(tc.GetInstanceName()).Labels(),
+			Annotations:     map[string]string{LastAppliedConfigAnnotation: lastApplied},
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		}
+	}
+	setIndexer := fakeDeps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer()
+	set := &apps.StatefulSet{ObjectMeta: meta("test-pd", `{"replicas":3}`)}
+	g.Expect(setIndexer.Add(set)).To(Succeed())
+	svc := &corev1.Service{ObjectMeta: meta("test-pd", `{"type":"ClusterIP"}`)}
+	g.Expect(fakeDeps.KubeInformerFactory.Core().V1().Services().Informer().GetIndexer().Add(svc)).To(Succeed())
+	// not controlled by the cluster
+	other := &apps.StatefulSet{ObjectMeta: meta("test-other", `{"replicas":1}`)}
+	other.OwnerReferences = nil
+	g.Expect(setIndexer.Add(other)).To(Succeed())
+
+	g.Expect(tsm.syncSpecChecksum(tc)).To(Succeed())
+	checksum := tc.Status.SpecChecksum
+	g.Expect(checksum).To(HaveLen(64))
+
+	// the live spec is changed externally
+	set = set.DeepCopy()
+	replicas := int32(5)
+	set.Spec.Replicas = &replicas
+	g.Expect(setIndexer.Update(set)).To(Succeed())
+	other = other.DeepCopy()
+	other.Annotations[LastAppliedConfigAnnotation] = `{"replicas":2}`
+	g.Expect(setIndexer.Update(other)).To(Succeed())
+	g.Expect(tsm.syncSpecChecksum(tc)).To(Succeed())
+	g.Expect(tc.Status.SpecChecksum).To(Equal(checksum))
+
+	// a new desired spec is applied
+	set = set.DeepCopy()
+	set.Annotations[LastAppliedConfigAnnotation] = `{"replicas":5}`
+	g.Expect(setIndexer.Update(set)).To(Succeed())
+	g.Expect(tsm.syncSpecChecksum(tc)).To(Succeed())
+	g.Expect(tc.Status.SpecChecksum).NotTo(Equal(checksum))
+}
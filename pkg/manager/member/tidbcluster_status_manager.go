@@ -17,13 +17,18 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	perrors "github.com/pingcap/errors"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
 
@@ -44,14 +49,174 @@ func NewTidbClusterStatusManager(deps *controller.Dependencies) *TidbClusterStat
 }
 
 func (m *TidbClusterStatusManager) Sync(tc *v1alpha1.TidbCluster) error {
+	syncOrdinals(tc)
+
 	err := m.syncAutoScalerRef(tc)
 	if err != nil {
 		return err
 	}
 
+	err = m.syncHelperImages(tc)
+	if err != nil {
+		return err
+	}
+
+	err = m.syncConfigDrift(tc)
+	if err != nil {
+		return err
+	}
+
+	err = m.syncClockSkew(tc)
+	if err != nil {
+		return err
+	}
+
+	err = m.syncVersionSkew(tc)
+	if err != nil {
+		return err
+	}
+
+	err = m.syncSpecChecksum(tc)
+	if err != nil {
+		return err
+	}
+
+	err = m.syncTombstoneStoreGC(tc)
+	if err != nil {
+		return err
+	}
+
+	err = m.syncDebugArtifactEvents(tc)
+	if err != nil {
+		return err
+	}
+
+	err = m.syncEffectiveConfigMap(tc)
+	if err != nil {
+		return err
+	}
+
 	return m.syncTiDBInfoKey(tc)
 }
 
+// syncOrdinals records the effective ordinals of the Pods of the components that support delete slots
+func syncOrdinals(tc *v1alpha1.TidbCluster) {
+	tc.Status.PD.Ordinals = tc.PDStsDesiredOrdinals(false).List()
+	tc.Status.TiDB.Ordinals = tc.TiDBStsDesiredOrdinals(false).List()
+	tc.Status.TiKV.Ordinals = tc.TiKVStsDesiredOrdinals(false).List()
+	tc.Status.TiFlash.Ordinals = tc.TiFlashStsDesiredOrdinals(false).List()
+}
+
+// syncHelperImages records the helper images used by the Pods of the cluster, and the image of the tikv-importer
+// deployed for the cluster by the tikv-importer chart, and the digests they are resolved to by the container runtime.
+// The digests are verified if the image is pinned, and the mismatches are reported once when they are found.
+func (m *TidbClusterStatusManager) syncHelperImages(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	helperImages := map[string]struct{}{}
+	for _, spec := range []v1alpha1.ComponentAccessor{tc.BasePDSpec(), tc.BaseTiKVSpec(), tc.BaseTiDBSpec(), tc.BaseTiFlashSpec()} {
+		helperImages[spec.HelperImage()] = struct{}{}
+	}
+
+	selector, err := label.New().Instance(tcName).Selector()
+	if err != nil {
+		return fmt.Errorf("syncHelperImages: failed to create selector for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncHelperImages: failed to list pods for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	// the Pods of the tikv-importer are not managed by the operator, all of their images are recorded
+	importerSelector := labels.SelectorFromSet(labels.Set{
+		label.InstanceLabelKey:  tcName,
+		label.ComponentLabelKey: label.ImporterLabelVal,
+	})
+	importerPods, err := m.deps.PodLister.Pods(ns).List(importerSelector)
+	if err != nil {
+		return fmt.Errorf("syncHelperImages: failed to list tikv-importer pods for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+
+	digests := map[string]map[string]struct{}{}
+	collect := func(pod *corev1.Pod, isTracked func(image string) bool) {
+		containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, container := range containers {
+			if !isTracked(container.Image) {
+				continue
+			}
+			if digests[container.Image] == nil {
+				digests[container.Image] = map[string]struct{}{}
+			}
+			for _, status := range statuses {
+				if status.Name != container.Name {
+					continue
+				}
+				if digest := resolvedImageDigest(status); digest != "" {
+					digests[container.Image][digest] = struct{}{}
+				}
+			}
+		}
+	}
+	for _, pod := range pods {
+		collect(pod, func(image string) bool {
+			_, ok := helperImages[image]
+			return ok
+		})
+	}
+	for _, pod := range importerPods {
+		collect(pod, func(string) bool { return true })
+	}
+
+	oldStatuses := map[string]v1alpha1.HelperImageStatus{}
+	for _, status := range tc.Status.HelperImages {
+		oldStatuses[status.Image] = status
+	}
+	var helperStatuses []v1alpha1.HelperImageStatus
+	for image, resolved := range digests {
+		status := v1alpha1.HelperImageStatus{Image: image}
+		for digest := range resolved {
+			status.Digests = append(status.Digests, digest)
+		}
+		sort.Strings(status.Digests)
+
+		if pinned := v1alpha1.DigestOfImage(image); pinned != "" && len(status.Digests) > 0 {
+			status.Verified = true
+			old, hasOld := oldStatuses[image]
+			for _, digest := range status.Digests {
+				if digest == pinned {
+					continue
+				}
+				status.Verified = false
+				// the mismatched digest has been reported if it's recorded in the status
+				if hasOld && sets.NewString(old.Digests...).Has(digest) {
+					continue
+				}
+				m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "HelperImageDigestMismatch",
+					"helper image %s is resolved to unexpected digest %s", image, digest)
+			}
+		}
+		helperStatuses = append(helperStatuses, status)
+	}
+	sort.Slice(helperStatuses, func(i, j int) bool {
+		return helperStatuses[i].Image < helperStatuses[j].Image
+	})
+	tc.Status.HelperImages = helperStatuses
+	return nil
+}
+
+// resolvedImageDigest returns the digest of the image that the container is running, the repo
+// digest is preferred because the image ID of some container runtimes is the digest of the image config
+func resolvedImageDigest(status corev1.ContainerStatus) string {
+	if strings.Contains(status.ImageID, "@") {
+		return v1alpha1.DigestOfImage(status.ImageID)
+	}
+	if strings.Contains(status.Image, "@") {
+		return v1alpha1.DigestOfImage(status.Image)
+	}
+	return v1alpha1.DigestOfImage(status.ImageID)
+}
+
 // ref https://github.com/pingcap/tidb/blob/36b04d1aa01db722b3f07af759168c6b8da33801/domain/infosync/info.go#L72
 // search `TopologyInformationPath` about how the key with 'ttl' and 'info' suffix is updated in that file.
 func getStaleTidbInfoKey(ctx context.Context, client pdapi.PDEtcdClient) (staleKeys []*pdapi.KeyValue, err error) {
@@ -104,9 +269,10 @@ func (m *TidbClusterStatusManager) syncTiDBInfoKey(tc *v1alpha1.TidbCluster) err
 	var err error
 
 	if tc.HeterogeneousWithoutLocalPD() {
-		pdEtcdClient, err = m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
+		pdEtcdClient, err = m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled(),
+			pdapi.ClientPort(controller.RefPDClientPort(m.deps.TiDBClusterLister, tc)))
 	} else {
-		pdEtcdClient, err = m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled())
+		pdEtcdClient, err = m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled(), pdapi.ClientPort(tc.PDClientPort()))
 	}
 	if err != nil {
 		return err
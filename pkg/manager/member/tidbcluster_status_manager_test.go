@@ -16,14 +16,19 @@ package member
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestTidbPattern(t *testing.T) {
@@ -146,6 +151,130 @@ func TestSyncAutoScalerRef(t *testing.T) {
 	}
 }
 
+func TestSyncHelperImages(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+	otherDigest := "sha256:" + strings.Repeat("b", 64)
+	newPod := func(name, image, imageID string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: corev1.NamespaceDefault,
+				Labels:    label.New().Instance("test-pd").Labels(),
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "tidb", Image: "pingcap/tidb:v5.4.0"},
+					{Name: "slowlog", Image: image},
+				},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "tidb", ImageID: "docker-pullable://pingcap/tidb@" + otherDigest},
+					{Name: "slowlog", ImageID: imageID},
+				},
+			},
+		}
+	}
+
+	testcases := []struct {
+		name             string
+		digest           *string
+		imageIDs         []string
+		expectedDigests  []string
+		expectedVerified bool
+		expectedEvents   int
+	}{
+		{
+			name:            "not pinned",
+			imageIDs:        []string{"docker-pullable://busybox@" + digest, "docker-pullable://busybox@" + otherDigest},
+			expectedDigests: []string{digest, otherDigest},
+		},
+		{
+			name:             "pinned and verified",
+			digest:           &digest,
+			imageIDs:         []string{"docker-pullable://busybox@" + digest, "docker-pullable://busybox@" + digest},
+			expectedDigests:  []string{digest},
+			expectedVerified: true,
+		},
+		{
+			name:             "pinned but mismatched",
+			digest:           &digest,
+			imageIDs:         []string{"docker-pullable://busybox@" + digest, "docker-pullable://busybox@" + otherDigest},
+			expectedDigests:  []string{digest, otherDigest},
+			expectedVerified: false,
+			expectedEvents:   1,
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			fakeDeps := controller.NewFakeDependencies()
+			podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+			tsm := NewTidbClusterStatusManager(fakeDeps)
+			tc := newTidbCluster()
+			tc.Spec.Helper = &v1alpha1.HelperSpec{Digest: testcase.digest}
+			image := tc.HelperImage()
+			for i, imageID := range testcase.imageIDs {
+				podIndexer.Add(newPod(fmt.Sprintf("test-pd-tidb-%d", i), image, imageID))
+			}
+
+			// the mismatch is only reported when it's found, not on every sync
+			for i := 0; i < 2; i++ {
+				err := tsm.syncHelperImages(tc)
+				g.Expect(err).ShouldNot(HaveOccurred())
+				g.Expect(tc.Status.HelperImages).Should(Equal([]v1alpha1.HelperImageStatus{
+					{
+						Image:    image,
+						Digests:  testcase.expectedDigests,
+						Verified: testcase.expectedVerified,
+					},
+				}))
+			}
+			g.Expect(fakeDeps.Recorder.(*record.FakeRecorder).Events).Should(HaveLen(testcase.expectedEvents))
+		})
+	}
+}
+
+func TestSyncHelperImagesOfTiKVImporter(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+	otherDigest := "sha256:" + strings.Repeat("b", 64)
+	image := "pingcap/tidb-lightning@" + digest
+	fakeDeps := controller.NewFakeDependencies()
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	tsm := NewTidbClusterStatusManager(fakeDeps)
+	tc := newTidbCluster()
+
+	importer := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tc.Name + "-importer-0",
+			Namespace: tc.Namespace,
+			Labels: map[string]string{
+				label.NameLabelKey:      "tikv-importer",
+				label.InstanceLabelKey:  tc.Name,
+				label.ComponentLabelKey: label.ImporterLabelVal,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "importer", Image: image}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "importer", ImageID: "docker-pullable://pingcap/tidb-lightning@" + otherDigest},
+			},
+		},
+	}
+	podIndexer.Add(importer)
+
+	g.Expect(tsm.syncHelperImages(tc)).Should(Succeed())
+	g.Expect(tc.Status.HelperImages).Should(Equal([]v1alpha1.HelperImageStatus{
+		{Image: image, Digests: []string{otherDigest}, Verified: false},
+	}))
+	g.Expect(fakeDeps.Recorder.(*record.FakeRecorder).Events).Should(HaveLen(1))
+}
+
 func newFakeTidbClusterStatusManager() (*TidbClusterStatusManager, kubernetes.Interface, *fake.Clientset, cache.Indexer) {
 	fakeDeps := controller.NewFakeDependencies()
 	scalerInformer := fakeDeps.InformerFactory.Pingcap().V1alpha1().TidbClusterAutoScalers()
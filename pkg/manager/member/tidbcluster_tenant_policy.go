@@ -0,0 +1,321 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// TidbClusterTenantPolicyManager enforces the policy of the tenant the namespace of the TidbCluster belongs to
+type TidbClusterTenantPolicyManager struct {
+	deps *controller.Dependencies
+}
+
+// NewTidbClusterTenantPolicyManager returns a TidbClusterTenantPolicyManager
+func NewTidbClusterTenantPolicyManager(deps *controller.Dependencies) *TidbClusterTenantPolicyManager {
+	return &TidbClusterTenantPolicyManager{
+		deps: deps,
+	}
+}
+
+// Sync checks the TidbCluster against the policy of its tenant. The clusters are counted in the order of their
+// creation, so the clusters created after the limit is reached are blocked and the existing ones keep running.
+// The TiKV replicas are only checked when the cluster scales out TiKV, against the current replicas of the other
+// clusters of the tenant. The TenantPolicyViolated condition records the violated limits, and only the offending
+// operations are blocked by the member managers, i.e. the StatefulSets of a cluster with too many clusters or
+// a disallowed storage class are not created, and TiKV is not scaled out beyond the limit, while the existing
+// StatefulSets are still synced, e.g. failed over, upgraded or scaled in.
+func (m *TidbClusterTenantPolicyManager) Sync(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	tenant, policy, err := m.tenantPolicy(ns)
+	if err != nil {
+		return fmt.Errorf("failed to get the tenant policy for tc %s/%s, error: %v", ns, tcName, err)
+	}
+	if policy == nil {
+		utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterTenantPolicyViolated)
+		return nil
+	}
+
+	// the problems blocking the creation of the StatefulSets
+	var problems []string
+	if len(policy.AllowedStorageClasses) > 0 {
+		allowed := sets.NewString(policy.AllowedStorageClasses...)
+		for _, sc := range tidbClusterStorageClasses(tc).List() {
+			if !allowed.Has(sc) {
+				problems = append(problems, fmt.Sprintf("storage class %s is not allowed for tenant %s", sc, tenant))
+			}
+		}
+	}
+	reason := utiltidbcluster.TenantPolicyViolated
+	if policy.MaxClusters > 0 || policy.MaxTiKVReplicas > 0 {
+		tcs, err := m.tenantClusters(tenant)
+		if err != nil {
+			return fmt.Errorf("failed to list the clusters of tenant %s for tc %s/%s, error: %v", tenant, ns, tcName, err)
+		}
+		if problem := checkTenantMaxClusters(tc, tcs, tenant, policy.MaxClusters); problem != "" {
+			problems = append(problems, problem)
+		}
+		if problem := checkTenantMaxTiKVReplicas(tc, tcs, tenant, policy.MaxTiKVReplicas); problem != "" {
+			if len(problems) == 0 {
+				reason = utiltidbcluster.TenantTiKVReplicasExceeded
+			}
+			problems = append(problems, problem)
+		}
+	}
+
+	old := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterTenantPolicyViolated)
+	if len(problems) == 0 {
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterTenantPolicyViolated, corev1.ConditionFalse,
+			utiltidbcluster.TenantPolicySatisfied, fmt.Sprintf("The cluster satisfies the policy of tenant %s", tenant))
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		return nil
+	}
+
+	message := strings.Join(problems, "; ")
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterTenantPolicyViolated, corev1.ConditionTrue, reason, message)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	if old == nil || old.Status != corev1.ConditionTrue || old.Message != message {
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, utiltidbcluster.TenantPolicyViolated, message)
+	}
+	klog.Warningf("tidbcluster: [%s/%s] the tenant policy is violated: %s", ns, tcName, message)
+	return nil
+}
+
+// tenantPolicyBlocksCreation returns whether the StatefulSets of the cluster can't be created as the cluster
+// violates the policy of its tenant, i.e. the tenant has too many clusters or a storage class is disallowed.
+// It relies on the condition set by TidbClusterTenantPolicyManager, which is synced before the members.
+func tenantPolicyBlocksCreation(tc *v1alpha1.TidbCluster) bool {
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterTenantPolicyViolated)
+	return cond != nil && cond.Status == corev1.ConditionTrue && cond.Reason == utiltidbcluster.TenantPolicyViolated
+}
+
+// syncTenantTiKVReplicas keeps the TiKV group of the member type from scaling out beyond the max TiKV replicas of
+// the tenant, the replicas of the new StatefulSet are clamped to the limit, and the scaling out of the existing
+// one is skipped once the tenant reaches the limit, as the scaler scales out the stores one by one.
+func syncTenantTiKVReplicas(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, oldSet, newSet *apps.StatefulSet) error {
+	current := int32(0)
+	if oldSet != nil {
+		if scaling, _, _, _ := scaleOne(oldSet, newSet); scaling <= 0 {
+			return nil
+		}
+		current = *oldSet.Spec.Replicas
+	}
+	limit, ok, err := tenantTiKVReplicasLimit(deps, tc, memberType)
+	if err != nil || !ok || *newSet.Spec.Replicas <= limit {
+		return err
+	}
+	if oldSet == nil {
+		if limit < 0 {
+			limit = 0
+		}
+		*newSet.Spec.Replicas = limit
+	} else if current < limit {
+		return nil
+	} else {
+		resetReplicas(newSet, oldSet)
+	}
+	klog.Warningf("scaling out %s of tc %s/%s is limited to %d replicas by the tenant policy", memberType, tc.GetNamespace(), tc.GetName(), *newSet.Spec.Replicas)
+	return nil
+}
+
+// tenantTiKVReplicasLimit returns the max replicas of the TiKV group of the member type allowed by the tenant policy,
+// that is the max TiKV replicas of the tenant excluding the current replicas of the other clusters and the other
+// TiKV group of the cluster. It returns false if the replicas are not limited.
+func tenantTiKVReplicasLimit(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) (int32, bool, error) {
+	m := NewTidbClusterTenantPolicyManager(deps)
+	tenant, policy, err := m.tenantPolicy(tc.GetNamespace())
+	if err != nil || policy == nil || policy.MaxTiKVReplicas <= 0 {
+		return 0, false, err
+	}
+	tcs, err := m.tenantClusters(tenant)
+	if err != nil {
+		return 0, false, err
+	}
+	limit := policy.MaxTiKVReplicas
+	for _, t := range tcs {
+		if t.Namespace != tc.Namespace || t.Name != tc.Name {
+			limit -= currentTiKVReplicas(t)
+		}
+	}
+	other := tc.Status.TiKVCold.StatefulSet
+	if memberType == v1alpha1.TiKVColdMemberType {
+		other = tc.Status.TiKV.StatefulSet
+	}
+	if other != nil {
+		limit -= other.Replicas
+	}
+	return limit, true, nil
+}
+
+// tenantPolicy returns the tenant of the namespace and its policy, the policy is nil if the namespace
+// doesn't belong to a tenant with a policy
+func (m *TidbClusterTenantPolicyManager) tenantPolicy(ns string) (string, *controller.TenantPolicy, error) {
+	if len(m.deps.TenantPolicies) == 0 || m.deps.NamespaceLister == nil {
+		return "", nil, nil
+	}
+	namespace, err := m.deps.NamespaceLister.Get(ns)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil, nil
+		}
+		return "", nil, err
+	}
+	tenant := namespace.Annotations[label.AnnTenant]
+	policy, ok := m.deps.TenantPolicies[tenant]
+	if tenant == "" || !ok {
+		return "", nil, nil
+	}
+	return tenant, &policy, nil
+}
+
+// tenantClusters returns the TidbClusters in the namespaces of the tenant
+func (m *TidbClusterTenantPolicyManager) tenantClusters(tenant string) ([]*v1alpha1.TidbCluster, error) {
+	namespaces, err := m.deps.NamespaceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var tcs []*v1alpha1.TidbCluster
+	for _, namespace := range namespaces {
+		if namespace.Annotations[label.AnnTenant] != tenant {
+			continue
+		}
+		list, err := m.deps.TiDBClusterLister.TidbClusters(namespace.Name).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		tcs = append(tcs, list...)
+	}
+	return tcs, nil
+}
+
+// checkTenantMaxClusters returns the problem if the cluster is created after the tenant reaches the max clusters
+func checkTenantMaxClusters(tc *v1alpha1.TidbCluster, tcs []*v1alpha1.TidbCluster, tenant string, maxClusters int) string {
+	if maxClusters <= 0 || len(tcs) <= maxClusters {
+		return ""
+	}
+	sort.Slice(tcs, func(i, j int) bool {
+		if !tcs[i].CreationTimestamp.Equal(&tcs[j].CreationTimestamp) {
+			return tcs[i].CreationTimestamp.Before(&tcs[j].CreationTimestamp)
+		}
+		return tcs[i].Namespace+"/"+tcs[i].Name < tcs[j].Namespace+"/"+tcs[j].Name
+	})
+	for i, t := range tcs {
+		if t.Namespace == tc.Namespace && t.Name == tc.Name && i >= maxClusters {
+			return fmt.Sprintf("tenant %s has %d clusters, exceeding the limit %d", tenant, len(tcs), maxClusters)
+		}
+	}
+	return ""
+}
+
+// checkTenantMaxTiKVReplicas returns the problem if the cluster scales out TiKV beyond the max TiKV replicas of the tenant
+func checkTenantMaxTiKVReplicas(tc *v1alpha1.TidbCluster, tcs []*v1alpha1.TidbCluster, tenant string, maxReplicas int32) string {
+	if maxReplicas <= 0 {
+		return ""
+	}
+	desired := tc.TiKVStsDesiredReplicas() + tc.TiKVColdStsDesiredReplicas()
+	if desired <= currentTiKVReplicas(tc) {
+		return ""
+	}
+	total := desired
+	for _, t := range tcs {
+		if t.Namespace != tc.Namespace || t.Name != tc.Name {
+			total += currentTiKVReplicas(t)
+		}
+	}
+	if total <= maxReplicas {
+		return ""
+	}
+	return fmt.Sprintf("scaling out TiKV to %d replicas makes tenant %s have %d TiKV replicas, exceeding the limit %d",
+		desired, tenant, total, maxReplicas)
+}
+
+// currentTiKVReplicas returns the replicas of the TiKV StatefulSets of the cluster, including the cold group
+func currentTiKVReplicas(tc *v1alpha1.TidbCluster) int32 {
+	var replicas int32
+	if tc.Status.TiKV.StatefulSet != nil {
+		replicas += tc.Status.TiKV.StatefulSet.Replicas
+	}
+	if tc.Status.TiKVCold.StatefulSet != nil {
+		replicas += tc.Status.TiKVCold.StatefulSet.Replicas
+	}
+	return replicas
+}
+
+// tidbClusterStorageClasses returns the storage classes set explicitly for the volumes of the components
+func tidbClusterStorageClasses(tc *v1alpha1.TidbCluster) sets.String {
+	scs := sets.NewString()
+	add := func(sc *string) {
+		if sc != nil && *sc != "" {
+			scs.Insert(*sc)
+		}
+	}
+	addVolumes := func(vols []v1alpha1.StorageVolume) {
+		for _, vol := range vols {
+			add(vol.StorageClassName)
+		}
+	}
+	if tc.Spec.PD != nil {
+		add(tc.Spec.PD.StorageClassName)
+		addVolumes(tc.Spec.PD.StorageVolumes)
+	}
+	if tc.Spec.TiKV != nil {
+		add(tc.Spec.TiKV.StorageClassName)
+		addVolumes(tc.Spec.TiKV.StorageVolumes)
+		if tc.Spec.TiKV.ColdGroup != nil {
+			add(tc.Spec.TiKV.ColdGroup.StorageClassName)
+		}
+	}
+	if tc.Spec.TiDB != nil {
+		add(tc.Spec.TiDB.StorageClassName)
+		addVolumes(tc.Spec.TiDB.StorageVolumes)
+	}
+	if tc.Spec.TiFlash != nil {
+		for _, claim := range tc.Spec.TiFlash.StorageClaims {
+			add(claim.StorageClassName)
+		}
+	}
+	if tc.Spec.TiCDC != nil {
+		add(tc.Spec.TiCDC.StorageClassName)
+		addVolumes(tc.Spec.TiCDC.StorageVolumes)
+	}
+	if tc.Spec.Pump != nil {
+		add(tc.Spec.Pump.StorageClassName)
+	}
+	return scs
+}
+
+type FakeTidbClusterTenantPolicyManager struct {
+}
+
+func NewFakeTidbClusterTenantPolicyManager() *FakeTidbClusterTenantPolicyManager {
+	return &FakeTidbClusterTenantPolicyManager{}
+}
+
+func (f *FakeTidbClusterTenantPolicyManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
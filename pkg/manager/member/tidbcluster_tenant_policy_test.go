@@ -0,0 +1,157 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestTidbClusterTenantPolicyManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	deps.NamespaceLister = deps.KubeInformerFactory.Core().V1().Namespaces().Lister()
+	deps.TenantPolicies = map[string]controller.TenantPolicy{
+		"team-a": {MaxClusters: 2, MaxTiKVReplicas: 6, AllowedStorageClasses: []string{"ssd"}},
+	}
+	m := NewTidbClusterTenantPolicyManager(deps)
+
+	nsIndexer := deps.KubeInformerFactory.Core().V1().Namespaces().Informer().GetIndexer()
+	for name, tenant := range map[string]string{"ns-1": "team-a", "ns-2": "team-a", "ns-3": "team-b"} {
+		g.Expect(nsIndexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{label.AnnTenant: tenant},
+		}})).To(Succeed())
+	}
+	tcIndexer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+	created := time.Now().Add(-time.Hour)
+	newTC := func(ns, name string, tikvReplicas int32) *v1alpha1.TidbCluster {
+		tc := &v1alpha1.TidbCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, CreationTimestamp: metav1.NewTime(created)},
+			Spec: v1alpha1.TidbClusterSpec{
+				PD:   &v1alpha1.PDSpec{Replicas: 1},
+				TiKV: &v1alpha1.TiKVSpec{Replicas: tikvReplicas},
+			},
+		}
+		tc.Status.TiKV.StatefulSet = &apps.StatefulSetStatus{Replicas: tikvReplicas}
+		created = created.Add(time.Minute)
+		g.Expect(tcIndexer.Add(tc)).To(Succeed())
+		return tc
+	}
+	violated := func(tc *v1alpha1.TidbCluster) *v1alpha1.TidbClusterCondition {
+		return utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterTenantPolicyViolated)
+	}
+
+	tc1 := newTC("ns-1", "tc-1", 3)
+	tc2 := newTC("ns-2", "tc-2", 3)
+	g.Expect(m.Sync(tc1)).To(Succeed())
+	g.Expect(violated(tc1).Status).To(Equal(corev1.ConditionFalse))
+
+	// the StatefulSets of the cluster created after the limit is reached are not created, while the existing
+	// clusters are not blocked
+	tc3 := newTC("ns-1", "tc-3", 0)
+	g.Expect(m.Sync(tc3)).To(Succeed())
+	g.Expect(violated(tc3).Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(violated(tc3).Message).To(ContainSubstring("tenant team-a has 3 clusters, exceeding the limit 2"))
+	g.Expect(tenantPolicyBlocksCreation(tc3)).To(BeTrue())
+	g.Expect(m.Sync(tc2)).To(Succeed())
+	g.Expect(tenantPolicyBlocksCreation(tc2)).To(BeFalse())
+	g.Expect(tcIndexer.Delete(tc3)).To(Succeed())
+
+	// scaling out TiKV beyond the limit is reported, while the creation of the StatefulSets is not blocked
+	tc2.Spec.TiKV.Replicas = 4
+	g.Expect(m.Sync(tc2)).To(Succeed())
+	g.Expect(violated(tc2).Reason).To(Equal(utiltidbcluster.TenantTiKVReplicasExceeded))
+	g.Expect(violated(tc2).Message).To(ContainSubstring("makes tenant team-a have 7 TiKV replicas, exceeding the limit 6"))
+	g.Expect(tenantPolicyBlocksCreation(tc2)).To(BeFalse())
+	tc2.Spec.TiKV.Replicas = 3
+	g.Expect(m.Sync(tc2)).To(Succeed())
+	g.Expect(violated(tc2).Status).To(Equal(corev1.ConditionFalse))
+
+	// the storage classes not allowed block the creation of the StatefulSets, the default one is not checked
+	tc1.Spec.TiKV.StorageClassName = pointer.StringPtr("hdd")
+	tc1.Spec.PD.StorageClassName = pointer.StringPtr("ssd")
+	g.Expect(m.Sync(tc1)).To(Succeed())
+	g.Expect(violated(tc1).Reason).To(Equal(utiltidbcluster.TenantPolicyViolated))
+	g.Expect(violated(tc1).Message).To(Equal("storage class hdd is not allowed for tenant team-a"))
+	g.Expect(tenantPolicyBlocksCreation(tc1)).To(BeTrue())
+
+	// the clusters of the tenants without a policy are not limited
+	tc4 := newTC("ns-3", "tc-4", 10)
+	tc4.Spec.TiKV.StorageClassName = pointer.StringPtr("hdd")
+	g.Expect(m.Sync(tc4)).To(Succeed())
+	g.Expect(violated(tc4)).To(BeNil())
+}
+
+func TestSyncTenantTiKVReplicas(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	deps.NamespaceLister = deps.KubeInformerFactory.Core().V1().Namespaces().Lister()
+	deps.TenantPolicies = map[string]controller.TenantPolicy{
+		"team-a": {MaxTiKVReplicas: 6},
+	}
+	g.Expect(deps.KubeInformerFactory.Core().V1().Namespaces().Informer().GetIndexer().Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "ns",
+		Annotations: map[string]string{label.AnnTenant: "team-a"},
+	}})).To(Succeed())
+	tcIndexer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+	other := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns"}}
+	other.Status.TiKV.StatefulSet = &apps.StatefulSetStatus{Replicas: 3}
+	g.Expect(tcIndexer.Add(other)).To(Succeed())
+	tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Name: "tc", Namespace: "ns"}}
+	g.Expect(tcIndexer.Add(tc)).To(Succeed())
+
+	newSet := func(replicas int32) *apps.StatefulSet {
+		return &apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "tc-tikv", Namespace: "ns"},
+			Spec:       apps.StatefulSetSpec{Replicas: pointer.Int32Ptr(replicas)},
+		}
+	}
+
+	// the new StatefulSet is clamped to the replicas left to the tenant
+	set := newSet(5)
+	g.Expect(syncTenantTiKVReplicas(deps, tc, v1alpha1.TiKVMemberType, nil, set)).To(Succeed())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(3)))
+
+	// the stores are scaled out one by one until the tenant reaches the limit
+	set = newSet(5)
+	g.Expect(syncTenantTiKVReplicas(deps, tc, v1alpha1.TiKVMemberType, newSet(2), set)).To(Succeed())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(5)))
+	set = newSet(5)
+	g.Expect(syncTenantTiKVReplicas(deps, tc, v1alpha1.TiKVMemberType, newSet(3), set)).To(Succeed())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(3)))
+
+	// the cold group shares the limit with TiKV of the cluster
+	tc.Status.TiKV.StatefulSet = &apps.StatefulSetStatus{Replicas: 2}
+	set = newSet(2)
+	g.Expect(syncTenantTiKVReplicas(deps, tc, v1alpha1.TiKVColdMemberType, newSet(1), set)).To(Succeed())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(1)))
+
+	// scaling in is never blocked
+	set = newSet(1)
+	g.Expect(syncTenantTiKVReplicas(deps, tc, v1alpha1.TiKVMemberType, newSet(3), set)).To(Succeed())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(1)))
+}
@@ -0,0 +1,202 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// tlsSecretRequirement is a cluster TLS Secret and the DNS names its certificate must cover
+type tlsSecretRequirement struct {
+	name     string
+	dnsNames []string
+}
+
+// TidbClusterTLSPreflightManager validates the cluster TLS Secrets before they are mounted by the components
+type TidbClusterTLSPreflightManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewTidbClusterTLSPreflightManager returns a TidbClusterTLSPreflightManager
+func NewTidbClusterTLSPreflightManager(deps *controller.Dependencies) *TidbClusterTLSPreflightManager {
+	return &TidbClusterTLSPreflightManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+// Sync checks that the certificate in each cluster TLS Secret covers the DNS names the component is reached by,
+// i.e. the services and the advertised addresses of the Pods, and that it's in its validity window. The TLSInvalid
+// condition records the missing SANs and the expiry of the invalid Secrets, and the sync of the members is blocked
+// by the returned error until the Secrets are fixed, as the components can't handshake with the invalid certificates.
+func (m *TidbClusterTLSPreflightManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if !tc.IsTLSClusterEnabled() {
+		utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterTLSInvalid)
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	now := m.now()
+	var problems []string
+	for _, req := range tlsSecretRequirements(tc) {
+		problem, err := m.checkSecret(ns, req, now)
+		if err != nil {
+			return fmt.Errorf("failed to check secret %s for tc %s/%s, error: %v", req.name, ns, tcName, err)
+		}
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	old := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterTLSInvalid)
+	if len(problems) == 0 {
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterTLSInvalid, corev1.ConditionFalse,
+			utiltidbcluster.TLSValid, "All the cluster TLS secrets are valid")
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		return nil
+	}
+
+	message := strings.Join(problems, "; ")
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterTLSInvalid, corev1.ConditionTrue,
+		utiltidbcluster.TLSInvalid, message)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	if old == nil || old.Status != corev1.ConditionTrue || old.Message != message {
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, utiltidbcluster.TLSInvalid, message)
+	}
+	return controller.RequeueErrorf("tidbcluster: [%s/%s] the cluster TLS secrets are invalid: %s", ns, tcName, message)
+}
+
+// checkSecret returns the problem of the certificate in the Secret, or an empty string if it's valid
+func (m *TidbClusterTLSPreflightManager) checkSecret(ns string, req tlsSecretRequirement, now time.Time) (string, error) {
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(req.name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("secret %s is not found", req.name), nil
+		}
+		return "", err
+	}
+	cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return fmt.Sprintf("failed to parse the certificate in secret %s: %v", req.name, err), nil
+	}
+
+	var details []string
+	if now.Before(cert.NotBefore) {
+		details = append(details, fmt.Sprintf("not valid before %s", cert.NotBefore.UTC().Format(time.RFC3339)))
+	}
+	if now.After(cert.NotAfter) {
+		details = append(details, fmt.Sprintf("expired at %s", cert.NotAfter.UTC().Format(time.RFC3339)))
+	}
+	var missing []string
+	for _, name := range req.dnsNames {
+		if cert.VerifyHostname(name) != nil {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		details = append(details, fmt.Sprintf("missing SANs %s", strings.Join(missing, ",")))
+	}
+	if len(details) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("secret %s: %s", req.name, strings.Join(details, ", ")), nil
+}
+
+// tlsSecretRequirements returns the cluster TLS Secrets of the deployed components and the DNS names the
+// certificates must cover. The client Secret is only checked for the validity window.
+func tlsSecretRequirements(tc *v1alpha1.TidbCluster) []tlsSecretRequirement {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	clusterDomain := tc.Spec.ClusterDomain
+	podNames := func(memberName, peerName string, replicas int32) []string {
+		var names []string
+		for ordinal := int32(0); ordinal < replicas; ordinal++ {
+			name := fmt.Sprintf("%s-%d.%s.%s.svc", memberName, ordinal, peerName, ns)
+			if clusterDomain != "" {
+				name = name + "." + clusterDomain
+			}
+			names = append(names, name)
+		}
+		return names
+	}
+
+	var reqs []tlsSecretRequirement
+	add := func(component v1alpha1.MemberType, dnsNames []string) {
+		reqs = append(reqs, tlsSecretRequirement{
+			name:     util.ClusterTLSSecretName(tcName, component.String()),
+			dnsNames: dnsNames,
+		})
+	}
+	if tc.Spec.PD != nil {
+		memberName := controller.PDMemberName(tcName)
+		peerName := controller.PDPeerMemberName(tcName)
+		dnsNames := []string{memberName, fmt.Sprintf("%s.%s", memberName, ns)}
+		if clusterDomain != "" {
+			dnsNames = append(dnsNames, fmt.Sprintf("%s.%s.svc.%s", peerName, ns, clusterDomain))
+		}
+		add(v1alpha1.PDMemberType, append(dnsNames, podNames(memberName, peerName, tc.PDStsDesiredReplicas())...))
+	}
+	if tc.Spec.TiKV != nil {
+		add(v1alpha1.TiKVMemberType, podNames(controller.TiKVMemberName(tcName), controller.TiKVPeerMemberName(tcName), tc.TiKVStsDesiredReplicas()))
+	}
+	if tc.Spec.TiDB != nil {
+		add(v1alpha1.TiDBMemberType, podNames(controller.TiDBMemberName(tcName), controller.TiDBPeerMemberName(tcName), tc.TiDBStsDesiredReplicas()))
+	}
+	if tc.Spec.TiFlash != nil {
+		add(v1alpha1.TiFlashMemberType, podNames(controller.TiFlashMemberName(tcName), controller.TiFlashPeerMemberName(tcName), tc.TiFlashStsDesiredReplicas()))
+	}
+	if tc.Spec.TiCDC != nil {
+		add(v1alpha1.TiCDCMemberType, podNames(controller.TiCDCMemberName(tcName), controller.TiCDCPeerMemberName(tcName), tc.TiCDCDeployDesiredReplicas()))
+	}
+	if tc.Spec.TiProxy != nil {
+		add(v1alpha1.TiProxyMemberType, podNames(controller.TiProxyMemberName(tcName), controller.TiProxyPeerMemberName(tcName), tc.Spec.TiProxy.Replicas))
+	}
+	if tc.Spec.Pump != nil {
+		// pump advertises the address without the namespace unless the cluster domain is set
+		memberName := controller.PumpMemberName(tcName)
+		var dnsNames []string
+		for ordinal := int32(0); ordinal < tc.Spec.Pump.Replicas; ordinal++ {
+			name := fmt.Sprintf("%s-%d.%s", memberName, ordinal, controller.PumpPeerMemberName(tcName))
+			if clusterDomain != "" {
+				name = fmt.Sprintf("%s.%s.svc.%s", name, ns, clusterDomain)
+			}
+			dnsNames = append(dnsNames, name)
+		}
+		add(v1alpha1.PumpMemberType, dnsNames)
+	}
+	reqs = append(reqs, tlsSecretRequirement{name: util.ClusterClientTLSSecretName(tcName)})
+	return reqs
+}
+
+type FakeTidbClusterTLSPreflightManager struct {
+}
+
+func NewFakeTidbClusterTLSPreflightManager() *FakeTidbClusterTLSPreflightManager {
+	return &FakeTidbClusterTLSPreflightManager{}
+}
+
+func (f *FakeTidbClusterTLSPreflightManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestCertificateWithSANs(t *testing.T, notAfter time.Time, dnsNames ...string) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     dnsNames,
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestTidbClusterTLSPreflightManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2021, 10, 10, 2, 30, 0, 0, time.UTC)
+	deps := controller.NewFakeDependencies()
+	m := NewTidbClusterTLSPreflightManager(deps)
+	m.now = func() time.Time { return now }
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: corev1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{Replicas: 1},
+			TiKV: &v1alpha1.TiKVSpec{Replicas: 2},
+		},
+	}
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	setSecret := func(name string, cert []byte) {
+		g.Expect(secretIndexer.Update(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: tc.Namespace},
+			Data:       map[string][]byte{corev1.TLSCertKey: cert},
+		})).To(Succeed())
+	}
+
+	// nothing is checked if tls is not enabled
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterTLSInvalid)).To(BeNil())
+
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	setSecret("test-pd-cluster-secret", newTestCertificateWithSANs(t, now.Add(time.Hour), "test-pd", "*.test-pd-peer.default.svc"))
+	// the certificate covers the pod of ordinal 0 only
+	setSecret("test-tikv-cluster-secret", newTestCertificateWithSANs(t, now.Add(-time.Hour), "test-tikv-0.test-tikv-peer.default.svc"))
+	err := m.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterTLSInvalid)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(cond.Message).To(Equal("secret test-pd-cluster-secret: missing SANs test-pd.default; " +
+		"secret test-tikv-cluster-secret: expired at 2021-10-10T01:30:00Z, missing SANs test-tikv-1.test-tikv-peer.default.svc; " +
+		"secret test-cluster-client-secret is not found"))
+
+	setSecret("test-pd-cluster-secret", newTestCertificateWithSANs(t, now.Add(time.Hour), "test-pd", "test-pd.default", "*.test-pd-peer.default.svc"))
+	setSecret("test-tikv-cluster-secret", newTestCertificateWithSANs(t, now.Add(time.Hour), "*.test-tikv-peer.default.svc"))
+	setSecret("test-cluster-client-secret", newTestCertificateWithSANs(t, now.Add(time.Hour)))
+	g.Expect(m.Sync(tc)).To(Succeed())
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterTLSInvalid)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+}
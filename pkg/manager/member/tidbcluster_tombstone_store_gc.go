@@ -0,0 +1,127 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// syncTombstoneStoreGC removes the tombstone stores from PD at most once per interval. As PD removes all the
+// tombstone stores at once, the stores are only removed when all of them are recorded as the tombstone stores
+// of the cluster and the PVCs of the stores are deleted, otherwise the reason is recorded in status.
+func (m *TidbClusterStatusManager) syncTombstoneStoreGC(tc *v1alpha1.TidbCluster) error {
+	if !tc.IsTombstoneStoreGCEnabled() {
+		tc.Status.TombstoneStoreGC = nil
+		return nil
+	}
+	if tc.Status.TombstoneStoreGC != nil && time.Since(tc.Status.TombstoneStoreGC.LastGCTime.Time) < tc.TombstoneStoreGCInterval() {
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	storesInfo, err := pdClient.GetTombStoneStores()
+	if err != nil {
+		return fmt.Errorf("syncTombstoneStoreGC: failed to get tombstone stores of cluster %s/%s, error: %s", ns, tcName, err)
+	}
+
+	podNames := map[string]string{}
+	for _, status := range []v1alpha1.TiKVStatus{tc.Status.TiKV, tc.Status.TiKVCold} {
+		for id, store := range status.TombstoneStores {
+			podNames[id] = store.PodName
+		}
+	}
+	for id, store := range tc.Status.TiFlash.TombstoneStores {
+		podNames[id] = store.PodName
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return fmt.Errorf("syncTombstoneStoreGC: failed to create selector for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	pvcs, err := m.deps.PVCLister.PersistentVolumeClaims(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncTombstoneStoreGC: failed to list pvcs for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	// the PVCs are matched by the store ID they are labeled with, as the PVCs of a Pod scaled out again
+	// after being scaled in belong to a new store, and by the Pod name only if they are not labeled yet
+	pvcStoreIDs := map[string]struct{}{}
+	pvcPodNames := map[string]struct{}{}
+	for _, pvc := range pvcs {
+		if storeID := pvc.Labels[label.StoreIDLabelKey]; storeID != "" {
+			pvcStoreIDs[storeID] = struct{}{}
+		} else if podName, ok := pvc.Annotations[label.AnnPodNameKey]; ok {
+			pvcPodNames[podName] = struct{}{}
+		}
+	}
+
+	var stores, blocked []string
+	if storesInfo != nil {
+		for _, store := range storesInfo.Stores {
+			if store == nil || store.Store == nil {
+				continue
+			}
+			id := strconv.FormatUint(store.Store.GetId(), 10)
+			stores = append(stores, id)
+			podName, ok := podNames[id]
+			if !ok {
+				blocked = append(blocked, fmt.Sprintf("store %s does not belong to the cluster", id))
+				continue
+			}
+			_, storePVC := pvcStoreIDs[id]
+			if _, podPVC := pvcPodNames[podName]; storePVC || podPVC {
+				blocked = append(blocked, fmt.Sprintf("the PVCs of Pod %s of store %s are not deleted", podName, id))
+			}
+		}
+	}
+	sort.Strings(stores)
+	sort.Strings(blocked)
+
+	status := &v1alpha1.TombstoneStoreGCStatus{LastGCTime: metav1.Now()}
+	switch {
+	case len(stores) == 0:
+	case len(blocked) > 0:
+		status.Message = strings.Join(blocked, "; ")
+		klog.Infof("tidb cluster %s/%s skips removing the tombstone stores %v: %s", ns, tcName, stores, status.Message)
+	case tc.Spec.TombstoneStoreGC.DryRun:
+		status.Stores = stores
+		status.Message = "dry run"
+		for _, id := range stores {
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "TombstoneStoreGCDryRun", "tombstone store %s (pod %s) would be removed from PD", id, podNames[id])
+		}
+	default:
+		if err := pdClient.RemoveTombstoneStores(); err != nil {
+			return fmt.Errorf("syncTombstoneStoreGC: failed to remove tombstone stores %v of cluster %s/%s, error: %s", stores, ns, tcName, err)
+		}
+		klog.Infof("tidb cluster %s/%s removed the tombstone stores %v from PD", ns, tcName, stores)
+		status.Stores = stores
+		for _, id := range stores {
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "TombstoneStoreRemoved", "tombstone store %s (pod %s) is removed from PD", id, podNames[id])
+		}
+	}
+	tc.Status.TombstoneStoreGC = status
+	return nil
+}
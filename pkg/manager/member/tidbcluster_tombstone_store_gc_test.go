@@ -0,0 +1,109 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSyncTombstoneStoreGC(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tsm := NewTidbClusterStatusManager(fakeDeps)
+	tc := newTidbClusterForPD()
+	tc.Spec.TombstoneStoreGC = &v1alpha1.TombstoneStoreGCSpec{DryRun: true}
+	tc.Status.TiKV.TombstoneStores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-3"},
+	}
+	tc.Status.TiFlash.TombstoneStores = map[string]v1alpha1.TiKVStore{
+		"2": {ID: "2", PodName: "test-tiflash-1"},
+	}
+
+	storeIDs := []uint64{1, 2, 10}
+	removed := false
+	pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetTombStoneStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		storesInfo := &pdapi.StoresInfo{}
+		for _, id := range storeIDs {
+			storesInfo.Stores = append(storesInfo.Stores, &pdapi.StoreInfo{Store: &pdapi.MetaStore{Store: &metapb.Store{Id: id}}})
+		}
+		return storesInfo, nil
+	})
+	pdClient.AddReaction(pdapi.RemoveTombstoneStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		removed = true
+		return nil, nil
+	})
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "tikv-test-tikv-3",
+			Namespace:   tc.Namespace,
+			Labels:      label.New().Instance(tc.GetInstanceName()).TiKV().Labels(),
+			Annotations: map[string]string{label.AnnPodNameKey: "test-tikv-3"},
+		},
+	}
+	pvcIndexer := fakeDeps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+	g.Expect(pvcIndexer.Add(pvc)).To(Succeed())
+
+	// store 10 does not belong to the cluster and the PVC of store 1 is not deleted
+	g.Expect(tsm.syncTombstoneStoreGC(tc)).To(Succeed())
+	g.Expect(tc.Status.TombstoneStoreGC).NotTo(BeNil())
+	g.Expect(tc.Status.TombstoneStoreGC.Stores).To(BeEmpty())
+	g.Expect(tc.Status.TombstoneStoreGC.Message).To(Equal("store 10 does not belong to the cluster; the PVCs of Pod test-tikv-3 of store 1 are not deleted"))
+
+	// the PVC of store 1 is reused by the new store of the Pod scaled out again
+	storeIDs = []uint64{1, 2}
+	pvc = pvc.DeepCopy()
+	pvc.Labels[label.StoreIDLabelKey] = "1"
+	g.Expect(pvcIndexer.Update(pvc)).To(Succeed())
+	tc.Status.TombstoneStoreGC.LastGCTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	g.Expect(tsm.syncTombstoneStoreGC(tc)).To(Succeed())
+	g.Expect(tc.Status.TombstoneStoreGC.Message).To(Equal("the PVCs of Pod test-tikv-3 of store 1 are not deleted"))
+	pvc = pvc.DeepCopy()
+	pvc.Labels[label.StoreIDLabelKey] = "11"
+	g.Expect(pvcIndexer.Update(pvc)).To(Succeed())
+
+	// skipped within the interval
+	g.Expect(tsm.syncTombstoneStoreGC(tc)).To(Succeed())
+	g.Expect(tc.Status.TombstoneStoreGC.Message).NotTo(BeEmpty())
+
+	// dry run only reports the stores
+	tc.Status.TombstoneStoreGC.LastGCTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	g.Expect(tsm.syncTombstoneStoreGC(tc)).To(Succeed())
+	g.Expect(tc.Status.TombstoneStoreGC.Stores).To(Equal([]string{"1", "2"}))
+	g.Expect(removed).To(BeFalse())
+	g.Expect(fakeDeps.Recorder.(*record.FakeRecorder).Events).To(HaveLen(2))
+
+	tc.Spec.TombstoneStoreGC.DryRun = false
+	tc.Status.TombstoneStoreGC.LastGCTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	g.Expect(tsm.syncTombstoneStoreGC(tc)).To(Succeed())
+	g.Expect(tc.Status.TombstoneStoreGC.Stores).To(Equal([]string{"1", "2"}))
+	g.Expect(tc.Status.TombstoneStoreGC.Message).To(BeEmpty())
+	g.Expect(removed).To(BeTrue())
+
+	tc.Spec.TombstoneStoreGC = nil
+	g.Expect(tsm.syncTombstoneStoreGC(tc)).To(Succeed())
+	g.Expect(tc.Status.TombstoneStoreGC).To(BeNil())
+}
@@ -0,0 +1,428 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+// versionSkewComponent is a component whose version is compared, in the upgrade order
+type versionSkewComponent struct {
+	memberType     v1alpha1.MemberType
+	setName        string
+	desiredVersion string
+}
+
+// instanceVersion is the version an instance runs
+type instanceVersion struct {
+	pod     *corev1.Pod
+	version string
+}
+
+// versionSkewComponents returns the deployed components whose versions are compared, in the upgrade order
+func versionSkewComponents(tc *v1alpha1.TidbCluster) []versionSkewComponent {
+	var components []versionSkewComponent
+	if tc.Spec.PD != nil {
+		components = append(components, versionSkewComponent{v1alpha1.PDMemberType, controller.PDMemberName(tc.GetName()), tc.PDVersion()})
+	}
+	if tc.Spec.TiKV != nil {
+		components = append(components, versionSkewComponent{v1alpha1.TiKVMemberType, controller.TiKVMemberName(tc.GetName()), tc.TiKVVersion()})
+	}
+	if tc.Spec.TiDB != nil {
+		components = append(components, versionSkewComponent{v1alpha1.TiDBMemberType, controller.TiDBMemberName(tc.GetName()), tc.TiDBVersion()})
+	}
+	return components
+}
+
+// syncVersionSkew compares the versions the PD, TiKV and TiDB instances run, records the instances that don't
+// run the desired versions in status and the VersionSkew condition once the skew lasts for the grace period,
+// and suggests a plan to repair the skew, which is executed once approved by the annotation of the cluster.
+func (m *TidbClusterStatusManager) syncVersionSkew(tc *v1alpha1.TidbCluster) error {
+	if !tc.IsVersionSkewCheckEnabled() {
+		tc.Status.VersionSkew = nil
+		utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterVersionSkew)
+		return nil
+	}
+
+	components := versionSkewComponents(tc)
+	instances := map[v1alpha1.MemberType][]instanceVersion{}
+	for _, c := range components {
+		selector, err := label.New().Instance(tc.GetInstanceName()).Component(c.memberType.String()).Selector()
+		if err != nil {
+			return fmt.Errorf("syncVersionSkew: failed to create selector for cluster %s/%s, error: %s", tc.GetNamespace(), tc.GetName(), err)
+		}
+		pods, err := m.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+		if err != nil {
+			return fmt.Errorf("syncVersionSkew: failed to list pods for cluster %s/%s, error: %s", tc.GetNamespace(), tc.GetName(), err)
+		}
+		sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+		for _, pod := range pods {
+			for _, container := range pod.Spec.Containers {
+				if container.Name == c.memberType.String() {
+					instances[c.memberType] = append(instances[c.memberType], instanceVersion{pod: pod, version: imageVersion(container.Image)})
+				}
+			}
+		}
+	}
+
+	if !isVersionSkewed(tc, components, instances) {
+		tc.Status.VersionSkew = nil
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterVersionSkew, corev1.ConditionFalse,
+			utiltidbcluster.VersionsInSync, "The versions of all instances are within the supported skew")
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		return nil
+	}
+
+	if tc.Status.VersionSkew == nil {
+		tc.Status.VersionSkew = &v1alpha1.VersionSkewStatus{DetectedTime: metav1.Now()}
+	}
+	status := tc.Status.VersionSkew
+	status.Instances = nil
+	for _, c := range components {
+		for _, instance := range instances[c.memberType] {
+			if instance.version != c.desiredVersion {
+				status.Instances = append(status.Instances, v1alpha1.VersionSkewInstance{
+					Component:      c.memberType,
+					Name:           instance.pod.Name,
+					Version:        instance.version,
+					DesiredVersion: c.desiredVersion,
+				})
+			}
+		}
+	}
+
+	message := versionSkewMessage(components, instances)
+	if tolerated := status.DetectedTime.Add(tc.VersionSkewGracePeriod()); time.Now().Before(tolerated) {
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterVersionSkew, corev1.ConditionFalse,
+			utiltidbcluster.VersionSkewTolerated, fmt.Sprintf("%s, tolerated until %s", message, tolerated.Format(time.RFC3339)))
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		return nil
+	}
+
+	oldCond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterVersionSkew)
+	reported := oldCond != nil && oldCond.Status == corev1.ConditionTrue
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterVersionSkew, corev1.ConditionTrue,
+		utiltidbcluster.VersionSkewed, message)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	if !reported {
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, utiltidbcluster.VersionSkewed, message)
+	}
+
+	return m.syncVersionSkewRepair(tc, components, instances)
+}
+
+// isVersionSkewed returns whether the instances run different major versions, the minor versions differ by more
+// than the tolerated skew, or a component runs a newer version than the component upgraded before it. The
+// versions that are not semantic versions, e.g. latest or nightly, are ignored.
+func isVersionSkewed(tc *v1alpha1.TidbCluster, components []versionSkewComponent, instances map[v1alpha1.MemberType][]instanceVersion) bool {
+	var oldest, newest *semver.Version
+	// oldestBefore is the oldest version of the components upgraded before the current one
+	var oldestBefore *semver.Version
+	for _, c := range components {
+		var componentOldest *semver.Version
+		for _, instance := range instances[c.memberType] {
+			v, err := semver.NewVersion(instance.version)
+			if err != nil {
+				continue
+			}
+			// only the major and minor versions are compared
+			v, _ = semver.NewVersion(fmt.Sprintf("%d.%d.0", v.Major(), v.Minor()))
+			if oldestBefore != nil && v.GreaterThan(oldestBefore) {
+				return true
+			}
+			if componentOldest == nil || v.LessThan(componentOldest) {
+				componentOldest = v
+			}
+			if oldest == nil || v.LessThan(oldest) {
+				oldest = v
+			}
+			if newest == nil || v.GreaterThan(newest) {
+				newest = v
+			}
+		}
+		if componentOldest != nil && (oldestBefore == nil || componentOldest.LessThan(oldestBefore)) {
+			oldestBefore = componentOldest
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	return oldest.Major() != newest.Major() || newest.Minor()-oldest.Minor() > tc.MaxMinorVersionSkew()
+}
+
+// versionSkewMessage describes the versions of the instances of each component
+func versionSkewMessage(components []versionSkewComponent, instances map[v1alpha1.MemberType][]instanceVersion) string {
+	var msgs []string
+	for _, c := range components {
+		var versions []string
+		names := map[string][]string{}
+		for _, instance := range instances[c.memberType] {
+			if _, ok := names[instance.version]; !ok {
+				versions = append(versions, instance.version)
+			}
+			names[instance.version] = append(names[instance.version], instance.pod.Name)
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		details := make([]string, 0, len(versions))
+		for _, version := range versions {
+			details = append(details, fmt.Sprintf("%s (%s)", version, strings.Join(names[version], ",")))
+		}
+		msgs = append(msgs, fmt.Sprintf("%s: %s", c.memberType, strings.Join(details, ", ")))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// syncVersionSkewRepair suggests the plan to repair the version skew until the plan is approved, and executes the
+// approved plan
+func (m *TidbClusterStatusManager) syncVersionSkewRepair(tc *v1alpha1.TidbCluster, components []versionSkewComponent, instances map[v1alpha1.MemberType][]instanceVersion) error {
+	status := tc.Status.VersionSkew
+	if status.Plan == nil || !status.Plan.Approved {
+		plan, err := m.versionSkewRepairPlan(tc, components, instances)
+		if err != nil {
+			return err
+		}
+		if plan == nil {
+			// the desired versions themselves are skewed, which is repaired by fixing the spec
+			status.Plan = nil
+			return nil
+		}
+		if status.Plan == nil || status.Plan.ID != plan.ID {
+			status.Plan = plan
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "VersionSkewRepairPlanned",
+				"plan %s to repair the version skew is suggested, approve it by setting the annotation %s to %s",
+				plan.ID, label.AnnRepairVersionSkew, plan.ID)
+		}
+		if tc.Annotations[label.AnnRepairVersionSkew] != status.Plan.ID {
+			return nil
+		}
+		status.Plan.Approved = true
+		klog.Infof("the plan %s to repair the version skew of tc %s/%s is approved", status.Plan.ID, tc.GetNamespace(), tc.GetName())
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "VersionSkewRepairApproved", "plan %s to repair the version skew is approved", status.Plan.ID)
+	}
+
+	for i := range status.Plan.Steps {
+		step := &status.Plan.Steps[i]
+		if step.CompletionTime != nil {
+			continue
+		}
+		done, err := m.executeVersionSkewRepairStep(tc, step, instances[step.Component])
+		if err != nil || !done {
+			return err
+		}
+		now := metav1.Now()
+		step.CompletionTime = &now
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "VersionSkewRepairStepCompleted",
+			"%s of %s to version %s is completed", step.Action, step.Component, step.Version)
+	}
+	return nil
+}
+
+// versionSkewRepairPlan returns the plan bringing the instances to the desired versions of their components, in
+// the upgrade order of the components. The instances that don't run the revision of their StatefulSets, or whose
+// StatefulSets are not at the desired versions, are upgraded by re-applying the StatefulSets, and the other ones
+// are restarted. Nil is returned if all the instances run the desired versions.
+func (m *TidbClusterStatusManager) versionSkewRepairPlan(tc *v1alpha1.TidbCluster, components []versionSkewComponent, instances map[v1alpha1.MemberType][]instanceVersion) (*v1alpha1.VersionSkewRepairPlan, error) {
+	var steps []v1alpha1.VersionSkewRepairStep
+	for _, c := range components {
+		set, err := m.deps.StatefulSetLister.StatefulSets(tc.GetNamespace()).Get(c.setName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("syncVersionSkew: failed to get sts %s for cluster %s/%s, error: %s", c.setName, tc.GetNamespace(), tc.GetName(), err)
+		}
+		templateVersion := ""
+		for _, container := range set.Spec.Template.Spec.Containers {
+			if container.Name == c.memberType.String() {
+				templateVersion = imageVersion(container.Image)
+			}
+		}
+
+		var upgrade, restart []string
+		for _, instance := range instances[c.memberType] {
+			if instance.version == c.desiredVersion {
+				continue
+			}
+			if templateVersion == c.desiredVersion && instance.pod.Labels[apps.ControllerRevisionHashLabelKey] == set.Status.UpdateRevision {
+				restart = append(restart, instance.pod.Name)
+			} else {
+				upgrade = append(upgrade, instance.pod.Name)
+			}
+		}
+		if len(upgrade) > 0 {
+			steps = append(steps, v1alpha1.VersionSkewRepairStep{
+				Component: c.memberType,
+				Action:    v1alpha1.VersionSkewRepairUpgradeStatefulSet,
+				Instances: upgrade,
+				Version:   c.desiredVersion,
+			})
+		}
+		if len(restart) > 0 {
+			steps = append(steps, v1alpha1.VersionSkewRepairStep{
+				Component: c.memberType,
+				Action:    v1alpha1.VersionSkewRepairRestartPods,
+				Instances: restart,
+				Version:   c.desiredVersion,
+			})
+		}
+	}
+	if len(steps) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return nil, err
+	}
+	h := fnv.New32a()
+	h.Write(data)
+	return &v1alpha1.VersionSkewRepairPlan{
+		ID:    fmt.Sprintf("%08x", h.Sum32()),
+		Steps: steps,
+	}, nil
+}
+
+// executeVersionSkewRepairStep starts the step or moves it forward, and returns whether all the instances of the
+// step run the version and are ready
+func (m *TidbClusterStatusManager) executeVersionSkewRepairStep(tc *v1alpha1.TidbCluster, step *v1alpha1.VersionSkewRepairStep, instances []instanceVersion) (bool, error) {
+	byName := map[string]instanceVersion{}
+	for _, instance := range instances {
+		byName[instance.pod.Name] = instance
+	}
+	var pending []instanceVersion
+	for _, name := range step.Instances {
+		instance, ok := byName[name]
+		if !ok {
+			// the Pod is being recreated
+			return false, nil
+		}
+		if instance.version != step.Version || instance.pod.DeletionTimestamp != nil || !podutil.IsPodReady(instance.pod) {
+			pending = append(pending, instance)
+		}
+	}
+	if len(pending) == 0 {
+		return true, nil
+	}
+
+	switch step.Action {
+	case v1alpha1.VersionSkewRepairUpgradeStatefulSet:
+		// the StatefulSet is re-applied and upgraded by the member manager of the component once the step is
+		// started, see versionSkewUpgradeRequested
+	case v1alpha1.VersionSkewRepairRestartPods:
+		for _, instance := range pending {
+			if instance.pod.DeletionTimestamp != nil || instance.version == step.Version {
+				// wait for the restarted Pod to be ready
+				return false, nil
+			}
+		}
+		if err := m.restartVersionSkewedPod(tc, step.Component, pending[0].pod); err != nil {
+			if !controller.IsRequeueError(err) {
+				return false, err
+			}
+			klog.Infof("syncVersionSkew: %v", err)
+		}
+	}
+	if step.StartTime == nil {
+		now := metav1.Now()
+		step.StartTime = &now
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "VersionSkewRepairStepStarted",
+			"%s of %s to version %s is started", step.Action, step.Component, step.Version)
+	}
+	return false, nil
+}
+
+// restartVersionSkewedPod restarts the Pod gracefully as the upgraders do: the region leaders of a TiKV store
+// are evicted first, and the PD leader is transferred to another member before its Pod is deleted
+func (m *TidbClusterStatusManager) restartVersionSkewedPod(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	switch memberType {
+	case v1alpha1.TiKVMemberType:
+		return restartTiKVPod(m.deps, tc, pod, "repair the version skew")
+	case v1alpha1.PDMemberType:
+		if strings.Split(tc.Status.PD.Leader.Name, ".")[0] == pod.Name {
+			var targets []string
+			for name, member := range tc.Status.PD.Members {
+				if member.Health && strings.Split(name, ".")[0] != pod.Name {
+					targets = append(targets, name)
+				}
+			}
+			if len(targets) > 0 {
+				sort.Strings(targets)
+				if err := controller.GetPDClient(m.deps.PDControl, tc).TransferPDLeader(targets[0]); err != nil {
+					return fmt.Errorf("syncVersionSkew: failed to transfer pd leader of cluster %s/%s to %s, error: %s", ns, tcName, targets[0], err)
+				}
+				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd member: [%s] is transferring leader to pd member: [%s]", ns, tcName, pod.Name, targets[0])
+			}
+		}
+	}
+	if err := m.deps.PodControl.DeletePod(tc, pod); err != nil {
+		return fmt.Errorf("syncVersionSkew: failed to delete pod %s/%s, error: %s", pod.Namespace, pod.Name, err)
+	}
+	klog.Infof("pod %s/%s is restarted to repair the version skew of tc %s/%s", pod.Namespace, pod.Name, ns, tcName)
+	return nil
+}
+
+// versionSkewUpgradeRequested returns whether the approved plan to repair the version skew is upgrading the
+// StatefulSet of the component, and the template of the StatefulSet doesn't run the desired version, e.g. it's
+// modified out of the operator. The StatefulSet is then re-applied by the member manager even if it equals the
+// last applied one, and upgraded by the upgrader as usual.
+func versionSkewUpgradeRequested(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, set *apps.StatefulSet) bool {
+	status := tc.Status.VersionSkew
+	if status == nil || status.Plan == nil || !status.Plan.Approved {
+		return false
+	}
+	for _, step := range status.Plan.Steps {
+		if step.Component != memberType || step.Action != v1alpha1.VersionSkewRepairUpgradeStatefulSet ||
+			step.StartTime == nil || step.CompletionTime != nil {
+			continue
+		}
+		for _, container := range set.Spec.Template.Spec.Containers {
+			if container.Name == memberType.String() {
+				return imageVersion(container.Image) != step.Version
+			}
+		}
+	}
+	return false
+}
+
+// imageVersion returns the tag of the image, the digest is ignored
+func imageVersion(image string) string {
+	if i := strings.IndexByte(image, '@'); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndexByte(image, ':'); i >= 0 && !strings.Contains(image[i:], "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
@@ -0,0 +1,259 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncVersionSkew(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tsm := NewTidbClusterStatusManager(fakeDeps)
+	tc := newTidbClusterForPD()
+	tc.Spec.Version = "v5.1.0"
+	tc.Spec.PD.Image = ""
+	tc.Spec.PD.BaseImage = "pingcap/pd"
+	tc.Spec.TiKV.Image = ""
+	tc.Spec.TiKV.BaseImage = "pingcap/tikv"
+	tc.Spec.TiDB.BaseImage = "pingcap/tidb"
+	tc.Spec.VersionSkew = &v1alpha1.VersionSkewSpec{}
+
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	setIndexer := fakeDeps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer()
+	newPod := func(component string, ordinal int, version, revision string) *corev1.Pod {
+		labels := label.New().Instance(tc.GetInstanceName()).Component(component)
+		labels[apps.ControllerRevisionHashLabelKey] = revision
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("test-%s-%d", component, ordinal),
+				Namespace: tc.Namespace,
+				Labels:    labels.Labels(),
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: component, Image: fmt.Sprintf("pingcap/%s:%s", component, version)}}},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	for _, component := range []string{"pd", "tikv", "tidb"} {
+		set := &apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("test-%s", component),
+				Namespace:   tc.Namespace,
+				Annotations: map[string]string{LastAppliedConfigAnnotation: "{}"},
+			},
+			Spec: apps.StatefulSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: component, Image: fmt.Sprintf("pingcap/%s:v5.1.0", component)}}},
+				},
+			},
+			Status: apps.StatefulSetStatus{UpdateRevision: "rev-new"},
+		}
+		g.Expect(setIndexer.Add(set)).To(Succeed())
+	}
+	pods := []*corev1.Pod{
+		newPod("pd", 0, "v5.1.0", "rev-new"),
+		newPod("tikv", 0, "v5.1.0", "rev-new"),
+		// not updated by the StatefulSet
+		newPod("tikv", 1, "v5.0.3", "rev-old"),
+		// updated by the StatefulSet, but runs a newer version than TiKV
+		newPod("tidb", 0, "v5.2.0", "rev-new"),
+	}
+	for _, pod := range pods {
+		g.Expect(podIndexer.Add(pod)).To(Succeed())
+	}
+
+	// tolerated in the grace period
+	g.Expect(tsm.syncVersionSkew(tc)).To(Succeed())
+	g.Expect(tc.Status.VersionSkew).NotTo(BeNil())
+	g.Expect(tc.Status.VersionSkew.Instances).To(Equal([]v1alpha1.VersionSkewInstance{
+		{Component: v1alpha1.TiKVMemberType, Name: "test-tikv-1", Version: "v5.0.3", DesiredVersion: "v5.1.0"},
+		{Component: v1alpha1.TiDBMemberType, Name: "test-tidb-0", Version: "v5.2.0", DesiredVersion: "v5.1.0"},
+	}))
+	g.Expect(tc.Status.VersionSkew.Plan).To(BeNil())
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterVersionSkew)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal(utiltidbcluster.VersionSkewTolerated))
+	g.Expect(cond.Message).To(HavePrefix("pd: v5.1.0 (test-pd-0); tikv: v5.1.0 (test-tikv-0), v5.0.3 (test-tikv-1); tidb: v5.2.0 (test-tidb-0)"))
+
+	// reported and planned after the grace period
+	tc.Status.VersionSkew.DetectedTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	g.Expect(tsm.syncVersionSkew(tc)).To(Succeed())
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterVersionSkew)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(cond.Reason).To(Equal(utiltidbcluster.VersionSkewed))
+	plan := tc.Status.VersionSkew.Plan
+	g.Expect(plan).NotTo(BeNil())
+	g.Expect(plan.ID).NotTo(BeEmpty())
+	g.Expect(plan.Approved).To(BeFalse())
+	g.Expect(plan.Steps).To(Equal([]v1alpha1.VersionSkewRepairStep{
+		{Component: v1alpha1.TiKVMemberType, Action: v1alpha1.VersionSkewRepairUpgradeStatefulSet, Instances: []string{"test-tikv-1"}, Version: "v5.1.0"},
+		{Component: v1alpha1.TiDBMemberType, Action: v1alpha1.VersionSkewRepairRestartPods, Instances: []string{"test-tidb-0"}, Version: "v5.1.0"},
+	}))
+
+	// nothing is done until approved
+	set, err := fakeDeps.StatefulSetLister.StatefulSets(tc.Namespace).Get("test-tikv")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Annotations).To(HaveKey(LastAppliedConfigAnnotation))
+	g.Expect(tsm.syncVersionSkew(tc)).To(Succeed())
+	g.Expect(tc.Status.VersionSkew.Plan.ID).To(Equal(plan.ID))
+	g.Expect(tc.Status.VersionSkew.Plan.Steps[0].StartTime).To(BeNil())
+
+	// the upgrade of TiKV is started once approved, the StatefulSet is only re-applied by the member manager
+	// if its template doesn't run the desired version
+	g.Expect(versionSkewUpgradeRequested(tc, v1alpha1.TiKVMemberType, set)).To(BeFalse())
+	tc.Annotations = map[string]string{label.AnnRepairVersionSkew: plan.ID}
+	g.Expect(tsm.syncVersionSkew(tc)).To(Succeed())
+	plan = tc.Status.VersionSkew.Plan
+	g.Expect(plan.Approved).To(BeTrue())
+	g.Expect(plan.Steps[0].StartTime).NotTo(BeNil())
+	g.Expect(plan.Steps[0].CompletionTime).To(BeNil())
+	set, err = fakeDeps.StatefulSetLister.StatefulSets(tc.Namespace).Get("test-tikv")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Annotations).To(HaveKey(LastAppliedConfigAnnotation))
+	g.Expect(versionSkewUpgradeRequested(tc, v1alpha1.TiKVMemberType, set)).To(BeFalse())
+	g.Expect(versionSkewUpgradeRequested(tc, v1alpha1.TiDBMemberType, set)).To(BeFalse())
+	modified := set.DeepCopy()
+	modified.Spec.Template.Spec.Containers[0].Image = "pingcap/tikv:v5.0.3"
+	g.Expect(versionSkewUpgradeRequested(tc, v1alpha1.TiKVMemberType, modified)).To(BeTrue())
+
+	// the TiDB pod is restarted once TiKV is upgraded
+	g.Expect(podIndexer.Update(newPod("tikv", 1, "v5.1.0", "rev-new"))).To(Succeed())
+	g.Expect(tsm.syncVersionSkew(tc)).To(Succeed())
+	plan = tc.Status.VersionSkew.Plan
+	g.Expect(plan.Steps[0].CompletionTime).NotTo(BeNil())
+	g.Expect(plan.Steps[1].StartTime).NotTo(BeNil())
+	_, err = fakeDeps.PodLister.Pods(tc.Namespace).Get("test-tidb-0")
+	g.Expect(err).To(HaveOccurred())
+
+	// in sync once the TiDB pod is recreated
+	g.Expect(podIndexer.Add(newPod("tidb", 0, "v5.1.0", "rev-new"))).To(Succeed())
+	g.Expect(tsm.syncVersionSkew(tc)).To(Succeed())
+	g.Expect(tc.Status.VersionSkew).To(BeNil())
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterVersionSkew)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal(utiltidbcluster.VersionsInSync))
+}
+
+func TestRestartVersionSkewedPod(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	tsm := NewTidbClusterStatusManager(fakeDeps)
+	tc := newTidbClusterForPD()
+	pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+	var transferredTo string
+	pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		transferredTo = action.Name
+		return nil, nil
+	})
+	var evictStoreID uint64
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		evictStoreID = action.ID
+		return nil, nil
+	})
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	newPod := func(name string) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: tc.Namespace}}
+		g.Expect(podIndexer.Add(pod)).To(Succeed())
+		return pod
+	}
+
+	// the PD leader is transferred before its Pod is restarted
+	pd := newPod("test-pd-0")
+	tc.Status.PD.Leader = v1alpha1.PDMember{Name: "test-pd-0", Health: true}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+		"test-pd-0": {Name: "test-pd-0", Health: true},
+		"test-pd-1": {Name: "test-pd-1", Health: false},
+		"test-pd-2": {Name: "test-pd-2", Health: true},
+	}
+	err := tsm.restartVersionSkewedPod(tc, v1alpha1.PDMemberType, pd)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+	g.Expect(transferredTo).To(Equal("test-pd-2"))
+	_, err = fakeDeps.PodLister.Pods(tc.Namespace).Get("test-pd-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	tc.Status.PD.Leader = tc.Status.PD.Members["test-pd-2"]
+	g.Expect(tsm.restartVersionSkewedPod(tc, v1alpha1.PDMemberType, pd)).To(Succeed())
+	_, err = fakeDeps.PodLister.Pods(tc.Namespace).Get("test-pd-0")
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+	// the region leaders of the store are evicted before the TiKV Pod is restarted
+	tikv := newPod("test-tikv-0")
+	tc.Spec.TiKV.Replicas = 1
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+	}
+	err = tsm.restartVersionSkewedPod(tc, v1alpha1.TiKVMemberType, tikv)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+	g.Expect(evictStoreID).To(Equal(uint64(1)))
+	g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationRestart, v1alpha1.TiKVMemberType, "test-tikv-0")).NotTo(BeNil())
+	_, err = fakeDeps.PodLister.Pods(tc.Namespace).Get("test-tikv-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	store := tc.Status.TiKV.Stores["1"]
+	store.LeaderCount = 0
+	tc.Status.TiKV.Stores["1"] = store
+	err = tsm.restartVersionSkewedPod(tc, v1alpha1.TiKVMemberType, tikv)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+	_, err = fakeDeps.PodLister.Pods(tc.Namespace).Get("test-tikv-0")
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+}
+
+func TestIsVersionSkewed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	components := versionSkewComponents(tc)
+	newInstances := func(pd, tikv, tidb string) map[v1alpha1.MemberType][]instanceVersion {
+		return map[v1alpha1.MemberType][]instanceVersion{
+			v1alpha1.PDMemberType:   {{pod: &corev1.Pod{}, version: pd}},
+			v1alpha1.TiKVMemberType: {{pod: &corev1.Pod{}, version: tikv}},
+			v1alpha1.TiDBMemberType: {{pod: &corev1.Pod{}, version: tidb}},
+		}
+	}
+
+	g.Expect(isVersionSkewed(tc, components, newInstances("v5.1.0", "v5.1.2", "v5.1.1"))).To(BeFalse())
+	g.Expect(isVersionSkewed(tc, components, newInstances("v5.2.0", "v5.1.0", "v5.1.0"))).To(BeTrue())
+	g.Expect(isVersionSkewed(tc, components, newInstances("v6.0.0", "v5.4.0", "v5.4.0"))).To(BeTrue())
+	g.Expect(isVersionSkewed(tc, components, newInstances("nightly", "v5.1.0", "latest"))).To(BeFalse())
+
+	tc.Spec.VersionSkew = &v1alpha1.VersionSkewSpec{MaxMinorSkew: 1}
+	// upgrading in order
+	g.Expect(isVersionSkewed(tc, components, newInstances("v5.2.0", "v5.1.0", "v5.1.0"))).To(BeFalse())
+	g.Expect(isVersionSkewed(tc, components, newInstances("v5.1.0", "v5.2.0", "v5.1.0"))).To(BeTrue())
+	g.Expect(isVersionSkewed(tc, components, newInstances("v5.3.0", "v5.1.0", "v5.1.0"))).To(BeTrue())
+}
+
+func TestImageVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(imageVersion("pingcap/tikv:v5.1.0")).To(Equal("v5.1.0"))
+	g.Expect(imageVersion("localhost:5000/pingcap/tikv:v5.1.0")).To(Equal("v5.1.0"))
+	g.Expect(imageVersion("localhost:5000/pingcap/tikv")).To(Equal("latest"))
+	g.Expect(imageVersion("pingcap/tikv:v5.1.0@sha256:abcd")).To(Equal("v5.1.0"))
+}
@@ -0,0 +1,298 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// maxVolumeRepairHistory is the number of the volume repair records kept in status
+const maxVolumeRepairHistory = 10
+
+// TidbClusterVolumeRepairManager repairs the volumes of the PD, TiKV and TiFlash Pods that are unschedulable
+// because their PVs are bound to a node that no longer exists, e.g. the local PVs of a lost node
+type TidbClusterVolumeRepairManager struct {
+	deps *controller.Dependencies
+}
+
+// NewTidbClusterVolumeRepairManager returns a TidbClusterVolumeRepairManager
+func NewTidbClusterVolumeRepairManager(deps *controller.Dependencies) *TidbClusterVolumeRepairManager {
+	return &TidbClusterVolumeRepairManager{
+		deps: deps,
+	}
+}
+
+// Sync deletes the PVCs and the PVs bound to the lost node and the Pod, so that the StatefulSet recreates the Pod
+// with fresh storage on another node. The store or the member of the Pod is deleted from PD first, so that the
+// recreated Pod can rejoin the cluster once the failover moves the data away from the lost store. A PD member is
+// only deleted if the quorum is kept without it, and at most one in a sync. The repairs are recorded in status for
+// auditing.
+func (m *TidbClusterVolumeRepairManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if !isPVTopologyRepairEnabled(tc) || tc.Spec.Paused {
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return fmt.Errorf("volume repair: failed to create selector for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("volume repair: failed to list pods for cluster %s/%s, error: %s", ns, tcName, err)
+	}
+
+	pdRepaired := false
+	for _, pod := range pods {
+		l := label.Label(pod.Labels)
+		if !(l.IsPD() || l.IsTiKV() || l.IsTiFlash()) || pod.Spec.NodeName != "" || !isPodUnschedulable(pod) {
+			continue
+		}
+		// at most one PD member is repaired in a sync, the next one is repaired after the status is refreshed
+		if l.IsPD() && pdRepaired {
+			continue
+		}
+		repaired, err := m.repairPodVolumes(tc, pod)
+		if err != nil {
+			return err
+		}
+		if repaired && l.IsPD() {
+			pdRepaired = true
+		}
+	}
+	return nil
+}
+
+// repairPodVolumes deletes the PVCs and the PVs of the Pod if any of its PVs is bound to a lost node, it returns
+// whether the volumes are repaired
+func (m *TidbClusterVolumeRepairManager) repairPodVolumes(tc *v1alpha1.TidbCluster, pod *corev1.Pod) (bool, error) {
+	ns := tc.GetNamespace()
+	var (
+		lostNode string
+		pvcs     []*corev1.PersistentVolumeClaim
+		pvs      []*corev1.PersistentVolume
+	)
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim == nil || vol.PersistentVolumeClaim.ClaimName == "" {
+			continue
+		}
+		pvc, err := m.deps.PVCLister.PersistentVolumeClaims(ns).Get(vol.PersistentVolumeClaim.ClaimName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("volume repair: failed to get pvc %s of pod %s/%s, error: %s", vol.PersistentVolumeClaim.ClaimName, ns, pod.Name, err)
+		}
+		pvcs = append(pvcs, pvc)
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := m.deps.PVLister.Get(pvc.Spec.VolumeName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("volume repair: failed to get pv %s of pod %s/%s, error: %s", pvc.Spec.VolumeName, ns, pod.Name, err)
+		}
+		pvs = append(pvs, pv)
+		if lostNode != "" {
+			continue
+		}
+		node, lost, err := m.lostNodeOfPV(pv)
+		if err != nil {
+			return false, err
+		}
+		if lost {
+			lostNode = node
+		}
+	}
+	if lostNode == "" {
+		return false, nil
+	}
+	if label.Label(pod.Labels).IsPD() && !pdQuorumKeptWithout(tc, pod.Name) {
+		klog.Infof("volume repair: pd pod %s/%s is not repaired until the other healthy pd members form a majority without it", ns, pod.Name)
+		return false, nil
+	}
+
+	klog.Infof("volume repair: pod %s/%s is unschedulable as node %s of its pvs is lost, recreate its volumes", ns, pod.Name, lostNode)
+	if err := m.removeFromPD(tc, pod); err != nil {
+		return false, err
+	}
+	record := v1alpha1.VolumeRepairRecord{
+		PodName:    pod.Name,
+		NodeName:   lostNode,
+		RepairTime: metav1.Now(),
+	}
+	for _, pvc := range pvcs {
+		if err := m.deps.PVCControl.DeletePVC(tc, pvc); err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("volume repair: failed to delete pvc %s of pod %s/%s, error: %s", pvc.Name, ns, pod.Name, err)
+		}
+		record.PVCs = append(record.PVCs, pvc.Name)
+	}
+	for _, pv := range pvs {
+		if err := m.deps.PVControl.DeletePV(tc, pv); err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("volume repair: failed to delete pv %s of pod %s/%s, error: %s", pv.Name, ns, pod.Name, err)
+		}
+		record.PVs = append(record.PVs, pv.Name)
+	}
+	if err := m.deps.PodControl.DeletePod(tc, pod); err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("volume repair: failed to delete pod %s/%s, error: %s", ns, pod.Name, err)
+	}
+
+	history := append([]v1alpha1.VolumeRepairRecord{record}, tc.Status.VolumeRepairs...)
+	if len(history) > maxVolumeRepairHistory {
+		history = history[:maxVolumeRepairHistory]
+	}
+	tc.Status.VolumeRepairs = history
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "VolumeRepaired", "the volumes of pod %s bound to the lost node %s are deleted to recreate the pod", pod.Name, lostNode)
+	return true, nil
+}
+
+// lostNodeOfPV returns the node the PV is bound to by its node affinity if none of the nodes exists
+func (m *TidbClusterVolumeRepairManager) lostNodeOfPV(pv *corev1.PersistentVolume) (string, bool, error) {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return "", false, nil
+	}
+	var hostnames []string
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == corev1.LabelHostname && expr.Operator == corev1.NodeSelectorOpIn {
+				hostnames = append(hostnames, expr.Values...)
+			}
+		}
+	}
+	if len(hostnames) == 0 {
+		return "", false, nil
+	}
+	for _, hostname := range hostnames {
+		selector := labels.SelectorFromSet(labels.Set{corev1.LabelHostname: hostname})
+		nodes, err := m.deps.NodeLister.List(selector)
+		if err != nil {
+			return "", false, fmt.Errorf("volume repair: failed to list nodes of hostname %s, error: %s", hostname, err)
+		}
+		if len(nodes) > 0 {
+			return "", false, nil
+		}
+		// if the node is not found in cache, re-check from apiserver directly to make sure the node really not exist
+		nodeList, err := m.deps.KubeClientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return "", false, fmt.Errorf("volume repair: failed to list nodes of hostname %s, error: %s", hostname, err)
+		}
+		if len(nodeList.Items) > 0 {
+			return "", false, nil
+		}
+	}
+	return hostnames[0], true, nil
+}
+
+// removeFromPD deletes the store or the member of the Pod from PD, so that the Pod recreated with fresh storage
+// is not rejected as a duplicated store or member
+func (m *TidbClusterVolumeRepairManager) removeFromPD(tc *v1alpha1.TidbCluster, pod *corev1.Pod) error {
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	l := label.Label(pod.Labels)
+	if l.IsPD() {
+		if _, ok := tc.Status.PD.Members[pod.Name]; !ok {
+			return nil
+		}
+		if err := pdClient.DeleteMember(pod.Name); err != nil {
+			return fmt.Errorf("volume repair: failed to delete pd member %s of cluster %s/%s, error: %s", pod.Name, tc.GetNamespace(), tc.GetName(), err)
+		}
+		return nil
+	}
+
+	stores := tc.Status.TiKV.Stores
+	if l.IsTiFlash() {
+		stores = tc.Status.TiFlash.Stores
+	}
+	for id, store := range stores {
+		if store.PodName != pod.Name || store.State == v1alpha1.TiKVStateTombstone {
+			continue
+		}
+		storeID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := pdClient.DeleteStore(storeID); err != nil {
+			return fmt.Errorf("volume repair: failed to delete store %s of pod %s/%s, error: %s", id, tc.GetNamespace(), pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// pdQuorumKeptWithout returns whether the healthy PD members other than the given one form a majority of both the
+// members after it's deleted and the desired replicas, so that the member can be deleted without losing the quorum
+// even if the members repaired before haven't rejoined yet
+func pdQuorumKeptWithout(tc *v1alpha1.TidbCluster, podName string) bool {
+	if _, ok := tc.Status.PD.Members[podName]; !ok {
+		return true
+	}
+	total := len(tc.Status.PD.Members) + len(tc.Status.PD.PeerMembers) - 1
+	if tc.Spec.PD != nil {
+		if replicas := int(tc.Spec.PD.Replicas) + len(tc.Status.PD.PeerMembers); replicas > total {
+			total = replicas
+		}
+	}
+	healthy := 0
+	for name, member := range tc.Status.PD.Members {
+		if name != podName && member.Health {
+			healthy++
+		}
+	}
+	for _, member := range tc.Status.PD.PeerMembers {
+		if member.Health {
+			healthy++
+		}
+	}
+	return healthy*2 > total
+}
+
+// isPodUnschedulable returns whether the scheduler fails to find a node for the Pod
+func isPodUnschedulable(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled {
+			return cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable
+		}
+	}
+	return false
+}
+
+// isPVTopologyRepairEnabled returns whether the volumes bound to the lost nodes are repaired for the cluster
+func isPVTopologyRepairEnabled(tc *v1alpha1.TidbCluster) bool {
+	enabled := features.DefaultFeatureGate.Enabled(features.PVTopologyRepair)
+	return features.EnabledForCluster(tc.Spec.FeatureGates, features.PVTopologyRepair, enabled)
+}
+
+type FakeTidbClusterVolumeRepairManager struct {
+}
+
+func NewFakeTidbClusterVolumeRepairManager() *FakeTidbClusterVolumeRepairManager {
+	return &FakeTidbClusterVolumeRepairManager{}
+}
+
+func (f *FakeTidbClusterVolumeRepairManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTidbClusterVolumeRepairManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	m := NewTidbClusterVolumeRepairManager(fakeDeps)
+	tc := newTidbClusterForPD()
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateDown},
+		"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp},
+	}
+
+	var deletedStores []uint64
+	pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		deletedStores = append(deletedStores, action.ID)
+		return nil, nil
+	})
+
+	informers := fakeDeps.KubeInformerFactory.Core().V1()
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{corev1.LabelHostname: "node-1"}}}
+	g.Expect(informers.Nodes().Informer().GetIndexer().Add(node)).To(Succeed())
+	_, err := fakeDeps.KubeClientset.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// test-tikv-0 is bound to the lost node-0, and test-tikv-1 is bound to node-1 which is unschedulable
+	for i, nodeName := range []string{"node-0", "node-1"} {
+		podName := TikvPodName(tc.Name, int32(i))
+		pvcName := "tikv-" + podName
+		pvName := "local-pv-" + nodeName
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance(tc.GetInstanceName()).TiKV().Labels(),
+			},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{
+					Name:         "tikv",
+					VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName}},
+				}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type:   corev1.PodScheduled,
+					Status: corev1.ConditionFalse,
+					Reason: corev1.PodReasonUnschedulable,
+				}},
+			},
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: tc.Namespace},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: pvName},
+		}
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: pvName},
+			Spec: corev1.PersistentVolumeSpec{
+				NodeAffinity: &corev1.VolumeNodeAffinity{
+					Required: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      corev1.LabelHostname,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{nodeName},
+							}},
+						}},
+					},
+				},
+			},
+		}
+		g.Expect(informers.Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+		g.Expect(informers.PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)).To(Succeed())
+		g.Expect(informers.PersistentVolumes().Informer().GetIndexer().Add(pv)).To(Succeed())
+	}
+
+	// disabled by default
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.VolumeRepairs).To(BeEmpty())
+
+	tc.Spec.FeatureGates = map[string]bool{features.PVTopologyRepair: true}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.VolumeRepairs).To(HaveLen(1))
+	record := tc.Status.VolumeRepairs[0]
+	g.Expect(record.PodName).To(Equal("test-tikv-0"))
+	g.Expect(record.NodeName).To(Equal("node-0"))
+	g.Expect(record.PVCs).To(Equal([]string{"tikv-test-tikv-0"}))
+	g.Expect(record.PVs).To(Equal([]string{"local-pv-node-0"}))
+	g.Expect(deletedStores).To(Equal([]uint64{1}))
+
+	_, err = fakeDeps.PodLister.Pods(tc.Namespace).Get("test-tikv-0")
+	g.Expect(err).To(HaveOccurred())
+	_, err = fakeDeps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("tikv-test-tikv-0")
+	g.Expect(err).To(HaveOccurred())
+	_, err = fakeDeps.PVLister.Get("local-pv-node-0")
+	g.Expect(err).To(HaveOccurred())
+	_, err = fakeDeps.PodLister.Pods(tc.Namespace).Get("test-tikv-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = fakeDeps.PVLister.Get("local-pv-node-1")
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestTidbClusterVolumeRepairManagerSyncPD(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	m := NewTidbClusterVolumeRepairManager(fakeDeps)
+	tc := newTidbClusterForPD()
+	tc.Spec.FeatureGates = map[string]bool{features.PVTopologyRepair: true}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+		"test-pd-0": {Name: "test-pd-0", Health: false},
+		"test-pd-1": {Name: "test-pd-1", Health: false},
+		"test-pd-2": {Name: "test-pd-2", Health: true},
+	}
+
+	var deletedMembers []string
+	pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.DeleteMemberActionType, func(action *pdapi.Action) (interface{}, error) {
+		deletedMembers = append(deletedMembers, action.Name)
+		return nil, nil
+	})
+
+	// test-pd-0 and test-pd-1 are bound to the lost nodes
+	informers := fakeDeps.KubeInformerFactory.Core().V1()
+	for i := int32(0); i < 2; i++ {
+		podName := PdPodName(tc.Name, i)
+		pvcName := "pd-" + podName
+		pvName := "local-pv-" + podName
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance(tc.GetInstanceName()).PD().Labels(),
+			},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{
+					Name:         "pd",
+					VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName}},
+				}},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable}},
+			},
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: tc.Namespace},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: pvName},
+		}
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: pvName},
+			Spec: corev1.PersistentVolumeSpec{
+				NodeAffinity: &corev1.VolumeNodeAffinity{
+					Required: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      corev1.LabelHostname,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"lost-node-" + podName},
+							}},
+						}},
+					},
+				},
+			},
+		}
+		g.Expect(informers.Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+		g.Expect(informers.PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)).To(Succeed())
+		g.Expect(informers.PersistentVolumes().Informer().GetIndexer().Add(pv)).To(Succeed())
+	}
+
+	// no member is deleted as the only healthy member doesn't form a majority
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(deletedMembers).To(BeEmpty())
+	g.Expect(tc.Status.VolumeRepairs).To(BeEmpty())
+
+	// only one member is repaired in a sync, even if the status is stale
+	tc.Status.PD.Members["test-pd-0"] = v1alpha1.PDMember{Name: "test-pd-0", Health: true}
+	tc.Status.PD.Members["test-pd-1"] = v1alpha1.PDMember{Name: "test-pd-1", Health: true}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(deletedMembers).To(Equal([]string{"test-pd-0"}))
+	g.Expect(tc.Status.VolumeRepairs).To(HaveLen(1))
+	g.Expect(tc.Status.VolumeRepairs[0].PodName).To(Equal("test-pd-0"))
+
+	// the next member is not repaired until the repaired one rejoins
+	delete(tc.Status.PD.Members, "test-pd-0")
+	tc.Status.PD.Members["test-pd-1"] = v1alpha1.PDMember{Name: "test-pd-1", Health: false}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(deletedMembers).To(Equal([]string{"test-pd-0"}))
+
+	tc.Status.PD.Members["test-pd-0"] = v1alpha1.PDMember{Name: "test-pd-0", Health: true}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(deletedMembers).To(Equal([]string{"test-pd-0", "test-pd-1"}))
+	g.Expect(tc.Status.VolumeRepairs).To(HaveLen(2))
+}
@@ -60,6 +60,9 @@ func (f *tiflashFailover) Failover(tc *v1alpha1.TidbCluster) error {
 			// (before it enters into Offline/Tombstone state)
 			continue
 		}
+		if isPodOnCordonedNode(f.deps, tc, podName) {
+			continue
+		}
 		deadline := store.LastTransitionTime.Add(f.deps.CLIConfig.TiFlashFailoverPeriod)
 		exist := false
 		for _, failureStore := range tc.Status.TiFlash.FailureStores {
@@ -186,10 +186,17 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 	if setNotExist {
+		if tenantPolicyBlocksCreation(tc) {
+			klog.Infof("TidbCluster: %s/%s, the tenant policy is violated, skip creating the tiflash statefulset", tc.GetNamespace(), tc.GetName())
+			return nil
+		}
 		if !tc.PDIsAvailable() {
 			klog.Infof("TidbCluster: %s/%s, waiting for PD cluster running", ns, tcName)
 			return nil
 		}
+		if err := syncStorageProvisioning(m.deps, tc, v1alpha1.TiFlashMemberType, newSet); err != nil {
+			return err
+		}
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newSet)
 		if err != nil {
 			return err
@@ -206,6 +213,10 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 
+	if err := syncScaleOutResources(m.deps, tc, v1alpha1.TiFlashMemberType, oldSet, newSet); err != nil {
+		return err
+	}
+
 	// Scaling takes precedence over upgrading because:
 	// - if a tiflash fails in the upgrading, users may want to delete it or add
 	//   new replicas
@@ -223,6 +234,8 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 		}
 	}
 
+	syncTimezoneChange(m.deps, tc, v1alpha1.TiFlashMemberType, oldSet, newSet)
+
 	if !templateEqual(newSet, oldSet) || tc.Status.TiFlash.Phase == v1alpha1.UpgradePhase {
 		if err := m.upgrader.Upgrade(tc, oldSet, newSet); err != nil {
 			return err
@@ -233,7 +246,11 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 }
 
 func (m *tiflashMemberManager) syncConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
-	newCm, err := getTiFlashConfigMap(tc)
+	cfgTC, err := withTiFlashConfigMapRef(m.deps.UserConfigMapLister, tc)
+	if err != nil {
+		return nil, err
+	}
+	newCm, err := getTiFlashConfigMap(cfgTC, controller.RefPDClientPort(m.deps.TiDBClusterLister, tc))
 	if err != nil {
 		return nil, err
 	}
@@ -308,6 +325,7 @@ func getNewStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.St
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse storage request for tiflash.StorageClaims, tidbcluster %s/%s, error: %v", tc.Namespace, tc.Name, err)
 	}
+	setVolumeClaimCloudTags(tc, pvcs)
 	annoMount, annoVolume := annotationsMountVolume()
 	volMounts := []corev1.VolumeMount{
 		annoMount,
@@ -357,7 +375,7 @@ func getNewStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.St
 				privileged := true
 				initContainers = append(initContainers, corev1.Container{
 					Name:  "init",
-					Image: tc.HelperImage(),
+					Image: baseTiFlashSpec.HelperImage(),
 					Command: []string{
 						"sh",
 						"-c",
@@ -405,9 +423,9 @@ func getNewStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.St
 	if len(tc.Spec.ClusterDomain) > 0 {
 		var pdAddr string
 		if tc.IsTLSClusterEnabled() {
-			pdAddr = fmt.Sprintf("https://%s-pd:2379", tcName)
+			pdAddr = fmt.Sprintf("https://%s-pd:%d", tcName, tc.PDClientPort())
 		} else {
-			pdAddr = fmt.Sprintf("http://%s-pd:2379", tcName)
+			pdAddr = fmt.Sprintf("http://%s-pd:%d", tcName, tc.PDClientPort())
 		}
 		str := `pd_url="%s"
 set +e
@@ -428,7 +446,7 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 
 	initContainers = append(initContainers, corev1.Container{
 		Name:  "init",
-		Image: tc.HelperImage(),
+		Image: baseTiFlashSpec.HelperImage(),
 		Command: []string{
 			"sh",
 			"-c",
@@ -443,7 +461,7 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 	podLabels := util.CombineStringMap(stsLabels, baseTiFlashSpec.Labels())
 	podAnnotations := util.CombineStringMap(controller.AnnProm(8234), baseTiFlashSpec.Annotations())
 	podAnnotations = util.CombineStringMap(controller.AnnAdditionalProm("tiflash.proxy", 20292), podAnnotations)
-	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiFlashLabelVal)
+	stsAnnotations := getTidbClusterStsAnnotations(tc, label.TiFlashLabelVal)
 	capacity := controller.TiKVCapacity(tc.Spec.TiFlash.Limits)
 	headlessSvcName := controller.TiFlashPeerMemberName(tcName)
 
@@ -452,6 +470,10 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 		return nil, fmt.Errorf("get delete slots number of statefulset %s/%s failed, err:%v", ns, setName, err)
 	}
 
+	timezone := baseTiFlashSpec.Timezone()
+	if timezone == "" {
+		timezone = tc.Timezone()
+	}
 	env := []corev1.EnvVar{
 		{
 			Name: "NAMESPACE",
@@ -475,7 +497,7 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 		},
 		{
 			Name:  "TZ",
-			Value: tc.Timezone(),
+			Value: timezone,
 		},
 	}
 	tiflashContainer := corev1.Container{
@@ -543,6 +565,7 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 	}
 	podSpec.Containers = append([]corev1.Container{tiflashContainer}, containers...)
 	podSpec.Containers = append(podSpec.Containers, baseTiFlashSpec.AdditionalContainers()...)
+	addTimezoneData(baseTiFlashSpec, &podSpec, v1alpha1.TiFlashMemberType.String())
 	podSpec.ServiceAccountName = tc.Spec.TiFlash.ServiceAccount
 	if podSpec.ServiceAccountName == "" {
 		podSpec.ServiceAccountName = tc.Spec.ServiceAccount
@@ -606,8 +629,8 @@ func flashVolumeClaimTemplate(storageClaims []v1alpha1.StorageClaim) ([]corev1.P
 	return pvcs, nil
 }
 
-func getTiFlashConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
-	config := getTiFlashConfig(tc)
+func getTiFlashConfigMap(tc *v1alpha1.TidbCluster, refPDClientPort int32) (*corev1.ConfigMap, error) {
+	config := getTiFlashConfig(tc, refPDClientPort)
 
 	configText, err := config.Common.MarshalTOML()
 	if err != nil {
@@ -696,6 +719,7 @@ func (m *tiflashMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, s
 
 		if store.Store != nil {
 			if pattern.Match([]byte(store.Store.Address)) {
+				status.VolumeTopology = getVolumeTopology(m.deps, tc.Namespace, status.PodName)
 				stores[status.ID] = *status
 			} else if util.MatchLabelFromStoreLabels(store.Store.Labels, label.TiFlashLabelVal) {
 				peerStores[status.ID] = *status
@@ -771,8 +795,8 @@ func (m *tiflashMemberManager) setStoreLabelsForTiFlash(tc *v1alpha1.TidbCluster
 		return setCount, err
 	}
 
-	locationLabels := []string(config.Replication.LocationLabels)
-	if locationLabels == nil {
+	storeLabels := append([]string(config.Replication.LocationLabels), tc.Spec.TiFlash.StoreLabels...)
+	if storeLabels == nil {
 		return setCount, nil
 	}
 
@@ -798,7 +822,7 @@ func (m *tiflashMemberManager) setStoreLabelsForTiFlash(tc *v1alpha1.TidbCluster
 		}
 
 		nodeName := pod.Spec.NodeName
-		ls, err := getNodeLabels(m.deps.NodeLister, nodeName, locationLabels)
+		ls, err := getNodeLabels(m.deps.NodeLister, nodeName, storeLabels)
 		if err != nil || len(ls) == 0 {
 			klog.Warningf("node: [%s] has no node labels, skipping set store labels for Pod: [%s/%s]", nodeName, ns, podName)
 			continue
@@ -807,7 +831,9 @@ func (m *tiflashMemberManager) setStoreLabelsForTiFlash(tc *v1alpha1.TidbCluster
 		if !m.storeLabelsEqualNodeLabels(store.Store.Labels, ls) {
 			set, err := pdCli.SetStoreLabels(store.Store.Id, ls)
 			if err != nil {
-				klog.Warningf("failed to set pod: [%s/%s]'s store labels: %v", ns, podName, ls)
+				msg := fmt.Sprintf("failed to set labels %v for store (id: %d, pod: %s/%s): %v ",
+					ls, store.Store.Id, ns, podName, err)
+				m.deps.Recorder.Event(tc, corev1.EventTypeWarning, FailedSetStoreLabels, msg)
 				continue
 			}
 			if set {
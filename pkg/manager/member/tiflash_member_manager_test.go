@@ -274,9 +274,14 @@ func TestTiFlashMemberManagerSetStoreLabelsForTiFlash(t *testing.T) {
 		errExpectFn      func(*GomegaWithT, error)
 		setCount         int
 		labelSetFailed   bool
+		// topologyNode indicates that the node only has the well-known topology labels
+		topologyNode bool
+		storeLabels  []string
+		expectLabels map[string]string
 	}
 	testFn := func(test *testcase, t *testing.T) {
 		tc := newTidbClusterForPD()
+		tc.Spec.TiFlash = &v1alpha1.TiFlashSpec{StoreLabels: test.storeLabels}
 		pmm, _, _, pdClient, podIndexer, nodeIndexer := newFakeTiFlashMemberManager(tc)
 		pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
 			return &pdapi.PDConfigFromAPI{
@@ -307,6 +312,14 @@ func TestTiFlashMemberManagerSetStoreLabelsForTiFlash(t *testing.T) {
 					},
 				},
 			}
+			if test.topologyNode {
+				node.Labels = map[string]string{
+					corev1.LabelZoneRegionStable:        "region",
+					corev1.LabelZoneFailureDomainStable: "zone",
+					corev1.LabelHostname:                "host",
+					"disk":                              "ssd",
+				}
+			}
 			nodeIndexer.Add(node)
 		}
 		if test.hasPod {
@@ -327,6 +340,9 @@ func TestTiFlashMemberManagerSetStoreLabelsForTiFlash(t *testing.T) {
 			})
 		} else {
 			pdClient.AddReaction(pdapi.SetStoreLabelsActionType, func(action *pdapi.Action) (interface{}, error) {
+				if test.expectLabels != nil {
+					g.Expect(action.Labels).To(Equal(test.expectLabels))
+				}
 				return true, nil
 			})
 		}
@@ -572,6 +588,42 @@ func TestTiFlashMemberManagerSetStoreLabelsForTiFlash(t *testing.T) {
 			setCount:       1,
 			labelSetFailed: false,
 		},
+		{
+			name:             "set labels from the topology labels of the node and the additional store labels",
+			errWhenGetStores: false,
+			storeInfo: &pdapi.StoresInfo{
+				Stores: []*pdapi.StoreInfo{
+					{
+						Store: &pdapi.MetaStore{
+							Store: &metapb.Store{
+								Id:      333,
+								Address: fmt.Sprintf("%s-tiflash-1.%s-tiflash-peer.%s.svc:20160", "test", "test", "default"),
+							},
+							StateName: "Up",
+						},
+						Status: &pdapi.StoreStatus{
+							LeaderCount:     1,
+							LastHeartbeatTS: time.Now(),
+						},
+					},
+				},
+			},
+			hasNode:      true,
+			hasPod:       true,
+			topologyNode: true,
+			storeLabels:  []string{"disk"},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			setCount:       1,
+			labelSetFailed: false,
+			expectLabels: map[string]string{
+				"region": "region",
+				"zone":   "zone",
+				"host":   "host",
+				"disk":   "ssd",
+			},
+		},
 	}
 
 	for i := range tests {
@@ -130,6 +130,9 @@ func (u *tiflashUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Statefu
 					return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded TiFlash pod: [%s], store status is %s instead of Running", ns, tcName, podName, status)
 				}
 			}
+			if msg := checkUpgradedPodPacing(tc.BaseTiFlashSpec(), pod); msg != "" {
+				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded TiFlash pod: [%s] %s", ns, tcName, podName, msg)
+			}
 
 			continue
 		}
@@ -33,8 +33,8 @@ const (
 func buildTiFlashSidecarContainers(tc *v1alpha1.TidbCluster) ([]corev1.Container, error) {
 	spec := tc.Spec.TiFlash
 	config := spec.Config.DeepCopy()
-	image := tc.HelperImage()
-	pullPolicy := tc.HelperImagePullPolicy()
+	image := tc.BaseTiFlashSpec().HelperImage()
+	pullPolicy := tc.BaseTiFlashSpec().HelperImagePullPolicy()
 	var containers []corev1.Container
 	var resource corev1.ResourceRequirements
 	if spec.LogTailer != nil {
@@ -99,7 +99,9 @@ func buildSidecarContainer(name, path, image string,
 	}
 }
 
-func getTiFlashConfig(tc *v1alpha1.TidbCluster) *v1alpha1.TiFlashConfigWraper {
+// getTiFlashConfig returns the config of TiFlash, refPDClientPort is the PD client port of the cluster
+// referenced by tc and only used when tc has no local PD
+func getTiFlashConfig(tc *v1alpha1.TidbCluster, refPDClientPort int32) *v1alpha1.TiFlashConfigWraper {
 	config := tc.Spec.TiFlash.Config.DeepCopy()
 	if config == nil {
 		config = v1alpha1.NewTiFlashConfig()
@@ -108,6 +110,7 @@ func getTiFlashConfig(tc *v1alpha1.TidbCluster) *v1alpha1.TiFlashConfigWraper {
 	if config.Common == nil {
 		config.Common = v1alpha1.NewTiFlashCommonConfig()
 	}
+	applyConfigProfile(tc, v1alpha1.TiFlashMemberType, config.Common.GenericConfig)
 
 	if config.Common.Get("path") == nil {
 		var paths []string
@@ -128,6 +131,17 @@ func getTiFlashConfig(tc *v1alpha1.TidbCluster) *v1alpha1.TiFlashConfigWraper {
 		noLocalTiDB = true
 	}
 
+	// set the addresses of the local components with the customized ports, the addresses with the
+	// default ports are set by setTiFlashConfigDefault
+	if !noLocalTiDB && tc.Spec.TiDB != nil && tc.Spec.TiDB.StatusPort != nil {
+		config.Common.SetIfNil("flash.tidb_status_addr", fmt.Sprintf("%s.%s.svc:%d", controller.TiDBMemberName(tc.Name), tc.Namespace, tc.TiDBStatusPort()))
+	}
+	if !noLocalPD && len(tc.Spec.ClusterDomain) == 0 && tc.Spec.PD != nil && tc.Spec.PD.ClientPort != nil {
+		config.Common.SetIfNil("raft.pd_addr", fmt.Sprintf("%s.%s.svc:%d", controller.PDMemberName(tc.Name), tc.Namespace, tc.PDClientPort()))
+	}
+	if noLocalPD && len(tc.Spec.ClusterDomain) == 0 && refPDClientPort != v1alpha1.DefaultPDClientPort {
+		config.Common.SetIfNil("raft.pd_addr", fmt.Sprintf("%s.%s.svc%s:%d", controller.PDMemberName(ref.Name), ref.Namespace, controller.FormatClusterDomain(ref.ClusterDomain), refPDClientPort))
+	}
 	setTiFlashConfigDefault(config, ref, tc.Name, tc.Namespace, tc.Spec.ClusterDomain, noLocalPD, noLocalTiDB)
 
 	// Note the config of tiflash use "_" by convention, others(proxy) use "-".
@@ -809,7 +809,7 @@ func TestGetTiFlashConfig(t *testing.T) {
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewGomegaWithT(t)
-			config := getTiFlashConfig(&tt.tc)
+			config := getTiFlashConfig(&tt.tc, v1alpha1.DefaultPDClientPort)
 			flashConfig := &v1alpha1.TiFlashConfig{
 				CommonConfig: new(v1alpha1.CommonConfig),
 				ProxyConfig:  new(v1alpha1.ProxyConfig),
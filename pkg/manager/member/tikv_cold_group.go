@@ -0,0 +1,417 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// tikvColdStoreLimitPattern matches the addresses of the stores of the TiKV cold group
+	tikvColdStoreLimitPattern = `%s-tikv-cold-\d+\.%s-tikv-cold-peer\.%s\.svc%s\:\d+`
+
+	// the default placement rule of PD which places all the data not covered by other rules
+	defaultPlacementRuleGroupID = "pd"
+	defaultPlacementRuleID      = "default"
+
+	// PlacementRulesDisabled is the type of the condition of the TiKV cold group and the reason of the event
+	// recorded when the group can't be excluded from the default placement rule because the placement rules
+	// are disabled in PD
+	PlacementRulesDisabled = "PlacementRulesDisabled"
+)
+
+// syncTiKVColdGroup syncs the peer service and the StatefulSet of the TiKV cold group, and keeps the
+// stores of the group out of the default placement rule of PD, so that only the data pinned to the
+// group by the placement policies is placed on them.
+func (m *tikvMemberManager) syncTiKVColdGroup(tc *v1alpha1.TidbCluster) error {
+	if !tc.TiKVColdGroupEnabled() {
+		if tc.Spec.Paused {
+			return nil
+		}
+		return m.cleanTiKVColdPlacementRule(tc)
+	}
+
+	svc := SvcConfig{
+		Name:       "peer",
+		Port:       tc.TiKVPort(),
+		Headless:   true,
+		SvcLabel:   func(l label.Label) label.Label { return l.TiKVCold() },
+		MemberName: controller.TiKVColdPeerMemberName,
+	}
+	if err := m.syncServiceForTidbCluster(tc, svc); err != nil {
+		return err
+	}
+	if err := m.syncTiKVColdStatefulSet(tc); err != nil {
+		return err
+	}
+	if tc.Spec.Paused {
+		return nil
+	}
+	return m.syncTiKVColdPlacementRule(tc)
+}
+
+func (m *tikvMemberManager) syncTiKVColdStatefulSet(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	oldSetTmp, err := m.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiKVColdMemberName(tcName))
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("syncTiKVColdStatefulSet: failed to get sts %s for cluster %s/%s, error: %s", controller.TiKVColdMemberName(tcName), ns, tcName, err)
+	}
+	setNotExist := errors.IsNotFound(err)
+
+	oldSet := oldSetTmp.DeepCopy()
+
+	if err := m.syncTiKVGroupStatus(tc, oldSet, v1alpha1.TiKVColdMemberType); err != nil {
+		return err
+	}
+
+	if tc.Spec.Paused {
+		klog.V(4).Infof("tikv cluster %s/%s is paused, skip syncing for tikv cold statefulset", ns, tcName)
+		return nil
+	}
+
+	// the cold group shares the configuration of TiKV
+	cm, err := m.syncTiKVConfigMap(tc, oldSet)
+	if err != nil {
+		return err
+	}
+
+	newSet, err := getNewTiKVColdSetForTidbCluster(tc, cm)
+	if err != nil {
+		return err
+	}
+	if err := addDebugArtifactsSidecar(m.deps, tc, v1alpha1.TiKVMemberType, &newSet.Spec.Template.Spec); err != nil {
+		return err
+	}
+	if setNotExist {
+		if tenantPolicyBlocksCreation(tc) {
+			klog.Infof("TidbCluster: %s/%s, the tenant policy is violated, skip creating the tikv cold statefulset", tc.GetNamespace(), tc.GetName())
+			return nil
+		}
+		if err := syncTenantTiKVReplicas(m.deps, tc, v1alpha1.TiKVColdMemberType, nil, newSet); err != nil {
+			return err
+		}
+		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newSet)
+		if err != nil {
+			return err
+		}
+		err = m.deps.StatefulSetControl.CreateStatefulSet(tc, newSet)
+		if err != nil {
+			return err
+		}
+		tc.Status.TiKVCold.StatefulSet = &apps.StatefulSetStatus{}
+		return nil
+	}
+
+	if err := syncTenantTiKVReplicas(m.deps, tc, v1alpha1.TiKVColdMemberType, oldSet, newSet); err != nil {
+		return err
+	}
+
+	if err := syncScaleOutResources(m.deps, tc, v1alpha1.TiKVColdMemberType, oldSet, newSet); err != nil {
+		return err
+	}
+
+	if err := m.scaler.Scale(tc, oldSet, newSet); err != nil {
+		return err
+	}
+
+	if !templateEqual(newSet, oldSet) || tc.Status.TiKVCold.Phase == v1alpha1.UpgradePhase {
+		if err := m.upgrader.Upgrade(tc, oldSet, newSet); err != nil {
+			return err
+		}
+	}
+
+	return mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSet, oldSet)
+}
+
+// getNewTiKVColdSetForTidbCluster generates the StatefulSet of the TiKV cold group from the one of TiKV,
+// the Pods share the image and the configuration of TiKV, while the replicas, the storage, the scheduling
+// and the store labels come from the spec of the group.
+func getNewTiKVColdSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.StatefulSet, error) {
+	set, err := getNewTiKVSetForTidbCluster(tc, cm)
+	if err != nil {
+		return nil, err
+	}
+	spec := tc.Spec.TiKV.ColdGroup
+	tcName := tc.GetName()
+
+	stsLabels := label.New().Instance(tc.GetInstanceName()).TiKVCold()
+	set.Name = controller.TiKVColdMemberName(tcName)
+	set.Labels = stsLabels.Labels()
+	set.Annotations = getTidbClusterStsAnnotations(tc, label.TiKVColdLabelVal)
+	set.Spec.Selector = stsLabels.LabelSelector()
+	set.Spec.Template.Labels = util.CombineStringMap(stsLabels.Labels(), tc.BaseTiKVSpec().Labels())
+	set.Spec.Replicas = pointer.Int32Ptr(tc.TiKVColdStsDesiredReplicas())
+	if set.Spec.UpdateStrategy.RollingUpdate != nil {
+		set.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(tc.TiKVColdStsDesiredReplicas())
+	}
+	set.Spec.ServiceName = controller.TiKVColdPeerMemberName(tcName)
+
+	podSpec := &set.Spec.Template.Spec
+	if spec.NodeSelector != nil {
+		podSpec.NodeSelector = spec.NodeSelector
+	}
+	if spec.Tolerations != nil {
+		podSpec.Tolerations = spec.Tolerations
+	}
+
+	// the configuration patches of the Pods are only supported by TiKV
+	var vols []corev1.Volume
+	for _, vol := range podSpec.Volumes {
+		if vol.Name != "per-pod-config" {
+			vols = append(vols, vol)
+		}
+	}
+	podSpec.Volumes = vols
+
+	capacity := controller.TiKVCapacity(tc.Spec.TiKV.Limits)
+	if spec.Limits != nil {
+		capacity = controller.TiKVCapacity(spec.Limits)
+	}
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if c.Name != v1alpha1.TiKVMemberType.String() {
+			continue
+		}
+		var volMounts []corev1.VolumeMount
+		for _, volMount := range c.VolumeMounts {
+			if volMount.Name != "per-pod-config" {
+				volMounts = append(volMounts, volMount)
+			}
+		}
+		c.VolumeMounts = volMounts
+		if spec.Requests != nil || spec.Limits != nil {
+			c.Resources = controller.ContainerResource(spec.ResourceRequirements)
+		}
+		c.Env = util.AppendOverwriteEnv(c.Env, []corev1.EnvVar{
+			{
+				Name:  "HEADLESS_SERVICE_NAME",
+				Value: controller.TiKVColdPeerMemberName(tcName),
+			},
+			{
+				Name:  "CAPACITY",
+				Value: capacity,
+			},
+			{
+				Name:  "STORE_LABELS",
+				Value: formatStoreLabels(tc.TiKVColdStoreLabels()),
+			},
+		})
+	}
+
+	if _, ok := spec.Requests[corev1.ResourceStorage]; ok {
+		storageRequest, err := controller.ParseStorageRequest(spec.Requests)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse storage request for tikv cold group, tidbcluster %s/%s, error: %v", tc.Namespace, tc.Name, err)
+		}
+		set.Spec.VolumeClaimTemplates[0].Spec.Resources = storageRequest
+	}
+	if spec.StorageClassName != nil {
+		set.Spec.VolumeClaimTemplates[0].Spec.StorageClassName = spec.StorageClassName
+	}
+	return set, nil
+}
+
+// syncTiKVColdPlacementRule adds the label constraints excluding the stores of the TiKV cold group to
+// the default placement rule of PD if they are missing.
+func (m *tikvMemberManager) syncTiKVColdPlacementRule(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	pdCli := controller.GetPDClient(m.deps.PDControl, tc)
+	config, err := pdCli.GetConfig()
+	if err != nil {
+		return err
+	}
+	if config.Replication == nil || config.Replication.EnablePlacementRules == nil || !*config.Replication.EnablePlacementRules {
+		msg := fmt.Sprintf("placement rules are disabled in PD, the data not pinned by placement policies may be placed on the tikv cold group of %s/%s", ns, tcName)
+		// the warning is only recorded once the condition is set, instead of on every sync
+		if !meta.IsStatusConditionTrue(tc.Status.TiKVCold.Conditions, PlacementRulesDisabled) {
+			klog.Warning(msg)
+			m.deps.Recorder.Event(tc, corev1.EventTypeWarning, PlacementRulesDisabled, msg)
+		}
+		meta.SetStatusCondition(&tc.Status.TiKVCold.Conditions, metav1.Condition{
+			Type:    PlacementRulesDisabled,
+			Status:  metav1.ConditionTrue,
+			Reason:  PlacementRulesDisabled,
+			Message: msg,
+		})
+		return nil
+	}
+	if meta.FindStatusCondition(tc.Status.TiKVCold.Conditions, PlacementRulesDisabled) != nil {
+		meta.RemoveStatusCondition(&tc.Status.TiKVCold.Conditions, PlacementRulesDisabled)
+	}
+
+	rule, err := pdCli.GetPlacementRule(defaultPlacementRuleGroupID, defaultPlacementRuleID)
+	if err != nil {
+		return err
+	}
+	storeLabels := tc.TiKVColdStoreLabels()
+	keys := make([]string, 0, len(storeLabels))
+	for k := range storeLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	updated := false
+	for _, k := range keys {
+		if !hasNotInLabelConstraint(rule.LabelConstraints, k, storeLabels[k]) {
+			rule.LabelConstraints = append(rule.LabelConstraints, pdapi.LabelConstraint{
+				Key:    k,
+				Op:     "notIn",
+				Values: []string{storeLabels[k]},
+			})
+			updated = true
+		}
+	}
+	if updated {
+		if err := pdCli.SetPlacementRule(rule); err != nil {
+			return fmt.Errorf("syncTiKVColdPlacementRule: failed to set the default placement rule for cluster %s/%s, error: %v", ns, tcName, err)
+		}
+		klog.Infof("tikv cold group of %s/%s is excluded from the default placement rule, label constraints: %v", ns, tcName, rule.LabelConstraints)
+	}
+	// record the excluded labels, so that the label constraints can be removed once the cold group is disabled
+	tc.Status.TiKVCold.ExcludedStoreLabels = util.CopyStringMap(storeLabels)
+	return nil
+}
+
+// cleanTiKVColdPlacementRule removes the label constraints excluding the stores of the TiKV cold group from
+// the default placement rule of PD after the cold group is disabled, otherwise the regions stay excluded from
+// the stores with the same label values.
+func (m *tikvMemberManager) cleanTiKVColdPlacementRule(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	storeLabels := tc.Status.TiKVCold.ExcludedStoreLabels
+	if len(storeLabels) == 0 {
+		return nil
+	}
+
+	pdCli := controller.GetPDClient(m.deps.PDControl, tc)
+	config, err := pdCli.GetConfig()
+	if err != nil {
+		return err
+	}
+	if config.Replication != nil && config.Replication.EnablePlacementRules != nil && *config.Replication.EnablePlacementRules {
+		rule, err := pdCli.GetPlacementRule(defaultPlacementRuleGroupID, defaultPlacementRuleID)
+		if err != nil {
+			return err
+		}
+		updated := false
+		var constraints []pdapi.LabelConstraint
+		for _, c := range rule.LabelConstraints {
+			if value, ok := storeLabels[c.Key]; ok && c.Op == "notIn" {
+				var values []string
+				for _, v := range c.Values {
+					if v != value {
+						values = append(values, v)
+					}
+				}
+				if len(values) != len(c.Values) {
+					updated = true
+					if len(values) == 0 {
+						continue
+					}
+					c.Values = values
+				}
+			}
+			constraints = append(constraints, c)
+		}
+		if updated {
+			rule.LabelConstraints = constraints
+			if err := pdCli.SetPlacementRule(rule); err != nil {
+				return fmt.Errorf("cleanTiKVColdPlacementRule: failed to set the default placement rule for cluster %s/%s, error: %v", ns, tcName, err)
+			}
+			klog.Infof("tikv cold group of %s/%s is disabled, label constraints of the default placement rule: %v", ns, tcName, rule.LabelConstraints)
+		}
+	}
+	tc.Status.TiKVCold.ExcludedStoreLabels = nil
+	if meta.FindStatusCondition(tc.Status.TiKVCold.Conditions, PlacementRulesDisabled) != nil {
+		meta.RemoveStatusCondition(&tc.Status.TiKVCold.Conditions, PlacementRulesDisabled)
+	}
+	return nil
+}
+
+func hasNotInLabelConstraint(constraints []pdapi.LabelConstraint, key, value string) bool {
+	for _, c := range constraints {
+		if c.Key != key || c.Op != "notIn" {
+			continue
+		}
+		for _, v := range c.Values {
+			if v == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatStoreLabels formats the store labels as the value of the --labels flag of TiKV
+func formatStoreLabels(storeLabels map[string]string) string {
+	items := make([]string, 0, len(storeLabels))
+	for k, v := range storeLabels {
+		items = append(items, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// tikvGroupMemberType returns the member type of the TiKV group managed by the StatefulSet
+func tikvGroupMemberType(set *apps.StatefulSet) v1alpha1.MemberType {
+	if set != nil && set.Labels[label.ComponentLabelKey] == label.TiKVColdLabelVal {
+		return v1alpha1.TiKVColdMemberType
+	}
+	return v1alpha1.TiKVMemberType
+}
+
+// tikvGroupPodWebhookEnabled returns whether the stores of the TiKV group are deleted and evicted by the pod
+// admission webhook, which only handles the Pods of TiKV, so the TiKV cold group is always scaled in and
+// upgraded by the controller.
+func tikvGroupPodWebhookEnabled(deps *controller.Dependencies, memberType v1alpha1.MemberType) bool {
+	return deps.CLIConfig.PodWebhookEnabled && memberType == v1alpha1.TiKVMemberType
+}
+
+// tikvGroupStatus returns the status of the TiKV group of the member type
+func tikvGroupStatus(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) *v1alpha1.TiKVStatus {
+	if memberType == v1alpha1.TiKVColdMemberType {
+		return &tc.Status.TiKVCold
+	}
+	return &tc.Status.TiKV
+}
+
+// tikvGroupStorePattern returns the pattern matching the addresses of the stores of the TiKV group
+func tikvGroupStorePattern(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) (*regexp.Regexp, error) {
+	pattern := tikvStoreLimitPattern
+	if memberType == v1alpha1.TiKVColdMemberType {
+		pattern = tikvColdStoreLimitPattern
+	}
+	return regexp.Compile(fmt.Sprintf(pattern, tc.Name, tc.Name, tc.Namespace, controller.FormatClusterDomainForRegex(tc.Spec.ClusterDomain)))
+}
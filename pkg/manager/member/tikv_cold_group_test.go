@@ -0,0 +1,302 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetNewTiKVColdSetForTidbCluster(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV.ColdGroup = &v1alpha1.TiKVColdGroupSpec{
+		ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:     resource.MustParse("500m"),
+				corev1.ResourceStorage: resource.MustParse("1Ti"),
+			},
+		},
+		Replicas:         2,
+		StorageClassName: pointer.StringPtr("hdd"),
+		NodeSelector:     map[string]string{"disk": "hdd"},
+		StoreLabels:      map[string]string{"tier": "cold", "disk": "hdd"},
+	}
+
+	set, err := getNewTiKVColdSetForTidbCluster(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Name).To(Equal("test-tikv-cold"))
+	g.Expect(set.Labels[label.ComponentLabelKey]).To(Equal(label.TiKVColdLabelVal))
+	g.Expect(set.Spec.Selector.MatchLabels[label.ComponentLabelKey]).To(Equal(label.TiKVColdLabelVal))
+	g.Expect(set.Spec.Template.Labels[label.ComponentLabelKey]).To(Equal(label.TiKVColdLabelVal))
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(2)))
+	g.Expect(*set.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+	g.Expect(set.Spec.ServiceName).To(Equal("test-tikv-cold-peer"))
+	g.Expect(set.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"disk": "hdd"}))
+	g.Expect(set.Spec.VolumeClaimTemplates[0].Spec.StorageClassName).To(Equal(pointer.StringPtr("hdd")))
+	g.Expect(set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("1Ti")))
+
+	c := findContainerByName(set, v1alpha1.TiKVMemberType.String())
+	g.Expect(c).NotTo(BeNil())
+	g.Expect(c.Resources.Requests[corev1.ResourceCPU]).To(Equal(resource.MustParse("500m")))
+	env := map[string]string{}
+	for _, e := range c.Env {
+		env[e.Name] = e.Value
+	}
+	g.Expect(env["HEADLESS_SERVICE_NAME"]).To(Equal("test-tikv-cold-peer"))
+	g.Expect(env["STORE_LABELS"]).To(Equal("disk=hdd,tier=cold"))
+
+	// the StatefulSet of TiKV is not affected
+	primary, err := getNewTiKVSetForTidbCluster(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(primary.Name).To(Equal("test-tikv"))
+	g.Expect(*primary.Spec.Replicas).To(Equal(int32(3)))
+	g.Expect(primary.Spec.VolumeClaimTemplates[0].Spec.StorageClassName).To(Equal(pointer.StringPtr("my-storage-class")))
+}
+
+func TestSyncTiKVColdPlacementRule(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV.ColdGroup = &v1alpha1.TiKVColdGroupSpec{Replicas: 1}
+	tkmm, _, _, pdClient, _, _ := newFakeTiKVMemberManager(tc)
+
+	enabled := false
+	pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.PDConfigFromAPI{
+			Replication: &pdapi.PDReplicationConfig{EnablePlacementRules: &enabled},
+		}, nil
+	})
+	rule := &pdapi.PlacementRule{GroupID: "pd", ID: "default", Role: "voter", Count: 3}
+	pdClient.AddReaction(pdapi.GetPlacementRuleActionType, func(action *pdapi.Action) (interface{}, error) {
+		g.Expect(action.Name).To(Equal("pd/default"))
+		r := *rule
+		return &r, nil
+	})
+	var setRules []*pdapi.PlacementRule
+	pdClient.AddReaction(pdapi.SetPlacementRuleActionType, func(action *pdapi.Action) (interface{}, error) {
+		setRules = append(setRules, action.Rule)
+		rule = action.Rule
+		return nil, nil
+	})
+
+	// the default rule is not changed if the placement rules are disabled, and the warning is recorded once
+	g.Expect(tkmm.syncTiKVColdPlacementRule(tc)).To(Succeed())
+	g.Expect(tkmm.syncTiKVColdPlacementRule(tc)).To(Succeed())
+	g.Expect(setRules).To(BeEmpty())
+	g.Expect(meta.IsStatusConditionTrue(tc.Status.TiKVCold.Conditions, PlacementRulesDisabled)).To(BeTrue())
+	events := collectEvents(tkmm.deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0]).To(ContainSubstring(PlacementRulesDisabled))
+
+	enabled = true
+	g.Expect(tkmm.syncTiKVColdPlacementRule(tc)).To(Succeed())
+	g.Expect(setRules).To(HaveLen(1))
+	g.Expect(tc.Status.TiKVCold.Conditions).To(BeEmpty())
+	g.Expect(setRules[0].LabelConstraints).To(Equal([]pdapi.LabelConstraint{
+		{Key: "tier", Op: "notIn", Values: []string{"cold"}},
+	}))
+
+	// the rule is only updated once
+	g.Expect(tkmm.syncTiKVColdPlacementRule(tc)).To(Succeed())
+	g.Expect(setRules).To(HaveLen(1))
+	g.Expect(tc.Status.TiKVCold.ExcludedStoreLabels).To(Equal(map[string]string{"tier": "cold"}))
+
+	// the label constraints are removed once the cold group is disabled
+	tc.Spec.TiKV.ColdGroup = nil
+	g.Expect(tkmm.syncTiKVColdGroup(tc)).To(Succeed())
+	g.Expect(setRules).To(HaveLen(2))
+	g.Expect(setRules[1].LabelConstraints).To(BeEmpty())
+	g.Expect(tc.Status.TiKVCold.ExcludedStoreLabels).To(BeNil())
+
+	// the rule is only updated once
+	g.Expect(tkmm.syncTiKVColdGroup(tc)).To(Succeed())
+	g.Expect(setRules).To(HaveLen(2))
+}
+
+func TestSyncTiKVColdGroupStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV.ColdGroup = &v1alpha1.TiKVColdGroupSpec{Replicas: 1}
+	tkmm, _, _, pdClient, _, _ := newFakeTiKVMemberManager(tc)
+	pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoresInfo{
+			Stores: []*pdapi.StoreInfo{
+				{
+					Store: &pdapi.MetaStore{
+						Store:     &metapb.Store{Id: 1, Address: "test-tikv-0.test-tikv-peer.default.svc:20160"},
+						StateName: "Up",
+					},
+					Status: &pdapi.StoreStatus{},
+				},
+				{
+					Store: &pdapi.MetaStore{
+						Store: &metapb.Store{
+							Id:      2,
+							Address: "test-tikv-cold-0.test-tikv-cold-peer.default.svc:20160",
+							Labels:  []*metapb.StoreLabel{{Key: "tier", Value: "cold"}},
+						},
+						StateName: "Up",
+					},
+					Status: &pdapi.StoreStatus{},
+				},
+			},
+		}, nil
+	})
+	pdClient.AddReaction(pdapi.GetTombStoneStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoresInfo{}, nil
+	})
+
+	newSet := func(memberType v1alpha1.MemberType) *apps.StatefulSet {
+		set := &apps.StatefulSet{Spec: apps.StatefulSetSpec{Replicas: pointer.Int32Ptr(1)}}
+		set.Labels = label.New().Instance(tc.GetInstanceName()).Component(memberType.String()).Labels()
+		return set
+	}
+	g.Expect(tkmm.syncTiKVGroupStatus(tc, newSet(v1alpha1.TiKVMemberType), v1alpha1.TiKVMemberType)).To(Succeed())
+	g.Expect(tkmm.syncTiKVGroupStatus(tc, newSet(v1alpha1.TiKVColdMemberType), v1alpha1.TiKVColdMemberType)).To(Succeed())
+
+	g.Expect(tc.Status.TiKV.Stores).To(HaveLen(1))
+	g.Expect(tc.Status.TiKV.Stores).To(HaveKey("1"))
+	g.Expect(tc.Status.TiKV.PeerStores).To(BeEmpty())
+	g.Expect(tc.Status.TiKV.Phase).To(Equal(v1alpha1.ScalePhase))
+	g.Expect(tc.Status.TiKVCold.Stores).To(HaveLen(1))
+	g.Expect(tc.Status.TiKVCold.Stores["2"].PodName).To(Equal("test-tikv-cold-0"))
+	g.Expect(tc.Status.TiKVCold.Phase).To(Equal(v1alpha1.NormalPhase))
+	g.Expect(tc.Status.TiKVCold.Synced).To(BeTrue())
+}
+
+func TestTiKVColdGroupWithPodWebhookEnabled(t *testing.T) {
+	coldStores := func(tcName string, replicas int) map[string]v1alpha1.TiKVStore {
+		stores := map[string]v1alpha1.TiKVStore{}
+		for i := 0; i < replicas; i++ {
+			id := strconv.Itoa(20 + i)
+			stores[id] = v1alpha1.TiKVStore{
+				ID:          id,
+				PodName:     ordinalPodName(v1alpha1.TiKVColdMemberType, tcName, int32(i)),
+				LeaderCount: 10,
+				State:       v1alpha1.TiKVStateUp,
+			}
+		}
+		return stores
+	}
+	coldSet := func(tcName string, replicas int32) *apps.StatefulSet {
+		return &apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      controller.TiKVColdMemberName(tcName),
+				Namespace: metav1.NamespaceDefault,
+				Labels:    label.New().Instance(tcName).TiKVCold().Labels(),
+			},
+			Spec: apps.StatefulSetSpec{
+				Replicas: pointer.Int32Ptr(replicas),
+				UpdateStrategy: apps.StatefulSetUpdateStrategy{
+					Type:          apps.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &apps.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(replicas)},
+				},
+			},
+		}
+	}
+	coldPod := func(tcName string, ordinal int32, revision string) *corev1.Pod {
+		l := label.New().Instance(tcName).TiKVCold().Labels()
+		l[apps.ControllerRevisionHashLabelKey] = revision
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              ordinalPodName(v1alpha1.TiKVColdMemberType, tcName, ordinal),
+				Namespace:         metav1.NamespaceDefault,
+				Labels:            l,
+				CreationTimestamp: metav1.Time{Time: time.Now().Add(-time.Hour)},
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	t.Run("scale in", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		tc := newTidbClusterForPD()
+		tc.Spec.TiKV.ColdGroup = &v1alpha1.TiKVColdGroupSpec{Replicas: 1}
+		tc.Status.TiKVCold.Stores = coldStores(tc.Name, 2)
+
+		scaler, pdControl, _, podIndexer, _ := newFakeTiKVScaler()
+		scaler.deps.CLIConfig.PodWebhookEnabled = true
+		podIndexer.Add(coldPod(tc.Name, 1, "1"))
+		var deleted []uint64
+		controller.NewFakePDClient(pdControl, tc).AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+			deleted = append(deleted, action.ID)
+			return nil, nil
+		})
+
+		oldSet := coldSet(tc.Name, 2)
+		newSet := oldSet.DeepCopy()
+		newSet.Spec.Replicas = pointer.Int32Ptr(1)
+
+		// the store is deleted by the controller instead of the webhook, which doesn't handle the cold group
+		err := scaler.ScaleIn(tc, oldSet, newSet)
+		g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+		g.Expect(deleted).To(Equal([]uint64{21}))
+		g.Expect(*newSet.Spec.Replicas).To(Equal(int32(2)))
+		g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationScaleIn, v1alpha1.TiKVColdMemberType, coldPod(tc.Name, 1, "1").Name)).NotTo(BeNil())
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		tc := newTidbClusterForTiKVUpgrader()
+		tc.Spec.TiKV.ColdGroup = &v1alpha1.TiKVColdGroupSpec{Replicas: 3}
+		tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+		tc.Status.TiKVCold = *tc.Status.TiKV.DeepCopy()
+		tc.Status.TiKVCold.Phase = v1alpha1.UpgradePhase
+		tc.Status.TiKVCold.Stores = coldStores(tc.Name, 3)
+
+		upgrader, pdControl, _, podInformer, _ := newTiKVUpgrader()
+		upgrader.(*tikvUpgrader).deps.CLIConfig.PodWebhookEnabled = true
+		for i := int32(0); i < 3; i++ {
+			podInformer.Informer().GetIndexer().Add(coldPod(tc.Name, i, "1"))
+		}
+		var evicted []uint64
+		controller.NewFakePDClient(pdControl, tc).AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+			evicted = append(evicted, action.ID)
+			return nil, nil
+		})
+
+		oldSet := coldSet(tc.Name, 3)
+		oldSet.Status = *tc.Status.TiKVCold.StatefulSet
+		g.Expect(mngerutils.SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+		newSet := oldSet.DeepCopy()
+
+		// the leaders are evicted by the controller before the Pod is upgraded
+		g.Expect(upgrader.Upgrade(tc, oldSet, newSet)).To(Succeed())
+		g.Expect(evicted).To(Equal([]uint64{22}))
+		g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(3)))
+		g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVColdMemberType, coldPod(tc.Name, 2, "1").Name)).NotTo(BeNil())
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// tikvEncryptionKeyPath is where the master key of the encryption at rest is stored
+	tikvEncryptionKeyPath = "/var/lib/tikv-encryption-key"
+	// tikvPreviousEncryptionKeyPath is where the master key before the last rotation is stored
+	tikvPreviousEncryptionKeyPath = "/var/lib/tikv-previous-encryption-key"
+	// tikvEncryptionMasterKey is the key of the master key in the Secret
+	tikvEncryptionMasterKey = "master-key"
+	// defaultTiKVDataEncryptionMethod is the data encryption method if it's not configured, as TiKV
+	// doesn't encrypt the data by default
+	defaultTiKVDataEncryptionMethod = "aes256-ctr"
+)
+
+// syncTiKVEncryptionKey records the Secrets of the master keys TiKV is configured with in the status.
+// When the EncryptionKeySecret is changed, the Secret in use becomes the previous master key, so that TiKV
+// can decrypt the data keys with it and re-encrypt them with the new master key after restarting.
+func (m *tikvMemberManager) syncTiKVEncryptionKey(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	secretName := tc.Spec.EncryptionKeySecret
+	// the master key is never removed from the status, as TiKV can't read the encrypted data without it
+	if secretName == "" || secretName == tc.Status.TiKV.EncryptionKeySecret {
+		return nil
+	}
+
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(secretName)
+	if err != nil {
+		return fmt.Errorf("syncTiKVEncryptionKey: failed to get the encryption key secret %s for TidbCluster %s/%s, error: %v", secretName, ns, tcName, err)
+	}
+	if err := validateTiKVEncryptionKey(secret.Data[tikvEncryptionMasterKey]); err != nil {
+		return fmt.Errorf("syncTiKVEncryptionKey: invalid encryption key secret %s for TidbCluster %s/%s, error: %v", secretName, ns, tcName, err)
+	}
+
+	current := tc.Status.TiKV.EncryptionKeySecret
+	if current != "" {
+		// the Pods not restarted after the last rotation only have the previous master key
+		if tc.TiKVUpgrading() || tc.Status.TiKVCold.Phase == v1alpha1.UpgradePhase {
+			return controller.RequeueErrorf("TidbCluster: [%s/%s], waiting for TiKV upgrade to complete before rotating the encryption key", ns, tcName)
+		}
+		klog.Infof("TidbCluster: [%s/%s] rotates the encryption key from secret %s to %s", ns, tcName, current, secretName)
+	}
+	tc.Status.TiKV.PreviousEncryptionKeySecret = current
+	tc.Status.TiKV.EncryptionKeySecret = secretName
+	return nil
+}
+
+// validateTiKVEncryptionKey checks the master key is a 256-bit key encoded in hex
+func validateTiKVEncryptionKey(key []byte) error {
+	if len(key) == 0 {
+		return fmt.Errorf("key %s is missing", tikvEncryptionMasterKey)
+	}
+	data, err := hex.DecodeString(strings.TrimSpace(string(key)))
+	if err != nil {
+		return fmt.Errorf("key %s is not encoded in hex: %v", tikvEncryptionMasterKey, err)
+	}
+	if len(data) != 32 {
+		return fmt.Errorf("key %s has %d bits, but 256 bits are required", tikvEncryptionMasterKey, len(data)*8)
+	}
+	return nil
+}
+
+// setTiKVEncryptionConfig sets the master keys of the encryption at rest in the TiKV configuration
+func setTiKVEncryptionConfig(tc *v1alpha1.TidbCluster, cfg *config.GenericConfig) {
+	if tc.Status.TiKV.EncryptionKeySecret == "" {
+		return
+	}
+	cfg.SetIfNil("security.encryption.data-encryption-method", defaultTiKVDataEncryptionMethod)
+	cfg.Set("security.encryption.master-key.type", "file")
+	cfg.Set("security.encryption.master-key.path", path.Join(tikvEncryptionKeyPath, tikvEncryptionMasterKey))
+	if tc.Status.TiKV.PreviousEncryptionKeySecret != "" {
+		cfg.Set("security.encryption.previous-master-key.type", "file")
+		cfg.Set("security.encryption.previous-master-key.path", path.Join(tikvPreviousEncryptionKeyPath, tikvEncryptionMasterKey))
+	}
+}
+
+// getTiKVEncryptionVolumes returns the volumes and volume mounts of the Secrets of the master keys
+func getTiKVEncryptionVolumes(tc *v1alpha1.TidbCluster) ([]corev1.Volume, []corev1.VolumeMount) {
+	var vols []corev1.Volume
+	var volMounts []corev1.VolumeMount
+	add := func(name, secretName, mountPath string) {
+		if secretName == "" {
+			return
+		}
+		vols = append(vols, corev1.Volume{
+			Name: name, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretName,
+					Items:      []corev1.KeyToPath{{Key: tikvEncryptionMasterKey, Path: tikvEncryptionMasterKey}},
+				},
+			},
+		})
+		volMounts = append(volMounts, corev1.VolumeMount{
+			Name: name, ReadOnly: true, MountPath: mountPath,
+		})
+	}
+	add("tikv-encryption-key", tc.Status.TiKV.EncryptionKeySecret, tikvEncryptionKeyPath)
+	add("tikv-previous-encryption-key", tc.Status.TiKV.PreviousEncryptionKeySecret, tikvPreviousEncryptionKeyPath)
+	return vols, volMounts
+}
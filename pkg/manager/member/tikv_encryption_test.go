@@ -0,0 +1,124 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncTiKVEncryptionKey(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKV()
+	tmm, _, _, _, _, _ := newFakeTiKVMemberManager(tc)
+	secretIndexer := tmm.deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	addSecret := func(name, key string) {
+		g.Expect(secretIndexer.Add(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: tc.Namespace},
+			Data:       map[string][]byte{tikvEncryptionMasterKey: []byte(key)},
+		})).To(Succeed())
+	}
+	addSecret("key-1", strings.Repeat("a1", 32)+"\n")
+	addSecret("key-2", strings.Repeat("b2", 32))
+	addSecret("key-short", strings.Repeat("c3", 16))
+
+	// the encryption is not enabled
+	g.Expect(tmm.syncTiKVEncryptionKey(tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.EncryptionKeySecret).To(BeEmpty())
+
+	// the missing or invalid keys are not applied
+	tc.Spec.EncryptionKeySecret = "key-missing"
+	g.Expect(tmm.syncTiKVEncryptionKey(tc)).To(HaveOccurred())
+	tc.Spec.EncryptionKeySecret = "key-short"
+	g.Expect(tmm.syncTiKVEncryptionKey(tc)).To(HaveOccurred())
+	g.Expect(tc.Status.TiKV.EncryptionKeySecret).To(BeEmpty())
+
+	tc.Spec.EncryptionKeySecret = "key-1"
+	g.Expect(tmm.syncTiKVEncryptionKey(tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.EncryptionKeySecret).To(Equal("key-1"))
+	g.Expect(tc.Status.TiKV.PreviousEncryptionKeySecret).To(BeEmpty())
+
+	// the key is not rotated again until the last rotation is rolled out
+	tc.Spec.EncryptionKeySecret = "key-2"
+	tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+	err := tmm.syncTiKVEncryptionKey(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tc.Status.TiKV.EncryptionKeySecret).To(Equal("key-1"))
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	g.Expect(tmm.syncTiKVEncryptionKey(tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.EncryptionKeySecret).To(Equal("key-2"))
+	g.Expect(tc.Status.TiKV.PreviousEncryptionKeySecret).To(Equal("key-1"))
+
+	// the key in use is kept if the field is removed
+	tc.Spec.EncryptionKeySecret = ""
+	g.Expect(tmm.syncTiKVEncryptionKey(tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.EncryptionKeySecret).To(Equal("key-2"))
+}
+
+func TestTiKVEncryptionConfigAndVolumes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKV()
+	tc.Spec.TiKV.Config = v1alpha1.NewTiKVConfig()
+	cm, err := getTikVConfigMap(tc, v1alpha1.DefaultPDClientPort)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).NotTo(ContainSubstring("encryption"))
+	vols, volMounts := getTiKVEncryptionVolumes(tc)
+	g.Expect(vols).To(BeEmpty())
+	g.Expect(volMounts).To(BeEmpty())
+
+	tc.Status.TiKV.EncryptionKeySecret = "key-1"
+	cm, err = getTikVConfigMap(tc, v1alpha1.DefaultPDClientPort)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(`data-encryption-method = "aes256-ctr"`))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(`path = "/var/lib/tikv-encryption-key/master-key"`))
+	g.Expect(cm.Data["config-file"]).NotTo(ContainSubstring("previous-master-key"))
+
+	// the previous master key is configured after the rotation, the configured method is kept
+	tc.Spec.TiKV.Config = v1alpha1.NewTiKVConfig()
+	tc.Spec.TiKV.Config.Set("security.encryption.data-encryption-method", "sm4-ctr")
+	tc.Status.TiKV.EncryptionKeySecret = "key-2"
+	tc.Status.TiKV.PreviousEncryptionKeySecret = "key-1"
+	cm, err = getTikVConfigMap(tc, v1alpha1.DefaultPDClientPort)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(`data-encryption-method = "sm4-ctr"`))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring("[security.encryption.previous-master-key]"))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(`path = "/var/lib/tikv-previous-encryption-key/master-key"`))
+
+	set, err := getNewTiKVSetForTidbCluster(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	secrets := map[string]string{}
+	for _, vol := range set.Spec.Template.Spec.Volumes {
+		if vol.Secret != nil {
+			secrets[vol.Name] = vol.Secret.SecretName
+		}
+	}
+	g.Expect(secrets).To(Equal(map[string]string{
+		"tikv-encryption-key":          "key-2",
+		"tikv-previous-encryption-key": "key-1",
+	}))
+	mountPaths := map[string]string{}
+	for _, mount := range set.Spec.Template.Spec.Containers[0].VolumeMounts {
+		mountPaths[mount.Name] = mount.MountPath
+	}
+	g.Expect(mountPaths).To(HaveKeyWithValue("tikv-encryption-key", tikvEncryptionKeyPath))
+	g.Expect(mountPaths).To(HaveKeyWithValue("tikv-previous-encryption-key", tikvPreviousEncryptionKeyPath))
+}
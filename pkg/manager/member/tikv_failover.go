@@ -59,6 +59,9 @@ func (f *tikvFailover) Failover(tc *v1alpha1.TidbCluster) error {
 			// (before it enters into Offline/Tombstone state)
 			continue
 		}
+		if isPodOnCordonedNode(f.deps, tc, podName) {
+			continue
+		}
 		deadline := store.LastTransitionTime.Add(f.deps.CLIConfig.TiKVFailoverPeriod)
 		exist := false
 		for _, failureStore := range tc.Status.TiKV.FailureStores {
@@ -18,8 +18,10 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
@@ -302,3 +304,66 @@ func TestTiKVFailoverFailover(t *testing.T) {
 		})
 	}
 }
+
+func TestTiKVFailoverOnCordonedNode(t *testing.T) {
+	tests := []struct {
+		name                string
+		maintenance         bool
+		unschedulable       bool
+		expectFailureStores int
+	}{
+		{
+			name:                "not in maintenance",
+			maintenance:         false,
+			unschedulable:       true,
+			expectFailureStores: 1,
+		},
+		{
+			name:                "in maintenance and node is cordoned",
+			maintenance:         true,
+			unschedulable:       true,
+			expectFailureStores: 0,
+		},
+		{
+			name:                "in maintenance and node is not cordoned",
+			maintenance:         true,
+			unschedulable:       false,
+			expectFailureStores: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := newTidbClusterForPD()
+			tc.Spec.TiKV.MaxFailoverCount = pointer.Int32Ptr(3)
+			if tt.maintenance {
+				tc.Annotations = map[string]string{label.AnnMaintenanceKey: label.AnnMaintenanceNodeUpgradeVal}
+			}
+			tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+				"1": {
+					State:              v1alpha1.TiKVStateDown,
+					PodName:            "tikv-1",
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-70 * time.Minute)},
+				},
+			}
+
+			fakeDeps := controller.NewFakeDependencies()
+			fakeDeps.CLIConfig.TiKVFailoverPeriod = 1 * time.Hour
+			podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+			nodeIndexer := fakeDeps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+			podIndexer.Add(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "tikv-1", Namespace: tc.Namespace},
+				Spec:       corev1.PodSpec{NodeName: "node-1"},
+			})
+			nodeIndexer.Add(&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec:       corev1.NodeSpec{Unschedulable: tt.unschedulable},
+			})
+			tikvFailover := &tikvFailover{deps: fakeDeps}
+
+			err := tikvFailover.Failover(tc)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(len(tc.Status.TiKV.FailureStores)).To(Equal(tt.expectFailureStores))
+		})
+	}
+}
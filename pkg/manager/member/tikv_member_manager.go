@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -101,7 +102,7 @@ func (m *tikvMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	svcList := []SvcConfig{
 		{
 			Name:       "peer",
-			Port:       20160,
+			Port:       tc.TiKVPort(),
 			Headless:   true,
 			SvcLabel:   func(l label.Label) label.Label { return l.TiKV() },
 			MemberName: controller.TiKVPeerMemberName,
@@ -112,7 +113,13 @@ func (m *tikvMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 			return err
 		}
 	}
-	return m.syncStatefulSetForTidbCluster(tc)
+	if err := m.syncTiKVEncryptionKey(tc); err != nil {
+		return err
+	}
+	if err := m.syncStatefulSetForTidbCluster(tc); err != nil {
+		return err
+	}
+	return m.syncTiKVColdGroup(tc)
 }
 
 func (m *tikvMemberManager) syncServiceForTidbCluster(tc *v1alpha1.TidbCluster, svcConfig SvcConfig) error {
@@ -184,6 +191,9 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 	if err != nil {
 		return err
 	}
+	if err := m.syncTiKVPerPodConfigMap(tc); err != nil {
+		return err
+	}
 
 	// Recover failed stores if any before generating desired statefulset
 	if len(tc.Status.TiKV.FailureStores) > 0 {
@@ -191,15 +201,35 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 	}
 	if len(tc.Status.TiKV.FailureStores) > 0 &&
 		tc.Spec.TiKV.RecoverFailover &&
-		shouldRecover(tc, label.TiKVLabelVal, m.deps.PodLister) {
+		shouldRecover(tc, label.TiKVLabelVal, m.deps.PodLister) &&
+		!hasFailureOnTerminatingNode(m.deps, tc, tikvFailureStorePodNames(tc)) {
 		m.failover.Recover(tc)
 	}
 
+	// Evict the region leaders of the stores on the nodes going to be terminated, e.g. the
+	// reclaimed spot instances, and create the replacement Pods in advance if required
+	if err := syncTiKVSpotTermination(m.deps, tc); err != nil {
+		return err
+	}
+
 	newSet, err := getNewTiKVSetForTidbCluster(tc, cm)
 	if err != nil {
 		return err
 	}
+	if err := addDebugArtifactsSidecar(m.deps, tc, v1alpha1.TiKVMemberType, &newSet.Spec.Template.Spec); err != nil {
+		return err
+	}
 	if setNotExist {
+		if tenantPolicyBlocksCreation(tc) {
+			klog.Infof("TidbCluster: %s/%s, the tenant policy is violated, skip creating the tikv statefulset", tc.GetNamespace(), tc.GetName())
+			return nil
+		}
+		if err := syncTenantTiKVReplicas(m.deps, tc, v1alpha1.TiKVMemberType, nil, newSet); err != nil {
+			return err
+		}
+		if err := syncStorageProvisioning(m.deps, tc, v1alpha1.TiKVMemberType, newSet); err != nil {
+			return err
+		}
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newSet)
 		if err != nil {
 			return err
@@ -216,6 +246,19 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return err
 	}
 
+	if err := m.syncStoreSchedulingForTiKV(tc); err != nil {
+		return err
+	}
+
+	// Don't scale out beyond the max TiKV replicas of the tenant
+	if err := syncTenantTiKVReplicas(m.deps, tc, v1alpha1.TiKVMemberType, oldSet, newSet); err != nil {
+		return err
+	}
+
+	if err := syncScaleOutResources(m.deps, tc, v1alpha1.TiKVMemberType, oldSet, newSet); err != nil {
+		return err
+	}
+
 	// Scaling takes precedence over upgrading because:
 	// - if a store fails in the upgrading, users may want to delete it or add
 	//   new replicas
@@ -236,21 +279,40 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		}
 	}
 
-	if !templateEqual(newSet, oldSet) || tc.Status.TiKV.Phase == v1alpha1.UpgradePhase {
+	syncTimezoneChange(m.deps, tc, v1alpha1.TiKVMemberType, oldSet, newSet)
+
+	templateUpToDate := templateEqual(newSet, oldSet)
+	if !templateUpToDate || tc.Status.TiKV.Phase == v1alpha1.UpgradePhase {
 		if err := m.upgrader.Upgrade(tc, oldSet, newSet); err != nil {
 			return err
 		}
 	}
 
-	return mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSet, oldSet)
+	if versionSkewUpgradeRequested(tc, v1alpha1.TiKVMemberType, oldSet) {
+		if err := mngerutils.ForceUpdateStatefulSet(m.deps.StatefulSetControl, tc, newSet, oldSet); err != nil {
+			return err
+		}
+	} else if err := mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSet, oldSet); err != nil {
+		return err
+	}
+
+	// Restart the Pods whose configuration patch is changed once the StatefulSet is up to date
+	if !templateUpToDate {
+		return nil
+	}
+	return m.syncTiKVPerPodRestart(tc, oldSet)
 }
 
 func (m *tikvMemberManager) syncTiKVConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
-	// For backward compatibility, only sync tidb configmap when .tikv.config is non-nil
-	if tc.Spec.TiKV.Config == nil {
+	// For backward compatibility, only sync tidb configmap when .tikv.config or .tikv.configMapRef is non-nil
+	if tc.Spec.TiKV.Config == nil && tc.Spec.TiKV.ConfigMapRef == nil {
 		return nil, nil
 	}
-	newCm, err := getTikVConfigMap(tc)
+	cfgTC, err := withTiKVConfigMapRef(m.deps.UserConfigMapLister, tc)
+	if err != nil {
+		return nil, err
+	}
+	newCm, err := getTikVConfigMap(cfgTC, controller.RefPDClientPort(m.deps.TiDBClusterLister, cfgTC))
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +320,7 @@ func (m *tikvMemberManager) syncTiKVConfigMap(tc *v1alpha1.TidbCluster, set *app
 	var inUseName string
 	if set != nil {
 		inUseName = mngerutils.FindConfigMapVolume(&set.Spec.Template.Spec, func(name string) bool {
-			return strings.HasPrefix(name, controller.TiKVMemberName(tc.Name))
+			return strings.HasPrefix(name, controller.TiKVMemberName(tc.Name)) && name != controller.TiKVPerPodConfigMapName(tc.Name)
 		})
 	}
 
@@ -330,6 +392,11 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		{Name: "config", ReadOnly: true, MountPath: "/etc/tikv"},
 		{Name: "startup-script", ReadOnly: true, MountPath: "/usr/local/bin"},
 	}
+	if tikvPerPodConfigEnabled(tc) {
+		volMounts = append(volMounts, corev1.VolumeMount{
+			Name: "per-pod-config", ReadOnly: true, MountPath: tikvPerPodConfigVolumeMountPath,
+		})
+	}
 	volMounts = append(volMounts, tc.Spec.TiKV.AdditionalVolumeMounts...)
 	if tc.IsTLSClusterEnabled() {
 		volMounts = append(volMounts, corev1.VolumeMount{
@@ -361,6 +428,21 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 			}},
 		},
 	}
+	if tikvPerPodConfigEnabled(tc) {
+		// the ConfigMap is mounted without items, so that the configuration files of the Pods are
+		// added or updated without changing the Pod template
+		optional := true
+		vols = append(vols, corev1.Volume{
+			Name: "per-pod-config", VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: controller.TiKVPerPodConfigMapName(tc.Name),
+					},
+					Optional: &optional,
+				},
+			},
+		})
+	}
 	if tc.IsTLSClusterEnabled() {
 		vols = append(vols, corev1.Volume{
 			Name: "tikv-tls", VolumeSource: corev1.VolumeSource{
@@ -379,6 +461,9 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 			})
 		}
 	}
+	encryptionVols, encryptionVolMounts := getTiKVEncryptionVolumes(tc)
+	vols = append(vols, encryptionVols...)
+	volMounts = append(volMounts, encryptionVolMounts...)
 	// handle StorageVolumes and AdditionalVolumeMounts in ComponentSpec
 	storageVolMounts, additionalPVCs := util.BuildStorageVolumeAndVolumeMount(tc.Spec.TiKV.StorageVolumes, tc.Spec.TiKV.StorageClassName, v1alpha1.TiKVMemberType)
 	volMounts = append(volMounts, storageVolMounts...)
@@ -395,7 +480,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 				privileged := true
 				initContainers = append(initContainers, corev1.Container{
 					Name:  "init",
-					Image: tc.HelperImage(),
+					Image: baseTiKVSpec.HelperImage(),
 					Command: []string{
 						"sh",
 						"-c",
@@ -431,7 +516,8 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	podLabels := util.CombineStringMap(stsLabels.Labels(), baseTiKVSpec.Labels())
 	setName := controller.TiKVMemberName(tcName)
 	podAnnotations := util.CombineStringMap(controller.AnnProm(20180), baseTiKVSpec.Annotations())
-	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiKVLabelVal)
+	setStartScriptVersionAnnotation(podAnnotations, baseTiKVSpec.StartScriptVersion())
+	stsAnnotations := getTidbClusterStsAnnotations(tc, label.TiKVLabelVal)
 	capacity := controller.TiKVCapacity(tc.Spec.TiKV.Limits)
 	headlessSvcName := controller.TiKVPeerMemberName(tcName)
 
@@ -479,8 +565,8 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		// mount a shared volume and tail the RocksDB log to STDOUT using a sidecar.
 		containers = append(containers, corev1.Container{
 			Name:            v1alpha1.RocksDBLogTailerMemberType.String(),
-			Image:           tc.HelperImage(),
-			ImagePullPolicy: tc.HelperImagePullPolicy(),
+			Image:           baseTiKVSpec.HelperImage(),
+			ImagePullPolicy: baseTiKVSpec.HelperImagePullPolicy(),
 			Resources:       controller.ContainerResource(tc.Spec.TiKV.GetLogTailerSpec().ResourceRequirements),
 			VolumeMounts:    []corev1.VolumeMount{rocksDBLogVolumeMount},
 			Command: []string{
@@ -528,8 +614,8 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		// mount a shared volume and tail the Raft log to STDOUT using a sidecar.
 		containers = append(containers, corev1.Container{
 			Name:            v1alpha1.RaftLogTailerMemberType.String(),
-			Image:           tc.HelperImage(),
-			ImagePullPolicy: tc.HelperImagePullPolicy(),
+			Image:           baseTiKVSpec.HelperImage(),
+			ImagePullPolicy: baseTiKVSpec.HelperImagePullPolicy(),
 			Resources:       controller.ContainerResource(tc.Spec.TiKV.GetLogTailerSpec().ResourceRequirements),
 			VolumeMounts:    []corev1.VolumeMount{raftLogVolumeMount},
 			Command: []string{
@@ -563,7 +649,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		},
 		{
 			Name:  "TZ",
-			Value: tc.Spec.Timezone,
+			Value: baseTiKVSpec.Timezone(),
 		},
 	}
 	tikvContainer := corev1.Container{
@@ -577,7 +663,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "server",
-				ContainerPort: int32(20160),
+				ContainerPort: tc.TiKVPort(),
 				Protocol:      corev1.ProtocolTCP,
 			},
 		},
@@ -614,6 +700,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	podSpec.SecurityContext = podSecurityContext
 	podSpec.InitContainers = append(initContainers, baseTiKVSpec.InitContainers()...)
 	podSpec.Containers = append(containers, baseTiKVSpec.AdditionalContainers()...)
+	addTimezoneData(baseTiKVSpec, &podSpec, v1alpha1.TiKVMemberType.String())
 	podSpec.ServiceAccountName = tc.Spec.TiKV.ServiceAccount
 	if podSpec.ServiceAccountName == "" {
 		podSpec.ServiceAccountName = tc.Spec.ServiceAccount
@@ -657,6 +744,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	}
 
 	tikvset.Spec.VolumeClaimTemplates = append(tikvset.Spec.VolumeClaimTemplates, additionalPVCs...)
+	setVolumeClaimCloudTags(tc, tikvset.Spec.VolumeClaimTemplates)
 	return tikvset, nil
 }
 
@@ -701,7 +789,8 @@ func transformTiKVConfigMap(srcStr string, tc *v1alpha1.TidbCluster) string {
 	return srcStr
 }
 
-func getTikVConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
+// getTikVConfigMap returns the ConfigMap of TiKV, refPDClientPort is only used when tc has no local PD
+func getTikVConfigMap(tc *v1alpha1.TidbCluster, refPDClientPort int32) (*corev1.ConfigMap, error) {
 	config := tc.Spec.TiKV.Config
 	if config == nil {
 		return nil, nil
@@ -711,6 +800,9 @@ func getTikVConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 		EnableAdvertiseStatusAddr: false,
 		DataDir:                   filepath.Join(tikvDataVolumeMountPath, tc.Spec.TiKV.DataSubDir),
 		ClusterDomain:             tc.Spec.ClusterDomain,
+		Port:                      tc.TiKVPort(),
+		StartScriptVersion:        tc.BaseTiKVSpec().StartScriptVersion(),
+		EnablePerPodConfig:        tikvPerPodConfigEnabled(tc),
 	}
 	if tc.Spec.EnableDynamicConfiguration != nil && *tc.Spec.EnableDynamicConfiguration {
 		scriptModel.AdvertiseStatusAddr = "${POD_NAME}.${HEADLESS_SERVICE_NAME}.${NAMESPACE}.svc" + controller.FormatClusterDomain(tc.Spec.ClusterDomain)
@@ -719,9 +811,9 @@ func getTikVConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 
 	if tc.HeterogeneousWithoutLocalPD() {
 		// TODO: for across k8s cluster, the start script do not support it now.
-		scriptModel.PDAddress = tc.Scheme() + "://" + controller.PDMemberName(tc.Spec.Cluster.Name) + ":2379"
+		scriptModel.PDAddress = fmt.Sprintf("%s://%s:%d", tc.Scheme(), controller.PDMemberName(tc.Spec.Cluster.Name), refPDClientPort)
 	} else {
-		scriptModel.PDAddress = tc.Scheme() + "://${CLUSTER_NAME}-pd:2379"
+		scriptModel.PDAddress = fmt.Sprintf("%s://${CLUSTER_NAME}-pd:%d", tc.Scheme(), tc.PDClientPort())
 	}
 	cm, err := getTikVConfigMapForTiKVSpec(tc.Spec.TiKV, tc, scriptModel)
 	if err != nil {
@@ -745,34 +837,52 @@ func labelTiKV(tc *v1alpha1.TidbCluster) label.Label {
 }
 
 func (m *tikvMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
+	return m.syncTiKVGroupStatus(tc, set, v1alpha1.TiKVMemberType)
+}
+
+// syncTiKVGroupStatus syncs the status of the TiKV group of the member type, i.e. TiKV or the TiKV cold group
+func (m *tikvMemberManager) syncTiKVGroupStatus(tc *v1alpha1.TidbCluster, set *apps.StatefulSet, memberType v1alpha1.MemberType) error {
 	if set == nil {
 		// skip if not created yet
 		return nil
 	}
-	tc.Status.TiKV.StatefulSet = &set.Status
+	status := tikvGroupStatus(tc, memberType)
+	status.StatefulSet = &set.Status
+	status.StartScriptVersion = getStartScriptVersion(set)
 	upgrading, err := m.statefulSetIsUpgradingFn(m.deps.PodLister, m.deps.PDControl, set, tc)
 	if err != nil {
 		return err
 	}
 
 	// If phase changes from UpgradePhase to NormalPhase, try to endEvictLeader for the last store.
-	if !upgrading && tc.Status.TiKV.Phase == v1alpha1.UpgradePhase {
-		if err = endEvictLeader(m.deps, tc, helper.GetMinPodOrdinal(*set.Spec.Replicas, set)); err != nil {
+	if !upgrading && status.Phase == v1alpha1.UpgradePhase {
+		if err = endEvictLeader(m.deps, tc, memberType, helper.GetMinPodOrdinal(*set.Spec.Replicas, set)); err != nil {
 			return err
 		}
 	}
+	if !upgrading {
+		// the upgrade operations end with the upgrade, the one of the last store is never removed by the upgrader
+		tc.RemoveInFlightOperations(v1alpha1.InFlightOperationUpgrade, memberType)
+	}
 
+	desiredReplicas := tc.TiKVStsDesiredReplicas()
+	if memberType == v1alpha1.TiKVColdMemberType {
+		desiredReplicas = tc.TiKVColdStsDesiredReplicas()
+	}
 	// Scaling takes precedence over upgrading.
-	if tc.TiKVStsDesiredReplicas() != *set.Spec.Replicas {
-		tc.Status.TiKV.Phase = v1alpha1.ScalePhase
+	if desiredReplicas != *set.Spec.Replicas {
+		status.Phase = v1alpha1.ScalePhase
 	} else if upgrading && tc.Status.PD.Phase != v1alpha1.UpgradePhase {
-		tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+		status.Phase = v1alpha1.UpgradePhase
 	} else {
-		tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+		status.Phase = v1alpha1.NormalPhase
+	}
+	if status.Phase != v1alpha1.UpgradePhase {
+		clearRollingUpdateProgress(&status.RollingUpdate, &status.Conditions)
 	}
 
-	previousStores := tc.Status.TiKV.Stores
-	previousPeerStores := tc.Status.TiKV.PeerStores
+	previousStores := status.Stores
+	previousPeerStores := status.PeerStores
 	stores := map[string]v1alpha1.TiKVStore{}
 	peerStores := map[string]v1alpha1.TiKVStore{}
 	tombstoneStores := map[string]v1alpha1.TiKVStore{}
@@ -783,41 +893,47 @@ func (m *tikvMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 	if err != nil {
 		if pdapi.IsTiKVNotBootstrappedError(err) {
 			klog.Infof("TiKV of Cluster %s/%s not bootstrapped yet", tc.Namespace, tc.Name)
-			tc.Status.TiKV.Synced = true
-			tc.Status.TiKV.BootStrapped = false
+			status.Synced = true
+			status.BootStrapped = false
 			return nil
 		}
-		tc.Status.TiKV.Synced = false
+		status.Synced = false
 		return err
 	}
 
-	pattern, err := regexp.Compile(fmt.Sprintf(tikvStoreLimitPattern, tc.Name, tc.Name, tc.Namespace, controller.FormatClusterDomainForRegex(tc.Spec.ClusterDomain)))
+	pattern, err := tikvGroupStorePattern(tc, memberType)
+	if err != nil {
+		return err
+	}
+	coldPattern, err := tikvGroupStorePattern(tc, v1alpha1.TiKVColdMemberType)
 	if err != nil {
 		return err
 	}
 	for _, store := range storesInfo.Stores {
-		status := getTiKVStore(store)
-		if status == nil {
+		storeStatus := getTiKVStore(store)
+		if storeStatus == nil {
 			continue
 		}
 
-		oldStore, exist := previousStores[status.ID]
+		oldStore, exist := previousStores[storeStatus.ID]
 		if !exist {
-			oldStore, exist = previousPeerStores[status.ID]
+			oldStore, exist = previousPeerStores[storeStatus.ID]
 		}
 
-		status.LastTransitionTime = metav1.Now()
-		if exist && status.State == oldStore.State {
-			status.LastTransitionTime = oldStore.LastTransitionTime
+		storeStatus.LastTransitionTime = metav1.Now()
+		if exist && storeStatus.State == oldStore.State {
+			storeStatus.LastTransitionTime = oldStore.LastTransitionTime
 		}
 
 		// In theory, the external tikv can join the cluster, and the operator would only manage the internal tikv.
-		// So we check the store owner to make sure it.
+		// So we check the store owner to make sure it. The stores of the TiKV cold group are not peer stores of TiKV.
 		if store.Store != nil {
 			if pattern.Match([]byte(store.Store.Address)) {
-				stores[status.ID] = *status
-			} else if util.MatchLabelFromStoreLabels(store.Store.Labels, label.TiKVLabelVal) {
-				peerStores[status.ID] = *status
+				storeStatus.VolumeTopology = getVolumeTopology(m.deps, tc.Namespace, storeStatus.PodName)
+				stores[storeStatus.ID] = *storeStatus
+			} else if memberType == v1alpha1.TiKVMemberType && !coldPattern.Match([]byte(store.Store.Address)) &&
+				util.MatchLabelFromStoreLabels(store.Store.Labels, label.TiKVLabelVal) {
+				peerStores[storeStatus.ID] = *storeStatus
 			}
 		}
 	}
@@ -825,33 +941,68 @@ func (m *tikvMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 	//this returns all tombstone stores
 	tombstoneStoresInfo, err := pdCli.GetTombStoneStores()
 	if err != nil {
-		tc.Status.TiKV.Synced = false
+		status.Synced = false
 		return err
 	}
 	for _, store := range tombstoneStoresInfo.Stores {
 		if store.Store != nil && !pattern.Match([]byte(store.Store.Address)) {
 			continue
 		}
-		status := getTiKVStore(store)
-		if status == nil {
+		storeStatus := getTiKVStore(store)
+		if storeStatus == nil {
 			continue
 		}
-		tombstoneStores[status.ID] = *status
+		tombstoneStores[storeStatus.ID] = *storeStatus
 	}
 
-	tc.Status.TiKV.Synced = true
-	tc.Status.TiKV.Stores = stores
-	tc.Status.TiKV.PeerStores = peerStores
-	tc.Status.TiKV.TombstoneStores = tombstoneStores
-	tc.Status.TiKV.BootStrapped = true
-	tc.Status.TiKV.Image = ""
+	status.Synced = true
+	status.Stores = stores
+	status.PeerStores = peerStores
+	status.TombstoneStores = tombstoneStores
+	status.BootStrapped = true
+	status.Image = ""
 	c := findContainerByName(set, "tikv")
 	if c != nil {
-		tc.Status.TiKV.Image = c.Image
+		status.Image = c.Image
+	}
+	if memberType == v1alpha1.TiKVMemberType {
+		syncRegionHealth(pdCli, tc)
 	}
 	return nil
 }
 
+// syncRegionHealth refreshes the summary of the region health of the cluster periodically, and in every sync
+// during the upgrade of TiKV as the upgrade waits for the regions with down peers to recover. The failure of
+// the refresh is only logged and the previous summary is kept.
+func syncRegionHealth(pdCli pdapi.PDClient, tc *v1alpha1.TidbCluster) {
+	interval := tc.TiKVRegionHealthRefreshInterval()
+	if interval <= 0 {
+		tc.Status.TiKV.RegionHealth = nil
+		return
+	}
+	now := time.Now()
+	health := tc.Status.TiKV.RegionHealth
+	if health != nil && tc.Status.TiKV.Phase != v1alpha1.UpgradePhase && now.Sub(health.LastRefreshTime.Time) < interval {
+		return
+	}
+
+	counts := map[pdapi.RegionCheck]int32{}
+	for _, check := range []pdapi.RegionCheck{pdapi.RegionCheckMissPeer, pdapi.RegionCheckDownPeer, pdapi.RegionCheckPendingPeer} {
+		regions, err := pdCli.GetRegionsCheck(check)
+		if err != nil {
+			klog.Warningf("failed to get the %s regions of TidbCluster %s/%s: %v", check, tc.Namespace, tc.Name, err)
+			return
+		}
+		counts[check] = int32(regions.Count)
+	}
+	tc.Status.TiKV.RegionHealth = &v1alpha1.RegionHealthStatus{
+		MissPeerRegionCount:    counts[pdapi.RegionCheckMissPeer],
+		DownPeerRegionCount:    counts[pdapi.RegionCheckDownPeer],
+		PendingPeerRegionCount: counts[pdapi.RegionCheckPendingPeer],
+		LastRefreshTime:        metav1.NewTime(now),
+	}
+}
+
 func getTiKVStore(store *pdapi.StoreInfo) *v1alpha1.TiKVStore {
 	if store.Store == nil || store.Status == nil {
 		return nil
@@ -904,10 +1055,14 @@ func (m *tikvMemberManager) setStoreLabelsForTiKV(tc *v1alpha1.TidbCluster) (int
 	if err != nil {
 		return -1, err
 	}
+	coldPattern, err := tikvGroupStorePattern(tc, v1alpha1.TiKVColdMemberType)
+	if err != nil {
+		return -1, err
+	}
 	for _, store := range storesInfo.Stores {
 		// In theory, the external tikv can join the cluster, and the operator would only manage the internal tikv.
 		// So we check the store owner to make sure it.
-		if store.Store != nil && !pattern.Match([]byte(store.Store.Address)) {
+		if store.Store != nil && !pattern.Match([]byte(store.Store.Address)) && !coldPattern.Match([]byte(store.Store.Address)) {
 			continue
 		}
 		status := getTiKVStore(store)
@@ -965,7 +1120,8 @@ func tikvStatefulSetIsUpgrading(podLister corelisters.PodLister, pdControl pdapi
 		return true, nil
 	}
 	instanceName := tc.GetInstanceName()
-	selector, err := label.New().Instance(instanceName).TiKV().Selector()
+	memberType := tikvGroupMemberType(set)
+	selector, err := label.New().Instance(instanceName).Component(memberType.String()).Selector()
 	if err != nil {
 		return false, err
 	}
@@ -978,7 +1134,7 @@ func tikvStatefulSetIsUpgrading(podLister corelisters.PodLister, pdControl pdapi
 		if !exist {
 			return false, nil
 		}
-		if revisionHash != tc.Status.TiKV.StatefulSet.UpdateRevision {
+		if revisionHash != tikvGroupStatus(tc, memberType).StatefulSet.UpdateRevision {
 			return true, nil
 		}
 	}
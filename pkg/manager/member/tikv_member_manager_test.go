@@ -932,6 +932,45 @@ func TestTiKVMemberManagerSyncTidbClusterStatus(t *testing.T) {
 				g.Expect(tc.Status.TiKV.Phase).To(Equal(v1alpha1.NormalPhase))
 			},
 		},
+		{
+			name: "statefulset is upgrading with the upgrade operations",
+			updateTC: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.SetInFlightOperation(v1alpha1.InFlightOperation{Type: v1alpha1.InFlightOperationUpgrade, Component: v1alpha1.TiKVMemberType, PodName: "test-tikv-0", StoreID: "1"})
+			},
+			upgradingFn: func(lister corelisters.PodLister, controlInterface pdapi.PDControlInterface, set *apps.StatefulSet, cluster *v1alpha1.TidbCluster) (bool, error) {
+				return true, nil
+			},
+			tcExpectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Status.TiKV.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVMemberType, "test-tikv-0")).NotTo(BeNil())
+			},
+		},
+		{
+			name: "statefulset finishes upgrading with the upgrade operations",
+			updateTC: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				for _, op := range []v1alpha1.InFlightOperation{
+					{Type: v1alpha1.InFlightOperationUpgrade, Component: v1alpha1.TiKVMemberType, PodName: "test-tikv-0", StoreID: "1"},
+					{Type: v1alpha1.InFlightOperationUpgrade, Component: v1alpha1.TiKVMemberType, PodName: "test-tikv-1", StoreID: "2"},
+					{Type: v1alpha1.InFlightOperationUpgrade, Component: v1alpha1.TiKVColdMemberType, PodName: "test-tikv-cold-0", StoreID: "3"},
+					{Type: v1alpha1.InFlightOperationScaleIn, Component: v1alpha1.TiKVMemberType, PodName: "test-tikv-2", StoreID: "4"},
+				} {
+					tc.SetInFlightOperation(op)
+				}
+			},
+			upgradingFn: func(lister corelisters.PodLister, controlInterface pdapi.PDControlInterface, set *apps.StatefulSet, cluster *v1alpha1.TidbCluster) (bool, error) {
+				return false, nil
+			},
+			tcExpectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Status.TiKV.Phase).To(Equal(v1alpha1.NormalPhase))
+				g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVMemberType, "test-tikv-0")).To(BeNil())
+				g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVMemberType, "test-tikv-1")).To(BeNil())
+				// the operations of the other groups or types are kept
+				g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVColdMemberType, "test-tikv-cold-0")).NotTo(BeNil())
+				g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationScaleIn, v1alpha1.TiKVMemberType, "test-tikv-2")).NotTo(BeNil())
+			},
+		},
 		{
 			name:     "statefulset is not upgrading",
 			updateTC: nil,
@@ -1588,6 +1627,47 @@ func newFakeTiKVMemberManager(tc *v1alpha1.TidbCluster) (
 	return tmm, setControl, svcControl, pdClient, podIndexer, nodeIndexer
 }
 
+func TestSyncRegionHealth(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pdClient := pdapi.NewFakePDClient()
+	counts := map[pdapi.RegionCheck]int{pdapi.RegionCheckMissPeer: 1, pdapi.RegionCheckDownPeer: 2, pdapi.RegionCheckPendingPeer: 3}
+	var getErr error
+	pdClient.AddReaction(pdapi.GetRegionsCheckActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.RegionsInfo{Count: counts[action.RegionCheck]}, getErr
+	})
+	tc := newTidbClusterForPD()
+
+	syncRegionHealth(pdClient, tc)
+	health := tc.Status.TiKV.RegionHealth
+	g.Expect(health).NotTo(BeNil())
+	g.Expect(health.MissPeerRegionCount).To(Equal(int32(1)))
+	g.Expect(health.DownPeerRegionCount).To(Equal(int32(2)))
+	g.Expect(health.PendingPeerRegionCount).To(Equal(int32(3)))
+
+	// the summary is not refreshed within the interval
+	counts[pdapi.RegionCheckDownPeer] = 0
+	syncRegionHealth(pdClient, tc)
+	g.Expect(tc.Status.TiKV.RegionHealth.DownPeerRegionCount).To(Equal(int32(2)))
+
+	// the summary is refreshed in every sync during the upgrade
+	tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+	syncRegionHealth(pdClient, tc)
+	g.Expect(tc.Status.TiKV.RegionHealth.DownPeerRegionCount).To(Equal(int32(0)))
+
+	// the previous summary is kept if the refresh fails
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	tc.Status.TiKV.RegionHealth.LastRefreshTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	getErr = fmt.Errorf("pd is unavailable")
+	syncRegionHealth(pdClient, tc)
+	g.Expect(tc.Status.TiKV.RegionHealth.PendingPeerRegionCount).To(Equal(int32(3)))
+
+	// the summary is cleared if it's disabled
+	tc.Spec.TiKV.RegionHealthRefreshInterval = pointer.StringPtr("0s")
+	syncRegionHealth(pdClient, tc)
+	g.Expect(tc.Status.TiKV.RegionHealth).To(BeNil())
+}
+
 func TestGetNewTiKVServiceForTidbCluster(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -1866,6 +1946,35 @@ func TestGetNewTiKVSetForTidbCluster(t *testing.T) {
 			},
 			testSts: testAdditionalVolumes(t, []corev1.Volume{{Name: "test", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}),
 		},
+		{
+			name: "tikv volumes with cloud tags",
+			tc: v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tc",
+					Namespace: "ns",
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					PD:   &v1alpha1.PDSpec{},
+					TiDB: &v1alpha1.TiDBSpec{},
+					TiKV: &v1alpha1.TiKVSpec{
+						StorageVolumes: []v1alpha1.StorageVolume{
+							{
+								Name:        "wal",
+								StorageSize: "2Gi",
+								MountPath:   "/var/lib/wal",
+							}},
+					},
+					CloudTags: map[string]string{"team": "db"},
+				},
+			},
+			testSts: func(sts *apps.StatefulSet) {
+				g := NewGomegaWithT(t)
+				g.Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(2))
+				for _, pvc := range sts.Spec.VolumeClaimTemplates {
+					g.Expect(pvc.Annotations).To(Equal(map[string]string{"team": "db"}))
+				}
+			},
+		},
 		{
 			name: "tikv spec storageVolumes",
 			tc: v1alpha1.TidbCluster{
@@ -2467,7 +2576,7 @@ func TestGetTiKVConfigMap(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			cm, err := getTikVConfigMap(&tt.tc)
+			cm, err := getTikVConfigMap(&tt.tc, v1alpha1.DefaultPDClientPort)
 			g.Expect(err).To(Succeed())
 			if tt.expected == nil {
 				g.Expect(cm).To(BeNil())
@@ -2597,3 +2706,20 @@ func mustTiKVConfig(x interface{}) *v1alpha1.TiKVConfigWraper {
 
 	return c
 }
+
+func TestGetTiKVConfigMapHeterogeneousPDAddress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "hetero", Namespace: "ns"},
+		Spec: v1alpha1.TidbClusterSpec{
+			Cluster: &v1alpha1.TidbClusterRef{Name: "ref"},
+			TiKV: &v1alpha1.TiKVSpec{
+				Config: v1alpha1.NewTiKVConfig(),
+			},
+		},
+	}
+	cm, err := getTikVConfigMap(tc, 12379)
+	g.Expect(err).To(Succeed())
+	g.Expect(cm.Data["startup-script"]).To(ContainSubstring("http://ref-pd:12379"))
+}
@@ -0,0 +1,300 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// tikvPerPodConfigVolumeMountPath is the directory of the configuration files of the TiKV Pods that have a
+	// configuration patch, it must be consistent with the start script
+	tikvPerPodConfigVolumeMountPath = "/etc/tikv-per-pod"
+)
+
+// tikvPerPodConfigEnabled returns whether the TiKV Pods use their own configuration files
+func tikvPerPodConfigEnabled(tc *v1alpha1.TidbCluster) bool {
+	return tc.Spec.TiKV != nil && (tc.Spec.TiKV.Config != nil || tc.Spec.TiKV.ConfigMapRef != nil) && len(tc.Spec.TiKV.PerPodConfig) > 0
+}
+
+// tikvPerPodConfigKey returns the key of the configuration file of the TiKV Pod in the per-pod ConfigMap
+func tikvPerPodConfigKey(ordinal int32) string {
+	return fmt.Sprintf("tikv-%d.toml", ordinal)
+}
+
+// tikvPerPodConfigPatches returns the configuration patches of the TiKV Pods keyed by their ordinals,
+// the keys that are not ordinals are ignored as they are rejected by the validation
+func tikvPerPodConfigPatches(tc *v1alpha1.TidbCluster) map[int32]*v1alpha1.TiKVConfigWraper {
+	patches := make(map[int32]*v1alpha1.TiKVConfigWraper)
+	keys := make([]string, 0, len(tc.Spec.TiKV.PerPodConfig))
+	for key := range tc.Spec.TiKV.PerPodConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ordinal, err := strconv.ParseInt(key, 10, 32)
+		if err != nil || ordinal < 0 || tc.Spec.TiKV.PerPodConfig[key] == nil {
+			continue
+		}
+		patches[int32(ordinal)] = tc.Spec.TiKV.PerPodConfig[key]
+	}
+	return patches
+}
+
+// tikvPerPodConfigHash returns the hash of the configuration patch of the TiKV Pod, the Pods without a
+// patch share the hash of the empty patch
+func tikvPerPodConfigHash(tc *v1alpha1.TidbCluster, ordinal int32) (string, error) {
+	var patch []byte
+	if config, ok := tikvPerPodConfigPatches(tc)[ordinal]; ok {
+		var err error
+		if patch, err = config.MarshalTOML(); err != nil {
+			return "", err
+		}
+	}
+	sum, err := mngerutils.Sha256Sum(string(patch))
+	if err != nil {
+		return "", err
+	}
+	return sum[:16], nil
+}
+
+// mergeConfig merges the configuration patch into the configuration, the tables are merged recursively
+// and the other values in the patch override the ones in the configuration
+func mergeConfig(config, patch map[string]interface{}) {
+	for key, value := range patch {
+		if patchTable, ok := value.(map[string]interface{}); ok {
+			if table, ok := config[key].(map[string]interface{}); ok {
+				mergeConfig(table, patchTable)
+				continue
+			}
+		}
+		config[key] = value
+	}
+}
+
+// getTiKVPerPodConfigMap renders the configuration file of each TiKV Pod that has a configuration patch,
+// by merging the patch into the configuration of all the TiKV Pods
+func getTiKVPerPodConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
+	data := make(map[string]string)
+	for ordinal, patch := range tikvPerPodConfigPatches(tc) {
+		config := tc.Spec.TiKV.Config.GenericConfig.DeepCopy()
+		if config.MP == nil {
+			config.MP = map[string]interface{}{}
+		}
+		if patch.GenericConfig != nil {
+			mergeConfig(config.MP, patch.GenericConfig.DeepCopy().MP)
+		}
+		applyConfigProfile(tc, v1alpha1.TiKVMemberType, config)
+		if tc.IsTLSClusterEnabled() {
+			config.Set("security.ca-path", path.Join(tikvClusterCertPath, tlsSecretRootCAKey))
+			config.Set("security.cert-path", path.Join(tikvClusterCertPath, corev1.TLSCertKey))
+			config.Set("security.key-path", path.Join(tikvClusterCertPath, corev1.TLSPrivateKeyKey))
+		}
+		setTiKVEncryptionConfig(tc, config)
+		confText, err := config.MarshalTOML()
+		if err != nil {
+			return nil, err
+		}
+		data[tikvPerPodConfigKey(ordinal)] = transformTiKVConfigMap(string(confText), tc)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            controller.TiKVPerPodConfigMapName(tc.Name),
+			Namespace:       tc.Namespace,
+			Labels:          labelTiKV(tc).Labels(),
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Data: data,
+	}, nil
+}
+
+// syncTiKVPerPodConfigMap syncs the ConfigMap of the configuration files of the TiKV Pods. The ConfigMap is
+// always updated in place, as it's mounted without a hash suffix and the Pods are restarted by the operator.
+func (m *tikvMemberManager) syncTiKVPerPodConfigMap(tc *v1alpha1.TidbCluster) error {
+	if !tikvPerPodConfigEnabled(tc) {
+		cmName := controller.TiKVPerPodConfigMapName(tc.Name)
+		cm, err := m.deps.ConfigMapLister.ConfigMaps(tc.Namespace).Get(cmName)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("syncTiKVPerPodConfigMap: failed to get configmap %s for cluster %s/%s, error: %s", cmName, tc.Namespace, tc.Name, err)
+		}
+		return m.deps.TypedControl.Delete(tc, cm)
+	}
+
+	cfgTC, err := withTiKVConfigMapRef(m.deps.UserConfigMapLister, tc)
+	if err != nil {
+		return err
+	}
+	cm, err := getTiKVPerPodConfigMap(cfgTC)
+	if err != nil {
+		return err
+	}
+	_, err = m.deps.TypedControl.CreateOrUpdateConfigMap(tc, cm)
+	return err
+}
+
+// syncTiKVPerPodRestart restarts the TiKV Pods whose configuration patch is changed one by one. The region
+// leaders of the store are evicted before the Pod is deleted, and the eviction ends once the new Pod is ready.
+// It's skipped while the StatefulSet is being scaled or upgraded, which recreates the Pods anyway.
+func (m *tikvMemberManager) syncTiKVPerPodRestart(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	for _, op := range tc.Status.InFlightOperations {
+		if op.Type != v1alpha1.InFlightOperationRestart || op.Component != v1alpha1.TiKVMemberType {
+			continue
+		}
+		store, exist := tc.Status.TiKV.Stores[op.StoreID]
+		if !exist {
+			// the store has been deleted or become tombstone, the evict leader scheduler is removed by PD
+			tc.RemoveInFlightOperation(op.Type, op.Component, op.PodName)
+			continue
+		}
+		pod, err := m.deps.PodLister.Pods(ns).Get(op.PodName)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("syncTiKVPerPodRestart: failed to get pod %s/%s for tc %s, error: %s", ns, op.PodName, tcName, err)
+		}
+		if pod == nil || !podutil.IsPodReady(pod) || store.State != v1alpha1.TiKVStateUp {
+			continue
+		}
+		// the Pod hasn't been recreated since the restart started, e.g. the Pod cache is stale
+		if !op.StartTime.Before(&pod.CreationTimestamp) {
+			continue
+		}
+		id, err := strconv.ParseUint(op.StoreID, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := endEvictLeaderbyStoreID(m.deps, tc, id); err != nil {
+			return err
+		}
+		tc.RemoveInFlightOperation(op.Type, op.Component, op.PodName)
+	}
+
+	if !tikvPerPodConfigEnabled(tc) || set == nil {
+		return nil
+	}
+	if tc.Status.TiKV.Phase != v1alpha1.NormalPhase || set.Status.CurrentRevision != set.Status.UpdateRevision {
+		return nil
+	}
+
+	var restartPod *corev1.Pod
+	for _, ordinal := range tc.TiKVStsDesiredOrdinals(true).List() {
+		podName := TikvPodName(tcName, ordinal)
+		pod, err := m.deps.PodLister.Pods(ns).Get(podName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("syncTiKVPerPodRestart: failed to get pod %s/%s for tc %s, error: %s", ns, podName, tcName, err)
+		}
+		if pod.Labels[apps.ControllerRevisionHashLabelKey] != set.Status.UpdateRevision {
+			continue
+		}
+		hash, err := tikvPerPodConfigHash(tc, ordinal)
+		if err != nil {
+			return err
+		}
+		applied, ok := pod.Annotations[label.AnnTiKVPerPodConfigHash]
+		if !ok {
+			// the Pod is started with the current configuration file, record it once the eviction ends
+			if tc.GetInFlightOperation(v1alpha1.InFlightOperationRestart, v1alpha1.TiKVMemberType, podName) != nil {
+				continue
+			}
+			pod = pod.DeepCopy()
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[label.AnnTiKVPerPodConfigHash] = hash
+			if _, err := m.deps.PodControl.UpdatePod(tc, pod); err != nil {
+				return err
+			}
+			continue
+		}
+		if applied != hash && restartPod == nil {
+			restartPod = pod
+		}
+	}
+	if restartPod == nil {
+		return nil
+	}
+	return restartTiKVPod(m.deps, tc, restartPod, "apply its configuration patch")
+}
+
+// restartTiKVPod evicts the region leaders of the store and then deletes the Pod, so that it's recreated, e.g.
+// with its new configuration file. The eviction ends in syncTiKVPerPodRestart once the new Pod is ready.
+func restartTiKVPod(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, pod *corev1.Pod, reason string) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	podName := pod.GetName()
+
+	op := tc.GetInFlightOperation(v1alpha1.InFlightOperationRestart, v1alpha1.TiKVMemberType, podName)
+	if op == nil {
+		for _, other := range tc.Status.InFlightOperations {
+			if other.Type == v1alpha1.InFlightOperationRestart && other.Component == v1alpha1.TiKVMemberType {
+				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv pod: [%s] is restarting", ns, tcName, other.PodName)
+			}
+		}
+		if !tc.TiKVAllStoresReady() {
+			return controller.RequeueErrorf("tidbcluster: [%s/%s] waiting for all tikv stores to be up before restarting pod: [%s]", ns, tcName, podName)
+		}
+		storeID, err := TiKVStoreIDFromStatus(tc, podName)
+		if err != nil {
+			if err == ErrNotFoundStoreID {
+				return controller.RequeueErrorf("tidbcluster: [%s/%s] no store status found for tikv pod: [%s]", ns, tcName, podName)
+			}
+			return err
+		}
+		if err := controller.GetPDClient(deps.PDControl, tc).BeginEvictLeader(storeID); err != nil {
+			klog.Errorf("tikv: failed to begin evict leader for store %d of %s/%s before restarting pod %s, error: %v", storeID, ns, tcName, podName, err)
+			return err
+		}
+		tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+			Type:      v1alpha1.InFlightOperationRestart,
+			Component: v1alpha1.TiKVMemberType,
+			PodName:   podName,
+			StoreID:   strconv.FormatUint(storeID, 10),
+		})
+		klog.Infof("tikv: begin evict leader for store %d of %s/%s before restarting pod %s to %s", storeID, ns, tcName, podName, reason)
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv pod: [%s] is evicting leader", ns, tcName, podName)
+	}
+
+	store := tc.Status.TiKV.Stores[op.StoreID]
+	if store.LeaderCount > 0 && time.Now().Before(op.StartTime.Add(tc.TiKVEvictLeaderTimeout())) {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv pod: [%s] is evicting leader, leader count: %d", ns, tcName, podName, store.LeaderCount)
+	}
+	if err := deps.PodControl.DeletePod(tc, pod); err != nil {
+		return err
+	}
+	deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "RestartPod", "tikv pod %s is restarted to %s", podName, reason)
+	return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv pod: [%s] is restarted to %s", ns, tcName, podName, reason)
+}
@@ -0,0 +1,189 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTidbClusterForTiKVPerPodConfig() *v1alpha1.TidbCluster {
+	config := v1alpha1.NewTiKVConfig()
+	config.Set("storage.block-cache.capacity", "1GB")
+	config.Set("server.grpc-concurrency", 4)
+	patch := v1alpha1.NewTiKVConfig()
+	patch.Set("storage.block-cache.capacity", "2GB")
+	patch.Set("readpool.unified.max-thread-count", 8)
+
+	stores := map[string]v1alpha1.TiKVStore{}
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprint(i + 1)
+		stores[id] = v1alpha1.TiKVStore{ID: id, PodName: TikvPodName("tc", int32(i)), State: v1alpha1.TiKVStateUp, LeaderCount: 10}
+	}
+	return &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "tc"},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV: &v1alpha1.TiKVSpec{
+				Replicas:     3,
+				Config:       config,
+				PerPodConfig: map[string]*v1alpha1.TiKVConfigWraper{"1": patch},
+			},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{Phase: v1alpha1.NormalPhase, Stores: stores},
+		},
+	}
+}
+
+func TestGetTiKVPerPodConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKVPerPodConfig()
+	cm, err := getTiKVPerPodConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Name).To(Equal("tc-tikv-per-pod"))
+	g.Expect(cm.Data).To(HaveLen(1))
+	g.Expect(cm.Data["tikv-1.toml"]).To(Equal(`[readpool]
+  [readpool.unified]
+    max-thread-count = 8
+
+[server]
+  grpc-concurrency = 4
+
+[storage]
+  [storage.block-cache]
+    capacity = "2GB"
+`))
+	// the configuration of all the Pods is not changed
+	g.Expect(tc.Spec.TiKV.Config.Get("storage.block-cache.capacity").MustString()).To(Equal("1GB"))
+	g.Expect(tc.Spec.TiKV.Config.Get("readpool.unified.max-thread-count")).To(BeNil())
+
+	volumeNames := func() []string {
+		set, err := getNewTiKVSetForTidbCluster(tc, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, vol := range set.Spec.Template.Spec.Volumes {
+			names = append(names, vol.Name)
+		}
+		return names
+	}
+	g.Expect(volumeNames()).To(ContainElement("per-pod-config"))
+	tc.Spec.TiKV.PerPodConfig = nil
+	g.Expect(volumeNames()).NotTo(ContainElement("per-pod-config"))
+}
+
+func TestSyncTiKVPerPodRestart(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKVPerPodConfig()
+	tmm, _, _, pdClient, podIndexer, _ := newFakeTiKVMemberManager(tc)
+	newPod := func(ordinal int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      TikvPodName("tc", ordinal),
+				Labels:    map[string]string{apps.ControllerRevisionHashLabelKey: "rev"},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	for i := int32(0); i < 3; i++ {
+		podIndexer.Add(newPod(i))
+	}
+	set := &apps.StatefulSet{
+		Status: apps.StatefulSetStatus{CurrentRevision: "rev", UpdateRevision: "rev"},
+	}
+	podHash := func(ordinal int32) string {
+		pod, err := tmm.deps.PodLister.Pods(corev1.NamespaceDefault).Get(TikvPodName("tc", ordinal))
+		g.Expect(err).NotTo(HaveOccurred())
+		return pod.Annotations[label.AnnTiKVPerPodConfigHash]
+	}
+	var evictStoreID, endEvictStoreID uint64
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		evictStoreID = action.ID
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		endEvictStoreID = action.ID
+		return nil, nil
+	})
+
+	// the hash of the patch is recorded for the new Pods
+	g.Expect(tmm.syncTiKVPerPodRestart(tc, set)).To(Succeed())
+	hash0, err := tikvPerPodConfigHash(tc, 0)
+	g.Expect(err).NotTo(HaveOccurred())
+	hash1, err := tikvPerPodConfigHash(tc, 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(hash0).NotTo(Equal(hash1))
+	g.Expect(podHash(0)).To(Equal(hash0))
+	g.Expect(podHash(1)).To(Equal(hash1))
+	g.Expect(podHash(2)).To(Equal(hash0))
+
+	// the leaders are evicted before the Pod whose patch is changed is restarted
+	tc.Spec.TiKV.PerPodConfig["1"].Set("storage.block-cache.capacity", "4GB")
+	err = tmm.syncTiKVPerPodRestart(tc, set)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+	g.Expect(evictStoreID).To(Equal(uint64(2)))
+	g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationRestart, v1alpha1.TiKVMemberType, "tc-tikv-1")).NotTo(BeNil())
+	err = tmm.syncTiKVPerPodRestart(tc, set)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+	_, err = tmm.deps.PodLister.Pods(corev1.NamespaceDefault).Get("tc-tikv-1")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the Pod is deleted once the leaders are evicted
+	store := tc.Status.TiKV.Stores["2"]
+	store.LeaderCount = 0
+	tc.Status.TiKV.Stores["2"] = store
+	err = tmm.syncTiKVPerPodRestart(tc, set)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+	_, err = tmm.deps.PodLister.Pods(corev1.NamespaceDefault).Get("tc-tikv-1")
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+	// the eviction doesn't end while the ready Pod is created before the restart, e.g. the cache is stale
+	op := tc.GetInFlightOperation(v1alpha1.InFlightOperationRestart, v1alpha1.TiKVMemberType, "tc-tikv-1")
+	stale := newPod(1)
+	stale.CreationTimestamp = metav1.NewTime(op.StartTime.Add(-time.Minute))
+	podIndexer.Add(stale)
+	err = tmm.syncTiKVPerPodRestart(tc, set)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(endEvictStoreID).To(BeZero())
+	g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationRestart, v1alpha1.TiKVMemberType, "tc-tikv-1")).NotTo(BeNil())
+	podIndexer.Delete(stale)
+
+	// the eviction ends once the recreated Pod is ready, and the other Pods are not restarted
+	recreated := newPod(1)
+	recreated.CreationTimestamp = metav1.NewTime(op.StartTime.Add(time.Second))
+	podIndexer.Add(recreated)
+	g.Expect(tmm.syncTiKVPerPodRestart(tc, set)).To(Succeed())
+	g.Expect(endEvictStoreID).To(Equal(uint64(2)))
+	g.Expect(tc.Status.InFlightOperations).To(BeEmpty())
+	hash1, err = tikvPerPodConfigHash(tc, 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(podHash(1)).To(Equal(hash1))
+	g.Expect(podHash(0)).To(Equal(hash0))
+	g.Expect(podHash(2)).To(Equal(hash0))
+}
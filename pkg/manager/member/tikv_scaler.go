@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 )
@@ -54,6 +55,7 @@ func (s *tikvScaler) Scale(meta metav1.Object, oldSet *apps.StatefulSet, newSet
 func (s *tikvScaler) ScaleOut(meta metav1.Object, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) error {
 	_, ordinal, replicas, deleteSlots := scaleOne(oldSet, newSet)
 	resetReplicas(newSet, oldSet)
+	memberType := tikvGroupMemberType(oldSet)
 	obj, ok := meta.(runtime.Object)
 	if !ok {
 		return fmt.Errorf("cluster[%s/%s] can't conver to runtime.Object", meta.GetNamespace(), meta.GetName())
@@ -62,13 +64,13 @@ func (s *tikvScaler) ScaleOut(meta metav1.Object, oldSet *apps.StatefulSet, newS
 	var pvcName string
 	switch meta.(type) {
 	case *v1alpha1.TidbCluster:
-		pvcName = fmt.Sprintf("tikv-%s-tikv-%d", meta.GetName(), ordinal)
+		pvcName = fmt.Sprintf("tikv-%s", ordinalPodName(memberType, meta.GetName(), ordinal))
 	default:
 		return fmt.Errorf("tikv.ScaleOut, failed to convert cluster %s/%s", meta.GetNamespace(), meta.GetName())
 	}
 	_, err := s.deps.PVCLister.PersistentVolumeClaims(meta.GetNamespace()).Get(pvcName)
 	if err == nil {
-		_, err = s.deleteDeferDeletingPVC(obj, v1alpha1.TiKVMemberType, ordinal)
+		_, err = s.deleteDeferDeletingPVC(obj, memberType, ordinal)
 		if err != nil {
 			return err
 		}
@@ -86,6 +88,7 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 	// we can only remove one member at a time when scaling in
 	_, ordinal, replicas, deleteSlots := scaleOne(oldSet, newSet)
 	resetReplicas(newSet, oldSet)
+	memberType := tikvGroupMemberType(oldSet)
 
 	klog.Infof("scaling in tikv statefulset %s/%s, ordinal: %d (replicas: %d, delete slots: %v)", oldSet.Namespace, oldSet.Name, ordinal, replicas, deleteSlots.List())
 	// We need remove member from cluster before reducing statefulset replicas
@@ -93,7 +96,7 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 
 	switch meta.(type) {
 	case *v1alpha1.TidbCluster:
-		podName = ordinalPodName(v1alpha1.TiKVMemberType, tcName, ordinal)
+		podName = ordinalPodName(memberType, tcName, ordinal)
 	default:
 		return fmt.Errorf("tikvScaler.ScaleIn: failed to convert cluster %s/%s", meta.GetNamespace(), meta.GetName())
 	}
@@ -103,18 +106,23 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 	}
 
 	tc, _ := meta.(*v1alpha1.TidbCluster)
+	status := tikvGroupStatus(tc, memberType)
 
-	if pass, err := s.preCheckUpStores(tc, podName); !pass {
-		return err
+	// the stores of the TiKV cold group only hold the data pinned by the placement policies,
+	// so the number of them is not restricted by the max replicas of the default placement rule
+	if memberType == v1alpha1.TiKVMemberType {
+		if pass, err := s.preCheckUpStores(tc, podName); !pass {
+			return err
+		}
 	}
 
-	if s.deps.CLIConfig.PodWebhookEnabled {
+	if tikvGroupPodWebhookEnabled(s.deps, memberType) {
 		setReplicasAndDeleteSlots(newSet, replicas, deleteSlots)
 		return nil
 	}
 
 	// call PD API to delete the store of the TiKV Pod to be scaled in
-	for _, store := range tc.Status.TiKV.Stores {
+	for _, store := range status.Stores {
 		if store.PodName == podName {
 			state := store.State
 			id, err := strconv.ParseUint(store.ID, 10, 64)
@@ -128,12 +136,18 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 				}
 				klog.Infof("tikvScaler.ScaleIn: delete store %d for tikv %s/%s successfully", id, ns, podName)
 			}
+			tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+				Type:      v1alpha1.InFlightOperationScaleIn,
+				Component: memberType,
+				PodName:   podName,
+				StoreID:   store.ID,
+			})
 			return controller.RequeueErrorf("TiKV %s/%s store %d is still in cluster, state: %s", ns, podName, id, state)
 		}
 	}
 
 	// If the store state turns to Tombstone, add defer deleting annotation to the PVCs of the Pod
-	for storeID, store := range tc.Status.TiKV.TombstoneStores {
+	for storeID, store := range status.TombstoneStores {
 		if store.PodName == podName && pod.Labels[label.StoreIDLabelKey] == storeID {
 			id, err := strconv.ParseUint(store.ID, 10, 64)
 			if err != nil {
@@ -142,25 +156,27 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 
 			// TODO: double check if store is really not in Up/Offline/Down state
 			klog.Infof("TiKV %s/%s store %d becomes tombstone", ns, podName, id)
+			return s.scaleInTombstoneStore(tc, pod, id, newSet, replicas, deleteSlots)
+		}
+	}
 
-			pvcs, err := util.ResolvePVCFromPod(pod, s.deps.PVCLister)
-			if err != nil {
-				return fmt.Errorf("tikvScaler.ScaleIn: failed to get pvcs for pod %s/%s in tc %s/%s, error: %s", ns, pod.Name, ns, tcName, err)
-			}
-			for _, pvc := range pvcs {
-				if err := addDeferDeletingAnnoToPVC(tc, pvc, s.deps.PVCControl); err != nil {
-					return err
-				}
-			}
-
-			// endEvictLeader for TombStone stores
-			if err = endEvictLeaderbyStoreID(s.deps, tc, id); err != nil {
-				return err
-			}
-
-			setReplicasAndDeleteSlots(newSet, replicas, deleteSlots)
-			return nil
+	// The store has been deleted by the previous leader of tidb-controller-manager, but the store is not
+	// in TidbCluster status yet, e.g. the status of the new leader is not synced. Check the store state
+	// from PD directly to resume scaling in rather than waiting for the status.
+	if op := tc.GetInFlightOperation(v1alpha1.InFlightOperationScaleIn, memberType, podName); op != nil && op.StoreID != "" {
+		id, err := strconv.ParseUint(op.StoreID, 10, 64)
+		if err != nil {
+			return err
+		}
+		storeInfo, err := controller.GetPDClient(s.deps.PDControl, tc).GetStore(id)
+		if err != nil {
+			return fmt.Errorf("tikvScaler.ScaleIn: failed to get store %d of tikv %s/%s, error: %v", id, ns, podName, err)
+		}
+		if storeInfo.Store == nil || storeInfo.Store.StateName != v1alpha1.TiKVStateTombstone {
+			return controller.RequeueErrorf("TiKV %s/%s store %d is being deleted, resumed from the in-flight operation", ns, podName, id)
 		}
+		klog.Infof("TiKV %s/%s store %d becomes tombstone, resumed from the in-flight operation", ns, podName, id)
+		return s.scaleInTombstoneStore(tc, pod, id, newSet, replicas, deleteSlots)
 	}
 
 	// When store not found in TidbCluster status, there are two possible situations:
@@ -200,6 +216,28 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 	return fmt.Errorf("TiKV %s/%s not found in cluster", ns, podName)
 }
 
+// scaleInTombstoneStore finishes scaling in the TiKV Pod whose store has become tombstone
+func (s *tikvScaler) scaleInTombstoneStore(tc *v1alpha1.TidbCluster, pod *v1.Pod, storeID uint64, newSet *apps.StatefulSet, replicas int32, deleteSlots sets.Int32) error {
+	pvcs, err := util.ResolvePVCFromPod(pod, s.deps.PVCLister)
+	if err != nil {
+		return fmt.Errorf("tikvScaler.ScaleIn: failed to get pvcs for pod %s/%s in tc %s/%s, error: %s", pod.Namespace, pod.Name, tc.Namespace, tc.Name, err)
+	}
+	for _, pvc := range pvcs {
+		if err := addDeferDeletingAnnoToPVC(tc, pvc, s.deps.PVCControl); err != nil {
+			return err
+		}
+	}
+
+	// endEvictLeader for TombStone stores
+	if err = endEvictLeaderbyStoreID(s.deps, tc, storeID); err != nil {
+		return err
+	}
+
+	tc.RemoveInFlightOperation(v1alpha1.InFlightOperationScaleIn, tikvGroupMemberType(newSet), pod.Name)
+	setReplicasAndDeleteSlots(newSet, replicas, deleteSlots)
+	return nil
+}
+
 func (s *tikvScaler) preCheckUpStores(tc *v1alpha1.TidbCluster, podName string) (bool, error) {
 	if !tc.TiKVBootStrapped() {
 		klog.Infof("TiKV of Cluster %s/%s is not bootstrapped yet, skip pre check when scale in TiKV", tc.Namespace, tc.Name)
@@ -214,9 +252,13 @@ func (s *tikvScaler) preCheckUpStores(tc *v1alpha1.TidbCluster, podName string)
 	if err != nil {
 		return false, fmt.Errorf("failed to get stores info in TidbCluster %s/%s", tc.GetNamespace(), tc.GetName())
 	}
-	// filter out TiFlash
+	coldPattern, err := tikvGroupStorePattern(tc, v1alpha1.TiKVColdMemberType)
+	if err != nil {
+		return false, err
+	}
+	// filter out TiFlash and the TiKV cold group
 	for _, store := range storesInfo.Stores {
-		if store.Store != nil {
+		if store.Store != nil && !coldPattern.Match([]byte(store.Store.Address)) {
 			if store.Store.StateName == v1alpha1.TiKVStateUp && util.MatchLabelFromStoreLabels(store.Store.Labels, label.TiKVLabelVal) {
 				upNumber++
 			}
@@ -159,6 +159,7 @@ func TestTiKVScalerScaleIn(t *testing.T) {
 		errExpectFn   func(*GomegaWithT, error)
 		changed       bool
 		getStoresFn   func(action *pdapi.Action) (interface{}, error)
+		getStoreFn    func(action *pdapi.Action) (interface{}, error)
 	}
 
 	resyncDuration := time.Duration(0)
@@ -255,6 +256,9 @@ func TestTiKVScalerScaleIn(t *testing.T) {
 			}
 		}
 		pdClient.AddReaction(pdapi.GetStoresActionType, test.getStoresFn)
+		if test.getStoreFn != nil {
+			pdClient.AddReaction(pdapi.GetStoreActionType, test.getStoreFn)
+		}
 
 		if test.delStoreErr {
 			pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
@@ -269,6 +273,7 @@ func TestTiKVScalerScaleIn(t *testing.T) {
 		test.errExpectFn(g, err)
 		if test.changed {
 			g.Expect(int(*newSet.Spec.Replicas)).To(Equal(4))
+			g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationScaleIn, v1alpha1.TiKVMemberType, pod.Name)).To(BeNil())
 		} else {
 			g.Expect(int(*newSet.Spec.Replicas)).To(Equal(5))
 		}
@@ -422,6 +427,34 @@ func TestTiKVScalerScaleIn(t *testing.T) {
 			errExpectFn:   errExpectNil,
 			changed:       true,
 		},
+		{
+			name:          "resume scaling in from the in-flight operation, store state is tombstone",
+			tikvUpgrading: false,
+			storeFun:      inFlightScaleInStoreFun,
+			delStoreErr:   false,
+			hasPVC:        true,
+			storeIDSynced: true,
+			isPodReady:    true,
+			hasSynced:     true,
+			pvcUpdateErr:  false,
+			errExpectFn:   errExpectNil,
+			changed:       true,
+			getStoreFn:    getStoreFn(v1alpha1.TiKVStateTombstone),
+		},
+		{
+			name:          "resume scaling in from the in-flight operation, store state is offline",
+			tikvUpgrading: false,
+			storeFun:      inFlightScaleInStoreFun,
+			delStoreErr:   false,
+			hasPVC:        true,
+			storeIDSynced: true,
+			isPodReady:    true,
+			hasSynced:     true,
+			pvcUpdateErr:  false,
+			errExpectFn:   errExpectRequeue,
+			changed:       false,
+			getStoreFn:    getStoreFn(v1alpha1.TiKVStateOffline),
+		},
 		{
 			name:          "store state is tombstone and store id not match",
 			tikvUpgrading: false,
@@ -602,6 +635,28 @@ func tombstoneStoreFun(tc *v1alpha1.TidbCluster) {
 	}
 }
 
+func inFlightScaleInStoreFun(tc *v1alpha1.TidbCluster) {
+	notReadyStoreFun(tc)
+
+	tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+		Type:      v1alpha1.InFlightOperationScaleIn,
+		Component: v1alpha1.TiKVMemberType,
+		PodName:   ordinalPodName(v1alpha1.TiKVMemberType, tc.GetName(), 4),
+		StoreID:   "1",
+	})
+}
+
+func getStoreFn(state string) func(action *pdapi.Action) (interface{}, error) {
+	return func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoreInfo{
+			Store: &pdapi.MetaStore{
+				StateName: state,
+				Store:     &metapb.Store{Id: action.ID},
+			},
+		}, nil
+	}
+}
+
 func minimalUpStoreFun(tc *v1alpha1.TidbCluster) {
 	normalStoreFun(tc)
 
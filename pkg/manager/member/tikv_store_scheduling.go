@@ -0,0 +1,165 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// FailedSetStoreScheduling is the reason of the events recorded when the scheduling settings of a store can't be set
+	FailedSetStoreScheduling = "FailedSetStoreScheduling"
+	// defaultStoreWeight is the default leader weight and region weight of a store in PD
+	defaultStoreWeight = 1.0
+)
+
+// syncStoreSchedulingForTiKV reconciles the weights and the store limits of the Up stores in PD with
+// spec.tikv.storeScheduling. The weights of the stores not selected by any item are reset to the default,
+// while the store limits are only set for the selected stores.
+func (m *tikvMemberManager) syncStoreSchedulingForTiKV(tc *v1alpha1.TidbCluster) error {
+	if len(tc.Spec.TiKV.StoreScheduling) == 0 || !tc.TiKVBootStrapped() {
+		return nil
+	}
+	ns := tc.GetNamespace()
+
+	pdCli := controller.GetPDClient(m.deps.PDControl, tc)
+	storesInfo, err := pdCli.GetStores()
+	if err != nil {
+		return err
+	}
+	var limits map[string]pdapi.StoreLimit
+	for _, item := range tc.Spec.TiKV.StoreScheduling {
+		if item.AddPeerLimit != nil || item.RemovePeerLimit != nil {
+			if limits, err = pdCli.GetStoresLimit(); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	pattern, err := regexp.Compile(fmt.Sprintf(tikvStoreLimitPattern, tc.Name, tc.Name, tc.Namespace, controller.FormatClusterDomainForRegex(tc.Spec.ClusterDomain)))
+	if err != nil {
+		return err
+	}
+	for _, store := range storesInfo.Stores {
+		if store.Store == nil || store.Status == nil || !pattern.Match([]byte(store.Store.Address)) {
+			continue
+		}
+		if store.Store.StateName != v1alpha1.TiKVStateUp {
+			continue
+		}
+		status := getTiKVStore(store)
+		ordinal, err := util.GetOrdinalFromPodName(status.PodName)
+		if err != nil {
+			klog.Warningf("unexpected pod name %q of store %s, error: %v", status.PodName, status.ID, err)
+			continue
+		}
+		desired := resolveTiKVStoreScheduling(tc.Spec.TiKV.StoreScheduling, ordinal, store.Store.Labels)
+		storeID := store.Store.Id
+		failed := func(what string, err error) {
+			msg := fmt.Sprintf("failed to set %s for store (id: %d, pod: %s/%s): %v", what, storeID, ns, status.PodName, err)
+			m.deps.Recorder.Event(tc, corev1.EventTypeWarning, FailedSetStoreScheduling, msg)
+		}
+
+		leaderWeight, regionWeight := defaultStoreWeight, defaultStoreWeight
+		if desired.LeaderWeight != nil {
+			leaderWeight = *desired.LeaderWeight
+		}
+		if desired.RegionWeight != nil {
+			regionWeight = *desired.RegionWeight
+		}
+		if store.Status.LeaderWeight != leaderWeight || store.Status.RegionWeight != regionWeight {
+			if err := pdCli.SetStoreWeight(storeID, leaderWeight, regionWeight); err != nil {
+				failed("weight", err)
+			} else {
+				klog.Infof("tikv: set weight of store %d (pod: %s/%s) to leader %v, region %v", storeID, ns, status.PodName, leaderWeight, regionWeight)
+			}
+		}
+
+		current, ok := limits[status.ID]
+		if desired.AddPeerLimit != nil && (!ok || current.AddPeer != *desired.AddPeerLimit) {
+			if err := pdCli.SetStoreLimit(storeID, pdapi.StoreLimitAddPeer, *desired.AddPeerLimit); err != nil {
+				failed("add-peer limit", err)
+			} else {
+				klog.Infof("tikv: set add-peer limit of store %d (pod: %s/%s) to %v", storeID, ns, status.PodName, *desired.AddPeerLimit)
+			}
+		}
+		if desired.RemovePeerLimit != nil && (!ok || current.RemovePeer != *desired.RemovePeerLimit) {
+			if err := pdCli.SetStoreLimit(storeID, pdapi.StoreLimitRemovePeer, *desired.RemovePeerLimit); err != nil {
+				failed("remove-peer limit", err)
+			} else {
+				klog.Infof("tikv: set remove-peer limit of store %d (pod: %s/%s) to %v", storeID, ns, status.PodName, *desired.RemovePeerLimit)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveTiKVStoreScheduling merges the items selecting the store, the later items take precedence
+func resolveTiKVStoreScheduling(items []v1alpha1.TiKVStoreScheduling, ordinal int32, labels []*metapb.StoreLabel) v1alpha1.TiKVStoreScheduling {
+	storeLabels := make(map[string]string, len(labels))
+	for _, l := range labels {
+		storeLabels[l.Key] = l.Value
+	}
+
+	var desired v1alpha1.TiKVStoreScheduling
+	for _, item := range items {
+		if !tikvStoreSchedulingSelects(&item, ordinal, storeLabels) {
+			continue
+		}
+		if item.LeaderWeight != nil {
+			desired.LeaderWeight = item.LeaderWeight
+		}
+		if item.RegionWeight != nil {
+			desired.RegionWeight = item.RegionWeight
+		}
+		if item.AddPeerLimit != nil {
+			desired.AddPeerLimit = item.AddPeerLimit
+		}
+		if item.RemovePeerLimit != nil {
+			desired.RemovePeerLimit = item.RemovePeerLimit
+		}
+	}
+	return desired
+}
+
+func tikvStoreSchedulingSelects(item *v1alpha1.TiKVStoreScheduling, ordinal int32, storeLabels map[string]string) bool {
+	if len(item.Ordinals) > 0 {
+		found := false
+		for _, o := range item.Ordinals {
+			if o == ordinal {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for k, v := range item.StoreLabels {
+		if storeLabels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+)
+
+func newStoreInfoForScheduling(id uint64, ordinal int, state string, leaderWeight, regionWeight float64, labels map[string]string) *pdapi.StoreInfo {
+	store := &pdapi.StoreInfo{
+		Store: &pdapi.MetaStore{
+			StateName: state,
+			Store: &metapb.Store{
+				Id:      id,
+				Address: fmt.Sprintf("test-tikv-%d.test-tikv-peer.default.svc:20160", ordinal),
+			},
+		},
+		Status: &pdapi.StoreStatus{LeaderWeight: leaderWeight, RegionWeight: regionWeight},
+	}
+	for k, v := range labels {
+		store.Store.Labels = append(store.Store.Labels, &metapb.StoreLabel{Key: k, Value: v})
+	}
+	return store
+}
+
+func TestTiKVMemberManagerSyncStoreScheduling(t *testing.T) {
+	g := NewGomegaWithT(t)
+	float := func(f float64) *float64 { return &f }
+
+	tc := newTidbClusterForTiKV()
+	tc.Status.TiKV.BootStrapped = true
+	tc.Spec.TiKV.StoreScheduling = []v1alpha1.TiKVStoreScheduling{
+		{StoreLabels: map[string]string{"disk": "large"}, RegionWeight: float(2), AddPeerLimit: float(30)},
+		{Ordinals: []int32{1}, LeaderWeight: float(0.5)},
+	}
+	tmm, _, _, pdClient, _, _ := newFakeTiKVMemberManager(tc)
+
+	pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoresInfo{Stores: []*pdapi.StoreInfo{
+			// not selected, the weights are reset
+			newStoreInfoForScheduling(1, 0, v1alpha1.TiKVStateUp, 3, 1, nil),
+			// selected by both items
+			newStoreInfoForScheduling(2, 1, v1alpha1.TiKVStateUp, 1, 1, map[string]string{"disk": "large"}),
+			// already in sync
+			newStoreInfoForScheduling(3, 2, v1alpha1.TiKVStateUp, 1, 2, map[string]string{"disk": "large"}),
+			// not up
+			newStoreInfoForScheduling(4, 3, v1alpha1.TiKVStateOffline, 1, 1, map[string]string{"disk": "large"}),
+		}}, nil
+	})
+	pdClient.AddReaction(pdapi.GetStoresLimitActionType, func(action *pdapi.Action) (interface{}, error) {
+		return map[string]pdapi.StoreLimit{
+			"2": {AddPeer: 15, RemovePeer: 15},
+			"3": {AddPeer: 30, RemovePeer: 15},
+		}, nil
+	})
+	weights := map[uint64][2]float64{}
+	pdClient.AddReaction(pdapi.SetStoreWeightActionType, func(action *pdapi.Action) (interface{}, error) {
+		weights[action.ID] = action.Weights
+		return nil, nil
+	})
+	limits := map[uint64]float64{}
+	pdClient.AddReaction(pdapi.SetStoreLimitActionType, func(action *pdapi.Action) (interface{}, error) {
+		g.Expect(action.LimitType).To(Equal(pdapi.StoreLimitAddPeer))
+		limits[action.ID] = action.Rate
+		return nil, nil
+	})
+
+	g.Expect(tmm.syncStoreSchedulingForTiKV(tc)).To(Succeed())
+	g.Expect(weights).To(Equal(map[uint64][2]float64{
+		1: {1, 1},
+		2: {0.5, 2},
+	}))
+	g.Expect(limits).To(Equal(map[uint64]float64{2: 30}))
+
+	// nothing is done without the settings
+	weights = map[uint64][2]float64{}
+	tc.Spec.TiKV.StoreScheduling = nil
+	g.Expect(tmm.syncStoreSchedulingForTiKV(tc)).To(Succeed())
+	g.Expect(weights).To(BeEmpty())
+}
@@ -53,17 +53,20 @@ func NewTiKVUpgrader(deps *controller.Dependencies) TiKVUpgrader {
 func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) error {
 	ns := meta.GetNamespace()
 	tcName := meta.GetName()
+	memberType := tikvGroupMemberType(oldSet)
 
 	var status *v1alpha1.TiKVStatus
 	switch meta := meta.(type) {
 	case *v1alpha1.TidbCluster:
+		// the TiKV cold group is upgraded after TiKV
 		if meta.Status.TiFlash.Phase == v1alpha1.UpgradePhase ||
 			meta.Status.PD.Phase == v1alpha1.UpgradePhase ||
-			meta.TiKVScaling() {
+			meta.TiKVScaling() ||
+			(memberType == v1alpha1.TiKVColdMemberType && (meta.Status.TiKV.Phase == v1alpha1.UpgradePhase || meta.TiKVColdScaling())) {
 			klog.Infof("TidbCluster: [%s/%s]'s tiflash status is %v, pd status is %v, "+
-				"tikv status is %v, can not upgrade tikv",
+				"tikv status is %v, tikv cold status is %v, can not upgrade %s",
 				ns, tcName,
-				meta.Status.TiFlash.Phase, meta.Status.PD.Phase, meta.Status.TiKV.Phase)
+				meta.Status.TiFlash.Phase, meta.Status.PD.Phase, meta.Status.TiKV.Phase, meta.Status.TiKVCold.Phase, memberType)
 			_, podSpec, err := GetLastAppliedConfig(oldSet)
 			if err != nil {
 				return err
@@ -71,7 +74,7 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 			newSet.Spec.Template.Spec = *podSpec
 			return nil
 		}
-		status = &meta.Status.TiKV
+		status = tikvGroupStatus(meta, memberType)
 	default:
 		return fmt.Errorf("cluster[%s/%s] failed to upgrading tikv due to converting", meta.GetNamespace(), meta.GetName())
 	}
@@ -109,15 +112,20 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 	}
 
 	mngerutils.SetUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
+	// the TiKV cold group inherits the progress deadline of TiKV
+	if syncRollingUpdateProgress(u.deps, tc, memberType, tc.Spec.TiKV.ProgressDeadlineSeconds, status.StatefulSet.UpdateRevision,
+		*oldSet.Spec.UpdateStrategy.RollingUpdate.Partition, &status.RollingUpdate, &status.Conditions) {
+		return nil
+	}
 	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
 	for _i := len(podOrdinals) - 1; _i >= 0; _i-- {
 		i := podOrdinals[_i]
-		store := getStoreByOrdinal(meta.GetName(), *status, i)
+		store := getStoreByOrdinal(memberType, meta.GetName(), *status, i)
 		if store == nil {
 			mngerutils.SetUpgradePartition(newSet, i)
 			continue
 		}
-		podName := TikvPodName(tcName, i)
+		podName := ordinalPodName(memberType, tcName, i)
 		pod, err := u.deps.PodLister.Pods(ns).Get(podName)
 		if err != nil {
 			return fmt.Errorf("tikvUpgrader.Upgrade: failed to get pods %s for cluster %s/%s, error: %s", podName, ns, tcName, err)
@@ -136,7 +144,7 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded tikv pod: [%s] is not all ready", ns, tcName, podName)
 			}
 
-			if !u.deps.CLIConfig.PodWebhookEnabled {
+			if !tikvGroupPodWebhookEnabled(u.deps, memberType) {
 				// If pods recreated successfully, endEvictLeader for the store on this Pod.
 				storeID, err := strconv.ParseUint(store.ID, 10, 64)
 				if err != nil {
@@ -145,26 +153,40 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 				if err := endEvictLeaderbyStoreID(u.deps, tc, storeID); err != nil {
 					return err
 				}
+				if err := u.waitLeaderRecovery(tc, memberType, store, pod); err != nil {
+					return err
+				}
+				tc.RemoveInFlightOperation(v1alpha1.InFlightOperationUpgrade, memberType, podName)
+			}
+			// the TiKV cold group inherits the rollout pacing of TiKV
+			if msg := checkUpgradedPodPacing(tc.BaseTiKVSpec(), pod); msg != "" {
+				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded tikv pod: [%s] %s", ns, tcName, podName, msg)
 			}
 
 			continue
 		}
 
-		if u.deps.CLIConfig.PodWebhookEnabled {
+		// the TiKV cold group inherits the resize policy of TiKV
+		resized, err := resizePodInPlace(u.deps, tc, v1alpha1.TiKVMemberType.String(), tc.Spec.TiKV.ResizePolicy, pod, oldSet, status.StatefulSet.UpdateRevision)
+		if err != nil || resized {
+			return err
+		}
+
+		if tikvGroupPodWebhookEnabled(u.deps, memberType) {
 			mngerutils.SetUpgradePartition(newSet, i)
 			return nil
 		}
 
-		return u.upgradeTiKVPod(tc, i, newSet)
+		return u.upgradeTiKVPod(tc, memberType, i, newSet)
 	}
 
 	return nil
 }
 
-func (u *tikvUpgrader) upgradeTiKVPod(tc *v1alpha1.TidbCluster, ordinal int32, newSet *apps.StatefulSet) error {
+func (u *tikvUpgrader) upgradeTiKVPod(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, ordinal int32, newSet *apps.StatefulSet) error {
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
-	upgradePodName := TikvPodName(tcName, ordinal)
+	upgradePodName := ordinalPodName(memberType, tcName, ordinal)
 	upgradePod, err := u.deps.PodLister.Pods(ns).Get(upgradePodName)
 	if err != nil {
 		return fmt.Errorf("upgradeTiKVPod: failed to get pods %s for cluster %s/%s, error: %s", upgradePodName, ns, tcName, err)
@@ -172,16 +194,37 @@ func (u *tikvUpgrader) upgradeTiKVPod(tc *v1alpha1.TidbCluster, ordinal int32, n
 
 	storeID, err := TiKVStoreIDFromStatus(tc, upgradePodName)
 	if err != nil {
-		if err == ErrNotFoundStoreID {
+		if err != ErrNotFoundStoreID {
+			return err
+		}
+		// resume the upgrade started by the previous leader of tidb-controller-manager
+		op := tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, memberType, upgradePodName)
+		if op == nil || op.StoreID == "" {
 			return controller.RequeueErrorf("tidbcluster: [%s/%s] no store status found for tikv pod: [%s]", ns, tcName, upgradePodName)
 		}
-		return err
+		if storeID, err = strconv.ParseUint(op.StoreID, 10, 64); err != nil {
+			return err
+		}
 	}
 
 	_, evicting := upgradePod.Annotations[EvictLeaderBeginTime]
 	if !evicting {
-		return u.beginEvictLeader(tc, storeID, upgradePod)
+		// restarting another store may make the regions with down peers unavailable
+		if health := tc.Status.TiKV.RegionHealth; health != nil && health.DownPeerRegionCount > 0 {
+			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv pod: [%s] is not upgraded as there are %d regions with down peers",
+				ns, tcName, upgradePodName, health.DownPeerRegionCount)
+		}
+		if err := u.checkReceivingSnapshots(tc, storeID, upgradePodName); err != nil {
+			return err
+		}
+		return u.beginEvictLeader(tc, memberType, storeID, upgradePod, tikvGroupStatus(tc, memberType).Stores[strconv.FormatUint(storeID, 10)].LeaderCount)
 	}
+	tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+		Type:      v1alpha1.InFlightOperationUpgrade,
+		Component: memberType,
+		PodName:   upgradePodName,
+		StoreID:   strconv.FormatUint(storeID, 10),
+	})
 
 	if u.readyToUpgrade(upgradePod, tc) {
 		mngerutils.SetUpgradePartition(newSet, ordinal)
@@ -191,6 +234,58 @@ func (u *tikvUpgrader) upgradeTiKVPod(tc *v1alpha1.TidbCluster, ordinal int32, n
 	return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv pod: [%s] is evicting leader", ns, tcName, upgradePodName)
 }
 
+// checkReceivingSnapshots delays the upgrade of the store if it's receiving or applying too many snapshots,
+// e.g. a new store that is still being filled, restarting it at that time makes PD re-replicate the regions.
+func (u *tikvUpgrader) checkReceivingSnapshots(tc *v1alpha1.TidbCluster, storeID uint64, podName string) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	limit := tc.TiKVMaxReceivingSnapshotsOnUpgrade()
+	if limit < 0 {
+		return nil
+	}
+	storeInfo, err := controller.GetPDClient(u.deps.PDControl, tc).GetStore(storeID)
+	if err != nil {
+		klog.Warningf("tikv upgrader: failed to get store %d of pod %s/%s, skip checking the snapshots, %v", storeID, ns, podName, err)
+		return nil
+	}
+	if storeInfo.Status == nil {
+		return nil
+	}
+	receiving, applying := storeInfo.Status.ReceivingSnapCount, storeInfo.Status.ApplyingSnapCount
+	if receiving+applying <= uint32(limit) {
+		return nil
+	}
+	msg := fmt.Sprintf("tikv pod %s (store %d) is receiving %d and applying %d snapshots, more than %d, delay the upgrade until the store settles down",
+		podName, storeID, receiving, applying, limit)
+	u.deps.Recorder.Event(tc, corev1.EventTypeNormal, "UpgradeDelayed", msg)
+	return controller.RequeueErrorf("tidbcluster: [%s/%s]'s %s", ns, tcName, msg)
+}
+
+// waitLeaderRecovery delays the upgrade of the next store until the upgraded store regains the configured percent
+// of the leaders it had before the leaders were evicted, restarting another store before the leaders are balanced
+// again makes the remaining stores serve most of the requests and regresses the latency during the whole rollout.
+func (u *tikvUpgrader) waitLeaderRecovery(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, store *v1alpha1.TiKVStore, pod *corev1.Pod) error {
+	ns := tc.GetNamespace()
+	percent := tc.TiKVLeaderRecoveryPercentOnUpgrade()
+	op := tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, memberType, pod.Name)
+	if percent <= 0 || op == nil || op.LeaderCount <= 0 {
+		return nil
+	}
+	expected := int32(int64(op.LeaderCount) * int64(percent) / 100)
+	if store.LeaderCount >= expected {
+		klog.Infof("tikv upgrader: store %s of pod %s/%s regains %d leaders, %d before the upgrade", store.ID, ns, pod.Name, store.LeaderCount, op.LeaderCount)
+		return nil
+	}
+	timeout := tc.TiKVLeaderRecoveryTimeout()
+	if cond := podutil.GetPodReadyCondition(pod.Status); cond != nil && time.Now().After(cond.LastTransitionTime.Add(timeout)) {
+		klog.Warningf("tikv upgrader: store %s of pod %s/%s has %d leaders, less than %d after %v, continue the upgrade",
+			store.ID, ns, pod.Name, store.LeaderCount, expected, timeout)
+		return nil
+	}
+	return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded tikv pod: [%s] has %d leaders, waiting for %d leaders to be transferred back",
+		ns, tc.GetName(), pod.Name, store.LeaderCount, expected)
+}
+
 func (u *tikvUpgrader) readyToUpgrade(upgradePod *corev1.Pod, tc *v1alpha1.TidbCluster) bool {
 	evictLeaderTimeout := tc.TiKVEvictLeaderTimeout()
 
@@ -223,7 +318,7 @@ func (u *tikvUpgrader) readyToUpgrade(upgradePod *corev1.Pod, tc *v1alpha1.TidbC
 	return false
 }
 
-func (u *tikvUpgrader) beginEvictLeader(tc *v1alpha1.TidbCluster, storeID uint64, pod *corev1.Pod) error {
+func (u *tikvUpgrader) beginEvictLeader(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, storeID uint64, pod *corev1.Pod, leaderCount int32) error {
 	ns := tc.GetNamespace()
 	podName := pod.GetName()
 	err := controller.GetPDClient(u.deps.PDControl, tc).BeginEvictLeader(storeID)
@@ -246,11 +341,18 @@ func (u *tikvUpgrader) beginEvictLeader(tc *v1alpha1.TidbCluster, storeID uint64
 	}
 	klog.Infof("tikv upgrader: set pod %s/%s annotation %s to %s successfully",
 		ns, podName, EvictLeaderBeginTime, now)
+	tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+		Type:        v1alpha1.InFlightOperationUpgrade,
+		Component:   memberType,
+		PodName:     podName,
+		StoreID:     strconv.FormatUint(storeID, 10),
+		LeaderCount: leaderCount,
+	})
 	return nil
 }
 
-func endEvictLeader(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, ordinal int32) error {
-	store := getStoreByOrdinal(tc.GetName(), tc.Status.TiKV, ordinal)
+func endEvictLeader(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, ordinal int32) error {
+	store := getStoreByOrdinal(memberType, tc.GetName(), *tikvGroupStatus(tc, memberType), ordinal)
 	if store == nil {
 		klog.Errorf("tikv: no store found for TiKV ordinal %v of %s/%s", ordinal, tc.Namespace, tc.Name)
 		return nil
@@ -278,8 +380,8 @@ func endEvictLeaderbyStoreID(deps *controller.Dependencies, tc *v1alpha1.TidbClu
 	return nil
 }
 
-func getStoreByOrdinal(name string, status v1alpha1.TiKVStatus, ordinal int32) *v1alpha1.TiKVStore {
-	podName := TikvPodName(name, ordinal)
+func getStoreByOrdinal(memberType v1alpha1.MemberType, name string, status v1alpha1.TiKVStatus, ordinal int32) *v1alpha1.TiKVStore {
+	podName := ordinalPodName(memberType, name, ordinal)
 	for _, store := range status.Stores {
 		if store.PodName == podName {
 			return &store
@@ -295,8 +397,8 @@ func NewFakeTiKVUpgrader() TiKVUpgrader {
 	return &fakeTiKVUpgrader{}
 }
 
-func (u *fakeTiKVUpgrader) Upgrade(meta metav1.Object, _ *apps.StatefulSet, _ *apps.StatefulSet) error {
+func (u *fakeTiKVUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, _ *apps.StatefulSet) error {
 	tc := meta.(*v1alpha1.TidbCluster)
-	tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+	tikvGroupStatus(tc, tikvGroupMemberType(oldSet)).Phase = v1alpha1.UpgradePhase
 	return nil
 }
@@ -52,6 +52,7 @@ func TestTiKVUpgraderUpgrade(t *testing.T) {
 		endEvictLeaderErr   bool
 		getLeaderCountErr   bool
 		leaderCount         int
+		receivingSnapCount  uint32
 		podName             string
 		updatePodErr        bool
 		errExpectFn         func(*GomegaWithT, error)
@@ -84,6 +85,12 @@ func TestTiKVUpgraderUpgrade(t *testing.T) {
 			})
 		}
 
+		if test.receivingSnapCount > 0 {
+			pdClient.AddReaction(pdapi.GetStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+				return &pdapi.StoreInfo{Status: &pdapi.StoreStatus{ReceivingSnapCount: test.receivingSnapCount}}, nil
+			})
+		}
+
 		tikvClient := controller.NewFakeTiKVClient(tikvControl, tc, "upgrader-tikv-2")
 		if len(test.podName) > 0 {
 			tikvClient = controller.NewFakeTiKVClient(tikvControl, tc, test.podName)
@@ -403,6 +410,218 @@ func TestTiKVUpgraderUpgrade(t *testing.T) {
 				g.Expect(exist).To(BeTrue())
 			},
 		},
+		{
+			name: "delay the upgrade when store[2] is receiving too many snapshots",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.Status.TiKV.Synced = true
+				tc.Status.TiKV.StatefulSet.CurrentReplicas = 2
+				tc.Status.TiKV.StatefulSet.UpdatedReplicas = 1
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				mngerutils.SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+				oldSet.Status.CurrentReplicas = 2
+				oldSet.Status.UpdatedReplicas = 1
+				oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+			},
+			changePods:          nil,
+			beginEvictLeaderErr: false,
+			endEvictLeaderErr:   false,
+			updatePodErr:        false,
+			receivingSnapCount:  10,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet, pods map[string]*corev1.Pod) {
+				g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+				_, exist := pods[TikvPodName(upgradeTcName, 1)].Annotations[EvictLeaderBeginTime]
+				g.Expect(exist).To(BeFalse())
+			},
+		},
+		{
+			name: "delay the upgrade when there are regions with down peers",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.Status.TiKV.Synced = true
+				tc.Status.TiKV.StatefulSet.CurrentReplicas = 2
+				tc.Status.TiKV.StatefulSet.UpdatedReplicas = 1
+				tc.Status.TiKV.RegionHealth = &v1alpha1.RegionHealthStatus{DownPeerRegionCount: 3}
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				mngerutils.SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+				oldSet.Status.CurrentReplicas = 2
+				oldSet.Status.UpdatedReplicas = 1
+				oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+			},
+			changePods:          nil,
+			beginEvictLeaderErr: false,
+			endEvictLeaderErr:   false,
+			updatePodErr:        false,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet, pods map[string]*corev1.Pod) {
+				g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+				_, exist := pods[TikvPodName(upgradeTcName, 1)].Annotations[EvictLeaderBeginTime]
+				g.Expect(exist).To(BeFalse())
+			},
+		},
+		{
+			name: "begin evict leaders on store[2] when the snapshot check is disabled",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.Status.TiKV.Synced = true
+				tc.Status.TiKV.StatefulSet.CurrentReplicas = 2
+				tc.Status.TiKV.StatefulSet.UpdatedReplicas = 1
+				tc.Spec.TiKV.MaxReceivingSnapshotsOnUpgrade = pointer.Int32Ptr(-1)
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				mngerutils.SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+				oldSet.Status.CurrentReplicas = 2
+				oldSet.Status.UpdatedReplicas = 1
+				oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+			},
+			changePods:          nil,
+			beginEvictLeaderErr: false,
+			endEvictLeaderErr:   false,
+			updatePodErr:        false,
+			receivingSnapCount:  10,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet, pods map[string]*corev1.Pod) {
+				g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+				_, exist := pods[TikvPodName(upgradeTcName, 1)].Annotations[EvictLeaderBeginTime]
+				g.Expect(exist).To(BeTrue())
+			},
+		},
+		{
+			name: "wait for the leaders to be transferred back to the upgraded store[2]",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.Status.TiKV.Synced = true
+				tc.Status.TiKV.StatefulSet.CurrentReplicas = 2
+				tc.Status.TiKV.StatefulSet.UpdatedReplicas = 1
+				tc.Spec.TiKV.LeaderRecoveryPercentOnUpgrade = pointer.Int32Ptr(80)
+				tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+					Type:        v1alpha1.InFlightOperationUpgrade,
+					Component:   v1alpha1.TiKVMemberType,
+					PodName:     TikvPodName(upgradeTcName, 2),
+					StoreID:     "3",
+					LeaderCount: 100,
+				})
+				store := tc.Status.TiKV.Stores["3"]
+				store.LeaderCount = 10
+				tc.Status.TiKV.Stores["3"] = store
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				mngerutils.SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+				oldSet.Status.CurrentReplicas = 2
+				oldSet.Status.UpdatedReplicas = 1
+				oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+			},
+			changePods: func(pods []*corev1.Pod) {
+				pods[2].Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Minute))
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring("waiting for 80 leaders to be transferred back"))
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet, pods map[string]*corev1.Pod) {
+				g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+				_, exist := pods[TikvPodName(upgradeTcName, 1)].Annotations[EvictLeaderBeginTime]
+				g.Expect(exist).To(BeFalse())
+				g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVMemberType, TikvPodName(upgradeTcName, 2))).NotTo(BeNil())
+			},
+		},
+		{
+			name: "begin evict leaders on store[1] after the leaders of store[2] recover",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.Status.TiKV.Synced = true
+				tc.Status.TiKV.StatefulSet.CurrentReplicas = 2
+				tc.Status.TiKV.StatefulSet.UpdatedReplicas = 1
+				tc.Spec.TiKV.LeaderRecoveryPercentOnUpgrade = pointer.Int32Ptr(80)
+				tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+					Type:        v1alpha1.InFlightOperationUpgrade,
+					Component:   v1alpha1.TiKVMemberType,
+					PodName:     TikvPodName(upgradeTcName, 2),
+					StoreID:     "3",
+					LeaderCount: 100,
+				})
+				store := tc.Status.TiKV.Stores["3"]
+				store.LeaderCount = 90
+				tc.Status.TiKV.Stores["3"] = store
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				mngerutils.SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+				oldSet.Status.CurrentReplicas = 2
+				oldSet.Status.UpdatedReplicas = 1
+				oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+			},
+			changePods: func(pods []*corev1.Pod) {
+				pods[2].Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Minute))
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet, pods map[string]*corev1.Pod) {
+				g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+				_, exist := pods[TikvPodName(upgradeTcName, 1)].Annotations[EvictLeaderBeginTime]
+				g.Expect(exist).To(BeTrue())
+				g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVMemberType, TikvPodName(upgradeTcName, 2))).To(BeNil())
+				op := tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVMemberType, TikvPodName(upgradeTcName, 1))
+				g.Expect(op).NotTo(BeNil())
+				g.Expect(op.LeaderCount).To(Equal(int32(10)))
+			},
+		},
+		{
+			name: "begin evict leaders on store[1] after waiting for the leaders of store[2] timed out",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+				tc.Status.TiKV.Synced = true
+				tc.Status.TiKV.StatefulSet.CurrentReplicas = 2
+				tc.Status.TiKV.StatefulSet.UpdatedReplicas = 1
+				tc.Spec.TiKV.LeaderRecoveryPercentOnUpgrade = pointer.Int32Ptr(80)
+				tc.SetInFlightOperation(v1alpha1.InFlightOperation{
+					Type:        v1alpha1.InFlightOperationUpgrade,
+					Component:   v1alpha1.TiKVMemberType,
+					PodName:     TikvPodName(upgradeTcName, 2),
+					StoreID:     "3",
+					LeaderCount: 100,
+				})
+				store := tc.Status.TiKV.Stores["3"]
+				store.LeaderCount = 10
+				tc.Status.TiKV.Stores["3"] = store
+			},
+			changeOldSet: func(oldSet *apps.StatefulSet) {
+				mngerutils.SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+				oldSet.Status.CurrentReplicas = 2
+				oldSet.Status.UpdatedReplicas = 1
+				oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+			},
+			changePods: func(pods []*corev1.Pod) {
+				pods[2].Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-20 * time.Minute))
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet, pods map[string]*corev1.Pod) {
+				g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+				_, exist := pods[TikvPodName(upgradeTcName, 1)].Annotations[EvictLeaderBeginTime]
+				g.Expect(exist).To(BeTrue())
+				g.Expect(tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVMemberType, TikvPodName(upgradeTcName, 2))).To(BeNil())
+				op := tc.GetInFlightOperation(v1alpha1.InFlightOperationUpgrade, v1alpha1.TiKVMemberType, TikvPodName(upgradeTcName, 1))
+				g.Expect(op).NotTo(BeNil())
+				g.Expect(op.LeaderCount).To(Equal(int32(10)))
+			},
+		},
 		{
 			name: "waiting leader count equals to 0",
 			changeFn: func(tc *v1alpha1.TidbCluster) {
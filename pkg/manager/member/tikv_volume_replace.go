@@ -0,0 +1,175 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// replaceTiKVVolumes shrinks the volumes of TiKV by replacing the stores one by one, because
+// the storage request of a PVC can't be decreased in place:
+//
+//  1. recreate the StatefulSet (orphaning its Pods) so that new PVCs are created with the desired size
+//  2. wait for all stores to be up, then evict the region leaders of the store
+//  3. delete the store and wait for its regions to be replicated to other stores
+//  4. once the store becomes tombstone, delete the PVCs and the Pod, the StatefulSet controller will
+//     recreate the Pod with new PVCs, which joins the cluster as a new store
+func (p *pvcResizer) replaceTiKVVolumes(tc *v1alpha1.TidbCluster, selector labels.Selector, pvcQuantityInSpec map[string]resource.Quantity) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	stsName := controller.TiKVMemberName(tcName)
+
+	pvcs, err := p.deps.PVCLister.PersistentVolumeClaims(ns).List(selector)
+	if err != nil {
+		return err
+	}
+	// the PVCs of each Pod, and the Pods that have volumes larger than desired
+	podPVCs := make(map[string][]*corev1.PersistentVolumeClaim)
+	podNames := sets.NewString()
+	for _, pvc := range pvcs {
+		match := rePvcPrefix.FindStringSubmatch(pvc.Name)
+		if match == nil {
+			continue
+		}
+		pvcPrefix := match[1]
+		podName := fmt.Sprintf("%s-%s", stsName, match[2])
+		podPVCs[podName] = append(podPVCs[podName], pvc)
+
+		quantityInSpec, ok := pvcQuantityInSpec[pvcPrefix]
+		if !ok || pvc.DeletionTimestamp != nil {
+			continue
+		}
+		currentRequest, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if ok && quantityInSpec.Cmp(currentRequest) < 0 {
+			podNames.Insert(podName)
+		}
+	}
+	if podNames.Len() == 0 {
+		return nil
+	}
+	podName := podNames.List()[0]
+
+	// new PVCs are created from the volumeClaimTemplates, which can't be updated
+	set, err := p.deps.StatefulSetLister.StatefulSets(ns).Get(stsName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return controller.RequeueErrorf("tikv volume replace: statefulset %s/%s is not recreated yet", ns, stsName)
+		}
+		return err
+	}
+	for _, tmpl := range set.Spec.VolumeClaimTemplates {
+		quantityInSpec, ok := pvcQuantityInSpec[fmt.Sprintf("%s-%s", tmpl.Name, stsName)]
+		if !ok {
+			continue
+		}
+		if quantity, ok := tmpl.Spec.Resources.Requests[corev1.ResourceStorage]; ok && quantity.Cmp(quantityInSpec) == 0 {
+			continue
+		}
+		orphan := metav1.DeletePropagationOrphan
+		err := p.deps.KubeClientset.AppsV1().StatefulSets(ns).Delete(context.TODO(), stsName, metav1.DeleteOptions{PropagationPolicy: &orphan})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("tikv volume replace: failed to delete statefulset %s/%s, error: %v", ns, stsName, err)
+		}
+		return controller.RequeueErrorf("tikv volume replace: statefulset %s/%s is deleted to update the volumeClaimTemplate %s", ns, stsName, tmpl.Name)
+	}
+
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.PodName != podName {
+			continue
+		}
+		id, err := strconv.ParseUint(store.ID, 10, 64)
+		if err != nil {
+			return err
+		}
+		if store.State == v1alpha1.TiKVStateOffline {
+			return controller.RequeueErrorf("tikv volume replace: regions of TiKV %s/%s store %d are being replicated to other stores", ns, podName, id)
+		}
+		if !tc.TiKVAllStoresReady() {
+			return controller.RequeueErrorf("tikv volume replace: waiting for all stores of TiKV %s/%s to be up before replacing the volumes of %s", ns, tcName, podName)
+		}
+
+		pdClient := controller.GetPDClient(p.deps.PDControl, tc)
+		config, err := pdClient.GetConfig()
+		if err != nil {
+			return err
+		}
+		maxReplicas := int(*config.Replication.MaxReplicas)
+		if len(tc.Status.TiKV.Stores) <= maxReplicas {
+			msg := fmt.Sprintf("the number of TiKV stores (%d) is not larger than MaxReplicas in PD configuration (%d), can't replace the volumes of %s", len(tc.Status.TiKV.Stores), maxReplicas, podName)
+			klog.Errorf("tikv volume replace: %s/%s %s", ns, tcName, msg)
+			p.deps.Recorder.Event(tc, corev1.EventTypeWarning, "FailedReplaceVolume", msg)
+			return nil
+		}
+
+		if store.LeaderCount > 0 {
+			if err := pdClient.BeginEvictLeader(id); err != nil {
+				return fmt.Errorf("tikv volume replace: failed to begin evict leader of TiKV %s/%s store %d, error: %v", ns, podName, id, err)
+			}
+			return controller.RequeueErrorf("tikv volume replace: evicting leaders of TiKV %s/%s store %d, leader count: %d", ns, podName, id, store.LeaderCount)
+		}
+		if err := pdClient.DeleteStore(id); err != nil {
+			return fmt.Errorf("tikv volume replace: failed to delete TiKV %s/%s store %d, error: %v", ns, podName, id, err)
+		}
+		klog.Infof("tikv volume replace: delete TiKV %s/%s store %d successfully", ns, podName, id)
+		return controller.RequeueErrorf("tikv volume replace: TiKV %s/%s store %d is deleted", ns, podName, id)
+	}
+
+	pod, err := p.deps.PodLister.Pods(ns).Get(podName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return controller.RequeueErrorf("tikv volume replace: TiKV pod %s/%s is not recreated yet", ns, podName)
+		}
+		return err
+	}
+	for storeID, store := range tc.Status.TiKV.TombstoneStores {
+		if store.PodName != podName || pod.Labels[label.StoreIDLabelKey] != storeID {
+			continue
+		}
+		id, err := strconv.ParseUint(storeID, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := endEvictLeaderbyStoreID(p.deps, tc, id); err != nil {
+			return err
+		}
+		for _, pvc := range podPVCs[podName] {
+			if pvc.DeletionTimestamp != nil {
+				continue
+			}
+			if err := p.deps.PVCControl.DeletePVC(tc, pvc); err != nil {
+				return fmt.Errorf("tikv volume replace: failed to delete PVC %s/%s, error: %v", ns, pvc.Name, err)
+			}
+			klog.Infof("tikv volume replace: delete PVC %s/%s successfully", ns, pvc.Name)
+		}
+		if err := p.deps.PodControl.DeletePod(tc, pod); err != nil {
+			return fmt.Errorf("tikv volume replace: failed to delete pod %s/%s, error: %v", ns, podName, err)
+		}
+		return controller.RequeueErrorf("tikv volume replace: TiKV %s/%s store %d becomes tombstone, recreating the pod with new volumes", ns, podName, id)
+	}
+
+	return controller.RequeueErrorf("tikv volume replace: store of TiKV %s/%s is not found", ns, podName)
+}
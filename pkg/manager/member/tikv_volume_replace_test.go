@@ -0,0 +1,230 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTiKVVolumeReplace(t *testing.T) {
+	newStore := func(id string, ordinal int, state string, leaderCount int32) v1alpha1.TiKVStore {
+		return v1alpha1.TiKVStore{
+			ID:          id,
+			PodName:     fmt.Sprintf("tc-tikv-%d", ordinal),
+			State:       state,
+			LeaderCount: leaderCount,
+		}
+	}
+	upStores := func(firstState string, leaderCount int32) map[string]v1alpha1.TiKVStore {
+		stores := map[string]v1alpha1.TiKVStore{}
+		for i := 0; i < 4; i++ {
+			store := newStore(fmt.Sprint(i+1), i, v1alpha1.TiKVStateUp, 10)
+			if i == 0 {
+				store = newStore("1", 0, firstState, leaderCount)
+			}
+			stores[store.ID] = store
+		}
+		return stores
+	}
+
+	tests := []struct {
+		name              string
+		templateSize      string
+		stores            map[string]v1alpha1.TiKVStore
+		tombstoneStores   map[string]v1alpha1.TiKVStore
+		wantRequeue       bool
+		wantStsDeleted    bool
+		wantEvictLeader   bool
+		wantDeleteStore   bool
+		wantEndEvict      bool
+		wantPodPVCDeleted bool
+	}{
+		{
+			name:           "statefulset is recreated with the new volumeClaimTemplates",
+			templateSize:   "2Gi",
+			stores:         upStores(v1alpha1.TiKVStateUp, 10),
+			wantRequeue:    true,
+			wantStsDeleted: true,
+		},
+		{
+			name:            "leaders are evicted",
+			templateSize:    "1Gi",
+			stores:          upStores(v1alpha1.TiKVStateUp, 10),
+			wantRequeue:     true,
+			wantEvictLeader: true,
+		},
+		{
+			name:            "store is deleted",
+			templateSize:    "1Gi",
+			stores:          upStores(v1alpha1.TiKVStateUp, 0),
+			wantRequeue:     true,
+			wantDeleteStore: true,
+		},
+		{
+			name:         "regions are being replicated",
+			templateSize: "1Gi",
+			stores:       upStores(v1alpha1.TiKVStateOffline, 0),
+			wantRequeue:  true,
+		},
+		{
+			name:         "not enough stores",
+			templateSize: "1Gi",
+			stores: map[string]v1alpha1.TiKVStore{
+				"1": newStore("1", 0, v1alpha1.TiKVStateUp, 10),
+				"2": newStore("2", 1, v1alpha1.TiKVStateUp, 10),
+				"3": newStore("3", 2, v1alpha1.TiKVStateUp, 10),
+			},
+		},
+		{
+			name:         "pod and PVCs are deleted after the store becomes tombstone",
+			templateSize: "1Gi",
+			stores: map[string]v1alpha1.TiKVStore{
+				"2": newStore("2", 1, v1alpha1.TiKVStateUp, 10),
+				"3": newStore("3", 2, v1alpha1.TiKVStateUp, 10),
+				"4": newStore("4", 3, v1alpha1.TiKVStateUp, 10),
+			},
+			tombstoneStores: map[string]v1alpha1.TiKVStore{
+				"1": newStore("1", 0, v1alpha1.TiKVStateTombstone, 0),
+			},
+			wantRequeue:       true,
+			wantEndEvict:      true,
+			wantPodPVCDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fakeDeps := controller.NewFakeDependencies()
+			for i := 0; i < 4; i++ {
+				size := "2Gi"
+				if i > 1 {
+					size = "1Gi"
+				}
+				pvc := newPVCWithStorage(fmt.Sprintf("tikv-tc-tikv-%d", i), label.TiKVLabelVal, "sc", size)
+				fakeDeps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+				pod := &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: v1.NamespaceDefault,
+						Name:      fmt.Sprintf("tc-tikv-%d", i),
+						Labels:    map[string]string{label.StoreIDLabelKey: fmt.Sprint(i + 1)},
+					},
+				}
+				fakeDeps.KubeClientset.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			}
+			set := &apps.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: v1.NamespaceDefault, Name: "tc-tikv"},
+				Spec: apps.StatefulSetSpec{
+					VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "tikv"},
+							Spec: v1.PersistentVolumeClaimSpec{
+								Resources: v1.ResourceRequirements{
+									Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(tt.templateSize)},
+								},
+							},
+						},
+					},
+				},
+			}
+			fakeDeps.KubeClientset.AppsV1().StatefulSets(set.Namespace).Create(context.TODO(), set, metav1.CreateOptions{})
+
+			informerFactory := fakeDeps.KubeInformerFactory
+			informerFactory.Start(ctx.Done())
+			informerFactory.WaitForCacheSync(ctx.Done())
+
+			tc := &v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: v1.NamespaceDefault, Name: "tc"},
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{
+						Replicas: int32(len(tt.stores)),
+						ResourceRequirements: v1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+						},
+						EnableVolumeReplace: true,
+					},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					TiKV: v1alpha1.TiKVStatus{
+						Stores:          tt.stores,
+						TombstoneStores: tt.tombstoneStores,
+					},
+				},
+			}
+
+			var evictLeader, deleteStore, endEvict bool
+			pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+			pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+				var replicas uint64 = 3
+				return &pdapi.PDConfigFromAPI{
+					Replication: &pdapi.PDReplicationConfig{MaxReplicas: &replicas},
+				}, nil
+			})
+			pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+				g.Expect(action.ID).To(Equal(uint64(1)))
+				evictLeader = true
+				return nil, nil
+			})
+			pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+				g.Expect(action.ID).To(Equal(uint64(1)))
+				deleteStore = true
+				return nil, nil
+			})
+			pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+				g.Expect(action.ID).To(Equal(uint64(1)))
+				endEvict = true
+				return nil, nil
+			})
+
+			resizer := NewPVCResizer(fakeDeps)
+			err := resizer.Resize(tc)
+			if tt.wantRequeue {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue(), "unexpected error: %v", err)
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+
+			_, err = fakeDeps.KubeClientset.AppsV1().StatefulSets(set.Namespace).Get(context.TODO(), set.Name, metav1.GetOptions{})
+			g.Expect(apierrors.IsNotFound(err)).To(Equal(tt.wantStsDeleted))
+			g.Expect(evictLeader).To(Equal(tt.wantEvictLeader))
+			g.Expect(deleteStore).To(Equal(tt.wantDeleteStore))
+			g.Expect(endEvict).To(Equal(tt.wantEndEvict))
+
+			_, err = fakeDeps.PodLister.Pods(v1.NamespaceDefault).Get("tc-tikv-0")
+			g.Expect(apierrors.IsNotFound(err)).To(Equal(tt.wantPodPVCDeleted))
+			_, err = fakeDeps.PVCLister.PersistentVolumeClaims(v1.NamespaceDefault).Get("tikv-tc-tikv-0")
+			g.Expect(apierrors.IsNotFound(err)).To(Equal(tt.wantPodPVCDeleted))
+			// the PVCs are never patched to a smaller size
+			pvc, err := fakeDeps.KubeClientset.CoreV1().PersistentVolumeClaims(v1.NamespaceDefault).Get(context.TODO(), "tikv-tc-tikv-1", metav1.GetOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("2Gi"))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	timezoneDataVolumeName = "timezone-data"
+	timezoneDataPath       = "/usr/share/zoneinfo"
+)
+
+// addTimezoneData mounts the time zone database of the node to the component container read-only
+// if mountTimezoneData is enabled for the component
+func addTimezoneData(spec v1alpha1.ComponentAccessor, podSpec *corev1.PodSpec, containerName string) {
+	if !spec.MountTimezoneData() {
+		return
+	}
+	hostPathType := corev1.HostPathDirectory
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: timezoneDataVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{Path: timezoneDataPath, Type: &hostPathType},
+		},
+	})
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != containerName {
+			continue
+		}
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      timezoneDataVolumeName,
+			MountPath: timezoneDataPath,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// syncTimezoneChange notes the time zone change of the component in the TimezoneUpdating condition
+// of the TidbCluster. The change of the TZ env is applied by the rolling restart of the upgrader as
+// the other changes of the Pod template, the note is removed once the StatefulSet is not upgrading.
+func syncTimezoneChange(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, oldSet, newSet *apps.StatefulSet) {
+	if oldSet == nil {
+		return
+	}
+	oldTZ := getContainerEnvValue(oldSet, memberType.String(), "TZ")
+	newTZ := getContainerEnvValue(newSet, memberType.String(), "TZ")
+	if oldTZ != newTZ {
+		msg := fmt.Sprintf("rolling restart to change the time zone from %q to %q", oldTZ, newTZ)
+		if setTimezoneUpdating(tc, memberType, msg) {
+			deps.Recorder.Eventf(tc, corev1.EventTypeNormal, utiltidbcluster.TimezoneChanged, "%s: %s", memberType, msg)
+		}
+		return
+	}
+	if !mngerutils.StatefulSetIsUpgrading(oldSet) {
+		setTimezoneUpdating(tc, memberType, "")
+	}
+}
+
+// setTimezoneUpdating sets the note of the component in the TimezoneUpdating condition,
+// or removes it if the note is empty. It returns whether the note is changed.
+func setTimezoneUpdating(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, note string) bool {
+	return setComponentConditionEntry(tc, v1alpha1.TidbClusterTimezoneUpdating, utiltidbcluster.TimezoneChanged, memberType, note)
+}
+
+func getContainerEnvValue(set *apps.StatefulSet, containerName, name string) string {
+	for _, c := range set.Spec.Template.Spec.Containers {
+		if c.Name != containerName {
+			continue
+		}
+		for _, env := range c.Env {
+			if env.Name == name {
+				return env.Value
+			}
+		}
+	}
+	return ""
+}
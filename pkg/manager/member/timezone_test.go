@@ -0,0 +1,84 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func newStatefulSetWithTimezone(tz string) *apps.StatefulSet {
+	set := &apps.StatefulSet{}
+	set.Spec.Replicas = pointer.Int32Ptr(3)
+	set.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: v1alpha1.TiKVMemberType.String(),
+		Env:  []corev1.EnvVar{{Name: "TZ", Value: tz}},
+	}}
+	return set
+}
+
+func TestSyncTimezoneChange(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := &v1alpha1.TidbCluster{}
+
+	// the creation is not a change
+	syncTimezoneChange(deps, tc, v1alpha1.TiKVMemberType, nil, newStatefulSetWithTimezone("UTC"))
+	g.Expect(tc.Status.Conditions).To(BeEmpty())
+
+	syncTimezoneChange(deps, tc, v1alpha1.TiKVMemberType, newStatefulSetWithTimezone("UTC"), newStatefulSetWithTimezone("Asia/Shanghai"))
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterTimezoneUpdating)
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Reason).To(Equal(utiltidbcluster.TimezoneChanged))
+	g.Expect(cond.Message).To(Equal(`tikv: rolling restart to change the time zone from "UTC" to "Asia/Shanghai"`))
+	g.Expect(deps.Recorder.(*record.FakeRecorder).Events).To(HaveLen(1))
+
+	// the note is kept during the rolling restart
+	upgrading := newStatefulSetWithTimezone("Asia/Shanghai")
+	upgrading.Status.CurrentRevision = "1"
+	upgrading.Status.UpdateRevision = "2"
+	syncTimezoneChange(deps, tc, v1alpha1.TiKVMemberType, upgrading, newStatefulSetWithTimezone("Asia/Shanghai"))
+	g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterTimezoneUpdating)).NotTo(BeNil())
+
+	upgrading.Status.CurrentRevision = "2"
+	syncTimezoneChange(deps, tc, v1alpha1.TiKVMemberType, upgrading, newStatefulSetWithTimezone("Asia/Shanghai"))
+	g.Expect(tc.Status.Conditions).To(BeEmpty())
+}
+
+func TestAddTimezoneData(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKV()
+	podSpec := corev1.PodSpec{Containers: []corev1.Container{{Name: "tikv"}, {Name: "sidecar"}}}
+	addTimezoneData(tc.BaseTiKVSpec(), &podSpec, v1alpha1.TiKVMemberType.String())
+	g.Expect(podSpec.Volumes).To(BeEmpty())
+
+	tc.Spec.MountTimezoneData = pointer.BoolPtr(true)
+	addTimezoneData(tc.BaseTiKVSpec(), &podSpec, v1alpha1.TiKVMemberType.String())
+	g.Expect(podSpec.Volumes).To(HaveLen(1))
+	g.Expect(podSpec.Volumes[0].HostPath.Path).To(Equal("/usr/share/zoneinfo"))
+	g.Expect(podSpec.Containers[0].VolumeMounts).To(Equal([]corev1.VolumeMount{
+		{Name: "timezone-data", MountPath: "/usr/share/zoneinfo", ReadOnly: true},
+	}))
+	g.Expect(podSpec.Containers[1].VolumeMounts).To(BeEmpty())
+}
@@ -0,0 +1,651 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	tiproxySQLPort        = 6000
+	tiproxyAPIPort        = 3080
+	tiproxyConfigPath     = "/etc/proxy"
+	tiproxyCertPath       = "/var/lib/tiproxy-tls"
+	tiproxyCertVolumeName = "tiproxy-tls"
+	tiproxySQLCertPath    = "/var/lib/tidb-client-tls"
+	tiproxySQLCertVolName = "tidb-client-tls"
+)
+
+// tiproxyMemberManager implements manager.Manager.
+type tiproxyMemberManager struct {
+	deps                     *controller.Dependencies
+	scaler                   Scaler
+	upgrader                 Upgrader
+	statefulSetIsUpgradingFn func(corelisters.PodLister, *apps.StatefulSet, *v1alpha1.TidbCluster) (bool, error)
+}
+
+// NewTiProxyMemberManager returns a *tiproxyMemberManager
+func NewTiProxyMemberManager(deps *controller.Dependencies, scaler Scaler, upgrader Upgrader) manager.Manager {
+	m := &tiproxyMemberManager{
+		deps:     deps,
+		scaler:   scaler,
+		upgrader: upgrader,
+	}
+	m.statefulSetIsUpgradingFn = tiproxyStatefulSetIsUpgrading
+	return m
+}
+
+// Sync fulfills the manager.Manager interface
+func (m *tiproxyMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.TiProxy == nil {
+		return nil
+	}
+
+	if err := m.syncTiProxyHeadlessService(tc); err != nil {
+		return err
+	}
+
+	if err := m.syncTiProxyService(tc); err != nil {
+		return err
+	}
+
+	return m.syncStatefulSet(tc)
+}
+
+func (m *tiproxyMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	oldStsTmp, err := m.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiProxyMemberName(tcName))
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("syncStatefulSet: failed to get sts %s for cluster %s/%s, error: %s", controller.TiProxyMemberName(tcName), ns, tcName, err)
+	}
+
+	stsNotExist := errors.IsNotFound(err)
+	oldSts := oldStsTmp.DeepCopy()
+
+	// failed to sync tiproxy status will not affect subsequent logic, just print the errors.
+	if err := m.syncTiProxyStatus(tc, oldSts); err != nil {
+		klog.Errorf("failed to sync TidbCluster: [%s/%s]'s tiproxy status, error: %v",
+			ns, tcName, err)
+	}
+
+	if tc.Spec.Paused {
+		klog.Infof("TidbCluster %s/%s is paused, skip syncing tiproxy statefulset", tc.GetNamespace(), tc.GetName())
+		return nil
+	}
+
+	cm, err := m.syncTiProxyConfigMap(tc, oldSts)
+	if err != nil {
+		return err
+	}
+
+	newSts, err := getNewTiProxyStatefulSet(tc, cm)
+	if err != nil {
+		return err
+	}
+
+	if stsNotExist {
+		if tenantPolicyBlocksCreation(tc) {
+			klog.Infof("TidbCluster: %s/%s, the tenant policy is violated, skip creating the tiproxy statefulset", tc.GetNamespace(), tc.GetName())
+			return nil
+		}
+		if !tc.PDIsAvailable() {
+			klog.Infof("TidbCluster: %s/%s, waiting for PD cluster running", ns, tcName)
+			return nil
+		}
+		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newSts)
+		if err != nil {
+			return err
+		}
+		return m.deps.StatefulSetControl.CreateStatefulSet(tc, newSts)
+	}
+
+	if err := syncScaleOutResources(m.deps, tc, v1alpha1.TiProxyMemberType, oldSts, newSts); err != nil {
+		return err
+	}
+
+	// Scaling takes precedence over upgrading because:
+	// - if a pod fails in the upgrading, users may want to delete it or add
+	//   new replicas
+	// - it's ok to scale in the middle of upgrading (in statefulset controller
+	//   scaling takes precedence over upgrading too)
+	if err := m.scaler.Scale(tc, oldSts, newSts); err != nil {
+		return err
+	}
+
+	syncTimezoneChange(m.deps, tc, v1alpha1.TiProxyMemberType, oldSts, newSts)
+
+	if !templateEqual(newSts, oldSts) || tc.Status.TiProxy.Phase == v1alpha1.UpgradePhase {
+		if err := m.upgrader.Upgrade(tc, oldSts, newSts); err != nil {
+			return err
+		}
+	}
+
+	return mngerutils.UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSts, oldSts)
+}
+
+func (m *tiproxyMemberManager) syncTiProxyStatus(tc *v1alpha1.TidbCluster, sts *apps.StatefulSet) error {
+	if sts == nil {
+		// skip if not created yet
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	tc.Status.TiProxy.StatefulSet = &sts.Status
+	upgrading, err := m.statefulSetIsUpgradingFn(m.deps.PodLister, sts, tc)
+	if err != nil {
+		tc.Status.TiProxy.Synced = false
+		return err
+	}
+	if upgrading {
+		tc.Status.TiProxy.Phase = v1alpha1.UpgradePhase
+	} else {
+		tc.Status.TiProxy.Phase = v1alpha1.NormalPhase
+	}
+
+	// TiProxy has no state to be queried, so the health of a member is the readiness of its Pod
+	members := map[string]v1alpha1.TiProxyMember{}
+	for id := range helper.GetPodOrdinals(tc.Status.TiProxy.StatefulSet.Replicas, sts) {
+		podName := tiproxyPodName(tcName, id)
+		pod, err := m.deps.PodLister.Pods(ns).Get(podName)
+		if err != nil {
+			klog.Warningf("Failed to get Pod %s of [%s/%s], error: %v", podName, ns, tcName, err)
+			continue
+		}
+
+		member := v1alpha1.TiProxyMember{
+			Name:   podName,
+			Health: podutil.IsPodReady(pod),
+		}
+		oldMember, exist := tc.Status.TiProxy.Members[podName]
+		member.LastTransitionTime = metav1.Now()
+		if exist && oldMember.Health == member.Health {
+			member.LastTransitionTime = oldMember.LastTransitionTime
+		}
+		members[podName] = member
+	}
+
+	tc.Status.TiProxy.Members = members
+	tc.Status.TiProxy.Synced = tc.TiProxyAllMembersReady()
+
+	return nil
+}
+
+func (m *tiproxyMemberManager) syncTiProxyConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
+	newCm, err := getTiProxyConfigMap(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	var inUseName string
+	if set != nil {
+		inUseName = mngerutils.FindConfigMapVolume(&set.Spec.Template.Spec, func(name string) bool {
+			return strings.HasPrefix(name, controller.TiProxyMemberName(tc.Name))
+		})
+	}
+
+	klog.V(3).Info("get tiproxy in use config map name: ", inUseName)
+
+	err = mngerutils.UpdateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BaseTiProxySpec().ConfigUpdateStrategy(), inUseName, newCm)
+	if err != nil {
+		return nil, err
+	}
+	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
+}
+
+func (m *tiproxyMemberManager) syncTiProxyService(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.Paused {
+		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for tiproxy service", tc.GetNamespace(), tc.GetName())
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	newSvc := getNewTiProxyService(tc)
+	oldSvcTmp, err := m.deps.ServiceLister.Services(ns).Get(controller.TiProxyMemberName(tcName))
+	if errors.IsNotFound(err) {
+		err = controller.SetServiceLastAppliedConfigAnnotation(newSvc)
+		if err != nil {
+			return err
+		}
+		return m.deps.ServiceControl.CreateService(tc, newSvc)
+	}
+	if err != nil {
+		return fmt.Errorf("syncTiProxyService: failed to get svc %s for cluster %s/%s, error: %s", controller.TiProxyMemberName(tcName), ns, tcName, err)
+	}
+
+	oldSvc := oldSvcTmp.DeepCopy()
+
+	equal, err := controller.ServiceEqual(newSvc, oldSvc)
+	if err != nil {
+		return err
+	}
+	if !equal {
+		svc := *oldSvc
+		svc.Spec = newSvc.Spec
+		err = controller.SetServiceLastAppliedConfigAnnotation(&svc)
+		if err != nil {
+			return err
+		}
+		svc.Spec.ClusterIP = oldSvc.Spec.ClusterIP
+		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
+		return err
+	}
+
+	return nil
+}
+
+func (m *tiproxyMemberManager) syncTiProxyHeadlessService(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.Paused {
+		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for tiproxy headless service", tc.GetNamespace(), tc.GetName())
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	newSvc := getNewTiProxyHeadlessService(tc)
+	oldSvcTmp, err := m.deps.ServiceLister.Services(ns).Get(controller.TiProxyPeerMemberName(tcName))
+	if errors.IsNotFound(err) {
+		err = controller.SetServiceLastAppliedConfigAnnotation(newSvc)
+		if err != nil {
+			return err
+		}
+		return m.deps.ServiceControl.CreateService(tc, newSvc)
+	}
+	if err != nil {
+		return fmt.Errorf("syncTiProxyHeadlessService: failed to get svc %s for cluster %s/%s, error: %s", controller.TiProxyPeerMemberName(tcName), ns, tcName, err)
+	}
+
+	oldSvc := oldSvcTmp.DeepCopy()
+
+	equal, err := controller.ServiceEqual(newSvc, oldSvc)
+	if err != nil {
+		return err
+	}
+	if !equal {
+		svc := *oldSvc
+		svc.Spec = newSvc.Spec
+		err = controller.SetServiceLastAppliedConfigAnnotation(&svc)
+		if err != nil {
+			return err
+		}
+		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
+		return err
+	}
+
+	return nil
+}
+
+// getTiProxyConfigMap renders the config of TiProxy, the addresses and the certificates
+// are always overridden by the operator.
+func getTiProxyConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
+	cfg := v1alpha1.NewTiProxyConfig()
+	if tc.Spec.TiProxy.Config != nil && tc.Spec.TiProxy.Config.GenericConfig != nil {
+		cfg.GenericConfig = tc.Spec.TiProxy.Config.GenericConfig.DeepCopy()
+	}
+
+	cfg.Set("proxy.addr", fmt.Sprintf("0.0.0.0:%d", tiproxySQLPort))
+	cfg.Set("proxy.pd-addrs", fmt.Sprintf("%s:%d", controller.PDMemberName(tc.Name), tc.PDClientPort()))
+	cfg.Set("api.addr", fmt.Sprintf("0.0.0.0:%d", tiproxyAPIPort))
+
+	if tc.IsTLSClusterEnabled() {
+		cfg.Set("security.cluster-tls.ca", path.Join(tiproxyCertPath, corev1.ServiceAccountRootCAKey))
+		cfg.Set("security.cluster-tls.cert", path.Join(tiproxyCertPath, corev1.TLSCertKey))
+		cfg.Set("security.cluster-tls.key", path.Join(tiproxyCertPath, corev1.TLSPrivateKeyKey))
+	}
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.IsTLSClientEnabled() {
+		// TiProxy connects to TiDB with the client certificate, and serves the clients with the
+		// same server certificate as TiDB
+		cfg.Set("security.sql-tls.ca", path.Join(tiproxySQLCertPath, corev1.ServiceAccountRootCAKey))
+		cfg.Set("security.sql-tls.cert", path.Join(tiproxySQLCertPath, corev1.TLSCertKey))
+		cfg.Set("security.sql-tls.key", path.Join(tiproxySQLCertPath, corev1.TLSPrivateKeyKey))
+		cfg.Set("security.server-tls.ca", path.Join(serverCertPath, corev1.ServiceAccountRootCAKey))
+		cfg.Set("security.server-tls.cert", path.Join(serverCertPath, corev1.TLSCertKey))
+		cfg.Set("security.server-tls.key", path.Join(serverCertPath, corev1.TLSPrivateKeyKey))
+	}
+
+	confText, err := cfg.MarshalTOML()
+	if err != nil {
+		return nil, err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            controller.TiProxyMemberName(tc.Name),
+			Namespace:       tc.Namespace,
+			Labels:          labelTiProxy(tc).Labels(),
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Data: map[string]string{
+			"config-file": string(confText),
+		},
+	}
+	return cm, nil
+}
+
+func getNewTiProxyService(tc *v1alpha1.TidbCluster) *corev1.Service {
+	selector := labelTiProxy(tc)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            controller.TiProxyMemberName(tc.Name),
+			Namespace:       tc.Namespace,
+			Labels:          selector.Copy().UsedByEndUser().Labels(),
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "mysql-client",
+					Port:       tiproxySQLPort,
+					TargetPort: intstr.FromInt(tiproxySQLPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+				{
+					Name:       "api",
+					Port:       tiproxyAPIPort,
+					TargetPort: intstr.FromInt(tiproxyAPIPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+			Selector: selector.Labels(),
+		},
+	}
+
+	// override fields with user-defined ServiceSpec
+	svcSpec := tc.Spec.TiProxy.Service
+	if svcSpec != nil {
+		if svcSpec.Type != "" {
+			svc.Spec.Type = svcSpec.Type
+		}
+		svc.ObjectMeta.Annotations = util.CopyStringMap(svcSpec.Annotations)
+		svc.ObjectMeta.Labels = util.CombineStringMap(svc.ObjectMeta.Labels, svcSpec.Labels)
+		if svcSpec.LoadBalancerIP != nil {
+			svc.Spec.LoadBalancerIP = *svcSpec.LoadBalancerIP
+		}
+		if svcSpec.ClusterIP != nil {
+			svc.Spec.ClusterIP = *svcSpec.ClusterIP
+		}
+		if svcSpec.PortName != nil {
+			svc.Spec.Ports[0].Name = *svcSpec.PortName
+		}
+	}
+	return svc
+}
+
+func getNewTiProxyHeadlessService(tc *v1alpha1.TidbCluster) *corev1.Service {
+	selector := labelTiProxy(tc)
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            controller.TiProxyPeerMemberName(tc.Name),
+			Namespace:       tc.Namespace,
+			Labels:          selector.Copy().UsedByPeer().Labels(),
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "None",
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "api",
+					Port:       tiproxyAPIPort,
+					TargetPort: intstr.FromInt(tiproxyAPIPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+			Selector:                 selector.Labels(),
+			PublishNotReadyAddresses: true,
+		},
+	}
+}
+
+func getNewTiProxyStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.StatefulSet, error) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	baseTiProxySpec := tc.BaseTiProxySpec()
+	stsLabels := labelTiProxy(tc)
+	stsName := controller.TiProxyMemberName(tcName)
+	podLabels := util.CombineStringMap(stsLabels, baseTiProxySpec.Labels())
+	podAnnotations := util.CombineStringMap(controller.AnnProm(tiproxyAPIPort), baseTiProxySpec.Annotations())
+	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiProxyLabelVal)
+	headlessSvcName := controller.TiProxyPeerMemberName(tcName)
+
+	script := fmt.Sprintf("exec /bin/tiproxy --config=%s --advertise-addr=${POD_NAME}.${HEADLESS_SERVICE_NAME}.${NAMESPACE}.svc%s",
+		path.Join(tiproxyConfigPath, "proxy.toml"), controller.FormatClusterDomain(tc.Spec.ClusterDomain))
+
+	volMounts := []corev1.VolumeMount{
+		{Name: "config", ReadOnly: true, MountPath: tiproxyConfigPath},
+	}
+	vols := []corev1.Volume{
+		{
+			Name: "config", VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cm.Name,
+					},
+					Items: []corev1.KeyToPath{{Key: "config-file", Path: "proxy.toml"}},
+				}},
+		},
+	}
+
+	if tc.IsTLSClusterEnabled() {
+		volMounts = append(volMounts, corev1.VolumeMount{
+			Name: tiproxyCertVolumeName, ReadOnly: true, MountPath: tiproxyCertPath,
+		})
+		vols = append(vols, corev1.Volume{
+			Name: tiproxyCertVolumeName, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.ClusterTLSSecretName(tcName, label.TiProxyLabelVal),
+				},
+			},
+		})
+	}
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.IsTLSClientEnabled() {
+		volMounts = append(volMounts, corev1.VolumeMount{
+			Name: tiproxySQLCertVolName, ReadOnly: true, MountPath: tiproxySQLCertPath,
+		}, corev1.VolumeMount{
+			Name: "tidb-server-tls", ReadOnly: true, MountPath: serverCertPath,
+		})
+		vols = append(vols, corev1.Volume{
+			Name: tiproxySQLCertVolName, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.TiDBClientTLSSecretName(tcName),
+				},
+			},
+		}, corev1.Volume{
+			Name: "tidb-server-tls", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.TiDBServerTLSSecretName(tcName),
+				},
+			},
+		})
+	}
+	volMounts = append(volMounts, baseTiProxySpec.AdditionalVolumeMounts()...)
+
+	envs := []corev1.EnvVar{
+		{
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name: "NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.namespace",
+				},
+			},
+		},
+		{
+			Name:  "HEADLESS_SERVICE_NAME",
+			Value: headlessSvcName,
+		},
+		{
+			Name:  "TZ",
+			Value: baseTiProxySpec.Timezone(),
+		},
+	}
+
+	tiproxyContainer := corev1.Container{
+		Name:            v1alpha1.TiProxyMemberType.String(),
+		Image:           tc.TiProxyImage(),
+		ImagePullPolicy: baseTiProxySpec.ImagePullPolicy(),
+		Command:         []string{"/bin/sh", "-c", script},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "mysql-client",
+				ContainerPort: tiproxySQLPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+			{
+				Name:          "api",
+				ContainerPort: tiproxyAPIPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		VolumeMounts: volMounts,
+		Resources:    controller.ContainerResource(tc.Spec.TiProxy.ResourceRequirements),
+		Env:          util.AppendEnv(envs, baseTiProxySpec.Env()),
+		ReadinessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(tiproxySQLPort),
+				},
+			},
+			InitialDelaySeconds: 10,
+		},
+	}
+
+	podSpec := baseTiProxySpec.BuildPodSpec()
+	podSpec.Containers = []corev1.Container{tiproxyContainer}
+	podSpec.Volumes = append(vols, baseTiProxySpec.AdditionalVolumes()...)
+	addTimezoneData(baseTiProxySpec, &podSpec, v1alpha1.TiProxyMemberType.String())
+	podSpec.ServiceAccountName = tc.Spec.TiProxy.ServiceAccount
+	podSpec.InitContainers = append(podSpec.InitContainers, baseTiProxySpec.InitContainers()...)
+	if podSpec.ServiceAccountName == "" {
+		podSpec.ServiceAccountName = tc.Spec.ServiceAccount
+	}
+
+	updateStrategy := apps.StatefulSetUpdateStrategy{}
+	if baseTiProxySpec.StatefulSetUpdateStrategy() == apps.OnDeleteStatefulSetStrategyType {
+		updateStrategy.Type = apps.OnDeleteStatefulSetStrategyType
+	} else {
+		updateStrategy.Type = apps.RollingUpdateStatefulSetStrategyType
+		updateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{
+			Partition: pointer.Int32Ptr(tc.TiProxyDeployDesiredReplicas()),
+		}
+	}
+
+	tiproxySts := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            stsName,
+			Namespace:       ns,
+			Labels:          stsLabels.Labels(),
+			Annotations:     stsAnnotations,
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: apps.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(tc.TiProxyDeployDesiredReplicas()),
+			Selector: stsLabels.LabelSelector(),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      podLabels,
+					Annotations: podAnnotations,
+				},
+				Spec: podSpec,
+			},
+			ServiceName:         headlessSvcName,
+			PodManagementPolicy: baseTiProxySpec.PodManagementPolicy(),
+			UpdateStrategy:      updateStrategy,
+		},
+	}
+	return tiproxySts, nil
+}
+
+func labelTiProxy(tc *v1alpha1.TidbCluster) label.Label {
+	instanceName := tc.GetInstanceName()
+	return label.New().Instance(instanceName).TiProxy()
+}
+
+func tiproxyStatefulSetIsUpgrading(podLister corelisters.PodLister, set *apps.StatefulSet, tc *v1alpha1.TidbCluster) (bool, error) {
+	if mngerutils.StatefulSetIsUpgrading(set) {
+		return true, nil
+	}
+	selector, err := labelTiProxy(tc).Selector()
+	if err != nil {
+		return false, err
+	}
+	tiproxyPods, err := podLister.Pods(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return false, fmt.Errorf("tiproxyStatefulSetIsUpgrading: failed to list pods for cluster %s/%s, selector %s, error: %s", tc.GetNamespace(), tc.GetName(), selector, err)
+	}
+	for _, pod := range tiproxyPods {
+		revisionHash, exist := pod.Labels[apps.ControllerRevisionHashLabelKey]
+		if !exist {
+			return false, nil
+		}
+		if revisionHash != tc.Status.TiProxy.StatefulSet.UpdateRevision {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type FakeTiProxyMemberManager struct {
+	err error
+}
+
+func NewFakeTiProxyMemberManager() *FakeTiProxyMemberManager {
+	return &FakeTiProxyMemberManager{}
+}
+
+func (m *FakeTiProxyMemberManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeTiProxyMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if m.err != nil {
+		return m.err
+	}
+	return nil
+}
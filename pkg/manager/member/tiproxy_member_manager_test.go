@@ -0,0 +1,234 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestTiProxyMemberManagerSyncCreate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	type testcase struct {
+		name    string
+		tls     bool
+		errSync bool
+		err     bool
+	}
+
+	testFn := func(test *testcase, t *testing.T) {
+		t.Log(test.name)
+
+		tc := newTidbClusterForTiProxy()
+		if test.tls {
+			tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+		}
+		ns := tc.GetNamespace()
+		tcName := tc.GetName()
+		oldSpec := tc.Spec
+
+		tmm, fakeSetControl := newFakeTiProxyMemberManager()
+		if test.errSync {
+			fakeSetControl.SetCreateStatefulSetError(errors.NewInternalError(fmt.Errorf("API server failed")), 0)
+		}
+
+		err := tmm.Sync(tc)
+		if test.err {
+			g.Expect(err).To(HaveOccurred())
+			return
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(tc.Spec).To(Equal(oldSpec))
+
+		_, err = tmm.deps.ServiceLister.Services(ns).Get(controller.TiProxyMemberName(tcName))
+		g.Expect(err).NotTo(HaveOccurred())
+		_, err = tmm.deps.ServiceLister.Services(ns).Get(controller.TiProxyPeerMemberName(tcName))
+		g.Expect(err).NotTo(HaveOccurred())
+		sts, err := tmm.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiProxyMemberName(tcName))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(*sts.Spec.Replicas).To(Equal(int32(2)))
+
+		hasTLSVolume := false
+		for _, vol := range sts.Spec.Template.Spec.Volumes {
+			if vol.Name == tiproxyCertVolumeName {
+				hasTLSVolume = true
+				g.Expect(vol.Secret.SecretName).To(Equal("test-tiproxy-cluster-secret"))
+			}
+		}
+		g.Expect(hasTLSVolume).To(Equal(test.tls))
+	}
+
+	tests := []testcase{
+		{
+			name: "normal",
+		},
+		{
+			name: "normal with tls",
+			tls:  true,
+		},
+		{
+			name:    "error when sync",
+			errSync: true,
+			err:     true,
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i], t)
+	}
+}
+
+func TestGetTiProxyConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiProxy()
+	tc.Spec.TiProxy.Config = v1alpha1.NewTiProxyConfig()
+	tc.Spec.TiProxy.Config.Set("proxy.max-connections", 100)
+	tc.Spec.TiProxy.Config.Set("proxy.addr", "0.0.0.0:4000")
+	cm, err := getTiProxyConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Name).To(Equal("test-tiproxy"))
+	g.Expect(cm.Data["config-file"]).To(Equal(`[api]
+  addr = "0.0.0.0:3080"
+
+[proxy]
+  addr = "0.0.0.0:6000"
+  max-connections = 100
+  pd-addrs = "test-pd:2379"
+`))
+	// the user config is not modified
+	g.Expect(tc.Spec.TiProxy.Config.Get("proxy.addr").MustString()).To(Equal("0.0.0.0:4000"))
+
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	tc.Spec.TiDB = &v1alpha1.TiDBSpec{TLSClient: &v1alpha1.TiDBTLSClient{Enabled: true}}
+	cm, err = getTiProxyConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(`[security.cluster-tls]
+    ca = "/var/lib/tiproxy-tls/ca.crt"`))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(`[security.server-tls]
+    ca = "/var/lib/tidb-server-tls/ca.crt"`))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(`[security.sql-tls]
+    ca = "/var/lib/tidb-client-tls/ca.crt"`))
+}
+
+func TestGetNewTiProxyService(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiProxy()
+	svc := getNewTiProxyService(tc)
+	g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+	g.Expect(svc.Spec.Ports).To(HaveLen(2))
+
+	tc.Spec.TiProxy.Service = &v1alpha1.ServiceSpec{
+		Type:           corev1.ServiceTypeLoadBalancer,
+		Annotations:    map[string]string{"lb-type": "nlb"},
+		LoadBalancerIP: pointer.StringPtr("10.0.0.1"),
+		PortName:       pointer.StringPtr("mysql"),
+	}
+	svc = getNewTiProxyService(tc)
+	g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+	g.Expect(svc.Annotations).To(Equal(map[string]string{"lb-type": "nlb"}))
+	g.Expect(svc.Spec.LoadBalancerIP).To(Equal("10.0.0.1"))
+	g.Expect(svc.Spec.Ports[0].Name).To(Equal("mysql"))
+}
+
+func TestTiProxyMemberManagerSyncTiProxyStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tmm, _ := newFakeTiProxyMemberManager()
+	tc := newTidbClusterForTiProxy()
+	sts := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tiproxy", Namespace: corev1.NamespaceDefault},
+		Spec:       apps.StatefulSetSpec{Replicas: pointer.Int32Ptr(2)},
+		Status:     apps.StatefulSetStatus{Replicas: 2, CurrentRevision: "1", UpdateRevision: "1"},
+	}
+	podIndexer := tmm.deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	for i, ready := range []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse} {
+		lbs := label.New().Instance(tc.GetInstanceName()).TiProxy().Labels()
+		lbs[apps.ControllerRevisionHashLabelKey] = "1"
+		podIndexer.Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      tiproxyPodName(tc.Name, int32(i)),
+				Namespace: corev1.NamespaceDefault,
+				Labels:    lbs,
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		})
+	}
+
+	g.Expect(tmm.syncTiProxyStatus(tc, sts)).To(Succeed())
+	g.Expect(tc.Status.TiProxy.Phase).To(Equal(v1alpha1.NormalPhase))
+	g.Expect(tc.Status.TiProxy.Members).To(HaveLen(2))
+	g.Expect(tc.Status.TiProxy.Members["test-tiproxy-0"].Health).To(BeTrue())
+	g.Expect(tc.Status.TiProxy.Members["test-tiproxy-1"].Health).To(BeFalse())
+	g.Expect(tc.Status.TiProxy.Synced).To(BeFalse())
+
+	// the transition time is kept while the health doesn't change
+	transitionTime := metav1.NewTime(tc.Status.TiProxy.Members["test-tiproxy-0"].LastTransitionTime.Add(-time.Minute))
+	member := tc.Status.TiProxy.Members["test-tiproxy-0"]
+	member.LastTransitionTime = transitionTime
+	tc.Status.TiProxy.Members["test-tiproxy-0"] = member
+	sts.Status.UpdateRevision = "2"
+	g.Expect(tmm.syncTiProxyStatus(tc, sts)).To(Succeed())
+	g.Expect(tc.Status.TiProxy.Phase).To(Equal(v1alpha1.UpgradePhase))
+	g.Expect(tc.Status.TiProxy.Members["test-tiproxy-0"].LastTransitionTime).To(Equal(transitionTime))
+}
+
+func newFakeTiProxyMemberManager() (*tiproxyMemberManager, *controller.FakeStatefulSetControl) {
+	fakeDeps := controller.NewFakeDependencies()
+	tmm := &tiproxyMemberManager{
+		deps:     fakeDeps,
+		scaler:   NewTiProxyScaler(fakeDeps),
+		upgrader: NewTiProxyUpgrader(fakeDeps),
+	}
+	tmm.statefulSetIsUpgradingFn = tiproxyStatefulSetIsUpgrading
+	setControl := fakeDeps.StatefulSetControl.(*controller.FakeStatefulSetControl)
+	return tmm, setControl
+}
+
+func newTidbClusterForTiProxy() *v1alpha1.TidbCluster {
+	return &v1alpha1.TidbCluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "TidbCluster",
+			APIVersion: "pingcap.com/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: corev1.NamespaceDefault,
+			UID:       types.UID("test"),
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiProxy: &v1alpha1.TiProxySpec{
+				ComponentSpec: v1alpha1.ComponentSpec{
+					Image: v1alpha1.TiProxyMemberType.String(),
+				},
+				Replicas: 2,
+			},
+		},
+	}
+}
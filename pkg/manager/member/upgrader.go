@@ -14,8 +14,13 @@
 package member
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 )
 
 // Upgrader implements the logic for upgrading the tidb cluster.
@@ -28,3 +33,21 @@ type Upgrader interface {
 type DMUpgrader interface {
 	Upgrade(*v1alpha1.DMCluster, *apps.StatefulSet, *apps.StatefulSet) error
 }
+
+// checkUpgradedPodPacing returns why the rolling update can't move on from the upgraded Pod yet, according to
+// the minReadySeconds and rollingUpdateIntervalSeconds of the component, or an empty string if it can
+func checkUpgradedPodPacing(spec v1alpha1.ComponentAccessor, pod *corev1.Pod) string {
+	now := time.Now()
+	if interval := spec.RollingUpdateIntervalSeconds(); interval > 0 {
+		if now.Before(pod.CreationTimestamp.Add(time.Duration(interval) * time.Second)) {
+			return fmt.Sprintf("was created less than %ds ago", interval)
+		}
+	}
+	if minReady := spec.MinReadySeconds(); minReady > 0 {
+		cond := podutil.GetPodReadyCondition(pod.Status)
+		if cond == nil || cond.Status != corev1.ConditionTrue || now.Before(cond.LastTransitionTime.Add(time.Duration(minReady)*time.Second)) {
+			return fmt.Sprintf("has been ready for less than %ds", minReady)
+		}
+	}
+	return ""
+}
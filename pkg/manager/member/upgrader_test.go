@@ -0,0 +1,51 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestCheckUpgradedPodPacing(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{Spec: v1alpha1.TidbClusterSpec{PD: &v1alpha1.PDSpec{}}}
+	newPod := func(created, ready time.Duration, status corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Now().Add(-created))},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type: corev1.PodReady, Status: status, LastTransitionTime: metav1.NewTime(time.Now().Add(-ready)),
+			}}},
+		}
+	}
+
+	// no pacing by default
+	g.Expect(checkUpgradedPodPacing(tc.BasePDSpec(), newPod(0, 0, corev1.ConditionTrue))).To(BeEmpty())
+
+	tc.Spec.PD.MinReadySeconds = pointer.Int32Ptr(60)
+	g.Expect(checkUpgradedPodPacing(tc.BasePDSpec(), newPod(2*time.Minute, 30*time.Second, corev1.ConditionTrue))).To(Equal("has been ready for less than 60s"))
+	g.Expect(checkUpgradedPodPacing(tc.BasePDSpec(), newPod(2*time.Minute, 90*time.Second, corev1.ConditionFalse))).To(Equal("has been ready for less than 60s"))
+	g.Expect(checkUpgradedPodPacing(tc.BasePDSpec(), newPod(2*time.Minute, 90*time.Second, corev1.ConditionTrue))).To(BeEmpty())
+
+	tc.Spec.PD.RollingUpdateIntervalSeconds = pointer.Int32Ptr(300)
+	g.Expect(checkUpgradedPodPacing(tc.BasePDSpec(), newPod(2*time.Minute, 90*time.Second, corev1.ConditionTrue))).To(Equal("was created less than 300s ago"))
+	g.Expect(checkUpgradedPodPacing(tc.BasePDSpec(), newPod(10*time.Minute, 90*time.Second, corev1.ConditionTrue))).To(BeEmpty())
+}
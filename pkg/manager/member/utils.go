@@ -14,9 +14,11 @@
 package member
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -133,6 +135,10 @@ func ticdcPodName(tcName string, ordinal int32) string {
 	return fmt.Sprintf("%s-%d", controller.TiCDCMemberName(tcName), ordinal)
 }
 
+func tiproxyPodName(tcName string, ordinal int32) string {
+	return fmt.Sprintf("%s-%d", controller.TiProxyMemberName(tcName), ordinal)
+}
+
 func DMMasterPodName(dcName string, ordinal int32) string {
 	return fmt.Sprintf("%s-%d", controller.DMMasterMemberName(dcName), ordinal)
 }
@@ -165,6 +171,36 @@ func UnmarshalTOML(b []byte, obj interface{}) error {
 	return toml.Unmarshal(b, obj)
 }
 
+// setStartScriptVersionAnnotation records the version of the start script in the Pod annotations.
+// It is not recorded for v1 to avoid rolling-updating the existing clusters.
+func setStartScriptVersionAnnotation(podAnnotations map[string]string, version v1alpha1.StartScriptVersion) {
+	if version != v1alpha1.StartScriptV1 {
+		podAnnotations[label.AnnStartScriptVersion] = string(version)
+	}
+}
+
+// secretDataHash returns the hash of the data in the secret, which changes once the data is updated
+func secretDataHash(secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%x;", k, secret.Data[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
+}
+
+// getStartScriptVersion returns the version of the start script used by the StatefulSet
+func getStartScriptVersion(set *apps.StatefulSet) v1alpha1.StartScriptVersion {
+	if version, ok := set.Spec.Template.Annotations[label.AnnStartScriptVersion]; ok {
+		return v1alpha1.StartScriptVersion(version)
+	}
+	return v1alpha1.StartScriptV1
+}
+
 // getStsAnnotations gets annotations for statefulset of given component.
 func getStsAnnotations(tcAnns map[string]string, component string) map[string]string {
 	anns := map[string]string{}
@@ -197,6 +233,39 @@ func getStsAnnotations(tcAnns map[string]string, component string) map[string]st
 	return anns
 }
 
+// getTidbClusterStsAnnotations gets annotations for statefulset of given component of the TidbCluster,
+// the delete slots in the component spec are merged into the delete-slots annotation.
+func getTidbClusterStsAnnotations(tc *v1alpha1.TidbCluster, component string) map[string]string {
+	anns := getStsAnnotations(tc.Annotations, component)
+	deleteSlots := tc.DeleteSlots(component)
+	if deleteSlots.Len() == 0 {
+		return anns
+	}
+	b, err := json.Marshal(deleteSlots.List())
+	if err != nil {
+		klog.Errorf("failed to marshal delete slots %v of %s of tc %s/%s, error: %v", deleteSlots.List(), component, tc.Namespace, tc.Name, err)
+		return anns
+	}
+	anns[helper.DeleteSlotsAnn] = string(b)
+	return anns
+}
+
+// setVolumeClaimCloudTags sets the cloud tags of the TidbCluster to the annotations of the volume claim templates,
+// so that the volumes provisioned for them are tagged by the CSI driver
+func setVolumeClaimCloudTags(tc *v1alpha1.TidbCluster, pvcs []corev1.PersistentVolumeClaim) {
+	if len(tc.Spec.CloudTags) == 0 {
+		return
+	}
+	for i := range pvcs {
+		if pvcs[i].Annotations == nil {
+			pvcs[i].Annotations = map[string]string{}
+		}
+		for k, v := range tc.Spec.CloudTags {
+			pvcs[i].Annotations[k] = v
+		}
+	}
+}
+
 // MapContainers index containers of Pod by container name in favor of looking up
 func MapContainers(podSpec *corev1.PodSpec) map[string]corev1.Container {
 	m := map[string]corev1.Container{}
@@ -223,6 +292,10 @@ func getTikVConfigMapForTiKVSpec(tikvSpec *v1alpha1.TiKVSpec, tc *v1alpha1.TidbC
 		config.Set("security.cert-path", path.Join(tikvClusterCertPath, corev1.TLSCertKey))
 		config.Set("security.key-path", path.Join(tikvClusterCertPath, corev1.TLSPrivateKeyKey))
 	}
+	if config != nil {
+		setTiKVEncryptionConfig(tc, config.GenericConfig)
+		config = &v1alpha1.TiKVConfigWraper{GenericConfig: withConfigProfile(tc, v1alpha1.TiKVMemberType, config.GenericConfig)}
+	}
 	confText, err := config.MarshalTOML()
 	if err != nil {
 		return nil, err
@@ -458,7 +531,11 @@ func parseImage(image string) (string, string) {
 var ErrNotFoundStoreID = fmt.Errorf("not found")
 
 func TiKVStoreIDFromStatus(tc *v1alpha1.TidbCluster, podName string) (uint64, error) {
-	for _, store := range tc.Status.TiKV.Stores {
+	stores := tc.Status.TiKV.Stores
+	if strings.HasPrefix(podName, controller.TiKVColdMemberName(tc.Name)+"-") {
+		stores = tc.Status.TiKVCold.Stores
+	}
+	for _, store := range stores {
 		if store.PodName == podName {
 			storeID, err := strconv.ParseUint(store.ID, 10, 64)
 			if err != nil {
@@ -97,6 +97,28 @@ func TestGetStsAnnotations(t *testing.T) {
 	}
 }
 
+func TestGetTidbClusterStsAnnotations(t *testing.T) {
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				label.AnnTiKVDeleteSlots: "[3,1]",
+			},
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiDB: &v1alpha1.TiDBSpec{},
+			TiKV: &v1alpha1.TiKVSpec{
+				DeleteSlots: []int32{2, 1},
+			},
+		},
+	}
+	if diff := cmp.Diff(map[string]string{helper.DeleteSlotsAnn: "[1,2,3]"}, getTidbClusterStsAnnotations(tc, label.TiKVLabelVal)); diff != "" {
+		t.Errorf("unexpected (-want, +got): %s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{}, getTidbClusterStsAnnotations(tc, label.TiDBLabelVal)); diff != "" {
+		t.Errorf("unexpected (-want, +got): %s", diff)
+	}
+}
+
 func TestShouldRecover(t *testing.T) {
 	notReadyPods := []*v1.Pod{
 		{
@@ -0,0 +1,99 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// volumeZoneLabels are the labels of the zone of the PVs, in the order of precedence
+var volumeZoneLabels = []string{corev1.LabelZoneFailureDomainStable, corev1.LabelZoneFailureDomain}
+
+// getVolumeTopology returns the zone and the node the PVs of the Pod are bound to, which are collected from
+// the node affinity and the labels of the PVs. It returns nil if the PVs are not bound to any zone or node,
+// or if the Pod, the PVCs or the PVs can not be found. The failures are only logged, as the topology is
+// informational and must not block the sync of the status.
+func getVolumeTopology(deps *controller.Dependencies, ns, podName string) *v1alpha1.VolumeTopology {
+	if deps.PVLister == nil {
+		return nil
+	}
+	pod, err := deps.PodLister.Pods(ns).Get(podName)
+	if err != nil {
+		klog.V(4).Infof("failed to get pod %s/%s for the volume topology: %v", ns, podName, err)
+		return nil
+	}
+
+	topology := &v1alpha1.VolumeTopology{}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := deps.PVCLister.PersistentVolumeClaims(ns).Get(vol.PersistentVolumeClaim.ClaimName)
+		if err != nil || pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := deps.PVLister.Get(pvc.Spec.VolumeName)
+		if err != nil {
+			klog.V(4).Infof("failed to get pv %s of pod %s/%s for the volume topology: %v", pvc.Spec.VolumeName, ns, podName, err)
+			continue
+		}
+		zone, node := pvTopology(pv)
+		if topology.Zone == "" {
+			topology.Zone = zone
+		}
+		if topology.Node == "" {
+			topology.Node = node
+		}
+	}
+	if topology.Zone == "" && topology.Node == "" {
+		return nil
+	}
+	return topology
+}
+
+// pvTopology returns the zone and the node the PV is bound to by its node affinity, the zone falls back
+// to the labels of the PV set by the cloud providers
+func pvTopology(pv *corev1.PersistentVolume) (zone, node string) {
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+			for _, expr := range term.MatchExpressions {
+				if expr.Operator != corev1.NodeSelectorOpIn || len(expr.Values) != 1 {
+					continue
+				}
+				switch expr.Key {
+				case corev1.LabelHostname:
+					if node == "" {
+						node = expr.Values[0]
+					}
+				case corev1.LabelZoneFailureDomainStable, corev1.LabelZoneFailureDomain:
+					if zone == "" {
+						zone = expr.Values[0]
+					}
+				}
+			}
+		}
+	}
+	if zone == "" {
+		for _, key := range volumeZoneLabels {
+			if z := pv.Labels[key]; z != "" {
+				zone = z
+				break
+			}
+		}
+	}
+	return zone, node
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetVolumeTopology(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	informers := fakeDeps.KubeInformerFactory.Core().V1()
+	addPod := func(podName string, pvs ...*corev1.PersistentVolume) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: "ns"}}
+		for _, pv := range pvs {
+			pvcName := "data-" + pv.Name
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+				Name:         pvcName,
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName}},
+			})
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "ns"},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: pv.Name},
+			}
+			g.Expect(informers.PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)).To(Succeed())
+			g.Expect(informers.PersistentVolumes().Informer().GetIndexer().Add(pv)).To(Succeed())
+		}
+		g.Expect(informers.Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+	}
+	newPV := func(name string, labels map[string]string, affinity map[string]string) *corev1.PersistentVolume {
+		pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		if len(affinity) > 0 {
+			term := corev1.NodeSelectorTerm{}
+			for k, v := range affinity {
+				term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+					Key:      k,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{v},
+				})
+			}
+			pv.Spec.NodeAffinity = &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{term}}}
+		}
+		return pv
+	}
+
+	// local PV bound to a node
+	addPod("local-0", newPV("pv-local", nil, map[string]string{corev1.LabelHostname: "node-1"}))
+	// cloud disk bound to a zone by the label
+	addPod("cloud-0", newPV("pv-cloud", map[string]string{corev1.LabelZoneFailureDomain: "zone-a"}, nil))
+	// the zone and the node are collected from different PVs
+	addPod("mixed-0",
+		newPV("pv-zone", nil, map[string]string{corev1.LabelZoneFailureDomainStable: "zone-b"}),
+		newPV("pv-node", nil, map[string]string{corev1.LabelHostname: "node-2"}))
+	// PV without topology
+	addPod("none-0", newPV("pv-none", nil, nil))
+
+	g.Expect(getVolumeTopology(fakeDeps, "ns", "local-0")).To(Equal(&v1alpha1.VolumeTopology{Node: "node-1"}))
+	g.Expect(getVolumeTopology(fakeDeps, "ns", "cloud-0")).To(Equal(&v1alpha1.VolumeTopology{Zone: "zone-a"}))
+	g.Expect(getVolumeTopology(fakeDeps, "ns", "mixed-0")).To(Equal(&v1alpha1.VolumeTopology{Zone: "zone-b", Node: "node-2"}))
+	g.Expect(getVolumeTopology(fakeDeps, "ns", "none-0")).To(BeNil())
+	g.Expect(getVolumeTopology(fakeDeps, "ns", "not-exist-0")).To(BeNil())
+}
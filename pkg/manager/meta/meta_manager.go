@@ -60,6 +60,7 @@ func (m *metaManager) Sync(tc *v1alpha1.TidbCluster) error {
 		switch pod.Labels[label.ComponentLabelKey] {
 		case label.PDLabelVal,
 			label.TiKVLabelVal,
+			label.TiKVColdLabelVal,
 			label.TiFlashLabelVal,
 			label.PumpLabelVal:
 			// Currently PD/TiKV/TiFlash/Pump must uses PV
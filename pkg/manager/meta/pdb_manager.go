@@ -0,0 +1,168 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultPDBMaxUnavailable keeps the quorum of PD, TiKV and dm-master while a node is drained
+var defaultPDBMaxUnavailable = intstr.FromInt(1)
+
+type pdbManager struct {
+	deps *controller.Dependencies
+}
+
+// pdbComponent is a component whose pods are protected by a PodDisruptionBudget
+type pdbComponent struct {
+	name   string
+	labels label.Label
+	spec   *v1alpha1.PodDisruptionBudgetSpec
+}
+
+// NewPDBManager returns a *pdbManager
+func NewPDBManager(deps *controller.Dependencies) *pdbManager {
+	return &pdbManager{
+		deps: deps,
+	}
+}
+
+// Sync creates or updates the PodDisruptionBudgets of PD, TiKV, TiFlash and TiDB, and deletes the ones no
+// longer configured.
+func (m *pdbManager) Sync(tc *v1alpha1.TidbCluster) error {
+	tcName := tc.GetName()
+	l := label.New().Instance(tc.GetInstanceName())
+	components := []pdbComponent{
+		{name: controller.PDMemberName(tcName), labels: l.Copy().PD()},
+		{name: controller.TiKVMemberName(tcName), labels: l.Copy().TiKV()},
+		{name: controller.TiFlashMemberName(tcName), labels: l.Copy().TiFlash()},
+		{name: controller.TiDBMemberName(tcName), labels: l.Copy().TiDB()},
+	}
+	if tc.Spec.PD != nil {
+		components[0].spec = tc.Spec.PD.PodDisruptionBudget
+	}
+	if tc.Spec.TiKV != nil {
+		components[1].spec = tc.Spec.TiKV.PodDisruptionBudget
+	}
+	if tc.Spec.TiFlash != nil {
+		components[2].spec = tc.Spec.TiFlash.PodDisruptionBudget
+	}
+	if tc.Spec.TiDB != nil {
+		components[3].spec = tc.Spec.TiDB.PodDisruptionBudget
+	}
+	return m.sync(tc, components)
+}
+
+// SyncDM creates or updates the PodDisruptionBudgets of dm-master and dm-worker, and deletes the ones no
+// longer configured.
+func (m *pdbManager) SyncDM(dc *v1alpha1.DMCluster) error {
+	dcName := dc.GetName()
+	l := label.NewDM().Instance(dc.GetInstanceName())
+	components := []pdbComponent{
+		{name: controller.DMMasterMemberName(dcName), labels: l.Copy().DMMaster(), spec: dc.Spec.Master.PodDisruptionBudget},
+		{name: controller.DMWorkerMemberName(dcName), labels: l.Copy().DMWorker()},
+	}
+	if dc.Spec.Worker != nil {
+		components[1].spec = dc.Spec.Worker.PodDisruptionBudget
+	}
+	return m.sync(dc, components)
+}
+
+func (m *pdbManager) sync(owner client.Object, components []pdbComponent) error {
+	var errs []error
+	for _, component := range components {
+		if err := m.syncPDB(owner, component); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+func (m *pdbManager) syncPDB(owner client.Object, component pdbComponent) error {
+	ns := owner.GetNamespace()
+	if component.spec == nil {
+		pdb, err := m.deps.PDBLister.PodDisruptionBudgets(ns).Get(component.name)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("pdbManager.syncPDB: failed to get pdb %s/%s, error: %v", ns, component.name, err)
+		}
+		// the PodDisruptionBudgets not created by the operator are left alone
+		if !metav1.IsControlledBy(pdb, owner) {
+			return nil
+		}
+		// the cache may be stale, the PodDisruptionBudget is deleted already if it's not found
+		if err := m.deps.TypedControl.Delete(owner, pdb.DeepCopy()); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("pdbManager.syncPDB: failed to delete pdb %s/%s, error: %v", ns, component.name, err)
+		}
+		klog.Infof("pdb %s/%s is deleted as it's not configured", ns, component.name)
+		return nil
+	}
+
+	maxUnavailable := defaultPDBMaxUnavailable
+	if component.spec.MaxUnavailable != nil {
+		maxUnavailable = *component.spec.MaxUnavailable
+	}
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      component.name,
+			Namespace: ns,
+			Labels:    component.labels.Labels(),
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector:       component.labels.LabelSelector(),
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+	if _, err := m.deps.TypedControl.CreateOrUpdatePodDisruptionBudget(owner, pdb); err != nil {
+		return fmt.Errorf("pdbManager.syncPDB: failed to create or update pdb %s/%s, error: %v", ns, component.name, err)
+	}
+	return nil
+}
+
+var _ manager.Manager = &pdbManager{}
+var _ manager.DMManager = &pdbManager{}
+
+type FakePDBManager struct {
+	err error
+}
+
+func NewFakePDBManager() *FakePDBManager {
+	return &FakePDBManager{}
+}
+
+func (m *FakePDBManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakePDBManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
+
+func (m *FakePDBManager) SyncDM(_ *v1alpha1.DMCluster) error {
+	return m.err
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestPDBManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	cli := deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+	m := NewPDBManager(deps)
+	tc := newTidbClusterForMeta()
+	getPDB := func(name string) (*policyv1beta1.PodDisruptionBudget, error) {
+		pdb := &policyv1beta1.PodDisruptionBudget{}
+		err := cli.Get(context.TODO(), types.NamespacedName{Namespace: tc.Namespace, Name: name}, pdb)
+		return pdb, err
+	}
+	// syncCache adds the PodDisruptionBudget to the cache of the lister as the informer does
+	syncCache := func(name string) {
+		pdb, err := getPDB(name)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(deps.KubeInformerFactory.Policy().V1beta1().PodDisruptionBudgets().Informer().GetIndexer().Add(pdb)).To(Succeed())
+	}
+
+	// no PodDisruptionBudget is created if it's not configured
+	tc.Spec.PD = &v1alpha1.PDSpec{}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	_, err := getPDB(controller.PDMemberName(tc.Name))
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	// maxUnavailable defaults to 1
+	tc.Spec.PD.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetSpec{}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	pdb, err := getPDB(controller.PDMemberName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromInt(1)))
+	g.Expect(pdb.Spec.Selector).To(Equal(label.New().Instance(tc.GetInstanceName()).PD().LabelSelector()))
+	g.Expect(metav1.IsControlledBy(pdb, tc)).To(BeTrue())
+	_, err = getPDB(controller.TiKVMemberName(tc.Name))
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	// maxUnavailable is updated
+	maxUnavailable := intstr.FromString("50%")
+	tc.Spec.PD.PodDisruptionBudget.MaxUnavailable = &maxUnavailable
+	tc.Spec.TiKV = &v1alpha1.TiKVSpec{PodDisruptionBudget: &v1alpha1.PodDisruptionBudgetSpec{}}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	pdb, err = getPDB(controller.PDMemberName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*pdb.Spec.MaxUnavailable).To(Equal(maxUnavailable))
+	_, err = getPDB(controller.TiKVMemberName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	syncCache(controller.PDMemberName(tc.Name))
+	syncCache(controller.TiKVMemberName(tc.Name))
+
+	// the PodDisruptionBudget is deleted when it's not configured any more
+	tc.Spec.PD.PodDisruptionBudget = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	_, err = getPDB(controller.PDMemberName(tc.Name))
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+	_, err = getPDB(controller.TiKVMemberName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the PodDisruptionBudget not created by the operator is kept
+	userPDB := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: controller.TiDBMemberName(tc.Name)},
+	}
+	g.Expect(cli.Create(context.TODO(), userPDB)).To(Succeed())
+	syncCache(controller.TiDBMemberName(tc.Name))
+	g.Expect(m.Sync(tc)).To(Succeed())
+	_, err = getPDB(controller.TiDBMemberName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestPDBManagerSyncDM(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	cli := deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+	m := NewPDBManager(deps)
+	dc := newDMClusterForMeta()
+	dc.Spec.Master.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetSpec{}
+	g.Expect(m.SyncDM(dc)).To(Succeed())
+
+	pdb := &policyv1beta1.PodDisruptionBudget{}
+	err := cli.Get(context.TODO(), types.NamespacedName{Namespace: dc.Namespace, Name: controller.DMMasterMemberName(dc.Name)}, pdb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromInt(1)))
+	g.Expect(pdb.Spec.Selector).To(Equal(label.NewDM().Instance(dc.GetInstanceName()).DMMaster().LabelSelector()))
+	err = cli.Get(context.TODO(), types.NamespacedName{Namespace: dc.Namespace, Name: controller.DMWorkerMemberName(dc.Name)}, pdb)
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+}
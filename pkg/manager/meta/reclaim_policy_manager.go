@@ -96,7 +96,7 @@ func (m *reclaimPolicyManager) sync(kind string, obj runtime.Object, isPVReclaim
 			// If the PV reclaim setting is enabled, and when PV is a candidate to be reclaimed, skip patching this PV.
 			continue
 		}
-		if l := label.Label(pvc.Labels); kind == v1alpha1.TiDBClusterKind && (!l.IsPD() && !l.IsTiDB() && !l.IsTiKV() && !l.IsTiFlash() && !l.IsPump()) {
+		if l := label.Label(pvc.Labels); kind == v1alpha1.TiDBClusterKind && (!l.IsPD() && !l.IsTiDB() && !l.IsTiKV() && !l.IsTiKVCold() && !l.IsTiFlash() && !l.IsPump()) {
 			continue
 		}
 		pv, err := m.deps.PVLister.Get(pvc.Spec.VolumeName)
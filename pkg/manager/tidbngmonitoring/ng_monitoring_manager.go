@@ -19,6 +19,7 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
 	"github.com/pingcap/tidb-operator/pkg/util"
@@ -26,6 +27,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
@@ -39,6 +41,11 @@ const (
 	ngmConfigMapConfigKey = "config-file" // the key for config data in config map
 
 	ngmServicePort = 12020
+
+	// the config items of Top SQL, which are overridden by the spec
+	ngmTopSQLEnableKey           = "topsql.enable"
+	ngmTopSQLRetentionPeriodKey  = "topsql.retention-period"
+	ngmTopSQLStorageSizeLimitKey = "topsql.storage-size-limit"
 )
 
 type ngMonitoringManager struct {
@@ -144,7 +151,7 @@ func (m *ngMonitoringManager) syncCore(tngm *v1alpha1.TidbNGMonitoring) error {
 		return err
 	}
 
-	newSts, err := GenerateNGMonitoringStatefulSet(tngm, cm)
+	newSts, err := GenerateNGMonitoringStatefulSet(tngm, cm, m.pdClientPort(tngm))
 	if err != nil {
 		return err
 	}
@@ -205,9 +212,36 @@ func (m *ngMonitoringManager) populateStatus(tngm *v1alpha1.TidbNGMonitoring, st
 
 	tngm.Status.NGMonitoring.Synced = true
 
+	m.populateTopSQLStatus(tngm, sts)
+
 	return nil
 }
 
+// populateTopSQLStatus updates the status of Top SQL, the storage usage is kept if it fails to
+// get the usage from ng monitoring
+func (m *ngMonitoringManager) populateTopSQLStatus(tngm *v1alpha1.TidbNGMonitoring, sts *apps.StatefulSet) {
+	status := &v1alpha1.TopSQLStatus{}
+	if tngm.Status.NGMonitoring.TopSQL != nil {
+		status = tngm.Status.NGMonitoring.TopSQL
+	}
+	status.Enabled = tngm.IsTopSQLEnabled()
+	tngm.Status.NGMonitoring.TopSQL = status
+
+	if sts.Status.ReadyReplicas == 0 {
+		return
+	}
+	usedBytes, err := m.deps.NGMControl.GetStorageUsage(tngm)
+	if err != nil {
+		klog.Warningf("failed to get the storage usage of tidb ng monitoring %s/%s, error: %v", tngm.GetNamespace(), tngm.GetName(), err)
+		return
+	}
+	usage := resource.NewQuantity(usedBytes, resource.BinarySI)
+	if status.StorageUsage == nil || status.StorageUsage.Cmp(*usage) != 0 {
+		status.StorageUsage = usage
+		status.LastUpdateTime = metav1.Now()
+	}
+}
+
 func (m *ngMonitoringManager) confirmStatefulSetIsUpgrading(tngm *v1alpha1.TidbNGMonitoring, oldSts *apps.StatefulSet) (bool, error) {
 	if mngerutils.StatefulSetIsUpgrading(oldSts) {
 		return true, nil
@@ -238,7 +272,22 @@ func (m *ngMonitoringManager) confirmStatefulSetIsUpgrading(tngm *v1alpha1.TidbN
 	return false, nil
 }
 
-func GenerateNGMonitoringStatefulSet(tngm *v1alpha1.TidbNGMonitoring, cm *corev1.ConfigMap) (*apps.StatefulSet, error) {
+// pdClientPort returns the PD client port of the monitored cluster, the default port is returned if
+// the cluster can't be found, e.g. it's in another Kubernetes cluster
+func (m *ngMonitoringManager) pdClientPort(tngm *v1alpha1.TidbNGMonitoring) int32 {
+	tcRef := tngm.Spec.Clusters[0]
+	ns := tcRef.Namespace
+	if ns == "" {
+		ns = tngm.GetNamespace()
+	}
+	tc, err := m.deps.TiDBClusterLister.TidbClusters(ns).Get(tcRef.Name)
+	if err != nil {
+		return v1alpha1.DefaultPDClientPort
+	}
+	return tc.PDClientPort()
+}
+
+func GenerateNGMonitoringStatefulSet(tngm *v1alpha1.TidbNGMonitoring, cm *corev1.ConfigMap, pdClientPort int32) (*apps.StatefulSet, error) {
 	ns := tngm.GetNamespace()
 	name := tngm.GetName()
 
@@ -259,7 +308,7 @@ func GenerateNGMonitoringStatefulSet(tngm *v1alpha1.TidbNGMonitoring, cm *corev1
 	// base containers
 	baseContainers := []corev1.Container{}
 	nmContainerName := v1alpha1.NGMonitoringMemberType.String()
-	startScript, err := GenerateNGMonitoringStartScript(tngm)
+	startScript, err := GenerateNGMonitoringStartScript(tngm, pdClientPort)
 	if err != nil {
 		return nil, fmt.Errorf("cannot render start-script for ng monitoring, tidb ng monitoring %s/%s, error: %v", ns, name, err)
 	}
@@ -398,12 +447,28 @@ func GenerateNGMonitoringStatefulSet(tngm *v1alpha1.TidbNGMonitoring, cm *corev1
 
 // GenerateNGMonitoringConfigMap generate ConfigMap from tidb ng monitoring
 func GenerateNGMonitoringConfigMap(tngm *v1alpha1.TidbNGMonitoring) (*corev1.ConfigMap, error) {
-	config := tngm.Spec.NGMonitoring.Config
+	cfg := tngm.Spec.NGMonitoring.Config
 	meta, _ := GenerateNGMonitoringMeta(tngm, NGMonitoringName)
 
+	if topSQL := tngm.Spec.NGMonitoring.TopSQL; topSQL != nil {
+		// the user config is not modified
+		if cfg == nil || cfg.MP == nil {
+			cfg = config.New(map[string]interface{}{})
+		} else {
+			cfg = cfg.DeepCopy()
+		}
+		cfg.Set(ngmTopSQLEnableKey, tngm.IsTopSQLEnabled())
+		if topSQL.RetentionPeriod != nil {
+			cfg.Set(ngmTopSQLRetentionPeriodKey, topSQL.RetentionPeriod.Duration.String())
+		}
+		if topSQL.StorageSizeLimit != nil {
+			cfg.Set(ngmTopSQLStorageSizeLimitKey, topSQL.StorageSizeLimit.Value())
+		}
+	}
+
 	// TODO: TLS
 
-	confText, err := config.MarshalTOML()
+	confText, err := cfg.MarshalTOML()
 	if err != nil {
 		return nil, err
 	}
@@ -459,13 +524,14 @@ func GenerateNGMonitoringHeadlessService(tngm *v1alpha1.TidbNGMonitoring) *corev
 	}
 }
 
-func GenerateNGMonitoringStartScript(tngm *v1alpha1.TidbNGMonitoring) (string, error) {
+func GenerateNGMonitoringStartScript(tngm *v1alpha1.TidbNGMonitoring, pdClientPort int32) (string, error) {
 	tcRef := tngm.Spec.Clusters[0]
 
 	model := &NGMonitoringStartScriptModel{
 		TCName:            tcRef.Name,
 		TCNamespace:       tcRef.Namespace,
 		TCClusterDomain:   tcRef.ClusterDomain,
+		TCPDClientPort:    pdClientPort,
 		TNGMName:          tngm.Name,
 		TNGMNamespace:     tngm.Namespace,
 		TNGMClusterDomain: tngm.Spec.ClusterDomain,
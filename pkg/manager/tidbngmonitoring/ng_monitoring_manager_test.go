@@ -0,0 +1,108 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbngmonitoring
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestGenerateNGMonitoringConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tngm := newTidbNGMonitoring()
+	cm, err := GenerateNGMonitoringConfigMap(tngm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data[ngmConfigMapConfigKey]).To(BeEmpty())
+
+	tngm.Spec.NGMonitoring.Config = config.New(map[string]interface{}{
+		"log": map[string]interface{}{"level": "INFO"},
+	})
+	limit := resource.MustParse("1Gi")
+	tngm.Spec.NGMonitoring.TopSQL = &v1alpha1.TopSQLSpec{
+		Enable:           pointer.BoolPtr(false),
+		RetentionPeriod:  &metav1.Duration{Duration: 72 * time.Hour},
+		StorageSizeLimit: &limit,
+	}
+	cm, err = GenerateNGMonitoringConfigMap(tngm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data[ngmConfigMapConfigKey]).To(Equal(`[log]
+  level = "INFO"
+
+[topsql]
+  enable = false
+  retention-period = "72h0m0s"
+  storage-size-limit = 1073741824
+`))
+	// the user config is not modified
+	g.Expect(tngm.Spec.NGMonitoring.Config.Inner()).NotTo(HaveKey("topsql"))
+}
+
+func TestNGMonitoringManagerPopulateTopSQLStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	ngmControl := deps.NGMControl.(*controller.FakeNGMonitoringControl)
+	m := NewNGMonitorManager(deps)
+	tngm := newTidbNGMonitoring()
+	sts := &apps.StatefulSet{}
+
+	// the storage usage is not queried before ng monitoring is ready
+	ngmControl.MockGetStorageUsage(func(tngm *v1alpha1.TidbNGMonitoring) (int64, error) {
+		return 0, fmt.Errorf("ng monitoring is not ready")
+	})
+	m.populateTopSQLStatus(tngm, sts)
+	g.Expect(tngm.Status.NGMonitoring.TopSQL.Enabled).To(BeTrue())
+	g.Expect(tngm.Status.NGMonitoring.TopSQL.StorageUsage).To(BeNil())
+
+	sts.Status.ReadyReplicas = 1
+	ngmControl.MockGetStorageUsage(func(tngm *v1alpha1.TidbNGMonitoring) (int64, error) {
+		return 1024, nil
+	})
+	m.populateTopSQLStatus(tngm, sts)
+	g.Expect(tngm.Status.NGMonitoring.TopSQL.StorageUsage.Value()).To(Equal(int64(1024)))
+	g.Expect(tngm.Status.NGMonitoring.TopSQL.LastUpdateTime.IsZero()).To(BeFalse())
+
+	// the last storage usage is kept if it fails to get the usage
+	tngm.Spec.NGMonitoring.TopSQL = &v1alpha1.TopSQLSpec{Enable: pointer.BoolPtr(false)}
+	ngmControl.MockGetStorageUsage(func(tngm *v1alpha1.TidbNGMonitoring) (int64, error) {
+		return 0, fmt.Errorf("failed to get storage usage")
+	})
+	m.populateTopSQLStatus(tngm, sts)
+	g.Expect(tngm.Status.NGMonitoring.TopSQL.Enabled).To(BeFalse())
+	g.Expect(tngm.Status.NGMonitoring.TopSQL.StorageUsage.Value()).To(Equal(int64(1024)))
+}
+
+func newTidbNGMonitoring() *v1alpha1.TidbNGMonitoring {
+	return &v1alpha1.TidbNGMonitoring{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ngm",
+			Namespace: corev1.NamespaceDefault,
+		},
+		Spec: v1alpha1.TidbNGMonitoringSpec{
+			Clusters: []v1alpha1.TidbClusterRef{{Name: "tc", Namespace: corev1.NamespaceDefault}},
+		},
+	}
+}
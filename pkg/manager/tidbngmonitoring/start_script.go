@@ -25,6 +25,7 @@ type NGMonitoringStartScriptModel struct {
 	TCName          string // name of tidb cluster
 	TCNamespace     string // namespace of tidb cluster's namespace
 	TCClusterDomain string // cluster domain of tidb cluster
+	TCPDClientPort  int32  // client port of pd of tidb cluster
 
 	TNGMName          string // name of tidb ng monitoring
 	TNGMNamespace     string // namespace of tidb ng monitoring
@@ -41,7 +42,7 @@ func (m *NGMonitoringStartScriptModel) FormatClusterDomain() string {
 func (m *NGMonitoringStartScriptModel) PDAddress() string {
 	// don't need add scheme, ng monitoring will use https if cert is configured
 	// TODO: support across kubernetes
-	return fmt.Sprintf("%s.%s:2379", controller.PDMemberName(m.TCName), m.TCNamespace)
+	return fmt.Sprintf("%s.%s:%d", controller.PDMemberName(m.TCName), m.TCNamespace, m.TCPDClientPort)
 }
 
 func (m *NGMonitoringStartScriptModel) RenderStartScript() (string, error) {
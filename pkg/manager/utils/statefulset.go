@@ -55,6 +55,16 @@ func SetStatefulSetLastAppliedConfigAnnotation(set *apps.StatefulSet) error {
 
 // UpdateStatefulSet is a template function to update the statefulset of components
 func UpdateStatefulSet(setCtl controller.StatefulSetControlInterface, object runtime.Object, newSet, oldSet *apps.StatefulSet) error {
+	return updateStatefulSet(setCtl, object, newSet, oldSet, false)
+}
+
+// ForceUpdateStatefulSet updates the StatefulSet even if the new one equals the last applied one, which reverts
+// the changes made to the StatefulSet out of the operator
+func ForceUpdateStatefulSet(setCtl controller.StatefulSetControlInterface, object runtime.Object, newSet, oldSet *apps.StatefulSet) error {
+	return updateStatefulSet(setCtl, object, newSet, oldSet, true)
+}
+
+func updateStatefulSet(setCtl controller.StatefulSetControlInterface, object runtime.Object, newSet, oldSet *apps.StatefulSet, force bool) error {
 	isOrphan := metav1.GetControllerOf(oldSet) == nil
 	if newSet.Annotations == nil {
 		newSet.Annotations = map[string]string{}
@@ -65,7 +75,7 @@ func UpdateStatefulSet(setCtl controller.StatefulSetControlInterface, object run
 
 	// Check if an upgrade is needed.
 	// If not, early return.
-	if util.StatefulSetEqual(*newSet, *oldSet) && !isOrphan {
+	if util.StatefulSetEqual(*newSet, *oldSet) && !isOrphan && !force {
 		return nil
 	}
 
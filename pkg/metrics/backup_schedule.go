@@ -0,0 +1,73 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	BackupScheduleLastSuccessTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "backup_schedule",
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Unix time at which the latest successful backup of each BackupSchedule was completed",
+		}, []string{LabelNamespace, LabelName})
+
+	BackupScheduleLastSuccessDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "backup_schedule",
+			Name:      "last_success_duration_seconds",
+			Help:      "Time taken by the latest successful backup of each BackupSchedule",
+		}, []string{LabelNamespace, LabelName})
+
+	BackupScheduleLastSuccessSize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "backup_schedule",
+			Name:      "last_success_size_bytes",
+			Help:      "Data size of the latest successful backup of each BackupSchedule",
+		}, []string{LabelNamespace, LabelName})
+
+	BackupScheduleConsecutiveFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "backup_schedule",
+			Name:      "consecutive_failures",
+			Help:      "Number of the backups of each BackupSchedule failed since the latest successful backup",
+		}, []string{LabelNamespace, LabelName})
+
+	BackupScheduleFresh = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "backup_schedule",
+			Name:      "fresh",
+			Help:      "Whether a backup of each BackupSchedule with an RPO completed successfully within the RPO, 1 for true and 0 for false",
+		}, []string{LabelNamespace, LabelName})
+)
+
+// DeleteBackupScheduleMetrics deletes the metrics of a BackupSchedule, it's called when the BackupSchedule is deleted.
+func DeleteBackupScheduleMetrics(ns, name string) {
+	for _, m := range []*prometheus.GaugeVec{
+		BackupScheduleLastSuccessTimestamp,
+		BackupScheduleLastSuccessDuration,
+		BackupScheduleLastSuccessSize,
+		BackupScheduleConsecutiveFailures,
+		BackupScheduleFresh,
+	} {
+		m.DeleteLabelValues(ns, name)
+	}
+}
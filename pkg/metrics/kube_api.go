@@ -0,0 +1,37 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	KubeAPIRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "kube_api",
+			Name:      "requests_total",
+			Help:      "Total number of requests sent to the Kubernetes API server by each controller",
+		}, []string{LabelBucket, LabelController, LabelMethod, LabelCode})
+
+	KubeAPIRateLimiterWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "kube_api",
+			Name:      "rate_limiter_wait_duration_seconds",
+			Help:      "Time spent by each controller waiting for the token bucket of its group before sending requests to the Kubernetes API server",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+		}, []string{LabelBucket, LabelController})
+)
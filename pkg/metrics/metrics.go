@@ -15,16 +15,37 @@ package metrics
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
 )
 
 // RegisterMetrics registers all metrics of tidb-operator.
 func RegisterMetrics() {
 	prometheus.MustRegister(ClusterSpecReplicas)
+	prometheus.MustRegister(KubeAPIRequests)
+	prometheus.MustRegister(KubeAPIRateLimiterWait)
+	prometheus.MustRegister(BackupScheduleLastSuccessTimestamp)
+	prometheus.MustRegister(BackupScheduleLastSuccessDuration)
+	prometheus.MustRegister(BackupScheduleLastSuccessSize)
+	prometheus.MustRegister(BackupScheduleConsecutiveFailures)
+	prometheus.MustRegister(BackupScheduleFresh)
+	prometheus.MustRegister(WorkQueueDepth)
+	prometheus.MustRegister(WorkQueueAdds)
+	prometheus.MustRegister(WorkQueueLatency)
+	prometheus.MustRegister(WorkQueueWorkDuration)
+	prometheus.MustRegister(WorkQueueUnfinishedWork)
+	prometheus.MustRegister(WorkQueueLongestRunningProcessor)
+	prometheus.MustRegister(WorkQueueRetries)
+	// the work queues created after this are instrumented, so it must be called before creating the controllers
+	workqueue.SetProvider(workQueueMetricsProvider{})
 }
 
 // Label constants.
 const (
-	LabelNamespace = "namespace"
-	LabelName      = "name"
-	LabelComponent = "component"
+	LabelNamespace  = "namespace"
+	LabelName       = "name"
+	LabelComponent  = "component"
+	LabelBucket     = "bucket"
+	LabelController = "controller"
+	LabelMethod     = "method"
+	LabelCode       = "code"
 )
@@ -0,0 +1,113 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// The metrics of the work queues of the controllers, the queues are labeled with their names, e.g. tidbcluster.
+var (
+	WorkQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "depth",
+			Help:      "Current depth of the work queue of each controller",
+		}, []string{LabelName})
+
+	WorkQueueAdds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "adds_total",
+			Help:      "Total number of adds handled by the work queue of each controller",
+		}, []string{LabelName})
+
+	WorkQueueLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "queue_duration_seconds",
+			Help:      "How long an item stays in the work queue of each controller before being processed",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 18),
+		}, []string{LabelName})
+
+	WorkQueueWorkDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "work_duration_seconds",
+			Help:      "How long processing an item from the work queue of each controller takes",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 18),
+		}, []string{LabelName})
+
+	WorkQueueUnfinishedWork = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "unfinished_work_seconds",
+			Help:      "How many seconds of work has been done by the workers of each controller that is in progress",
+		}, []string{LabelName})
+
+	WorkQueueLongestRunningProcessor = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "longest_running_processor_seconds",
+			Help:      "How many seconds the longest running worker of each controller has been running",
+		}, []string{LabelName})
+
+	WorkQueueRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "retries_total",
+			Help:      "Total number of the rate limited retries handled by the work queue of each controller",
+		}, []string{LabelName})
+)
+
+// workQueueMetricsProvider provides the metrics of the named work queues
+type workQueueMetricsProvider struct{}
+
+func (workQueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return WorkQueueDepth.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return WorkQueueAdds.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return WorkQueueLatency.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return WorkQueueWorkDuration.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return WorkQueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return WorkQueueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return WorkQueueRetries.WithLabelValues(name)
+}
+
+var _ workqueue.MetricsProvider = workQueueMetricsProvider{}
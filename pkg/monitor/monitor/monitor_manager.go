@@ -14,10 +14,12 @@
 package monitor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -38,9 +40,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	discoverycachedmemory "k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/util/slice"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -68,7 +73,7 @@ func NewMonitorManager(deps *controller.Dependencies) *MonitorManager {
 
 func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 	if monitor.DeletionTimestamp != nil {
-		return nil
+		return m.cleanTargetNamespacesRbac(monitor, nil)
 	}
 	if len(monitor.Spec.Clusters) < 1 && (monitor.Spec.DM == nil || len(monitor.Spec.DM.Clusters) < 1) {
 		klog.Errorf("tm[%s/%s] does not configure the target tidbcluster", monitor.Namespace, monitor.Name)
@@ -81,7 +86,7 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 	}
 
 	var firstTc *v1alpha1.TidbCluster
-	assetStore := NewStore(m.deps.SecretLister)
+	assetStore := NewStore(m.deps.SecretLister, m.deps.UserConfigMapLister)
 
 	for _, tcRef := range monitor.Spec.Clusters {
 		tc, err := m.deps.TiDBClusterLister.TidbClusters(tcRef.Namespace).Get(tcRef.Name)
@@ -131,6 +136,12 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 		}
 	}
 
+	if err := assetStore.addRemoteWriteAssets(monitor.Namespace, monitor.Spec.Prometheus.RemoteWrite); err != nil {
+		return fmt.Errorf("get tm[%s/%s]'s remote write assets failed, err: %v", monitor.Namespace, monitor.Name, err)
+	}
+
+	m.syncDashboardVersion(monitor, firstTc)
+
 	// create or update tls asset secret
 	err := m.syncAssetSecret(monitor, assetStore)
 	if err != nil {
@@ -146,12 +157,19 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 	klog.V(4).Infof("tm[%s/%s]'s service synced", monitor.Namespace, monitor.Name)
 
 	// Sync Statefulset
-	if err := m.syncTidbMonitorStatefulset(firstTc, firstDc, monitor); err != nil {
+	if err := m.syncTidbMonitorStatefulset(firstTc, firstDc, monitor, assetStore); err != nil {
 		message := fmt.Sprintf("Sync TidbMonitor[%s/%s] Statefulset failed, err:%v", monitor.Namespace, monitor.Name, err)
 		m.deps.Recorder.Event(monitor, corev1.EventTypeWarning, FailedSync, message)
 		return err
 	}
 
+	// Sync Thanos Query
+	if err := m.syncThanosQuery(monitor); err != nil {
+		message := fmt.Sprintf("Sync TidbMonitor[%s/%s] Thanos Query failed, err:%v", monitor.Namespace, monitor.Name, err)
+		m.deps.Recorder.Event(monitor, corev1.EventTypeWarning, FailedSync, message)
+		return err
+	}
+
 	// Sync PV
 	if monitor.Spec.Persistent {
 		// syncing all PVs managed by this tidbmonitor
@@ -173,6 +191,9 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 	}
 	klog.V(4).Infof("tm[%s/%s]'s ingress synced", monitor.Namespace, monitor.Name)
 
+	// Sync silence windows
+	m.syncSilenceWindows(monitor, time.Now())
+
 	err = m.syncTidbMonitorStatus(monitor)
 	if err != nil {
 		klog.Errorf("Fail to sync tm[%s/%s]'s status, err: %v", monitor.Namespace, monitor.Name, err)
@@ -182,6 +203,26 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 	return nil
 }
 
+// syncDashboardVersion selects the version of the initializer image by the version of the first monitored
+// cluster, the change of the version rolls the Pods of the monitor to reprovision the dashboards of Grafana.
+func (m *MonitorManager) syncDashboardVersion(monitor *v1alpha1.TidbMonitor, tc *v1alpha1.TidbCluster) {
+	if !monitor.Spec.DashboardVersionFromCluster {
+		monitor.Status.DashboardVersion = ""
+		return
+	}
+	if tc == nil {
+		return
+	}
+	version := getClusterDashboardVersion(tc)
+	if version == "" || version == monitor.Status.DashboardVersion {
+		return
+	}
+	msg := fmt.Sprintf("switch the dashboards from version %q to %q of tc[%s/%s]", getInitializerVersion(monitor), version, tc.Namespace, tc.Name)
+	klog.Infof("tm[%s/%s]: %s", monitor.Namespace, monitor.Name, msg)
+	m.deps.Recorder.Event(monitor, corev1.EventTypeNormal, "DashboardVersionChanged", msg)
+	monitor.Status.DashboardVersion = version
+}
+
 func (m *MonitorManager) syncTidbMonitorStatus(monitor *v1alpha1.TidbMonitor) error {
 	sts, err := m.deps.StatefulSetLister.StatefulSets(monitor.Namespace).Get(GetMonitorObjectName(monitor))
 	if err != nil {
@@ -205,10 +246,10 @@ func (m *MonitorManager) syncTidbMonitorService(monitor *v1alpha1.TidbMonitor) e
 	return nil
 }
 
-func (m *MonitorManager) syncTidbMonitorStatefulset(tc *v1alpha1.TidbCluster, dc *v1alpha1.DMCluster, monitor *v1alpha1.TidbMonitor) error {
+func (m *MonitorManager) syncTidbMonitorStatefulset(tc *v1alpha1.TidbCluster, dc *v1alpha1.DMCluster, monitor *v1alpha1.TidbMonitor, store *Store) error {
 	ns := monitor.Namespace
 	name := monitor.Name
-	err := m.syncTidbMonitorConfig(monitor)
+	err := m.syncTidbMonitorConfig(monitor, store)
 	if err != nil {
 		klog.Errorf("tm[%s/%s]'s configmap failed to sync,err: %v", ns, name, err)
 		return err
@@ -281,7 +322,7 @@ func (m *MonitorManager) syncTidbMonitorSecret(monitor *v1alpha1.TidbMonitor) (*
 	return m.deps.TypedControl.CreateOrUpdateSecret(monitor, newSt)
 }
 
-func (m *MonitorManager) syncTidbMonitorConfig(monitor *v1alpha1.TidbMonitor) error {
+func (m *MonitorManager) syncTidbMonitorConfig(monitor *v1alpha1.TidbMonitor, store *Store) error {
 	if features.DefaultFeatureGate.Enabled(features.AutoScaling) {
 		// TODO: We need to update the status to tell users we are monitoring extra clusters
 		// Get all autoscaling clusters for TC, and add them to .Spec.Clusters to
@@ -364,7 +405,7 @@ func (m *MonitorManager) syncTidbMonitorConfig(monitor *v1alpha1.TidbMonitor) er
 	}
 
 	shards := monitor.GetShards()
-	promCM, err := getPromConfigMap(monitor, monitorClusterInfos, dmClusterInfos, shards)
+	promCM, err := getPromConfigMap(monitor, store, monitorClusterInfos, dmClusterInfos, shards)
 	if err != nil {
 		return err
 	}
@@ -463,9 +504,113 @@ func (m *MonitorManager) syncTidbMonitorRbac(monitor *v1alpha1.TidbMonitor) (*co
 		}
 	}
 
+	if err := m.syncTargetNamespacesRbac(monitor, sa); err != nil {
+		klog.Errorf("tm[%s/%s]'s rbac in the namespaces of the target clusters failed to sync, err: %v", monitor.Namespace, monitor.Name, err)
+		return nil, err
+	}
+
 	return sa, nil
 }
 
+// syncTargetNamespacesRbac creates the Role and RoleBinding in each namespace of the target clusters other than the
+// namespace of the monitor, so that Prometheus can discover the Pods of the clusters. They are not needed if the monitor
+// is cluster scoped. As they can't be owned by the monitor, the monitor has a finalizer to delete them.
+func (m *MonitorManager) syncTargetNamespacesRbac(monitor *v1alpha1.TidbMonitor, sa *corev1.ServiceAccount) error {
+	namespaces := sets.NewString()
+	if !monitor.Spec.ClusterScoped {
+		namespaces = getTargetNamespaces(monitor)
+	}
+	if namespaces.Len() == 0 {
+		return m.cleanTargetNamespacesRbac(monitor, namespaces)
+	}
+
+	if !slice.ContainsString(monitor.Finalizers, label.TidbMonitorRBACFinalizer, nil) {
+		finalizers := append(monitor.Finalizers, label.TidbMonitorRBACFinalizer)
+		if err := m.patchFinalizers(monitor, finalizers); err != nil {
+			return err
+		}
+	}
+	for _, ns := range namespaces.List() {
+		role := getMonitorTargetRole(monitor, ns)
+		if _, err := m.deps.GenericControl.CreateOrUpdate(monitor, role, func(existing, desired client.Object) error {
+			existing.(*rbac.Role).Labels = desired.(*rbac.Role).Labels
+			existing.(*rbac.Role).Rules = desired.(*rbac.Role).Rules
+			return nil
+		}, false); err != nil {
+			return err
+		}
+		rb := getMonitorTargetRoleBinding(sa, role, monitor)
+		if _, err := m.deps.GenericControl.CreateOrUpdate(monitor, rb, func(existing, desired client.Object) error {
+			existing.(*rbac.RoleBinding).Labels = desired.(*rbac.RoleBinding).Labels
+			existing.(*rbac.RoleBinding).RoleRef = desired.(*rbac.RoleBinding).RoleRef
+			existing.(*rbac.RoleBinding).Subjects = desired.(*rbac.RoleBinding).Subjects
+			return nil
+		}, false); err != nil {
+			return err
+		}
+	}
+	return m.cleanTargetNamespacesRbac(monitor, namespaces)
+}
+
+// cleanTargetNamespacesRbac deletes the Roles and RoleBindings of the monitor in the namespaces other than the given
+// ones, the finalizer of the monitor is removed once none are left.
+func (m *MonitorManager) cleanTargetNamespacesRbac(monitor *v1alpha1.TidbMonitor, namespaces sets.String) error {
+	if !slice.ContainsString(monitor.Finalizers, label.TidbMonitorRBACFinalizer, nil) {
+		return nil
+	}
+	selector := client.MatchingLabels(buildTidbMonitorTargetLabel(monitor))
+	roles := &rbac.RoleList{}
+	if err := m.deps.GenericClient.List(context.TODO(), roles, selector); err != nil {
+		return fmt.Errorf("list roles of tm[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, err)
+	}
+	for i := range roles.Items {
+		if namespaces.Has(roles.Items[i].Namespace) {
+			continue
+		}
+		if err := m.deps.GenericClient.Delete(context.TODO(), &roles.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete role %s/%s of tm[%s/%s] failed, err: %v", roles.Items[i].Namespace, roles.Items[i].Name, monitor.Namespace, monitor.Name, err)
+		}
+		klog.Infof("role %s/%s of tm[%s/%s] is deleted", roles.Items[i].Namespace, roles.Items[i].Name, monitor.Namespace, monitor.Name)
+	}
+	rbs := &rbac.RoleBindingList{}
+	if err := m.deps.GenericClient.List(context.TODO(), rbs, selector); err != nil {
+		return fmt.Errorf("list rolebindings of tm[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, err)
+	}
+	for i := range rbs.Items {
+		if namespaces.Has(rbs.Items[i].Namespace) {
+			continue
+		}
+		if err := m.deps.GenericClient.Delete(context.TODO(), &rbs.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete rolebinding %s/%s of tm[%s/%s] failed, err: %v", rbs.Items[i].Namespace, rbs.Items[i].Name, monitor.Namespace, monitor.Name, err)
+		}
+		klog.Infof("rolebinding %s/%s of tm[%s/%s] is deleted", rbs.Items[i].Namespace, rbs.Items[i].Name, monitor.Namespace, monitor.Name)
+	}
+	if namespaces.Len() > 0 {
+		return nil
+	}
+	return m.patchFinalizers(monitor, slice.RemoveString(monitor.Finalizers, label.TidbMonitorRBACFinalizer, nil))
+}
+
+// patchFinalizers patches the finalizers of the monitor, the spec and status are left untouched.
+func (m *MonitorManager) patchFinalizers(monitor *v1alpha1.TidbMonitor, finalizers []string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": monitor.ResourceVersion,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	updated, err := m.deps.Clientset.PingcapV1alpha1().TidbMonitors(monitor.Namespace).Patch(context.TODO(), monitor.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("patch finalizers of tm[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, err)
+	}
+	monitor.Finalizers = updated.Finalizers
+	monitor.ResourceVersion = updated.ResourceVersion
+	return nil
+}
+
 func (m *MonitorManager) syncIngress(monitor *v1alpha1.TidbMonitor) error {
 	if err := m.syncPrometheusIngress(monitor); err != nil {
 		return err
@@ -506,6 +651,32 @@ func (m *MonitorManager) syncGrafanaIngress(monitor *v1alpha1.TidbMonitor) error
 	return err
 }
 
+// syncThanosQuery creates or updates the Thanos Query if it is enabled, otherwise removes it and its Service
+func (m *MonitorManager) syncThanosQuery(monitor *v1alpha1.TidbMonitor) error {
+	if monitor.Spec.Thanos != nil && monitor.Spec.Thanos.Query != nil {
+		_, err := m.deps.TypedControl.CreateOrUpdateDeployment(monitor, getThanosQueryDeployment(monitor))
+		return err
+	}
+
+	name := ThanosQueryName(monitor.Name)
+	deploy, err := m.deps.DeploymentLister.Deployments(monitor.Namespace).Get(name)
+	if err == nil {
+		if err := m.deps.TypedControl.Delete(monitor, deploy); err != nil {
+			return err
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	svc, err := m.deps.ServiceLister.Services(monitor.Namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return m.deps.TypedControl.Delete(monitor, svc)
+}
+
 // removeIngressIfExist removes Ingress if it exists
 func (m *MonitorManager) removeIngressIfExist(monitor *v1alpha1.TidbMonitor, name string) error {
 	var (
@@ -744,7 +915,7 @@ func (m *MonitorManager) syncDashboardMetricStorage(tc *v1alpha1.TidbCluster, tm
 	if tc.Spec.PD == nil {
 		return nil
 	}
-	pdEtcdClient, err := m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled())
+	pdEtcdClient, err := m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled(), pdapi.ClientPort(tc.PDClientPort()))
 
 	if err != nil {
 		return err
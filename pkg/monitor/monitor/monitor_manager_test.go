@@ -14,16 +14,22 @@
 package monitor
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
 	"github.com/prometheus/common/model"
 	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	discoverycachedmemory "k8s.io/client-go/discovery/cached/memory"
 	discoveryfake "k8s.io/client-go/discovery/fake"
@@ -549,6 +555,137 @@ func TestTidbMonitorSyncUpdate(t *testing.T) {
 	}
 }
 
+func TestTidbMonitorSyncCrossNamespace(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tmm := newFakeTidbMonitorManager()
+	cli := tmm.deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+	tmm.deps.GenericClient = cli
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns"},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV: &v1alpha1.TiKVSpec{BaseImage: "pingcap/tikv"},
+		},
+	}
+	g.Expect(tmm.deps.TiDBClusterControl.Create(tc)).To(Succeed())
+	tc2 := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "ns2"},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV:       &v1alpha1.TiKVSpec{BaseImage: "pingcap/tikv"},
+			TLSCluster: &v1alpha1.TLSCluster{Enabled: true},
+		},
+	}
+	g.Expect(tmm.deps.TiDBClusterControl.Create(tc2)).To(Succeed())
+	secretIndexer := tmm.deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	g.Expect(secretIndexer.Add(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar-cluster-client-secret", Namespace: "ns2"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca")},
+	})).To(Succeed())
+	dc := &v1alpha1.DMCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "dm-test", Namespace: "ns3"},
+		Spec:       v1alpha1.DMClusterSpec{Master: v1alpha1.MasterSpec{Replicas: 1}},
+	}
+	g.Expect(tmm.deps.InformerFactory.Pingcap().V1alpha1().DMClusters().Informer().GetIndexer().Add(dc)).To(Succeed())
+
+	tm := newTidbMonitor(v1alpha1.TidbClusterRef{Name: tc.Name})
+	tm.Spec.Clusters = append(tm.Spec.Clusters, v1alpha1.TidbClusterRef{Name: tc2.Name, Namespace: tc2.Namespace})
+	tm.Spec.DM = &v1alpha1.DMMonitorSpec{
+		Clusters:    []v1alpha1.ClusterRef{{Name: dc.Name, Namespace: dc.Namespace}},
+		Initializer: v1alpha1.InitializerSpec{MonitorContainer: v1alpha1.MonitorContainer{BaseImage: "pingcap/dm-monitor-initializer", Version: "v2.0.0"}},
+	}
+	tm.Spec.Shards = pointer.Int32Ptr(1)
+	tm, err := tmm.deps.Clientset.PingcapV1alpha1().TidbMonitors(tm.Namespace).Create(context.TODO(), tm, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	roleExists := func(ns string) bool {
+		key := types.NamespacedName{Namespace: ns, Name: GetMonitorObjectNameCrossNamespace(tm)}
+		err := cli.Get(context.TODO(), key, &rbacv1.Role{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		rb := &rbacv1.RoleBinding{}
+		g.Expect(cli.Get(context.TODO(), key, rb)).To(Succeed())
+		g.Expect(rb.Subjects[0].Namespace).To(Equal(tm.Namespace))
+		return true
+	}
+
+	err = tmm.SyncMonitor(tm)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(roleExists("ns2")).To(BeTrue())
+	g.Expect(roleExists("ns3")).To(BeTrue())
+	g.Expect(tm.Finalizers).To(ConsistOf(label.TidbMonitorRBACFinalizer))
+	assets := &v1.Secret{}
+	g.Expect(cli.Get(context.TODO(), types.NamespacedName{Namespace: tm.Namespace, Name: GetTLSAssetsSecretName(tm.Name)}, assets)).To(Succeed())
+	g.Expect(assets.Data).To(HaveKeyWithValue("secret_ns2_bar-cluster-client-secret_ca.crt", []byte("ca")))
+
+	// the rbac in the namespace no longer monitored is deleted
+	tm.Spec.DM = nil
+	g.Expect(tmm.SyncMonitor(tm)).To(Succeed())
+	g.Expect(roleExists("ns2")).To(BeTrue())
+	g.Expect(roleExists("ns3")).To(BeFalse())
+
+	// the rbac is deleted with the monitor
+	tm.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	g.Expect(tmm.SyncMonitor(tm)).To(Succeed())
+	g.Expect(roleExists("ns2")).To(BeFalse())
+	g.Expect(tm.Finalizers).To(BeEmpty())
+}
+
+func TestSyncDashboardVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m := &MonitorManager{deps: controller.NewFakeDependencies()}
+	monitor := newTidbMonitor(v1alpha1.TidbClusterRef{Name: "basic", Namespace: "ns"})
+	monitor.Spec.Initializer.BaseImage = "pingcap/tidb-monitor-initializer"
+	monitor.Spec.Initializer.Version = "v5.3.0"
+	monitor.Spec.DashboardVersionFromCluster = true
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic", Namespace: "ns"},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{},
+			TiKV: &v1alpha1.TiKVSpec{},
+			TiDB: &v1alpha1.TiDBSpec{},
+		},
+	}
+	setImages := func(pd, tikv, tidb string) {
+		tc.Status.PD.Image = pd
+		tc.Status.TiKV.Image = tikv
+		tc.Status.TiDB.Image = tidb
+	}
+	initializerImage := func() string {
+		return getMonitorInitContainer(monitor, tc).Image
+	}
+
+	// the version of the spec is used until the versions of the components are the same
+	setImages("pingcap/pd:v5.4.0", "pingcap/tikv:v5.3.0", "pingcap/tidb:v5.3.0")
+	m.syncDashboardVersion(monitor, tc)
+	g.Expect(monitor.Status.DashboardVersion).To(BeEmpty())
+	g.Expect(initializerImage()).To(Equal("pingcap/tidb-monitor-initializer:v5.3.0"))
+
+	setImages("pingcap/pd:v5.4.0", "pingcap/tikv:v5.4.0", "registry:5000/pingcap/tidb:v5.4.0")
+	m.syncDashboardVersion(monitor, tc)
+	g.Expect(monitor.Status.DashboardVersion).To(Equal("v5.4.0"))
+	g.Expect(initializerImage()).To(Equal("pingcap/tidb-monitor-initializer:v5.4.0"))
+	g.Expect(getAlertManagerRulesVersion(monitor)).To(Equal("tidb:v5.4.0"))
+
+	// the selected version is kept during the next upgrade
+	setImages("pingcap/pd:v6.1.0", "pingcap/tikv:v5.4.0", "pingcap/tidb:v5.4.0")
+	m.syncDashboardVersion(monitor, tc)
+	g.Expect(monitor.Status.DashboardVersion).To(Equal("v5.4.0"))
+
+	// the images without a tag are ignored
+	setImages("registry:5000/pingcap/pd", "registry:5000/pingcap/tikv", "registry:5000/pingcap/tidb")
+	m.syncDashboardVersion(monitor, tc)
+	g.Expect(monitor.Status.DashboardVersion).To(Equal("v5.4.0"))
+
+	// the version of the spec is used again once disabled
+	monitor.Spec.DashboardVersionFromCluster = false
+	m.syncDashboardVersion(monitor, tc)
+	g.Expect(monitor.Status.DashboardVersion).To(BeEmpty())
+	g.Expect(initializerImage()).To(Equal("pingcap/tidb-monitor-initializer:v5.3.0"))
+}
+
 func newTidbMonitor(cluster v1alpha1.TidbClusterRef) *v1alpha1.TidbMonitor {
 	return &v1alpha1.TidbMonitor{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,194 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+	"github.com/robfig/cron"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	silencesPrefix = "api/v2/silences"
+	silencePrefix  = "api/v2/silence"
+	// silenceCreator is the createdBy of the silences created by the operator
+	silenceCreator = "tidb-operator"
+)
+
+var silenceHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// alertmanagerSilence is a silence of the Alertmanager API v2
+type alertmanagerSilence struct {
+	Matchers  []alertmanagerMatcher `json:"matchers"`
+	StartsAt  time.Time             `json:"startsAt"`
+	EndsAt    time.Time             `json:"endsAt"`
+	CreatedBy string                `json:"createdBy"`
+	Comment   string                `json:"comment"`
+}
+
+type alertmanagerMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+}
+
+type postSilenceResponse struct {
+	SilenceID string `json:"silenceID"`
+}
+
+// syncSilenceWindows creates a silence in the Alertmanager for the current or next occurrence of
+// each silence window, so that the alerts are silenced even if the controller is not running at
+// the start of the window. The silences of the removed windows are expired.
+func (m *MonitorManager) syncSilenceWindows(monitor *v1alpha1.TidbMonitor, now time.Time) {
+	if monitor.Spec.AlertmanagerURL == nil || *monitor.Spec.AlertmanagerURL == "" {
+		return
+	}
+	apiURL := *monitor.Spec.AlertmanagerURL
+	if !strings.Contains(apiURL, "://") {
+		apiURL = "http://" + apiURL
+	}
+	apiURL = strings.TrimSuffix(apiURL, "/")
+
+	statuses := make(map[string]v1alpha1.SilenceWindowStatus)
+	for _, status := range monitor.Status.SilenceWindows {
+		statuses[status.Name] = status
+	}
+
+	var newStatuses []v1alpha1.SilenceWindowStatus
+	for i := range monitor.Spec.SilenceWindows {
+		window := &monitor.Spec.SilenceWindows[i]
+		status, ok := statuses[window.Name]
+		if !ok {
+			status = v1alpha1.SilenceWindowStatus{Name: window.Name}
+		}
+		delete(statuses, window.Name)
+		if err := m.syncSilenceWindow(monitor, window, &status, apiURL, now); err != nil {
+			klog.Errorf("failed to sync silence window %s of tm[%s/%s], error: %v", window.Name, monitor.Namespace, monitor.Name, err)
+			m.deps.Recorder.Event(monitor, corev1.EventTypeWarning, FailedSync, fmt.Sprintf("Sync silence window %s failed, err: %v", window.Name, err))
+			status.Message = err.Error()
+		}
+		newStatuses = append(newStatuses, status)
+	}
+
+	// expire the pending silences of the removed windows
+	var removed []string
+	for name := range statuses {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		status := statuses[name]
+		if status.SilenceID == "" || status.EndsAt == nil || !status.EndsAt.Time.After(now) {
+			continue
+		}
+		if _, err := httputil.DeleteBodyOK(silenceHTTPClient, fmt.Sprintf("%s/%s/%s", apiURL, silencePrefix, status.SilenceID)); err != nil {
+			klog.Errorf("failed to expire silence %s of the removed silence window %s of tm[%s/%s], error: %v", status.SilenceID, name, monitor.Namespace, monitor.Name, err)
+			newStatuses = append(newStatuses, status)
+			continue
+		}
+		klog.Infof("silence %s of the removed silence window %s of tm[%s/%s] is expired", status.SilenceID, name, monitor.Namespace, monitor.Name)
+	}
+	monitor.Status.SilenceWindows = newStatuses
+}
+
+func (m *MonitorManager) syncSilenceWindow(monitor *v1alpha1.TidbMonitor, window *v1alpha1.SilenceWindow,
+	status *v1alpha1.SilenceWindowStatus, apiURL string, now time.Time) error {
+	sched, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q: %v", window.Schedule, err)
+	}
+	duration, err := time.ParseDuration(window.Duration)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %v", window.Duration, err)
+	}
+
+	// the first occurrence that ends after now is the current or the next window
+	startsAt := sched.Next(now.Add(-duration))
+	endsAt := startsAt.Add(duration)
+	if status.SilenceID != "" && status.StartsAt != nil && status.StartsAt.Time.Equal(startsAt) {
+		return nil
+	}
+
+	comment := window.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("silence window %s of TidbMonitor %s/%s", window.Name, monitor.Namespace, monitor.Name)
+	}
+	silence := alertmanagerSilence{
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: silenceCreator,
+		Comment:   comment,
+	}
+	var names []string
+	for name := range window.Matchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		silence.Matchers = append(silence.Matchers, alertmanagerMatcher{Name: name, Value: window.Matchers[name], IsRegex: true})
+	}
+
+	id, err := postSilence(fmt.Sprintf("%s/%s", apiURL, silencesPrefix), &silence)
+	if err != nil {
+		return err
+	}
+	klog.Infof("silence %s is created for silence window %s of tm[%s/%s], from %s to %s",
+		id, window.Name, monitor.Namespace, monitor.Name, startsAt.Format(time.RFC3339), endsAt.Format(time.RFC3339))
+	status.SilenceID = id
+	status.StartsAt = &metav1.Time{Time: startsAt}
+	status.EndsAt = &metav1.Time{Time: endsAt}
+	status.Message = ""
+	return nil
+}
+
+func postSilence(apiURL string, silence *alertmanagerSilence) (string, error) {
+	body, err := json.Marshal(silence)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := silenceHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer httputil.DeferClose(res.Body)
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error response %v URL %s, body response: %s", res.StatusCode, apiURL, string(resBody))
+	}
+	resp := &postSilenceResponse{}
+	if err := json.Unmarshal(resBody, resp); err != nil {
+		return "", err
+	}
+	return resp.SilenceID, nil
+}
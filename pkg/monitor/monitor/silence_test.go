@@ -0,0 +1,106 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestSyncSilenceWindows(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var (
+		posted  []alertmanagerSilence
+		deleted []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/"+silencesPrefix:
+			g.Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+			silence := alertmanagerSilence{}
+			g.Expect(json.NewDecoder(r.Body).Decode(&silence)).To(Succeed())
+			posted = append(posted, silence)
+			fmt.Fprintf(w, `{"silenceID":"silence-%d"}`, len(posted))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	m := &MonitorManager{deps: controller.NewFakeDependencies()}
+	monitor := &v1alpha1.TidbMonitor{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "monitor"},
+		Spec: v1alpha1.TidbMonitorSpec{
+			AlertmanagerURL: pointer.StringPtr(server.URL),
+			SilenceWindows: []v1alpha1.SilenceWindow{
+				{Name: "backup", Schedule: "0 2 * * *", Duration: "2h", Matchers: map[string]string{"alertname": "TiKV_.*", "env": "prod"}},
+			},
+		},
+	}
+	day := time.Date(2021, 10, 1, 0, 0, 0, 0, time.Local)
+
+	// the silence of the next window is created ahead
+	m.syncSilenceWindows(monitor, day.Add(time.Hour))
+	g.Expect(posted).To(HaveLen(1))
+	g.Expect(posted[0].StartsAt.Equal(day.Add(2 * time.Hour))).To(BeTrue())
+	g.Expect(posted[0].EndsAt.Equal(day.Add(4 * time.Hour))).To(BeTrue())
+	g.Expect(posted[0].CreatedBy).To(Equal(silenceCreator))
+	g.Expect(posted[0].Comment).To(Equal("silence window backup of TidbMonitor ns/monitor"))
+	g.Expect(posted[0].Matchers).To(Equal([]alertmanagerMatcher{
+		{Name: "alertname", Value: "TiKV_.*", IsRegex: true},
+		{Name: "env", Value: "prod", IsRegex: true},
+	}))
+	g.Expect(monitor.Status.SilenceWindows).To(HaveLen(1))
+	g.Expect(monitor.Status.SilenceWindows[0].SilenceID).To(Equal("silence-1"))
+	g.Expect(monitor.Status.SilenceWindows[0].StartsAt.Time.Equal(day.Add(2 * time.Hour))).To(BeTrue())
+
+	// the silence is not recreated during the window
+	m.syncSilenceWindows(monitor, day.Add(3*time.Hour))
+	g.Expect(posted).To(HaveLen(1))
+
+	// the silence of the next day is created after the window ends
+	m.syncSilenceWindows(monitor, day.Add(4*time.Hour))
+	g.Expect(posted).To(HaveLen(2))
+	g.Expect(posted[1].StartsAt.Equal(day.Add(26 * time.Hour))).To(BeTrue())
+	g.Expect(monitor.Status.SilenceWindows[0].SilenceID).To(Equal("silence-2"))
+
+	// the pending silence of the removed window is expired
+	monitor.Spec.SilenceWindows = nil
+	m.syncSilenceWindows(monitor, day.Add(5*time.Hour))
+	g.Expect(deleted).To(Equal([]string{"/" + silencePrefix + "/silence-2"}))
+	g.Expect(monitor.Status.SilenceWindows).To(BeEmpty())
+
+	// the error is recorded in the status
+	monitor.Spec.SilenceWindows = []v1alpha1.SilenceWindow{
+		{Name: "upgrade", Schedule: "0 2 * * *", Duration: "1h", Matchers: map[string]string{"alertname": ".*"}},
+	}
+	monitor.Spec.AlertmanagerURL = pointer.StringPtr(server.URL + "/not-found")
+	m.syncSilenceWindows(monitor, day)
+	g.Expect(monitor.Status.SilenceWindows).To(HaveLen(1))
+	g.Expect(monitor.Status.SilenceWindows[0].SilenceID).To(BeEmpty())
+	g.Expect(monitor.Status.SilenceWindows[0].Message).To(ContainSubstring("error response 404"))
+}
@@ -15,7 +15,11 @@ package monitor
 
 import (
 	"fmt"
+	"path"
 
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 )
 
@@ -27,15 +31,21 @@ import (
 //
 // Store doesn't support concurrent access.
 type Store struct {
-	secretLister corelisterv1.SecretLister
-	TLSAssets    map[TLSAssetKey]TLSAsset
+	secretLister    corelisterv1.SecretLister
+	configMapLister corelisterv1.ConfigMapLister
+	TLSAssets       map[TLSAssetKey]TLSAsset
+	// BasicAuthUsernames maps the key of the remote write, e.g. `remoteWrite/0`, to
+	// the username of its basic auth, the password is added to the TLS assets.
+	BasicAuthUsernames map[string]string
 }
 
 // NewStore returns an empty assetStore.
-func NewStore(secretLister corelisterv1.SecretLister) *Store {
+func NewStore(secretLister corelisterv1.SecretLister, configMapLister corelisterv1.ConfigMapLister) *Store {
 	return &Store{
-		secretLister: secretLister,
-		TLSAssets:    make(map[TLSAssetKey]TLSAsset),
+		secretLister:       secretLister,
+		configMapLister:    configMapLister,
+		TLSAssets:          make(map[TLSAssetKey]TLSAsset),
+		BasicAuthUsernames: make(map[string]string),
 	}
 }
 
@@ -52,6 +62,103 @@ func (s *Store) addTLSAssets(ns string, secretName string) error {
 	return nil
 }
 
+// addRemoteWriteAssets processes the Secrets and ConfigMaps referenced by the remote write specs, the CAs,
+// certificates, keys and basic auth passwords are added to the TLS assets, which are mounted into the
+// Prometheus container, and the basic auth usernames are added to the store.
+func (s *Store) addRemoteWriteAssets(ns string, remoteWrites []*v1alpha1.RemoteWriteSpec) error {
+	for i, remoteWrite := range remoteWrites {
+		if remoteWrite.BasicAuth != nil {
+			username, err := s.getSecretKey(ns, remoteWrite.BasicAuth.Username)
+			if err != nil {
+				return err
+			}
+			s.BasicAuthUsernames[remoteWriteKey(i)] = username
+			if err := s.addSecretKeyAsset(ns, &remoteWrite.BasicAuth.Password); err != nil {
+				return err
+			}
+		}
+		if remoteWrite.TLSConfig != nil {
+			tlsConfig := remoteWrite.TLSConfig
+			if err := s.addSecretOrConfigMapAsset(ns, tlsConfig.CA); err != nil {
+				return err
+			}
+			if err := s.addSecretOrConfigMapAsset(ns, tlsConfig.Cert); err != nil {
+				return err
+			}
+			if tlsConfig.KeySecret != nil {
+				if err := s.addSecretKeyAsset(ns, tlsConfig.KeySecret); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addSecretOrConfigMapAsset adds the key of the Secret or ConfigMap to the TLS assets, nothing is done if neither is set.
+func (s *Store) addSecretOrConfigMapAsset(ns string, sel v1alpha1.SecretOrConfigMap) error {
+	if sel.Secret != nil {
+		return s.addSecretKeyAsset(ns, sel.Secret)
+	}
+	if sel.ConfigMap != nil {
+		cm, err := s.configMapLister.ConfigMaps(ns).Get(sel.ConfigMap.Name)
+		if err != nil {
+			return fmt.Errorf("get configmap [%s/%s] failed, err: %v", ns, sel.ConfigMap.Name, err)
+		}
+		value, ok := cm.Data[sel.ConfigMap.Key]
+		if !ok {
+			return fmt.Errorf("key %s not found in configmap [%s/%s]", sel.ConfigMap.Key, ns, sel.ConfigMap.Name)
+		}
+		s.TLSAssets[TLSAssetKey{"configmap", ns, sel.ConfigMap.Name, sel.ConfigMap.Key}] = TLSAsset(value)
+	}
+	return nil
+}
+
+// addSecretKeyAsset adds the key of the Secret to the TLS assets.
+func (s *Store) addSecretKeyAsset(ns string, sel *corev1.SecretKeySelector) error {
+	value, err := s.getSecretKey(ns, *sel)
+	if err != nil {
+		return err
+	}
+	s.TLSAssets[TLSAssetKey{"secret", ns, sel.Name, sel.Key}] = TLSAsset(value)
+	return nil
+}
+
+// getSecretKey returns the value of the key of the Secret.
+func (s *Store) getSecretKey(ns string, sel corev1.SecretKeySelector) (string, error) {
+	secret, err := s.secretLister.Secrets(ns).Get(sel.Name)
+	if err != nil {
+		return "", fmt.Errorf("get secret [%s/%s] failed, err: %v", ns, sel.Name, err)
+	}
+	value, ok := secret.Data[sel.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret [%s/%s]", sel.Key, ns, sel.Name)
+	}
+	return string(value), nil
+}
+
+// remoteWriteKey returns the key of the i-th remote write in the store.
+func remoteWriteKey(i int) string {
+	return fmt.Sprintf("remoteWrite/%d", i)
+}
+
+// secretKeyAssetPath returns the path of the key of the Secret in the Prometheus container.
+func secretKeyAssetPath(ns string, sel *corev1.SecretKeySelector) string {
+	return path.Join(util.ClusterAssetsTLSPath, TLSAssetKey{"secret", ns, sel.Name, sel.Key}.String())
+}
+
+// secretOrConfigMapAssetPath returns the path of the key of the Secret or ConfigMap in the Prometheus container,
+// an empty path is returned if neither is set.
+func secretOrConfigMapAssetPath(ns string, sel v1alpha1.SecretOrConfigMap) string {
+	if sel.Secret != nil {
+		return secretKeyAssetPath(ns, sel.Secret)
+	}
+	if sel.ConfigMap != nil {
+		return path.Join(util.ClusterAssetsTLSPath, TLSAssetKey{"configmap", ns, sel.ConfigMap.Name, sel.ConfigMap.Key}.String())
+	}
+	return ""
+}
+
 // TLSAssetKey is a key for a TLS asset.
 type TLSAssetKey struct {
 	from string
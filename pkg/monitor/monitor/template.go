@@ -143,12 +143,20 @@ type MonitorConfigModel struct {
 	ClusterInfos              []ClusterRegexInfo
 	DMClusterInfos            []ClusterRegexInfo
 	ExternalLabels            model.LabelSet
+	ReplicaExternalLabelName  string
 	RemoteWriteConfigs        []*config.RemoteWriteConfig
 	EnableAlertRules          bool
 	EnableExternalRuleConfigs bool
+	ScrapeOverrides           map[string]ScrapeOverride
 	shards                    int32
 }
 
+// ScrapeOverride is the scrape interval and timeout of a job, the zero values mean the defaults
+type ScrapeOverride struct {
+	Interval model.Duration
+	Timeout  model.Duration
+}
+
 // ClusterRegexInfo is the monitor cluster info
 type ClusterRegexInfo struct {
 	Name      string
@@ -411,6 +419,15 @@ func scrapeJob(jobName string, componentPattern config.Regexp, cmodel *MonitorCo
 				},
 			},
 		}
+		if override, ok := cmodel.ScrapeOverrides[jobName]; ok {
+			if override.Interval != 0 {
+				scrapeconfig.ScrapeInterval = override.Interval
+			}
+			// Prometheus defaults the timeout to the interval if the interval is less than the global timeout
+			if override.Timeout != 0 {
+				scrapeconfig.ScrapeTimeout = override.Timeout
+			}
+		}
 		scrapeconfig.RelabelConfigs = appendShardingRelabelConfigRules(scrapeconfig.RelabelConfigs, uint64(cmodel.shards))
 		if cluster.enableTLS && !isDMJob(jobName) {
 			scrapeconfig.Scheme = "https"
@@ -465,6 +482,21 @@ func addAlertManagerUrl(pc *config.Config, cmodel *MonitorConfigModel) {
 			},
 		},
 	}
+	// drop the replica label from the alerts, so that Alertmanager deduplicates the same alerts
+	// fired by the replicas of the monitor
+	if cmodel.ReplicaExternalLabelName != "" {
+		replicaPattern, err := config.NewRegexp(cmodel.ReplicaExternalLabelName)
+		if err != nil {
+			klog.Errorf("Generate pattern for replica label %s error: %v", cmodel.ReplicaExternalLabelName, err)
+			return
+		}
+		pc.AlertingConfig.AlertRelabelConfigs = []*config.RelabelConfig{
+			{
+				Regex:  replicaPattern,
+				Action: config.RelabelLabelDrop,
+			},
+		}
+	}
 }
 
 func RenderPrometheusConfig(model *MonitorConfigModel) (string, error) {
@@ -29,6 +29,7 @@ import (
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 type promConfigsModel struct {
@@ -2332,3 +2333,60 @@ func TestScrapeJob(t *testing.T) {
 		KeyFile:  path.Join(util.ClusterAssetsTLSPath, TLSAssetKey{"secret", ns, tcTlsSecretName, corev1.TLSPrivateKeyKey}.String()),
 	}))
 }
+
+func TestScrapeJobWithScrapeOverrides(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tm := &v1alpha1.TidbMonitor{
+		Spec: v1alpha1.TidbMonitorSpec{
+			Prometheus: v1alpha1.PrometheusSpec{
+				ScrapeConfigs: map[string]v1alpha1.ScrapeConfig{
+					"tikv": {Interval: pointer.StringPtr("1m"), Timeout: pointer.StringPtr("30s")},
+					"pd":   {Interval: pointer.StringPtr("5s")},
+				},
+			},
+		},
+	}
+	overrides, err := buildScrapeOverrides(tm)
+	g.Expect(err).NotTo(HaveOccurred())
+	cmodel := &MonitorConfigModel{
+		ClusterInfos:    []ClusterRegexInfo{{Name: "ns1", Namespace: "ns1"}},
+		ScrapeOverrides: overrides,
+	}
+
+	tikvJobs := scrapeJob("tikv", tikvPattern, cmodel, buildAddressRelabelConfigByComponent("tikv"))
+	g.Expect(tikvJobs[0].ScrapeInterval).To(Equal(model.Duration(time.Minute)))
+	g.Expect(tikvJobs[0].ScrapeTimeout).To(Equal(model.Duration(30 * time.Second)))
+	pdJobs := scrapeJob("pd", pdPattern, cmodel, buildAddressRelabelConfigByComponent("pd"))
+	g.Expect(pdJobs[0].ScrapeInterval).To(Equal(model.Duration(5 * time.Second)))
+	g.Expect(pdJobs[0].ScrapeTimeout).To(BeZero())
+	tidbJobs := scrapeJob("tidb", tidbPattern, cmodel, buildAddressRelabelConfigByComponent("tidb"))
+	g.Expect(tidbJobs[0].ScrapeInterval).To(Equal(model.Duration(15 * time.Second)))
+
+	content, err := RenderPrometheusConfig(cmodel)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).To(ContainSubstring("scrape_interval: 1m\n  scrape_timeout: 30s"))
+}
+
+func TestRenderPrometheusConfigAlertReplicaLabel(t *testing.T) {
+	g := NewGomegaWithT(t)
+	model := &MonitorConfigModel{
+		ClusterInfos: []ClusterRegexInfo{
+			{Name: "target", Namespace: "ns1"},
+		},
+		AlertmanagerURL:          "alert-url",
+		ReplicaExternalLabelName: "prometheus_replica",
+	}
+	content, err := RenderPrometheusConfig(model)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).To(ContainSubstring(`alerting:
+  alert_relabel_configs:
+  - regex: prometheus_replica
+    action: labeldrop
+`))
+
+	model.ReplicaExternalLabelName = ""
+	content, err = RenderPrometheusConfig(model)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).NotTo(ContainSubstring("alert_relabel_configs"))
+}
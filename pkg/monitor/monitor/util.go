@@ -37,6 +37,7 @@ import (
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
 
@@ -88,6 +89,12 @@ func buildTidbMonitorLabel(name string) map[string]string {
 	return label.NewMonitor().Instance(name).Monitor().Labels()
 }
 
+// buildTidbMonitorTargetLabel returns the labels of the objects of the monitor in the namespaces of the target clusters,
+// the namespace of the monitor is included as they can't be owned by the monitor.
+func buildTidbMonitorTargetLabel(monitor *v1alpha1.TidbMonitor) map[string]string {
+	return label.NewMonitor().Instance(monitor.Name).Monitor().Namespace(monitor.Namespace).Labels()
+}
+
 func buildTidbMonitorPromLabel(name string) map[string]string {
 	return label.NewMonitor().Instance(name).Monitor().Prometheus().Labels()
 }
@@ -96,6 +103,10 @@ func buildTidbMonitorGrafanaLabel(name string) map[string]string {
 	return label.NewMonitor().Instance(name).Monitor().Grafana().Labels()
 }
 
+func buildTidbMonitorThanosQueryLabel(name string) map[string]string {
+	return label.NewMonitor().Instance(name).Monitor().UsedBy("thanos-query").Labels()
+}
+
 func getInitCommand(monitor *v1alpha1.TidbMonitor) []string {
 	c := `mkdir -p /data/prometheus
 chmod 777 /data/prometheus
@@ -140,8 +151,48 @@ func getGrafanaEnvs() []core.EnvVar {
 	}
 }
 
+// getInitializerVersion returns the version of the initializer image, which is selected by the version
+// of the monitored cluster if `dashboardVersionFromCluster` is enabled and the version is known.
+func getInitializerVersion(monitor *v1alpha1.TidbMonitor) string {
+	if monitor.Spec.DashboardVersionFromCluster && monitor.Status.DashboardVersion != "" {
+		return monitor.Status.DashboardVersion
+	}
+	return monitor.Spec.Initializer.Version
+}
+
+// getClusterDashboardVersion returns the version of the cluster to select the dashboards by, an empty
+// version is returned unless the images of PD, TiKV and TiDB in the status have the same version,
+// e.g. during the upgrade of the cluster.
+func getClusterDashboardVersion(tc *v1alpha1.TidbCluster) string {
+	var images []string
+	if tc.Spec.PD != nil {
+		images = append(images, tc.Status.PD.Image)
+	}
+	if tc.Spec.TiKV != nil {
+		images = append(images, tc.Status.TiKV.Image)
+	}
+	if tc.Spec.TiDB != nil {
+		images = append(images, tc.Status.TiDB.Image)
+	}
+
+	var version string
+	for _, image := range images {
+		colonIdx := strings.LastIndexByte(image, ':')
+		if colonIdx < 0 || strings.ContainsAny(image[colonIdx+1:], "/@") {
+			// the image without a tag or with a digest
+			return ""
+		}
+		tag := image[colonIdx+1:]
+		if version != "" && tag != version {
+			return ""
+		}
+		version = tag
+	}
+	return version
+}
+
 func getAlertManagerRulesVersion(monitor *v1alpha1.TidbMonitor) string {
-	alertManagerRulesVersion := fmt.Sprintf("tidb:%s", monitor.Spec.Initializer.Version)
+	alertManagerRulesVersion := fmt.Sprintf("tidb:%s", getInitializerVersion(monitor))
 	if monitor.Spec.AlertManagerRulesVersion != nil {
 		alertManagerRulesVersion = fmt.Sprintf("tidb:%s", *monitor.Spec.AlertManagerRulesVersion)
 	}
@@ -150,18 +201,25 @@ func getAlertManagerRulesVersion(monitor *v1alpha1.TidbMonitor) string {
 
 // getPromConfigMap generate the Prometheus config for TidbMonitor,
 // If the namespace in ClusterRef is empty, we would set the TidbMonitor's namespace in the default
-func getPromConfigMap(monitor *v1alpha1.TidbMonitor, monitorClusterInfos []ClusterRegexInfo, dmClusterInfos []ClusterRegexInfo, shard int32) (*core.ConfigMap, error) {
+func getPromConfigMap(monitor *v1alpha1.TidbMonitor, store *Store, monitorClusterInfos []ClusterRegexInfo, dmClusterInfos []ClusterRegexInfo, shard int32) (*core.ConfigMap, error) {
 	model := &MonitorConfigModel{
-		AlertmanagerURL:  "",
-		ClusterInfos:     monitorClusterInfos,
-		DMClusterInfos:   dmClusterInfos,
-		ExternalLabels:   buildExternalLabels(monitor),
-		EnableAlertRules: monitor.Spec.EnableAlertRules,
-		shards:           shard,
+		AlertmanagerURL:          "",
+		ClusterInfos:             monitorClusterInfos,
+		DMClusterInfos:           dmClusterInfos,
+		ExternalLabels:           buildExternalLabels(monitor),
+		ReplicaExternalLabelName: getReplicaExternalLabelName(monitor),
+		EnableAlertRules:         monitor.Spec.EnableAlertRules,
+		shards:                   shard,
+	}
+
+	scrapeOverrides, err := buildScrapeOverrides(monitor)
+	if err != nil {
+		return nil, err
 	}
+	model.ScrapeOverrides = scrapeOverrides
 
 	if len(monitor.Spec.Prometheus.RemoteWrite) > 0 {
-		model.RemoteWriteConfigs = generateRemoteWrite(monitor)
+		model.RemoteWriteConfigs = generateRemoteWrite(monitor, store)
 	}
 
 	if monitor.Spec.AlertmanagerURL != nil {
@@ -304,11 +362,69 @@ func getMonitorRoleBinding(sa *core.ServiceAccount, role *rbac.Role, monitor *v1
 	}
 }
 
+// getMonitorTargetRole returns the Role in the namespace of a target cluster for Prometheus to discover the Pods,
+// it's not owned by the monitor as owner references across namespaces are not allowed.
+func getMonitorTargetRole(monitor *v1alpha1.TidbMonitor, ns string) *rbac.Role {
+	return &rbac.Role{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      GetMonitorObjectNameCrossNamespace(monitor),
+			Namespace: ns,
+			Labels:    buildTidbMonitorTargetLabel(monitor),
+		},
+		Rules: []rbac.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get", "list", "watch"},
+			},
+		},
+	}
+}
+
+// getMonitorTargetRoleBinding returns the RoleBinding of the Role in the namespace of a target cluster.
+func getMonitorTargetRoleBinding(sa *core.ServiceAccount, role *rbac.Role, monitor *v1alpha1.TidbMonitor) *rbac.RoleBinding {
+	return &rbac.RoleBinding{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      role.Name,
+			Namespace: role.Namespace,
+			Labels:    buildTidbMonitorTargetLabel(monitor),
+		},
+		Subjects: []rbac.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      sa.Name,
+				Namespace: sa.Namespace,
+				APIGroup:  "",
+			},
+		},
+		RoleRef: rbac.RoleRef{
+			Kind:     "Role",
+			Name:     role.Name,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+}
+
+// getTargetNamespaces returns the namespaces of the target clusters other than the namespace of the monitor.
+func getTargetNamespaces(monitor *v1alpha1.TidbMonitor) sets.String {
+	namespaces := sets.NewString()
+	for _, tcRef := range monitor.Spec.Clusters {
+		namespaces.Insert(tcRef.Namespace)
+	}
+	if monitor.Spec.DM != nil {
+		for _, dcRef := range monitor.Spec.DM.Clusters {
+			namespaces.Insert(dcRef.Namespace)
+		}
+	}
+	namespaces.Delete(monitor.Namespace, "")
+	return namespaces
+}
+
 func getMonitorInitContainer(monitor *v1alpha1.TidbMonitor, tc *v1alpha1.TidbCluster) core.Container {
 	command := getInitCommand(monitor)
 	container := core.Container{
 		Name:  "monitor-initializer",
-		Image: fmt.Sprintf("%s:%s", monitor.Spec.Initializer.BaseImage, monitor.Spec.Initializer.Version),
+		Image: v1alpha1.ImageWithRegistryPrefix(monitor.Spec.ClusterRegistryPrefix, fmt.Sprintf("%s:%s", monitor.Spec.Initializer.BaseImage, getInitializerVersion(monitor))),
 		Env: []core.EnvVar{
 			{
 				Name:  "PROM_CONFIG_PATH",
@@ -398,7 +514,7 @@ func getMonitorDMInitContainer(monitor *v1alpha1.TidbMonitor, dc *v1alpha1.DMClu
 	command := getInitCommand(monitor)
 	container := core.Container{
 		Name:  "dm-initializer",
-		Image: fmt.Sprintf("%s:%s", monitor.Spec.DM.Initializer.BaseImage, monitor.Spec.DM.Initializer.Version),
+		Image: v1alpha1.ImageWithRegistryPrefix(monitor.Spec.ClusterRegistryPrefix, fmt.Sprintf("%s:%s", monitor.Spec.DM.Initializer.BaseImage, monitor.Spec.DM.Initializer.Version)),
 		Env: []core.EnvVar{
 			{
 				Name:  "DM_CLUSTER_NAME",
@@ -474,7 +590,7 @@ func getMonitorPrometheusContainer(monitor *v1alpha1.TidbMonitor, shard int32) c
 	commands := []string{"sed -e '5s/[()]//g' -e 's/SHARD//g'  -e 's/$NAMESPACE/'\"$NAMESPACE\"'/g;s/$POD_NAME/'\"$POD_NAME\"'/g;s/$()/'$(SHARD)'/g' /etc/prometheus/config/prometheus.yml > /etc/prometheus/config_out/prometheus.yml && /bin/prometheus --web.enable-admin-api --web.enable-lifecycle --config.file=/etc/prometheus/config_out/prometheus.yml --storage.tsdb.path=/data/prometheus --storage.tsdb.retention.time=" + retention}
 	c := core.Container{
 		Name:      "prometheus",
-		Image:     fmt.Sprintf("%s:%s", monitor.Spec.Prometheus.BaseImage, monitor.Spec.Prometheus.Version),
+		Image:     v1alpha1.ImageWithRegistryPrefix(monitor.Spec.ClusterRegistryPrefix, fmt.Sprintf("%s:%s", monitor.Spec.Prometheus.BaseImage, monitor.Spec.Prometheus.Version)),
 		Resources: controller.ContainerResource(monitor.Spec.Prometheus.ResourceRequirements),
 		Command: []string{
 			"/bin/sh",
@@ -621,7 +737,7 @@ func getMonitorGrafanaContainer(secret *core.Secret, monitor *v1alpha1.TidbMonit
 
 	c := core.Container{
 		Name:      "grafana",
-		Image:     fmt.Sprintf("%s:%s", monitor.Spec.Grafana.BaseImage, monitor.Spec.Grafana.Version),
+		Image:     v1alpha1.ImageWithRegistryPrefix(monitor.Spec.ClusterRegistryPrefix, fmt.Sprintf("%s:%s", monitor.Spec.Grafana.BaseImage, monitor.Spec.Grafana.Version)),
 		Resources: controller.ContainerResource(monitor.Spec.Grafana.ResourceRequirements),
 		Ports: []core.ContainerPort{
 			{
@@ -723,7 +839,7 @@ func getMonitorGrafanaContainer(secret *core.Secret, monitor *v1alpha1.TidbMonit
 func getMonitorPrometheusReloaderContainer(monitor *v1alpha1.TidbMonitor, shard int32) core.Container {
 	c := core.Container{
 		Name:  "prometheus-config-reloader",
-		Image: fmt.Sprintf("%s:%s", monitor.Spec.PrometheusReloader.BaseImage, monitor.Spec.PrometheusReloader.Version),
+		Image: v1alpha1.ImageWithRegistryPrefix(monitor.Spec.ClusterRegistryPrefix, fmt.Sprintf("%s:%s", monitor.Spec.PrometheusReloader.BaseImage, monitor.Spec.PrometheusReloader.Version)),
 		Command: []string{
 			"/bin/prometheus-config-reloader",
 			"--listen-address=:9088",
@@ -787,7 +903,7 @@ func getMonitorPrometheusReloaderContainer(monitor *v1alpha1.TidbMonitor, shard
 func getMonitorReloaderContainer(monitor *v1alpha1.TidbMonitor) core.Container {
 	c := core.Container{
 		Name:  "reloader",
-		Image: fmt.Sprintf("%s:%s", monitor.Spec.Reloader.BaseImage, monitor.Spec.Reloader.Version),
+		Image: v1alpha1.ImageWithRegistryPrefix(monitor.Spec.ClusterRegistryPrefix, fmt.Sprintf("%s:%s", monitor.Spec.Reloader.BaseImage, monitor.Spec.Reloader.Version)),
 		Command: []string{
 			"/bin/reload",
 			"--root-store-path=/data",
@@ -1063,11 +1179,163 @@ func getMonitorService(monitor *v1alpha1.TidbMonitor) []*core.Service {
 
 			services = append(services, grafanaService)
 		}
+
+		if monitor.Spec.Thanos != nil && monitor.Spec.Thanos.Query != nil {
+			// the headless service resolves to every replica of the shard, so that
+			// the Thanos Query discovers all the Thanos sidecars by DNS SRV records
+			services = append(services, &core.Service{
+				ObjectMeta: meta.ObjectMeta{
+					Name:            thanosSidecarName(monitor.Name, shard),
+					Namespace:       monitor.Namespace,
+					Labels:          util.CombineStringMap(promeLabel.Labels(), monitor.Spec.Labels),
+					OwnerReferences: []meta.OwnerReference{controller.GetTiDBMonitorOwnerRef(monitor)},
+					Annotations:     util.CopyStringMap(monitor.Spec.Annotations),
+				},
+				Spec: core.ServiceSpec{
+					ClusterIP: core.ClusterIPNone,
+					Ports: []core.ServicePort{
+						{
+							Name:       "thanos-grpc",
+							Protocol:   core.ProtocolTCP,
+							Port:       10901,
+							TargetPort: intstr.FromInt(10901),
+						},
+					},
+					Selector:                 selector,
+					PublishNotReadyAddresses: true,
+				},
+			})
+		}
+	}
+
+	if monitor.Spec.Thanos != nil && monitor.Spec.Thanos.Query != nil {
+		services = append(services, getThanosQueryService(monitor))
 	}
 
 	return services
 }
 
+func getThanosQueryService(monitor *v1alpha1.TidbMonitor) *core.Service {
+	svcSpec := monitor.Spec.Thanos.Query.Service
+	svc := &core.Service{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            ThanosQueryName(monitor.Name),
+			Namespace:       monitor.Namespace,
+			Labels:          util.CombineStringMap(buildTidbMonitorThanosQueryLabel(monitor.Name), svcSpec.Labels, monitor.Spec.Labels),
+			OwnerReferences: []meta.OwnerReference{controller.GetTiDBMonitorOwnerRef(monitor)},
+			Annotations:     util.CombineStringMap(svcSpec.Annotations, monitor.Spec.Annotations),
+		},
+		Spec: core.ServiceSpec{
+			Ports: []core.ServicePort{
+				{
+					Name:       "http-thanos-query",
+					Protocol:   core.ProtocolTCP,
+					Port:       10902,
+					TargetPort: intstr.FromInt(10902),
+				},
+			},
+			Type:     svcSpec.Type,
+			Selector: buildTidbMonitorThanosQueryLabel(monitor.Name),
+		},
+	}
+	if svcSpec.Type == core.ServiceTypeLoadBalancer {
+		if svcSpec.LoadBalancerIP != nil {
+			svc.Spec.LoadBalancerIP = *svcSpec.LoadBalancerIP
+		}
+		if svcSpec.LoadBalancerSourceRanges != nil {
+			svc.Spec.LoadBalancerSourceRanges = svcSpec.LoadBalancerSourceRanges
+		}
+	}
+	return svc
+}
+
+// getThanosQueryDeployment returns the Thanos Query that queries the Thanos sidecars of all the shards and
+// deduplicates the series of the replicas by the replica external label
+func getThanosQueryDeployment(monitor *v1alpha1.TidbMonitor) *apps.Deployment {
+	thanos := monitor.Spec.Thanos
+	replicas := int32(1)
+	if thanos.Query.Replicas != nil {
+		replicas = *thanos.Query.Replicas
+	}
+	args := []string{"query",
+		"--http-address=0.0.0.0:10902",
+		"--grpc-address=0.0.0.0:10901",
+	}
+	if replicaLabel := getReplicaExternalLabelName(monitor); replicaLabel != "" {
+		args = append(args, "--query.replica-label="+replicaLabel)
+	}
+	for shard := int32(0); shard < monitor.GetShards(); shard++ {
+		args = append(args, fmt.Sprintf("--store=dnssrv+_thanos-grpc._tcp.%s.%s.svc", thanosSidecarName(monitor.Name, shard), monitor.Namespace))
+	}
+	if thanos.LogLevel != "" {
+		args = append(args, "--log.level="+thanos.LogLevel)
+	}
+	if thanos.LogFormat != "" {
+		args = append(args, "--log.format="+thanos.LogFormat)
+	}
+
+	podLabels := buildTidbMonitorThanosQueryLabel(monitor.Name)
+	deploy := &apps.Deployment{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            ThanosQueryName(monitor.Name),
+			Namespace:       monitor.Namespace,
+			Labels:          podLabels,
+			OwnerReferences: []meta.OwnerReference{controller.GetTiDBMonitorOwnerRef(monitor)},
+			Annotations:     util.CopyStringMap(monitor.Spec.Annotations),
+		},
+		Spec: apps.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &meta.LabelSelector{
+				MatchLabels: podLabels,
+			},
+			Template: core.PodTemplateSpec{
+				ObjectMeta: meta.ObjectMeta{
+					Labels:      util.CombineStringMap(podLabels, monitor.Spec.Labels),
+					Annotations: util.CopyStringMap(monitor.Spec.Annotations),
+				},
+				Spec: core.PodSpec{
+					SecurityContext:  monitor.Spec.PodSecurityContext,
+					Tolerations:      monitor.Spec.Tolerations,
+					NodeSelector:     monitor.Spec.NodeSelector,
+					ImagePullSecrets: monitor.Spec.ImagePullSecrets,
+					Containers: []core.Container{
+						{
+							Name:      "thanos-query",
+							Image:     v1alpha1.ImageWithRegistryPrefix(monitor.Spec.ClusterRegistryPrefix, fmt.Sprintf("%s:%s", thanos.BaseImage, thanos.Version)),
+							Resources: controller.ContainerResource(thanos.Query.ResourceRequirements),
+							Args:      args,
+							Ports: []core.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: 10902,
+									Protocol:      core.ProtocolTCP,
+								},
+								{
+									Name:          "grpc",
+									ContainerPort: 10901,
+									Protocol:      core.ProtocolTCP,
+								},
+							},
+							ReadinessProbe: &core.Probe{
+								Handler: core.Handler{
+									HTTPGet: &core.HTTPGetAction{
+										Path: "/-/ready",
+										Port: intstr.FromInt(10902),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if thanos.ImagePullPolicy != nil {
+		deploy.Spec.Template.Spec.Containers[0].ImagePullPolicy = *thanos.ImagePullPolicy
+	}
+	return deploy
+}
+
 func getIngress(monitor *v1alpha1.TidbMonitor, ingressSpec *v1alpha1.IngressSpec, svcName string, port int) *networkingv1.Ingress {
 	monitorLabel := buildTidbMonitorLabel(monitor.Name)
 	backend := networkingv1.IngressBackend{
@@ -1186,6 +1454,20 @@ func reloaderName(monitor *v1alpha1.TidbMonitor, shard int32) string {
 	return fmt.Sprintf("%s-monitor-reloader-shard-%d", monitor.Name, shard)
 }
 
+// ThanosQueryName returns the name of the Deployment and the Service of the Thanos Query
+func ThanosQueryName(name string) string {
+	return fmt.Sprintf("%s-thanos-query", name)
+}
+
+// thanosSidecarName returns the name of the headless Service of the Thanos sidecars of the shard
+func thanosSidecarName(name string, shard int32) string {
+	base := fmt.Sprintf("%s-thanos-sidecar", name)
+	if shard == 0 {
+		return base
+	}
+	return fmt.Sprintf("%s-thanos-sidecar-shard-%d", name, shard)
+}
+
 func defaultTidbMonitor(monitor *v1alpha1.TidbMonitor) {
 	for id, tcRef := range monitor.Spec.Clusters {
 		if len(tcRef.Namespace) < 1 {
@@ -1349,7 +1631,7 @@ func getThanosSidecarContainer(monitor *v1alpha1.TidbMonitor) core.Container {
 
 	container := core.Container{
 		Name:      "thanos-sidecar",
-		Image:     fmt.Sprintf("%s:%s", thanos.BaseImage, thanos.Version),
+		Image:     v1alpha1.ImageWithRegistryPrefix(monitor.Spec.ClusterRegistryPrefix, fmt.Sprintf("%s:%s", thanos.BaseImage, thanos.Version)),
 		Resources: controller.ContainerResource(thanos.ResourceRequirements),
 		Args:      thanosArgs,
 		Env: []core.EnvVar{
@@ -1441,18 +1723,19 @@ func getThanosSidecarContainer(monitor *v1alpha1.TidbMonitor) core.Container {
 	return container
 }
 
-func buildExternalLabels(monitor *v1alpha1.TidbMonitor) model.LabelSet {
-	m := model.LabelSet{}
+// getReplicaExternalLabelName returns the name of the external label distinguishing the replicas of the monitor
+func getReplicaExternalLabelName(monitor *v1alpha1.TidbMonitor) string {
 	// Use defaultReplicaExternalLabelName constant by default if field is missing.
 	// Do not add external label if field is set to empty string.
-	replicaExternalLabelName := defaultReplicaExternalLabelName
 	if monitor.Spec.ReplicaExternalLabelName != nil {
-		if *monitor.Spec.ReplicaExternalLabelName != "" {
-			replicaExternalLabelName = *monitor.Spec.ReplicaExternalLabelName
-		} else {
-			replicaExternalLabelName = ""
-		}
+		return *monitor.Spec.ReplicaExternalLabelName
 	}
+	return defaultReplicaExternalLabelName
+}
+
+func buildExternalLabels(monitor *v1alpha1.TidbMonitor) model.LabelSet {
+	m := model.LabelSet{}
+	replicaExternalLabelName := getReplicaExternalLabelName(monitor)
 	if replicaExternalLabelName != "" {
 		m[model.LabelName(replicaExternalLabelName)] = "$(NAMESPACE)_$(POD_NAME)"
 	}
@@ -1462,9 +1745,38 @@ func buildExternalLabels(monitor *v1alpha1.TidbMonitor) model.LabelSet {
 	return m
 }
 
-func generateRemoteWrite(monitor *v1alpha1.TidbMonitor) []*config.RemoteWriteConfig {
+// buildScrapeOverrides parses the scrape intervals and timeouts of the components overridden by the TidbMonitor
+func buildScrapeOverrides(monitor *v1alpha1.TidbMonitor) (map[string]ScrapeOverride, error) {
+	if len(monitor.Spec.Prometheus.ScrapeConfigs) == 0 {
+		return nil, nil
+	}
+	overrides := map[string]ScrapeOverride{}
+	for name, scrapeConfig := range monitor.Spec.Prometheus.ScrapeConfigs {
+		var override ScrapeOverride
+		if scrapeConfig.Interval != nil {
+			interval, err := model.ParseDuration(*scrapeConfig.Interval)
+			if err != nil {
+				return nil, fmt.Errorf("parse the scrape interval of %s failed: %v", name, err)
+			}
+			override.Interval = interval
+		}
+		if scrapeConfig.Timeout != nil {
+			timeout, err := model.ParseDuration(*scrapeConfig.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("parse the scrape timeout of %s failed: %v", name, err)
+			}
+			override.Timeout = timeout
+		}
+		overrides[name] = override
+	}
+	return overrides, nil
+}
+
+// generateRemoteWrite generates the remote write configs, the Secrets and ConfigMaps referenced by
+// the basic auth and TLS config are read from the files of the TLS assets in the store.
+func generateRemoteWrite(monitor *v1alpha1.TidbMonitor, store *Store) []*config.RemoteWriteConfig {
 	var remoteWriteConfigs []*config.RemoteWriteConfig
-	for _, remoteWrite := range monitor.Spec.Prometheus.RemoteWrite {
+	for i, remoteWrite := range monitor.Spec.Prometheus.RemoteWrite {
 		url, err := url.Parse(remoteWrite.URL)
 		if err != nil {
 			klog.Errorf("remote write url[%s] config fail to parse, err:%v", remoteWrite.URL, err)
@@ -1481,6 +1793,32 @@ func generateRemoteWrite(monitor *v1alpha1.TidbMonitor) []*config.RemoteWriteCon
 				ServerName:         remoteWrite.TLSConfig.ServerName,
 				InsecureSkipVerify: remoteWrite.TLSConfig.InsecureSkipVerify,
 			}
+			if caFile := secretOrConfigMapAssetPath(monitor.Namespace, remoteWrite.TLSConfig.CA); caFile != "" {
+				httpClientConfig.TLSConfig.CAFile = caFile
+			}
+			if certFile := secretOrConfigMapAssetPath(monitor.Namespace, remoteWrite.TLSConfig.Cert); certFile != "" {
+				httpClientConfig.TLSConfig.CertFile = certFile
+			}
+			if remoteWrite.TLSConfig.KeySecret != nil {
+				httpClientConfig.TLSConfig.KeyFile = secretKeyAssetPath(monitor.Namespace, remoteWrite.TLSConfig.KeySecret)
+			}
+		}
+		if remoteWrite.BasicAuth != nil {
+			// the vendored config doesn't support password_file, and marshals the password as `<secret>`
+			httpClientConfig.BasicAuth = &config.BasicAuth{
+				Username: store.BasicAuthUsernames[remoteWriteKey(i)],
+				XXX: map[string]interface{}{
+					"password_file": secretKeyAssetPath(monitor.Namespace, &remoteWrite.BasicAuth.Password),
+				},
+			}
+		}
+		if remoteWrite.ProxyURL != nil && *remoteWrite.ProxyURL != "" {
+			proxyURL, err := url.Parse(*remoteWrite.ProxyURL)
+			if err != nil {
+				klog.Errorf("remote write proxy url[%s] config fail to parse, err:%v", *remoteWrite.ProxyURL, err)
+				continue
+			}
+			httpClientConfig.ProxyURL = config.URL{URL: proxyURL}
 		}
 		var writeRelabelConfigs []*config.RelabelConfig
 		for _, writeRelabelConfig := range remoteWrite.WriteRelabelConfigs {
@@ -19,6 +19,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/prometheus/common/model"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,7 +38,7 @@ func TestGenerateRemoteWrite(t *testing.T) {
 			},
 		},
 	}
-	remoteWriteConfig := generateRemoteWrite(&monitor)
+	remoteWriteConfig := generateRemoteWrite(&monitor, NewStore(nil, nil))
 	if remoteWriteConfig == nil || remoteWriteConfig[0] == nil {
 		t.Errorf("unexpected remoteWriteConfig %v", remoteWriteConfig)
 	}
@@ -46,6 +47,80 @@ func TestGenerateRemoteWrite(t *testing.T) {
 	}
 }
 
+func TestGenerateRemoteWriteWithAssets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	cmIndexer := deps.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	g.Expect(secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "remote-write", Namespace: "ns"},
+		Data: map[string][]byte{
+			"username": []byte("tenant"),
+			"password": []byte("passwd"),
+			"tls.crt":  []byte("cert"),
+			"tls.key":  []byte("key"),
+		},
+	})).To(Succeed())
+	g.Expect(cmIndexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "remote-write-ca", Namespace: "ns"},
+		Data:       map[string]string{"ca.crt": "ca"},
+	})).To(Succeed())
+
+	secretRef := func(key string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "remote-write"}, Key: key}
+	}
+	monitor := v1alpha1.TidbMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns"},
+		Spec: v1alpha1.TidbMonitorSpec{
+			Prometheus: v1alpha1.PrometheusSpec{
+				RemoteWrite: []*v1alpha1.RemoteWriteSpec{
+					{
+						URL:       "https://mimir/api/v1/push",
+						BasicAuth: &v1alpha1.BasicAuth{Username: *secretRef("username"), Password: *secretRef("password")},
+						TLSConfig: &v1alpha1.TLSConfig{SafeTLSConfig: v1alpha1.SafeTLSConfig{
+							CA:        v1alpha1.SecretOrConfigMap{ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "remote-write-ca"}, Key: "ca.crt"}},
+							Cert:      v1alpha1.SecretOrConfigMap{Secret: secretRef("tls.crt")},
+							KeySecret: secretRef("tls.key"),
+						}},
+						ProxyURL: pointer.StringPtr("http://proxy:3128"),
+					},
+				},
+			},
+		},
+	}
+
+	store := NewStore(deps.SecretLister, deps.UserConfigMapLister)
+	g.Expect(store.addRemoteWriteAssets(monitor.Namespace, monitor.Spec.Prometheus.RemoteWrite)).To(Succeed())
+	g.Expect(store.TLSAssets).To(HaveLen(4))
+	g.Expect(store.TLSAssets[TLSAssetKey{"configmap", "ns", "remote-write-ca", "ca.crt"}]).To(Equal(TLSAsset("ca")))
+	g.Expect(store.TLSAssets[TLSAssetKey{"secret", "ns", "remote-write", "password"}]).To(Equal(TLSAsset("passwd")))
+
+	remoteWriteConfigs := generateRemoteWrite(&monitor, store)
+	g.Expect(remoteWriteConfigs).To(HaveLen(1))
+	content, err := RenderPrometheusConfig(&MonitorConfigModel{RemoteWriteConfigs: remoteWriteConfigs})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).To(ContainSubstring(`remote_write:
+- url: https://mimir/api/v1/push
+  basic_auth:
+    username: tenant
+    password: null
+    password_file: /var/lib/cluster-assets-tls/secret_ns_remote-write_password
+  proxy_url: http://proxy:3128
+  tls_config:
+    ca_file: /var/lib/cluster-assets-tls/configmap_ns_remote-write-ca_ca.crt
+    cert_file: /var/lib/cluster-assets-tls/secret_ns_remote-write_tls.crt
+    key_file: /var/lib/cluster-assets-tls/secret_ns_remote-write_tls.key
+    insecure_skip_verify: false
+`))
+	// the password is not rendered inline
+	g.Expect(content).NotTo(ContainSubstring("passwd"))
+
+	// the missing key is an error
+	monitor.Spec.Prometheus.RemoteWrite[0].BasicAuth.Password.Key = "missing"
+	g.Expect(NewStore(deps.SecretLister, deps.UserConfigMapLister).addRemoteWriteAssets(monitor.Namespace, monitor.Spec.Prometheus.RemoteWrite)).NotTo(Succeed())
+}
+
 func TestGetMonitorConfigMap(t *testing.T) {
 	g := NewGomegaWithT(t)
 	varTrue := true
@@ -103,7 +178,7 @@ func TestGetMonitorConfigMap(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			cm, err := getPromConfigMap(&tt.monitor, tt.monitorClusterInfos, nil, 0)
+			cm, err := getPromConfigMap(&tt.monitor, NewStore(nil, nil), tt.monitorClusterInfos, nil, 0)
 			g.Expect(err).NotTo(HaveOccurred())
 			if tt.expected == nil {
 				g.Expect(cm).To(BeNil())
@@ -1432,3 +1507,55 @@ func TestBuildExternalLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestGetThanosQuery(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	monitor := &v1alpha1.TidbMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "ns",
+		},
+		Spec: v1alpha1.TidbMonitorSpec{
+			Replicas: pointer.Int32Ptr(2),
+			Shards:   pointer.Int32Ptr(2),
+			Thanos: &v1alpha1.ThanosSpec{
+				MonitorContainer: v1alpha1.MonitorContainer{
+					BaseImage: "thanosio/thanos",
+					Version:   "v0.17.2",
+				},
+				Query: &v1alpha1.ThanosQuerySpec{
+					Replicas: pointer.Int32Ptr(2),
+				},
+			},
+		},
+	}
+
+	deploy := getThanosQueryDeployment(monitor)
+	g.Expect(deploy.Name).To(Equal("foo-thanos-query"))
+	g.Expect(*deploy.Spec.Replicas).To(Equal(int32(2)))
+	g.Expect(deploy.Spec.Template.Spec.Containers[0].Image).To(Equal("thanosio/thanos:v0.17.2"))
+	g.Expect(deploy.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{
+		"query",
+		"--http-address=0.0.0.0:10902",
+		"--grpc-address=0.0.0.0:10901",
+		"--query.replica-label=prometheus_replica",
+		"--store=dnssrv+_thanos-grpc._tcp.foo-thanos-sidecar.ns.svc",
+		"--store=dnssrv+_thanos-grpc._tcp.foo-thanos-sidecar-shard-1.ns.svc",
+	}))
+
+	svcNames := []string{}
+	for _, svc := range getMonitorService(monitor) {
+		svcNames = append(svcNames, svc.Name)
+		if svc.Name == "foo-thanos-sidecar-shard-1" {
+			g.Expect(svc.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+			g.Expect(svc.Spec.Selector).To(HaveKeyWithValue("app.kubernetes.io/instance", "foo-shard-1"))
+		}
+	}
+	g.Expect(svcNames).To(ContainElements("foo-thanos-sidecar", "foo-thanos-sidecar-shard-1", "foo-thanos-query"))
+
+	monitor.Spec.Thanos.Query = nil
+	for _, svc := range getMonitorService(monitor) {
+		g.Expect(svc.Name).NotTo(ContainSubstring("thanos"))
+	}
+}
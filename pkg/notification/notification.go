@@ -0,0 +1,161 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultSendTimeout = 10 * time.Second
+)
+
+var severityOrder = map[v1alpha1.NotificationSeverity]int{
+	v1alpha1.NotificationSeverityInfo:     0,
+	v1alpha1.NotificationSeverityWarning:  1,
+	v1alpha1.NotificationSeverityCritical: 2,
+}
+
+// Notification is a structured message of a significant transition of a resource
+type Notification struct {
+	Type     v1alpha1.NotificationEventType `json:"type"`
+	Severity v1alpha1.NotificationSeverity  `json:"severity"`
+	// Kind is the kind of the resource, e.g. TidbCluster and Backup
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Component is the component the transition happens on, e.g. tikv
+	Component string    `json:"component,omitempty"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+}
+
+// Summary returns a one-line description of the notification
+func (n *Notification) Summary() string {
+	subject := fmt.Sprintf("%s %s/%s", n.Kind, n.Namespace, n.Name)
+	if n.Component != "" {
+		subject += " " + n.Component
+	}
+	return fmt.Sprintf("[%s] %s %s: %s", n.Severity, subject, n.Type, n.Message)
+}
+
+// key identifies the notifications of the same transition, the sinks may use it to group the alerts
+func (n *Notification) key() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s/%s", n.Kind, n.Namespace, n.Name, n.Component, n.Type, n.Message)
+}
+
+// Interface sends the notifications to the sinks
+type Interface interface {
+	// Notify sends the notification to the sinks in spec that accept it, the notification is sent
+	// asynchronously and the failures are only logged. The callers notify only on the transitions
+	// between the old and the new status, the notifications are not deduplicated.
+	Notify(spec *v1alpha1.NotificationSpec, n Notification)
+}
+
+type notifier struct {
+	secretLister corelisterv1.SecretLister
+	httpClient   *http.Client
+}
+
+// NewNotifier returns a notifier which reads the credentials of the sinks from the Secrets
+func NewNotifier(secretLister corelisterv1.SecretLister) Interface {
+	return &notifier{
+		secretLister: secretLister,
+		httpClient:   &http.Client{Timeout: defaultSendTimeout},
+	}
+}
+
+func (m *notifier) Notify(spec *v1alpha1.NotificationSpec, n Notification) {
+	if spec == nil || len(spec.Sinks) == 0 {
+		return
+	}
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+	for _, sinkSpec := range spec.Sinks {
+		if !accepts(&sinkSpec, &n) {
+			continue
+		}
+		sinkSpec := sinkSpec
+		go func() {
+			s, err := m.newSink(&sinkSpec, n.Namespace)
+			if err == nil {
+				err = s.send(&n)
+			}
+			if err != nil {
+				klog.Errorf("failed to send notification %q to sink %s of %s %s/%s, error: %v", n.Summary(), sinkSpec.Name, n.Kind, n.Namespace, n.Name, err)
+				return
+			}
+			klog.Infof("notification %q is sent to sink %s of %s %s/%s", n.Summary(), sinkSpec.Name, n.Kind, n.Namespace, n.Name)
+		}()
+	}
+}
+
+// accepts returns whether the notification matches the severity and the event filters of the sink
+func accepts(sink *v1alpha1.NotificationSink, n *Notification) bool {
+	if sink.MinSeverity != "" && severityOrder[n.Severity] < severityOrder[sink.MinSeverity] {
+		return false
+	}
+	if len(sink.Events) == 0 {
+		return true
+	}
+	for _, t := range sink.Events {
+		if t == n.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// FakeNotifier records the notifications accepted by the sinks
+type FakeNotifier struct {
+	mu            sync.Mutex
+	notifications []Notification
+}
+
+// NewFakeNotifier returns a FakeNotifier
+func NewFakeNotifier() *FakeNotifier {
+	return &FakeNotifier{}
+}
+
+func (f *FakeNotifier) Notify(spec *v1alpha1.NotificationSpec, n Notification) {
+	if spec == nil {
+		return
+	}
+	for _, sink := range spec.Sinks {
+		if accepts(&sink, &n) {
+			f.mu.Lock()
+			f.notifications = append(f.notifications, n)
+			f.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Notifications returns the recorded notifications
+func (f *FakeNotifier) Notifications() []Notification {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Notification(nil), f.notifications...)
+}
+
+var _ Interface = &notifier{}
+var _ Interface = &FakeNotifier{}
@@ -0,0 +1,130 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAccepts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	n := &Notification{Type: v1alpha1.NotificationEventFailoverTriggered, Severity: v1alpha1.NotificationSeverityWarning}
+	g.Expect(accepts(&v1alpha1.NotificationSink{}, n)).Should(BeTrue())
+	g.Expect(accepts(&v1alpha1.NotificationSink{MinSeverity: v1alpha1.NotificationSeverityWarning}, n)).Should(BeTrue())
+	g.Expect(accepts(&v1alpha1.NotificationSink{MinSeverity: v1alpha1.NotificationSeverityCritical}, n)).Should(BeFalse())
+	g.Expect(accepts(&v1alpha1.NotificationSink{Events: []v1alpha1.NotificationEventType{v1alpha1.NotificationEventFailoverTriggered}}, n)).Should(BeTrue())
+	g.Expect(accepts(&v1alpha1.NotificationSink{Events: []v1alpha1.NotificationEventType{v1alpha1.NotificationEventBackupFailed}}, n)).Should(BeFalse())
+}
+
+func TestNotify(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	received := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		v := map[string]interface{}{}
+		json.Unmarshal(body, &v)
+		v["path"] = r.URL.Path
+		received <- v
+	}))
+	defer server.Close()
+
+	kubeCli := kubefake.NewSimpleClientset()
+	informerFactory := kubeinformers.NewSharedInformerFactory(kubeCli, 0)
+	informerFactory.Core().V1().Secrets().Informer().GetIndexer().Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "notification"},
+		Data: map[string][]byte{
+			"slack":     []byte(server.URL + "/slack\n"),
+			"pagerduty": []byte("routing-key"),
+		},
+	})
+	m := NewNotifier(informerFactory.Core().V1().Secrets().Lister())
+
+	spec := &v1alpha1.NotificationSpec{
+		Sinks: []v1alpha1.NotificationSink{
+			{
+				Name:    "webhook",
+				Webhook: &v1alpha1.WebhookNotificationSink{URL: server.URL + "/webhook"},
+			},
+			{
+				Name: "slack",
+				Slack: &v1alpha1.SlackNotificationSink{
+					WebhookURLSecretRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "notification"}, Key: "slack"},
+				},
+			},
+			{
+				Name:        "pagerduty",
+				MinSeverity: v1alpha1.NotificationSeverityCritical,
+				PagerDuty: &v1alpha1.PagerDutyNotificationSink{
+					RoutingKeySecretRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "notification"}, Key: "pagerduty"},
+					URL:                 server.URL + "/pagerduty",
+				},
+			},
+		},
+	}
+	n := Notification{
+		Type:      v1alpha1.NotificationEventBackupFailed,
+		Severity:  v1alpha1.NotificationSeverityCritical,
+		Kind:      "Backup",
+		Namespace: "ns",
+		Name:      "backup",
+		Message:   "pod backup-xxx has failed",
+	}
+	m.Notify(spec, n)
+
+	results := map[string]map[string]interface{}{}
+	for i := 0; i < 3; i++ {
+		select {
+		case v := <-received:
+			results[v["path"].(string)] = v
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the notifications")
+		}
+	}
+	g.Expect(results["/webhook"]["type"]).Should(Equal(string(v1alpha1.NotificationEventBackupFailed)))
+	g.Expect(results["/webhook"]["name"]).Should(Equal("backup"))
+	g.Expect(results["/slack"]["text"]).Should(Equal("[Critical] Backup ns/backup BackupFailed: pod backup-xxx has failed"))
+	g.Expect(results["/pagerduty"]["routing_key"]).Should(Equal("routing-key"))
+	g.Expect(results["/pagerduty"]["event_action"]).Should(Equal("trigger"))
+	g.Expect(results["/pagerduty"]["payload"].(map[string]interface{})["severity"]).Should(Equal("critical"))
+
+	// the notification is filtered out by the pagerduty sink
+	n.Severity = v1alpha1.NotificationSeverityWarning
+	n.Message = "another message"
+	m.Notify(spec, n)
+	// the same notification is sent again, the callers notify only on the transitions
+	m.Notify(spec, n)
+	for i := 0; i < 4; i++ {
+		select {
+		case v := <-received:
+			g.Expect(v["path"]).ShouldNot(Equal("/pagerduty"))
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the notifications")
+		}
+	}
+	g.Consistently(received, time.Second).ShouldNot(Receive())
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const defaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+type sink interface {
+	send(n *Notification) error
+}
+
+func (m *notifier) newSink(spec *v1alpha1.NotificationSink, namespace string) (sink, error) {
+	switch {
+	case spec.Webhook != nil:
+		return &webhookSink{httpClient: m.httpClient, url: spec.Webhook.URL}, nil
+	case spec.Slack != nil:
+		url, err := m.getSecretValue(namespace, &spec.Slack.WebhookURLSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		return &slackSink{httpClient: m.httpClient, url: url}, nil
+	case spec.PagerDuty != nil:
+		routingKey, err := m.getSecretValue(namespace, &spec.PagerDuty.RoutingKeySecretRef)
+		if err != nil {
+			return nil, err
+		}
+		url := spec.PagerDuty.URL
+		if url == "" {
+			url = defaultPagerDutyURL
+		}
+		return &pagerDutySink{httpClient: m.httpClient, url: url, routingKey: routingKey}, nil
+	}
+	return nil, fmt.Errorf("no destination is set in sink %s", spec.Name)
+}
+
+func (m *notifier) getSecretValue(namespace string, selector *corev1.SecretKeySelector) (string, error) {
+	secret, err := m.secretLister.Secrets(namespace).Get(selector.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s/%s, error: %v", namespace, selector.Name, err)
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s/%s", selector.Key, namespace, selector.Name)
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// webhookSink posts the notification as is
+type webhookSink struct {
+	httpClient *http.Client
+	url        string
+}
+
+func (s *webhookSink) send(n *Notification) error {
+	return postJSON(s.httpClient, s.url, n)
+}
+
+// slackSink posts the summary of the notification to the incoming webhook of Slack
+type slackSink struct {
+	httpClient *http.Client
+	url        string
+}
+
+func (s *slackSink) send(n *Notification) error {
+	return postJSON(s.httpClient, s.url, map[string]string{"text": n.Summary()})
+}
+
+// pagerDutySink triggers an alert by the Events API v2 of PagerDuty, see
+// https://developer.pagerduty.com/docs/events-api-v2/trigger-events/
+type pagerDutySink struct {
+	httpClient *http.Client
+	url        string
+	routingKey string
+}
+
+func (s *pagerDutySink) send(n *Notification) error {
+	return postJSON(s.httpClient, s.url, map[string]interface{}{
+		"routing_key":  s.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    n.key(),
+		"payload": map[string]interface{}{
+			"summary":        n.Summary(),
+			"source":         fmt.Sprintf("%s/%s", n.Namespace, n.Name),
+			"severity":       strings.ToLower(string(n.Severity)),
+			"timestamp":      n.Time.Format(time.RFC3339),
+			"component":      n.Component,
+			"class":          string(n.Type),
+			"custom_details": n,
+		},
+	})
+}
+
+func postJSON(httpClient *http.Client, url string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode >= 300 {
+		resBody, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("error response %v, body response: %s", res.StatusCode, string(resBody))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package overview
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// APIPath is the path of the JSON overview
+	APIPath = "/api/v1/overview"
+	// UIPath is the path of the HTML overview
+	UIPath = "/overview"
+)
+
+var uiTemplate = template.Must(template.New("overview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="30">
+<title>TiDB Operator Overview</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.ready { color: green; }
+.not-ready { color: red; }
+.warning { color: #c60; }
+</style>
+</head>
+<body>
+<p>Generated at {{ .GeneratedAt.UTC.Format "2006-01-02T15:04:05Z07:00" }}</p>
+{{ template "clusters" .TidbClusters }}
+{{ template "clusters" .DMClusters }}
+</body>
+</html>
+{{ define "clusters" }}
+<table>
+<tr><th>Kind</th><th>Namespace</th><th>Name</th><th>Version</th><th>Ready</th><th>Components</th><th>Pending Operations</th><th>Recent Events</th></tr>
+{{- range . }}
+<tr>
+<td>{{ .Kind }}</td>
+<td>{{ .Namespace }}</td>
+<td>{{ .Name }}</td>
+<td>{{ .Version }}</td>
+<td>{{ if .Ready }}<span class="ready">Ready</span>{{ else }}<span class="not-ready">NotReady</span>{{ end }}{{ if .Paused }} (Paused){{ end }}<br>{{ .Message }}</td>
+<td>{{ range .Components }}{{ .Name }}: {{ .Phase }} {{ .ReadyReplicas }}/{{ .Replicas }} {{ .Image }}<br>{{ end }}</td>
+<td>{{ range .PendingOperations }}{{ . }}<br>{{ end }}</td>
+<td>{{ range .Events }}<span{{ if eq .Type "Warning" }} class="warning"{{ end }}>{{ .Reason }}: {{ .Message }}</span><br>{{ end }}</td>
+</tr>
+{{- end }}
+</table>
+{{ end }}
+`))
+
+// Handler serves the read-only overview of the clusters managed by the operator
+type Handler struct {
+	summarizer *summarizer
+	// synced returns whether the caches of the listers have been synced, the caches are only
+	// started by the leader of the controller managers
+	synced func() bool
+}
+
+// NewHandler returns a Handler which reads the clusters from the listers in deps, the EventLister in deps is
+// only set when the overview is enabled
+func NewHandler(deps *controller.Dependencies) *Handler {
+	tcInformer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer()
+	dcInformer := deps.InformerFactory.Pingcap().V1alpha1().DMClusters().Informer()
+	eventInformer := deps.KubeInformerFactory.Core().V1().Events().Informer()
+	return &Handler{
+		summarizer: &summarizer{deps: deps},
+		synced: func() bool {
+			return tcInformer.HasSynced() && dcInformer.HasSynced() && eventInformer.HasSynced()
+		},
+	}
+}
+
+// Register registers the JSON API and the HTML UI to mux
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc(APIPath, h.serveJSON)
+	mux.HandleFunc(UIPath, h.serveHTML)
+}
+
+func (h *Handler) getOverview(w http.ResponseWriter, r *http.Request) *Overview {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil
+	}
+	if !h.synced() {
+		http.Error(w, "the cache is not synced, this instance may not be the leader", http.StatusServiceUnavailable)
+		return nil
+	}
+	o, err := h.summarizer.overview()
+	if err != nil {
+		klog.Errorf("failed to get the overview of the clusters, error: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	return o
+}
+
+func (h *Handler) serveJSON(w http.ResponseWriter, r *http.Request) {
+	o := h.getOverview(w, r)
+	if o == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(o); err != nil {
+		klog.Errorf("failed to write the overview of the clusters, error: %v", err)
+	}
+}
+
+func (h *Handler) serveHTML(w http.ResponseWriter, r *http.Request) {
+	o := h.getOverview(w, r)
+	if o == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := uiTemplate.Execute(w, o); err != nil {
+		klog.Errorf("failed to render the overview of the clusters, error: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package overview
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTidbCluster() *v1alpha1.TidbCluster {
+	return &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "tc"},
+		Spec: v1alpha1.TidbClusterSpec{
+			Version: "v5.2.1",
+			PD:      &v1alpha1.PDSpec{Replicas: 3, BaseImage: "pingcap/pd"},
+			TiKV:    &v1alpha1.TiKVSpec{Replicas: 3, BaseImage: "pingcap/tikv"},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			PD: v1alpha1.PDStatus{
+				Phase:       v1alpha1.NormalPhase,
+				StatefulSet: &apps.StatefulSetStatus{ReadyReplicas: 3},
+			},
+			TiKV: v1alpha1.TiKVStatus{
+				Phase:       v1alpha1.UpgradePhase,
+				StatefulSet: &apps.StatefulSetStatus{ReadyReplicas: 2},
+			},
+			Conditions: []v1alpha1.TidbClusterCondition{
+				{Type: v1alpha1.TidbClusterReady, Status: corev1.ConditionFalse, Message: "TiKV store(s) are not up"},
+			},
+			InFlightOperations: []v1alpha1.InFlightOperation{
+				{Type: v1alpha1.InFlightOperationUpgrade, Component: v1alpha1.TiKVMemberType, PodName: "tc-tikv-2"},
+			},
+		},
+	}
+}
+
+func newEvent(name, reason string, lastTimestamp time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: name},
+		InvolvedObject: corev1.ObjectReference{Kind: v1alpha1.TiDBClusterKind, Namespace: corev1.NamespaceDefault, Name: "tc"},
+		Type:           corev1.EventTypeNormal,
+		Reason:         reason,
+		LastTimestamp:  metav1.Time{Time: lastTimestamp},
+	}
+}
+
+func TestHandler(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	// the events are only cached when the overview is enabled
+	deps.EventLister = deps.KubeInformerFactory.Core().V1().Events().Lister()
+	h := NewHandler(deps)
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	// the cache is not synced
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPath, nil))
+	g.Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+
+	h.synced = func() bool { return true }
+	tc := newTidbCluster()
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+	now := time.Now()
+	for i := 0; i < maxEventsPerCluster+2; i++ {
+		e := newEvent(fmt.Sprintf("tc.%d", i), fmt.Sprintf("Reason%d", i), now.Add(time.Duration(i)*time.Minute))
+		g.Expect(deps.KubeInformerFactory.Core().V1().Events().Informer().GetIndexer().Add(e)).To(Succeed())
+	}
+	other := newEvent("other", "Other", now)
+	other.InvolvedObject.Name = "other"
+	g.Expect(deps.KubeInformerFactory.Core().V1().Events().Informer().GetIndexer().Add(other)).To(Succeed())
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPath, nil))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	o := &Overview{}
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), o)).To(Succeed())
+	g.Expect(o.DMClusters).To(BeEmpty())
+	g.Expect(o.TidbClusters).To(HaveLen(1))
+	summary := o.TidbClusters[0]
+	g.Expect(summary.Name).To(Equal("tc"))
+	g.Expect(summary.Version).To(Equal("v5.2.1"))
+	g.Expect(summary.Ready).To(BeFalse())
+	g.Expect(summary.Message).To(Equal("TiKV store(s) are not up"))
+	g.Expect(summary.Components).To(Equal([]ComponentSummary{
+		{Name: "pd", Phase: v1alpha1.NormalPhase, Image: "pingcap/pd:v5.2.1", Replicas: 3, ReadyReplicas: 3},
+		{Name: "tikv", Phase: v1alpha1.UpgradePhase, Image: "pingcap/tikv:v5.2.1", Replicas: 3, ReadyReplicas: 2},
+	}))
+	g.Expect(summary.PendingOperations).To(HaveLen(2))
+	g.Expect(summary.PendingOperations[0]).To(Equal("tikv Upgrade"))
+	g.Expect(summary.PendingOperations[1]).To(HavePrefix("Upgrade tikv pod tc-tikv-2"))
+	g.Expect(summary.Events).To(HaveLen(maxEventsPerCluster))
+	g.Expect(summary.Events[0].Reason).To(Equal(fmt.Sprintf("Reason%d", maxEventsPerCluster+1)))
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, UIPath, nil))
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	g.Expect(rec.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+	g.Expect(rec.Body.String()).To(ContainSubstring("tikv: Upgrade 2/3 pingcap/tikv:v5.2.1"))
+
+	// the API is read-only
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, APIPath, nil))
+	g.Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+}
@@ -0,0 +1,255 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package overview
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	dmclusterutil "github.com/pingcap/tidb-operator/pkg/util/dmcluster"
+	tidbclusterutil "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// maxEventsPerCluster is the number of the most recent events listed in the summary of a cluster
+const maxEventsPerCluster = 5
+
+// Overview is the summary of all the clusters managed by the operator
+type Overview struct {
+	GeneratedAt  time.Time        `json:"generatedAt"`
+	TidbClusters []ClusterSummary `json:"tidbClusters"`
+	DMClusters   []ClusterSummary `json:"dmClusters"`
+}
+
+// ClusterSummary is the summary of a TidbCluster or a DMCluster
+type ClusterSummary struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ready     bool   `json:"ready"`
+	Paused    bool   `json:"paused,omitempty"`
+	// Message is the message of the Ready condition
+	Message    string             `json:"message,omitempty"`
+	Components []ComponentSummary `json:"components"`
+	// PendingOperations are the operations in progress, e.g. upgrading, scaling and the in-flight operations
+	// recorded in the status
+	PendingOperations []string       `json:"pendingOperations,omitempty"`
+	Events            []EventSummary `json:"events,omitempty"`
+}
+
+// ComponentSummary is the summary of a component of a cluster
+type ComponentSummary struct {
+	Name          string               `json:"name"`
+	Phase         v1alpha1.MemberPhase `json:"phase"`
+	Image         string               `json:"image"`
+	Replicas      int32                `json:"replicas"`
+	ReadyReplicas int32                `json:"readyReplicas"`
+}
+
+// EventSummary is the summary of an event of a cluster
+type EventSummary struct {
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+// summarizer builds the overview from the listers of the controllers
+type summarizer struct {
+	deps *controller.Dependencies
+}
+
+func (s *summarizer) overview() (*Overview, error) {
+	tcs, err := s.deps.TiDBClusterLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list TidbClusters: %v", err)
+	}
+	dcs, err := s.deps.DMClusterLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DMClusters: %v", err)
+	}
+
+	o := &Overview{
+		GeneratedAt:  time.Now(),
+		TidbClusters: make([]ClusterSummary, 0, len(tcs)),
+		DMClusters:   make([]ClusterSummary, 0, len(dcs)),
+	}
+	// the events are read from the shared informer, and listed once for each namespace
+	events := map[string][]*corev1.Event{}
+	listEvents := func(namespace string) []*corev1.Event {
+		if es, ok := events[namespace]; ok {
+			return es
+		}
+		es, err := s.deps.EventLister.Events(namespace).List(labels.Everything())
+		if err != nil {
+			klog.Warningf("failed to list events in namespace %s, error: %v", namespace, err)
+		}
+		events[namespace] = es
+		return es
+	}
+	for _, tc := range tcs {
+		summary := summarizeTidbCluster(tc)
+		summary.Events = recentEvents(listEvents(tc.Namespace), v1alpha1.TiDBClusterKind, tc.Name)
+		o.TidbClusters = append(o.TidbClusters, summary)
+	}
+	for _, dc := range dcs {
+		summary := summarizeDMCluster(dc)
+		summary.Events = recentEvents(listEvents(dc.Namespace), v1alpha1.DMClusterKind, dc.Name)
+		o.DMClusters = append(o.DMClusters, summary)
+	}
+	sortSummaries(o.TidbClusters)
+	sortSummaries(o.DMClusters)
+	return o, nil
+}
+
+// recentEvents returns the most recent events of the object among the events of its namespace
+func recentEvents(events []*corev1.Event, kind, name string) []EventSummary {
+	var summaries []EventSummary
+	for _, e := range events {
+		if e.InvolvedObject.Kind != kind || e.InvolvedObject.Name != name {
+			continue
+		}
+		summaries = append(summaries, EventSummary{
+			Type:          e.Type,
+			Reason:        e.Reason,
+			Message:       e.Message,
+			Count:         e.Count,
+			LastTimestamp: eventTime(e),
+		})
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].LastTimestamp.After(summaries[j].LastTimestamp)
+	})
+	if len(summaries) > maxEventsPerCluster {
+		summaries = summaries[:maxEventsPerCluster]
+	}
+	return summaries
+}
+
+func eventTime(e *corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+func summarizeTidbCluster(tc *v1alpha1.TidbCluster) ClusterSummary {
+	summary := ClusterSummary{
+		Kind:      v1alpha1.TiDBClusterKind,
+		Namespace: tc.Namespace,
+		Name:      tc.Name,
+		Version:   tc.Spec.Version,
+		Paused:    tc.Spec.Paused,
+	}
+	if cond := tidbclusterutil.GetTidbClusterReadyCondition(tc.Status); cond != nil {
+		summary.Ready = cond.Status == corev1.ConditionTrue
+		summary.Message = cond.Message
+	}
+
+	if tc.Spec.PD != nil {
+		summary.Components = append(summary.Components, newComponentSummary(label.PDLabelVal, tc.Status.PD.Phase, tc.PDImage(), tc.Spec.PD.Replicas, tc.Status.PD.StatefulSet))
+	}
+	if tc.Spec.TiKV != nil {
+		summary.Components = append(summary.Components, newComponentSummary(label.TiKVLabelVal, tc.Status.TiKV.Phase, tc.TiKVImage(), tc.Spec.TiKV.Replicas, tc.Status.TiKV.StatefulSet))
+	}
+	if tc.Spec.TiFlash != nil {
+		summary.Components = append(summary.Components, newComponentSummary(label.TiFlashLabelVal, tc.Status.TiFlash.Phase, tc.TiFlashImage(), tc.Spec.TiFlash.Replicas, tc.Status.TiFlash.StatefulSet))
+	}
+	if tc.Spec.TiDB != nil {
+		summary.Components = append(summary.Components, newComponentSummary(label.TiDBLabelVal, tc.Status.TiDB.Phase, tc.TiDBImage(), tc.Spec.TiDB.Replicas, tc.Status.TiDB.StatefulSet))
+	}
+	if tc.Spec.Pump != nil {
+		image := ""
+		if img := tc.PumpImage(); img != nil {
+			image = *img
+		}
+		summary.Components = append(summary.Components, newComponentSummary(label.PumpLabelVal, tc.Status.Pump.Phase, image, tc.Spec.Pump.Replicas, tc.Status.Pump.StatefulSet))
+	}
+	if tc.Spec.TiCDC != nil {
+		summary.Components = append(summary.Components, newComponentSummary(label.TiCDCLabelVal, tc.Status.TiCDC.Phase, tc.TiCDCImage(), tc.Spec.TiCDC.Replicas, tc.Status.TiCDC.StatefulSet))
+	}
+	summary.PendingOperations = pendingOperations(summary.Components)
+
+	for _, op := range tc.Status.InFlightOperations {
+		summary.PendingOperations = append(summary.PendingOperations,
+			fmt.Sprintf("%s %s pod %s since %s", op.Type, op.Component, op.PodName, op.StartTime.UTC().Format(time.RFC3339)))
+	}
+	return summary
+}
+
+func summarizeDMCluster(dc *v1alpha1.DMCluster) ClusterSummary {
+	summary := ClusterSummary{
+		Kind:      v1alpha1.DMClusterKind,
+		Namespace: dc.Namespace,
+		Name:      dc.Name,
+		Version:   dc.Spec.Version,
+		Paused:    dc.Spec.Paused,
+	}
+	if cond := dmclusterutil.GetDMClusterReadyCondition(dc.Status); cond != nil {
+		summary.Ready = cond.Status == corev1.ConditionTrue
+		summary.Message = cond.Message
+	}
+
+	summary.Components = append(summary.Components, newComponentSummary(label.DMMasterLabelVal, dc.Status.Master.Phase, dc.MasterImage(), dc.Spec.Master.Replicas, dc.Status.Master.StatefulSet))
+	if dc.Spec.Worker != nil {
+		summary.Components = append(summary.Components, newComponentSummary(label.DMWorkerLabelVal, dc.Status.Worker.Phase, dc.WorkerImage(), dc.Spec.Worker.Replicas, dc.Status.Worker.StatefulSet))
+	}
+	summary.PendingOperations = pendingOperations(summary.Components)
+	return summary
+}
+
+func newComponentSummary(name string, phase v1alpha1.MemberPhase, image string, replicas int32, sts *apps.StatefulSetStatus) ComponentSummary {
+	c := ComponentSummary{
+		Name:     name,
+		Phase:    phase,
+		Image:    image,
+		Replicas: replicas,
+	}
+	if sts != nil {
+		c.ReadyReplicas = sts.ReadyReplicas
+	}
+	return c
+}
+
+// pendingOperations returns the components that are not in the Normal phase, e.g. upgrading or scaling
+func pendingOperations(components []ComponentSummary) []string {
+	var ops []string
+	for _, c := range components {
+		if c.Phase != "" && c.Phase != v1alpha1.NormalPhase {
+			ops = append(ops, fmt.Sprintf("%s %s", c.Name, c.Phase))
+		}
+	}
+	return ops
+}
+
+func sortSummaries(summaries []ClusterSummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+}
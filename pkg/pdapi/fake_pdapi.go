@@ -15,6 +15,7 @@ package pdapi
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -42,6 +43,18 @@ const (
 	GetPDLeaderActionType              ActionType = "GetPDLeader"
 	TransferPDLeaderActionType         ActionType = "TransferPDLeader"
 	GetAutoscalingPlansActionType      ActionType = "GetAutoscalingPlans"
+	SetStoreWeightActionType           ActionType = "SetStoreWeight"
+	GetStoresLimitActionType           ActionType = "GetStoresLimit"
+	SetStoreLimitActionType            ActionType = "SetStoreLimit"
+	GetScheduleConfigActionType        ActionType = "GetScheduleConfig"
+	UpdateScheduleConfigActionType     ActionType = "UpdateScheduleConfig"
+	GetPlacementRuleActionType         ActionType = "GetPlacementRule"
+	SetPlacementRuleActionType         ActionType = "SetPlacementRule"
+	GetTimeActionType                  ActionType = "GetTime"
+	GetRegionsCheckActionType          ActionType = "GetRegionsCheck"
+	SetMemberLeaderPriorityActionType  ActionType = "SetMemberLeaderPriority"
+	RemoveTombstoneStoresActionType    ActionType = "RemoveTombstoneStores"
+	GetMinResolvedTSActionType         ActionType = "GetMinResolvedTS"
 )
 
 type NotFoundReaction struct {
@@ -57,6 +70,19 @@ type Action struct {
 	Name        string
 	Labels      map[string]string
 	Replication PDReplicationConfig
+	// Weights are the leader weight and the region weight of SetStoreWeight
+	Weights [2]float64
+	// LimitType and Rate are the arguments of SetStoreLimit
+	LimitType StoreLimitType
+	Rate      float64
+	// Config is the items of UpdateScheduleConfig
+	Config map[string]interface{}
+	// Rule is the placement rule of SetPlacementRule
+	Rule *PlacementRule
+	// RegionCheck is the check of GetRegionsCheck
+	RegionCheck RegionCheck
+	// LeaderPriority is the priority of SetMemberLeaderPriority
+	LeaderPriority int32
 }
 
 type Reaction func(action *Action) (interface{}, error)
@@ -252,6 +278,15 @@ func (c *FakePDClient) TransferPDLeader(memberName string) error {
 	return nil
 }
 
+func (c *FakePDClient) SetMemberLeaderPriority(name string, priority int32) error {
+	if reaction, ok := c.reactions[SetMemberLeaderPriorityActionType]; ok {
+		action := &Action{Name: name, LeaderPriority: priority}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
 func (c *FakePDClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	if reaction, ok := c.reactions[GetAutoscalingPlansActionType]; ok {
 		action := &Action{}
@@ -260,3 +295,108 @@ func (c *FakePDClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	}
 	return nil, nil
 }
+
+func (c *FakePDClient) SetStoreWeight(storeID uint64, leaderWeight, regionWeight float64) error {
+	if reaction, ok := c.reactions[SetStoreWeightActionType]; ok {
+		action := &Action{ID: storeID, Weights: [2]float64{leaderWeight, regionWeight}}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetStoresLimit() (map[string]StoreLimit, error) {
+	if reaction, ok := c.reactions[GetStoresLimitActionType]; ok {
+		action := &Action{}
+		result, err := reaction(action)
+		return result.(map[string]StoreLimit), err
+	}
+	return nil, nil
+}
+
+func (c *FakePDClient) SetStoreLimit(storeID uint64, limitType StoreLimitType, rate float64) error {
+	if reaction, ok := c.reactions[SetStoreLimitActionType]; ok {
+		action := &Action{ID: storeID, LimitType: limitType, Rate: rate}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetScheduleConfig() (map[string]interface{}, error) {
+	if reaction, ok := c.reactions[GetScheduleConfigActionType]; ok {
+		action := &Action{}
+		result, err := reaction(action)
+		if err != nil {
+			return nil, err
+		}
+		return result.(map[string]interface{}), nil
+	}
+	return nil, nil
+}
+
+func (c *FakePDClient) UpdateScheduleConfig(items map[string]interface{}) error {
+	if reaction, ok := c.reactions[UpdateScheduleConfigActionType]; ok {
+		action := &Action{Config: items}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetPlacementRule(groupID, ruleID string) (*PlacementRule, error) {
+	action := &Action{Name: fmt.Sprintf("%s/%s", groupID, ruleID)}
+	result, err := c.fakeAPI(GetPlacementRuleActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PlacementRule), nil
+}
+
+func (c *FakePDClient) SetPlacementRule(rule *PlacementRule) error {
+	if reaction, ok := c.reactions[SetPlacementRuleActionType]; ok {
+		action := &Action{Rule: rule}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetTime() (time.Time, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetTimeActionType, action)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return result.(time.Time), nil
+}
+
+func (c *FakePDClient) GetRegionsCheck(check RegionCheck) (*RegionsInfo, error) {
+	if reaction, ok := c.reactions[GetRegionsCheckActionType]; ok {
+		action := &Action{RegionCheck: check}
+		result, err := reaction(action)
+		if err != nil {
+			return nil, err
+		}
+		return result.(*RegionsInfo), nil
+	}
+	return &RegionsInfo{}, nil
+}
+
+func (c *FakePDClient) RemoveTombstoneStores() error {
+	if reaction, ok := c.reactions[RemoveTombstoneStoresActionType]; ok {
+		action := &Action{}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetMinResolvedTS() (*MinResolvedTS, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetMinResolvedTSActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*MinResolvedTS), nil
+}
@@ -25,6 +25,9 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// defaultClientPort is the default port of the PD client requests
+const defaultClientPort = 2379
+
 // Namespace is a newtype of a string
 type Namespace string
 
@@ -38,6 +41,13 @@ func ClusterRef(clusterDomain string) Option {
 	}
 }
 
+// ClientPort sets the client port of PD, it is used when generating the PD address from TC.
+func ClientPort(port int32) Option {
+	return func(c *clientConfig) {
+		c.clientPort = port
+	}
+}
+
 // TLSCertFromTC indicates that the clients use certs from specified TC's secret.
 func TLSCertFromTC(ns Namespace, tcName string) Option {
 	return func(c *clientConfig) {
@@ -67,13 +77,15 @@ type PDControlInterface interface {
 	// GetPDClient provides PDClient of the tidb cluster.
 	GetPDClient(namespace Namespace, tcName string, tlsEnabled bool, opts ...Option) PDClient
 	// GetPDEtcdClient provides PD etcd Client of the tidb cluster.
-	GetPDEtcdClient(namespace Namespace, tcName string, tlsEnabled bool) (PDEtcdClient, error)
+	GetPDEtcdClient(namespace Namespace, tcName string, tlsEnabled bool, opts ...Option) (PDEtcdClient, error)
 	// GetEndpoints return the endpoints and client tls.Config to connection pd/etcd.
-	GetEndpoints(namespace Namespace, tcName string, tlsEnabled bool) (endpoints []string, tlsConfig *tls.Config, err error)
+	GetEndpoints(namespace Namespace, tcName string, tlsEnabled bool, opts ...Option) (endpoints []string, tlsConfig *tls.Config, err error)
 }
 
 type clientConfig struct {
 	clusterDomain string
+	// clientPort is the client port of PD. If it is 0, the default port is used
+	clientPort int32
 
 	// clientURL is PD addr. If it is empty, will generate from target TC
 	clientURL string
@@ -102,8 +114,11 @@ func (c *clientConfig) complete(namespace Namespace, tcName string) {
 		}
 	}
 
+	if c.clientPort == 0 {
+		c.clientPort = defaultClientPort
+	}
 	if c.clientURL == "" {
-		c.clientURL = genClientUrl(namespace, tcName, scheme, c.clusterDomain)
+		c.clientURL = genClientUrl(namespace, tcName, scheme, c.clusterDomain, c.clientPort)
 	}
 	if c.clientKey == "" {
 		c.clientKey = genClientKey(scheme, namespace, tcName, c.clusterDomain)
@@ -139,7 +154,7 @@ func NewDefaultPDControlByCli(kubeCli kubernetes.Interface) PDControlInterface {
 	return &defaultPDControl{pdClients: map[string]PDClient{}, pdEtcdClients: map[string]PDEtcdClient{}}
 }
 
-func (c *defaultPDControl) GetEndpoints(namespace Namespace, tcName string, tlsEnabled bool) (endpoints []string, tlsConfig *tls.Config, err error) {
+func (c *defaultPDControl) GetEndpoints(namespace Namespace, tcName string, tlsEnabled bool, opts ...Option) (endpoints []string, tlsConfig *tls.Config, err error) {
 	if tlsEnabled {
 		tlsConfig, err = GetTLSConfig(c.secretLister, namespace, util.ClusterClientTLSSecretName(tcName))
 		if err != nil {
@@ -147,12 +162,14 @@ func (c *defaultPDControl) GetEndpoints(namespace Namespace, tcName string, tlsE
 		}
 	}
 
-	endpoints = []string{PDEtcdClientURL(namespace, tcName)}
+	config := &clientConfig{}
+	config.applyOptions(opts...)
+	endpoints = []string{pdEtcdClientURL(namespace, tcName, config.clientPort)}
 
 	return
 }
 
-func (c *defaultPDControl) GetPDEtcdClient(namespace Namespace, tcName string, tlsEnabled bool) (PDEtcdClient, error) {
+func (c *defaultPDControl) GetPDEtcdClient(namespace Namespace, tcName string, tlsEnabled bool, opts ...Option) (PDEtcdClient, error) {
 	c.etcdmutex.Lock()
 	defer c.etcdmutex.Unlock()
 
@@ -169,7 +186,9 @@ func (c *defaultPDControl) GetPDEtcdClient(namespace Namespace, tcName string, t
 
 	key := pdEtcdClientKey(namespace, tcName, tlsEnabled)
 	if _, ok := c.pdEtcdClients[key]; !ok {
-		pdetcdClient, err := NewPdEtcdClient(PDEtcdClientURL(namespace, tcName), DefaultTimeout, tlsConfig)
+		config := &clientConfig{}
+		config.applyOptions(opts...)
+		pdetcdClient, err := NewPdEtcdClient(pdEtcdClientURL(namespace, tcName, config.clientPort), DefaultTimeout, tlsConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -226,18 +245,25 @@ func pdEtcdClientKey(namespace Namespace, clusterName string, tlsEnabled bool) s
 }
 
 // genClientUrl builds the url of cluster pd client
-func genClientUrl(namespace Namespace, clusterName string, scheme string, clusterDomain string) string {
+func genClientUrl(namespace Namespace, clusterName string, scheme string, clusterDomain string, port int32) string {
 	if len(namespace) == 0 {
-		return fmt.Sprintf("%s://%s-pd:2379", scheme, clusterName)
+		return fmt.Sprintf("%s://%s-pd:%d", scheme, clusterName, port)
 	}
 	if len(clusterDomain) == 0 {
-		return fmt.Sprintf("%s://%s-pd.%s:2379", scheme, clusterName, string(namespace))
+		return fmt.Sprintf("%s://%s-pd.%s:%d", scheme, clusterName, string(namespace), port)
 	}
-	return fmt.Sprintf("%s://%s-pd-peer.%s.svc.%s:2379", scheme, clusterName, string(namespace), clusterDomain)
+	return fmt.Sprintf("%s://%s-pd-peer.%s.svc.%s:%d", scheme, clusterName, string(namespace), clusterDomain, port)
 }
 
 func PDEtcdClientURL(namespace Namespace, clusterName string) string {
-	return fmt.Sprintf("%s-pd.%s:2379", clusterName, string(namespace))
+	return pdEtcdClientURL(namespace, clusterName, defaultClientPort)
+}
+
+func pdEtcdClientURL(namespace Namespace, clusterName string, port int32) string {
+	if port == 0 {
+		port = defaultClientPort
+	}
+	return fmt.Sprintf("%s-pd.%s:%d", clusterName, string(namespace), port)
 }
 
 // FakePDControl implements a fake version of PDControlInterface.
@@ -88,8 +88,35 @@ type PDClient interface {
 	GetPDLeader() (*pdpb.Member, error)
 	// TransferPDLeader transfers pd leader to specified member
 	TransferPDLeader(name string) error
+	// SetMemberLeaderPriority sets the leader priority of the PD member, the member with a higher
+	// priority is preferred to be the leader
+	SetMemberLeaderPriority(name string, priority int32) error
 	// GetAutoscalingPlans returns the scaling plan for the cluster
 	GetAutoscalingPlans(strategy Strategy) ([]Plan, error)
+	// SetStoreWeight sets the leader weight and the region weight of the store
+	SetStoreWeight(storeID uint64, leaderWeight, regionWeight float64) error
+	// GetStoresLimit returns the store limits of all the stores, keyed by the store ID
+	GetStoresLimit() (map[string]StoreLimit, error)
+	// SetStoreLimit sets the store limit of the given type of the store
+	SetStoreLimit(storeID uint64, limitType StoreLimitType, rate float64) error
+	// GetScheduleConfig returns the schedule config items of PD, e.g. `leader-schedule-limit`
+	GetScheduleConfig() (map[string]interface{}, error)
+	// UpdateScheduleConfig changes the given schedule config items of PD
+	UpdateScheduleConfig(items map[string]interface{}) error
+	// GetPlacementRule returns the placement rule of the given group and ID
+	GetPlacementRule(groupID, ruleID string) (*PlacementRule, error)
+	// SetPlacementRule creates or updates the placement rule
+	SetPlacementRule(rule *PlacementRule) error
+	// GetTime returns the current time of the PD member serving the request, which is read from the
+	// Date header of the response and truncated to seconds
+	GetTime() (time.Time, error)
+	// GetRegionsCheck returns the regions in the abnormal state of the given check, e.g. the regions with down peers
+	GetRegionsCheck(check RegionCheck) (*RegionsInfo, error)
+	// RemoveTombstoneStores removes all the tombstone stores from cluster
+	RemoveTombstoneStores() error
+	// GetMinResolvedTS returns the min resolved ts of all the stores, all the transactions committed before it
+	// are resolved, i.e. no lock of them is left
+	GetMinResolvedTS() (*MinResolvedTS, error)
 }
 
 var (
@@ -97,12 +124,19 @@ var (
 	membersPrefix          = "pd/api/v1/members"
 	storesPrefix           = "pd/api/v1/stores"
 	storePrefix            = "pd/api/v1/store"
+	storesLimitPrefix      = "pd/api/v1/stores/limit"
 	configPrefix           = "pd/api/v1/config"
 	clusterIDPrefix        = "pd/api/v1/cluster"
 	schedulersPrefix       = "pd/api/v1/schedulers"
 	pdLeaderPrefix         = "pd/api/v1/leader"
 	pdLeaderTransferPrefix = "pd/api/v1/leader/transfer"
 	pdReplicationPrefix    = "pd/api/v1/config/replicate"
+	pdSchedulePrefix       = "pd/api/v1/config/schedule"
+	placementRulePrefix    = "pd/api/v1/config/rule"
+	statusPrefix           = "pd/api/v1/status"
+	regionsCheckPrefix     = "pd/api/v1/regions/check"
+	removeTombstonePrefix  = "pd/api/v1/stores/remove-tombstone"
+	minResolvedTSPrefix    = "pd/api/v1/min-resolved-ts"
 	// evictLeaderSchedulerConfigPrefix is the prefix of evict-leader-scheduler
 	// config API, available since PD v3.1.0.
 	evictLeaderSchedulerConfigPrefix = "pd/api/v1/scheduler-config/evict-leader-scheduler/list"
@@ -166,6 +200,72 @@ type StoreStatus struct {
 	StartTS         time.Time         `json:"start_ts"`
 	LastHeartbeatTS time.Time         `json:"last_heartbeat_ts"`
 	Uptime          typeutil.Duration `json:"uptime"`
+
+	LeaderWeight float64 `json:"leader_weight"`
+	RegionWeight float64 `json:"region_weight"`
+}
+
+// StoreLimitType is the type of a store limit
+type StoreLimitType string
+
+const (
+	// StoreLimitAddPeer limits the speed of adding peers to the store
+	StoreLimitAddPeer StoreLimitType = "add-peer"
+	// StoreLimitRemovePeer limits the speed of removing peers from the store
+	StoreLimitRemovePeer StoreLimitType = "remove-peer"
+)
+
+// RegionCheck is the type of the region check of PD
+type RegionCheck string
+
+const (
+	// RegionCheckMissPeer checks the regions with fewer peers than the max replicas
+	RegionCheckMissPeer RegionCheck = "miss-peer"
+	// RegionCheckDownPeer checks the regions with peers on the down stores
+	RegionCheckDownPeer RegionCheck = "down-peer"
+	// RegionCheckPendingPeer checks the regions with peers whose raft logs are lagging behind
+	RegionCheckPendingPeer RegionCheck = "pending-peer"
+)
+
+// MinResolvedTS is the min resolved ts returned from PD RESTful interface
+type MinResolvedTS struct {
+	MinResolvedTS uint64 `json:"min_resolved_ts"`
+	// IsRealTime is false if the min resolved ts is not persisted by PD, e.g. the persist interval is 0
+	IsRealTime bool `json:"is_real_time,omitempty"`
+}
+
+// RegionsInfo is the regions info returned from PD RESTful interface, only the count is kept
+// as the regions can be too many to be stored
+type RegionsInfo struct {
+	Count int `json:"count"`
+}
+
+// StoreLimit is the store limits of a store returned from PD RESTful interface, in operators per minute
+type StoreLimit struct {
+	AddPeer    float64 `json:"add-peer"`
+	RemovePeer float64 `json:"remove-peer"`
+}
+
+// PlacementRule is a placement rule of PD, only the fields used by the operator are kept
+type PlacementRule struct {
+	GroupID          string            `json:"group_id"`
+	ID               string            `json:"id"`
+	Index            int               `json:"index,omitempty"`
+	Override         bool              `json:"override,omitempty"`
+	StartKeyHex      string            `json:"start_key"`
+	EndKeyHex        string            `json:"end_key"`
+	Role             string            `json:"role"`
+	Count            int               `json:"count"`
+	LabelConstraints []LabelConstraint `json:"label_constraints,omitempty"`
+	LocationLabels   []string          `json:"location_labels,omitempty"`
+	IsolationLevel   string            `json:"isolation_level,omitempty"`
+}
+
+// LabelConstraint is the constraint of the store labels in a placement rule
+type LabelConstraint struct {
+	Key    string   `json:"key"`
+	Op     string   `json:"op"`
+	Values []string `json:"values"`
 }
 
 // StoreInfo is a single store info returned from PD RESTful interface
@@ -680,6 +780,26 @@ func (c *pdClient) TransferPDLeader(memberName string) error {
 	return fmt.Errorf("failed %v to transfer pd leader to %s,error: %v", res.StatusCode, memberName, err2)
 }
 
+func (c *pdClient) SetMemberLeaderPriority(name string, priority int32) error {
+	apiURL := fmt.Sprintf("%s/%s/name/%s", c.url, membersPrefix, name)
+	data, err := json.Marshal(map[string]int32{
+		"leader-priority": priority,
+	})
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	err = httputil.ReadErrorBody(res.Body)
+	return fmt.Errorf("failed %v to set leader priority of pd member %s: %v", res.StatusCode, name, err)
+}
+
 func (c *pdClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, autoscalingPrefix)
 	data, err := json.Marshal(strategy)
@@ -698,6 +818,137 @@ func (c *pdClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	return plans, nil
 }
 
+func (c *pdClient) SetStoreWeight(storeID uint64, leaderWeight, regionWeight float64) error {
+	apiURL := fmt.Sprintf("%s/%s/%d/weight", c.url, storePrefix, storeID)
+	data, err := json.Marshal(map[string]float64{
+		"leader": leaderWeight,
+		"region": regionWeight,
+	})
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	err = httputil.ReadErrorBody(res.Body)
+	return fmt.Errorf("failed %v to set weight of store %d: %v", res.StatusCode, storeID, err)
+}
+
+func (c *pdClient) GetStoresLimit() (map[string]StoreLimit, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, storesLimitPrefix)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	limits := map[string]StoreLimit{}
+	if err := json.Unmarshal(body, &limits); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+func (c *pdClient) SetStoreLimit(storeID uint64, limitType StoreLimitType, rate float64) error {
+	apiURL := fmt.Sprintf("%s/%s/%d/limit", c.url, storePrefix, storeID)
+	data, err := json.Marshal(map[string]interface{}{
+		"rate": rate,
+		"type": limitType,
+	})
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	err = httputil.ReadErrorBody(res.Body)
+	return fmt.Errorf("failed %v to set %s limit of store %d: %v", res.StatusCode, limitType, storeID, err)
+}
+
+func (c *pdClient) GetScheduleConfig() (map[string]interface{}, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, pdSchedulePrefix)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	items := map[string]interface{}{}
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *pdClient) UpdateScheduleConfig(items map[string]interface{}) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, pdSchedulePrefix)
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	err = httputil.ReadErrorBody(res.Body)
+	return fmt.Errorf("failed %v to update schedule config: %v", res.StatusCode, err)
+}
+
+func (c *pdClient) GetPlacementRule(groupID, ruleID string) (*PlacementRule, error) {
+	apiURL := fmt.Sprintf("%s/%s/%s/%s", c.url, placementRulePrefix, groupID, ruleID)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	rule := &PlacementRule{}
+	if err := json.Unmarshal(body, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (c *pdClient) SetPlacementRule(rule *PlacementRule) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, placementRulePrefix)
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	err = httputil.ReadErrorBody(res.Body)
+	return fmt.Errorf("failed %v to set placement rule %s/%s: %v", res.StatusCode, rule.GroupID, rule.ID, err)
+}
+
+func (c *pdClient) GetTime() (time.Time, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, statusPrefix)
+	res, err := c.httpClient.Get(apiURL)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode != http.StatusOK {
+		err = httputil.ReadErrorBody(res.Body)
+		return time.Time{}, fmt.Errorf("failed %v to get status: %v", res.StatusCode, err)
+	}
+	return http.ParseTime(res.Header.Get("Date"))
+}
+
 func getLeaderEvictSchedulerInfo(storeID uint64) *schedulerInfo {
 	return &schedulerInfo{"evict-leader-scheduler", storeID}
 }
@@ -725,3 +976,47 @@ func IsTiKVNotBootstrappedError(err error) bool {
 	_, ok := err.(*TiKVNotBootstrappedError)
 	return ok
 }
+
+func (c *pdClient) GetRegionsCheck(check RegionCheck) (*RegionsInfo, error) {
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, regionsCheckPrefix, check)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	regionsInfo := &RegionsInfo{}
+	if err := json.Unmarshal(body, regionsInfo); err != nil {
+		return nil, err
+	}
+	return regionsInfo, nil
+}
+
+func (c *pdClient) RemoveTombstoneStores() error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, removeTombstonePrefix)
+	req, err := http.NewRequest("DELETE", apiURL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	err = httputil.ReadErrorBody(res.Body)
+	return fmt.Errorf("failed %v to remove tombstone stores: %v", res.StatusCode, err)
+}
+
+func (c *pdClient) GetMinResolvedTS() (*MinResolvedTS, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, minResolvedTSPrefix)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	ts := &MinResolvedTS{}
+	if err := json.Unmarshal(body, ts); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
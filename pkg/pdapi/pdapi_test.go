@@ -774,6 +774,69 @@ func TestGeneric(t *testing.T) {
 			wantPath:    fmt.Sprintf("/%s/%s", pdLeaderTransferPrefix, "foo"),
 			checkResult: checkNoError,
 		},
+		{
+			name:   "SetMemberLeaderPriority",
+			method: "SetMemberLeaderPriority",
+			args: []reflect.Value{
+				reflect.ValueOf("foo"),
+				reflect.ValueOf(int32(2)),
+			},
+			resp:        []byte(``),
+			statusCode:  http.StatusOK,
+			wantMethod:  "POST",
+			wantPath:    fmt.Sprintf("/%s/name/%s", membersPrefix, "foo"),
+			checkResult: checkNoError,
+		},
+		{
+			name:   "GetPlacementRule",
+			method: "GetPlacementRule",
+			args: []reflect.Value{
+				reflect.ValueOf("pd"),
+				reflect.ValueOf("default"),
+			},
+			resp: []byte(`
+{
+	"group_id": "pd",
+	"id": "default",
+	"start_key": "",
+	"end_key": "",
+	"role": "voter",
+	"count": 3
+}
+`),
+			statusCode:  http.StatusOK,
+			wantMethod:  "GET",
+			wantPath:    fmt.Sprintf("/%s/pd/default", placementRulePrefix),
+			checkResult: checkNoError,
+		},
+		{
+			name:   "SetPlacementRule",
+			method: "SetPlacementRule",
+			args: []reflect.Value{
+				reflect.ValueOf(&PlacementRule{GroupID: "pd", ID: "default", Role: "voter", Count: 3}),
+			},
+			statusCode:  http.StatusOK,
+			wantMethod:  "POST",
+			wantPath:    fmt.Sprintf("/%s", placementRulePrefix),
+			checkResult: checkNoError,
+		},
+		{
+			name:        "GetTime",
+			method:      "GetTime",
+			resp:        []byte(`{}`),
+			statusCode:  http.StatusOK,
+			wantMethod:  "GET",
+			wantPath:    fmt.Sprintf("/%s", statusPrefix),
+			checkResult: checkNoError,
+		},
+		{
+			name:        "RemoveTombstoneStores",
+			method:      "RemoveTombstoneStores",
+			statusCode:  http.StatusOK,
+			wantMethod:  "DELETE",
+			wantPath:    fmt.Sprintf("/%s", removeTombstonePrefix),
+			checkResult: checkNoError,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,77 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/defaulting"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+)
+
+type DMClusterStrategy struct{}
+
+func (DMClusterStrategy) NewObject() runtime.Object {
+	return &v1alpha1.DMCluster{}
+}
+
+func (DMClusterStrategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+	if dc, ok := castDMCluster(obj); ok {
+		defaulting.SetDMClusterDefault(dc)
+	}
+}
+
+func (DMClusterStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
+}
+
+func (DMClusterStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	if dc, ok := castDMCluster(obj); ok {
+		return validation.ValidateDMCluster(dc)
+	}
+	return field.ErrorList{}
+}
+
+func (DMClusterStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	if dc, ok := castDMCluster(obj); ok {
+		return validation.ValidateDMCluster(dc)
+	}
+	return field.ErrorList{}
+}
+
+func (DMClusterStrategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
+	if dc, ok := castDMCluster(obj); ok {
+		return validation.DMClusterConfigWarnings(dc)
+	}
+	return nil
+}
+
+func (DMClusterStrategy) WarningsOnUpdate(ctx context.Context, obj, old runtime.Object) []string {
+	if dc, ok := castDMCluster(obj); ok {
+		return validation.DMClusterConfigWarnings(dc)
+	}
+	return nil
+}
+
+func castDMCluster(obj runtime.Object) (*v1alpha1.DMCluster, bool) {
+	dc, ok := obj.(*v1alpha1.DMCluster)
+	if !ok {
+		klog.Errorf("Object %T is not v1alpah1.DMCluster, cannot processed by DMClusterStrategy", obj)
+		return nil, false
+	}
+	return dc, true
+}
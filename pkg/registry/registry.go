@@ -16,5 +16,6 @@ package registry
 var (
 	Strategies = []CreateUpdateStrategy{
 		TidbClusterStrategy{},
+		DMClusterStrategy{},
 	}
 )
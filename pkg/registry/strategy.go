@@ -74,3 +74,11 @@ type CreateUpdateStrategy interface {
 	// ValidateUpdate validates an update request for existing resource
 	ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList
 }
+
+// WarningsStrategy is implemented by the strategies returning warnings of the resources to the clients
+type WarningsStrategy interface {
+	// WarningsOnCreate returns the warnings of a new resource
+	WarningsOnCreate(ctx context.Context, obj runtime.Object) []string
+	// WarningsOnUpdate returns the warnings of an update request for existing resource
+	WarningsOnUpdate(ctx context.Context, obj, old runtime.Object) []string
+}
@@ -21,6 +21,8 @@ type ActionType string
 
 const (
 	GetLeaderCountActionType ActionType = "GetLeaderCount"
+	GetConfigActionType      ActionType = "GetConfig"
+	UpdateConfigActionType   ActionType = "UpdateConfig"
 )
 
 type NotFoundReaction struct {
@@ -35,6 +37,7 @@ type Action struct {
 	ID     uint64
 	Name   string
 	Labels map[string]string
+	Config map[string]interface{}
 }
 
 type Reaction func(action *Action) (interface{}, error)
@@ -72,3 +75,18 @@ func (c *FakeTiKVClient) GetLeaderCount() (int, error) {
 	}
 	return result.(int), nil
 }
+
+func (c *FakeTiKVClient) GetConfig() (map[string]interface{}, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetConfigActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]interface{}), nil
+}
+
+func (c *FakeTiKVClient) UpdateConfig(items map[string]interface{}) error {
+	action := &Action{Config: items}
+	_, err := c.fakeAPI(UpdateConfigActionType, action)
+	return err
+}
@@ -16,6 +16,7 @@ package tikvapi
 import (
 	"crypto/tls"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
@@ -70,7 +71,12 @@ func tikvPodClientKey(schema, namespace, clusterName, podName string) string {
 
 // TiKVPodClientURL builds the url of tikv pod client
 func TiKVPodClientURL(namespace, clusterName, podName, scheme string) string {
-	return fmt.Sprintf("%s://%s.%s-tikv-peer.%s:20180", scheme, podName, clusterName, namespace)
+	peerSvc := fmt.Sprintf("%s-tikv-peer", clusterName)
+	// the Pods of the TiKV cold group are governed by the peer service of the group
+	if strings.HasPrefix(podName, fmt.Sprintf("%s-tikv-cold-", clusterName)) {
+		peerSvc = fmt.Sprintf("%s-tikv-cold-peer", clusterName)
+	}
+	return fmt.Sprintf("%s://%s.%s.%s:20180", scheme, podName, peerSvc, namespace)
 }
 
 // FakeTiKVControl implements a fake version of TiKVControlInterface.
@@ -14,13 +14,17 @@
 package tikvapi
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"strconv"
 	"time"
 
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/prom2json"
 	"k8s.io/klog/v2"
@@ -31,11 +35,16 @@ const (
 	metricNameRegionCount = "tikv_raftstore_region_count"
 	labelNameLeaderCount  = "leader"
 	metricsPrefix         = "metrics"
+	configPrefix          = "config"
 )
 
 // TiKVClient provides tikv server's api
 type TiKVClient interface {
 	GetLeaderCount() (int, error)
+	// GetConfig returns the config that the TiKV server is running with
+	GetConfig() (map[string]interface{}, error)
+	// UpdateConfig changes the config items online, the keys are dotted paths, e.g. `storage.block-cache.capacity`
+	UpdateConfig(items map[string]interface{}) error
 }
 
 // tikvClient is default implementation of TiKVClient
@@ -74,6 +83,50 @@ func (c *tikvClient) GetLeaderCount() (int, error) {
 	return 0, fmt.Errorf("metric %s{type=\"%s\"} not found for %s", metricNameRegionCount, labelNameLeaderCount, apiURL)
 }
 
+// GetConfig gets the live config from the URL
+func (c *tikvClient) GetConfig() (map[string]interface{}, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, configPrefix)
+	res, err := c.httpClient.Get(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	defer httputil.DeferClose(res.Body)
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response %s:%v URL: %s", string(body), res.StatusCode, apiURL)
+	}
+	cfg := map[string]interface{}{}
+	if err := json.Unmarshal(body, &cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// UpdateConfig updates the config items online by the URL
+func (c *tikvClient) UpdateConfig(items map[string]interface{}) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, configPrefix)
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("error response %s:%v URL: %s", string(body), res.StatusCode, apiURL)
+	}
+	return nil
+}
+
 // NewTiKVClient returns a new TiKVClient
 func NewTiKVClient(url string, timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) TiKVClient {
 	return &tikvClient{
@@ -0,0 +1,361 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chart
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Result is the result of converting the values of the legacy tidb-cluster chart
+type Result struct {
+	TidbCluster *v1alpha1.TidbCluster
+	// TidbMonitor is nil if the monitor is not created by the chart
+	TidbMonitor *v1alpha1.TidbMonitor
+	// Warnings are the settings which are not converted and should be migrated manually
+	Warnings []string
+}
+
+// ParseValues parses the values.yaml of the legacy tidb-cluster chart
+func ParseValues(data []byte) (*Values, error) {
+	values := &Values{}
+	if err := yaml.Unmarshal(data, values); err != nil {
+		return nil, fmt.Errorf("failed to parse values: %v", err)
+	}
+	return values, nil
+}
+
+// Convert translates the values of the legacy tidb-cluster chart into the equivalent TidbCluster and TidbMonitor.
+// The name defaults to the clusterName in the values, which is the name of the release if not set.
+func Convert(values *Values, name, namespace string) (*Result, error) {
+	if name == "" {
+		name = values.ClusterName
+	}
+	if name == "" {
+		return nil, fmt.Errorf("the name of the cluster is required as clusterName is not set in the values")
+	}
+	if values.PD == nil || values.TiKV == nil || values.TiDB == nil {
+		return nil, fmt.Errorf("pd, tikv and tidb are required in the values")
+	}
+
+	c := &converter{values: values}
+	tc, err := c.tidbCluster(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{TidbCluster: tc}
+	if values.Monitor != nil && values.Monitor.Create {
+		result.TidbMonitor = c.tidbMonitor(name, namespace)
+	}
+	c.checkUnsupported()
+	result.Warnings = c.warnings
+	return result, nil
+}
+
+type converter struct {
+	values   *Values
+	warnings []string
+}
+
+func (c *converter) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *converter) tidbCluster(name, namespace string) (*v1alpha1.TidbCluster, error) {
+	values := c.values
+	tc := &v1alpha1.TidbCluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       v1alpha1.TiDBClusterKind,
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    values.ExtraLabels,
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			SchedulerName:   values.SchedulerName,
+			Timezone:        values.Timezone,
+			EnablePVReclaim: values.EnablePVReclaim,
+			TLSCluster:      values.TLSCluster,
+			Services:        values.Services,
+		},
+	}
+	if values.PVReclaimPolicy != "" {
+		policy := values.PVReclaimPolicy
+		tc.Spec.PVReclaimPolicy = &policy
+	}
+	if values.HATopologyKey != "" {
+		tc.Annotations = map[string]string{label.AnnHATopologyKey: values.HATopologyKey}
+	}
+	if values.EnableConfigMapRollout != nil && *values.EnableConfigMapRollout {
+		tc.Spec.ConfigUpdateStrategy = v1alpha1.ConfigUpdateStrategyRollingUpdate
+	} else {
+		tc.Spec.ConfigUpdateStrategy = v1alpha1.ConfigUpdateStrategyInPlace
+	}
+	if values.Helper != nil && values.Helper.Image != "" {
+		image := values.Helper.Image
+		tc.Spec.Helper = &v1alpha1.HelperSpec{Image: &image}
+	}
+	if values.Discovery != nil {
+		tc.Spec.Discovery = v1alpha1.DiscoverySpec{
+			ComponentSpec: &v1alpha1.ComponentSpec{
+				Affinity:    values.Discovery.Affinity,
+				Tolerations: values.Discovery.Tolerations,
+			},
+			ResourceRequirements: values.Discovery.Resources,
+		}
+	}
+
+	// the version of pd is used as the version of the cluster
+	var version string
+	tc.Spec.PD = &v1alpha1.PDSpec{Config: v1alpha1.NewPDConfig()}
+	tc.Spec.PD.BaseImage, version = splitImage(values.PD.Image)
+	tc.Spec.Version = version
+	tc.Spec.PD.ComponentSpec = c.componentSpec(&values.PD.ComponentValues, version)
+	tc.Spec.PD.Replicas = values.PD.Replicas
+	tc.Spec.PD.ResourceRequirements = values.PD.Resources
+	tc.Spec.PD.StorageClassName = stringPtr(values.PD.StorageClassName)
+	tc.Spec.PD.Service = values.PD.Service
+	if err := tc.Spec.PD.Config.UnmarshalTOML([]byte(values.PD.Config)); err != nil {
+		return nil, fmt.Errorf("failed to parse the config of pd: %v", err)
+	}
+
+	tc.Spec.TiKV = &v1alpha1.TiKVSpec{Config: v1alpha1.NewTiKVConfig()}
+	tc.Spec.TiKV.BaseImage, _ = splitImage(values.TiKV.Image)
+	tc.Spec.TiKV.ComponentSpec = c.componentSpec(&values.TiKV.ComponentValues, version)
+	tc.Spec.TiKV.Replicas = values.TiKV.Replicas
+	tc.Spec.TiKV.ResourceRequirements = values.TiKV.Resources
+	tc.Spec.TiKV.StorageClassName = stringPtr(values.TiKV.StorageClassName)
+	tc.Spec.TiKV.MaxFailoverCount = values.TiKV.MaxFailoverCount
+	if err := tc.Spec.TiKV.Config.UnmarshalTOML([]byte(values.TiKV.Config)); err != nil {
+		return nil, fmt.Errorf("failed to parse the config of tikv: %v", err)
+	}
+	if values.TiKV.PostArgScript != "" {
+		c.warn("tikv.postArgScript is not converted, the store labels are set by the operator")
+	}
+
+	tc.Spec.TiDB = &v1alpha1.TiDBSpec{Config: v1alpha1.NewTiDBConfig()}
+	tc.Spec.TiDB.BaseImage, _ = splitImage(values.TiDB.Image)
+	tc.Spec.TiDB.ComponentSpec = c.componentSpec(&values.TiDB.ComponentValues, version)
+	tc.Spec.TiDB.Replicas = values.TiDB.Replicas
+	tc.Spec.TiDB.ResourceRequirements = values.TiDB.Resources
+	tc.Spec.TiDB.MaxFailoverCount = values.TiDB.MaxFailoverCount
+	tc.Spec.TiDB.Service = values.TiDB.Service
+	tc.Spec.TiDB.SeparateSlowLog = values.TiDB.SeparateSlowLog
+	tc.Spec.TiDB.TLSClient = values.TiDB.TLSClient
+	if tailer := values.TiDB.SlowLogTailer; tailer != nil {
+		tc.Spec.TiDB.SlowLogTailer = &v1alpha1.TiDBSlowLogTailerSpec{
+			ResourceRequirements: tailer.Resources,
+			Image:                stringPtr(tailer.Image),
+			ImagePullPolicy:      pullPolicyPtr(tailer.ImagePullPolicy),
+		}
+	}
+	if plugin := values.TiDB.Plugin; plugin != nil && plugin.Enable {
+		tc.Spec.TiDB.Plugins = plugin.List
+	}
+	if err := tc.Spec.TiDB.Config.UnmarshalTOML([]byte(values.TiDB.Config)); err != nil {
+		return nil, fmt.Errorf("failed to parse the config of tidb: %v", err)
+	}
+
+	if values.Binlog != nil && values.Binlog.Pump != nil && values.Binlog.Pump.Create {
+		pump, err := c.pumpSpec(version)
+		if err != nil {
+			return nil, err
+		}
+		tc.Spec.Pump = pump
+		binlogEnabled := true
+		tc.Spec.TiDB.BinlogEnabled = &binlogEnabled
+	}
+	return tc, nil
+}
+
+func (c *converter) componentSpec(values *ComponentValues, version string) v1alpha1.ComponentSpec {
+	spec := v1alpha1.ComponentSpec{
+		ImagePullPolicy:    pullPolicyPtr(values.ImagePullPolicy),
+		HostNetwork:        values.HostNetwork,
+		Affinity:           values.Affinity,
+		PriorityClassName:  stringPtr(values.PriorityClassName),
+		NodeSelector:       values.NodeSelector,
+		Annotations:        values.Annotations,
+		Tolerations:        values.Tolerations,
+		PodSecurityContext: values.PodSecurityContext,
+	}
+	if _, v := splitImage(values.Image); v != "" && v != version {
+		spec.Version = &v
+	}
+	return spec
+}
+
+func (c *converter) pumpSpec(version string) (*v1alpha1.PumpSpec, error) {
+	values := c.values.Binlog.Pump
+	pump := &v1alpha1.PumpSpec{
+		ComponentSpec: v1alpha1.ComponentSpec{
+			ImagePullPolicy: pullPolicyPtr(values.ImagePullPolicy),
+			Affinity:        values.Affinity,
+			Tolerations:     values.Tolerations,
+		},
+		ResourceRequirements: *values.Resources.DeepCopy(),
+		Replicas:             values.Replicas,
+		StorageClassName:     stringPtr(values.StorageClassName),
+		Config:               config.New(map[string]interface{}{}),
+	}
+	var v string
+	pump.BaseImage, v = splitImage(values.Image)
+	if v != "" && v != version {
+		pump.Version = &v
+	}
+	if values.Storage != "" {
+		quantity, err := resource.ParseQuantity(values.Storage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse binlog.pump.storage: %v", err)
+		}
+		if pump.Requests == nil {
+			pump.Requests = corev1.ResourceList{}
+		}
+		pump.Requests[corev1.ResourceStorage] = quantity
+	}
+
+	// keep the same defaults as the config template of the chart
+	gc, heartbeatInterval, syncLog := 7, 2, true
+	if values.GC != nil {
+		gc = *values.GC
+	}
+	if values.HeartbeatInterval != nil {
+		heartbeatInterval = *values.HeartbeatInterval
+	}
+	if values.SyncLog != nil {
+		syncLog = *values.SyncLog
+	}
+	pump.Config.Set("gc", gc)
+	pump.Config.Set("heartbeat-interval", heartbeatInterval)
+	pump.Config.Set("storage.sync-log", syncLog)
+	if values.LogLevel != "" {
+		pump.Config.Set("log-level", values.LogLevel)
+	}
+	return pump, nil
+}
+
+func (c *converter) tidbMonitor(name, namespace string) *v1alpha1.TidbMonitor {
+	values := c.values.Monitor
+	tm := &v1alpha1.TidbMonitor{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       v1alpha1.TiDBMonitorKind,
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    c.values.ExtraLabels,
+		},
+		Spec: v1alpha1.TidbMonitorSpec{
+			Clusters:     []v1alpha1.TidbClusterRef{{Name: name, Namespace: namespace}},
+			Persistent:   values.Persistent,
+			Storage:      values.Storage,
+			NodeSelector: values.NodeSelector,
+			Tolerations:  values.Tolerations,
+		},
+	}
+	if values.Persistent {
+		tm.Spec.StorageClassName = stringPtr(values.StorageClassName)
+	}
+	if p := values.Prometheus; p != nil {
+		tm.Spec.Prometheus = v1alpha1.PrometheusSpec{
+			MonitorContainer: monitorContainer(&p.MonitorContainerValues),
+			LogLevel:         p.LogLevel,
+			Service:          p.Service,
+			ReserveDays:      p.ReserveDays,
+		}
+	}
+	if g := values.Grafana; g != nil && g.Create {
+		tm.Spec.Grafana = &v1alpha1.GrafanaSpec{
+			MonitorContainer: monitorContainer(&g.MonitorContainerValues),
+			LogLevel:         g.LogLevel,
+			Service:          g.Service,
+			Username:         g.Username,
+			Password:         g.Password,
+			Envs:             g.Config,
+		}
+		if g.Username != "" || g.Password != "" {
+			c.warn("monitor.grafana.username and password are converted in plain text, consider using usernameSecret and passwordSecret of the TidbMonitor")
+		}
+	}
+	if r := values.Reloader; r != nil && r.Create {
+		tm.Spec.Reloader = v1alpha1.ReloaderSpec{
+			MonitorContainer: monitorContainer(&r.MonitorContainerValues),
+			Service:          r.Service,
+		}
+	}
+	if i := values.Initializer; i != nil {
+		tm.Spec.Initializer = v1alpha1.InitializerSpec{
+			MonitorContainer: monitorContainer(&i.MonitorContainerValues),
+			Envs:             i.Config,
+		}
+	}
+	return tm
+}
+
+func (c *converter) checkUnsupported() {
+	values := c.values
+	if values.Binlog != nil && values.Binlog.Drainer != nil && values.Binlog.Drainer.Create {
+		c.warn("binlog.drainer is not converted, deploy drainer with the tidb-drainer chart")
+	}
+	if values.ScheduledBackup != nil && values.ScheduledBackup.Create {
+		c.warn("scheduledBackup is not converted, create a BackupSchedule instead")
+	}
+	if values.Importer != nil && values.Importer.Create {
+		c.warn("importer is not converted, deploy tikv-importer with the tikv-importer chart")
+	}
+}
+
+func monitorContainer(values *MonitorContainerValues) v1alpha1.MonitorContainer {
+	baseImage, version := splitImage(values.Image)
+	return v1alpha1.MonitorContainer{
+		ResourceRequirements: values.Resources,
+		BaseImage:            baseImage,
+		Version:              version,
+		ImagePullPolicy:      pullPolicyPtr(values.ImagePullPolicy),
+	}
+}
+
+// splitImage splits the image into the repository and the tag
+func splitImage(image string) (string, string) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return image, ""
+	}
+	return image[:i], image[i+1:]
+}
+
+func stringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func pullPolicyPtr(policy corev1.PullPolicy) *corev1.PullPolicy {
+	if policy == "" {
+		return nil
+	}
+	return &policy
+}
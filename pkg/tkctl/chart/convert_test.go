@@ -0,0 +1,155 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chart
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const testValues = `
+clusterName: demo
+schedulerName: tidb-scheduler
+pvReclaimPolicy: Retain
+enableConfigMapRollout: true
+haTopologyKey: kubernetes.io/hostname
+pd:
+  config: |
+    [replication]
+    location-labels = ["zone", "host"]
+  replicas: 3
+  image: pingcap/pd:v5.2.1
+  storageClassName: local-storage
+  resources:
+    requests:
+      storage: 1Gi
+tikv:
+  config: |
+    log-level = "info"
+  replicas: 3
+  image: pingcap/tikv:v5.2.1
+  maxFailoverCount: 3
+tidb:
+  config: |
+    [log]
+    level = "info"
+  replicas: 2
+  image: pingcap/tidb:v5.2.2
+  service:
+    type: NodePort
+    exposeStatus: true
+  plugin:
+    enable: true
+    list: ["allowlist-1"]
+monitor:
+  create: true
+  prometheus:
+    image: prom/prometheus:v2.27.1
+    reserveDays: 12
+  grafana:
+    create: false
+binlog:
+  pump:
+    create: true
+    replicas: 1
+    image: pingcap/tidb-binlog:v5.2.1
+    storage: 20Gi
+    gc: 3
+  drainer:
+    create: true
+scheduledBackup:
+  create: true
+`
+
+func TestConvert(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	values, err := ParseValues([]byte(testValues))
+	g.Expect(err).NotTo(HaveOccurred())
+	result, err := Convert(values, "", "tidb")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tc := result.TidbCluster
+	g.Expect(tc.Name).To(Equal("demo"))
+	g.Expect(tc.Namespace).To(Equal("tidb"))
+	g.Expect(tc.Annotations[label.AnnHATopologyKey]).To(Equal("kubernetes.io/hostname"))
+	g.Expect(tc.Spec.Version).To(Equal("v5.2.1"))
+	g.Expect(tc.Spec.ConfigUpdateStrategy).To(Equal(v1alpha1.ConfigUpdateStrategyRollingUpdate))
+	g.Expect(*tc.Spec.PVReclaimPolicy).To(Equal(corev1.PersistentVolumeReclaimRetain))
+
+	g.Expect(tc.Spec.PD.BaseImage).To(Equal("pingcap/pd"))
+	g.Expect(tc.Spec.PD.Version).To(BeNil())
+	g.Expect(*tc.Spec.PD.StorageClassName).To(Equal("local-storage"))
+	g.Expect(tc.Spec.PD.Requests.Storage().String()).To(Equal("1Gi"))
+	g.Expect(tc.Spec.PD.Config.Get("replication.location-labels").MustStringSlice()).To(Equal([]string{"zone", "host"}))
+	g.Expect(tc.Spec.TiKV.Config.Get("log-level").MustString()).To(Equal("info"))
+	g.Expect(*tc.Spec.TiKV.MaxFailoverCount).To(Equal(int32(3)))
+
+	g.Expect(tc.Spec.TiDB.BaseImage).To(Equal("pingcap/tidb"))
+	g.Expect(*tc.Spec.TiDB.Version).To(Equal("v5.2.2"))
+	g.Expect(tc.Spec.TiDB.Config.Get("log.level").MustString()).To(Equal("info"))
+	g.Expect(tc.Spec.TiDB.Service.Type).To(Equal(corev1.ServiceTypeNodePort))
+	g.Expect(*tc.Spec.TiDB.Service.ExposeStatus).To(BeTrue())
+	g.Expect(tc.Spec.TiDB.Plugins).To(Equal([]string{"allowlist-1"}))
+	g.Expect(*tc.Spec.TiDB.BinlogEnabled).To(BeTrue())
+
+	g.Expect(tc.Spec.Pump.BaseImage).To(Equal("pingcap/tidb-binlog"))
+	g.Expect(tc.Spec.Pump.Requests.Storage().String()).To(Equal("20Gi"))
+	g.Expect(tc.Spec.Pump.Config.Get("gc").MustInt()).To(Equal(int64(3)))
+	g.Expect(tc.Spec.Pump.Config.Get("heartbeat-interval").MustInt()).To(Equal(int64(2)))
+
+	tm := result.TidbMonitor
+	g.Expect(tm).NotTo(BeNil())
+	g.Expect(tm.Spec.Clusters).To(Equal([]v1alpha1.TidbClusterRef{{Name: "demo", Namespace: "tidb"}}))
+	g.Expect(tm.Spec.Prometheus.BaseImage).To(Equal("prom/prometheus"))
+	g.Expect(tm.Spec.Prometheus.Version).To(Equal("v2.27.1"))
+	g.Expect(tm.Spec.Prometheus.ReserveDays).To(Equal(12))
+	g.Expect(tm.Spec.Grafana).To(BeNil())
+
+	g.Expect(result.Warnings).To(HaveLen(2))
+	g.Expect(result.Warnings[0]).To(ContainSubstring("binlog.drainer"))
+	g.Expect(result.Warnings[1]).To(ContainSubstring("scheduledBackup"))
+
+	// the name is required if clusterName is not set
+	values.ClusterName = ""
+	_, err = Convert(values, "", "tidb")
+	g.Expect(err).To(HaveOccurred())
+	result, err = Convert(values, "other", "tidb")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.TidbCluster.Name).To(Equal("other"))
+}
+
+func TestSplitImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		image   string
+		base    string
+		version string
+	}{
+		{image: "pingcap/pd:v5.2.1", base: "pingcap/pd", version: "v5.2.1"},
+		{image: "pingcap/pd", base: "pingcap/pd", version: ""},
+		{image: "localhost:5000/pingcap/pd", base: "localhost:5000/pingcap/pd", version: ""},
+		{image: "localhost:5000/pingcap/pd:latest", base: "localhost:5000/pingcap/pd", version: "latest"},
+	}
+	for _, test := range tests {
+		base, version := splitImage(test.image)
+		g.Expect(base).To(Equal(test.base), test.image)
+		g.Expect(version).To(Equal(test.version), test.image)
+	}
+}
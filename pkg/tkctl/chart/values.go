@@ -0,0 +1,188 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chart
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Values is the subset of the values.yaml of the legacy tidb-cluster chart
+// that can be translated into TidbCluster and TidbMonitor.
+type Values struct {
+	ClusterName            string                               `json:"clusterName,omitempty"`
+	ExtraLabels            map[string]string                    `json:"extraLabels,omitempty"`
+	SchedulerName          string                               `json:"schedulerName,omitempty"`
+	Timezone               string                               `json:"timezone,omitempty"`
+	PVReclaimPolicy        corev1.PersistentVolumeReclaimPolicy `json:"pvReclaimPolicy,omitempty"`
+	EnablePVReclaim        *bool                                `json:"enablePVReclaim,omitempty"`
+	Services               []v1alpha1.Service                   `json:"services,omitempty"`
+	Discovery              *DiscoveryValues                     `json:"discovery,omitempty"`
+	EnableConfigMapRollout *bool                                `json:"enableConfigMapRollout,omitempty"`
+	HATopologyKey          string                               `json:"haTopologyKey,omitempty"`
+	TLSCluster             *v1alpha1.TLSCluster                 `json:"tlsCluster,omitempty"`
+	Helper                 *ImageValues                         `json:"helper,omitempty"`
+	PD                     *PDValues                            `json:"pd,omitempty"`
+	TiKV                   *TiKVValues                          `json:"tikv,omitempty"`
+	TiDB                   *TiDBValues                          `json:"tidb,omitempty"`
+	Monitor                *MonitorValues                       `json:"monitor,omitempty"`
+	Binlog                 *BinlogValues                        `json:"binlog,omitempty"`
+	ScheduledBackup        *CreateValues                        `json:"scheduledBackup,omitempty"`
+	Importer               *CreateValues                        `json:"importer,omitempty"`
+}
+
+// ImageValues is the image setting shared by the containers of the chart
+type ImageValues struct {
+	Image           string            `json:"image,omitempty"`
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+}
+
+// CreateValues is used for the parts of the chart which are only checked for existence
+type CreateValues struct {
+	Create bool `json:"create,omitempty"`
+}
+
+// DiscoveryValues is the values of the discovery service
+type DiscoveryValues struct {
+	ImageValues `json:",inline"`
+	Resources   corev1.ResourceRequirements `json:"resources,omitempty"`
+	Affinity    *corev1.Affinity            `json:"affinity,omitempty"`
+	Tolerations []corev1.Toleration         `json:"tolerations,omitempty"`
+}
+
+// ComponentValues is the values shared by the pd, tikv and tidb of the chart
+type ComponentValues struct {
+	ImageValues        `json:",inline"`
+	Config             string                      `json:"config,omitempty"`
+	Replicas           int32                       `json:"replicas,omitempty"`
+	StorageClassName   string                      `json:"storageClassName,omitempty"`
+	Resources          corev1.ResourceRequirements `json:"resources,omitempty"`
+	Affinity           *corev1.Affinity            `json:"affinity,omitempty"`
+	NodeSelector       map[string]string           `json:"nodeSelector,omitempty"`
+	Tolerations        []corev1.Toleration         `json:"tolerations,omitempty"`
+	Annotations        map[string]string           `json:"annotations,omitempty"`
+	HostNetwork        *bool                       `json:"hostNetwork,omitempty"`
+	PodSecurityContext *corev1.PodSecurityContext  `json:"podSecurityContext,omitempty"`
+	PriorityClassName  string                      `json:"priorityClassName,omitempty"`
+	MaxFailoverCount   *int32                      `json:"maxFailoverCount,omitempty"`
+}
+
+// PDValues is the values of pd
+type PDValues struct {
+	ComponentValues `json:",inline"`
+	Service         *v1alpha1.ServiceSpec `json:"service,omitempty"`
+}
+
+// TiKVValues is the values of tikv
+type TiKVValues struct {
+	ComponentValues `json:",inline"`
+	PostArgScript   string `json:"postArgScript,omitempty"`
+}
+
+// TiDBValues is the values of tidb
+type TiDBValues struct {
+	ComponentValues `json:",inline"`
+	Service         *v1alpha1.TiDBServiceSpec `json:"service,omitempty"`
+	SeparateSlowLog *bool                     `json:"separateSlowLog,omitempty"`
+	SlowLogTailer   *SlowLogTailerValues      `json:"slowLogTailer,omitempty"`
+	Plugin          *PluginValues             `json:"plugin,omitempty"`
+	TLSClient       *v1alpha1.TiDBTLSClient   `json:"tlsClient,omitempty"`
+}
+
+// SlowLogTailerValues is the values of the slow log tailer of tidb
+type SlowLogTailerValues struct {
+	ImageValues `json:",inline"`
+	Resources   corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// PluginValues is the values of the tidb plugins
+type PluginValues struct {
+	Enable    bool     `json:"enable,omitempty"`
+	Directory string   `json:"directory,omitempty"`
+	List      []string `json:"list,omitempty"`
+}
+
+// BinlogValues is the values of tidb-binlog
+type BinlogValues struct {
+	Pump    *PumpValues   `json:"pump,omitempty"`
+	Drainer *CreateValues `json:"drainer,omitempty"`
+}
+
+// PumpValues is the values of pump
+type PumpValues struct {
+	ImageValues       `json:",inline"`
+	Create            bool                        `json:"create,omitempty"`
+	Replicas          int32                       `json:"replicas,omitempty"`
+	LogLevel          string                      `json:"logLevel,omitempty"`
+	StorageClassName  string                      `json:"storageClassName,omitempty"`
+	Storage           string                      `json:"storage,omitempty"`
+	Affinity          *corev1.Affinity            `json:"affinity,omitempty"`
+	Tolerations       []corev1.Toleration         `json:"tolerations,omitempty"`
+	SyncLog           *bool                       `json:"syncLog,omitempty"`
+	GC                *int                        `json:"gc,omitempty"`
+	HeartbeatInterval *int                        `json:"heartbeatInterval,omitempty"`
+	Resources         corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// MonitorValues is the values of the monitor
+type MonitorValues struct {
+	Create           bool                `json:"create,omitempty"`
+	Persistent       bool                `json:"persistent,omitempty"`
+	StorageClassName string              `json:"storageClassName,omitempty"`
+	Storage          string              `json:"storage,omitempty"`
+	Initializer      *MonitorInitializer `json:"initializer,omitempty"`
+	Reloader         *MonitorReloader    `json:"reloader,omitempty"`
+	Grafana          *MonitorGrafana     `json:"grafana,omitempty"`
+	Prometheus       *MonitorPrometheus  `json:"prometheus,omitempty"`
+	NodeSelector     map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations      []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// MonitorContainerValues is the values shared by the containers of the monitor
+type MonitorContainerValues struct {
+	ImageValues `json:",inline"`
+	Resources   corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// MonitorInitializer is the values of the monitor initializer
+type MonitorInitializer struct {
+	MonitorContainerValues `json:",inline"`
+	Config                 map[string]string `json:"config,omitempty"`
+}
+
+// MonitorReloader is the values of the monitor reloader
+type MonitorReloader struct {
+	MonitorContainerValues `json:",inline"`
+	Create                 bool                 `json:"create,omitempty"`
+	Service                v1alpha1.ServiceSpec `json:"service,omitempty"`
+}
+
+// MonitorGrafana is the values of grafana
+type MonitorGrafana struct {
+	MonitorContainerValues `json:",inline"`
+	Create                 bool                 `json:"create,omitempty"`
+	LogLevel               string               `json:"logLevel,omitempty"`
+	Username               string               `json:"username,omitempty"`
+	Password               string               `json:"password,omitempty"`
+	Config                 map[string]string    `json:"config,omitempty"`
+	Service                v1alpha1.ServiceSpec `json:"service,omitempty"`
+}
+
+// MonitorPrometheus is the values of prometheus
+type MonitorPrometheus struct {
+	MonitorContainerValues `json:",inline"`
+	LogLevel               string               `json:"logLevel,omitempty"`
+	Service                v1alpha1.ServiceSpec `json:"service,omitempty"`
+	ReserveDays            int                  `json:"reserveDays,omitempty"`
+}
@@ -16,6 +16,9 @@ package cmd
 import (
 	"flag"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/diagnose"
 
@@ -25,6 +28,8 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/get"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/info"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/list"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/migrate"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/status"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/upinfo"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/use"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/version"
@@ -44,7 +49,12 @@ import (
 const (
 	tkcLongDescription = `
 		"tkctl"(TiDB kubernetes control) is a command line interface for cloud tidb management and troubleshooting.
+
+		It can also be installed as a kubectl plugin (e.g. via krew), in which case it is invoked as "kubectl tidb".
 `
+
+	// kubectlPluginBinary is the binary name of tkctl when installed as a kubectl plugin
+	kubectlPluginBinary = "kubectl-tidb"
 )
 
 // NewTkcCommand creates the root `tkc` command and its nested children.
@@ -54,7 +64,7 @@ func NewTkcCommand(streams genericclioptions.IOStreams) *cobra.Command {
 
 	// Root command that all the subcommands are added to
 	rootCmd := &cobra.Command{
-		Use:   "tkctl",
+		Use:   rootCommandName(),
 		Short: "TiDB kubernetes control.",
 		Long:  tkcLongDescription,
 		Run:   runHelp,
@@ -80,7 +90,9 @@ func NewTkcCommand(streams genericclioptions.IOStreams) *cobra.Command {
 				use.NewCmdUse(tkcContext, streams),
 				version.NewCmdVersion(tkcContext, streams.Out),
 				upinfo.NewCmdUpInfo(tkcContext, streams),
+				status.NewCmdStatus(tkcContext, streams),
 				diagnose.NewCmdDiagnoseInfo(tkcContext, streams),
+				migrate.NewCmdMigrate(tkcContext, streams),
 			},
 		},
 		{
@@ -123,3 +135,12 @@ func NewCmdOptions(out io.Writer) *cobra.Command {
 	templates.UseOptionsTemplates(cmd)
 	return cmd
 }
+
+// rootCommandName returns the name shown in usage messages, which is
+// "kubectl tidb" when tkctl is invoked as a kubectl plugin
+func rootCommandName() string {
+	if strings.HasPrefix(filepath.Base(os.Args[0]), kubectlPluginBinary) {
+		return "kubectl tidb"
+	}
+	return "tkctl"
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pingcap/tidb-operator/pkg/tkctl/chart"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	migrateLongDesc = `
+		Convert the values.yaml of a tidb cluster deployed by the legacy tidb-cluster chart
+		into the equivalent TidbCluster and TidbMonitor.
+
+		The name of the cluster defaults to the clusterName in the values, which must be
+		specified by --tidbcluster if clusterName is not set. Settings which can not be
+		converted are printed to stderr and should be migrated manually.
+`
+	migrateExample = `
+		# convert the values of a release and print the resources
+		tkctl migrate -f values.yaml -t demo -n tidb
+
+		# convert the values of a release and apply the resources
+		tkctl migrate -f values.yaml -t demo -n tidb | kubectl apply -f -
+`
+)
+
+// MigrateOptions contains the input to the migrate command.
+type MigrateOptions struct {
+	valuesFile      string
+	namespace       string
+	tidbClusterName string
+
+	genericclioptions.IOStreams
+}
+
+// NewMigrateOptions returns a MigrateOptions.
+func NewMigrateOptions(streams genericclioptions.IOStreams) *MigrateOptions {
+	return &MigrateOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdMigrate creates the migrate command which converts the values of the tidb-cluster chart into resources.
+func NewCmdMigrate(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewMigrateOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "migrate",
+		Short:   "Convert the values of the tidb-cluster chart into TidbCluster and TidbMonitor",
+		Long:    migrateLongDesc,
+		Example: migrateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.valuesFile, "filename", "f", "", "The values.yaml of the tidb-cluster chart.")
+	cmdutil.CheckErr(cmd.MarkFlagRequired("filename"))
+	return cmd
+}
+
+// Complete populates default values from the environment.
+func (o *MigrateOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.tidbClusterName = tidbClusterName
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.namespace = namespace
+	return nil
+}
+
+// Run converts the values and prints the resources.
+func (o *MigrateOptions) Run() error {
+	data, err := ioutil.ReadFile(o.valuesFile)
+	if err != nil {
+		return err
+	}
+	values, err := chart.ParseValues(data)
+	if err != nil {
+		return err
+	}
+	result, err := chart.Convert(values, o.tidbClusterName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	objs := []runtime.Object{result.TidbCluster}
+	if result.TidbMonitor != nil {
+		objs = append(objs, result.TidbMonitor)
+	}
+	printer := &printers.YAMLPrinter{}
+	for _, obj := range objs {
+		u, err := toUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		if err := printer.PrintObj(u, o.Out); err != nil {
+			return err
+		}
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(o.ErrOut, "WARN: %s\n", warning)
+	}
+	return nil
+}
+
+// toUnstructured drops the empty status and creation timestamp which are not expected in the manifests
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	unstructured.RemoveNestedField(u.Object, "status")
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	return u, nil
+}
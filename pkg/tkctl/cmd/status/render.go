@@ -0,0 +1,331 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/readable"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	noneValue = "<none>"
+)
+
+// componentSummary is a row of the overview table
+type componentSummary struct {
+	name        string
+	phase       v1alpha1.MemberPhase
+	desired     int32
+	statefulSet *apps.StatefulSetStatus
+	image       string
+}
+
+// failureMember is a row of the failure members table
+type failureMember struct {
+	component string
+	podName   string
+	id        string
+	createdAt metav1.Time
+}
+
+// go template is lacking type checking and hard to maintain, in this
+// case we just render manually
+func renderTidbClusterStatus(tc *v1alpha1.TidbCluster) (string, error) {
+	var components []componentSummary
+	if tc.Spec.PD != nil {
+		components = append(components, componentSummary{"PD", tc.Status.PD.Phase, tc.Spec.PD.Replicas, tc.Status.PD.StatefulSet, tc.Status.PD.Image})
+	}
+	if tc.Spec.TiKV != nil {
+		components = append(components, componentSummary{"TiKV", tc.Status.TiKV.Phase, tc.Spec.TiKV.Replicas, tc.Status.TiKV.StatefulSet, tc.Status.TiKV.Image})
+	}
+	if tc.Spec.TiFlash != nil {
+		components = append(components, componentSummary{"TiFlash", tc.Status.TiFlash.Phase, tc.Spec.TiFlash.Replicas, tc.Status.TiFlash.StatefulSet, tc.Status.TiFlash.Image})
+	}
+	if tc.Spec.TiDB != nil {
+		components = append(components, componentSummary{"TiDB", tc.Status.TiDB.Phase, tc.Spec.TiDB.Replicas, tc.Status.TiDB.StatefulSet, tc.Status.TiDB.Image})
+	}
+	if tc.Spec.TiCDC != nil {
+		components = append(components, componentSummary{"TiCDC", tc.Status.TiCDC.Phase, tc.Spec.TiCDC.Replicas, tc.Status.TiCDC.StatefulSet, ""})
+	}
+	if tc.Spec.Pump != nil {
+		components = append(components, componentSummary{"Pump", tc.Status.Pump.Phase, tc.Spec.Pump.Replicas, tc.Status.Pump.StatefulSet, ""})
+	}
+
+	var failures []failureMember
+	for _, m := range tc.Status.PD.FailureMembers {
+		failures = append(failures, failureMember{"PD", m.PodName, m.MemberID, m.CreatedAt})
+	}
+	for _, s := range tc.Status.TiKV.FailureStores {
+		failures = append(failures, failureMember{"TiKV", s.PodName, s.StoreID, s.CreatedAt})
+	}
+	for _, s := range tc.Status.TiFlash.FailureStores {
+		failures = append(failures, failureMember{"TiFlash", s.PodName, s.StoreID, s.CreatedAt})
+	}
+	for _, m := range tc.Status.TiDB.FailureMembers {
+		failures = append(failures, failureMember{"TiDB", m.PodName, "", m.CreatedAt})
+	}
+
+	return readable.TabbedString(func(out io.Writer) error {
+		w := readable.NewPrefixWriter(out)
+		w.WriteLine(readable.LEVEL_0, "Name:\t%s", tc.Name)
+		w.WriteLine(readable.LEVEL_0, "Namespace:\t%s", tc.Namespace)
+		w.WriteLine(readable.LEVEL_0, "CreationTimestamp:\t%s", tc.CreationTimestamp)
+		w.WriteLine(readable.LEVEL_0, "Version:\t%s", valueOrNone(tc.Spec.Version))
+		w.WriteLine(readable.LEVEL_0, "Paused:\t%t", tc.Spec.Paused)
+		renderOverview(w, components)
+		renderTidbClusterMembers(w, tc)
+		renderFailureMembers(w, failures)
+		w.WriteLine(readable.LEVEL_0, "Conditions:")
+		if len(tc.Status.Conditions) == 0 {
+			w.WriteLine(readable.LEVEL_1, noneValue)
+		} else {
+			w.WriteLine(readable.LEVEL_1, "Type\tStatus\tLastTransitionTime\tReason\tMessage")
+			w.WriteLine(readable.LEVEL_1, "----\t------\t------------------\t------\t-------")
+			for _, c := range tc.Status.Conditions {
+				w.WriteLine(readable.LEVEL_1, "%s\t%s\t%s\t%s\t%s", c.Type, c.Status, c.LastTransitionTime, c.Reason, c.Message)
+			}
+		}
+		return nil
+	})
+}
+
+func renderDMClusterStatus(dc *v1alpha1.DMCluster) (string, error) {
+	components := []componentSummary{
+		{"Master", dc.Status.Master.Phase, dc.Spec.Master.Replicas, dc.Status.Master.StatefulSet, dc.Status.Master.Image},
+	}
+	if dc.Spec.Worker != nil {
+		components = append(components, componentSummary{"Worker", dc.Status.Worker.Phase, dc.Spec.Worker.Replicas, dc.Status.Worker.StatefulSet, dc.Status.Worker.Image})
+	}
+
+	var failures []failureMember
+	for _, m := range dc.Status.Master.FailureMembers {
+		failures = append(failures, failureMember{"Master", m.PodName, m.MemberID, m.CreatedAt})
+	}
+	for _, m := range dc.Status.Worker.FailureMembers {
+		failures = append(failures, failureMember{"Worker", m.PodName, "", m.CreatedAt})
+	}
+
+	return readable.TabbedString(func(out io.Writer) error {
+		w := readable.NewPrefixWriter(out)
+		w.WriteLine(readable.LEVEL_0, "Name:\t%s", dc.Name)
+		w.WriteLine(readable.LEVEL_0, "Namespace:\t%s", dc.Namespace)
+		w.WriteLine(readable.LEVEL_0, "CreationTimestamp:\t%s", dc.CreationTimestamp)
+		w.WriteLine(readable.LEVEL_0, "Version:\t%s", valueOrNone(dc.Spec.Version))
+		w.WriteLine(readable.LEVEL_0, "Paused:\t%t", dc.Spec.Paused)
+		renderOverview(w, components)
+		w.WriteLine(readable.LEVEL_0, "Members:")
+		{
+			w.WriteLine(readable.LEVEL_1, "Component\tName\tID/Address\tHealth/Stage\tLastTransitionTime")
+			w.WriteLine(readable.LEVEL_1, "---------\t----\t----------\t------------\t------------------")
+			for _, name := range sortedKeys(len(dc.Status.Master.Members), func(keys *[]string) {
+				for k := range dc.Status.Master.Members {
+					*keys = append(*keys, k)
+				}
+			}) {
+				m := dc.Status.Master.Members[name]
+				health := healthString(m.Health)
+				if m.Name == dc.Status.Master.Leader.Name {
+					health += " (leader)"
+				}
+				w.WriteLine(readable.LEVEL_1, "Master\t%s\t%s\t%s\t%s", m.Name, m.ID, health, m.LastTransitionTime)
+			}
+			for _, name := range sortedKeys(len(dc.Status.Worker.Members), func(keys *[]string) {
+				for k := range dc.Status.Worker.Members {
+					*keys = append(*keys, k)
+				}
+			}) {
+				m := dc.Status.Worker.Members[name]
+				w.WriteLine(readable.LEVEL_1, "Worker\t%s\t%s\t%s\t%s", m.Name, m.Addr, m.Stage, m.LastTransitionTime)
+			}
+		}
+		renderFailureMembers(w, failures)
+		w.WriteLine(readable.LEVEL_0, "Conditions:")
+		if len(dc.Status.Conditions) == 0 {
+			w.WriteLine(readable.LEVEL_1, noneValue)
+		} else {
+			w.WriteLine(readable.LEVEL_1, "Type\tStatus\tLastTransitionTime\tReason\tMessage")
+			w.WriteLine(readable.LEVEL_1, "----\t------\t------------------\t------\t-------")
+			for _, c := range dc.Status.Conditions {
+				w.WriteLine(readable.LEVEL_1, "%s\t%s\t%s\t%s\t%s", c.Type, c.Status, c.LastTransitionTime, c.Reason, c.Message)
+			}
+		}
+		return nil
+	})
+}
+
+func renderBackupStatus(backup *v1alpha1.Backup) (string, error) {
+	return readable.TabbedString(func(out io.Writer) error {
+		w := readable.NewPrefixWriter(out)
+		w.WriteLine(readable.LEVEL_0, "Name:\t%s", backup.Name)
+		w.WriteLine(readable.LEVEL_0, "Namespace:\t%s", backup.Namespace)
+		w.WriteLine(readable.LEVEL_0, "CreationTimestamp:\t%s", backup.CreationTimestamp)
+		if backup.Spec.BR != nil {
+			w.WriteLine(readable.LEVEL_0, "Cluster:\t%s", backup.Spec.BR.Cluster)
+			w.WriteLine(readable.LEVEL_0, "Tool:\tbr")
+		} else {
+			w.WriteLine(readable.LEVEL_0, "Tool:\tdumpling")
+		}
+		w.WriteLine(readable.LEVEL_0, "Type:\t%s", valueOrNone(string(backup.Spec.Type)))
+		w.WriteLine(readable.LEVEL_0, "Phase:\t%s", valueOrNone(string(backup.Status.Phase)))
+		w.WriteLine(readable.LEVEL_0, "BackupPath:\t%s", valueOrNone(backup.Status.BackupPath))
+		w.WriteLine(readable.LEVEL_0, "BackupSize:\t%s", valueOrNone(backup.Status.BackupSizeReadable))
+		w.WriteLine(readable.LEVEL_0, "CommitTS:\t%s", valueOrNone(backup.Status.CommitTs))
+		w.WriteLine(readable.LEVEL_0, "Started:\t%s", timeOrNone(backup.Status.TimeStarted))
+		w.WriteLine(readable.LEVEL_0, "Completed:\t%s", timeOrNone(backup.Status.TimeCompleted))
+		if !backup.Status.TimeStarted.IsZero() && !backup.Status.TimeCompleted.IsZero() {
+			w.WriteLine(readable.LEVEL_0, "Duration:\t%s", backup.Status.TimeCompleted.Sub(backup.Status.TimeStarted.Time))
+		}
+		w.WriteLine(readable.LEVEL_0, "Conditions:")
+		if len(backup.Status.Conditions) == 0 {
+			w.WriteLine(readable.LEVEL_1, noneValue)
+		} else {
+			w.WriteLine(readable.LEVEL_1, "Type\tStatus\tLastTransitionTime\tReason\tMessage")
+			w.WriteLine(readable.LEVEL_1, "----\t------\t------------------\t------\t-------")
+			for _, c := range backup.Status.Conditions {
+				w.WriteLine(readable.LEVEL_1, "%s\t%s\t%s\t%s\t%s", c.Type, c.Status, c.LastTransitionTime, c.Reason, c.Message)
+			}
+		}
+		return nil
+	})
+}
+
+func renderOverview(w readable.PrefixWriter, components []componentSummary) {
+	w.WriteLine(readable.LEVEL_0, "Overview:")
+	w.WriteLine(readable.LEVEL_1, "\tPhase\tReady\tDesired\tUpgrade\tImage")
+	w.WriteLine(readable.LEVEL_1, "\t-----\t-----\t-------\t-------\t-----")
+	for _, c := range components {
+		var ready int32
+		upgrade := noneValue
+		if c.statefulSet != nil {
+			ready = c.statefulSet.ReadyReplicas
+			if c.statefulSet.CurrentRevision != c.statefulSet.UpdateRevision {
+				upgrade = fmt.Sprintf("%d/%d updated", c.statefulSet.UpdatedReplicas, c.statefulSet.Replicas)
+			}
+		}
+		w.WriteLine(readable.LEVEL_1, "%s:\t%s\t%d\t%d\t%s\t%s", c.name, valueOrNone(string(c.phase)), ready, c.desired, upgrade, valueOrNone(c.image))
+	}
+}
+
+func renderTidbClusterMembers(w readable.PrefixWriter, tc *v1alpha1.TidbCluster) {
+	w.WriteLine(readable.LEVEL_0, "Members:")
+	w.WriteLine(readable.LEVEL_1, "Component\tName\tID\tHealth/State\tLeaders\tLastTransitionTime")
+	w.WriteLine(readable.LEVEL_1, "---------\t----\t--\t------------\t-------\t------------------")
+	for _, name := range sortedKeys(len(tc.Status.PD.Members), func(keys *[]string) {
+		for k := range tc.Status.PD.Members {
+			*keys = append(*keys, k)
+		}
+	}) {
+		m := tc.Status.PD.Members[name]
+		health := healthString(m.Health)
+		if m.Name == tc.Status.PD.Leader.Name {
+			health += " (leader)"
+		}
+		w.WriteLine(readable.LEVEL_1, "PD\t%s\t%s\t%s\t-\t%s", m.Name, m.ID, health, m.LastTransitionTime)
+	}
+	for _, id := range sortedKeys(len(tc.Status.TiKV.Stores), func(keys *[]string) {
+		for k := range tc.Status.TiKV.Stores {
+			*keys = append(*keys, k)
+		}
+	}) {
+		s := tc.Status.TiKV.Stores[id]
+		w.WriteLine(readable.LEVEL_1, "TiKV\t%s\t%s\t%s\t%d\t%s", s.PodName, s.ID, s.State, s.LeaderCount, s.LastTransitionTime)
+	}
+	for _, id := range sortedKeys(len(tc.Status.TiFlash.Stores), func(keys *[]string) {
+		for k := range tc.Status.TiFlash.Stores {
+			*keys = append(*keys, k)
+		}
+	}) {
+		s := tc.Status.TiFlash.Stores[id]
+		w.WriteLine(readable.LEVEL_1, "TiFlash\t%s\t%s\t%s\t%d\t%s", s.PodName, s.ID, s.State, s.LeaderCount, s.LastTransitionTime)
+	}
+	for _, name := range sortedKeys(len(tc.Status.TiDB.Members), func(keys *[]string) {
+		for k := range tc.Status.TiDB.Members {
+			*keys = append(*keys, k)
+		}
+	}) {
+		m := tc.Status.TiDB.Members[name]
+		w.WriteLine(readable.LEVEL_1, "TiDB\t%s\t-\t%s\t-\t%s", m.Name, healthString(m.Health), m.LastTransitionTime)
+	}
+	for _, name := range sortedKeys(len(tc.Status.TiCDC.Captures), func(keys *[]string) {
+		for k := range tc.Status.TiCDC.Captures {
+			*keys = append(*keys, k)
+		}
+	}) {
+		c := tc.Status.TiCDC.Captures[name]
+		state := "NotReady"
+		if c.Ready {
+			state = "Ready"
+		}
+		if c.IsOwner {
+			state += " (owner)"
+		}
+		w.WriteLine(readable.LEVEL_1, "TiCDC\t%s\t%s\t%s\t-\t-", c.PodName, c.ID, state)
+	}
+	for _, m := range tc.Status.Pump.Members {
+		w.WriteLine(readable.LEVEL_1, "Pump\t%s\t%s\t%s\t-\t-", m.Host, m.NodeID, m.State)
+	}
+}
+
+func renderFailureMembers(w readable.PrefixWriter, failures []failureMember) {
+	w.WriteLine(readable.LEVEL_0, "FailureMembers:")
+	if len(failures) == 0 {
+		w.WriteLine(readable.LEVEL_1, noneValue)
+		return
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].component != failures[j].component {
+			return failures[i].component < failures[j].component
+		}
+		return failures[i].podName < failures[j].podName
+	})
+	w.WriteLine(readable.LEVEL_1, "Component\tPod\tID\tCreatedAt")
+	w.WriteLine(readable.LEVEL_1, "---------\t---\t--\t---------")
+	for _, f := range failures {
+		w.WriteLine(readable.LEVEL_1, "%s\t%s\t%s\t%s", f.component, f.podName, valueOrNone(f.id), f.createdAt)
+	}
+}
+
+func sortedKeys(n int, collect func(keys *[]string)) []string {
+	keys := make([]string, 0, n)
+	collect(&keys)
+	sort.Strings(keys)
+	return keys
+}
+
+func healthString(health bool) string {
+	if health {
+		return "Healthy"
+	}
+	return "Unhealthy"
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return noneValue
+	}
+	return s
+}
+
+func timeOrNone(t metav1.Time) string {
+	if t.IsZero() {
+		return noneValue
+	}
+	return t.String()
+}
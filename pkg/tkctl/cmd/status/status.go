@@ -0,0 +1,190 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	statusLongDesc = `
+		Show a human-friendly summary of the status of a TidbCluster, DMCluster or Backup.
+
+		Available kinds include: tidbcluster (tc), dmcluster (dc), backup (bk).
+		The kind defaults to tidbcluster, and you can omit the name of the tidbcluster
+		by running 'tkctl use <clusterName>' or passing --tidbcluster=<name>.
+`
+	statusExample = `
+		# show the status summary of the current tidb cluster (set by tkctl use)
+		tkctl status
+
+		# show the status summary of a specified tidb cluster
+		tkctl status tc another-cluster
+
+		# show the status summary of a dm cluster
+		tkctl status dc basic-dm
+
+		# show the status summary of a backup
+		tkctl status bk demo-backup
+`
+	statusUsage = `expected 'status [KIND] [NAME]' for the status command, or
+using 'tkctl use' to set tidb cluster first.
+`
+)
+
+const (
+	kindTidbCluster = "tidbcluster"
+	kindDMCluster   = "dmcluster"
+	kindBackup      = "backup"
+)
+
+var kindAliases = map[string]string{
+	"tidbcluster":  kindTidbCluster,
+	"tidbclusters": kindTidbCluster,
+	"tc":           kindTidbCluster,
+	"dmcluster":    kindDMCluster,
+	"dmclusters":   kindDMCluster,
+	"dc":           kindDMCluster,
+	"backup":       kindBackup,
+	"backups":      kindBackup,
+	"bk":           kindBackup,
+}
+
+// StatusOptions contains the input to the status command.
+type StatusOptions struct {
+	Kind      string
+	Name      string
+	Namespace string
+
+	TcCli *versioned.Clientset
+
+	genericclioptions.IOStreams
+}
+
+// NewStatusOptions returns a StatusOptions
+func NewStatusOptions(streams genericclioptions.IOStreams) *StatusOptions {
+	return &StatusOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdStatus creates the status command which summarizes the status of the custom resources
+func NewCmdStatus(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewStatusOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "status [KIND] [NAME]",
+		Short:   "Show status summary of tidbcluster|dmcluster|backup.",
+		Long:    statusLongDesc,
+		Example: statusExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		SuggestFor: []string{"describe", "summary"},
+	}
+
+	return cmd
+}
+
+func (o *StatusOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	if len(args) > 2 {
+		return cmdutil.UsageErrorf(cmd, statusUsage)
+	}
+
+	o.Kind = kindTidbCluster
+	if len(args) > 0 {
+		kind, ok := kindAliases[args[0]]
+		if !ok {
+			return cmdutil.UsageErrorf(cmd, "unknown kind %q, %s", args[0], statusUsage)
+		}
+		o.Kind = kind
+	}
+	if len(args) > 1 {
+		o.Name = args[1]
+	}
+
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.Name == "" {
+		tidbClusterName, ok := clientConfig.TidbClusterName()
+		if o.Kind != kindTidbCluster || !ok {
+			return cmdutil.UsageErrorf(cmd, statusUsage)
+		}
+		o.Name = tidbClusterName
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+
+	return nil
+}
+
+func (o *StatusOptions) Run() error {
+	var msg string
+	switch o.Kind {
+	case kindTidbCluster:
+		tc, err := o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).Get(context.TODO(), o.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if msg, err = renderTidbClusterStatus(tc); err != nil {
+			return err
+		}
+	case kindDMCluster:
+		dc, err := o.TcCli.PingcapV1alpha1().DMClusters(o.Namespace).Get(context.TODO(), o.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if msg, err = renderDMClusterStatus(dc); err != nil {
+			return err
+		}
+	case kindBackup:
+		backup, err := o.TcCli.PingcapV1alpha1().Backups(o.Namespace).Get(context.TODO(), o.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if msg, err = renderBackupStatus(backup); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported kind %q", o.Kind)
+	}
+	fmt.Fprint(o.Out, msg)
+	return nil
+}
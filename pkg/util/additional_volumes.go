@@ -0,0 +1,130 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+)
+
+// ValidateAdditionalVolumes checks that the additional volumes and volume mounts of a Job don't conflict with
+// the volumes and volume mounts built by the operator, which would be overridden or make the Job invalid
+func ValidateAdditionalVolumes(volumes []corev1.Volume, mounts []corev1.VolumeMount, additionalVolumes []corev1.Volume, additionalMounts []corev1.VolumeMount) error {
+	names := map[string]struct{}{}
+	for _, v := range volumes {
+		names[v.Name] = struct{}{}
+	}
+	for _, v := range additionalVolumes {
+		if _, ok := names[v.Name]; ok {
+			return fmt.Errorf("additional volume %s conflicts with the volumes built by the operator", v.Name)
+		}
+		names[v.Name] = struct{}{}
+	}
+	paths := map[string]struct{}{}
+	for _, m := range mounts {
+		paths[m.MountPath] = struct{}{}
+	}
+	for _, m := range additionalMounts {
+		if _, ok := paths[m.MountPath]; ok {
+			return fmt.Errorf("mount path %s of additional volume mount %s conflicts with the volume mounts built by the operator", m.MountPath, m.Name)
+		}
+		paths[m.MountPath] = struct{}{}
+	}
+	return nil
+}
+
+// AdditionalVolumesHash returns the hash of the data of the ConfigMaps and Secrets referenced by the additional
+// volumes, so that the changes of them can be detected. It returns an empty string if no ConfigMap or Secret is
+// referenced, and the ConfigMaps and Secrets not found are hashed as empty ones.
+func AdditionalVolumesHash(cmLister corelisterv1.ConfigMapLister, secretLister corelisterv1.SecretLister, ns string, volumes []corev1.Volume) (string, error) {
+	var configMaps, secrets []string
+	for _, v := range volumes {
+		switch {
+		case v.ConfigMap != nil:
+			configMaps = append(configMaps, v.ConfigMap.Name)
+		case v.Secret != nil:
+			secrets = append(secrets, v.Secret.SecretName)
+		case v.Projected != nil:
+			for _, source := range v.Projected.Sources {
+				if source.ConfigMap != nil {
+					configMaps = append(configMaps, source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					secrets = append(secrets, source.Secret.Name)
+				}
+			}
+		}
+	}
+	if len(configMaps) == 0 && len(secrets) == 0 {
+		return "", nil
+	}
+	sort.Strings(configMaps)
+	sort.Strings(secrets)
+
+	h := sha256.New()
+	for _, name := range configMaps {
+		cm, err := cmLister.ConfigMaps(ns).Get(name)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", fmt.Errorf("failed to get configmap %s/%s, error: %v", ns, name, err)
+		}
+		fmt.Fprintf(h, "configmap/%s;", name)
+		if cm == nil {
+			continue
+		}
+		for _, k := range sortedKeys(cm.Data) {
+			fmt.Fprintf(h, "%s=%x;", k, cm.Data[k])
+		}
+		binaryKeys := make([]string, 0, len(cm.BinaryData))
+		for k := range cm.BinaryData {
+			binaryKeys = append(binaryKeys, k)
+		}
+		sort.Strings(binaryKeys)
+		for _, k := range binaryKeys {
+			fmt.Fprintf(h, "%s=%x;", k, cm.BinaryData[k])
+		}
+	}
+	for _, name := range secrets {
+		secret, err := secretLister.Secrets(ns).Get(name)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", fmt.Errorf("failed to get secret %s/%s, error: %v", ns, name, err)
+		}
+		fmt.Fprintf(h, "secret/%s;", name)
+		if secret == nil {
+			continue
+		}
+		keys := make([]string, 0, len(secret.Data))
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(h, "%s=%x;", k, secret.Data[k])
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:16], nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
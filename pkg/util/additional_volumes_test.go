@@ -0,0 +1,83 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateAdditionalVolumes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	volumes := []corev1.Volume{{Name: "br-bin"}}
+	mounts := []corev1.VolumeMount{{Name: "br-bin", MountPath: "/var/lib/br-bin"}}
+
+	g.Expect(ValidateAdditionalVolumes(volumes, mounts,
+		[]corev1.Volume{{Name: "ca-bundle"}}, []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/custom"}})).To(Succeed())
+	g.Expect(ValidateAdditionalVolumes(volumes, mounts,
+		[]corev1.Volume{{Name: "br-bin"}}, nil)).To(MatchError(ContainSubstring("additional volume br-bin conflicts")))
+	g.Expect(ValidateAdditionalVolumes(volumes, mounts,
+		[]corev1.Volume{{Name: "a"}, {Name: "a"}}, nil)).To(HaveOccurred())
+	g.Expect(ValidateAdditionalVolumes(volumes, mounts,
+		[]corev1.Volume{{Name: "data"}}, []corev1.VolumeMount{{Name: "data", MountPath: "/var/lib/br-bin"}})).To(MatchError(ContainSubstring("mount path /var/lib/br-bin")))
+}
+
+func TestAdditionalVolumesHash(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	informerFactory := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	cmIndexer := informerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	secretIndexer := informerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	cmLister := informerFactory.Core().V1().ConfigMaps().Lister()
+	secretLister := informerFactory.Core().V1().Secrets().Lister()
+
+	hash, err := AdditionalVolumesHash(cmLister, secretLister, "ns", []corev1.Volume{
+		{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(hash).To(BeEmpty())
+
+	volumes := []corev1.Volume{
+		{Name: "ca-bundle", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"}}}},
+		{Name: "creds", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+			{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}}},
+		}}}},
+	}
+	// the ConfigMaps and Secrets not found are hashed as empty ones
+	missing, err := AdditionalVolumesHash(cmLister, secretLister, "ns", volumes)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(missing).NotTo(BeEmpty())
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "ns"}, Data: map[string]string{"ca.crt": "a"}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "ns"}, Data: map[string][]byte{"token": []byte("a")}}
+	g.Expect(cmIndexer.Add(cm)).To(Succeed())
+	g.Expect(secretIndexer.Add(secret)).To(Succeed())
+	hash, err = AdditionalVolumesHash(cmLister, secretLister, "ns", volumes)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(hash).NotTo(Equal(missing))
+
+	// changed once the data of the Secret is updated
+	secret = secret.DeepCopy()
+	secret.Data["token"] = []byte("b")
+	g.Expect(secretIndexer.Update(secret)).To(Succeed())
+	updated, err := AdditionalVolumesHash(cmLister, secretLister, "ns", volumes)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated).NotTo(Equal(hash))
+}
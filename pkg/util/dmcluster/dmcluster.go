@@ -28,6 +28,8 @@ const (
 	StatfulSetNotUpToDate = "StatefulSetNotUpToDate"
 	// MasterUnhealthy is added when one of dm-master members is unhealthy.
 	MasterUnhealthy = "DMMasterUnhealthy"
+	// Suspended is added when the dm cluster is suspended.
+	Suspended = "Suspended"
 )
 
 // NewDMClusterCondition creates a new dmcluster condition.
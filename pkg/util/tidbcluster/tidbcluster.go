@@ -34,6 +34,46 @@ const (
 	TiDBUnhealthy = "TiDBUnhealthy"
 	// TiFlashStoreNotUp is added when one of tiflash stores is not up.
 	TiFlashStoreNotUp = "TiFlashStoreNotUp"
+	// TiCDCCaptureNotReady is added when one of ticdc captures is not ready.
+	TiCDCCaptureNotReady = "TiCDCCaptureNotReady"
+	// TiProxyUnhealthy is added when one of tiproxy members is unhealthy.
+	TiProxyUnhealthy = "TiProxyUnhealthy"
+	// PumpNotOnline is added when one of pump members is not online.
+	PumpNotOnline = "PumpNotOnline"
+	// ComponentUpgrading is added when one of components is being upgraded.
+	ComponentUpgrading = "ComponentUpgrading"
+	// ConfigDrifted is added when the live config of some instances differs from the desired config.
+	ConfigDrifted = "ConfigDrifted"
+	// ConfigInSync is added when the live config of all instances matches the desired config.
+	ConfigInSync = "ConfigInSync"
+	// InsufficientResources is added when the resources to scale out some components are insufficient.
+	InsufficientResources = "InsufficientResources"
+	// TimezoneChanged is added when the time zone of some components is changed.
+	TimezoneChanged = "TimezoneChanged"
+	// ClockSkewed is added when the clocks of some nodes are skewed.
+	ClockSkewed = "ClockSkewed"
+	// ClockInSync is added when the clocks of all nodes are in sync.
+	ClockInSync = "ClockInSync"
+	// TLSInvalid is added when some cluster TLS Secrets are invalid.
+	TLSInvalid = "TLSInvalid"
+	// TLSValid is added when all the cluster TLS Secrets are valid.
+	TLSValid = "TLSValid"
+	// TenantPolicyViolated is added when the cluster violates the policy of its tenant.
+	TenantPolicyViolated = "TenantPolicyViolated"
+	// TenantTiKVReplicasExceeded is added when the cluster only violates the max TiKV replicas of its tenant.
+	TenantTiKVReplicasExceeded = "TenantTiKVReplicasExceeded"
+	// TenantPolicySatisfied is added when the cluster satisfies the policy of its tenant.
+	TenantPolicySatisfied = "TenantPolicySatisfied"
+	// ReadOnlyEnforced is added when all the TiDB instances are confirmed to be read-only.
+	ReadOnlyEnforced = "ReadOnlyEnforced"
+	// ReadOnlyDisabled is added when all the TiDB instances are confirmed to be writable again.
+	ReadOnlyDisabled = "ReadOnlyDisabled"
+	// VersionSkewed is added when the versions of some instances are skewed beyond the supported skew.
+	VersionSkewed = "VersionSkewed"
+	// VersionSkewTolerated is added when the versions of some instances are skewed within the grace period.
+	VersionSkewTolerated = "VersionSkewTolerated"
+	// VersionsInSync is added when the versions of all instances are within the supported skew.
+	VersionsInSync = "VersionsInSync"
 )
 
 // NewTidbClusterCondition creates a new tidbcluster condition.
@@ -60,10 +100,10 @@ func GetTidbClusterCondition(status v1alpha1.TidbClusterStatus, condType v1alpha
 }
 
 // SetTidbClusterCondition updates the tidb cluster to include the provided condition. If the condition that
-// we are about to add already exists and has the same status and reason then we are not going to update.
+// we are about to add already exists and has the same status, reason and message then we are not going to update.
 func SetTidbClusterCondition(status *v1alpha1.TidbClusterStatus, condition v1alpha1.TidbClusterCondition) {
 	currentCond := GetTidbClusterCondition(*status, condition.Type)
-	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason {
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
 		return
 	}
 	// Do not update lastTransitionTime if the status of the condition doesn't change.
@@ -74,6 +114,14 @@ func SetTidbClusterCondition(status *v1alpha1.TidbClusterStatus, condition v1alp
 	status.Conditions = append(newConditions, condition)
 }
 
+// RemoveTidbClusterCondition removes the condition with the provided type from the tidb cluster status.
+func RemoveTidbClusterCondition(status *v1alpha1.TidbClusterStatus, condType v1alpha1.TidbClusterConditionType) {
+	if GetTidbClusterCondition(*status, condType) == nil {
+		return
+	}
+	status.Conditions = filterOutCondition(status.Conditions, condType)
+}
+
 // filterOutCondition returns a new slice of tidbcluster conditions without conditions with the provided type.
 func filterOutCondition(conditions []v1alpha1.TidbClusterCondition, condType v1alpha1.TidbClusterConditionType) []v1alpha1.TidbClusterCondition {
 	var newConditions []v1alpha1.TidbClusterCondition
@@ -44,7 +44,7 @@ func TestCondition(t *testing.T) {
 
 	// test SetTidbClusterCondition
 
-	//  we are about to add already exists and has the same status and reason then we are not going to update
+	//  we are about to add already exists and has the same status, reason and message then we are not going to update
 	SetTidbClusterCondition(&status, *c)
 	g.Expect(len(status.Conditions)).Should(Equal(1))
 	getc = GetTidbClusterCondition(status, v1alpha1.TidbClusterReady)
@@ -60,6 +60,7 @@ func TestCondition(t *testing.T) {
 	g.Expect(getc.LastTransitionTime).Should(Equal(c.LastTransitionTime))
 	g.Expect(getc.LastTransitionTime).ShouldNot(Equal(c2.LastTransitionTime))
 	g.Expect(getc.Reason).Should(Equal(c2.Reason))
+	g.Expect(getc.Message).Should(Equal(c2.Message))
 
 	// status change from True -> False
 	c3 := NewTidbClusterCondition(v1alpha1.TidbClusterReady, v1.ConditionFalse, StatfulSetNotUpToDate, "reason3")
@@ -70,4 +71,10 @@ func TestCondition(t *testing.T) {
 	// test GetTidbClusterReadyCondition
 	getc = GetTidbClusterReadyCondition(status)
 	g.Expect(getc).Should(Equal(c3))
+
+	// test RemoveTidbClusterCondition
+	RemoveTidbClusterCondition(&status, v1alpha1.TidbClusterConditionType("not exist"))
+	g.Expect(len(status.Conditions)).Should(Equal(1))
+	RemoveTidbClusterCondition(&status, v1alpha1.TidbClusterReady)
+	g.Expect(GetTidbClusterCondition(status, v1alpha1.TidbClusterReady)).Should(BeNil())
 }
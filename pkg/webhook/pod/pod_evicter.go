@@ -0,0 +1,237 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/webhook/util"
+	admission "k8s.io/api/admission/v1beta1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	evictionSubResource    = "eviction"
+	nodeUpgradeEvictReason = "NodeUpgradeEvict"
+)
+
+// evictionOrder is the order in which the Pods on the same node are evicted in the node upgrade
+// maintenance mode, the stateless components go first and PD goes last
+var evictionOrder = map[string]int{
+	label.TiDBLabelVal:    0,
+	label.TiCDCLabelVal:   1,
+	label.PumpLabelVal:    2,
+	label.TiFlashLabelVal: 3,
+	label.TiKVLabelVal:    4,
+	label.PDLabelVal:      5,
+}
+
+// Webhook server receive request to evict pod
+// if the tidbcluster which the pod belongs to is not in the node upgrade maintenance mode, we let the request
+// pass and leave it to the PodDisruptionBudgets. Otherwise, the pods are evicted node by node and in the order
+// of the components, and the region leaders and the pd leader are transferred before the pod gets evicted.
+// The evictions are rejected with 429 in the meantime, so that `kubectl drain` keeps retrying.
+func (pc *PodAdmissionControl) admitEvictPods(name, namespace string) *admission.AdmissionResponse {
+	klog.Infof("receive admission to %s pod[%s/%s]", "evict", namespace, name)
+
+	pod, err := pc.kubeCli.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		klog.Infof("failed to find pod[%s/%s] during evicting it,admit to evict", namespace, name)
+		return util.ARSuccess()
+	}
+
+	l := label.Label(pod.Labels)
+	if !l.IsManagedByTiDBOperator() || !l.IsTidbClusterPod() {
+		return util.ARSuccess()
+	}
+
+	tcName := l[label.InstanceLabelKey]
+	tc, err := pc.tcLister.TidbClusters(namespace).Get(tcName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.Infof("tc[%s/%s] had been deleted,admit to evict pod[%s/%s]", namespace, tcName, namespace, name)
+			return util.ARSuccess()
+		}
+		klog.Errorf("failed get tc[%s/%s],refuse to evict pod[%s/%s]", namespace, tcName, namespace, name)
+		return util.ARFail(err)
+	}
+	if !tc.IsNodeUpgradeMaintenance() {
+		return util.ARSuccess()
+	}
+
+	pods, err := pc.kubeCli.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: label.New().Instance(tcName).String(),
+	})
+	if err != nil {
+		return util.ARFail(err)
+	}
+	if msg := checkEvictionOrder(pod, pods.Items); msg != "" {
+		klog.Infof("tc[%s/%s] is in node upgrade maintenance, refuse to evict pod[%s/%s]: %s", namespace, tcName, namespace, name, msg)
+		return util.ARRetry(msg)
+	}
+
+	payload := &admitPayload{
+		pod:        pod,
+		controller: tc,
+		pdClient:   controller.GetPDClientFromService(pc.pdControl, tc),
+		controllerDesc: controllerDesc{
+			name:      tcName,
+			namespace: namespace,
+			kind:      v1alpha1.TiDBClusterKind,
+		},
+	}
+	if l.IsPD() {
+		return pc.admitEvictPDPod(payload, pods.Items)
+	} else if l.IsTiKV() {
+		return pc.admitEvictTiKVPod(payload)
+	}
+
+	pc.recorder.Event(tc, core.EventTypeNormal, nodeUpgradeEvictReason, podEvictEventMessage(name))
+	return util.ARSuccess()
+}
+
+// checkEvictionOrder returns the reason why the pod can't be evicted now. The eviction is rejected if any pod
+// of the cluster on other nodes is not ready, which may be evicted but not rescheduled yet, or if any pod on
+// the same node that should be evicted earlier is still running.
+func checkEvictionOrder(pod *core.Pod, pods []core.Pod) string {
+	order, ok := evictionOrder[label.Label(pod.Labels).ComponentType()]
+	if !ok {
+		return ""
+	}
+	for i := range pods {
+		p := &pods[i]
+		if p.Name == pod.Name || p.Status.Phase == core.PodSucceeded || p.Status.Phase == core.PodFailed {
+			continue
+		}
+		pOrder, ok := evictionOrder[label.Label(p.Labels).ComponentType()]
+		if !ok {
+			continue
+		}
+		if p.Spec.NodeName != pod.Spec.NodeName {
+			if p.DeletionTimestamp != nil || !podutil.IsPodReady(p) {
+				return fmt.Sprintf("pod %s on node %q is not ready, evict pods on node %q later", p.Name, p.Spec.NodeName, pod.Spec.NodeName)
+			}
+			continue
+		}
+		if p.DeletionTimestamp == nil && pOrder < order {
+			return fmt.Sprintf("pod %s on the same node should be evicted first", p.Name)
+		}
+	}
+	return ""
+}
+
+// admitEvictTiKVPod evicts the region leaders of the store before the tikv pod gets evicted, the evict leader
+// scheduler is removed when the pod is created again.
+func (pc *PodAdmissionControl) admitEvictTiKVPod(payload *admitPayload) *admission.AdmissionResponse {
+	pod := payload.pod
+	name := pod.Name
+	namespace := pod.Namespace
+	tc := payload.controller.(*v1alpha1.TidbCluster)
+
+	if tc.TiKVStsDesiredReplicas() < 2 {
+		klog.Infof("TiKV statefulset replicas are less than 2, skip evicting region leader for Pod %s/%s", namespace, name)
+		return util.ARSuccess()
+	}
+
+	storesInfo, err := payload.pdClient.GetStores()
+	if err != nil {
+		return util.ARFail(err)
+	}
+	store, err := getStoreByPod(pod, storesInfo)
+	if err != nil || store.Store.StateName != v1alpha1.TiKVStateUp {
+		klog.Infof("tikv pod[%s/%s] has no up store, admit to evict", namespace, name)
+		pc.recorder.Event(tc, core.EventTypeNormal, nodeUpgradeEvictReason, podEvictEventMessage(name))
+		return util.ARSuccess()
+	}
+
+	if _, evicting := pod.Annotations[EvictLeaderBeginTime]; !evicting {
+		if err := beginEvictLeader(pc.kubeCli, store.Store.Id, pod, payload.pdClient); err != nil {
+			return util.ARFail(err)
+		}
+		return util.ARRetry(fmt.Sprintf("begin evicting region leaders of store %d on pod %s", store.Store.Id, name))
+	}
+	if !isTiKVReadyToUpgrade(pod, store, tc.TiKVEvictLeaderTimeout()) {
+		return util.ARRetry(fmt.Sprintf("waiting for region leaders of store %d on pod %s to be evicted", store.Store.Id, name))
+	}
+
+	pc.recorder.Event(tc, core.EventTypeNormal, nodeUpgradeEvictReason, podEvictEventMessage(name))
+	return util.ARSuccess()
+}
+
+// admitEvictPDPod transfers the pd leader to a healthy member on another node before the pd pod gets evicted
+func (pc *PodAdmissionControl) admitEvictPDPod(payload *admitPayload, pods []core.Pod) *admission.AdmissionResponse {
+	pod := payload.pod
+	name := pod.Name
+	namespace := pod.Namespace
+	tc := payload.controller.(*v1alpha1.TidbCluster)
+
+	if tc.PDStsDesiredReplicas() < 2 {
+		klog.Infof("PD statefulset replicas are less than 2, admit to evict pod[%s/%s] ", namespace, name)
+		return util.ARSuccess()
+	}
+
+	isLeader, err := isPDLeader(payload.pdClient, pod)
+	if err != nil {
+		return util.ARFail(err)
+	}
+	if !isLeader {
+		pc.recorder.Event(tc, core.EventTypeNormal, nodeUpgradeEvictReason, podEvictEventMessage(name))
+		return util.ARSuccess()
+	}
+
+	nodeOfPods := make(map[string]string, len(pods))
+	for _, p := range pods {
+		nodeOfPods[p.Name] = p.Spec.NodeName
+	}
+	names := make([]string, 0, len(tc.Status.PD.Members))
+	for memberName := range tc.Status.PD.Members {
+		names = append(names, memberName)
+	}
+	sort.Strings(names)
+	targetName := ""
+	for _, memberName := range names {
+		podName := strings.Split(memberName, ".")[0]
+		if podName == name || !tc.Status.PD.Members[memberName].Health {
+			continue
+		}
+		if node, ok := nodeOfPods[podName]; !ok || node == pod.Spec.NodeName {
+			continue
+		}
+		targetName = memberName
+		break
+	}
+	if targetName == "" {
+		return util.ARRetry(fmt.Sprintf("no healthy pd member on other nodes to transfer the leader of pod %s to", name))
+	}
+
+	if err := payload.pdClient.TransferPDLeader(targetName); err != nil {
+		klog.Errorf("tc[%s/%s] failed to transfer pd leader to %s,%v", namespace, tc.Name, targetName, err)
+		return util.ARFail(err)
+	}
+	return util.ARRetry(fmt.Sprintf("transferring pd leader from pod %s to %s", name, targetName))
+}
+
+func podEvictEventMessage(name string) string {
+	return fmt.Sprintf("pod [%s] evicted for node upgrade", name)
+}
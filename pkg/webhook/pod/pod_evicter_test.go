@@ -0,0 +1,239 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newPodForEviction(name, component, nodeName string, ready bool) core.Pod {
+	status := core.ConditionFalse
+	if ready {
+		status = core.ConditionTrue
+	}
+	return core.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    label.New().Instance(tcName).Component(component).Labels(),
+		},
+		Spec: core.PodSpec{NodeName: nodeName},
+		Status: core.PodStatus{
+			Phase:      core.PodRunning,
+			Conditions: []core.PodCondition{{Type: core.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestCheckEvictionOrder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tidb0 := newPodForEviction("tc-tidb-0", label.TiDBLabelVal, "node-1", true)
+	tikv0 := newPodForEviction("tc-tikv-0", label.TiKVLabelVal, "node-1", true)
+	pd0 := newPodForEviction("tc-pd-0", label.PDLabelVal, "node-1", true)
+	tikv1 := newPodForEviction("tc-tikv-1", label.TiKVLabelVal, "node-2", true)
+	discovery := newPodForEviction("tc-discovery", label.DiscoveryLabelVal, "node-2", false)
+
+	// the pods on the same node are evicted in order
+	g.Expect(checkEvictionOrder(&tidb0, []core.Pod{tidb0, tikv0, pd0, tikv1, discovery})).Should(BeEmpty())
+	g.Expect(checkEvictionOrder(&tikv0, []core.Pod{tidb0, tikv0, pd0, tikv1, discovery})).ShouldNot(BeEmpty())
+	g.Expect(checkEvictionOrder(&tikv0, []core.Pod{tikv0, pd0, tikv1, discovery})).Should(BeEmpty())
+	g.Expect(checkEvictionOrder(&pd0, []core.Pod{tikv0, pd0, tikv1})).ShouldNot(BeEmpty())
+
+	// the pods on the other nodes must be ready
+	tikv1.Status.Conditions[0].Status = core.ConditionFalse
+	g.Expect(checkEvictionOrder(&tidb0, []core.Pod{tidb0, tikv0, pd0, tikv1})).ShouldNot(BeEmpty())
+	tikv1.Status.Conditions[0].Status = core.ConditionTrue
+	tikv1.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	g.Expect(checkEvictionOrder(&tidb0, []core.Pod{tidb0, tikv0, pd0, tikv1})).ShouldNot(BeEmpty())
+}
+
+func TestAdmitEvictTiKVPod(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name        string
+		evicting    bool
+		leaderCount int
+		stateName   string
+		wantAllowed bool
+		wantRetry   bool
+	}{
+		{
+			name:        "begin evicting leaders",
+			evicting:    false,
+			leaderCount: 10,
+			stateName:   v1alpha1.TiKVStateUp,
+			wantAllowed: false,
+			wantRetry:   true,
+		},
+		{
+			name:        "waiting for leaders to be evicted",
+			evicting:    true,
+			leaderCount: 10,
+			stateName:   v1alpha1.TiKVStateUp,
+			wantAllowed: false,
+			wantRetry:   true,
+		},
+		{
+			name:        "leaders are evicted",
+			evicting:    true,
+			leaderCount: 0,
+			stateName:   v1alpha1.TiKVStateUp,
+			wantAllowed: true,
+		},
+		{
+			name:        "store is down",
+			evicting:    false,
+			leaderCount: 10,
+			stateName:   v1alpha1.TiKVStateDown,
+			wantAllowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeCli := kubefake.NewSimpleClientset()
+			podAdmissionControl := newPodAdmissionControl(nil, kubeCli, fake.NewSimpleClientset())
+			tc := newTidbClusterForPodAdmissionControl(pdReplicas, tikvReplicas)
+			informer := kubeinformers.NewSharedInformerFactory(kubeCli, 0)
+			fakePDClient := controller.NewFakePDClient(pdapi.NewFakePDControl(informer.Core().V1().Secrets().Lister()), tc)
+
+			pod := newPodForEviction(member.TikvPodName(tcName, 1), label.TiKVLabelVal, "node-1", true)
+			if tt.evicting {
+				pod.Annotations = map[string]string{EvictLeaderBeginTime: time.Now().Format(time.RFC3339)}
+			}
+			_, err := kubeCli.CoreV1().Pods(namespace).Create(context.TODO(), &pod, metav1.CreateOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			fakePDClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+				return &pdapi.StoresInfo{
+					Count: 1,
+					Stores: []*pdapi.StoreInfo{
+						{
+							Store: &pdapi.MetaStore{
+								StateName: tt.stateName,
+								Store: &metapb.Store{
+									Id:      1,
+									Address: fmt.Sprintf("%s.%s-tikv-peer.%s.svc:20160", pod.Name, tcName, namespace),
+								},
+							},
+							Status: &pdapi.StoreStatus{LeaderCount: tt.leaderCount},
+						},
+					},
+				}, nil
+			})
+			fakePDClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+				return nil, nil
+			})
+
+			payload := &admitPayload{
+				pod:        &pod,
+				controller: tc,
+				pdClient:   fakePDClient,
+			}
+			resp := podAdmissionControl.admitEvictTiKVPod(payload)
+			g.Expect(resp.Allowed).Should(Equal(tt.wantAllowed))
+			if tt.wantRetry {
+				g.Expect(resp.Result.Code).Should(Equal(int32(http.StatusTooManyRequests)))
+			}
+			if !tt.evicting && tt.wantRetry {
+				updated, err := kubeCli.CoreV1().Pods(namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(updated.Annotations).Should(HaveKey(EvictLeaderBeginTime))
+			}
+		})
+	}
+}
+
+func TestAdmitEvictPDPod(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name         string
+		leader       string
+		pdNodes      []string
+		wantAllowed  bool
+		wantTransfer string
+	}{
+		{
+			name:        "not leader",
+			leader:      "tc-pd-1",
+			pdNodes:     []string{"node-1", "node-2", "node-3"},
+			wantAllowed: true,
+		},
+		{
+			name:         "transfer leader",
+			leader:       "tc-pd-0",
+			pdNodes:      []string{"node-1", "node-2", "node-3"},
+			wantAllowed:  false,
+			wantTransfer: "tc-pd-1",
+		},
+		{
+			name:         "transfer leader to other nodes",
+			leader:       "tc-pd-0",
+			pdNodes:      []string{"node-1", "node-1", "node-3"},
+			wantAllowed:  false,
+			wantTransfer: "tc-pd-2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeCli := kubefake.NewSimpleClientset()
+			podAdmissionControl := newPodAdmissionControl(nil, kubeCli, fake.NewSimpleClientset())
+			tc := newTidbClusterForPodAdmissionControl(pdReplicas, tikvReplicas)
+			informer := kubeinformers.NewSharedInformerFactory(kubeCli, 0)
+			fakePDClient := controller.NewFakePDClient(pdapi.NewFakePDControl(informer.Core().V1().Secrets().Lister()), tc)
+
+			var pods []core.Pod
+			for i, node := range tt.pdNodes {
+				pods = append(pods, newPodForEviction(member.PdPodName(tcName, int32(i)), label.PDLabelVal, node, true))
+			}
+			fakePDClient.AddReaction(pdapi.GetPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+				return &pdpb.Member{Name: tt.leader}, nil
+			})
+			transferred := ""
+			fakePDClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+				transferred = action.Name
+				return nil, nil
+			})
+
+			payload := &admitPayload{
+				pod:        &pods[0],
+				controller: tc,
+				pdClient:   fakePDClient,
+			}
+			resp := podAdmissionControl.admitEvictPDPod(payload, pods)
+			g.Expect(resp.Allowed).Should(Equal(tt.wantAllowed))
+			g.Expect(transferred).Should(Equal(tt.wantTransfer))
+		})
+	}
+}
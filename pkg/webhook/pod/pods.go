@@ -160,6 +160,11 @@ func (pc *PodAdmissionControl) Validate(ar *admission.AdmissionRequest) *admissi
 	serviceAccount := ar.UserInfo.Username
 	klog.Infof("receive %s pod[%s/%s] by sa[%s]", operation, namespace, name, serviceAccount)
 
+	// evictions are usually requested by the users or tools that drain the nodes
+	if operation == admission.Create && ar.SubResource == evictionSubResource {
+		return pc.admitEvictPods(name, namespace)
+	}
+
 	if !pc.serviceAccounts.Has(serviceAccount) {
 		klog.Infof("Request was not sent by known controlled ServiceAccounts, admit to %s pod [%s/%s]", operation, namespace, name)
 		return util.ARSuccess()
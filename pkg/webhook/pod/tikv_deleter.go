@@ -52,7 +52,11 @@ func (pc *PodAdmissionControl) admitDeleteTiKVPods(payload *admitPayload) *admis
 
 	switch controllerKind {
 	case v1alpha1.TiDBClusterKind:
-		expectedAddress = fmt.Sprintf("%s.%s-tikv-peer.%s.svc:20160", name, controllerName, namespace)
+		port := int32(v1alpha1.DefaultTiKVPort)
+		if tc, ok := payload.controller.(*v1alpha1.TidbCluster); ok {
+			port = tc.TiKVPort()
+		}
+		expectedAddress = fmt.Sprintf("%s.%s-tikv-peer.%s.svc:%d", name, controllerName, namespace, port)
 	default:
 		// unreachable
 		klog.V(4).Infof("tikv pod[%s/%s] controlled by unknown controllerKind[%s], admite to delete", namespace, name, controllerKind)
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 
 	"github.com/openshift/generic-admission-server/pkg/apiserver"
+	"github.com/pingcap/tidb-operator/pkg/registry"
 	"github.com/pingcap/tidb-operator/pkg/webhook/util"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -71,8 +72,13 @@ func (w *StrategyAdmissionHook) Validate(ar *admissionv1beta1.AdmissionRequest)
 		return util.ARFail(err)
 	}
 	var allErr field.ErrorList
+	var warnings []string
+	ws, hasWarnings := s.(registry.WarningsStrategy)
 	if ar.Operation == admissionv1beta1.Create {
 		allErr = s.Validate(context.TODO(), obj)
+		if hasWarnings {
+			warnings = ws.WarningsOnCreate(context.TODO(), obj)
+		}
 	} else {
 		old := s.NewObject()
 		if err := json.Unmarshal(ar.OldObject.Raw, old); err != nil {
@@ -80,11 +86,16 @@ func (w *StrategyAdmissionHook) Validate(ar *admissionv1beta1.AdmissionRequest)
 			return util.ARFail(err)
 		}
 		allErr = s.ValidateUpdate(context.TODO(), obj, old)
+		if hasWarnings {
+			warnings = ws.WarningsOnUpdate(context.TODO(), obj, old)
+		}
 	}
+	resp := util.ARSuccess()
 	if len(allErr) > 0 {
-		return util.ARFail(allErr.ToAggregate())
+		resp = util.ARFail(allErr.ToAggregate())
 	}
-	return util.ARSuccess()
+	resp.Warnings = warnings
+	return resp
 }
 
 func (w *StrategyAdmissionHook) Admit(ar *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
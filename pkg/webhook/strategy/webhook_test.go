@@ -208,6 +208,15 @@ func TestStrategyAdmissionHook_Validate(t *testing.T) {
 		}
 		g.Expect(s.validateTracker.GetRequests()).To(Equal(tt.expectedValidateTimes))
 		g.Expect(s.validateUpdateTracker.GetRequests()).To(Equal(tt.expectedValidateForUpdateTimes))
+		// the warnings are returned whether the object is valid or not
+		switch {
+		case tt.expectedValidateTimes > 0:
+			g.Expect(resp.Warnings).To(Equal([]string{"warning on create"}))
+		case tt.expectedValidateForUpdateTimes > 0:
+			g.Expect(resp.Warnings).To(Equal([]string{"warning on update"}))
+		default:
+			g.Expect(resp.Warnings).To(BeEmpty())
+		}
 	}
 
 	for i := range testcases {
@@ -252,6 +261,14 @@ func (s *FakeStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Obje
 	return allErrs
 }
 
+func (s *FakeStrategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
+	return []string{"warning on create"}
+}
+
+func (s *FakeStrategy) WarningsOnUpdate(ctx context.Context, obj, old runtime.Object) []string {
+	return []string{"warning on update"}
+}
+
 func TestValidatingResource(t *testing.T) {
 	r := NewRegistry()
 	w := NewStrategyAdmissionHook(&r)
@@ -15,6 +15,7 @@ package util
 
 import (
 	"encoding/json"
+	"net/http"
 
 	"gomodules.xyz/jsonpatch/v2"
 	admission "k8s.io/api/admission/v1beta1"
@@ -34,6 +35,19 @@ func ARFail(err error) *admission.AdmissionResponse {
 	}
 }
 
+// ARRetry is a helper function to create an AdmissionResponse which rejects
+// the request temporarily, clients like `kubectl drain` retry the eviction later
+func ARRetry(msg string) *admission.AdmissionResponse {
+	return &admission.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Message: msg,
+			Reason:  metav1.StatusReasonTooManyRequests,
+			Code:    http.StatusTooManyRequests,
+		},
+	}
+}
+
 // ARSuccess return allow to action
 func ARSuccess() *admission.AdmissionResponse {
 	return &admission.AdmissionResponse{
@@ -31,6 +31,10 @@ func TestAR(t *testing.T) {
 	resp = ARFail(errors.New("err"))
 	g.Expect(resp.Allowed).Should(BeFalse())
 
+	resp = ARRetry("retry")
+	g.Expect(resp.Allowed).Should(BeFalse())
+	g.Expect(resp.Result.Code).Should(Equal(int32(429)))
+
 	resp = ARSuccess()
 	g.Expect(resp.Allowed).Should(BeTrue())
 
@@ -46,6 +46,34 @@ func (p *proxiedTiDBClient) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*c
 	panic("implement when necessary")
 }
 
+func (p *proxiedTiDBClient) GetConfig(tc *v1alpha1.TidbCluster, ordinal int32) (map[string]interface{}, error) {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) GetTables(tc *v1alpha1.TidbCluster, ordinal int32) (map[string][]string, error) {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) GetTableIDs(tc *v1alpha1.TidbCluster, ordinal int32, db, table string) ([]int64, error) {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) GetSuperReadOnly(tc *v1alpha1.TidbCluster, ordinal int32, user, password string) (bool, error) {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) SetSuperReadOnly(tc *v1alpha1.TidbCluster, ordinal int32, user, password string, readOnly bool) error {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) SyncUser(tc *v1alpha1.TidbCluster, ordinal int32, user, password string, sqlUser controller.SQLUser) (bool, error) {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) SetTikvGCLifeTime(from *v1alpha1.TiDBAccessConfig, password, gcLifeTime string) error {
+	panic("implement when necessary")
+}
+
 func (p *proxiedTiDBClient) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error) {
 	tcName := tc.GetName()
 	ns := tc.GetNamespace()
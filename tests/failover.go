@@ -305,7 +305,7 @@ func (oa *OperatorActions) TruncateSSTFileThenCheckFailover(info *TidbClusterCon
 			return true, nil
 		}
 		tc.Status.TiKV.FailureStores = nil
-		_, err = oa.cli.PingcapV1alpha1().TidbClusters(ns).Update(context.TODO(), tc, metav1.UpdateOptions{})
+		_, err = oa.cli.PingcapV1alpha1().TidbClusters(ns).UpdateStatus(context.TODO(), tc, metav1.UpdateOptions{})
 		if err != nil {
 			log.Logf("ERROR: %v", err)
 		}
@@ -548,7 +548,7 @@ func (oa *OperatorActions) CheckRecover(cluster *TidbClusterConfig) (bool, error
 	// recover tikv manually
 	if tc.Status.TiKV.FailureStores != nil {
 		tc.Status.TiKV.FailureStores = nil
-		_, err = oa.cli.PingcapV1alpha1().TidbClusters(cluster.Namespace).Update(context.TODO(), tc, metav1.UpdateOptions{})
+		_, err = oa.cli.PingcapV1alpha1().TidbClusters(cluster.Namespace).UpdateStatus(context.TODO(), tc, metav1.UpdateOptions{})
 		if err != nil {
 			log.Logf("ERROR: failed to set status.tikv.failureStore to nil, %v", err)
 			return false, nil